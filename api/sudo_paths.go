@@ -15,20 +15,40 @@ import (
 // contain templated fields.)
 var sudoPaths = map[string]*regexp.Regexp{
 	"/auth/token/accessors":                         regexp.MustCompile(`^/auth/token/accessors/?$`),
+	"/auth/token/impersonate":                       regexp.MustCompile(`^/auth/token/impersonate$`),
 	"/auth/token/revoke-orphan":                     regexp.MustCompile(`^/auth/token/revoke-orphan$`),
+	"/identity/entity-alias/import":                 regexp.MustCompile(`^/identity/entity-alias/import$`),
+	"/identity/entity/export":                       regexp.MustCompile(`^/identity/entity/export$`),
+	"/identity/entity/import":                       regexp.MustCompile(`^/identity/entity/import$`),
+	"/identity/group/import":                        regexp.MustCompile(`^/identity/group/import$`),
 	"/pki/root":                                     regexp.MustCompile(`^/pki/root$`),
 	"/pki/root/sign-self-issued":                    regexp.MustCompile(`^/pki/root/sign-self-issued$`),
+	"/sys/advisor":                                  regexp.MustCompile(`^/sys/advisor$`),
 	"/sys/audit":                                    regexp.MustCompile(`^/sys/audit$`),
+	"/sys/audit-export/{path}":                      regexp.MustCompile(`^/sys/audit-export/.+$`),
+	"/sys/audit-tail/{path}":                        regexp.MustCompile(`^/sys/audit-tail/.+$`),
+	"/sys/audit-test/{path}":                        regexp.MustCompile(`^/sys/audit-test/.+$`),
 	"/sys/audit/{path}":                             regexp.MustCompile(`^/sys/audit/.+$`),
 	"/sys/auth/{path}":                              regexp.MustCompile(`^/sys/auth/.+$`),
+	"/sys/auth/{path}/owners":                       regexp.MustCompile(`^/sys/auth/.+/owners$`),
 	"/sys/auth/{path}/tune":                         regexp.MustCompile(`^/sys/auth/.+/tune$`),
 	"/sys/config/auditing/request-headers":          regexp.MustCompile(`^/sys/config/auditing/request-headers$`),
 	"/sys/config/auditing/request-headers/{header}": regexp.MustCompile(`^/sys/config/auditing/request-headers/.+$`),
 	"/sys/config/cors":                              regexp.MustCompile(`^/sys/config/cors$`),
+	"/sys/config/state":                             regexp.MustCompile(`^/sys/config/state$`),
+	"/sys/config/state/drift":                       regexp.MustCompile(`^/sys/config/state/drift$`),
 	"/sys/config/ui/headers":                        regexp.MustCompile(`^/sys/config/ui/headers/?$`),
 	"/sys/config/ui/headers/{header}":               regexp.MustCompile(`^/sys/config/ui/headers/.+$`),
+	"/sys/import":                                   regexp.MustCompile(`^/sys/import$`),
+	"/sys/import/cancel/{import_id}":                regexp.MustCompile(`^/sys/import/cancel/.+$`),
+	"/sys/import/status":                            regexp.MustCompile(`^/sys/import/status/?$`),
+	"/sys/import/status/{import_id}":                regexp.MustCompile(`^/sys/import/status/.+$`),
+	"/sys/internal/debug/request-capture":           regexp.MustCompile(`^/sys/internal/debug/request-capture$`),
+	"/sys/internal/debug/request/{id}":              regexp.MustCompile(`^/sys/internal/debug/request/.+$`),
 	"/sys/internal/inspect/router/{tag}":            regexp.MustCompile(`^/sys/internal/inspect/router/.+$`),
 	"/sys/leases":                                   regexp.MustCompile(`^/sys/leases$`),
+	"/sys/leases/export":                            regexp.MustCompile(`^/sys/leases/export$`),
+	"/sys/leases/import":                            regexp.MustCompile(`^/sys/leases/import$`),
 	// This entry is a bit wrong... sys/leases/lookup does NOT require sudo. But sys/leases/lookup/ with a trailing
 	// slash DOES require sudo. But the part of the Vault CLI that uses this logic doesn't pass operation-appropriate
 	// trailing slashes, it always strips them off, so we end up giving the wrong answer for one of these.
@@ -39,6 +59,8 @@ var sudoPaths = map[string]*regexp.Regexp{
 	"/sys/plugins/catalog/{name}":        regexp.MustCompile(`^/sys/plugins/catalog/[^/]+$`),
 	"/sys/plugins/catalog/{type}":        regexp.MustCompile(`^/sys/plugins/catalog/[\w-]+$`),
 	"/sys/plugins/catalog/{type}/{name}": regexp.MustCompile(`^/sys/plugins/catalog/[\w-]+/[^/]+$`),
+	"/sys/plugins/runtime/stats":         regexp.MustCompile(`^/sys/plugins/runtime/stats$`),
+	"/sys/pprof/capture/config":          regexp.MustCompile(`^/sys/pprof/capture/config$`),
 	"/sys/raw":                           regexp.MustCompile(`^/sys/raw$`),
 	"/sys/raw/{path}":                    regexp.MustCompile(`^/sys/raw/.+$`),
 	"/sys/remount":                       regexp.MustCompile(`^/sys/remount$`),
@@ -55,6 +55,24 @@ func (c *Sys) AuditHashWithContext(ctx context.Context, path string, input strin
 	return hashStr, nil
 }
 
+// TestAudit logs a test message through the audit device at the given path.
+func (c *Sys) TestAudit(path string) error {
+	return c.TestAuditWithContext(context.Background(), path)
+}
+
+func (c *Sys) TestAuditWithContext(ctx context.Context, path string) error {
+	ctx, cancelFunc := c.c.withConfiguredTimeout(ctx)
+	defer cancelFunc()
+
+	r := c.c.NewRequest(http.MethodPut, fmt.Sprintf("/v1/sys/audit-test/%s", path))
+
+	resp, err := c.c.rawRequestWithContext(ctx, r)
+	if err == nil {
+		defer resp.Body.Close()
+	}
+	return err
+}
+
 func (c *Sys) ListAudit() (map[string]*Audit, error) {
 	return c.ListAuditWithContext(context.Background())
 }
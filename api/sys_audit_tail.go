@@ -0,0 +1,50 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// auditTailMaxEntrySize is the largest audit entry the tail stream accepts.
+const auditTailMaxEntrySize = 16 * 1024 * 1024
+
+// AuditTail returns a channel that outputs the JSON audit entries logged by
+// the audit device at the given path, as they are logged. If filter is not
+// empty, only the entries matching the filter expression are streamed. The
+// channel is closed when the stream ends or the context is canceled.
+func (c *Sys) AuditTail(ctx context.Context, path string, filter string) (chan string, error) {
+	r := c.c.NewRequest(http.MethodGet, fmt.Sprintf("/v1/sys/audit-tail/%s", path))
+	if filter != "" {
+		r.Params.Add("filter", filter)
+	}
+
+	resp, err := c.c.RawRequestWithContext(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	entryCh := make(chan string, 64)
+
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(nil, auditTailMaxEntrySize)
+
+		defer close(entryCh)
+		defer resp.Body.Close()
+
+		for scanner.Scan() {
+			select {
+			case entryCh <- scanner.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return entryCh, nil
+}
@@ -38,15 +38,16 @@ func (c *Sys) HealthWithContext(ctx context.Context) (*HealthResponse, error) {
 }
 
 type HealthResponse struct {
-	Initialized                bool   `json:"initialized"`
-	Sealed                     bool   `json:"sealed"`
-	Standby                    bool   `json:"standby"`
-	PerformanceStandby         bool   `json:"performance_standby"`
-	ReplicationPerformanceMode string `json:"replication_performance_mode"`
-	ReplicationDRMode          string `json:"replication_dr_mode"`
-	ServerTimeUTC              int64  `json:"server_time_utc"`
-	Version                    string `json:"version"`
-	ClusterName                string `json:"cluster_name,omitempty"`
-	ClusterID                  string `json:"cluster_id,omitempty"`
-	LastWAL                    uint64 `json:"last_wal,omitempty"`
+	Initialized                bool     `json:"initialized"`
+	Sealed                     bool     `json:"sealed"`
+	Standby                    bool     `json:"standby"`
+	PerformanceStandby         bool     `json:"performance_standby"`
+	ReplicationPerformanceMode string   `json:"replication_performance_mode"`
+	ReplicationDRMode          string   `json:"replication_dr_mode"`
+	ServerTimeUTC              int64    `json:"server_time_utc"`
+	Version                    string   `json:"version"`
+	ClusterName                string   `json:"cluster_name,omitempty"`
+	ClusterID                  string   `json:"cluster_id,omitempty"`
+	LastWAL                    uint64   `json:"last_wal,omitempty"`
+	Warnings                   []string `json:"warnings,omitempty"`
 }
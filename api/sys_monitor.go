@@ -10,21 +10,48 @@ import (
 	"net/http"
 )
 
+// MonitorInput selects the log messages streamed by MonitorWithInput.
+type MonitorInput struct {
+	LogLevel  string
+	LogFormat string
+
+	// Subsystems and Mounts restrict the log messages to those of the given
+	// subsystems, such as "core", and of the mounts at the given paths.
+	Subsystems []string
+	Mounts     []string
+}
+
 // Monitor returns a channel that outputs strings containing the log messages
 // coming from the server.
 func (c *Sys) Monitor(ctx context.Context, logLevel string, logFormat string) (chan string, error) {
+	return c.MonitorWithInput(ctx, &MonitorInput{
+		LogLevel:  logLevel,
+		LogFormat: logFormat,
+	})
+}
+
+// MonitorWithInput returns a channel that outputs strings containing the log
+// messages coming from the server, filtered by the server as per the input.
+func (c *Sys) MonitorWithInput(ctx context.Context, input *MonitorInput) (chan string, error) {
 	r := c.c.NewRequest(http.MethodGet, "/v1/sys/monitor")
 
-	if logLevel == "" {
+	if input.LogLevel == "" {
 		r.Params.Add("log_level", "info")
 	} else {
-		r.Params.Add("log_level", logLevel)
+		r.Params.Add("log_level", input.LogLevel)
 	}
 
-	if logFormat == "" {
+	if input.LogFormat == "" {
 		r.Params.Add("log_format", "standard")
 	} else {
-		r.Params.Add("log_format", logFormat)
+		r.Params.Add("log_format", input.LogFormat)
+	}
+
+	for _, subsystem := range input.Subsystems {
+		r.Params.Add("subsystem", subsystem)
+	}
+	for _, mount := range input.Mounts {
+		r.Params.Add("mount", mount)
 	}
 
 	resp, err := c.c.RawRequestWithContext(ctx, r)
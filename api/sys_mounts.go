@@ -248,6 +248,7 @@ type MountInput struct {
 	Config                MountConfigInput  `json:"config"`
 	Local                 bool              `json:"local"`
 	SealWrap              bool              `json:"seal_wrap" mapstructure:"seal_wrap"`
+	DataKey               bool              `json:"data_key,omitempty" mapstructure:"data_key"`
 	ExternalEntropyAccess bool              `json:"external_entropy_access" mapstructure:"external_entropy_access"`
 	Options               map[string]string `json:"options"`
 
@@ -271,6 +272,13 @@ type MountConfigInput struct {
 	AllowedManagedKeys        []string                `json:"allowed_managed_keys,omitempty" mapstructure:"allowed_managed_keys"`
 	PluginVersion             string                  `json:"plugin_version,omitempty"`
 	UserLockoutConfig         *UserLockoutConfigInput `json:"user_lockout_config,omitempty"`
+	RollbackPeriod            string                  `json:"rollback_period,omitempty" mapstructure:"rollback_period"`
+	LeaseTTLJitter            *int                    `json:"lease_ttl_jitter,omitempty" mapstructure:"lease_ttl_jitter"`
+	MaxRequestSize            *int64                  `json:"max_request_size,omitempty" mapstructure:"max_request_size"`
+	MaxResponseSize           *int64                  `json:"max_response_size,omitempty" mapstructure:"max_response_size"`
+	TokenBindSourceCIDR       *bool                   `json:"token_bind_source_cidr,omitempty" mapstructure:"token_bind_source_cidr"`
+	TokenBindClientCert       *bool                   `json:"token_bind_client_cert,omitempty" mapstructure:"token_bind_client_cert"`
+	IdentityTokenRole         *string                 `json:"identity_token_role,omitempty" mapstructure:"identity_token_role"`
 	// Deprecated: This field will always be blank for newer server responses.
 	PluginName string `json:"plugin_name,omitempty" mapstructure:"plugin_name"`
 }
@@ -284,6 +292,7 @@ type MountOutput struct {
 	Options               map[string]string `json:"options"`
 	Local                 bool              `json:"local"`
 	SealWrap              bool              `json:"seal_wrap" mapstructure:"seal_wrap"`
+	DataKey               bool              `json:"data_key,omitempty" mapstructure:"data_key"`
 	ExternalEntropyAccess bool              `json:"external_entropy_access" mapstructure:"external_entropy_access"`
 	PluginVersion         string            `json:"plugin_version" mapstructure:"plugin_version"`
 	RunningVersion        string            `json:"running_plugin_version" mapstructure:"running_plugin_version"`
@@ -303,6 +312,13 @@ type MountConfigOutput struct {
 	TokenType                 string                   `json:"token_type,omitempty" mapstructure:"token_type"`
 	AllowedManagedKeys        []string                 `json:"allowed_managed_keys,omitempty" mapstructure:"allowed_managed_keys"`
 	UserLockoutConfig         *UserLockoutConfigOutput `json:"user_lockout_config,omitempty"`
+	RollbackPeriod            int                      `json:"rollback_period,omitempty" mapstructure:"rollback_period"`
+	LeaseTTLJitter            int                      `json:"lease_ttl_jitter,omitempty" mapstructure:"lease_ttl_jitter"`
+	MaxRequestSize            int64                    `json:"max_request_size,omitempty" mapstructure:"max_request_size"`
+	MaxResponseSize           int64                    `json:"max_response_size,omitempty" mapstructure:"max_response_size"`
+	TokenBindSourceCIDR       bool                     `json:"token_bind_source_cidr,omitempty" mapstructure:"token_bind_source_cidr"`
+	TokenBindClientCert       bool                     `json:"token_bind_client_cert,omitempty" mapstructure:"token_bind_client_cert"`
+	IdentityTokenRole         string                   `json:"identity_token_role,omitempty" mapstructure:"identity_token_role"`
 	// Deprecated: This field will always be blank for newer server responses.
 	PluginName string `json:"plugin_name,omitempty" mapstructure:"plugin_name"`
 }
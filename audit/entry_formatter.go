@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/jefferai/jsonx"
+	"github.com/ryanuber/go-glob"
 
 	"github.com/hashicorp/vault/helper/namespace"
 	"github.com/hashicorp/vault/sdk/logical"
@@ -126,7 +127,8 @@ func (f *EntryFormatter) Process(ctx context.Context, e *eventlogger.Event) (*ev
 	// don't support a prefix just sitting there.
 	// However, this would be a breaking change to how Vault currently works to
 	// include the prefix as part of the JSON object or XML document.
-	if f.prefix != "" {
+	// Parquet rows have no room for a prefix, it is not applied.
+	if f.prefix != "" && f.config.RequiredFormat != ParquetFormat {
 		result = append([]byte(f.prefix), result...)
 	}
 
@@ -206,6 +208,7 @@ func (f *EntryFormatter) FormatRequest(ctx context.Context, in *logical.LogInput
 			RemainingUses:             req.ClientTokenRemainingUses,
 			TokenType:                 auth.TokenType.String(),
 			TokenTTL:                  int64(auth.TTL.Seconds()),
+			Impersonation:             newImpersonation(auth),
 		},
 
 		Request: &Request{
@@ -263,6 +266,11 @@ func (f *EntryFormatter) FormatRequest(ctx context.Context, in *logical.LogInput
 		reqEntry.Time = time.Now().UTC().Format(time.RFC3339Nano)
 	}
 
+	if err := redactEntry(reqEntry, f.config.redactions); err != nil {
+		return nil, err
+	}
+	reqEntry.schemaVersion = f.config.SchemaVersion
+
 	return reqEntry, nil
 }
 
@@ -298,6 +306,14 @@ func (f *EntryFormatter) FormatResponse(ctx context.Context, in *logical.LogInpu
 
 	elideListResponseData := f.config.ElideListResponses && req.Operation == logical.ListOperation
 
+	// The response data of excluded paths is omitted altogether, as even its
+	// HMAC may be too sensitive to log.
+	if resp.Data != nil && f.config.excludesResponseData(req.Path) {
+		excluded := *resp
+		excluded.Data = nil
+		resp = &excluded
+	}
+
 	var respData map[string]interface{}
 	if f.config.Raw {
 		// In the non-raw case, elision of list response data occurs inside HashResponse, to avoid redundant deep
@@ -358,6 +374,7 @@ func (f *EntryFormatter) FormatResponse(ctx context.Context, in *logical.LogInpu
 			EntityID:                  resp.Auth.EntityID,
 			TokenType:                 resp.Auth.TokenType.String(),
 			TokenTTL:                  int64(resp.Auth.TTL.Seconds()),
+			Impersonation:             newImpersonation(resp.Auth),
 		}
 		if !resp.Auth.IssueTime.IsZero() {
 			respAuth.TokenIssueTime = resp.Auth.IssueTime.Format(time.RFC3339)
@@ -410,6 +427,7 @@ func (f *EntryFormatter) FormatResponse(ctx context.Context, in *logical.LogInpu
 			EntityCreated:             auth.EntityCreated,
 			TokenType:                 auth.TokenType.String(),
 			TokenTTL:                  int64(auth.TTL.Seconds()),
+			Impersonation:             newImpersonation(auth),
 		},
 
 		Request: &Request{
@@ -457,6 +475,14 @@ func (f *EntryFormatter) FormatResponse(ctx context.Context, in *logical.LogInpu
 		},
 	}
 
+	if f.config.RequestMetrics && in.RequestMetrics != nil {
+		respEntry.Metrics = &Metrics{
+			StorageReads:      in.RequestMetrics.StorageReads,
+			StorageWrites:     in.RequestMetrics.StorageWrites,
+			HandlerDurationUs: in.RequestMetrics.HandlerDuration.Microseconds(),
+		}
+	}
+
 	if auth.PolicyResults != nil {
 		respEntry.Auth.PolicyResults = &PolicyResults{
 			Allowed: auth.PolicyResults.Allowed,
@@ -483,11 +509,28 @@ func (f *EntryFormatter) FormatResponse(ctx context.Context, in *logical.LogInpu
 		respEntry.Time = time.Now().UTC().Format(time.RFC3339Nano)
 	}
 
+	if err := redactEntry(respEntry, f.config.redactions); err != nil {
+		return nil, err
+	}
+	respEntry.schemaVersion = f.config.SchemaVersion
+
 	return respEntry, nil
 }
 
+// excludesResponseData returns whether the response data of requests to the
+// given path is omitted from entries.
+func (c FormatterConfig) excludesResponseData(path string) bool {
+	for _, pattern := range c.ExcludedResponsePaths {
+		if glob.Glob(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
 // NewFormatterConfig should be used to create a FormatterConfig.
-// Accepted options: WithElision, WithHMACAccessor, WithOmitTime, WithRaw, WithFormat.
+// Accepted options: WithElision, WithExcludedResponsePaths, WithHMACAccessor, WithOmitTime, WithRaw, WithFormat,
+// WithRedaction, WithSchemaVersion.
 func NewFormatterConfig(opt ...Option) (FormatterConfig, error) {
 	const op = "audit.NewFormatterConfig"
 
@@ -496,12 +539,32 @@ func NewFormatterConfig(opt ...Option) (FormatterConfig, error) {
 		return FormatterConfig{}, fmt.Errorf("%s: error applying options: %w", op, err)
 	}
 
+	redactions := make([]redactionPath, 0, len(opts.withRedaction))
+	for _, rule := range opts.withRedaction {
+		p, err := parseRedactionPath(rule.Path)
+		if err != nil {
+			return FormatterConfig{}, fmt.Errorf("%s: %w", op, err)
+		}
+		redactions = append(redactions, p)
+	}
+
+	// Parquet rows are built from the fields of v1 entries.
+	if opts.withSchemaVersion != SchemaV1 && opts.withFormat == ParquetFormat {
+		return FormatterConfig{}, fmt.Errorf("%s: schema version %q is not supported by the %q format: %w", op, opts.withSchemaVersion, opts.withFormat, event.ErrInvalidParameter)
+	}
+
 	return FormatterConfig{
-		ElideListResponses: opts.withElision,
-		HMACAccessor:       opts.withHMACAccessor,
-		OmitTime:           opts.withOmitTime,
-		Raw:                opts.withRaw,
-		RequiredFormat:     opts.withFormat,
+		ElideListResponses:    opts.withElision,
+		HMACAccessor:          opts.withHMACAccessor,
+		OmitTime:              opts.withOmitTime,
+		Raw:                   opts.withRaw,
+		RequestMetrics:        opts.withRequestMetrics,
+		RedactionRules:        opts.withRedaction,
+		redactions:            redactions,
+		ExcludedResponsePaths: opts.withExcludedPaths,
+		RequiredFormat:        opts.withFormat,
+		SchemaVersion:         opts.withSchemaVersion,
+		Headers:               opts.withHeaders,
 	}, nil
 }
 
@@ -569,3 +632,18 @@ func doElideListResponseData(data map[string]interface{}) {
 		}
 	}
 }
+
+// newImpersonation returns the impersonation tag for the given auth, or nil if
+// it isn't for an impersonation token.
+func newImpersonation(auth *logical.Auth) *Impersonation {
+	if auth == nil || auth.Impersonator == nil {
+		return nil
+	}
+
+	return &Impersonation{
+		ActorEntityID:    auth.Impersonator.EntityID,
+		ActorAccessor:    auth.Impersonator.Accessor,
+		ActorDisplayName: auth.Impersonator.DisplayName,
+		SubjectEntityID:  auth.EntityID,
+	}
+}
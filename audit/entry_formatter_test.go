@@ -291,6 +291,27 @@ func TestEntryFormatter_Process(t *testing.T) {
 			Data:            &logical.LogInput{Request: &logical.Request{ID: "123"}},
 			RootNamespace:   true,
 		},
+		"parquet-request-no-data": {
+			IsErrorExpected:      true,
+			ExpectedErrorMessage: "audit.(EntryFormatter).Process: unable to parse request from audit event: request to request-audit a nil request",
+			Subtype:              RequestType,
+			RequiredFormat:       ParquetFormat,
+			Data:                 nil,
+		},
+		"parquet-request-basic-input-and-request-with-ns": {
+			IsErrorExpected: false,
+			Subtype:         RequestType,
+			RequiredFormat:  ParquetFormat,
+			Data:            &logical.LogInput{Request: &logical.Request{ID: "123"}},
+			RootNamespace:   true,
+		},
+		"parquet-response-basic-input-and-request-with-ns": {
+			IsErrorExpected: false,
+			Subtype:         ResponseType,
+			RequiredFormat:  ParquetFormat,
+			Data:            &logical.LogInput{Request: &logical.Request{ID: "123"}},
+			RootNamespace:   true,
+		},
 	}
 
 	for name, tc := range tests {
@@ -5,8 +5,10 @@ package audit
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/vault/helper/namespace"
 	"github.com/hashicorp/vault/sdk/helper/salt"
@@ -283,6 +285,164 @@ func TestEntryFormatter_FormatResponse(t *testing.T) {
 	}
 }
 
+// TestEntryFormatter_FormatResponse_RequestMetrics ensures that request
+// metrics are only included in response entries when configured.
+func TestEntryFormatter_FormatResponse_RequestMetrics(t *testing.T) {
+	tests := map[string]struct {
+		Enabled         bool
+		Metrics         *logical.RequestMetrics
+		ExpectedMetrics *Metrics
+	}{
+		"disabled": {
+			Enabled: false,
+			Metrics: &logical.RequestMetrics{StorageReads: 2, StorageWrites: 1, HandlerDuration: time.Millisecond},
+		},
+		"enabled-no-metrics": {
+			Enabled: true,
+		},
+		"enabled": {
+			Enabled:         true,
+			Metrics:         &logical.RequestMetrics{StorageReads: 2, StorageWrites: 1, HandlerDuration: time.Millisecond},
+			ExpectedMetrics: &Metrics{StorageReads: 2, StorageWrites: 1, HandlerDurationUs: 1000},
+		},
+	}
+
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg, err := NewFormatterConfig(WithRequestMetrics(tc.Enabled))
+			require.NoError(t, err)
+			f, err := NewEntryFormatter(cfg, newStaticSalt(t))
+			require.NoError(t, err)
+
+			entry, err := f.FormatResponse(namespace.RootContext(context.Background()), &logical.LogInput{
+				Request:        &logical.Request{ID: "123"},
+				RequestMetrics: tc.Metrics,
+			})
+			require.NoError(t, err)
+			require.Equal(t, tc.ExpectedMetrics, entry.Metrics)
+		})
+	}
+}
+
+// TestEntryFormatter_Redaction ensures that fields matched by redaction rules
+// are replaced in request and response entries, whatever the format.
+func TestEntryFormatter_Redaction(t *testing.T) {
+	for _, f := range []format{JSONFormat, JSONxFormat} {
+		f := f
+		t.Run(f.String(), func(t *testing.T) {
+			t.Parallel()
+
+			cfg, err := NewFormatterConfig(
+				WithFormat(f.String()),
+				WithRaw(true),
+				WithRedaction([]RedactionRule{{Path: "$.request.data.password"}, {Path: "$..secret_id"}, {Path: "$.request.wrap_ttl"}}),
+			)
+			require.NoError(t, err)
+			formatter, err := NewEntryFormatter(cfg, newStaticSalt(t))
+			require.NoError(t, err)
+
+			in := &logical.LogInput{
+				Request: &logical.Request{
+					ID:       "123",
+					Path:     "auth/userpass/login/bob",
+					Data:     map[string]interface{}{"password": "hunter2", "ttl": 60},
+					WrapInfo: &logical.RequestWrapInfo{TTL: time.Minute},
+				},
+				Response: &logical.Response{
+					Data: map[string]interface{}{"secret_id": "s.123", "secret_id_accessor": "abc"},
+				},
+			}
+			ctx := namespace.RootContext(context.Background())
+
+			reqEntry, err := formatter.FormatRequest(ctx, in)
+			require.NoError(t, err)
+			require.Equal(t, RedactedValue, reqEntry.Request.Data["password"])
+			require.Equal(t, "60", fmt.Sprint(reqEntry.Request.Data["ttl"]))
+			require.Equal(t, "auth/userpass/login/bob", reqEntry.Request.Path)
+			// Fields which cannot hold the redacted value are removed.
+			require.Zero(t, reqEntry.Request.WrapTTL)
+
+			respEntry, err := formatter.FormatResponse(ctx, in)
+			require.NoError(t, err)
+			require.Equal(t, RedactedValue, respEntry.Response.Data["secret_id"])
+			require.Equal(t, "abc", respEntry.Response.Data["secret_id_accessor"])
+
+			e := fakeEvent(t, ResponseType, f, in)
+			processed, err := formatter.Process(ctx, e)
+			require.NoError(t, err)
+			formatted, ok := processed.Format(f.String())
+			require.True(t, ok)
+			require.Contains(t, string(formatted), RedactedValue)
+			require.NotContains(t, string(formatted), "s.123")
+		})
+	}
+}
+
+// TestEntryFormatter_ExcludedResponsePaths ensures that the response data of
+// requests to excluded paths is omitted, whether entries are hashed or raw.
+func TestEntryFormatter_ExcludedResponsePaths(t *testing.T) {
+	tests := map[string]struct {
+		Path     string
+		Raw      bool
+		Excluded bool
+	}{
+		"excluded": {
+			Path:     "transit/export/encryption-key/my-key",
+			Excluded: true,
+		},
+		"excluded-raw": {
+			Path:     "transit/export/encryption-key/my-key",
+			Raw:      true,
+			Excluded: true,
+		},
+		"excluded-suffix": {
+			Path:     "pki/issuer/default/export",
+			Excluded: true,
+		},
+		"not-excluded": {
+			Path: "transit/keys/my-key",
+		},
+	}
+
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg, err := NewFormatterConfig(
+				WithRaw(tc.Raw),
+				WithExcludedResponsePaths("transit/export/*, /pki/*/export"),
+			)
+			require.NoError(t, err)
+			f, err := NewEntryFormatter(cfg, newStaticSalt(t))
+			require.NoError(t, err)
+
+			in := &logical.LogInput{
+				Request: &logical.Request{ID: "123", Path: tc.Path},
+				Response: &logical.Response{
+					Data:     map[string]interface{}{"keys": map[string]interface{}{"1": "secret"}},
+					Warnings: []string{"warning"},
+				},
+			}
+			entry, err := f.FormatResponse(namespace.RootContext(context.Background()), in)
+			require.NoError(t, err)
+			require.Equal(t, []string{"warning"}, entry.Response.Warnings)
+			if tc.Excluded {
+				require.Nil(t, entry.Response.Data)
+			} else {
+				require.NotNil(t, entry.Response.Data)
+			}
+			// The input is left untouched.
+			require.NotNil(t, in.Response.Data)
+		})
+	}
+}
+
 func TestElideListResponses(t *testing.T) {
 	type test struct {
 		name         string
@@ -93,7 +93,7 @@ func (t subtype) validate() error {
 func (f format) validate() error {
 	const op = "audit.(format).validate"
 	switch f {
-	case JSONFormat, JSONxFormat:
+	case JSONFormat, JSONxFormat, ParquetFormat:
 		return nil
 	default:
 		return fmt.Errorf("%s: '%s' is not a valid format: %w", op, f, event.ErrInvalidParameter)
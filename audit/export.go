@@ -0,0 +1,89 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// EntityExporter selects the archived entries involving an entity, and
+// redacts them before they are handed to the requester of the export, such as
+// the subject of a data access request.
+type EntityExporter struct {
+	entityID string
+	paths    []redactionPath
+}
+
+// NewEntityExporter returns an exporter of the entries involving the entity
+// with the given ID, applying the given redaction rules to them.
+func NewEntityExporter(entityID string, rules []RedactionRule) (*EntityExporter, error) {
+	entityID = strings.TrimSpace(entityID)
+	if entityID == "" {
+		return nil, fmt.Errorf("missing entity ID")
+	}
+
+	e := &EntityExporter{
+		entityID: entityID,
+	}
+	for _, rule := range rules {
+		p, err := parseRedactionPath(rule.Path)
+		if err != nil {
+			return nil, err
+		}
+		e.paths = append(e.paths, p)
+	}
+	return e, nil
+}
+
+// Export returns the redacted JSON entry when it involves the entity of the
+// exporter, and nil otherwise.
+func (e *EntityExporter) Export(entry []byte) ([]byte, error) {
+	var doc map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(entry))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("unable to decode entry: %w", err)
+	}
+
+	if !e.involves(doc) {
+		return nil, nil
+	}
+
+	var v interface{} = doc
+	for _, p := range e.paths {
+		v = p.redact(v)
+	}
+
+	exported, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode redacted entry: %w", err)
+	}
+	return exported, nil
+}
+
+// involves reports whether the entry involves the entity: either the request
+// was made with a token of the entity, or a segment of the request path is the
+// ID of the entity, as with requests to identity/entity/id/:id.
+func (e *EntityExporter) involves(doc map[string]interface{}) bool {
+	if auth, ok := doc["auth"].(map[string]interface{}); ok {
+		if auth["entity_id"] == e.entityID {
+			return true
+		}
+	}
+
+	if req, ok := doc["request"].(map[string]interface{}); ok {
+		if path, ok := req["path"].(string); ok {
+			for _, segment := range strings.Split(path, "/") {
+				if segment == e.entityID {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,59 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package audit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestEntityExporter ensures that only the entries involving the entity are
+// exported, with the redaction rules applied to them.
+func TestEntityExporter(t *testing.T) {
+	_, err := NewEntityExporter(" ", nil)
+	require.EqualError(t, err, "missing entity ID")
+
+	_, err = NewEntityExporter("alice", []RedactionRule{{Path: "$"}})
+	require.Error(t, err)
+
+	e, err := NewEntityExporter("alice", []RedactionRule{{Path: "$.request.remote_address"}, {Path: "..client_token"}})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		Entry    string
+		Expected string
+	}{
+		"token-of-entity": {
+			Entry:    `{"type":"request","auth":{"entity_id":"alice","client_token":"hmac-sha256:abc"},"request":{"path":"secret/foo","remote_address":"10.0.0.1","remote_port":4321}}`,
+			Expected: `{"auth":{"client_token":"[redacted]","entity_id":"alice"},"request":{"path":"secret/foo","remote_address":"[redacted]","remote_port":4321},"type":"request"}`,
+		},
+		"path-of-entity": {
+			Entry:    `{"type":"request","auth":{"entity_id":"root"},"request":{"path":"identity/entity/id/alice"}}`,
+			Expected: `{"auth":{"entity_id":"root"},"request":{"path":"identity/entity/id/alice"},"type":"request"}`,
+		},
+		"other-entity": {
+			Entry: `{"type":"request","auth":{"entity_id":"bob"},"request":{"path":"secret/alice-notes"}}`,
+		},
+	}
+
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			exported, err := e.Export([]byte(tc.Entry))
+			require.NoError(t, err)
+			if tc.Expected == "" {
+				require.Nil(t, exported)
+				return
+			}
+			require.JSONEq(t, tc.Expected, string(exported))
+		})
+	}
+
+	_, err = e.Export([]byte("not json"))
+	require.Error(t, err)
+}
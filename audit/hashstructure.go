@@ -37,6 +37,11 @@ func HashAuth(salter *salt.Salt, in *logical.Auth, HMACAccessor bool) (*logical.
 	if HMACAccessor && auth.Accessor != "" {
 		auth.Accessor = fn(auth.Accessor)
 	}
+	if HMACAccessor && auth.Impersonator != nil && auth.Impersonator.Accessor != "" {
+		impersonator := *auth.Impersonator
+		impersonator.Accessor = fn(impersonator.Accessor)
+		auth.Impersonator = &impersonator
+	}
 	return &auth, nil
 }
 
@@ -145,6 +145,20 @@ func TestHashAuth(t *testing.T) {
 			},
 			false,
 		},
+		{
+			&logical.Auth{
+				ClientToken:  "foo",
+				Impersonator: &logical.Impersonator{EntityID: "bar", Accessor: "foo"},
+			},
+			&logical.Auth{
+				ClientToken: "hmac-sha256:08ba357e274f528065766c770a639abf6809b39ccfd37c2a3157c7f51954da0a",
+				Impersonator: &logical.Impersonator{
+					EntityID: "bar",
+					Accessor: "hmac-sha256:08ba357e274f528065766c770a639abf6809b39ccfd37c2a3157c7f51954da0a",
+				},
+			},
+			true,
+		},
 	}
 
 	inmemStorage := &logical.InmemStorage{}
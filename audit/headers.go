@@ -0,0 +1,50 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package audit
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/vault/internal/observability/event"
+)
+
+// ParseHeaders parses a comma separated list of the request headers audited
+// by an audit device, as configured on audit devices. Each header name may be
+// followed by ":hmac" to HMAC its values, or ":plaintext" to log them as they
+// are, which is the default. A nil map is returned when no headers are given,
+// in which case the device audits the headers of the audited headers
+// configuration.
+func ParseHeaders(raw string) (map[string]bool, error) {
+	const op = "audit.ParseHeaders"
+
+	var headers map[string]bool
+	for _, h := range strings.Split(raw, ",") {
+		h = strings.TrimSpace(h)
+		if h == "" {
+			continue
+		}
+
+		name, mode, _ := strings.Cut(h, ":")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("%s: header name cannot be empty in %q: %w", op, h, event.ErrInvalidParameter)
+		}
+
+		var hmac bool
+		switch strings.ToLower(strings.TrimSpace(mode)) {
+		case "", "plaintext":
+		case "hmac":
+			hmac = true
+		default:
+			return nil, fmt.Errorf("%s: unsupported mode %q of header %q, expected \"hmac\" or \"plaintext\": %w", op, mode, name, event.ErrInvalidParameter)
+		}
+
+		if headers == nil {
+			headers = make(map[string]bool)
+		}
+		headers[name] = hmac
+	}
+	return headers, nil
+}
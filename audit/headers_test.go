@@ -0,0 +1,59 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package audit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseHeaders ensures that the headers audited by a device are parsed
+// from a comma separated list, and that invalid modes are rejected.
+func TestParseHeaders(t *testing.T) {
+	tests := map[string]struct {
+		Value                string
+		IsErrorExpected      bool
+		ExpectedErrorMessage string
+		ExpectedHeaders      map[string]bool
+	}{
+		"empty": {
+			Value: " , ",
+		},
+		"plaintext": {
+			Value:           "X-Request-ID, user-agent:plaintext",
+			ExpectedHeaders: map[string]bool{"X-Request-ID": false, "user-agent": false},
+		},
+		"hmac": {
+			Value:           "Authorization:HMAC ,X-Request-ID",
+			ExpectedHeaders: map[string]bool{"Authorization": true, "X-Request-ID": false},
+		},
+		"empty-name": {
+			Value:                ":hmac",
+			IsErrorExpected:      true,
+			ExpectedErrorMessage: `audit.ParseHeaders: header name cannot be empty in ":hmac": invalid parameter`,
+		},
+		"unsupported-mode": {
+			Value:                "Authorization:hash",
+			IsErrorExpected:      true,
+			ExpectedErrorMessage: `audit.ParseHeaders: unsupported mode "hash" of header "Authorization", expected "hmac" or "plaintext": invalid parameter`,
+		},
+	}
+
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			headers, err := ParseHeaders(tc.Value)
+			switch {
+			case tc.IsErrorExpected:
+				require.EqualError(t, err, tc.ExpectedErrorMessage)
+			default:
+				require.NoError(t, err)
+				require.Equal(t, tc.ExpectedHeaders, headers)
+			}
+		})
+	}
+}
@@ -12,8 +12,9 @@ import (
 // getDefaultOptions returns options with their default values.
 func getDefaultOptions() options {
 	return options{
-		withNow:    time.Now(),
-		withFormat: JSONFormat,
+		withNow:           time.Now(),
+		withFormat:        JSONFormat,
+		withSchemaVersion: SchemaV1,
 	}
 }
 
@@ -141,6 +142,69 @@ func WithOmitTime(t bool) Option {
 	}
 }
 
+// WithRequestMetrics provides an Option to represent whether request metrics
+// (storage operation counts and backend handler duration) are included in
+// response entries.
+func WithRequestMetrics(m bool) Option {
+	return func(o *options) error {
+		o.withRequestMetrics = m
+		return nil
+	}
+}
+
+// WithRedaction provides an Option to represent the rules of fields to redact
+// from entries.
+func WithRedaction(rules []RedactionRule) Option {
+	return func(o *options) error {
+		for _, rule := range rules {
+			if _, err := parseRedactionPath(rule.Path); err != nil {
+				return err
+			}
+		}
+
+		o.withRedaction = rules
+		return nil
+	}
+}
+
+// WithExcludedResponsePaths provides an Option to represent the glob patterns,
+// as a comma separated list, of the request paths whose response data is
+// omitted from entries.
+func WithExcludedResponsePaths(patterns string) Option {
+	return func(o *options) error {
+		var paths []string
+		for _, p := range strings.Split(patterns, ",") {
+			if p = strings.TrimPrefix(strings.TrimSpace(p), "/"); p != "" {
+				paths = append(paths, p)
+			}
+		}
+
+		o.withExcludedPaths = paths
+		return nil
+	}
+}
+
+// WithSchemaVersion provides an Option to represent the schema version of
+// entries.
+func WithSchemaVersion(v string) Option {
+	return func(o *options) error {
+		v := strings.TrimSpace(v)
+		if v == "" {
+			// Return early, we won't attempt to apply this option if its empty.
+			return nil
+		}
+
+		parsed := schemaVersion(v)
+		err := parsed.validate()
+		if err != nil {
+			return err
+		}
+
+		o.withSchemaVersion = parsed
+		return nil
+	}
+}
+
 // WithHMACAccessor provides an Option to represent whether an HMAC accessor is applicable.
 func WithHMACAccessor(h bool) Option {
 	return func(o *options) error {
@@ -148,3 +212,28 @@ func WithHMACAccessor(h bool) Option {
 		return nil
 	}
 }
+
+// WithHeaders provides an Option to represent the request headers audited by
+// a device instead of those of the audited headers configuration, and whether
+// their values are HMAC-ed. Header names are matched case-insensitively, so
+// they are normalized to lower case.
+func WithHeaders(headers map[string]bool) Option {
+	return func(o *options) error {
+		if headers == nil {
+			o.withHeaders = nil
+			return nil
+		}
+
+		normalized := make(map[string]bool, len(headers))
+		for name, hmac := range headers {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" {
+				return errors.New("header name cannot be empty")
+			}
+			normalized[name] = normalized[name] || hmac
+		}
+
+		o.withHeaders = normalized
+		return nil
+	}
+}
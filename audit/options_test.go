@@ -43,6 +43,11 @@ func TestOptions_WithFormat(t *testing.T) {
 			IsErrorExpected: false,
 			ExpectedValue:   JSONxFormat,
 		},
+		"valid-parquet": {
+			Value:           "parquet",
+			IsErrorExpected: false,
+			ExpectedValue:   ParquetFormat,
+		},
 	}
 
 	for name, tc := range tests {
@@ -331,7 +336,213 @@ func TestOptions_WithHMACAccessor(t *testing.T) {
 	}
 }
 
+// TestOptions_WithRequestMetrics exercises WithRequestMetrics Option to ensure it performs as expected.
+func TestOptions_WithRequestMetrics(t *testing.T) {
+	tests := map[string]struct {
+		Value         bool
+		ExpectedValue bool
+	}{
+		"true": {
+			Value:         true,
+			ExpectedValue: true,
+		},
+		"false": {
+			Value:         false,
+			ExpectedValue: false,
+		},
+	}
+
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			options := &options{}
+			applyOption := WithRequestMetrics(tc.Value)
+			err := applyOption(options)
+			require.NoError(t, err)
+			require.Equal(t, tc.ExpectedValue, options.withRequestMetrics)
+		})
+	}
+}
+
 // TestOptions_WithOmitTime exercises WithOmitTime Option to ensure it performs as expected.
+// TestOptions_WithRedaction exercises WithRedaction Option to ensure it performs as expected.
+func TestOptions_WithRedaction(t *testing.T) {
+	tests := map[string]struct {
+		Value                []RedactionRule
+		IsErrorExpected      bool
+		ExpectedErrorMessage string
+	}{
+		"none": {
+			Value: nil,
+		},
+		"valid": {
+			Value: []RedactionRule{{Path: "$.request.data.password"}, {Path: "$..secret_id"}},
+		},
+		"invalid": {
+			Value:                []RedactionRule{{Path: "$.request.data.password"}, {Path: "request"}},
+			IsErrorExpected:      true,
+			ExpectedErrorMessage: `invalid redaction path "request": unexpected "request"`,
+		},
+	}
+
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			options := &options{}
+			applyOption := WithRedaction(tc.Value)
+			err := applyOption(options)
+			switch {
+			case tc.IsErrorExpected:
+				require.EqualError(t, err, tc.ExpectedErrorMessage)
+			default:
+				require.NoError(t, err)
+				require.Equal(t, tc.Value, options.withRedaction)
+			}
+		})
+	}
+}
+
+// TestOptions_WithExcludedResponsePaths exercises WithExcludedResponsePaths Option to ensure it performs as expected.
+func TestOptions_WithExcludedResponsePaths(t *testing.T) {
+	tests := map[string]struct {
+		Value         string
+		ExpectedValue []string
+	}{
+		"empty": {
+			Value: "",
+		},
+		"whitespace": {
+			Value: "  , ",
+		},
+		"valid": {
+			Value:         " transit/export/*,/pki/*/export ",
+			ExpectedValue: []string{"transit/export/*", "pki/*/export"},
+		},
+	}
+
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			options := &options{}
+			applyOption := WithExcludedResponsePaths(tc.Value)
+			err := applyOption(options)
+			require.NoError(t, err)
+			require.Equal(t, tc.ExpectedValue, options.withExcludedPaths)
+		})
+	}
+}
+
+// TestOptions_WithHeaders exercises WithHeaders Option to ensure it performs as expected.
+func TestOptions_WithHeaders(t *testing.T) {
+	tests := map[string]struct {
+		Value                map[string]bool
+		IsErrorExpected      bool
+		ExpectedErrorMessage string
+		ExpectedValue        map[string]bool
+	}{
+		"nil": {
+			Value: nil,
+		},
+		"empty": {
+			Value:         map[string]bool{},
+			ExpectedValue: map[string]bool{},
+		},
+		"empty-name": {
+			Value:                map[string]bool{" ": true},
+			IsErrorExpected:      true,
+			ExpectedErrorMessage: "header name cannot be empty",
+		},
+		"normalized": {
+			Value:         map[string]bool{" X-Request-ID ": false, "Authorization": true},
+			ExpectedValue: map[string]bool{"x-request-id": false, "authorization": true},
+		},
+		"duplicates-hmac": {
+			Value:         map[string]bool{"X-Secret": false, "x-secret": true},
+			ExpectedValue: map[string]bool{"x-secret": true},
+		},
+	}
+
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			options := &options{}
+			applyOption := WithHeaders(tc.Value)
+			err := applyOption(options)
+			switch {
+			case tc.IsErrorExpected:
+				require.Error(t, err)
+				require.EqualError(t, err, tc.ExpectedErrorMessage)
+			default:
+				require.NoError(t, err)
+				require.Equal(t, tc.ExpectedValue, options.withHeaders)
+			}
+		})
+	}
+}
+
+// TestOptions_WithSchemaVersion exercises WithSchemaVersion Option to ensure it performs as expected.
+func TestOptions_WithSchemaVersion(t *testing.T) {
+	tests := map[string]struct {
+		Value                string
+		IsErrorExpected      bool
+		ExpectedErrorMessage string
+		ExpectedValue        schemaVersion
+	}{
+		"empty": {
+			Value:           "",
+			IsErrorExpected: false,
+			ExpectedValue:   schemaVersion(""),
+		},
+		"whitespace": {
+			Value:           "     ",
+			IsErrorExpected: false,
+			ExpectedValue:   schemaVersion(""),
+		},
+		"invalid-test": {
+			Value:                "v3",
+			IsErrorExpected:      true,
+			ExpectedErrorMessage: "audit.(schemaVersion).validate: 'v3' is not a valid schema version: invalid parameter",
+		},
+		"valid-v1": {
+			Value:           "v1",
+			IsErrorExpected: false,
+			ExpectedValue:   SchemaV1,
+		},
+		"valid-v2": {
+			Value:           " v2 ",
+			IsErrorExpected: false,
+			ExpectedValue:   SchemaV2,
+		},
+	}
+
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			options := &options{}
+			applyOption := WithSchemaVersion(tc.Value)
+			err := applyOption(options)
+			switch {
+			case tc.IsErrorExpected:
+				require.Error(t, err)
+				require.EqualError(t, err, tc.ExpectedErrorMessage)
+			default:
+				require.NoError(t, err)
+				require.Equal(t, tc.ExpectedValue, options.withSchemaVersion)
+			}
+		})
+	}
+}
+
 func TestOptions_WithOmitTime(t *testing.T) {
 	tests := map[string]struct {
 		Value         bool
@@ -367,6 +578,7 @@ func TestOptions_Default(t *testing.T) {
 	require.NotNil(t, opts)
 	require.True(t, time.Now().After(opts.withNow))
 	require.False(t, opts.withNow.IsZero())
+	require.Equal(t, SchemaV1, opts.withSchemaVersion)
 }
 
 // TestOptions_Opts exercises GetOpts with various Option values.
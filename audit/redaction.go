@@ -0,0 +1,240 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// RedactedValue is the token which replaces the fields matched by redaction
+// rules.
+const RedactedValue = "[redacted]"
+
+// RedactionRule declares fields of audit entries which are replaced by
+// RedactedValue before the entries are formatted.
+type RedactionRule struct {
+	// Path is a JSONPath-style expression selecting fields of the entry as
+	// it is encoded in JSON, e.g. "$.request.data.password". It supports
+	// child names (".name" or "['name']"), array indexes ("[0]"), wildcards
+	// (".*" or "[*]") and recursive descent ("..name").
+	Path string
+}
+
+// ParseRedactionRules parses a comma separated list of redaction paths, as
+// configured on audit devices.
+func ParseRedactionRules(raw string) ([]RedactionRule, error) {
+	var rules []RedactionRule
+	for _, p := range strings.Split(raw, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if _, err := parseRedactionPath(p); err != nil {
+			return nil, err
+		}
+		rules = append(rules, RedactionRule{Path: p})
+	}
+	return rules, nil
+}
+
+// redactionStepKind is the kind of a step of a redaction path.
+type redactionStepKind int
+
+const (
+	redactionStepName redactionStepKind = iota
+	redactionStepIndex
+	redactionStepWildcard
+)
+
+// redactionStep is a single step of a redaction path, selecting children of
+// the current node. Recursive steps select descendants at any depth.
+type redactionStep struct {
+	kind      redactionStepKind
+	name      string
+	index     int
+	recursive bool
+}
+
+// redactionPath is a parsed RedactionRule path.
+type redactionPath []redactionStep
+
+// parseRedactionPath parses a JSONPath-style expression into its steps.
+func parseRedactionPath(path string) (redactionPath, error) {
+	invalid := func(reason string) (redactionPath, error) {
+		return nil, fmt.Errorf("invalid redaction path %q: %s", path, reason)
+	}
+
+	rest := strings.TrimPrefix(strings.TrimSpace(path), "$")
+	var steps redactionPath
+	for rest != "" {
+		var step redactionStep
+
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			step.recursive = true
+			rest = rest[2:]
+			if strings.HasPrefix(rest, "[") {
+				break
+			}
+			fallthrough
+		case strings.HasPrefix(rest, "."):
+			rest = strings.TrimPrefix(rest, ".")
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			rest = rest[end:]
+			switch name {
+			case "":
+				return invalid("empty name")
+			case "*":
+				step.kind = redactionStepWildcard
+			default:
+				step.name = name
+			}
+			steps = append(steps, step)
+			continue
+		case !strings.HasPrefix(rest, "["):
+			return invalid(fmt.Sprintf("unexpected %q", rest))
+		}
+
+		end := strings.Index(rest, "]")
+		if end == -1 {
+			return invalid("unterminated bracket")
+		}
+		selector := rest[1:end]
+		rest = rest[end+1:]
+		switch {
+		case selector == "*":
+			step.kind = redactionStepWildcard
+		case len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0]:
+			step.name = selector[1 : len(selector)-1]
+		default:
+			index, err := strconv.Atoi(selector)
+			if err != nil || index < 0 {
+				return invalid(fmt.Sprintf("unexpected selector %q", selector))
+			}
+			step.kind = redactionStepIndex
+			step.index = index
+		}
+		steps = append(steps, step)
+	}
+
+	if len(steps) == 0 {
+		return invalid("the root cannot be redacted")
+	}
+	return steps, nil
+}
+
+// redact replaces the values matched by the steps within v, returning the
+// resulting value.
+func (p redactionPath) redact(v interface{}) interface{} {
+	if len(p) == 0 {
+		return RedactedValue
+	}
+
+	step := p[0]
+	if step.recursive {
+		// Match the step at this level, then at every level below.
+		current := step
+		current.recursive = false
+		v = append(redactionPath{current}, p[1:]...).redact(v)
+		return forEachChild(v, func(child interface{}) interface{} {
+			return p.redact(child)
+		})
+	}
+
+	switch node := v.(type) {
+	case map[string]interface{}:
+		switch step.kind {
+		case redactionStepName:
+			if child, ok := node[step.name]; ok {
+				node[step.name] = p[1:].redact(child)
+			}
+		case redactionStepWildcard:
+			for key, child := range node {
+				node[key] = p[1:].redact(child)
+			}
+		}
+	case []interface{}:
+		switch step.kind {
+		case redactionStepIndex:
+			if step.index < len(node) {
+				node[step.index] = p[1:].redact(node[step.index])
+			}
+		case redactionStepWildcard:
+			for i, child := range node {
+				node[i] = p[1:].redact(child)
+			}
+		}
+	}
+	return v
+}
+
+// forEachChild replaces each child of the object or array v by the result of
+// fn.
+func forEachChild(v interface{}, fn func(interface{}) interface{}) interface{} {
+	switch node := v.(type) {
+	case map[string]interface{}:
+		for key, child := range node {
+			node[key] = fn(child)
+		}
+	case []interface{}:
+		for i, child := range node {
+			node[i] = fn(child)
+		}
+	}
+	return v
+}
+
+// redactEntry applies the redaction paths to the entry, which must be a
+// pointer to a RequestEntry or ResponseEntry. The entry is redacted through
+// its JSON encoding so that paths match the formatted output; fields which
+// cannot hold RedactedValue, such as numbers, are removed instead.
+func redactEntry(entry interface{}, paths []redactionPath) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	encoded, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("unable to encode entry for redaction: %w", err)
+	}
+
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(encoded))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return fmt.Errorf("unable to decode entry for redaction: %w", err)
+	}
+
+	for _, p := range paths {
+		doc = p.redact(doc)
+	}
+
+	encoded, err = json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("unable to encode redacted entry: %w", err)
+	}
+
+	target := reflect.ValueOf(entry).Elem()
+	target.Set(reflect.Zero(target.Type()))
+	dec = json.NewDecoder(bytes.NewReader(encoded))
+	dec.UseNumber()
+	if err := dec.Decode(entry); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			return fmt.Errorf("unable to decode redacted entry: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package audit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseRedactionRules ensures that redaction rules are parsed from comma
+// separated paths, and that invalid paths are rejected.
+func TestParseRedactionRules(t *testing.T) {
+	tests := map[string]struct {
+		Value                string
+		IsErrorExpected      bool
+		ExpectedErrorMessage string
+		ExpectedRules        []RedactionRule
+	}{
+		"empty": {
+			Value: "  ",
+		},
+		"single": {
+			Value:         "$.request.data.password",
+			ExpectedRules: []RedactionRule{{Path: "$.request.data.password"}},
+		},
+		"multiple": {
+			Value:         " $.request.data.password, ..secret_id ,",
+			ExpectedRules: []RedactionRule{{Path: "$.request.data.password"}, {Path: "..secret_id"}},
+		},
+		"root": {
+			Value:                "$",
+			IsErrorExpected:      true,
+			ExpectedErrorMessage: `invalid redaction path "$": the root cannot be redacted`,
+		},
+		"unterminated-bracket": {
+			Value:                "$.request.data['password",
+			IsErrorExpected:      true,
+			ExpectedErrorMessage: `invalid redaction path "$.request.data['password": unterminated bracket`,
+		},
+		"bad-selector": {
+			Value:                "$.response.data.keys[-1]",
+			IsErrorExpected:      true,
+			ExpectedErrorMessage: `invalid redaction path "$.response.data.keys[-1]": unexpected selector "-1"`,
+		},
+		"empty-name": {
+			Value:                "$.request..",
+			IsErrorExpected:      true,
+			ExpectedErrorMessage: `invalid redaction path "$.request..": empty name`,
+		},
+	}
+
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			rules, err := ParseRedactionRules(tc.Value)
+			switch {
+			case tc.IsErrorExpected:
+				require.EqualError(t, err, tc.ExpectedErrorMessage)
+			default:
+				require.NoError(t, err)
+				require.Equal(t, tc.ExpectedRules, rules)
+			}
+		})
+	}
+}
+
+// TestRedactionPath_redact ensures that redaction paths replace the fields
+// they match, and only those.
+func TestRedactionPath_redact(t *testing.T) {
+	newDoc := func() interface{} {
+		return map[string]interface{}{
+			"request": map[string]interface{}{
+				"path": "auth/approle/login",
+				"data": map[string]interface{}{
+					"role_id":   "role",
+					"secret_id": "secret",
+					"nested":    map[string]interface{}{"secret_id": "nested-secret"},
+				},
+			},
+			"response": map[string]interface{}{
+				"data": map[string]interface{}{
+					"keys":          []interface{}{"a", "b", "c"},
+					"key.with.dots": "dots",
+				},
+			},
+		}
+	}
+	get := func(doc interface{}, keys ...interface{}) interface{} {
+		for _, key := range keys {
+			switch k := key.(type) {
+			case string:
+				doc = doc.(map[string]interface{})[k]
+			case int:
+				doc = doc.([]interface{})[k]
+			}
+		}
+		return doc
+	}
+
+	tests := map[string]struct {
+		Path       string
+		Redacted   [][]interface{}
+		Unredacted [][]interface{}
+	}{
+		"child": {
+			Path:       "$.request.data.secret_id",
+			Redacted:   [][]interface{}{{"request", "data", "secret_id"}},
+			Unredacted: [][]interface{}{{"request", "data", "role_id"}, {"request", "data", "nested", "secret_id"}},
+		},
+		"bracket-name": {
+			Path:     "$.response.data['key.with.dots']",
+			Redacted: [][]interface{}{{"response", "data", "key.with.dots"}},
+		},
+		"index": {
+			Path:       "$.response.data.keys[1]",
+			Redacted:   [][]interface{}{{"response", "data", "keys", 1}},
+			Unredacted: [][]interface{}{{"response", "data", "keys", 0}, {"response", "data", "keys", 2}},
+		},
+		"wildcard": {
+			Path:       "$.request.data.*",
+			Redacted:   [][]interface{}{{"request", "data", "role_id"}, {"request", "data", "secret_id"}, {"request", "data", "nested"}},
+			Unredacted: [][]interface{}{{"request", "path"}},
+		},
+		"recursive": {
+			Path:       "$..secret_id",
+			Redacted:   [][]interface{}{{"request", "data", "secret_id"}, {"request", "data", "nested", "secret_id"}},
+			Unredacted: [][]interface{}{{"request", "data", "role_id"}},
+		},
+		"recursive-wildcard-index": {
+			Path:     "..keys[*]",
+			Redacted: [][]interface{}{{"response", "data", "keys", 0}, {"response", "data", "keys", 2}},
+		},
+		"no-match": {
+			Path:       "$.request.data.password",
+			Unredacted: [][]interface{}{{"request", "data", "secret_id"}},
+		},
+	}
+
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := parseRedactionPath(tc.Path)
+			require.NoError(t, err)
+
+			doc := p.redact(newDoc())
+			for _, keys := range tc.Redacted {
+				require.Equal(t, RedactedValue, get(doc, keys...), keys)
+			}
+			for _, keys := range tc.Unredacted {
+				require.NotEqual(t, RedactedValue, get(doc, keys...), keys)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package audit
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/vault/internal/observability/event"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+var _ eventlogger.Node = (*EntrySampler)(nil)
+
+// defaultSampledOperations are the operations sampled when none are
+// configured.
+var defaultSampledOperations = []logical.Operation{logical.ReadOperation}
+
+// EntrySampler is a filter node which keeps a statistical sample of the
+// entries of the configured operations and paths, while keeping every other
+// entry. Entries of requests to auth mounts, and entries of requests issuing
+// a token, are always kept.
+//
+// Whether an entry is kept depends on the ID of its request only, so the
+// request and response entries of a request are either both kept or both
+// dropped, and every device sampling at the same rate keeps the same entries.
+type EntrySampler struct {
+	rate         float64
+	operations   []logical.Operation
+	pathPrefixes []string
+}
+
+// NewEntrySampler should be used to create an EntrySampler from the
+// configuration of an audit device: the rate is the fraction of the entries
+// to keep, in (0, 1], and the operations and path prefixes select the entries
+// which are sampled, as comma separated lists. When no operations are given,
+// read entries are sampled; when no path prefixes are given, the entries of
+// any path are. A nil sampler is returned when no rate is given, or when it
+// is 1, as every entry is kept then.
+func NewEntrySampler(rate, operations, pathPrefixes string) (*EntrySampler, error) {
+	const op = "audit.NewEntrySampler"
+
+	rate = strings.TrimSpace(rate)
+	if rate == "" {
+		if strings.TrimSpace(operations) != "" || strings.TrimSpace(pathPrefixes) != "" {
+			return nil, fmt.Errorf("%s: sample_rate is required to sample entries: %w", op, event.ErrInvalidParameter)
+		}
+		return nil, nil
+	}
+
+	parsedRate, err := strconv.ParseFloat(rate, 64)
+	if err != nil || math.IsNaN(parsedRate) || parsedRate <= 0 || parsedRate > 1 {
+		return nil, fmt.Errorf("%s: sample_rate must be greater than 0 and at most 1: %w", op, event.ErrInvalidParameter)
+	}
+	if parsedRate == 1 {
+		return nil, nil
+	}
+
+	s := &EntrySampler{rate: parsedRate}
+
+	for _, o := range strings.Split(operations, ",") {
+		o = strings.ToLower(strings.TrimSpace(o))
+		switch logical.Operation(o) {
+		case "":
+			continue
+		case logical.ReadOperation, logical.ListOperation, logical.CreateOperation, logical.UpdateOperation,
+			logical.PatchOperation, logical.DeleteOperation, logical.HelpOperation:
+			s.operations = append(s.operations, logical.Operation(o))
+		default:
+			return nil, fmt.Errorf("%s: unsupported operation %q in sample_operations: %w", op, o, event.ErrInvalidParameter)
+		}
+	}
+	if len(s.operations) == 0 {
+		s.operations = defaultSampledOperations
+	}
+
+	for _, p := range strings.Split(pathPrefixes, ",") {
+		if p = strings.TrimPrefix(strings.TrimSpace(p), "/"); p != "" {
+			s.pathPrefixes = append(s.pathPrefixes, p)
+		}
+	}
+
+	return s, nil
+}
+
+// Keep returns whether the entry of the input is kept.
+func (s *EntrySampler) Keep(in *logical.LogInput) bool {
+	if s == nil || in == nil || in.Request == nil {
+		return true
+	}
+	req := in.Request
+
+	// Test messages and requests lacking an ID can't be sampled consistently.
+	if req.ID == "" || !s.sampled(req) {
+		return true
+	}
+
+	// Authentication is always kept.
+	if strings.HasPrefix(req.Path, "auth/") || (in.Response != nil && in.Response.Auth != nil) {
+		return true
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(req.ID))
+	return float64(h.Sum64())/math.MaxUint64 < s.rate
+}
+
+// sampled returns whether the request is of the sampled operations and paths.
+func (s *EntrySampler) sampled(req *logical.Request) bool {
+	var matched bool
+	for _, o := range s.operations {
+		if req.Operation == o {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return false
+	}
+
+	if len(s.pathPrefixes) == 0 {
+		return true
+	}
+	for _, p := range s.pathPrefixes {
+		if strings.HasPrefix(req.Path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Process drops the audit events which aren't kept, before they are
+// formatted.
+func (s *EntrySampler) Process(ctx context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
+	const op = "audit.(EntrySampler).Process"
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	if e == nil {
+		return nil, fmt.Errorf("%s: event is nil: %w", op, event.ErrInvalidParameter)
+	}
+
+	a, ok := e.Payload.(*auditEvent)
+	if !ok {
+		return nil, fmt.Errorf("%s: cannot parse event payload: %w", op, event.ErrInvalidParameter)
+	}
+
+	if !s.Keep(a.Data) {
+		// return nil for the event to indicate the pipeline is complete.
+		return nil, nil
+	}
+	return e, nil
+}
+
+// Reopen is a no-op for the sampler node.
+func (_ *EntrySampler) Reopen() error {
+	return nil
+}
+
+// Type describes the type of this node (filter).
+func (_ *EntrySampler) Type() eventlogger.NodeType {
+	return eventlogger.NodeTypeFilter
+}
@@ -0,0 +1,174 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package audit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewEntrySampler ensures that samplers are created from the configuration
+// of audit devices, and that invalid configuration is rejected.
+func TestNewEntrySampler(t *testing.T) {
+	tests := map[string]struct {
+		Rate                 string
+		Operations           string
+		Paths                string
+		IsNilExpected        bool
+		IsErrorExpected      bool
+		ExpectedErrorMessage string
+		ExpectedSampler      *EntrySampler
+	}{
+		"unconfigured": {
+			IsNilExpected: true,
+		},
+		"full-rate": {
+			Rate:          "1",
+			Operations:    "read",
+			IsNilExpected: true,
+		},
+		"defaults": {
+			Rate: "0.1",
+			ExpectedSampler: &EntrySampler{
+				rate:       0.1,
+				operations: []logical.Operation{logical.ReadOperation},
+			},
+		},
+		"scoped": {
+			Rate:       " 0.25 ",
+			Operations: "Read, list",
+			Paths:      "/secret/data/, kv/ ,",
+			ExpectedSampler: &EntrySampler{
+				rate:         0.25,
+				operations:   []logical.Operation{logical.ReadOperation, logical.ListOperation},
+				pathPrefixes: []string{"secret/data/", "kv/"},
+			},
+		},
+		"missing-rate": {
+			Paths:                "secret/",
+			IsErrorExpected:      true,
+			ExpectedErrorMessage: "audit.NewEntrySampler: sample_rate is required to sample entries: invalid parameter",
+		},
+		"zero-rate": {
+			Rate:                 "0",
+			IsErrorExpected:      true,
+			ExpectedErrorMessage: "audit.NewEntrySampler: sample_rate must be greater than 0 and at most 1: invalid parameter",
+		},
+		"invalid-rate": {
+			Rate:                 "10%",
+			IsErrorExpected:      true,
+			ExpectedErrorMessage: "audit.NewEntrySampler: sample_rate must be greater than 0 and at most 1: invalid parameter",
+		},
+		"unsupported-operation": {
+			Rate:                 "0.5",
+			Operations:           "read,renew",
+			IsErrorExpected:      true,
+			ExpectedErrorMessage: `audit.NewEntrySampler: unsupported operation "renew" in sample_operations: invalid parameter`,
+		},
+	}
+
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := NewEntrySampler(tc.Rate, tc.Operations, tc.Paths)
+			switch {
+			case tc.IsErrorExpected:
+				require.EqualError(t, err, tc.ExpectedErrorMessage)
+				require.Nil(t, s)
+			case tc.IsNilExpected:
+				require.NoError(t, err)
+				require.Nil(t, s)
+			default:
+				require.NoError(t, err)
+				require.Equal(t, tc.ExpectedSampler, s)
+			}
+		})
+	}
+}
+
+// TestEntrySampler_Keep ensures that only a sample of the entries of the
+// configured operations and paths is kept, consistently for a request.
+func TestEntrySampler_Keep(t *testing.T) {
+	s, err := NewEntrySampler("0.1", "read", "secret/")
+	require.NoError(t, err)
+
+	input := func(id string, op logical.Operation, path string) *logical.LogInput {
+		return &logical.LogInput{
+			Request: &logical.Request{
+				ID:        id,
+				Operation: op,
+				Path:      path,
+			},
+		}
+	}
+
+	var kept int
+	for i := 0; i < 10000; i++ {
+		id := fmt.Sprintf("request-%d", i)
+		in := input(id, logical.ReadOperation, "secret/data/foo")
+		keep := s.Keep(in)
+		if keep {
+			kept++
+		}
+
+		// The response entry of the request shares the fate of its request
+		// entry.
+		in.Response = &logical.Response{Data: map[string]interface{}{"foo": "bar"}}
+		require.Equal(t, keep, s.Keep(in))
+
+		require.True(t, s.Keep(input(id, logical.UpdateOperation, "secret/data/foo")), "write was sampled")
+		require.True(t, s.Keep(input(id, logical.ReadOperation, "kv/foo")), "read of another path was sampled")
+	}
+	require.InDelta(t, 1000, kept, 150)
+
+	require.True(t, s.Keep(input("", logical.ReadOperation, "secret/data/foo")), "request without an ID was sampled")
+	require.True(t, (*EntrySampler)(nil).Keep(input("request-1", logical.ReadOperation, "secret/data/foo")))
+
+	// Authentication is kept, wherever it happens.
+	s, err = NewEntrySampler("0.000001", "read,update", "")
+	require.NoError(t, err)
+	require.True(t, s.Keep(input("request-1", logical.UpdateOperation, "auth/userpass/login/alice")))
+	login := input("request-1", logical.UpdateOperation, "sys/wrapping/unwrap")
+	login.Response = &logical.Response{Auth: &logical.Auth{}}
+	require.True(t, s.Keep(login))
+	require.False(t, s.Keep(input("request-1", logical.UpdateOperation, "sys/wrapping/unwrap")))
+}
+
+// TestEntrySampler_Process ensures that the sampler filters audit events.
+func TestEntrySampler_Process(t *testing.T) {
+	s, err := NewEntrySampler("0.000001", "", "")
+	require.NoError(t, err)
+	require.Equal(t, eventlogger.NodeTypeFilter, s.Type())
+
+	for op, expectKept := range map[logical.Operation]bool{
+		logical.ReadOperation:   false,
+		logical.CreateOperation: true,
+	} {
+		a, err := newEvent(RequestType, JSONFormat)
+		require.NoError(t, err)
+		a.Data = &logical.LogInput{
+			Request: &logical.Request{ID: "request-1", Operation: op, Path: "secret/foo"},
+		}
+		e := &eventlogger.Event{Type: eventlogger.EventType("audit"), Payload: a}
+
+		processed, err := s.Process(context.Background(), e)
+		require.NoError(t, err)
+		if expectKept {
+			require.Equal(t, e, processed)
+		} else {
+			require.Nil(t, processed)
+		}
+	}
+
+	_, err = s.Process(context.Background(), &eventlogger.Event{Payload: "foo"})
+	require.ErrorContains(t, err, "cannot parse event payload")
+}
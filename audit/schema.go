@@ -0,0 +1,160 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/vault/internal/observability/event"
+)
+
+// Audit entry schema versions.
+const (
+	// SchemaV1 is the original schema of audit entries, used unless a device
+	// selects another version.
+	SchemaV1 schemaVersion = "v1"
+	// SchemaV2 renames fields whose names don't state their unit, nests the
+	// remote address and port, always includes boolean fields and identifies
+	// its entries with a "schema_version" field.
+	SchemaV2 schemaVersion = "v2"
+)
+
+// schemaVersion defines the schema version of audit entries.
+type schemaVersion string
+
+// validate ensures that the schema version is one of the supported versions.
+func (v schemaVersion) validate() error {
+	const op = "audit.(schemaVersion).validate"
+	switch v {
+	case SchemaV1, SchemaV2:
+		return nil
+	default:
+		return fmt.Errorf("%s: '%s' is not a valid schema version: %w", op, v, event.ErrInvalidParameter)
+	}
+}
+
+// String returns the string version of a schema version.
+func (v schemaVersion) String() string {
+	return string(v)
+}
+
+// schemaV2Renames maps the names of fields of v1 objects to their v2 names.
+var schemaV2Renames = map[string]string{
+	"time":                         "timestamp",
+	"wrap_ttl":                     "wrap_ttl_seconds",
+	"token_ttl":                    "token_ttl_seconds",
+	"mount_running_plugin_version": "mount_running_version",
+	"ttl":                          "ttl_seconds",
+}
+
+// schemaV2Booleans lists, per object, the boolean fields which v2 entries
+// always include, so that false can be told apart from a missing field.
+var schemaV2Booleans = map[string][]string{
+	"entry":    {"forwarded"},
+	"request":  {"policy_override", "mount_is_external_plugin"},
+	"response": {"mount_is_external_plugin"},
+	"auth":     {"no_default_policy", "entity_created"},
+}
+
+// RequestEntry is encoded according to its schema version.
+func (e RequestEntry) MarshalJSON() ([]byte, error) {
+	type requestEntry RequestEntry
+	return marshalEntry(requestEntry(e), e.schemaVersion, false)
+}
+
+// ResponseEntry is encoded according to its schema version.
+func (e ResponseEntry) MarshalJSON() ([]byte, error) {
+	type responseEntry ResponseEntry
+	return marshalEntry(responseEntry(e), e.schemaVersion, true)
+}
+
+// marshalEntry encodes the v1 entry, then converts it to the schema version.
+func marshalEntry(entry interface{}, version schemaVersion, response bool) ([]byte, error) {
+	encoded, err := json.Marshal(entry)
+	if err != nil || version != SchemaV2 {
+		return encoded, err
+	}
+
+	var doc map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(encoded))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("unable to decode entry for schema conversion: %w", err)
+	}
+
+	convertEntryV2(doc, response)
+
+	return json.Marshal(doc)
+}
+
+// convertEntryV2 converts the decoded v1 entry to v2 in place.
+func convertEntryV2(doc map[string]interface{}, response bool) {
+	if response {
+		setBooleans(doc, schemaV2Booleans["entry"])
+	}
+	renameFields(doc)
+	doc["schema_version"] = SchemaV2.String()
+
+	if auth, ok := doc["auth"].(map[string]interface{}); ok {
+		convertAuthV2(auth)
+	}
+
+	if req, ok := doc["request"].(map[string]interface{}); ok {
+		setBooleans(req, schemaV2Booleans["request"])
+		renameFields(req)
+
+		remote := make(map[string]interface{})
+		if addr, ok := req["remote_address"]; ok {
+			remote["address"] = addr
+			delete(req, "remote_address")
+		}
+		if port, ok := req["remote_port"]; ok {
+			remote["port"] = port
+			delete(req, "remote_port")
+		}
+		if len(remote) > 0 {
+			req["remote"] = remote
+		}
+	}
+
+	if resp, ok := doc["response"].(map[string]interface{}); ok {
+		setBooleans(resp, schemaV2Booleans["response"])
+		renameFields(resp)
+		if auth, ok := resp["auth"].(map[string]interface{}); ok {
+			convertAuthV2(auth)
+		}
+		if wrapInfo, ok := resp["wrap_info"].(map[string]interface{}); ok {
+			renameFields(wrapInfo)
+		}
+	}
+}
+
+// convertAuthV2 converts a decoded v1 auth object to v2 in place.
+func convertAuthV2(auth map[string]interface{}) {
+	setBooleans(auth, schemaV2Booleans["auth"])
+	renameFields(auth)
+}
+
+// renameFields renames the fields of the object listed in schemaV2Renames.
+// Only the direct fields of the object are renamed, not those of its children.
+func renameFields(obj map[string]interface{}) {
+	for from, to := range schemaV2Renames {
+		if v, ok := obj[from]; ok {
+			obj[to] = v
+			delete(obj, from)
+		}
+	}
+}
+
+// setBooleans sets the named fields of the object to false when they're
+// missing.
+func setBooleans(obj map[string]interface{}, names []string) {
+	for _, name := range names {
+		if _, ok := obj[name]; !ok {
+			obj[name] = false
+		}
+	}
+}
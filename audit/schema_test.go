@@ -0,0 +1,103 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEntryFormatter_SchemaVersion ensures that entries are formatted with
+// the fields of the configured schema version.
+func TestEntryFormatter_SchemaVersion(t *testing.T) {
+	t.Parallel()
+
+	in := &logical.LogInput{
+		Auth: &logical.Auth{
+			ClientToken: "foo",
+			TokenType:   logical.TokenTypeService,
+			LeaseOptions: logical.LeaseOptions{
+				TTL: time.Hour,
+			},
+		},
+		Request: &logical.Request{
+			ID:         "123",
+			Operation:  logical.UpdateOperation,
+			Path:       "secret/foo",
+			Connection: &logical.Connection{RemoteAddr: "127.0.0.1", RemotePort: 1234},
+			WrapInfo:   &logical.RequestWrapInfo{TTL: time.Minute},
+		},
+		Response: &logical.Response{
+			Data: map[string]interface{}{"ttl": 60},
+			WrapInfo: &logical.ResponseWrapInfo{
+				TTL:   time.Minute,
+				Token: "bar",
+			},
+		},
+	}
+	ctx := namespace.RootContext(context.Background())
+
+	formatEntry := func(t *testing.T, version string, subtype subtype) map[string]interface{} {
+		t.Helper()
+
+		cfg, err := NewFormatterConfig(WithSchemaVersion(version), WithRaw(true))
+		require.NoError(t, err)
+		formatter, err := NewEntryFormatter(cfg, newStaticSalt(t))
+		require.NoError(t, err)
+
+		processed, err := formatter.Process(ctx, fakeEvent(t, subtype, JSONFormat, in))
+		require.NoError(t, err)
+		formatted, ok := processed.Format(JSONFormat.String())
+		require.True(t, ok)
+
+		var doc map[string]interface{}
+		require.NoError(t, json.Unmarshal(formatted, &doc))
+		return doc
+	}
+
+	v1 := formatEntry(t, "", ResponseType)
+	require.NotContains(t, v1, "schema_version")
+	require.Contains(t, v1, "time")
+	request := v1["request"].(map[string]interface{})
+	require.Equal(t, "127.0.0.1", request["remote_address"])
+	require.Equal(t, float64(60), request["wrap_ttl"])
+	require.NotContains(t, request, "policy_override")
+	require.NotContains(t, v1, "forwarded")
+
+	v2 := formatEntry(t, "v2", ResponseType)
+	require.Equal(t, "v2", v2["schema_version"])
+	require.Contains(t, v2, "timestamp")
+	require.NotContains(t, v2, "time")
+	require.Equal(t, false, v2["forwarded"])
+
+	request = v2["request"].(map[string]interface{})
+	require.Equal(t, map[string]interface{}{"address": "127.0.0.1", "port": float64(1234)}, request["remote"])
+	require.NotContains(t, request, "remote_address")
+	require.Equal(t, float64(60), request["wrap_ttl_seconds"])
+	require.Equal(t, false, request["policy_override"])
+
+	auth := v2["auth"].(map[string]interface{})
+	require.Equal(t, float64(3600), auth["token_ttl_seconds"])
+	require.Equal(t, false, auth["entity_created"])
+
+	response := v2["response"].(map[string]interface{})
+	require.Equal(t, float64(60), response["wrap_info"].(map[string]interface{})["ttl_seconds"])
+	// Fields of response data are never renamed.
+	require.Equal(t, float64(60), response["data"].(map[string]interface{})["ttl"])
+
+	// Request entries have no forwarded field.
+	v2 = formatEntry(t, "v2", RequestType)
+	require.Equal(t, "v2", v2["schema_version"])
+	require.NotContains(t, v2, "forwarded")
+
+	// Parquet rows are only built from v1 entries.
+	_, err := NewFormatterConfig(WithSchemaVersion("v2"), WithFormat("parquet"))
+	require.Error(t, err)
+}
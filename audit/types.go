@@ -23,6 +23,9 @@ const (
 const (
 	JSONFormat  format = "json"
 	JSONxFormat format = "jsonx"
+	// ParquetFormat events hold the JSON entry, which is converted into a row
+	// by the parquet sink.
+	ParquetFormat format = "parquet"
 )
 
 // version defines the version of audit events.
@@ -49,15 +52,20 @@ type Option func(*options) error
 
 // options are used to represent configuration for a audit related nodes.
 type options struct {
-	withID           string
-	withNow          time.Time
-	withSubtype      subtype
-	withFormat       format
-	withPrefix       string
-	withRaw          bool
-	withElision      bool
-	withOmitTime     bool
-	withHMACAccessor bool
+	withID             string
+	withNow            time.Time
+	withSubtype        subtype
+	withFormat         format
+	withPrefix         string
+	withRaw            bool
+	withElision        bool
+	withOmitTime       bool
+	withHMACAccessor   bool
+	withRequestMetrics bool
+	withRedaction      []RedactionRule
+	withExcludedPaths  []string
+	withSchemaVersion  schemaVersion
+	withHeaders        map[string]bool
 }
 
 // Salter is an interface that provides a way to obtain a Salt for hashing.
@@ -129,8 +137,31 @@ type FormatterConfig struct {
 	// This should only ever be used in a testing context
 	OmitTime bool
 
-	// The required/target format for the event (supported: JSONFormat and JSONxFormat).
+	// RequestMetrics controls whether response entries include the number of
+	// storage reads and writes caused by the request and how long the backend
+	// took to handle it, to support capacity analysis from audit data.
+	RequestMetrics bool
+
+	// RedactionRules declares fields of request and response entries which
+	// are replaced by RedactedValue before being formatted.
+	RedactionRules []RedactionRule
+	redactions     []redactionPath
+
+	// ExcludedResponsePaths are glob patterns of the request paths whose
+	// response data is omitted from response entries, rather than hashed.
+	ExcludedResponsePaths []string
+
+	// SchemaVersion is the schema version of the formatted entries
+	// (supported: SchemaV1 and SchemaV2).
+	SchemaVersion schemaVersion
+
+	// The required/target format for the event (supported: JSONFormat, JSONxFormat and ParquetFormat).
 	RequiredFormat format
+
+	// Headers are the request headers audited by the device, keyed by their
+	// lower case names, and whether their values are HMAC-ed. When nil, the
+	// headers of the audited headers configuration are audited instead.
+	Headers map[string]bool
 }
 
 // RequestEntry is the structure of a request audit log entry.
@@ -141,6 +172,8 @@ type RequestEntry struct {
 	Request       *Request `json:"request,omitempty"`
 	Error         string   `json:"error,omitempty"`
 	ForwardedFrom string   `json:"forwarded_from,omitempty"` // Populated in Enterprise when a request is forwarded
+
+	schemaVersion schemaVersion
 }
 
 // ResponseEntry is the structure of a response audit log entry.
@@ -152,6 +185,16 @@ type ResponseEntry struct {
 	Response  *Response `json:"response,omitempty"`
 	Error     string    `json:"error,omitempty"`
 	Forwarded bool      `json:"forwarded,omitempty"`
+	Metrics   *Metrics  `json:"metrics,omitempty"`
+
+	schemaVersion schemaVersion
+}
+
+// Metrics records the resources consumed by a request.
+type Metrics struct {
+	StorageReads      int   `json:"storage_reads"`
+	StorageWrites     int   `json:"storage_writes"`
+	HandlerDurationUs int64 `json:"handler_duration_us"`
 }
 
 type Request struct {
@@ -214,6 +257,17 @@ type Auth struct {
 	TokenType                 string              `json:"token_type,omitempty"`
 	TokenTTL                  int64               `json:"token_ttl,omitempty"`
 	TokenIssueTime            string              `json:"token_issue_time,omitempty"`
+	Impersonation             *Impersonation      `json:"impersonation,omitempty"`
+}
+
+// Impersonation tags requests made with, and responses issuing, an
+// impersonation token. The actor is the operator who obtained the token, and
+// the subject is the entity being impersonated.
+type Impersonation struct {
+	ActorEntityID    string `json:"actor_entity_id,omitempty"`
+	ActorAccessor    string `json:"actor_accessor,omitempty"`
+	ActorDisplayName string `json:"actor_display_name,omitempty"`
+	SubjectEntityID  string `json:"subject_entity_id,omitempty"`
 }
 
 type PolicyResults struct {
@@ -284,6 +338,54 @@ type Backend interface {
 	Invalidate(context.Context)
 }
 
+// Tailable may be implemented by audit backends whose entries can be streamed
+// to tail subscribers. The returned Formatter must apply the same hashing and
+// elision as is applied to the entries written by the backend.
+type Tailable interface {
+	TailFormatter() Formatter
+}
+
+// Flushable may be implemented by audit backends that buffer entries before
+// persisting them, so that the entries can be persisted before the node steps
+// down.
+type Flushable interface {
+	Flush(ctx context.Context) error
+}
+
+// Closable may be implemented by audit backends which hold resources, such as
+// background goroutines, to release once the backend isn't used anymore.
+type Closable interface {
+	Close() error
+}
+
+// Sampleable may be implemented by audit backends which keep only a sample
+// of their entries. A nil EntrySampler keeps every entry.
+type Sampleable interface {
+	Sampler() *EntrySampler
+}
+
+// HeaderAuditor may be implemented by audit backends which audit their own
+// selection of request headers, rather than the headers of the audited
+// headers configuration shared by every device.
+type HeaderAuditor interface {
+	// AuditedHeaders returns the audited headers, keyed by their lower case
+	// names, and whether their values are HMAC-ed, or nil when the backend
+	// audits the headers of the audited headers configuration.
+	AuditedHeaders() map[string]bool
+}
+
+// Archived may be implemented by audit backends which keep the entries they
+// log, so that the entries involving an entity can be exported.
+type Archived interface {
+	// ReadArchive calls fn with each entry kept by the backend, as JSON,
+	// oldest first, and stops at the first error returned by fn.
+	ReadArchive(ctx context.Context, fn func(entry []byte) error) error
+
+	// ExportRedactions returns the rules redacting the exported entries, in
+	// addition to the redaction applied when the entries were logged.
+	ExportRedactions() []RedactionRule
+}
+
 // BackendConfig contains configuration parameters used in the factory func to
 // instantiate audit backends
 type BackendConfig struct {
@@ -0,0 +1,452 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// Audit delivery modes.
+const (
+	// DeliveryModeDirect writes entries to the sink of the device before the
+	// request proceeds.
+	DeliveryModeDirect = "direct"
+
+	// DeliveryModeWAL appends entries to a local write-ahead log before the
+	// request proceeds, and ships them to the sink of the device in the
+	// background.
+	DeliveryModeWAL = "wal"
+)
+
+const (
+	walFileName       = "audit.wal"
+	walOffsetFileName = "audit.wal.offset"
+
+	// walHeaderSize is the size of the header of a record of the WAL: the
+	// length of the entry and its CRC-32, both as big endian uint32s.
+	walHeaderSize = 8
+
+	// walCompactSize is the size from which a WAL whose entries were all
+	// shipped is truncated.
+	walCompactSize = 4 << 20
+
+	// walMinRetryBackoff and walMaxRetryBackoff bound the wait before
+	// shipping entries again after the sink failed.
+	walMinRetryBackoff = 100 * time.Millisecond
+	walMaxRetryBackoff = 30 * time.Second
+
+	// walFlushTimeout bounds how long Flush waits for the WAL to be drained.
+	walFlushTimeout = 10 * time.Second
+)
+
+var errWALClosed = errors.New("audit WAL is closed")
+
+// Shippable may be implemented by audit backends whose entries can be
+// formatted and written to their sink in separate steps, so that entries can
+// be delivered from a write-ahead log.
+type Shippable interface {
+	// ShipFormatter returns the formatter of the entries of the backend.
+	ShipFormatter() *EntryFormatterWriter
+
+	// Ship writes an entry formatted by the ShipFormatter to the sink of
+	// the backend.
+	Ship(ctx context.Context, entry []byte) error
+}
+
+// WALBackend is an audit backend delivering the entries of the backend it
+// wraps at least once. Entries are formatted and appended to a write-ahead
+// log, which is synced to disk before LogRequest and LogResponse return, so
+// that the latency of requests doesn't depend on the sink. Entries are then
+// shipped to the sink, in order, by a background shipper, which retries
+// until the sink accepts them. The position of the shipper is persisted next
+// to the log, so entries still in the log when Vault stops are shipped once
+// the device is loaded again. An entry may be shipped twice if Vault stops
+// between shipping it and persisting the position.
+type WALBackend struct {
+	Backend
+
+	name      string
+	shippable Shippable
+	logger    log.Logger
+
+	// lock guards the log, its size and the shipped offset.
+	lock       sync.Mutex
+	f          *os.File
+	offsetFile *os.File
+	size       int64
+	shipped    int64
+
+	notify chan struct{}
+	stopCh chan struct{}
+	doneCh chan struct{}
+	once   sync.Once
+}
+
+var (
+	_ Backend       = (*WALBackend)(nil)
+	_ Tailable      = (*WALBackend)(nil)
+	_ Sampleable    = (*WALBackend)(nil)
+	_ HeaderAuditor = (*WALBackend)(nil)
+	_ Flushable     = (*WALBackend)(nil)
+	_ Closable      = (*WALBackend)(nil)
+)
+
+// NewWALBackend wraps the backend, named after its path, so that its entries
+// are delivered from a write-ahead log kept in the dir directory. Entries
+// left in the log by a previous run are shipped first.
+func NewWALBackend(name string, backend Backend, dir string, logger log.Logger) (*WALBackend, error) {
+	shippable, ok := backend.(Shippable)
+	if !ok {
+		return nil, fmt.Errorf("audit backend doesn't support the %q delivery mode", DeliveryModeWAL)
+	}
+	if dir == "" {
+		return nil, fmt.Errorf("wal_path is required with the %q delivery mode", DeliveryModeWAL)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create WAL directory: %w", err)
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, walFileName), os.O_RDWR|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open WAL: %w", err)
+	}
+	offsetFile, err := os.OpenFile(filepath.Join(dir, walOffsetFileName), os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to open WAL offset: %w", err)
+	}
+
+	b := &WALBackend{
+		Backend:    backend,
+		name:       name,
+		shippable:  shippable,
+		logger:     logger,
+		f:          f,
+		offsetFile: offsetFile,
+		notify:     make(chan struct{}, 1),
+		stopCh:     make(chan struct{}),
+		doneCh:     make(chan struct{}),
+	}
+	if err := b.recover(); err != nil {
+		f.Close()
+		offsetFile.Close()
+		return nil, err
+	}
+
+	go b.ship()
+	return b, nil
+}
+
+// recover loads the shipped offset, and truncates a record left incomplete
+// at the end of the log, as a crash while appending may leave behind.
+func (b *WALBackend) recover() error {
+	info, err := b.f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read WAL: %w", err)
+	}
+	b.size = info.Size()
+
+	var buf [8]byte
+	if _, err := b.offsetFile.ReadAt(buf[:], 0); err == nil {
+		b.shipped = int64(binary.BigEndian.Uint64(buf[:]))
+	} else if err != io.EOF {
+		return fmt.Errorf("failed to read WAL offset: %w", err)
+	}
+	if b.shipped < 0 || b.shipped > b.size {
+		// The offset doesn't match the log, which happens when the log was
+		// truncated before the offset was reset. Shipping the whole log
+		// again keeps the delivery guarantee.
+		b.logger.Warn("invalid audit WAL offset, shipping the whole WAL", "offset", b.shipped, "size", b.size)
+		b.shipped = 0
+	}
+
+	end := b.shipped
+	for end < b.size {
+		_, next, err := b.readRecord(end)
+		if err != nil {
+			b.logger.Warn("truncating incomplete audit WAL record", "offset", end, "error", err)
+			if err := b.f.Truncate(end); err != nil {
+				return fmt.Errorf("failed to truncate WAL: %w", err)
+			}
+			b.size = end
+			break
+		}
+		end = next
+	}
+
+	if pending := b.size - b.shipped; pending > 0 {
+		b.logger.Info("shipping audit entries left in the WAL", "pending_bytes", pending)
+	}
+	return nil
+}
+
+// TailFormatter returns the formatter of the wrapped backend.
+func (b *WALBackend) TailFormatter() Formatter {
+	return b.shippable.ShipFormatter()
+}
+
+// Sampler returns the sampler of the wrapped backend, if any.
+func (b *WALBackend) Sampler() *EntrySampler {
+	if sampleable, ok := b.Backend.(Sampleable); ok {
+		return sampleable.Sampler()
+	}
+	return nil
+}
+
+// AuditedHeaders returns the headers audited by the wrapped backend, if any.
+func (b *WALBackend) AuditedHeaders() map[string]bool {
+	if auditor, ok := b.Backend.(HeaderAuditor); ok {
+		return auditor.AuditedHeaders()
+	}
+	return nil
+}
+
+func (b *WALBackend) LogRequest(ctx context.Context, in *logical.LogInput) error {
+	var buf bytes.Buffer
+	if err := b.shippable.ShipFormatter().FormatAndWriteRequest(ctx, &buf, in); err != nil {
+		return err
+	}
+	return b.append(buf.Bytes())
+}
+
+func (b *WALBackend) LogResponse(ctx context.Context, in *logical.LogInput) error {
+	var buf bytes.Buffer
+	if err := b.shippable.ShipFormatter().FormatAndWriteResponse(ctx, &buf, in); err != nil {
+		return err
+	}
+	return b.append(buf.Bytes())
+}
+
+// append appends the entry to the log and syncs it to disk.
+func (b *WALBackend) append(entry []byte) error {
+	record := make([]byte, walHeaderSize+len(entry))
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(entry)))
+	binary.BigEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(entry))
+	copy(record[walHeaderSize:], entry)
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.f == nil {
+		return errWALClosed
+	}
+	if _, err := b.f.Write(record); err != nil {
+		// Drop what was written of the record, so that the log stays
+		// readable.
+		if tErr := b.f.Truncate(b.size); tErr != nil {
+			b.logger.Error("failed to truncate audit WAL", "error", tErr)
+		}
+		return fmt.Errorf("failed to append to WAL: %w", err)
+	}
+	b.size += int64(len(record))
+	if err := b.f.Sync(); err != nil {
+		return fmt.Errorf("failed to sync WAL: %w", err)
+	}
+	b.setPendingGauge()
+
+	select {
+	case b.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// readRecord reads the entry of the record at the offset, and returns it
+// along with the offset of the next record.
+func (b *WALBackend) readRecord(offset int64) ([]byte, int64, error) {
+	var header [walHeaderSize]byte
+	if _, err := b.f.ReadAt(header[:], offset); err != nil {
+		return nil, 0, err
+	}
+	length := binary.BigEndian.Uint32(header[0:4])
+	if offset+walHeaderSize+int64(length) > b.size {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	entry := make([]byte, length)
+	if _, err := b.f.ReadAt(entry, offset+walHeaderSize); err != nil {
+		return nil, 0, err
+	}
+	if crc32.ChecksumIEEE(entry) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, 0, fmt.Errorf("checksum mismatch")
+	}
+	return entry, offset + walHeaderSize + int64(length), nil
+}
+
+// ship drains the log whenever entries are appended, backing off while the
+// sink fails.
+func (b *WALBackend) ship() {
+	defer close(b.doneCh)
+
+	backoff := walMinRetryBackoff
+	for {
+		var retry <-chan time.Time
+		if err := b.drain(); err != nil {
+			b.logger.Warn("failed to ship audit entries, retrying", "error", err, "backoff", backoff)
+			metrics.IncrCounter([]string{"audit", b.name, "wal_ship_failure"}, 1)
+			retry = time.After(backoff)
+			backoff *= 2
+			if backoff > walMaxRetryBackoff {
+				backoff = walMaxRetryBackoff
+			}
+		} else {
+			backoff = walMinRetryBackoff
+		}
+
+		select {
+		case <-b.stopCh:
+			return
+		case <-retry:
+		case <-b.notify:
+			if retry != nil {
+				// Keep backing off while the sink fails.
+				select {
+				case <-b.stopCh:
+					return
+				case <-retry:
+				}
+			}
+		}
+	}
+}
+
+// drain ships the entries of the log, in order, until none is left or the
+// sink fails.
+func (b *WALBackend) drain() error {
+	for {
+		select {
+		case <-b.stopCh:
+			return nil
+		default:
+		}
+
+		b.lock.Lock()
+		if b.f == nil {
+			b.lock.Unlock()
+			return nil
+		}
+		if b.shipped >= b.size {
+			err := b.compact()
+			b.lock.Unlock()
+			return err
+		}
+		entry, next, err := b.readRecord(b.shipped)
+		b.lock.Unlock()
+		if err != nil {
+			return fmt.Errorf("failed to read WAL: %w", err)
+		}
+
+		if err := b.shippable.Ship(context.Background(), entry); err != nil {
+			return err
+		}
+
+		b.lock.Lock()
+		err = b.storeOffset(next)
+		b.setPendingGauge()
+		b.lock.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// compact truncates the log once all of its entries were shipped, if it
+// grew large enough. The lock must be held.
+func (b *WALBackend) compact() error {
+	if b.size < walCompactSize {
+		return nil
+	}
+	if err := b.f.Truncate(0); err != nil {
+		return fmt.Errorf("failed to truncate WAL: %w", err)
+	}
+	b.size = 0
+	return b.storeOffset(0)
+}
+
+// storeOffset persists the offset up to which entries were shipped. It isn't
+// synced to disk, as losing it only ships entries again. The lock must be
+// held.
+func (b *WALBackend) storeOffset(offset int64) error {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(offset))
+	if _, err := b.offsetFile.WriteAt(buf[:], 0); err != nil {
+		return fmt.Errorf("failed to store WAL offset: %w", err)
+	}
+	b.shipped = offset
+	return nil
+}
+
+// setPendingGauge reports the size of the entries not shipped yet. The lock
+// must be held.
+func (b *WALBackend) setPendingGauge() {
+	metrics.SetGauge([]string{"audit", b.name, "wal_pending_bytes"}, float32(b.size-b.shipped))
+}
+
+// pending returns the size of the entries not shipped yet.
+func (b *WALBackend) pending() int64 {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.size - b.shipped
+}
+
+// Flush waits for the entries in the log to be shipped, for up to
+// walFlushTimeout, then flushes the wrapped backend. Entries which couldn't
+// be shipped stay in the log.
+func (b *WALBackend) Flush(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, walFlushTimeout)
+	defer cancel()
+
+	select {
+	case b.notify <- struct{}{}:
+	default:
+	}
+
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for b.pending() > 0 {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%d bytes of audit entries are still pending in the WAL", b.pending())
+		case <-ticker.C:
+		}
+	}
+
+	if flushable, ok := b.Backend.(Flushable); ok {
+		return flushable.Flush(ctx)
+	}
+	return nil
+}
+
+// Close stops the shipper and closes the log. Entries not shipped yet stay
+// in the log.
+func (b *WALBackend) Close() error {
+	var err error
+	b.once.Do(func() {
+		close(b.stopCh)
+		<-b.doneCh
+
+		b.lock.Lock()
+		defer b.lock.Unlock()
+		if sErr := b.offsetFile.Sync(); sErr != nil {
+			err = sErr
+		}
+		b.offsetFile.Close()
+		b.f.Close()
+		b.f = nil
+	})
+	return err
+}
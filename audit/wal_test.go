@@ -0,0 +1,159 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package audit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+// testShipBackend is a shippable audit backend collecting the entries shipped
+// to it, which fails to ship them while fail is set.
+type testShipBackend struct {
+	Backend
+
+	formatter *EntryFormatterWriter
+
+	lock    sync.Mutex
+	fail    bool
+	shipped []string
+}
+
+func newTestShipBackend(t *testing.T) *testShipBackend {
+	t.Helper()
+
+	cfg, err := NewFormatterConfig()
+	require.NoError(t, err)
+	f, err := NewEntryFormatter(cfg, newStaticSalt(t))
+	require.NoError(t, err)
+	fw, err := NewEntryFormatterWriter(cfg, f, &JSONWriter{})
+	require.NoError(t, err)
+	return &testShipBackend{formatter: fw}
+}
+
+func (b *testShipBackend) ShipFormatter() *EntryFormatterWriter {
+	return b.formatter
+}
+
+func (b *testShipBackend) Ship(_ context.Context, entry []byte) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.fail {
+		return errors.New("sink unavailable")
+	}
+	b.shipped = append(b.shipped, string(entry))
+	return nil
+}
+
+func (b *testShipBackend) setFail(fail bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.fail = fail
+}
+
+func (b *testShipBackend) entries() []string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return append([]string{}, b.shipped...)
+}
+
+func testLogWALRequests(t *testing.T, b *WALBackend, ids ...string) {
+	t.Helper()
+	for _, id := range ids {
+		err := b.LogRequest(context.Background(), &logical.LogInput{
+			Request: &logical.Request{ID: id, Path: "secret/foo", Operation: logical.ReadOperation},
+		})
+		require.NoError(t, err)
+	}
+}
+
+// TestWALBackend_Ship checks that entries are acknowledged while the sink
+// fails, and shipped in order once it recovers.
+func TestWALBackend_Ship(t *testing.T) {
+	sink := newTestShipBackend(t)
+	sink.setFail(true)
+
+	b, err := NewWALBackend("test/", sink, t.TempDir(), log.NewNullLogger())
+	require.NoError(t, err)
+	defer b.Close()
+
+	testLogWALRequests(t, b, "first", "second", "third")
+	require.Empty(t, sink.entries())
+	require.Greater(t, b.pending(), int64(0))
+
+	sink.setFail(false)
+	require.NoError(t, b.Flush(context.Background()))
+	require.Zero(t, b.pending())
+
+	entries := sink.entries()
+	require.Len(t, entries, 3)
+	for i, id := range []string{"first", "second", "third"} {
+		require.Contains(t, entries[i], `"id":"`+id+`"`)
+	}
+}
+
+// TestWALBackend_Recover checks that the entries left in the WAL are shipped
+// once it is opened again, without the record left incomplete by a crash.
+func TestWALBackend_Recover(t *testing.T) {
+	dir := t.TempDir()
+	sink := newTestShipBackend(t)
+	sink.setFail(true)
+
+	b, err := NewWALBackend("test/", sink, dir, log.NewNullLogger())
+	require.NoError(t, err)
+	testLogWALRequests(t, b, "first", "second")
+	require.NoError(t, b.Close())
+	require.ErrorIs(t, b.append([]byte("closed")), errWALClosed)
+
+	// Simulate a crash while appending a record.
+	walPath := filepath.Join(dir, walFileName)
+	info, err := os.Stat(walPath)
+	require.NoError(t, err)
+	f, err := os.OpenFile(walPath, os.O_WRONLY|os.O_APPEND, 0o600)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0, 0, 1, 0, 1, 2})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	sink.setFail(false)
+	b, err = NewWALBackend("test/", sink, dir, log.NewNullLogger())
+	require.NoError(t, err)
+	defer b.Close()
+	require.NoError(t, b.Flush(context.Background()))
+
+	entries := sink.entries()
+	require.Len(t, entries, 2)
+	require.Contains(t, entries[0], `"id":"first"`)
+	require.Contains(t, entries[1], `"id":"second"`)
+
+	truncated, err := os.Stat(walPath)
+	require.NoError(t, err)
+	require.Equal(t, info.Size(), truncated.Size())
+
+	// Shipped entries aren't shipped again when the WAL is opened again.
+	require.NoError(t, b.Close())
+	b, err = NewWALBackend("test/", sink, dir, log.NewNullLogger())
+	require.NoError(t, err)
+	defer b.Close()
+	require.Zero(t, b.pending())
+}
+
+func TestNewWALBackend_Unsupported(t *testing.T) {
+	_, err := NewWALBackend("test/", &testShipBackend{}, "", log.NewNullLogger())
+	require.Error(t, err)
+
+	type unshippable struct {
+		Backend
+	}
+	_, err = NewWALBackend("test/", &unshippable{}, t.TempDir(), log.NewNullLogger())
+	require.Error(t, err)
+}
@@ -4,18 +4,26 @@
 package file
 
 import (
+	"bufio"
 	"bytes"
+	"compress/gzip"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
+	"github.com/hashicorp/eventlogger"
 	"github.com/hashicorp/vault/audit"
+	"github.com/hashicorp/vault/internal/observability/event"
+	"github.com/hashicorp/vault/sdk/helper/jsonutil"
 	"github.com/hashicorp/vault/sdk/helper/salt"
 	"github.com/hashicorp/vault/sdk/logical"
 )
@@ -50,10 +58,24 @@ func Factory(ctx context.Context, conf *audit.BackendConfig, useEventLogger bool
 	}
 	switch format {
 	case "json", "jsonx":
+	case "parquet":
+		if path == "stdout" || path == "discard" {
+			return nil, fmt.Errorf("file_path must be a directory with the parquet format")
+		}
 	default:
 		return nil, fmt.Errorf("unknown format type %q", format)
 	}
 
+	compression := strings.TrimSpace(conf.Config["compression"])
+	if compression != "" {
+		switch {
+		case format == "parquet":
+			return nil, fmt.Errorf("compression is not supported with the parquet format")
+		case path == "stdout" || path == "discard":
+			return nil, fmt.Errorf("compression is only supported when writing to a file")
+		}
+	}
+
 	// Check if hashing of accessor is disabled
 	hmacAccessor := true
 	if hmacAccessorRaw, ok := conf.Config["hmac_accessor"]; ok {
@@ -83,6 +105,15 @@ func Factory(ctx context.Context, conf *audit.BackendConfig, useEventLogger bool
 		elideListResponses = value
 	}
 
+	logRequestMetrics := false
+	if logRequestMetricsRaw, ok := conf.Config["log_request_metrics"]; ok {
+		value, err := strconv.ParseBool(logRequestMetricsRaw)
+		if err != nil {
+			return nil, err
+		}
+		logRequestMetrics = value
+	}
+
 	// Check if mode is provided
 	mode := os.FileMode(0o600)
 	if modeRaw, ok := conf.Config["mode"]; ok {
@@ -107,23 +138,51 @@ func Factory(ctx context.Context, conf *audit.BackendConfig, useEventLogger bool
 
 	}
 
+	redactionRules, err := audit.ParseRedactionRules(conf.Config["redact"])
+	if err != nil {
+		return nil, err
+	}
+
+	exportRedactions, err := audit.ParseRedactionRules(conf.Config["export_redact"])
+	if err != nil {
+		return nil, err
+	}
+
+	sampler, err := audit.NewEntrySampler(conf.Config["sample_rate"], conf.Config["sample_operations"], conf.Config["sample_paths"])
+	if err != nil {
+		return nil, err
+	}
+
+	headers, err := audit.ParseHeaders(conf.Config["headers"])
+	if err != nil {
+		return nil, err
+	}
+
 	cfg, err := audit.NewFormatterConfig(
 		audit.WithElision(elideListResponses),
 		audit.WithFormat(format),
 		audit.WithHMACAccessor(hmacAccessor),
 		audit.WithRaw(logRaw),
+		audit.WithRequestMetrics(logRequestMetrics),
+		audit.WithRedaction(redactionRules),
+		audit.WithExcludedResponsePaths(conf.Config["exclude_response_paths"]),
+		audit.WithSchemaVersion(conf.Config["schema_version"]),
+		audit.WithHeaders(headers),
 	)
 	if err != nil {
 		return nil, err
 	}
 
 	b := &Backend{
-		path:         path,
-		mode:         mode,
-		saltConfig:   conf.SaltConfig,
-		saltView:     conf.SaltView,
-		salt:         new(atomic.Value),
-		formatConfig: cfg,
+		path:             path,
+		prefix:           conf.Config["prefix"],
+		mode:             mode,
+		exportRedactions: exportRedactions,
+		saltConfig:       conf.SaltConfig,
+		saltView:         conf.SaltView,
+		salt:             new(atomic.Value),
+		formatConfig:     cfg,
+		sampler:          sampler,
 	}
 
 	// Ensure we are working with the right type by explicitly storing a nil of
@@ -137,7 +196,7 @@ func Factory(ctx context.Context, conf *audit.BackendConfig, useEventLogger bool
 	}
 	var w audit.Writer
 	switch format {
-	case "json":
+	case "json", "parquet":
 		w = &audit.JSONWriter{Prefix: conf.Config["prefix"]}
 	case "jsonx":
 		w = &audit.JSONxWriter{Prefix: conf.Config["prefix"]}
@@ -149,8 +208,29 @@ func Factory(ctx context.Context, conf *audit.BackendConfig, useEventLogger bool
 	}
 	b.formatter = fw
 
-	switch path {
-	case "stdout", "discard":
+	switch {
+	case format == "parquet":
+		// Entries are batched into files written in the directory at path.
+		b.parquet, err = event.NewParquetSink(path, format,
+			event.WithFileMode(conf.Config["mode"]),
+			event.WithBatchSize(conf.Config["batch_size"]),
+			event.WithFlushInterval(conf.Config["flush_interval"]),
+		)
+		if err != nil {
+			return nil, err
+		}
+	case compression != "":
+		// Entries are compressed by the sink, which also checks that the file
+		// can be opened for writing.
+		b.compressed, err = event.NewFileSink(path, format,
+			event.WithFileMode(conf.Config["mode"]),
+			event.WithCompression(compression),
+			event.WithFlushInterval(conf.Config["flush_interval"]),
+		)
+		if err != nil {
+			return nil, err
+		}
+	case path == "stdout", path == "discard":
 		// no need to test opening file if outputting to stdout or discarding
 	default:
 		// Ensure that the file can be successfully opened for writing;
@@ -170,22 +250,44 @@ func Factory(ctx context.Context, conf *audit.BackendConfig, useEventLogger bool
 // It doesn't do anything more at the moment to assist with rotation
 // or reset the write cursor, this should be done in the future.
 type Backend struct {
-	path string
+	path   string
+	prefix string
 
 	formatter    *audit.EntryFormatterWriter
 	formatConfig audit.FormatterConfig
+	sampler      *audit.EntrySampler
 
 	fileLock sync.RWMutex
 	f        *os.File
 	mode     os.FileMode
 
+	// parquet batches the entries into parquet files when the format is
+	// parquet, instead of appending them to a file.
+	parquet *event.ParquetSink
+
+	// compressed writes the entries to a compressed file when compression is
+	// configured.
+	compressed *event.FileSink
+
+	// exportRedactions are applied to the entries exported from the log file
+	// and its rotated archives.
+	exportRedactions []audit.RedactionRule
+
 	saltMutex  sync.RWMutex
 	salt       *atomic.Value
 	saltConfig *salt.Config
 	saltView   logical.Storage
 }
 
-var _ audit.Backend = (*Backend)(nil)
+var (
+	_ audit.Backend       = (*Backend)(nil)
+	_ audit.Tailable      = (*Backend)(nil)
+	_ audit.Flushable     = (*Backend)(nil)
+	_ audit.Sampleable    = (*Backend)(nil)
+	_ audit.HeaderAuditor = (*Backend)(nil)
+	_ audit.Shippable     = (*Backend)(nil)
+	_ audit.Archived      = (*Backend)(nil)
+)
 
 func (b *Backend) Salt(ctx context.Context) (*salt.Salt, error) {
 	s := b.salt.Load().(*salt.Salt)
@@ -211,6 +313,23 @@ func (b *Backend) Salt(ctx context.Context) (*salt.Salt, error) {
 	return newSalt, nil
 }
 
+// TailFormatter returns the formatter of the backend, so that tailed entries
+// are hashed like the entries written by the backend.
+func (b *Backend) TailFormatter() audit.Formatter {
+	return b.formatter
+}
+
+// Sampler returns the sampler of the entries of the backend.
+func (b *Backend) Sampler() *audit.EntrySampler {
+	return b.sampler
+}
+
+// AuditedHeaders returns the request headers audited by the backend, or nil
+// when it audits the headers of the audited headers configuration.
+func (b *Backend) AuditedHeaders() map[string]bool {
+	return b.formatConfig.Headers
+}
+
 func (b *Backend) GetHash(ctx context.Context, data string) (string, error) {
 	salt, err := b.Salt(ctx)
 	if err != nil {
@@ -220,7 +339,38 @@ func (b *Backend) GetHash(ctx context.Context, data string) (string, error) {
 	return audit.HashString(salt, data), nil
 }
 
+// ShipFormatter returns the formatter of the entries of the backend.
+func (b *Backend) ShipFormatter() *audit.EntryFormatterWriter {
+	return b.formatter
+}
+
+// Ship writes a formatted entry to the log file, or hands it to the parquet
+// sink when the format is parquet.
+func (b *Backend) Ship(ctx context.Context, entry []byte) error {
+	if b.parquet != nil {
+		return b.processParquet(ctx, entry)
+	}
+
+	var writer io.Writer
+	switch b.path {
+	case "stdout":
+		writer = os.Stdout
+	case "discard":
+		return nil
+	}
+
+	return b.log(ctx, bytes.NewBuffer(entry), writer)
+}
+
 func (b *Backend) LogRequest(ctx context.Context, in *logical.LogInput) error {
+	if b.parquet != nil {
+		entry, err := b.formatter.FormatRequest(ctx, in)
+		if err != nil {
+			return err
+		}
+		return b.logParquet(ctx, entry)
+	}
+
 	var writer io.Writer
 	switch b.path {
 	case "stdout":
@@ -239,6 +389,10 @@ func (b *Backend) LogRequest(ctx context.Context, in *logical.LogInput) error {
 }
 
 func (b *Backend) log(ctx context.Context, buf *bytes.Buffer, writer io.Writer) error {
+	if writer == nil && b.compressed != nil {
+		return b.logCompressed(ctx, buf)
+	}
+
 	reader := bytes.NewReader(buf.Bytes())
 
 	b.fileLock.Lock()
@@ -277,6 +431,14 @@ func (b *Backend) log(ctx context.Context, buf *bytes.Buffer, writer io.Writer)
 }
 
 func (b *Backend) LogResponse(ctx context.Context, in *logical.LogInput) error {
+	if b.parquet != nil {
+		entry, err := b.formatter.FormatResponse(ctx, in)
+		if err != nil {
+			return err
+		}
+		return b.logParquet(ctx, entry)
+	}
+
 	var writer io.Writer
 	switch b.path {
 	case "stdout":
@@ -295,6 +457,23 @@ func (b *Backend) LogResponse(ctx context.Context, in *logical.LogInput) error {
 }
 
 func (b *Backend) LogTestMessage(ctx context.Context, in *logical.LogInput, config map[string]string) error {
+	if b.parquet != nil {
+		temporaryFormatter, err := audit.NewTemporaryFormatter(config["format"], "")
+		if err != nil {
+			return err
+		}
+		entry, err := temporaryFormatter.FormatRequest(ctx, in)
+		if err != nil {
+			return err
+		}
+		if err := b.logParquet(ctx, entry); err != nil {
+			return err
+		}
+		// Write the test message straight away, to check that files can be
+		// written.
+		return b.parquet.Flush(ctx)
+	}
+
 	var writer io.Writer
 	switch b.path {
 	case "stdout":
@@ -316,6 +495,45 @@ func (b *Backend) LogTestMessage(ctx context.Context, in *logical.LogInput, conf
 	return b.log(ctx, &buf, writer)
 }
 
+// logParquet hands the entry to the parquet sink, which writes it along with
+// the rest of its batch.
+func (b *Backend) logParquet(ctx context.Context, entry interface{}) error {
+	data, err := jsonutil.EncodeJSON(entry)
+	if err != nil {
+		return err
+	}
+
+	return b.processParquet(ctx, data)
+}
+
+// processParquet hands the JSON entry to the parquet sink.
+func (b *Backend) processParquet(ctx context.Context, data []byte) error {
+	e := &eventlogger.Event{
+		Type:      eventlogger.EventType(event.AuditType),
+		CreatedAt: time.Now(),
+		Formatted: make(map[string][]byte),
+	}
+	e.FormattedAs(audit.ParquetFormat.String(), data)
+
+	_, err := b.parquet.Process(ctx, e)
+	return err
+}
+
+// logCompressed hands the formatted entry to the compressing file sink.
+func (b *Backend) logCompressed(ctx context.Context, buf *bytes.Buffer) error {
+	format := b.formatConfig.RequiredFormat.String()
+
+	e := &eventlogger.Event{
+		Type:      eventlogger.EventType(event.AuditType),
+		CreatedAt: time.Now(),
+		Formatted: make(map[string][]byte),
+	}
+	e.FormattedAs(format, buf.Bytes())
+
+	_, err := b.compressed.Process(ctx, e)
+	return err
+}
+
 // The file lock must be held before calling this
 func (b *Backend) open() error {
 	if b.f != nil {
@@ -347,7 +565,137 @@ func (b *Backend) open() error {
 	return nil
 }
 
+// Flush commits the entries written to the log file to stable storage, or
+// writes the batched entries with the parquet format.
+func (b *Backend) Flush(ctx context.Context) error {
+	if b.parquet != nil {
+		return b.parquet.Flush(ctx)
+	}
+	if b.compressed != nil {
+		return b.compressed.Flush(ctx)
+	}
+
+	b.fileLock.Lock()
+	defer b.fileLock.Unlock()
+
+	if b.f == nil {
+		return nil
+	}
+	return b.f.Sync()
+}
+
+// ExportRedactions returns the rules redacting the entries exported from the
+// log file.
+func (b *Backend) ExportRedactions() []audit.RedactionRule {
+	return b.exportRedactions
+}
+
+// ReadArchive reads the entries of the log file, and of its archives rotated
+// alongside it, such as "audit.log.1" or "audit.log-20230601.gz", oldest file
+// first. Compressed files are decompressed.
+func (b *Backend) ReadArchive(ctx context.Context, fn func(entry []byte) error) error {
+	switch {
+	case b.parquet != nil:
+		return fmt.Errorf("entries written with the parquet format cannot be read back")
+	case b.formatConfig.RequiredFormat != audit.JSONFormat:
+		return fmt.Errorf("entries can only be read back with the json format")
+	case b.path == "stdout", b.path == "discard":
+		return fmt.Errorf("entries are not written to a file")
+	}
+
+	// Include the entries which are still buffered.
+	if err := b.Flush(ctx); err != nil {
+		return err
+	}
+
+	archives, err := filepath.Glob(b.path + ".*")
+	if err != nil {
+		return err
+	}
+
+	type archive struct {
+		path    string
+		modTime time.Time
+	}
+	var files []archive
+	for _, path := range append(archives, b.path) {
+		info, err := os.Stat(path)
+		switch {
+		case os.IsNotExist(err):
+			continue
+		case err != nil:
+			return err
+		case !info.Mode().IsRegular():
+			continue
+		}
+		files = append(files, archive{path: path, modTime: info.ModTime()})
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+
+	for _, file := range files {
+		if err := b.readArchiveFile(ctx, file.path, fn); err != nil {
+			return fmt.Errorf("error reading %q: %w", file.path, err)
+		}
+	}
+	return nil
+}
+
+// readArchiveFile calls fn with each entry of the file at path.
+func (b *Backend) readArchiveFile(ctx context.Context, path string, fn func(entry []byte) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r *bufio.Reader
+	br := bufio.NewReader(f)
+	if magic, _ := br.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = bufio.NewReader(gz)
+	} else {
+		r = br
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		line, err := r.ReadBytes('\n')
+		switch {
+		case err == io.EOF, errors.Is(err, io.ErrUnexpectedEOF):
+			// The last gzip member of the log file is only complete once the
+			// file is closed, and the last line may still be being written.
+			return nil
+		case err != nil:
+			return err
+		}
+
+		line = bytes.TrimPrefix(bytes.TrimSpace(line), []byte(b.prefix))
+		if len(line) == 0 {
+			continue
+		}
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+}
+
 func (b *Backend) Reload(_ context.Context) error {
+	if b.parquet != nil {
+		return b.parquet.Reopen()
+	}
+	if b.compressed != nil {
+		return b.compressed.Reopen()
+	}
+
 	switch b.path {
 	case "stdout", "discard":
 		return nil
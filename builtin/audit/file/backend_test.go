@@ -4,11 +4,16 @@
 package file
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -136,6 +141,204 @@ func TestAuditFile_fileMode0000(t *testing.T) {
 	}
 }
 
+func TestAuditFile_parquet(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "audit")
+
+	_, err := Factory(context.Background(), &audit.BackendConfig{
+		Config:     map[string]string{"path": "stdout", "format": "parquet"},
+		SaltConfig: &salt.Config{},
+		SaltView:   &logical.InmemStorage{},
+	}, false)
+	if err == nil {
+		t.Fatal("expected an error with stdout and the parquet format")
+	}
+
+	b, err := Factory(context.Background(), &audit.BackendConfig{
+		Config: map[string]string{
+			"path":       dir,
+			"format":     "parquet",
+			"batch_size": "2",
+		},
+		SaltConfig: &salt.Config{},
+		SaltView:   &logical.InmemStorage{},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := func() []string {
+		t.Helper()
+		matches, err := filepath.Glob(filepath.Join(dir, "*.parquet"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return matches
+	}
+
+	in := &logical.LogInput{
+		Request: &logical.Request{
+			Operation: logical.ReadOperation,
+			Path:      "secret/foo",
+		},
+	}
+	ctx := namespace.RootContext(nil)
+
+	// Entries are written once the batch is complete.
+	if err := b.LogRequest(ctx, in); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(files()); n != 0 {
+		t.Fatalf("expected the entry to be batched, got %d files", n)
+	}
+	if err := b.LogResponse(ctx, in); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(files()); n != 1 {
+		t.Fatalf("expected 1 file, got %d", n)
+	}
+
+	// Batched entries are written when flushing.
+	if err := b.LogRequest(ctx, in); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.(audit.Flushable).Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(files()); n != 2 {
+		t.Fatalf("expected 2 files, got %d", n)
+	}
+}
+
+func TestAuditFile_gzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log.gz")
+
+	_, err := Factory(context.Background(), &audit.BackendConfig{
+		Config:     map[string]string{"path": "stdout", "compression": "gzip"},
+		SaltConfig: &salt.Config{},
+		SaltView:   &logical.InmemStorage{},
+	}, false)
+	if err == nil {
+		t.Fatal("expected an error with stdout and compression")
+	}
+
+	b, err := Factory(context.Background(), &audit.BackendConfig{
+		Config:     map[string]string{"path": path, "compression": "gzip"},
+		SaltConfig: &salt.Config{},
+		SaltView:   &logical.InmemStorage{},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	in := &logical.LogInput{
+		Request: &logical.Request{
+			Operation: logical.ReadOperation,
+			Path:      "secret/foo",
+		},
+	}
+	ctx := namespace.RootContext(nil)
+	if err := b.LogRequest(ctx, in); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.(audit.Flushable).Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The gzip member is only complete once the file is closed, so reading
+	// ends with an unexpected EOF.
+	data, _ := ioutil.ReadAll(r)
+	if !strings.Contains(string(data), `"path":"secret/foo"`) {
+		t.Fatalf("expected the entry to be written, got %q", data)
+	}
+}
+
+// TestAuditFile_ReadArchive ensures that the entries of the log file and of
+// its rotated archives are read back, oldest first, including compressed
+// archives.
+func TestAuditFile_ReadArchive(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.log")
+
+	b, err := Factory(context.Background(), &audit.BackendConfig{
+		Config:     map[string]string{"path": path, "prefix": "vault:"},
+		SaltConfig: &salt.Config{},
+		SaltView:   &logical.InmemStorage{},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An archive compressed by logrotate
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte("vault:{\"request\":{\"path\":\"archived\"}}\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	archive := path + ".1.gz"
+	if err := os.WriteFile(archive, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(archive, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	in := &logical.LogInput{
+		Request: &logical.Request{
+			Operation: logical.ReadOperation,
+			Path:      "secret/foo",
+		},
+	}
+	ctx := namespace.RootContext(nil)
+	if err := b.LogRequest(ctx, in); err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	err = b.(audit.Archived).ReadArchive(ctx, func(entry []byte) error {
+		var e struct {
+			Request struct {
+				Path string `json:"path"`
+			} `json:"request"`
+		}
+		if err := json.Unmarshal(entry, &e); err != nil {
+			return err
+		}
+		paths = append(paths, e.Request.Path)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(paths, []string{"archived", "secret/foo"}) {
+		t.Fatalf("unexpected entries: %v", paths)
+	}
+
+	b, err = Factory(context.Background(), &audit.BackendConfig{
+		Config:     map[string]string{"path": "stdout"},
+		SaltConfig: &salt.Config{},
+		SaltView:   &logical.InmemStorage{},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.(audit.Archived).ReadArchive(ctx, func([]byte) error { return nil }); err == nil {
+		t.Fatal("expected an error reading back entries written to stdout")
+	}
+}
+
 func BenchmarkAuditFile_request(b *testing.B) {
 	config := map[string]string{
 		"path": "/dev/null",
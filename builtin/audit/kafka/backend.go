@@ -0,0 +1,345 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/vault/audit"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/internal/observability/event"
+	"github.com/hashicorp/vault/sdk/helper/salt"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	partitionKeyMountPath = "mount_path" // partitionKeyMountPath keys entries with the mount path of their request
+	partitionKeyNamespace = "namespace"  // partitionKeyNamespace keys entries with the namespace of their request
+	partitionKeyNone      = "none"       // partitionKeyNone spreads entries across the partitions
+)
+
+func Factory(ctx context.Context, conf *audit.BackendConfig, useEventLogger bool) (audit.Backend, error) {
+	if conf.SaltConfig == nil {
+		return nil, fmt.Errorf("nil salt config")
+	}
+	if conf.SaltView == nil {
+		return nil, fmt.Errorf("nil salt view")
+	}
+
+	brokers, ok := conf.Config["brokers"]
+	if !ok {
+		return nil, fmt.Errorf("brokers is required")
+	}
+
+	topic, ok := conf.Config["topic"]
+	if !ok {
+		return nil, fmt.Errorf("topic is required")
+	}
+
+	partitionKey, ok := conf.Config["partition_key"]
+	if !ok {
+		partitionKey = partitionKeyMountPath
+	}
+	switch partitionKey {
+	case partitionKeyMountPath, partitionKeyNamespace, partitionKeyNone:
+	default:
+		return nil, fmt.Errorf("unknown partition key %q", partitionKey)
+	}
+
+	writeTimeout, ok := conf.Config["write_timeout"]
+	if !ok {
+		writeTimeout = "2s"
+	}
+
+	format, ok := conf.Config["format"]
+	if !ok {
+		format = "json"
+	}
+	switch format {
+	case "json", "jsonx":
+	default:
+		return nil, fmt.Errorf("unknown format type %q", format)
+	}
+
+	// Check if hashing of accessor is disabled
+	hmacAccessor := true
+	if hmacAccessorRaw, ok := conf.Config["hmac_accessor"]; ok {
+		value, err := strconv.ParseBool(hmacAccessorRaw)
+		if err != nil {
+			return nil, err
+		}
+		hmacAccessor = value
+	}
+
+	// Check if raw logging is enabled
+	logRaw := false
+	if raw, ok := conf.Config["log_raw"]; ok {
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, err
+		}
+		logRaw = b
+	}
+
+	elideListResponses := false
+	if elideListResponsesRaw, ok := conf.Config["elide_list_responses"]; ok {
+		value, err := strconv.ParseBool(elideListResponsesRaw)
+		if err != nil {
+			return nil, err
+		}
+		elideListResponses = value
+	}
+
+	logRequestMetrics := false
+	if logRequestMetricsRaw, ok := conf.Config["log_request_metrics"]; ok {
+		value, err := strconv.ParseBool(logRequestMetricsRaw)
+		if err != nil {
+			return nil, err
+		}
+		logRequestMetrics = value
+	}
+
+	redactionRules, err := audit.ParseRedactionRules(conf.Config["redact"])
+	if err != nil {
+		return nil, err
+	}
+
+	sampler, err := audit.NewEntrySampler(conf.Config["sample_rate"], conf.Config["sample_operations"], conf.Config["sample_paths"])
+	if err != nil {
+		return nil, err
+	}
+
+	headers, err := audit.ParseHeaders(conf.Config["headers"])
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := audit.NewFormatterConfig(
+		audit.WithElision(elideListResponses),
+		audit.WithFormat(format),
+		audit.WithHMACAccessor(hmacAccessor),
+		audit.WithRaw(logRaw),
+		audit.WithRequestMetrics(logRequestMetrics),
+		audit.WithRedaction(redactionRules),
+		audit.WithExcludedResponsePaths(conf.Config["exclude_response_paths"]),
+		audit.WithSchemaVersion(conf.Config["schema_version"]),
+		audit.WithHeaders(headers),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Backend{
+		saltConfig:   conf.SaltConfig,
+		saltView:     conf.SaltView,
+		formatConfig: cfg,
+		sampler:      sampler,
+		partitionKey: partitionKey,
+	}
+
+	// Configure the formatter for either case.
+	f, err := audit.NewEntryFormatter(b.formatConfig, b)
+	if err != nil {
+		return nil, fmt.Errorf("error creating formatter: %w", err)
+	}
+	var w audit.Writer
+	switch format {
+	case "json":
+		w = &audit.JSONWriter{Prefix: conf.Config["prefix"]}
+	case "jsonx":
+		w = &audit.JSONxWriter{Prefix: conf.Config["prefix"]}
+	}
+
+	fw, err := audit.NewEntryFormatterWriter(b.formatConfig, f, w)
+	if err != nil {
+		return nil, fmt.Errorf("error creating formatter writer: %w", err)
+	}
+
+	b.formatter = fw
+
+	b.sink, err = event.NewKafkaSink(format, brokers, topic,
+		event.WithPartitionKeyFunc(eventPartitionKey),
+		event.WithMaxDuration(writeTimeout),
+		event.WithQueueSize(conf.Config["max_in_flight"]),
+		event.WithFailOpen(conf.Config["fail_open"]),
+		event.WithTLSEnabled(conf.Config["tls_enabled"]),
+		event.WithTLSCACert(conf.Config["tls_ca_cert"]),
+		event.WithTLSClientCert(conf.Config["tls_client_cert"]),
+		event.WithTLSClientKey(conf.Config["tls_client_key"]),
+		event.WithTLSServerName(conf.Config["tls_server_name"]),
+		event.WithSASLMechanism(conf.Config["sasl_mechanism"]),
+		event.WithSASLUsername(conf.Config["sasl_username"]),
+		event.WithSASLPassword(conf.Config["sasl_password"]),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// Backend is the audit backend producing entries to a kafka topic.
+type Backend struct {
+	sink         *event.KafkaSink
+	partitionKey string
+
+	formatter    *audit.EntryFormatterWriter
+	formatConfig audit.FormatterConfig
+	sampler      *audit.EntrySampler
+
+	saltMutex  sync.RWMutex
+	salt       *salt.Salt
+	saltConfig *salt.Config
+	saltView   logical.Storage
+}
+
+var (
+	_ audit.Backend       = (*Backend)(nil)
+	_ audit.Tailable      = (*Backend)(nil)
+	_ audit.Sampleable    = (*Backend)(nil)
+	_ audit.HeaderAuditor = (*Backend)(nil)
+)
+
+// TailFormatter returns the formatter of the backend, so that tailed entries
+// are hashed like the entries written by the backend.
+func (b *Backend) TailFormatter() audit.Formatter {
+	return b.formatter
+}
+
+// Sampler returns the sampler of the entries of the backend.
+func (b *Backend) Sampler() *audit.EntrySampler {
+	return b.sampler
+}
+
+// AuditedHeaders returns the request headers audited by the backend, or nil
+// when it audits the headers of the audited headers configuration.
+func (b *Backend) AuditedHeaders() map[string]bool {
+	return b.formatConfig.Headers
+}
+
+func (b *Backend) GetHash(ctx context.Context, data string) (string, error) {
+	salt, err := b.Salt(ctx)
+	if err != nil {
+		return "", err
+	}
+	return audit.HashString(salt, data), nil
+}
+
+func (b *Backend) LogRequest(ctx context.Context, in *logical.LogInput) error {
+	var buf bytes.Buffer
+	if err := b.formatter.FormatAndWriteRequest(ctx, &buf, in); err != nil {
+		return err
+	}
+
+	return b.write(ctx, b.key(ctx, in), buf.Bytes())
+}
+
+func (b *Backend) LogResponse(ctx context.Context, in *logical.LogInput) error {
+	var buf bytes.Buffer
+	if err := b.formatter.FormatAndWriteResponse(ctx, &buf, in); err != nil {
+		return err
+	}
+
+	return b.write(ctx, b.key(ctx, in), buf.Bytes())
+}
+
+func (b *Backend) LogTestMessage(ctx context.Context, in *logical.LogInput, config map[string]string) error {
+	var buf bytes.Buffer
+
+	temporaryFormatter, err := audit.NewTemporaryFormatter(config["format"], config["prefix"])
+	if err != nil {
+		return err
+	}
+
+	if err = temporaryFormatter.FormatAndWriteRequest(ctx, &buf, in); err != nil {
+		return err
+	}
+
+	return b.write(ctx, b.key(ctx, in), buf.Bytes())
+}
+
+// key returns the partition key of the entry of the request.
+func (b *Backend) key(ctx context.Context, in *logical.LogInput) string {
+	if b.partitionKey == partitionKeyNone {
+		return ""
+	}
+
+	var nsPath string
+	if ns, err := namespace.FromContext(ctx); err == nil {
+		nsPath = ns.Path
+	}
+
+	switch {
+	case b.partitionKey == partitionKeyNamespace:
+		// The root namespace has an empty path.
+		if nsPath == "" {
+			return namespace.RootNamespaceID
+		}
+		return nsPath
+	case in == nil || in.Request == nil:
+		return ""
+	default:
+		return nsPath + in.Request.MountPoint
+	}
+}
+
+// write hands the entry to the kafka sink, with its partition key as the
+// payload of the event.
+func (b *Backend) write(ctx context.Context, key string, buf []byte) error {
+	e := &eventlogger.Event{
+		Type:      eventlogger.EventType(event.AuditType),
+		CreatedAt: time.Now(),
+		Formatted: make(map[string][]byte),
+		Payload:   key,
+	}
+	e.FormattedAs(b.formatConfig.RequiredFormat.String(), buf)
+
+	_, err := b.sink.Process(ctx, e)
+	return err
+}
+
+// eventPartitionKey returns the partition key carried by the payload of the
+// event.
+func eventPartitionKey(e *eventlogger.Event) string {
+	key, _ := e.Payload.(string)
+	return key
+}
+
+// Reload is a no-op for the kafka backend, whose sink reconnects to the
+// brokers on its own.
+func (b *Backend) Reload(_ context.Context) error {
+	return nil
+}
+
+func (b *Backend) Salt(ctx context.Context) (*salt.Salt, error) {
+	b.saltMutex.RLock()
+	if b.salt != nil {
+		defer b.saltMutex.RUnlock()
+		return b.salt, nil
+	}
+	b.saltMutex.RUnlock()
+	b.saltMutex.Lock()
+	defer b.saltMutex.Unlock()
+	if b.salt != nil {
+		return b.salt, nil
+	}
+	salt, err := salt.NewSalt(ctx, b.saltView, b.saltConfig)
+	if err != nil {
+		return nil, err
+	}
+	b.salt = salt
+	return salt, nil
+}
+
+func (b *Backend) Invalidate(_ context.Context) {
+	b.saltMutex.Lock()
+	defer b.saltMutex.Unlock()
+	b.salt = nil
+}
@@ -0,0 +1,88 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/vault/audit"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/helper/salt"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestAuditKafka_config(t *testing.T) {
+	cases := map[string]struct {
+		config map[string]string
+		err    bool
+	}{
+		"valid": {
+			config: map[string]string{"brokers": "kafka:9092", "topic": "audit"},
+		},
+		"missing brokers": {
+			config: map[string]string{"topic": "audit"},
+			err:    true,
+		},
+		"missing topic": {
+			config: map[string]string{"brokers": "kafka:9092"},
+			err:    true,
+		},
+		"unknown partition key": {
+			config: map[string]string{"brokers": "kafka:9092", "topic": "audit", "partition_key": "path"},
+			err:    true,
+		},
+		"bad fail open": {
+			config: map[string]string{"brokers": "kafka:9092", "topic": "audit", "fail_open": "maybe"},
+			err:    true,
+		},
+		"unsupported SASL mechanism": {
+			config: map[string]string{"brokers": "kafka:9092", "topic": "audit", "sasl_mechanism": "gssapi"},
+			err:    true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Factory(context.Background(), &audit.BackendConfig{
+				SaltConfig: &salt.Config{},
+				SaltView:   &logical.InmemStorage{},
+				Config:     tc.config,
+			}, false)
+			if tc.err && err == nil {
+				t.Fatal("expected an error")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestAuditKafka_partitionKey(t *testing.T) {
+	in := &logical.LogInput{
+		Request: &logical.Request{MountPoint: "secret/"},
+	}
+	rootCtx := namespace.RootContext(context.Background())
+	nsCtx := namespace.ContextWithNamespace(context.Background(), &namespace.Namespace{ID: "abc12", Path: "ns1/"})
+
+	cases := []struct {
+		partitionKey string
+		ctx          context.Context
+		expected     string
+	}{
+		{partitionKeyMountPath, rootCtx, "secret/"},
+		{partitionKeyMountPath, nsCtx, "ns1/secret/"},
+		{partitionKeyNamespace, rootCtx, "root"},
+		{partitionKeyNamespace, nsCtx, "ns1/"},
+		{partitionKeyNone, nsCtx, ""},
+	}
+
+	for _, tc := range cases {
+		b := &Backend{partitionKey: tc.partitionKey}
+		if key := b.key(tc.ctx, in); key != tc.expected {
+			t.Fatalf("expected partition key %q with %q, got %q", tc.expected, tc.partitionKey, key)
+		}
+	}
+}
@@ -12,9 +12,11 @@ import (
 	"sync"
 	"time"
 
+	"github.com/hashicorp/eventlogger"
 	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/go-secure-stdlib/parseutil"
 	"github.com/hashicorp/vault/audit"
+	"github.com/hashicorp/vault/internal/observability/event"
 	"github.com/hashicorp/vault/sdk/helper/salt"
 	"github.com/hashicorp/vault/sdk/logical"
 )
@@ -85,11 +87,40 @@ func Factory(ctx context.Context, conf *audit.BackendConfig, useEventLogger bool
 		elideListResponses = value
 	}
 
+	logRequestMetrics := false
+	if logRequestMetricsRaw, ok := conf.Config["log_request_metrics"]; ok {
+		value, err := strconv.ParseBool(logRequestMetricsRaw)
+		if err != nil {
+			return nil, err
+		}
+		logRequestMetrics = value
+	}
+
+	redactionRules, err := audit.ParseRedactionRules(conf.Config["redact"])
+	if err != nil {
+		return nil, err
+	}
+
+	sampler, err := audit.NewEntrySampler(conf.Config["sample_rate"], conf.Config["sample_operations"], conf.Config["sample_paths"])
+	if err != nil {
+		return nil, err
+	}
+
+	headers, err := audit.ParseHeaders(conf.Config["headers"])
+	if err != nil {
+		return nil, err
+	}
+
 	cfg, err := audit.NewFormatterConfig(
 		audit.WithElision(elideListResponses),
 		audit.WithFormat(format),
 		audit.WithHMACAccessor(hmacAccessor),
 		audit.WithRaw(logRaw),
+		audit.WithRequestMetrics(logRequestMetrics),
+		audit.WithRedaction(redactionRules),
+		audit.WithExcludedResponsePaths(conf.Config["exclude_response_paths"]),
+		audit.WithSchemaVersion(conf.Config["schema_version"]),
+		audit.WithHeaders(headers),
 	)
 	if err != nil {
 		return nil, err
@@ -99,6 +130,7 @@ func Factory(ctx context.Context, conf *audit.BackendConfig, useEventLogger bool
 		saltConfig:   conf.SaltConfig,
 		saltView:     conf.SaltView,
 		formatConfig: cfg,
+		sampler:      sampler,
 
 		writeDuration: writeDuration,
 		address:       address,
@@ -125,6 +157,36 @@ func Factory(ctx context.Context, conf *audit.BackendConfig, useEventLogger bool
 
 	b.formatter = fw
 
+	switch socketType {
+	case "tls":
+		// Entries are sent over TLS through the socket sink, which frames
+		// them as RFC 5425 does for syslog.
+		b.sink, err = event.NewSocketSink(format, address,
+			event.WithSocketType(socketType),
+			event.WithMaxDuration(writeDeadline),
+			event.WithTLSCACert(conf.Config["tls_ca_cert"]),
+			event.WithTLSClientCert(conf.Config["tls_client_cert"]),
+			event.WithTLSClientKey(conf.Config["tls_client_key"]),
+			event.WithTLSServerName(conf.Config["tls_server_name"]),
+		)
+		if err != nil {
+			return nil, err
+		}
+	case "unix", "unixgram", "unixpacket":
+		// Entries are sent over UNIX sockets through the socket sink, which
+		// creates the socket file of the local address.
+		b.sink, err = event.NewSocketSink(format, address,
+			event.WithSocketType(socketType),
+			event.WithMaxDuration(writeDeadline),
+			event.WithSocketLocalAddress(conf.Config["local_address"]),
+			event.WithFileMode(conf.Config["socket_mode"]),
+			event.WithSocketOwner(conf.Config["socket_owner"]),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return b, nil
 }
 
@@ -134,11 +196,16 @@ type Backend struct {
 
 	formatter    *audit.EntryFormatterWriter
 	formatConfig audit.FormatterConfig
+	sampler      *audit.EntrySampler
 
 	writeDuration time.Duration
 	address       string
 	socketType    string
 
+	// sink writes the entries when the socket type is "tls", or one of the
+	// UNIX socket types.
+	sink *event.SocketSink
+
 	sync.Mutex
 
 	saltMutex  sync.RWMutex
@@ -147,60 +214,80 @@ type Backend struct {
 	saltView   logical.Storage
 }
 
-var _ audit.Backend = (*Backend)(nil)
+var (
+	_ audit.Backend       = (*Backend)(nil)
+	_ audit.Tailable      = (*Backend)(nil)
+	_ audit.Sampleable    = (*Backend)(nil)
+	_ audit.HeaderAuditor = (*Backend)(nil)
+	_ audit.Shippable     = (*Backend)(nil)
+)
 
-func (b *Backend) GetHash(ctx context.Context, data string) (string, error) {
-	salt, err := b.Salt(ctx)
-	if err != nil {
-		return "", err
-	}
-	return audit.HashString(salt, data), nil
+// TailFormatter returns the formatter of the backend, so that tailed entries
+// are hashed like the entries written by the backend.
+func (b *Backend) TailFormatter() audit.Formatter {
+	return b.formatter
 }
 
-func (b *Backend) LogRequest(ctx context.Context, in *logical.LogInput) error {
-	var buf bytes.Buffer
-	if err := b.formatter.FormatAndWriteRequest(ctx, &buf, in); err != nil {
-		return err
-	}
+// Sampler returns the sampler of the entries of the backend.
+func (b *Backend) Sampler() *audit.EntrySampler {
+	return b.sampler
+}
+
+// AuditedHeaders returns the request headers audited by the backend, or nil
+// when it audits the headers of the audited headers configuration.
+func (b *Backend) AuditedHeaders() map[string]bool {
+	return b.formatConfig.Headers
+}
 
+// ShipFormatter returns the formatter of the entries of the backend.
+func (b *Backend) ShipFormatter() *audit.EntryFormatterWriter {
+	return b.formatter
+}
+
+// Ship writes a formatted entry to the socket, reconnecting once if the write
+// fails.
+func (b *Backend) Ship(ctx context.Context, entry []byte) error {
 	b.Lock()
 	defer b.Unlock()
 
-	err := b.write(ctx, buf.Bytes())
+	err := b.write(ctx, entry)
 	if err != nil {
 		rErr := b.reconnect(ctx)
 		if rErr != nil {
 			err = multierror.Append(err, rErr)
 		} else {
 			// Try once more after reconnecting
-			err = b.write(ctx, buf.Bytes())
+			err = b.write(ctx, entry)
 		}
 	}
 
 	return err
 }
 
-func (b *Backend) LogResponse(ctx context.Context, in *logical.LogInput) error {
+func (b *Backend) GetHash(ctx context.Context, data string) (string, error) {
+	salt, err := b.Salt(ctx)
+	if err != nil {
+		return "", err
+	}
+	return audit.HashString(salt, data), nil
+}
+
+func (b *Backend) LogRequest(ctx context.Context, in *logical.LogInput) error {
 	var buf bytes.Buffer
-	if err := b.formatter.FormatAndWriteResponse(ctx, &buf, in); err != nil {
+	if err := b.formatter.FormatAndWriteRequest(ctx, &buf, in); err != nil {
 		return err
 	}
 
-	b.Lock()
-	defer b.Unlock()
+	return b.Ship(ctx, buf.Bytes())
+}
 
-	err := b.write(ctx, buf.Bytes())
-	if err != nil {
-		rErr := b.reconnect(ctx)
-		if rErr != nil {
-			err = multierror.Append(err, rErr)
-		} else {
-			// Try once more after reconnecting
-			err = b.write(ctx, buf.Bytes())
-		}
+func (b *Backend) LogResponse(ctx context.Context, in *logical.LogInput) error {
+	var buf bytes.Buffer
+	if err := b.formatter.FormatAndWriteResponse(ctx, &buf, in); err != nil {
+		return err
 	}
 
-	return err
+	return b.Ship(ctx, buf.Bytes())
 }
 
 func (b *Backend) LogTestMessage(ctx context.Context, in *logical.LogInput, config map[string]string) error {
@@ -233,6 +320,10 @@ func (b *Backend) LogTestMessage(ctx context.Context, in *logical.LogInput, conf
 }
 
 func (b *Backend) write(ctx context.Context, buf []byte) error {
+	if b.sink != nil {
+		return b.writeSink(ctx, buf)
+	}
+
 	if b.connection == nil {
 		if err := b.reconnect(ctx); err != nil {
 			return err
@@ -252,7 +343,25 @@ func (b *Backend) write(ctx context.Context, buf []byte) error {
 	return nil
 }
 
+// writeSink hands the entry to the socket sink, which reconnects and retries
+// on its own.
+func (b *Backend) writeSink(ctx context.Context, buf []byte) error {
+	e := &eventlogger.Event{
+		Type:      eventlogger.EventType(event.AuditType),
+		CreatedAt: time.Now(),
+		Formatted: make(map[string][]byte),
+	}
+	e.FormattedAs(b.formatConfig.RequiredFormat.String(), buf)
+
+	_, err := b.sink.Process(ctx, e)
+	return err
+}
+
 func (b *Backend) reconnect(ctx context.Context) error {
+	if b.sink != nil {
+		return b.sink.Reopen()
+	}
+
 	if b.connection != nil {
 		b.connection.Close()
 		b.connection = nil
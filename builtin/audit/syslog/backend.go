@@ -75,17 +75,46 @@ func Factory(ctx context.Context, conf *audit.BackendConfig, useEventLogger bool
 		elideListResponses = value
 	}
 
+	logRequestMetrics := false
+	if logRequestMetricsRaw, ok := conf.Config["log_request_metrics"]; ok {
+		value, err := strconv.ParseBool(logRequestMetricsRaw)
+		if err != nil {
+			return nil, err
+		}
+		logRequestMetrics = value
+	}
+
 	// Get the logger
 	logger, err := gsyslog.NewLogger(gsyslog.LOG_INFO, facility, tag)
 	if err != nil {
 		return nil, err
 	}
 
+	redactionRules, err := audit.ParseRedactionRules(conf.Config["redact"])
+	if err != nil {
+		return nil, err
+	}
+
+	sampler, err := audit.NewEntrySampler(conf.Config["sample_rate"], conf.Config["sample_operations"], conf.Config["sample_paths"])
+	if err != nil {
+		return nil, err
+	}
+
+	headers, err := audit.ParseHeaders(conf.Config["headers"])
+	if err != nil {
+		return nil, err
+	}
+
 	cfg, err := audit.NewFormatterConfig(
 		audit.WithElision(elideListResponses),
 		audit.WithFormat(format),
 		audit.WithHMACAccessor(hmacAccessor),
 		audit.WithRaw(logRaw),
+		audit.WithRequestMetrics(logRequestMetrics),
+		audit.WithRedaction(redactionRules),
+		audit.WithExcludedResponsePaths(conf.Config["exclude_response_paths"]),
+		audit.WithSchemaVersion(conf.Config["schema_version"]),
+		audit.WithHeaders(headers),
 	)
 	if err != nil {
 		return nil, err
@@ -96,6 +125,7 @@ func Factory(ctx context.Context, conf *audit.BackendConfig, useEventLogger bool
 		saltConfig:   conf.SaltConfig,
 		saltView:     conf.SaltView,
 		formatConfig: cfg,
+		sampler:      sampler,
 	}
 
 	// Configure the formatter for either case.
@@ -128,6 +158,7 @@ type Backend struct {
 
 	formatter    *audit.EntryFormatterWriter
 	formatConfig audit.FormatterConfig
+	sampler      *audit.EntrySampler
 
 	saltMutex  sync.RWMutex
 	salt       *salt.Salt
@@ -135,7 +166,30 @@ type Backend struct {
 	saltView   logical.Storage
 }
 
-var _ audit.Backend = (*Backend)(nil)
+var (
+	_ audit.Backend       = (*Backend)(nil)
+	_ audit.Tailable      = (*Backend)(nil)
+	_ audit.Sampleable    = (*Backend)(nil)
+	_ audit.HeaderAuditor = (*Backend)(nil)
+	_ audit.Shippable     = (*Backend)(nil)
+)
+
+// TailFormatter returns the formatter of the backend, so that tailed entries
+// are hashed like the entries written by the backend.
+func (b *Backend) TailFormatter() audit.Formatter {
+	return b.formatter
+}
+
+// Sampler returns the sampler of the entries of the backend.
+func (b *Backend) Sampler() *audit.EntrySampler {
+	return b.sampler
+}
+
+// AuditedHeaders returns the request headers audited by the backend, or nil
+// when it audits the headers of the audited headers configuration.
+func (b *Backend) AuditedHeaders() map[string]bool {
+	return b.formatConfig.Headers
+}
 
 func (b *Backend) GetHash(ctx context.Context, data string) (string, error) {
 	salt, err := b.Salt(ctx)
@@ -145,6 +199,17 @@ func (b *Backend) GetHash(ctx context.Context, data string) (string, error) {
 	return audit.HashString(salt, data), nil
 }
 
+// ShipFormatter returns the formatter of the entries of the backend.
+func (b *Backend) ShipFormatter() *audit.EntryFormatterWriter {
+	return b.formatter
+}
+
+// Ship writes a formatted entry to syslog.
+func (b *Backend) Ship(_ context.Context, entry []byte) error {
+	_, err := b.logger.Write(entry)
+	return err
+}
+
 func (b *Backend) LogRequest(ctx context.Context, in *logical.LogInput) error {
 	var buf bytes.Buffer
 	if err := b.formatter.FormatAndWriteRequest(ctx, &buf, in); err != nil {
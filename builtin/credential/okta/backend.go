@@ -52,6 +52,7 @@ func Backend() *backend {
 			pathGroups(&b),
 			pathUsersList(&b),
 			pathGroupsList(&b),
+			pathDirectoryGroups(&b),
 			pathLogin(&b),
 			pathVerify(&b),
 		},
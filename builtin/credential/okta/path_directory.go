@@ -0,0 +1,101 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package okta
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/okta/okta-sdk-golang/v2/okta"
+	"github.com/okta/okta-sdk-golang/v2/okta/query"
+)
+
+// directoryGroupsPageSize is the number of groups requested from Okta per
+// page when listing the group directory.
+const directoryGroupsPageSize = 200
+
+func pathDirectoryGroups(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "directory/groups/?$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixOkta,
+			OperationVerb:   "list",
+			OperationSuffix: "directory-groups",
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ListOperation: b.pathDirectoryGroupsList,
+		},
+
+		HelpSynopsis:    pathDirectoryGroupsHelpSyn,
+		HelpDescription: pathDirectoryGroupsHelpDesc,
+	}
+}
+
+func (b *backend) pathDirectoryGroupsList(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	cfg, err := b.Config(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return logical.ErrorResponse("Okta auth method not configured"), nil
+	}
+
+	shim, err := cfg.OktaClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	client, oktactx := shim.Client()
+	if client == nil {
+		return logical.ErrorResponse("an API token must be configured to list Okta groups"), nil
+	}
+
+	groups, err := b.getOktaDirectoryGroups(oktactx, client)
+	if err != nil {
+		return logical.ErrorResponse(fmt.Sprintf("okta failure retrieving groups: %v", err)), nil
+	}
+
+	return logical.ListResponse(groups), nil
+}
+
+// getOktaDirectoryGroups returns the names of all groups in the Okta
+// organization, following the API's pagination.
+func (b *backend) getOktaDirectoryGroups(ctx context.Context, client *okta.Client) ([]string, error) {
+	groups, resp, err := client.Group.ListGroups(ctx, &query.Params{Limit: directoryGroupsPageSize})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(groups))
+	for _, group := range groups {
+		names = append(names, group.Profile.Name)
+	}
+	for resp.HasNextPage() {
+		var nextGroups []*okta.Group
+		resp, err = resp.Next(ctx, &nextGroups)
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range nextGroups {
+			names = append(names, group.Profile.Name)
+		}
+	}
+	if b.Logger().IsDebug() {
+		b.Logger().Debug("directory groups fetched from Okta", "num_groups", len(names))
+	}
+	return names, nil
+}
+
+const pathDirectoryGroupsHelpSyn = `
+List the groups in the Okta organization.
+`
+
+const pathDirectoryGroupsHelpDesc = `
+This endpoint lists the names of all groups in the configured Okta
+organization, and requires an API token to be configured. It is used by the
+identity store's external group sync to create external groups and group
+aliases for Okta groups before any of their members have logged in.
+`
@@ -0,0 +1,75 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package accounts
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const operationPrefixAccounts = "accounts"
+
+// Factory creates and configures the backend
+func Factory(ctx context.Context, conf *logical.BackendConfig) (logical.Backend, error) {
+	b := Backend()
+	if err := b.Setup(ctx, conf); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Backend creates a new backend with all the paths and secrets belonging to it
+func Backend() *backend {
+	b := &backend{
+		locks: locksutil.CreateLocks(),
+	}
+	b.Backend = &framework.Backend{
+		Help: strings.TrimSpace(backendHelp),
+
+		PathsSpecial: &logical.Paths{
+			SealWrapStorage: []string{
+				accountPrefix,
+			},
+		},
+
+		Paths: []*framework.Path{
+			pathListAccounts(b),
+			pathAccounts(b),
+			pathCheckOut(b),
+			pathCheckIn(b),
+			pathForcedCheckIn(b),
+			pathStatus(b),
+			pathHistory(b),
+		},
+
+		Secrets: []*framework.Secret{
+			secretCheckout(b),
+		},
+
+		BackendType: logical.TypeLogical,
+	}
+
+	return b
+}
+
+type backend struct {
+	*framework.Backend
+
+	// locks serialize the check-outs and check-ins of each account.
+	locks []*locksutil.LockEntry
+}
+
+const backendHelp = `
+The accounts backend manages the credentials of shared static accounts,
+such as privileged accounts, which are checked out exclusively.
+
+After creating an account with the "accounts/" endpoints, a client checks
+it out to get its credentials, which no other client can check out until
+they are checked back in, or the lease of the check-out expires. Accounts
+may be configured with a rotator which changes their password on check-in.
+`
@@ -0,0 +1,203 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package accounts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func getBackend(t *testing.T) (*backend, logical.Storage) {
+	t.Helper()
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+
+	b := Backend()
+	require.NoError(t, b.Setup(context.Background(), config))
+	return b, config.StorageView
+}
+
+// testRotator records the passwords it rotates, and fails while failing is
+// set.
+type testRotator struct {
+	passwords []string
+	failing   bool
+}
+
+func (r *testRotator) Rotate(_ context.Context, _, password string) error {
+	if r.failing {
+		return fmt.Errorf("target unreachable")
+	}
+	r.passwords = append(r.passwords, password)
+	return nil
+}
+
+func request(s logical.Storage, op logical.Operation, path, entityID string, data map[string]interface{}) *logical.Request {
+	return &logical.Request{
+		Operation:           op,
+		Path:                path,
+		Storage:             s,
+		Data:                data,
+		EntityID:            entityID,
+		DisplayName:         "userpass-" + entityID,
+		ClientTokenAccessor: "accessor-" + entityID,
+	}
+}
+
+func TestBackend_CheckOutCheckIn(t *testing.T) {
+	b, s := getBackend(t)
+	ctx := context.Background()
+
+	resp, err := b.HandleRequest(ctx, request(s, logical.CreateOperation, "accounts/admin", "", map[string]interface{}{
+		"username": "admin",
+		"password": "initial",
+		"ttl":      "1h",
+		"max_ttl":  "4h",
+	}))
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	resp, err = b.HandleRequest(ctx, request(s, logical.ReadOperation, "accounts/admin", "", nil))
+	require.NoError(t, err)
+	require.Equal(t, "admin", resp.Data["username"])
+	require.NotContains(t, resp.Data, "password")
+
+	// The account is checked out exclusively.
+	resp, err = b.HandleRequest(ctx, request(s, logical.UpdateOperation, "accounts/admin/check-out", "alice", map[string]interface{}{
+		"ttl": "2h",
+	}))
+	require.NoError(t, err)
+	require.False(t, resp.IsError(), resp.Error())
+	require.Equal(t, "initial", resp.Data["password"])
+	require.Equal(t, "1h0m0s", resp.Secret.TTL.String())
+	secret := resp.Secret
+
+	resp, err = b.HandleRequest(ctx, request(s, logical.UpdateOperation, "accounts/admin/check-out", "bob", nil))
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+
+	resp, err = b.HandleRequest(ctx, request(s, logical.ReadOperation, "accounts/admin/status", "", nil))
+	require.NoError(t, err)
+	require.Equal(t, false, resp.Data["available"])
+	require.Equal(t, "alice", resp.Data["borrower_entity_id"])
+
+	// Only the borrower checks the account in.
+	_, err = b.HandleRequest(ctx, request(s, logical.UpdateOperation, "accounts/admin/check-in", "bob", nil))
+	require.ErrorIs(t, err, logical.ErrPermissionDenied)
+
+	resp, err = b.HandleRequest(ctx, request(s, logical.UpdateOperation, "accounts/admin/check-in", "alice", nil))
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	// The lease of a check-out which was checked in is ignored.
+	_, err = b.HandleRequest(ctx, request(s, logical.UpdateOperation, "accounts/admin/check-out", "bob", nil))
+	require.NoError(t, err)
+	revoke := request(s, logical.RevokeOperation, "", "", nil)
+	revoke.Secret = secret
+	_, err = b.HandleRequest(ctx, revoke)
+	require.NoError(t, err)
+
+	resp, err = b.HandleRequest(ctx, request(s, logical.ReadOperation, "accounts/admin/status", "", nil))
+	require.NoError(t, err)
+	require.Equal(t, "bob", resp.Data["borrower_entity_id"])
+
+	// Accounts checked out can't be deleted.
+	resp, err = b.HandleRequest(ctx, request(s, logical.DeleteOperation, "accounts/admin", "", nil))
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+
+	resp, err = b.HandleRequest(ctx, request(s, logical.ReadOperation, "accounts/admin/history", "", nil))
+	require.NoError(t, err)
+	events := resp.Data["events"].([]map[string]interface{})
+	require.Len(t, events, 3)
+	require.Equal(t, eventCheckOut, events[0]["action"])
+	require.Equal(t, "alice", events[0]["entity_id"])
+	require.Equal(t, eventCheckIn, events[1]["action"])
+	require.Equal(t, eventCheckOut, events[2]["action"])
+	require.Equal(t, "bob", events[2]["entity_id"])
+}
+
+func TestBackend_Rotation(t *testing.T) {
+	rotator := &testRotator{}
+	RegisterRotator("test", func(map[string]string) (Rotator, error) {
+		return rotator, nil
+	})
+
+	b, s := getBackend(t)
+	ctx := context.Background()
+
+	resp, err := b.HandleRequest(ctx, request(s, logical.CreateOperation, "accounts/admin", "", map[string]interface{}{
+		"username": "admin",
+		"password": "initial",
+		"rotator":  "test",
+	}))
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	resp, err = b.HandleRequest(ctx, request(s, logical.UpdateOperation, "accounts/admin/check-out", "alice", nil))
+	require.NoError(t, err)
+	secret := resp.Secret
+
+	// The account stays checked out when its password can't be rotated.
+	rotator.failing = true
+	_, err = b.HandleRequest(ctx, request(s, logical.UpdateOperation, "manage/admin/check-in", "operator", nil))
+	require.Error(t, err)
+
+	resp, err = b.HandleRequest(ctx, request(s, logical.ReadOperation, "accounts/admin/status", "", nil))
+	require.NoError(t, err)
+	require.Equal(t, false, resp.Data["available"])
+
+	// The check-out ends with its lease.
+	rotator.failing = false
+	revoke := request(s, logical.RevokeOperation, "", "", nil)
+	revoke.Secret = secret
+	_, err = b.HandleRequest(ctx, revoke)
+	require.NoError(t, err)
+	require.Len(t, rotator.passwords, 1)
+
+	resp, err = b.HandleRequest(ctx, request(s, logical.UpdateOperation, "accounts/admin/check-out", "bob", nil))
+	require.NoError(t, err)
+	require.Equal(t, rotator.passwords[0], resp.Data["password"])
+
+	resp, err = b.HandleRequest(ctx, request(s, logical.ReadOperation, "accounts/admin/history", "", nil))
+	require.NoError(t, err)
+	events := resp.Data["events"].([]map[string]interface{})
+	require.Len(t, events, 4)
+	require.Equal(t, eventRotationFailed, events[1]["action"])
+	require.Equal(t, "target unreachable", events[1]["error"])
+	require.Equal(t, "userpass-operator", events[1]["forced_by"])
+	require.Equal(t, eventLeaseEnded, events[2]["action"])
+	require.Equal(t, "alice", events[2]["entity_id"])
+}
+
+func TestWebhookRotator(t *testing.T) {
+	var received map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	_, err := newRotator("webhook", map[string]string{"url": "ftp://example.com"})
+	require.Error(t, err)
+
+	rotator, err := newRotator("webhook", map[string]string{"url": server.URL})
+	require.NoError(t, err)
+	require.EqualError(t, rotator.Rotate(context.Background(), "admin", "secret"), "rotation webhook responded with status 401")
+
+	rotator, err = newRotator("webhook", map[string]string{"url": server.URL, "authorization": "Bearer token"})
+	require.NoError(t, err)
+	require.NoError(t, rotator.Rotate(context.Background(), "admin", "secret"))
+	require.Equal(t, map[string]string{"username": "admin", "password": "secret"}, received)
+}
@@ -0,0 +1,177 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package accounts
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// maxHistoryEvents is the number of events kept in the history of an account,
+// the oldest of which are dropped.
+const maxHistoryEvents = 100
+
+// The actions recorded in the history of accounts.
+const (
+	eventCheckOut       = "check-out"
+	eventCheckIn        = "check-in"
+	eventForcedCheckIn  = "forced-check-in"
+	eventLeaseEnded     = "lease-ended"
+	eventRotationFailed = "rotation-failed"
+)
+
+// historyEvent records the check-out or check-in of an account, and the
+// client which held it.
+type historyEvent struct {
+	Time                time.Time `json:"time"`
+	Action              string    `json:"action"`
+	EntityID            string    `json:"entity_id"`
+	DisplayName         string    `json:"display_name"`
+	ClientTokenAccessor string    `json:"client_token_accessor"`
+
+	// ForcedBy is the display name of the client which checked the account
+	// in forcibly.
+	ForcedBy string `json:"forced_by,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// event returns an event of the given action, for the client holding the
+// check-out.
+func (co *checkout) event(action string) *historyEvent {
+	return &historyEvent{
+		Time:                time.Now().UTC(),
+		Action:              action,
+		EntityID:            co.EntityID,
+		DisplayName:         co.DisplayName,
+		ClientTokenAccessor: co.ClientTokenAccessor,
+	}
+}
+
+// readHistory reads the history of the named account from the storage, from
+// the oldest event to the newest.
+func readHistory(ctx context.Context, s logical.Storage, name string) ([]*historyEvent, error) {
+	entry, err := s.Get(ctx, historyPrefix+name)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var events []*historyEvent
+	if err := entry.DecodeJSON(&events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// recordEvent appends the event to the history of the named account. The lock
+// of the account must be held.
+func recordEvent(ctx context.Context, s logical.Storage, name string, event *historyEvent) error {
+	events, err := readHistory(ctx, s, name)
+	if err != nil {
+		return err
+	}
+	events = append(events, event)
+	if len(events) > maxHistoryEvents {
+		events = events[len(events)-maxHistoryEvents:]
+	}
+
+	entry, err := logical.StorageEntryJSON(historyPrefix+name, events)
+	if err != nil {
+		return err
+	}
+	return s.Put(ctx, entry)
+}
+
+func pathHistory(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "accounts/" + framework.GenericNameRegex("name") + "/history$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixAccounts,
+			OperationVerb:   "read",
+			OperationSuffix: "history",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the account.",
+				Required:    true,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathHistoryRead,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields: map[string]*framework.FieldSchema{
+							"events": {Type: framework.TypeSlice, Required: true},
+						},
+					}},
+				},
+			},
+		},
+
+		HelpSynopsis:    pathHistoryHelpSyn,
+		HelpDescription: pathHistoryHelpDesc,
+	}
+}
+
+func (b *backend) pathHistoryRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+	acct, err := readAccount(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if acct == nil {
+		return nil, nil
+	}
+
+	events, err := readHistory(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]map[string]interface{}, 0, len(events))
+	for _, event := range events {
+		e := map[string]interface{}{
+			"time":                  event.Time,
+			"action":                event.Action,
+			"entity_id":             event.EntityID,
+			"display_name":          event.DisplayName,
+			"client_token_accessor": event.ClientTokenAccessor,
+		}
+		if event.ForcedBy != "" {
+			e["forced_by"] = event.ForcedBy
+		}
+		if event.Error != "" {
+			e["error"] = event.Error
+		}
+		data = append(data, e)
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"events": data,
+		},
+	}, nil
+}
+
+const pathHistoryHelpSyn = `
+Report who held an account, and when.
+`
+
+const pathHistoryHelpDesc = `
+This path reports the last check-outs and check-ins of an account, from the
+oldest to the newest, with the client which held the account, and the
+failures to rotate its password.
+`
@@ -0,0 +1,359 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package accounts
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	accountPrefix  = "account/"
+	checkoutPrefix = "checkout/"
+	historyPrefix  = "history/"
+)
+
+// account is a shared static account.
+type account struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+
+	// TTL is the default and MaxTTL the longest duration of check-outs.
+	TTL    time.Duration `json:"ttl"`
+	MaxTTL time.Duration `json:"max_ttl"`
+
+	// DisableCheckInEnforcement allows any client to check the account in,
+	// rather than only the client which checked it out.
+	DisableCheckInEnforcement bool `json:"disable_check_in_enforcement"`
+
+	// Rotator is the name of the rotator changing the password of the
+	// account on check-in, configured with RotatorConfig. The password isn't
+	// rotated when it is empty.
+	Rotator        string            `json:"rotator"`
+	RotatorConfig  map[string]string `json:"rotator_config"`
+	PasswordPolicy string            `json:"password_policy"`
+
+	LastRotated time.Time `json:"last_rotated"`
+}
+
+func pathListAccounts(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "accounts/?$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixAccounts,
+			OperationSuffix: "accounts",
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ListOperation: &framework.PathOperation{
+				Callback: b.pathAccountList,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields: map[string]*framework.FieldSchema{
+							"keys": {
+								Type:     framework.TypeStringSlice,
+								Required: true,
+							},
+						},
+					}},
+				},
+			},
+		},
+
+		HelpSynopsis:    pathAccountsHelpSyn,
+		HelpDescription: pathAccountsHelpDesc,
+	}
+}
+
+func pathAccounts(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "accounts/" + framework.GenericNameRegex("name") + "$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixAccounts,
+			OperationSuffix: "account",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the account.",
+				Required:    true,
+			},
+			"username": {
+				Type:        framework.TypeString,
+				Description: "Username of the account.",
+			},
+			"password": {
+				Type:        framework.TypeString,
+				Description: "Password of the account. Once the account is created, it may only be set while the account is checked in.",
+				DisplayAttrs: &framework.DisplayAttributes{
+					Sensitive: true,
+				},
+			},
+			"ttl": {
+				Type:        framework.TypeDurationSecond,
+				Description: "Default duration of check-outs. Defaults to the default lease TTL of the mount.",
+			},
+			"max_ttl": {
+				Type:        framework.TypeDurationSecond,
+				Description: "Longest duration of check-outs, including renewals. Defaults to the max lease TTL of the mount.",
+			},
+			"disable_check_in_enforcement": {
+				Type:        framework.TypeBool,
+				Description: "Allow any client to check the account in, rather than only the client which checked it out.",
+			},
+			"rotator": {
+				Type:        framework.TypeString,
+				Description: "Name of the rotator changing the password of the account on check-in. If empty, the password isn't rotated.",
+			},
+			"rotator_config": {
+				Type:        framework.TypeKVPairs,
+				Description: "Configuration of the rotator.",
+			},
+			"password_policy": {
+				Type:        framework.TypeString,
+				Description: "Name of the password policy to generate rotated passwords with.",
+			},
+		},
+
+		ExistenceCheck: b.pathAccountExistenceCheck,
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.CreateOperation: &framework.PathOperation{
+				Callback: b.pathAccountWrite,
+				Responses: map[int][]framework.Response{
+					http.StatusNoContent: {{
+						Description: "No Content",
+					}},
+				},
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathAccountWrite,
+				Responses: map[int][]framework.Response{
+					http.StatusNoContent: {{
+						Description: "No Content",
+					}},
+				},
+			},
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathAccountRead,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields: map[string]*framework.FieldSchema{
+							"username":                     {Type: framework.TypeString},
+							"ttl":                          {Type: framework.TypeDurationSecond},
+							"max_ttl":                      {Type: framework.TypeDurationSecond},
+							"disable_check_in_enforcement": {Type: framework.TypeBool},
+							"rotator":                      {Type: framework.TypeString},
+							"rotator_config":               {Type: framework.TypeKVPairs},
+							"password_policy":              {Type: framework.TypeString},
+							"last_rotated":                 {Type: framework.TypeTime},
+						},
+					}},
+				},
+			},
+			logical.DeleteOperation: &framework.PathOperation{
+				Callback: b.pathAccountDelete,
+				Responses: map[int][]framework.Response{
+					http.StatusNoContent: {{
+						Description: "No Content",
+					}},
+				},
+			},
+		},
+
+		HelpSynopsis:    pathAccountsHelpSyn,
+		HelpDescription: pathAccountsHelpDesc,
+	}
+}
+
+// readAccount reads the named account from the storage.
+func readAccount(ctx context.Context, s logical.Storage, name string) (*account, error) {
+	entry, err := s.Get(ctx, accountPrefix+name)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var result account
+	if err := entry.DecodeJSON(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// writeAccount writes the named account to the storage.
+func writeAccount(ctx context.Context, s logical.Storage, name string, acct *account) error {
+	entry, err := logical.StorageEntryJSON(accountPrefix+name, acct)
+	if err != nil {
+		return err
+	}
+	return s.Put(ctx, entry)
+}
+
+func (b *backend) pathAccountExistenceCheck(ctx context.Context, req *logical.Request, d *framework.FieldData) (bool, error) {
+	acct, err := readAccount(ctx, req.Storage, d.Get("name").(string))
+	if err != nil {
+		return false, err
+	}
+	return acct != nil, nil
+}
+
+func (b *backend) pathAccountList(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	names, err := req.Storage.List(ctx, accountPrefix)
+	if err != nil {
+		return nil, err
+	}
+	return logical.ListResponse(names), nil
+}
+
+func (b *backend) pathAccountRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	acct, err := readAccount(ctx, req.Storage, d.Get("name").(string))
+	if err != nil {
+		return nil, err
+	}
+	if acct == nil {
+		return nil, nil
+	}
+
+	// The configuration of rotators may hold credentials, so only its keys
+	// are returned.
+	rotatorConfig := make(map[string]string, len(acct.RotatorConfig))
+	for key := range acct.RotatorConfig {
+		rotatorConfig[key] = ""
+	}
+
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"username":                     acct.Username,
+			"ttl":                          int64(acct.TTL.Seconds()),
+			"max_ttl":                      int64(acct.MaxTTL.Seconds()),
+			"disable_check_in_enforcement": acct.DisableCheckInEnforcement,
+			"rotator":                      acct.Rotator,
+			"rotator_config":               rotatorConfig,
+			"password_policy":              acct.PasswordPolicy,
+		},
+	}
+	if !acct.LastRotated.IsZero() {
+		resp.Data["last_rotated"] = acct.LastRotated
+	}
+	return resp, nil
+}
+
+func (b *backend) pathAccountWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+	lock := b.lockAccount(name)
+	defer lock.Unlock()
+
+	acct, err := readAccount(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if acct == nil {
+		if req.Operation == logical.UpdateOperation {
+			return logical.ErrorResponse("account %q does not exist", name), nil
+		}
+		acct = &account{}
+	}
+
+	if username, ok := d.GetOk("username"); ok {
+		acct.Username = username.(string)
+	}
+	if acct.Username == "" {
+		return logical.ErrorResponse("username is required"), nil
+	}
+	if password, ok := d.GetOk("password"); ok {
+		co, err := readCheckout(ctx, req.Storage, name)
+		if err != nil {
+			return nil, err
+		}
+		if co != nil {
+			return logical.ErrorResponse("the password of account %q can't be set while it is checked out", name), nil
+		}
+		acct.Password = password.(string)
+	}
+	if acct.Password == "" {
+		return logical.ErrorResponse("password is required"), nil
+	}
+
+	if ttl, ok := d.GetOk("ttl"); ok {
+		acct.TTL = time.Duration(ttl.(int)) * time.Second
+	}
+	if maxTTL, ok := d.GetOk("max_ttl"); ok {
+		acct.MaxTTL = time.Duration(maxTTL.(int)) * time.Second
+	}
+	if acct.TTL < 0 || acct.MaxTTL < 0 {
+		return logical.ErrorResponse("ttl and max_ttl must not be negative"), nil
+	}
+	if acct.MaxTTL > 0 && acct.TTL > acct.MaxTTL {
+		return logical.ErrorResponse("ttl must not be greater than max_ttl"), nil
+	}
+
+	if disable, ok := d.GetOk("disable_check_in_enforcement"); ok {
+		acct.DisableCheckInEnforcement = disable.(bool)
+	}
+	if rotator, ok := d.GetOk("rotator"); ok {
+		acct.Rotator = rotator.(string)
+	}
+	if rotatorConfig, ok := d.GetOk("rotator_config"); ok {
+		acct.RotatorConfig = rotatorConfig.(map[string]string)
+	}
+	if acct.Rotator != "" {
+		if _, err := newRotator(acct.Rotator, acct.RotatorConfig); err != nil {
+			return logical.ErrorResponse("invalid rotator: %s", err), nil
+		}
+	}
+	if passwordPolicy, ok := d.GetOk("password_policy"); ok {
+		acct.PasswordPolicy = passwordPolicy.(string)
+	}
+
+	if err := writeAccount(ctx, req.Storage, name, acct); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (b *backend) pathAccountDelete(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+	lock := b.lockAccount(name)
+	defer lock.Unlock()
+
+	co, err := readCheckout(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if co != nil {
+		return logical.ErrorResponse("account %q is checked out, it must be checked in first", name), nil
+	}
+
+	if err := req.Storage.Delete(ctx, accountPrefix+name); err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Delete(ctx, historyPrefix+name); err != nil {
+		return nil, fmt.Errorf("failed to delete the history of account %q: %w", name, err)
+	}
+	return nil, nil
+}
+
+const pathAccountsHelpSyn = `
+Manage the shared static accounts which are checked out.
+`
+
+const pathAccountsHelpDesc = `
+This path creates, reads, updates and deletes the shared static accounts
+which clients check out exclusively. The password of accounts is only
+returned by check-outs. Accounts can't be deleted while they are checked
+out.
+`
@@ -0,0 +1,440 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package accounts
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/go-secure-stdlib/base62"
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// checkout is the check-out of an account by a client.
+type checkout struct {
+	// ID identifies the check-out, so that the revocation of its lease
+	// doesn't check in later check-outs.
+	ID string `json:"id"`
+
+	EntityID            string    `json:"entity_id"`
+	DisplayName         string    `json:"display_name"`
+	ClientTokenAccessor string    `json:"client_token_accessor"`
+	CheckedOutAt        time.Time `json:"checked_out_at"`
+}
+
+// heldBy returns whether the check-out was made by the client of the request:
+// by the same entity, or with the same token for clients without an entity.
+func (co *checkout) heldBy(req *logical.Request) bool {
+	if co.EntityID != "" {
+		return co.EntityID == req.EntityID
+	}
+	return co.ClientTokenAccessor != "" && co.ClientTokenAccessor == req.ClientTokenAccessor
+}
+
+func pathCheckOut(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "accounts/" + framework.GenericNameRegex("name") + "/check-out$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixAccounts,
+			OperationVerb:   "check-out",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the account.",
+				Required:    true,
+			},
+			"ttl": {
+				Type:        framework.TypeDurationSecond,
+				Description: "Duration of the check-out. Defaults to the ttl of the account, which it can't exceed.",
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathCheckOutUpdate,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields: map[string]*framework.FieldSchema{
+							"username": {Type: framework.TypeString, Required: true},
+							"password": {Type: framework.TypeString, Required: true},
+						},
+					}},
+				},
+			},
+		},
+
+		HelpSynopsis:    pathCheckOutHelpSyn,
+		HelpDescription: pathCheckOutHelpDesc,
+	}
+}
+
+func pathCheckIn(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "accounts/" + framework.GenericNameRegex("name") + "/check-in$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixAccounts,
+			OperationVerb:   "check-in",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the account.",
+				Required:    true,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathCheckInUpdate(false),
+				Responses: map[int][]framework.Response{
+					http.StatusNoContent: {{
+						Description: "No Content",
+					}},
+				},
+			},
+		},
+
+		HelpSynopsis:    pathCheckInHelpSyn,
+		HelpDescription: pathCheckInHelpDesc,
+	}
+}
+
+func pathForcedCheckIn(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "manage/" + framework.GenericNameRegex("name") + "/check-in$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixAccounts,
+			OperationVerb:   "force-check-in",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the account.",
+				Required:    true,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathCheckInUpdate(true),
+				Responses: map[int][]framework.Response{
+					http.StatusNoContent: {{
+						Description: "No Content",
+					}},
+				},
+			},
+		},
+
+		HelpSynopsis:    pathForcedCheckInHelpSyn,
+		HelpDescription: pathForcedCheckInHelpDesc,
+	}
+}
+
+func pathStatus(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "accounts/" + framework.GenericNameRegex("name") + "/status$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixAccounts,
+			OperationVerb:   "read",
+			OperationSuffix: "status",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the account.",
+				Required:    true,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathStatusRead,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields: map[string]*framework.FieldSchema{
+							"available":                      {Type: framework.TypeBool, Required: true},
+							"borrower_entity_id":             {Type: framework.TypeString},
+							"borrower_display_name":          {Type: framework.TypeString},
+							"borrower_client_token_accessor": {Type: framework.TypeString},
+							"checked_out_at":                 {Type: framework.TypeTime},
+						},
+					}},
+				},
+			},
+		},
+
+		HelpSynopsis:    pathStatusHelpSyn,
+		HelpDescription: pathStatusHelpDesc,
+	}
+}
+
+// lockAccount locks the check-outs and check-ins of the named account, and
+// returns the lock to unlock.
+func (b *backend) lockAccount(name string) *locksutil.LockEntry {
+	lock := locksutil.LockForKey(b.locks, name)
+	lock.Lock()
+	return lock
+}
+
+// readCheckout reads the check-out of the named account from the storage, and
+// returns nil if it is checked in.
+func readCheckout(ctx context.Context, s logical.Storage, name string) (*checkout, error) {
+	entry, err := s.Get(ctx, checkoutPrefix+name)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var result checkout
+	if err := entry.DecodeJSON(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (b *backend) pathCheckOutUpdate(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+	lock := b.lockAccount(name)
+	defer lock.Unlock()
+
+	acct, err := readAccount(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if acct == nil {
+		return logical.ErrorResponse("account %q does not exist", name), nil
+	}
+
+	co, err := readCheckout(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if co != nil {
+		return logical.ErrorResponse("account %q is already checked out", name), nil
+	}
+
+	ttl := acct.TTL
+	if ttl == 0 {
+		ttl = b.System().DefaultLeaseTTL()
+	}
+	if requested, ok := d.GetOk("ttl"); ok {
+		requestedTTL := time.Duration(requested.(int)) * time.Second
+		if requestedTTL <= 0 {
+			return logical.ErrorResponse("ttl must be positive"), nil
+		}
+		if requestedTTL < ttl {
+			ttl = requestedTTL
+		}
+	}
+
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
+	co = &checkout{
+		ID:                  id,
+		EntityID:            req.EntityID,
+		DisplayName:         req.DisplayName,
+		ClientTokenAccessor: req.ClientTokenAccessor,
+		CheckedOutAt:        time.Now().UTC(),
+	}
+	entry, err := logical.StorageEntryJSON(checkoutPrefix+name, co)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		return nil, err
+	}
+	if err := recordEvent(ctx, req.Storage, name, co.event(eventCheckOut)); err != nil {
+		return nil, err
+	}
+
+	resp := b.Secret(secretCheckoutType).Response(map[string]interface{}{
+		"username": acct.Username,
+		"password": acct.Password,
+	}, map[string]interface{}{
+		"name":        name,
+		"checkout_id": co.ID,
+	})
+	resp.Secret.TTL = ttl
+	resp.Secret.MaxTTL = acct.MaxTTL
+	resp.Secret.Renewable = true
+	return resp, nil
+}
+
+// pathCheckInUpdate returns the handler checking accounts in, either on
+// behalf of the client which checked them out, or forcibly.
+func (b *backend) pathCheckInUpdate(forced bool) framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		name := d.Get("name").(string)
+		lock := b.lockAccount(name)
+		defer lock.Unlock()
+
+		acct, err := readAccount(ctx, req.Storage, name)
+		if err != nil {
+			return nil, err
+		}
+		if acct == nil {
+			return logical.ErrorResponse("account %q does not exist", name), nil
+		}
+		co, err := readCheckout(ctx, req.Storage, name)
+		if err != nil {
+			return nil, err
+		}
+		if co == nil {
+			return logical.ErrorResponse("account %q is not checked out", name), nil
+		}
+
+		event := co.event(eventCheckIn)
+		switch {
+		case forced:
+			event.Action = eventForcedCheckIn
+			event.ForcedBy = req.DisplayName
+		case !acct.DisableCheckInEnforcement && !co.heldBy(req):
+			return logical.ErrorResponse("account %q is checked out by another client", name), logical.ErrPermissionDenied
+		}
+
+		if err := b.checkIn(ctx, req.Storage, name, acct, event); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+}
+
+// checkIn checks the named account in, rotating its password if it has a
+// rotator, and records the check-in event. The account stays checked out if
+// its password can't be rotated, as its borrower still knows it. The lock of
+// the account must be held.
+func (b *backend) checkIn(ctx context.Context, s logical.Storage, name string, acct *account, event *historyEvent) error {
+	if acct.Rotator != "" {
+		if err := b.rotate(ctx, acct); err != nil {
+			failure := *event
+			failure.Action = eventRotationFailed
+			failure.Error = err.Error()
+			if recordErr := recordEvent(ctx, s, name, &failure); recordErr != nil {
+				b.Logger().Error("failed to record rotation failure", "account", name, "error", recordErr)
+			}
+			return fmt.Errorf("failed to rotate the password of account %q: %w", name, err)
+		}
+		if err := writeAccount(ctx, s, name, acct); err != nil {
+			return err
+		}
+	}
+
+	if err := s.Delete(ctx, checkoutPrefix+name); err != nil {
+		return err
+	}
+	return recordEvent(ctx, s, name, event)
+}
+
+// rotate changes the password of the account with its rotator.
+func (b *backend) rotate(ctx context.Context, acct *account) error {
+	rotator, err := newRotator(acct.Rotator, acct.RotatorConfig)
+	if err != nil {
+		return err
+	}
+
+	var password string
+	if acct.PasswordPolicy != "" {
+		password, err = b.System().GeneratePasswordFromPolicy(ctx, acct.PasswordPolicy)
+	} else {
+		password, err = base62.Random(36)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to generate password: %w", err)
+	}
+
+	if err := rotator.Rotate(ctx, acct.Username, password); err != nil {
+		return err
+	}
+	acct.Password = password
+	acct.LastRotated = time.Now().UTC()
+	return nil
+}
+
+func (b *backend) pathStatusRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+	acct, err := readAccount(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if acct == nil {
+		return nil, nil
+	}
+	co, err := readCheckout(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"available": co == nil,
+		},
+	}
+	if co != nil {
+		resp.Data["borrower_entity_id"] = co.EntityID
+		resp.Data["borrower_display_name"] = co.DisplayName
+		resp.Data["borrower_client_token_accessor"] = co.ClientTokenAccessor
+		resp.Data["checked_out_at"] = co.CheckedOutAt
+	}
+	return resp, nil
+}
+
+const pathCheckOutHelpSyn = `
+Check an account out.
+`
+
+const pathCheckOutHelpDesc = `
+This path checks an account out, returning its credentials in a lease.
+No other client can check the account out until it is checked in, either
+explicitly, or when the lease expires or is revoked.
+`
+
+const pathCheckInHelpSyn = `
+Check an account in.
+`
+
+const pathCheckInHelpDesc = `
+This path checks in an account checked out by the client of the request,
+by the same entity or with the same token, unless the check-in enforcement
+of the account is disabled. The password of the account is rotated if it
+has a rotator.
+`
+
+const pathForcedCheckInHelpSyn = `
+Check an account in, whichever client checked it out.
+`
+
+const pathForcedCheckInHelpDesc = `
+This path checks an account in, whichever client checked it out, for
+operators to reclaim accounts. The password of the account is rotated if it
+has a rotator.
+`
+
+const pathStatusHelpSyn = `
+Report whether an account is checked out, and by whom.
+`
+
+const pathStatusHelpDesc = `
+This path reports whether an account is available, or the client which
+checked it out and when.
+`
@@ -0,0 +1,154 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package accounts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	cleanhttp "github.com/hashicorp/go-cleanhttp"
+)
+
+// Rotator changes the password of an account on the system the account
+// belongs to.
+type Rotator interface {
+	// Rotate sets the password of the account with the given username. The
+	// account keeps its previous password if it returns an error.
+	Rotate(ctx context.Context, username, password string) error
+}
+
+// RotatorFactory creates a rotator from the rotator configuration of an
+// account.
+type RotatorFactory func(config map[string]string) (Rotator, error)
+
+var (
+	rotatorsLock sync.RWMutex
+	rotators     = map[string]RotatorFactory{
+		"local":   newLocalRotator,
+		"webhook": newWebhookRotator,
+	}
+)
+
+// RegisterRotator makes a rotator available to accounts under the given name,
+// replacing the rotator already registered under it, if any.
+func RegisterRotator(name string, factory RotatorFactory) {
+	rotatorsLock.Lock()
+	defer rotatorsLock.Unlock()
+	rotators[name] = factory
+}
+
+// newRotator creates the named rotator with the given configuration.
+func newRotator(name string, config map[string]string) (Rotator, error) {
+	rotatorsLock.RLock()
+	factory, ok := rotators[name]
+	rotatorsLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown rotator %q, must be one of %v", name, rotatorNames())
+	}
+	return factory(config)
+}
+
+// rotatorNames returns the sorted names of the registered rotators.
+func rotatorNames() []string {
+	rotatorsLock.RLock()
+	defer rotatorsLock.RUnlock()
+
+	names := make([]string, 0, len(rotators))
+	for name := range rotators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// localRotator changes the password of accounts in Vault only, for accounts
+// whose system reads their password from Vault.
+type localRotator struct{}
+
+func newLocalRotator(config map[string]string) (Rotator, error) {
+	if len(config) > 0 {
+		return nil, fmt.Errorf("the local rotator takes no configuration")
+	}
+	return localRotator{}, nil
+}
+
+func (localRotator) Rotate(context.Context, string, string) error {
+	return nil
+}
+
+const webhookRotatorTimeout = 30 * time.Second
+
+// webhookRotator changes the password of accounts by posting their username
+// and new password to a URL, which must respond with a 2xx status once the
+// password is changed.
+type webhookRotator struct {
+	url           string
+	authorization string
+	client        *http.Client
+}
+
+func newWebhookRotator(config map[string]string) (Rotator, error) {
+	r := &webhookRotator{
+		client: cleanhttp.DefaultClient(),
+	}
+	r.client.Timeout = webhookRotatorTimeout
+
+	for key, value := range config {
+		switch key {
+		case "url":
+			u, err := url.Parse(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid url: %w", err)
+			}
+			if u.Scheme != "https" && u.Scheme != "http" {
+				return nil, fmt.Errorf("url must be an http or https URL")
+			}
+			r.url = value
+		case "authorization":
+			r.authorization = value
+		default:
+			return nil, fmt.Errorf("unknown webhook rotator configuration %q", key)
+		}
+	}
+	if r.url == "" {
+		return nil, fmt.Errorf("the webhook rotator requires a url")
+	}
+	return r, nil
+}
+
+func (r *webhookRotator) Rotate(ctx context.Context, username, password string) error {
+	body, err := json.Marshal(map[string]string{
+		"username": username,
+		"password": password,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.authorization != "" {
+		req.Header.Set("Authorization", r.authorization)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call the rotation webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("rotation webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package accounts
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// secretCheckoutType is the key of the check-outs of accounts.
+const secretCheckoutType = "checkout"
+
+func secretCheckout(b *backend) *framework.Secret {
+	return &framework.Secret{
+		Type: secretCheckoutType,
+		Fields: map[string]*framework.FieldSchema{
+			"username": {
+				Type:        framework.TypeString,
+				Description: "Username of the account",
+			},
+			"password": {
+				Type:        framework.TypeString,
+				Description: "Password of the account",
+			},
+		},
+		Renew:  b.secretCheckoutRenew,
+		Revoke: b.secretCheckoutRevoke,
+	}
+}
+
+// checkoutInternalData returns the name of the account and the ID of the
+// check-out of a lease.
+func checkoutInternalData(req *logical.Request) (string, string, error) {
+	name, ok := req.Secret.InternalData["name"].(string)
+	if !ok {
+		return "", "", fmt.Errorf("secret is missing name internal data")
+	}
+	id, ok := req.Secret.InternalData["checkout_id"].(string)
+	if !ok {
+		return "", "", fmt.Errorf("secret is missing checkout_id internal data")
+	}
+	return name, id, nil
+}
+
+// Renew the check-out, as long as the account wasn't checked in since
+func (b *backend) secretCheckoutRenew(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name, id, err := checkoutInternalData(req)
+	if err != nil {
+		return nil, err
+	}
+
+	lock := b.lockAccount(name)
+	defer lock.Unlock()
+
+	co, err := readCheckout(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if co == nil || co.ID != id {
+		return nil, fmt.Errorf("account %q was checked in", name)
+	}
+	acct, err := readAccount(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if acct == nil {
+		return nil, fmt.Errorf("account %q does not exist", name)
+	}
+
+	resp := &logical.Response{Secret: req.Secret}
+	resp.Secret.TTL = acct.TTL
+	resp.Secret.MaxTTL = acct.MaxTTL
+	return resp, nil
+}
+
+// Revoke the check-out, checking the account in unless it was already
+func (b *backend) secretCheckoutRevoke(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name, id, err := checkoutInternalData(req)
+	if err != nil {
+		return nil, err
+	}
+
+	lock := b.lockAccount(name)
+	defer lock.Unlock()
+
+	co, err := readCheckout(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if co == nil || co.ID != id {
+		return nil, nil
+	}
+	acct, err := readAccount(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if acct == nil {
+		return nil, req.Storage.Delete(ctx, checkoutPrefix+name)
+	}
+
+	return nil, b.checkIn(ctx, req.Storage, name, acct, co.event(eventLeaseEnded))
+}
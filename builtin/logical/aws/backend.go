@@ -10,10 +10,14 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/aws/aws-sdk-go/service/sso/ssoiface"
+	"github.com/aws/aws-sdk-go/service/ssoadmin/ssoadminiface"
+	"github.com/aws/aws-sdk-go/service/ssooidc/ssooidciface"
 	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+	"github.com/hashicorp/vault/helper/timeutil"
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
-	"github.com/hashicorp/vault/sdk/queue"
+	"github.com/hashicorp/vault/sdk/rotation"
 )
 
 const (
@@ -33,7 +37,13 @@ func Factory(ctx context.Context, conf *logical.BackendConfig) (logical.Backend,
 
 func Backend(_ *logical.BackendConfig) *backend {
 	var b backend
-	b.credRotationQueue = queue.New()
+	b.clock = timeutil.DefaultClock{}
+	b.staticRotations = b.newStaticRotationManager(b.clock)
+	b.credentialsSTSClient = func(ctx context.Context, s logical.Storage, creds *awsCredentials) (stsiface.STSAPI, error) {
+		b.clientMutex.RLock()
+		defer b.clientMutex.RUnlock()
+		return nonCachedClientSTSWithCredentials(ctx, s, creds, b.Logger())
+	}
 	b.Backend = &framework.Backend{
 		Help: strings.TrimSpace(backendHelp),
 
@@ -43,6 +53,7 @@ func Backend(_ *logical.BackendConfig) *backend {
 			},
 			SealWrapStorage: []string{
 				rootConfigPath,
+				identityCenterConfigPath,
 				pathStaticCreds + "/",
 			},
 		},
@@ -51,9 +62,13 @@ func Backend(_ *logical.BackendConfig) *backend {
 			pathConfigRoot(&b),
 			pathConfigRotateRoot(&b),
 			pathConfigLease(&b),
+			pathConfigIdentityCenter(&b),
 			pathRoles(&b),
 			pathListRoles(&b),
 			pathStaticRoles(&b),
+			pathStaticRolesDrift(&b),
+			pathStaticRolesRotate(&b),
+			pathStaticRolesVerify(&b),
 			pathStaticCredentials(&b),
 			pathUser(&b),
 		},
@@ -62,12 +77,13 @@ func Backend(_ *logical.BackendConfig) *backend {
 			secretAccessKeys(&b),
 		},
 
+		InitializeFunc:    b.initialize,
 		Invalidate:        b.invalidate,
 		WALRollback:       b.walRollback,
 		WALRollbackMinAge: minAwsUserRollbackAge,
 		PeriodicFunc: func(ctx context.Context, req *logical.Request) error {
 			if b.WriteSafeReplicationState() {
-				return b.rotateExpiredStaticCreds(ctx, req)
+				return b.staticRotations.RotateExpired(ctx, req.Storage)
 			}
 			return nil
 		},
@@ -91,9 +107,31 @@ type backend struct {
 	iamClient iamiface.IAMAPI
 	stsClient stsiface.STSAPI
 
-	// the age of a static role's credential is tracked by a priority queue and handled
-	// by the PeriodicFunc
-	credRotationQueue *queue.PriorityQueue
+	// assumedRoleIAMClients holds the IAM clients of static roles assuming a
+	// role in another account, by role ARN
+	assumedRoleIAMClients map[string]iamiface.IAMAPI
+
+	// ssoAdminClient, ssoClient and ssoOIDCClient hold configured IAM Identity
+	// Center clients for reuse, and to enable mocking with AWS iface for tests
+	ssoAdminClient ssoadminiface.SSOAdminAPI
+	ssoClient      ssoiface.SSOAPI
+	ssoOIDCClient  ssooidciface.SSOOIDCAPI
+
+	// identityCenterMutex protects the refresh token of IAM Identity Center
+	// and the access token obtained with it
+	identityCenterMutex sync.Mutex
+	identityCenterToken *identityCenterToken
+
+	// staticRotations schedules the rotations of the credentials of static
+	// roles, which are done by the PeriodicFunc
+	staticRotations *rotation.RotationManager
+
+	// clock is the time source of the rotations, replaced in tests
+	clock timeutil.Clock
+
+	// credentialsSTSClient returns an STS client using the given static role
+	// credential, replaced in tests
+	credentialsSTSClient func(ctx context.Context, s logical.Storage, creds *awsCredentials) (stsiface.STSAPI, error)
 }
 
 const backendHelp = `
@@ -110,6 +148,10 @@ func (b *backend) invalidate(ctx context.Context, key string) {
 	switch {
 	case key == rootConfigPath:
 		b.clearClients()
+	case key == identityCenterConfigPath:
+		b.clientMutex.Lock()
+		b.clearIdentityCenterClients()
+		b.clientMutex.Unlock()
 	}
 }
 
@@ -119,6 +161,20 @@ func (b *backend) clearClients() {
 	defer b.clientMutex.Unlock()
 	b.iamClient = nil
 	b.stsClient = nil
+	b.assumedRoleIAMClients = nil
+	b.clearIdentityCenterClients()
+}
+
+// clearIdentityCenterClients clears the backend's IAM Identity Center clients
+// and access token. The caller must hold b.clientMutex for writing.
+func (b *backend) clearIdentityCenterClients() {
+	b.ssoAdminClient = nil
+	b.ssoClient = nil
+	b.ssoOIDCClient = nil
+
+	b.identityCenterMutex.Lock()
+	b.identityCenterToken = nil
+	b.identityCenterMutex.Unlock()
 }
 
 // clientIAM returns the configured IAM client. If nil, it constructs a new one
@@ -150,6 +206,41 @@ func (b *backend) clientIAM(ctx context.Context, s logical.Storage) (iamiface.IA
 	return b.iamClient, nil
 }
 
+// clientIAMAssumingRole returns the IAM client using the credentials of the
+// given role, constructing it if needed. If roleARN is empty, it returns the
+// IAM client of the root credentials.
+func (b *backend) clientIAMAssumingRole(ctx context.Context, s logical.Storage, roleARN string) (iamiface.IAMAPI, error) {
+	if roleARN == "" {
+		return b.clientIAM(ctx, s)
+	}
+
+	b.clientMutex.RLock()
+	if client, ok := b.assumedRoleIAMClients[roleARN]; ok {
+		b.clientMutex.RUnlock()
+		return client, nil
+	}
+
+	// Upgrade the lock for writing
+	b.clientMutex.RUnlock()
+	b.clientMutex.Lock()
+	defer b.clientMutex.Unlock()
+
+	if client, ok := b.assumedRoleIAMClients[roleARN]; ok {
+		return client, nil
+	}
+
+	iamClient, err := nonCachedClientIAMAssumingRole(ctx, s, roleARN, b.Logger())
+	if err != nil {
+		return nil, err
+	}
+	if b.assumedRoleIAMClients == nil {
+		b.assumedRoleIAMClients = make(map[string]iamiface.IAMAPI)
+	}
+	b.assumedRoleIAMClients[roleARN] = iamClient
+
+	return iamClient, nil
+}
+
 func (b *backend) clientSTS(ctx context.Context, s logical.Storage) (stsiface.STSAPI, error) {
 	b.clientMutex.RLock()
 	if b.stsClient != nil {
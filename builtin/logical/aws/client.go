@@ -9,8 +9,13 @@ import (
 	"os"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/sso"
+	"github.com/aws/aws-sdk-go/service/ssoadmin"
+	"github.com/aws/aws-sdk-go/service/ssooidc"
 	"github.com/aws/aws-sdk-go/service/sts"
 	cleanhttp "github.com/hashicorp/go-cleanhttp"
 	"github.com/hashicorp/go-hclog"
@@ -90,6 +95,35 @@ func nonCachedClientIAM(ctx context.Context, s logical.Storage, logger hclog.Log
 	return client, nil
 }
 
+// nonCachedClientIAMAssumingRole returns an IAM client using the credentials
+// of the given role, assumed with the root credentials. It is used to manage
+// IAM users in other accounts than the one of the root credentials.
+func nonCachedClientIAMAssumingRole(ctx context.Context, s logical.Storage, roleARN string, logger hclog.Logger) (*iam.IAM, error) {
+	stsConfig, err := getRootConfig(ctx, s, "sts", logger)
+	if err != nil {
+		return nil, err
+	}
+	stsSess, err := session.NewSession(stsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	awsConfig, err := getRootConfig(ctx, s, "iam", logger)
+	if err != nil {
+		return nil, err
+	}
+	awsConfig.Credentials = stscreds.NewCredentials(stsSess, roleARN)
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, err
+	}
+	client := iam.New(sess)
+	if client == nil {
+		return nil, fmt.Errorf("could not obtain iam client")
+	}
+	return client, nil
+}
+
 func nonCachedClientSTS(ctx context.Context, s logical.Storage, logger hclog.Logger) (*sts.STS, error) {
 	awsConfig, err := getRootConfig(ctx, s, "sts", logger)
 	if err != nil {
@@ -105,3 +139,40 @@ func nonCachedClientSTS(ctx context.Context, s logical.Storage, logger hclog.Log
 	}
 	return client, nil
 }
+
+// nonCachedClientSTSWithCredentials returns an STS client using the given
+// access key rather than the root credentials, to check that the access key is
+// valid.
+func nonCachedClientSTSWithCredentials(ctx context.Context, s logical.Storage, creds *awsCredentials, logger hclog.Logger) (*sts.STS, error) {
+	awsConfig, err := getRootConfig(ctx, s, "sts", logger)
+	if err != nil {
+		return nil, err
+	}
+	awsConfig.Credentials = credentials.NewStaticCredentials(creds.AccessKeyID, creds.SecretAccessKey, "")
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, err
+	}
+	client := sts.New(sess)
+	if client == nil {
+		return nil, fmt.Errorf("could not obtain sts client")
+	}
+	return client, nil
+}
+
+// nonCachedClientsIdentityCenter returns the IAM Identity Center admin, portal
+// and OIDC clients, in the region of the Identity Center instance.
+func nonCachedClientsIdentityCenter(ctx context.Context, s logical.Storage, region string, logger hclog.Logger) (*ssoadmin.SSOAdmin, *sso.SSO, *ssooidc.SSOOIDC, error) {
+	awsConfig, err := getRootConfig(ctx, s, "identity-center", logger)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if region != "" {
+		awsConfig.Region = aws.String(region)
+	}
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return ssoadmin.New(sess), sso.New(sess), ssooidc.New(sess), nil
+}
@@ -4,14 +4,18 @@
 package aws
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/identitytpl"
 	"github.com/hashicorp/vault/sdk/logical"
 )
 
@@ -142,3 +146,105 @@ func combinePolicyDocuments(policies ...string) (string, error) {
 	policy = string(policyBytes)
 	return policy, nil
 }
+
+// validatePolicyDocumentTemplate returns an error if one of the strings of the
+// JSON policy document is not a valid identity template.
+func validatePolicyDocumentTemplate(policy string) error {
+	_, err := mapPolicyDocumentStrings(policy, func(s string) (string, error) {
+		if _, err := framework.ValidateIdentityTemplate(s); err != nil {
+			return "", fmt.Errorf("invalid template %q: %w", s, err)
+		}
+		return s, nil
+	})
+	return err
+}
+
+// renderPolicyDocument populates the identity templates of the strings of the
+// JSON policy document with the entity and groups of the request. Each string
+// is populated on its own, so that the identity data can't change the
+// structure of the document.
+func renderPolicyDocument(policy, entityID string, sysView logical.SystemView) (string, error) {
+	if entityID == "" {
+		return "", errors.New("no entity associated with the request's token")
+	}
+	entity, err := sysView.EntityInfo(entityID)
+	if err != nil {
+		return "", err
+	}
+	if entity == nil {
+		return "", errors.New("no entity found")
+	}
+	groups, err := sysView.GroupsForEntity(entityID)
+	if err != nil {
+		return "", err
+	}
+
+	return mapPolicyDocumentStrings(policy, func(s string) (string, error) {
+		_, out, err := identitytpl.PopulateString(identitytpl.PopulateStringInput{
+			String: s,
+			Entity: entity,
+			Groups: groups,
+			Mode:   identitytpl.ACLTemplating,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to populate template %q: %w", s, err)
+		}
+		return out, nil
+	})
+}
+
+// mapPolicyDocumentStrings applies fn to every string of the JSON policy
+// document, keys of objects included, and returns the compacted result.
+func mapPolicyDocumentStrings(policy string, fn func(string) (string, error)) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(policy)))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return "", err
+	}
+
+	var walk func(v interface{}) (interface{}, error)
+	walk = func(v interface{}) (interface{}, error) {
+		switch v := v.(type) {
+		case string:
+			return fn(v)
+		case []interface{}:
+			for i, elem := range v {
+				mapped, err := walk(elem)
+				if err != nil {
+					return nil, err
+				}
+				v[i] = mapped
+			}
+			return v, nil
+		case map[string]interface{}:
+			mappedObj := make(map[string]interface{}, len(v))
+			for key, elem := range v {
+				mappedKey, err := fn(key)
+				if err != nil {
+					return nil, err
+				}
+				mapped, err := walk(elem)
+				if err != nil {
+					return nil, err
+				}
+				mappedObj[mappedKey] = mapped
+			}
+			return mappedObj, nil
+		default:
+			return v, nil
+		}
+	}
+	mapped, err := walk(doc)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(mapped); err != nil {
+		return "", err
+	}
+	return string(bytes.TrimSpace(buf.Bytes())), nil
+}
@@ -262,3 +262,68 @@ func Test_combinePolicyDocuments(t *testing.T) {
 		})
 	}
 }
+
+func Test_renderPolicyDocument(t *testing.T) {
+	t.Parallel()
+	sysView := logical.StaticSystemView{
+		EntityVal: &logical.Entity{
+			ID:   "entity-id",
+			Name: "alice",
+			Metadata: map[string]string{
+				"team":  "payments",
+				"quote": `a"b`,
+			},
+		},
+		GroupsVal: []*logical.Group{{ID: "group-id", Name: "devs"}},
+	}
+
+	testCases := []struct {
+		description    string
+		entityID       string
+		input          string
+		expectedOutput string
+		expectedErr    bool
+	}{
+		{
+			description:    "no templates",
+			entityID:       "entity-id",
+			input:          `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"*","Condition":{"NumericLessThan":{"s3:max-keys":10}}}]}`,
+			expectedOutput: `{"Statement":[{"Action":"s3:GetObject","Condition":{"NumericLessThan":{"s3:max-keys":10}},"Effect":"Allow","Resource":"*"}],"Version":"2012-10-17"}`,
+		},
+		{
+			description:    "entity and group templates",
+			entityID:       "entity-id",
+			input:          `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:*","Resource":["arn:aws:s3:::{{identity.entity.metadata.team}}/*","arn:aws:s3:::{{identity.groups.ids.group-id.name}}/{{identity.entity.name}}/*"]}]}`,
+			expectedOutput: `{"Statement":[{"Action":"s3:*","Effect":"Allow","Resource":["arn:aws:s3:::payments/*","arn:aws:s3:::devs/alice/*"]}],"Version":"2012-10-17"}`,
+		},
+		{
+			description:    "identity data can't change the structure of the document",
+			entityID:       "entity-id",
+			input:          `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:*","Resource":"arn:aws:s3:::{{identity.entity.metadata.quote}}"}]}`,
+			expectedOutput: `{"Statement":[{"Action":"s3:*","Effect":"Allow","Resource":"arn:aws:s3:::a\"b"}],"Version":"2012-10-17"}`,
+		},
+		{
+			description: "missing metadata",
+			entityID:    "entity-id",
+			input:       `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:*","Resource":"arn:aws:s3:::{{identity.entity.metadata.missing}}"}]}`,
+			expectedErr: true,
+		},
+		{
+			description: "no entity",
+			input:       `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:*","Resource":"*"}]}`,
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			policyOut, err := renderPolicyDocument(tc.input, tc.entityID, sysView)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+			if policyOut != tc.expectedOutput {
+				t.Fatalf("did not receive expected output: want %s, got %s", tc.expectedOutput, policyOut)
+			}
+		})
+	}
+}
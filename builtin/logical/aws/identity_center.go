@@ -0,0 +1,261 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sso"
+	"github.com/aws/aws-sdk-go/service/sso/ssoiface"
+	"github.com/aws/aws-sdk-go/service/ssoadmin"
+	"github.com/aws/aws-sdk-go/service/ssoadmin/ssoadminiface"
+	"github.com/aws/aws-sdk-go/service/ssooidc"
+	"github.com/aws/aws-sdk-go/service/ssooidc/ssooidciface"
+	"github.com/hashicorp/go-secure-stdlib/awsutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	// identityCenterAssignmentTimeout is how long to wait for the assignment
+	// of a permission set to be provisioned in an account.
+	identityCenterAssignmentTimeout = 2 * time.Minute
+
+	// identityCenterTokenRefreshWindow is how long before its expiration an
+	// access token is refreshed.
+	identityCenterTokenRefreshWindow = time.Minute
+)
+
+// identityCenterAssignmentPollInterval is the interval between checks of the
+// provisioning status of an assignment, replaced in tests.
+var identityCenterAssignmentPollInterval = 2 * time.Second
+
+// identityCenterToken is an access token of the IAM Identity Center user.
+type identityCenterToken struct {
+	accessToken string
+	expiration  time.Time
+}
+
+// clientsIdentityCenter returns the configured IAM Identity Center clients.
+// If nil, it constructs new ones and returns them, setting the internal
+// variables.
+func (b *backend) clientsIdentityCenter(ctx context.Context, s logical.Storage, config *identityCenterConfig) (ssoadminiface.SSOAdminAPI, ssoiface.SSOAPI, ssooidciface.SSOOIDCAPI, error) {
+	b.clientMutex.RLock()
+	if b.ssoAdminClient != nil && b.ssoClient != nil && b.ssoOIDCClient != nil {
+		defer b.clientMutex.RUnlock()
+		return b.ssoAdminClient, b.ssoClient, b.ssoOIDCClient, nil
+	}
+
+	// Upgrade the lock for writing
+	b.clientMutex.RUnlock()
+	b.clientMutex.Lock()
+	defer b.clientMutex.Unlock()
+
+	// check clients again, in the event that they were being created while we
+	// waited for Lock()
+	if b.ssoAdminClient != nil && b.ssoClient != nil && b.ssoOIDCClient != nil {
+		return b.ssoAdminClient, b.ssoClient, b.ssoOIDCClient, nil
+	}
+
+	adminClient, portalClient, oidcClient, err := nonCachedClientsIdentityCenter(ctx, s, config.Region, b.Logger())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	b.ssoAdminClient, b.ssoClient, b.ssoOIDCClient = adminClient, portalClient, oidcClient
+
+	return b.ssoAdminClient, b.ssoClient, b.ssoOIDCClient, nil
+}
+
+// identityCenterCredentials vends temporary credentials for the permission set
+// of the role in its account, on behalf of the configured IAM Identity Center
+// user. The permission set is assigned to the user in the account first if
+// needed.
+func (b *backend) identityCenterCredentials(ctx context.Context, s logical.Storage, role *awsRoleEntry) (*logical.Response, error) {
+	config, err := readIdentityCenterConfig(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return logical.ErrorResponse("IAM Identity Center is not configured; configure it at %q", identityCenterConfigPath), nil
+	}
+
+	adminClient, portalClient, oidcClient, err := b.clientsIdentityCenter(ctx, s, config)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	permissionSet, err := adminClient.DescribePermissionSetWithContext(ctx, &ssoadmin.DescribePermissionSetInput{
+		InstanceArn:      aws.String(config.InstanceArn),
+		PermissionSetArn: aws.String(role.PermissionSetArn),
+	})
+	if err != nil {
+		return logical.ErrorResponse("error describing permission set: %s", err), awsutil.CheckAWSError(err)
+	}
+	if permissionSet.PermissionSet == nil || aws.StringValue(permissionSet.PermissionSet.Name) == "" {
+		return nil, fmt.Errorf("permission set %q has no name", role.PermissionSetArn)
+	}
+
+	if err := ensureAccountAssignment(ctx, adminClient, config, role); err != nil {
+		return logical.ErrorResponse("error assigning permission set: %s", err), awsutil.CheckAWSError(err)
+	}
+
+	accessToken, err := b.identityCenterAccessToken(ctx, s, oidcClient)
+	if err != nil {
+		return logical.ErrorResponse("error obtaining IAM Identity Center access token: %s", err), awsutil.CheckAWSError(err)
+	}
+
+	credsResp, err := portalClient.GetRoleCredentialsWithContext(ctx, &sso.GetRoleCredentialsInput{
+		AccessToken: aws.String(accessToken),
+		AccountId:   aws.String(role.AccountID),
+		RoleName:    permissionSet.PermissionSet.Name,
+	})
+	if err != nil {
+		return logical.ErrorResponse("error retrieving permission set credentials: %s", err), awsutil.CheckAWSError(err)
+	}
+	creds := credsResp.RoleCredentials
+	if creds == nil {
+		return nil, errors.New("no credentials returned for the permission set")
+	}
+
+	// The credentials can't be revoked nor renewed, so the lease ends when
+	// they expire, which is decided by the session duration of the permission
+	// set.
+	ttl := time.Until(time.UnixMilli(aws.Int64Value(creds.Expiration)))
+	resp := b.Secret(secretAccessKeyType).Response(map[string]interface{}{
+		"access_key":     aws.StringValue(creds.AccessKeyId),
+		"secret_key":     aws.StringValue(creds.SecretAccessKey),
+		"security_token": aws.StringValue(creds.SessionToken),
+		"ttl":            uint64(ttl.Seconds()),
+	}, map[string]interface{}{
+		"username":           aws.StringValue(permissionSet.PermissionSet.Name),
+		"is_sts":             true,
+		"permission_set_arn": role.PermissionSetArn,
+		"account_id":         role.AccountID,
+	})
+
+	resp.Secret.TTL = ttl
+	resp.Secret.Renewable = false
+
+	return resp, nil
+}
+
+// ensureAccountAssignment assigns the permission set of the role to the
+// configured user in the account of the role, unless it is already assigned,
+// and waits for the assignment to be provisioned.
+func ensureAccountAssignment(ctx context.Context, client ssoadminiface.SSOAdminAPI, config *identityCenterConfig, role *awsRoleEntry) error {
+	var assigned bool
+	err := client.ListAccountAssignmentsPagesWithContext(ctx, &ssoadmin.ListAccountAssignmentsInput{
+		InstanceArn:      aws.String(config.InstanceArn),
+		AccountId:        aws.String(role.AccountID),
+		PermissionSetArn: aws.String(role.PermissionSetArn),
+	}, func(page *ssoadmin.ListAccountAssignmentsOutput, lastPage bool) bool {
+		for _, assignment := range page.AccountAssignments {
+			if aws.StringValue(assignment.PrincipalType) == ssoadmin.PrincipalTypeUser &&
+				aws.StringValue(assignment.PrincipalId) == config.PrincipalID {
+				assigned = true
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if assigned {
+		return nil
+	}
+
+	created, err := client.CreateAccountAssignmentWithContext(ctx, &ssoadmin.CreateAccountAssignmentInput{
+		InstanceArn:      aws.String(config.InstanceArn),
+		PermissionSetArn: aws.String(role.PermissionSetArn),
+		PrincipalId:      aws.String(config.PrincipalID),
+		PrincipalType:    aws.String(ssoadmin.PrincipalTypeUser),
+		TargetId:         aws.String(role.AccountID),
+		TargetType:       aws.String(ssoadmin.TargetTypeAwsAccount),
+	})
+	if err != nil {
+		return err
+	}
+
+	status := created.AccountAssignmentCreationStatus
+	ctx, cancel := context.WithTimeout(ctx, identityCenterAssignmentTimeout)
+	defer cancel()
+	for {
+		if status == nil {
+			return errors.New("no assignment status returned")
+		}
+		switch aws.StringValue(status.Status) {
+		case ssoadmin.StatusValuesSucceeded:
+			return nil
+		case ssoadmin.StatusValuesFailed:
+			return fmt.Errorf("assignment failed: %s", aws.StringValue(status.FailureReason))
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for the assignment to be provisioned: %w", ctx.Err())
+		case <-time.After(identityCenterAssignmentPollInterval):
+		}
+
+		described, err := client.DescribeAccountAssignmentCreationStatusWithContext(ctx, &ssoadmin.DescribeAccountAssignmentCreationStatusInput{
+			InstanceArn:                        aws.String(config.InstanceArn),
+			AccountAssignmentCreationRequestId: status.RequestId,
+		})
+		if err != nil {
+			return err
+		}
+		status = described.AccountAssignmentCreationStatus
+	}
+}
+
+// identityCenterAccessToken returns an access token of the configured user,
+// refreshing it with the refresh token when it is about to expire. When IAM
+// Identity Center rotates the refresh token, the new one is persisted.
+func (b *backend) identityCenterAccessToken(ctx context.Context, s logical.Storage, client ssooidciface.SSOOIDCAPI) (string, error) {
+	b.identityCenterMutex.Lock()
+	defer b.identityCenterMutex.Unlock()
+
+	if token := b.identityCenterToken; token != nil && time.Until(token.expiration) > identityCenterTokenRefreshWindow {
+		return token.accessToken, nil
+	}
+
+	// Read the configuration again under the lock, as another request may
+	// have rotated the refresh token.
+	config, err := readIdentityCenterConfig(ctx, s)
+	if err != nil {
+		return "", err
+	}
+	if config == nil {
+		return "", errors.New("IAM Identity Center is not configured")
+	}
+
+	out, err := client.CreateTokenWithContext(ctx, &ssooidc.CreateTokenInput{
+		ClientId:     aws.String(config.ClientID),
+		ClientSecret: aws.String(config.ClientSecret),
+		GrantType:    aws.String("refresh_token"),
+		RefreshToken: aws.String(config.RefreshToken),
+	})
+	if err != nil {
+		return "", err
+	}
+	if aws.StringValue(out.AccessToken) == "" {
+		return "", errors.New("no access token returned")
+	}
+
+	if refreshToken := aws.StringValue(out.RefreshToken); refreshToken != "" && refreshToken != config.RefreshToken {
+		config.RefreshToken = refreshToken
+		if err := writeIdentityCenterConfig(ctx, s, config); err != nil {
+			return "", fmt.Errorf("failed to persist the rotated refresh token: %w", err)
+		}
+	}
+
+	b.identityCenterToken = &identityCenterToken{
+		accessToken: aws.StringValue(out.AccessToken),
+		expiration:  time.Now().Add(time.Duration(aws.Int64Value(out.ExpiresIn)) * time.Second),
+	}
+	return b.identityCenterToken.accessToken, nil
+}
@@ -0,0 +1,237 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package aws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sso"
+	"github.com/aws/aws-sdk-go/service/sso/ssoiface"
+	"github.com/aws/aws-sdk-go/service/ssoadmin"
+	"github.com/aws/aws-sdk-go/service/ssoadmin/ssoadminiface"
+	"github.com/aws/aws-sdk-go/service/ssooidc"
+	"github.com/aws/aws-sdk-go/service/ssooidc/ssooidciface"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testInstanceArn      = "arn:aws:sso:::instance/ssoins-1234567890abcdef"
+	testPermissionSetArn = "arn:aws:sso:::permissionSet/ssoins-1234567890abcdef/ps-1234567890abcdef"
+	testPrincipalID      = "user-1234"
+	testAccountID        = "123456789012"
+)
+
+// mockSSOAdmin assigns permission sets, reporting the assignments as in
+// progress for the given number of status checks.
+type mockSSOAdmin struct {
+	ssoadminiface.SSOAdminAPI
+	assigned      bool
+	pendingChecks int
+	created       int
+}
+
+func (m *mockSSOAdmin) DescribePermissionSetWithContext(_ aws.Context, in *ssoadmin.DescribePermissionSetInput, _ ...request.Option) (*ssoadmin.DescribePermissionSetOutput, error) {
+	return &ssoadmin.DescribePermissionSetOutput{
+		PermissionSet: &ssoadmin.PermissionSet{
+			Name:             aws.String("ReadOnly"),
+			PermissionSetArn: in.PermissionSetArn,
+		},
+	}, nil
+}
+
+func (m *mockSSOAdmin) ListAccountAssignmentsPagesWithContext(_ aws.Context, in *ssoadmin.ListAccountAssignmentsInput, fn func(*ssoadmin.ListAccountAssignmentsOutput, bool) bool, _ ...request.Option) error {
+	// The first page holds a group assignment, which isn't enough.
+	if !fn(&ssoadmin.ListAccountAssignmentsOutput{
+		AccountAssignments: []*ssoadmin.AccountAssignment{{
+			PrincipalId:   aws.String(testPrincipalID),
+			PrincipalType: aws.String(ssoadmin.PrincipalTypeGroup),
+		}},
+	}, false) {
+		return nil
+	}
+
+	var assignments []*ssoadmin.AccountAssignment
+	if m.assigned {
+		assignments = append(assignments, &ssoadmin.AccountAssignment{
+			AccountId:        in.AccountId,
+			PermissionSetArn: in.PermissionSetArn,
+			PrincipalId:      aws.String(testPrincipalID),
+			PrincipalType:    aws.String(ssoadmin.PrincipalTypeUser),
+		})
+	}
+	fn(&ssoadmin.ListAccountAssignmentsOutput{AccountAssignments: assignments}, true)
+	return nil
+}
+
+func (m *mockSSOAdmin) CreateAccountAssignmentWithContext(_ aws.Context, in *ssoadmin.CreateAccountAssignmentInput, _ ...request.Option) (*ssoadmin.CreateAccountAssignmentOutput, error) {
+	m.created++
+	return &ssoadmin.CreateAccountAssignmentOutput{
+		AccountAssignmentCreationStatus: m.status(),
+	}, nil
+}
+
+func (m *mockSSOAdmin) DescribeAccountAssignmentCreationStatusWithContext(_ aws.Context, in *ssoadmin.DescribeAccountAssignmentCreationStatusInput, _ ...request.Option) (*ssoadmin.DescribeAccountAssignmentCreationStatusOutput, error) {
+	return &ssoadmin.DescribeAccountAssignmentCreationStatusOutput{
+		AccountAssignmentCreationStatus: m.status(),
+	}, nil
+}
+
+func (m *mockSSOAdmin) status() *ssoadmin.AccountAssignmentOperationStatus {
+	status := &ssoadmin.AccountAssignmentOperationStatus{
+		RequestId: aws.String("request-1234"),
+		Status:    aws.String(ssoadmin.StatusValuesInProgress),
+	}
+	if m.pendingChecks == 0 {
+		m.assigned = true
+		status.Status = aws.String(ssoadmin.StatusValuesSucceeded)
+	}
+	m.pendingChecks--
+	return status
+}
+
+// mockSSOOIDC issues access tokens, rotating the refresh token each time.
+type mockSSOOIDC struct {
+	ssooidciface.SSOOIDCAPI
+	refreshTokens []string
+}
+
+func (m *mockSSOOIDC) CreateTokenWithContext(_ aws.Context, in *ssooidc.CreateTokenInput, _ ...request.Option) (*ssooidc.CreateTokenOutput, error) {
+	m.refreshTokens = append(m.refreshTokens, aws.StringValue(in.RefreshToken))
+	return &ssooidc.CreateTokenOutput{
+		AccessToken:  aws.String("access-token"),
+		ExpiresIn:    aws.Int64(3600),
+		RefreshToken: aws.String("rotated-refresh-token"),
+	}, nil
+}
+
+// mockSSO returns credentials for the permission set named ReadOnly, expiring
+// in an hour.
+type mockSSO struct {
+	ssoiface.SSOAPI
+}
+
+func (m *mockSSO) GetRoleCredentialsWithContext(_ aws.Context, in *sso.GetRoleCredentialsInput, _ ...request.Option) (*sso.GetRoleCredentialsOutput, error) {
+	if aws.StringValue(in.AccessToken) != "access-token" || aws.StringValue(in.RoleName) != "ReadOnly" {
+		return nil, &sso.UnauthorizedException{}
+	}
+	return &sso.GetRoleCredentialsOutput{
+		RoleCredentials: &sso.RoleCredentials{
+			AccessKeyId:     aws.String("ASIAEXAMPLE"),
+			SecretAccessKey: aws.String("secret"),
+			SessionToken:    aws.String("session"),
+			Expiration:      aws.Int64(time.Now().Add(time.Hour).UnixMilli()),
+		},
+	}, nil
+}
+
+func TestIdentityCenterCredentials(t *testing.T) {
+	pollInterval := identityCenterAssignmentPollInterval
+	identityCenterAssignmentPollInterval = time.Millisecond
+	defer func() {
+		identityCenterAssignmentPollInterval = pollInterval
+	}()
+
+	ctx := context.Background()
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	b := Backend(config)
+	require.NoError(t, b.Setup(ctx, config))
+
+	admin := &mockSSOAdmin{pendingChecks: 2}
+	oidc := &mockSSOOIDC{}
+	b.ssoAdminClient, b.ssoClient, b.ssoOIDCClient = admin, &mockSSO{}, oidc
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "roles/test",
+		Storage:   config.StorageView,
+		Data: map[string]interface{}{
+			"credential_type":    identityCenterCred,
+			"permission_set_arn": testPermissionSetArn,
+			"account_id":         testAccountID,
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, resp.IsError(), "%#v", resp)
+
+	credsReq := &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "creds/test",
+		Storage:   config.StorageView,
+	}
+	resp, err = b.HandleRequest(ctx, credsReq)
+	require.NoError(t, err)
+	require.True(t, resp.IsError(), "credentials were vended without configuration")
+
+	// Write the configuration directly, as writing it clears the clients.
+	require.NoError(t, writeIdentityCenterConfig(ctx, config.StorageView, &identityCenterConfig{
+		InstanceArn:  testInstanceArn,
+		PrincipalID:  testPrincipalID,
+		ClientID:     "client",
+		ClientSecret: "client-secret",
+		RefreshToken: "refresh-token",
+	}))
+
+	resp, err = b.HandleRequest(ctx, credsReq)
+	require.NoError(t, err)
+	require.False(t, resp.IsError(), "%#v", resp)
+	require.Equal(t, "ASIAEXAMPLE", resp.Data["access_key"])
+	require.Equal(t, "session", resp.Data["security_token"])
+	require.False(t, resp.Secret.Renewable)
+	require.InDelta(t, time.Hour, resp.Secret.TTL, float64(time.Minute))
+	require.Equal(t, testPermissionSetArn, resp.Secret.InternalData["permission_set_arn"])
+	require.Equal(t, 1, admin.created)
+
+	stored, err := readIdentityCenterConfig(ctx, config.StorageView)
+	require.NoError(t, err)
+	require.Equal(t, "rotated-refresh-token", stored.RefreshToken)
+
+	// The assignment and the access token are reused.
+	resp, err = b.HandleRequest(ctx, credsReq)
+	require.NoError(t, err)
+	require.False(t, resp.IsError(), "%#v", resp)
+	require.Equal(t, 1, admin.created)
+	require.Equal(t, []string{"refresh-token"}, oidc.refreshTokens)
+
+	// Once the access token is about to expire, the rotated refresh token is
+	// used to get a new one.
+	b.identityCenterToken.expiration = time.Now().Add(identityCenterTokenRefreshWindow / 2)
+	resp, err = b.HandleRequest(ctx, credsReq)
+	require.NoError(t, err)
+	require.False(t, resp.IsError(), "%#v", resp)
+	require.Equal(t, []string{"refresh-token", "rotated-refresh-token"}, oidc.refreshTokens)
+}
+
+func TestIdentityCenterAssignmentFailure(t *testing.T) {
+	failing := &failingSSOAdmin{mockSSOAdmin: &mockSSOAdmin{}}
+
+	err := ensureAccountAssignment(context.Background(), failing, &identityCenterConfig{
+		InstanceArn: testInstanceArn,
+		PrincipalID: testPrincipalID,
+	}, &awsRoleEntry{
+		PermissionSetArn: testPermissionSetArn,
+		AccountID:        testAccountID,
+	})
+	require.ErrorContains(t, err, "assignment failed: permission set is not provisioned")
+}
+
+// failingSSOAdmin fails to provision assignments.
+type failingSSOAdmin struct {
+	*mockSSOAdmin
+}
+
+func (m *failingSSOAdmin) CreateAccountAssignmentWithContext(_ aws.Context, _ *ssoadmin.CreateAccountAssignmentInput, _ ...request.Option) (*ssoadmin.CreateAccountAssignmentOutput, error) {
+	return &ssoadmin.CreateAccountAssignmentOutput{
+		AccountAssignmentCreationStatus: &ssoadmin.AccountAssignmentOperationStatus{
+			RequestId:     aws.String("request-1234"),
+			Status:        aws.String(ssoadmin.StatusValuesFailed),
+			FailureReason: aws.String("permission set is not provisioned"),
+		},
+	}, nil
+}
@@ -0,0 +1,195 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const identityCenterConfigPath = "config/identity-center"
+
+func pathConfigIdentityCenter(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: identityCenterConfigPath,
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixAWS,
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"instance_arn": {
+				Type:        framework.TypeString,
+				Description: "ARN of the IAM Identity Center instance.",
+			},
+			"region": {
+				Type:        framework.TypeString,
+				Description: "Region of the IAM Identity Center instance. Defaults to the region of the root configuration.",
+			},
+			"principal_id": {
+				Type:        framework.TypeString,
+				Description: "ID of the IAM Identity Center user credentials are vended for.",
+			},
+			"client_id": {
+				Type:        framework.TypeString,
+				Description: "ID of the OIDC client registered with IAM Identity Center.",
+			},
+			"client_secret": {
+				Type:        framework.TypeString,
+				Description: "Secret of the OIDC client registered with IAM Identity Center.",
+			},
+			"refresh_token": {
+				Type:        framework.TypeString,
+				Description: "Refresh token of the IAM Identity Center user, issued to the OIDC client.",
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathConfigIdentityCenterRead,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationSuffix: "identity-center-configuration",
+				},
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathConfigIdentityCenterWrite,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb:   "configure",
+					OperationSuffix: "identity-center",
+				},
+			},
+		},
+
+		HelpSynopsis:    pathConfigIdentityCenterHelpSyn,
+		HelpDescription: pathConfigIdentityCenterHelpDesc,
+	}
+}
+
+func (b *backend) pathConfigIdentityCenterRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	b.clientMutex.RLock()
+	defer b.clientMutex.RUnlock()
+
+	config, err := readIdentityCenterConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"instance_arn": config.InstanceArn,
+			"region":       config.Region,
+			"principal_id": config.PrincipalID,
+			"client_id":    config.ClientID,
+		},
+	}, nil
+}
+
+func (b *backend) pathConfigIdentityCenterWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	b.clientMutex.Lock()
+	defer b.clientMutex.Unlock()
+
+	config, err := readIdentityCenterConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		config = &identityCenterConfig{}
+	}
+
+	if instanceArn, ok := data.GetOk("instance_arn"); ok {
+		config.InstanceArn = instanceArn.(string)
+	}
+	if region, ok := data.GetOk("region"); ok {
+		config.Region = region.(string)
+	}
+	if principalID, ok := data.GetOk("principal_id"); ok {
+		config.PrincipalID = principalID.(string)
+	}
+	if clientID, ok := data.GetOk("client_id"); ok {
+		config.ClientID = clientID.(string)
+	}
+	if clientSecret, ok := data.GetOk("client_secret"); ok {
+		config.ClientSecret = clientSecret.(string)
+	}
+	if refreshToken, ok := data.GetOk("refresh_token"); ok {
+		config.RefreshToken = refreshToken.(string)
+	}
+
+	switch {
+	case config.InstanceArn == "":
+		return logical.ErrorResponse("missing instance_arn"), nil
+	case config.PrincipalID == "":
+		return logical.ErrorResponse("missing principal_id"), nil
+	case config.ClientID == "" || config.ClientSecret == "":
+		return logical.ErrorResponse("missing client_id or client_secret"), nil
+	case config.RefreshToken == "":
+		return logical.ErrorResponse("missing refresh_token"), nil
+	}
+
+	if err := writeIdentityCenterConfig(ctx, req.Storage, config); err != nil {
+		return nil, err
+	}
+
+	// clear possible cached Identity Center clients and access token after
+	// successfully updating the configuration
+	b.clearIdentityCenterClients()
+
+	return nil, nil
+}
+
+type identityCenterConfig struct {
+	InstanceArn  string `json:"instance_arn"`
+	Region       string `json:"region"`
+	PrincipalID  string `json:"principal_id"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+func readIdentityCenterConfig(ctx context.Context, s logical.Storage) (*identityCenterConfig, error) {
+	entry, err := s.Get(ctx, identityCenterConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var config identityCenterConfig
+	if err := entry.DecodeJSON(&config); err != nil {
+		return nil, fmt.Errorf("error reading identity center configuration: %w", err)
+	}
+	return &config, nil
+}
+
+func writeIdentityCenterConfig(ctx context.Context, s logical.Storage, config *identityCenterConfig) error {
+	entry, err := logical.StorageEntryJSON(identityCenterConfigPath, config)
+	if err != nil {
+		return err
+	}
+	return s.Put(ctx, entry)
+}
+
+const pathConfigIdentityCenterHelpSyn = `
+Configure the IAM Identity Center instance used to vend credentials.
+`
+
+const pathConfigIdentityCenterHelpDesc = `
+Roles with the identity_center credential type vend temporary credentials
+for a permission set in an AWS account managed through IAM Identity Center.
+Vault assigns the permission set to the configured Identity Center user in
+the account, using the root credentials, then retrieves the credentials of
+the permission set on behalf of that user.
+
+Vault obtains access tokens of the user from the refresh token issued to a
+registered OIDC client, for instance by completing the device authorization
+flow once, and keeps the refresh token up to date as IAM Identity Center
+rotates it.
+`
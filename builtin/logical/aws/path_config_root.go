@@ -142,6 +142,8 @@ func (b *backend) pathConfigRootWrite(ctx context.Context, req *logical.Request,
 	// config/root
 	b.iamClient = nil
 	b.stsClient = nil
+	b.assumedRoleIAMClients = nil
+	b.clearIdentityCenterClients()
 
 	return nil, nil
 }
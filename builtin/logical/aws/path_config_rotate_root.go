@@ -109,6 +109,7 @@ func (b *backend) pathConfigRotateRootUpdate(ctx context.Context, req *logical.R
 
 	b.iamClient = nil
 	b.stsClient = nil
+	b.assumedRoleIAMClients = nil
 
 	deleteAccessKeyInput := iam.DeleteAccessKeyInput{
 		AccessKeyId: aws.String(oldAccessKey),
@@ -61,12 +61,12 @@ func pathRoles(b *backend) *framework.Path {
 
 			"credential_type": {
 				Type:        framework.TypeString,
-				Description: fmt.Sprintf("Type of credential to retrieve. Must be one of %s, %s, or %s", assumedRoleCred, iamUserCred, federationTokenCred),
+				Description: fmt.Sprintf("Type of credential to retrieve. Must be one of %s, %s, %s, %s, or %s", assumedRoleCred, iamUserCred, federationTokenCred, identityCenterCred, webIdentityCred),
 			},
 
 			"role_arns": {
 				Type:        framework.TypeCommaStringSlice,
-				Description: "ARNs of AWS roles allowed to be assumed. Only valid when credential_type is " + assumedRoleCred,
+				Description: fmt.Sprintf("ARNs of AWS roles allowed to be assumed. Only valid when credential_type is %s or %s", assumedRoleCred, webIdentityCred),
 				DisplayAttrs: &framework.DisplayAttributes{
 					Name: "Role ARNs",
 				},
@@ -76,8 +76,8 @@ func pathRoles(b *backend) *framework.Path {
 				Type: framework.TypeCommaStringSlice,
 				Description: fmt.Sprintf(`ARNs of AWS policies. Behavior varies by credential_type. When credential_type is
 %s, then it will attach the specified policies to the generated IAM user.
-When credential_type is %s or %s, the policies will be passed as the
-PolicyArns parameter, acting as a filter on permissions available.`, iamUserCred, assumedRoleCred, federationTokenCred),
+When credential_type is %s, %s or %s, the policies will be passed as the
+PolicyArns parameter, acting as a filter on permissions available.`, iamUserCred, assumedRoleCred, federationTokenCred, webIdentityCred),
 				DisplayAttrs: &framework.DisplayAttributes{
 					Name: "Policy ARNs",
 				},
@@ -87,9 +87,21 @@ PolicyArns parameter, acting as a filter on permissions available.`, iamUserCred
 				Type: framework.TypeString,
 				Description: `JSON-encoded IAM policy document. Behavior varies by credential_type. When credential_type is
 iam_user, then it will attach the contents of the policy_document to the IAM
-user generated. When credential_type is assumed_role or federation_token, this
-will be passed in as the Policy parameter to the AssumeRole or
-GetFederationToken API call, acting as a filter on permissions available.`,
+user generated. When credential_type is assumed_role, federation_token or
+web_identity, this will be passed in as the Policy parameter to the
+AssumeRole, GetFederationToken or AssumeRoleWithWebIdentity API call, acting as
+a filter on permissions available.`,
+			},
+
+			"policy_document_template": {
+				Type: framework.TypeBool,
+				Description: `If set, the strings of policy_document are identity templates, populated
+with the entity of the request and its groups whenever credentials are
+generated, so that a single role can vend credentials scoped to each entity or
+team. Credentials can then only be generated by tokens with an entity.`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Policy Document Template",
+				},
 			},
 
 			"iam_groups": {
@@ -118,7 +130,7 @@ delimited key pairs.`,
 
 			"default_sts_ttl": {
 				Type:        framework.TypeDurationSecond,
-				Description: fmt.Sprintf("Default TTL for %s and %s credential types when no TTL is explicitly requested with the credentials", assumedRoleCred, federationTokenCred),
+				Description: fmt.Sprintf("Default TTL for %s, %s and %s credential types when no TTL is explicitly requested with the credentials", assumedRoleCred, federationTokenCred, webIdentityCred),
 				DisplayAttrs: &framework.DisplayAttributes{
 					Name: "Default STS TTL",
 				},
@@ -126,7 +138,7 @@ delimited key pairs.`,
 
 			"max_sts_ttl": {
 				Type:        framework.TypeDurationSecond,
-				Description: fmt.Sprintf("Max allowed TTL for %s and %s credential types", assumedRoleCred, federationTokenCred),
+				Description: fmt.Sprintf("Max allowed TTL for %s, %s and %s credential types", assumedRoleCred, federationTokenCred, webIdentityCred),
 				DisplayAttrs: &framework.DisplayAttributes{
 					Name: "Max STS TTL",
 				},
@@ -152,6 +164,22 @@ delimited key pairs.`,
 				Deprecated:  true,
 			},
 
+			"permission_set_arn": {
+				Type:        framework.TypeString,
+				Description: "ARN of the IAM Identity Center permission set to vend credentials for. Only valid when credential_type is " + identityCenterCred,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Permission Set ARN",
+				},
+			},
+
+			"account_id": {
+				Type:        framework.TypeString,
+				Description: "ID of the AWS account the permission set is assigned in. Only valid when credential_type is " + identityCenterCred,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Account ID",
+				},
+			},
+
 			"user_path": {
 				Type:        framework.TypeString,
 				Description: "Path for IAM User. Only valid when credential_type is " + iamUserCred,
@@ -291,6 +319,13 @@ func (b *backend) pathRolesWrite(ctx context.Context, req *logical.Request, d *f
 		roleEntry.PolicyDocument = compacted
 	}
 
+	if policyDocumentTemplateRaw, ok := d.GetOk("policy_document_template"); ok {
+		if legacyRole != "" {
+			return logical.ErrorResponse("cannot supply deprecated role or policy parameters with policy_document_template"), nil
+		}
+		roleEntry.PolicyDocumentTemplate = policyDocumentTemplateRaw.(bool)
+	}
+
 	if defaultSTSTTLRaw, ok := d.GetOk("default_sts_ttl"); ok {
 		if legacyRole != "" {
 			return logical.ErrorResponse("cannot supply deprecated role or policy parameters with default_sts_ttl"), nil
@@ -324,6 +359,20 @@ func (b *backend) pathRolesWrite(ctx context.Context, req *logical.Request, d *f
 		roleEntry.IAMGroups = iamGroups.([]string)
 	}
 
+	if permissionSetArnRaw, ok := d.GetOk("permission_set_arn"); ok {
+		if legacyRole != "" {
+			return logical.ErrorResponse("cannot supply deprecated role or policy parameters with permission_set_arn"), nil
+		}
+		roleEntry.PermissionSetArn = permissionSetArnRaw.(string)
+	}
+
+	if accountIDRaw, ok := d.GetOk("account_id"); ok {
+		if legacyRole != "" {
+			return logical.ErrorResponse("cannot supply deprecated role or policy parameters with account_id"), nil
+		}
+		roleEntry.AccountID = accountIDRaw.(string)
+	}
+
 	if iamTags, ok := d.GetOk("iam_tags"); ok {
 		roleEntry.IAMTags = iamTags.(map[string]string)
 	}
@@ -508,10 +557,11 @@ func setAwsRole(ctx context.Context, s logical.Storage, roleName string, roleEnt
 }
 
 type awsRoleEntry struct {
-	CredentialTypes          []string          `json:"credential_types"`                      // Entries must all be in the set of ("iam_user", "assumed_role", "federation_token")
+	CredentialTypes          []string          `json:"credential_types"`                      // Entries must all be in the set of ("iam_user", "assumed_role", "federation_token", "identity_center", "web_identity")
 	PolicyArns               []string          `json:"policy_arns"`                           // ARNs of managed policies to attach to an IAM user
-	RoleArns                 []string          `json:"role_arns"`                             // ARNs of roles to assume for AssumedRole credentials
+	RoleArns                 []string          `json:"role_arns"`                             // ARNs of roles to assume for AssumedRole and WebIdentity credentials
 	PolicyDocument           string            `json:"policy_document"`                       // JSON-serialized inline policy to attach to IAM users and/or to specify as the Policy parameter in AssumeRole calls
+	PolicyDocumentTemplate   bool              `json:"policy_document_template,omitempty"`    // Whether the strings of PolicyDocument are identity templates populated when generating credentials
 	IAMGroups                []string          `json:"iam_groups"`                            // Names of IAM groups that generated IAM users will be added to
 	IAMTags                  map[string]string `json:"iam_tags"`                              // IAM tags that will be added to the generated IAM users
 	InvalidData              string            `json:"invalid_data,omitempty"`                // Invalid role data. Exists to support converting the legacy role data into the new format
@@ -521,6 +571,8 @@ type awsRoleEntry struct {
 	MaxSTSTTL                time.Duration     `json:"max_sts_ttl"`                           // Max allowed TTL for STS credentials
 	UserPath                 string            `json:"user_path"`                             // The path for the IAM user when using "iam_user" credential type
 	PermissionsBoundaryARN   string            `json:"permissions_boundary_arn"`              // ARN of an IAM policy to attach as a permissions boundary
+	PermissionSetArn         string            `json:"permission_set_arn,omitempty"`          // ARN of the IAM Identity Center permission set to vend credentials for
+	AccountID                string            `json:"account_id,omitempty"`                  // ID of the AWS account the permission set is assigned in
 }
 
 func (r *awsRoleEntry) toResponseData() map[string]interface{} {
@@ -529,6 +581,7 @@ func (r *awsRoleEntry) toResponseData() map[string]interface{} {
 		"policy_arns":              r.PolicyArns,
 		"role_arns":                r.RoleArns,
 		"policy_document":          r.PolicyDocument,
+		"policy_document_template": r.PolicyDocumentTemplate,
 		"iam_groups":               r.IAMGroups,
 		"iam_tags":                 r.IAMTags,
 		"default_sts_ttl":          int64(r.DefaultSTSTTL.Seconds()),
@@ -537,6 +590,11 @@ func (r *awsRoleEntry) toResponseData() map[string]interface{} {
 		"permissions_boundary_arn": r.PermissionsBoundaryARN,
 	}
 
+	if strutil.StrListContains(r.CredentialTypes, identityCenterCred) {
+		respData["permission_set_arn"] = r.PermissionSetArn
+		respData["account_id"] = r.AccountID
+	}
+
 	if r.InvalidData != "" {
 		respData["invalid_data"] = r.InvalidData
 	}
@@ -550,19 +608,22 @@ func (r *awsRoleEntry) validate() error {
 		errors = multierror.Append(errors, fmt.Errorf("did not supply credential_type"))
 	}
 
-	allowedCredentialTypes := []string{iamUserCred, assumedRoleCred, federationTokenCred}
+	allowedCredentialTypes := []string{iamUserCred, assumedRoleCred, federationTokenCred, identityCenterCred, webIdentityCred}
 	for _, credType := range r.CredentialTypes {
 		if !strutil.StrListContains(allowedCredentialTypes, credType) {
 			errors = multierror.Append(errors, fmt.Errorf("unrecognized credential type: %s", credType))
 		}
 	}
 
-	if r.DefaultSTSTTL != 0 && !strutil.StrListContains(r.CredentialTypes, assumedRoleCred) && !strutil.StrListContains(r.CredentialTypes, federationTokenCred) {
-		errors = multierror.Append(errors, fmt.Errorf("default_sts_ttl parameter only valid for %s and %s credential types", assumedRoleCred, federationTokenCred))
+	isSTS := strutil.StrListContains(r.CredentialTypes, assumedRoleCred) ||
+		strutil.StrListContains(r.CredentialTypes, federationTokenCred) ||
+		strutil.StrListContains(r.CredentialTypes, webIdentityCred)
+	if r.DefaultSTSTTL != 0 && !isSTS {
+		errors = multierror.Append(errors, fmt.Errorf("default_sts_ttl parameter only valid for %s, %s and %s credential types", assumedRoleCred, federationTokenCred, webIdentityCred))
 	}
 
-	if r.MaxSTSTTL != 0 && !strutil.StrListContains(r.CredentialTypes, assumedRoleCred) && !strutil.StrListContains(r.CredentialTypes, federationTokenCred) {
-		errors = multierror.Append(errors, fmt.Errorf("max_sts_ttl parameter only valid for %s and %s credential types", assumedRoleCred, federationTokenCred))
+	if r.MaxSTSTTL != 0 && !isSTS {
+		errors = multierror.Append(errors, fmt.Errorf("max_sts_ttl parameter only valid for %s, %s and %s credential types", assumedRoleCred, federationTokenCred, webIdentityCred))
 	}
 
 	if r.MaxSTSTTL > 0 &&
@@ -589,8 +650,30 @@ func (r *awsRoleEntry) validate() error {
 		}
 	}
 
-	if len(r.RoleArns) > 0 && !strutil.StrListContains(r.CredentialTypes, assumedRoleCred) {
-		errors = multierror.Append(errors, fmt.Errorf("cannot supply role_arns when credential_type isn't %s", assumedRoleCred))
+	if len(r.RoleArns) > 0 && !strutil.StrListContains(r.CredentialTypes, assumedRoleCred) && !strutil.StrListContains(r.CredentialTypes, webIdentityCred) {
+		errors = multierror.Append(errors, fmt.Errorf("cannot supply role_arns when credential_type isn't %s or %s", assumedRoleCred, webIdentityCred))
+	}
+
+	// Group policies are read with the root credentials, which web identity
+	// credentials don't need
+	if strutil.StrListContains(r.CredentialTypes, webIdentityCred) && len(r.IAMGroups) > 0 {
+		errors = multierror.Append(errors, fmt.Errorf("cannot supply iam_groups when credential_type is %s", webIdentityCred))
+	}
+
+	if r.PolicyDocumentTemplate {
+		if r.PolicyDocument == "" {
+			errors = multierror.Append(errors, fmt.Errorf("policy_document_template requires a policy_document"))
+		} else if err := validatePolicyDocumentTemplate(r.PolicyDocument); err != nil {
+			errors = multierror.Append(errors, fmt.Errorf("invalid policy_document template: %w", err))
+		}
+	}
+
+	if strutil.StrListContains(r.CredentialTypes, identityCenterCred) {
+		if r.PermissionSetArn == "" || r.AccountID == "" {
+			errors = multierror.Append(errors, fmt.Errorf("permission_set_arn and account_id are required with the %s credential type", identityCenterCred))
+		}
+	} else if r.PermissionSetArn != "" || r.AccountID != "" {
+		errors = multierror.Append(errors, fmt.Errorf("cannot supply permission_set_arn or account_id when credential_type isn't %s", identityCenterCred))
 	}
 
 	return errors.ErrorOrNil()
@@ -606,6 +689,8 @@ const (
 	assumedRoleCred     = "assumed_role"
 	iamUserCred         = "iam_user"
 	federationTokenCred = "federation_token"
+	identityCenterCred  = "identity_center"
+	webIdentityCred     = "web_identity"
 )
 
 const pathListRolesHelpSyn = `List the existing roles in this backend`
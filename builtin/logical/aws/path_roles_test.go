@@ -449,3 +449,69 @@ func TestRoleEntryValidationFederationTokenCred(t *testing.T) {
 		t.Errorf("bad: invalid roleEntry with unrecognized PermissionsBoundary %#v passed validation", roleEntry)
 	}
 }
+
+func TestRoleEntryValidationIdentityCenterCred(t *testing.T) {
+	roleEntry := awsRoleEntry{
+		CredentialTypes:  []string{identityCenterCred},
+		PermissionSetArn: "arn:aws:sso:::permissionSet/ssoins-1234567890abcdef/ps-1234567890abcdef",
+		AccountID:        "123456789012",
+	}
+	if err := roleEntry.validate(); err != nil {
+		t.Errorf("bad: valid roleEntry %#v failed validation: %v", roleEntry, err)
+	}
+
+	roleEntry.AccountID = ""
+	if roleEntry.validate() == nil {
+		t.Errorf("bad: invalid roleEntry without AccountID %#v passed validation", roleEntry)
+	}
+	roleEntry.AccountID = "123456789012"
+	roleEntry.DefaultSTSTTL = 2
+	if roleEntry.validate() == nil {
+		t.Errorf("bad: invalid roleEntry with unrecognized DefaultSTSTTL %#v passed validation", roleEntry)
+	}
+	roleEntry.DefaultSTSTTL = 0
+
+	roleEntry.CredentialTypes = []string{assumedRoleCred}
+	if roleEntry.validate() == nil {
+		t.Errorf("bad: invalid roleEntry with unrecognized PermissionSetArn %#v passed validation", roleEntry)
+	}
+}
+
+func TestRoleEntryValidationWebIdentityCred(t *testing.T) {
+	roleEntry := awsRoleEntry{
+		CredentialTypes: []string{webIdentityCred},
+		RoleArns:        []string{"arn:aws:iam::123456789012:role/SomeRole"},
+		DefaultSTSTTL:   2,
+		MaxSTSTTL:       3,
+	}
+	if err := roleEntry.validate(); err != nil {
+		t.Errorf("bad: valid roleEntry %#v failed validation: %v", roleEntry, err)
+	}
+
+	roleEntry.IAMGroups = []string{"group1"}
+	if roleEntry.validate() == nil {
+		t.Errorf("bad: invalid roleEntry with unsupported IAMGroups %#v passed validation", roleEntry)
+	}
+}
+
+func TestRoleEntryValidationPolicyDocumentTemplate(t *testing.T) {
+	roleEntry := awsRoleEntry{
+		CredentialTypes:        []string{federationTokenCred},
+		PolicyDocument:         `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:*","Resource":"arn:aws:s3:::{{identity.entity.metadata.team}}/*"}]}`,
+		PolicyDocumentTemplate: true,
+	}
+	if err := roleEntry.validate(); err != nil {
+		t.Errorf("bad: valid roleEntry %#v failed validation: %v", roleEntry, err)
+	}
+
+	roleEntry.PolicyDocument = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:*","Resource":"arn:aws:s3:::{{identity.entity.metadata.team}/*"}]}`
+	if roleEntry.validate() == nil {
+		t.Errorf("bad: invalid roleEntry with malformed template %#v passed validation", roleEntry)
+	}
+
+	roleEntry.PolicyDocument = ""
+	roleEntry.PolicyArns = []string{adminAccessPolicyARN}
+	if roleEntry.validate() == nil {
+		t.Errorf("bad: invalid roleEntry with template but no policy document %#v passed validation", roleEntry)
+	}
+}
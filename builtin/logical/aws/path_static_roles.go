@@ -5,14 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/fatih/structs"
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
-	"github.com/hashicorp/vault/sdk/queue"
 )
 
 const (
@@ -21,6 +22,13 @@ const (
 	paramRoleName       = "name"
 	paramUsername       = "username"
 	paramRotationPeriod = "rotation_period"
+	paramPolicyARNs     = "policy_arns"
+	paramInlinePolicies = "inline_policies"
+	paramTags           = "tags"
+	paramRemediateDrift = "remediate_drift"
+	paramAssumeRoleARN  = "assume_role_arn"
+
+	paramLastRotationError = "last_rotation_error"
 )
 
 type staticRoleEntry struct {
@@ -28,6 +36,21 @@ type staticRoleEntry struct {
 	ID             string        `json:"id" structs:"id" mapstructure:"id"`
 	Username       string        `json:"username" structs:"username" mapstructure:"username"`
 	RotationPeriod time.Duration `json:"rotation_period" structs:"rotation_period" mapstructure:"rotation_period"`
+
+	// AssumeRoleARN is the role assumed to manage the IAM user, when it lives
+	// in another account than the one of the root credentials.
+	AssumeRoleARN string `json:"assume_role_arn,omitempty" structs:"assume_role_arn" mapstructure:"assume_role_arn"`
+
+	// LastRotationError is the error of the last scheduled rotation of the
+	// credential, if it failed. It is cleared by the next successful rotation.
+	LastRotationError string `json:"last_rotation_error,omitempty" structs:"last_rotation_error" mapstructure:"last_rotation_error"`
+
+	// The expected configuration of the IAM user, compared with its live
+	// configuration to detect drift.
+	PolicyARNs     []string          `json:"policy_arns" structs:"policy_arns" mapstructure:"policy_arns"`
+	InlinePolicies map[string]string `json:"inline_policies" structs:"inline_policies" mapstructure:"inline_policies"`
+	Tags           map[string]string `json:"tags" structs:"tags" mapstructure:"tags"`
+	RemediateDrift bool              `json:"remediate_drift" structs:"remediate_drift" mapstructure:"remediate_drift"`
 }
 
 func pathStaticRoles(b *backend) *framework.Path {
@@ -47,6 +70,30 @@ func pathStaticRoles(b *backend) *framework.Path {
 					Type:        framework.TypeDurationSecond,
 					Description: descRotationPeriod,
 				},
+				paramPolicyARNs: {
+					Type:        framework.TypeCommaStringSlice,
+					Description: descPolicyARNs,
+				},
+				paramInlinePolicies: {
+					Type:        framework.TypeKVPairs,
+					Description: descInlinePolicies,
+				},
+				paramTags: {
+					Type:        framework.TypeKVPairs,
+					Description: descTags,
+				},
+				paramRemediateDrift: {
+					Type:        framework.TypeBool,
+					Description: descRemediateDrift,
+				},
+				paramAssumeRoleARN: {
+					Type:        framework.TypeString,
+					Description: descAssumeRoleARN,
+				},
+				paramLastRotationError: {
+					Type:        framework.TypeString,
+					Description: descLastRotationError,
+				},
 			},
 		}},
 	}
@@ -66,6 +113,26 @@ func pathStaticRoles(b *backend) *framework.Path {
 				Type:        framework.TypeDurationSecond,
 				Description: descRotationPeriod,
 			},
+			paramPolicyARNs: {
+				Type:        framework.TypeCommaStringSlice,
+				Description: descPolicyARNs,
+			},
+			paramInlinePolicies: {
+				Type:        framework.TypeKVPairs,
+				Description: descInlinePolicies,
+			},
+			paramTags: {
+				Type:        framework.TypeKVPairs,
+				Description: descTags,
+			},
+			paramRemediateDrift: {
+				Type:        framework.TypeBool,
+				Description: descRemediateDrift,
+			},
+			paramAssumeRoleARN: {
+				Type:        framework.TypeString,
+				Description: descAssumeRoleARN,
+			},
 		},
 
 		Operations: map[logical.Operation]framework.OperationHandler{
@@ -156,6 +223,22 @@ func (b *backend) pathStaticRolesWrite(ctx context.Context, req *logical.Request
 
 	// other params are optional if we're not Creating
 
+	if rawAssumeRoleARN, ok := data.GetOk(paramAssumeRoleARN); ok {
+		assumeRoleARN := rawAssumeRoleARN.(string)
+		// the credential of the role belongs to the IAM user of its account,
+		// which can't be moved to another account
+		if !isCreate && assumeRoleARN != config.AssumeRoleARN {
+			return logical.ErrorResponse("%q cannot be changed on an existing role", paramAssumeRoleARN), nil
+		}
+		if assumeRoleARN != "" {
+			parsedARN, err := arn.Parse(assumeRoleARN)
+			if err != nil || !strings.HasPrefix(parsedARN.Resource, "role/") {
+				return logical.ErrorResponse("%q must be the ARN of an IAM role", paramAssumeRoleARN), nil
+			}
+		}
+		config.AssumeRoleARN = assumeRoleARN
+	}
+
 	if rawUsername, ok := data.GetOk(paramUsername); ok {
 		config.Username = rawUsername.(string)
 
@@ -176,6 +259,30 @@ func (b *backend) pathStaticRolesWrite(ctx context.Context, req *logical.Request
 		return logical.ErrorResponse("missing %q parameter", paramRotationPeriod), nil
 	}
 
+	if rawPolicyARNs, ok := data.GetOk(paramPolicyARNs); ok {
+		config.PolicyARNs = rawPolicyARNs.([]string)
+	}
+
+	if rawInlinePolicies, ok := data.GetOk(paramInlinePolicies); ok {
+		config.InlinePolicies = rawInlinePolicies.(map[string]string)
+
+		for name, document := range config.InlinePolicies {
+			compacted, err := compactJSON(document)
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse inline policy %q: %w", name, err)
+			}
+			config.InlinePolicies[name] = compacted
+		}
+	}
+
+	if rawTags, ok := data.GetOk(paramTags); ok {
+		config.Tags = rawTags.(map[string]string)
+	}
+
+	if rawRemediateDrift, ok := data.GetOk(paramRemediateDrift); ok {
+		config.RemediateDrift = rawRemediateDrift.(bool)
+	}
+
 	b.roleMutex.Lock()
 	defer b.roleMutex.Unlock()
 
@@ -201,13 +308,16 @@ func (b *backend) pathStaticRolesWrite(ctx context.Context, req *logical.Request
 			return nil, fmt.Errorf("failed to create new credentials for role %q: %w", config.Name, err)
 		}
 
-		err = b.credRotationQueue.Push(&queue.Item{
-			Key:      config.Name,
-			Value:    config,
-			Priority: time.Now().Add(config.RotationPeriod).Unix(),
-		})
+		err = b.staticRotations.Schedule(ctx, req.Storage, config.Name, staticRoleSchedule(config))
+		if err != nil {
+			return nil, fmt.Errorf("failed to schedule the rotation of role %q: %w", config.Name, err)
+		}
+	} else {
+		// keep the time of the next rotation, but rotate with the updated
+		// period from then on
+		err = b.staticRotations.UpdateSchedule(ctx, req.Storage, config.Name, staticRoleSchedule(config))
 		if err != nil {
-			return nil, fmt.Errorf("failed to add item into the rotation queue for role %q: %w", config.Name, err)
+			return nil, fmt.Errorf("failed to update the rotation schedule of role %q: %w", config.Name, err)
 		}
 	}
 
@@ -244,10 +354,10 @@ func (b *backend) pathStaticRolesDelete(ctx context.Context, req *logical.Reques
 		return nil, fmt.Errorf("failed to clean credentials while deleting role %q: %w", roleName.(string), err)
 	}
 
-	// delete from the queue
-	_, err = b.credRotationQueue.PopByKey(cfg.Name)
+	// delete from the rotation schedule
+	err = b.staticRotations.Unschedule(ctx, req.Storage, cfg.Name)
 	if err != nil {
-		return nil, fmt.Errorf("couldn't delete key from queue: %w", err)
+		return nil, fmt.Errorf("couldn't delete the rotation schedule of role %q: %w", cfg.Name, err)
 	}
 
 	return nil, req.Storage.Delete(ctx, formatRoleStoragePath(roleName.(string)))
@@ -263,7 +373,7 @@ func (b *backend) validateRoleName(name string) error {
 // validateIAMUser checks the user information we have for the role against the information on AWS. On a create, it uses the username
 // to retrieve the user information and _sets_ the userID. On update, it validates the userID and username.
 func (b *backend) validateIAMUserExists(ctx context.Context, storage logical.Storage, entry *staticRoleEntry, isCreate bool) error {
-	c, err := b.clientIAM(ctx, storage)
+	c, err := b.clientIAMAssumingRole(ctx, storage, entry.AssumeRoleARN)
 	if err != nil {
 		return fmt.Errorf("unable to validate username %q: %w", entry.Username, err)
 	}
@@ -321,6 +431,9 @@ This path lets you manage static roles (users) for the AWS secret backend.
 A static role is associated with a single IAM user, and manages the access
 keys based on a rotation period, automatically rotating the credential. If
 the IAM user has multiple access keys, the oldest key will be rotated.
+The managed policies, inline policies and tags expected on the IAM user can be
+configured, to detect drift from them through the "drift" path. IAM users in
+other accounts are managed by assuming the role set in "assume_role_arn".
 `
 
 const (
@@ -328,4 +441,10 @@ const (
 	descUsername       = "The IAM user to adopt as a static role."
 	descRotationPeriod = `Period by which to rotate the backing credential of the adopted user. 
 This can be a Go duration (e.g, '1m', 24h'), or an integer number of seconds.`
+	descPolicyARNs        = "Managed policies expected to be attached to the IAM user, to detect drift from them."
+	descInlinePolicies    = "Inline policies expected on the IAM user, as pairs of policy names and JSON policy documents, to detect drift from them."
+	descTags              = "Tags expected on the IAM user, to detect drift from them. Other tags of the user are ignored."
+	descRemediateDrift    = "If set, the drift of the IAM user from its expected policies and tags is reverted each time its credential is rotated."
+	descAssumeRoleARN     = "The ARN of a role assumed with the root credentials to manage the IAM user, when it lives in another account. Cannot be changed once set."
+	descLastRotationError = "The error of the last scheduled rotation of the credential, if it failed. Cleared by the next successful rotation."
 )
@@ -0,0 +1,367 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// staticRoleDrift describes how the live configuration of the IAM user of a
+// static role differs from the configuration expected by the role.
+type staticRoleDrift struct {
+	MissingPolicyARNs        []string `json:"missing_policy_arns" structs:"missing_policy_arns"`
+	UnexpectedPolicyARNs     []string `json:"unexpected_policy_arns" structs:"unexpected_policy_arns"`
+	MissingInlinePolicies    []string `json:"missing_inline_policies" structs:"missing_inline_policies"`
+	UnexpectedInlinePolicies []string `json:"unexpected_inline_policies" structs:"unexpected_inline_policies"`
+	ModifiedInlinePolicies   []string `json:"modified_inline_policies" structs:"modified_inline_policies"`
+	MissingTags              []string `json:"missing_tags" structs:"missing_tags"`
+	ModifiedTags             []string `json:"modified_tags" structs:"modified_tags"`
+}
+
+// drifted returns whether the IAM user differs from the expected configuration.
+func (d *staticRoleDrift) drifted() bool {
+	return len(d.MissingPolicyARNs)+len(d.UnexpectedPolicyARNs)+
+		len(d.MissingInlinePolicies)+len(d.UnexpectedInlinePolicies)+len(d.ModifiedInlinePolicies)+
+		len(d.MissingTags)+len(d.ModifiedTags) > 0
+}
+
+func (d *staticRoleDrift) toResponseData() map[string]interface{} {
+	return map[string]interface{}{
+		"drifted":                    d.drifted(),
+		"missing_policy_arns":        d.MissingPolicyARNs,
+		"unexpected_policy_arns":     d.UnexpectedPolicyARNs,
+		"missing_inline_policies":    d.MissingInlinePolicies,
+		"unexpected_inline_policies": d.UnexpectedInlinePolicies,
+		"modified_inline_policies":   d.ModifiedInlinePolicies,
+		"missing_tags":               d.MissingTags,
+		"modified_tags":              d.ModifiedTags,
+	}
+}
+
+func pathStaticRolesDrift(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: fmt.Sprintf("%s/%s/drift", pathStaticRole, framework.GenericNameWithAtRegex(paramRoleName)),
+		Fields: map[string]*framework.FieldSchema{
+			paramRoleName: {
+				Type:        framework.TypeString,
+				Description: descRoleName,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathStaticRolesDriftRead,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: http.StatusText(http.StatusOK),
+						Fields: map[string]*framework.FieldSchema{
+							"drifted": {
+								Type:        framework.TypeBool,
+								Description: "Whether the IAM user differs from the configuration expected by the role.",
+							},
+							"missing_policy_arns": {
+								Type:        framework.TypeCommaStringSlice,
+								Description: "Expected managed policies which are not attached to the IAM user.",
+							},
+							"unexpected_policy_arns": {
+								Type:        framework.TypeCommaStringSlice,
+								Description: "Managed policies attached to the IAM user which are not expected.",
+							},
+							"missing_inline_policies": {
+								Type:        framework.TypeCommaStringSlice,
+								Description: "Expected inline policies which the IAM user doesn't have.",
+							},
+							"unexpected_inline_policies": {
+								Type:        framework.TypeCommaStringSlice,
+								Description: "Inline policies of the IAM user which are not expected.",
+							},
+							"modified_inline_policies": {
+								Type:        framework.TypeCommaStringSlice,
+								Description: "Inline policies of the IAM user whose document differs from the expected one.",
+							},
+							"missing_tags": {
+								Type:        framework.TypeCommaStringSlice,
+								Description: "Expected tags which the IAM user doesn't have.",
+							},
+							"modified_tags": {
+								Type:        framework.TypeCommaStringSlice,
+								Description: "Tags of the IAM user whose value differs from the expected one.",
+							},
+						},
+					}},
+				},
+			},
+		},
+
+		HelpSynopsis:    pathStaticRolesDriftHelpSyn,
+		HelpDescription: pathStaticRolesDriftHelpDesc,
+	}
+}
+
+func (b *backend) pathStaticRolesDriftRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	roleName := data.Get(paramRoleName).(string)
+
+	b.roleMutex.RLock()
+	entry, err := req.Storage.Get(ctx, formatRoleStoragePath(roleName))
+	b.roleMutex.RUnlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration for static role %q: %w", roleName, err)
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var config staticRoleEntry
+	if err := entry.DecodeJSON(&config); err != nil {
+		return nil, fmt.Errorf("failed to decode configuration for static role %q: %w", roleName, err)
+	}
+
+	iamClient, err := b.clientIAMAssumingRole(ctx, req.Storage, config.AssumeRoleARN)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the AWS IAM client: %w", err)
+	}
+
+	drift, err := detectStaticRoleDrift(iamClient, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: drift.toResponseData(),
+	}, nil
+}
+
+// detectStaticRoleDrift compares the live configuration of the IAM user of the
+// role with the configuration expected by the role. Only the aspects which are
+// configured on the role are compared: managed policies and inline policies
+// are compared when the role expects some, and only the expected tags are
+// compared, as other tags are commonly added by other tools.
+func detectStaticRoleDrift(iamClient iamiface.IAMAPI, cfg staticRoleEntry) (*staticRoleDrift, error) {
+	drift := &staticRoleDrift{}
+
+	if len(cfg.PolicyARNs) > 0 {
+		attached, err := listAttachedUserPolicyARNs(iamClient, cfg.Username)
+		if err != nil {
+			return nil, err
+		}
+		drift.MissingPolicyARNs, drift.UnexpectedPolicyARNs = diffStrings(cfg.PolicyARNs, attached)
+	}
+
+	if len(cfg.InlinePolicies) > 0 {
+		names, err := listUserPolicyNames(iamClient, cfg.Username)
+		if err != nil {
+			return nil, err
+		}
+		expected := make([]string, 0, len(cfg.InlinePolicies))
+		for name := range cfg.InlinePolicies {
+			expected = append(expected, name)
+		}
+		drift.MissingInlinePolicies, drift.UnexpectedInlinePolicies = diffStrings(expected, names)
+
+		for _, name := range names {
+			expectedDocument, ok := cfg.InlinePolicies[name]
+			if !ok {
+				continue
+			}
+			out, err := iamClient.GetUserPolicy(&iam.GetUserPolicyInput{
+				UserName:   aws.String(cfg.Username),
+				PolicyName: aws.String(name),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("unable to get inline policy %q of IAM user %q: %w", name, cfg.Username, err)
+			}
+			// IAM returns URL-encoded policy documents.
+			document, err := url.QueryUnescape(aws.StringValue(out.PolicyDocument))
+			if err != nil {
+				return nil, fmt.Errorf("unable to decode inline policy %q of IAM user %q: %w", name, cfg.Username, err)
+			}
+			if !equalPolicyDocuments(expectedDocument, document) {
+				drift.ModifiedInlinePolicies = append(drift.ModifiedInlinePolicies, name)
+			}
+		}
+		sort.Strings(drift.ModifiedInlinePolicies)
+	}
+
+	if len(cfg.Tags) > 0 {
+		tags, err := listUserTags(iamClient, cfg.Username)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range cfg.Tags {
+			live, ok := tags[key]
+			switch {
+			case !ok:
+				drift.MissingTags = append(drift.MissingTags, key)
+			case live != value:
+				drift.ModifiedTags = append(drift.ModifiedTags, key)
+			}
+		}
+		sort.Strings(drift.MissingTags)
+		sort.Strings(drift.ModifiedTags)
+	}
+
+	return drift, nil
+}
+
+// remediateStaticRoleDrift reverts the IAM user of the role to the
+// configuration expected by the role.
+func remediateStaticRoleDrift(iamClient iamiface.IAMAPI, cfg staticRoleEntry, drift *staticRoleDrift) error {
+	username := aws.String(cfg.Username)
+
+	for _, arn := range drift.MissingPolicyARNs {
+		if _, err := iamClient.AttachUserPolicy(&iam.AttachUserPolicyInput{UserName: username, PolicyArn: aws.String(arn)}); err != nil {
+			return fmt.Errorf("unable to attach policy %q to IAM user %q: %w", arn, cfg.Username, err)
+		}
+	}
+	for _, arn := range drift.UnexpectedPolicyARNs {
+		if _, err := iamClient.DetachUserPolicy(&iam.DetachUserPolicyInput{UserName: username, PolicyArn: aws.String(arn)}); err != nil {
+			return fmt.Errorf("unable to detach policy %q from IAM user %q: %w", arn, cfg.Username, err)
+		}
+	}
+
+	for _, names := range [][]string{drift.MissingInlinePolicies, drift.ModifiedInlinePolicies} {
+		for _, name := range names {
+			_, err := iamClient.PutUserPolicy(&iam.PutUserPolicyInput{
+				UserName:       username,
+				PolicyName:     aws.String(name),
+				PolicyDocument: aws.String(cfg.InlinePolicies[name]),
+			})
+			if err != nil {
+				return fmt.Errorf("unable to put inline policy %q of IAM user %q: %w", name, cfg.Username, err)
+			}
+		}
+	}
+	for _, name := range drift.UnexpectedInlinePolicies {
+		if _, err := iamClient.DeleteUserPolicy(&iam.DeleteUserPolicyInput{UserName: username, PolicyName: aws.String(name)}); err != nil {
+			return fmt.Errorf("unable to delete inline policy %q of IAM user %q: %w", name, cfg.Username, err)
+		}
+	}
+
+	var tags []*iam.Tag
+	for _, keys := range [][]string{drift.MissingTags, drift.ModifiedTags} {
+		for _, key := range keys {
+			tags = append(tags, &iam.Tag{Key: aws.String(key), Value: aws.String(cfg.Tags[key])})
+		}
+	}
+	if len(tags) > 0 {
+		if _, err := iamClient.TagUser(&iam.TagUserInput{UserName: username, Tags: tags}); err != nil {
+			return fmt.Errorf("unable to tag IAM user %q: %w", cfg.Username, err)
+		}
+	}
+
+	return nil
+}
+
+func listAttachedUserPolicyARNs(iamClient iamiface.IAMAPI, username string) ([]string, error) {
+	var arns []string
+	input := &iam.ListAttachedUserPoliciesInput{UserName: aws.String(username)}
+	for {
+		out, err := iamClient.ListAttachedUserPolicies(input)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list attached policies of IAM user %q: %w", username, err)
+		}
+		for _, policy := range out.AttachedPolicies {
+			arns = append(arns, aws.StringValue(policy.PolicyArn))
+		}
+		if !aws.BoolValue(out.IsTruncated) {
+			return arns, nil
+		}
+		input.Marker = out.Marker
+	}
+}
+
+func listUserPolicyNames(iamClient iamiface.IAMAPI, username string) ([]string, error) {
+	var names []string
+	input := &iam.ListUserPoliciesInput{UserName: aws.String(username)}
+	for {
+		out, err := iamClient.ListUserPolicies(input)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list inline policies of IAM user %q: %w", username, err)
+		}
+		names = append(names, aws.StringValueSlice(out.PolicyNames)...)
+		if !aws.BoolValue(out.IsTruncated) {
+			return names, nil
+		}
+		input.Marker = out.Marker
+	}
+}
+
+func listUserTags(iamClient iamiface.IAMAPI, username string) (map[string]string, error) {
+	tags := make(map[string]string)
+	input := &iam.ListUserTagsInput{UserName: aws.String(username)}
+	for {
+		out, err := iamClient.ListUserTags(input)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list tags of IAM user %q: %w", username, err)
+		}
+		for _, tag := range out.Tags {
+			tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+		}
+		if !aws.BoolValue(out.IsTruncated) {
+			return tags, nil
+		}
+		input.Marker = out.Marker
+	}
+}
+
+// diffStrings returns the sorted values which are expected but not in
+// actual, and those which are in actual but not expected.
+func diffStrings(expected, actual []string) (missing []string, unexpected []string) {
+	expectedSet := make(map[string]struct{}, len(expected))
+	for _, v := range expected {
+		expectedSet[v] = struct{}{}
+	}
+	actualSet := make(map[string]struct{}, len(actual))
+	for _, v := range actual {
+		actualSet[v] = struct{}{}
+		if _, ok := expectedSet[v]; !ok {
+			unexpected = append(unexpected, v)
+		}
+	}
+	for v := range expectedSet {
+		if _, ok := actualSet[v]; !ok {
+			missing = append(missing, v)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(unexpected)
+	return missing, unexpected
+}
+
+// equalPolicyDocuments returns whether the JSON policy documents are
+// equivalent, regardless of their formatting and of the order of their keys.
+func equalPolicyDocuments(a, b string) bool {
+	var decodedA, decodedB interface{}
+	if err := json.Unmarshal([]byte(a), &decodedA); err != nil {
+		return a == b
+	}
+	if err := json.Unmarshal([]byte(b), &decodedB); err != nil {
+		return false
+	}
+	encodedA, _ := json.Marshal(decodedA)
+	encodedB, _ := json.Marshal(decodedB)
+	return string(encodedA) == string(encodedB)
+}
+
+const pathStaticRolesDriftHelpSyn = `
+Report how the IAM user of a static role differs from its expected configuration.
+`
+
+const pathStaticRolesDriftHelpDesc = `
+This path compares the managed policies attached to the IAM user of a static
+role, its inline policies and its tags with those configured on the role with
+the "policy_arns", "inline_policies" and "tags" parameters, and reports the
+differences, such as those caused by out-of-band edits. Only the aspects
+configured on the role are compared, and tags of the user which the role
+doesn't configure are ignored. When the role sets "remediate_drift", the
+differences are reverted each time the credential of the role is rotated.
+`
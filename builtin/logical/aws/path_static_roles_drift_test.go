@@ -0,0 +1,310 @@
+package aws
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/hashicorp/go-secure-stdlib/awsutil"
+	"github.com/hashicorp/vault/helper/timeutil"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+// driftIAM is an IAM client holding the policies and tags of a single user,
+// which pages its listings one element at a time.
+type driftIAM struct {
+	iamiface.IAMAPI
+	attached map[string]struct{}
+	inline   map[string]string
+	tags     map[string]string
+}
+
+// page returns the element of the sorted values at the marker, with the marker
+// of the next page if any.
+func page(values []string, marker *string) (string, *string, bool) {
+	sort.Strings(values)
+	i := 0
+	if marker != nil {
+		for i < len(values) && values[i] != *marker {
+			i++
+		}
+	}
+	if i+1 < len(values) {
+		return values[i], aws.String(values[i+1]), true
+	}
+	return values[i], nil, false
+}
+
+func (d *driftIAM) ListAttachedUserPolicies(in *iam.ListAttachedUserPoliciesInput) (*iam.ListAttachedUserPoliciesOutput, error) {
+	var arns []string
+	for arn := range d.attached {
+		arns = append(arns, arn)
+	}
+	if len(arns) == 0 {
+		return &iam.ListAttachedUserPoliciesOutput{IsTruncated: aws.Bool(false)}, nil
+	}
+	arn, marker, truncated := page(arns, in.Marker)
+	return &iam.ListAttachedUserPoliciesOutput{
+		AttachedPolicies: []*iam.AttachedPolicy{{PolicyArn: aws.String(arn)}},
+		Marker:           marker,
+		IsTruncated:      aws.Bool(truncated),
+	}, nil
+}
+
+func (d *driftIAM) ListUserPolicies(in *iam.ListUserPoliciesInput) (*iam.ListUserPoliciesOutput, error) {
+	var names []string
+	for name := range d.inline {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return &iam.ListUserPoliciesOutput{IsTruncated: aws.Bool(false)}, nil
+	}
+	name, marker, truncated := page(names, in.Marker)
+	return &iam.ListUserPoliciesOutput{
+		PolicyNames: []*string{aws.String(name)},
+		Marker:      marker,
+		IsTruncated: aws.Bool(truncated),
+	}, nil
+}
+
+func (d *driftIAM) GetUserPolicy(in *iam.GetUserPolicyInput) (*iam.GetUserPolicyOutput, error) {
+	return &iam.GetUserPolicyOutput{
+		PolicyName:     in.PolicyName,
+		PolicyDocument: aws.String(url.QueryEscape(d.inline[*in.PolicyName])),
+	}, nil
+}
+
+func (d *driftIAM) ListUserTags(in *iam.ListUserTagsInput) (*iam.ListUserTagsOutput, error) {
+	var keys []string
+	for key := range d.tags {
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return &iam.ListUserTagsOutput{IsTruncated: aws.Bool(false)}, nil
+	}
+	key, marker, truncated := page(keys, in.Marker)
+	return &iam.ListUserTagsOutput{
+		Tags:        []*iam.Tag{{Key: aws.String(key), Value: aws.String(d.tags[key])}},
+		Marker:      marker,
+		IsTruncated: aws.Bool(truncated),
+	}, nil
+}
+
+func (d *driftIAM) AttachUserPolicy(in *iam.AttachUserPolicyInput) (*iam.AttachUserPolicyOutput, error) {
+	d.attached[*in.PolicyArn] = struct{}{}
+	return &iam.AttachUserPolicyOutput{}, nil
+}
+
+func (d *driftIAM) DetachUserPolicy(in *iam.DetachUserPolicyInput) (*iam.DetachUserPolicyOutput, error) {
+	delete(d.attached, *in.PolicyArn)
+	return &iam.DetachUserPolicyOutput{}, nil
+}
+
+func (d *driftIAM) PutUserPolicy(in *iam.PutUserPolicyInput) (*iam.PutUserPolicyOutput, error) {
+	d.inline[*in.PolicyName] = *in.PolicyDocument
+	return &iam.PutUserPolicyOutput{}, nil
+}
+
+func (d *driftIAM) DeleteUserPolicy(in *iam.DeleteUserPolicyInput) (*iam.DeleteUserPolicyOutput, error) {
+	delete(d.inline, *in.PolicyName)
+	return &iam.DeleteUserPolicyOutput{}, nil
+}
+
+func (d *driftIAM) TagUser(in *iam.TagUserInput) (*iam.TagUserOutput, error) {
+	for _, tag := range in.Tags {
+		d.tags[*tag.Key] = *tag.Value
+	}
+	return &iam.TagUserOutput{}, nil
+}
+
+const (
+	readPolicy  = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"*"}]}`
+	writePolicy = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:PutObject","Resource":"*"}]}`
+)
+
+func driftRole() staticRoleEntry {
+	return staticRoleEntry{
+		Name:           "test",
+		ID:             "unique-id",
+		Username:       "jane-doe",
+		RotationPeriod: 24 * time.Hour,
+		PolicyARNs:     []string{"arn:aws:iam::aws:policy/ReadOnlyAccess", "arn:aws:iam::aws:policy/IAMUserChangePassword"},
+		InlinePolicies: map[string]string{"read": readPolicy, "write": writePolicy},
+		Tags:           map[string]string{"team": "storage", "env": "prod"},
+	}
+}
+
+// TestDetectStaticRoleDrift verifies that the drift of the IAM user from the policies and tags expected by the role
+// is detected, ignoring the formatting of policy documents and the tags which the role doesn't configure.
+func TestDetectStaticRoleDrift(t *testing.T) {
+	cases := []struct {
+		name     string
+		role     func(*staticRoleEntry)
+		iam      *driftIAM
+		expected *staticRoleDrift
+	}{
+		{
+			name: "no drift",
+			iam: &driftIAM{
+				attached: map[string]struct{}{"arn:aws:iam::aws:policy/ReadOnlyAccess": {}, "arn:aws:iam::aws:policy/IAMUserChangePassword": {}},
+				inline: map[string]string{
+					"read":  "{\n  \"Statement\": [{\"Resource\": \"*\", \"Action\": \"s3:GetObject\", \"Effect\": \"Allow\"}],\n  \"Version\": \"2012-10-17\"\n}",
+					"write": writePolicy,
+				},
+				tags: map[string]string{"team": "storage", "env": "prod", "owner": "someone"},
+			},
+			expected: &staticRoleDrift{},
+		},
+		{
+			name: "drift",
+			iam: &driftIAM{
+				attached: map[string]struct{}{"arn:aws:iam::aws:policy/ReadOnlyAccess": {}, "arn:aws:iam::aws:policy/AdministratorAccess": {}},
+				inline:   map[string]string{"read": writePolicy, "admin": `{"Statement":[]}`},
+				tags:     map[string]string{"team": "compute"},
+			},
+			expected: &staticRoleDrift{
+				MissingPolicyARNs:        []string{"arn:aws:iam::aws:policy/IAMUserChangePassword"},
+				UnexpectedPolicyARNs:     []string{"arn:aws:iam::aws:policy/AdministratorAccess"},
+				MissingInlinePolicies:    []string{"write"},
+				UnexpectedInlinePolicies: []string{"admin"},
+				ModifiedInlinePolicies:   []string{"read"},
+				MissingTags:              []string{"env"},
+				ModifiedTags:             []string{"team"},
+			},
+		},
+		{
+			name: "unconfigured aspects are not compared",
+			role: func(role *staticRoleEntry) {
+				role.PolicyARNs = nil
+				role.InlinePolicies = nil
+				role.Tags = nil
+			},
+			iam: &driftIAM{
+				attached: map[string]struct{}{"arn:aws:iam::aws:policy/AdministratorAccess": {}},
+				inline:   map[string]string{"admin": `{"Statement":[]}`},
+				tags:     map[string]string{"team": "compute"},
+			},
+			expected: &staticRoleDrift{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			role := driftRole()
+			if c.role != nil {
+				c.role(&role)
+			}
+
+			drift, err := detectStaticRoleDrift(c.iam, role)
+			require.NoError(t, err)
+			require.Equal(t, c.expected, drift)
+			require.Equal(t, len(c.expected.MissingPolicyARNs) > 0, drift.drifted())
+		})
+	}
+}
+
+// TestStaticRoleDriftRead verifies that the drift path reports the drift of the IAM user of a configured role, and
+// nothing for a role which doesn't exist.
+func TestStaticRoleDriftRead(t *testing.T) {
+	bgCTX := context.Background()
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+
+	b := Backend(config)
+	b.iamClient = &driftIAM{
+		attached: map[string]struct{}{"arn:aws:iam::aws:policy/ReadOnlyAccess": {}},
+		inline:   map[string]string{"read": readPolicy, "write": writePolicy},
+		tags:     map[string]string{"team": "storage", "env": "dev"},
+	}
+
+	entry, err := logical.StorageEntryJSON(formatRoleStoragePath("test"), driftRole())
+	require.NoError(t, err)
+	require.NoError(t, config.StorageView.Put(bgCTX, entry))
+
+	readDrift := func(name string) *logical.Response {
+		req := &logical.Request{
+			Operation: logical.ReadOperation,
+			Storage:   config.StorageView,
+			Path:      formatRoleStoragePath(name) + "/drift",
+		}
+		data := &framework.FieldData{
+			Raw:    map[string]interface{}{paramRoleName: name},
+			Schema: pathStaticRolesDrift(b).Fields,
+		}
+		resp, err := b.pathStaticRolesDriftRead(bgCTX, req, data)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := readDrift("test")
+	require.NotNil(t, resp)
+	require.Equal(t, true, resp.Data["drifted"])
+	require.Equal(t, []string{"arn:aws:iam::aws:policy/IAMUserChangePassword"}, resp.Data["missing_policy_arns"])
+	require.Equal(t, []string{"env"}, resp.Data["modified_tags"])
+	require.Empty(t, resp.Data["modified_inline_policies"])
+
+	require.Nil(t, readDrift("missing"))
+}
+
+// TestStaticRoleDriftRemediation verifies that the drift of the IAM user is reverted when its credential is rotated,
+// if the role asks for it.
+func TestStaticRoleDriftRemediation(t *testing.T) {
+	for _, remediate := range []bool{false, true} {
+		bgCTX := context.Background()
+		config := logical.TestBackendConfig()
+		config.StorageView = &logical.InmemStorage{}
+
+		clock := timeutil.NewManualClock(time.Now())
+		b := Backend(config)
+		setTestClock(b, clock)
+
+		role := driftRole()
+		role.RemediateDrift = remediate
+
+		miam, err := awsutil.NewMockIAM(
+			awsutil.WithListAccessKeysOutput(&iam.ListAccessKeysOutput{
+				AccessKeyMetadata: []*iam.AccessKeyMetadata{},
+			}),
+			awsutil.WithCreateAccessKeyOutput(&iam.CreateAccessKeyOutput{
+				AccessKey: &iam.AccessKey{
+					AccessKeyId:     aws.String("key"),
+					SecretAccessKey: aws.String("itsasecret"),
+				},
+			}),
+			awsutil.WithGetUserOutput(&iam.GetUserOutput{
+				User: &iam.User{
+					UserId:   aws.String(role.ID),
+					UserName: aws.String(role.Username),
+				},
+			}),
+		)(nil)
+		require.NoError(t, err)
+		driftClient := &driftIAM{
+			IAMAPI:   miam,
+			attached: map[string]struct{}{"arn:aws:iam::aws:policy/AdministratorAccess": {}},
+			inline:   map[string]string{"read": writePolicy},
+			tags:     map[string]string{"team": "compute", "owner": "someone"},
+		}
+		b.iamClient = driftClient
+
+		scheduleStaticRole(t, b, config.StorageView, role, role.RotationPeriod)
+
+		require.NoError(t, b.staticRotations.RotateExpired(bgCTX, config.StorageView))
+		next, _ := b.staticRotations.NextRotation(role.Name)
+		require.Equal(t, clock.Now().Add(role.RotationPeriod), next)
+
+		drift, err := detectStaticRoleDrift(driftClient, role)
+		require.NoError(t, err)
+		require.Equal(t, !remediate, drift.drifted())
+		if remediate {
+			require.Equal(t, "someone", driftClient.tags["owner"], "tags which the role doesn't configure must be kept")
+		}
+	}
+}
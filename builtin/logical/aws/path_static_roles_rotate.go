@@ -0,0 +1,77 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathStaticRolesRotate(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: fmt.Sprintf("%s/%s/rotate", pathStaticRole, framework.GenericNameWithAtRegex(paramRoleName)),
+		Fields: map[string]*framework.FieldSchema{
+			paramRoleName: {
+				Type:        framework.TypeString,
+				Description: descRoleName,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback:                    b.pathStaticRolesRotateWrite,
+				ForwardPerformanceSecondary: true,
+				ForwardPerformanceStandby:   true,
+				Responses: map[int][]framework.Response{
+					http.StatusNoContent: {{
+						Description: http.StatusText(http.StatusNoContent),
+					}},
+				},
+			},
+		},
+
+		HelpSynopsis:    pathStaticRolesRotateHelpSyn,
+		HelpDescription: pathStaticRolesRotateHelpDesc,
+	}
+}
+
+func (b *backend) pathStaticRolesRotateWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	roleName := data.Get(paramRoleName).(string)
+
+	cfg, err := b.readStaticRole(ctx, req.Storage, roleName)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return logical.ErrorResponse("static role %q not found", roleName), nil
+	}
+
+	// Roles missing from the schedule are scheduled again, and keep this
+	// schedule if the rotation fails.
+	if _, ok := b.staticRotations.NextRotation(cfg.Name); !ok {
+		if err := b.staticRotations.Schedule(ctx, req.Storage, cfg.Name, staticRoleSchedule(*cfg)); err != nil {
+			return nil, fmt.Errorf("failed to schedule the rotation of static role %q: %w", cfg.Name, err)
+		}
+	}
+
+	// The next scheduled rotation is a full rotation period from now
+	if err := b.staticRotations.RotateNow(ctx, req.Storage, cfg.Name); err != nil {
+		return nil, fmt.Errorf("failed to rotate credentials for role %q: %w", cfg.Name, err)
+	}
+
+	return nil, nil
+}
+
+const pathStaticRolesRotateHelpSyn = `
+Rotate the credential of a static role immediately.
+`
+
+const pathStaticRolesRotateHelpDesc = `
+This path rotates the access key of the IAM user of a static role immediately,
+regardless of its rotation period, for instance after the access key was
+exposed. The next scheduled rotation of the role happens a full rotation
+period after this one. When the role sets "remediate_drift", the drift of the
+IAM user is reverted too.
+`
@@ -0,0 +1,102 @@
+package aws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/hashicorp/go-secure-stdlib/awsutil"
+	"github.com/hashicorp/vault/helper/timeutil"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStaticRoleRotate verifies that the credential of a static role is rotated on demand, and that its next
+// scheduled rotation is a full rotation period later.
+func TestStaticRoleRotate(t *testing.T) {
+	bgCTX := context.Background()
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+
+	b := Backend(config)
+	clock := timeutil.NewManualClock(time.Now())
+	setTestClock(b, clock)
+
+	role := staticRoleEntry{
+		Name:           "test",
+		Username:       "jane-doe",
+		ID:             "unique-id",
+		RotationPeriod: 24 * time.Hour,
+	}
+
+	miam, err := awsutil.NewMockIAM(
+		awsutil.WithListAccessKeysOutput(&iam.ListAccessKeysOutput{
+			AccessKeyMetadata: []*iam.AccessKeyMetadata{},
+		}),
+		awsutil.WithCreateAccessKeyOutput(&iam.CreateAccessKeyOutput{
+			AccessKey: &iam.AccessKey{
+				AccessKeyId:     aws.String("new-key"),
+				SecretAccessKey: aws.String("new-secret"),
+			},
+		}),
+		awsutil.WithGetUserOutput(&iam.GetUserOutput{
+			User: &iam.User{
+				UserId:   aws.String(role.ID),
+				UserName: aws.String(role.Username),
+			},
+		}),
+	)(nil)
+	require.NoError(t, err)
+	b.iamClient = miam
+
+	entry, err := logical.StorageEntryJSON(formatCredsStoragePath(role.Name), &awsCredentials{
+		AccessKeyID:     "old-key",
+		SecretAccessKey: "old-secret",
+	})
+	require.NoError(t, err)
+	require.NoError(t, config.StorageView.Put(bgCTX, entry))
+	// the next scheduled rotation is in an hour
+	scheduleStaticRole(t, b, config.StorageView, role, role.RotationPeriod-time.Hour)
+
+	rotate := func(name string) (*logical.Response, error) {
+		req := &logical.Request{
+			Operation: logical.UpdateOperation,
+			Storage:   config.StorageView,
+			Path:      formatRoleStoragePath(name) + "/rotate",
+		}
+		data := &framework.FieldData{
+			Raw:    map[string]interface{}{paramRoleName: name},
+			Schema: pathStaticRolesRotate(b).Fields,
+		}
+		return b.pathStaticRolesRotateWrite(bgCTX, req, data)
+	}
+
+	clock.Advance(time.Minute)
+	resp, err := rotate(role.Name)
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	entry, err = config.StorageView.Get(bgCTX, formatCredsStoragePath(role.Name))
+	require.NoError(t, err)
+	var creds awsCredentials
+	require.NoError(t, entry.DecodeJSON(&creds))
+	require.Equal(t, "new-key", creds.AccessKeyID)
+
+	next, ok := b.staticRotations.NextRotation(role.Name)
+	require.True(t, ok)
+	require.Equal(t, clock.Now().Add(role.RotationPeriod), next)
+
+	// roles missing from the schedule are scheduled again
+	require.NoError(t, b.staticRotations.Unschedule(bgCTX, config.StorageView, role.Name))
+	resp, err = rotate(role.Name)
+	require.NoError(t, err)
+	require.Nil(t, resp)
+	require.Equal(t, 1, b.staticRotations.Len())
+
+	resp, err = rotate("missing")
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+}
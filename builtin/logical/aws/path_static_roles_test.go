@@ -6,10 +6,9 @@ import (
 	"testing"
 	"time"
 
-	"github.com/hashicorp/vault/sdk/queue"
-
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
 	"github.com/hashicorp/go-secure-stdlib/awsutil"
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
@@ -86,6 +85,19 @@ func TestStaticRolesValidation(t *testing.T) {
 			},
 			isError: true,
 		},
+		{
+			name: "bad inline policy",
+			opts: []awsutil.MockIAMOption{
+				awsutil.WithGetUserOutput(&iam.GetUserOutput{User: &iam.User{UserName: aws.String("jane-doe"), UserId: aws.String("unique-id")}}),
+			},
+			requestData: map[string]interface{}{
+				"name":            "test",
+				"username":        "jane-doe",
+				"rotation_period": "1d",
+				"inline_policies": map[string]interface{}{"read": `{"Version":`},
+			},
+			isError: true,
+		},
 	}
 
 	for _, c := range cases {
@@ -290,6 +302,99 @@ func TestStaticRolesWrite(t *testing.T) {
 	}
 }
 
+// TestStaticRolesWrite_AssumeRole validates that the IAM user of a static role assuming a role is managed with the
+// credentials of that role, and that the role can't be changed afterwards.
+func TestStaticRolesWrite_AssumeRole(t *testing.T) {
+	bgCTX := context.Background()
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	const roleARN = "arn:aws:iam::123456789012:role/vault-static-roles"
+
+	rootIAM, err := awsutil.NewMockIAM(
+		awsutil.WithGetUserError(errors.New("no such user in the root account")),
+	)(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	memberIAM, err := awsutil.NewMockIAM(
+		awsutil.WithGetUserOutput(&iam.GetUserOutput{User: &iam.User{UserName: aws.String("jane-doe"), UserId: aws.String("unique-id")}}),
+		awsutil.WithListAccessKeysOutput(&iam.ListAccessKeysOutput{
+			AccessKeyMetadata: []*iam.AccessKeyMetadata{},
+			IsTruncated:       aws.Bool(false),
+		}),
+		awsutil.WithCreateAccessKeyOutput(&iam.CreateAccessKeyOutput{
+			AccessKey: &iam.AccessKey{
+				AccessKeyId:     aws.String("abcdefghijklmnopqrstuvwxyz"),
+				SecretAccessKey: aws.String("zyxwvutsrqponmlkjihgfedcba"),
+				UserName:        aws.String("jane-doe"),
+			},
+		}),
+	)(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := Backend(config)
+	b.iamClient = rootIAM
+	b.assumedRoleIAMClients = map[string]iamiface.IAMAPI{roleARN: memberIAM}
+	if err := b.Setup(bgCTX, config); err != nil {
+		t.Fatal(err)
+	}
+
+	write := func(data map[string]interface{}) (*logical.Response, error) {
+		req := &logical.Request{
+			Operation: logical.UpdateOperation,
+			Storage:   config.StorageView,
+			Data:      data,
+			Path:      "static-roles/test",
+		}
+		return b.pathStaticRolesWrite(bgCTX, req, staticRoleFieldData(req.Data))
+	}
+
+	resp, err := write(map[string]interface{}{
+		"name":            "test",
+		"username":        "jane-doe",
+		"rotation_period": "1d",
+		"assume_role_arn": "arn:aws:iam::123456789012:user/not-a-role",
+	})
+	if err != nil || !resp.IsError() {
+		t.Fatalf("expected an error response, got: %#v, %v", resp, err)
+	}
+
+	resp, err = write(map[string]interface{}{
+		"name":            "test",
+		"username":        "jane-doe",
+		"rotation_period": "1d",
+		"assume_role_arn": roleARN,
+	})
+	if err != nil {
+		t.Fatalf("got an unexpected error: %s", err)
+	}
+	if resp.Data[paramAssumeRoleARN] != roleARN {
+		t.Fatalf("bad: %#v", resp.Data)
+	}
+
+	entry, err := config.StorageView.Get(bgCTX, formatCredsStoragePath("test"))
+	if err != nil || entry == nil {
+		t.Fatalf("couldn't find the credential of the role: %v", err)
+	}
+	var creds awsCredentials
+	if err := entry.DecodeJSON(&creds); err != nil {
+		t.Fatal(err)
+	}
+	if creds.AccessKeyID != "abcdefghijklmnopqrstuvwxyz" {
+		t.Fatalf("bad: access key %q", creds.AccessKeyID)
+	}
+
+	resp, err = write(map[string]interface{}{
+		"name":            "test",
+		"assume_role_arn": "arn:aws:iam::210987654321:role/vault-static-roles",
+	})
+	if err != nil || !resp.IsError() {
+		t.Fatalf("expected an error response, got: %#v, %v", resp, err)
+	}
+}
+
 // TestStaticRoleRead validates that we can read a configured role and correctly do not read anything if we
 // request something that doesn't exist.
 func TestStaticRoleRead(t *testing.T) {
@@ -416,19 +521,15 @@ func TestStaticRoleDelete(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			l, err := config.StorageView.List(bgCTX, "")
+			l, err := config.StorageView.List(bgCTX, formatRoleStoragePath(""))
 			if err != nil || len(l) != 1 {
 				t.Fatalf("couldn't add an entry to storage during test setup: %s", err)
 			}
 
-			// put in queue
-			err = b.credRotationQueue.Push(&queue.Item{
-				Key:      staticRole.Name,
-				Value:    staticRole,
-				Priority: time.Now().Add(90 * time.Hour).Unix(),
-			})
+			// schedule the rotation
+			err = b.staticRotations.Schedule(bgCTX, config.StorageView, staticRole.Name, staticRoleSchedule(staticRole))
 			if err != nil {
-				t.Fatalf("couldn't add items to pq")
+				t.Fatalf("couldn't schedule the rotation: %s", err)
 			}
 
 			req := &logical.Request{
@@ -448,7 +549,7 @@ func TestStaticRoleDelete(t *testing.T) {
 				t.Fatal("response wasn't nil, but it should have been")
 			}
 
-			l, err = config.StorageView.List(bgCTX, "")
+			l, err = config.StorageView.List(bgCTX, formatRoleStoragePath(""))
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -458,10 +559,10 @@ func TestStaticRoleDelete(t *testing.T) {
 				t.Fatal("size of role storage changed after what should have been no deletion")
 			}
 
-			if c.found && b.credRotationQueue.Len() != 0 {
-				t.Fatal("size of queue is non-zero after delete")
-			} else if !c.found && b.credRotationQueue.Len() != 1 {
-				t.Fatal("size of queue changed after what should have been no deletion")
+			if c.found && b.staticRotations.Len() != 0 {
+				t.Fatal("size of rotation schedule is non-zero after delete")
+			} else if !c.found && b.staticRotations.Len() != 1 {
+				t.Fatal("size of rotation schedule changed after what should have been no deletion")
 			}
 		})
 	}
@@ -481,6 +582,26 @@ func staticRoleFieldData(data map[string]interface{}) *framework.FieldData {
 			Type:        framework.TypeDurationSecond,
 			Description: descRotationPeriod,
 		},
+		paramPolicyARNs: {
+			Type:        framework.TypeCommaStringSlice,
+			Description: descPolicyARNs,
+		},
+		paramInlinePolicies: {
+			Type:        framework.TypeKVPairs,
+			Description: descInlinePolicies,
+		},
+		paramTags: {
+			Type:        framework.TypeKVPairs,
+			Description: descTags,
+		},
+		paramRemediateDrift: {
+			Type:        framework.TypeBool,
+			Description: descRemediateDrift,
+		},
+		paramAssumeRoleARN: {
+			Type:        framework.TypeString,
+			Description: descAssumeRoleARN,
+		},
 	}
 
 	return &framework.FieldData{
@@ -0,0 +1,159 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathStaticRolesVerify(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: fmt.Sprintf("%s/%s/verify", pathStaticRole, framework.GenericNameWithAtRegex(paramRoleName)),
+		Fields: map[string]*framework.FieldSchema{
+			paramRoleName: {
+				Type:        framework.TypeString,
+				Description: descRoleName,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathStaticRolesVerifyRead,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: http.StatusText(http.StatusOK),
+						Fields: map[string]*framework.FieldSchema{
+							"valid": {
+								Type:        framework.TypeBool,
+								Description: "Whether AWS accepts the stored credential, as the access key of the IAM user of the role.",
+							},
+							"access_key_id": {
+								Type:        framework.TypeString,
+								Description: "The ID of the stored access key.",
+							},
+							"arn": {
+								Type:        framework.TypeString,
+								Description: "The ARN AWS identifies the stored credential with, when it is accepted.",
+							},
+							"error": {
+								Type:        framework.TypeString,
+								Description: "Why the stored credential is not valid.",
+							},
+							"last_used_date": {
+								Type:        framework.TypeTime,
+								Description: "When the access key was last used, if ever.",
+							},
+							"last_used_service": {
+								Type:        framework.TypeString,
+								Description: "The AWS service the access key was last used with.",
+							},
+							"last_used_region": {
+								Type:        framework.TypeString,
+								Description: "The AWS region the access key was last used in.",
+							},
+						},
+					}},
+				},
+			},
+		},
+
+		HelpSynopsis:    pathStaticRolesVerifyHelpSyn,
+		HelpDescription: pathStaticRolesVerifyHelpDesc,
+	}
+}
+
+func (b *backend) pathStaticRolesVerifyRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	roleName := data.Get(paramRoleName).(string)
+
+	b.roleMutex.RLock()
+	roleEntry, err := req.Storage.Get(ctx, formatRoleStoragePath(roleName))
+	if err != nil {
+		b.roleMutex.RUnlock()
+		return nil, fmt.Errorf("failed to read configuration for static role %q: %w", roleName, err)
+	}
+	credsEntry, err := req.Storage.Get(ctx, formatCredsStoragePath(roleName))
+	b.roleMutex.RUnlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials for role %q: %w", roleName, err)
+	}
+	if roleEntry == nil {
+		return nil, nil
+	}
+	if credsEntry == nil {
+		return logical.ErrorResponse("static role %q has no credential", roleName), nil
+	}
+
+	var cfg staticRoleEntry
+	if err := roleEntry.DecodeJSON(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode configuration for static role %q: %w", roleName, err)
+	}
+	var creds awsCredentials
+	if err := credsEntry.DecodeJSON(&creds); err != nil {
+		return nil, fmt.Errorf("failed to decode credentials: %w", err)
+	}
+
+	respData := map[string]interface{}{
+		"valid":         false,
+		"access_key_id": creds.AccessKeyID,
+	}
+
+	// Authenticate with the credential itself: this fails if the access key
+	// was deleted or deactivated out-of-band.
+	stsClient, err := b.credentialsSTSClient(ctx, req.Storage, &creds)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the AWS STS client: %w", err)
+	}
+	identity, err := stsClient.GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	switch {
+	case err != nil:
+		respData["error"] = err.Error()
+	case aws.StringValue(identity.UserId) != cfg.ID:
+		respData["arn"] = aws.StringValue(identity.Arn)
+		respData["error"] = fmt.Sprintf("the credential belongs to %q rather than the IAM user of the role", aws.StringValue(identity.Arn))
+	default:
+		respData["arn"] = aws.StringValue(identity.Arn)
+		respData["valid"] = true
+	}
+
+	// The last use of the access key is best-effort, as reading it requires
+	// the iam:GetAccessKeyLastUsed permission.
+	iamClient, err := b.clientIAMAssumingRole(ctx, req.Storage, cfg.AssumeRoleARN)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the AWS IAM client: %w", err)
+	}
+	lastUsed, err := iamClient.GetAccessKeyLastUsed(&iam.GetAccessKeyLastUsedInput{
+		AccessKeyId: aws.String(creds.AccessKeyID),
+	})
+	if err != nil {
+		b.Logger().Debug("unable to read the last use of the access key of static role", "role", cfg.Name, "error", err)
+	} else if lastUsed.AccessKeyLastUsed != nil {
+		if lastUsed.AccessKeyLastUsed.LastUsedDate != nil {
+			respData["last_used_date"] = lastUsed.AccessKeyLastUsed.LastUsedDate.UTC().Format(time.RFC3339)
+		}
+		respData["last_used_service"] = aws.StringValue(lastUsed.AccessKeyLastUsed.ServiceName)
+		respData["last_used_region"] = aws.StringValue(lastUsed.AccessKeyLastUsed.Region)
+	}
+
+	return &logical.Response{
+		Data: respData,
+	}, nil
+}
+
+const pathStaticRolesVerifyHelpSyn = `
+Check that the credential of a static role is still valid in AWS.
+`
+
+const pathStaticRolesVerifyHelpDesc = `
+This path authenticates to AWS with the stored access key of a static role, and
+reports whether AWS still accepts it as the access key of the IAM user of the
+role. It is not valid anymore when the access key was deleted or deactivated
+out-of-band. The last use of the access key is reported too, when the root
+credentials are allowed to read it.
+`
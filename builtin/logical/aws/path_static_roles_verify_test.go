@@ -0,0 +1,102 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+	"github.com/hashicorp/go-secure-stdlib/awsutil"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+// lastUsedIAM is an IAM client reporting the last use of access keys.
+type lastUsedIAM struct {
+	iamiface.IAMAPI
+	lastUsed *iam.AccessKeyLastUsed
+}
+
+func (l *lastUsedIAM) GetAccessKeyLastUsed(*iam.GetAccessKeyLastUsedInput) (*iam.GetAccessKeyLastUsedOutput, error) {
+	return &iam.GetAccessKeyLastUsedOutput{AccessKeyLastUsed: l.lastUsed}, nil
+}
+
+// TestStaticRoleVerify verifies that the stored credential of a static role is reported valid only when AWS accepts
+// it as the access key of the IAM user of the role.
+func TestStaticRoleVerify(t *testing.T) {
+	bgCTX := context.Background()
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+
+	b := Backend(config)
+	lastUsedDate := time.Date(2023, 7, 12, 9, 41, 5, 0, time.UTC)
+	b.iamClient = &lastUsedIAM{lastUsed: &iam.AccessKeyLastUsed{
+		LastUsedDate: aws.Time(lastUsedDate),
+		ServiceName:  aws.String("s3"),
+		Region:       aws.String("us-east-1"),
+	}}
+
+	role := staticRoleEntry{
+		Name:           "test",
+		Username:       "jane-doe",
+		ID:             "unique-id",
+		RotationPeriod: 24 * time.Hour,
+	}
+	entry, err := logical.StorageEntryJSON(formatRoleStoragePath(role.Name), role)
+	require.NoError(t, err)
+	require.NoError(t, config.StorageView.Put(bgCTX, entry))
+	entry, err = logical.StorageEntryJSON(formatCredsStoragePath(role.Name), &awsCredentials{
+		AccessKeyID:     "key",
+		SecretAccessKey: "secret",
+	})
+	require.NoError(t, err)
+	require.NoError(t, config.StorageView.Put(bgCTX, entry))
+
+	verify := func(name string, opts ...awsutil.MockSTSOption) *logical.Response {
+		b.credentialsSTSClient = func(_ context.Context, _ logical.Storage, creds *awsCredentials) (stsiface.STSAPI, error) {
+			require.Equal(t, "key", creds.AccessKeyID)
+			return awsutil.NewMockSTS(opts...)(nil)
+		}
+		req := &logical.Request{
+			Operation: logical.ReadOperation,
+			Storage:   config.StorageView,
+			Path:      formatRoleStoragePath(name) + "/verify",
+		}
+		data := &framework.FieldData{
+			Raw:    map[string]interface{}{paramRoleName: name},
+			Schema: pathStaticRolesVerify(b).Fields,
+		}
+		resp, err := b.pathStaticRolesVerifyRead(bgCTX, req, data)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := verify(role.Name, awsutil.WithGetCallerIdentityOutput(&sts.GetCallerIdentityOutput{
+		Arn:    aws.String("arn:aws:iam::123456789012:user/jane-doe"),
+		UserId: aws.String(role.ID),
+	}))
+	require.Equal(t, true, resp.Data["valid"])
+	require.Equal(t, "arn:aws:iam::123456789012:user/jane-doe", resp.Data["arn"])
+	require.Equal(t, lastUsedDate.Format(time.RFC3339), resp.Data["last_used_date"])
+	require.Equal(t, "s3", resp.Data["last_used_service"])
+
+	// the access key was deleted out-of-band
+	resp = verify(role.Name, awsutil.WithGetCallerIdentityError(errors.New("InvalidClientTokenId")))
+	require.Equal(t, false, resp.Data["valid"])
+	require.Contains(t, resp.Data["error"], "InvalidClientTokenId")
+
+	// the access key belongs to another user
+	resp = verify(role.Name, awsutil.WithGetCallerIdentityOutput(&sts.GetCallerIdentityOutput{
+		Arn:    aws.String("arn:aws:iam::123456789012:user/john-doe"),
+		UserId: aws.String("other-id"),
+	}))
+	require.Equal(t, false, resp.Data["valid"])
+
+	require.Nil(t, verify("missing"))
+}
@@ -34,7 +34,7 @@ func pathUser(b *backend) *framework.Path {
 			},
 			"role_arn": {
 				Type:        framework.TypeString,
-				Description: "ARN of role to assume when credential_type is " + assumedRoleCred,
+				Description: fmt.Sprintf("ARN of role to assume when credential_type is %s or %s", assumedRoleCred, webIdentityCred),
 			},
 			"ttl": {
 				Type:        framework.TypeDurationSecond,
@@ -136,10 +136,20 @@ func (b *backend) pathCredsRead(ctx context.Context, req *logical.Request, d *fr
 		}
 	}
 
+	if role.PolicyDocumentTemplate {
+		policyDocument, err := renderPolicyDocument(role.PolicyDocument, req.EntityID, b.System())
+		if err != nil {
+			return logical.ErrorResponse("unable to render policy_document of role %q: %s", roleName, err), nil
+		}
+		rendered := *role
+		rendered.PolicyDocument = policyDocument
+		role = &rendered
+	}
+
 	switch credentialType {
 	case iamUserCred:
 		return b.secretAccessKeysCreate(ctx, req.Storage, req.DisplayName, roleName, role)
-	case assumedRoleCred:
+	case assumedRoleCred, webIdentityCred:
 		switch {
 		case roleArn == "":
 			if len(role.RoleArns) != 1 {
@@ -149,9 +159,14 @@ func (b *backend) pathCredsRead(ctx context.Context, req *logical.Request, d *fr
 		case !strutil.StrListContains(role.RoleArns, roleArn):
 			return logical.ErrorResponse(fmt.Sprintf("role_arn %q not in allowed role arns for Vault role %q", roleArn, roleName)), nil
 		}
+		if credentialType == webIdentityCred {
+			return b.assumeRoleWithWebIdentity(ctx, req.Storage, req.DisplayName, req.ID, req.EntityID, roleName, roleArn, role, ttl, roleSessionName)
+		}
 		return b.assumeRole(ctx, req.Storage, req.DisplayName, roleName, roleArn, role.PolicyDocument, role.PolicyArns, role.IAMGroups, ttl, roleSessionName)
 	case federationTokenCred:
 		return b.getFederationToken(ctx, req.Storage, req.DisplayName, roleName, role.PolicyDocument, role.PolicyArns, role.IAMGroups, ttl)
+	case identityCenterCred:
+		return b.identityCenterCredentials(ctx, req.Storage, role)
 	default:
 		return logical.ErrorResponse(fmt.Sprintf("unknown credential_type: %q", credentialType)), nil
 	}
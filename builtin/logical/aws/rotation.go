@@ -7,74 +7,162 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/iam"
-	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/vault/helper/timeutil"
 	"github.com/hashicorp/vault/sdk/logical"
-	"github.com/hashicorp/vault/sdk/queue"
+	"github.com/hashicorp/vault/sdk/rotation"
 )
 
-// rotateExpiredStaticCreds will pop expired credentials (credentials whose priority
-// represents a time before the present), rotate the associated credential, and push
-// them back onto the queue with the new priority.
-func (b *backend) rotateExpiredStaticCreds(ctx context.Context, req *logical.Request) error {
-	var errs *multierror.Error
+// staticRotationsStoragePrefix is the storage prefix the rotation schedules
+// of static roles are persisted under.
+const staticRotationsStoragePrefix = "static-rotations/"
 
-	for {
-		keepGoing, err := b.rotateCredential(ctx, req.Storage)
+// newStaticRotationManager returns the manager of the rotations of the
+// credentials of static roles, keyed by role name, driven by the given clock.
+func (b *backend) newStaticRotationManager(clock timeutil.Clock) *rotation.RotationManager {
+	return rotation.NewRotationManager(rotation.RotationManagerConfig{
+		StoragePrefix: staticRotationsStoragePrefix,
+		Rotate:        b.rotateStaticRole,
+		Hooks: rotation.Hooks{
+			OnSuccess: b.staticRoleRotated,
+			OnFailure: b.staticRoleRotationFailed,
+		},
+		RetryInterval: staticRotationRetryInterval,
+		Clock:         clock,
+	})
+}
+
+// initialize loads the rotation schedules of the static roles, and schedules
+// the roles created before their schedules were persisted.
+func (b *backend) initialize(ctx context.Context, req *logical.InitializationRequest) error {
+	if !b.WriteSafeReplicationState() {
+		return nil
+	}
+
+	if err := b.staticRotations.Initialize(ctx, req.Storage); err != nil {
+		return err
+	}
+
+	names, err := req.Storage.List(ctx, pathStaticRole+"/")
+	if err != nil {
+		return fmt.Errorf("failed to list static roles: %w", err)
+	}
+	for _, name := range names {
+		if _, ok := b.staticRotations.NextRotation(name); ok {
+			continue
+		}
+		cfg, err := b.readStaticRole(ctx, req.Storage, name)
 		if err != nil {
-			errs = multierror.Append(errs, err)
+			return err
 		}
-		if !keepGoing {
-			if errs.ErrorOrNil() != nil {
-				return fmt.Errorf("error(s) occurred while rotating expired static credentials: %w", errs)
-			} else {
-				return nil
-			}
+		if cfg == nil {
+			continue
 		}
+		if err := b.staticRotations.Schedule(ctx, req.Storage, cfg.Name, staticRoleSchedule(*cfg)); err != nil {
+			return fmt.Errorf("failed to schedule the rotation of static role %q: %w", cfg.Name, err)
+		}
+	}
+	return nil
+}
+
+// staticRoleSchedule returns the rotation schedule of a static role.
+func staticRoleSchedule(cfg staticRoleEntry) rotation.Schedule {
+	return rotation.Schedule{
+		Period: cfg.RotationPeriod,
 	}
 }
 
-// rotateCredential pops an element from the priority queue, and if it is expired, rotate and re-push.
-// If a cred was rotated, it returns true, otherwise false.
-func (b *backend) rotateCredential(ctx context.Context, storage logical.Storage) (rotated bool, err error) {
-	// If queue is empty or first item does not need a rotation (priority is next rotation timestamp) there is nothing to do
-	item, err := b.credRotationQueue.Pop()
+// readStaticRole reads the configuration of a static role, which is nil if
+// the role doesn't exist.
+func (b *backend) readStaticRole(ctx context.Context, storage logical.Storage, name string) (*staticRoleEntry, error) {
+	b.roleMutex.RLock()
+	entry, err := storage.Get(ctx, formatRoleStoragePath(name))
+	b.roleMutex.RUnlock()
 	if err != nil {
-		// the queue is just empty, which is fine.
-		if err == queue.ErrEmpty {
-			return false, nil
-		}
-		return false, fmt.Errorf("failed to pop from queue for role %q: %w", item.Key, err)
+		return nil, fmt.Errorf("failed to read configuration for static role %q: %w", name, err)
 	}
-	if item.Priority > time.Now().Unix() {
-		// no rotation required
-		// push the item back into priority queue
-		err = b.credRotationQueue.Push(item)
-		if err != nil {
-			return false, fmt.Errorf("failed to add item into the rotation queue for role %q: %w", item.Key, err)
+	if entry == nil {
+		return nil, nil
+	}
+
+	var cfg staticRoleEntry
+	if err := entry.DecodeJSON(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode configuration for static role %q: %w", name, err)
+	}
+	return &cfg, nil
+}
+
+// rotateStaticRole rotates the credential of a static role, for the rotation
+// manager.
+func (b *backend) rotateStaticRole(ctx context.Context, storage logical.Storage, name string) error {
+	cfg, err := b.readStaticRole(ctx, storage, name)
+	if err != nil {
+		return err
+	}
+	if cfg == nil {
+		return fmt.Errorf("static role %q: %w", name, rotation.ErrCredentialNotFound)
+	}
+
+	return b.createCredential(ctx, storage, *cfg, true)
+}
+
+// staticRoleRotated clears the rotation error of a static role whose
+// credential was rotated, and reverts the drift of its IAM user if the role
+// asks for it.
+func (b *backend) staticRoleRotated(ctx context.Context, storage logical.Storage, name string) error {
+	cfg, err := b.readStaticRole(ctx, storage, name)
+	if err != nil || cfg == nil {
+		return err
+	}
+
+	if cfg.LastRotationError != "" {
+		if err := b.setLastRotationError(ctx, storage, cfg.Name, ""); err != nil {
+			b.Logger().Warn("unable to clear the rotation error of static role", "role", cfg.Name, "error", err)
 		}
-		return false, nil
 	}
 
-	cfg := item.Value.(staticRoleEntry)
+	if cfg.RemediateDrift {
+		return b.remediateDrift(ctx, storage, *cfg)
+	}
+	return nil
+}
 
-	err = b.createCredential(ctx, storage, cfg, true)
+// staticRoleRotationFailed reports the failed scheduled rotation of the
+// credential of a static role.
+func (b *backend) staticRoleRotationFailed(ctx context.Context, storage logical.Storage, name string, rotationErr error, retry time.Time) {
+	cfg, err := b.readStaticRole(ctx, storage, name)
+	if err != nil || cfg == nil {
+		b.Logger().Error("failed to rotate the credential of static role", "role", name, "error", rotationErr)
+		return
+	}
+	b.reportRotationFailure(ctx, storage, *cfg, rotationErr, retry)
+}
+
+// remediateDrift reverts the drift of the IAM user of the role from the
+// policies and tags expected by the role.
+func (b *backend) remediateDrift(ctx context.Context, storage logical.Storage, cfg staticRoleEntry) error {
+	iamClient, err := b.clientIAMAssumingRole(ctx, storage, cfg.AssumeRoleARN)
 	if err != nil {
-		return false, err
+		return fmt.Errorf("unable to get the AWS IAM client: %w", err)
 	}
 
-	// set new priority and re-queue
-	item.Priority = time.Now().Add(cfg.RotationPeriod).Unix()
-	err = b.credRotationQueue.Push(item)
+	drift, err := detectStaticRoleDrift(iamClient, cfg)
 	if err != nil {
-		return false, fmt.Errorf("failed to add item into the rotation queue for role %q: %w", cfg.Name, err)
+		return fmt.Errorf("failed to detect drift for role %q: %w", cfg.Name, err)
+	}
+	if !drift.drifted() {
+		return nil
 	}
 
-	return true, nil
+	b.Logger().Warn("reverting drift of the IAM user of static role", "role", cfg.Name, "username", cfg.Username)
+	if err := remediateStaticRoleDrift(iamClient, cfg, drift); err != nil {
+		return fmt.Errorf("failed to remediate drift for role %q: %w", cfg.Name, err)
+	}
+	return nil
 }
 
 // createCredential will create a new iam credential, deleting the oldest one if necessary.
 func (b *backend) createCredential(ctx context.Context, storage logical.Storage, cfg staticRoleEntry, shouldLockStorage bool) error {
-	iamClient, err := b.clientIAM(ctx, storage)
+	iamClient, err := b.clientIAMAssumingRole(ctx, storage, cfg.AssumeRoleARN)
 	if err != nil {
 		return fmt.Errorf("unable to get the AWS IAM client: %w", err)
 	}
@@ -175,8 +263,13 @@ func (b *backend) deleteCredential(ctx context.Context, storage logical.Storage,
 		return fmt.Errorf("couldn't delete from storage: %w", err)
 	}
 
+	iamClient, err := b.clientIAMAssumingRole(ctx, storage, cfg.AssumeRoleARN)
+	if err != nil {
+		return fmt.Errorf("unable to get the AWS IAM client: %w", err)
+	}
+
 	// because we have the information, this is the one we created, so it's safe for us to delete.
-	_, err = b.iamClient.DeleteAccessKey(&iam.DeleteAccessKeyInput{
+	_, err = iamClient.DeleteAccessKey(&iam.DeleteAccessKeyInput{
 		AccessKeyId: aws.String(creds.AccessKeyID),
 		UserName:    aws.String(cfg.Username),
 	})
@@ -0,0 +1,96 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// eventTypeStaticRotationFailure is the type of the events sent when the
+	// credential of a static role fails to rotate.
+	eventTypeStaticRotationFailure logical.EventType = "aws/static-role-rotation-failure"
+
+	// staticRotationRetryInterval is how long after a failed rotation the
+	// credential of a static role is rotated again.
+	staticRotationRetryInterval = time.Minute
+)
+
+// reportRotationFailure alerts about the failed rotation of the credential of
+// a static role: it is logged, counted by the
+// vault.aws.static_rotation.failure metric, sent as an event, and recorded as
+// the last rotation error of the role. The rotation is retried at the given
+// time.
+func (b *backend) reportRotationFailure(ctx context.Context, storage logical.Storage, cfg staticRoleEntry, rotationErr error, retry time.Time) {
+	b.Logger().Error("failed to rotate the credential of static role", "role", cfg.Name, "username", cfg.Username, "error", rotationErr)
+
+	metrics.IncrCounterWithLabels([]string{"aws", "static_rotation", "failure"}, 1, []metrics.Label{
+		{Name: "role", Value: cfg.Name},
+	})
+
+	if err := b.setLastRotationError(ctx, storage, cfg.Name, rotationErr.Error()); err != nil {
+		b.Logger().Warn("unable to record the rotation error of static role", "role", cfg.Name, "error", err)
+	}
+
+	b.sendRotationFailureEvent(ctx, cfg, rotationErr, retry)
+}
+
+// setLastRotationError records the error of the last rotation of the
+// credential of a static role, or clears it if the error is empty.
+func (b *backend) setLastRotationError(ctx context.Context, storage logical.Storage, roleName, rotationErr string) error {
+	b.roleMutex.Lock()
+	defer b.roleMutex.Unlock()
+
+	entry, err := storage.Get(ctx, formatRoleStoragePath(roleName))
+	if err != nil {
+		return fmt.Errorf("failed to read configuration for static role %q: %w", roleName, err)
+	}
+	// the role was deleted meanwhile
+	if entry == nil {
+		return nil
+	}
+
+	var cfg staticRoleEntry
+	if err := entry.DecodeJSON(&cfg); err != nil {
+		return fmt.Errorf("failed to decode configuration for static role %q: %w", roleName, err)
+	}
+	if cfg.LastRotationError == rotationErr {
+		return nil
+	}
+	cfg.LastRotationError = rotationErr
+
+	entry, err = logical.StorageEntryJSON(formatRoleStoragePath(roleName), cfg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal object to JSON: %w", err)
+	}
+	return storage.Put(ctx, entry)
+}
+
+func (b *backend) sendRotationFailureEvent(ctx context.Context, cfg staticRoleEntry, rotationErr error, retry time.Time) {
+	event, err := logical.NewEvent()
+	if err != nil {
+		b.Logger().Warn("unable to create event", "event_type", eventTypeStaticRotationFailure, "error", err)
+		return
+	}
+	event.Metadata, err = structpb.NewStruct(map[string]interface{}{
+		"role":       cfg.Name,
+		"username":   cfg.Username,
+		"error":      rotationErr.Error(),
+		"next_retry": retry.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		b.Logger().Warn("unable to encode event metadata", "event_type", eventTypeStaticRotationFailure, "error", err)
+		return
+	}
+
+	err = b.SendEvent(ctx, eventTypeStaticRotationFailure, event)
+	if err != nil && !errors.Is(err, framework.ErrNoEvents) {
+		b.Logger().Warn("unable to send event", "event_type", eventTypeStaticRotationFailure, "error", err)
+	}
+}
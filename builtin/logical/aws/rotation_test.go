@@ -11,11 +11,41 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/hashicorp/go-secure-stdlib/awsutil"
+	"github.com/hashicorp/vault/helper/timeutil"
 	"github.com/hashicorp/vault/sdk/logical"
-	"github.com/hashicorp/vault/sdk/queue"
 )
 
-// TestRotation verifies that the rotation code and priority queue correctly selects and rotates credentials
+// scheduleStaticRole stores the configuration of a static role, and schedules
+// the rotation of its credential as if the role was created age ago.
+func scheduleStaticRole(t *testing.T, b *backend, storage logical.Storage, cfg staticRoleEntry, age time.Duration) {
+	t.Helper()
+
+	entry, err := logical.StorageEntryJSON(formatRoleStoragePath(cfg.Name), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.Put(context.Background(), entry); err != nil {
+		t.Fatal(err)
+	}
+
+	// Schedule the rotation as of age ago, then reload the schedules
+	past := b.newStaticRotationManager(timeutil.NewManualClock(b.clock.Now().Add(-age)))
+	if err := past.Schedule(context.Background(), storage, cfg.Name, staticRoleSchedule(cfg)); err != nil {
+		t.Fatalf("couldn't schedule the rotation of role %q: %s", cfg.Name, err)
+	}
+	if err := b.staticRotations.Initialize(context.Background(), storage); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// setTestClock makes the backend, and the rotation of its static roles, use
+// the given clock.
+func setTestClock(b *backend, clock timeutil.Clock) {
+	b.clock = clock
+	b.staticRotations = b.newStaticRotationManager(clock)
+}
+
+// TestRotation verifies that the rotation code and rotation manager correctly selects and rotates credentials
 // for static secrets.
 func TestRotation(t *testing.T) {
 	bgCTX := context.Background()
@@ -106,6 +136,8 @@ func TestRotation(t *testing.T) {
 			config.StorageView = &logical.InmemStorage{}
 
 			b := Backend(config)
+			clock := timeutil.NewManualClock(time.Now())
+			setTestClock(b, clock)
 
 			// insert all our creds
 			for i, cred := range c.creds {
@@ -143,15 +175,7 @@ func TestRotation(t *testing.T) {
 					t.Fatalf("couldn't insert credential %d: %s", i, err)
 				}
 
-				item := &queue.Item{
-					Key:      cred.config.Name,
-					Value:    cred.config,
-					Priority: time.Now().Add(-1 * cred.age).Add(cred.config.RotationPeriod).Unix(),
-				}
-				err = b.credRotationQueue.Push(item)
-				if err != nil {
-					t.Fatalf("couldn't push item onto queue: %s", err)
-				}
+				scheduleStaticRole(t, b, config.StorageView, cred.config, cred.age)
 			}
 
 			// update aws responses, same argument for why it's okay every cred will be the same
@@ -186,7 +210,7 @@ func TestRotation(t *testing.T) {
 			req := &logical.Request{
 				Storage: config.StorageView,
 			}
-			err = b.rotateExpiredStaticCreds(bgCTX, req)
+			err = b.staticRotations.RotateExpired(bgCTX, req.Storage)
 			if err != nil {
 				t.Fatalf("got an error rotating credentials: %s", err)
 			}
@@ -213,6 +237,172 @@ func TestRotation(t *testing.T) {
 	}
 }
 
+// TestRotation_AdvanceClock verifies that a static credential is rotated once
+// the backend's clock passes its rotation period, and is then rescheduled for
+// the following period.
+func TestRotation_AdvanceClock(t *testing.T) {
+	bgCTX := context.Background()
+
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+
+	b := Backend(config)
+	clock := timeutil.NewManualClock(time.Now())
+	setTestClock(b, clock)
+
+	role := staticRoleEntry{
+		Name:           "test",
+		Username:       "jane-doe",
+		ID:             "unique-id",
+		RotationPeriod: time.Hour,
+	}
+
+	miam, err := awsutil.NewMockIAM(
+		awsutil.WithListAccessKeysOutput(&iam.ListAccessKeysOutput{
+			AccessKeyMetadata: []*iam.AccessKeyMetadata{},
+		}),
+		awsutil.WithCreateAccessKeyOutput(&iam.CreateAccessKeyOutput{
+			AccessKey: &iam.AccessKey{
+				AccessKeyId:     aws.String("key"),
+				SecretAccessKey: aws.String("itsasecret"),
+			},
+		}),
+		awsutil.WithGetUserOutput(&iam.GetUserOutput{
+			User: &iam.User{
+				UserId:   aws.String(role.ID),
+				UserName: aws.String(role.Username),
+			},
+		}),
+	)(nil)
+	if err != nil {
+		t.Fatalf("couldn't initialze mock IAM handler: %s", err)
+	}
+	b.iamClient = miam
+
+	scheduleStaticRole(t, b, config.StorageView, role, 0)
+	scheduled := clock.Now().Add(role.RotationPeriod)
+
+	err = b.staticRotations.RotateExpired(bgCTX, config.StorageView)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next, _ := b.staticRotations.NextRotation(role.Name); !next.Equal(scheduled) {
+		t.Fatal("expected the credential not to be rotated before its rotation period")
+	}
+	if creds, err := config.StorageView.Get(bgCTX, formatCredsStoragePath(role.Name)); err != nil || creds != nil {
+		t.Fatalf("expected no credential before the rotation period, got %v, %v", creds, err)
+	}
+
+	clock.Advance(role.RotationPeriod)
+	err = b.staticRotations.RotateExpired(bgCTX, config.StorageView)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds, err := config.StorageView.Get(bgCTX, formatCredsStoragePath(role.Name)); err != nil || creds == nil {
+		t.Fatalf("expected the credential to be rotated after its rotation period: %v", err)
+	}
+
+	next, ok := b.staticRotations.NextRotation(role.Name)
+	if !ok {
+		t.Fatal("expected the credential to be rescheduled")
+	}
+	if expected := clock.Now().Add(role.RotationPeriod); !next.Equal(expected) {
+		t.Fatalf("expected the credential to be rescheduled at %s, got %s", expected, next)
+	}
+}
+
+// TestRotation_Failure verifies that a failed rotation is recorded on the role and retried, and that the error is
+// cleared by the next successful rotation.
+func TestRotation_Failure(t *testing.T) {
+	bgCTX := context.Background()
+
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+
+	b := Backend(config)
+	clock := timeutil.NewManualClock(time.Now())
+	setTestClock(b, clock)
+
+	role := staticRoleEntry{
+		Name:           "test",
+		Username:       "jane-doe",
+		ID:             "unique-id",
+		RotationPeriod: time.Hour,
+	}
+	entry, err := logical.StorageEntryJSON(formatRoleStoragePath(role.Name), role)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.StorageView.Put(bgCTX, entry); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := []awsutil.MockIAMOption{
+		awsutil.WithListAccessKeysOutput(&iam.ListAccessKeysOutput{
+			AccessKeyMetadata: []*iam.AccessKeyMetadata{},
+		}),
+		awsutil.WithGetUserOutput(&iam.GetUserOutput{
+			User: &iam.User{
+				UserId:   aws.String(role.ID),
+				UserName: aws.String(role.Username),
+			},
+		}),
+	}
+	failingIAM, err := awsutil.NewMockIAM(append(opts, awsutil.WithCreateAccessKeyError(errors.New("access denied")))...)(nil)
+	if err != nil {
+		t.Fatalf("couldn't initialze mock IAM handler: %s", err)
+	}
+	b.iamClient = failingIAM
+
+	scheduleStaticRole(t, b, config.StorageView, role, role.RotationPeriod)
+
+	readRole := func() staticRoleEntry {
+		entry, err := config.StorageView.Get(bgCTX, formatRoleStoragePath(role.Name))
+		if err != nil || entry == nil {
+			t.Fatalf("couldn't read the role: %v", err)
+		}
+		var cfg staticRoleEntry
+		if err := entry.DecodeJSON(&cfg); err != nil {
+			t.Fatal(err)
+		}
+		return cfg
+	}
+
+	if err := b.staticRotations.RotateExpired(bgCTX, config.StorageView); err == nil {
+		t.Fatal("expected the rotation to fail")
+	}
+	if cfg := readRole(); cfg.LastRotationError == "" {
+		t.Fatal("expected the rotation error to be recorded on the role")
+	}
+
+	// the role is retried rather than dropped from the schedule
+	next, ok := b.staticRotations.NextRotation(role.Name)
+	if !ok {
+		t.Fatal("expected the role to be rescheduled")
+	}
+	if expected := clock.Now().Add(staticRotationRetryInterval); !next.Equal(expected) {
+		t.Fatalf("expected the credential to be retried at %s, got %s", expected, next)
+	}
+
+	b.iamClient, err = awsutil.NewMockIAM(append(opts, awsutil.WithCreateAccessKeyOutput(&iam.CreateAccessKeyOutput{
+		AccessKey: &iam.AccessKey{
+			AccessKeyId:     aws.String("key"),
+			SecretAccessKey: aws.String("itsasecret"),
+		},
+	}))...)(nil)
+	if err != nil {
+		t.Fatalf("couldn't initialze mock IAM handler: %s", err)
+	}
+
+	clock.Advance(staticRotationRetryInterval)
+	if err := b.staticRotations.RotateExpired(bgCTX, config.StorageView); err != nil {
+		t.Fatal(err)
+	}
+	if cfg := readRole(); cfg.LastRotationError != "" {
+		t.Fatalf("expected the rotation error to be cleared, got %q", cfg.LastRotationError)
+	}
+}
+
 type fakeIAM struct {
 	iamiface.IAMAPI
 	delReqs []*iam.DeleteAccessKeyInput
@@ -0,0 +1,98 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/hashicorp/go-secure-stdlib/awsutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// assumeRoleWithWebIdentity vends temporary credentials for the role with the
+// given ARN, in exchange for an identity token of the entity of the request
+// issued by the identity OIDC provider of Vault, through the OIDC role
+// configured on the mount. The trust policy of the role decides which entities
+// may assume it, from the claims of the token, and no root credentials are
+// needed.
+func (b *backend) assumeRoleWithWebIdentity(ctx context.Context, s logical.Storage,
+	displayName, requestID, entityID, roleName, roleArn string, role *awsRoleEntry,
+	lifeTimeInSeconds int64, roleSessionName string) (*logical.Response, error,
+) {
+	if entityID == "" {
+		return logical.ErrorResponse("no entity associated with the request's token; %s credentials are bound to the identity of an entity", webIdentityCred), nil
+	}
+
+	token, err := b.System().GenerateIdentityToken(ctx, requestID)
+	if err != nil {
+		return logical.ErrorResponse("unable to generate an identity token: %s", err), nil
+	}
+
+	stsClient, err := b.clientSTS(ctx, s)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	config, err := readConfig(ctx, s)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read configuration: %w", err)
+	}
+
+	// Set as defaultUsernameTemplate if not provided
+	usernameTemplate := config.UsernameTemplate
+	if usernameTemplate == "" {
+		usernameTemplate = defaultUserNameTemplate
+	}
+
+	if roleSessionName == "" {
+		roleSessionName, err = genUsername(displayName, roleName, "assume_role", usernameTemplate)
+		// Send a 400 to Framework.OperationFunc Handler
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		roleSessionName = normalizeDisplayName(roleSessionName)
+	}
+
+	input := &sts.AssumeRoleWithWebIdentityInput{
+		RoleSessionName:  aws.String(roleSessionName),
+		RoleArn:          aws.String(roleArn),
+		WebIdentityToken: aws.String(token),
+		DurationSeconds:  &lifeTimeInSeconds,
+	}
+	if role.PolicyDocument != "" {
+		input.SetPolicy(role.PolicyDocument)
+	}
+	if len(role.PolicyArns) > 0 {
+		input.SetPolicyArns(convertPolicyARNs(role.PolicyArns))
+	}
+	tokenResp, err := stsClient.AssumeRoleWithWebIdentityWithContext(ctx, input)
+	if err != nil {
+		return logical.ErrorResponse("Error assuming role with web identity: %s", err), awsutil.CheckAWSError(err)
+	}
+
+	// As with assumed roles, the credentials cannot be revoked, but get a
+	// lease matching their expiration.
+	ttl := tokenResp.Credentials.Expiration.Sub(time.Now())
+	resp := b.Secret(secretAccessKeyType).Response(map[string]interface{}{
+		"access_key":     *tokenResp.Credentials.AccessKeyId,
+		"secret_key":     *tokenResp.Credentials.SecretAccessKey,
+		"security_token": *tokenResp.Credentials.SessionToken,
+		"arn":            *tokenResp.AssumedRoleUser.Arn,
+		"ttl":            uint64(ttl.Seconds()),
+	}, map[string]interface{}{
+		"username": roleSessionName,
+		"policy":   roleArn,
+		"is_sts":   true,
+	})
+
+	resp.Secret.TTL = ttl
+	resp.Secret.Renewable = false
+
+	return resp, nil
+}
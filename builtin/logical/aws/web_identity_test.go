@@ -0,0 +1,93 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package aws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+// webIdentitySTS is an STS client vending credentials for web identity tokens.
+type webIdentitySTS struct {
+	stsiface.STSAPI
+	input *sts.AssumeRoleWithWebIdentityInput
+}
+
+func (w *webIdentitySTS) AssumeRoleWithWebIdentityWithContext(_ aws.Context, input *sts.AssumeRoleWithWebIdentityInput, _ ...request.Option) (*sts.AssumeRoleWithWebIdentityOutput, error) {
+	w.input = input
+	return &sts.AssumeRoleWithWebIdentityOutput{
+		Credentials: &sts.Credentials{
+			AccessKeyId:     aws.String("ASIAEXAMPLE"),
+			SecretAccessKey: aws.String("secret"),
+			SessionToken:    aws.String("session"),
+			Expiration:      aws.Time(time.Now().Add(time.Hour)),
+		},
+		AssumedRoleUser: &sts.AssumedRoleUser{
+			Arn: aws.String("arn:aws:sts::123456789012:assumed-role/Workload/" + aws.StringValue(input.RoleSessionName)),
+		},
+	}, nil
+}
+
+// TestWebIdentityCredentials verifies that web_identity credentials are vended
+// in exchange for an identity token of the entity of the request.
+func TestWebIdentityCredentials(t *testing.T) {
+	ctx := context.Background()
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	sysView := logical.TestSystemView()
+	sysView.IdentityTokenVal = "eyJhbGciOiJSUzI1NiJ9.e30.c2lnbmF0dXJl"
+	config.System = sysView
+
+	b := Backend(config)
+	require.NoError(t, b.Setup(ctx, config))
+	fakeSTS := &webIdentitySTS{}
+	b.stsClient = fakeSTS
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "roles/workload",
+		Storage:   config.StorageView,
+		Data: map[string]interface{}{
+			"credential_type": webIdentityCred,
+			"role_arns":       []string{"arn:aws:iam::123456789012:role/Workload"},
+			"policy_arns":     []string{adminAccessPolicyARN},
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, resp != nil && resp.IsError(), "resp: %#v", resp)
+
+	credsReq := &logical.Request{
+		ID:          "request-id",
+		Operation:   logical.ReadOperation,
+		Path:        "sts/workload",
+		Storage:     config.StorageView,
+		DisplayName: "token",
+	}
+
+	// Credentials are bound to entities
+	resp, err = b.HandleRequest(ctx, credsReq)
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+	require.Nil(t, fakeSTS.input)
+
+	credsReq.EntityID = "entity-id"
+	resp, err = b.HandleRequest(ctx, credsReq)
+	require.NoError(t, err)
+	require.False(t, resp.IsError(), "resp: %#v", resp)
+	require.Equal(t, "ASIAEXAMPLE", resp.Data["access_key"])
+	require.Equal(t, "session", resp.Data["security_token"])
+	require.False(t, resp.Secret.Renewable)
+
+	require.Equal(t, sysView.IdentityTokenVal, aws.StringValue(fakeSTS.input.WebIdentityToken))
+	require.Equal(t, "arn:aws:iam::123456789012:role/Workload", aws.StringValue(fakeSTS.input.RoleArn))
+	require.Equal(t, adminAccessPolicyARN, aws.StringValue(fakeSTS.input.PolicyArns[0].Arn))
+}
@@ -20,7 +20,9 @@ import (
 	"github.com/hashicorp/vault/helper/namespace"
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
 	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/patrickmn/go-cache"
 )
 
 const (
@@ -125,6 +127,7 @@ func Backend(conf *logical.BackendConfig) *backend {
 				clusterConfigPath,
 				"crls/",
 				"certs/",
+				roleUsagePrefix,
 				acmePathPrefix,
 			},
 
@@ -159,6 +162,7 @@ func Backend(conf *logical.BackendConfig) *backend {
 			pathConfigCRL(&b),
 			pathConfigURLs(&b),
 			pathConfigCluster(&b),
+			pathConfigWarnings(&b),
 			pathSignVerbatim(&b),
 			pathSign(&b),
 			pathIssue(&b),
@@ -270,6 +274,9 @@ func Backend(conf *logical.BackendConfig) *backend {
 		// We specifically do NOT add acme/new-eab to this as it should be auth'd
 	}
 
+	b.roleUsageLocks = locksutil.CreateLocks()
+	b.precheckCache = cache.New(defaultPrecheckCacheTTL, time.Minute)
+
 	b.tidyCASGuard = new(uint32)
 	b.tidyCancelCAS = new(uint32)
 	b.tidyStatus = &tidyStatus{state: tidyStatusInactive}
@@ -337,6 +344,17 @@ type backend struct {
 	// Write lock around issuers and keys.
 	issuersLock sync.RWMutex
 
+	// Locks around the issuance usage of roles with issuance limits.
+	roleUsageLocks []*locksutil.LockEntry
+
+	// Cached outcomes of the issuance prechecks of roles, keyed by role name.
+	precheckCache *cache.Cache
+
+	// State of the periodic warning checks.
+	warningsLock      sync.Mutex
+	lastWarningsCheck time.Time
+	activeWarnings    map[string]*healthWarning
+
 	// Context around ACME operations
 	acmeState       *acmeState
 	acmeAccountLock sync.RWMutex // (Write) Locked on Tidy, (Read) Locked on Account Creation
@@ -699,6 +717,9 @@ func (b *backend) periodicFunc(ctx context.Context, request *logical.Request) er
 	crlErr := doCRL()
 	tidyErr := doAutoTidy()
 
+	// Then check for expiring issuers and CRLs.
+	warningsErr := b.checkHealthWarnings(sc)
+
 	// Periodically re-emit gauges so that they don't disappear/go stale
 	tidyConfig, err := sc.getAutoTidyConfig()
 	if err != nil {
@@ -715,6 +736,10 @@ func (b *backend) periodicFunc(ctx context.Context, request *logical.Request) er
 		errors = multierror.Append(errors, fmt.Errorf("Error running auto-tidy:\n - %w\n", tidyErr))
 	}
 
+	if warningsErr != nil {
+		errors = multierror.Append(errors, fmt.Errorf("Error checking for warnings:\n - %w\n", warningsErr))
+	}
+
 	if errors != nil {
 		return errors
 	}
@@ -3698,6 +3698,15 @@ func TestReadWriteDeleteRoles(t *testing.T) {
 		"issuer_ref":                         "default",
 		"cn_validations":                     []interface{}{"email", "hostname"},
 		"allowed_user_ids":                   []interface{}{},
+		"issuance_rate_limit":                json.Number("0"),
+		"issuance_rate_window":               json.Number("3600"),
+		"max_active_certs":                   json.Number("0"),
+		"max_active_certs_per_cn":            json.Number("0"),
+		"check_caa":                          false,
+		"caa_identities":                     []interface{}{},
+		"naming_policy_url":                  "",
+		"precheck_failure_mode":              "fail_closed",
+		"precheck_cache_ttl":                 json.Number("300"),
 	}
 
 	if diff := deep.Equal(expectedData, resp.Data); len(diff) > 0 {
@@ -6779,6 +6788,7 @@ func TestProperAuthing(t *testing.T) {
 		"config/issuers":                         shouldBeAuthed,
 		"config/keys":                            shouldBeAuthed,
 		"config/urls":                            shouldBeAuthed,
+		"config/warnings":                        shouldBeAuthed,
 		"crl":                                    shouldBeUnauthedReadList,
 		"crl/pem":                                shouldBeUnauthedReadList,
 		"crl/delta":                              shouldBeUnauthedReadList,
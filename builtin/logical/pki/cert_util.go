@@ -40,6 +40,10 @@ type inputBundle struct {
 	role    *roleEntry
 	req     *logical.Request
 	apiData *framework.FieldData
+
+	// sc is set by the endpoints which run the role's issuance prechecks
+	// before signing.
+	sc *storageContext
 }
 
 var (
@@ -1492,6 +1496,14 @@ func generateCreationBundle(b *backend, data *inputBundle, caSign *certutil.CAIn
 		return creation, warnings, nil
 	}
 
+	if data.sc != nil && data.role.hasIssuancePrechecks() {
+		precheckWarnings, err := b.checkIssuance(data.sc, data.role, creation.Params)
+		if err != nil {
+			return nil, nil, err
+		}
+		warnings = append(warnings, precheckWarnings...)
+	}
+
 	// This will have been read in from the getGlobalAIAURLs function
 	creation.Params.URLs = caSign.URLs
 
@@ -0,0 +1,238 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package pki
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/logical"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	eventTypeIssuerExpiring     logical.EventType = "pki/issuer-expiring"
+	eventTypeCRLExpiring        logical.EventType = "pki/crl-expiring"
+	eventTypeCertStoreThreshold logical.EventType = "pki/cert-store-threshold"
+)
+
+// healthWarning is a warning raised by the periodic checks of the mount.
+type healthWarning struct {
+	eventType logical.EventType
+	message   string
+	metadata  map[string]interface{}
+}
+
+// checkHealthWarnings runs the periodic checks of the mount, once their
+// interval elapsed. An event is sent for every new warning, and the current
+// warnings are reported to sys/health.
+func (b *backend) checkHealthWarnings(sc *storageContext) error {
+	// The checks only run on the active node, so that warnings are raised
+	// once per cluster.
+	if b.System().ReplicationState().HasState(consts.ReplicationPerformanceStandby) ||
+		b.System().ReplicationState().HasState(consts.ReplicationDRSecondary) {
+		return nil
+	}
+
+	config, err := sc.getWarningsConfig()
+	if err != nil {
+		return err
+	}
+
+	b.warningsLock.Lock()
+	defer b.warningsLock.Unlock()
+
+	now := time.Now()
+	if !config.Enabled {
+		if len(b.activeWarnings) > 0 {
+			b.activeWarnings = nil
+			b.reportHealthWarnings(sc, nil)
+		}
+		return nil
+	}
+	if now.Before(b.lastWarningsCheck.Add(config.Interval)) {
+		return nil
+	}
+	b.lastWarningsCheck = now
+
+	warnings := make(map[string]*healthWarning)
+	if err := b.checkIssuerExpiry(sc, config, now, warnings); err != nil {
+		return err
+	}
+	if err := b.checkCRLExpiry(sc, config, now, warnings); err != nil {
+		return err
+	}
+	b.checkCertStoreSize(config, warnings)
+
+	for key, warning := range warnings {
+		if previous, ok := b.activeWarnings[key]; ok && previous.message == warning.message {
+			continue
+		}
+		b.sendWarningEvent(sc, warning)
+	}
+	b.activeWarnings = warnings
+	b.reportHealthWarnings(sc, warnings)
+
+	return nil
+}
+
+// checkIssuerExpiry warns about the issuers which expire within the window.
+// Revoked issuers are ignored.
+func (b *backend) checkIssuerExpiry(sc *storageContext, config *warningsConfig, now time.Time, warnings map[string]*healthWarning) error {
+	issuers, err := sc.listIssuers()
+	if err != nil {
+		return fmt.Errorf("unable to list issuers: %w", err)
+	}
+
+	for _, id := range issuers {
+		issuer, err := sc.fetchIssuerById(id)
+		if err != nil {
+			return err
+		}
+		if issuer.Revoked {
+			continue
+		}
+
+		cert, err := issuer.GetCertificate()
+		if err != nil {
+			return fmt.Errorf("unable to parse certificate of issuer %v: %w", id, err)
+		}
+		if cert.NotAfter.After(now.Add(config.IssuerExpiryWindow)) {
+			continue
+		}
+
+		message := fmt.Sprintf("issuer %v (%q) expires at %v", id, issuer.Name, cert.NotAfter.Format(time.RFC3339))
+		if !cert.NotAfter.After(now) {
+			message = fmt.Sprintf("issuer %v (%q) expired at %v", id, issuer.Name, cert.NotAfter.Format(time.RFC3339))
+		}
+		warnings["issuer/"+id.String()] = &healthWarning{
+			eventType: eventTypeIssuerExpiring,
+			message:   message,
+			metadata: map[string]interface{}{
+				"issuer_id":   id.String(),
+				"issuer_name": issuer.Name,
+				"not_after":   cert.NotAfter.Format(time.RFC3339),
+			},
+		}
+	}
+
+	return nil
+}
+
+// checkCRLExpiry warns about the CRLs whose next update is within the window,
+// and which therefore should already have been rebuilt.
+func (b *backend) checkCRLExpiry(sc *storageContext, config *warningsConfig, now time.Time, warnings map[string]*healthWarning) error {
+	crlConfig, err := b.crlBuilder.getConfigWithUpdate(sc)
+	if err != nil {
+		return err
+	}
+	if crlConfig.Disable {
+		return nil
+	}
+
+	internalCRLConfig, err := sc.getLocalCRLConfig()
+	if err != nil {
+		return fmt.Errorf("unable to fetch cluster-local CRL configuration: %w", err)
+	}
+	if internalCRLConfig == nil {
+		return nil
+	}
+
+	for id, nextUpdate := range internalCRLConfig.CRLExpirationMap {
+		if nextUpdate.IsZero() || nextUpdate.After(now.Add(config.CRLExpiryWindow)) {
+			continue
+		}
+
+		warnings["crl/"+id.String()] = &healthWarning{
+			eventType: eventTypeCRLExpiring,
+			message:   fmt.Sprintf("CRL %v reaches its next update at %v and has not been rebuilt", id, nextUpdate.Format(time.RFC3339)),
+			metadata: map[string]interface{}{
+				"crl_id":      id.String(),
+				"next_update": nextUpdate.Format(time.RFC3339),
+			},
+		}
+	}
+
+	return nil
+}
+
+// checkCertStoreSize warns when the number of stored certificates crossed
+// the highest of the thresholds it reached. It requires certificates to be
+// counted.
+func (b *backend) checkCertStoreSize(config *warningsConfig, warnings map[string]*healthWarning) {
+	if !b.certCountEnabled.Load() || !b.certsCounted.Load() {
+		return
+	}
+
+	count := int(b.certCount.Load())
+	var crossed int
+	for _, threshold := range config.CertStoreThresholds {
+		if count >= threshold && threshold > crossed {
+			crossed = threshold
+		}
+	}
+	if crossed == 0 {
+		return
+	}
+
+	warnings["cert-store"] = &healthWarning{
+		eventType: eventTypeCertStoreThreshold,
+		message:   fmt.Sprintf("%d certificates are stored, crossing the threshold of %d", count, crossed),
+		metadata: map[string]interface{}{
+			"certificate_count": count,
+			"threshold":         crossed,
+		},
+	}
+}
+
+func (b *backend) sendWarningEvent(sc *storageContext, warning *healthWarning) {
+	b.Logger().Warn("pki warning", "warning", warning.message)
+
+	metadata := map[string]interface{}{
+		"message": warning.message,
+	}
+	for key, value := range warning.metadata {
+		metadata[key] = value
+	}
+	b.sendEvent(sc, warning.eventType, warning.message, metadata)
+}
+
+// reportHealthWarnings reports the current warnings to sys/health, replacing
+// the previously reported ones.
+func (b *backend) reportHealthWarnings(sc *storageContext, warnings map[string]*healthWarning) {
+	messages := make([]interface{}, 0, len(warnings))
+	for _, warning := range warnings {
+		messages = append(messages, warning.message)
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].(string) < messages[j].(string)
+	})
+
+	b.sendEvent(sc, logical.EventTypeHealthWarnings, "", map[string]interface{}{
+		"warnings": messages,
+	})
+}
+
+func (b *backend) sendEvent(sc *storageContext, eventType logical.EventType, note string, metadata map[string]interface{}) {
+	event, err := logical.NewEvent()
+	if err != nil {
+		b.Logger().Warn("unable to create event", "event_type", eventType, "error", err)
+		return
+	}
+	event.Note = note
+	event.Metadata, err = structpb.NewStruct(metadata)
+	if err != nil {
+		b.Logger().Warn("unable to encode event metadata", "event_type", eventType, "error", err)
+		return
+	}
+
+	err = b.SendEvent(sc.Context, eventType, event)
+	if err != nil && !errors.Is(err, framework.ErrNoEvents) {
+		b.Logger().Warn("unable to send event", "event_type", eventType, "error", err)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package pki
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingEventSender records the events sent by the backend.
+type recordingEventSender struct {
+	lock   sync.Mutex
+	events map[logical.EventType][]*logical.EventData
+}
+
+func (s *recordingEventSender) Send(_ context.Context, eventType logical.EventType, event *logical.EventData) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.events == nil {
+		s.events = make(map[logical.EventType][]*logical.EventData)
+	}
+	s.events[eventType] = append(s.events[eventType], event)
+	return nil
+}
+
+func (s *recordingEventSender) sent(eventType logical.EventType) []*logical.EventData {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.events[eventType]
+}
+
+func TestPki_HealthWarnings(t *testing.T) {
+	t.Parallel()
+
+	events := &recordingEventSender{}
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	config.EventsSender = events
+	b := Backend(config)
+	require.NoError(t, b.Setup(context.Background(), config))
+	b.pkiStorageVersion.Store(1)
+	s := config.StorageView
+
+	resp, err := CBRead(b, s, "config/warnings")
+	requireSuccessNonNilResponse(t, resp, err)
+	require.Equal(t, true, resp.Data["enabled"])
+	require.Equal(t, 30*24*3600, resp.Data["issuer_expiry_window"])
+
+	resp, err = CBWrite(b, s, "config/warnings", map[string]interface{}{"interval": 0})
+	require.NotNil(t, resp)
+	require.True(t, resp.IsError(), "expected an error: %#v", resp)
+
+	// An issuer expiring within the window raises a warning.
+	resp, err = CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"ttl":         "48h",
+		"issuer_name": "short-lived",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	_, err = CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"ttl":         "8760h",
+		"issuer_name": "long-lived",
+	})
+	require.NoError(t, err)
+
+	sc := b.makeStorageContext(context.Background(), s)
+	require.NoError(t, b.checkHealthWarnings(sc))
+
+	expiring := events.sent(eventTypeIssuerExpiring)
+	require.Len(t, expiring, 1)
+	require.Equal(t, "short-lived", expiring[0].Metadata.AsMap()["issuer_name"])
+	reported := events.sent(logical.EventTypeHealthWarnings)
+	require.Len(t, reported, 1)
+	require.Len(t, reported[0].Metadata.AsMap()["warnings"], 1)
+
+	// Checks don't run again until the interval elapsed.
+	require.NoError(t, b.checkHealthWarnings(sc))
+	require.Len(t, events.sent(logical.EventTypeHealthWarnings), 1)
+
+	// Warnings which are still active are reported again, without sending
+	// another event.
+	b.lastWarningsCheck = time.Time{}
+	require.NoError(t, b.checkHealthWarnings(sc))
+	require.Len(t, events.sent(eventTypeIssuerExpiring), 1)
+	require.Len(t, events.sent(logical.EventTypeHealthWarnings), 2)
+
+	// Disabling the checks clears the warnings.
+	resp, err = CBWrite(b, s, "config/warnings", map[string]interface{}{"enabled": false})
+	requireSuccessNonNilResponse(t, resp, err)
+	require.NoError(t, b.checkHealthWarnings(sc))
+	reported = events.sent(logical.EventTypeHealthWarnings)
+	require.Len(t, reported, 3)
+	require.Empty(t, reported[2].Metadata.AsMap()["warnings"])
+}
@@ -0,0 +1,471 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package pki
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/hashicorp/vault/sdk/helper/certutil"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	precheckFailClosed = "fail_closed"
+	precheckFailOpen   = "fail_open"
+)
+
+// defaultPrecheckCacheTTL is how long the outcome of an issuance precheck is
+// cached, unless the role sets its own.
+const defaultPrecheckCacheTTL = 5 * time.Minute
+
+// precheckTimeout bounds each CAA lookup and naming policy request.
+const precheckTimeout = 10 * time.Second
+
+// typeCAA is the DNS resource record type of CAA records, per RFC 8659.
+const typeCAA = dnsmessage.Type(257)
+
+// caaRecord is a single property of a CAA resource record set.
+type caaRecord struct {
+	Critical bool
+	Tag      string
+	Value    string
+}
+
+// namingPolicyRequest is the body posted to a role's naming policy service.
+type namingPolicyRequest struct {
+	Role           string   `json:"role"`
+	CommonName     string   `json:"common_name"`
+	DNSNames       []string `json:"dns_names"`
+	EmailAddresses []string `json:"email_addresses"`
+	IPAddresses    []string `json:"ip_addresses"`
+	URISANs        []string `json:"uri_sans"`
+}
+
+// namingPolicyResponse is the decision of a role's naming policy service.
+type namingPolicyResponse struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason"`
+}
+
+// precheckOutcome is a cached decision of an issuance precheck; an empty
+// denial allows issuance.
+type precheckOutcome struct {
+	denial string
+}
+
+// hasIssuancePrechecks returns whether the names requested against the role
+// are checked before issuance.
+func (r *roleEntry) hasIssuancePrechecks() bool {
+	return r.CheckCAA || r.NamingPolicyURL != ""
+}
+
+// validateRolePrechecks returns an error response when the issuance
+// prechecks of the role are misconfigured.
+func validateRolePrechecks(entry *roleEntry) *logical.Response {
+	if entry.CheckCAA && len(entry.CAAIdentities) == 0 {
+		return logical.ErrorResponse(`"caa_identities" must be set when "check_caa" is set`)
+	}
+
+	if entry.NamingPolicyURL != "" {
+		u, err := url.Parse(entry.NamingPolicyURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return logical.ErrorResponse(`"naming_policy_url" must be an http or https URL`)
+		}
+	}
+
+	switch entry.PrecheckFailureMode {
+	case "", precheckFailClosed, precheckFailOpen:
+	default:
+		return logical.ErrorResponse(`"precheck_failure_mode" must be %q or %q`, precheckFailClosed, precheckFailOpen)
+	}
+
+	if entry.PrecheckCacheTTL < 0 {
+		return logical.ErrorResponse(`"precheck_cache_ttl" cannot be negative`)
+	}
+
+	return nil
+}
+
+// precheckFailureMode returns whether issuance is denied or allowed when a
+// precheck cannot be run.
+func (r *roleEntry) precheckFailureMode() string {
+	if r.PrecheckFailureMode == "" {
+		return precheckFailClosed
+	}
+	return r.PrecheckFailureMode
+}
+
+// checkIssuance runs the role's prechecks against the names of the
+// certificate about to be issued. Denials are returned as user errors.
+// Prechecks that cannot be run deny issuance when the role fails closed, and
+// are returned as warnings when it fails open.
+func (b *backend) checkIssuance(sc *storageContext, role *roleEntry, params *certutil.CreationParameters) ([]string, error) {
+	var warnings []string
+	handle := func(denial string, err error) error {
+		switch {
+		case err != nil && role.precheckFailureMode() == precheckFailOpen:
+			b.Logger().Warn("issuance precheck failed, allowing issuance", "role", role.Name, "error", err)
+			warnings = append(warnings, fmt.Sprintf("issuance precheck failed and was skipped: %v", err))
+			return nil
+		case err != nil:
+			return errutil.InternalError{Err: fmt.Sprintf("issuance precheck failed: %v", err)}
+		case denial != "":
+			return errutil.UserError{Err: denial}
+		}
+		return nil
+	}
+
+	if role.CheckCAA {
+		resolver, err := b.caaResolver(sc)
+		if err != nil {
+			if err := handle("", err); err != nil {
+				return nil, err
+			}
+		} else {
+			for _, name := range params.DNSNames {
+				denial, err := b.checkCAA(sc.Context, resolver, role, name)
+				if err := handle(denial, err); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if role.NamingPolicyURL != "" {
+		denial, err := b.checkNamingPolicy(sc.Context, role, params)
+		if err := handle(denial, err); err != nil {
+			return nil, err
+		}
+	}
+
+	return warnings, nil
+}
+
+// cachedPrecheck returns the cached outcome of a precheck, or runs it and
+// caches its decision. Failures to run the precheck are never cached.
+func (b *backend) cachedPrecheck(role *roleEntry, key string, check func() (string, error)) (string, error) {
+	key = role.Name + "/" + key
+	if cached, ok := b.precheckCache.Get(key); ok {
+		return cached.(precheckOutcome).denial, nil
+	}
+
+	denial, err := check()
+	if err != nil {
+		return "", err
+	}
+	if role.PrecheckCacheTTL > 0 {
+		b.precheckCache.Set(key, precheckOutcome{denial: denial}, role.PrecheckCacheTTL)
+	}
+	return denial, nil
+}
+
+// flushPrecheckCache forgets the cached outcomes of the prechecks of a role,
+// once it is updated or deleted.
+func (b *backend) flushPrecheckCache(name string) {
+	for key := range b.precheckCache.Items() {
+		if strings.HasPrefix(key, name+"/") {
+			b.precheckCache.Delete(key)
+		}
+	}
+}
+
+// caaResolver returns the address of the DNS resolver CAA records are looked
+// up through: the mount's ACME resolver when set, or the system's.
+func (b *backend) caaResolver(sc *storageContext) (string, error) {
+	config, err := sc.getAcmeConfig()
+	if err != nil {
+		return "", err
+	}
+	if config.DNSResolver != "" {
+		return config.DNSResolver, nil
+	}
+
+	f, err := os.Open("/etc/resolv.conf")
+	if err != nil {
+		return "", fmt.Errorf("unable to find a DNS resolver: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" && net.ParseIP(fields[1]) != nil {
+			return net.JoinHostPort(fields[1], "53"), nil
+		}
+	}
+	return "", fmt.Errorf("unable to find a DNS resolver in /etc/resolv.conf")
+}
+
+// checkCAA returns a denial when the CAA records relevant to the name do not
+// authorize any of the role's CAA identities to issue for it. Following RFC
+// 8659, the relevant records are those of the closest ancestor of the name
+// which has any, and names without any are unrestricted.
+func (b *backend) checkCAA(ctx context.Context, resolver string, role *roleEntry, name string) (string, error) {
+	return b.cachedPrecheck(role, "caa/"+name, func() (string, error) {
+		wildcard := strings.HasPrefix(name, "*.")
+		domain := strings.TrimSuffix(strings.TrimPrefix(name, "*."), ".")
+		for domain != "" {
+			records, err := lookupCAA(ctx, resolver, domain)
+			if err != nil {
+				return "", err
+			}
+			if len(records) > 0 {
+				if caaAuthorizes(records, role.CAAIdentities, wildcard) {
+					return "", nil
+				}
+				return fmt.Sprintf("subject alternate name %s is not allowed by the CAA records of %s", name, domain), nil
+			}
+
+			_, parent, found := strings.Cut(domain, ".")
+			if !found {
+				break
+			}
+			domain = parent
+		}
+		return "", nil
+	})
+}
+
+// caaAuthorizes returns whether a CAA record set authorizes any of the
+// identities to issue for a name. Wildcard names are governed by the
+// issuewild properties when there are any. Unknown critical properties
+// forbid issuance.
+func caaAuthorizes(records []caaRecord, identities []string, wildcard bool) bool {
+	tag := "issue"
+	for _, record := range records {
+		switch record.Tag {
+		case "issue", "issuewild", "iodef":
+		default:
+			if record.Critical {
+				return false
+			}
+		}
+		if wildcard && record.Tag == "issuewild" {
+			tag = "issuewild"
+		}
+	}
+
+	restricted := false
+	for _, record := range records {
+		if record.Tag != tag {
+			continue
+		}
+		restricted = true
+
+		issuer, _, _ := strings.Cut(record.Value, ";")
+		issuer = strings.TrimSpace(issuer)
+		for _, identity := range identities {
+			if issuer != "" && strings.EqualFold(issuer, identity) {
+				return true
+			}
+		}
+	}
+	return !restricted
+}
+
+// lookupCAA queries the resolver for the CAA records of the domain, and
+// returns none when the domain does not exist.
+func lookupCAA(ctx context.Context, resolver string, domain string) ([]caaRecord, error) {
+	name, err := dnsmessage.NewName(domain + ".")
+	if err != nil {
+		return nil, fmt.Errorf("invalid domain %q: %w", domain, err)
+	}
+
+	query := dnsmessage.Message{
+		Header: dnsmessage.Header{
+			ID:               uint16(time.Now().UnixNano()),
+			RecursionDesired: true,
+		},
+		Questions: []dnsmessage.Question{{
+			Name:  name,
+			Type:  typeCAA,
+			Class: dnsmessage.ClassINET,
+		}},
+	}
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, precheckTimeout)
+	defer cancel()
+
+	response, err := exchangeDNS(ctx, "udp", resolver, packed)
+	if err == nil && response.Truncated {
+		response, err = exchangeDNS(ctx, "tcp", resolver, packed)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to look up CAA records of %s via resolver %s: %w", domain, resolver, err)
+	}
+	if response.ID != query.ID {
+		return nil, fmt.Errorf("unable to look up CAA records of %s via resolver %s: mismatched response", domain, resolver)
+	}
+
+	switch response.RCode {
+	case dnsmessage.RCodeSuccess:
+	case dnsmessage.RCodeNameError:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unable to look up CAA records of %s via resolver %s: %v", domain, resolver, response.RCode)
+	}
+
+	var records []caaRecord
+	for _, answer := range response.Answers {
+		if answer.Header.Type != typeCAA {
+			continue
+		}
+		body, ok := answer.Body.(*dnsmessage.UnknownResource)
+		if !ok {
+			continue
+		}
+		record, err := parseCAARecord(body.Data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CAA record of %s: %w", domain, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// exchangeDNS sends a DNS query to the resolver over UDP or TCP and returns
+// its response.
+func exchangeDNS(ctx context.Context, network string, resolver string, query []byte) (*dnsmessage.Message, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, network, resolver)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	var buf []byte
+	if network == "tcp" {
+		framed := make([]byte, 2+len(query))
+		binary.BigEndian.PutUint16(framed, uint16(len(query)))
+		copy(framed[2:], query)
+		if _, err := conn.Write(framed); err != nil {
+			return nil, err
+		}
+
+		var length [2]byte
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			return nil, err
+		}
+		buf = make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			return nil, err
+		}
+	} else {
+		if _, err := conn.Write(query); err != nil {
+			return nil, err
+		}
+
+		buf = make([]byte, 65535)
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		buf = buf[:n]
+	}
+
+	var response dnsmessage.Message
+	if err := response.Unpack(buf); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// parseCAARecord parses the data of a CAA resource record: a flags byte, the
+// length of the tag, the tag and the value.
+func parseCAARecord(data []byte) (caaRecord, error) {
+	if len(data) < 2 {
+		return caaRecord{}, fmt.Errorf("record too short")
+	}
+	tagLength := int(data[1])
+	if tagLength == 0 || len(data) < 2+tagLength {
+		return caaRecord{}, fmt.Errorf("invalid tag length %d", tagLength)
+	}
+
+	return caaRecord{
+		Critical: data[0]&0x80 != 0,
+		Tag:      strings.ToLower(string(data[2 : 2+tagLength])),
+		Value:    string(data[2+tagLength:]),
+	}, nil
+}
+
+// checkNamingPolicy returns a denial when the role's naming policy service
+// does not allow the names of the certificate.
+func (b *backend) checkNamingPolicy(ctx context.Context, role *roleEntry, params *certutil.CreationParameters) (string, error) {
+	policyReq := namingPolicyRequest{
+		Role:           role.Name,
+		CommonName:     params.Subject.CommonName,
+		DNSNames:       params.DNSNames,
+		EmailAddresses: params.EmailAddresses,
+		IPAddresses:    []string{},
+		URISANs:        []string{},
+	}
+	for _, ip := range params.IPAddresses {
+		policyReq.IPAddresses = append(policyReq.IPAddresses, ip.String())
+	}
+	for _, uri := range params.URIs {
+		policyReq.URISANs = append(policyReq.URISANs, uri.String())
+	}
+	body, err := json.Marshal(policyReq)
+	if err != nil {
+		return "", err
+	}
+
+	digest := sha256.Sum256(body)
+	return b.cachedPrecheck(role, "naming-policy/"+hex.EncodeToString(digest[:]), func() (string, error) {
+		ctx, cancel := context.WithTimeout(ctx, precheckTimeout)
+		defer cancel()
+
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, role.NamingPolicyURL, bytes.NewReader(body))
+		if err != nil {
+			return "", err
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		resp, err := cleanhttp.DefaultClient().Do(httpReq)
+		if err != nil {
+			return "", fmt.Errorf("unable to reach naming policy service: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("naming policy service responded with status %d", resp.StatusCode)
+		}
+
+		var decision namingPolicyResponse
+		if err := json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&decision); err != nil {
+			return "", fmt.Errorf("unable to decode naming policy service response: %w", err)
+		}
+		if decision.Allowed {
+			return "", nil
+		}
+		if decision.Reason == "" {
+			decision.Reason = "no reason given"
+		}
+		return fmt.Sprintf("names are not allowed by the naming policy service: %s", decision.Reason), nil
+	})
+}
@@ -0,0 +1,226 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package pki
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// startCAAServer serves the given CAA records, keyed by domain, over UDP and
+// returns its address. Other domains do not exist.
+func startCAAServer(t *testing.T, records map[string][]caaRecord) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			var query dnsmessage.Message
+			if err := query.Unpack(buf[:n]); err != nil || len(query.Questions) != 1 {
+				continue
+			}
+			question := query.Questions[0]
+			domain := strings.TrimSuffix(question.Name.String(), ".")
+
+			response := dnsmessage.Message{
+				Header: dnsmessage.Header{
+					ID:       query.ID,
+					Response: true,
+				},
+				Questions: query.Questions,
+			}
+			domainRecords, ok := records[domain]
+			if !ok {
+				response.RCode = dnsmessage.RCodeNameError
+			}
+			for _, record := range domainRecords {
+				data := []byte{0, byte(len(record.Tag))}
+				if record.Critical {
+					data[0] = 0x80
+				}
+				data = append(data, record.Tag...)
+				data = append(data, record.Value...)
+				response.Answers = append(response.Answers, dnsmessage.Resource{
+					Header: dnsmessage.ResourceHeader{
+						Name:  question.Name,
+						Type:  typeCAA,
+						Class: dnsmessage.ClassINET,
+					},
+					Body: &dnsmessage.UnknownResource{Type: typeCAA, Data: data},
+				})
+			}
+
+			packed, err := response.Pack()
+			if err != nil {
+				continue
+			}
+			conn.WriteTo(packed, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func TestPki_CAAAuthorizes(t *testing.T) {
+	t.Parallel()
+
+	identities := []string{"ca.example.net"}
+	cases := []struct {
+		name     string
+		records  []caaRecord
+		wildcard bool
+		expected bool
+	}{
+		{"no issue properties", []caaRecord{{Tag: "iodef", Value: "mailto:security@example.com"}}, false, true},
+		{"authorized", []caaRecord{{Tag: "issue", Value: "other.example.org"}, {Tag: "issue", Value: "CA.example.net; account=1"}}, false, true},
+		{"unauthorized", []caaRecord{{Tag: "issue", Value: "other.example.org"}}, false, false},
+		{"forbidden", []caaRecord{{Tag: "issue", Value: ";"}}, false, false},
+		{"unknown critical property", []caaRecord{{Tag: "issue", Value: "ca.example.net"}, {Critical: true, Tag: "future", Value: "x"}}, false, false},
+		{"unknown property", []caaRecord{{Tag: "issue", Value: "ca.example.net"}, {Tag: "future", Value: "x"}}, false, true},
+		{"wildcard governed by issuewild", []caaRecord{{Tag: "issue", Value: "ca.example.net"}, {Tag: "issuewild", Value: ";"}}, true, false},
+		{"wildcard governed by issue", []caaRecord{{Tag: "issue", Value: "ca.example.net"}}, true, true},
+		{"issuewild ignored for names", []caaRecord{{Tag: "issue", Value: "ca.example.net"}, {Tag: "issuewild", Value: ";"}}, false, true},
+	}
+	for _, tc := range cases {
+		require.Equal(t, tc.expected, caaAuthorizes(tc.records, identities, tc.wildcard), tc.name)
+	}
+
+	record, err := parseCAARecord([]byte("\x80\x05IssueCA.example.net"))
+	require.NoError(t, err)
+	require.Equal(t, caaRecord{Critical: true, Tag: "issue", Value: "CA.example.net"}, record)
+	_, err = parseCAARecord([]byte("\x00\x09issue"))
+	require.Error(t, err)
+}
+
+func TestPki_RoleIssuancePrechecks(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	resolver := startCAAServer(t, map[string][]caaRecord{
+		"example.com":        {{Tag: "issue", Value: "ca.example.net"}},
+		"other.example.com":  {{Tag: "issue", Value: "other.example.org"}},
+		"nested.example.com": {},
+	})
+	resp, err := CBWrite(b, s, "config/acme", map[string]interface{}{"dns_resolver": resolver})
+	requireSuccessNonNilResponse(t, resp, err, "failed configuring resolver")
+
+	resp, err = CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "failed generating root")
+
+	// CAA identities are required to check CAA records.
+	resp, err = CBWrite(b, s, "roles/caa", map[string]interface{}{
+		"allow_any_name": true,
+		"check_caa":      true,
+	})
+	require.NotNil(t, resp)
+	require.True(t, resp.IsError(), "expected an error: %#v", resp)
+
+	resp, err = CBWrite(b, s, "roles/caa", map[string]interface{}{
+		"allow_any_name": true,
+		"key_type":       "ec",
+		"check_caa":      true,
+		"caa_identities": "ca.example.net",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "failed creating role")
+	require.Equal(t, "fail_closed", resp.Data["precheck_failure_mode"])
+	require.Equal(t, int64(300), resp.Data["precheck_cache_ttl"])
+
+	// Names are checked against the records of their closest ancestor with
+	// any, and are unrestricted without any.
+	for _, name := range []string{"example.com", "www.example.com", "a.nested.example.com", "example.org"} {
+		resp, err = CBWrite(b, s, "issue/caa", map[string]interface{}{"common_name": name})
+		requireSuccessNonNilResponse(t, resp, err, "failed issuing for "+name)
+	}
+	resp, err = CBWrite(b, s, "issue/caa", map[string]interface{}{
+		"common_name": "www.example.com",
+		"alt_names":   "www.other.example.com",
+	})
+	require.NotNil(t, resp)
+	require.True(t, resp.IsError(), "expected an error: %#v", resp)
+	require.Contains(t, resp.Error().Error(), "CAA records of other.example.com")
+
+	var allowed atomic.Bool
+	var requests atomic.Int32
+	policyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		var policyReq namingPolicyRequest
+		if err := json.NewDecoder(r.Body).Decode(&policyReq); err != nil || policyReq.Role != "policy" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(namingPolicyResponse{
+			Allowed: allowed.Load() && !strings.HasPrefix(policyReq.CommonName, "forbidden"),
+			Reason:  "name is reserved",
+		})
+	}))
+	defer policyServer.Close()
+
+	resp, err = CBWrite(b, s, "roles/policy", map[string]interface{}{
+		"allow_any_name":        true,
+		"key_type":              "ec",
+		"naming_policy_url":     policyServer.URL,
+		"precheck_failure_mode": "fail_sometimes",
+	})
+	require.NotNil(t, resp)
+	require.True(t, resp.IsError(), "expected an error: %#v", resp)
+
+	resp, err = CBWrite(b, s, "roles/policy", map[string]interface{}{
+		"allow_any_name":    true,
+		"key_type":          "ec",
+		"naming_policy_url": policyServer.URL,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "failed creating role")
+
+	resp, err = CBWrite(b, s, "issue/policy", map[string]interface{}{"common_name": "forbidden.example.com"})
+	require.NotNil(t, resp)
+	require.True(t, resp.IsError(), "expected an error: %#v", resp)
+	require.Contains(t, resp.Error().Error(), "name is reserved")
+
+	// Decisions are cached until the role is updated.
+	allowed.Store(true)
+	resp, err = CBWrite(b, s, "issue/policy", map[string]interface{}{"common_name": "forbidden.example.com"})
+	require.NotNil(t, resp)
+	require.True(t, resp.IsError(), "expected an error: %#v", resp)
+	require.Equal(t, int32(1), requests.Load())
+
+	resp, err = CBWrite(b, s, "issue/policy", map[string]interface{}{"common_name": "www.example.com"})
+	requireSuccessNonNilResponse(t, resp, err, "failed issuing")
+	resp, err = CBWrite(b, s, "issue/policy", map[string]interface{}{"common_name": "www.example.com"})
+	requireSuccessNonNilResponse(t, resp, err, "failed issuing")
+	require.Equal(t, int32(2), requests.Load())
+
+	// Unreachable services deny issuance, unless the role fails open.
+	policyServer.Close()
+	resp, err = CBPatch(b, s, "roles/policy", map[string]interface{}{"precheck_cache_ttl": 0})
+	requireSuccessNonNilResponse(t, resp, err, "failed updating role")
+	_, err = CBWrite(b, s, "issue/policy", map[string]interface{}{"common_name": "www.example.com"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "naming policy service")
+
+	resp, err = CBPatch(b, s, "roles/policy", map[string]interface{}{"precheck_failure_mode": "fail_open"})
+	requireSuccessNonNilResponse(t, resp, err, "failed updating role")
+	resp, err = CBWrite(b, s, "issue/policy", map[string]interface{}{"common_name": "www.example.com"})
+	requireSuccessNonNilResponse(t, resp, err, "failed issuing")
+	require.NotEmpty(t, resp.Warnings)
+}
@@ -544,6 +544,7 @@ func issueCertFromCsr(ac *acmeContext, csr *x509.CertificateRequest) (*certutil.
 		req:     &logical.Request{},
 		apiData: data,
 		role:    ac.role,
+		sc:      ac.sc,
 	}
 
 	normalNotAfter, _, err := getCertificateNotAfter(ac.sc.Backend, input, signingBundle)
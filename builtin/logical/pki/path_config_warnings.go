@@ -0,0 +1,222 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package pki
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const warningsConfigPath = "config/warnings"
+
+type warningsConfig struct {
+	Enabled             bool          `json:"enabled"`
+	Interval            time.Duration `json:"interval"`
+	IssuerExpiryWindow  time.Duration `json:"issuer_expiry_window"`
+	CRLExpiryWindow     time.Duration `json:"crl_expiry_window"`
+	CertStoreThresholds []int         `json:"cert_store_thresholds"`
+}
+
+var defaultWarningsConfig = warningsConfig{
+	Enabled:             true,
+	Interval:            1 * time.Hour,
+	IssuerExpiryWindow:  30 * 24 * time.Hour,
+	CRLExpiryWindow:     6 * time.Hour,
+	CertStoreThresholds: []int{},
+}
+
+var warningsConfigFields = map[string]*framework.FieldSchema{
+	"enabled": {
+		Type:        framework.TypeBool,
+		Description: `Set to true to enable the periodic warning checks. Defaults to true.`,
+		Default:     defaultWarningsConfig.Enabled,
+	},
+	"interval": {
+		Type:        framework.TypeDurationSecond,
+		Description: `Interval at which to run the warning checks. Defaults to one hour.`,
+		Default:     int(defaultWarningsConfig.Interval / time.Second),
+	},
+	"issuer_expiry_window": {
+		Type: framework.TypeDurationSecond,
+		Description: `Warn about issuers expiring within this duration.
+Defaults to 30 days.`,
+		Default: int(defaultWarningsConfig.IssuerExpiryWindow / time.Second),
+	},
+	"crl_expiry_window": {
+		Type: framework.TypeDurationSecond,
+		Description: `Warn about CRLs reaching their next update within
+this duration without being rebuilt. Defaults to 6 hours.`,
+		Default: int(defaultWarningsConfig.CRLExpiryWindow / time.Second),
+	},
+	"cert_store_thresholds": {
+		Type: framework.TypeCommaIntSlice,
+		Description: `Warn when the number of stored certificates crosses
+any of these thresholds. Requires the maintain_stored_certificate_counts
+option of the auto-tidy configuration. Defaults to no thresholds.`,
+	},
+}
+
+func pathConfigWarnings(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: warningsConfigPath,
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+		},
+
+		Fields: warningsConfigFields,
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationSuffix: "warnings-configuration",
+				},
+				Callback: b.pathReadWarnings,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields:      warningsConfigFields,
+					}},
+				},
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb:   "configure",
+					OperationSuffix: "warnings",
+				},
+				Callback: b.pathWriteWarnings,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields:      warningsConfigFields,
+					}},
+				},
+			},
+		},
+
+		HelpSynopsis:    pathConfigWarningsHelpSyn,
+		HelpDescription: pathConfigWarningsHelpDesc,
+	}
+}
+
+func (b *backend) pathReadWarnings(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+	config, err := sc.getWarningsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: config.toResponseData(),
+	}, nil
+}
+
+func (b *backend) pathWriteWarnings(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+	config, err := sc.getWarningsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if value, ok := data.GetOk("enabled"); ok {
+		config.Enabled = value.(bool)
+	}
+
+	if value, ok := data.GetOk("interval"); ok {
+		config.Interval = time.Duration(value.(int)) * time.Second
+		if config.Interval <= 0 {
+			return logical.ErrorResponse("given interval must be greater than zero seconds; got: %v", value), nil
+		}
+	}
+
+	if value, ok := data.GetOk("issuer_expiry_window"); ok {
+		config.IssuerExpiryWindow = time.Duration(value.(int)) * time.Second
+		if config.IssuerExpiryWindow < 0 {
+			return logical.ErrorResponse("given issuer_expiry_window must not be negative; got: %v", value), nil
+		}
+	}
+
+	if value, ok := data.GetOk("crl_expiry_window"); ok {
+		config.CRLExpiryWindow = time.Duration(value.(int)) * time.Second
+		if config.CRLExpiryWindow < 0 {
+			return logical.ErrorResponse("given crl_expiry_window must not be negative; got: %v", value), nil
+		}
+	}
+
+	if value, ok := data.GetOk("cert_store_thresholds"); ok {
+		config.CertStoreThresholds = value.([]int)
+		for _, threshold := range config.CertStoreThresholds {
+			if threshold <= 0 {
+				return logical.ErrorResponse("given cert_store_thresholds must be greater than zero; got: %v", threshold), nil
+			}
+		}
+	}
+
+	if err := sc.writeWarningsConfig(config); err != nil {
+		return nil, err
+	}
+
+	// Run the checks with the new configuration on the next periodic run.
+	b.warningsLock.Lock()
+	b.lastWarningsCheck = time.Time{}
+	b.warningsLock.Unlock()
+
+	return &logical.Response{
+		Data: config.toResponseData(),
+	}, nil
+}
+
+func (c *warningsConfig) toResponseData() map[string]interface{} {
+	return map[string]interface{}{
+		"enabled":               c.Enabled,
+		"interval":              int(c.Interval / time.Second),
+		"issuer_expiry_window":  int(c.IssuerExpiryWindow / time.Second),
+		"crl_expiry_window":     int(c.CRLExpiryWindow / time.Second),
+		"cert_store_thresholds": c.CertStoreThresholds,
+	}
+}
+
+func (sc *storageContext) getWarningsConfig() (*warningsConfig, error) {
+	entry, err := sc.Storage.Get(sc.Context, warningsConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := defaultWarningsConfig
+	if entry == nil {
+		return &result, nil
+	}
+
+	if err = entry.DecodeJSON(&result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (sc *storageContext) writeWarningsConfig(config *warningsConfig) error {
+	entry, err := logical.StorageEntryJSON(warningsConfigPath, config)
+	if err != nil {
+		return err
+	}
+
+	return sc.Storage.Put(sc.Context, entry)
+}
+
+const pathConfigWarningsHelpSyn = `
+Configure the periodic checks warning about expiring issuers and CRLs.
+`
+
+const pathConfigWarningsHelpDesc = `
+This path configures the periodic checks of this mount, which warn about
+issuers approaching their expiry, CRLs approaching their next update without
+being rebuilt, and the certificate store crossing the given size thresholds.
+
+Warnings are reported as events, when events are enabled, and surfaced by
+the sys/health endpoint until they are resolved.
+`
@@ -18,6 +18,7 @@ import (
 	"github.com/hashicorp/vault/sdk/helper/certutil"
 	"github.com/hashicorp/vault/sdk/helper/consts"
 	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
 	"github.com/hashicorp/vault/sdk/logical"
 )
 
@@ -379,10 +380,36 @@ func (b *backend) pathIssueSignCert(ctx context.Context, req *logical.Request, d
 		}
 	}
 
+	var usage *roleUsage
+	if role.hasIssuanceLimits() {
+		// Issuance against the role is recorded in storage, forward this
+		// request on to the primary even when not storing the certificate.
+		if b.System().ReplicationState().HasState(consts.ReplicationPerformanceStandby) {
+			return nil, logical.ErrReadOnly
+		}
+
+		// Hold the role's lock until the certificate is recorded, so that
+		// concurrent requests cannot exceed its limits.
+		lock := locksutil.LockForKey(b.roleUsageLocks, role.Name)
+		lock.Lock()
+		defer lock.Unlock()
+
+		var err error
+		usage, err = sc.fetchRoleUsage(role.Name)
+		if err != nil {
+			return nil, err
+		}
+		usage.prune(role, time.Now())
+		if err := usage.checkRate(role); err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+	}
+
 	input := &inputBundle{
 		req:     req,
 		apiData: data,
 		role:    role,
+		sc:      sc,
 	}
 	var parsedBundle *certutil.ParsedCertBundle
 	var err error
@@ -403,6 +430,18 @@ func (b *backend) pathIssueSignCert(ctx context.Context, req *logical.Request, d
 		}
 	}
 
+	if usage != nil {
+		// The common name is only known once the certificate is built; it is
+		// discarded if the role has too many certificates for it.
+		if err := usage.checkCommonName(role, parsedBundle.Certificate.Subject.CommonName); err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+		usage.record(role, parsedBundle.Certificate, time.Now())
+		if err := sc.writeRoleUsage(role.Name, usage); err != nil {
+			return nil, fmt.Errorf("unable to record issuance against role: %w", err)
+		}
+	}
+
 	signingCB, err := signingBundle.ToCertBundle()
 	if err != nil {
 		return nil, fmt.Errorf("error converting raw signing bundle to cert bundle: %w", err)
@@ -205,6 +205,10 @@ func pathRotateCRL(b *backend) *framework.Path {
 	return &framework.Path{
 		Pattern: `crl/rotate`,
 
+		// Rebuilds are serialized, and each request waits for its own full
+		// rebuild, so concurrent requests would only pile up
+		MaxConcurrency: 1,
+
 		DisplayAttrs: &framework.DisplayAttributes{
 			OperationPrefix: operationPrefixPKI,
 			OperationVerb:   "rotate",
@@ -242,6 +246,9 @@ func pathRotateDeltaCRL(b *backend) *framework.Path {
 	return &framework.Path{
 		Pattern: `crl/rotate-delta`,
 
+		// Delta CRLs are rebuilt one at a time too
+		MaxConcurrency: 1,
+
 		DisplayAttrs: &framework.DisplayAttributes{
 			OperationPrefix: operationPrefixPKI,
 			OperationVerb:   "rotate",
@@ -397,6 +397,42 @@ The value format should be given in UTC format YYYY-MM-ddTHH:MM:SSZ.`,
 			Description: `Reference to the issuer used to sign requests
 serviced by this role.`,
 		},
+		"issuance_rate_limit": {
+			Type:        framework.TypeInt,
+			Description: `The maximum number of certificates issued within issuance_rate_window, or 0 for no limit.`,
+		},
+		"issuance_rate_window": {
+			Type:        framework.TypeInt64,
+			Description: `The window in seconds issuance_rate_limit applies to.`,
+		},
+		"max_active_certs": {
+			Type:        framework.TypeInt,
+			Description: `The maximum number of unexpired certificates issued against the role, or 0 for no limit.`,
+		},
+		"max_active_certs_per_cn": {
+			Type:        framework.TypeInt,
+			Description: `The maximum number of unexpired certificates issued against the role for a single common name, or 0 for no limit.`,
+		},
+		"check_caa": {
+			Type:        framework.TypeBool,
+			Description: `Whether the DNS names requested against the role are checked against their CAA records before issuance.`,
+		},
+		"caa_identities": {
+			Type:        framework.TypeCommaStringSlice,
+			Description: `The issuer domain names identifying this CA in CAA records.`,
+		},
+		"naming_policy_url": {
+			Type:        framework.TypeString,
+			Description: `The URL of the naming policy service the names requested against the role are checked against before issuance.`,
+		},
+		"precheck_failure_mode": {
+			Type:        framework.TypeString,
+			Description: `Whether issuance is denied (fail_closed) or allowed (fail_open) when an issuance precheck cannot be run.`,
+		},
+		"precheck_cache_ttl": {
+			Type:        framework.TypeInt64,
+			Description: `The duration in seconds the outcome of issuance prechecks is cached for.`,
+		},
 	}
 
 	return &framework.Path{
@@ -818,6 +854,61 @@ The value format should be given in UTC format YYYY-MM-ddTHH:MM:SSZ.`,
 serviced by this role.`,
 				Default: defaultRef,
 			},
+			"issuance_rate_limit": {
+				Type: framework.TypeInt,
+				Description: `The maximum number of certificates the issue
+and sign endpoints can issue against this role within
+issuance_rate_window. Defaults to 0, for no limit.`,
+			},
+			"issuance_rate_window": {
+				Type: framework.TypeDurationSecond,
+				Description: `The window issuance_rate_limit applies to.
+Defaults to one hour.`,
+			},
+			"max_active_certs": {
+				Type: framework.TypeInt,
+				Description: `The maximum number of unexpired certificates
+the issue and sign endpoints can have issued against this role.
+Defaults to 0, for no limit.`,
+			},
+			"max_active_certs_per_cn": {
+				Type: framework.TypeInt,
+				Description: `The maximum number of unexpired certificates
+the issue and sign endpoints can have issued against this role for a
+single common name. Defaults to 0, for no limit.`,
+			},
+			"check_caa": {
+				Type: framework.TypeBool,
+				Description: `If set, the DNS names requested against this
+role are checked against their CAA records before issuance, and denied
+unless unrestricted or authorized for one of caa_identities. Only
+applies to the issue, sign and ACME endpoints.`,
+			},
+			"caa_identities": {
+				Type: framework.TypeCommaStringSlice,
+				Description: `The issuer domain names identifying this CA in
+CAA records. Required when check_caa is set.`,
+			},
+			"naming_policy_url": {
+				Type: framework.TypeString,
+				Description: `If set, the URL of a naming policy service the
+names requested against this role are posted to before issuance, which
+must allow them. Only applies to the issue, sign and ACME endpoints.`,
+			},
+			"precheck_failure_mode": {
+				Type: framework.TypeString,
+				Description: `Whether issuance is denied ("fail_closed") or
+allowed with a warning ("fail_open") when a CAA lookup or the naming
+policy service fails. Defaults to "fail_closed".`,
+				Default: precheckFailClosed,
+			},
+			"precheck_cache_ttl": {
+				Type: framework.TypeDurationSecond,
+				Description: `The duration the outcome of CAA and naming
+policy checks is cached for. Failures are never cached. Defaults to
+5 minutes; 0 disables caching.`,
+				Default: int(defaultPrecheckCacheTTL.Seconds()),
+			},
 		},
 
 		Operations: map[logical.Operation]framework.OperationHandler{
@@ -1020,11 +1111,18 @@ func (b *backend) getRole(ctx context.Context, s logical.Storage, n string) (*ro
 }
 
 func (b *backend) pathRoleDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
-	err := req.Storage.Delete(ctx, "role/"+data.Get("name").(string))
+	name := data.Get("name").(string)
+	err := req.Storage.Delete(ctx, "role/"+name)
 	if err != nil {
 		return nil, err
 	}
 
+	// Usage is local to the cluster, and forgotten along with the role.
+	if err := b.makeStorageContext(ctx, req.Storage).deleteRoleUsage(name); err != nil {
+		return nil, err
+	}
+	b.flushPrecheckCache(name)
+
 	return nil, nil
 }
 
@@ -1107,6 +1205,15 @@ func (b *backend) pathRoleCreate(ctx context.Context, req *logical.Request, data
 		NotBeforeDuration:             time.Duration(data.Get("not_before_duration").(int)) * time.Second,
 		NotAfter:                      data.Get("not_after").(string),
 		Issuer:                        data.Get("issuer_ref").(string),
+		IssuanceRateLimit:             data.Get("issuance_rate_limit").(int),
+		IssuanceRateWindow:            time.Duration(data.Get("issuance_rate_window").(int)) * time.Second,
+		MaxActiveCerts:                data.Get("max_active_certs").(int),
+		MaxActiveCertsPerCN:           data.Get("max_active_certs_per_cn").(int),
+		CheckCAA:                      data.Get("check_caa").(bool),
+		CAAIdentities:                 data.Get("caa_identities").([]string),
+		NamingPolicyURL:               data.Get("naming_policy_url").(string),
+		PrecheckFailureMode:           data.Get("precheck_failure_mode").(string),
+		PrecheckCacheTTL:              time.Duration(data.Get("precheck_cache_ttl").(int)) * time.Second,
 		Name:                          name,
 	}
 
@@ -1164,6 +1271,7 @@ func (b *backend) pathRoleCreate(ctx context.Context, req *logical.Request, data
 	if err := req.Storage.Put(ctx, jsonEntry); err != nil {
 		return nil, err
 	}
+	b.flushPrecheckCache(name)
 
 	return resp, nil
 }
@@ -1178,6 +1286,16 @@ func validateRole(b *backend, entry *roleEntry, ctx context.Context, s logical.S
 		), nil
 	}
 
+	if entry.IssuanceRateLimit < 0 || entry.IssuanceRateWindow < 0 || entry.MaxActiveCerts < 0 || entry.MaxActiveCertsPerCN < 0 {
+		return logical.ErrorResponse(
+			`"issuance_rate_limit", "issuance_rate_window", "max_active_certs" and "max_active_certs_per_cn" cannot be negative`,
+		), nil
+	}
+
+	if resp := validateRolePrechecks(entry); resp != nil {
+		return resp, nil
+	}
+
 	if entry.KeyBits, entry.SignatureBits, err = certutil.ValidateDefaultOrValueKeyTypeSignatureLength(entry.KeyType, entry.KeyBits, entry.SignatureBits); err != nil {
 		return logical.ErrorResponse(err.Error()), nil
 	}
@@ -1307,6 +1425,15 @@ func (b *backend) pathRolePatch(ctx context.Context, req *logical.Request, data
 		NotBeforeDuration:             getTimeWithExplicitDefault(data, "not_before_duration", oldEntry.NotBeforeDuration),
 		NotAfter:                      getWithExplicitDefault(data, "not_after", oldEntry.NotAfter).(string),
 		Issuer:                        getWithExplicitDefault(data, "issuer_ref", oldEntry.Issuer).(string),
+		IssuanceRateLimit:             getWithExplicitDefault(data, "issuance_rate_limit", oldEntry.IssuanceRateLimit).(int),
+		IssuanceRateWindow:            getTimeWithExplicitDefault(data, "issuance_rate_window", oldEntry.IssuanceRateWindow),
+		MaxActiveCerts:                getWithExplicitDefault(data, "max_active_certs", oldEntry.MaxActiveCerts).(int),
+		MaxActiveCertsPerCN:           getWithExplicitDefault(data, "max_active_certs_per_cn", oldEntry.MaxActiveCertsPerCN).(int),
+		CheckCAA:                      getWithExplicitDefault(data, "check_caa", oldEntry.CheckCAA).(bool),
+		CAAIdentities:                 getWithExplicitDefault(data, "caa_identities", oldEntry.CAAIdentities).([]string),
+		NamingPolicyURL:               getWithExplicitDefault(data, "naming_policy_url", oldEntry.NamingPolicyURL).(string),
+		PrecheckFailureMode:           getWithExplicitDefault(data, "precheck_failure_mode", oldEntry.PrecheckFailureMode).(string),
+		PrecheckCacheTTL:              getTimeWithExplicitDefault(data, "precheck_cache_ttl", oldEntry.PrecheckCacheTTL),
 	}
 
 	allowedOtherSANsData, wasSet := data.GetOk("allowed_other_sans")
@@ -1371,6 +1498,7 @@ func (b *backend) pathRolePatch(ctx context.Context, req *logical.Request, data
 	if err := req.Storage.Put(ctx, jsonEntry); err != nil {
 		return nil, err
 	}
+	b.flushPrecheckCache(name)
 
 	return resp, nil
 }
@@ -1513,6 +1641,15 @@ type roleEntry struct {
 	NotBeforeDuration             time.Duration `json:"not_before_duration"`
 	NotAfter                      string        `json:"not_after"`
 	Issuer                        string        `json:"issuer"`
+	IssuanceRateLimit             int           `json:"issuance_rate_limit,omitempty"`
+	IssuanceRateWindow            time.Duration `json:"issuance_rate_window,omitempty"`
+	MaxActiveCerts                int           `json:"max_active_certs,omitempty"`
+	MaxActiveCertsPerCN           int           `json:"max_active_certs_per_cn,omitempty"`
+	CheckCAA                      bool          `json:"check_caa,omitempty"`
+	CAAIdentities                 []string      `json:"caa_identities"`
+	NamingPolicyURL               string        `json:"naming_policy_url,omitempty"`
+	PrecheckFailureMode           string        `json:"precheck_failure_mode,omitempty"`
+	PrecheckCacheTTL              time.Duration `json:"precheck_cache_ttl,omitempty"`
 	// Name is only set when the role has been stored, on the fly roles have a blank name
 	Name string `json:"-"`
 }
@@ -1565,6 +1702,15 @@ func (r *roleEntry) ToResponseData() map[string]interface{} {
 		"not_before_duration":                int64(r.NotBeforeDuration.Seconds()),
 		"not_after":                          r.NotAfter,
 		"issuer_ref":                         r.Issuer,
+		"issuance_rate_limit":                r.IssuanceRateLimit,
+		"issuance_rate_window":               int64(r.issuanceRateWindow().Seconds()),
+		"max_active_certs":                   r.MaxActiveCerts,
+		"max_active_certs_per_cn":            r.MaxActiveCertsPerCN,
+		"check_caa":                          r.CheckCAA,
+		"caa_identities":                     r.CAAIdentities,
+		"naming_policy_url":                  r.NamingPolicyURL,
+		"precheck_failure_mode":              r.precheckFailureMode(),
+		"precheck_cache_ttl":                 int64(r.PrecheckCacheTTL.Seconds()),
 	}
 	if r.MaxPathLength != nil {
 		responseData["max_path_length"] = r.MaxPathLength
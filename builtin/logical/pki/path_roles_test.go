@@ -1132,6 +1132,65 @@ func TestPKI_RolePolicyInformation_Flat(t *testing.T) {
 	}
 }
 
+func TestPki_RoleIssuanceLimits(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "failed generating root")
+
+	// Limits cannot be negative.
+	resp, err = CBWrite(b, s, "roles/limited", map[string]interface{}{
+		"allow_any_name":      true,
+		"issuance_rate_limit": -1,
+	})
+	require.NotNil(t, resp)
+	require.True(t, resp.IsError(), "expected an error: %#v", resp)
+
+	resp, err = CBWrite(b, s, "roles/limited", map[string]interface{}{
+		"allow_any_name":          true,
+		"key_type":                "ec",
+		"issuance_rate_limit":     3,
+		"issuance_rate_window":    "10m",
+		"max_active_certs_per_cn": 1,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "failed creating role")
+	require.Equal(t, 3, resp.Data["issuance_rate_limit"])
+	require.Equal(t, int64(600), resp.Data["issuance_rate_window"])
+	require.Equal(t, 0, resp.Data["max_active_certs"])
+	require.Equal(t, 1, resp.Data["max_active_certs_per_cn"])
+
+	resp, err = CBWrite(b, s, "issue/limited", map[string]interface{}{"common_name": "a.example.com"})
+	requireSuccessNonNilResponse(t, resp, err, "failed issuing first certificate")
+
+	// Only one unexpired certificate is allowed per common name.
+	resp, err = CBWrite(b, s, "issue/limited", map[string]interface{}{"common_name": "a.example.com"})
+	require.NotNil(t, resp)
+	require.True(t, resp.IsError(), "expected an error: %#v", resp)
+	require.Contains(t, resp.Error().Error(), "max_active_certs_per_cn")
+
+	resp, err = CBWrite(b, s, "issue/limited", map[string]interface{}{"common_name": "b.example.com"})
+	requireSuccessNonNilResponse(t, resp, err, "failed issuing second certificate")
+	resp, err = CBWrite(b, s, "issue/limited", map[string]interface{}{"common_name": "c.example.com"})
+	requireSuccessNonNilResponse(t, resp, err, "failed issuing third certificate")
+
+	// The rate limit is reached, regardless of the common name.
+	resp, err = CBWrite(b, s, "issue/limited", map[string]interface{}{"common_name": "d.example.com"})
+	require.NotNil(t, resp)
+	require.True(t, resp.IsError(), "expected an error: %#v", resp)
+	require.Contains(t, resp.Error().Error(), "issuance_rate_limit")
+
+	// Deleting the role resets its usage.
+	_, err = CBDelete(b, s, "roles/limited")
+	require.NoError(t, err)
+	entry, err := s.Get(context.Background(), roleUsagePrefix+"limited")
+	require.NoError(t, err)
+	require.Nil(t, entry)
+}
+
 func getPolicyIdentifiersOffCertificate(resp logical.Response) ([]string, error) {
 	stringCertificate := resp.Data["certificate"].(string)
 	block, _ := pem.Decode([]byte(stringCertificate))
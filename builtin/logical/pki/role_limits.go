@@ -0,0 +1,146 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package pki
+
+import (
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// roleUsagePrefix is the storage prefix of the issuance usage of roles with
+// issuance limits. Like issued certificates, usage is local to the cluster.
+const roleUsagePrefix = "role-usage/"
+
+// defaultIssuanceRateWindow is the window issuance_rate_limit applies to,
+// unless the role sets its own.
+const defaultIssuanceRateWindow = time.Hour
+
+// roleUsage tracks the certificates issued against a role, to enforce its
+// issuance limits.
+type roleUsage struct {
+	// Issued holds when certificates were issued within the rate window.
+	Issued []time.Time `json:"issued,omitempty"`
+
+	// Active holds the unexpired certificates issued against the role.
+	Active []roleActiveCert `json:"active,omitempty"`
+}
+
+type roleActiveCert struct {
+	SerialNumber string    `json:"serial_number"`
+	CommonName   string    `json:"common_name"`
+	NotAfter     time.Time `json:"not_after"`
+}
+
+// hasIssuanceLimits returns whether issuance against the role is limited.
+// Roles built on the fly, without a name, never are.
+func (r *roleEntry) hasIssuanceLimits() bool {
+	return r.Name != "" && (r.IssuanceRateLimit > 0 || r.MaxActiveCerts > 0 || r.MaxActiveCertsPerCN > 0)
+}
+
+// issuanceRateWindow returns the window issuance_rate_limit applies to.
+func (r *roleEntry) issuanceRateWindow() time.Duration {
+	if r.IssuanceRateWindow > 0 {
+		return r.IssuanceRateWindow
+	}
+	return defaultIssuanceRateWindow
+}
+
+func (sc *storageContext) fetchRoleUsage(name string) (*roleUsage, error) {
+	entry, err := sc.Storage.Get(sc.Context, roleUsagePrefix+name)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch usage of role %q: %w", name, err)
+	}
+
+	var usage roleUsage
+	if entry == nil {
+		return &usage, nil
+	}
+	if err := entry.DecodeJSON(&usage); err != nil {
+		return nil, fmt.Errorf("unable to decode usage of role %q: %w", name, err)
+	}
+	return &usage, nil
+}
+
+func (sc *storageContext) writeRoleUsage(name string, usage *roleUsage) error {
+	entry, err := logical.StorageEntryJSON(roleUsagePrefix+name, usage)
+	if err != nil {
+		return err
+	}
+	return sc.Storage.Put(sc.Context, entry)
+}
+
+func (sc *storageContext) deleteRoleUsage(name string) error {
+	return sc.Storage.Delete(sc.Context, roleUsagePrefix+name)
+}
+
+// prune drops the issuances which left the rate window, and the certificates
+// which expired.
+func (u *roleUsage) prune(role *roleEntry, now time.Time) {
+	windowStart := now.Add(-role.issuanceRateWindow())
+	issued := u.Issued[:0]
+	for _, t := range u.Issued {
+		if t.After(windowStart) {
+			issued = append(issued, t)
+		}
+	}
+	u.Issued = issued
+
+	active := u.Active[:0]
+	for _, cert := range u.Active {
+		if cert.NotAfter.After(now) {
+			active = append(active, cert)
+		}
+	}
+	u.Active = active
+}
+
+// checkRate returns an error when issuing another certificate would exceed
+// the role's rate limit, or its cap on active certificates.
+func (u *roleUsage) checkRate(role *roleEntry) error {
+	if role.IssuanceRateLimit > 0 && len(u.Issued) >= role.IssuanceRateLimit {
+		return errutil.UserError{Err: fmt.Sprintf("role %q issued %d certificates within the last %s, the maximum allowed by issuance_rate_limit", role.Name, len(u.Issued), role.issuanceRateWindow())}
+	}
+	if role.MaxActiveCerts > 0 && len(u.Active) >= role.MaxActiveCerts {
+		return errutil.UserError{Err: fmt.Sprintf("role %q has %d unexpired certificates, the maximum allowed by max_active_certs", role.Name, len(u.Active))}
+	}
+	return nil
+}
+
+// checkCommonName returns an error when issuing another certificate for the
+// common name would exceed the role's cap on active certificates per common
+// name.
+func (u *roleUsage) checkCommonName(role *roleEntry, commonName string) error {
+	if role.MaxActiveCertsPerCN <= 0 {
+		return nil
+	}
+
+	var count int
+	for _, cert := range u.Active {
+		if cert.CommonName == commonName {
+			count++
+		}
+	}
+	if count >= role.MaxActiveCertsPerCN {
+		return errutil.UserError{Err: fmt.Sprintf("role %q has %d unexpired certificates for common name %q, the maximum allowed by max_active_certs_per_cn", role.Name, count, commonName)}
+	}
+	return nil
+}
+
+// record adds the issued certificate to the usage.
+func (u *roleUsage) record(role *roleEntry, cert *x509.Certificate, now time.Time) {
+	if role.IssuanceRateLimit > 0 {
+		u.Issued = append(u.Issued, now)
+	}
+	if role.MaxActiveCerts > 0 || role.MaxActiveCertsPerCN > 0 {
+		u.Active = append(u.Active, roleActiveCert{
+			SerialNumber: serialFromCert(cert),
+			CommonName:   cert.Subject.CommonName,
+			NotAfter:     cert.NotAfter,
+		})
+	}
+}
@@ -57,9 +57,13 @@ func Backend(ctx context.Context, conf *logical.BackendConfig) (*backend, error)
 			b.pathImportVersion(),
 			b.pathKeys(),
 			b.pathListKeys(),
+			b.pathAliases(),
+			b.pathListAliases(),
 			b.pathBYOKExportKeys(),
 			b.pathExportKeys(),
 			b.pathKeysConfig(),
+			b.pathKeysAccess(),
+			b.pathKeysUsage(),
 			b.pathEncrypt(),
 			b.pathDecrypt(),
 			b.pathDatakey(),
@@ -117,6 +121,14 @@ type backend struct {
 	checkAutoRotateAfter time.Time
 	autoRotateOnce       sync.Once
 	backendUUID          string
+
+	// aliases caches the keys pointed at by key aliases, keyed by alias. It
+	// is nil until the aliases are loaded.
+	aliasesLock sync.RWMutex
+	aliases     map[string]string
+
+	// usage accumulates the usage of keys until it is flushed to storage.
+	usage keyUsageTracker
 }
 
 func GetCacheSizeFromStorage(ctx context.Context, s logical.Storage) (int, error) {
@@ -135,8 +147,19 @@ func GetCacheSizeFromStorage(ctx context.Context, s logical.Storage) (int, error
 	return size, nil
 }
 
-// Update cache size and get policy
+// Update cache size and get policy. Key aliases are resolved to the key they
+// point at.
 func (b *backend) GetPolicy(ctx context.Context, polReq keysutil.PolicyRequest, rand io.Reader) (retP *keysutil.Policy, retUpserted bool, retErr error) {
+	target, err := b.resolveKeyAlias(ctx, polReq.Storage, polReq.Name)
+	if err != nil {
+		return nil, false, err
+	}
+	if target != "" {
+		// Aliases only ever point at existing keys, never create them.
+		polReq.Name = target
+		polReq.Upsert = false
+	}
+
 	// Acquire read lock to read cacheSizeChanged
 	b.configMutex.RLock()
 	if b.lm.GetUseCache() && b.cacheSizeChanged {
@@ -177,6 +200,10 @@ func (b *backend) invalidate(ctx context.Context, key string) {
 	case strings.HasPrefix(key, "policy/"):
 		name := strings.TrimPrefix(key, "policy/")
 		b.lm.InvalidatePolicy(name)
+	case strings.HasPrefix(key, keyAliasPrefix):
+		b.clearKeyAliases()
+	case key == keysConfigPath:
+		b.clearUsageTrackingConfig()
 	case strings.HasPrefix(key, "cache-config/"):
 		// Acquire the lock to set the flag to indicate that cache size needs to be refreshed from storage
 		b.configMutex.Lock()
@@ -201,6 +228,10 @@ func (b *backend) periodicFunc(ctx context.Context, req *logical.Request) error
 		b.autoRotateOnce = sync.Once{}
 	}
 
+	if flushErr := b.flushKeyUsage(ctx, req); flushErr != nil {
+		err = multierror.Append(err, flushErr)
+	}
+
 	return err
 }
 
@@ -267,20 +298,35 @@ func (b *backend) rotateIfRequired(ctx context.Context, req *logical.Request, ke
 		return nil
 	}
 
-	// If the policy's automatic rotation period is 0, it should not
-	// automatically rotate.
-	if p.AutoRotatePeriod == 0 {
+	// If the policy's automatic rotation period and maximum number of
+	// operations are 0, it should not automatically rotate.
+	if p.AutoRotatePeriod == 0 && p.AutoRotateMaxOperations == 0 {
 		return nil
 	}
 
 	// Retrieve the latest version of the policy and determine if it is time to rotate.
 	latestKey := p.Keys[strconv.Itoa(p.LatestVersion)]
-	if time.Now().After(latestKey.CreationTime.Add(p.AutoRotatePeriod)) {
+	if p.AutoRotatePeriod != 0 && time.Now().After(latestKey.CreationTime.Add(p.AutoRotatePeriod)) {
 		if b.Logger().IsDebug() {
 			b.Logger().Debug("automatically rotating key", "key", key)
 		}
 		return p.Rotate(ctx, req.Storage, b.GetRandomReader())
 
 	}
+
+	// Determine if the latest version performed its maximum number of
+	// encryptions.
+	if p.AutoRotateMaxOperations != 0 {
+		usage, err := b.readKeyUsage(ctx, req.Storage, key)
+		if err != nil {
+			return err
+		}
+		if usage.Encryptions[p.LatestVersion] >= p.AutoRotateMaxOperations {
+			if b.Logger().IsDebug() {
+				b.Logger().Debug("automatically rotating key after its maximum number of operations", "key", key)
+			}
+			return p.Rotate(ctx, req.Storage, b.GetRandomReader())
+		}
+	}
 	return nil
 }
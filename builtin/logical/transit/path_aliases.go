@@ -0,0 +1,271 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package transit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// keyAliasPrefix is the storage prefix of key aliases.
+const keyAliasPrefix = "alias/"
+
+// keyAlias is a stable name pointing at a key, so that applications can keep
+// using the alias while operators cut over to another key.
+type keyAlias struct {
+	Key string `json:"key"`
+}
+
+func (b *backend) pathListAliases() *framework.Path {
+	return &framework.Path{
+		Pattern: "aliases/?$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixTransit,
+			OperationSuffix: "key-aliases",
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ListOperation: b.pathAliasesList,
+		},
+
+		HelpSynopsis:    pathAliasesHelpSyn,
+		HelpDescription: pathAliasesHelpDesc,
+	}
+}
+
+func (b *backend) pathAliases() *framework.Path {
+	return &framework.Path{
+		Pattern: "aliases/" + framework.GenericNameRegex("name"),
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixTransit,
+			OperationSuffix: "key-alias",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the alias",
+			},
+
+			"key": {
+				Type:        framework.TypeString,
+				Description: "Name of the key the alias points at",
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathAliasWrite,
+				Summary:  "Creates an alias, or points an existing alias at another key",
+			},
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathAliasRead,
+				Summary:  "Returns the key an alias points at",
+			},
+			logical.DeleteOperation: &framework.PathOperation{
+				Callback: b.pathAliasDelete,
+				Summary:  "Deletes an alias, leaving the key it points at untouched",
+			},
+		},
+
+		HelpSynopsis:    pathAliasesHelpSyn,
+		HelpDescription: pathAliasesHelpDesc,
+	}
+}
+
+func (b *backend) pathAliasesList(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	entries, err := req.Storage.List(ctx, keyAliasPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return logical.ListResponse(entries), nil
+}
+
+func (b *backend) pathAliasWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+	key := strings.TrimSpace(d.Get("key").(string))
+
+	switch {
+	case key == "":
+		return logical.ErrorResponse("missing key"), logical.ErrInvalidRequest
+	case key == name:
+		return logical.ErrorResponse("an alias cannot point at itself"), logical.ErrInvalidRequest
+	}
+
+	// Aliases and keys share names, so that either can be used wherever a
+	// key name is expected.
+	entry, err := req.Storage.Get(ctx, "policy/"+name)
+	if err != nil {
+		return nil, err
+	}
+	if entry != nil {
+		return logical.ErrorResponse(fmt.Sprintf("a key named %q already exists", name)), logical.ErrInvalidRequest
+	}
+
+	// Aliases point at keys, not at other aliases.
+	entry, err = req.Storage.Get(ctx, "policy/"+key)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return logical.ErrorResponse(fmt.Sprintf("key %q not found", key)), logical.ErrInvalidRequest
+	}
+
+	entry, err = logical.StorageEntryJSON(keyAliasPrefix+name, &keyAlias{Key: key})
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		return nil, err
+	}
+	b.clearKeyAliases()
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"name": name,
+			"key":  key,
+		},
+	}, nil
+}
+
+func (b *backend) pathAliasRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+
+	alias, err := getKeyAlias(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if alias == nil {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"name": name,
+			"key":  alias.Key,
+		},
+	}, nil
+}
+
+func (b *backend) pathAliasDelete(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+
+	if err := req.Storage.Delete(ctx, keyAliasPrefix+name); err != nil {
+		return nil, err
+	}
+	b.clearKeyAliases()
+
+	return nil, nil
+}
+
+func getKeyAlias(ctx context.Context, s logical.Storage, name string) (*keyAlias, error) {
+	entry, err := s.Get(ctx, keyAliasPrefix+name)
+	if err != nil || entry == nil {
+		return nil, err
+	}
+
+	var alias keyAlias
+	if err := entry.DecodeJSON(&alias); err != nil {
+		return nil, err
+	}
+	return &alias, nil
+}
+
+// loadKeyAliases returns the keys pointed at by every alias, keyed by alias.
+func loadKeyAliases(ctx context.Context, s logical.Storage) (map[string]string, error) {
+	names, err := s.List(ctx, keyAliasPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	aliases := make(map[string]string, len(names))
+	for _, name := range names {
+		alias, err := getKeyAlias(ctx, s, name)
+		if err != nil {
+			return nil, err
+		}
+		if alias != nil {
+			aliases[name] = alias.Key
+		}
+	}
+	return aliases, nil
+}
+
+// aliasesOfKey returns the sorted aliases pointing at the named key.
+func aliasesOfKey(ctx context.Context, s logical.Storage, key string) ([]string, error) {
+	aliases, err := loadKeyAliases(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name, target := range aliases {
+		if target == key {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// resolveKeyAlias returns the key the named alias points at, or an empty
+// string if there is no alias with that name. Aliases are cached unless
+// caching is disabled.
+func (b *backend) resolveKeyAlias(ctx context.Context, s logical.Storage, name string) (string, error) {
+	if b.System().CachingDisabled() {
+		alias, err := getKeyAlias(ctx, s, name)
+		if err != nil || alias == nil {
+			return "", err
+		}
+		return alias.Key, nil
+	}
+
+	b.aliasesLock.RLock()
+	aliases := b.aliases
+	b.aliasesLock.RUnlock()
+	if aliases != nil {
+		return aliases[name], nil
+	}
+
+	b.aliasesLock.Lock()
+	defer b.aliasesLock.Unlock()
+
+	if b.aliases == nil {
+		var err error
+		b.aliases, err = loadKeyAliases(ctx, s)
+		if err != nil {
+			return "", err
+		}
+	}
+	return b.aliases[name], nil
+}
+
+// clearKeyAliases clears the cached aliases, so that they are loaded again
+// on their next use.
+func (b *backend) clearKeyAliases() {
+	b.aliasesLock.Lock()
+	defer b.aliasesLock.Unlock()
+	b.aliases = nil
+}
+
+const pathAliasesHelpSyn = `Manage aliases of named encryption keys`
+
+const pathAliasesHelpDesc = `
+This path is used to manage key aliases. An alias is a stable name pointing
+at a key, which can be used wherever a key name is expected. Writing the key
+of an existing alias atomically points it at another key, so that applications
+using the alias move to the new key without any change to their configuration.
+
+Data encrypted with the previous key can only be decrypted through the
+alias while the alias points at that key; use the name of the previous key to
+decrypt or rewrap it after the cut over.
+`
@@ -0,0 +1,97 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package transit
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestTransit_Aliases(t *testing.T) {
+	b, storage := createBackendWithSysView(t)
+
+	doReq := func(t *testing.T, op logical.Operation, path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(namespace.RootContext(nil), &logical.Request{
+			Operation: op,
+			Path:      path,
+			Storage:   storage,
+			Data:      data,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("got err:\n%#v\nresp:\n%#v\n", err, resp)
+		}
+		return resp
+	}
+	doErrReq := func(t *testing.T, op logical.Operation, path string, data map[string]interface{}) {
+		t.Helper()
+		resp, err := b.HandleRequest(namespace.RootContext(nil), &logical.Request{
+			Operation: op,
+			Path:      path,
+			Storage:   storage,
+			Data:      data,
+		})
+		if err == nil && (resp == nil || !resp.IsError()) {
+			t.Fatalf("expected error; resp:\n%#v\n", resp)
+		}
+	}
+
+	doReq(t, logical.UpdateOperation, "keys/payments-2023", nil)
+	doReq(t, logical.UpdateOperation, "keys/payments-2024", nil)
+
+	// Aliases must point at an existing key, and cannot shadow one.
+	doErrReq(t, logical.UpdateOperation, "aliases/payments-current", nil)
+	doErrReq(t, logical.UpdateOperation, "aliases/payments-current", map[string]interface{}{"key": "missing"})
+	doErrReq(t, logical.UpdateOperation, "aliases/payments-2023", map[string]interface{}{"key": "payments-2024"})
+
+	doReq(t, logical.UpdateOperation, "aliases/payments-current", map[string]interface{}{"key": "payments-2023"})
+
+	resp := doReq(t, logical.ReadOperation, "aliases/payments-current", nil)
+	if resp.Data["key"] != "payments-2023" {
+		t.Fatalf("expected the alias to point at payments-2023, got: %#v", resp.Data)
+	}
+	resp = doReq(t, logical.ListOperation, "aliases/", nil)
+	if keys := resp.Data["keys"]; !reflect.DeepEqual(keys, []string{"payments-current"}) {
+		t.Fatalf("unexpected aliases: %#v", keys)
+	}
+
+	// Keys cannot be created with the name of an alias, and keys cannot be
+	// deleted while aliases point at them.
+	doErrReq(t, logical.UpdateOperation, "keys/payments-current", nil)
+	doReq(t, logical.UpdateOperation, "keys/payments-2023/config", map[string]interface{}{"deletion_allowed": true})
+	doErrReq(t, logical.DeleteOperation, "keys/payments-2023", nil)
+
+	// The alias can be used in place of the key.
+	plaintext := "dGhlIHF1aWNrIGJyb3duIGZveA=="
+	resp = doReq(t, logical.UpdateOperation, "encrypt/payments-current", map[string]interface{}{"plaintext": plaintext})
+	ciphertext := resp.Data["ciphertext"]
+	resp = doReq(t, logical.UpdateOperation, "decrypt/payments-2023", map[string]interface{}{"ciphertext": ciphertext})
+	if resp.Data["plaintext"] != plaintext {
+		t.Fatalf("unexpected plaintext: %#v", resp.Data)
+	}
+
+	// Cut over to the new key.
+	doReq(t, logical.UpdateOperation, "aliases/payments-current", map[string]interface{}{"key": "payments-2024"})
+	resp = doReq(t, logical.UpdateOperation, "encrypt/payments-current", map[string]interface{}{"plaintext": plaintext})
+	resp = doReq(t, logical.UpdateOperation, "decrypt/payments-2024", map[string]interface{}{"ciphertext": resp.Data["ciphertext"]})
+	if resp.Data["plaintext"] != plaintext {
+		t.Fatalf("unexpected plaintext: %#v", resp.Data)
+	}
+	doErrReq(t, logical.UpdateOperation, "decrypt/payments-current", map[string]interface{}{"ciphertext": ciphertext})
+
+	// The previous key can be deleted once no alias points at it.
+	doReq(t, logical.DeleteOperation, "keys/payments-2023", nil)
+
+	// Deleting the alias leaves the key untouched.
+	doReq(t, logical.DeleteOperation, "aliases/payments-current", nil)
+	if resp := doReq(t, logical.ReadOperation, "aliases/payments-current", nil); resp != nil {
+		t.Fatalf("expected the alias to be deleted, got: %#v", resp)
+	}
+	if resp := doReq(t, logical.ReadOperation, "keys/payments-2024", nil); resp == nil {
+		t.Fatal("expected the key to be kept")
+	}
+}
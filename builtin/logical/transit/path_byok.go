@@ -14,6 +14,7 @@ import (
 	"strings"
 
 	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
 	"github.com/hashicorp/vault/sdk/helper/keysutil"
 	"github.com/hashicorp/vault/sdk/logical"
 )
@@ -22,6 +23,8 @@ func (b *backend) pathBYOKExportKeys() *framework.Path {
 	return &framework.Path{
 		Pattern: "byok-export/" + framework.GenericNameRegex("destination") + "/" + framework.GenericNameRegex("source") + framework.OptionalParamRegex("version"),
 
+		MaxConcurrency: exportMaxConcurrency,
+
 		DisplayAttrs: &framework.DisplayAttributes{
 			OperationPrefix: operationPrefixTransit,
 			OperationVerb:   "byok",
@@ -46,6 +49,12 @@ func (b *backend) pathBYOKExportKeys() *framework.Path {
 				Description: "Hash function to use for inner OAEP encryption. Defaults to SHA256.",
 				Default:     "SHA256",
 			},
+			"wrapping_algorithm": {
+				Type: framework.TypeString,
+				Description: `Algorithm wrapping the exported key with the destination key. One of
+"rsa-oaep-aes-kwp" (default), "rsa-oaep" or "ecdh-es-p384".`,
+				Default: keysutil.WrappingAlgorithmRSAOAEPAESKWP,
+			},
 		},
 
 		Callbacks: map[logical.Operation]framework.OperationFunc{
@@ -62,6 +71,7 @@ func (b *backend) pathPolicyBYOKExportRead(ctx context.Context, req *logical.Req
 	src := d.Get("source").(string)
 	version := d.Get("version").(string)
 	hash := d.Get("hash").(string)
+	algorithm := d.Get("wrapping_algorithm").(string)
 
 	dstP, _, err := b.GetPolicy(ctx, keysutil.PolicyRequest{
 		Storage: req.Storage,
@@ -101,9 +111,9 @@ func (b *backend) pathPolicyBYOKExportRead(ctx context.Context, req *logical.Req
 	switch version {
 	case "":
 		for k, v := range srcP.Keys {
-			exportKey, err := getBYOKExportKey(dstP, srcP, &v, hash)
+			exportKey, err := getBYOKExportKey(dstP, srcP, &v, algorithm, hash)
 			if err != nil {
-				return nil, err
+				return handleBYOKExportError(err)
 			}
 			retKeys[k] = exportKey
 		}
@@ -128,9 +138,9 @@ func (b *backend) pathPolicyBYOKExportRead(ctx context.Context, req *logical.Req
 			return logical.ErrorResponse("version does not exist or cannot be found"), logical.ErrInvalidRequest
 		}
 
-		exportKey, err := getBYOKExportKey(dstP, srcP, &key, hash)
+		exportKey, err := getBYOKExportKey(dstP, srcP, &key, algorithm, hash)
 		if err != nil {
-			return nil, err
+			return handleBYOKExportError(err)
 		}
 
 		retKeys[strconv.Itoa(versionValue)] = exportKey
@@ -147,7 +157,17 @@ func (b *backend) pathPolicyBYOKExportRead(ctx context.Context, req *logical.Req
 	return resp, nil
 }
 
-func getBYOKExportKey(dstP *keysutil.Policy, srcP *keysutil.Policy, key *keysutil.KeyEntry, hash string) (string, error) {
+// handleBYOKExportError returns user errors, such as an unsupported wrapping
+// algorithm for the destination key, as error responses.
+func handleBYOKExportError(err error) (*logical.Response, error) {
+	var userErr errutil.UserError
+	if errors.As(err, &userErr) {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+	return nil, err
+}
+
+func getBYOKExportKey(dstP *keysutil.Policy, srcP *keysutil.Policy, key *keysutil.KeyEntry, algorithm string, hash string) (string, error) {
 	if dstP == nil || srcP == nil {
 		return "", errors.New("nil policy provided")
 	}
@@ -188,7 +208,7 @@ func getBYOKExportKey(dstP *keysutil.Policy, srcP *keysutil.Policy, key *keysuti
 		return "", err
 	}
 
-	return dstP.WrapKey(0, targetKey, srcP.Type, hasher)
+	return dstP.WrapKeyWithAlgorithm(0, targetKey, srcP.Type, algorithm, hasher)
 }
 
 const pathBYOKExportHelpSyn = `Securely export named encryption or signing key`
@@ -202,5 +222,16 @@ the same encryption specification /import, allowing secure migration
 of keys between clusters to enable workloads to communicate between
 them.
 
-Presently this only works for RSA destination keys.
+By default, keys are wrapped with an ephemeral AES-256 key using KWP,
+itself encrypted with RSA-OAEP under the destination key. The
+wrapping_algorithm parameter selects another scheme, for importing keys
+into HSMs and KMS services which don't support this one:
+
+  - "rsa-oaep" encrypts the key directly with RSA-OAEP under the
+    destination RSA key, using the given hash, e.g. SHA512;
+
+  - "ecdh-es-p384" wraps the key using KWP with an AES-256 key derived,
+    with the ANSI X9.63 KDF over SHA-384, from an ECDH agreement between
+    an ephemeral key and the destination ecdsa-p384 key. The result is the
+    uncompressed ephemeral public key followed by the wrapped key.
 `
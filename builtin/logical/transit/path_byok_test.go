@@ -4,9 +4,16 @@
 package transit
 
 import (
+	"bytes"
 	"context"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha512"
+	"encoding/base64"
 	"testing"
 
+	"github.com/google/tink/go/kwp/subtle"
+	"github.com/hashicorp/vault/sdk/helper/keysutil"
 	"github.com/hashicorp/vault/sdk/logical"
 )
 
@@ -227,3 +234,108 @@ func testBYOKExportImport(t *testing.T, keyType, feature string) {
 	// Ensure the original key is functional
 	validationFunc("test-source")
 }
+
+// TestTransit_BYOKExportWrappingAlgorithms verifies that keys exported with
+// the alternative wrapping algorithms can be unwrapped with the destination
+// key.
+func TestTransit_BYOKExportWrappingAlgorithms(t *testing.T) {
+	ctx := context.Background()
+	b, s := createBackendWithStorage(t)
+
+	createKey := func(name, keyType string, exportable bool) *keysutil.Policy {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Path:      "keys/" + name,
+			Operation: logical.UpdateOperation,
+			Storage:   s,
+			Data: map[string]interface{}{
+				"type":       keyType,
+				"exportable": exportable,
+			},
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("resp: %#v\nerr: %v", resp, err)
+		}
+		p, _, err := b.GetPolicy(ctx, keysutil.PolicyRequest{Storage: s, Name: name}, b.GetRandomReader())
+		if err != nil || p == nil {
+			t.Fatalf("failed to read policy %q: %v", name, err)
+		}
+		return p
+	}
+	source := createKey("source", "aes256-gcm96", true).Keys["1"].Key
+	rsaDestination := createKey("rsa-destination", "rsa-4096", false).Keys["1"].RSAKey
+	ecDestination := createKey("ec-destination", "ecdsa-p384", false).Keys["1"].EC_D
+
+	export := func(destination string, data map[string]interface{}) (*logical.Response, error) {
+		return b.HandleRequest(ctx, &logical.Request{
+			Path:      "byok-export/" + destination + "/source/1",
+			Operation: logical.ReadOperation,
+			Storage:   s,
+			Data:      data,
+		})
+	}
+	exported := func(destination string, data map[string]interface{}) []byte {
+		t.Helper()
+		resp, err := export(destination, data)
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("resp: %#v\nerr: %v", resp, err)
+		}
+		wrapped, err := base64.StdEncoding.DecodeString(resp.Data["keys"].(map[string]string)["1"])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return wrapped
+	}
+
+	// RSA-OAEP with SHA-512
+	wrapped := exported("rsa-destination", map[string]interface{}{
+		"wrapping_algorithm": "rsa-oaep",
+		"hash":               "SHA512",
+	})
+	unwrapped, err := rsa.DecryptOAEP(sha512.New(), nil, rsaDestination, wrapped, []byte{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(unwrapped, source) {
+		t.Fatal("RSA-OAEP unwrapped key doesn't match the source key")
+	}
+
+	// ECDH-ES over P-384
+	wrapped = exported("ec-destination", map[string]interface{}{
+		"wrapping_algorithm": "ecdh-es-p384",
+	})
+	curve := elliptic.P384()
+	ephX, ephY := elliptic.Unmarshal(curve, wrapped[:97])
+	if ephX == nil {
+		t.Fatal("invalid ephemeral public key")
+	}
+	x, _ := curve.ScalarMult(ephX, ephY, ecDestination.Bytes())
+	h := sha512.New384()
+	h.Write(x.FillBytes(make([]byte, 48)))
+	h.Write([]byte{0, 0, 0, 1})
+	kwp, err := subtle.NewKWP(h.Sum(nil)[:32])
+	if err != nil {
+		t.Fatal(err)
+	}
+	unwrapped, err = kwp.Unwrap(wrapped[97:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(unwrapped, source) {
+		t.Fatal("ECDH-ES unwrapped key doesn't match the source key")
+	}
+
+	// Algorithms must match the destination key
+	resp, err := export("rsa-destination", map[string]interface{}{
+		"wrapping_algorithm": "ecdh-es-p384",
+	})
+	if err == nil || resp == nil || !resp.IsError() {
+		t.Fatalf("expected an error wrapping with a RSA key using ECDH, got resp: %#v", resp)
+	}
+	resp, err = export("rsa-destination", map[string]interface{}{
+		"wrapping_algorithm": "unknown",
+	})
+	if err == nil || resp == nil || !resp.IsError() {
+		t.Fatalf("expected an error wrapping with an unknown algorithm, got resp: %#v", resp)
+	}
+}
@@ -14,11 +14,15 @@ import (
 const keysConfigPath = "config/keys"
 
 type keysConfig struct {
-	DisableUpsert bool `json:"disable_upsert"`
+	DisableUpsert        bool `json:"disable_upsert"`
+	EnforceHMACKeyType   bool `json:"enforce_hmac_key_type"`
+	DisableUsageTracking bool `json:"disable_usage_tracking"`
 }
 
 var defaultKeysConfig = keysConfig{
-	DisableUpsert: false,
+	DisableUpsert:        false,
+	EnforceHMACKeyType:   false,
+	DisableUsageTracking: false,
 }
 
 func (b *backend) pathConfigKeys() *framework.Path {
@@ -35,6 +39,17 @@ func (b *backend) pathConfigKeys() *framework.Path {
 				Description: `Whether to allow automatic upserting (creation) of
 keys on the encrypt endpoint.`,
 			},
+			"enforce_hmac_key_type": {
+				Type: framework.TypeBool,
+				Description: `Whether to only allow keys of type hmac to be
+used for generating and verifying HMACs, keeping
+MAC keys separate from encryption and signing keys.`,
+			},
+			"disable_usage_tracking": {
+				Type: framework.TypeBool,
+				Description: `Whether to stop counting the operations performed
+with keys and recording when they were last used.`,
+			},
 		},
 
 		Operations: map[logical.Operation]framework.OperationHandler{
@@ -89,14 +104,14 @@ func (b *backend) writeConfigKeys(ctx context.Context, req *logical.Request, cfg
 func respondConfigKeys(cfg *keysConfig) *logical.Response {
 	return &logical.Response{
 		Data: map[string]interface{}{
-			"disable_upsert": cfg.DisableUpsert,
+			"disable_upsert":         cfg.DisableUpsert,
+			"enforce_hmac_key_type":  cfg.EnforceHMACKeyType,
+			"disable_usage_tracking": cfg.DisableUsageTracking,
 		},
 	}
 }
 
 func (b *backend) pathConfigKeysWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
-	upsert := d.Get("disable_upsert").(bool)
-
 	cfg, err := b.readConfigKeys(ctx, req)
 	if err != nil {
 		return nil, err
@@ -104,15 +119,32 @@ func (b *backend) pathConfigKeysWrite(ctx context.Context, req *logical.Request,
 
 	modified := false
 
-	if cfg.DisableUpsert != upsert {
-		cfg.DisableUpsert = upsert
-		modified = true
+	if upsertRaw, ok := d.GetOk("disable_upsert"); ok {
+		if upsert := upsertRaw.(bool); cfg.DisableUpsert != upsert {
+			cfg.DisableUpsert = upsert
+			modified = true
+		}
+	}
+
+	if enforceRaw, ok := d.GetOk("enforce_hmac_key_type"); ok {
+		if enforce := enforceRaw.(bool); cfg.EnforceHMACKeyType != enforce {
+			cfg.EnforceHMACKeyType = enforce
+			modified = true
+		}
+	}
+
+	if trackingRaw, ok := d.GetOk("disable_usage_tracking"); ok {
+		if disable := trackingRaw.(bool); cfg.DisableUsageTracking != disable {
+			cfg.DisableUsageTracking = disable
+			modified = true
+		}
 	}
 
 	if modified {
 		if err := b.writeConfigKeys(ctx, req, cfg); err != nil {
 			return nil, err
 		}
+		b.clearUsageTrackingConfig()
 	}
 
 	return respondConfigKeys(cfg), nil
@@ -132,5 +164,7 @@ const pathConfigKeysHelpSyn = `Configuration common across all keys`
 const pathConfigKeysHelpDesc = `
 This path is used to configure common functionality across all keys. Currently,
 this supports limiting the ability to automatically create new keys when an
-unknown key is used for encryption (upsert).
+unknown key is used for encryption (upsert), restricting HMAC operations to
+keys of type hmac so that MAC keys are never shared with encryption or signing,
+and disabling the tracking of the usage of keys.
 `
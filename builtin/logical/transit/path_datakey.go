@@ -124,6 +124,9 @@ func (b *backend) pathDatakeyWrite(ctx context.Context, req *logical.Request, d
 		p.Lock(false)
 	}
 	defer p.Unlock()
+	if resp, err := b.checkKeyAccess(req, p, keyOperationEncrypt); resp != nil || err != nil {
+		return resp, err
+	}
 
 	newKey := make([]byte, 32)
 	bits := d.Get("bits").(int)
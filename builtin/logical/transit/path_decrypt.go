@@ -184,8 +184,13 @@ func (b *backend) pathDecryptWrite(ctx context.Context, req *logical.Request, d
 	if !b.System().CachingDisabled() {
 		p.Lock(false)
 	}
+	if resp, err := b.checkKeyAccess(req, p, keyOperationDecrypt); resp != nil || err != nil {
+		p.Unlock()
+		return resp, err
+	}
 
 	successesInBatch := false
+	successes := 0
 	for i, item := range batchInputItems {
 		if batchResponseItems[i].Error != "" {
 			continue
@@ -229,6 +234,7 @@ func (b *backend) pathDecryptWrite(ctx context.Context, req *logical.Request, d
 			continue
 		}
 		successesInBatch = true
+		successes++
 		batchResponseItems[i].Plaintext = plaintext
 	}
 
@@ -256,6 +262,7 @@ func (b *backend) pathDecryptWrite(ctx context.Context, req *logical.Request, d
 		}
 	}
 
+	b.recordKeyUsage(ctx, req, p.Name, keyOperationDecrypt, successes)
 	p.Unlock()
 
 	return batchRequestResponse(d, resp, req, successesInBatch, userErrorInBatch, internalErrorInBatch)
@@ -460,12 +460,18 @@ func (b *backend) pathEncryptWrite(ctx context.Context, req *logical.Request, d
 	if !b.System().CachingDisabled() {
 		p.Lock(false)
 	}
+	if resp, err := b.checkKeyAccess(req, p, keyOperationEncrypt); resp != nil || err != nil {
+		p.Unlock()
+		return resp, err
+	}
 
 	// Process batch request items. If encryption of any request
 	// item fails, respectively mark the error in the response
 	// collection and continue to process other items.
 	warnAboutNonceUsage := false
 	successesInBatch := false
+	successes := 0
+	versions := make(map[int]uint64)
 	for i, item := range batchInputItems {
 		if batchResponseItems[i].Error != "" {
 			continue
@@ -520,10 +526,12 @@ func (b *backend) pathEncryptWrite(ctx context.Context, req *logical.Request, d
 		}
 
 		successesInBatch = true
+		successes++
 		keyVersion := item.KeyVersion
 		if keyVersion == 0 {
 			keyVersion = p.LatestVersion
 		}
+		versions[keyVersion]++
 
 		batchResponseItems[i].Ciphertext = ciphertext
 		batchResponseItems[i].KeyVersion = keyVersion
@@ -563,6 +571,8 @@ func (b *backend) pathEncryptWrite(ctx context.Context, req *logical.Request, d
 		resp.AddWarning("Attempted creation of the key during the encrypt operation, but it was created beforehand")
 	}
 
+	b.recordKeyUsage(ctx, req, p.Name, keyOperationEncrypt, successes)
+	b.recordKeyEncryptions(p, versions)
 	p.Unlock()
 
 	return batchRequestResponse(d, resp, req, successesInBatch, userErrorInBatch, internalErrorInBatch)
@@ -27,10 +27,16 @@ const (
 	exportTypePublicKey     = "public-key"
 )
 
+// exportMaxConcurrency limits the concurrent exports of keys, which marshal
+// every version of the keys, so that they can't monopolize the backend.
+const exportMaxConcurrency = 16
+
 func (b *backend) pathExportKeys() *framework.Path {
 	return &framework.Path{
 		Pattern: "export/" + framework.GenericNameRegex("type") + "/" + framework.GenericNameRegex("name") + framework.OptionalParamRegex("version"),
 
+		MaxConcurrency: exportMaxConcurrency,
+
 		DisplayAttrs: &framework.DisplayAttributes{
 			OperationPrefix: operationPrefixTransit,
 			OperationVerb:   "export",
@@ -199,7 +205,7 @@ func getExportKey(policy *keysutil.Policy, key *keysutil.KeyEntry, exportType st
 			}
 			return ecKey, nil
 
-		case keysutil.KeyType_ED25519:
+		case keysutil.KeyType_ED25519, keysutil.KeyType_ED448:
 			if len(key.Key) == 0 {
 				return "", nil
 			}
@@ -231,7 +237,7 @@ func getExportKey(policy *keysutil.Policy, key *keysutil.KeyEntry, exportType st
 			}
 			return ecKey, nil
 
-		case keysutil.KeyType_ED25519:
+		case keysutil.KeyType_ED25519, keysutil.KeyType_ED448:
 			return strings.TrimSpace(key.FormattedPublicKey), nil
 
 		case keysutil.KeyType_RSA2048, keysutil.KeyType_RSA3072, keysutil.KeyType_RSA4096:
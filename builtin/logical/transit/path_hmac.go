@@ -133,9 +133,16 @@ func (b *backend) pathHMACWrite(ctx context.Context, req *logical.Request, d *fr
 	if p == nil {
 		return logical.ErrorResponse("encryption key not found"), logical.ErrInvalidRequest
 	}
+	if resp, err := b.checkHMACKeyType(ctx, req, p); resp != nil || err != nil {
+		return resp, err
+	}
 	if !b.System().CachingDisabled() {
 		p.Lock(false)
 	}
+	if resp, err := b.checkKeyAccess(req, p, keyOperationSign); resp != nil || err != nil {
+		p.Unlock()
+		return resp, err
+	}
 
 	switch {
 	case ver == 0:
@@ -279,9 +286,16 @@ func (b *backend) pathHMACVerify(ctx context.Context, req *logical.Request, d *f
 	if p == nil {
 		return logical.ErrorResponse("encryption key not found"), logical.ErrInvalidRequest
 	}
+	if resp, err := b.checkHMACKeyType(ctx, req, p); resp != nil || err != nil {
+		return resp, err
+	}
 	if !b.System().CachingDisabled() {
 		p.Lock(false)
 	}
+	if resp, err := b.checkKeyAccess(req, p, keyOperationVerify); resp != nil || err != nil {
+		p.Unlock()
+		return resp, err
+	}
 
 	hashAlgorithm, ok := keysutil.HashTypeMap[algorithm]
 	if !ok {
@@ -426,6 +440,23 @@ func (b *backend) pathHMACVerify(ctx context.Context, req *logical.Request, d *f
 	return resp, nil
 }
 
+// checkHMACKeyType returns an error response when the keys configuration only
+// allows keys of type hmac to be used for HMACs and the key is of another type.
+func (b *backend) checkHMACKeyType(ctx context.Context, req *logical.Request, p *keysutil.Policy) (*logical.Response, error) {
+	if p.Type == keysutil.KeyType_HMAC {
+		return nil, nil
+	}
+
+	cfg, err := b.readConfigKeys(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.EnforceHMACKeyType {
+		return logical.ErrorResponse(fmt.Sprintf("key %q is of type %s; only keys of type hmac can be used for HMACs", p.Name, p.Type)), logical.ErrInvalidRequest
+	}
+	return nil, nil
+}
+
 const pathHMACHelpSyn = `Generate an HMAC for input data using the named key`
 
 const pathHMACHelpDesc = `
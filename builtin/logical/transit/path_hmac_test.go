@@ -218,6 +218,86 @@ func TestTransit_HMAC(t *testing.T) {
 	}
 }
 
+func TestTransit_HMACKeySeparation(t *testing.T) {
+	b, storage := createBackendWithSysView(t)
+
+	doReq := func(t *testing.T, path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(context.Background(), &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      path,
+			Storage:   storage,
+			Data:      data,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("got err:\n%#v\nresp:\n%#v\n", err, resp)
+		}
+		return resp
+	}
+	doErrReq := func(t *testing.T, path string, data map[string]interface{}) {
+		t.Helper()
+		resp, err := b.HandleRequest(context.Background(), &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      path,
+			Storage:   storage,
+			Data:      data,
+		})
+		if err == nil && (resp == nil || !resp.IsError()) {
+			t.Fatalf("expected error; resp:\n%#v\n", resp)
+		}
+	}
+
+	input := map[string]interface{}{"input": "dGhlIHF1aWNrIGJyb3duIGZveA=="}
+	doReq(t, "keys/enc", nil)
+	doReq(t, "keys/mac", map[string]interface{}{"type": "hmac", "key_size": 32})
+
+	// Without enforcement, any key can be used for HMACs.
+	doReq(t, "hmac/enc", input)
+
+	resp := doReq(t, "config/keys", map[string]interface{}{"enforce_hmac_key_type": true})
+	if resp.Data["enforce_hmac_key_type"] != true || resp.Data["disable_upsert"] != false {
+		t.Fatalf("unexpected keys configuration: %#v", resp.Data)
+	}
+	doErrReq(t, "hmac/enc", input)
+	doErrReq(t, "verify/enc", map[string]interface{}{"input": input["input"], "hmac": "vault:v1:UcBvm5VskkukzZHlPgm3p5P/Yr/PV6xpuOGZISya3A4="})
+
+	// hmac keys rotate on their own, and min_verify_version retires the HMACs
+	// of older versions.
+	v1 := doReq(t, "hmac/mac", input).Data["hmac"].(string)
+	doReq(t, "keys/mac/rotate", nil)
+	v2 := doReq(t, "hmac/mac", input).Data["hmac"].(string)
+
+	verify := map[string]interface{}{
+		"batch_input": []interface{}{
+			map[string]interface{}{"input": input["input"], "hmac": v1, "reference": "v1"},
+			map[string]interface{}{"input": input["input"], "hmac": v2, "reference": "v2"},
+		},
+	}
+	for _, item := range doReq(t, "verify/mac", verify).Data["batch_results"].([]batchResponseHMACItem) {
+		if !item.Valid {
+			t.Fatalf("expected %s to verify, got: %#v", item.Reference, item)
+		}
+	}
+
+	doErrReq(t, "keys/enc/config", map[string]interface{}{"min_verify_version": 1})
+	doErrReq(t, "keys/mac/config", map[string]interface{}{"min_verify_version": 2, "min_decryption_version": 1})
+	doReq(t, "keys/mac/config", map[string]interface{}{"min_verify_version": 2})
+
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "keys/mac",
+		Storage:   storage,
+	})
+	if err != nil || resp == nil || resp.Data["min_verify_version"] != 2 {
+		t.Fatalf("expected min_verify_version of 2; err: %v, resp: %#v", err, resp)
+	}
+
+	results := doReq(t, "verify/mac", verify).Data["batch_results"].([]batchResponseHMACItem)
+	if results[0].Error == "" || !results[1].Valid {
+		t.Fatalf("expected only v2 to verify, got: %#v", results)
+	}
+}
+
 func TestTransit_batchHMAC(t *testing.T) {
 	b, storage := createBackendWithSysView(t)
 
@@ -103,6 +103,15 @@ being automatically rotated. A value of 0
 (default) disables automatic rotation for the
 key.`,
 			},
+			"auto_rotate_max_operations": {
+				Type:    framework.TypeInt64,
+				Default: 0,
+				Description: `Number of encryptions the latest version of
+the key may perform before the key is
+automatically rotated. A value of 0 (default)
+disables rotation based on the number of
+encryptions.`,
+			},
 		},
 		Callbacks: map[logical.Operation]framework.OperationFunc{
 			logical.UpdateOperation: b.pathImportWrite,
@@ -163,6 +172,7 @@ func (b *backend) pathImportWrite(ctx context.Context, req *logical.Request, d *
 	exportable := d.Get("exportable").(bool)
 	allowPlaintextBackup := d.Get("allow_plaintext_backup").(bool)
 	autoRotatePeriod := time.Second * time.Duration(d.Get("auto_rotate_period").(int))
+	autoRotateMaxOperations := d.Get("auto_rotate_max_operations").(int64)
 	allowRotation := d.Get("allow_rotation").(bool)
 
 	// Ensure the caller didn't supply "convergent_encryption" as a field, since it's not supported on import.
@@ -170,9 +180,12 @@ func (b *backend) pathImportWrite(ctx context.Context, req *logical.Request, d *
 		return nil, errors.New("import cannot be used on keys with convergent encryption enabled")
 	}
 
-	if autoRotatePeriod > 0 && !allowRotation {
+	if (autoRotatePeriod > 0 || autoRotateMaxOperations > 0) && !allowRotation {
 		return nil, errors.New("allow_rotation must be set to true if auto-rotation is enabled")
 	}
+	if autoRotateMaxOperations < 0 {
+		return nil, errors.New("auto_rotate_max_operations must be 0 to disable or positive")
+	}
 
 	// Ensure that at least on `key` field has been set
 	isCiphertextSet, err := checkKeyFieldsSet(d)
@@ -187,6 +200,7 @@ func (b *backend) pathImportWrite(ctx context.Context, req *logical.Request, d *
 		Exportable:               exportable,
 		AllowPlaintextBackup:     allowPlaintextBackup,
 		AutoRotatePeriod:         autoRotatePeriod,
+		AutoRotateMaxOperations:  uint64(autoRotateMaxOperations),
 		AllowImportedKeyRotation: allowRotation,
 		IsPrivateKey:             isCiphertextSet,
 	}
@@ -12,6 +12,7 @@ import (
 	"encoding/pem"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"golang.org/x/crypto/ed25519"
@@ -60,7 +61,7 @@ func (b *backend) pathKeys() *framework.Path {
 				Default: "aes256-gcm96",
 				Description: `
 The type of key to create. Currently, "aes128-gcm96" (symmetric), "aes256-gcm96" (symmetric), "ecdsa-p256"
-(asymmetric), "ecdsa-p384" (asymmetric), "ecdsa-p521" (asymmetric), "ed25519" (asymmetric), "rsa-2048" (asymmetric), "rsa-3072"
+(asymmetric), "ecdsa-p384" (asymmetric), "ecdsa-p521" (asymmetric), "ed25519" (asymmetric), "ed448" (asymmetric), "rsa-2048" (asymmetric), "rsa-3072"
 (asymmetric), "rsa-4096" (asymmetric) are supported.  Defaults to "aes256-gcm96".
 `,
 			},
@@ -117,6 +118,15 @@ return the public key for the given context.`,
 being automatically rotated. A value of 0
 (default) disables automatic rotation for the
 key.`,
+			},
+			"auto_rotate_max_operations": {
+				Type:    framework.TypeInt64,
+				Default: 0,
+				Description: `Number of encryptions the latest version of
+the key may perform before the key is
+automatically rotated. A value of 0 (default)
+disables rotation based on the number of
+encryptions.`,
 			},
 			"key_size": {
 				Type:        framework.TypeInt,
@@ -177,26 +187,39 @@ func (b *backend) pathPolicyWrite(ctx context.Context, req *logical.Request, d *
 	exportable := d.Get("exportable").(bool)
 	allowPlaintextBackup := d.Get("allow_plaintext_backup").(bool)
 	autoRotatePeriod := time.Second * time.Duration(d.Get("auto_rotate_period").(int))
+	autoRotateMaxOperations := d.Get("auto_rotate_max_operations").(int64)
 	managedKeyName := d.Get("managed_key_name").(string)
 	managedKeyId := d.Get("managed_key_id").(string)
 
 	if autoRotatePeriod != 0 && autoRotatePeriod < time.Hour {
 		return logical.ErrorResponse("auto rotate period must be 0 to disable or at least an hour"), nil
 	}
+	if autoRotateMaxOperations < 0 {
+		return logical.ErrorResponse("auto rotate max operations must be 0 to disable or positive"), nil
+	}
+
+	alias, err := getKeyAlias(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if alias != nil {
+		return logical.ErrorResponse(fmt.Sprintf("an alias named %q already exists", name)), logical.ErrInvalidRequest
+	}
 
 	if !derived && convergent {
 		return logical.ErrorResponse("convergent encryption requires derivation to be enabled"), nil
 	}
 
 	polReq := keysutil.PolicyRequest{
-		Upsert:               true,
-		Storage:              req.Storage,
-		Name:                 name,
-		Derived:              derived,
-		Convergent:           convergent,
-		Exportable:           exportable,
-		AllowPlaintextBackup: allowPlaintextBackup,
-		AutoRotatePeriod:     autoRotatePeriod,
+		Upsert:                  true,
+		Storage:                 req.Storage,
+		Name:                    name,
+		Derived:                 derived,
+		Convergent:              convergent,
+		Exportable:              exportable,
+		AllowPlaintextBackup:    allowPlaintextBackup,
+		AutoRotatePeriod:        autoRotatePeriod,
+		AutoRotateMaxOperations: uint64(autoRotateMaxOperations),
 	}
 
 	switch keyType {
@@ -214,6 +237,8 @@ func (b *backend) pathPolicyWrite(ctx context.Context, req *logical.Request, d *
 		polReq.KeyType = keysutil.KeyType_ECDSA_P521
 	case "ed25519":
 		polReq.KeyType = keysutil.KeyType_ED25519
+	case "ed448":
+		polReq.KeyType = keysutil.KeyType_ED448
 	case "rsa-2048":
 		polReq.KeyType = keysutil.KeyType_RSA2048
 	case "rsa-3072":
@@ -236,6 +261,9 @@ func (b *backend) pathPolicyWrite(ctx context.Context, req *logical.Request, d *
 		}
 		polReq.KeySize = keySize
 	}
+	if autoRotateMaxOperations != 0 && !polReq.KeyType.EncryptionSupported() {
+		return logical.ErrorResponse(fmt.Sprintf("auto_rotate_max_operations is not valid for algorithm %v", polReq.KeyType)), logical.ErrInvalidRequest
+	}
 
 	if polReq.KeyType == keysutil.KeyType_MANAGED_KEY {
 		keyId, err := GetManagedKeyUUID(ctx, b, managedKeyName, managedKeyId)
@@ -301,39 +329,68 @@ func (b *backend) pathPolicyRead(ctx context.Context, req *logical.Request, d *f
 		}
 	}
 
-	return b.formatKeyPolicy(p, context)
+	resp, err := b.formatKeyPolicy(p, context)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := b.readKeyUsage(ctx, req.Storage, p.Name)
+	if err != nil {
+		return nil, err
+	}
+	resp.Data["usage"] = formatKeyUsage(usage)
+
+	return resp, nil
 }
 
 func (b *backend) formatKeyPolicy(p *keysutil.Policy, context []byte) (*logical.Response, error) {
 	// Return the response
 	resp := &logical.Response{
 		Data: map[string]interface{}{
-			"name":                   p.Name,
-			"type":                   p.Type.String(),
-			"derived":                p.Derived,
-			"deletion_allowed":       p.DeletionAllowed,
-			"min_available_version":  p.MinAvailableVersion,
-			"min_decryption_version": p.MinDecryptionVersion,
-			"min_encryption_version": p.MinEncryptionVersion,
-			"latest_version":         p.LatestVersion,
-			"exportable":             p.Exportable,
-			"allow_plaintext_backup": p.AllowPlaintextBackup,
-			"supports_encryption":    p.Type.EncryptionSupported(),
-			"supports_decryption":    p.Type.DecryptionSupported(),
-			"supports_signing":       p.Type.SigningSupported(),
-			"supports_derivation":    p.Type.DerivationSupported(),
-			"auto_rotate_period":     int64(p.AutoRotatePeriod.Seconds()),
-			"imported_key":           p.Imported,
+			"name":                       p.Name,
+			"type":                       p.Type.String(),
+			"derived":                    p.Derived,
+			"deletion_allowed":           p.DeletionAllowed,
+			"min_available_version":      p.MinAvailableVersion,
+			"min_decryption_version":     p.MinDecryptionVersion,
+			"min_encryption_version":     p.MinEncryptionVersion,
+			"latest_version":             p.LatestVersion,
+			"exportable":                 p.Exportable,
+			"allow_plaintext_backup":     p.AllowPlaintextBackup,
+			"supports_encryption":        p.Type.EncryptionSupported(),
+			"supports_decryption":        p.Type.DecryptionSupported(),
+			"supports_signing":           p.Type.SigningSupported(),
+			"supports_derivation":        p.Type.DerivationSupported(),
+			"auto_rotate_period":         int64(p.AutoRotatePeriod.Seconds()),
+			"auto_rotate_max_operations": p.AutoRotateMaxOperations,
+			"imported_key":               p.Imported,
 		},
 	}
 	if p.KeySize != 0 {
 		resp.Data["key_size"] = p.KeySize
 	}
 
+	// HMAC keys are never used for decryption, the minimum decryption version
+	// is the minimum version allowed to verify HMACs.
+	if p.Type == keysutil.KeyType_HMAC {
+		resp.Data["min_verify_version"] = p.MinDecryptionVersion
+	}
+
 	if p.Imported {
 		resp.Data["imported_key_allow_rotation"] = p.AllowImportedKeyRotation
 	}
 
+	if len(p.AccessPolicies) > 0 {
+		accessPolicies := make(map[string]interface{}, len(p.AccessPolicies))
+		for operation, accessPolicy := range p.AccessPolicies {
+			accessPolicies[operation] = map[string]interface{}{
+				"entity_ids": accessPolicy.EntityIDs,
+				"group_ids":  accessPolicy.GroupIDs,
+			}
+		}
+		resp.Data["access_policies"] = accessPolicies
+	}
+
 	if p.BackupInfo != nil {
 		resp.Data["backup_info"] = map[string]interface{}{
 			"time":    p.BackupInfo.Time,
@@ -369,7 +426,7 @@ func (b *backend) formatKeyPolicy(p *keysutil.Policy, context []byte) (*logical.
 		}
 		resp.Data["keys"] = retKeys
 
-	case keysutil.KeyType_ECDSA_P256, keysutil.KeyType_ECDSA_P384, keysutil.KeyType_ECDSA_P521, keysutil.KeyType_ED25519, keysutil.KeyType_RSA2048, keysutil.KeyType_RSA3072, keysutil.KeyType_RSA4096:
+	case keysutil.KeyType_ECDSA_P256, keysutil.KeyType_ECDSA_P384, keysutil.KeyType_ECDSA_P521, keysutil.KeyType_ED25519, keysutil.KeyType_ED448, keysutil.KeyType_RSA2048, keysutil.KeyType_RSA3072, keysutil.KeyType_RSA4096:
 		retKeys := map[string]map[string]interface{}{}
 		for k, v := range p.Keys {
 			key := asymKey{
@@ -405,6 +462,8 @@ func (b *backend) formatKeyPolicy(p *keysutil.Policy, context []byte) (*logical.
 					}
 				}
 				key.Name = "ed25519"
+			case keysutil.KeyType_ED448:
+				key.Name = "ed448"
 			case keysutil.KeyType_RSA2048, keysutil.KeyType_RSA3072, keysutil.KeyType_RSA4096:
 				key.Name = "rsa-2048"
 				if p.Type == keysutil.KeyType_RSA3072 {
@@ -449,12 +508,24 @@ func (b *backend) formatKeyPolicy(p *keysutil.Policy, context []byte) (*logical.
 func (b *backend) pathPolicyDelete(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
 	name := d.Get("name").(string)
 
+	aliases, err := aliasesOfKey(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(aliases) > 0 {
+		return logical.ErrorResponse(fmt.Sprintf("key %q is pointed at by aliases %s; delete them or point them at another key first", name, strings.Join(aliases, ", "))), logical.ErrInvalidRequest
+	}
+
 	// Delete does its own locking
-	err := b.lm.DeletePolicy(ctx, req.Storage, name)
+	err = b.lm.DeletePolicy(ctx, req.Storage, name)
 	if err != nil {
 		return logical.ErrorResponse(fmt.Sprintf("error deleting policy %s: %s", name, err)), err
 	}
 
+	if err := b.deleteKeyUsage(ctx, req.Storage, name); err != nil {
+		return nil, err
+	}
+
 	return nil, nil
 }
 
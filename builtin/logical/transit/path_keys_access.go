@@ -0,0 +1,226 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package transit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-secure-stdlib/strutil"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/keysutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// The classes of operations whose allowed identities can be restricted per
+// key. Rewrapping requires both decrypt and encrypt access, generating data
+// keys requires encrypt access, and generating and verifying HMACs require
+// sign and verify access respectively.
+const (
+	keyOperationEncrypt = "encrypt"
+	keyOperationDecrypt = "decrypt"
+	keyOperationSign    = "sign"
+	keyOperationVerify  = "verify"
+)
+
+var keyOperations = []string{keyOperationEncrypt, keyOperationDecrypt, keyOperationSign, keyOperationVerify}
+
+func (b *backend) pathKeysAccess() *framework.Path {
+	return &framework.Path{
+		Pattern: "keys/" + framework.GenericNameRegex("name") + "/access/" + framework.GenericNameRegex("operation"),
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixTransit,
+			OperationSuffix: "key-access-policy",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the key",
+			},
+
+			"operation": {
+				Type: framework.TypeString,
+				Description: `Class of operations the policy applies to: one of
+"encrypt", "decrypt", "sign" or "verify".`,
+			},
+
+			"entity_ids": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "IDs of the identity entities allowed to perform the operations",
+			},
+
+			"group_ids": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "IDs of the identity groups whose members are allowed to perform the operations",
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathKeysAccessWrite,
+				Summary:  "Restricts the identities allowed to perform a class of operations with the key",
+			},
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathKeysAccessRead,
+				Summary:  "Returns the identities allowed to perform a class of operations with the key",
+			},
+			logical.DeleteOperation: &framework.PathOperation{
+				Callback: b.pathKeysAccessDelete,
+				Summary:  "Lifts the restriction of the identities allowed to perform a class of operations with the key",
+			},
+		},
+
+		HelpSynopsis:    pathKeysAccessHelpSyn,
+		HelpDescription: pathKeysAccessHelpDesc,
+	}
+}
+
+// getAccessPolicyKey returns the key whose access policies are configured,
+// locked exclusively.
+func (b *backend) getAccessPolicyKey(ctx context.Context, req *logical.Request, d *framework.FieldData) (*keysutil.Policy, *logical.Response, error) {
+	operation := d.Get("operation").(string)
+	if !strutil.StrListContains(keyOperations, operation) {
+		return nil, logical.ErrorResponse("unknown operation %q; must be one of %s", operation, strings.Join(keyOperations, ", ")), logical.ErrInvalidRequest
+	}
+
+	name := d.Get("name").(string)
+	p, _, err := b.GetPolicy(ctx, keysutil.PolicyRequest{
+		Storage: req.Storage,
+		Name:    name,
+	}, b.GetRandomReader())
+	if err != nil {
+		return nil, nil, err
+	}
+	if p == nil {
+		return nil, logical.ErrorResponse(fmt.Sprintf("no existing key named %s could be found", name)), logical.ErrInvalidRequest
+	}
+	if !b.System().CachingDisabled() {
+		p.Lock(true)
+	}
+
+	return p, nil, nil
+}
+
+func (b *backend) pathKeysAccessWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	p, resp, err := b.getAccessPolicyKey(ctx, req, d)
+	if p == nil {
+		return resp, err
+	}
+	defer p.Unlock()
+
+	accessPolicy := &keysutil.KeyAccessPolicy{
+		EntityIDs: d.Get("entity_ids").([]string),
+		GroupIDs:  d.Get("group_ids").([]string),
+	}
+	if len(accessPolicy.EntityIDs) == 0 && len(accessPolicy.GroupIDs) == 0 {
+		return logical.ErrorResponse("at least one of entity_ids or group_ids must be set"), logical.ErrInvalidRequest
+	}
+
+	operation := d.Get("operation").(string)
+	original, hadOriginal := p.AccessPolicies[operation]
+	if p.AccessPolicies == nil {
+		p.AccessPolicies = make(map[string]*keysutil.KeyAccessPolicy)
+	}
+	p.AccessPolicies[operation] = accessPolicy
+
+	if err := p.Persist(ctx, req.Storage); err != nil {
+		if hadOriginal {
+			p.AccessPolicies[operation] = original
+		} else {
+			delete(p.AccessPolicies, operation)
+		}
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (b *backend) pathKeysAccessRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	p, resp, err := b.getAccessPolicyKey(ctx, req, d)
+	if p == nil {
+		return resp, err
+	}
+	defer p.Unlock()
+
+	accessPolicy, ok := p.AccessPolicies[d.Get("operation").(string)]
+	if !ok {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"entity_ids": accessPolicy.EntityIDs,
+			"group_ids":  accessPolicy.GroupIDs,
+		},
+	}, nil
+}
+
+func (b *backend) pathKeysAccessDelete(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	p, resp, err := b.getAccessPolicyKey(ctx, req, d)
+	if p == nil {
+		return resp, err
+	}
+	defer p.Unlock()
+
+	operation := d.Get("operation").(string)
+	original, ok := p.AccessPolicies[operation]
+	if !ok {
+		return nil, nil
+	}
+	delete(p.AccessPolicies, operation)
+
+	if err := p.Persist(ctx, req.Storage); err != nil {
+		p.AccessPolicies[operation] = original
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// checkKeyAccess returns a permission denied error if the key restricts the
+// identities allowed to perform the class of operations, and the entity of
+// the request is neither allowed nor a member of an allowed group. Requests
+// without an entity, such as those made with the root token, are denied.
+func (b *backend) checkKeyAccess(req *logical.Request, p *keysutil.Policy, operation string) (*logical.Response, error) {
+	accessPolicy, ok := p.AccessPolicies[operation]
+	if !ok {
+		return nil, nil
+	}
+
+	if req.EntityID != "" {
+		if strutil.StrListContains(accessPolicy.EntityIDs, req.EntityID) {
+			return nil, nil
+		}
+
+		if len(accessPolicy.GroupIDs) > 0 {
+			groups, err := b.System().GroupsForEntity(req.EntityID)
+			if err != nil {
+				return nil, err
+			}
+			for _, group := range groups {
+				if strutil.StrListContains(accessPolicy.GroupIDs, group.ID) {
+					return nil, nil
+				}
+			}
+		}
+	}
+
+	return logical.ErrorResponse("the identity of the request is not allowed to %s with key %q", operation, p.Name), logical.ErrPermissionDenied
+}
+
+const pathKeysAccessHelpSyn = `Restrict the identities allowed to use a named key`
+
+const pathKeysAccessHelpDesc = `
+This path restricts the identity entities and groups allowed to perform
+a class of operations with the named key: "encrypt", which also covers
+generating data keys; "decrypt"; "sign", which also covers generating
+HMACs; and "verify", which also covers verifying HMACs. Rewrapping
+requires both "decrypt" and "encrypt". The restriction is enforced in
+addition to the ACL policies of the paths, and requests without an
+entity are denied. Classes of operations without a restriction are
+allowed to any request permitted by its ACL policies.
+`
@@ -0,0 +1,115 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package transit
+
+import (
+	"context"
+	"encoding/base64"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestTransit_KeyAccessPolicies(t *testing.T) {
+	sysView := logical.TestSystemView()
+	storage := &logical.InmemStorage{}
+	conf := &logical.BackendConfig{
+		StorageView: storage,
+		System:      sysView,
+	}
+	b, _ := Backend(context.Background(), conf)
+	if b == nil {
+		t.Fatal("failed to create backend")
+	}
+	if err := b.Backend.Setup(context.Background(), conf); err != nil {
+		t.Fatal(err)
+	}
+
+	doReq := func(t *testing.T, entityID string, op logical.Operation, path string, data map[string]interface{}) (*logical.Response, error) {
+		t.Helper()
+		return b.HandleRequest(namespace.RootContext(nil), &logical.Request{
+			Operation: op,
+			Path:      path,
+			Storage:   storage,
+			Data:      data,
+			EntityID:  entityID,
+		})
+	}
+	expectAllowed := func(t *testing.T, entityID string, op logical.Operation, path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := doReq(t, entityID, op, path, data)
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("got err:\n%#v\nresp:\n%#v\n", err, resp)
+		}
+		return resp
+	}
+	expectDenied := func(t *testing.T, entityID string, path string, data map[string]interface{}) {
+		t.Helper()
+		resp, err := doReq(t, entityID, logical.UpdateOperation, path, data)
+		if err != logical.ErrPermissionDenied {
+			t.Fatalf("expected permission denied, got err:\n%#v\nresp:\n%#v\n", err, resp)
+		}
+	}
+
+	expectAllowed(t, "", logical.UpdateOperation, "keys/payments", nil)
+
+	plaintext := map[string]interface{}{
+		"plaintext": base64.StdEncoding.EncodeToString([]byte(testPlaintext)),
+	}
+	resp := expectAllowed(t, "", logical.UpdateOperation, "encrypt/payments", plaintext)
+	ciphertext := map[string]interface{}{
+		"ciphertext": resp.Data["ciphertext"],
+	}
+
+	resp, err := doReq(t, "", logical.UpdateOperation, "keys/payments/access/unwrap", map[string]interface{}{"entity_ids": "entity1"})
+	if err != logical.ErrInvalidRequest {
+		t.Fatalf("expected an unknown operation to be rejected, got err: %v resp: %#v", err, resp)
+	}
+	resp, err = doReq(t, "", logical.UpdateOperation, "keys/payments/access/decrypt", nil)
+	if err != logical.ErrInvalidRequest {
+		t.Fatalf("expected an empty policy to be rejected, got err: %v resp: %#v", err, resp)
+	}
+
+	expectAllowed(t, "", logical.UpdateOperation, "keys/payments/access/decrypt", map[string]interface{}{
+		"entity_ids": "entity1",
+		"group_ids":  "group2",
+	})
+	resp = expectAllowed(t, "", logical.ReadOperation, "keys/payments/access/decrypt", nil)
+	if !reflect.DeepEqual(resp.Data["entity_ids"], []string{"entity1"}) || !reflect.DeepEqual(resp.Data["group_ids"], []string{"group2"}) {
+		t.Fatalf("unexpected access policy: %#v", resp.Data)
+	}
+	resp = expectAllowed(t, "", logical.ReadOperation, "keys/payments", nil)
+	if _, ok := resp.Data["access_policies"].(map[string]interface{})["decrypt"]; !ok {
+		t.Fatalf("expected the key to report its access policies: %#v", resp.Data)
+	}
+
+	// Only the allowed entity and the members of the allowed group may
+	// decrypt, while encryption is unrestricted.
+	sysView.GroupsVal = []*logical.Group{{ID: "group1"}}
+	expectDenied(t, "", "decrypt/payments", ciphertext)
+	expectDenied(t, "entity2", "decrypt/payments", ciphertext)
+	expectDenied(t, "entity2", "rewrap/payments", ciphertext)
+	expectAllowed(t, "entity1", logical.UpdateOperation, "decrypt/payments", ciphertext)
+	expectAllowed(t, "entity2", logical.UpdateOperation, "encrypt/payments", plaintext)
+
+	sysView.GroupsVal = []*logical.Group{{ID: "group2"}}
+	expectAllowed(t, "entity2", logical.UpdateOperation, "decrypt/payments", ciphertext)
+	expectAllowed(t, "entity2", logical.UpdateOperation, "rewrap/payments", ciphertext)
+
+	// Rewrapping also requires encrypt access.
+	expectAllowed(t, "", logical.UpdateOperation, "keys/payments/access/encrypt", map[string]interface{}{
+		"entity_ids": "entity1",
+	})
+	expectDenied(t, "entity2", "rewrap/payments", ciphertext)
+	expectDenied(t, "entity2", "datakey/plaintext/payments", nil)
+
+	expectAllowed(t, "", logical.DeleteOperation, "keys/payments/access/decrypt", nil)
+	expectAllowed(t, "", logical.UpdateOperation, "decrypt/payments", ciphertext)
+	resp = expectAllowed(t, "", logical.ReadOperation, "keys/payments/access/decrypt", nil)
+	if resp != nil {
+		t.Fatalf("expected no access policy, got: %#v", resp.Data)
+	}
+}
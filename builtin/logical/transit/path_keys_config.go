@@ -36,6 +36,14 @@ to be decrypted. For signing keys, the minimum
 version allowed to be used for verification.`,
 			},
 
+			"min_verify_version": {
+				Type: framework.TypeInt,
+				Description: `If set, the minimum version of an hmac key
+allowed to be used for verifying HMACs. This is
+the same setting as min_decryption_version,
+named after its meaning for hmac keys.`,
+			},
+
 			"min_encryption_version": {
 				Type: framework.TypeInt,
 				Description: `If set, the minimum version of the key allowed
@@ -65,6 +73,14 @@ the latest version of the key is allowed.`,
 being automatically rotated. A value of 0
 disables automatic rotation for the key.`,
 			},
+
+			"auto_rotate_max_operations": {
+				Type: framework.TypeInt64,
+				Description: `Number of encryptions the latest version of
+the key may perform before the key is
+automatically rotated. A value of 0 disables
+rotation based on the number of encryptions.`,
+			},
 		},
 
 		Callbacks: map[logical.Operation]framework.OperationFunc{
@@ -118,6 +134,15 @@ func (b *backend) pathKeysConfigWrite(ctx context.Context, req *logical.Request,
 	persistNeeded := false
 
 	minDecryptionVersionRaw, ok := d.GetOk("min_decryption_version")
+	if minVerifyVersionRaw, verifyOk := d.GetOk("min_verify_version"); verifyOk {
+		if p.Type != keysutil.KeyType_HMAC {
+			return logical.ErrorResponse("min verify version only applies to keys of type hmac; use min decryption version instead"), nil
+		}
+		if ok && minDecryptionVersionRaw.(int) != minVerifyVersionRaw.(int) {
+			return logical.ErrorResponse("min verify version and min decryption version must match when both are set"), nil
+		}
+		minDecryptionVersionRaw, ok = minVerifyVersionRaw, true
+	}
 	if ok {
 		minDecryptionVersion := minDecryptionVersionRaw.(int)
 
@@ -220,6 +245,25 @@ func (b *backend) pathKeysConfigWrite(ctx context.Context, req *logical.Request,
 		}
 	}
 
+	autoRotateMaxOperationsRaw, ok, err := d.GetOkErr("auto_rotate_max_operations")
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		autoRotateMaxOperations := autoRotateMaxOperationsRaw.(int64)
+		if autoRotateMaxOperations < 0 {
+			return logical.ErrorResponse("auto rotate max operations must be 0 to disable or positive"), nil
+		}
+		if autoRotateMaxOperations != 0 && !p.Type.EncryptionSupported() {
+			return logical.ErrorResponse(fmt.Sprintf("auto_rotate_max_operations is not valid for algorithm %v", p.Type)), logical.ErrInvalidRequest
+		}
+
+		if uint64(autoRotateMaxOperations) != p.AutoRotateMaxOperations {
+			p.AutoRotateMaxOperations = uint64(autoRotateMaxOperations)
+			persistNeeded = true
+		}
+	}
+
 	if !persistNeeded {
 		resp, err := b.formatKeyPolicy(p, nil)
 		if err != nil {
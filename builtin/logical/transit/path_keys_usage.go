@@ -0,0 +1,382 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package transit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/helper/keysutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// keyUsagePrefix is the storage prefix of the usage of keys.
+const keyUsagePrefix = "usage/"
+
+// keyUsage counts the operations performed with a key, and records when each
+// class of operations was last performed. The encryptions performed with each
+// version of keys rotating after a number of encryptions are counted too.
+type keyUsage struct {
+	Counts      map[string]uint64    `json:"counts"`
+	LastUsed    map[string]time.Time `json:"last_used"`
+	Encryptions map[int]uint64       `json:"encryptions,omitempty"`
+}
+
+func newKeyUsage() *keyUsage {
+	return &keyUsage{
+		Counts:      make(map[string]uint64),
+		LastUsed:    make(map[string]time.Time),
+		Encryptions: make(map[int]uint64),
+	}
+}
+
+// merge adds the counts of other to the usage, keeping the latest of the
+// last used times.
+func (u *keyUsage) merge(other *keyUsage) {
+	for operation, count := range other.Counts {
+		u.Counts[operation] += count
+	}
+	for operation, lastUsed := range other.LastUsed {
+		if lastUsed.After(u.LastUsed[operation]) {
+			u.LastUsed[operation] = lastUsed
+		}
+	}
+	for version, count := range other.Encryptions {
+		u.Encryptions[version] += count
+	}
+}
+
+// keyUsageTracker accumulates the usage of keys in memory until it is
+// periodically flushed to storage, so that operations on keys don't write to
+// storage.
+type keyUsageTracker struct {
+	l sync.Mutex
+
+	// pending is the usage not yet flushed to storage, keyed by key name.
+	pending map[string]*keyUsage
+
+	// disabled caches whether usage tracking is disabled by the keys
+	// configuration. It is nil until the configuration is loaded.
+	disabled *bool
+}
+
+func (b *backend) pathKeysUsage() *framework.Path {
+	return &framework.Path{
+		Pattern: "keys/" + framework.GenericNameRegex("name") + "/usage",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixTransit,
+			OperationSuffix: "key-usage",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the key",
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathKeysUsageRead,
+				Summary:  "Returns the number of operations performed with a key, and when they were last performed",
+			},
+		},
+
+		HelpSynopsis:    pathKeysUsageHelpSyn,
+		HelpDescription: pathKeysUsageHelpDesc,
+	}
+}
+
+func (b *backend) pathKeysUsageRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+
+	target, err := b.resolveKeyAlias(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if target != "" {
+		name = target
+	}
+
+	entry, err := req.Storage.Get(ctx, "policy/"+name)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	usage, err := b.readKeyUsage(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+
+	data := formatKeyUsage(usage)
+	data["name"] = name
+	return &logical.Response{
+		Data: data,
+	}, nil
+}
+
+// formatKeyUsage returns the usage of a key as returned by the API. Every
+// class of operations is counted, but only those which were performed have a
+// last used time.
+func formatKeyUsage(usage *keyUsage) map[string]interface{} {
+	counts := make(map[string]uint64, len(keyOperations))
+	lastUsed := make(map[string]string, len(usage.LastUsed))
+	var latest time.Time
+	for _, operation := range keyOperations {
+		counts[operation] = usage.Counts[operation]
+		if t, ok := usage.LastUsed[operation]; ok {
+			lastUsed[operation] = t.Format(time.RFC3339Nano)
+			if t.After(latest) {
+				latest = t
+			}
+		}
+	}
+
+	data := map[string]interface{}{
+		"counts":         counts,
+		"last_used":      lastUsed,
+		"last_used_time": "",
+	}
+	if !latest.IsZero() {
+		data["last_used_time"] = latest.Format(time.RFC3339Nano)
+	}
+	return data
+}
+
+// readKeyUsage returns the usage of the named key, both stored and pending.
+func (b *backend) readKeyUsage(ctx context.Context, s logical.Storage, name string) (*keyUsage, error) {
+	usage, err := getKeyUsage(ctx, s, name)
+	if err != nil {
+		return nil, err
+	}
+
+	b.usage.l.Lock()
+	defer b.usage.l.Unlock()
+	if pending, ok := b.usage.pending[name]; ok {
+		usage.merge(pending)
+	}
+	return usage, nil
+}
+
+func getKeyUsage(ctx context.Context, s logical.Storage, name string) (*keyUsage, error) {
+	usage := newKeyUsage()
+
+	entry, err := s.Get(ctx, keyUsagePrefix+name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch usage of key %q: %w", name, err)
+	}
+	if entry == nil {
+		return usage, nil
+	}
+
+	if err := entry.DecodeJSON(usage); err != nil {
+		return nil, fmt.Errorf("failed to decode usage of key %q: %w", name, err)
+	}
+	if usage.Counts == nil {
+		usage.Counts = make(map[string]uint64)
+	}
+	if usage.LastUsed == nil {
+		usage.LastUsed = make(map[string]time.Time)
+	}
+	if usage.Encryptions == nil {
+		usage.Encryptions = make(map[int]uint64)
+	}
+	return usage, nil
+}
+
+// usageTrackingDisabled returns whether the keys configuration disables usage
+// tracking. The configuration is cached unless caching is disabled.
+func (b *backend) usageTrackingDisabled(ctx context.Context, req *logical.Request) (bool, error) {
+	if b.System().CachingDisabled() {
+		cfg, err := b.readConfigKeys(ctx, req)
+		if err != nil {
+			return false, err
+		}
+		return cfg.DisableUsageTracking, nil
+	}
+
+	b.usage.l.Lock()
+	defer b.usage.l.Unlock()
+
+	if b.usage.disabled == nil {
+		cfg, err := b.readConfigKeys(ctx, req)
+		if err != nil {
+			return false, err
+		}
+		b.usage.disabled = &cfg.DisableUsageTracking
+	}
+	return *b.usage.disabled, nil
+}
+
+// clearUsageTrackingConfig drops the cached keys configuration, so that it is
+// read again the next time a key is used.
+func (b *backend) clearUsageTrackingConfig() {
+	b.usage.l.Lock()
+	defer b.usage.l.Unlock()
+	b.usage.disabled = nil
+}
+
+// recordKeyUsage counts count operations of the given class performed with
+// the named key. Failing to do so doesn't fail the operations, which were
+// already performed.
+func (b *backend) recordKeyUsage(ctx context.Context, req *logical.Request, name, operation string, count int) {
+	if count == 0 {
+		return
+	}
+
+	disabled, err := b.usageTrackingDisabled(ctx, req)
+	if err != nil {
+		b.Logger().Warn("failed to record key usage", "key", name, "error", err)
+		return
+	}
+	if disabled {
+		return
+	}
+
+	b.usage.l.Lock()
+	defer b.usage.l.Unlock()
+
+	if b.usage.pending == nil {
+		b.usage.pending = make(map[string]*keyUsage)
+	}
+	usage, ok := b.usage.pending[name]
+	if !ok {
+		usage = newKeyUsage()
+		b.usage.pending[name] = usage
+	}
+	usage.Counts[operation] += uint64(count)
+	usage.LastUsed[operation] = time.Now().UTC()
+}
+
+// recordKeyEncryptions counts the encryptions performed with each version of
+// the key, when it rotates after a number of encryptions. They are counted
+// even when usage tracking is disabled, as the rotation of the key depends on
+// them.
+func (b *backend) recordKeyEncryptions(p *keysutil.Policy, versions map[int]uint64) {
+	if p.AutoRotateMaxOperations == 0 || len(versions) == 0 {
+		return
+	}
+
+	b.usage.l.Lock()
+	defer b.usage.l.Unlock()
+
+	if b.usage.pending == nil {
+		b.usage.pending = make(map[string]*keyUsage)
+	}
+	usage, ok := b.usage.pending[p.Name]
+	if !ok {
+		usage = newKeyUsage()
+		b.usage.pending[p.Name] = usage
+	}
+	for version, count := range versions {
+		usage.Encryptions[version] += count
+	}
+}
+
+// deleteKeyUsage deletes the usage of the named key, both stored and pending.
+func (b *backend) deleteKeyUsage(ctx context.Context, s logical.Storage, name string) error {
+	b.usage.l.Lock()
+	delete(b.usage.pending, name)
+	b.usage.l.Unlock()
+
+	return s.Delete(ctx, keyUsagePrefix+name)
+}
+
+// flushKeyUsage adds the pending usage of keys to their stored usage. Usage
+// which can't be stored stays pending until the next flush, while the usage
+// of keys deleted in the meantime is dropped. Nodes which can't write to
+// storage keep the usage of keys pending, and only report it themselves.
+// Keys which performed encryptions are then rotated if their latest version
+// reached its maximum number of encryptions.
+func (b *backend) flushKeyUsage(ctx context.Context, req *logical.Request) error {
+	if b.System().ReplicationState().HasState(consts.ReplicationDRSecondary|consts.ReplicationPerformanceStandby) ||
+		(!b.System().LocalMount() && b.System().ReplicationState().HasState(consts.ReplicationPerformanceSecondary)) {
+		return nil
+	}
+
+	b.usage.l.Lock()
+	pending := b.usage.pending
+	b.usage.pending = nil
+	b.usage.l.Unlock()
+
+	var errs *multierror.Error
+	var encrypted []string
+	for name, usage := range pending {
+		if len(usage.Encryptions) > 0 {
+			encrypted = append(encrypted, name)
+		}
+		if err := b.storeKeyUsage(ctx, req.Storage, name, usage); err != nil {
+			errs = multierror.Append(errs, err)
+
+			b.usage.l.Lock()
+			if b.usage.pending == nil {
+				b.usage.pending = make(map[string]*keyUsage)
+			}
+			if newer, ok := b.usage.pending[name]; ok {
+				usage.merge(newer)
+			}
+			b.usage.pending[name] = usage
+			b.usage.l.Unlock()
+		}
+	}
+
+	for _, name := range encrypted {
+		p, _, err := b.GetPolicy(ctx, keysutil.PolicyRequest{
+			Storage: req.Storage,
+			Name:    name,
+		}, b.GetRandomReader())
+		if err != nil {
+			errs = multierror.Append(errs, err)
+			continue
+		}
+		if p == nil {
+			continue
+		}
+		if err := b.rotateIfRequired(ctx, req, name, p); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+
+	return errs.ErrorOrNil()
+}
+
+func (b *backend) storeKeyUsage(ctx context.Context, s logical.Storage, name string, pending *keyUsage) error {
+	entry, err := s.Get(ctx, "policy/"+name)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return nil
+	}
+
+	usage, err := getKeyUsage(ctx, s, name)
+	if err != nil {
+		return err
+	}
+	usage.merge(pending)
+
+	entry, err = logical.StorageEntryJSON(keyUsagePrefix+name, usage)
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage of key %q: %w", name, err)
+	}
+	return s.Put(ctx, entry)
+}
+
+const pathKeysUsageHelpSyn = `Report the usage of a key`
+
+const pathKeysUsageHelpDesc = `
+This path reports how many encrypt, decrypt, sign and verify operations were
+performed with the named key, and when each of them was last performed. Usage
+is accumulated in memory and periodically stored, and can be disabled with the
+disable_usage_tracking option of config/keys.
+`
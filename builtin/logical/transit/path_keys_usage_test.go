@@ -0,0 +1,203 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package transit
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestTransit_KeyUsage(t *testing.T) {
+	b, storage := createBackendWithSysView(t)
+	ctx := namespace.RootContext(nil)
+
+	doReq := func(t *testing.T, op logical.Operation, path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: op,
+			Path:      path,
+			Storage:   storage,
+			Data:      data,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("got err:\n%#v\nresp:\n%#v\n", err, resp)
+		}
+		return resp
+	}
+	checkCounts := func(t *testing.T, path string, expected map[string]uint64) {
+		t.Helper()
+		resp := doReq(t, logical.ReadOperation, path, nil)
+		usage := resp.Data
+		if path == "keys/enc" || path == "keys/sig" {
+			usage = resp.Data["usage"].(map[string]interface{})
+		}
+		if counts := usage["counts"]; !reflect.DeepEqual(counts, expected) {
+			t.Fatalf("unexpected counts of %s: %#v", path, counts)
+		}
+	}
+
+	doReq(t, logical.UpdateOperation, "keys/enc", nil)
+	doReq(t, logical.UpdateOperation, "keys/sig", map[string]interface{}{"type": "ed25519"})
+
+	checkCounts(t, "keys/enc/usage", map[string]uint64{"encrypt": 0, "decrypt": 0, "sign": 0, "verify": 0})
+	resp := doReq(t, logical.ReadOperation, "keys/enc/usage", nil)
+	if resp.Data["last_used_time"] != "" {
+		t.Fatalf("expected the key to have never been used, got: %#v", resp.Data)
+	}
+
+	// Only the successful items of batches are counted
+	resp = doReq(t, logical.UpdateOperation, "encrypt/enc", map[string]interface{}{"plaintext": "aGVsbG8K"})
+	ciphertext := resp.Data["ciphertext"].(string)
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "encrypt/enc",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"batch_input": []interface{}{
+				map[string]interface{}{"plaintext": "aGVsbG8K"},
+				map[string]interface{}{"plaintext": "not base64"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	doReq(t, logical.UpdateOperation, "decrypt/enc", map[string]interface{}{"ciphertext": ciphertext})
+	resp = doReq(t, logical.UpdateOperation, "sign/sig", map[string]interface{}{"input": "aGVsbG8K"})
+	doReq(t, logical.UpdateOperation, "verify/sig", map[string]interface{}{
+		"input":     "aGVsbG8K",
+		"signature": resp.Data["signature"],
+	})
+
+	checkCounts(t, "keys/enc/usage", map[string]uint64{"encrypt": 2, "decrypt": 1, "sign": 0, "verify": 0})
+	checkCounts(t, "keys/sig", map[string]uint64{"encrypt": 0, "decrypt": 0, "sign": 1, "verify": 1})
+	resp = doReq(t, logical.ReadOperation, "keys/enc/usage", nil)
+	lastUsed := resp.Data["last_used"].(map[string]string)
+	if lastUsed["encrypt"] == "" || lastUsed["decrypt"] == "" || lastUsed["sign"] != "" {
+		t.Fatalf("unexpected last used times: %#v", lastUsed)
+	}
+	if resp.Data["last_used_time"] == "" {
+		t.Fatalf("expected a last used time, got: %#v", resp.Data)
+	}
+
+	// Usage is flushed to storage periodically, and the usage since then is
+	// added to it
+	if err := b.periodicFunc(ctx, &logical.Request{Storage: storage}); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := getKeyUsage(ctx, storage, "enc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Counts[keyOperationEncrypt] != 2 || stored.Counts[keyOperationDecrypt] != 1 {
+		t.Fatalf("unexpected stored usage: %#v", stored)
+	}
+	doReq(t, logical.UpdateOperation, "encrypt/enc", map[string]interface{}{"plaintext": "aGVsbG8K"})
+	checkCounts(t, "keys/enc", map[string]uint64{"encrypt": 3, "decrypt": 1, "sign": 0, "verify": 0})
+
+	// Aliases report the usage of the key they point at
+	doReq(t, logical.UpdateOperation, "aliases/current", map[string]interface{}{"key": "enc"})
+	doReq(t, logical.UpdateOperation, "encrypt/current", map[string]interface{}{"plaintext": "aGVsbG8K"})
+	checkCounts(t, "keys/current/usage", map[string]uint64{"encrypt": 4, "decrypt": 1, "sign": 0, "verify": 0})
+	doReq(t, logical.DeleteOperation, "aliases/current", nil)
+
+	// Nothing is counted while tracking is disabled
+	doReq(t, logical.UpdateOperation, "config/keys", map[string]interface{}{"disable_usage_tracking": true})
+	doReq(t, logical.UpdateOperation, "encrypt/enc", map[string]interface{}{"plaintext": "aGVsbG8K"})
+	checkCounts(t, "keys/enc/usage", map[string]uint64{"encrypt": 4, "decrypt": 1, "sign": 0, "verify": 0})
+	doReq(t, logical.UpdateOperation, "config/keys", map[string]interface{}{"disable_usage_tracking": false})
+	doReq(t, logical.UpdateOperation, "encrypt/enc", map[string]interface{}{"plaintext": "aGVsbG8K"})
+	checkCounts(t, "keys/enc/usage", map[string]uint64{"encrypt": 5, "decrypt": 1, "sign": 0, "verify": 0})
+
+	// The usage of deleted keys is deleted with them
+	if err := b.periodicFunc(ctx, &logical.Request{Storage: storage}); err != nil {
+		t.Fatal(err)
+	}
+	doReq(t, logical.UpdateOperation, "keys/enc/config", map[string]interface{}{"deletion_allowed": true})
+	doReq(t, logical.DeleteOperation, "keys/enc", nil)
+	entry, err := storage.Get(ctx, keyUsagePrefix+"enc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry != nil {
+		t.Fatal("expected the usage of the deleted key to be deleted")
+	}
+	if resp := doReq(t, logical.ReadOperation, "keys/enc/usage", nil); resp != nil {
+		t.Fatalf("expected no usage for a deleted key, got: %#v", resp.Data)
+	}
+}
+
+func TestTransit_AutoRotateMaxOperations(t *testing.T) {
+	b, storage := createBackendWithSysView(t)
+	ctx := namespace.RootContext(nil)
+
+	doReq := func(t *testing.T, op logical.Operation, path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: op,
+			Path:      path,
+			Storage:   storage,
+			Data:      data,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("got err:\n%#v\nresp:\n%#v\n", err, resp)
+		}
+		return resp
+	}
+	encrypt := func(t *testing.T, count int, keyVersion int) {
+		t.Helper()
+		var batch []interface{}
+		for i := 0; i < count; i++ {
+			batch = append(batch, map[string]interface{}{"plaintext": "aGVsbG8K", "key_version": keyVersion})
+		}
+		doReq(t, logical.UpdateOperation, "encrypt/enc", map[string]interface{}{"batch_input": batch})
+	}
+	checkLatestVersion := func(t *testing.T, expected int) {
+		t.Helper()
+		if err := b.periodicFunc(ctx, &logical.Request{Storage: storage}); err != nil {
+			t.Fatal(err)
+		}
+		resp := doReq(t, logical.ReadOperation, "keys/enc", nil)
+		if resp.Data["latest_version"] != expected {
+			t.Fatalf("incorrect latest_version found, got: %d, want: %d", resp.Data["latest_version"], expected)
+		}
+	}
+
+	// Only keys supporting encryption rotate after a number of encryptions
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "keys/sig",
+		Storage:   storage,
+		Data:      map[string]interface{}{"type": "ed25519", "auto_rotate_max_operations": 3},
+	})
+	if err == nil || resp == nil || !resp.IsError() {
+		t.Fatalf("expected an error creating a signing key, got: %#v", resp)
+	}
+
+	resp = doReq(t, logical.UpdateOperation, "keys/enc", map[string]interface{}{"auto_rotate_max_operations": 3})
+	if resp.Data["auto_rotate_max_operations"] != uint64(3) {
+		t.Fatalf("unexpected auto_rotate_max_operations: %#v", resp.Data["auto_rotate_max_operations"])
+	}
+
+	encrypt(t, 2, 0)
+	checkLatestVersion(t, 1)
+	encrypt(t, 1, 0)
+	checkLatestVersion(t, 2)
+
+	// Encryptions with older versions don't count towards the latest one
+	encrypt(t, 3, 1)
+	checkLatestVersion(t, 2)
+
+	// Encryptions are counted even when usage tracking is disabled
+	doReq(t, logical.UpdateOperation, "config/keys", map[string]interface{}{"disable_usage_tracking": true})
+	encrypt(t, 3, 0)
+	checkLatestVersion(t, 3)
+
+	doReq(t, logical.UpdateOperation, "keys/enc/config", map[string]interface{}{"auto_rotate_max_operations": 0})
+	encrypt(t, 5, 0)
+	checkLatestVersion(t, 3)
+}
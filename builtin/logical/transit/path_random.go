@@ -36,7 +36,7 @@ func (b *backend) pathRandom() *framework.Path {
 			"format": {
 				Type:        framework.TypeString,
 				Default:     "base64",
-				Description: `Encoding format to use. Can be "hex" or "base64". Defaults to "base64".`,
+				Description: `Encoding format to use. Can be "hex", "base64", "base64url" or "base32". Defaults to "base64".`,
 			},
 
 			"source": {
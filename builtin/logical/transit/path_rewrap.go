@@ -145,6 +145,14 @@ func (b *backend) pathRewrapWrite(ctx context.Context, req *logical.Request, d *
 	if !b.System().CachingDisabled() {
 		p.Lock(false)
 	}
+	if resp, err := b.checkKeyAccess(req, p, keyOperationDecrypt); resp != nil || err != nil {
+		p.Unlock()
+		return resp, err
+	}
+	if resp, err := b.checkKeyAccess(req, p, keyOperationEncrypt); resp != nil || err != nil {
+		p.Unlock()
+		return resp, err
+	}
 
 	warnAboutNonceUsage := false
 	for i, item := range batchInputItems {
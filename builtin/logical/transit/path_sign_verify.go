@@ -371,6 +371,10 @@ func (b *backend) pathSignWrite(ctx context.Context, req *logical.Request, d *fr
 	if !b.System().CachingDisabled() {
 		p.Lock(false)
 	}
+	if resp, err := b.checkKeyAccess(req, p, keyOperationSign); resp != nil || err != nil {
+		p.Unlock()
+		return resp, err
+	}
 
 	if !p.Type.SigningSupported() {
 		p.Unlock()
@@ -400,6 +404,7 @@ func (b *backend) pathSignWrite(ctx context.Context, req *logical.Request, d *fr
 	}
 
 	response := make([]batchResponseSignItem, len(batchInputItems))
+	successes := 0
 
 	for i, item := range batchInputItems {
 
@@ -471,6 +476,7 @@ func (b *backend) pathSignWrite(ctx context.Context, req *logical.Request, d *fr
 			response[i].Signature = sig.Signature
 			response[i].PublicKey = sig.PublicKey
 			response[i].KeyVersion = keyVersion
+			successes++
 		}
 	}
 
@@ -504,6 +510,7 @@ func (b *backend) pathSignWrite(ctx context.Context, req *logical.Request, d *fr
 		}
 	}
 
+	b.recordKeyUsage(ctx, req, p.Name, keyOperationSign, successes)
 	p.Unlock()
 	return resp, nil
 }
@@ -624,6 +631,10 @@ func (b *backend) pathVerifyWrite(ctx context.Context, req *logical.Request, d *
 	if !b.System().CachingDisabled() {
 		p.Lock(false)
 	}
+	if resp, err := b.checkKeyAccess(req, p, keyOperationVerify); resp != nil || err != nil {
+		p.Unlock()
+		return resp, err
+	}
 
 	if !p.Type.SigningSupported() {
 		p.Unlock()
@@ -631,6 +642,7 @@ func (b *backend) pathVerifyWrite(ctx context.Context, req *logical.Request, d *
 	}
 
 	response := make([]batchResponseVerifyItem, len(batchInputItems))
+	successes := 0
 
 	for i, item := range batchInputItems {
 
@@ -707,6 +719,7 @@ func (b *backend) pathVerifyWrite(ctx context.Context, req *logical.Request, d *
 			}
 		} else {
 			response[i].Valid = valid
+			successes++
 		}
 	}
 
@@ -733,6 +746,7 @@ func (b *backend) pathVerifyWrite(ctx context.Context, req *logical.Request, d *
 		}
 	}
 
+	b.recordKeyUsage(ctx, req, p.Name, keyOperationVerify, successes)
 	p.Unlock()
 	return resp, nil
 }
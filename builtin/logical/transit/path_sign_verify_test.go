@@ -714,6 +714,102 @@ func TestTransit_SignVerify_ED25519(t *testing.T) {
 	verifyRequest(req, false, outcome, "bar", goodsig, true)
 }
 
+func TestTransit_SignVerify_ED448(t *testing.T) {
+	b, storage := createBackendWithSysView(t)
+
+	doReq := func(path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(context.Background(), &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      path,
+			Data:      data,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("got err:\n%#v\nresp:\n%#v\n", err, resp)
+		}
+		return resp
+	}
+	verify := func(input, signature string) bool {
+		t.Helper()
+		resp := doReq("verify/foo", map[string]interface{}{
+			"input":     input,
+			"signature": signature,
+		})
+		return resp.Data["valid"].(bool)
+	}
+
+	// ed448 keys can't be derived
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      "keys/bar",
+		Data: map[string]interface{}{
+			"type":    "ed448",
+			"derived": true,
+		},
+	})
+	if err == nil && (resp == nil || !resp.IsError()) {
+		t.Fatal("expected an error creating a derived ed448 key")
+	}
+
+	doReq("keys/foo", map[string]interface{}{"type": "ed448"})
+	resp, err = b.HandleRequest(context.Background(), &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      "keys/foo",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data["type"] != "ed448" || resp.Data["supports_signing"] != true || resp.Data["supports_encryption"] != false {
+		t.Fatalf("unexpected key: %#v", resp.Data)
+	}
+	keys := resp.Data["keys"].(map[string]map[string]interface{})
+	pubKey, err := base64.StdEncoding.DecodeString(keys["1"]["public_key"].(string))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pubKey) != 57 || keys["1"]["name"] != "ed448" {
+		t.Fatalf("unexpected public key: %#v", keys["1"])
+	}
+
+	input := "dGhlIHF1aWNrIGJyb3duIGZveA=="
+	v1Sig := doReq("sign/foo", map[string]interface{}{"input": input}).Data["signature"].(string)
+	if !strings.HasPrefix(v1Sig, "vault:v1:") {
+		t.Fatalf("unexpected signature: %s", v1Sig)
+	}
+	if !verify(input, v1Sig) {
+		t.Fatal("expected the signature to be valid")
+	}
+	if verify("YW5vdGhlciBpbnB1dA==", v1Sig) {
+		t.Fatal("expected the signature of another input to be invalid")
+	}
+
+	// Signatures of older versions still verify after rotation
+	doReq("keys/foo/rotate", nil)
+	v2Sig := doReq("sign/foo", map[string]interface{}{"input": input}).Data["signature"].(string)
+	if !strings.HasPrefix(v2Sig, "vault:v2:") {
+		t.Fatalf("unexpected signature: %s", v2Sig)
+	}
+	if !verify(input, v1Sig) || !verify(input, v2Sig) {
+		t.Fatal("expected the signatures of both versions to be valid")
+	}
+	doReq("keys/foo/config", map[string]interface{}{"min_decryption_version": 2})
+	resp, err = b.HandleRequest(context.Background(), &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      "verify/foo",
+		Data: map[string]interface{}{
+			"input":     input,
+			"signature": v1Sig,
+		},
+	})
+	if err == nil && (resp == nil || !resp.IsError()) {
+		t.Fatal("expected an error verifying a signature of a version below the minimum")
+	}
+}
+
 func TestTransit_SignVerify_RSA_PSS(t *testing.T) {
 	t.Run("2048", func(t *testing.T) {
 		testTransit_SignVerify_RSA_PSS(t, 2048)
@@ -0,0 +1,130 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+)
+
+var (
+	_ cli.Command             = (*AuditTailCommand)(nil)
+	_ cli.CommandAutocomplete = (*AuditTailCommand)(nil)
+)
+
+type AuditTailCommand struct {
+	*BaseCommand
+
+	flagFilter string
+
+	// ShutdownCh is used to capture interrupt signal and end streaming
+	ShutdownCh chan struct{}
+}
+
+func (c *AuditTailCommand) Synopsis() string {
+	return "Stream the entries of an audit device"
+}
+
+func (c *AuditTailCommand) Help() string {
+	helpText := `
+Usage: vault audit tail [options] PATH
+
+  Streams the entries logged by an audit device as they are logged, as JSON,
+  one entry per line. Entries are hashed as they are by the device. Only the
+  entries logged by the server handling the request are streamed.
+
+  The argument corresponds to the PATH of audit device, not the TYPE!
+
+  Stream the entries of the audit device enabled at "file/":
+
+      $ vault audit tail file/
+
+  Stream only the entries for requests to the "secret/" mount:
+
+      $ vault audit tail -filter='type == "request" and mount_point == "secret/"' file/
+
+` + c.Flags().Help()
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *AuditTailCommand) Flags() *FlagSets {
+	set := c.flagSet(FlagSetHTTP)
+
+	f := set.NewFlagSet("Command Options")
+
+	f.StringVar(&StringVar{
+		Name:       "filter",
+		Target:     &c.flagFilter,
+		Completion: complete.PredictAnything,
+		Usage: "Boolean expression selecting the entries to stream. The " +
+			"expression may refer to the \"type\", \"operation\", \"path\", " +
+			"\"mount_type\", \"mount_point\", \"namespace\" and " +
+			"\"remote_address\" fields of an entry.",
+	})
+
+	return set
+}
+
+func (c *AuditTailCommand) AutocompleteArgs() complete.Predictor {
+	return c.PredictVaultAudits()
+}
+
+func (c *AuditTailCommand) AutocompleteFlags() complete.Flags {
+	return c.Flags().Completions()
+}
+
+func (c *AuditTailCommand) Run(args []string) int {
+	f := c.Flags()
+
+	if err := f.Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	args = f.Args()
+	switch {
+	case len(args) < 1:
+		c.UI.Error(fmt.Sprintf("Not enough arguments (expected 1, got %d)", len(args)))
+		return 1
+	case len(args) > 1:
+		c.UI.Error(fmt.Sprintf("Too many arguments (expected 1, got %d)", len(args)))
+		return 1
+	}
+
+	path := ensureTrailingSlash(sanitizePath(args[0]))
+
+	client, err := c.Client()
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 2
+	}
+
+	// Remove the default 60 second timeout so we can stream indefinitely
+	client.SetClientTimeout(0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	entryCh, err := client.Sys().AuditTail(ctx, path, c.flagFilter)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error tailing audit device: %s", err))
+		return 2
+	}
+
+	for {
+		select {
+		case entry, ok := <-entryCh:
+			if !ok {
+				return 0
+			}
+			c.UI.Output(entry)
+		case <-c.ShutdownCh:
+			return 0
+		}
+	}
+}
@@ -4,12 +4,14 @@
 package command
 
 import (
+	"errors"
 	"os"
 	"sort"
 	"strings"
 	"sync"
 
 	"github.com/hashicorp/vault/api"
+	"github.com/mitchellh/mapstructure"
 	"github.com/posener/complete"
 )
 
@@ -85,6 +87,7 @@ func (b *BaseCommand) PredictVaultAvailableMounts() complete.Predictor {
 	// This list does not contain deprecated backends. At present, there is no
 	// API that lists all available secret backends, so this is hard-coded :(.
 	return complete.PredictSet(
+		"accounts",
 		"aws",
 		"consul",
 		"database",
@@ -460,12 +463,36 @@ func (p *Predict) mountInfos() (map[string]*api.MountOutput, error) {
 
 	mounts, err := client.Sys().ListMounts()
 	if err != nil {
+		// Tokens that cannot read sys/mounts can still see the mounts their
+		// policies grant access to.
+		if visible, visibleErr := p.visibleMountInfos(); visibleErr == nil {
+			return visible, nil
+		}
 		return nil, err
 	}
 
 	return mounts, nil
 }
 
+// visibleMountInfos returns the secrets engine mounts the client's token has
+// access to, as listed by the sys/internal/ui/mounts endpoint, which is
+// available to every token.
+func (p *Predict) visibleMountInfos() (map[string]*api.MountOutput, error) {
+	secret, err := p.Client().Logical().Read("sys/internal/ui/mounts")
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, errors.New("data from server response is empty")
+	}
+
+	mounts := map[string]*api.MountOutput{}
+	if err := mapstructure.Decode(secret.Data["secret"], &mounts); err != nil {
+		return nil, err
+	}
+	return mounts, nil
+}
+
 // mounts returns a sorted list of the mount paths for Vault server for
 // which the client is configured to communicate with. This function returns the
 // default list of mounts if an error occurs.
@@ -344,6 +344,7 @@ func TestPredict_Plugins(t *testing.T) {
 			"good_path",
 			client,
 			[]string{
+				"accounts",
 				"ad",
 				"alicloud",
 				"approle",
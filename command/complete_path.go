@@ -0,0 +1,119 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+)
+
+var (
+	_ cli.Command             = (*CompletePathCommand)(nil)
+	_ cli.CommandAutocomplete = (*CompletePathCommand)(nil)
+)
+
+type CompletePathCommand struct {
+	*BaseCommand
+
+	flagFoldersOnly bool
+}
+
+func (c *CompletePathCommand) Synopsis() string {
+	return "Suggest Vault paths for shell completion"
+}
+
+func (c *CompletePathCommand) Help() string {
+	helpText := `
+Usage: vault complete-path [options] [PREFIX]
+
+  Prints the mounts and paths on the Vault server that start with the given
+  prefix, one per line. Mounts are listed from the mounts the token has access
+  to, and keys are listed from the mount, so only paths permitted by the
+  token's policies are suggested. Errors are not reported, since this command
+  is meant to be called by shell completion functions.
+
+  Suggest the mounts the token has access to:
+
+      $ vault complete-path
+
+  Suggest the keys under a KV mount:
+
+      $ vault complete-path secret/
+
+  Complete secret paths in bash by adding to the shell profile:
+
+      _vault_paths() { COMPREPLY=($(vault complete-path "${COMP_WORDS[COMP_CWORD]}")); }
+      complete -o nospace -F _vault_paths vault
+
+  Complete secret paths in fish:
+
+      complete -c vault -f -a '(vault complete-path (commandline -ct))'
+
+` + c.Flags().Help()
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *CompletePathCommand) Flags() *FlagSets {
+	set := c.flagSet(FlagSetHTTP)
+
+	f := set.NewFlagSet("Command Options")
+
+	f.BoolVar(&BoolVar{
+		Name:    "folders-only",
+		Target:  &c.flagFoldersOnly,
+		Default: false,
+		Usage:   "Only suggest mounts and folders, not the keys within them.",
+	})
+
+	return set
+}
+
+func (c *CompletePathCommand) AutocompleteArgs() complete.Predictor {
+	return c.PredictVaultFolders()
+}
+
+func (c *CompletePathCommand) AutocompleteFlags() complete.Flags {
+	return c.Flags().Completions()
+}
+
+func (c *CompletePathCommand) Run(args []string) int {
+	f := c.Flags()
+
+	if err := f.Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	args = f.Args()
+	var prefix string
+	switch len(args) {
+	case 0:
+	case 1:
+		prefix = strings.TrimSpace(args[0])
+	default:
+		c.UI.Error(fmt.Sprintf("Too many arguments (expected 0-1, got %d)", len(args)))
+		return 1
+	}
+
+	client, err := c.Client()
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 2
+	}
+
+	p := &Predict{client: client}
+	predictions := p.vaultPaths(!c.flagFoldersOnly).Predict(complete.Args{
+		All:  []string{prefix},
+		Last: prefix,
+	})
+
+	for _, prediction := range predictions {
+		c.UI.Output(prediction)
+	}
+	return 0
+}
@@ -0,0 +1,122 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/mitchellh/cli"
+)
+
+func testCompletePathCommand(tb testing.TB) (*cli.MockUi, *CompletePathCommand) {
+	tb.Helper()
+
+	ui := cli.NewMockUi()
+	return ui, &CompletePathCommand{
+		BaseCommand: &BaseCommand{
+			UI: ui,
+		},
+	}
+}
+
+func TestCompletePathCommand_Run(t *testing.T) {
+	t.Parallel()
+
+	client, closer := testVaultServer(t)
+	defer closer()
+
+	data := map[string]interface{}{"a": "b"}
+	for _, path := range []string{"secret/bar", "secret/foo", "secret/zip/zap"} {
+		if _, err := client.Logical().Write(path, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		name string
+		args []string
+		out  string
+		code int
+	}{
+		{
+			"too_many_args",
+			[]string{"foo", "bar"},
+			"Too many arguments",
+			1,
+		},
+		{
+			"mounts",
+			nil,
+			"secret/\n",
+			0,
+		},
+		{
+			"keys",
+			[]string{"secret/"},
+			"secret/bar\nsecret/foo\nsecret/zip/\n",
+			0,
+		},
+		{
+			"partial_key",
+			[]string{"secret/f"},
+			"secret/foo\n",
+			0,
+		},
+		{
+			"folders_only",
+			[]string{"-folders-only", "secret/"},
+			"secret/zip/\n",
+			0,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			ui, cmd := testCompletePathCommand(t)
+			cmd.client = client
+
+			code := cmd.Run(tc.args)
+			if code != tc.code {
+				t.Errorf("expected %d to be %d", code, tc.code)
+			}
+
+			combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+			if !strings.Contains(combined, tc.out) {
+				t.Errorf("expected %q to contain %q", combined, tc.out)
+			}
+		})
+	}
+
+	t.Run("acl_filtered_mounts", func(t *testing.T) {
+		if err := client.Sys().PutPolicy("secret-reader", `path "secret/*" { capabilities = ["read", "list"] }`); err != nil {
+			t.Fatal(err)
+		}
+		secret, err := client.Auth().Token().Create(&api.TokenCreateRequest{
+			Policies: []string{"secret-reader"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		restricted, err := client.Clone()
+		if err != nil {
+			t.Fatal(err)
+		}
+		restricted.SetToken(secret.Auth.ClientToken)
+
+		ui, cmd := testCompletePathCommand(t)
+		cmd.client = restricted
+
+		if code := cmd.Run([]string{"se"}); code != 0 {
+			t.Fatalf("expected 0, got %d: %s", code, ui.ErrorWriter.String())
+		}
+		if out := ui.OutputWriter.String(); !strings.Contains(out, "secret/") {
+			t.Errorf("expected %q to contain %q", out, "secret/")
+		}
+	})
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/hashicorp/hcl"
 	"github.com/hashicorp/hcl/hcl/ast"
+	"github.com/hashicorp/vault/command/token"
 	"github.com/hashicorp/vault/sdk/helper/hclutil"
 	homedir "github.com/mitchellh/go-homedir"
 )
@@ -31,6 +32,12 @@ type DefaultConfig struct {
 	// is not specified, then vault's internal token store will be used, which
 	// stores the token on disk unencrypted.
 	TokenHelper string `hcl:"token_helper"`
+
+	// TokenStore is the credential store of the operating system the
+	// internal token helper stores the token in, instead of on disk: one of
+	// "keychain", "wincred" or "secret-service", which must be supported by
+	// this build. It cannot be combined with TokenHelper.
+	TokenStore string `hcl:"token_store"`
 }
 
 // Config loads the configuration and returns it. If the configuration
@@ -90,6 +97,7 @@ func ParseConfig(contents string) (*DefaultConfig, error) {
 
 	valid := []string{
 		"token_helper",
+		"token_store",
 	}
 	if err := hclutil.CheckHCLKeys(list, valid); err != nil {
 		return nil, err
@@ -99,5 +107,13 @@ func ParseConfig(contents string) (*DefaultConfig, error) {
 	if err := hcl.DecodeObject(&c, list); err != nil {
 		return nil, err
 	}
+	if c.TokenHelper != "" && c.TokenStore != "" {
+		return nil, fmt.Errorf("only one of token_helper or token_store may be set")
+	}
+	if c.TokenStore != "" {
+		if err := token.CheckKeyringTokenStore(c.TokenStore); err != nil {
+			return nil, fmt.Errorf("invalid token_store: %w", err)
+		}
+	}
 	return &c, nil
 }
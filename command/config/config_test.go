@@ -4,10 +4,13 @@
 package config
 
 import (
+	"fmt"
 	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/hashicorp/vault/command/token"
 )
 
 const FixturePath = "../test-fixtures"
@@ -50,3 +53,34 @@ nope = "true"
 		t.Errorf("bad error: %s", err.Error())
 	}
 }
+
+func TestParseConfig_tokenStore(t *testing.T) {
+	// Only the stores supported by this build may be configured
+	for _, store := range token.KeyringTokenStores() {
+		config, err := ParseConfig(fmt.Sprintf("token_store = %q", store))
+		if token.CheckKeyringTokenStore(store) != nil {
+			if err == nil {
+				t.Errorf("expected an error for the unsupported token store %q", store)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if config.TokenStore != store {
+			t.Errorf("expected %q to be %q", config.TokenStore, store)
+		}
+	}
+
+	if _, err := ParseConfig(`token_store = "plaintext"`); err == nil {
+		t.Fatal("expected error")
+	}
+
+	_, err := ParseConfig(`
+token_helper = "/token"
+token_store = "keychain"
+`)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
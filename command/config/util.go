@@ -15,6 +15,10 @@ func DefaultTokenHelper() (token.TokenHelper, error) {
 		return nil, err
 	}
 
+	if config.TokenStore != "" {
+		return token.NewKeyringTokenHelper(config.TokenStore)
+	}
+
 	path := config.TokenHelper
 	if path == "" {
 		return token.NewInternalTokenHelper()
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/hashicorp/go-secure-stdlib/strutil"
+	"github.com/hashicorp/vault/api"
 	"github.com/mitchellh/cli"
 	"github.com/posener/complete"
 )
@@ -21,8 +22,10 @@ var (
 type MonitorCommand struct {
 	*BaseCommand
 
-	logLevel  string
-	logFormat string
+	logLevel   string
+	logFormat  string
+	subsystems []string
+	mounts     []string
 
 	// ShutdownCh is used to capture interrupt signal and end streaming
 	ShutdownCh chan struct{}
@@ -41,6 +44,12 @@ Usage: vault monitor [options]
 	the server may be logging at the INFO level, but with the monitor command
 	you can set -log-level=DEBUG.
 
+	The log messages can be restricted to those of some subsystems or mounts,
+	for instance to debug a single mount without streaming the logs of the
+	whole server:
+
+	    $ vault monitor -log-level=trace -mount=secret/ -subsystem=expiration
+
 ` + c.Flags().Help()
 
 	return strings.TrimSpace(helpText)
@@ -66,6 +75,19 @@ func (c *MonitorCommand) Flags() *FlagSets {
 		Completion: complete.PredictSet("standard", "json"),
 		Usage:      "Output format of logs. Supported values are \"standard\" and \"json\".",
 	})
+	f.StringSliceVar(&StringSliceVar{
+		Name:   "subsystem",
+		Target: &c.subsystems,
+		Usage: "If passed, only the logs of the subsystem, such as \"core\" or " +
+			"\"expiration\", are streamed. This can be specified multiple times.",
+	})
+	f.StringSliceVar(&StringSliceVar{
+		Name:   "mount",
+		Target: &c.mounts,
+		Usage: "If passed, only the logs of the mount at the path, such as " +
+			"\"secret/\" or \"auth/userpass/\", are streamed. This can be " +
+			"specified multiple times.",
+	})
 
 	return set
 }
@@ -118,7 +140,12 @@ func (c *MonitorCommand) Run(args []string) int {
 	var logCh chan string
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	logCh, err = client.Sys().Monitor(ctx, c.logLevel, c.logFormat)
+	logCh, err = client.Sys().MonitorWithInput(ctx, &api.MonitorInput{
+		LogLevel:   c.logLevel,
+		LogFormat:  c.logFormat,
+		Subsystems: c.subsystems,
+		Mounts:     c.mounts,
+	})
 	if err != nil {
 		c.UI.Error(fmt.Sprintf("Error starting monitor: %s", err))
 		return 1
@@ -52,6 +52,7 @@ type OperatorDiagnoseCommand struct {
 	diagnose *diagnose.Session
 
 	flagDebug    bool
+	flagLive     bool
 	flagSkips    []string
 	flagConfigs  []string
 	cleanupGuard sync.Once
@@ -85,6 +86,12 @@ Usage: vault operator diagnose
 
      $ vault operator diagnose -config=/etc/vault/config.hcl -skip=listener
 
+  Additionally check the audit devices, external plugins and raft cluster of
+  the running Vault server at VAULT_ADDR, using the token in VAULT_TOKEN, which
+  requires sudo capability on sys/audit-test:
+
+     $ vault operator diagnose -config=/etc/vault/config.hcl -skip=listener -live
+
 ` + c.Flags().Help()
 	return strings.TrimSpace(helpText)
 }
@@ -113,6 +120,16 @@ func (c *OperatorDiagnoseCommand) Flags() *FlagSets {
 		Usage:  "Skip the health checks named as arguments. May be 'listener', 'storage', or 'autounseal'.",
 	})
 
+	f.BoolVar(&BoolVar{
+		Name:    "live",
+		Target:  &c.flagLive,
+		Default: false,
+		Usage: "Also run checks against the running Vault server: log a test " +
+			"message through each audit device, ping each external plugin, and " +
+			"check the health of the raft cluster. The server address and token " +
+			"are read from the VAULT_ADDR and VAULT_TOKEN environment variables.",
+	})
+
 	f.BoolVar(&BoolVar{
 		Name:    "debug",
 		Target:  &c.flagDebug,
@@ -684,14 +701,18 @@ SEALFAIL:
 			return fmt.Errorf("Diagnose could not create unique UUID for unsealing.")
 		}
 		barrierEncValue := "diagnose-" + barrierUUID
+		start := time.Now()
 		ciphertext, err := barrierWrapper.Encrypt(ctx, []byte(barrierEncValue), nil)
 		if err != nil {
 			return fmt.Errorf("Error encrypting with seal barrier: %w.", err)
 		}
+		diagnose.SealLatencyCheck(ctx, "encrypt", time.Since(start))
+		start = time.Now()
 		plaintext, err := barrierWrapper.Decrypt(ctx, ciphertext, nil)
 		if err != nil {
 			return fmt.Errorf("Error decrypting with seal barrier: %w", err)
 		}
+		diagnose.SealLatencyCheck(ctx, "decrypt", time.Since(start))
 		if string(plaintext) != barrierEncValue {
 			return fmt.Errorf("Barrier returned incorrect decrypted value for mock data.")
 		}
@@ -754,9 +775,35 @@ SEALFAIL:
 		}
 	}
 
+	if c.flagLive {
+		c.liveDiagnostics(ctx)
+	}
+
 	return nil
 }
 
+// liveDiagnostics runs the checks that require a running Vault server.
+func (c *OperatorDiagnoseCommand) liveDiagnostics(ctx context.Context) {
+	ctx, span := diagnose.StartSpan(ctx, "Check Running Server")
+	defer span.End()
+
+	client, err := c.Client()
+	if err != nil {
+		diagnose.Fail(ctx, fmt.Sprintf("Could not create a client for the running server: %s.", err))
+		return
+	}
+
+	diagnose.Test(ctx, "Check Audit Devices", diagnose.WithTimeout(30*time.Second, func(ctx context.Context) error {
+		return diagnose.AuditDeviceChecks(ctx, client)
+	}))
+	diagnose.Test(ctx, "Check External Plugins", diagnose.WithTimeout(30*time.Second, func(ctx context.Context) error {
+		return diagnose.PluginChecks(ctx, client)
+	}))
+	diagnose.Test(ctx, "Check Raft Cluster", diagnose.WithTimeout(30*time.Second, func(ctx context.Context) error {
+		return diagnose.RaftClusterHealth(ctx, client)
+	}))
+}
+
 func coalesce(values ...interface{}) interface{} {
 	for _, val := range values {
 		if val != nil && val != "" {
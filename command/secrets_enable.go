@@ -38,6 +38,7 @@ type SecretsEnableCommand struct {
 	flagOptions                   map[string]string
 	flagLocal                     bool
 	flagSealWrap                  bool
+	flagDataKey                   bool
 	flagExternalEntropyAccess     bool
 	flagVersion                   int
 	flagAllowedManagedKeys        []string
@@ -206,6 +207,15 @@ func (c *SecretsEnableCommand) Flags() *FlagSets {
 		Usage:   "Enable seal wrapping of critical values in the secrets engine.",
 	})
 
+	f.BoolVar(&BoolVar{
+		Name:    "data-key",
+		Target:  &c.flagDataKey,
+		Default: false,
+		Usage: "Encrypt the entries of the secrets engine with a data key of its " +
+			"own, which can be rotated independently and is destroyed when the " +
+			"secrets engine is disabled.",
+	})
+
 	f.BoolVar(&BoolVar{
 		Name:    "external-entropy-access",
 		Target:  &c.flagExternalEntropyAccess,
@@ -296,6 +306,7 @@ func (c *SecretsEnableCommand) Run(args []string) int {
 		Description:           c.flagDescription,
 		Local:                 c.flagLocal,
 		SealWrap:              c.flagSealWrap,
+		DataKey:               c.flagDataKey,
 		ExternalEntropyAccess: c.flagExternalEntropyAccess,
 		Config: api.MountConfigInput{
 			DefaultLeaseTTL: c.flagDefaultLeaseTTL.String(),
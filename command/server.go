@@ -143,6 +143,7 @@ type ServerCommand struct {
 	flagDevTransactional   bool
 	flagDevAutoSeal        bool
 	flagDevClusterJson     string
+	flagDevFixtures        string
 	flagTestVerifyOnly     bool
 	flagTestServerConfig   bool
 	flagDevConsul          bool
@@ -276,6 +277,16 @@ func (c *ServerCommand) Flags() *FlagSets {
 			"The token will only be displayed in the command output.",
 	})
 
+	f.StringVar(&StringVar{
+		Name:       "dev-fixtures",
+		Target:     &c.flagDevFixtures,
+		Default:    "",
+		Completion: complete.PredictFiles("*"),
+		Usage: "Path to a YAML or JSON file declaring the policies, auth methods, " +
+			"secrets engines, entities and secrets to provision when the \"dev\" " +
+			"server starts. This implies -dev.",
+	})
+
 	// Internal-only flags to follow.
 	//
 	// Why hello there little source code reader! Welcome to the Vault source
@@ -1017,10 +1028,15 @@ func (c *ServerCommand) Run(args []string) int {
 	}
 
 	// Automatically enable dev mode if other dev flags are provided.
-	if c.flagDevConsul || c.flagDevHA || c.flagDevTransactional || c.flagDevLeasedKV || c.flagDevThreeNode || c.flagDevFourCluster || c.flagDevAutoSeal || c.flagDevKVV1 || c.flagDevTLS {
+	if c.flagDevConsul || c.flagDevHA || c.flagDevTransactional || c.flagDevLeasedKV || c.flagDevThreeNode || c.flagDevFourCluster || c.flagDevAutoSeal || c.flagDevKVV1 || c.flagDevTLS || c.flagDevFixtures != "" {
 		c.flagDev = true
 	}
 
+	if c.flagDevFixtures != "" && (c.flagDevSkipInit || c.flagDevThreeNode || c.flagDevFourCluster) {
+		c.UI.Error("-dev-fixtures cannot be used with -dev-skip-init, -dev-three-node or -dev-four-cluster")
+		return 1
+	}
+
 	// Validation
 	if !c.flagDev {
 		switch {
@@ -2771,6 +2787,8 @@ func createCoreConfig(c *ServerCommand, config *server.Config, backend physical.
 		DetectDeadlocks:                config.DetectDeadlocks,
 		DisableSentinelTrace:           config.DisableSentinelTrace,
 		DisableCache:                   config.DisableCache,
+		DisableCachePrewarm:            config.DisableCachePrewarm,
+		StepDownGracePeriod:            config.StepDownGracePeriod,
 		DisableMlock:                   config.DisableMlock,
 		MaxLeaseTTL:                    config.MaxLeaseTTL,
 		DefaultLeaseTTL:                config.DefaultLeaseTTL,
@@ -2779,6 +2797,17 @@ func createCoreConfig(c *ServerCommand, config *server.Config, backend physical.
 		PluginDirectory:                config.PluginDirectory,
 		PluginFileUid:                  config.PluginFileUid,
 		PluginFilePermissions:          config.PluginFilePermissions,
+		PluginMaxMemory:                config.PluginMaxMemory,
+		PluginMaxRestarts:              config.PluginMaxRestarts,
+		PluginRestartBackoff:           config.PluginRestartBackoff,
+		RollbackWorkers:                config.RollbackWorkers,
+		RollbackMaxBackoff:             config.RollbackMaxBackoff,
+		RollbackBackoffJitter:          config.RollbackBackoffJitter,
+		SecretsImportAllowedEndpoints:  config.SecretsImportAllowedEndpoints,
+		WatchdogPath:                   config.WatchdogPath,
+		WatchdogGoroutineGrowth:        config.WatchdogGoroutineGrowth,
+		WatchdogStateLockHold:          config.WatchdogStateLockHold,
+		WatchdogBarrierLatency:         config.WatchdogBarrierLatency,
 		EnableUI:                       config.EnableUI,
 		EnableRaw:                      config.EnableRawEndpoint,
 		EnableIntrospection:            config.EnableIntrospectionEndpoint,
@@ -2872,6 +2901,16 @@ func initDevCore(c *ServerCommand, coreConfig *vault.CoreConfig, config *server.
 			sort.Strings(plugins)
 		}
 
+		if c.flagDevFixtures != "" {
+			fixtures, err := loadDevFixtures(c.flagDevFixtures)
+			if err != nil {
+				return fmt.Errorf("Error loading dev fixtures: %s", err)
+			}
+			if err := fixtures.apply(core, init.RootToken); err != nil {
+				return fmt.Errorf("Error applying dev fixtures: %s", err)
+			}
+		}
+
 		var qw *quiescenceSink
 		var qwo sync.Once
 		qw = &quiescenceSink{
@@ -61,6 +61,8 @@ type Config struct {
 	CacheSize                int         `hcl:"cache_size"`
 	DisableCache             bool        `hcl:"-"`
 	DisableCacheRaw          interface{} `hcl:"disable_cache"`
+	DisableCachePrewarm      bool        `hcl:"-"`
+	DisableCachePrewarmRaw   interface{} `hcl:"disable_cache_prewarm"`
 	DisablePrintableCheck    bool        `hcl:"-"`
 	DisablePrintableCheckRaw interface{} `hcl:"disable_printable_check"`
 
@@ -72,6 +74,9 @@ type Config struct {
 	DefaultLeaseTTL    time.Duration `hcl:"-"`
 	DefaultLeaseTTLRaw interface{}   `hcl:"default_lease_ttl,alias:DefaultLeaseTTL"`
 
+	StepDownGracePeriod    time.Duration `hcl:"-"`
+	StepDownGracePeriodRaw interface{}   `hcl:"step_down_grace_period"`
+
 	ClusterCipherSuites string `hcl:"cluster_cipher_suites"`
 
 	PluginDirectory string `hcl:"plugin_directory"`
@@ -81,6 +86,30 @@ type Config struct {
 	PluginFilePermissions    int         `hcl:"-"`
 	PluginFilePermissionsRaw interface{} `hcl:"plugin_file_permissions,alias:PluginFilePermissions"`
 
+	PluginMaxMemory    uint64      `hcl:"-"`
+	PluginMaxMemoryRaw interface{} `hcl:"plugin_max_memory"`
+
+	PluginMaxRestarts int `hcl:"plugin_max_restarts"`
+
+	PluginRestartBackoff    time.Duration `hcl:"-"`
+	PluginRestartBackoffRaw interface{}   `hcl:"plugin_restart_backoff"`
+
+	RollbackWorkers int `hcl:"rollback_workers"`
+
+	RollbackMaxBackoff       time.Duration `hcl:"-"`
+	RollbackMaxBackoffRaw    interface{}   `hcl:"rollback_max_backoff"`
+	RollbackBackoffJitter    bool          `hcl:"-"`
+	RollbackBackoffJitterRaw interface{}   `hcl:"rollback_backoff_jitter"`
+
+	SecretsImportAllowedEndpoints []string `hcl:"secrets_import_allowed_endpoints"`
+
+	WatchdogPath              string        `hcl:"watchdog_path"`
+	WatchdogGoroutineGrowth   int           `hcl:"watchdog_goroutine_growth"`
+	WatchdogStateLockHold     time.Duration `hcl:"-"`
+	WatchdogStateLockHoldRaw  interface{}   `hcl:"watchdog_state_lock_hold"`
+	WatchdogBarrierLatency    time.Duration `hcl:"-"`
+	WatchdogBarrierLatencyRaw interface{}   `hcl:"watchdog_barrier_latency"`
+
 	EnableIntrospectionEndpoint    bool        `hcl:"-"`
 	EnableIntrospectionEndpointRaw interface{} `hcl:"introspection_endpoint,alias:EnableIntrospectionEndpoint"`
 
@@ -307,6 +336,11 @@ func (c *Config) Merge(c2 *Config) *Config {
 		result.DisableCache = c2.DisableCache
 	}
 
+	result.DisableCachePrewarm = c.DisableCachePrewarm
+	if c2.DisableCachePrewarm {
+		result.DisableCachePrewarm = c2.DisableCachePrewarm
+	}
+
 	result.DisableSentinelTrace = c.DisableSentinelTrace
 	if c2.DisableSentinelTrace {
 		result.DisableSentinelTrace = c2.DisableSentinelTrace
@@ -328,6 +362,11 @@ func (c *Config) Merge(c2 *Config) *Config {
 		result.DefaultLeaseTTL = c2.DefaultLeaseTTL
 	}
 
+	result.StepDownGracePeriod = c.StepDownGracePeriod
+	if c2.StepDownGracePeriod > result.StepDownGracePeriod {
+		result.StepDownGracePeriod = c2.StepDownGracePeriod
+	}
+
 	result.ClusterCipherSuites = c.ClusterCipherSuites
 	if c2.ClusterCipherSuites != "" {
 		result.ClusterCipherSuites = c2.ClusterCipherSuites
@@ -382,6 +421,71 @@ func (c *Config) Merge(c2 *Config) *Config {
 		result.PluginFilePermissionsRaw = c2.PluginFilePermissionsRaw
 	}
 
+	result.PluginMaxMemory = c.PluginMaxMemory
+	if c2.PluginMaxMemoryRaw != nil {
+		result.PluginMaxMemory = c2.PluginMaxMemory
+		result.PluginMaxMemoryRaw = c2.PluginMaxMemoryRaw
+	}
+
+	result.PluginMaxRestarts = c.PluginMaxRestarts
+	if c2.PluginMaxRestarts != 0 {
+		result.PluginMaxRestarts = c2.PluginMaxRestarts
+	}
+
+	result.PluginRestartBackoff = c.PluginRestartBackoff
+	if c2.PluginRestartBackoffRaw != nil {
+		result.PluginRestartBackoff = c2.PluginRestartBackoff
+		result.PluginRestartBackoffRaw = c2.PluginRestartBackoffRaw
+	}
+
+	result.RollbackWorkers = c.RollbackWorkers
+	if c2.RollbackWorkers != 0 {
+		result.RollbackWorkers = c2.RollbackWorkers
+	}
+
+	result.RollbackMaxBackoff = c.RollbackMaxBackoff
+	result.RollbackMaxBackoffRaw = c.RollbackMaxBackoffRaw
+	if c2.RollbackMaxBackoffRaw != nil {
+		result.RollbackMaxBackoff = c2.RollbackMaxBackoff
+		result.RollbackMaxBackoffRaw = c2.RollbackMaxBackoffRaw
+	}
+
+	result.RollbackBackoffJitter = c.RollbackBackoffJitter
+	result.RollbackBackoffJitterRaw = c.RollbackBackoffJitterRaw
+	if c2.RollbackBackoffJitterRaw != nil {
+		result.RollbackBackoffJitter = c2.RollbackBackoffJitter
+		result.RollbackBackoffJitterRaw = c2.RollbackBackoffJitterRaw
+	}
+
+	result.SecretsImportAllowedEndpoints = c.SecretsImportAllowedEndpoints
+	if len(c2.SecretsImportAllowedEndpoints) > 0 {
+		result.SecretsImportAllowedEndpoints = c2.SecretsImportAllowedEndpoints
+	}
+
+	result.WatchdogPath = c.WatchdogPath
+	if c2.WatchdogPath != "" {
+		result.WatchdogPath = c2.WatchdogPath
+	}
+
+	result.WatchdogGoroutineGrowth = c.WatchdogGoroutineGrowth
+	if c2.WatchdogGoroutineGrowth != 0 {
+		result.WatchdogGoroutineGrowth = c2.WatchdogGoroutineGrowth
+	}
+
+	result.WatchdogStateLockHold = c.WatchdogStateLockHold
+	result.WatchdogStateLockHoldRaw = c.WatchdogStateLockHoldRaw
+	if c2.WatchdogStateLockHoldRaw != nil {
+		result.WatchdogStateLockHold = c2.WatchdogStateLockHold
+		result.WatchdogStateLockHoldRaw = c2.WatchdogStateLockHoldRaw
+	}
+
+	result.WatchdogBarrierLatency = c.WatchdogBarrierLatency
+	result.WatchdogBarrierLatencyRaw = c.WatchdogBarrierLatencyRaw
+	if c2.WatchdogBarrierLatencyRaw != nil {
+		result.WatchdogBarrierLatency = c2.WatchdogBarrierLatency
+		result.WatchdogBarrierLatencyRaw = c2.WatchdogBarrierLatencyRaw
+	}
+
 	result.DisablePerformanceStandby = c.DisablePerformanceStandby
 	if c2.DisablePerformanceStandby {
 		result.DisablePerformanceStandby = c2.DisablePerformanceStandby
@@ -601,6 +705,11 @@ func ParseConfig(d, source string) (*Config, error) {
 			return nil, err
 		}
 	}
+	if result.StepDownGracePeriodRaw != nil {
+		if result.StepDownGracePeriod, err = parseutil.ParseDurationSecond(result.StepDownGracePeriodRaw); err != nil {
+			return nil, err
+		}
+	}
 
 	if result.EnableUIRaw != nil {
 		if result.EnableUI, err = parseutil.ParseBool(result.EnableUIRaw); err != nil {
@@ -614,6 +723,12 @@ func ParseConfig(d, source string) (*Config, error) {
 		}
 	}
 
+	if result.DisableCachePrewarmRaw != nil {
+		if result.DisableCachePrewarm, err = parseutil.ParseBool(result.DisableCachePrewarmRaw); err != nil {
+			return nil, err
+		}
+	}
+
 	if result.DisablePrintableCheckRaw != nil {
 		if result.DisablePrintableCheck, err = parseutil.ParseBool(result.DisablePrintableCheckRaw); err != nil {
 			return nil, err
@@ -653,6 +768,63 @@ func ParseConfig(d, source string) (*Config, error) {
 		result.PluginFilePermissions = int(pluginFilePermissions)
 	}
 
+	if result.PluginMaxMemoryRaw != nil {
+		if result.PluginMaxMemory, err = parseutil.ParseCapacityString(result.PluginMaxMemoryRaw); err != nil {
+			return nil, fmt.Errorf("error parsing plugin_max_memory: %w", err)
+		}
+	}
+
+	if result.PluginMaxRestarts < 0 {
+		return nil, fmt.Errorf("plugin_max_restarts must not be negative")
+	}
+
+	if result.PluginRestartBackoffRaw != nil {
+		if result.PluginRestartBackoff, err = parseutil.ParseDurationSecond(result.PluginRestartBackoffRaw); err != nil {
+			return nil, fmt.Errorf("error parsing plugin_restart_backoff: %w", err)
+		}
+	}
+
+	if result.RollbackWorkers < 0 {
+		return nil, fmt.Errorf("rollback_workers must not be negative")
+	}
+
+	if result.RollbackMaxBackoffRaw != nil {
+		if result.RollbackMaxBackoff, err = parseutil.ParseDurationSecond(result.RollbackMaxBackoffRaw); err != nil {
+			return nil, fmt.Errorf("error parsing rollback_max_backoff: %w", err)
+		}
+		if result.RollbackMaxBackoff < 0 {
+			return nil, fmt.Errorf("rollback_max_backoff must not be negative")
+		}
+	}
+
+	if result.RollbackBackoffJitterRaw != nil {
+		if result.RollbackBackoffJitter, err = parseutil.ParseBool(result.RollbackBackoffJitterRaw); err != nil {
+			return nil, fmt.Errorf("error parsing rollback_backoff_jitter: %w", err)
+		}
+	}
+
+	if result.WatchdogGoroutineGrowth < 0 {
+		return nil, fmt.Errorf("watchdog_goroutine_growth must not be negative")
+	}
+
+	if result.WatchdogStateLockHoldRaw != nil {
+		if result.WatchdogStateLockHold, err = parseutil.ParseDurationSecond(result.WatchdogStateLockHoldRaw); err != nil {
+			return nil, fmt.Errorf("error parsing watchdog_state_lock_hold: %w", err)
+		}
+		if result.WatchdogStateLockHold < 0 {
+			return nil, fmt.Errorf("watchdog_state_lock_hold must not be negative")
+		}
+	}
+
+	if result.WatchdogBarrierLatencyRaw != nil {
+		if result.WatchdogBarrierLatency, err = parseutil.ParseDurationSecond(result.WatchdogBarrierLatencyRaw); err != nil {
+			return nil, fmt.Errorf("error parsing watchdog_barrier_latency: %w", err)
+		}
+		if result.WatchdogBarrierLatency < 0 {
+			return nil, fmt.Errorf("watchdog_barrier_latency must not be negative")
+		}
+	}
+
 	if result.DisableSentinelTraceRaw != nil {
 		if result.DisableSentinelTrace, err = parseutil.ParseBool(result.DisableSentinelTraceRaw); err != nil {
 			return nil, err
@@ -1103,6 +1275,7 @@ func (c *Config) Sanitized() map[string]interface{} {
 		"cache_size":              c.CacheSize,
 		"disable_sentinel_trace":  c.DisableSentinelTrace,
 		"disable_cache":           c.DisableCache,
+		"disable_cache_prewarm":   c.DisableCachePrewarm,
 		"disable_printable_check": c.DisablePrintableCheck,
 
 		"enable_ui": c.EnableUI,
@@ -1110,6 +1283,8 @@ func (c *Config) Sanitized() map[string]interface{} {
 		"max_lease_ttl":     c.MaxLeaseTTL / time.Second,
 		"default_lease_ttl": c.DefaultLeaseTTL / time.Second,
 
+		"step_down_grace_period": c.StepDownGracePeriod / time.Second,
+
 		"cluster_cipher_suites": c.ClusterCipherSuites,
 
 		"plugin_directory": c.PluginDirectory,
@@ -1118,6 +1293,19 @@ func (c *Config) Sanitized() map[string]interface{} {
 
 		"plugin_file_permissions": c.PluginFilePermissions,
 
+		"plugin_max_memory":      c.PluginMaxMemory,
+		"plugin_max_restarts":    c.PluginMaxRestarts,
+		"plugin_restart_backoff": c.PluginRestartBackoff / time.Second,
+
+		"rollback_workers":        c.RollbackWorkers,
+		"rollback_max_backoff":    c.RollbackMaxBackoff / time.Second,
+		"rollback_backoff_jitter": c.RollbackBackoffJitter,
+
+		"watchdog_path":             c.WatchdogPath,
+		"watchdog_goroutine_growth": c.WatchdogGoroutineGrowth,
+		"watchdog_state_lock_hold":  c.WatchdogStateLockHold / time.Second,
+		"watchdog_barrier_latency":  c.WatchdogBarrierLatency / time.Second,
+
 		"raw_storage_endpoint": c.EnableRawEndpoint,
 
 		"introspection_endpoint": c.EnableIntrospectionEndpoint,
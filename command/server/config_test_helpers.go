@@ -763,6 +763,7 @@ func testConfig_Sanitized(t *testing.T) {
 		"default_lease_ttl":                   (365 * 24 * time.Hour) / time.Second,
 		"default_max_request_duration":        0 * time.Second,
 		"disable_cache":                       true,
+		"disable_cache_prewarm":               false,
 		"disable_clustering":                  false,
 		"disable_indexing":                    false,
 		"disable_mlock":                       true,
@@ -770,6 +771,16 @@ func testConfig_Sanitized(t *testing.T) {
 		"experiments":                         []string(nil),
 		"plugin_file_uid":                     0,
 		"plugin_file_permissions":             0,
+		"plugin_max_memory":                   uint64(0),
+		"plugin_max_restarts":                 0,
+		"plugin_restart_backoff":              0 * time.Second,
+		"rollback_workers":                    0,
+		"rollback_max_backoff":                0 * time.Second,
+		"rollback_backoff_jitter":             false,
+		"watchdog_path":                       "",
+		"watchdog_goroutine_growth":           0,
+		"watchdog_state_lock_hold":            0 * time.Second,
+		"watchdog_barrier_latency":            0 * time.Second,
 		"disable_printable_check":             false,
 		"disable_sealwrap":                    true,
 		"raw_storage_endpoint":                true,
@@ -806,6 +817,7 @@ func testConfig_Sanitized(t *testing.T) {
 				"name":     "awskms",
 			},
 		},
+		"step_down_grace_period": 0 * time.Second,
 		"storage": map[string]interface{}{
 			"cluster_addr":       "top_level_cluster_addr",
 			"disable_clustering": false,
@@ -0,0 +1,240 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/vault"
+)
+
+// devFixtures is the declarative content provisioned by the -dev-fixtures
+// flag when a dev server starts.
+type devFixtures struct {
+	// Policies maps the names of ACL policies to their HCL.
+	Policies map[string]string `json:"policies"`
+
+	// Auth maps the paths of auth methods to enable to their configuration.
+	Auth map[string]*devFixtureMount `json:"auth"`
+
+	// Mounts maps the paths of secrets engines to enable to their
+	// configuration.
+	Mounts map[string]*devFixtureMount `json:"mounts"`
+
+	Entities []*devFixtureEntity `json:"entities"`
+
+	// Secrets are written in order once everything else is provisioned.
+	Secrets []*devFixtureSecret `json:"secrets"`
+}
+
+type devFixtureMount struct {
+	Type        string                 `json:"type"`
+	Description string                 `json:"description"`
+	Config      map[string]interface{} `json:"config"`
+	Options     map[string]string      `json:"options"`
+}
+
+type devFixtureEntity struct {
+	Name     string                   `json:"name"`
+	Policies []string                 `json:"policies"`
+	Metadata map[string]string        `json:"metadata"`
+	Disabled bool                     `json:"disabled"`
+	Aliases  []*devFixtureEntityAlias `json:"aliases"`
+}
+
+type devFixtureEntityAlias struct {
+	Name string `json:"name"`
+
+	// Mount is the path of the auth method of the alias, such as "userpass".
+	Mount string `json:"mount"`
+}
+
+type devFixtureSecret struct {
+	// Path is the API path to write to, such as "secret/data/app" for a K/V
+	// version 2 secrets engine.
+	Path string                 `json:"path"`
+	Data map[string]interface{} `json:"data"`
+}
+
+// loadDevFixtures reads the fixtures at path, in YAML or JSON.
+func loadDevFixtures(path string) (*devFixtures, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var fixtures devFixtures
+	if err := yaml.Unmarshal(raw, &fixtures); err != nil {
+		return nil, fmt.Errorf("error parsing %q: %w", path, err)
+	}
+
+	for _, entity := range fixtures.Entities {
+		if entity.Name == "" {
+			return nil, fmt.Errorf("error parsing %q: entities must have a name", path)
+		}
+		for _, alias := range entity.Aliases {
+			if alias.Name == "" || alias.Mount == "" {
+				return nil, fmt.Errorf("error parsing %q: the aliases of entity %q must have a name and a mount", path, entity.Name)
+			}
+		}
+	}
+	for _, secret := range fixtures.Secrets {
+		if secret.Path == "" {
+			return nil, fmt.Errorf("error parsing %q: secrets must have a path", path)
+		}
+	}
+
+	return &fixtures, nil
+}
+
+// apply provisions the fixtures in the root namespace of core with the root
+// token. Maps are applied in the order of their keys, so that a given fixture
+// file always results in the same server state.
+func (f *devFixtures) apply(core *vault.Core, rootToken string) error {
+	ctx := namespace.ContextWithNamespace(context.Background(), namespace.RootNamespace)
+
+	write := func(path string, data map[string]interface{}) (*logical.Response, error) {
+		resp, err := core.HandleRequest(ctx, &logical.Request{
+			Operation:   logical.UpdateOperation,
+			ClientToken: rootToken,
+			Path:        path,
+			Data:        data,
+		})
+		if err == nil && resp.IsError() {
+			err = resp.Error()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error writing to %q: %w", path, err)
+		}
+		return resp, nil
+	}
+
+	for _, name := range sortedKeys(f.Policies) {
+		if _, err := write("sys/policies/acl/"+name, map[string]interface{}{
+			"policy": f.Policies[name],
+		}); err != nil {
+			return err
+		}
+	}
+
+	for _, path := range sortedKeys(f.Auth) {
+		if _, err := write("sys/auth/"+strings.Trim(path, "/"), f.Auth[path].requestData()); err != nil {
+			return err
+		}
+	}
+
+	for _, path := range sortedKeys(f.Mounts) {
+		if _, err := write("sys/mounts/"+strings.Trim(path, "/"), f.Mounts[path].requestData()); err != nil {
+			return err
+		}
+	}
+
+	if len(f.Entities) == 0 && len(f.Secrets) == 0 {
+		return nil
+	}
+
+	// Aliases reference auth methods by accessor
+	var accessors map[string]string
+	for _, entity := range f.Entities {
+		resp, err := write("identity/entity", map[string]interface{}{
+			"name":     entity.Name,
+			"policies": entity.Policies,
+			"metadata": entity.Metadata,
+			"disabled": entity.Disabled,
+		})
+		if err != nil {
+			return err
+		}
+		if len(entity.Aliases) == 0 {
+			continue
+		}
+		if resp == nil || resp.Data["id"] == nil {
+			return fmt.Errorf("error creating entity %q: no ID returned", entity.Name)
+		}
+
+		if accessors == nil {
+			accessors, err = authAccessors(ctx, core, rootToken)
+			if err != nil {
+				return err
+			}
+		}
+		for _, alias := range entity.Aliases {
+			accessor, ok := accessors[strings.Trim(alias.Mount, "/")+"/"]
+			if !ok {
+				return fmt.Errorf("error creating alias %q of entity %q: no auth method is enabled at %q", alias.Name, entity.Name, alias.Mount)
+			}
+			if _, err := write("identity/entity-alias", map[string]interface{}{
+				"name":           alias.Name,
+				"canonical_id":   resp.Data["id"],
+				"mount_accessor": accessor,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, secret := range f.Secrets {
+		if _, err := write(secret.Path, secret.Data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *devFixtureMount) requestData() map[string]interface{} {
+	data := map[string]interface{}{
+		"type":        m.Type,
+		"description": m.Description,
+	}
+	if m.Config != nil {
+		data["config"] = m.Config
+	}
+	if m.Options != nil {
+		data["options"] = m.Options
+	}
+	return data
+}
+
+// authAccessors returns the accessors of the auth methods of the root
+// namespace, by path.
+func authAccessors(ctx context.Context, core *vault.Core, rootToken string) (map[string]string, error) {
+	resp, err := core.HandleRequest(ctx, &logical.Request{
+		Operation:   logical.ReadOperation,
+		ClientToken: rootToken,
+		Path:        "sys/auth",
+	})
+	if err == nil && resp.IsError() {
+		err = resp.Error()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error listing auth methods: %w", err)
+	}
+
+	accessors := make(map[string]string)
+	for path, raw := range resp.Data {
+		if mount, ok := raw.(map[string]interface{}); ok {
+			if accessor, ok := mount["accessor"].(string); ok {
+				accessors[path] = accessor
+			}
+		}
+	}
+	return accessors, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
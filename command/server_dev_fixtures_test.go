@@ -0,0 +1,90 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	credUserpass "github.com/hashicorp/vault/builtin/credential/userpass"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/vault"
+	"github.com/stretchr/testify/require"
+)
+
+const testDevFixtures = `
+policies:
+  app: |
+    path "apps/*" {
+      capabilities = ["read"]
+    }
+auth:
+  userpass:
+    type: userpass
+    description: test users
+mounts:
+  apps/:
+    type: kv
+entities:
+  - name: alice
+    policies: [app]
+    metadata:
+      team: payments
+    aliases:
+      - name: alice
+        mount: userpass
+secrets:
+  - path: auth/userpass/users/alice
+    data:
+      password: foo
+  - path: apps/payments
+    data:
+      api_key: bar
+`
+
+// TestDevFixtures verifies that the fixtures of a dev server are provisioned,
+// so that a user declared in them can log in and read the declared secrets.
+func TestDevFixtures(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixtures.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testDevFixtures), 0o600))
+
+	fixtures, err := loadDevFixtures(path)
+	require.NoError(t, err)
+
+	core, _, root := vault.TestCoreUnsealedWithConfig(t, &vault.CoreConfig{
+		CredentialBackends: map[string]logical.Factory{
+			"userpass": credUserpass.Factory,
+		},
+	})
+	require.NoError(t, fixtures.apply(core, root))
+
+	ctx := namespace.RootContext(nil)
+	resp, err := core.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "auth/userpass/login/alice",
+		Data: map[string]interface{}{
+			"password": "foo",
+		},
+		Connection: &logical.Connection{},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp.Auth)
+	require.Contains(t, resp.Auth.IdentityPolicies, "app")
+
+	resp, err = core.HandleRequest(ctx, &logical.Request{
+		Operation:   logical.ReadOperation,
+		ClientToken: resp.Auth.ClientToken,
+		Path:        "apps/payments",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "bar", resp.Data["api_key"])
+
+	// Aliases must reference an enabled auth method
+	require.NoError(t, os.WriteFile(path, []byte(`{"entities": [{"name": "bob", "aliases": [{"name": "bob", "mount": "ldap"}]}]}`), 0o600))
+	fixtures, err = loadDevFixtures(path)
+	require.NoError(t, err)
+	require.ErrorContains(t, fixtures.apply(core, root), `no auth method is enabled at "ldap"`)
+}
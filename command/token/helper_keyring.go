@@ -0,0 +1,189 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package token
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/99designs/keyring"
+)
+
+const (
+	// keyringServiceName is the service the token is stored under in the
+	// credential stores of the operating system.
+	keyringServiceName = "vault"
+
+	// keyringTokenKey is the key of the stored token.
+	keyringTokenKey = "token"
+)
+
+// keyringBackends maps the names of the supported credential stores to their
+// keyring backends.
+var keyringBackends = map[string]keyring.BackendType{
+	"keychain":       keyring.KeychainBackend,
+	"wincred":        keyring.WinCredBackend,
+	"secret-service": keyring.SecretServiceBackend,
+}
+
+// KeyringTokenStores returns the names of the credential stores of the
+// operating system a KeyringTokenHelper can store the token in.
+func KeyringTokenStores() []string {
+	stores := make([]string, 0, len(keyringBackends))
+	for store := range keyringBackends {
+		stores = append(stores, store)
+	}
+	sort.Strings(stores)
+	return stores
+}
+
+// AvailableKeyringTokenStores returns the names of the credential stores
+// supported by this build of the binary. Each store is only built for its
+// operating system, and the macOS Keychain store also requires cgo, so it is
+// missing from binaries built with CGO_ENABLED=0, such as the ones built by
+// the Makefile.
+func AvailableKeyringTokenStores() []string {
+	var stores []string
+	for _, store := range KeyringTokenStores() {
+		for _, backend := range keyring.AvailableBackends() {
+			if keyringBackends[store] == backend {
+				stores = append(stores, store)
+				break
+			}
+		}
+	}
+	return stores
+}
+
+// CheckKeyringTokenStore returns an error if the credential store with the
+// given name is unknown, or isn't supported by this build of the binary.
+func CheckKeyringTokenStore(store string) error {
+	if _, ok := keyringBackends[store]; !ok {
+		return fmt.Errorf("unknown token store %q, must be one of %s", store, strings.Join(KeyringTokenStores(), ", "))
+	}
+	for _, available := range AvailableKeyringTokenStores() {
+		if store == available {
+			return nil
+		}
+	}
+	if store == "keychain" && runtime.GOOS == "darwin" {
+		return fmt.Errorf("token store %q is not supported by this build of vault, which was built without cgo", store)
+	}
+	return fmt.Errorf("token store %q is not supported on %s", store, runtime.GOOS)
+}
+
+var _ TokenHelper = (*KeyringTokenHelper)(nil)
+
+// KeyringTokenHelper fulfills the TokenHelper interface by storing the token
+// in a credential store of the operating system rather than in plaintext on
+// disk: the macOS Keychain ("keychain"), the Windows Credential Manager
+// ("wincred"), or a Secret Service provider such as GNOME Keyring on Linux
+// ("secret-service").
+type KeyringTokenHelper struct {
+	store string
+
+	// open opens the credential store, which is only done when the token is
+	// first accessed, as the stores may prompt users or be unavailable.
+	open func() (keyring.Keyring, error)
+	ring keyring.Keyring
+}
+
+// NewKeyringTokenHelper returns a token helper storing the token in the
+// credential store of the operating system with the given name.
+func NewKeyringTokenHelper(store string) (*KeyringTokenHelper, error) {
+	if err := CheckKeyringTokenStore(store); err != nil {
+		return nil, err
+	}
+	backend := keyringBackends[store]
+
+	return &KeyringTokenHelper{
+		store: store,
+		open: func() (keyring.Keyring, error) {
+			return keyring.Open(keyring.Config{
+				ServiceName:     keyringServiceName,
+				AllowedBackends: []keyring.BackendType{backend},
+
+				// Allow the vault binary to read the token without
+				// prompting, in the login keychain
+				KeychainTrustApplication: true,
+
+				// Store the token in the default collection, which is
+				// unlocked on login
+				LibSecretCollectionName: "login",
+			})
+		},
+	}, nil
+}
+
+func (k *KeyringTokenHelper) keyring() (keyring.Keyring, error) {
+	if k.ring != nil {
+		return k.ring, nil
+	}
+
+	ring, err := k.open()
+	if err != nil {
+		if errors.Is(err, keyring.ErrNoAvailImpl) {
+			return nil, fmt.Errorf("token store %q is not available on this system", k.store)
+		}
+		return nil, fmt.Errorf("error opening token store %q: %w", k.store, err)
+	}
+	k.ring = ring
+	return k.ring, nil
+}
+
+// Path returns the location of the token in the credential store.
+func (k *KeyringTokenHelper) Path() string {
+	return fmt.Sprintf("%s:%s/%s", k.store, keyringServiceName, keyringTokenKey)
+}
+
+// Get gets the value of the stored token, if any
+func (k *KeyringTokenHelper) Get() (string, error) {
+	ring, err := k.keyring()
+	if err != nil {
+		return "", err
+	}
+
+	item, err := ring.Get(keyringTokenKey)
+	if errors.Is(err, keyring.ErrKeyNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(item.Data)), nil
+}
+
+// Store stores the value of the token in the credential store, replacing any
+// existing token.
+func (k *KeyringTokenHelper) Store(input string) error {
+	ring, err := k.keyring()
+	if err != nil {
+		return err
+	}
+
+	return ring.Set(keyring.Item{
+		Key:         keyringTokenKey,
+		Data:        []byte(input),
+		Label:       "Vault token",
+		Description: "Token of the Vault CLI",
+	})
+}
+
+// Erase erases the value of the token
+func (k *KeyringTokenHelper) Erase() error {
+	ring, err := k.keyring()
+	if err != nil {
+		return err
+	}
+
+	if err := ring.Remove(keyringTokenKey); err != nil && !errors.Is(err, keyring.ErrKeyNotFound) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package token
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/99designs/keyring"
+)
+
+// TestKeyringTokenHelper re-uses the existing Test function to ensure proper
+// behavior of the keyring token helper, against an in-memory keyring
+func TestKeyringTokenHelper(t *testing.T) {
+	ring := keyring.NewArrayKeyring(nil)
+	helper := &KeyringTokenHelper{
+		store: "keychain",
+		open: func() (keyring.Keyring, error) {
+			return ring, nil
+		},
+	}
+	Test(t, helper)
+
+	// Erasing a missing token isn't an error
+	if err := helper.Erase(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewKeyringTokenHelper("plaintext"); err == nil {
+		t.Fatal("expected an error for an unknown token store")
+	}
+}
+
+// TestAvailableKeyringTokenStores ensures that the credential stores reported
+// as available are the ones built into the binary, and that the others are
+// rejected.
+func TestAvailableKeyringTokenStores(t *testing.T) {
+	expected := map[string]bool{
+		"keychain":       runtime.GOOS == "darwin" && cgoEnabled,
+		"wincred":        runtime.GOOS == "windows",
+		"secret-service": runtime.GOOS == "linux",
+	}
+
+	var available []string
+	for _, store := range KeyringTokenStores() {
+		if expected[store] {
+			available = append(available, store)
+		}
+
+		_, err := NewKeyringTokenHelper(store)
+		switch {
+		case expected[store] && err != nil:
+			t.Errorf("expected token store %q to be available: %s", store, err)
+		case !expected[store] && err == nil:
+			t.Errorf("expected token store %q to be unavailable", store)
+		}
+	}
+	if !reflect.DeepEqual(available, AvailableKeyringTokenStores()) {
+		t.Fatalf("expected the available token stores to be %v, got %v", available, AvailableKeyringTokenStores())
+	}
+
+	if runtime.GOOS == "darwin" && !cgoEnabled {
+		err := CheckKeyringTokenStore("keychain")
+		if err == nil || !strings.Contains(err.Error(), "built without cgo") {
+			t.Fatalf("bad error: %v", err)
+		}
+	}
+}
@@ -39,6 +39,7 @@ import (
 	credOkta "github.com/hashicorp/vault/builtin/credential/okta"
 	credRadius "github.com/hashicorp/vault/builtin/credential/radius"
 	credUserpass "github.com/hashicorp/vault/builtin/credential/userpass"
+	logicalAccounts "github.com/hashicorp/vault/builtin/logical/accounts"
 	logicalAws "github.com/hashicorp/vault/builtin/logical/aws"
 	logicalConsul "github.com/hashicorp/vault/builtin/logical/consul"
 	logicalNomad "github.com/hashicorp/vault/builtin/logical/nomad"
@@ -149,6 +150,7 @@ func newRegistry() *registry {
 			"snowflake-database-plugin":         {Factory: dbSnowflake.New},
 		},
 		logicalBackends: map[string]logicalBackend{
+			"accounts": {Factory: logicalAccounts.Factory},
 			"ad": {
 				Factory:           logicalAd.Factory,
 				DeprecationStatus: consts.Deprecated,
@@ -35,10 +35,17 @@ type HostInfo struct {
 	// Memory contains statistics about the memory such as total, available, and
 	// used memory in number of bytes.
 	Memory *VirtualMemoryStat `json:"memory"`
+	// Runtime contains information about the Go runtime, such as the memory
+	// limit and garbage collection statistics.
+	Runtime *RuntimeStat `json:"runtime"`
+	// Process contains the open file descriptor count and resource limits of
+	// the Vault process.
+	Process *ProcessStat `json:"process"`
 }
 
 // CollectHostInfo returns information on the host, which includes general
-// host status, CPU, memory, and disk utilization.
+// host status, CPU, memory, and disk utilization, along with the Go runtime
+// and resource limits of the Vault process.
 //
 // The function does a best-effort capture on the most information possible,
 // continuing on capture errors encountered and appending them to a resulting
@@ -89,6 +96,14 @@ func CollectHostInfo(ctx context.Context) (*HostInfo, error) {
 		info.CPUTimes = t
 	}
 
+	info.Runtime = CollectRuntimeStat()
+
+	p, err := CollectProcessStat(ctx)
+	if err != nil {
+		retErr = multierror.Append(retErr, &HostInfoError{"process", err})
+	}
+	info.Process = p
+
 	return info, retErr.ErrorOrNil()
 }
 
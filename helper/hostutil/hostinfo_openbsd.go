@@ -18,6 +18,8 @@ type HostInfo struct {
 	Disk      []interface{} `json:"disk"`
 	Host      interface{}   `json:"host"`
 	Memory    interface{}   `json:"memory"`
+	Runtime   *RuntimeStat  `json:"runtime"`
+	Process   interface{}   `json:"process"`
 }
 
 func CollectHostInfo(ctx context.Context) (*HostInfo, error) {
@@ -27,3 +29,16 @@ func CollectHostInfo(ctx context.Context) (*HostInfo, error) {
 func CollectHostMemory(ctx context.Context) (*VirtualMemoryStat, error) {
 	return nil, fmt.Errorf("host info not supported on this platform")
 }
+
+type ProcessUsage struct {
+	PID              int32   `json:"pid"`
+	CPUUserSeconds   float64 `json:"cpu_user_seconds"`
+	CPUSystemSeconds float64 `json:"cpu_system_seconds"`
+	MemoryRSS        uint64  `json:"memory_rss"`
+	MemoryVMS        uint64  `json:"memory_vms"`
+	NumThreads       int32   `json:"num_threads"`
+}
+
+func CollectProcessUsage(ctx context.Context, pid int32) (*ProcessUsage, error) {
+	return nil, fmt.Errorf("process usage not supported on this platform")
+}
@@ -40,6 +40,15 @@ func TestCollectHostInfo(t *testing.T) {
 	if !checkErrTypeExists(errs, "memory") && info.Memory == nil {
 		t.Fatal("expected non-nil Memory value")
 	}
+	if info.Runtime == nil || info.Runtime.Memory == nil {
+		t.Fatal("expected non-nil Runtime value")
+	}
+	if info.Runtime.MemoryLimit <= 0 {
+		t.Fatalf("expected a positive memory limit, got %d", info.Runtime.MemoryLimit)
+	}
+	if !checkErrTypeExists(errs, "process") && info.Process == nil {
+		t.Fatal("expected non-nil Process value")
+	}
 }
 
 // checkErrTypeExists is a helper that checks whether an particular
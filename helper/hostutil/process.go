@@ -0,0 +1,138 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !openbsd
+
+package hostutil
+
+import (
+	"context"
+	"os"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// rlimitNames are the resource limits reported in ProcessStat, keyed by
+// gopsutil resource.
+var rlimitNames = map[int32]string{
+	process.RLIMIT_AS:      "address_space",
+	process.RLIMIT_CORE:    "core",
+	process.RLIMIT_CPU:     "cpu",
+	process.RLIMIT_DATA:    "data",
+	process.RLIMIT_FSIZE:   "file_size",
+	process.RLIMIT_MEMLOCK: "locked_memory",
+	process.RLIMIT_NOFILE:  "open_files",
+	process.RLIMIT_NPROC:   "processes",
+	process.RLIMIT_RSS:     "resident_set",
+	process.RLIMIT_STACK:   "stack",
+}
+
+// ProcessStat holds information about the resource usage and limits of the
+// Vault process.
+type ProcessStat struct {
+	PID int32 `json:"pid"`
+	// OpenFDs is the number of file descriptors opened by the process.
+	OpenFDs int32 `json:"open_fds"`
+	// Rlimits contains the resource limits of the process, along with the
+	// current usage where the platform reports it.
+	Rlimits map[string]*RlimitStat `json:"rlimits"`
+}
+
+// RlimitStat is a soft and hard resource limit, along with its usage. Limits
+// equal to math.MaxUint64 are unlimited.
+type RlimitStat struct {
+	Soft uint64 `json:"soft"`
+	Hard uint64 `json:"hard"`
+	Used uint64 `json:"used"`
+}
+
+// CollectProcessStat returns the open file descriptor count and resource
+// limits of the current process. Like CollectHostInfo, it is best-effort: the
+// stat holds what could be collected and the errors are returned as a
+// multierror.Error.
+func CollectProcessStat(ctx context.Context) (*ProcessStat, error) {
+	p, err := process.NewProcessWithContext(ctx, int32(os.Getpid()))
+	if err != nil {
+		return nil, err
+	}
+
+	var retErr *multierror.Error
+	stat := &ProcessStat{PID: p.Pid}
+
+	if n, err := p.NumFDsWithContext(ctx); err != nil {
+		retErr = multierror.Append(retErr, err)
+	} else {
+		stat.OpenFDs = n
+	}
+
+	limits, err := p.RlimitUsageWithContext(ctx, true)
+	if err != nil {
+		retErr = multierror.Append(retErr, err)
+	} else {
+		stat.Rlimits = make(map[string]*RlimitStat, len(rlimitNames))
+		for _, l := range limits {
+			name, ok := rlimitNames[l.Resource]
+			if !ok {
+				continue
+			}
+			stat.Rlimits[name] = &RlimitStat{
+				Soft: l.Soft,
+				Hard: l.Hard,
+				Used: l.Used,
+			}
+		}
+	}
+
+	return stat, retErr.ErrorOrNil()
+}
+
+// ProcessUsage holds the CPU and memory usage of a process.
+type ProcessUsage struct {
+	PID int32 `json:"pid"`
+	// CPUUserSeconds and CPUSystemSeconds are the CPU time spent by the
+	// process in user and system mode since it started.
+	CPUUserSeconds   float64 `json:"cpu_user_seconds"`
+	CPUSystemSeconds float64 `json:"cpu_system_seconds"`
+	// MemoryRSS and MemoryVMS are the resident and virtual memory sizes of the
+	// process, in bytes.
+	MemoryRSS uint64 `json:"memory_rss"`
+	MemoryVMS uint64 `json:"memory_vms"`
+	// NumThreads is the number of threads of the process.
+	NumThreads int32 `json:"num_threads"`
+}
+
+// CollectProcessUsage returns the CPU and memory usage of the process with
+// the given PID, such as a plugin process spawned by Vault. Like
+// CollectProcessStat, it is best-effort.
+func CollectProcessUsage(ctx context.Context, pid int32) (*ProcessUsage, error) {
+	p, err := process.NewProcessWithContext(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+
+	var retErr *multierror.Error
+	usage := &ProcessUsage{PID: pid}
+
+	if times, err := p.TimesWithContext(ctx); err != nil {
+		retErr = multierror.Append(retErr, err)
+	} else {
+		usage.CPUUserSeconds = times.User
+		usage.CPUSystemSeconds = times.System
+	}
+
+	if mem, err := p.MemoryInfoWithContext(ctx); err != nil {
+		retErr = multierror.Append(retErr, err)
+	} else {
+		usage.MemoryRSS = mem.RSS
+		usage.MemoryVMS = mem.VMS
+	}
+
+	if n, err := p.NumThreadsWithContext(ctx); err != nil {
+		retErr = multierror.Append(retErr, err)
+	} else {
+		usage.NumThreads = n
+	}
+
+	return usage, retErr.ErrorOrNil()
+}
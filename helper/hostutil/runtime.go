@@ -0,0 +1,90 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hostutil
+
+import (
+	"os"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// RuntimeStat holds information about the Go runtime of the Vault process.
+type RuntimeStat struct {
+	GoVersion    string `json:"go_version"`
+	NumCPU       int    `json:"num_cpu"`
+	GOMAXPROCS   int    `json:"gomaxprocs"`
+	NumGoroutine int    `json:"num_goroutine"`
+	// GOGC is the value of the GOGC environment variable, or "100" if unset.
+	GOGC string `json:"gogc"`
+	// MemoryLimit is the soft memory limit of the runtime in bytes, as set by
+	// GOMEMLIMIT. It is math.MaxInt64 when no limit is set.
+	MemoryLimit int64 `json:"memory_limit"`
+	// Memory contains heap and garbage collection statistics.
+	Memory *RuntimeMemoryStat `json:"memory"`
+}
+
+// RuntimeMemoryStat holds a subset of runtime.MemStats. Sizes are in bytes
+// and durations in nanoseconds.
+type RuntimeMemoryStat struct {
+	Sys           uint64    `json:"sys"`
+	HeapAlloc     uint64    `json:"heap_alloc"`
+	HeapSys       uint64    `json:"heap_sys"`
+	HeapIdle      uint64    `json:"heap_idle"`
+	HeapInuse     uint64    `json:"heap_inuse"`
+	HeapReleased  uint64    `json:"heap_released"`
+	HeapObjects   uint64    `json:"heap_objects"`
+	StackInuse    uint64    `json:"stack_inuse"`
+	NextGC        uint64    `json:"next_gc"`
+	NumGC         uint32    `json:"num_gc"`
+	NumForcedGC   uint32    `json:"num_forced_gc"`
+	LastGC        time.Time `json:"last_gc"`
+	LastPauseNs   uint64    `json:"last_pause_ns"`
+	PauseTotalNs  uint64    `json:"pause_total_ns"`
+	GCCPUFraction float64   `json:"gc_cpu_fraction"`
+}
+
+// CollectRuntimeStat returns information about the Go runtime, including its
+// memory limit and garbage collection statistics. Reading the memory
+// statistics briefly stops the world.
+func CollectRuntimeStat() *RuntimeStat {
+	gogc := os.Getenv("GOGC")
+	if gogc == "" {
+		gogc = "100"
+	}
+
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	stat := &RuntimeStat{
+		GoVersion:    runtime.Version(),
+		NumCPU:       runtime.NumCPU(),
+		GOMAXPROCS:   runtime.GOMAXPROCS(0),
+		NumGoroutine: runtime.NumGoroutine(),
+		GOGC:         gogc,
+		// A negative limit reads the current limit without changing it.
+		MemoryLimit: debug.SetMemoryLimit(-1),
+		Memory: &RuntimeMemoryStat{
+			Sys:           m.Sys,
+			HeapAlloc:     m.HeapAlloc,
+			HeapSys:       m.HeapSys,
+			HeapIdle:      m.HeapIdle,
+			HeapInuse:     m.HeapInuse,
+			HeapReleased:  m.HeapReleased,
+			HeapObjects:   m.HeapObjects,
+			StackInuse:    m.StackInuse,
+			NextGC:        m.NextGC,
+			NumGC:         m.NumGC,
+			NumForcedGC:   m.NumForcedGC,
+			PauseTotalNs:  m.PauseTotalNs,
+			GCCPUFraction: m.GCCPUFraction,
+		},
+	}
+	if m.NumGC > 0 {
+		stat.Memory.LastGC = time.Unix(0, int64(m.LastGC)).UTC()
+		stat.Memory.LastPauseNs = m.PauseNs[(m.NumGC+255)%256]
+	}
+
+	return stat
+}
@@ -8,6 +8,7 @@ import (
 
 	proto "github.com/golang/protobuf/proto"
 	"github.com/hashicorp/vault/sdk/logical"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func (g *Group) Clone() (*Group, error) {
@@ -116,13 +117,24 @@ func ToSDKEntity(e *Entity) *logical.Entity {
 	}
 
 	return &logical.Entity{
-		ID:          e.ID,
-		Name:        e.Name,
-		Disabled:    e.Disabled,
-		Aliases:     aliases,
-		Metadata:    metadata,
-		NamespaceID: e.NamespaceID,
+		ID:             e.ID,
+		Name:           e.Name,
+		Disabled:       e.Disabled,
+		Aliases:        aliases,
+		Metadata:       metadata,
+		NamespaceID:    e.NamespaceID,
+		CreationTime:   CloneTimestamp(e.CreationTime),
+		LastUpdateTime: CloneTimestamp(e.LastUpdateTime),
+		Policies:       append([]string(nil), e.Policies...),
+	}
+}
+
+// CloneTimestamp returns a copy of the given timestamp, or nil if it is nil.
+func CloneTimestamp(t *timestamppb.Timestamp) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
 	}
+	return &timestamppb.Timestamp{Seconds: t.Seconds, Nanos: t.Nanos}
 }
 
 // ToSDKGroup converts the provided group to an SDK compatible group.
@@ -137,10 +149,13 @@ func ToSDKGroup(g *Group) *logical.Group {
 	}
 
 	return &logical.Group{
-		ID:          g.ID,
-		Name:        g.Name,
-		Metadata:    metadata,
-		NamespaceID: g.NamespaceID,
+		ID:              g.ID,
+		Name:            g.Name,
+		Metadata:        metadata,
+		NamespaceID:     g.NamespaceID,
+		ParentGroupIDs:  append([]string(nil), g.ParentGroupIDs...),
+		MemberEntityIDs: append([]string(nil), g.MemberEntityIDs...),
+		Type:            g.Type,
 	}
 }
 
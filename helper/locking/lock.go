@@ -5,6 +5,8 @@ package locking
 
 import (
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/sasha-s/go-deadlock"
 )
@@ -47,3 +49,33 @@ type SyncMutex struct {
 type SyncRWMutex struct {
 	sync.RWMutex
 }
+
+// TimedRWMutex wraps a RWMutex, recording when its write lock was acquired so
+// that watchdogs can tell how long it has been held.
+type TimedRWMutex struct {
+	RWMutex
+
+	// locked is the time the write lock was acquired, in nanoseconds since
+	// the epoch, or zero when it isn't held.
+	locked atomic.Int64
+}
+
+func (m *TimedRWMutex) Lock() {
+	m.RWMutex.Lock()
+	m.locked.Store(time.Now().UnixNano())
+}
+
+func (m *TimedRWMutex) Unlock() {
+	m.locked.Store(0)
+	m.RWMutex.Unlock()
+}
+
+// HeldFor returns how long the write lock has been held, or zero when it
+// isn't held.
+func (m *TimedRWMutex) HeldFor() time.Duration {
+	locked := m.locked.Load()
+	if locked == 0 {
+		return 0
+	}
+	return time.Since(time.Unix(0, locked))
+}
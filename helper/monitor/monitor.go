@@ -24,6 +24,22 @@ type Monitor interface {
 	Stop()
 }
 
+// Filter selects the log messages streamed by a monitor, from the name of
+// their logger and their level.
+type Filter func(name string, level log.Level) bool
+
+// filterSink forwards the log messages selected by its filter to its sink.
+type filterSink struct {
+	log.SinkAdapter
+	filter Filter
+}
+
+func (f *filterSink) Accept(name string, level log.Level, msg string, args ...interface{}) {
+	if f.filter(name, level) {
+		f.SinkAdapter.Accept(name, level, msg, args...)
+	}
+}
+
 // monitor implements the Monitor interface. Note that this
 // struct is not threadsafe.
 type monitor struct {
@@ -57,10 +73,16 @@ type monitor struct {
 // NewMonitor creates a new Monitor. Start must be called in order to actually start
 // streaming logs. buf is the buffer size of the channel that sends log messages.
 func NewMonitor(buf int, logger log.InterceptLogger, opts *log.LoggerOptions) (Monitor, error) {
-	return newMonitor(buf, logger, opts)
+	return newMonitor(buf, logger, opts, nil)
+}
+
+// NewMonitorWithFilter creates a new Monitor which only streams the log
+// messages selected by the filter.
+func NewMonitorWithFilter(buf int, logger log.InterceptLogger, opts *log.LoggerOptions, filter Filter) (Monitor, error) {
+	return newMonitor(buf, logger, opts, filter)
 }
 
-func newMonitor(buf int, logger log.InterceptLogger, opts *log.LoggerOptions) (*monitor, error) {
+func newMonitor(buf int, logger log.InterceptLogger, opts *log.LoggerOptions, filter Filter) (*monitor, error) {
 	if buf <= 0 {
 		return nil, fmt.Errorf("buf must be greater than zero")
 	}
@@ -78,6 +100,9 @@ func newMonitor(buf int, logger log.InterceptLogger, opts *log.LoggerOptions) (*
 	opts.Output = sw
 	sink := log.NewSinkAdapter(opts)
 	sw.sink = sink
+	if filter != nil {
+		sw.sink = &filterSink{SinkAdapter: sink, filter: filter}
+	}
 
 	return sw, nil
 }
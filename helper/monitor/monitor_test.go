@@ -82,6 +82,36 @@ func TestMonitor_JSONFormat(t *testing.T) {
 	}
 }
 
+func TestMonitor_Filter(t *testing.T) {
+	t.Parallel()
+
+	logger := log.NewInterceptLogger(&log.LoggerOptions{
+		Level: log.Error,
+	})
+
+	m, _ := NewMonitorWithFilter(512, logger, &log.LoggerOptions{
+		Level: log.Debug,
+	}, func(name string, level log.Level) bool {
+		return name == "expiration"
+	})
+
+	logCh := m.Start()
+	defer m.Stop()
+
+	go func() {
+		logger.Named("core").Debug("filtered log")
+		logger.Named("expiration").Debug("streamed log")
+		time.Sleep(10 * time.Millisecond)
+	}()
+
+	select {
+	case l := <-logCh:
+		require.Contains(t, string(l), "expiration: streamed log")
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected to receive from log channel")
+	}
+}
+
 func TestMonitor_Start_Unbuffered(t *testing.T) {
 	t.Parallel()
 
@@ -112,7 +142,7 @@ func TestMonitor_DroppedMessages(t *testing.T) {
 
 	m, _ := newMonitor(5, logger, &log.LoggerOptions{
 		Level: log.Debug,
-	})
+	}, nil)
 	m.dropCheckInterval = 5 * time.Millisecond
 
 	logCh := m.Start()
@@ -0,0 +1,37 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package random
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// EncodingFormats are the output formats supported by EncodeBytes.
+var EncodingFormats = []string{"hex", "base64", "base64url", "base32"}
+
+// EncodeBytes encodes the given bytes in the given format: hex, base64,
+// base64url (URL-safe base64, without padding) or base32.
+func EncodeBytes(b []byte, format string) (string, error) {
+	switch format {
+	case "hex":
+		return hex.EncodeToString(b), nil
+	case "base64":
+		return base64.StdEncoding.EncodeToString(b), nil
+	case "base64url":
+		return base64.RawURLEncoding.EncodeToString(b), nil
+	case "base32":
+		return base32.StdEncoding.EncodeToString(b), nil
+	default:
+		return "", fmt.Errorf("unsupported encoding format %q; must be \"hex\", \"base64\", \"base64url\" or \"base32\"", format)
+	}
+}
+
+// ValidEncodingFormat returns whether EncodeBytes supports the given format.
+func ValidEncodingFormat(format string) bool {
+	_, err := EncodeBytes(nil, format)
+	return err == nil
+}
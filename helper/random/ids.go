@@ -0,0 +1,63 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package random
+
+import (
+	"encoding/binary"
+	"io"
+	"time"
+
+	"github.com/hashicorp/go-uuid"
+)
+
+// crockfordAlphabet is the Crockford base32 alphabet ULIDs are encoded with.
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// timeOrderedID returns 16 bytes whose first 48 bits are the Unix time of
+// now in milliseconds, and the rest read from r.
+func timeOrderedID(r io.Reader, now time.Time) ([]byte, error) {
+	id := make([]byte, 16)
+	if _, err := io.ReadFull(r, id[6:]); err != nil {
+		return nil, err
+	}
+	ms := uint64(now.UnixMilli())
+	for i := 5; i >= 0; i-- {
+		id[i] = byte(ms)
+		ms >>= 8
+	}
+	return id, nil
+}
+
+// NewUUIDv7 returns a version 7 UUID, as defined by RFC 9562, holding the
+// time of now and random bits read from r. UUIDs of different milliseconds
+// sort in the order they were generated.
+func NewUUIDv7(r io.Reader, now time.Time) (string, error) {
+	id, err := timeOrderedID(r, now)
+	if err != nil {
+		return "", err
+	}
+	id[6] = id[6]&0x0f | 0x70
+	id[8] = id[8]&0x3f | 0x80
+	return uuid.FormatUUID(id)
+}
+
+// NewULID returns a ULID holding the time of now and random bits read from r,
+// encoded as 26 characters of Crockford base32. ULIDs of different
+// milliseconds sort in the order they were generated.
+func NewULID(r io.Reader, now time.Time) (string, error) {
+	id, err := timeOrderedID(r, now)
+	if err != nil {
+		return "", err
+	}
+
+	hi := binary.BigEndian.Uint64(id[:8])
+	lo := binary.BigEndian.Uint64(id[8:])
+	out := make([]byte, 26)
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = crockfordAlphabet[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out), nil
+}
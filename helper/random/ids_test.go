@@ -0,0 +1,78 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package random
+
+import (
+	"bytes"
+	"crypto/rand"
+	"regexp"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestNewUUIDv7(t *testing.T) {
+	now := time.UnixMilli(1469918176385)
+	id, err := NewUUIDv7(bytes.NewReader(bytes.Repeat([]byte{0xff}, 10)), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "01563df3-6481-7fff-bfff-ffffffffffff"; id != expected {
+		t.Fatalf("expected %s, got %s", expected, id)
+	}
+
+	pattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	var ids []string
+	for i := 0; i < 10; i++ {
+		id, err := NewUUIDv7(rand.Reader, now.Add(time.Duration(i)*time.Millisecond))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !pattern.MatchString(id) {
+			t.Fatalf("invalid UUIDv7 %s", id)
+		}
+		ids = append(ids, id)
+	}
+	if !sort.StringsAreSorted(ids) {
+		t.Fatalf("expected UUIDs sorted by time, got %v", ids)
+	}
+
+	if _, err := NewUUIDv7(bytes.NewReader(nil), now); err == nil {
+		t.Fatal("expected an error when the reader is exhausted")
+	}
+}
+
+func TestNewULID(t *testing.T) {
+	now := time.UnixMilli(1469918176385)
+	id, err := NewULID(bytes.NewReader(make([]byte, 10)), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "01ARYZ6S410000000000000000"; id != expected {
+		t.Fatalf("expected %s, got %s", expected, id)
+	}
+
+	id, err = NewULID(bytes.NewReader(bytes.Repeat([]byte{0xff}, 10)), time.UnixMilli(1<<48-1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "7ZZZZZZZZZZZZZZZZZZZZZZZZZ"; id != expected {
+		t.Fatalf("expected %s, got %s", expected, id)
+	}
+
+	var ids []string
+	for i := 0; i < 10; i++ {
+		id, err := NewULID(rand.Reader, now.Add(time.Duration(i)*time.Millisecond))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(id) != 26 {
+			t.Fatalf("invalid ULID %s", id)
+		}
+		ids = append(ids, id)
+	}
+	if !sort.StringsAreSorted(ids) {
+		t.Fatalf("expected ULIDs sorted by time, got %v", ids)
+	}
+}
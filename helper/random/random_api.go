@@ -5,8 +5,6 @@ package random
 
 import (
 	"crypto/rand"
-	"encoding/base64"
-	"encoding/hex"
 	"fmt"
 	"io"
 	"strconv"
@@ -53,11 +51,8 @@ func HandleRandomAPI(d *framework.FieldData, additionalSource io.Reader) (*logic
 		return logical.ErrorResponse(`"bytes" should be less than %d`, APIMaxBytes), nil
 	}
 
-	switch format {
-	case "hex":
-	case "base64":
-	default:
-		return logical.ErrorResponse("unsupported encoding format %q; must be \"hex\" or \"base64\"", format), nil
+	if !ValidEncodingFormat(format) {
+		return logical.ErrorResponse("unsupported encoding format %q; must be \"hex\", \"base64\", \"base64url\" or \"base32\"", format), nil
 	}
 
 	var randBytes []byte
@@ -89,12 +84,9 @@ func HandleRandomAPI(d *framework.FieldData, additionalSource io.Reader) (*logic
 		return nil, err
 	}
 
-	var retStr string
-	switch format {
-	case "hex":
-		retStr = hex.EncodeToString(randBytes)
-	case "base64":
-		retStr = base64.StdEncoding.EncodeToString(randBytes)
+	retStr, err := EncodeBytes(randBytes, format)
+	if err != nil {
+		return nil, err
 	}
 
 	// Generate the response
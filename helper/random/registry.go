@@ -13,7 +13,10 @@ var (
 	// defaultRuleNameMapping is the default mapping of HCL rule names to the appropriate rule constructor.
 	// Add to this map when adding a new Rule type to be recognized in HCL.
 	defaultRuleNameMapping = map[string]ruleConstructor{
-		"charset": ParseCharset,
+		"charset":    ParseCharset,
+		"dictionary": ParseDictionary,
+		"entropy":    ParseEntropy,
+		"sequence":   ParseSequence,
 	}
 
 	defaultRegistry = Registry{
@@ -5,6 +5,9 @@ package random
 
 import (
 	"fmt"
+	"math"
+	"strings"
+	"unicode"
 
 	"github.com/mitchellh/mapstructure"
 )
@@ -92,3 +95,263 @@ func charIn(search rune, charset []rune) bool {
 	}
 	return false
 }
+
+// EntropyRule requires an estimated minimum entropy.
+type EntropyRule struct {
+	// MinBits is the minimum (inclusive) number of bits of entropy the string should have, as estimated by
+	// EstimateEntropy.
+	MinBits float64 `mapstructure:"min-bits" json:"min-bits"`
+}
+
+// ParseEntropy from the provided data map. The data map is expected to be parsed from HCL.
+func ParseEntropy(data map[string]interface{}) (rule Rule, err error) {
+	er := &EntropyRule{}
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           er,
+		WeaklyTypedInput: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode entropy restriction: %w", err)
+	}
+
+	err = decoder.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse entropy restriction: %w", err)
+	}
+	if er.MinBits < 0 {
+		return nil, fmt.Errorf("min-bits must be >= 0")
+	}
+
+	return *er, nil
+}
+
+func (e EntropyRule) Type() string {
+	return "entropy"
+}
+
+// Pass returns true if the estimated entropy of the provided candidate string is at least the minimum.
+func (e EntropyRule) Pass(value []rune) bool {
+	return EstimateEntropy(value) >= e.MinBits
+}
+
+// EstimateEntropy estimates the entropy, in bits, of a string as if each of its characters was chosen at random
+// from the character classes it uses: lowercase and uppercase letters, numbers and symbols, along with each distinct
+// character belonging to none of them.
+func EstimateEntropy(value []rune) float64 {
+	if len(value) == 0 {
+		return 0
+	}
+
+	var lower, upper, numeric, symbol bool
+	others := map[rune]struct{}{}
+	for _, r := range value {
+		switch {
+		case charIn(r, LowercaseRuneset):
+			lower = true
+		case charIn(r, UppercaseRuneset):
+			upper = true
+		case charIn(r, NumericRuneset):
+			numeric = true
+		case charIn(r, FullSymbolRuneset):
+			symbol = true
+		default:
+			others[r] = struct{}{}
+		}
+	}
+
+	pool := len(others)
+	if lower {
+		pool += len(LowercaseRuneset)
+	}
+	if upper {
+		pool += len(UppercaseRuneset)
+	}
+	if numeric {
+		pool += len(NumericRuneset)
+	}
+	if symbol {
+		pool += len(FullSymbolRuneset)
+	}
+	if pool < 2 {
+		return 0
+	}
+	return float64(len(value)) * math.Log2(float64(pool))
+}
+
+// keyboardRows are the rows of a US keyboard, whose adjacent keys make sequences.
+var keyboardRows = []string{
+	"`1234567890-=",
+	"qwertyuiop[]\\",
+	"asdfghjkl;'",
+	"zxcvbnm,./",
+}
+
+// SequenceRule rejects strings containing sequences of characters longer than allowed: consecutive letters or
+// numbers, such as "abc" or "987", and adjacent keys of a keyboard row, such as "qwe" or "lkj", ignoring case.
+type SequenceRule struct {
+	// MaxLength is the length of the longest sequence allowed. It defaults to 2.
+	MaxLength int `mapstructure:"max-length" json:"max-length"`
+}
+
+// ParseSequence from the provided data map. The data map is expected to be parsed from HCL.
+func ParseSequence(data map[string]interface{}) (rule Rule, err error) {
+	sr := &SequenceRule{}
+
+	err = mapstructure.Decode(data, sr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse sequence restriction: %w", err)
+	}
+	switch {
+	case sr.MaxLength == 0:
+		sr.MaxLength = 2
+	case sr.MaxLength < 2:
+		return nil, fmt.Errorf("max-length must be >= 2")
+	}
+
+	return *sr, nil
+}
+
+func (s SequenceRule) Type() string {
+	return "sequence"
+}
+
+// Pass returns true if the provided candidate string contains no sequence longer than the maximum length.
+func (s SequenceRule) Pass(value []rune) bool {
+	n := s.MaxLength + 1
+	if s.MaxLength <= 0 || len(value) < n {
+		return true
+	}
+
+	lowered := []rune(strings.ToLower(string(value)))
+	for i := 0; i+n <= len(lowered); i++ {
+		window := lowered[i : i+n]
+		if isCharacterSequence(window) || isKeyboardSequence(string(window)) {
+			return false
+		}
+	}
+	return true
+}
+
+// isCharacterSequence returns whether the runes are consecutive letters or numbers, in either order.
+func isCharacterSequence(window []rune) bool {
+	isLetter := unicode.IsLetter(window[0])
+	isDigit := unicode.IsDigit(window[0])
+	if !isLetter && !isDigit {
+		return false
+	}
+
+	step := window[1] - window[0]
+	if step != 1 && step != -1 {
+		return false
+	}
+	for i := 1; i < len(window); i++ {
+		if window[i]-window[i-1] != step || unicode.IsLetter(window[i]) != isLetter || unicode.IsDigit(window[i]) != isDigit {
+			return false
+		}
+	}
+	return true
+}
+
+// isKeyboardSequence returns whether the string is made of adjacent keys of a keyboard row, in either order.
+func isKeyboardSequence(window string) bool {
+	reversed := []rune(window)
+	for i, j := 0, len(reversed)-1; i < j; i, j = i+1, j-1 {
+		reversed[i], reversed[j] = reversed[j], reversed[i]
+	}
+
+	for _, row := range keyboardRows {
+		if strings.Contains(row, window) || strings.Contains(row, string(reversed)) {
+			return true
+		}
+	}
+	return false
+}
+
+// DictionaryRule rejects strings containing any of its words, ignoring case. Its words are either listed, or read
+// from a wordlist uploaded to Vault, which must be resolved with StringGenerator.ResolveWordlists.
+type DictionaryRule struct {
+	// Words the string must not contain.
+	Words []string `mapstructure:"words" json:"words,omitempty"`
+
+	// Wordlist is the name of a wordlist holding words the string must not contain.
+	Wordlist string `mapstructure:"wordlist" json:"wordlist,omitempty"`
+
+	// MinWordLength is the length of the shortest words checked, as very short words would reject most strings.
+	// It defaults to 4.
+	MinWordLength int `mapstructure:"min-word-length" json:"min-word-length"`
+
+	// index holds the lowercased words checked, and their shortest and longest lengths.
+	index          map[string]struct{}
+	shortest       int
+	longest        int
+	unresolvedList bool
+}
+
+// ParseDictionary from the provided data map. The data map is expected to be parsed from HCL.
+func ParseDictionary(data map[string]interface{}) (rule Rule, err error) {
+	dr := &DictionaryRule{}
+
+	err = mapstructure.Decode(data, dr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dictionary restriction: %w", err)
+	}
+	if len(dr.Words) == 0 && dr.Wordlist == "" {
+		return *dr, fmt.Errorf("words or wordlist is required")
+	}
+	switch {
+	case dr.MinWordLength == 0:
+		dr.MinWordLength = 4
+	case dr.MinWordLength < 1:
+		return nil, fmt.Errorf("min-word-length must be > 0")
+	}
+
+	dr.unresolvedList = dr.Wordlist != ""
+	return dr.withWords(dr.Words), nil
+}
+
+func (d DictionaryRule) Type() string {
+	return "dictionary"
+}
+
+// withWords returns a copy of the rule also rejecting the given words.
+func (d DictionaryRule) withWords(words []string) DictionaryRule {
+	index := make(map[string]struct{}, len(d.index)+len(words))
+	for word := range d.index {
+		index[word] = struct{}{}
+	}
+	for _, word := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		length := len([]rune(word))
+		if length < d.MinWordLength {
+			continue
+		}
+		index[word] = struct{}{}
+		if d.shortest == 0 || length < d.shortest {
+			d.shortest = length
+		}
+		if length > d.longest {
+			d.longest = length
+		}
+	}
+	d.index = index
+	return d
+}
+
+// Pass returns true if the provided candidate string contains none of the words of the dictionary. It returns false
+// when the wordlist of the rule was not resolved.
+func (d DictionaryRule) Pass(value []rune) bool {
+	if d.unresolvedList {
+		return false
+	}
+
+	lowered := []rune(strings.ToLower(string(value)))
+	for i := range lowered {
+		for length := d.shortest; length <= d.longest && i+length <= len(lowered); length++ {
+			if _, ok := d.index[string(lowered[i:i+length])]; ok {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -91,3 +91,178 @@ func TestCharset(t *testing.T) {
 		})
 	}
 }
+
+func TestEntropy(t *testing.T) {
+	type testCase struct {
+		minBits  float64
+		input    string
+		expected bool
+	}
+
+	tests := map[string]testCase{
+		"empty input": {
+			minBits:  1,
+			input:    "",
+			expected: false,
+		},
+		"0 minimum": {
+			minBits:  0,
+			input:    "aaaa",
+			expected: true,
+		},
+		"lowercase only, too short": {
+			minBits:  60,
+			input:    "abcdefghij",
+			expected: false,
+		},
+		"lowercase only, long enough": {
+			minBits:  60,
+			input:    "abcdefghijklmno",
+			expected: true,
+		},
+		"mixed classes": {
+			minBits:  60,
+			input:    "aB3$eF7&iJ",
+			expected: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			er := EntropyRule{
+				MinBits: test.minBits,
+			}
+			actual := er.Pass([]rune(test.input))
+			if actual != test.expected {
+				t.Fatalf("Actual: %t Expected: %t (entropy: %f)", actual, test.expected, EstimateEntropy([]rune(test.input)))
+			}
+		})
+	}
+}
+
+func TestSequence(t *testing.T) {
+	type testCase struct {
+		maxLength int
+		input     string
+		expected  bool
+	}
+
+	tests := map[string]testCase{
+		"empty input": {
+			maxLength: 2,
+			input:     "",
+			expected:  true,
+		},
+		"no sequence": {
+			maxLength: 2,
+			input:     "a8Kd2!xq",
+			expected:  true,
+		},
+		"sequence at max length": {
+			maxLength: 2,
+			input:     "xab9",
+			expected:  true,
+		},
+		"alphabetic sequence": {
+			maxLength: 2,
+			input:     "x9abcq",
+			expected:  false,
+		},
+		"mixed case alphabetic sequence": {
+			maxLength: 2,
+			input:     "x9aBcq",
+			expected:  false,
+		},
+		"descending numeric sequence": {
+			maxLength: 2,
+			input:     "x987q",
+			expected:  false,
+		},
+		"keyboard sequence": {
+			maxLength: 2,
+			input:     "1qwe9",
+			expected:  false,
+		},
+		"reversed keyboard sequence": {
+			maxLength: 2,
+			input:     "1LKJ9",
+			expected:  false,
+		},
+		"keyboard sequence with symbols": {
+			maxLength: 2,
+			input:     "x,./q",
+			expected:  false,
+		},
+		"sequence within a longer max length": {
+			maxLength: 3,
+			input:     "xabcq",
+			expected:  true,
+		},
+		"sequence at the end of the alphabet": {
+			maxLength: 2,
+			input:     "xyz",
+			expected:  false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			sr := SequenceRule{
+				MaxLength: test.maxLength,
+			}
+			actual := sr.Pass([]rune(test.input))
+			if actual != test.expected {
+				t.Fatalf("Actual: %t Expected: %t", actual, test.expected)
+			}
+		})
+	}
+}
+
+func TestDictionary(t *testing.T) {
+	type testCase struct {
+		data     map[string]interface{}
+		input    string
+		expected bool
+	}
+
+	tests := map[string]testCase{
+		"no words in input": {
+			data:     map[string]interface{}{"words": []string{"password", "vault"}},
+			input:    "x8Kd2!xqz",
+			expected: true,
+		},
+		"word in input": {
+			data:     map[string]interface{}{"words": []string{"password", "vault"}},
+			input:    "x8Vault2!",
+			expected: false,
+		},
+		"words shorter than the minimum are ignored": {
+			data:     map[string]interface{}{"words": []string{"abc"}},
+			input:    "xabcx",
+			expected: true,
+		},
+		"custom minimum word length": {
+			data:     map[string]interface{}{"words": []string{"abc"}, "min-word-length": 3},
+			input:    "xABCx",
+			expected: false,
+		},
+		"unresolved wordlist": {
+			data:     map[string]interface{}{"wordlist": "common"},
+			input:    "x8Kd2!xqz",
+			expected: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dr, err := ParseDictionary(test.data)
+			if err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+			actual := dr.Pass([]rune(test.input))
+			if actual != test.expected {
+				t.Fatalf("Actual: %t Expected: %t", actual, test.expected)
+			}
+		})
+	}
+}
@@ -117,6 +117,27 @@ LOOP:
 	}
 }
 
+// ResolveWordlists reads the words of the wordlists of the dictionary rules with the provided lookup function, which
+// returns an error when there is no wordlist with the given name. Dictionary rules with a wordlist reject all strings
+// until their wordlist is resolved.
+func (g *StringGenerator) ResolveWordlists(lookup func(name string) ([]string, error)) error {
+	for i, rule := range g.Rules {
+		dr, ok := rule.(DictionaryRule)
+		if !ok || !dr.unresolvedList {
+			continue
+		}
+
+		words, err := lookup(dr.Wordlist)
+		if err != nil {
+			return fmt.Errorf("unable to resolve wordlist %q: %w", dr.Wordlist, err)
+		}
+		dr = dr.withWords(words)
+		dr.unresolvedList = false
+		g.Rules[i] = dr
+	}
+	return nil
+}
+
 func (g *StringGenerator) generate(rng io.Reader) (str string, err error) {
 	// If performance improvements need to be made, this can be changed to read a batch of
 	// potential strings at once rather than one at a time. This will significantly
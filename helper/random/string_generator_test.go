@@ -551,6 +551,47 @@ func TestStringGenerator_JSON(t *testing.T) {
 	}
 }
 
+func TestStringGenerator_ResolveWordlists(t *testing.T) {
+	gen, err := ParsePolicy(`
+length = 20
+rule "charset" {
+  charset = "abcdefghijklmnopqrstuvwxyz"
+}
+rule "dictionary" {
+  wordlist = "common"
+}`)
+	if err != nil {
+		t.Fatalf("no error expected, got: %s", err)
+	}
+
+	if gen.Rules[1].Pass([]rune("abcdefgh")) {
+		t.Fatalf("unresolved wordlist should reject all strings")
+	}
+
+	err = gen.ResolveWordlists(func(name string) ([]string, error) {
+		return nil, fmt.Errorf("no wordlist")
+	})
+	if err == nil {
+		t.Fatalf("error expected, got none")
+	}
+
+	err = gen.ResolveWordlists(func(name string) ([]string, error) {
+		if name != "common" {
+			t.Fatalf("unexpected wordlist %q", name)
+		}
+		return []string{"secret"}, nil
+	})
+	if err != nil {
+		t.Fatalf("no error expected, got: %s", err)
+	}
+	if !gen.Rules[1].Pass([]rune("abcdefgh")) {
+		t.Fatalf("string without words of the wordlist should pass")
+	}
+	if gen.Rules[1].Pass([]rune("mysecretx")) {
+		t.Fatalf("string with words of the wordlist should not pass")
+	}
+}
+
 type badReader struct{}
 
 func (badReader) Read([]byte) (int, error) {
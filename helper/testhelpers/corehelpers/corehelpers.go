@@ -391,6 +391,31 @@ func (n *NoopAudit) Salt(ctx context.Context) (*salt.Salt, error) {
 	return s, nil
 }
 
+func (n *NoopAudit) TailFormatter() audit.Formatter {
+	return n.formatter
+}
+
+// ReadArchive calls fn with the formatted entries logged by the backend.
+func (n *NoopAudit) ReadArchive(_ context.Context, fn func(entry []byte) error) error {
+	n.l.RLock()
+	records := n.records
+	n.l.RUnlock()
+
+	for _, record := range records {
+		if err := fn(bytes.TrimSpace(record)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportRedactions returns the redaction rules configured with the
+// "export_redact" key.
+func (n *NoopAudit) ExportRedactions() []audit.RedactionRule {
+	rules, _ := audit.ParseRedactionRules(n.Config.Config["export_redact"])
+	return rules
+}
+
 func (n *NoopAudit) GetHash(ctx context.Context, data string) (string, error) {
 	s, err := n.Salt(ctx)
 	if err != nil {
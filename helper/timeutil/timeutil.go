@@ -10,6 +10,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/hashicorp/vault/sdk/helper/clockutil"
 )
 
 func StartOfPreviousMonth(t time.Time) time.Time {
@@ -143,25 +145,19 @@ func SkipAtEndOfMonth(t *testing.T) {
 	}
 }
 
-// This interface allows unit tests to substitute in a simulated Clock.
-type Clock interface {
-	Now() time.Time
-	NewTicker(time.Duration) *time.Ticker
-	NewTimer(time.Duration) *time.Timer
-}
+// Clock allows unit tests to substitute in a simulated clock. It is defined
+// in the sdk so that plugins share it.
+type Clock = clockutil.Clock
 
-type DefaultClock struct{}
+// Timer is a timer returned by Clock.AfterFunc.
+type Timer = clockutil.Timer
 
-var _ Clock = (*DefaultClock)(nil)
+type DefaultClock = clockutil.DefaultClock
 
-func (_ DefaultClock) Now() time.Time {
-	return time.Now()
-}
-
-func (_ DefaultClock) NewTicker(d time.Duration) *time.Ticker {
-	return time.NewTicker(d)
-}
+// ManualClock is a Clock that only moves when it is advanced.
+type ManualClock = clockutil.ManualClock
 
-func (_ DefaultClock) NewTimer(d time.Duration) *time.Timer {
-	return time.NewTimer(d)
+// NewManualClock returns a ManualClock set to the given time.
+func NewManualClock(start time.Time) *ManualClock {
+	return clockutil.NewManualClock(start)
 }
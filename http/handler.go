@@ -165,6 +165,8 @@ func handler(props *vault.HandlerProperties) http.Handler {
 		mux.Handle("/v1/sys/leader", handleSysLeader(core))
 		mux.Handle("/v1/sys/health", handleSysHealth(core))
 		mux.Handle("/v1/sys/monitor", handleLogicalNoForward(core))
+		mux.Handle("/v1/sys/audit-tail/", handleLogicalNoForward(core))
+		mux.Handle("/v1/sys/audit-export/", handleLogicalNoForward(core))
 		mux.Handle("/v1/sys/generate-root/attempt", handleRequestForwarding(core,
 			handleAuditNonLogical(core, handleSysGenerateRootAttempt(core, vault.GenerateStandardRootTokenStrategy))))
 		mux.Handle("/v1/sys/generate-root/update", handleRequestForwarding(core,
@@ -352,8 +354,9 @@ func wrapGenericHandler(core *vault.Core, h http.Handler, props *vault.HandlerPr
 		// Start with the request context
 		ctx := r.Context()
 		var cancelFunc context.CancelFunc
-		// Add our timeout, but not for the monitor or events endpoints, as they are streaming
-		if strings.HasSuffix(r.URL.Path, "sys/monitor") || strings.Contains(r.URL.Path, "sys/events") {
+		// Add our timeout, but not for the monitor, audit tail or events endpoints, as they are streaming,
+		// nor for audit exports, which read through entire audit logs
+		if strings.HasSuffix(r.URL.Path, "sys/monitor") || strings.Contains(r.URL.Path, "sys/audit-tail/") || strings.Contains(r.URL.Path, "sys/audit-export/") || strings.Contains(r.URL.Path, "sys/events/subscribe/") {
 			ctx, cancelFunc = context.WithCancel(ctx)
 		} else {
 			ctx, cancelFunc = context.WithTimeout(ctx, maxRequestDuration)
@@ -935,6 +938,14 @@ func request(core *vault.Core, w http.ResponseWriter, rawReq *http.Request, r *l
 		return nil, false, true
 	}
 
+	// Return the index state of writes so clients can require it on later
+	// reads, possibly served by other nodes.
+	if state := r.ResponseState(); state != nil {
+		if header := core.IndexStateHeader(state); header != "" {
+			w.Header().Set(VaultIndexHeaderName, header)
+		}
+	}
+
 	if resp != nil && len(resp.Headers) > 0 {
 		// Set this here so it will take effect regardless of any other type of
 		// response processing
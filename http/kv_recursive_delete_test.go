@@ -0,0 +1,141 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package http
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	kv "github.com/hashicorp/vault-plugin-secrets-kv"
+	"github.com/hashicorp/vault/api"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/vault"
+)
+
+func TestKVRecursiveDelete(t *testing.T) {
+	coreConfig := &vault.CoreConfig{
+		LogicalBackends: map[string]logical.Factory{
+			"kv": kv.Factory,
+		},
+	}
+	cluster := vault.NewTestCluster(t, coreConfig, &vault.TestClusterOptions{
+		HandlerFunc: Handler,
+	})
+	cluster.Start()
+	defer cluster.Cleanup()
+
+	core := cluster.Cores[0].Core
+	vault.TestWaitActive(t, core)
+	client := cluster.Cores[0].Client
+
+	if err := client.Sys().Mount("kv", &api.MountInput{
+		Type:    "kv",
+		Options: map[string]string{"version": "2"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	data := map[string]interface{}{
+		"data": map[string]interface{}{"a": "b"},
+	}
+	for _, path := range []string{"kv/data/app", "kv/data/app/db", "kv/data/app/web/tls", "kv/data/other"} {
+		if _, err := client.Logical().Write(path, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	secret, err := client.Logical().Write("sys/kv-recursive-delete", map[string]interface{}{
+		"path":    "kv/metadata/app",
+		"dry_run": true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := secret.Data["keys"]
+	if expected := []interface{}{"app", "app/db", "app/web/tls"}; !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("expected dry run keys %v, got %v", expected, keys)
+	}
+	if secret, err := client.Logical().Read("kv/metadata/app/db"); err != nil || secret == nil {
+		t.Fatalf("expected the dry run not to delete keys, got: %v, %v", secret, err)
+	}
+
+	recursiveDelete := func(client *api.Client) map[string]interface{} {
+		t.Helper()
+		secret, err := client.Logical().Write("sys/kv-recursive-delete", map[string]interface{}{
+			"path": "kv/metadata/app",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		statusPath := secret.Data["status_path"].(string)
+
+		var status *api.Secret
+		for i := 0; i < 50; i++ {
+			status, err = cluster.Cores[0].Client.Logical().Read(statusPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status.Data["status"] != "running" {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		if status.Data["status"] != "completed" {
+			t.Fatalf("expected the delete to complete, got: %v", status.Data)
+		}
+		return status.Data
+	}
+
+	// Deletes are authorized with the policies of the token starting the
+	// recursive delete
+	if err := client.Sys().PutPolicy("lister", `
+path "sys/kv-recursive-delete" {
+	capabilities = ["update"]
+}
+path "kv/metadata/*" {
+	capabilities = ["read", "list"]
+}
+`); err != nil {
+		t.Fatal(err)
+	}
+	tokenSecret, err := client.Auth().Token().Create(&api.TokenCreateRequest{
+		Policies: []string{"lister"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lister, err := client.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lister.SetToken(tokenSecret.Auth.ClientToken)
+	status := recursiveDelete(lister)
+	if deleted, failed := status["deleted"].(json.Number).String(), status["failed"].(json.Number).String(); deleted != "0" || failed != "3" {
+		t.Fatalf("expected 3 failed deletes, got: %v", status)
+	}
+	if secret, err := client.Logical().Read("kv/metadata/app/db"); err != nil || secret == nil {
+		t.Fatalf("expected the key to be kept, got: %v, %v", secret, err)
+	}
+
+	status = recursiveDelete(client)
+	if deleted := status["deleted"].(json.Number).String(); deleted != "3" {
+		t.Fatalf("expected 3 deleted keys, got: %v", status)
+	}
+
+	for _, path := range []string{"kv/metadata/app", "kv/metadata/app/db", "kv/metadata/app/web/tls"} {
+		if secret, err := client.Logical().Read(path); err != nil || secret != nil {
+			t.Fatalf("expected %q to be deleted, got: %v, %v", path, secret, err)
+		}
+	}
+	if secret, err := client.Logical().Read("kv/metadata/other"); err != nil || secret == nil {
+		t.Fatalf("expected keys outside the prefix to be kept, got: %v, %v", secret, err)
+	}
+
+	// Deleting the prefix again succeeds without deleting anything.
+	if deleted := recursiveDelete(client)["deleted"].(json.Number).String(); deleted != "0" {
+		t.Fatalf("expected no deleted keys, got: %v", deleted)
+	}
+}
@@ -5,6 +5,7 @@ package http
 
 import (
 	"bufio"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -96,6 +97,10 @@ func buildLogicalRequestNoAuth(perfStandby bool, w http.ResponseWriter, r *http.
 		case path == "sys/monitor":
 			passHTTPReq = true
 			responseWriter = w
+		case strings.HasPrefix(path, "sys/audit-tail/"):
+			responseWriter = w
+		case strings.HasPrefix(path, "sys/audit-export/"):
+			responseWriter = w
 		}
 
 	case "POST", "PUT":
@@ -263,7 +268,25 @@ func buildLogicalPath(r *http.Request) (string, int, error) {
 	return path, 0, nil
 }
 
+// withMountMaxRequestSize returns the request with the maximum request size
+// tuned on the mount handling it, if any, in place of the one of the listener,
+// so that mounts can accept larger requests or cap them lower.
+func withMountMaxRequestSize(core *vault.Core, r *http.Request) *http.Request {
+	ns, err := namespace.FromContext(r.Context())
+	if err != nil {
+		return r
+	}
+	path := ns.TrimmedPath(r.URL.Path[len("/v1/"):])
+
+	max := core.MountMaxRequestSize(r.Context(), path)
+	if max <= 0 {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), "max_request_size", max))
+}
+
 func buildLogicalRequest(core *vault.Core, w http.ResponseWriter, r *http.Request) (*logical.Request, io.ReadCloser, int, error) {
+	r = withMountMaxRequestSize(core, r)
 	req, origBody, status, err := buildLogicalRequestNoAuth(core.PerfStandby(), w, r)
 	if err != nil || status != 0 {
 		return nil, nil, status, err
@@ -314,6 +314,46 @@ func TestLogical_RequestSizeDisableLimit(t *testing.T) {
 	testResponseStatus(t, resp, http.StatusNoContent)
 }
 
+func TestLogical_RequestSizeMountLimit(t *testing.T) {
+	core, _, token := vault.TestCoreUnsealed(t)
+	ln, addr := TestListener(t)
+	props := &vault.HandlerProperties{
+		Core: core,
+		ListenerConfig: &configutil.Listener{
+			MaxRequestSize: 1024,
+			Address:        "127.0.0.1",
+			TLSDisable:     true,
+		},
+	}
+	TestServerWithListenerAndProperties(t, ln, addr, core, props)
+
+	defer ln.Close()
+	TestServerAuth(t, addr, token)
+
+	data := map[string]interface{}{
+		"data": strings.Repeat("a", 4096),
+	}
+
+	// The limit of the mount replaces the one of the listener
+	resp := testHttpPost(t, token, addr+"/v1/sys/mounts/secret/tune", map[string]interface{}{
+		"max_request_size": 1024 * 1024,
+	})
+	testResponseStatus(t, resp, http.StatusNoContent)
+	resp = testHttpPut(t, token, addr+"/v1/secret/foo", data)
+	testResponseStatus(t, resp, http.StatusNoContent)
+	resp = testHttpPut(t, token, addr+"/v1/cubbyhole/foo", data)
+	testResponseStatus(t, resp, http.StatusRequestEntityTooLarge)
+
+	resp = testHttpPost(t, token, addr+"/v1/sys/mounts/secret/tune", map[string]interface{}{
+		"max_request_size": 128,
+	})
+	testResponseStatus(t, resp, http.StatusNoContent)
+	resp = testHttpPut(t, token, addr+"/v1/secret/foo", map[string]interface{}{
+		"data": strings.Repeat("a", 512),
+	})
+	testResponseStatus(t, resp, http.StatusRequestEntityTooLarge)
+}
+
 func TestLogical_ListSuffix(t *testing.T) {
 	core, _, rootToken := vault.TestCoreUnsealed(t)
 	req, _ := http.NewRequest("GET", "http://127.0.0.1:8200/v1/secret/foo", nil)
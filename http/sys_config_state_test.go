@@ -164,6 +164,16 @@ func TestSysConfigState_Sanitized(t *testing.T) {
 				"plugin_directory":                    "",
 				"plugin_file_uid":                     json.Number("0"),
 				"plugin_file_permissions":             json.Number("0"),
+				"plugin_max_memory":                   json.Number("0"),
+				"plugin_max_restarts":                 json.Number("0"),
+				"plugin_restart_backoff":              json.Number("0"),
+				"rollback_workers":                    json.Number("0"),
+				"rollback_max_backoff":                json.Number("0"),
+				"rollback_backoff_jitter":             false,
+				"watchdog_path":                       "",
+				"watchdog_goroutine_growth":           json.Number("0"),
+				"watchdog_state_lock_hold":            json.Number("0"),
+				"watchdog_barrier_latency":            json.Number("0"),
 				"enable_response_header_hostname":     false,
 				"enable_response_header_raft_node_id": false,
 				"log_requests_level":                  "",
@@ -217,6 +217,10 @@ func getSysHealth(core *vault.Core, r *http.Request) (int, *HealthResponse, erro
 		body.LastWAL = vault.LastWAL(core)
 	}
 
+	if init && !sealed {
+		body.Warnings = core.HealthWarnings()
+	}
+
 	return code, body, nil
 }
 
@@ -239,4 +243,5 @@ type HealthResponse struct {
 	ClusterID                  string                 `json:"cluster_id,omitempty"`
 	LastWAL                    uint64                 `json:"last_wal,omitempty"`
 	License                    *HealthResponseLicense `json:"license,omitempty"`
+	Warnings                   []string               `json:"warnings,omitempty"`
 }
@@ -55,6 +55,12 @@ func TestSysHostInfo(t *testing.T) {
 	if info.Memory == nil {
 		t.Fatal("expected memory info")
 	}
+	if info.Runtime == nil {
+		t.Fatal("expected runtime info")
+	}
+	if _, ok := secret.Data["mlock"]; !ok {
+		t.Fatal("expected mlock status")
+	}
 
 	// Query against a standby, should error
 	secret, err = cores[1].Client.Logical().Read("sys/host-info")
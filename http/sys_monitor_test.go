@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/hashicorp/vault/api"
 	"github.com/hashicorp/vault/helper/testhelpers"
 	"github.com/hashicorp/vault/vault"
 )
@@ -125,3 +126,99 @@ func TestSysMonitorStreamingLogs(t *testing.T) {
 		})
 	}
 }
+
+func TestSysMonitorFilters(t *testing.T) {
+	t.Parallel()
+	cluster := vault.NewTestCluster(t, nil, &vault.TestClusterOptions{
+		HandlerFunc: Handler,
+		NumCores:    1,
+	})
+	defer cluster.Cleanup()
+
+	client := cluster.Cores[0].Client
+	stopCh := testhelpers.GenerateDebugLogs(t, client)
+	defer close(stopCh)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logCh, err := client.Sys().MonitorWithInput(ctx, &api.MonitorInput{
+		LogLevel:   "DEBUG",
+		LogFormat:  "json",
+		Subsystems: []string{"core"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type jsonlog struct {
+		Module string `json:"@module"`
+	}
+
+	count := 0
+	timeCh := time.After(10 * time.Second)
+	for count < 3 {
+		select {
+		case log := <-logCh:
+			jsonLog := &jsonlog{}
+			if err := json.Unmarshal([]byte(log), jsonLog); err != nil {
+				t.Fatal("Expected JSON log from channel")
+			}
+			if jsonLog.Module != "core" && !strings.HasPrefix(jsonLog.Module, "core.") {
+				t.Fatalf("expected only logs of the core subsystem, got one of %q", jsonLog.Module)
+			}
+			count++
+		case <-timeCh:
+			t.Fatal("Failed to get messages of the core subsystem after 10 seconds")
+		}
+	}
+
+	request := client.NewRequest("GET", "/v1/sys/monitor")
+	request.Params.Add("mount", "nonexistent/")
+	_, err = client.RawRequest(request)
+	if err == nil || !strings.Contains(err.Error(), "Code: 400") {
+		t.Fatalf("expected a 400 error for an unknown mount, got: %v", err)
+	}
+}
+
+func TestSysMonitorStreamsPerToken(t *testing.T) {
+	t.Parallel()
+	cluster := vault.NewTestCluster(t, nil, &vault.TestClusterOptions{
+		HandlerFunc: Handler,
+		NumCores:    1,
+	})
+	defer cluster.Cleanup()
+
+	client := cluster.Cores[0].Client
+	client.SetClientTimeout(0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for i := 0; i < 5; i++ {
+		if _, err := client.Sys().Monitor(ctx, "info", "standard"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := client.Sys().Monitor(ctx, "info", "standard")
+	if err == nil || !strings.Contains(err.Error(), "Code: 429") {
+		t.Fatalf("expected a 429 error past the concurrent streams of the token, got: %v", err)
+	}
+
+	// Streams are released once closed
+	cancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		ctx, cancel := context.WithCancel(context.Background())
+		_, err := client.Sys().Monitor(ctx, "info", "standard")
+		cancel()
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the streams to be released: %v", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
@@ -6,11 +6,13 @@ package event
 import (
 	"errors"
 	"fmt"
+	"math"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/hashicorp/eventlogger"
 	"github.com/hashicorp/go-secure-stdlib/parseutil"
 
 	"github.com/hashicorp/go-uuid"
@@ -28,6 +30,33 @@ type options struct {
 	withSocketType  string
 	withMaxDuration time.Duration
 	withFileMode    *os.FileMode
+	withCompression string
+
+	withTLSCACert     string
+	withTLSClientCert string
+	withTLSClientKey  string
+	withTLSServerName string
+
+	withSocketLocalAddress string
+	withSocketOwner        string
+
+	withBatchSize     int
+	withFlushInterval time.Duration
+
+	withPriorityFunc           func(*eventlogger.Event) Priority
+	withOperationalRateLimit   float64
+	withInformationalRateLimit float64
+
+	withBearerToken string
+	withQueueSize   int
+	withMaxRetries  int
+
+	withTLSEnabled       bool
+	withSASLMechanism    string
+	withSASLUsername     string
+	withSASLPassword     string
+	withPartitionKeyFunc func(*eventlogger.Event) string
+	withFailOpen         bool
 }
 
 // getDefaultOptions returns Options with their default values.
@@ -38,6 +67,12 @@ func getDefaultOptions() options {
 		withTag:         "vault",
 		withSocketType:  "tcp",
 		withMaxDuration: 2 * time.Second,
+
+		withBatchSize:     1000,
+		withFlushInterval: time.Minute,
+
+		withQueueSize:  1000,
+		withMaxRetries: 5,
 	}
 }
 
@@ -134,6 +169,8 @@ func WithTag(tag string) Option {
 }
 
 // WithSocketType provides an Option to represent the socket type for a socket sink.
+// Besides the network types supported by net.Dial, the "tls" socket type
+// writes to a TCP connection secured with TLS, as RFC 5425 does for syslog.
 func WithSocketType(socketType string) Option {
 	return func(o *options) error {
 		socketType = strings.TrimSpace(socketType)
@@ -166,7 +203,8 @@ func WithMaxDuration(duration string) Option {
 	}
 }
 
-// WithFileMode provides an Option to represent a file mode for a file sink.
+// WithFileMode provides an Option to represent a file mode for a file sink, or
+// for the socket file a UNIX socket sink binds to.
 // Supplying an empty string or whitespace will prevent this Option from being
 // applied, but it will not return an error in those circumstances.
 func WithFileMode(mode string) Option {
@@ -194,3 +232,380 @@ func WithFileMode(mode string) Option {
 		return nil
 	}
 }
+
+// WithCompression provides an Option to represent the compression of the
+// events written by a file sink. The only supported compression is "gzip".
+// Supplying an empty string or whitespace will prevent this Option from being
+// applied.
+func WithCompression(compression string) Option {
+	return func(o *options) error {
+		compression = strings.TrimSpace(compression)
+		switch compression {
+		case "":
+			return nil
+		case CompressionGzip:
+			o.withCompression = compression
+			return nil
+		default:
+			return fmt.Errorf("unsupported compression %q", compression)
+		}
+	}
+}
+
+// WithBatchSize provides an Option to represent the number of events written
+// per file by a parquet sink. Supplying an empty string or whitespace will
+// prevent this Option from being applied.
+func WithBatchSize(size string) Option {
+	return func(o *options) error {
+		size = strings.TrimSpace(size)
+		if size == "" {
+			return nil
+		}
+
+		parsed, err := strconv.Atoi(size)
+		switch {
+		case err != nil:
+			return fmt.Errorf("unable to parse batch size: %w", err)
+		case parsed <= 0:
+			return errors.New("batch size must be greater than zero")
+		default:
+			o.withBatchSize = parsed
+		}
+
+		return nil
+	}
+}
+
+// WithFlushInterval provides an Option to represent the longest time events
+// are buffered by a parquet sink, or compressed by a file sink, before being
+// written. Supplying an empty string or whitespace will prevent this Option
+// from being applied.
+func WithFlushInterval(interval string) Option {
+	return func(o *options) error {
+		interval = strings.TrimSpace(interval)
+		if interval == "" {
+			return nil
+		}
+
+		parsed, err := parseutil.ParseDurationSecond(interval)
+		switch {
+		case err != nil:
+			return fmt.Errorf("unable to parse flush interval: %w", err)
+		case parsed <= 0:
+			return errors.New("flush interval must be greater than zero")
+		default:
+			o.withFlushInterval = parsed
+		}
+
+		return nil
+	}
+}
+
+// WithTLSCACert provides an Option to represent the path of the PEM encoded CA
+// certificates verifying the server of a "tls" socket sink or a webhook sink.
+// The system roots are used when it isn't supplied.
+func WithTLSCACert(path string) Option {
+	return func(o *options) error {
+		path = strings.TrimSpace(path)
+		if path != "" {
+			o.withTLSCACert = path
+		}
+
+		return nil
+	}
+}
+
+// WithTLSClientCert provides an Option to represent the path of the PEM
+// encoded client certificate presented by a "tls" socket sink or a webhook
+// sink.
+func WithTLSClientCert(path string) Option {
+	return func(o *options) error {
+		path = strings.TrimSpace(path)
+		if path != "" {
+			o.withTLSClientCert = path
+		}
+
+		return nil
+	}
+}
+
+// WithTLSClientKey provides an Option to represent the path of the PEM
+// encoded private key of the client certificate of a "tls" socket sink or a
+// webhook sink.
+func WithTLSClientKey(path string) Option {
+	return func(o *options) error {
+		path = strings.TrimSpace(path)
+		if path != "" {
+			o.withTLSClientKey = path
+		}
+
+		return nil
+	}
+}
+
+// WithTLSServerName provides an Option to represent the name the certificate
+// of the server of a "tls" socket sink or a webhook sink is verified against.
+// The host of the address or URL is used when it isn't supplied.
+func WithTLSServerName(name string) Option {
+	return func(o *options) error {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			o.withTLSServerName = name
+		}
+
+		return nil
+	}
+}
+
+// WithSocketLocalAddress provides an Option to represent the local address a
+// UNIX socket sink binds to, either the path of a socket file the sink
+// creates, or, on Linux, an abstract socket name starting with "@".
+func WithSocketLocalAddress(address string) Option {
+	return func(o *options) error {
+		address = strings.TrimSpace(address)
+		if address != "" {
+			o.withSocketLocalAddress = address
+		}
+
+		return nil
+	}
+}
+
+// WithSocketOwner provides an Option to represent the owner of the socket file
+// a UNIX socket sink binds to, as "user" or "user:group", where the user and
+// group are either names or numeric IDs.
+func WithSocketOwner(owner string) Option {
+	return func(o *options) error {
+		owner = strings.TrimSpace(owner)
+		if owner != "" {
+			o.withSocketOwner = owner
+		}
+
+		return nil
+	}
+}
+
+// WithPriorityFunc provides an Option to represent the func classifying the
+// events processed by a priority sink.
+func WithPriorityFunc(fn func(*eventlogger.Event) Priority) Option {
+	return func(o *options) error {
+		if fn != nil {
+			o.withPriorityFunc = fn
+		}
+
+		return nil
+	}
+}
+
+// WithOperationalRateLimit provides an Option to represent the number of
+// operational events per second processed by a priority sink. Supplying an
+// empty string or whitespace will prevent this Option from being applied,
+// and a limit of zero lets every event through.
+func WithOperationalRateLimit(limit string) Option {
+	return func(o *options) error {
+		parsed, ok, err := parseRateLimit(limit)
+		if ok {
+			o.withOperationalRateLimit = parsed
+		}
+
+		return err
+	}
+}
+
+// WithInformationalRateLimit provides an Option to represent the number of
+// informational events per second processed by a priority sink. Supplying an
+// empty string or whitespace will prevent this Option from being applied,
+// and a limit of zero lets every event through.
+func WithInformationalRateLimit(limit string) Option {
+	return func(o *options) error {
+		parsed, ok, err := parseRateLimit(limit)
+		if ok {
+			o.withInformationalRateLimit = parsed
+		}
+
+		return err
+	}
+}
+
+// parseRateLimit parses a number of events per second, returning whether a
+// valid limit was supplied.
+func parseRateLimit(limit string) (float64, bool, error) {
+	limit = strings.TrimSpace(limit)
+	if limit == "" {
+		return 0, false, nil
+	}
+
+	parsed, err := strconv.ParseFloat(limit, 64)
+	switch {
+	case err != nil:
+		return 0, false, fmt.Errorf("unable to parse rate limit: %w", err)
+	case math.IsNaN(parsed) || math.IsInf(parsed, 0):
+		return 0, false, fmt.Errorf("invalid rate limit %q", limit)
+	case parsed < 0:
+		return 0, false, errors.New("rate limit cannot be negative")
+	default:
+		return parsed, true, nil
+	}
+}
+
+// WithBearerToken provides an Option to represent the bearer token
+// authenticating the requests of a webhook sink.
+func WithBearerToken(token string) Option {
+	return func(o *options) error {
+		token = strings.TrimSpace(token)
+		if token != "" {
+			o.withBearerToken = token
+		}
+
+		return nil
+	}
+}
+
+// WithQueueSize provides an Option to represent the number of events queued
+// by a webhook sink, or being produced by a kafka sink, before further events
+// are rejected. Supplying an empty string or whitespace will prevent this
+// Option from being applied.
+func WithQueueSize(size string) Option {
+	return func(o *options) error {
+		size = strings.TrimSpace(size)
+		if size == "" {
+			return nil
+		}
+
+		parsed, err := strconv.Atoi(size)
+		switch {
+		case err != nil:
+			return fmt.Errorf("unable to parse queue size: %w", err)
+		case parsed <= 0:
+			return errors.New("queue size must be greater than zero")
+		default:
+			o.withQueueSize = parsed
+		}
+
+		return nil
+	}
+}
+
+// WithMaxRetries provides an Option to represent the number of times a webhook
+// sink retries delivering an event before dropping it. Supplying an empty
+// string or whitespace will prevent this Option from being applied.
+func WithMaxRetries(retries string) Option {
+	return func(o *options) error {
+		retries = strings.TrimSpace(retries)
+		if retries == "" {
+			return nil
+		}
+
+		parsed, err := strconv.Atoi(retries)
+		switch {
+		case err != nil:
+			return fmt.Errorf("unable to parse max retries: %w", err)
+		case parsed < 0:
+			return errors.New("max retries cannot be negative")
+		default:
+			o.withMaxRetries = parsed
+		}
+
+		return nil
+	}
+}
+
+// WithTLSEnabled provides an Option to represent whether a kafka sink
+// connects to the brokers over TLS. Supplying any of the TLS options enables
+// TLS too. Supplying an empty string or whitespace will prevent this Option
+// from being applied.
+func WithTLSEnabled(enabled string) Option {
+	return func(o *options) error {
+		parsed, ok, err := parseBool("TLS enabled", enabled)
+		if ok {
+			o.withTLSEnabled = parsed
+		}
+
+		return err
+	}
+}
+
+// WithSASLMechanism provides an Option to represent the SASL mechanism a kafka
+// sink authenticates with: "plain", "scram-sha-256" or "scram-sha-512".
+func WithSASLMechanism(mechanism string) Option {
+	return func(o *options) error {
+		mechanism = strings.ToLower(strings.TrimSpace(mechanism))
+		switch mechanism {
+		case "":
+		case SASLMechanismPlain, SASLMechanismSCRAMSHA256, SASLMechanismSCRAMSHA512:
+			o.withSASLMechanism = mechanism
+		default:
+			return fmt.Errorf("unsupported SASL mechanism %q", mechanism)
+		}
+
+		return nil
+	}
+}
+
+// WithSASLUsername provides an Option to represent the username a kafka sink
+// authenticates with.
+func WithSASLUsername(username string) Option {
+	return func(o *options) error {
+		username = strings.TrimSpace(username)
+		if username != "" {
+			o.withSASLUsername = username
+		}
+
+		return nil
+	}
+}
+
+// WithSASLPassword provides an Option to represent the password a kafka sink
+// authenticates with.
+func WithSASLPassword(password string) Option {
+	return func(o *options) error {
+		password = strings.TrimSpace(password)
+		if password != "" {
+			o.withSASLPassword = password
+		}
+
+		return nil
+	}
+}
+
+// WithPartitionKeyFunc provides an Option to represent the func returning the
+// key of the events produced by a kafka sink, which decides their partition.
+// Events with an empty key are spread across the partitions.
+func WithPartitionKeyFunc(fn func(*eventlogger.Event) string) Option {
+	return func(o *options) error {
+		if fn != nil {
+			o.withPartitionKeyFunc = fn
+		}
+
+		return nil
+	}
+}
+
+// WithFailOpen provides an Option to represent whether a kafka sink drops the
+// events it can't produce instead of returning an error. Supplying an empty
+// string or whitespace will prevent this Option from being applied.
+func WithFailOpen(failOpen string) Option {
+	return func(o *options) error {
+		parsed, ok, err := parseBool("fail open", failOpen)
+		if ok {
+			o.withFailOpen = parsed
+		}
+
+		return err
+	}
+}
+
+// parseBool parses the value of the named option, returning whether a valid
+// value was supplied.
+func parseBool(name string, value string) (bool, bool, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return false, false, nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, false, fmt.Errorf("unable to parse %s: %w", name, err)
+	}
+	return parsed, true, nil
+}
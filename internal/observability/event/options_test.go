@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/hashicorp/eventlogger"
 	"github.com/stretchr/testify/require"
 )
 
@@ -106,6 +107,10 @@ func TestOptions_Default(t *testing.T) {
 	require.Equal(t, "AUTH", opts.withFacility)
 	require.Equal(t, "vault", opts.withTag)
 	require.Equal(t, 2*time.Second, opts.withMaxDuration)
+	require.Equal(t, 1000, opts.withBatchSize)
+	require.Equal(t, time.Minute, opts.withFlushInterval)
+	require.Equal(t, 1000, opts.withQueueSize)
+	require.Equal(t, 5, opts.withMaxRetries)
 }
 
 // TestOptions_Opts exercises getOpts with various Option values.
@@ -423,3 +428,313 @@ func TestOptions_WithFileMode(t *testing.T) {
 		})
 	}
 }
+
+// TestOptions_WithBatchSize exercises WithBatchSize Option to ensure it performs as expected.
+func TestOptions_WithBatchSize(t *testing.T) {
+	tests := map[string]struct {
+		Value                string
+		ExpectedValue        int
+		IsErrorExpected      bool
+		ExpectedErrorMessage string
+	}{
+		"empty-gives-default": {
+			Value: "",
+		},
+		"whitespace-give-default": {
+			Value: "    ",
+		},
+		"bad-value": {
+			Value:                "juan",
+			IsErrorExpected:      true,
+			ExpectedErrorMessage: "unable to parse batch size: strconv.Atoi: parsing \"juan\": invalid syntax",
+		},
+		"zero": {
+			Value:                "0",
+			IsErrorExpected:      true,
+			ExpectedErrorMessage: "batch size must be greater than zero",
+		},
+		"valid": {
+			Value:         " 500 ",
+			ExpectedValue: 500,
+		},
+	}
+
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			options := &options{}
+			applyOption := WithBatchSize(tc.Value)
+			err := applyOption(options)
+			switch {
+			case tc.IsErrorExpected:
+				require.Error(t, err)
+				require.EqualError(t, err, tc.ExpectedErrorMessage)
+			default:
+				require.NoError(t, err)
+				require.Equal(t, tc.ExpectedValue, options.withBatchSize)
+			}
+		})
+	}
+}
+
+// TestOptions_WithFlushInterval exercises WithFlushInterval Option to ensure it performs as expected.
+func TestOptions_WithFlushInterval(t *testing.T) {
+	tests := map[string]struct {
+		Value                string
+		ExpectedValue        time.Duration
+		IsErrorExpected      bool
+		ExpectedErrorMessage string
+	}{
+		"empty-gives-default": {
+			Value: "",
+		},
+		"whitespace-give-default": {
+			Value: "    ",
+		},
+		"bad-value": {
+			Value:                "juan",
+			IsErrorExpected:      true,
+			ExpectedErrorMessage: "unable to parse flush interval: time: invalid duration \"juan\"",
+		},
+		"zero": {
+			Value:                "0",
+			IsErrorExpected:      true,
+			ExpectedErrorMessage: "flush interval must be greater than zero",
+		},
+		"duration-30s": {
+			Value:         "30s",
+			ExpectedValue: 30 * time.Second,
+		},
+		"seconds": {
+			Value:         "90",
+			ExpectedValue: 90 * time.Second,
+		},
+	}
+
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			options := &options{}
+			applyOption := WithFlushInterval(tc.Value)
+			err := applyOption(options)
+			switch {
+			case tc.IsErrorExpected:
+				require.Error(t, err)
+				require.EqualError(t, err, tc.ExpectedErrorMessage)
+			default:
+				require.NoError(t, err)
+				require.Equal(t, tc.ExpectedValue, options.withFlushInterval)
+			}
+		})
+	}
+}
+
+// TestOptions_WithCompression exercises WithCompression Option to ensure it performs as expected.
+func TestOptions_WithCompression(t *testing.T) {
+	tests := map[string]struct {
+		Value                string
+		ExpectedValue        string
+		IsErrorExpected      bool
+		ExpectedErrorMessage string
+	}{
+		"empty": {
+			Value: "",
+		},
+		"whitespace": {
+			Value: "    ",
+		},
+		"unsupported": {
+			Value:                "zstd",
+			IsErrorExpected:      true,
+			ExpectedErrorMessage: "unsupported compression \"zstd\"",
+		},
+		"gzip": {
+			Value:         " gzip ",
+			ExpectedValue: CompressionGzip,
+		},
+	}
+
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			options := &options{}
+			applyOption := WithCompression(tc.Value)
+			err := applyOption(options)
+			switch {
+			case tc.IsErrorExpected:
+				require.Error(t, err)
+				require.EqualError(t, err, tc.ExpectedErrorMessage)
+			default:
+				require.NoError(t, err)
+				require.Equal(t, tc.ExpectedValue, options.withCompression)
+			}
+		})
+	}
+}
+
+// TestOptions_WithTLS exercises the TLS Options of socket sinks to ensure they
+// perform as expected.
+func TestOptions_WithTLS(t *testing.T) {
+	tests := map[string]struct {
+		Option func(string) Option
+		Value  func(*options) string
+	}{
+		"ca-cert": {
+			Option: WithTLSCACert,
+			Value:  func(o *options) string { return o.withTLSCACert },
+		},
+		"client-cert": {
+			Option: WithTLSClientCert,
+			Value:  func(o *options) string { return o.withTLSClientCert },
+		},
+		"client-key": {
+			Option: WithTLSClientKey,
+			Value:  func(o *options) string { return o.withTLSClientKey },
+		},
+		"server-name": {
+			Option: WithTLSServerName,
+			Value:  func(o *options) string { return o.withTLSServerName },
+		},
+	}
+
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			options := &options{}
+			require.NoError(t, tc.Option("    ")(options))
+			require.Equal(t, "", tc.Value(options))
+			require.NoError(t, tc.Option("   juan   ")(options))
+			require.Equal(t, "juan", tc.Value(options))
+		})
+	}
+}
+
+// TestOptions_WithRateLimit exercises the rate limit Options of priority
+// sinks to ensure they perform as expected.
+func TestOptions_WithRateLimit(t *testing.T) {
+	tests := map[string]struct {
+		Value                string
+		ExpectedValue        float64
+		IsErrorExpected      bool
+		ExpectedErrorMessage string
+	}{
+		"empty": {
+			Value: "",
+		},
+		"whitespace": {
+			Value: "    ",
+		},
+		"bad-value": {
+			Value:                "juan",
+			IsErrorExpected:      true,
+			ExpectedErrorMessage: "unable to parse rate limit: strconv.ParseFloat: parsing \"juan\": invalid syntax",
+		},
+		"negative": {
+			Value:                "-1",
+			IsErrorExpected:      true,
+			ExpectedErrorMessage: "rate limit cannot be negative",
+		},
+		"infinite": {
+			Value:                "Inf",
+			IsErrorExpected:      true,
+			ExpectedErrorMessage: "invalid rate limit \"Inf\"",
+		},
+		"unlimited": {
+			Value: "0",
+		},
+		"fractional": {
+			Value:         "0.5",
+			ExpectedValue: 0.5,
+		},
+	}
+
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			options := &options{}
+			err := WithOperationalRateLimit(tc.Value)(options)
+			switch {
+			case tc.IsErrorExpected:
+				require.EqualError(t, err, tc.ExpectedErrorMessage)
+			default:
+				require.NoError(t, err)
+				require.Equal(t, tc.ExpectedValue, options.withOperationalRateLimit)
+			}
+
+			err = WithInformationalRateLimit(tc.Value)(options)
+			switch {
+			case tc.IsErrorExpected:
+				require.EqualError(t, err, tc.ExpectedErrorMessage)
+			default:
+				require.NoError(t, err)
+				require.Equal(t, tc.ExpectedValue, options.withInformationalRateLimit)
+			}
+		})
+	}
+}
+
+// TestOptions_WithWebhook exercises the Options of webhook sinks to ensure
+// they perform as expected.
+func TestOptions_WithWebhook(t *testing.T) {
+	opts, err := getOpts(WithBearerToken(" token "), WithQueueSize("10"), WithMaxRetries("0"))
+	require.NoError(t, err)
+	require.Equal(t, "token", opts.withBearerToken)
+	require.Equal(t, 10, opts.withQueueSize)
+	require.Equal(t, 0, opts.withMaxRetries)
+
+	opts, err = getOpts(WithBearerToken(" "), WithQueueSize(" "), WithMaxRetries(" "))
+	require.NoError(t, err)
+	require.Empty(t, opts.withBearerToken)
+	require.Equal(t, 1000, opts.withQueueSize)
+	require.Equal(t, 5, opts.withMaxRetries)
+
+	_, err = getOpts(WithQueueSize("0"))
+	require.EqualError(t, err, "queue size must be greater than zero")
+	_, err = getOpts(WithQueueSize("juan"))
+	require.EqualError(t, err, "unable to parse queue size: strconv.Atoi: parsing \"juan\": invalid syntax")
+	_, err = getOpts(WithMaxRetries("-1"))
+	require.EqualError(t, err, "max retries cannot be negative")
+}
+
+// TestOptions_WithKafka exercises the options of the kafka sink.
+func TestOptions_WithKafka(t *testing.T) {
+	opts, err := getOpts(
+		WithTLSEnabled("true"),
+		WithSASLMechanism(" SCRAM-SHA-512 "),
+		WithSASLUsername(" vault "),
+		WithSASLPassword(" secret "),
+		WithPartitionKeyFunc(func(*eventlogger.Event) string { return "key" }),
+		WithFailOpen("true"),
+	)
+	require.NoError(t, err)
+	require.True(t, opts.withTLSEnabled)
+	require.Equal(t, SASLMechanismSCRAMSHA512, opts.withSASLMechanism)
+	require.Equal(t, "vault", opts.withSASLUsername)
+	require.Equal(t, "secret", opts.withSASLPassword)
+	require.NotNil(t, opts.withPartitionKeyFunc)
+	require.True(t, opts.withFailOpen)
+
+	opts, err = getOpts(WithTLSEnabled(" "), WithSASLMechanism(" "), WithPartitionKeyFunc(nil), WithFailOpen(" "))
+	require.NoError(t, err)
+	require.False(t, opts.withTLSEnabled)
+	require.Empty(t, opts.withSASLMechanism)
+	require.Nil(t, opts.withPartitionKeyFunc)
+	require.False(t, opts.withFailOpen)
+
+	_, err = getOpts(WithSASLMechanism("gssapi"))
+	require.EqualError(t, err, "unsupported SASL mechanism \"gssapi\"")
+	_, err = getOpts(WithFailOpen("juan"))
+	require.EqualError(t, err, "unable to parse fail open: strconv.ParseBool: parsing \"juan\": invalid syntax")
+	_, err = getOpts(WithTLSEnabled("juan"))
+	require.EqualError(t, err, "unable to parse TLS enabled: strconv.ParseBool: parsing \"juan\": invalid syntax")
+}
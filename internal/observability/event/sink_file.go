@@ -5,6 +5,7 @@ package event
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"fmt"
 	"io"
@@ -12,6 +13,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/eventlogger"
 )
@@ -22,6 +24,10 @@ const (
 	devnull         = "/dev/null"
 )
 
+// CompressionGzip is the compression used by a FileSink to write events
+// through a gzip writer.
+const CompressionGzip = "gzip"
+
 // FileSink is a sink node which handles writing events to file.
 type FileSink struct {
 	file           *os.File
@@ -29,10 +35,18 @@ type FileSink struct {
 	fileMode       os.FileMode
 	path           string
 	requiredFormat string
+
+	// gz compresses the events written to the file when the sink uses gzip
+	// compression. It is flushed once the flush interval elapsed since an
+	// event was written, so that the file can be read while it's written.
+	compression   string
+	gz            *gzip.Writer
+	flushInterval time.Duration
+	timer         *time.Timer
 }
 
 // NewFileSink should be used to create a new FileSink.
-// Accepted options: WithFileMode.
+// Accepted options: WithFileMode, WithCompression and WithFlushInterval.
 func NewFileSink(path string, format string, opt ...Option) (*FileSink, error) {
 	const op = "event.NewFileSink"
 
@@ -69,6 +83,8 @@ func NewFileSink(path string, format string, opt ...Option) (*FileSink, error) {
 		fileMode:       mode,
 		requiredFormat: format,
 		path:           p,
+		compression:    opts.withCompression,
+		flushInterval:  opts.withFlushInterval,
 	}
 
 	// Ensure that the file can be successfully opened for writing;
@@ -130,9 +146,7 @@ func (f *FileSink) Reopen() error {
 		return f.open()
 	}
 
-	err := f.file.Close()
-	// Set to nil here so that even if we error out, on the next access open() will be tried.
-	f.file = nil
+	err := f.close()
 	if err != nil {
 		return fmt.Errorf("%s: unable to close file for re-opening on sink: %w", op, err)
 	}
@@ -140,6 +154,28 @@ func (f *FileSink) Reopen() error {
 	return f.open()
 }
 
+// Flush writes the events compressed by the sink to the file, and commits the
+// file to stable storage.
+func (f *FileSink) Flush(_ context.Context) error {
+	const op = "event.(FileSink).Flush"
+
+	f.fileLock.Lock()
+	defer f.fileLock.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+
+	if err := f.flush(); err != nil {
+		return fmt.Errorf("%s: unable to flush compressed events: %w", op, err)
+	}
+	if err := f.file.Sync(); err != nil {
+		return fmt.Errorf("%s: unable to sync file: %w", op, err)
+	}
+
+	return nil
+}
+
 // Type describes the type of this node (sink).
 func (_ *FileSink) Type() eventlogger.NodeType {
 	return eventlogger.NodeTypeSink
@@ -166,6 +202,12 @@ func (f *FileSink) open() error {
 		return fmt.Errorf("%s: unable to open file for sink: %w", op, err)
 	}
 
+	// Each time the file is opened a new gzip member is appended to it, which
+	// tools such as gunzip decompress as if it were part of a single stream.
+	if f.compression == CompressionGzip {
+		f.gz = gzip.NewWriter(f.file)
+	}
+
 	// Change the file mode in case the log file already existed.
 	// We special case '/dev/null' since we can't chmod it, and bypass if the mode is zero.
 	switch f.path {
@@ -182,6 +224,70 @@ func (f *FileSink) open() error {
 	return nil
 }
 
+// close closes the file, first completing the gzip member written to it
+// when the sink uses compression. The file is unset even when closing fails,
+// so that open() will be tried on the next access.
+// The file lock must be held before calling this.
+func (f *FileSink) close() error {
+	if f.timer != nil {
+		f.timer.Stop()
+		f.timer = nil
+	}
+
+	var gzErr error
+	if f.gz != nil {
+		gzErr = f.gz.Close()
+		f.gz = nil
+	}
+
+	err := f.file.Close()
+	f.file = nil
+	if gzErr != nil {
+		return gzErr
+	}
+	return err
+}
+
+// writer returns the writer events are written to, which is the gzip writer
+// when the sink uses compression.
+// The file lock must be held before calling this.
+func (f *FileSink) writer() io.Writer {
+	if f.gz != nil {
+		return f.gz
+	}
+	return f.file
+}
+
+// flush writes the events buffered by the gzip writer to the file.
+// The file lock must be held before calling this.
+func (f *FileSink) flush() error {
+	if f.timer != nil {
+		f.timer.Stop()
+		f.timer = nil
+	}
+	if f.gz == nil {
+		return nil
+	}
+	return f.gz.Flush()
+}
+
+// flushOnInterval is called once the flush interval elapsed since an event
+// was compressed. When flushing fails, the file is closed so that it is
+// reopened on the next write.
+func (f *FileSink) flushOnInterval() {
+	f.fileLock.Lock()
+	defer f.fileLock.Unlock()
+
+	f.timer = nil
+	if f.file == nil {
+		return
+	}
+	if err := f.flush(); err != nil {
+		f.gz = nil
+		f.close()
+	}
+}
+
 // log writes the buffer to the file.
 // It acquires a lock on the file to do this.
 func (f *FileSink) log(data []byte) error {
@@ -196,18 +302,21 @@ func (f *FileSink) log(data []byte) error {
 		return fmt.Errorf("%s: unable to open file for sink: %w", op, err)
 	}
 
-	if _, err := reader.WriteTo(f.file); err == nil {
+	if _, err := reader.WriteTo(f.writer()); err == nil {
+		if f.gz != nil && f.timer == nil {
+			f.timer = time.AfterFunc(f.flushInterval, f.flushOnInterval)
+		}
 		return nil
 	}
 
 	// Otherwise, opportunistically try to re-open the FD, once per call (1 retry attempt).
-	err := f.file.Close()
+	// The gzip writer is discarded as it holds the error.
+	f.gz = nil
+	err := f.close()
 	if err != nil {
 		return fmt.Errorf("%s: unable to close file for sink: %w", op, err)
 	}
 
-	f.file = nil
-
 	if err := f.open(); err != nil {
 		return fmt.Errorf("%s: unable to re-open file for sink: %w", op, err)
 	}
@@ -217,10 +326,13 @@ func (f *FileSink) log(data []byte) error {
 		return fmt.Errorf("%s: unable to seek to start of file for sink: %w", op, err)
 	}
 
-	_, err = reader.WriteTo(f.file)
+	_, err = reader.WriteTo(f.writer())
 	if err != nil {
 		return fmt.Errorf("%s: unable to re-write to file for sink: %w", op, err)
 	}
+	if f.gz != nil && f.timer == nil {
+		f.timer = time.AfterFunc(f.flushInterval, f.flushOnInterval)
+	}
 
 	return nil
 }
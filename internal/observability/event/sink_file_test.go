@@ -4,6 +4,9 @@
 package event
 
 import (
+	"compress/gzip"
+	"context"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
@@ -299,3 +302,47 @@ func TestFileSink_Process(t *testing.T) {
 		})
 	}
 }
+
+// TestFileSink_Process_Gzip ensures that events are compressed with gzip,
+// flushed on the flush interval, and that the file remains a valid gzip
+// stream when it's reopened.
+func TestFileSink_Process_Gzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.log.gz")
+	sink, err := NewFileSink(path, "json", WithCompression("gzip"), WithFlushInterval("1s"))
+	require.NoError(t, err)
+
+	process := func(data string) {
+		t.Helper()
+		e := &eventlogger.Event{Formatted: make(map[string][]byte)}
+		e.FormattedAs("json", []byte(data))
+		_, err := sink.Process(namespace.RootContext(nil), e)
+		require.NoError(t, err)
+	}
+
+	// readAll decompresses the members of the file written so far. The last
+	// member is incomplete until the file is closed, so reading ends with an
+	// unexpected EOF.
+	readAll := func() string {
+		f, err := os.Open(path)
+		if err != nil {
+			return ""
+		}
+		defer f.Close()
+		r, err := gzip.NewReader(f)
+		if err != nil {
+			return ""
+		}
+		data, _ := io.ReadAll(r)
+		return string(data)
+	}
+
+	process("first\n")
+	require.Eventually(t, func() bool {
+		return readAll() == "first\n"
+	}, 10*time.Second, 100*time.Millisecond)
+
+	require.NoError(t, sink.Reopen())
+	process("second\n")
+	require.NoError(t, sink.Flush(context.Background()))
+	require.Equal(t, "first\nsecond\n", readAll())
+}
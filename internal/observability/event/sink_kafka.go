@@ -0,0 +1,235 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+const (
+	SASLMechanismPlain       = "plain"         // SASLMechanismPlain authenticates with the PLAIN SASL mechanism
+	SASLMechanismSCRAMSHA256 = "scram-sha-256" // SASLMechanismSCRAMSHA256 authenticates with the SCRAM-SHA-256 SASL mechanism
+	SASLMechanismSCRAMSHA512 = "scram-sha-512" // SASLMechanismSCRAMSHA512 authenticates with the SCRAM-SHA-512 SASL mechanism
+)
+
+// ErrKafkaBackPressure is returned when an event is processed while the
+// number of events being produced by a KafkaSink has reached its queue size.
+var ErrKafkaBackPressure = errors.New("too many events are being produced to kafka")
+
+// kafkaWriter produces messages to a kafka topic. It is satisfied by
+// *kafka.Writer, and replaced in tests.
+type kafkaWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// KafkaSink is a sink node which produces events to a kafka topic. Events are
+// keyed with the partition key func, so that the events sharing a key, such as
+// those of a mount, land in the same partition and keep their order.
+//
+// Processing an event blocks until the brokers acknowledge it. When the
+// brokers are unreachable or too slow, so that the number of events being
+// produced reaches the queue size, further events are rejected straight away
+// rather than piling up. Unless the sink fails open, failing to produce an
+// event is returned as an error.
+type KafkaSink struct {
+	requiredFormat string
+	maxDuration    time.Duration
+	partitionKey   func(*eventlogger.Event) string
+	failOpen       bool
+
+	writer   kafkaWriter
+	inFlight chan struct{}
+	dropped  atomic.Uint64
+}
+
+// NewKafkaSink should be used to create a new KafkaSink, producing events to
+// the topic through the comma separated brokers.
+// Accepted options: WithPartitionKeyFunc, WithQueueSize, WithMaxDuration,
+// WithFailOpen, WithTLSEnabled, WithTLSCACert, WithTLSClientCert,
+// WithTLSClientKey, WithTLSServerName, WithSASLMechanism, WithSASLUsername and
+// WithSASLPassword.
+func NewKafkaSink(format string, brokers string, topic string, opt ...Option) (*KafkaSink, error) {
+	const op = "event.NewKafkaSink"
+
+	var addrs []string
+	for _, broker := range strings.Split(brokers, ",") {
+		if broker = strings.TrimSpace(broker); broker != "" {
+			addrs = append(addrs, broker)
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("%s: at least one broker is required: %w", op, ErrInvalidParameter)
+	}
+
+	topic = strings.TrimSpace(topic)
+	if topic == "" {
+		return nil, fmt.Errorf("%s: topic is required: %w", op, ErrInvalidParameter)
+	}
+
+	opts, err := getOpts(opt...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: error applying options: %w", op, err)
+	}
+
+	transport := &kafka.Transport{
+		DialTimeout: opts.withMaxDuration,
+	}
+
+	// Supplying any TLS option implies TLS.
+	if opts.withTLSEnabled || opts.withTLSCACert != "" || opts.withTLSClientCert != "" ||
+		opts.withTLSClientKey != "" || opts.withTLSServerName != "" {
+		transport.TLS, err = newTLSConfig(opts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: error configuring TLS: %w", op, err)
+		}
+	}
+
+	transport.SASL, err = newSASLMechanism(opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: error configuring SASL: %w", op, err)
+	}
+
+	return &KafkaSink{
+		requiredFormat: format,
+		maxDuration:    opts.withMaxDuration,
+		partitionKey:   opts.withPartitionKeyFunc,
+		failOpen:       opts.withFailOpen,
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(addrs...),
+			Topic:        topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			// Events are produced synchronously, so only the events produced
+			// concurrently are batched together.
+			BatchTimeout: 5 * time.Millisecond,
+			WriteTimeout: opts.withMaxDuration,
+			Transport:    transport,
+		},
+		inFlight: make(chan struct{}, opts.withQueueSize),
+	}, nil
+}
+
+// newSASLMechanism returns the SASL mechanism authenticating with the brokers,
+// or nil when no mechanism is configured.
+func newSASLMechanism(opts options) (sasl.Mechanism, error) {
+	if opts.withSASLMechanism == "" {
+		if opts.withSASLUsername != "" || opts.withSASLPassword != "" {
+			return nil, errors.New("SASL mechanism is required with SASL credentials")
+		}
+		return nil, nil
+	}
+
+	if opts.withSASLUsername == "" || opts.withSASLPassword == "" {
+		return nil, errors.New("SASL username and password are required")
+	}
+
+	switch opts.withSASLMechanism {
+	case SASLMechanismPlain:
+		return plain.Mechanism{
+			Username: opts.withSASLUsername,
+			Password: opts.withSASLPassword,
+		}, nil
+	case SASLMechanismSCRAMSHA256:
+		return scram.Mechanism(scram.SHA256, opts.withSASLUsername, opts.withSASLPassword)
+	case SASLMechanismSCRAMSHA512:
+		return scram.Mechanism(scram.SHA512, opts.withSASLUsername, opts.withSASLPassword)
+	default:
+		return nil, fmt.Errorf("unsupported SASL mechanism %q", opts.withSASLMechanism)
+	}
+}
+
+// Process produces the event to the topic, blocking until the brokers
+// acknowledge it.
+func (s *KafkaSink) Process(ctx context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
+	const op = "event.(KafkaSink).Process"
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	if e == nil {
+		return nil, fmt.Errorf("%s: event is nil: %w", op, ErrInvalidParameter)
+	}
+
+	formatted, found := e.Format(s.requiredFormat)
+	if !found {
+		return nil, fmt.Errorf("%s: unable to retrieve event formatted as %q", op, s.requiredFormat)
+	}
+
+	msg := kafka.Message{
+		// The formatted event may be reused once it has been processed.
+		Value: append([]byte(nil), formatted...),
+		Time:  e.CreatedAt,
+	}
+	if s.partitionKey != nil {
+		if key := s.partitionKey(e); key != "" {
+			msg.Key = []byte(key)
+		}
+	}
+
+	if err := s.produce(ctx, msg); err != nil {
+		s.dropped.Add(1)
+		if !s.failOpen {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	// return nil for the event to indicate the pipeline is complete.
+	return nil, nil
+}
+
+// produce writes the message to the topic, unless the queue is full.
+func (s *KafkaSink) produce(ctx context.Context, msg kafka.Message) error {
+	select {
+	case s.inFlight <- struct{}{}:
+		defer func() { <-s.inFlight }()
+	default:
+		return ErrKafkaBackPressure
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.maxDuration)
+	defer cancel()
+
+	if err := s.writer.WriteMessages(ctx, msg); err != nil {
+		return fmt.Errorf("unable to produce event: %w", err)
+	}
+	return nil
+}
+
+// Dropped returns the number of events which couldn't be produced, whether
+// the sink failed open or closed.
+func (s *KafkaSink) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close flushes the events being produced, then closes the connections to
+// the brokers.
+func (s *KafkaSink) Close() error {
+	return s.writer.Close()
+}
+
+// Reopen is a no-op for the kafka sink, which reconnects to the brokers on
+// its own.
+func (_ *KafkaSink) Reopen() error {
+	return nil
+}
+
+// Type describes the type of this node (sink).
+func (_ *KafkaSink) Type() eventlogger.NodeType {
+	return eventlogger.NodeTypeSink
+}
@@ -0,0 +1,168 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package event
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKafkaWriter records the messages it writes, failing with err when it is
+// set, and blocking on each write until release is closed when it is set.
+type fakeKafkaWriter struct {
+	lock     sync.Mutex
+	messages []kafka.Message
+	err      error
+	started  chan struct{}
+	release  chan struct{}
+}
+
+func (w *fakeKafkaWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	if w.started != nil {
+		w.started <- struct{}{}
+		<-w.release
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	w.messages = append(w.messages, msgs...)
+	return nil
+}
+
+func (w *fakeKafkaWriter) Close() error {
+	return nil
+}
+
+func (w *fakeKafkaWriter) written() []kafka.Message {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.messages
+}
+
+// TestNewKafkaSink tests creation of a KafkaSink.
+func TestNewKafkaSink(t *testing.T) {
+	_, err := NewKafkaSink("json", " , ", "audit")
+	require.ErrorContains(t, err, "at least one broker is required")
+
+	_, err = NewKafkaSink("json", "kafka:9092", " ")
+	require.ErrorContains(t, err, "topic is required")
+
+	_, err = NewKafkaSink("json", "kafka:9092", "audit", WithSASLUsername("vault"))
+	require.ErrorContains(t, err, "SASL mechanism is required with SASL credentials")
+
+	_, err = NewKafkaSink("json", "kafka:9092", "audit", WithSASLMechanism("plain"), WithSASLUsername("vault"))
+	require.ErrorContains(t, err, "SASL username and password are required")
+
+	_, err = NewKafkaSink("json", "kafka:9092", "audit", WithTLSClientCert("cert.pem"))
+	require.ErrorContains(t, err, "client certificate and key must be supplied together")
+
+	s, err := NewKafkaSink("json", "kafka-1:9092, kafka-2:9092", "audit",
+		WithTLSEnabled("true"),
+		WithSASLMechanism("scram-sha-256"),
+		WithSASLUsername("vault"),
+		WithSASLPassword("secret"),
+		WithQueueSize("5"),
+	)
+	require.NoError(t, err)
+	require.Equal(t, eventlogger.NodeTypeSink, s.Type())
+	require.Equal(t, 5, cap(s.inFlight))
+
+	writer := s.writer.(*kafka.Writer)
+	require.Equal(t, "audit", writer.Topic)
+	transport := writer.Transport.(*kafka.Transport)
+	require.NotNil(t, transport.TLS)
+	require.NotNil(t, transport.SASL)
+	require.Equal(t, "SCRAM-SHA-256", transport.SASL.Name())
+}
+
+// TestKafkaSink_Process ensures that events are produced with the key returned
+// by the partition key func.
+func TestKafkaSink_Process(t *testing.T) {
+	s, err := NewKafkaSink("json", "kafka:9092", "audit", WithPartitionKeyFunc(func(e *eventlogger.Event) string {
+		key, _ := e.Payload.(string)
+		return key
+	}))
+	require.NoError(t, err)
+	writer := &fakeKafkaWriter{}
+	s.writer = writer
+
+	for _, key := range []string{"secret/", ""} {
+		e := &eventlogger.Event{
+			Type:      eventlogger.EventType(AuditType),
+			CreatedAt: time.Now(),
+			Formatted: make(map[string][]byte),
+			Payload:   key,
+		}
+		e.FormattedAs("json", []byte(`{"type":"request"}`))
+		_, err := s.Process(context.Background(), e)
+		require.NoError(t, err)
+	}
+
+	written := writer.written()
+	require.Len(t, written, 2)
+	require.Equal(t, []byte("secret/"), written[0].Key)
+	require.Nil(t, written[1].Key)
+	require.Equal(t, []byte(`{"type":"request"}`), written[0].Value)
+
+	_, err = s.Process(context.Background(), &eventlogger.Event{Formatted: make(map[string][]byte)})
+	require.ErrorContains(t, err, "unable to retrieve event formatted as \"json\"")
+}
+
+// TestKafkaSink_FailClosed ensures that events which can't be produced, or
+// which exceed the queue size, are returned as errors unless the sink fails
+// open.
+func TestKafkaSink_FailClosed(t *testing.T) {
+	e := &eventlogger.Event{Formatted: make(map[string][]byte)}
+	e.FormattedAs("json", []byte("{}"))
+
+	for _, failOpen := range []bool{false, true} {
+		failOpenValue := "false"
+		if failOpen {
+			failOpenValue = "true"
+		}
+		s, err := NewKafkaSink("json", "kafka:9092", "audit", WithQueueSize("1"), WithFailOpen(failOpenValue))
+		require.NoError(t, err)
+
+		writer := &fakeKafkaWriter{err: errors.New("brokers are unreachable")}
+		s.writer = writer
+		_, err = s.Process(context.Background(), e)
+		if failOpen {
+			require.NoError(t, err)
+		} else {
+			require.ErrorContains(t, err, "brokers are unreachable")
+		}
+
+		// While an event is being produced, the queue is full.
+		writer = &fakeKafkaWriter{started: make(chan struct{}), release: make(chan struct{})}
+		s.writer = writer
+		done := make(chan error)
+		go func() {
+			_, err := s.Process(context.Background(), e)
+			done <- err
+		}()
+		<-writer.started
+
+		_, err = s.Process(context.Background(), e)
+		if failOpen {
+			require.NoError(t, err)
+		} else {
+			require.ErrorIs(t, err, ErrKafkaBackPressure)
+		}
+
+		close(writer.release)
+		require.NoError(t, <-done)
+		require.Len(t, writer.written(), 1)
+		require.Equal(t, uint64(2), s.Dropped())
+	}
+}
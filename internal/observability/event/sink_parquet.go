@@ -0,0 +1,338 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// parquetFileExt is the extension of the files written by the ParquetSink.
+const parquetFileExt = ".parquet"
+
+// ParquetSink is a sink node which batches audit entries and writes each
+// batch as a new Parquet file in a directory, for loading into columnar
+// analytics stores such as BigQuery.
+// The event formatted as the sink's required format is expected to hold an
+// audit entry encoded as JSON, which the sink converts into a row.
+type ParquetSink struct {
+	dir            string
+	fileMode       os.FileMode
+	requiredFormat string
+	batchSize      int
+	flushInterval  time.Duration
+
+	lock  sync.Mutex
+	rows  []*parquetRow
+	timer *time.Timer
+	seq   uint64
+}
+
+// parquetRow is the schema of the files written by the ParquetSink. The most
+// commonly queried fields of the audit entry have their own column, while the
+// request and response are kept as JSON.
+type parquetRow struct {
+	Time          int64    `parquet:"name=time, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	Type          string   `parquet:"name=type, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Error         string   `parquet:"name=error, type=BYTE_ARRAY, convertedtype=UTF8"`
+	RequestID     string   `parquet:"name=request_id, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Operation     string   `parquet:"name=operation, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Path          string   `parquet:"name=path, type=BYTE_ARRAY, convertedtype=UTF8"`
+	NamespaceID   string   `parquet:"name=namespace_id, type=BYTE_ARRAY, convertedtype=UTF8"`
+	MountType     string   `parquet:"name=mount_type, type=BYTE_ARRAY, convertedtype=UTF8"`
+	MountAccessor string   `parquet:"name=mount_accessor, type=BYTE_ARRAY, convertedtype=UTF8"`
+	RemoteAddress string   `parquet:"name=remote_address, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ClientToken   string   `parquet:"name=client_token, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Accessor      string   `parquet:"name=accessor, type=BYTE_ARRAY, convertedtype=UTF8"`
+	DisplayName   string   `parquet:"name=display_name, type=BYTE_ARRAY, convertedtype=UTF8"`
+	EntityID      string   `parquet:"name=entity_id, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Policies      []string `parquet:"name=policies, type=LIST, valuetype=BYTE_ARRAY, valueconvertedtype=UTF8"`
+	Request       string   `parquet:"name=request, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Response      string   `parquet:"name=response, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+// parquetEntry holds the fields of a JSON audit entry that have their own
+// column.
+type parquetEntry struct {
+	Time  string `json:"time"`
+	Type  string `json:"type"`
+	Error string `json:"error"`
+	Auth  *struct {
+		ClientToken string   `json:"client_token"`
+		Accessor    string   `json:"accessor"`
+		DisplayName string   `json:"display_name"`
+		EntityID    string   `json:"entity_id"`
+		Policies    []string `json:"policies"`
+	} `json:"auth"`
+	Request  json.RawMessage `json:"request"`
+	Response json.RawMessage `json:"response"`
+}
+
+// parquetRequest holds the fields of the request of a JSON audit entry that
+// have their own column.
+type parquetRequest struct {
+	ID            string `json:"id"`
+	Operation     string `json:"operation"`
+	Path          string `json:"path"`
+	MountType     string `json:"mount_type"`
+	MountAccessor string `json:"mount_accessor"`
+	RemoteAddress string `json:"remote_address"`
+	Namespace     *struct {
+		ID string `json:"id"`
+	} `json:"namespace"`
+}
+
+// NewParquetSink should be used to create a new ParquetSink, writing files in
+// the directory at path.
+// Accepted options: WithFileMode, WithBatchSize and WithFlushInterval.
+func NewParquetSink(path string, format string, opt ...Option) (*ParquetSink, error) {
+	const op = "event.NewParquetSink"
+
+	p := strings.TrimSpace(path)
+	if p == "" {
+		return nil, fmt.Errorf("%s: path is required", op)
+	}
+
+	opts, err := getOpts(opt...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: error applying options: %w", op, err)
+	}
+
+	mode := os.FileMode(defaultFileMode)
+	if opts.withFileMode != nil && *opts.withFileMode != 0 {
+		mode = *opts.withFileMode
+	}
+
+	// Ensure that files can be written to the directory, as it will be too
+	// late to report it once the first batch is flushed.
+	if err := os.MkdirAll(p, 0o700); err != nil {
+		return nil, fmt.Errorf("%s: unable to create directory %q: %w", op, p, err)
+	}
+	f, err := os.CreateTemp(p, ".check-*")
+	if err != nil {
+		return nil, fmt.Errorf("%s: sanity check failed; unable to write to %q: %w", op, p, err)
+	}
+	f.Close()
+	os.Remove(f.Name())
+
+	return &ParquetSink{
+		dir:            p,
+		fileMode:       mode,
+		requiredFormat: format,
+		batchSize:      opts.withBatchSize,
+		flushInterval:  opts.withFlushInterval,
+	}, nil
+}
+
+// Process buffers the event, writing the buffered events to a new file once
+// there are as many as the batch size.
+func (s *ParquetSink) Process(ctx context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
+	const op = "event.(ParquetSink).Process"
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	if e == nil {
+		return nil, fmt.Errorf("%s: event is nil: %w", op, ErrInvalidParameter)
+	}
+
+	formatted, found := e.Format(s.requiredFormat)
+	if !found {
+		return nil, fmt.Errorf("%s: unable to retrieve event formatted as %q", op, s.requiredFormat)
+	}
+
+	row, err := newParquetRow(formatted, e.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("%s: unable to convert event: %w", op, err)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.rows = append(s.rows, row)
+	if len(s.rows) < s.batchSize {
+		if s.timer == nil {
+			s.timer = time.AfterFunc(s.flushInterval, s.flushOnInterval)
+		}
+		return nil, nil
+	}
+
+	if err := s.flush(); err != nil {
+		// The events are kept, retry writing them on the next interval
+		// unless more events are received first.
+		s.timer = time.AfterFunc(s.flushInterval, s.flushOnInterval)
+		return nil, fmt.Errorf("%s: error writing batch for sink: %w", op, err)
+	}
+
+	// return nil for the event to indicate the pipeline is complete.
+	return nil, nil
+}
+
+// Flush writes the buffered events to a new file.
+func (s *ParquetSink) Flush(_ context.Context) error {
+	const op = "event.(ParquetSink).Flush"
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.flush(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// Reopen writes the buffered events, so that no entry is held back while
+// the files are being rotated.
+func (s *ParquetSink) Reopen() error {
+	return s.Flush(context.Background())
+}
+
+// Type describes the type of this node (sink).
+func (_ *ParquetSink) Type() eventlogger.NodeType {
+	return eventlogger.NodeTypeSink
+}
+
+// flushOnInterval is called once the flush interval elapsed since an event
+// was buffered. When writing fails, the events are kept for the next attempt.
+func (s *ParquetSink) flushOnInterval() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.timer = nil
+	if err := s.flush(); err != nil && len(s.rows) > 0 {
+		s.timer = time.AfterFunc(s.flushInterval, s.flushOnInterval)
+	}
+}
+
+// flush writes the buffered events to a new file. The lock must be held
+// before calling this.
+func (s *ParquetSink) flush() error {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if len(s.rows) == 0 {
+		return nil
+	}
+
+	s.seq++
+	name := fmt.Sprintf("audit-%s-%d%s", time.Now().UTC().Format("20060102T150405.000Z"), s.seq, parquetFileExt)
+	if err := s.write(filepath.Join(s.dir, name)); err != nil {
+		return err
+	}
+
+	s.rows = nil
+	return nil
+}
+
+// write writes the buffered events to a temporary file, renamed to path once
+// it is complete so that incomplete files are never picked up by loaders.
+func (s *ParquetSink) write(path string) (retErr error) {
+	const op = "event.(ParquetSink).write"
+
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, s.fileMode)
+	if err != nil {
+		return fmt.Errorf("%s: unable to create file %q: %w", op, tmp, err)
+	}
+	defer func() {
+		if retErr != nil {
+			f.Close()
+			os.Remove(tmp)
+		}
+	}()
+
+	pw, err := writer.NewParquetWriterFromWriter(f, new(parquetRow), 1)
+	if err != nil {
+		return fmt.Errorf("%s: unable to create parquet writer: %w", op, err)
+	}
+	pw.CompressionType = parquet.CompressionCodec_SNAPPY
+
+	for _, row := range s.rows {
+		if err := pw.Write(row); err != nil {
+			return fmt.Errorf("%s: unable to write row: %w", op, err)
+		}
+	}
+	if err := pw.WriteStop(); err != nil {
+		return fmt.Errorf("%s: unable to complete file: %w", op, err)
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("%s: unable to sync file: %w", op, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("%s: unable to close file: %w", op, err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("%s: unable to rename file %q: %w", op, tmp, err)
+	}
+
+	return nil
+}
+
+// newParquetRow converts a JSON audit entry into a row. The event creation
+// time is used when the entry omits its time.
+func newParquetRow(data []byte, created time.Time) (*parquetRow, error) {
+	var entry parquetEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+
+	t := created
+	if entry.Time != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, entry.Time)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse time: %w", err)
+		}
+		t = parsed
+	}
+
+	row := &parquetRow{
+		Time:     t.UnixMilli(),
+		Type:     entry.Type,
+		Error:    entry.Error,
+		Request:  string(entry.Request),
+		Response: string(entry.Response),
+	}
+
+	if entry.Auth != nil {
+		row.ClientToken = entry.Auth.ClientToken
+		row.Accessor = entry.Auth.Accessor
+		row.DisplayName = entry.Auth.DisplayName
+		row.EntityID = entry.Auth.EntityID
+		row.Policies = entry.Auth.Policies
+	}
+
+	if len(entry.Request) > 0 {
+		var req parquetRequest
+		if err := json.Unmarshal(entry.Request, &req); err != nil {
+			return nil, fmt.Errorf("unable to parse request: %w", err)
+		}
+		row.RequestID = req.ID
+		row.Operation = req.Operation
+		row.Path = req.Path
+		row.MountType = req.MountType
+		row.MountAccessor = req.MountAccessor
+		row.RemoteAddress = req.RemoteAddress
+		if req.Namespace != nil {
+			row.NamespaceID = req.Namespace.ID
+		}
+	}
+
+	return row, nil
+}
@@ -0,0 +1,145 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package event
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testParquetEntry = `{"time":"2023-07-04T12:03:00.123Z","type":"request","auth":{"client_token":"hmac-sha256:abc","accessor":"hmac-sha256:def","display_name":"root","policies":["root"]},"request":{"id":"req-1","operation":"read","path":"secret/foo","mount_type":"kv","namespace":{"id":"root"},"remote_address":"127.0.0.1"}}`
+
+// newTestParquetEvent returns an event holding a JSON audit entry, formatted
+// as "parquet".
+func newTestParquetEvent(t *testing.T) *eventlogger.Event {
+	t.Helper()
+
+	e := &eventlogger.Event{
+		Type:      eventlogger.EventType(AuditType),
+		CreatedAt: time.Now(),
+		Formatted: make(map[string][]byte),
+	}
+	e.FormattedAs("parquet", []byte(testParquetEntry))
+	return e
+}
+
+// parquetFiles returns the parquet files written to dir.
+func parquetFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"+parquetFileExt))
+	require.NoError(t, err)
+	return files
+}
+
+// TestParquetSink_Type ensures that the node is a 'sink' type.
+func TestParquetSink_Type(t *testing.T) {
+	s, err := NewParquetSink(t.TempDir(), "parquet")
+	require.NoError(t, err)
+	require.NotNil(t, s)
+	require.Equal(t, eventlogger.NodeTypeSink, s.Type())
+}
+
+// TestNewParquetSink tests creation of a ParquetSink.
+func TestNewParquetSink(t *testing.T) {
+	_, err := NewParquetSink("   ", "parquet")
+	require.EqualError(t, err, "event.NewParquetSink: path is required")
+
+	_, err = NewParquetSink(t.TempDir(), "parquet", WithBatchSize("0"))
+	require.EqualError(t, err, "event.NewParquetSink: error applying options: batch size must be greater than zero")
+
+	dir := filepath.Join(t.TempDir(), "audit")
+	s, err := NewParquetSink(dir, "parquet", WithBatchSize("10"), WithFlushInterval("5s"), WithFileMode("0640"))
+	require.NoError(t, err)
+	require.Equal(t, dir, s.dir)
+	require.Equal(t, 10, s.batchSize)
+	require.Equal(t, 5*time.Second, s.flushInterval)
+	require.Equal(t, os.FileMode(0o640), s.fileMode)
+
+	// The directory is created, without leaving files behind.
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+// TestParquetSink_Process ensures that events are written once there are as
+// many as the batch size, once the flush interval elapsed, or when flushed.
+func TestParquetSink_Process(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewParquetSink(dir, "parquet", WithBatchSize("2"), WithFlushInterval("500ms"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	// A complete batch is written straight away.
+	for i := 0; i < 2; i++ {
+		_, err := s.Process(ctx, newTestParquetEvent(t))
+		require.NoError(t, err)
+	}
+	files := parquetFiles(t, dir)
+	require.Len(t, files, 1)
+
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(data, []byte("PAR1")))
+	require.True(t, bytes.HasSuffix(data, []byte("PAR1")))
+
+	// Incomplete batches are written once the flush interval elapsed.
+	_, err = s.Process(ctx, newTestParquetEvent(t))
+	require.NoError(t, err)
+	require.Len(t, parquetFiles(t, dir), 1)
+	require.Eventually(t, func() bool {
+		return len(parquetFiles(t, dir)) == 2
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// And when flushed.
+	_, err = s.Process(ctx, newTestParquetEvent(t))
+	require.NoError(t, err)
+	require.NoError(t, s.Flush(ctx))
+	require.Len(t, parquetFiles(t, dir), 3)
+
+	// Flushing without buffered events doesn't write a file.
+	require.NoError(t, s.Reopen())
+	require.Len(t, parquetFiles(t, dir), 3)
+
+	// Events must hold a JSON audit entry.
+	e := &eventlogger.Event{Formatted: make(map[string][]byte)}
+	e.FormattedAs("parquet", []byte("<xml/>"))
+	_, err = s.Process(ctx, e)
+	require.Error(t, err)
+}
+
+// TestParquetSink_newParquetRow ensures that audit entries are converted into
+// rows as expected.
+func TestParquetSink_newParquetRow(t *testing.T) {
+	created := time.Date(2023, time.July, 4, 13, 0, 0, 0, time.UTC)
+
+	row, err := newParquetRow([]byte(testParquetEntry), created)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2023, time.July, 4, 12, 3, 0, 123000000, time.UTC).UnixMilli(), row.Time)
+	require.Equal(t, "request", row.Type)
+	require.Equal(t, "req-1", row.RequestID)
+	require.Equal(t, "read", row.Operation)
+	require.Equal(t, "secret/foo", row.Path)
+	require.Equal(t, "kv", row.MountType)
+	require.Equal(t, "root", row.NamespaceID)
+	require.Equal(t, "127.0.0.1", row.RemoteAddress)
+	require.Equal(t, "hmac-sha256:abc", row.ClientToken)
+	require.Equal(t, []string{"root"}, row.Policies)
+	require.Contains(t, row.Request, `"path":"secret/foo"`)
+	require.Empty(t, row.Response)
+
+	// The creation time of the event is used when the entry omits its time.
+	row, err = newParquetRow([]byte(`{"type":"response"}`), created)
+	require.NoError(t, err)
+	require.Equal(t, created.UnixMilli(), row.Time)
+}
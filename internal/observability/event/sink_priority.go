@@ -0,0 +1,206 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package event
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hashicorp/eventlogger"
+	"golang.org/x/time/rate"
+)
+
+// Priority is the delivery priority class of an event. When events of several
+// classes share a sink, events of a higher class are processed ahead of the
+// events of lower classes waiting for the sink.
+type Priority int
+
+const (
+	PriorityInformational Priority = iota // PriorityInformational is the class of events which are only of interest to subscribers
+	PriorityOperational                   // PriorityOperational is the class of events reporting on the operation of Vault
+	PriorityAudit                         // PriorityAudit is the class of audit events, which are never rate limited
+)
+
+// numPriorities is the number of priority classes.
+const numPriorities = int(PriorityAudit) + 1
+
+// String returns the name of the priority class.
+func (p Priority) String() string {
+	switch p {
+	case PriorityInformational:
+		return "informational"
+	case PriorityOperational:
+		return "operational"
+	case PriorityAudit:
+		return "audit"
+	default:
+		return fmt.Sprintf("priority(%d)", int(p))
+	}
+}
+
+// validate ensures that the priority is one of the priority classes.
+func (p Priority) validate() error {
+	const op = "event.(Priority).validate"
+	if p < PriorityInformational || p > PriorityAudit {
+		return fmt.Errorf("%s: '%d' is not a valid priority: %w", op, p, ErrInvalidParameter)
+	}
+	return nil
+}
+
+// PriorityOf returns the priority class of events of the type: audit events
+// belong to PriorityAudit, and other events to PriorityInformational.
+func PriorityOf(e *eventlogger.Event) Priority {
+	if e != nil && e.Type == eventlogger.EventType(AuditType) {
+		return PriorityAudit
+	}
+	return PriorityInformational
+}
+
+// PrioritySink is a sink node wrapping another sink, which it feeds with
+// events in order of their priority class, and which drops the operational
+// and informational events exceeding their class' rate limit. Audit events are
+// never rate limited, so that a flood of lower priority events can never delay
+// or prevent their delivery.
+type PrioritySink struct {
+	sink     eventlogger.Node
+	priority func(*eventlogger.Event) Priority
+	limiters [numPriorities]*rate.Limiter
+	dropped  [numPriorities]atomic.Uint64
+
+	lock    sync.Mutex
+	cond    *sync.Cond
+	busy    bool
+	waiting [numPriorities]int
+}
+
+var _ eventlogger.Node = (*PrioritySink)(nil)
+
+// NewPrioritySink should be used to create a new PrioritySink wrapping the
+// sink. Events are classified with PriorityOf unless a priority func is
+// supplied.
+// Accepted options: WithPriorityFunc, WithOperationalRateLimit and
+// WithInformationalRateLimit.
+func NewPrioritySink(sink eventlogger.Node, opt ...Option) (*PrioritySink, error) {
+	const op = "event.NewPrioritySink"
+
+	if sink == nil {
+		return nil, fmt.Errorf("%s: sink is required: %w", op, ErrInvalidParameter)
+	}
+
+	opts, err := getOpts(opt...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: error applying options: %w", op, err)
+	}
+
+	s := &PrioritySink{
+		sink:     sink,
+		priority: opts.withPriorityFunc,
+	}
+	if s.priority == nil {
+		s.priority = PriorityOf
+	}
+	s.limiters[PriorityOperational] = newRateLimiter(opts.withOperationalRateLimit)
+	s.limiters[PriorityInformational] = newRateLimiter(opts.withInformationalRateLimit)
+	s.cond = sync.NewCond(&s.lock)
+
+	return s, nil
+}
+
+// newRateLimiter returns a limiter allowing the number of events per second,
+// or nil when events are not limited.
+func newRateLimiter(limit float64) *rate.Limiter {
+	if limit <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(limit), int(math.Max(1, math.Ceil(limit))))
+}
+
+// Process hands the event to the wrapped sink once no event of a higher
+// priority class is waiting for it. Events exceeding the rate limit of their
+// class are dropped.
+func (s *PrioritySink) Process(ctx context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
+	const op = "event.(PrioritySink).Process"
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	if e == nil {
+		return nil, fmt.Errorf("%s: event is nil: %w", op, ErrInvalidParameter)
+	}
+
+	p := s.priority(e)
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if limiter := s.limiters[p]; limiter != nil && !limiter.Allow() {
+		s.dropped[p].Add(1)
+		// return nil for the event to indicate the pipeline is complete.
+		return nil, nil
+	}
+
+	s.acquire(p)
+	defer s.release()
+
+	return s.sink.Process(ctx, e)
+}
+
+// acquire blocks until the wrapped sink is free and no event of a higher
+// priority class is waiting for it.
+func (s *PrioritySink) acquire(p Priority) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.waiting[p]++
+	for s.busy || s.higherWaiting(p) {
+		s.cond.Wait()
+	}
+	s.waiting[p]--
+	s.busy = true
+}
+
+// release frees the wrapped sink for the next waiting event.
+func (s *PrioritySink) release() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.busy = false
+	s.cond.Broadcast()
+}
+
+// higherWaiting returns whether events of a higher priority class than p are
+// waiting for the sink. The lock must be held before calling this.
+func (s *PrioritySink) higherWaiting(p Priority) bool {
+	for q := int(p) + 1; q < numPriorities; q++ {
+		if s.waiting[q] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Dropped returns the number of events of the priority class which were
+// dropped because they exceeded the rate limit of their class.
+func (s *PrioritySink) Dropped(p Priority) uint64 {
+	if p.validate() != nil {
+		return 0
+	}
+	return s.dropped[p].Load()
+}
+
+// Reopen reopens the wrapped sink.
+func (s *PrioritySink) Reopen() error {
+	return s.sink.Reopen()
+}
+
+// Type describes the type of this node (sink).
+func (_ *PrioritySink) Type() eventlogger.NodeType {
+	return eventlogger.NodeTypeSink
+}
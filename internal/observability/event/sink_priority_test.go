@@ -0,0 +1,131 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package event
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSink records the events it processes, blocking on each of them
+// until release is closed when it is set.
+type recordingSink struct {
+	NoopSink
+	lock    sync.Mutex
+	events  []*eventlogger.Event
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *recordingSink) Process(_ context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
+	if s.started != nil {
+		s.started <- struct{}{}
+		<-s.release
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.events = append(s.events, e)
+	return nil, nil
+}
+
+func (s *recordingSink) processed() []*eventlogger.Event {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.events
+}
+
+// TestNewPrioritySink tests creation of a PrioritySink.
+func TestNewPrioritySink(t *testing.T) {
+	_, err := NewPrioritySink(nil)
+	require.EqualError(t, err, "event.NewPrioritySink: sink is required: invalid parameter")
+
+	_, err = NewPrioritySink(&NoopSink{}, WithInformationalRateLimit("-1"))
+	require.EqualError(t, err, "event.NewPrioritySink: error applying options: rate limit cannot be negative")
+
+	s, err := NewPrioritySink(&NoopSink{}, WithOperationalRateLimit("10"))
+	require.NoError(t, err)
+	require.Equal(t, eventlogger.NodeTypeSink, s.Type())
+	require.NotNil(t, s.limiters[PriorityOperational])
+	require.Nil(t, s.limiters[PriorityInformational])
+	require.Nil(t, s.limiters[PriorityAudit])
+}
+
+// TestPrioritySink_RateLimit ensures that events exceeding the rate limit of
+// their class are dropped, and that audit events are never dropped.
+func TestPrioritySink_RateLimit(t *testing.T) {
+	sink := &recordingSink{}
+	s, err := NewPrioritySink(sink, WithInformationalRateLimit("1"))
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		_, err := s.Process(context.Background(), &eventlogger.Event{Type: "kv"})
+		require.NoError(t, err)
+		_, err = s.Process(context.Background(), &eventlogger.Event{Type: eventlogger.EventType(AuditType)})
+		require.NoError(t, err)
+	}
+
+	require.Len(t, sink.processed(), 6)
+	require.Equal(t, uint64(4), s.Dropped(PriorityInformational))
+	require.Zero(t, s.Dropped(PriorityAudit))
+}
+
+// TestPrioritySink_Order ensures that waiting events of a higher priority
+// class are processed first.
+func TestPrioritySink_Order(t *testing.T) {
+	sink := &recordingSink{
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	s, err := NewPrioritySink(sink)
+	require.NoError(t, err)
+
+	process := func(e *eventlogger.Event) {
+		_, err := s.Process(context.Background(), e)
+		require.NoError(t, err)
+	}
+	waiting := func(p Priority) int {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		return s.waiting[p]
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		process(&eventlogger.Event{Type: "first"})
+	}()
+	<-sink.started
+
+	// While the sink is busy, an informational event then an audit event wait
+	// for it.
+	go func() {
+		defer wg.Done()
+		process(&eventlogger.Event{Type: "second"})
+	}()
+	require.Eventually(t, func() bool { return waiting(PriorityInformational) == 1 }, 5*time.Second, 10*time.Millisecond)
+	go func() {
+		defer wg.Done()
+		process(&eventlogger.Event{Type: eventlogger.EventType(AuditType)})
+	}()
+	require.Eventually(t, func() bool { return waiting(PriorityAudit) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	close(sink.release)
+	for i := 0; i < 2; i++ {
+		<-sink.started
+	}
+	wg.Wait()
+
+	processed := sink.processed()
+	require.Len(t, processed, 3)
+	require.Equal(t, eventlogger.EventType("first"), processed[0].Type)
+	require.Equal(t, eventlogger.EventType(AuditType), processed[1].Type)
+	require.Equal(t, eventlogger.EventType("second"), processed[2].Type)
+}
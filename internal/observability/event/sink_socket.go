@@ -5,8 +5,16 @@ package event
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"net"
+	"os"
+	"os/user"
+	"runtime"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -23,10 +31,28 @@ type SocketSink struct {
 	maxDuration    time.Duration
 	socketLock     sync.RWMutex
 	connection     net.Conn
+
+	// tlsConfig is set with the "tls" socket type.
+	tlsConfig *tls.Config
+
+	// localAddress is the address UNIX sockets are bound to, if any. When it
+	// is the path of a socket file, the file is created with the fileMode,
+	// when not nil, and owned by the uid and gid, when not -1.
+	localAddress string
+	fileMode     *os.FileMode
+	uid          int
+	gid          int
 }
 
+// socketTypeTLS is the socket type of TCP connections secured with TLS.
+const socketTypeTLS = "tls"
+
 // NewSocketSink should be used to create a new SocketSink.
-// Accepted options: WithMaxDuration and WithSocketType.
+// Accepted options: WithMaxDuration, WithSocketType, with the "tls" socket
+// type, WithTLSCACert, WithTLSClientCert, WithTLSClientKey and
+// WithTLSServerName, and, with the "unix", "unixgram" and "unixpacket"
+// socket types, WithSocketLocalAddress, WithFileMode and WithSocketOwner.
+// Addresses starting with "@" name Linux abstract sockets.
 func NewSocketSink(format string, address string, opt ...Option) (*SocketSink, error) {
 	const op = "event.NewSocketSink"
 
@@ -44,9 +70,166 @@ func NewSocketSink(format string, address string, opt ...Option) (*SocketSink, e
 		connection:     nil,
 	}
 
+	switch {
+	case sink.socketType == socketTypeTLS:
+		sink.tlsConfig, err = newSocketTLSConfig(address, opts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: error configuring TLS: %w", op, err)
+		}
+	case isUnixSocketType(sink.socketType):
+		if err := sink.configureUnix(opts); err != nil {
+			return nil, fmt.Errorf("%s: error configuring UNIX socket: %w", op, err)
+		}
+	case opts.withSocketLocalAddress != "" || opts.withSocketOwner != "":
+		return nil, fmt.Errorf("%s: local address and owner are only supported by UNIX socket types: %w", op, ErrInvalidParameter)
+	}
+
 	return sink, nil
 }
 
+// isUnixSocketType returns whether the socket type is a UNIX domain socket
+// type.
+func isUnixSocketType(socketType string) bool {
+	switch socketType {
+	case "unix", "unixgram", "unixpacket":
+		return true
+	default:
+		return false
+	}
+}
+
+// isAbstractSocketAddress returns whether the UNIX socket address names a
+// Linux abstract socket, which has no socket file.
+func isAbstractSocketAddress(address string) bool {
+	return strings.HasPrefix(address, "@")
+}
+
+// configureUnix configures the local address of a UNIX socket sink, and the
+// mode and owner of its socket file.
+func (s *SocketSink) configureUnix(opts options) error {
+	if runtime.GOOS != "linux" && (isAbstractSocketAddress(s.address) || isAbstractSocketAddress(opts.withSocketLocalAddress)) {
+		return fmt.Errorf("abstract sockets are only supported on Linux: %w", ErrInvalidParameter)
+	}
+
+	s.localAddress = opts.withSocketLocalAddress
+	s.uid, s.gid = -1, -1
+
+	if s.localAddress == "" || isAbstractSocketAddress(s.localAddress) {
+		if opts.withFileMode != nil || opts.withSocketOwner != "" {
+			return fmt.Errorf("the mode and owner of the socket file require a local socket file address: %w", ErrInvalidParameter)
+		}
+		return nil
+	}
+
+	if opts.withFileMode != nil && *opts.withFileMode != 0 {
+		s.fileMode = opts.withFileMode
+	}
+
+	if opts.withSocketOwner != "" {
+		var err error
+		s.uid, s.gid, err = parseSocketOwner(opts.withSocketOwner)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseSocketOwner parses the owner of a socket file, as "user" or
+// "user:group", where the user and group are either names or numeric IDs,
+// into the IDs of the user and group. Either may be omitted, in which case
+// its ID is -1.
+func parseSocketOwner(owner string) (int, int, error) {
+	userName, groupName, _ := strings.Cut(owner, ":")
+
+	uid := -1
+	if userName != "" {
+		id, err := strconv.Atoi(userName)
+		if err != nil {
+			u, err := user.Lookup(userName)
+			if err != nil {
+				return 0, 0, fmt.Errorf("unable to look up user %q: %w", userName, err)
+			}
+			id, err = strconv.Atoi(u.Uid)
+			if err != nil {
+				return 0, 0, fmt.Errorf("user %q has no numeric ID", userName)
+			}
+		}
+		uid = id
+	}
+
+	gid := -1
+	if groupName != "" {
+		id, err := strconv.Atoi(groupName)
+		if err != nil {
+			g, err := user.LookupGroup(groupName)
+			if err != nil {
+				return 0, 0, fmt.Errorf("unable to look up group %q: %w", groupName, err)
+			}
+			id, err = strconv.Atoi(g.Gid)
+			if err != nil {
+				return 0, 0, fmt.Errorf("group %q has no numeric ID", groupName)
+			}
+		}
+		gid = id
+	}
+
+	return uid, gid, nil
+}
+
+// newSocketTLSConfig returns the TLS configuration of the connections of a
+// "tls" socket sink to the address.
+func newSocketTLSConfig(address string, opts options) (*tls.Config, error) {
+	config, err := newTLSConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.ServerName == "" {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse address %q: %w", address, err)
+		}
+		config.ServerName = host
+	}
+
+	return config, nil
+}
+
+// newTLSConfig returns the TLS configuration of the connections of a sink,
+// from its TLS options.
+func newTLSConfig(opts options) (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: opts.withTLSServerName,
+	}
+
+	if opts.withTLSCACert != "" {
+		pem, err := os.ReadFile(opts.withTLSCACert)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read CA certificate: %w", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA certificate %q", opts.withTLSCACert)
+		}
+	}
+
+	switch {
+	case opts.withTLSClientCert != "" && opts.withTLSClientKey != "":
+		cert, err := tls.LoadX509KeyPair(opts.withTLSClientCert, opts.withTLSClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	case opts.withTLSClientCert != "" || opts.withTLSClientKey != "":
+		return nil, errors.New("client certificate and key must be supplied together")
+	}
+
+	return config, nil
+}
+
 // Process handles writing the event to the socket.
 func (s *SocketSink) Process(ctx context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
 	const op = "event.(SocketSink).Process"
@@ -101,7 +284,7 @@ func (s *SocketSink) Reopen() error {
 	s.socketLock.Lock()
 	defer s.socketLock.Unlock()
 
-	err := s.reconnect(nil)
+	err := s.reconnect(context.Background())
 	if err != nil {
 		return fmt.Errorf("%s: error reconnecting: %w", op, err)
 	}
@@ -126,8 +309,18 @@ func (s *SocketSink) connect(ctx context.Context) error {
 	timeoutContext, cancel := context.WithTimeout(ctx, s.maxDuration)
 	defer cancel()
 
-	dialer := net.Dialer{}
-	conn, err := dialer.DialContext(timeoutContext, s.socketType, s.address)
+	var conn net.Conn
+	var err error
+	switch {
+	case s.socketType == socketTypeTLS:
+		dialer := tls.Dialer{Config: s.tlsConfig}
+		conn, err = dialer.DialContext(timeoutContext, "tcp", s.address)
+	case s.localAddress != "":
+		conn, err = s.dialUnix()
+	default:
+		dialer := net.Dialer{}
+		conn, err = dialer.DialContext(timeoutContext, s.socketType, s.address)
+	}
 	if err != nil {
 		return fmt.Errorf("%s: error connecting to %q address %q: %w", op, s.socketType, s.address, err)
 	}
@@ -152,6 +345,68 @@ func (s *SocketSink) disconnect() error {
 	}
 	s.connection = nil
 
+	if s.localAddress != "" && !isAbstractSocketAddress(s.localAddress) {
+		if err := removeSocketFile(s.localAddress); err != nil {
+			return fmt.Errorf("%s: error removing socket file: %w", op, err)
+		}
+	}
+
+	return nil
+}
+
+// dialUnix connects to the address from a UNIX socket bound to the local
+// address. Its socket file, which replaces any socket file left behind, is
+// given the configured mode and owner.
+func (s *SocketSink) dialUnix() (net.Conn, error) {
+	socketFile := !isAbstractSocketAddress(s.localAddress)
+	if socketFile {
+		if err := removeSocketFile(s.localAddress); err != nil {
+			return nil, err
+		}
+	}
+
+	conn, err := net.DialUnix(s.socketType,
+		&net.UnixAddr{Name: s.localAddress, Net: s.socketType},
+		&net.UnixAddr{Name: s.address, Net: s.socketType})
+	if err != nil {
+		return nil, err
+	}
+	if !socketFile {
+		return conn, nil
+	}
+
+	if s.fileMode != nil {
+		err = os.Chmod(s.localAddress, *s.fileMode)
+	}
+	if err == nil && (s.uid != -1 || s.gid != -1) {
+		err = os.Chown(s.localAddress, s.uid, s.gid)
+	}
+	if err != nil {
+		_ = conn.Close()
+		_ = os.Remove(s.localAddress)
+		return nil, fmt.Errorf("unable to set the permissions of socket file %q: %w", s.localAddress, err)
+	}
+
+	return conn, nil
+}
+
+// removeSocketFile removes the socket file at the path, if any. Files which
+// aren't sockets are left alone.
+func removeSocketFile(path string) error {
+	info, err := os.Lstat(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil
+	case err != nil:
+		return err
+	case info.Mode().Type() != os.ModeSocket:
+		return fmt.Errorf("%q exists and is not a socket", path)
+	}
+
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
 	return nil
 }
 
@@ -187,6 +442,12 @@ func (s *SocketSink) write(ctx context.Context, data []byte) error {
 		return fmt.Errorf("%s: unable to set write deadline: %w", op, err)
 	}
 
+	// Messages sent over TLS are framed with their length, as RFC 5425
+	// requires for syslog.
+	if s.socketType == socketTypeTLS {
+		data = append([]byte(strconv.Itoa(len(data))+" "), data...)
+	}
+
 	_, err = s.connection.Write(data)
 	if err != nil {
 		return fmt.Errorf("%s: unable to write to socket: %w", op, err)
@@ -0,0 +1,257 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package event
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestCertificate writes a self-signed certificate for 127.0.0.1 and
+// its key to dir, returning their paths.
+func writeTestCertificate(t *testing.T, dir string) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "audit.example.com"},
+		DNSNames:              []string{"audit.example.com"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certPath, keyPath
+}
+
+// TestNewSocketSink_TLS tests creation of a SocketSink with the "tls" socket
+// type.
+func TestNewSocketSink_TLS(t *testing.T) {
+	dir := t.TempDir()
+	certPath, keyPath := writeTestCertificate(t, dir)
+
+	_, err := NewSocketSink("json", "127.0.0.1:6514", WithSocketType("tls"), WithTLSCACert(filepath.Join(dir, "missing.pem")))
+	require.ErrorContains(t, err, "unable to read CA certificate")
+
+	_, err = NewSocketSink("json", "127.0.0.1:6514", WithSocketType("tls"), WithTLSClientCert(certPath))
+	require.ErrorContains(t, err, "client certificate and key must be supplied together")
+
+	_, err = NewSocketSink("json", "collector", WithSocketType("tls"))
+	require.ErrorContains(t, err, "unable to parse address")
+
+	s, err := NewSocketSink("json", "127.0.0.1:6514", WithSocketType("tls"),
+		WithTLSCACert(certPath), WithTLSClientCert(certPath), WithTLSClientKey(keyPath))
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.1", s.tlsConfig.ServerName)
+	require.Len(t, s.tlsConfig.Certificates, 1)
+
+	s, err = NewSocketSink("json", "127.0.0.1:6514", WithSocketType("tls"), WithTLSServerName("audit.example.com"))
+	require.NoError(t, err)
+	require.Equal(t, "audit.example.com", s.tlsConfig.ServerName)
+
+	// Other socket types don't use TLS.
+	s, err = NewSocketSink("json", "127.0.0.1:514", WithTLSCACert(certPath))
+	require.NoError(t, err)
+	require.Nil(t, s.tlsConfig)
+}
+
+// TestSocketSink_Process_TLS ensures that events are sent over TLS, framed
+// with their length.
+func TestSocketSink_Process_TLS(t *testing.T) {
+	certPath, keyPath := writeTestCertificate(t, t.TempDir())
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	require.NoError(t, err)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	})
+	require.NoError(t, err)
+	defer listener.Close()
+
+	received := make(chan string, 2)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		for {
+			length, err := r.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, err := strconv.Atoi(length[:len(length)-1])
+			if err != nil {
+				return
+			}
+			msg := make([]byte, n)
+			if _, err := io.ReadFull(r, msg); err != nil {
+				return
+			}
+			received <- string(msg)
+		}
+	}()
+
+	s, err := NewSocketSink("json", listener.Addr().String(), WithSocketType("tls"), WithTLSCACert(certPath))
+	require.NoError(t, err)
+
+	for _, msg := range []string{`{"type":"request"}`, `{"type":"response"}`} {
+		e := &eventlogger.Event{Formatted: make(map[string][]byte)}
+		e.FormattedAs("json", []byte(msg))
+		_, err = s.Process(context.Background(), e)
+		require.NoError(t, err)
+
+		select {
+		case got := <-received:
+			require.Equal(t, msg, got)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the message")
+		}
+	}
+}
+
+// TestNewSocketSink_Unix tests creation of a SocketSink with the UNIX socket
+// types.
+func TestNewSocketSink_Unix(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := NewSocketSink("json", "127.0.0.1:514", WithSocketLocalAddress(filepath.Join(dir, "vault.sock")))
+	require.ErrorIs(t, err, ErrInvalidParameter)
+
+	_, err = NewSocketSink("json", filepath.Join(dir, "collector.sock"), WithSocketType("unixgram"), WithFileMode("0600"))
+	require.ErrorIs(t, err, ErrInvalidParameter)
+
+	_, err = NewSocketSink("json", filepath.Join(dir, "collector.sock"), WithSocketType("unixgram"),
+		WithSocketLocalAddress(filepath.Join(dir, "vault.sock")), WithSocketOwner("no-such-user-exists"))
+	require.ErrorContains(t, err, "unable to look up user")
+
+	s, err := NewSocketSink("json", filepath.Join(dir, "collector.sock"), WithSocketType("unixgram"),
+		WithSocketLocalAddress(filepath.Join(dir, "vault.sock")), WithFileMode("0660"), WithSocketOwner(":"+strconv.Itoa(os.Getgid())))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o660), *s.fileMode)
+	require.Equal(t, -1, s.uid)
+	require.Equal(t, os.Getgid(), s.gid)
+}
+
+// TestSocketSink_Process_Unixgram ensures that events are sent as datagrams
+// from the socket file the sink creates.
+func TestSocketSink_Process_Unixgram(t *testing.T) {
+	dir := t.TempDir()
+	collectorPath := filepath.Join(dir, "collector.sock")
+	localPath := filepath.Join(dir, "vault.sock")
+
+	collector, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: collectorPath, Net: "unixgram"})
+	require.NoError(t, err)
+	defer collector.Close()
+
+	// A socket file left behind is replaced, but other files aren't.
+	require.NoError(t, os.WriteFile(localPath, nil, 0o600))
+	s, err := NewSocketSink("json", collectorPath, WithSocketType("unixgram"),
+		WithSocketLocalAddress(localPath), WithFileMode("0640"))
+	require.NoError(t, err)
+	e := &eventlogger.Event{Formatted: make(map[string][]byte)}
+	e.FormattedAs("json", []byte(`{"type":"request"}`))
+	_, err = s.Process(context.Background(), e)
+	require.ErrorContains(t, err, "exists and is not a socket")
+	require.NoError(t, os.Remove(localPath))
+
+	for _, msg := range []string{`{"type":"request"}`, `{"type":"response"}`} {
+		e := &eventlogger.Event{Formatted: make(map[string][]byte)}
+		e.FormattedAs("json", []byte(msg))
+		_, err = s.Process(context.Background(), e)
+		require.NoError(t, err)
+
+		require.NoError(t, collector.SetReadDeadline(time.Now().Add(5*time.Second)))
+		buf := make([]byte, 1024)
+		n, addr, err := collector.ReadFromUnix(buf)
+		require.NoError(t, err)
+		require.Equal(t, msg, string(buf[:n]))
+		require.Equal(t, localPath, addr.Name)
+	}
+
+	info, err := os.Stat(localPath)
+	require.NoError(t, err)
+	require.Equal(t, os.ModeSocket, info.Mode().Type())
+	require.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+
+	// Reopening recreates the socket file.
+	require.NoError(t, s.Reopen())
+	_, err = os.Stat(localPath)
+	require.NoError(t, err)
+
+	s.socketLock.Lock()
+	require.NoError(t, s.disconnect())
+	s.socketLock.Unlock()
+	_, err = os.Stat(localPath)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+// TestSocketSink_Process_Abstract ensures that events are sent to and from
+// Linux abstract sockets.
+func TestSocketSink_Process_Abstract(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		_, err := NewSocketSink("json", "@vault-audit-collector", WithSocketType("unixgram"))
+		require.ErrorIs(t, err, ErrInvalidParameter)
+		return
+	}
+
+	collectorName := "@vault-audit-collector-" + strconv.Itoa(os.Getpid())
+	localName := "@vault-audit-" + strconv.Itoa(os.Getpid())
+	collector, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: collectorName, Net: "unixgram"})
+	require.NoError(t, err)
+	defer collector.Close()
+
+	s, err := NewSocketSink("json", collectorName, WithSocketType("unixgram"), WithSocketLocalAddress(localName))
+	require.NoError(t, err)
+
+	e := &eventlogger.Event{Formatted: make(map[string][]byte)}
+	e.FormattedAs("json", []byte(`{"type":"request"}`))
+	_, err = s.Process(context.Background(), e)
+	require.NoError(t, err)
+
+	require.NoError(t, collector.SetReadDeadline(time.Now().Add(5*time.Second)))
+	buf := make([]byte, 1024)
+	n, addr, err := collector.ReadFromUnix(buf)
+	require.NoError(t, err)
+	require.Equal(t, `{"type":"request"}`, string(buf[:n]))
+	require.Equal(t, localName, addr.Name)
+}
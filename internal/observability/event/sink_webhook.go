@@ -0,0 +1,290 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package event
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+)
+
+const (
+	defaultWebhookMinBackoff = 250 * time.Millisecond
+	defaultWebhookMaxBackoff = 30 * time.Second
+)
+
+// ErrWebhookQueueFull is returned when an event is processed while the queue
+// of a WebhookSink is full.
+var ErrWebhookQueueFull = errors.New("webhook queue is full")
+
+// WebhookSink is a sink node which POSTs events to an HTTP(S) endpoint, such
+// as the webhook of a SIEM. Events are queued in memory, in a queue of bounded
+// size, and delivered in order by a background worker which retries failed
+// deliveries with an exponential backoff.
+type WebhookSink struct {
+	url            string
+	requiredFormat string
+	contentType    string
+	bearerToken    string
+	client         *http.Client
+	maxRetries     int
+	minBackoff     time.Duration
+	maxBackoff     time.Duration
+
+	queue   chan []byte
+	stop    chan struct{}
+	stopped chan struct{}
+	dropped atomic.Uint64
+
+	lock    sync.Mutex
+	closed  bool
+	pending int
+	drained chan struct{}
+}
+
+// NewWebhookSink should be used to create a new WebhookSink, posting events
+// to the URL.
+// Accepted options: WithBearerToken, WithQueueSize, WithMaxRetries,
+// WithMaxDuration, WithTLSCACert, WithTLSClientCert, WithTLSClientKey and
+// WithTLSServerName.
+func NewWebhookSink(format string, address string, opt ...Option) (*WebhookSink, error) {
+	const op = "event.NewWebhookSink"
+
+	address = strings.TrimSpace(address)
+	parsed, err := url.Parse(address)
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("%s: unable to parse URL: %w", op, err)
+	case parsed.Scheme != "http" && parsed.Scheme != "https", parsed.Host == "":
+		return nil, fmt.Errorf("%s: URL must be an absolute http or https URL: %w", op, ErrInvalidParameter)
+	}
+
+	opts, err := getOpts(opt...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: error applying options: %w", op, err)
+	}
+
+	tlsConfig, err := newTLSConfig(opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: error configuring TLS: %w", op, err)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	contentType := "application/json"
+	if format == "jsonx" {
+		contentType = "application/xml"
+	}
+
+	s := &WebhookSink{
+		url:            parsed.String(),
+		requiredFormat: format,
+		contentType:    contentType,
+		bearerToken:    opts.withBearerToken,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   opts.withMaxDuration,
+			// Redirects aren't followed, so that events and the bearer token
+			// are only ever sent to the configured endpoint.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		maxRetries: opts.withMaxRetries,
+		minBackoff: defaultWebhookMinBackoff,
+		maxBackoff: defaultWebhookMaxBackoff,
+		queue:      make(chan []byte, opts.withQueueSize),
+		stop:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+		drained:    make(chan struct{}),
+	}
+	close(s.drained)
+
+	go s.run()
+
+	return s, nil
+}
+
+// Process queues the event to be posted to the endpoint. An error is returned
+// when the queue is full.
+func (s *WebhookSink) Process(ctx context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
+	const op = "event.(WebhookSink).Process"
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	if e == nil {
+		return nil, fmt.Errorf("%s: event is nil: %w", op, ErrInvalidParameter)
+	}
+
+	formatted, found := e.Format(s.requiredFormat)
+	if !found {
+		return nil, fmt.Errorf("%s: unable to retrieve event formatted as %q", op, s.requiredFormat)
+	}
+	// The formatted event may be reused once it has been processed.
+	body := append([]byte(nil), formatted...)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return nil, fmt.Errorf("%s: sink is closed", op)
+	}
+
+	select {
+	case s.queue <- body:
+		if s.pending == 0 {
+			s.drained = make(chan struct{})
+		}
+		s.pending++
+	default:
+		s.dropped.Add(1)
+		return nil, fmt.Errorf("%s: %w", op, ErrWebhookQueueFull)
+	}
+
+	// return nil for the event to indicate the pipeline is complete.
+	return nil, nil
+}
+
+// Flush blocks until the queued events have been delivered, or dropped after
+// exhausting their retries, or until the context is done.
+func (s *WebhookSink) Flush(ctx context.Context) error {
+	s.lock.Lock()
+	drained := s.drained
+	s.lock.Unlock()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close flushes the queued events, then stops the sink. Events processed once
+// the sink is closed are rejected.
+func (s *WebhookSink) Close(ctx context.Context) error {
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.lock.Unlock()
+
+	err := s.Flush(ctx)
+	close(s.stop)
+	<-s.stopped
+	return err
+}
+
+// Dropped returns the number of events which were dropped, because the queue
+// was full or their delivery failed after exhausting their retries.
+func (s *WebhookSink) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Reopen is a no-op for the webhook sink.
+func (_ *WebhookSink) Reopen() error {
+	return nil
+}
+
+// Type describes the type of this node (sink).
+func (_ *WebhookSink) Type() eventlogger.NodeType {
+	return eventlogger.NodeTypeSink
+}
+
+// run delivers the queued events until the sink is stopped.
+func (s *WebhookSink) run() {
+	defer close(s.stopped)
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case body := <-s.queue:
+			if err := s.deliver(body); err != nil {
+				s.dropped.Add(1)
+			}
+
+			s.lock.Lock()
+			s.pending--
+			if s.pending == 0 {
+				close(s.drained)
+			}
+			s.lock.Unlock()
+		}
+	}
+}
+
+// deliver posts the event to the endpoint, retrying with an exponential
+// backoff when the endpoint can't be reached, or responds with a server error
+// or asks to retry later.
+func (s *WebhookSink) deliver(body []byte) error {
+	backoff := s.minBackoff
+	var err error
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		retry, err = s.post(body)
+		if err == nil || !retry || attempt >= s.maxRetries {
+			return err
+		}
+
+		select {
+		case <-s.stop:
+			return err
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > s.maxBackoff {
+			backoff = s.maxBackoff
+		}
+	}
+}
+
+// post makes a single attempt at posting the event to the endpoint,
+// returning whether a failed attempt should be retried.
+func (s *WebhookSink) post(body []byte) (bool, error) {
+	const op = "event.(WebhookSink).post"
+
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("%s: unable to create request: %w", op, err)
+	}
+	req.Header.Set("Content-Type", s.contentType)
+	if s.bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.bearerToken)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("%s: unable to post event: %w", op, err)
+	}
+	// Drain the body so that the connection can be reused.
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		return true, fmt.Errorf("%s: endpoint responded with status %d", op, resp.StatusCode)
+	default:
+		return false, fmt.Errorf("%s: endpoint responded with status %d", op, resp.StatusCode)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package event
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/stretchr/testify/require"
+)
+
+// webhookEvent returns an event formatted as JSON with the data.
+func webhookEvent(data string) *eventlogger.Event {
+	e := &eventlogger.Event{Formatted: make(map[string][]byte)}
+	e.FormattedAs("json", []byte(data))
+	return e
+}
+
+// TestNewWebhookSink tests creation of a WebhookSink.
+func TestNewWebhookSink(t *testing.T) {
+	_, err := NewWebhookSink("json", "collector:8080")
+	require.ErrorContains(t, err, "URL must be an absolute http or https URL")
+
+	_, err = NewWebhookSink("json", "ftp://collector")
+	require.ErrorContains(t, err, "URL must be an absolute http or https URL")
+
+	_, err = NewWebhookSink("json", "https://collector", WithTLSClientKey("key.pem"))
+	require.ErrorContains(t, err, "client certificate and key must be supplied together")
+
+	s, err := NewWebhookSink("jsonx", "https://collector/audit", WithQueueSize("5"))
+	require.NoError(t, err)
+	defer s.Close(context.Background())
+	require.Equal(t, eventlogger.NodeTypeSink, s.Type())
+	require.Equal(t, "application/xml", s.contentType)
+	require.Equal(t, 5, cap(s.queue))
+}
+
+// TestWebhookSink_Process ensures that events are posted in order with the
+// bearer token, and that failed deliveries are retried.
+func TestWebhookSink_Process(t *testing.T) {
+	var lock sync.Mutex
+	var received []string
+	failures := 2
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		if r.Header.Get("Authorization") != "Bearer secret" || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, string(body))
+	}))
+	defer server.Close()
+
+	s, err := NewWebhookSink("json", server.URL, WithBearerToken("secret"))
+	require.NoError(t, err)
+	s.minBackoff = time.Millisecond
+
+	for _, msg := range []string{`{"type":"request"}`, `{"type":"response"}`} {
+		_, err := s.Process(context.Background(), webhookEvent(msg))
+		require.NoError(t, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	require.NoError(t, s.Close(ctx))
+
+	lock.Lock()
+	defer lock.Unlock()
+	require.Equal(t, []string{`{"type":"request"}`, `{"type":"response"}`}, received)
+	require.Zero(t, s.Dropped())
+
+	// Closed sinks reject events.
+	_, err = s.Process(context.Background(), webhookEvent("{}"))
+	require.ErrorContains(t, err, "sink is closed")
+}
+
+// TestWebhookSink_Drop ensures that events are dropped when the queue is full
+// or when their delivery can't succeed.
+func TestWebhookSink_Drop(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	s, err := NewWebhookSink("json", server.URL, WithQueueSize("1"))
+	require.NoError(t, err)
+
+	// The first event is being delivered while the second one is queued.
+	_, err = s.Process(context.Background(), webhookEvent("1"))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return len(s.queue) == 0 }, 5*time.Second, 10*time.Millisecond)
+	_, err = s.Process(context.Background(), webhookEvent("2"))
+	require.NoError(t, err)
+
+	_, err = s.Process(context.Background(), webhookEvent("3"))
+	require.ErrorIs(t, err, ErrWebhookQueueFull)
+
+	// Client errors aren't retried.
+	close(release)
+	require.NoError(t, s.Flush(context.Background()))
+	require.Equal(t, uint64(3), s.Dropped())
+	require.NoError(t, s.Close(context.Background()))
+}
+
+// TestWebhookSink_TLS ensures that events are posted with the client
+// certificate to an endpoint verified against the CA certificate.
+func TestWebhookSink_TLS(t *testing.T) {
+	certPath, keyPath := writeTestCertificate(t, t.TempDir())
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	require.NoError(t, err)
+	caPEM, err := os.ReadFile(certPath)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	require.True(t, pool.AppendCertsFromPEM(caPEM))
+
+	received := make(chan string, 1)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- string(body)
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	s, err := NewWebhookSink("json", server.URL,
+		WithTLSCACert(certPath), WithTLSClientCert(certPath), WithTLSClientKey(keyPath))
+	require.NoError(t, err)
+	defer s.Close(context.Background())
+
+	_, err = s.Process(context.Background(), webhookEvent(`{"type":"request"}`))
+	require.NoError(t, err)
+
+	select {
+	case got := <-received:
+		require.Equal(t, `{"type":"request"}`, got)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the event")
+	}
+}
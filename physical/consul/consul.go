@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -53,15 +54,18 @@ var (
 // it allows Vault to run on multiple machines in a highly-available manner.
 // failGetInTxn is only used in tests.
 type ConsulBackend struct {
-	client          *api.Client
-	path            string
-	kv              *api.KV
-	txn             *api.Txn
-	permitPool      *physical.PermitPool
-	consistencyMode string
-	sessionTTL      string
-	lockWaitTime    time.Duration
-	failGetInTxn    *uint32
+	logger           log.Logger
+	client           *api.Client
+	path             string
+	kv               *api.KV
+	txn              *api.Txn
+	permitPool       *physical.PermitPool
+	consistencyMode  string
+	sessionTTL       string
+	lockWaitTime     time.Duration
+	monitorRetries   int
+	monitorRetryTime time.Duration
+	failGetInTxn     *uint32
 }
 
 // NewConsulBackend constructs a Consul backend using the given API client
@@ -112,6 +116,35 @@ func NewConsulBackend(conf map[string]string, logger log.Logger) (physical.Backe
 		}
 	}
 
+	monitorRetries := 5
+	monitorRetriesRaw, ok := conf["lock_monitor_retries"]
+	if ok {
+		i, err := strconv.Atoi(monitorRetriesRaw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid lock_monitor_retries: %w", err)
+		}
+		if i < 0 {
+			return nil, fmt.Errorf("lock_monitor_retries must not be negative")
+		}
+		monitorRetries = i
+		if logger.IsDebug() {
+			logger.Debug("config lock_monitor_retries set", "lock_monitor_retries", i)
+		}
+	}
+
+	monitorRetryTime := api.DefaultMonitorRetryTime
+	monitorRetryTimeRaw, ok := conf["lock_monitor_retry_time"]
+	if ok {
+		d, err := parseutil.ParseDurationSecond(monitorRetryTimeRaw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid lock_monitor_retry_time: %w", err)
+		}
+		monitorRetryTime = d
+		if logger.IsDebug() {
+			logger.Debug("config lock_monitor_retry_time set", "lock_monitor_retry_time", d)
+		}
+	}
+
 	maxParStr, ok := conf["max_parallel"]
 	var maxParInt int
 	if ok {
@@ -152,15 +185,18 @@ func NewConsulBackend(conf map[string]string, logger log.Logger) (physical.Backe
 
 	// Set up the backend
 	c := &ConsulBackend{
-		path:            path,
-		client:          client,
-		kv:              client.KV(),
-		txn:             client.Txn(),
-		permitPool:      physical.NewPermitPool(maxParInt),
-		consistencyMode: consistencyMode,
-		sessionTTL:      sessionTTL,
-		lockWaitTime:    lockWaitTime,
-		failGetInTxn:    new(uint32),
+		logger:           logger,
+		path:             path,
+		client:           client,
+		kv:               client.KV(),
+		txn:              client.Txn(),
+		permitPool:       physical.NewPermitPool(maxParInt),
+		consistencyMode:  consistencyMode,
+		sessionTTL:       sessionTTL,
+		lockWaitTime:     lockWaitTime,
+		monitorRetries:   monitorRetries,
+		monitorRetryTime: monitorRetryTime,
+		failGetInTxn:     new(uint32),
 	}
 
 	return c, nil
@@ -295,7 +331,9 @@ func (c *ConsulBackend) Transaction(ctx context.Context, txns []*physical.TxnEnt
 	queryOpts := &api.QueryOptions{}
 	queryOpts = queryOpts.WithContext(ctx)
 
+	start := time.Now()
 	ok, resp, _, err := c.txn.Txn(ops, queryOpts)
+	measureRoundTrip("transaction", start, err)
 	if err != nil {
 		if strings.Contains(err.Error(), "is too large") {
 			return fmt.Errorf("%s: %w", physical.ErrValueTooLarge, err)
@@ -374,7 +412,9 @@ func (c *ConsulBackend) Put(ctx context.Context, entry *physical.Entry) error {
 	writeOpts := &api.WriteOptions{}
 	writeOpts = writeOpts.WithContext(ctx)
 
+	start := time.Now()
 	_, err := c.kv.Put(pair, writeOpts)
+	measureRoundTrip("put", start, err)
 	if err != nil {
 		if strings.Contains(err.Error(), "Value exceeds") {
 			return fmt.Errorf("%s: %w", physical.ErrValueTooLarge, err)
@@ -398,7 +438,9 @@ func (c *ConsulBackend) Get(ctx context.Context, key string) (*physical.Entry, e
 		queryOpts.RequireConsistent = true
 	}
 
+	start := time.Now()
 	pair, _, err := c.kv.Get(c.path+key, queryOpts)
+	measureRoundTrip("get", start, err)
 	if err != nil {
 		return nil, err
 	}
@@ -422,7 +464,9 @@ func (c *ConsulBackend) Delete(ctx context.Context, key string) error {
 	writeOpts := &api.WriteOptions{}
 	writeOpts = writeOpts.WithContext(ctx)
 
+	start := time.Now()
 	_, err := c.kv.Delete(c.path+key, writeOpts)
+	measureRoundTrip("delete", start, err)
 	return err
 }
 
@@ -445,7 +489,9 @@ func (c *ConsulBackend) List(ctx context.Context, prefix string) ([]string, erro
 	queryOpts := &api.QueryOptions{}
 	queryOpts = queryOpts.WithContext(ctx)
 
+	start := time.Now()
 	out, _, err := c.kv.Keys(scan, "/", queryOpts)
+	measureRoundTrip("list", start, err)
 	for idx, val := range out {
 		out[idx] = strings.TrimPrefix(val, scan)
 	}
@@ -453,6 +499,17 @@ func (c *ConsulBackend) List(ctx context.Context, prefix string) ([]string, erro
 	return out, err
 }
 
+// measureRoundTrip records the time taken by a request to Consul, excluding
+// any time spent waiting for a permit, so that Consul latency can be told
+// apart from contention within Vault.
+func measureRoundTrip(op string, start time.Time, err error) {
+	labels := []metrics.Label{{Name: "op", Value: op}}
+	metrics.MeasureSinceWithLabels([]string{"consul", "round_trip"}, start, labels)
+	if err != nil {
+		metrics.IncrCounterWithLabels([]string{"consul", "round_trip", "error"}, 1, labels)
+	}
+}
+
 func (c *ConsulBackend) FailGetInTxn(fail bool) {
 	var val uint32
 	if fail {
@@ -463,23 +520,31 @@ func (c *ConsulBackend) FailGetInTxn(fail bool) {
 
 // LockWith is used for mutual exclusion based on the given key.
 func (c *ConsulBackend) LockWith(key, value string) (physical.Lock, error) {
-	// Create the lock
-	opts := &api.LockOptions{
-		Key:            c.path + key,
-		Value:          []byte(value),
-		SessionName:    "Vault Lock",
-		MonitorRetries: 5,
-		SessionTTL:     c.sessionTTL,
-		LockWaitTime:   c.lockWaitTime,
-	}
-	lock, err := c.client.LockOpts(opts)
+	sessionTTL, err := parseutil.ParseDurationSecond(c.sessionTTL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create lock: %w", err)
 	}
+
+	// Validate the options up front; the lock itself is created once a
+	// session is available.
+	opts := &api.LockOptions{
+		Key:              c.path + key,
+		Value:            []byte(value),
+		SessionName:      "Vault Lock",
+		MonitorRetries:   c.monitorRetries,
+		MonitorRetryTime: c.monitorRetryTime,
+		SessionTTL:       c.sessionTTL,
+		LockWaitTime:     c.lockWaitTime,
+	}
+	if _, err := c.client.LockOpts(opts); err != nil {
+		return nil, fmt.Errorf("failed to create lock: %w", err)
+	}
 	cl := &ConsulLock{
+		logger:          c.logger.Named("lock"),
 		client:          c.client,
 		key:             c.path + key,
-		lock:            lock,
+		opts:            opts,
+		sessionTTL:      sessionTTL,
 		consistencyMode: c.consistencyMode,
 	}
 	return cl, nil
@@ -505,20 +570,82 @@ func (c *ConsulBackend) DetectHostAddr() (string, error) {
 	return addr, nil
 }
 
-// ConsulLock is used to provide the Lock interface backed by Consul
+// ConsulLock is used to provide the Lock interface backed by Consul. The
+// lock's session is created and renewed by the ConsulLock rather than by the
+// Consul API, so that renewals can be retried through transient failures.
 type ConsulLock struct {
+	logger          log.Logger
 	client          *api.Client
 	key             string
-	lock            *api.Lock
+	opts            *api.LockOptions
+	sessionTTL      time.Duration
 	consistencyMode string
+
+	l       sync.Mutex
+	lock    *api.Lock
+	session *sessionRenewer
 }
 
 func (c *ConsulLock) Lock(stopCh <-chan struct{}) (<-chan struct{}, error) {
-	return c.lock.Lock(stopCh)
+	c.l.Lock()
+	held := c.lock != nil
+	c.l.Unlock()
+	if held {
+		return nil, api.ErrLockHeld
+	}
+
+	session, err := createSession(c.client, c.opts.SessionName, c.sessionTTL, c.logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	opts := *c.opts
+	opts.Session = session.id
+	lock, err := c.client.LockOpts(&opts)
+	if err != nil {
+		session.stop()
+		return nil, fmt.Errorf("failed to create lock: %w", err)
+	}
+
+	leaderCh, err := lock.Lock(stopCh)
+	if err != nil || leaderCh == nil {
+		session.stop()
+		return leaderCh, err
+	}
+
+	c.l.Lock()
+	c.lock = lock
+	c.session = session
+	c.l.Unlock()
+	return leaderCh, nil
 }
 
 func (c *ConsulLock) Unlock() error {
-	return c.lock.Unlock()
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	if c.lock == nil {
+		return api.ErrLockNotHeld
+	}
+
+	err := c.lock.Unlock()
+	c.session.stop()
+	c.lock = nil
+	c.session = nil
+	return err
+}
+
+// SessionHealthy returns whether the session backing the held lock has been
+// renewed within its TTL, and the number of consecutive failed renewals. It
+// returns false if the lock is not held.
+func (c *ConsulLock) SessionHealthy() (bool, int) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	if c.session == nil {
+		return false, 0
+	}
+	return c.session.healthy()
 }
 
 func (c *ConsulLock) Value() (bool, string, error) {
@@ -0,0 +1,161 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package consul
+
+import (
+	"sync"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/hashicorp/consul/api"
+	log "github.com/hashicorp/go-hclog"
+)
+
+const (
+	// sessionRenewBackoffMin is the initial delay before retrying a failed
+	// session renewal.
+	sessionRenewBackoffMin = 500 * time.Millisecond
+)
+
+// sessionRenewer keeps a Consul session alive for as long as a lock is held
+// or being waited on. Unlike the renewal performed by the Consul API's lock,
+// failed renewals are retried with an exponential backoff, bounded by the
+// renewal interval, for as long as the session may still be alive, so that a
+// brief spike in Consul latency does not cause the session, and with it the
+// lock, to be lost.
+type sessionRenewer struct {
+	session *api.Session
+	id      string
+	ttl     time.Duration
+	logger  log.Logger
+
+	// now is used to determine whether the session has expired, and can be
+	// replaced in tests
+	now func() time.Time
+
+	l                   sync.Mutex
+	lastRenewal         time.Time
+	consecutiveFailures int
+
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// createSession creates a Consul session with the given name and TTL and
+// starts renewing it.
+func createSession(client *api.Client, name string, ttl time.Duration, logger log.Logger) (*sessionRenewer, error) {
+	session := client.Session()
+	id, _, err := session.Create(&api.SessionEntry{
+		Name:     name,
+		TTL:      ttl.String(),
+		Behavior: api.SessionBehaviorRelease,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &sessionRenewer{
+		session:     session,
+		id:          id,
+		ttl:         ttl,
+		logger:      logger,
+		now:         time.Now,
+		lastRenewal: time.Now(),
+		stopCh:      make(chan struct{}),
+		doneCh:      make(chan struct{}),
+	}
+	go r.run()
+	return r, nil
+}
+
+// stop stops renewing the session and destroys it.
+func (r *sessionRenewer) stop() {
+	r.stopOnce.Do(func() {
+		close(r.stopCh)
+		<-r.doneCh
+	})
+}
+
+// healthy returns whether the session has been renewed within its TTL, along
+// with the number of renewal attempts that have failed since the last
+// successful one.
+func (r *sessionRenewer) healthy() (bool, int) {
+	r.l.Lock()
+	defer r.l.Unlock()
+	return r.now().Sub(r.lastRenewal) < r.ttl, r.consecutiveFailures
+}
+
+func (r *sessionRenewer) run() {
+	defer close(r.doneCh)
+
+	interval := r.ttl / 2
+	backoff := sessionRenewBackoffMin
+	wait := interval
+
+	for {
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-r.stopCh:
+			timer.Stop()
+			if _, err := r.session.Destroy(r.id, nil); err != nil {
+				r.logger.Warn("failed to destroy session", "session", r.id, "error", err)
+			}
+			return
+		}
+
+		if !r.renew() {
+			return
+		}
+
+		if healthy, failures := r.healthy(); failures == 0 {
+			wait = interval
+			backoff = sessionRenewBackoffMin
+		} else {
+			if !healthy {
+				r.logger.Error("session expired before it could be renewed", "session", r.id, "failures", failures)
+				metrics.IncrCounter([]string{"consul", "session", "expired"}, 1)
+				return
+			}
+			wait = backoff
+			backoff *= 2
+			if backoff > interval {
+				backoff = interval
+			}
+		}
+	}
+}
+
+// renew attempts to renew the session once, recording the outcome. It
+// returns false if the session no longer exists and renewal should stop.
+func (r *sessionRenewer) renew() bool {
+	start := time.Now()
+	entry, _, err := r.session.Renew(r.id, nil)
+	metrics.MeasureSince([]string{"consul", "session", "renew"}, start)
+
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	switch {
+	case err != nil:
+		r.consecutiveFailures++
+		metrics.IncrCounter([]string{"consul", "session", "renew_failure"}, 1)
+		r.logger.Warn("failed to renew session, retrying", "session", r.id, "failures", r.consecutiveFailures, "error", err)
+	case entry == nil:
+		// The session was invalidated, so there is nothing left to renew
+		metrics.IncrCounter([]string{"consul", "session", "invalidated"}, 1)
+		r.logger.Error("session was invalidated", "session", r.id)
+		return false
+	default:
+		if r.consecutiveFailures > 0 {
+			r.logger.Info("session renewed after failures", "session", r.id, "failures", r.consecutiveFailures)
+		}
+		r.consecutiveFailures = 0
+		r.lastRenewal = r.now()
+	}
+
+	metrics.SetGauge([]string{"consul", "session", "consecutive_renew_failures"}, float32(r.consecutiveFailures))
+	return true
+}
@@ -0,0 +1,104 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package consul
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/helper/logging"
+)
+
+// fakeSessionServer serves the Consul session endpoints, failing the first
+// failRenewals renewal requests.
+func fakeSessionServer(t *testing.T, failRenewals int32) (*httptest.Server, *int32, *int32) {
+	t.Helper()
+
+	var renewals, destroyed int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/session/create":
+			w.Write([]byte(`{"ID": "test-session"}`))
+		case strings.HasPrefix(r.URL.Path, "/v1/session/renew/"):
+			if atomic.AddInt32(&renewals, 1) <= failRenewals {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.Write([]byte(`[{"ID": "test-session", "TTL": "1s"}]`))
+		case strings.HasPrefix(r.URL.Path, "/v1/session/destroy/"):
+			atomic.AddInt32(&destroyed, 1)
+			w.Write([]byte(`true`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &renewals, &destroyed
+}
+
+func TestConsul_SessionRenewer_Backoff(t *testing.T) {
+	srv, renewals, destroyed := fakeSessionServer(t, 2)
+
+	client, err := api.NewClient(&api.Config{Address: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := createSession(client, "Vault Lock", 4*time.Second, logging.NewVaultLogger(log.Debug))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first renewal is attempted after half the TTL, and the two
+	// failures are retried within the rest of it.
+	deadline := time.Now().Add(4 * time.Second)
+	for atomic.LoadInt32(renewals) < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected failed renewals to be retried, got %d attempts", atomic.LoadInt32(renewals))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if healthy, failures := r.healthy(); !healthy || failures != 0 {
+		t.Fatalf("expected session to be healthy, got healthy=%t failures=%d", healthy, failures)
+	}
+
+	r.stop()
+	if atomic.LoadInt32(destroyed) != 1 {
+		t.Fatal("expected session to be destroyed on stop")
+	}
+}
+
+func TestConsul_SessionRenewer_Expired(t *testing.T) {
+	srv, _, _ := fakeSessionServer(t, 1000)
+
+	client, err := api.NewClient(&api.Config{Address: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := createSession(client, "Vault Lock", 200*time.Millisecond, logging.NewVaultLogger(log.Debug))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.stop()
+
+	select {
+	case <-r.doneCh:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected renewal to stop once the session expired")
+	}
+
+	if healthy, failures := r.healthy(); healthy || failures == 0 {
+		t.Fatalf("expected session to be unhealthy, got healthy=%t failures=%d", healthy, failures)
+	}
+}
@@ -105,6 +105,9 @@ type FSM struct {
 
 	chunker *raftchunking.ChunkingBatchingFSM
 
+	// usage tracks the number of keys and bytes stored per prefix
+	usage *storageUsage
+
 	localID         string
 	desiredSuffrage string
 	unknownOpTypes  sync.Map
@@ -132,6 +135,7 @@ func NewFSM(path string, localID string, logger log.Logger) (*FSM, error) {
 		// setup if this is already part of a cluster with a desired suffrage.
 		desiredSuffrage: "voter",
 		localID:         localID,
+		usage:           newStorageUsage(),
 	}
 
 	f.chunker = raftchunking.NewChunkingBatchingFSM(f, &FSMChunkStorage{
@@ -231,7 +235,9 @@ func (f *FSM) openDBFile(dbPath string) error {
 
 			f.latestConfig.Store(&latest)
 		}
-		return nil
+
+		// Compute the storage usage of the data we've just opened
+		return f.usage.reset(tx)
 	})
 	if err != nil {
 		return err
@@ -446,9 +452,18 @@ func (f *FSM) Delete(ctx context.Context, path string) error {
 	f.l.RLock()
 	defer f.l.RUnlock()
 
-	return f.db.Update(func(tx *bolt.Tx) error {
-		return tx.Bucket(dataBucketName).Delete([]byte(path))
+	delta := make(usageDelta)
+	err := f.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(dataBucketName)
+		delta.delete(path, b.Get([]byte(path)))
+		return b.Delete([]byte(path))
 	})
+	if err != nil {
+		return err
+	}
+
+	f.usage.apply(delta)
+	return nil
 }
 
 // Delete deletes the given key from the bolt file.
@@ -458,12 +473,14 @@ func (f *FSM) DeletePrefix(ctx context.Context, prefix string) error {
 	f.l.RLock()
 	defer f.l.RUnlock()
 
+	delta := make(usageDelta)
 	err := f.db.Update(func(tx *bolt.Tx) error {
 		// Assume bucket exists and has keys
 		c := tx.Bucket(dataBucketName).Cursor()
 
 		prefixBytes := []byte(prefix)
-		for k, _ := c.Seek(prefixBytes); k != nil && bytes.HasPrefix(k, prefixBytes); k, _ = c.Next() {
+		for k, v := c.Seek(prefixBytes); k != nil && bytes.HasPrefix(k, prefixBytes); k, v = c.Next() {
+			delta.delete(string(k), v)
 			if err := c.Delete(); err != nil {
 				return err
 			}
@@ -471,8 +488,12 @@ func (f *FSM) DeletePrefix(ctx context.Context, prefix string) error {
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
 
-	return err
+	f.usage.apply(delta)
+	return nil
 }
 
 // Get retrieves the value at the given path from the bolt file.
@@ -518,9 +539,18 @@ func (f *FSM) Put(ctx context.Context, entry *physical.Entry) error {
 	defer f.l.RUnlock()
 
 	// Start a write transaction.
-	return f.db.Update(func(tx *bolt.Tx) error {
-		return tx.Bucket(dataBucketName).Put([]byte(entry.Key), entry.Value)
+	delta := make(usageDelta)
+	err := f.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(dataBucketName)
+		delta.put(entry.Key, b.Get([]byte(entry.Key)), entry.Value)
+		return b.Put([]byte(entry.Key), entry.Value)
 	})
+	if err != nil {
+		return err
+	}
+
+	f.usage.apply(delta)
+	return nil
 }
 
 // List retrieves the set of keys with the given prefix from the bolt file.
@@ -566,14 +596,17 @@ func (f *FSM) Transaction(ctx context.Context, txns []*physical.TxnEntry) error
 	defer f.l.RUnlock()
 
 	// Start a write transaction.
+	delta := make(usageDelta)
 	err := f.db.Update(func(tx *bolt.Tx) error {
 		b := tx.Bucket(dataBucketName)
 		for _, txn := range txns {
 			var err error
 			switch txn.Operation {
 			case physical.PutOperation:
+				delta.put(txn.Entry.Key, b.Get([]byte(txn.Entry.Key)), txn.Entry.Value)
 				err = b.Put([]byte(txn.Entry.Key), txn.Entry.Value)
 			case physical.DeleteOperation:
+				delta.delete(txn.Entry.Key, b.Get([]byte(txn.Entry.Key)))
 				err = b.Delete([]byte(txn.Entry.Key))
 			default:
 				return fmt.Errorf("%q is not a supported transaction operation", txn.Operation)
@@ -585,8 +618,12 @@ func (f *FSM) Transaction(ctx context.Context, txns []*physical.TxnEntry) error
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
 
-	return err
+	f.usage.apply(delta)
+	return nil
 }
 
 // ApplyBatch will apply a set of logs to the FSM. This is called from the raft
@@ -653,6 +690,7 @@ func (f *FSM) ApplyBatch(logs []*raft.Log) []interface{} {
 		f.applyCallback()
 	}
 
+	delta := make(usageDelta)
 	err = f.db.Update(func(tx *bolt.Tx) error {
 		b := tx.Bucket(dataBucketName)
 		for _, commandRaw := range commands {
@@ -663,8 +701,10 @@ func (f *FSM) ApplyBatch(logs []*raft.Log) []interface{} {
 					var err error
 					switch op.OpType {
 					case putOp:
+						delta.put(op.Key, b.Get([]byte(op.Key)), op.Value)
 						err = b.Put([]byte(op.Key), op.Value)
 					case deleteOp:
+						delta.delete(op.Key, b.Get([]byte(op.Key)))
 						err = b.Delete([]byte(op.Key))
 					case getOp:
 						fsmEntry := &FSMEntry{
@@ -722,6 +762,8 @@ func (f *FSM) ApplyBatch(logs []*raft.Log) []interface{} {
 		panic("failed to store data")
 	}
 
+	f.usage.apply(delta)
+
 	// If we advanced the latest value, update the in-memory representation too.
 	if len(logIndex) > 0 {
 		atomic.StoreUint64(f.latestTerm, lastLog.Term)
@@ -980,7 +1022,9 @@ func (f *FSMChunkStorage) StoreChunk(chunk *raftchunking.ChunkInfo) (bool, error
 
 	// Start a write transaction.
 	done := new(bool)
+	delta := make(usageDelta)
 	if err := f.f.db.Update(func(tx *bolt.Tx) error {
+		delta.put(entry.Key, tx.Bucket(dataBucketName).Get([]byte(entry.Key)), entry.Value)
 		if err := tx.Bucket(dataBucketName).Put([]byte(entry.Key), entry.Value); err != nil {
 			return fmt.Errorf("error storing chunk info: %w", err)
 		}
@@ -1009,6 +1053,7 @@ func (f *FSMChunkStorage) StoreChunk(chunk *raftchunking.ChunkInfo) (bool, error
 		return false, err
 	}
 
+	f.f.usage.apply(delta)
 	return *done, nil
 }
 
@@ -162,3 +162,69 @@ func TestFSM_List(t *testing.T) {
 		t.Fatal(diff)
 	}
 }
+
+func TestFSM_StorageUsage(t *testing.T) {
+	fsm, dir := getFSM(t)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	ctx := context.Background()
+	entries := []*physical.Entry{
+		{Key: "core/seal-config", Value: []byte("abc")},
+		{Key: "sys/policy/default", Value: []byte("abcd")},
+		{Key: "sys/token/id/foo", Value: []byte("ab")},
+		{Key: "logical/1234/foo", Value: []byte("abcde")},
+		{Key: "logical/1234/bar/baz", Value: []byte("a")},
+		{Key: "logical/5678/foo", Value: []byte("ab")},
+		{Key: "auth/9abc/role/foo", Value: []byte("abc")},
+	}
+	for _, entry := range entries {
+		if err := fsm.Put(ctx, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Overwrite a key, delete another, and remove a prefix through the log
+	if err := fsm.Put(ctx, &physical.Entry{Key: "logical/1234/foo", Value: []byte("abcdefgh")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := fsm.Delete(ctx, "sys/token/id/foo"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fsm.Delete(ctx, "sys/token/id/missing"); err != nil {
+		t.Fatal(err)
+	}
+	command := &LogData{
+		Operations: []*LogOperation{
+			{OpType: putOp, Key: "auth/9abc/role/bar", Value: []byte("abcdef")},
+			{OpType: deleteOp, Key: "logical/5678/foo"},
+		},
+	}
+	commandBytes, err := proto.Marshal(command)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsm.ApplyBatch([]*raft.Log{{Index: 1, Term: 1, Type: raft.LogCommand, Data: commandBytes}})
+
+	expected := map[string]PrefixUsage{
+		"core":         {Keys: 1, Bytes: 3},
+		"sys":          {Keys: 1, Bytes: 4},
+		"logical/1234": {Keys: 2, Bytes: 9},
+		"auth/9abc":    {Keys: 2, Bytes: 9},
+	}
+	if diff := deep.Equal(expected, fsm.StorageUsage()); len(diff) > 0 {
+		t.Fatal(diff)
+	}
+
+	// Reopening the FSM recomputes the same totals from disk
+	if err := fsm.Close(); err != nil {
+		t.Fatal(err)
+	}
+	fsm, err = NewFSM(dir, "", hclog.NewNullLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsm.Close()
+	if diff := deep.Equal(expected, fsm.StorageUsage()); len(diff) > 0 {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package raft
+
+import (
+	"strings"
+	"sync"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// PrefixUsage is the number of keys stored under a storage prefix and the
+// total size of their values.
+type PrefixUsage struct {
+	Keys  int64 `json:"keys"`
+	Bytes int64 `json:"bytes"`
+}
+
+// usagePrefix returns the prefix a key is accounted under. Keys are grouped
+// by their top-level path segment, except for mount data under logical/ and
+// auth/, which is grouped by mount UUID so that each mount is reported
+// separately.
+func usagePrefix(key string) string {
+	parts := strings.SplitN(key, "/", 3)
+	switch {
+	case len(parts) == 1:
+		return parts[0]
+	case len(parts) == 3 && (parts[0] == "logical" || parts[0] == "auth"):
+		return parts[0] + "/" + parts[1]
+	default:
+		return parts[0]
+	}
+}
+
+// usageDelta accumulates the changes made by a single bolt transaction, so
+// that they can be applied to the totals only once it has committed.
+type usageDelta map[string]*PrefixUsage
+
+func (d usageDelta) add(key string, keys, bytes int64) {
+	prefix := usagePrefix(key)
+	u, ok := d[prefix]
+	if !ok {
+		u = &PrefixUsage{}
+		d[prefix] = u
+	}
+	u.Keys += keys
+	u.Bytes += bytes
+}
+
+// put records the write of value to key, replacing old, which is nil if the
+// key did not previously exist.
+func (d usageDelta) put(key string, old, value []byte) {
+	if old == nil {
+		d.add(key, 1, int64(len(value)))
+		return
+	}
+	d.add(key, 0, int64(len(value)-len(old)))
+}
+
+// delete records the removal of key, whose value was old. It is a no-op if
+// old is nil, meaning the key did not exist.
+func (d usageDelta) delete(key string, old []byte) {
+	if old == nil {
+		return
+	}
+	d.add(key, -1, -int64(len(old)))
+}
+
+// storageUsage holds the per-prefix usage totals of the FSM's data bucket.
+// The totals are computed by scanning the bucket when the database is opened
+// and are then maintained incrementally as writes are applied.
+type storageUsage struct {
+	l        sync.RWMutex
+	prefixes map[string]*PrefixUsage
+}
+
+func newStorageUsage() *storageUsage {
+	return &storageUsage{
+		prefixes: make(map[string]*PrefixUsage),
+	}
+}
+
+// reset recomputes the totals from the contents of the data bucket.
+func (u *storageUsage) reset(tx *bolt.Tx) error {
+	delta := make(usageDelta)
+	err := tx.Bucket(dataBucketName).ForEach(func(k, v []byte) error {
+		delta.add(string(k), 1, int64(len(v)))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	u.l.Lock()
+	defer u.l.Unlock()
+	u.prefixes = map[string]*PrefixUsage(delta)
+	return nil
+}
+
+func (u *storageUsage) apply(delta usageDelta) {
+	if len(delta) == 0 {
+		return
+	}
+
+	u.l.Lock()
+	defer u.l.Unlock()
+
+	for prefix, d := range delta {
+		total, ok := u.prefixes[prefix]
+		if !ok {
+			total = &PrefixUsage{}
+			u.prefixes[prefix] = total
+		}
+		total.Keys += d.Keys
+		total.Bytes += d.Bytes
+		if total.Keys <= 0 {
+			delete(u.prefixes, prefix)
+		}
+	}
+}
+
+// snapshot returns a copy of the current totals.
+func (u *storageUsage) snapshot() map[string]PrefixUsage {
+	u.l.RLock()
+	defer u.l.RUnlock()
+
+	ret := make(map[string]PrefixUsage, len(u.prefixes))
+	for prefix, total := range u.prefixes {
+		ret[prefix] = *total
+	}
+	return ret
+}
+
+// StorageUsage returns the number of keys and bytes stored under each
+// top-level storage prefix.
+func (f *FSM) StorageUsage() map[string]PrefixUsage {
+	return f.usage.snapshot()
+}
+
+// StorageUsage returns the number of keys and bytes stored under each
+// top-level storage prefix on this node.
+func (b *RaftBackend) StorageUsage() map[string]PrefixUsage {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.fsm.StorageUsage()
+}
@@ -0,0 +1,172 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package raft
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/golang/protobuf/proto"
+	log "github.com/hashicorp/go-hclog"
+)
+
+const (
+	// defaultGroupCommitMaxDelay is how long the first write of a batch waits
+	// for further writes to join it if group_commit_max_delay is not set.
+	defaultGroupCommitMaxDelay = 2 * time.Millisecond
+)
+
+type groupCommitRequest struct {
+	command *LogData
+	size    int
+	errCh   chan error
+}
+
+// groupCommitter merges concurrent put and delete commands into a single raft
+// log entry, so that they are replicated and fsynced together rather than one
+// at a time. A batch is applied once it holds maxBatchSize operations, once
+// adding another command would exceed maxEntrySize, or once maxDelay has
+// passed since its first command arrived, whichever comes first.
+//
+// Callers of submit must hold the backend's read lock until it returns. The
+// committer applies batches without taking the lock itself, relying on the
+// callers waiting on the batch to keep the raft instance from being torn down
+// underneath it.
+type groupCommitter struct {
+	logger       log.Logger
+	maxBatchSize int
+	maxDelay     time.Duration
+	maxEntrySize uint64
+	apply        func(context.Context, *LogData) error
+
+	reqCh    chan *groupCommitRequest
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+	stopOnce sync.Once
+}
+
+func newGroupCommitter(logger log.Logger, maxBatchSize int, maxDelay time.Duration, maxEntrySize uint64, apply func(context.Context, *LogData) error) *groupCommitter {
+	g := &groupCommitter{
+		logger:       logger,
+		maxBatchSize: maxBatchSize,
+		maxDelay:     maxDelay,
+		maxEntrySize: maxEntrySize,
+		apply:        apply,
+		reqCh:        make(chan *groupCommitRequest),
+		stopCh:       make(chan struct{}),
+		doneCh:       make(chan struct{}),
+	}
+	go g.run()
+	return g
+}
+
+// submit queues the command to be applied as part of the next batch and waits
+// for that batch to be applied.
+func (g *groupCommitter) submit(ctx context.Context, command *LogData) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	req := &groupCommitRequest{
+		command: command,
+		size:    proto.Size(command),
+		errCh:   make(chan error, 1),
+	}
+
+	select {
+	case g.reqCh <- req:
+	case <-g.stopCh:
+		// The committer has been stopped, so apply the command on its own.
+		return g.apply(ctx, command)
+	}
+
+	return <-req.errCh
+}
+
+func (g *groupCommitter) stop() {
+	g.stopOnce.Do(func() {
+		close(g.stopCh)
+		<-g.doneCh
+	})
+}
+
+func (g *groupCommitter) run() {
+	defer close(g.doneCh)
+
+	// pending holds a command that didn't fit in the previous batch and so
+	// starts the next one.
+	var pending *groupCommitRequest
+	timer := time.NewTimer(g.maxDelay)
+	if !timer.Stop() {
+		<-timer.C
+	}
+
+	for {
+		first := pending
+		pending = nil
+		if first == nil {
+			select {
+			case first = <-g.reqCh:
+			case <-g.stopCh:
+				return
+			}
+		}
+
+		batch := []*groupCommitRequest{first}
+		numOps := len(first.command.Operations)
+		size := first.size
+
+		timer.Reset(g.maxDelay)
+	COLLECT:
+		for numOps < g.maxBatchSize {
+			select {
+			case req := <-g.reqCh:
+				if uint64(size+req.size) > g.maxEntrySize {
+					pending = req
+					break COLLECT
+				}
+				batch = append(batch, req)
+				numOps += len(req.command.Operations)
+				size += req.size
+			case <-timer.C:
+				break COLLECT
+			case <-g.stopCh:
+				break COLLECT
+			}
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+
+		g.commit(batch, numOps)
+	}
+}
+
+func (g *groupCommitter) commit(batch []*groupCommitRequest, numOps int) {
+	metrics.AddSample([]string{"raft-storage", "group_commit", "batch_size"}, float32(len(batch)))
+	metrics.AddSample([]string{"raft-storage", "group_commit", "batch_ops"}, float32(numOps))
+
+	command := batch[0].command
+	if len(batch) > 1 {
+		command = &LogData{
+			Operations: make([]*LogOperation, 0, numOps),
+		}
+		for _, req := range batch {
+			command.Operations = append(command.Operations, req.command.Operations...)
+		}
+	}
+
+	err := g.apply(context.Background(), command)
+	if err != nil && len(batch) > 1 {
+		g.logger.Debug("failed to apply group commit batch", "commands", len(batch), "error", err)
+	}
+	for _, req := range batch {
+		req.errCh <- err
+	}
+}
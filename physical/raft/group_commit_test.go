@@ -0,0 +1,118 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package raft
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/physical"
+)
+
+func TestGroupCommitter_Batching(t *testing.T) {
+	var l sync.Mutex
+	var applied []*LogData
+	release := make(chan struct{})
+	apply := func(_ context.Context, command *LogData) error {
+		<-release
+		l.Lock()
+		defer l.Unlock()
+		applied = append(applied, command)
+		return nil
+	}
+
+	g := newGroupCommitter(hclog.NewNullLogger(), 4, time.Second, defaultMaxEntrySize, apply)
+	defer g.stop()
+
+	// Applies are held until all writes have been submitted, so the writes
+	// queue up and are applied in batches of at most four.
+	var wg sync.WaitGroup
+	for i := 0; i < 9; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := g.submit(context.Background(), &LogData{
+				Operations: []*LogOperation{{OpType: putOp, Key: fmt.Sprintf("key-%d", i)}},
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}(i)
+		if i == 0 {
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	l.Lock()
+	defer l.Unlock()
+	var total int
+	for _, command := range applied {
+		if len(command.Operations) > 4 {
+			t.Fatalf("batch exceeded max size: %d", len(command.Operations))
+		}
+		total += len(command.Operations)
+	}
+	if total != 9 {
+		t.Fatalf("expected 9 operations to be applied, got %d", total)
+	}
+	if len(applied) > 4 {
+		t.Fatalf("expected writes to be batched, got %d applies", len(applied))
+	}
+}
+
+func TestGroupCommitter_MaxEntrySize(t *testing.T) {
+	var l sync.Mutex
+	var applied []*LogData
+	apply := func(_ context.Context, command *LogData) error {
+		l.Lock()
+		defer l.Unlock()
+		applied = append(applied, command)
+		return nil
+	}
+
+	command := &LogData{
+		Operations: []*LogOperation{{OpType: putOp, Key: "foo", Value: make([]byte, 100)}},
+	}
+	maxEntrySize := uint64(proto.Size(command)*2 - 1)
+	g := newGroupCommitter(hclog.NewNullLogger(), 10, 100*time.Millisecond, maxEntrySize, apply)
+	defer g.stop()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := g.submit(context.Background(), command); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	l.Lock()
+	defer l.Unlock()
+	if len(applied) != 2 {
+		t.Fatalf("expected commands exceeding max entry size to be applied separately, got %d applies", len(applied))
+	}
+}
+
+func TestRaft_Backend_GroupCommit(t *testing.T) {
+	b, dir := GetRaft(t, true, true)
+	defer os.RemoveAll(dir)
+
+	b.groupCommit = newGroupCommitter(b.logger, 16, defaultGroupCommitMaxDelay, b.maxEntrySize, b.applyLog)
+	defer b.groupCommit.stop()
+
+	physical.ExerciseBackend(t, b)
+	physical.ExerciseTransactionalBackend(t, b)
+}
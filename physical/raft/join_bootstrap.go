@@ -0,0 +1,124 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package raft
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+)
+
+// bootstrapClientCertTTL is the validity of the client certificates a node
+// issues itself with a bootstrap CA to join a cluster. Certificates are
+// reissued once half of it has elapsed.
+const bootstrapClientCertTTL = time.Hour
+
+// bootstrapIssuer issues the client certificates presented to the leader
+// when joining with a bootstrap CA.
+type bootstrapIssuer struct {
+	caCert *x509.Certificate
+	caKey  crypto.Signer
+
+	l    sync.Mutex
+	cert *tls.Certificate
+}
+
+// bootstrapTLSConfig returns the TLS configuration to join a cluster with a
+// bootstrap CA shared by its nodes: the leader's certificate must be issued
+// by the CA, and the node presents a short-lived client certificate issued
+// with the CA, so that no certificate has to be provisioned per node.
+func bootstrapTLSConfig(caCertFile, caKeyFile string) (*tls.Config, error) {
+	ca, err := tls.LoadX509KeyPair(caCertFile, caKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load bootstrap CA: %w", err)
+	}
+	caCert, err := x509.ParseCertificate(ca.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse bootstrap CA certificate: %w", err)
+	}
+	if !caCert.IsCA {
+		return nil, errors.New("bootstrap CA certificate is not a CA")
+	}
+	caKey, ok := ca.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("bootstrap CA key cannot sign certificates")
+	}
+
+	issuer := &bootstrapIssuer{
+		caCert: caCert,
+		caKey:  caKey,
+	}
+	// Fail early on keys which cannot issue certificates
+	if _, err := issuer.clientCertificate(nil); err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(caCert)
+
+	return &tls.Config{
+		RootCAs:              pool,
+		GetClientCertificate: issuer.clientCertificate,
+		MinVersion:           tls.VersionTLS12,
+	}, nil
+}
+
+// clientCertificate returns the current client certificate, issuing a new
+// one when it is past half of its validity.
+func (b *bootstrapIssuer) clientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	if b.cert != nil && time.Until(b.cert.Leaf.NotAfter) > bootstrapClientCertTTL/2 {
+		return b.cert, nil
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	commonName := "vault-raft-join"
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		commonName = hostname
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    now.Add(-time.Minute),
+		NotAfter:     now.Add(bootstrapClientCertTTL),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, b.caCert, key.Public(), b.caKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue client certificate with bootstrap CA: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	b.cert = &tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}
+	return b.cert, nil
+}
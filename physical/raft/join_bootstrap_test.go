@@ -0,0 +1,131 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package raft
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeBootstrapCA generates a CA and returns the paths of its certificate
+// and key files.
+func writeBootstrapCA(t *testing.T, isCA bool) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "raft bootstrap CA"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "ca.pem")
+	keyFile := filepath.Join(dir, "ca-key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestRaft_BootstrapTLSConfig(t *testing.T) {
+	certFile, keyFile := writeBootstrapCA(t, true)
+
+	tlsConfig, err := bootstrapTLSConfig(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := tlsConfig.GetClientCertificate(&tls.CertificateRequestInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = cert.Leaf.Verify(x509.VerifyOptions{
+		Roots:     tlsConfig.RootCAs,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		t.Fatalf("client certificate does not chain to the bootstrap CA: %v", err)
+	}
+	if ttl := time.Until(cert.Leaf.NotAfter); ttl > bootstrapClientCertTTL {
+		t.Fatalf("bad: client certificate ttl: %v", ttl)
+	}
+
+	// Certificates are reused until half of their validity has elapsed
+	again, err := tlsConfig.GetClientCertificate(&tls.CertificateRequestInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != cert {
+		t.Fatal("expected the client certificate to be reused")
+	}
+
+	notCACertFile, notCAKeyFile := writeBootstrapCA(t, false)
+	if _, err := bootstrapTLSConfig(notCACertFile, notCAKeyFile); err == nil {
+		t.Fatal("expected an error for a certificate which is not a CA")
+	}
+}
+
+func TestRaft_JoinConfig_BootstrapCA(t *testing.T) {
+	certFile, keyFile := writeBootstrapCA(t, true)
+
+	joinConfig := func(retryJoin string) ([]*LeaderJoinInfo, error) {
+		b := &RaftBackend{
+			conf: map[string]string{"retry_join": retryJoin},
+		}
+		return b.JoinConfig()
+	}
+
+	infos, err := joinConfig(fmt.Sprintf(`[{"auto_join": "provider=aws tag_key=vault tag_value=raft", "bootstrap_ca_cert_file": %q, "bootstrap_ca_key_file": %q, "leader_tls_servername": "vault.example.com"}]`, certFile, keyFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 1 || infos[0].TLSConfig == nil || infos[0].TLSConfig.GetClientCertificate == nil {
+		t.Fatalf("bad: join config: %#v", infos)
+	}
+	if infos[0].TLSConfig.ServerName != "vault.example.com" {
+		t.Fatalf("bad: server name: %q", infos[0].TLSConfig.ServerName)
+	}
+
+	invalid := map[string]string{
+		fmt.Sprintf(`[{"leader_api_addr": "https://127.0.0.1:8200", "bootstrap_ca_cert_file": %q}]`, certFile):                                                                            "must be provided together",
+		fmt.Sprintf(`[{"leader_api_addr": "https://127.0.0.1:8200", "bootstrap_ca_cert_file": %q, "bootstrap_ca_key_file": %q, "leader_ca_cert_file": %q}]`, certFile, keyFile, certFile): "cannot provide both",
+	}
+	for retryJoin, expected := range invalid {
+		_, err := joinConfig(retryJoin)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected an error containing %q, got: %v", expected, err)
+		}
+	}
+}
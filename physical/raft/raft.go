@@ -187,6 +187,10 @@ type RaftBackend struct {
 
 	effectiveSDKVersion string
 	failGetInTxn        *uint32
+
+	// groupCommit, if set, merges concurrent writes into shared raft log
+	// entries. It is enabled by setting group_commit_max_batch_size.
+	groupCommit *groupCommitter
 }
 
 // LeaderJoinInfo contains information required by a node to join itself as a
@@ -237,6 +241,18 @@ type LeaderJoinInfo struct {
 	// certificate, instead of the host/IP we're actually connecting to.
 	LeaderTLSServerName string `json:"leader_tls_servername"`
 
+	// BootstrapCACertFile is the path on disk to the CA cert file of a
+	// bootstrap CA shared by the nodes of the cluster. The leader's certificate
+	// must be issued by it, and the follower node issues itself short-lived
+	// client certificates with it, instead of using LeaderCACertFile,
+	// LeaderClientCertFile and LeaderClientKeyFile. This should only be
+	// provided via Vault's configuration file.
+	BootstrapCACertFile string `json:"bootstrap_ca_cert_file"`
+
+	// BootstrapCAKeyFile is the path on disk to the key file of the bootstrap
+	// CA. This should only be provided via Vault's configuration file.
+	BootstrapCAKeyFile string `json:"bootstrap_ca_key_file"`
+
 	// Retry indicates if the join process should automatically be retried
 	Retry bool `json:"-"`
 
@@ -271,6 +287,14 @@ func (b *RaftBackend) JoinConfig() ([]*LeaderJoinInfo, error) {
 			return nil, fmt.Errorf("invalid scheme %q; must either be http or https", info.AutoJoinScheme)
 		}
 
+		if (info.BootstrapCACertFile == "") != (info.BootstrapCAKeyFile == "") {
+			return nil, errors.New("bootstrap_ca_cert_file and bootstrap_ca_key_file must be provided together")
+		}
+		if info.BootstrapCACertFile != "" && (info.LeaderCACert != "" || info.LeaderClientCert != "" || info.LeaderClientKey != "" ||
+			info.LeaderCACertFile != "" || info.LeaderClientCertFile != "" || info.LeaderClientKeyFile != "") {
+			return nil, errors.New("cannot provide both a bootstrap CA and leader certificates")
+		}
+
 		info.Retry = true
 		info.TLSConfig, err = parseTLSInfo(info)
 		if err != nil {
@@ -281,12 +305,17 @@ func (b *RaftBackend) JoinConfig() ([]*LeaderJoinInfo, error) {
 	return leaderInfos, nil
 }
 
-// parseTLSInfo is a helper for parses the TLS information, preferring file
-// paths over raw certificate content.
+// parseTLSInfo is a helper for parses the TLS information, preferring a
+// bootstrap CA, then file paths over raw certificate content.
 func parseTLSInfo(leaderInfo *LeaderJoinInfo) (*tls.Config, error) {
 	var tlsConfig *tls.Config
 	var err error
-	if len(leaderInfo.LeaderCACertFile) != 0 || len(leaderInfo.LeaderClientCertFile) != 0 || len(leaderInfo.LeaderClientKeyFile) != 0 {
+	if len(leaderInfo.BootstrapCACertFile) != 0 {
+		tlsConfig, err = bootstrapTLSConfig(leaderInfo.BootstrapCACertFile, leaderInfo.BootstrapCAKeyFile)
+		if err != nil {
+			return nil, err
+		}
+	} else if len(leaderInfo.LeaderCACertFile) != 0 || len(leaderInfo.LeaderClientCertFile) != 0 || len(leaderInfo.LeaderClientKeyFile) != 0 {
 		tlsConfig, err = tlsutil.LoadClientTLSConfig(leaderInfo.LeaderCACertFile, leaderInfo.LeaderClientCertFile, leaderInfo.LeaderClientKeyFile)
 		if err != nil {
 			return nil, err
@@ -503,7 +532,29 @@ func NewRaftBackend(conf map[string]string, logger log.Logger) (physical.Backend
 		return nil, fmt.Errorf("setting %s to true is only valid if at least one retry_join stanza is specified", raftNonVoterConfigKey)
 	}
 
-	return &RaftBackend{
+	var groupCommitMaxBatchSize int
+	if v := conf["group_commit_max_batch_size"]; v != "" {
+		groupCommitMaxBatchSize, err = strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse 'group_commit_max_batch_size': %w", err)
+		}
+		if groupCommitMaxBatchSize < 0 {
+			return nil, errors.New("'group_commit_max_batch_size' must not be negative")
+		}
+	}
+
+	groupCommitMaxDelay := defaultGroupCommitMaxDelay
+	if v := conf["group_commit_max_delay"]; v != "" {
+		groupCommitMaxDelay, err = parseutil.ParseDurationSecond(v)
+		if err != nil {
+			return nil, fmt.Errorf("group_commit_max_delay does not parse as a duration: %w", err)
+		}
+		if groupCommitMaxDelay <= 0 {
+			return nil, errors.New("group_commit_max_delay must be greater than zero")
+		}
+	}
+
+	backend := &RaftBackend{
 		logger:                     logger,
 		fsm:                        fsm,
 		raftInitCh:                 make(chan struct{}),
@@ -522,7 +573,15 @@ func NewRaftBackend(conf map[string]string, logger log.Logger) (physical.Backend
 		nonVoter:                   nonVoter,
 		upgradeVersion:             upgradeVersion,
 		failGetInTxn:               new(uint32),
-	}, nil
+	}
+
+	// A batch size of one would only add latency, so group commit is left
+	// disabled unless at least two operations can share an entry.
+	if groupCommitMaxBatchSize > 1 {
+		backend.groupCommit = newGroupCommitter(logger.Named("group-commit"), groupCommitMaxBatchSize, groupCommitMaxDelay, maxEntrySize, backend.applyLog)
+	}
+
+	return backend, nil
 }
 
 type snapshotStoreDelay struct {
@@ -562,6 +621,10 @@ func (b *RaftBackend) Close() error {
 	b.l.Lock()
 	defer b.l.Unlock()
 
+	if b.groupCommit != nil {
+		b.groupCommit.stop()
+	}
+
 	if err := b.fsm.Close(); err != nil {
 		return err
 	}
@@ -1486,7 +1549,7 @@ func (b *RaftBackend) Delete(ctx context.Context, path string) error {
 	defer b.permitPool.Release()
 
 	b.l.RLock()
-	err := b.applyLog(ctx, command)
+	err := b.applyWrite(ctx, command)
 	b.l.RUnlock()
 	return err
 }
@@ -1548,7 +1611,7 @@ func (b *RaftBackend) Put(ctx context.Context, entry *physical.Entry) error {
 	defer b.permitPool.Release()
 
 	b.l.RLock()
-	err := b.applyLog(ctx, command)
+	err := b.applyWrite(ctx, command)
 	b.l.RUnlock()
 	return err
 }
@@ -1623,7 +1686,12 @@ func (b *RaftBackend) Transaction(ctx context.Context, txns []*physical.TxnEntry
 	defer b.permitPool.Release()
 
 	b.l.RLock()
-	err := b.applyLog(ctx, command)
+	var err error
+	if len(txnMap) == 0 {
+		err = b.applyWrite(ctx, command)
+	} else {
+		err = b.applyLog(ctx, command)
+	}
 	b.l.RUnlock()
 
 	// loop over results and update pointers to get operations
@@ -1638,6 +1706,19 @@ func (b *RaftBackend) Transaction(ctx context.Context, txns []*physical.TxnEntry
 	return err
 }
 
+// applyWrite applies a command made up only of put and delete operations,
+// through the group committer if it is enabled. Caller should hold the
+// backend's read lock.
+func (b *RaftBackend) applyWrite(ctx context.Context, command *LogData) error {
+	if b.groupCommit == nil {
+		return b.applyLog(ctx, command)
+	}
+	if b.raft == nil {
+		return errors.New("raft storage is not initialized")
+	}
+	return b.groupCommit.submit(ctx, command)
+}
+
 // applyLog will take a given log command and apply it to the raft log. applyLog
 // doesn't return until the log has been applied to a quorum of servers and is
 // persisted to the local FSM. Caller should hold the backend's read lock.
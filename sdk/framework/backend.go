@@ -109,6 +109,12 @@ type Backend struct {
 	// RunningVersion is the optional version that will be self-reported
 	RunningVersion string
 
+	// MaxConcurrentCost limits the total Cost of the requests the backend
+	// handles at once. Like the concurrency limits of paths, it is reported
+	// in the SpecialPaths of the backend and enforced by core, which rejects
+	// requests beyond the limit with a 429 status code. Zero means no limit.
+	MaxConcurrentCost int
+
 	logger  log.Logger
 	system  logical.SystemView
 	events  logical.EventSender
@@ -394,9 +400,45 @@ func HandlePatchOperation(input *FieldData, resource map[string]interface{}, pre
 	return modified, nil
 }
 
-// SpecialPaths is the logical.Backend implementation.
+// SpecialPaths is the logical.Backend implementation. The concurrency limits
+// and costs declared by the paths, and the MaxConcurrentCost of the backend,
+// are added to PathsSpecial.
 func (b *Backend) SpecialPaths() *logical.Paths {
-	return b.PathsSpecial
+	// Paths are routed to the first one matching, so the paths preceding the
+	// last limited one are reported as well, even if they have no limit.
+	last := -1
+	for i, p := range b.Paths {
+		if p.MaxConcurrency > 0 || p.Cost > 0 {
+			last = i
+		}
+	}
+	if last < 0 && b.MaxConcurrentCost <= 0 {
+		return b.PathsSpecial
+	}
+
+	paths := &logical.Paths{}
+	if b.PathsSpecial != nil {
+		*paths = *b.PathsSpecial
+	}
+	paths.Limits = append([]*logical.PathLimit(nil), paths.Limits...)
+	for _, p := range b.Paths[:last+1] {
+		pattern := p.Pattern
+		if !strings.HasPrefix(pattern, "^") {
+			pattern = "^" + pattern
+		}
+		if !strings.HasSuffix(pattern, "$") {
+			pattern = pattern + "$"
+		}
+		paths.Limits = append(paths.Limits, &logical.PathLimit{
+			Pattern:        pattern,
+			MaxConcurrency: p.MaxConcurrency,
+			Cost:           p.Cost,
+		})
+	}
+	if b.MaxConcurrentCost > 0 {
+		paths.MaxConcurrentCost = b.MaxConcurrentCost
+	}
+	return paths
 }
 
 // Cleanup is used to release resources and prepare to stop the backend
@@ -307,6 +307,33 @@ func TestBackendHandleRequest_badwrite(t *testing.T) {
 	}
 }
 
+// TestBackend_SpecialPaths_limits verifies that the concurrency limits and
+// costs of paths are reported in the special paths of the backend.
+func TestBackend_SpecialPaths_limits(t *testing.T) {
+	b := &Backend{
+		PathsSpecial: &logical.Paths{
+			Root: []string{"config"},
+		},
+		Paths: []*Path{
+			{Pattern: "foo/bar"},
+			{Pattern: "foo/.*", MaxConcurrency: 1, Cost: 2},
+			{Pattern: "baz"},
+		},
+	}
+	require.Equal(t, b.PathsSpecial, (&Backend{PathsSpecial: b.PathsSpecial}).SpecialPaths())
+
+	b.MaxConcurrentCost = 4
+	require.Equal(t, &logical.Paths{
+		Root: []string{"config"},
+		Limits: []*logical.PathLimit{
+			{Pattern: "^foo/bar$"},
+			{Pattern: "^foo/.*$", MaxConcurrency: 1, Cost: 2},
+		},
+		MaxConcurrentCost: 4,
+	}, b.SpecialPaths())
+	require.Empty(t, b.PathsSpecial.Limits)
+}
+
 func TestBackendHandleRequest_404(t *testing.T) {
 	callback := func(ctx context.Context, req *logical.Request, data *FieldData) (*logical.Response, error) {
 		return &logical.Response{
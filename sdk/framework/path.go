@@ -121,6 +121,19 @@ type Path struct {
 	// enabled for the set of paths
 	FeatureRequired license.Features
 
+	// MaxConcurrency limits how many requests to the path the backend handles
+	// at once, so that expensive operations can't monopolize the backend.
+	// The limit is reported in the SpecialPaths of the backend and enforced
+	// by core, which rejects requests beyond it with a 429 status code. Zero
+	// means no limit. Help operations are not limited.
+	MaxConcurrency int
+
+	// Cost hints how expensive requests to the path are to handle, relative
+	// to the requests to the other paths of the backend. While being handled,
+	// requests take their cost out of the MaxConcurrentCost of the backend.
+	// Defaults to 1.
+	Cost int
+
 	// Deprecated denotes that this path is considered deprecated. This may
 	// be reflected in help and documentation.
 	Deprecated bool
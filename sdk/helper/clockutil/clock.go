@@ -0,0 +1,201 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package clockutil provides the time source injected into time-driven code,
+// such as lease expiry and credential rotation, so that tests can control it.
+package clockutil
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is a source of time, timers and tickers. Tests substitute a
+// ManualClock for the DefaultClock.
+type Clock interface {
+	Now() time.Time
+	NewTicker(time.Duration) *time.Ticker
+	NewTimer(time.Duration) *time.Timer
+
+	// AfterFunc calls f in its own goroutine once the duration has passed.
+	// Unlike NewTimer, the returned Timer can be reset through the clock.
+	AfterFunc(time.Duration, func()) Timer
+}
+
+// Timer is a timer returned by Clock.AfterFunc. Stop and Reset behave as the
+// ones of time.Timer.
+type Timer interface {
+	Stop() bool
+	Reset(time.Duration) bool
+}
+
+// DefaultClock is the system clock.
+type DefaultClock struct{}
+
+var _ Clock = (*DefaultClock)(nil)
+
+func (_ DefaultClock) Now() time.Time {
+	return time.Now()
+}
+
+func (_ DefaultClock) NewTicker(d time.Duration) *time.Ticker {
+	return time.NewTicker(d)
+}
+
+func (_ DefaultClock) NewTimer(d time.Duration) *time.Timer {
+	return time.NewTimer(d)
+}
+
+func (_ DefaultClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// ManualClock is a Clock that only moves when it is advanced, so that tests
+// of time-driven code do not have to sleep. Tickers, timers and functions
+// created from it fire as the clock passes their deadlines.
+//
+// The tickers and timers returned by NewTicker and NewTimer are backed by real
+// ones that never fire, so Stop may be called on them, but Stop and Reset are
+// not observed by the clock. The timers returned by AfterFunc are fully
+// controlled by the clock.
+type ManualClock struct {
+	l      sync.Mutex
+	now    time.Time
+	events []*manualEvent
+}
+
+type manualEvent struct {
+	ch     chan time.Time
+	f      func()
+	next   time.Time
+	period time.Duration
+}
+
+var _ Clock = (*ManualClock)(nil)
+
+// NewManualClock returns a ManualClock set to the given time.
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+func (c *ManualClock) Now() time.Time {
+	c.l.Lock()
+	defer c.l.Unlock()
+	return c.now
+}
+
+func (c *ManualClock) NewTicker(d time.Duration) *time.Ticker {
+	if d <= 0 {
+		panic("non-positive interval for ManualClock.NewTicker")
+	}
+	ch := make(chan time.Time, 1)
+	t := time.NewTicker(1000 * time.Hour)
+	t.Stop()
+	t.C = ch
+	c.addEvent(&manualEvent{ch: ch, period: d}, d)
+	return t
+}
+
+func (c *ManualClock) NewTimer(d time.Duration) *time.Timer {
+	ch := make(chan time.Time, 1)
+	t := time.NewTimer(1000 * time.Hour)
+	t.Stop()
+	t.C = ch
+	c.addEvent(&manualEvent{ch: ch}, d)
+	return t
+}
+
+func (c *ManualClock) AfterFunc(d time.Duration, f func()) Timer {
+	e := &manualEvent{f: f}
+	c.addEvent(e, d)
+	return &manualTimer{clock: c, event: e}
+}
+
+func (c *ManualClock) addEvent(e *manualEvent, d time.Duration) {
+	c.l.Lock()
+	defer c.l.Unlock()
+	c.addEventLocked(e, d)
+}
+
+func (c *ManualClock) addEventLocked(e *manualEvent, d time.Duration) {
+	e.next = c.now.Add(d)
+	c.events = append(c.events, e)
+}
+
+// removeEventLocked removes a pending event, and returns whether it was
+// pending.
+func (c *ManualClock) removeEventLocked(e *manualEvent) bool {
+	for i, pending := range c.events {
+		if pending == e {
+			c.events = append(c.events[:i], c.events[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Set moves the clock forward to t, as Advance does. Times before the current
+// time of the clock are ignored.
+func (c *ManualClock) Set(t time.Time) {
+	if d := t.Sub(c.Now()); d > 0 {
+		c.Advance(d)
+	}
+}
+
+// Advance moves the clock forward by d, firing in order every ticker, timer
+// and function whose deadline is passed. As with real tickers, ticks are
+// dropped if the receiver has not consumed the previous one. Functions are
+// called in their own goroutines.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	target := c.now.Add(d)
+	for {
+		sort.SliceStable(c.events, func(i, j int) bool {
+			return c.events[i].next.Before(c.events[j].next)
+		})
+		if len(c.events) == 0 || c.events[0].next.After(target) {
+			break
+		}
+
+		e := c.events[0]
+		c.now = e.next
+		switch {
+		case e.f != nil:
+			go e.f()
+		default:
+			select {
+			case e.ch <- e.next:
+			default:
+			}
+		}
+		if e.period > 0 {
+			e.next = e.next.Add(e.period)
+		} else {
+			c.events = c.events[1:]
+		}
+	}
+	c.now = target
+}
+
+// manualTimer is a timer returned by ManualClock.AfterFunc.
+type manualTimer struct {
+	clock *ManualClock
+	event *manualEvent
+}
+
+func (t *manualTimer) Stop() bool {
+	t.clock.l.Lock()
+	defer t.clock.l.Unlock()
+	return t.clock.removeEventLocked(t.event)
+}
+
+func (t *manualTimer) Reset(d time.Duration) bool {
+	t.clock.l.Lock()
+	defer t.clock.l.Unlock()
+	active := t.clock.removeEventLocked(t.event)
+	t.clock.addEventLocked(t.event, d)
+	return active
+}
@@ -0,0 +1,118 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package clockutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestManualClock(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewManualClock(start)
+
+	ticker := c.NewTicker(time.Minute)
+	timer := c.NewTimer(90 * time.Second)
+
+	c.Advance(59 * time.Second)
+	select {
+	case <-ticker.C:
+		t.Fatal("ticker fired early")
+	case <-timer.C:
+		t.Fatal("timer fired early")
+	default:
+	}
+
+	c.Advance(time.Second)
+	select {
+	case tick := <-ticker.C:
+		if !tick.Equal(start.Add(time.Minute)) {
+			t.Fatalf("bad tick time: %v", tick)
+		}
+	default:
+		t.Fatal("ticker did not fire")
+	}
+
+	// The timer fires once, and unconsumed ticks are dropped.
+	c.Advance(5 * time.Minute)
+	select {
+	case <-timer.C:
+	default:
+		t.Fatal("timer did not fire")
+	}
+	select {
+	case tick := <-ticker.C:
+		if !tick.Equal(start.Add(2 * time.Minute)) {
+			t.Fatalf("expected the first pending tick to be kept, got %v", tick)
+		}
+	default:
+		t.Fatal("ticker did not fire")
+	}
+	select {
+	case <-ticker.C:
+		t.Fatal("expected the remaining ticks to be dropped")
+	default:
+	}
+
+	if now := c.Now(); !now.Equal(start.Add(6 * time.Minute)) {
+		t.Fatalf("bad time: %v", now)
+	}
+
+	// Times in the past are ignored.
+	c.Set(start)
+	if now := c.Now(); !now.Equal(start.Add(6 * time.Minute)) {
+		t.Fatalf("bad time: %v", now)
+	}
+}
+
+func TestManualClock_AfterFunc(t *testing.T) {
+	c := NewManualClock(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	fired := make(chan time.Time, 3)
+	fire := func() {
+		fired <- c.Now()
+	}
+	expectFired := func(expected bool) {
+		t.Helper()
+		select {
+		case <-fired:
+			if !expected {
+				t.Fatal("function called unexpectedly")
+			}
+		case <-time.After(100 * time.Millisecond):
+			if expected {
+				t.Fatal("function not called")
+			}
+		}
+	}
+
+	// Resetting a pending timer moves its deadline.
+	timer := c.AfterFunc(time.Minute, fire)
+	c.Advance(30 * time.Second)
+	if !timer.Reset(time.Minute) {
+		t.Fatal("expected the timer to be active")
+	}
+	c.Advance(45 * time.Second)
+	expectFired(false)
+	c.Advance(15 * time.Second)
+	expectFired(true)
+
+	// Fired timers can be reset to fire again.
+	if timer.Reset(time.Minute) {
+		t.Fatal("expected the timer to have fired")
+	}
+	c.Advance(time.Minute)
+	expectFired(true)
+
+	// Stopped timers don't fire.
+	timer = c.AfterFunc(time.Minute, fire)
+	if !timer.Stop() {
+		t.Fatal("expected the timer to be active")
+	}
+	if timer.Stop() {
+		t.Fatal("expected the timer to be stopped")
+	}
+	c.Advance(time.Hour)
+	expectFired(false)
+}
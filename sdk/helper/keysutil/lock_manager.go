@@ -60,6 +60,10 @@ type PolicyRequest struct {
 	// How frequently the key should automatically rotate
 	AutoRotatePeriod time.Duration
 
+	// How many encryptions the latest key version may perform before the key
+	// should automatically rotate
+	AutoRotateMaxOperations uint64
+
 	// AllowImportedKeyRotation indicates whether an imported key may be rotated by Vault
 	AllowImportedKeyRotation bool
 
@@ -380,6 +384,12 @@ func (lm *LockManager) GetPolicy(ctx context.Context, req PolicyRequest, rand io
 				return nil, false, fmt.Errorf("convergent encryption not supported for keys of type %v", req.KeyType)
 			}
 
+		case KeyType_ED448:
+			if req.Derived || req.Convergent {
+				cleanup()
+				return nil, false, fmt.Errorf("key derivation and convergent encryption not supported for keys of type %v", req.KeyType)
+			}
+
 		case KeyType_RSA2048, KeyType_RSA3072, KeyType_RSA4096:
 			if req.Derived || req.Convergent {
 				cleanup()
@@ -403,14 +413,15 @@ func (lm *LockManager) GetPolicy(ctx context.Context, req PolicyRequest, rand io
 		}
 
 		p = &Policy{
-			l:                    new(sync.RWMutex),
-			Name:                 req.Name,
-			Type:                 req.KeyType,
-			Derived:              req.Derived,
-			Exportable:           req.Exportable,
-			AllowPlaintextBackup: req.AllowPlaintextBackup,
-			AutoRotatePeriod:     req.AutoRotatePeriod,
-			KeySize:              req.KeySize,
+			l:                       new(sync.RWMutex),
+			Name:                    req.Name,
+			Type:                    req.KeyType,
+			Derived:                 req.Derived,
+			Exportable:              req.Exportable,
+			AllowPlaintextBackup:    req.AllowPlaintextBackup,
+			AutoRotatePeriod:        req.AutoRotatePeriod,
+			AutoRotateMaxOperations: req.AutoRotateMaxOperations,
+			KeySize:                 req.KeySize,
 		}
 
 		if req.Derived {
@@ -509,6 +520,7 @@ func (lm *LockManager) ImportPolicy(ctx context.Context, req PolicyRequest, key
 			Exportable:               req.Exportable,
 			AllowPlaintextBackup:     req.AllowPlaintextBackup,
 			AutoRotatePeriod:         req.AutoRotatePeriod,
+			AutoRotateMaxOperations:  req.AutoRotateMaxOperations,
 			AllowImportedKeyRotation: req.AllowImportedKeyRotation,
 			Imported:                 true,
 		}
@@ -15,9 +15,11 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
+	"crypto/sha512"
 	"crypto/x509"
 	"encoding/asn1"
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/json"
 	"encoding/pem"
 	"errors"
@@ -43,6 +45,7 @@ import (
 	"github.com/hashicorp/vault/sdk/helper/kdf"
 	"github.com/hashicorp/vault/sdk/logical"
 
+	"github.com/cloudflare/circl/sign/ed448"
 	"github.com/google/tink/go/kwp/subtle"
 )
 
@@ -70,6 +73,22 @@ const (
 	KeyType_RSA3072
 	KeyType_MANAGED_KEY
 	KeyType_HMAC
+	KeyType_ED448
+)
+
+// Algorithms wrapping keys for import elsewhere, see WrapKeyWithAlgorithm
+const (
+	// WrappingAlgorithmRSAOAEPAESKWP wraps keys with an ephemeral AES-256 key
+	// using KWP, itself encrypted with RSA-OAEP, like CKM_RSA_AES_KEY_WRAP.
+	WrappingAlgorithmRSAOAEPAESKWP = "rsa-oaep-aes-kwp"
+
+	// WrappingAlgorithmRSAOAEP encrypts keys directly with RSA-OAEP, which
+	// limits the size of the keys that can be wrapped.
+	WrappingAlgorithmRSAOAEP = "rsa-oaep"
+
+	// WrappingAlgorithmECDHESP384 wraps keys with an AES-256 key agreed upon
+	// through ephemeral-static ECDH over P-384, using KWP.
+	WrappingAlgorithmECDHESP384 = "ecdh-es-p384"
 )
 
 const (
@@ -98,6 +117,13 @@ type RestoreInfo struct {
 	Version int       `json:"version"`
 }
 
+// KeyAccessPolicy lists the identity entities, and the identity groups whose
+// members, are allowed to perform a class of operations with a key.
+type KeyAccessPolicy struct {
+	EntityIDs []string `json:"entity_ids"`
+	GroupIDs  []string `json:"group_ids"`
+}
+
 type BackupInfo struct {
 	Time    time.Time `json:"time"`
 	Version int       `json:"version"`
@@ -140,7 +166,7 @@ func (kt KeyType) DecryptionSupported() bool {
 
 func (kt KeyType) SigningSupported() bool {
 	switch kt {
-	case KeyType_ECDSA_P256, KeyType_ECDSA_P384, KeyType_ECDSA_P521, KeyType_ED25519, KeyType_RSA2048, KeyType_RSA3072, KeyType_RSA4096, KeyType_MANAGED_KEY:
+	case KeyType_ECDSA_P256, KeyType_ECDSA_P384, KeyType_ECDSA_P521, KeyType_ED25519, KeyType_ED448, KeyType_RSA2048, KeyType_RSA3072, KeyType_RSA4096, KeyType_MANAGED_KEY:
 		return true
 	}
 	return false
@@ -194,6 +220,8 @@ func (kt KeyType) String() string {
 		return "ecdsa-p521"
 	case KeyType_ED25519:
 		return "ed25519"
+	case KeyType_ED448:
+		return "ed448"
 	case KeyType_RSA2048:
 		return "rsa-2048"
 	case KeyType_RSA3072:
@@ -454,6 +482,11 @@ type Policy struct {
 	// rotate. Setting this to zero disables automatic rotation for the key.
 	AutoRotatePeriod time.Duration `json:"auto_rotate_period"`
 
+	// AutoRotateMaxOperations defines how many encryptions the latest version
+	// of the key may perform before the key is automatically rotated. Setting
+	// this to zero disables rotation based on the number of encryptions.
+	AutoRotateMaxOperations uint64 `json:"auto_rotate_max_operations"`
+
 	// versionPrefixCache stores caches of version prefix strings and the split
 	// version template.
 	versionPrefixCache sync.Map
@@ -464,6 +497,11 @@ type Policy struct {
 
 	// AllowImportedKeyRotation indicates whether an imported key may be rotated by Vault
 	AllowImportedKeyRotation bool
+
+	// AccessPolicies restricts the identities allowed to perform a class of
+	// operations with the key, such as decryption, keyed by the class.
+	// Classes of operations without a policy are unrestricted.
+	AccessPolicies map[string]*KeyAccessPolicy `json:"access_policies,omitempty"`
 }
 
 func (p *Policy) Lock(exclusive bool) {
@@ -1213,6 +1251,11 @@ func (p *Policy) SignWithOptions(ver int, context, input []byte, options *Signin
 			return nil, err
 		}
 
+	case KeyType_ED448:
+		// Like ed25519, ed448 performs its own hashing of the input, which is
+		// signed without context
+		sig = ed448.Sign(ed448.PrivateKey(keyParams.Key), input, "")
+
 	case KeyType_RSA2048, KeyType_RSA3072, KeyType_RSA4096:
 		key := keyParams.RSAKey
 
@@ -1406,6 +1449,22 @@ func (p *Policy) VerifySignatureWithOptions(context, input []byte, sig string, o
 
 		return ed25519.Verify(pub, input, sigBytes), nil
 
+	case KeyType_ED448:
+		keyEntry, err := p.safeGetKeyEntry(ver)
+		if err != nil {
+			return false, err
+		}
+
+		raw, err := base64.StdEncoding.DecodeString(keyEntry.FormattedPublicKey)
+		if err != nil {
+			return false, err
+		}
+		if len(raw) != ed448.PublicKeySize {
+			return false, errutil.InternalError{Err: "invalid ed448 public key"}
+		}
+
+		return ed448.Verify(ed448.PublicKey(raw), input, sigBytes, ""), nil
+
 	case KeyType_RSA2048, KeyType_RSA3072, KeyType_RSA4096:
 		keyEntry, err := p.safeGetKeyEntry(ver)
 		if err != nil {
@@ -1680,6 +1739,15 @@ func (p *Policy) RotateInMemory(randReader io.Reader) (retErr error) {
 		}
 		entry.Key = pri
 		entry.FormattedPublicKey = base64.StdEncoding.EncodeToString(pub)
+	case KeyType_ED448:
+		// The 114-byte private key is the RFC 8032 seed followed by the
+		// public key, as with ed25519.
+		pub, pri, err := ed448.GenerateKey(randReader)
+		if err != nil {
+			return err
+		}
+		entry.Key = pri
+		entry.FormattedPublicKey = base64.StdEncoding.EncodeToString(pub)
 	case KeyType_RSA2048, KeyType_RSA3072, KeyType_RSA4096:
 		bitSize := 2048
 		if p.Type == KeyType_RSA3072 {
@@ -2310,6 +2378,13 @@ func (ke *KeyEntry) parseFromKey(PolKeyType KeyType, parsedKey any) error {
 }
 
 func (p *Policy) WrapKey(ver int, targetKey interface{}, targetKeyType KeyType, hash hash.Hash) (string, error) {
+	return p.WrapKeyWithAlgorithm(ver, targetKey, targetKeyType, WrappingAlgorithmRSAOAEPAESKWP, hash)
+}
+
+// WrapKeyWithAlgorithm wraps the target key for import elsewhere with the
+// given version of the policy's key, using the given wrapping algorithm. The
+// hash is used by the RSA-OAEP algorithms.
+func (p *Policy) WrapKeyWithAlgorithm(ver int, targetKey interface{}, targetKeyType KeyType, algorithm string, hash hash.Hash) (string, error) {
 	if !p.Type.SigningSupported() {
 		return "", fmt.Errorf("message signing not supported for key type %v", p.Type)
 	}
@@ -2330,7 +2405,39 @@ func (p *Policy) WrapKey(ver int, targetKey interface{}, targetKeyType KeyType,
 		return "", err
 	}
 
-	return keyEntry.WrapKey(targetKey, targetKeyType, hash)
+	switch algorithm {
+	case WrappingAlgorithmRSAOAEPAESKWP:
+		return keyEntry.WrapKey(targetKey, targetKeyType, hash)
+	case WrappingAlgorithmRSAOAEP:
+		if keyEntry.RSAPublicKey == nil {
+			return "", errutil.UserError{Err: fmt.Sprintf("wrapping algorithm %q requires a rsa key", algorithm)}
+		}
+		preppedTargetKey, err := prepTargetKeyForImport(targetKey, targetKeyType)
+		if err != nil {
+			return "", err
+		}
+		wrapped, err := rsa.EncryptOAEP(hash, rand.Reader, keyEntry.RSAPublicKey, preppedTargetKey, []byte{} /* label */)
+		if err != nil {
+			return "", fmt.Errorf("failed to encrypt target key with public key: %w", err)
+		}
+		return base64.StdEncoding.EncodeToString(wrapped), nil
+	case WrappingAlgorithmECDHESP384:
+		if p.Type != KeyType_ECDSA_P384 {
+			return "", errutil.UserError{Err: fmt.Sprintf("wrapping algorithm %q requires a ecdsa-p384 key", algorithm)}
+		}
+		wrappingKey := &ecdsa.PublicKey{
+			Curve: elliptic.P384(),
+			X:     keyEntry.EC_X,
+			Y:     keyEntry.EC_Y,
+		}
+		preppedTargetKey, err := prepTargetKeyForImport(targetKey, targetKeyType)
+		if err != nil {
+			return "", err
+		}
+		return wrapTargetKeyECDHES(wrappingKey, preppedTargetKey)
+	default:
+		return "", errutil.UserError{Err: fmt.Sprintf("unknown wrapping algorithm %q", algorithm)}
+	}
 }
 
 func (ke *KeyEntry) WrapKey(targetKey interface{}, targetKeyType KeyType, hash hash.Hash) (string, error) {
@@ -2340,20 +2447,9 @@ func (ke *KeyEntry) WrapKey(targetKey interface{}, targetKeyType KeyType, hash h
 		return "", fmt.Errorf("unsupported key type in use; must be a rsa key")
 	}
 
-	var preppedTargetKey []byte
-	switch targetKeyType {
-	case KeyType_AES128_GCM96, KeyType_AES256_GCM96, KeyType_ChaCha20_Poly1305, KeyType_HMAC:
-		var ok bool
-		preppedTargetKey, ok = targetKey.([]byte)
-		if !ok {
-			return "", fmt.Errorf("failed to wrap target key for import: symmetric key not provided in byte format (%T)", targetKey)
-		}
-	default:
-		var err error
-		preppedTargetKey, err = x509.MarshalPKCS8PrivateKey(targetKey)
-		if err != nil {
-			return "", fmt.Errorf("failed to wrap target key for import: %w", err)
-		}
+	preppedTargetKey, err := prepTargetKeyForImport(targetKey, targetKeyType)
+	if err != nil {
+		return "", err
 	}
 
 	result, err := wrapTargetPKCS8ForImport(ke.RSAPublicKey, preppedTargetKey, hash)
@@ -2393,3 +2489,68 @@ func wrapTargetPKCS8ForImport(wrappingKey *rsa.PublicKey, preppedTargetKey []byt
 	wrappedKeys := append(ephKeyWrapped, targetKeyWrapped...)
 	return base64.StdEncoding.EncodeToString(wrappedKeys), nil
 }
+
+// prepTargetKeyForImport returns the raw bytes of symmetric keys, and the
+// PKCS#8 encoding of asymmetric keys.
+func prepTargetKeyForImport(targetKey interface{}, targetKeyType KeyType) ([]byte, error) {
+	switch targetKeyType {
+	case KeyType_AES128_GCM96, KeyType_AES256_GCM96, KeyType_ChaCha20_Poly1305, KeyType_HMAC:
+		preppedTargetKey, ok := targetKey.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("failed to wrap target key for import: symmetric key not provided in byte format (%T)", targetKey)
+		}
+		return preppedTargetKey, nil
+	default:
+		preppedTargetKey, err := x509.MarshalPKCS8PrivateKey(targetKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to wrap target key for import: %w", err)
+		}
+		return preppedTargetKey, nil
+	}
+}
+
+// wrapTargetKeyECDHES wraps the target key with an AES-256 key agreed upon
+// with the wrapping key through an ephemeral ECDH key, like
+// CKM_ECDH1_DERIVE with the CKD_SHA384_KDF function followed by
+// CKM_AES_KEY_WRAP_KWP. The result is the uncompressed ephemeral public key
+// followed by the wrapped target key.
+func wrapTargetKeyECDHES(wrappingKey *ecdsa.PublicKey, preppedTargetKey []byte) (string, error) {
+	curve := wrappingKey.Curve
+	if !curve.IsOnCurve(wrappingKey.X, wrappingKey.Y) {
+		return "", errors.New("invalid wrapping key: point is not on the curve")
+	}
+
+	ephKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate an ephemeral ECDH key: %w", err)
+	}
+	x, _ := curve.ScalarMult(wrappingKey.X, wrappingKey.Y, ephKey.D.Bytes())
+	sharedSecret := x.FillBytes(make([]byte, (curve.Params().BitSize+7)/8))
+
+	kwp, err := subtle.NewKWP(x963KDF(sha512.New384(), sharedSecret, 32))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate new KWP from AES key: %w", err)
+	}
+	targetKeyWrapped, err := kwp.Wrap(preppedTargetKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to wrap target key with KWP: %w", err)
+	}
+
+	wrappedKeys := append(elliptic.Marshal(curve, ephKey.X, ephKey.Y), targetKeyWrapped...)
+	return base64.StdEncoding.EncodeToString(wrappedKeys), nil
+}
+
+// x963KDF derives a key of the given size from the shared secret with the
+// ANSI X9.63 key derivation function, without shared info.
+func x963KDF(h hash.Hash, sharedSecret []byte, size int) []byte {
+	var key []byte
+	counter := make([]byte, 4)
+	for i := uint32(1); len(key) < size; i++ {
+		binary.BigEndian.PutUint32(counter, i)
+		h.Reset()
+		h.Write(sharedSecret)
+		h.Write(counter)
+		key = h.Sum(key)
+	}
+	return key[:size]
+}
@@ -3,6 +3,8 @@
 
 package logical
 
+import "time"
+
 type LogInput struct {
 	Type                string
 	Auth                *Auth
@@ -11,6 +13,18 @@ type LogInput struct {
 	OuterErr            error
 	NonHMACReqDataKeys  []string
 	NonHMACRespDataKeys []string
+
+	// RequestMetrics, when set, records the resources the request consumed.
+	// It is only populated when logging responses.
+	RequestMetrics *RequestMetrics
+}
+
+// RequestMetrics records the storage operations caused by a request and the
+// time spent in the backend handling it.
+type RequestMetrics struct {
+	StorageReads    int
+	StorageWrites   int
+	HandlerDuration time.Duration
 }
 
 type MarshalOptions struct {
@@ -93,6 +93,11 @@ type Auth struct {
 	// The set of CIDRs that this token can be used with
 	BoundCIDRs []*sockaddr.SockAddrMarshaler `json:"bound_cidrs"`
 
+	// BoundClientCertFingerprint is the SHA-256 fingerprint of the TLS client
+	// certificate that requests using the token must present, if set. It is
+	// set by core when the auth mount binds tokens to client certificates.
+	BoundClientCertFingerprint string `json:"bound_client_cert_fingerprint,omitempty"`
+
 	// CreationPath is a path that the backend can return to use in the lease.
 	// This is currently only supported for the token store where roles may
 	// change the perceived path of the lease, even though they don't change
@@ -114,6 +119,10 @@ type Auth struct {
 
 	// EntityCreated is set to true if an entity is created as part of a login request
 	EntityCreated bool `json:"entity_created"`
+
+	// Impersonator is set if the token is an impersonation token, and
+	// identifies the operator acting as the token's entity.
+	Impersonator *Impersonator `json:"impersonator,omitempty"`
 }
 
 func (a *Auth) GoString() string {
@@ -45,6 +45,18 @@ var (
 	// rate limit quota being exceeded.
 	ErrRateLimitQuotaExceeded = errors.New("rate limit quota exceeded")
 
+	// ErrTooManyConcurrentRequests is returned when a request is rejected
+	// because it exceeds the concurrency limits its backend declares.
+	ErrTooManyConcurrentRequests = errors.New("too many concurrent requests")
+
+	// ErrRequestTooLarge is returned when a request is rejected because its
+	// data exceeds the maximum request size of the mount it is routed to.
+	ErrRequestTooLarge = errors.New("request too large")
+
+	// ErrResponseTooLarge is returned when a response is withheld because its
+	// data exceeds the maximum response size of the mount which handled it.
+	ErrResponseTooLarge = errors.New("response too large")
+
 	// ErrUnrecoverable is returned when a request fails due to something that
 	// is likely to require manual intervention. This is a generic form of an
 	// unrecoverable error.
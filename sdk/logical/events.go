@@ -28,6 +28,18 @@ func NewEvent() (*EventData, error) {
 // EventType represents a topic, and is a wrapper around eventlogger.EventType.
 type EventType string
 
+// EventTypeHealthWarnings is the type of the events plugins send to report
+// the current warnings of their mount, which are surfaced by sys/health. The
+// "warnings" metadata of the event lists the warnings, replacing the ones the
+// mount reported before; an empty list clears them.
+const EventTypeHealthWarnings EventType = "health/warnings"
+
+// EventTypeHeartbeat is the type of the events the event bus periodically
+// sends to the subscribers of each topic, so that they can tell they are
+// still connected, and so that subscribers which stopped receiving events are
+// detected. The "topic" metadata of the event holds the subscribed pattern.
+const EventTypeHeartbeat EventType = "heartbeat"
+
 // EventSender sends events to the common event bus.
 type EventSender interface {
 	Send(ctx context.Context, eventType EventType, event *EventData) error
@@ -159,6 +159,30 @@ type Paths struct {
 	// On standby nodes, like all storage write operations, this will trigger
 	// an ErrReadOnly return.
 	WriteForwardedStorage []string
+
+	// Limits are the concurrency limits and costs of the requests to the
+	// paths of the backend, which core enforces when admitting requests.
+	// Requests beyond them are rejected with a 429 status code. The first
+	// limit whose pattern matches a path applies to it.
+	Limits []*PathLimit
+
+	// MaxConcurrentCost limits the total cost of the requests to the backend
+	// handled at once. Zero means no limit.
+	MaxConcurrentCost int
+}
+
+// PathLimit is the concurrency limit and cost of the requests to the paths
+// matching Pattern, a regular expression relative to the mount.
+type PathLimit struct {
+	Pattern string
+
+	// MaxConcurrency limits how many requests to the paths are handled at
+	// once. Zero means no limit.
+	MaxConcurrency int
+
+	// Cost is how expensive requests to the paths are to handle, counted
+	// against the MaxConcurrentCost of the backend. Defaults to 1.
+	Cost int
 }
 
 type Auditor interface {
@@ -0,0 +1,88 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package logical
+
+import (
+	"context"
+	"sync"
+)
+
+// Backends should stop working on a request once the context of the request
+// is done: its deadline is the deadline of the client's request, and it is
+// canceled when the client disconnects. This holds for external plugins too,
+// as the deadline and cancellation of the context are propagated to the
+// plugin process over gRPC.
+//
+// Long-running operations may report their progress with ReportProgress, so
+// that the progress they made is logged when they are interrupted.
+
+// Progress is the progress of a long-running operation.
+type Progress struct {
+	// Done and Total count the units of work of the operation that are done
+	// and to be done. Total is zero when it is unknown.
+	Done  int64
+	Total int64
+
+	// Message describes the current stage of the operation.
+	Message string
+}
+
+// ProgressTracker holds the progress reported while a request is handled.
+type ProgressTracker struct {
+	l        sync.Mutex
+	progress Progress
+	reported bool
+}
+
+type ctxKeyProgressTracker struct{}
+
+func (c ctxKeyProgressTracker) String() string {
+	return "progress-tracker"
+}
+
+// ContextWithProgressTracker returns a context holding a new tracker of the
+// progress reported by the handler of a request.
+func ContextWithProgressTracker(ctx context.Context) (context.Context, *ProgressTracker) {
+	t := &ProgressTracker{}
+	return context.WithValue(ctx, ctxKeyProgressTracker{}, t), t
+}
+
+// ReportProgress records the progress of the operation handling the request
+// of the context. It is a no-op when the progress of the request isn't
+// tracked.
+func ReportProgress(ctx context.Context, progress Progress) {
+	t, ok := ctx.Value(ctxKeyProgressTracker{}).(*ProgressTracker)
+	if !ok || t == nil {
+		return
+	}
+
+	t.l.Lock()
+	defer t.l.Unlock()
+	t.progress = progress
+	t.reported = true
+}
+
+// Progress returns the last progress reported, and whether any progress was
+// reported.
+func (t *ProgressTracker) Progress() (Progress, bool) {
+	if t == nil {
+		return Progress{}, false
+	}
+
+	t.l.Lock()
+	defer t.l.Unlock()
+	return t.progress, t.reported
+}
+
+// LogArgs returns the progress as key/value pairs for a structured logger.
+func (p Progress) LogArgs() []interface{} {
+	args := []interface{}{"progress_done", p.Done}
+	if p.Total > 0 {
+		args = append(args, "progress_total", p.Total)
+	}
+	if p.Message != "" {
+		args = append(args, "progress_message", p.Message)
+	}
+	return args
+}
@@ -0,0 +1,35 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package logical
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestReportProgress(t *testing.T) {
+	// Reporting progress without a tracker is a no-op.
+	ReportProgress(context.Background(), Progress{Done: 1})
+
+	ctx, tracker := ContextWithProgressTracker(context.Background())
+	if _, ok := tracker.Progress(); ok {
+		t.Fatal("expected no progress before any is reported")
+	}
+
+	ReportProgress(ctx, Progress{Done: 1, Total: 10, Message: "copying"})
+	ReportProgress(ctx, Progress{Done: 3, Total: 10, Message: "copying"})
+	progress, ok := tracker.Progress()
+	if !ok {
+		t.Fatal("expected progress to be reported")
+	}
+	if expected := (Progress{Done: 3, Total: 10, Message: "copying"}); progress != expected {
+		t.Fatalf("expected %#v, got %#v", expected, progress)
+	}
+
+	args := Progress{Done: 3}.LogArgs()
+	if expected := []interface{}{"progress_done", int64(3)}; !reflect.DeepEqual(args, expected) {
+		t.Fatalf("expected %#v, got %#v", expected, args)
+	}
+}
@@ -130,6 +130,10 @@ func RespondErrorCommon(req *Request, resp *Response, err error) (int, error) {
 			statusCode = http.StatusTooManyRequests
 		case errwrap.Contains(err, ErrLeaseCountQuotaExceeded.Error()):
 			statusCode = http.StatusTooManyRequests
+		case errwrap.Contains(err, ErrTooManyConcurrentRequests.Error()):
+			statusCode = http.StatusTooManyRequests
+		case errwrap.Contains(err, ErrRequestTooLarge.Error()):
+			statusCode = http.StatusRequestEntityTooLarge
 		case errwrap.Contains(err, ErrMissingRequiredState.Error()):
 			statusCode = http.StatusPreconditionFailed
 		case errwrap.Contains(err, ErrPathFunctionalityRemoved.Error()):
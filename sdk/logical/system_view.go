@@ -83,6 +83,24 @@ type SystemView interface {
 	// entity id
 	GroupsForEntity(entityID string) ([]*Group, error)
 
+	// AliasesByMount returns the aliases of the provided entity id on the
+	// mount with the provided accessor, so that auth plugins can tell whether
+	// the entity already has an alias on their mount
+	AliasesByMount(ctx context.Context, entityID, mountAccessor string) ([]*Alias, error)
+
+	// MFAConstraintsByMount returns the login MFA constraints enforced on
+	// every login to the mount with the provided accessor, keyed by the name
+	// of their enforcement, so that auth plugins can tell clients ahead of a
+	// login that MFA will be required
+	MFAConstraintsByMount(ctx context.Context, mountAccessor string) (map[string]*MFAConstraintAny, error)
+
+	// GenerateIdentityToken returns an identity token for the entity of the
+	// request with the given ID, which must be in flight on the mount, issued
+	// through the identity OIDC role an operator configured on the mount, so
+	// that plugins can federate the identity of the entity with external
+	// systems
+	GenerateIdentityToken(ctx context.Context, requestID string) (string, error)
+
 	// PluginEnv returns Vault environment information used by plugins
 	PluginEnv(context.Context) (*PluginEnvironment, error)
 
@@ -123,6 +141,8 @@ type StaticSystemView struct {
 	ReplicationStateVal consts.ReplicationState
 	EntityVal           *Entity
 	GroupsVal           []*Group
+	MFAConstraintsVal   map[string]*MFAConstraintAny
+	IdentityTokenVal    string
 	Features            license.Features
 	PluginEnvironment   *PluginEnvironment
 	PasswordPolicies    map[string]PasswordGenerator
@@ -208,6 +228,28 @@ func (d StaticSystemView) GroupsForEntity(entityID string) ([]*Group, error) {
 	return d.GroupsVal, nil
 }
 
+func (d StaticSystemView) AliasesByMount(_ context.Context, entityID, mountAccessor string) ([]*Alias, error) {
+	if d.EntityVal == nil {
+		return nil, nil
+	}
+
+	var aliases []*Alias
+	for _, alias := range d.EntityVal.Aliases {
+		if alias.MountAccessor == mountAccessor {
+			aliases = append(aliases, alias)
+		}
+	}
+	return aliases, nil
+}
+
+func (d StaticSystemView) MFAConstraintsByMount(_ context.Context, _ string) (map[string]*MFAConstraintAny, error) {
+	return d.MFAConstraintsVal, nil
+}
+
+func (d StaticSystemView) GenerateIdentityToken(_ context.Context, _ string) (string, error) {
+	return d.IdentityTokenVal, nil
+}
+
 func (d StaticSystemView) HasFeature(feature license.Features) bool {
 	return d.Features.HasFeature(feature)
 }
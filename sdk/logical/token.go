@@ -163,9 +163,17 @@ type TokenEntry struct {
 	// identity policies from the associated EntityID.
 	NoIdentityPolicies bool `json:"no_identity_policies" mapstructure:"no_identity_policies" structs:"no_identity_policies"`
 
+	// Impersonator is set on tokens issued by the token store's impersonate
+	// endpoint, and identifies the operator acting as the token's entity.
+	Impersonator *Impersonator `json:"impersonator,omitempty" mapstructure:"impersonator" structs:"impersonator" sentinel:""`
+
 	// The set of CIDRs that this token can be used with
 	BoundCIDRs []*sockaddr.SockAddrMarshaler `json:"bound_cidrs" sentinel:""`
 
+	// The SHA-256 fingerprint of the TLS client certificate that requests
+	// using this token must present, if set
+	BoundClientCertFingerprint string `json:"bound_client_cert_fingerprint,omitempty" mapstructure:"bound_client_cert_fingerprint" structs:"bound_client_cert_fingerprint" sentinel:""`
+
 	// NamespaceID is the identifier of the namespace to which this token is
 	// confined to. Do not return this value over the API when the token is
 	// being looked up.
@@ -176,6 +184,21 @@ type TokenEntry struct {
 	CubbyholeID string `json:"cubbyhole_id" mapstructure:"cubbyhole_id" structs:"cubbyhole_id" sentinel:""`
 }
 
+// Impersonator identifies the operator that obtained an impersonation token,
+// that is, a token acting as another entity with a reduced set of policies.
+type Impersonator struct {
+	// EntityID is the ID of the operator's entity, if any
+	EntityID string `json:"entity_id" mapstructure:"entity_id" structs:"entity_id"`
+
+	// Accessor is the accessor of the token used to obtain the impersonation
+	// token
+	Accessor string `json:"accessor" mapstructure:"accessor" structs:"accessor"`
+
+	// DisplayName is the display name of the token used to obtain the
+	// impersonation token
+	DisplayName string `json:"display_name" mapstructure:"display_name" structs:"display_name"`
+}
+
 // CreateClientID returns the client ID, and a boolean which is false if the clientID
 // has an entity, and true otherwise
 func (te *TokenEntry) CreateClientID() (string, bool) {
@@ -79,6 +79,8 @@ type TransactionalCache struct {
 var (
 	_ ToggleablePurgemonster = (*Cache)(nil)
 	_ ToggleablePurgemonster = (*TransactionalCache)(nil)
+	_ HotKeysReporter        = (*Cache)(nil)
+	_ HotKeysReporter        = (*TransactionalCache)(nil)
 	_ Backend                = (*Cache)(nil)
 	_ Transactional          = (*TransactionalCache)(nil)
 )
@@ -147,6 +149,21 @@ func (c *Cache) Purge(ctx context.Context) {
 	c.lru.Purge()
 }
 
+// HotKeys returns up to n of the keys held in the cache, keys that were read
+// more than once first. If n is not positive, all keys are returned.
+func (c *Cache) HotKeys(n int) []string {
+	raw := c.lru.Keys()
+	if n > 0 && len(raw) > n {
+		raw = raw[:n]
+	}
+
+	keys := make([]string, 0, len(raw))
+	for _, key := range raw {
+		keys = append(keys, key.(string))
+	}
+	return keys
+}
+
 func (c *Cache) Put(ctx context.Context, entry *Entry) error {
 	if entry != nil && !c.ShouldCache(entry.Key) {
 		return c.backend.Put(ctx, entry)
@@ -68,6 +68,12 @@ type ToggleablePurgemonster interface {
 	SetEnabled(bool)
 }
 
+// HotKeysReporter is an optional interface for caches that can report the keys
+// they hold, most frequently used first. This is only used for the cache.
+type HotKeysReporter interface {
+	HotKeys(n int) []string
+}
+
 // RedirectDetect is an optional interface that an HABackend
 // can implement. If they do, a redirect address can be automatically
 // detected.
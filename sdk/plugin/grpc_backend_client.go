@@ -6,6 +6,7 @@ package plugin
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math"
 	"sync/atomic"
 
@@ -95,6 +96,8 @@ func (b *backendGRPCPluginClient) HandleRequest(ctx context.Context, req *logica
 		return nil, err
 	}
 
+	// The deadline and cancellation of the context are propagated to the
+	// plugin, which stops handling the request along with Vault.
 	reply, err := b.client.HandleRequest(ctx, &pb.HandleRequestArgs{
 		Request: protoReq,
 	}, largeMsgGRPCCallOpts...)
@@ -102,6 +105,11 @@ func (b *backendGRPCPluginClient) HandleRequest(ctx context.Context, req *logica
 		if b.doneCtx.Err() != nil {
 			return nil, ErrPluginShutdown
 		}
+		if ctx.Err() != nil {
+			// Report the gRPC status of the interrupted call as the error of
+			// the context, so that callers can check it with errors.Is.
+			return nil, fmt.Errorf("plugin request interrupted: %w", ctx.Err())
+		}
 
 		return nil, err
 	}
@@ -132,6 +140,8 @@ func (b *backendGRPCPluginClient) SpecialPaths() *logical.Paths {
 		LocalStorage:          reply.Paths.LocalStorage,
 		SealWrapStorage:       reply.Paths.SealWrapStorage,
 		WriteForwardedStorage: reply.Paths.WriteForwardedStorage,
+		Limits:                pb.ProtoPathLimitsToLogicalPathLimits(reply.Paths.Limits),
+		MaxConcurrentCost:     int(reply.Paths.MaxConcurrentCost),
 	}
 }
 
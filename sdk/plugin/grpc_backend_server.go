@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	log "github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-plugin"
@@ -142,7 +143,14 @@ func (b *backendGRPCPluginServer) HandleRequest(ctx context.Context, args *pb.Ha
 
 	logicalReq.Storage = newGRPCStorageClient(brokeredClient)
 
+	// The context carries the deadline of the request, and is canceled when
+	// Vault cancels it, typically because the client disconnected.
+	ctx, tracker := logical.ContextWithProgressTracker(ctx)
+	start := time.Now()
 	resp, respErr := backend.HandleRequest(ctx, logicalReq)
+	if ctx.Err() != nil {
+		b.logInterruptedRequest(ctx, logicalReq, tracker, time.Since(start))
+	}
 
 	pbResp, err := pb.LogicalResponseToProtoResponse(resp)
 	if err != nil {
@@ -155,6 +163,21 @@ func (b *backendGRPCPluginServer) HandleRequest(ctx context.Context, args *pb.Ha
 	}, nil
 }
 
+// logInterruptedRequest logs a request whose context was canceled or expired
+// while it was handled, with the progress reported by the backend, so that
+// operators can tell how far the operation went.
+func (b *backendGRPCPluginServer) logInterruptedRequest(ctx context.Context, req *logical.Request, tracker *logical.ProgressTracker, elapsed time.Duration) {
+	if b.logger == nil {
+		return
+	}
+
+	args := []interface{}{"path", req.Path, "operation", req.Operation, "error", ctx.Err(), "elapsed", elapsed}
+	if progress, ok := tracker.Progress(); ok {
+		args = append(args, progress.LogArgs()...)
+	}
+	b.logger.Warn("request interrupted", args...)
+}
+
 func (b *backendGRPCPluginServer) Initialize(ctx context.Context, _ *pb.InitializeArgs) (*pb.InitializeReply, error) {
 	backend, brokeredClient, err := b.getBackendAndBrokeredClient(ctx)
 	if err != nil {
@@ -196,6 +219,8 @@ func (b *backendGRPCPluginServer) SpecialPaths(ctx context.Context, args *pb.Emp
 			LocalStorage:          paths.LocalStorage,
 			SealWrapStorage:       paths.SealWrapStorage,
 			WriteForwardedStorage: paths.WriteForwardedStorage,
+			Limits:                pb.LogicalPathLimitsToProtoPathLimits(paths.Limits),
+			MaxConcurrentCost:     int64(paths.MaxConcurrentCost),
 		},
 	}, nil
 }
@@ -5,6 +5,7 @@ package plugin
 
 import (
 	"context"
+	"errors"
 	"os"
 	"testing"
 	"time"
@@ -40,6 +41,25 @@ func TestGRPCBackendPlugin_HandleRequest(t *testing.T) {
 	}
 }
 
+// TestGRPCBackendPlugin_HandleRequest_Deadline ensures that the deadline of a
+// request reaches the plugin, which stops handling the request when it
+// expires.
+func TestGRPCBackendPlugin_HandleRequest_Deadline(t *testing.T) {
+	b, cleanup := testGRPCBackend(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "slow",
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline to be exceeded, got %v", err)
+	}
+}
+
 func TestGRPCBackendPlugin_SpecialPaths(t *testing.T) {
 	b, cleanup := testGRPCBackend(t)
 	defer cleanup()
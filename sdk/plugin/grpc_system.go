@@ -173,6 +173,49 @@ func (s *gRPCSystemViewClient) GroupsForEntity(entityID string) ([]*logical.Grou
 	return reply.Groups, nil
 }
 
+func (s *gRPCSystemViewClient) AliasesByMount(ctx context.Context, entityID, mountAccessor string) ([]*logical.Alias, error) {
+	reply, err := s.client.AliasesByMount(ctx, &pb.AliasesByMountArgs{
+		EntityID:      entityID,
+		MountAccessor: mountAccessor,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reply.Err != "" {
+		return nil, errors.New(reply.Err)
+	}
+
+	return reply.Aliases, nil
+}
+
+func (s *gRPCSystemViewClient) MFAConstraintsByMount(ctx context.Context, mountAccessor string) (map[string]*logical.MFAConstraintAny, error) {
+	reply, err := s.client.MFAConstraintsByMount(ctx, &pb.MFAConstraintsByMountArgs{
+		MountAccessor: mountAccessor,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reply.Err != "" {
+		return nil, errors.New(reply.Err)
+	}
+
+	return reply.MFAConstraints, nil
+}
+
+func (s *gRPCSystemViewClient) GenerateIdentityToken(ctx context.Context, requestID string) (string, error) {
+	reply, err := s.client.GenerateIdentityToken(ctx, &pb.GenerateIdentityTokenArgs{
+		RequestID: requestID,
+	})
+	if err != nil {
+		return "", err
+	}
+	if reply.Err != "" {
+		return "", errors.New(reply.Err)
+	}
+
+	return reply.Token, nil
+}
+
 func (s *gRPCSystemViewClient) PluginEnv(ctx context.Context) (*logical.PluginEnvironment, error) {
 	reply, err := s.client.PluginEnv(ctx, &pb.Empty{})
 	if err != nil {
@@ -345,6 +388,51 @@ func (s *gRPCSystemViewServer) GroupsForEntity(ctx context.Context, args *pb.Ent
 	}, nil
 }
 
+func (s *gRPCSystemViewServer) AliasesByMount(ctx context.Context, args *pb.AliasesByMountArgs) (*pb.AliasesByMountReply, error) {
+	if s.impl == nil {
+		return nil, errMissingSystemView
+	}
+	aliases, err := s.impl.AliasesByMount(ctx, args.EntityID, args.MountAccessor)
+	if err != nil {
+		return &pb.AliasesByMountReply{
+			Err: pb.ErrToString(err),
+		}, nil
+	}
+	return &pb.AliasesByMountReply{
+		Aliases: aliases,
+	}, nil
+}
+
+func (s *gRPCSystemViewServer) MFAConstraintsByMount(ctx context.Context, args *pb.MFAConstraintsByMountArgs) (*pb.MFAConstraintsByMountReply, error) {
+	if s.impl == nil {
+		return nil, errMissingSystemView
+	}
+	constraints, err := s.impl.MFAConstraintsByMount(ctx, args.MountAccessor)
+	if err != nil {
+		return &pb.MFAConstraintsByMountReply{
+			Err: pb.ErrToString(err),
+		}, nil
+	}
+	return &pb.MFAConstraintsByMountReply{
+		MFAConstraints: constraints,
+	}, nil
+}
+
+func (s *gRPCSystemViewServer) GenerateIdentityToken(ctx context.Context, args *pb.GenerateIdentityTokenArgs) (*pb.GenerateIdentityTokenReply, error) {
+	if s.impl == nil {
+		return nil, errMissingSystemView
+	}
+	token, err := s.impl.GenerateIdentityToken(ctx, args.RequestID)
+	if err != nil {
+		return &pb.GenerateIdentityTokenReply{
+			Err: pb.ErrToString(err),
+		}, nil
+	}
+	return &pb.GenerateIdentityTokenReply{
+		Token: token,
+	}, nil
+}
+
 func (s *gRPCSystemViewServer) PluginEnv(ctx context.Context, _ *pb.Empty) (*pb.PluginEnvReply, error) {
 	if s.impl == nil {
 		return nil, errMissingSystemView
@@ -15,6 +15,7 @@ import (
 	"github.com/hashicorp/vault/sdk/plugin/pb"
 	"google.golang.org/grpc"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestSystem_GRPC_ReturnsErrIfSystemViewNil(t *testing.T) {
@@ -169,7 +170,10 @@ func TestSystem_GRPC_entityInfo(t *testing.T) {
 				},
 			},
 		},
-		Disabled: true,
+		Disabled:       true,
+		CreationTime:   timestamppb.New(time.Unix(1690000000, 0)),
+		LastUpdateTime: timestamppb.New(time.Unix(1690000100, 0)),
+		Policies:       []string{"dev", "ops"},
 	}
 	client, _ := plugin.TestGRPCConn(t, func(s *grpc.Server) {
 		pb.RegisterSystemViewServer(s, &gRPCSystemViewServer{
@@ -197,6 +201,9 @@ func TestSystem_GRPC_groupsForEntity(t *testing.T) {
 			Metadata: map[string]string{
 				"group-metadata": "metadata-value",
 			},
+			ParentGroupIDs:  []string{"group2-id"},
+			MemberEntityIDs: []string{"entity1-id"},
+			Type:            "internal",
 		},
 	}
 	client, _ := plugin.TestGRPCConn(t, func(s *grpc.Server) {
@@ -216,6 +223,97 @@ func TestSystem_GRPC_groupsForEntity(t *testing.T) {
 	}
 }
 
+func TestSystem_GRPC_aliasesByMount(t *testing.T) {
+	sys := logical.TestSystemView()
+	sys.EntityVal = &logical.Entity{
+		ID: "id",
+		Aliases: []*logical.Alias{
+			{
+				MountAccessor: "auth_userpass_1234",
+				Name:          "alias1",
+			},
+			{
+				MountAccessor: "auth_approle_5678",
+				Name:          "alias2",
+			},
+		},
+	}
+	client, _ := plugin.TestGRPCConn(t, func(s *grpc.Server) {
+		pb.RegisterSystemViewServer(s, &gRPCSystemViewServer{
+			impl: sys,
+		})
+	})
+	defer client.Close()
+	testSystemView := newGRPCSystemView(client)
+
+	actual, err := testSystemView.AliasesByMount(context.Background(), "id", "auth_approle_5678")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actual) != 1 || !proto.Equal(sys.EntityVal.Aliases[1], actual[0]) {
+		t.Fatalf("expected: %v, got: %v", sys.EntityVal.Aliases[1:], actual)
+	}
+
+	actual, err = testSystemView.AliasesByMount(context.Background(), "id", "auth_token_0000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actual) != 0 {
+		t.Fatalf("expected no aliases, got: %v", actual)
+	}
+}
+
+func TestSystem_GRPC_mfaConstraintsByMount(t *testing.T) {
+	sys := logical.TestSystemView()
+	sys.MFAConstraintsVal = map[string]*logical.MFAConstraintAny{
+		"totp-enforcement": {
+			Any: []*logical.MFAMethodID{
+				{
+					Type:         "totp",
+					ID:           "method-id",
+					UsesPasscode: true,
+					Name:         "totp",
+				},
+			},
+		},
+	}
+	client, _ := plugin.TestGRPCConn(t, func(s *grpc.Server) {
+		pb.RegisterSystemViewServer(s, &gRPCSystemViewServer{
+			impl: sys,
+		})
+	})
+	defer client.Close()
+	testSystemView := newGRPCSystemView(client)
+
+	actual, err := testSystemView.MFAConstraintsByMount(context.Background(), "auth_userpass_1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actual) != 1 || !proto.Equal(sys.MFAConstraintsVal["totp-enforcement"], actual["totp-enforcement"]) {
+		t.Fatalf("expected: %v, got: %v", sys.MFAConstraintsVal, actual)
+	}
+}
+
+func TestSystem_GRPC_generateIdentityToken(t *testing.T) {
+	sys := logical.TestSystemView()
+	sys.IdentityTokenVal = "eyJhbGciOiJSUzI1NiJ9.e30.c2lnbmF0dXJl"
+	client, _ := plugin.TestGRPCConn(t, func(s *grpc.Server) {
+		pb.RegisterSystemViewServer(s, &gRPCSystemViewServer{
+			impl: sys,
+		})
+	})
+	defer client.Close()
+	testSystemView := newGRPCSystemView(client)
+
+	actual, err := testSystemView.GenerateIdentityToken(context.Background(), "request-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual != sys.IdentityTokenVal {
+		t.Fatalf("expected: %v, got: %v", sys.IdentityTokenVal, actual)
+	}
+}
+
 func TestSystem_GRPC_pluginEnv(t *testing.T) {
 	sys := logical.TestSystemView()
 	sys.PluginEnvironment = &logical.PluginEnvironment{
@@ -53,6 +53,7 @@ func Backend() *backend {
 				pathInternal(&b),
 				pathSpecial(&b),
 				pathRaw(&b),
+				pathSlow(&b),
 			},
 		),
 		PathsSpecial: &logical.Paths{
@@ -0,0 +1,41 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package mock
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// pathSlow is used to test the propagation of the deadline and cancellation
+// of requests. It reports progress until the request is interrupted.
+func pathSlow(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "slow",
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation: b.pathSlowRead,
+		},
+	}
+}
+
+func (b *backend) pathSlowRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		return nil, errors.New("request has no deadline")
+	}
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for done := int64(1); ; done++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+			logical.ReportProgress(ctx, logical.Progress{Done: done, Message: "waiting"})
+		}
+	}
+}
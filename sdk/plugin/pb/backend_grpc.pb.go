@@ -644,6 +644,16 @@ type SystemViewClient interface {
 	GeneratePasswordFromPolicy(ctx context.Context, in *GeneratePasswordFromPolicyRequest, opts ...grpc.CallOption) (*GeneratePasswordFromPolicyReply, error)
 	// ClusterInfo returns the ClusterID information; may be reused if ClusterName is also exposed.
 	ClusterInfo(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ClusterInfoReply, error)
+	// AliasesByMount returns the aliases of the given entity on the mount
+	// with the given accessor
+	AliasesByMount(ctx context.Context, in *AliasesByMountArgs, opts ...grpc.CallOption) (*AliasesByMountReply, error)
+	// MFAConstraintsByMount returns the login MFA constraints enforced on
+	// every login to the mount with the given accessor
+	MFAConstraintsByMount(ctx context.Context, in *MFAConstraintsByMountArgs, opts ...grpc.CallOption) (*MFAConstraintsByMountReply, error)
+	// GenerateIdentityToken returns an identity token for the entity of the
+	// request with the given ID, issued through the identity OIDC role
+	// configured on the mount
+	GenerateIdentityToken(ctx context.Context, in *GenerateIdentityTokenArgs, opts ...grpc.CallOption) (*GenerateIdentityTokenReply, error)
 }
 
 type systemViewClient struct {
@@ -771,6 +781,33 @@ func (c *systemViewClient) ClusterInfo(ctx context.Context, in *Empty, opts ...g
 	return out, nil
 }
 
+func (c *systemViewClient) AliasesByMount(ctx context.Context, in *AliasesByMountArgs, opts ...grpc.CallOption) (*AliasesByMountReply, error) {
+	out := new(AliasesByMountReply)
+	err := c.cc.Invoke(ctx, "/pb.SystemView/AliasesByMount", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *systemViewClient) MFAConstraintsByMount(ctx context.Context, in *MFAConstraintsByMountArgs, opts ...grpc.CallOption) (*MFAConstraintsByMountReply, error) {
+	out := new(MFAConstraintsByMountReply)
+	err := c.cc.Invoke(ctx, "/pb.SystemView/MFAConstraintsByMount", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *systemViewClient) GenerateIdentityToken(ctx context.Context, in *GenerateIdentityTokenArgs, opts ...grpc.CallOption) (*GenerateIdentityTokenReply, error) {
+	out := new(GenerateIdentityTokenReply)
+	err := c.cc.Invoke(ctx, "/pb.SystemView/GenerateIdentityToken", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SystemViewServer is the server API for SystemView service.
 // All implementations must embed UnimplementedSystemViewServer
 // for forward compatibility
@@ -815,6 +852,16 @@ type SystemViewServer interface {
 	GeneratePasswordFromPolicy(context.Context, *GeneratePasswordFromPolicyRequest) (*GeneratePasswordFromPolicyReply, error)
 	// ClusterInfo returns the ClusterID information; may be reused if ClusterName is also exposed.
 	ClusterInfo(context.Context, *Empty) (*ClusterInfoReply, error)
+	// AliasesByMount returns the aliases of the given entity on the mount
+	// with the given accessor
+	AliasesByMount(context.Context, *AliasesByMountArgs) (*AliasesByMountReply, error)
+	// MFAConstraintsByMount returns the login MFA constraints enforced on
+	// every login to the mount with the given accessor
+	MFAConstraintsByMount(context.Context, *MFAConstraintsByMountArgs) (*MFAConstraintsByMountReply, error)
+	// GenerateIdentityToken returns an identity token for the entity of the
+	// request with the given ID, issued through the identity OIDC role
+	// configured on the mount
+	GenerateIdentityToken(context.Context, *GenerateIdentityTokenArgs) (*GenerateIdentityTokenReply, error)
 	mustEmbedUnimplementedSystemViewServer()
 }
 
@@ -861,6 +908,15 @@ func (UnimplementedSystemViewServer) GeneratePasswordFromPolicy(context.Context,
 func (UnimplementedSystemViewServer) ClusterInfo(context.Context, *Empty) (*ClusterInfoReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ClusterInfo not implemented")
 }
+func (UnimplementedSystemViewServer) AliasesByMount(context.Context, *AliasesByMountArgs) (*AliasesByMountReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AliasesByMount not implemented")
+}
+func (UnimplementedSystemViewServer) MFAConstraintsByMount(context.Context, *MFAConstraintsByMountArgs) (*MFAConstraintsByMountReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MFAConstraintsByMount not implemented")
+}
+func (UnimplementedSystemViewServer) GenerateIdentityToken(context.Context, *GenerateIdentityTokenArgs) (*GenerateIdentityTokenReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GenerateIdentityToken not implemented")
+}
 func (UnimplementedSystemViewServer) mustEmbedUnimplementedSystemViewServer() {}
 
 // UnsafeSystemViewServer may be embedded to opt out of forward compatibility for this service.
@@ -1108,6 +1164,60 @@ func _SystemView_ClusterInfo_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SystemView_AliasesByMount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AliasesByMountArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SystemViewServer).AliasesByMount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.SystemView/AliasesByMount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SystemViewServer).AliasesByMount(ctx, req.(*AliasesByMountArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SystemView_MFAConstraintsByMount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MFAConstraintsByMountArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SystemViewServer).MFAConstraintsByMount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.SystemView/MFAConstraintsByMount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SystemViewServer).MFAConstraintsByMount(ctx, req.(*MFAConstraintsByMountArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SystemView_GenerateIdentityToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GenerateIdentityTokenArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SystemViewServer).GenerateIdentityToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.SystemView/GenerateIdentityToken",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SystemViewServer).GenerateIdentityToken(ctx, req.(*GenerateIdentityTokenArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // SystemView_ServiceDesc is the grpc.ServiceDesc for SystemView service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -1167,6 +1277,18 @@ var SystemView_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ClusterInfo",
 			Handler:    _SystemView_ClusterInfo_Handler,
 		},
+		{
+			MethodName: "AliasesByMount",
+			Handler:    _SystemView_AliasesByMount_Handler,
+		},
+		{
+			MethodName: "MFAConstraintsByMount",
+			Handler:    _SystemView_MFAConstraintsByMount_Handler,
+		},
+		{
+			MethodName: "GenerateIdentityToken",
+			Handler:    _SystemView_GenerateIdentityToken_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "sdk/plugin/pb/backend.proto",
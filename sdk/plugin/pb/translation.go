@@ -211,6 +211,38 @@ func LogicalSecretToProtoSecret(s *logical.Secret) (*Secret, error) {
 	}, err
 }
 
+func LogicalPathLimitsToProtoPathLimits(l []*logical.PathLimit) []*PathLimit {
+	if l == nil {
+		return nil
+	}
+
+	limits := make([]*PathLimit, 0, len(l))
+	for _, limit := range l {
+		limits = append(limits, &PathLimit{
+			Pattern:        limit.Pattern,
+			MaxConcurrency: int64(limit.MaxConcurrency),
+			Cost:           int64(limit.Cost),
+		})
+	}
+	return limits
+}
+
+func ProtoPathLimitsToLogicalPathLimits(l []*PathLimit) []*logical.PathLimit {
+	if l == nil {
+		return nil
+	}
+
+	limits := make([]*logical.PathLimit, 0, len(l))
+	for _, limit := range l {
+		limits = append(limits, &logical.PathLimit{
+			Pattern:        limit.Pattern,
+			MaxConcurrency: int(limit.MaxConcurrency),
+			Cost:           int(limit.Cost),
+		})
+	}
+	return limits
+}
+
 func LogicalRequestToProtoRequest(r *logical.Request) (*Request, error) {
 	if r == nil {
 		return nil, nil
@@ -624,6 +656,8 @@ func LogicalTokenEntryToProtoTokenEntry(t *logical.TokenEntry) *TokenEntry {
 		CubbyholeID:        t.CubbyholeID,
 		Type:               uint32(t.Type),
 		ExternalID:         t.ExternalID,
+
+		BoundClientCertFingerprint: t.BoundClientCertFingerprint,
 	}
 }
 
@@ -665,6 +699,8 @@ func ProtoTokenEntryToLogicalTokenEntry(t *TokenEntry) (*logical.TokenEntry, err
 		CubbyholeID:        t.CubbyholeID,
 		Type:               logical.TokenType(t.Type),
 		ExternalID:         t.ExternalID,
+
+		BoundClientCertFingerprint: t.BoundClientCertFingerprint,
 	}, nil
 }
 
@@ -0,0 +1,454 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package rotation provides Vault plugins with a RotationManager, scheduling
+// the periodic rotation of static credentials. Schedules are kept in a
+// priority queue, persisted to storage so that they survive restarts, and
+// rotations can be spread with jitter and restricted to daily windows.
+package rotation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/vault/sdk/helper/clockutil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/sdk/queue"
+)
+
+const (
+	// DefaultRetryInterval is how long after a failed scheduled rotation the
+	// credential is rotated again, unless configured otherwise.
+	DefaultRetryInterval = time.Minute
+
+	day = 24 * time.Hour
+)
+
+var (
+	// ErrCredentialNotFound may be returned, or wrapped, by a RotateFunc when
+	// the credential no longer exists. Its schedule is then removed, rather
+	// than retried.
+	ErrCredentialNotFound = errors.New("credential not found")
+
+	// ErrNotScheduled is returned when rotating a credential which isn't
+	// scheduled.
+	ErrNotScheduled = errors.New("credential is not scheduled for rotation")
+)
+
+// RotateFunc rotates the credential with the given key.
+type RotateFunc func(ctx context.Context, s logical.Storage, key string) error
+
+// Hooks are called as credentials are rotated.
+type Hooks struct {
+	// OnSuccess, if set, is called after each successful rotation, once the
+	// next rotation is scheduled. Its error is returned by the rotation.
+	OnSuccess func(ctx context.Context, s logical.Storage, key string) error
+
+	// OnFailure, if set, is called after each failed scheduled rotation, with
+	// the time at which the rotation is retried.
+	OnFailure func(ctx context.Context, s logical.Storage, key string, err error, retry time.Time)
+}
+
+// Window restricts rotations to a daily window, in UTC. A rotation scheduled
+// outside of the window is postponed to the start of the next window.
+type Window struct {
+	// Start is the offset of the start of the window from midnight.
+	Start time.Duration `json:"start"`
+
+	// Duration is the length of the window, which may extend past midnight.
+	Duration time.Duration `json:"duration"`
+}
+
+func (w *Window) validate() error {
+	switch {
+	case w == nil:
+		return nil
+	case w.Start < 0 || w.Start >= day:
+		return fmt.Errorf("the start of the rotation window must be within a day")
+	case w.Duration <= 0 || w.Duration > day:
+		return fmt.Errorf("the duration of the rotation window must be positive and at most a day")
+	}
+	return nil
+}
+
+// next returns t if it falls within the window, and the start of the next
+// window otherwise.
+func (w *Window) next(t time.Time) time.Time {
+	if w == nil || w.Duration >= day {
+		return t
+	}
+
+	t = t.UTC()
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	// The window of the previous day may extend past midnight.
+	for _, d := range []time.Time{midnight.AddDate(0, 0, -1), midnight} {
+		start := d.Add(w.Start)
+		if !t.Before(start) && t.Before(start.Add(w.Duration)) {
+			return t
+		}
+	}
+
+	start := midnight.Add(w.Start)
+	if start.Before(t) {
+		start = start.AddDate(0, 0, 1)
+	}
+	return start
+}
+
+// Schedule is the schedule of the rotations of a credential.
+type Schedule struct {
+	// Period is the time between rotations.
+	Period time.Duration `json:"period"`
+
+	// Window, if set, restricts the rotations to a daily window.
+	Window *Window `json:"window,omitempty"`
+}
+
+func (s Schedule) validate() error {
+	if s.Period <= 0 {
+		return fmt.Errorf("the rotation period must be positive")
+	}
+	return s.Window.validate()
+}
+
+// entry is the persisted schedule of a credential.
+type entry struct {
+	Key          string    `json:"key"`
+	Schedule     Schedule  `json:"schedule"`
+	NextRotation time.Time `json:"next_rotation"`
+}
+
+// RotationManagerConfig configures a RotationManager.
+type RotationManagerConfig struct {
+	// StoragePrefix is the storage prefix the schedules are persisted under,
+	// such as "rotation/".
+	StoragePrefix string
+
+	// Rotate rotates the credentials, and is required.
+	Rotate RotateFunc
+
+	Hooks Hooks
+
+	// RetryInterval is how long after a failed scheduled rotation the
+	// credential is rotated again. Defaults to DefaultRetryInterval.
+	RetryInterval time.Duration
+
+	// Jitter is the longest random delay added to each scheduled rotation,
+	// so that credentials created together are not all rotated at once.
+	Jitter time.Duration
+
+	// Clock is the time source of the manager. Defaults to the system clock.
+	Clock clockutil.Clock
+}
+
+// RotationManager schedules the rotations of static credentials, identified
+// by keys. Due rotations are done by calling RotateExpired periodically,
+// typically from the PeriodicFunc of the backend.
+type RotationManager struct {
+	prefix        string
+	rotate        RotateFunc
+	hooks         Hooks
+	retryInterval time.Duration
+	jitter        time.Duration
+	clock         clockutil.Clock
+
+	// l serializes the changes of the queue, but isn't held while rotating.
+	l     sync.Mutex
+	queue *queue.PriorityQueue
+}
+
+// NewRotationManager returns a RotationManager with no scheduled rotations.
+// Initialize loads the persisted schedules.
+func NewRotationManager(config RotationManagerConfig) *RotationManager {
+	m := &RotationManager{
+		prefix:        config.StoragePrefix,
+		rotate:        config.Rotate,
+		hooks:         config.Hooks,
+		retryInterval: config.RetryInterval,
+		jitter:        config.Jitter,
+		clock:         config.Clock,
+		queue:         queue.New(),
+	}
+	if !strings.HasSuffix(m.prefix, "/") {
+		m.prefix += "/"
+	}
+	if m.rotate == nil {
+		m.rotate = func(context.Context, logical.Storage, string) error {
+			return errors.New("no rotate function configured")
+		}
+	}
+	if m.retryInterval <= 0 {
+		m.retryInterval = DefaultRetryInterval
+	}
+	if m.clock == nil {
+		m.clock = clockutil.DefaultClock{}
+	}
+	return m
+}
+
+// Initialize loads the schedules persisted in storage, replacing the ones
+// held by the manager.
+func (m *RotationManager) Initialize(ctx context.Context, s logical.Storage) error {
+	keys, err := s.List(ctx, m.prefix)
+	if err != nil {
+		return fmt.Errorf("failed to list rotation schedules: %w", err)
+	}
+
+	q := queue.New()
+	for _, key := range keys {
+		raw, err := s.Get(ctx, m.prefix+key)
+		if err != nil {
+			return fmt.Errorf("failed to read the rotation schedule of %q: %w", key, err)
+		}
+		if raw == nil {
+			continue
+		}
+		var e entry
+		if err := raw.DecodeJSON(&e); err != nil {
+			return fmt.Errorf("failed to decode the rotation schedule of %q: %w", key, err)
+		}
+		if err := q.Push(e.item()); err != nil {
+			return fmt.Errorf("failed to queue the rotation of %q: %w", key, err)
+		}
+	}
+
+	m.l.Lock()
+	m.queue = q
+	m.l.Unlock()
+	return nil
+}
+
+// Schedule schedules the rotations of the credential with the given key,
+// the next one a period from now. It replaces any existing schedule of the
+// credential.
+func (m *RotationManager) Schedule(ctx context.Context, s logical.Storage, key string, schedule Schedule) error {
+	if err := schedule.validate(); err != nil {
+		return err
+	}
+
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	if _, err := m.queue.PopByKey(key); err != nil {
+		return err
+	}
+	return m.push(ctx, s, &entry{
+		Key:          key,
+		Schedule:     schedule,
+		NextRotation: m.nextRotation(schedule),
+	})
+}
+
+// UpdateSchedule updates the schedule of the credential with the given key,
+// keeping the time of its next rotation. Credentials which aren't scheduled
+// yet are scheduled as with Schedule.
+func (m *RotationManager) UpdateSchedule(ctx context.Context, s logical.Storage, key string, schedule Schedule) error {
+	if err := schedule.validate(); err != nil {
+		return err
+	}
+
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	item, err := m.queue.PopByKey(key)
+	if err != nil {
+		return err
+	}
+	e := &entry{
+		Key:          key,
+		Schedule:     schedule,
+		NextRotation: m.nextRotation(schedule),
+	}
+	if item != nil {
+		e.NextRotation = schedule.Window.next(item.Value.(*entry).NextRotation)
+	}
+	return m.push(ctx, s, e)
+}
+
+// Unschedule removes the schedule of the credential with the given key.
+func (m *RotationManager) Unschedule(ctx context.Context, s logical.Storage, key string) error {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	if _, err := m.queue.PopByKey(key); err != nil {
+		return err
+	}
+	if err := s.Delete(ctx, m.prefix+key); err != nil {
+		return fmt.Errorf("failed to delete the rotation schedule of %q: %w", key, err)
+	}
+	return nil
+}
+
+// NextRotation returns the time of the next rotation of the credential with
+// the given key, and whether it is scheduled.
+func (m *RotationManager) NextRotation(key string) (time.Time, bool) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	item, err := m.queue.PopByKey(key)
+	if err != nil || item == nil {
+		return time.Time{}, false
+	}
+	m.queue.Push(item)
+	return item.Value.(*entry).NextRotation, true
+}
+
+// Len returns the number of scheduled credentials.
+func (m *RotationManager) Len() int {
+	m.l.Lock()
+	defer m.l.Unlock()
+	return m.queue.Len()
+}
+
+// RotateNow rotates the credential with the given key immediately. Its next
+// scheduled rotation is a full period later. If the rotation fails, the
+// existing schedule is kept.
+func (m *RotationManager) RotateNow(ctx context.Context, s logical.Storage, key string) error {
+	// Take the credential off the queue while rotating, so that the scheduled
+	// rotations don't rotate it concurrently.
+	m.l.Lock()
+	item, err := m.queue.PopByKey(key)
+	m.l.Unlock()
+	if err != nil {
+		return err
+	}
+	if item == nil {
+		return fmt.Errorf("%w: %q", ErrNotScheduled, key)
+	}
+	e := item.Value.(*entry)
+
+	if err := m.rotate(ctx, s, key); err != nil {
+		if errors.Is(err, ErrCredentialNotFound) {
+			return multierror.Append(err, m.Unschedule(ctx, s, key)).ErrorOrNil()
+		}
+		m.l.Lock()
+		pushErr := m.queue.Push(item)
+		m.l.Unlock()
+		if pushErr != nil {
+			return multierror.Append(err, fmt.Errorf("failed to queue the rotation of %q: %w", key, pushErr))
+		}
+		return err
+	}
+
+	return m.rotated(ctx, s, e)
+}
+
+// RotateExpired rotates the credentials whose rotation is due. Failed
+// rotations are retried after the retry interval.
+func (m *RotationManager) RotateExpired(ctx context.Context, s logical.Storage) error {
+	var errs *multierror.Error
+	for {
+		m.l.Lock()
+		item, err := m.queue.Pop()
+		if err == nil && item.Value.(*entry).NextRotation.After(m.clock.Now()) {
+			// Nothing else is due yet
+			err = m.queue.Push(item)
+			m.l.Unlock()
+			if err != nil {
+				errs = multierror.Append(errs, fmt.Errorf("failed to queue the rotation of %q: %w", item.Key, err))
+			}
+			break
+		}
+		m.l.Unlock()
+		if errors.Is(err, queue.ErrEmpty) {
+			break
+		}
+		if err != nil {
+			errs = multierror.Append(errs, err)
+			break
+		}
+
+		e := item.Value.(*entry)
+		if err := m.rotate(ctx, s, e.Key); err != nil {
+			if errors.Is(err, ErrCredentialNotFound) {
+				if err := s.Delete(ctx, m.prefix+e.Key); err != nil {
+					errs = multierror.Append(errs, fmt.Errorf("failed to delete the rotation schedule of %q: %w", e.Key, err))
+				}
+				continue
+			}
+
+			// Retry later, rather than dropping the credential from the queue
+			e.NextRotation = e.Schedule.Window.next(m.clock.Now().Add(m.retryInterval))
+			if m.hooks.OnFailure != nil {
+				m.hooks.OnFailure(ctx, s, e.Key, err, e.NextRotation)
+			}
+			errs = multierror.Append(errs, fmt.Errorf("failed to rotate %q: %w", e.Key, err))
+
+			m.l.Lock()
+			err = m.push(ctx, s, e)
+			m.l.Unlock()
+			if err != nil {
+				errs = multierror.Append(errs, err)
+			}
+			continue
+		}
+
+		if err := m.rotated(ctx, s, e); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+
+	if err := errs.ErrorOrNil(); err != nil {
+		return fmt.Errorf("error(s) occurred while rotating expired credentials: %w", err)
+	}
+	return nil
+}
+
+// rotated schedules the next rotation of a successfully rotated credential,
+// and calls the success hook.
+func (m *RotationManager) rotated(ctx context.Context, s logical.Storage, e *entry) error {
+	e.NextRotation = m.nextRotation(e.Schedule)
+
+	m.l.Lock()
+	err := m.push(ctx, s, e)
+	m.l.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if m.hooks.OnSuccess != nil {
+		return m.hooks.OnSuccess(ctx, s, e.Key)
+	}
+	return nil
+}
+
+// nextRotation returns the time of the next rotation of a schedule, a period
+// from now.
+func (m *RotationManager) nextRotation(schedule Schedule) time.Time {
+	next := m.clock.Now().Add(schedule.Period)
+	if m.jitter > 0 {
+		next = next.Add(time.Duration(rand.Int63n(int64(m.jitter))))
+	}
+	return schedule.Window.next(next)
+}
+
+// push queues and persists the entry. The lock must be held.
+func (m *RotationManager) push(ctx context.Context, s logical.Storage, e *entry) error {
+	if err := m.queue.Push(e.item()); err != nil {
+		return fmt.Errorf("failed to queue the rotation of %q: %w", e.Key, err)
+	}
+
+	raw, err := logical.StorageEntryJSON(m.prefix+e.Key, e)
+	if err != nil {
+		return err
+	}
+	if err := s.Put(ctx, raw); err != nil {
+		return fmt.Errorf("failed to persist the rotation schedule of %q: %w", e.Key, err)
+	}
+	return nil
+}
+
+func (e *entry) item() *queue.Item {
+	return &queue.Item{
+		Key:      e.Key,
+		Value:    e,
+		Priority: e.NextRotation.Unix(),
+	}
+}
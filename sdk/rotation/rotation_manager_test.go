@@ -0,0 +1,154 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package rotation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/helper/clockutil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRotationManager verifies that credentials are rotated once due, that
+// failed rotations are retried, and that schedules are persisted.
+func TestRotationManager(t *testing.T) {
+	ctx := context.Background()
+	s := &logical.InmemStorage{}
+	clock := clockutil.NewManualClock(time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC))
+
+	rotated := make(map[string]int)
+	failing := map[string]error{}
+	var succeeded, failed []string
+	config := RotationManagerConfig{
+		StoragePrefix: "rotation/",
+		Rotate: func(_ context.Context, _ logical.Storage, key string) error {
+			if err := failing[key]; err != nil {
+				return err
+			}
+			rotated[key]++
+			return nil
+		},
+		Hooks: Hooks{
+			OnSuccess: func(_ context.Context, _ logical.Storage, key string) error {
+				succeeded = append(succeeded, key)
+				return nil
+			},
+			OnFailure: func(_ context.Context, _ logical.Storage, key string, _ error, retry time.Time) {
+				failed = append(failed, key)
+				require.Equal(t, clock.Now().Add(DefaultRetryInterval), retry)
+			},
+		},
+		Clock: clock,
+	}
+	m := NewRotationManager(config)
+
+	require.Error(t, m.Schedule(ctx, s, "a", Schedule{}))
+	require.NoError(t, m.Schedule(ctx, s, "a", Schedule{Period: time.Hour}))
+	require.NoError(t, m.Schedule(ctx, s, "b", Schedule{Period: 2 * time.Hour}))
+	require.Equal(t, 2, m.Len())
+
+	// Nothing is due yet
+	require.NoError(t, m.RotateExpired(ctx, s))
+	require.Empty(t, rotated)
+
+	clock.Advance(time.Hour)
+	require.NoError(t, m.RotateExpired(ctx, s))
+	require.Equal(t, map[string]int{"a": 1}, rotated)
+	require.Equal(t, []string{"a"}, succeeded)
+	next, ok := m.NextRotation("a")
+	require.True(t, ok)
+	require.Equal(t, clock.Now().Add(time.Hour), next)
+
+	// Failed rotations are retried
+	failing["b"] = errors.New("access denied")
+	clock.Advance(time.Hour)
+	require.ErrorContains(t, m.RotateExpired(ctx, s), "access denied")
+	require.Equal(t, []string{"b"}, failed)
+	next, ok = m.NextRotation("b")
+	require.True(t, ok)
+	require.Equal(t, clock.Now().Add(DefaultRetryInterval), next)
+
+	// Schedules survive restarts
+	m = NewRotationManager(config)
+	require.NoError(t, m.Initialize(ctx, s))
+	require.Equal(t, 2, m.Len())
+	next, ok = m.NextRotation("b")
+	require.True(t, ok)
+	require.Equal(t, clock.Now().Add(DefaultRetryInterval), next)
+
+	// Updating a schedule keeps the next rotation
+	require.NoError(t, m.UpdateSchedule(ctx, s, "b", Schedule{Period: 3 * time.Hour}))
+	next, _ = m.NextRotation("b")
+	require.Equal(t, clock.Now().Add(DefaultRetryInterval), next)
+
+	// Rotating on demand reschedules a full period later, and keeps the
+	// schedule on failure
+	require.ErrorContains(t, m.RotateNow(ctx, s, "b"), "access denied")
+	next, _ = m.NextRotation("b")
+	require.Equal(t, clock.Now().Add(DefaultRetryInterval), next)
+	delete(failing, "b")
+	require.NoError(t, m.RotateNow(ctx, s, "b"))
+	next, _ = m.NextRotation("b")
+	require.Equal(t, clock.Now().Add(3*time.Hour), next)
+	require.ErrorIs(t, m.RotateNow(ctx, s, "c"), ErrNotScheduled)
+
+	// Credentials which no longer exist are unscheduled
+	failing["a"] = ErrCredentialNotFound
+	clock.Advance(time.Hour)
+	require.NoError(t, m.RotateExpired(ctx, s))
+	_, ok = m.NextRotation("a")
+	require.False(t, ok)
+
+	require.NoError(t, m.Unschedule(ctx, s, "b"))
+	require.Equal(t, 0, m.Len())
+	keys, err := s.List(ctx, "rotation/")
+	require.NoError(t, err)
+	require.Empty(t, keys)
+}
+
+// TestRotationManager_jitter verifies that rotations are delayed by at most
+// the jitter.
+func TestRotationManager_jitter(t *testing.T) {
+	clock := clockutil.NewManualClock(time.Now())
+	m := NewRotationManager(RotationManagerConfig{
+		StoragePrefix: "rotation",
+		Rotate:        func(context.Context, logical.Storage, string) error { return nil },
+		Jitter:        time.Minute,
+		Clock:         clock,
+	})
+
+	require.NoError(t, m.Schedule(context.Background(), &logical.InmemStorage{}, "a", Schedule{Period: time.Hour}))
+	next, ok := m.NextRotation("a")
+	require.True(t, ok)
+	require.False(t, next.Before(clock.Now().Add(time.Hour)))
+	require.True(t, next.Before(clock.Now().Add(time.Hour+time.Minute)))
+}
+
+// TestWindow verifies that rotations are postponed to the next window.
+func TestWindow(t *testing.T) {
+	at := func(hour int) time.Time {
+		return time.Date(2023, 6, 1, hour, 0, 0, 0, time.UTC)
+	}
+
+	// From 22:00 to 02:00
+	w := &Window{Start: 22 * time.Hour, Duration: 4 * time.Hour}
+	require.NoError(t, w.validate())
+	require.Equal(t, at(23), w.next(at(23)))
+	require.Equal(t, at(1), w.next(at(1)))
+	require.Equal(t, at(22), w.next(at(12)))
+	require.Equal(t, at(22), w.next(at(2)))
+
+	// From 02:00 to 04:00
+	w = &Window{Start: 2 * time.Hour, Duration: 2 * time.Hour}
+	require.Equal(t, at(2), w.next(at(1)))
+	require.Equal(t, at(2).AddDate(0, 0, 1), w.next(at(5)))
+
+	require.Equal(t, at(5), (*Window)(nil).next(at(5)))
+	require.Error(t, (&Window{Start: 25 * time.Hour, Duration: time.Hour}).validate())
+	require.Error(t, (&Window{Duration: -time.Hour}).validate())
+}
@@ -0,0 +1,782 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package mockcluster provides a lightweight, in-process stand-in for a Vault
+// server, so that plugin authors can write integration tests for their
+// backends without importing Vault's internal packages or running Docker.
+//
+// A Cluster mounts backends at paths, routes requests to them, performs
+// logins against auth backends and issues tokens, and tracks the leases of
+// returned secrets. Time is controlled by the cluster's Clock: leases and
+// tokens expire, and periodic functions and WAL rollbacks run, only when the
+// cluster is advanced with Advance.
+//
+// A Cluster does not enforce ACL policies; tokens are checked for validity
+// only. Backends which read the time themselves still observe the wall clock,
+// unless they are given the cluster's Clock.
+package mockcluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/vault/sdk/helper/clockutil"
+	"github.com/hashicorp/vault/sdk/helper/logging"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	// DefaultRollbackInterval matches the interval at which Vault invokes the
+	// periodic functions of mounted backends.
+	DefaultRollbackInterval = time.Minute
+
+	defaultLeaseTTL = 32 * 24 * time.Hour
+	maxLeaseTTL     = 32 * 24 * time.Hour
+)
+
+// Options configures a Cluster. The zero value is usable.
+type Options struct {
+	// Logger is the parent logger of the mounted backends. Defaults to a
+	// trace-level logger.
+	Logger log.Logger
+
+	// DefaultLeaseTTL and MaxLeaseTTL are reported to the backends through
+	// their system view. Both default to 32 days, as in Vault.
+	DefaultLeaseTTL time.Duration
+	MaxLeaseTTL     time.Duration
+
+	// RollbackInterval is the interval at which the periodic functions of
+	// mounted backends run as the clock advances. Defaults to
+	// DefaultRollbackInterval.
+	RollbackInterval time.Duration
+
+	// Start is the initial time of the clock. Defaults to the current time.
+	Start time.Time
+}
+
+// Cluster is an in-process mock of a Vault server.
+type Cluster struct {
+	l sync.Mutex
+
+	logger           log.Logger
+	clock            *clockutil.ManualClock
+	defaultLeaseTTL  time.Duration
+	maxLeaseTTL      time.Duration
+	rollbackInterval time.Duration
+	nextRollback     time.Time
+
+	mounts map[string]*mount
+	tokens map[string]*token
+	leases map[string]*lease
+
+	rootToken string
+}
+
+type mount struct {
+	path     string
+	accessor string
+	table    logical.BackendType
+	backend  logical.Backend
+	storage  logical.Storage
+}
+
+type token struct {
+	id       string
+	accessor string
+	policies []string
+	entityID string
+	auth     *logical.Auth
+
+	// mount and path are those of the login request, used to renew the
+	// token with its auth backend
+	mount *mount
+	path  string
+
+	// expire is zero for tokens which do not expire
+	expire time.Time
+}
+
+type lease struct {
+	id     string
+	mount  *mount
+	path   string
+	secret *logical.Secret
+	data   map[string]interface{}
+	token  string
+	expire time.Time
+}
+
+// New returns a Cluster, which is closed when the test completes.
+func New(t testing.TB, opts *Options) *Cluster {
+	t.Helper()
+
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	c := &Cluster{
+		logger:           opts.Logger,
+		defaultLeaseTTL:  opts.DefaultLeaseTTL,
+		maxLeaseTTL:      opts.MaxLeaseTTL,
+		rollbackInterval: opts.RollbackInterval,
+		mounts:           make(map[string]*mount),
+		tokens:           make(map[string]*token),
+		leases:           make(map[string]*lease),
+	}
+	if c.logger == nil {
+		c.logger = logging.NewVaultLogger(log.Trace)
+	}
+	if c.defaultLeaseTTL == 0 {
+		c.defaultLeaseTTL = defaultLeaseTTL
+	}
+	if c.maxLeaseTTL == 0 {
+		c.maxLeaseTTL = maxLeaseTTL
+	}
+	if c.rollbackInterval == 0 {
+		c.rollbackInterval = DefaultRollbackInterval
+	}
+
+	start := opts.Start
+	if start.IsZero() {
+		start = time.Now()
+	}
+	c.clock = clockutil.NewManualClock(start)
+	c.nextRollback = start.Add(c.rollbackInterval)
+
+	root, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.rootToken = root
+	c.tokens[root] = &token{
+		id:       root,
+		accessor: "root",
+		policies: []string{"root"},
+	}
+
+	t.Cleanup(c.Close)
+	return c
+}
+
+// Clock returns the clock of the cluster. It only moves when the cluster is
+// advanced, and can be injected into backends that take a clockutil.Clock.
+func (c *Cluster) Clock() clockutil.Clock {
+	return c.clock
+}
+
+// RootToken returns a token which is valid for every request and never
+// expires.
+func (c *Cluster) RootToken() string {
+	return c.rootToken
+}
+
+// Mount mounts a secrets backend created by the factory at the given path.
+func (c *Cluster) Mount(path string, factory logical.Factory, config map[string]string) error {
+	return c.mount(strings.Trim(path, "/")+"/", logical.TypeLogical, factory, config)
+}
+
+// EnableAuth mounts an auth backend created by the factory at the given path
+// under "auth/".
+func (c *Cluster) EnableAuth(path string, factory logical.Factory, config map[string]string) error {
+	return c.mount("auth/"+strings.Trim(path, "/")+"/", logical.TypeCredential, factory, config)
+}
+
+func (c *Cluster) mount(path string, table logical.BackendType, factory logical.Factory, config map[string]string) error {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	if _, ok := c.mounts[path]; ok {
+		return fmt.Errorf("path is already in use at %s", path)
+	}
+
+	backendUUID, err := uuid.GenerateUUID()
+	if err != nil {
+		return err
+	}
+	if config == nil {
+		config = make(map[string]string)
+	}
+
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+	backend, err := factory(ctx, &logical.BackendConfig{
+		StorageView: storage,
+		Logger:      c.logger.Named(strings.TrimSuffix(path, "/")),
+		System: &logical.StaticSystemView{
+			DefaultLeaseTTLVal: c.defaultLeaseTTL,
+			MaxLeaseTTLVal:     c.maxLeaseTTL,
+			VersionString:      "mockcluster",
+		},
+		BackendUUID: backendUUID,
+		Config:      config,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create backend for %s: %w", path, err)
+	}
+	if backend == nil {
+		return fmt.Errorf("nil backend for %s", path)
+	}
+	if err := backend.Initialize(ctx, &logical.InitializationRequest{Storage: storage}); err != nil {
+		return fmt.Errorf("failed to initialize backend for %s: %w", path, err)
+	}
+
+	c.mounts[path] = &mount{
+		path:     path,
+		accessor: fmt.Sprintf("%s_%s", strings.Split(path, "/")[0], backendUUID[:8]),
+		table:    table,
+		backend:  backend,
+		storage:  storage,
+	}
+	return nil
+}
+
+// Unmount revokes the leases of the backend mounted at the given path, and
+// removes it. Auth mounts are given with their "auth/" prefix.
+func (c *Cluster) Unmount(path string) error {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	m, ok := c.mounts[strings.Trim(path, "/")+"/"]
+	if !ok {
+		return fmt.Errorf("no mount at %s", path)
+	}
+
+	var merr *multierror.Error
+	for _, id := range c.sortedLeaseIDs() {
+		if l := c.leases[id]; l.mount == m {
+			merr = multierror.Append(merr, c.revokeLease(l))
+		}
+	}
+	m.backend.Cleanup(context.Background())
+	delete(c.mounts, m.path)
+	return merr.ErrorOrNil()
+}
+
+// Close cleans up all mounted backends.
+func (c *Cluster) Close() {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	for path, m := range c.mounts {
+		m.backend.Cleanup(context.Background())
+		delete(c.mounts, path)
+	}
+}
+
+// Request sends a request to the backend mounted at the longest prefix of the
+// path. Write requests are sent as create operations when the backend's
+// existence check reports that the target doesn't exist, as Vault does.
+// Leases are registered for returned secrets, and tokens are issued for
+// returned auths.
+func (c *Cluster) Request(clientToken string, op logical.Operation, path string, data map[string]interface{}) (*logical.Response, error) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	return c.request(clientToken, op, path, data)
+}
+
+// Read sends a read request.
+func (c *Cluster) Read(clientToken, path string) (*logical.Response, error) {
+	return c.Request(clientToken, logical.ReadOperation, path, nil)
+}
+
+// Write sends an update request, or a create request if the backend reports
+// that the target doesn't exist.
+func (c *Cluster) Write(clientToken, path string, data map[string]interface{}) (*logical.Response, error) {
+	return c.Request(clientToken, logical.UpdateOperation, path, data)
+}
+
+// List sends a list request.
+func (c *Cluster) List(clientToken, path string) (*logical.Response, error) {
+	return c.Request(clientToken, logical.ListOperation, path, nil)
+}
+
+// Delete sends a delete request.
+func (c *Cluster) Delete(clientToken, path string) (*logical.Response, error) {
+	return c.Request(clientToken, logical.DeleteOperation, path, nil)
+}
+
+// Login sends an unauthenticated write to the given path of an auth backend,
+// and returns the auth of the issued token.
+func (c *Cluster) Login(path string, data map[string]interface{}) (*logical.Auth, error) {
+	resp, err := c.Request("", logical.UpdateOperation, path, data)
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil || resp.Auth == nil {
+		if resp != nil && resp.IsError() {
+			return nil, resp.Error()
+		}
+		return nil, fmt.Errorf("login to %s returned no auth", path)
+	}
+	return resp.Auth, nil
+}
+
+func (c *Cluster) request(clientToken string, op logical.Operation, path string, data map[string]interface{}) (*logical.Response, error) {
+	m, relPath := c.match(path)
+	if m == nil {
+		return nil, logical.ErrUnsupportedPath
+	}
+
+	req := &logical.Request{
+		Operation:     op,
+		Path:          relPath,
+		Data:          data,
+		Storage:       m.storage,
+		MountPoint:    m.path,
+		MountAccessor: m.accessor,
+		Connection:    &logical.Connection{RemoteAddr: "127.0.0.1"},
+	}
+
+	unauthenticated := isSpecialPath(m.backend.SpecialPaths(), relPath)
+	if !unauthenticated {
+		tok := c.validToken(clientToken)
+		if tok == nil {
+			return nil, logical.ErrPermissionDenied
+		}
+		req.ClientToken = tok.id
+		req.ClientTokenAccessor = tok.accessor
+		req.EntityID = tok.entityID
+		req.DisplayName = tok.accessor
+	}
+
+	ctx := context.Background()
+	if op == logical.UpdateOperation {
+		checkFound, exists, err := m.backend.HandleExistenceCheck(ctx, req)
+		switch {
+		case err != nil && !errors.Is(err, logical.ErrUnsupportedPath):
+			return nil, err
+		case err == nil && checkFound && !exists:
+			req.Operation = logical.CreateOperation
+		}
+	}
+
+	resp, err := m.backend.HandleRequest(ctx, req)
+	if err != nil || resp == nil || resp.IsError() {
+		return resp, err
+	}
+
+	if resp.Secret != nil {
+		if err := c.registerLease(m, relPath, req, resp); err != nil {
+			return nil, err
+		}
+	}
+	if resp.Auth != nil {
+		if m.table != logical.TypeCredential {
+			return nil, fmt.Errorf("secrets backend at %s returned an auth", m.path)
+		}
+		if err := c.issueToken(m, relPath, resp.Auth); err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
+
+// match returns the mount with the longest path prefixing the given path,
+// and the path relative to the mount.
+func (c *Cluster) match(path string) (*mount, string) {
+	path = strings.TrimPrefix(path, "/")
+
+	var match *mount
+	for prefix, m := range c.mounts {
+		if !strings.HasPrefix(path, prefix) && path != strings.TrimSuffix(prefix, "/") {
+			continue
+		}
+		if match == nil || len(prefix) > len(match.path) {
+			match = m
+		}
+	}
+	if match == nil {
+		return nil, ""
+	}
+	return match, strings.TrimPrefix(strings.TrimPrefix(path, strings.TrimSuffix(match.path, "/")), "/")
+}
+
+// isSpecialPath reports whether the path is one of the backend's
+// unauthenticated paths. Entries ending in "*" match by prefix, and "+"
+// segments match any single segment.
+func isSpecialPath(paths *logical.Paths, path string) bool {
+	if paths == nil {
+		return false
+	}
+
+	for _, pattern := range paths.Unauthenticated {
+		prefix := strings.HasSuffix(pattern, "*")
+		pattern = strings.TrimSuffix(pattern, "*")
+
+		patternSegments := strings.Split(pattern, "/")
+		pathSegments := strings.Split(path, "/")
+		if len(pathSegments) < len(patternSegments) || (!prefix && len(pathSegments) != len(patternSegments)) {
+			continue
+		}
+
+		matched := true
+		for i, segment := range patternSegments {
+			last := i == len(patternSegments)-1
+			switch {
+			case segment == "+" && pathSegments[i] != "":
+			case last && prefix && strings.HasPrefix(pathSegments[i], segment):
+			case segment == pathSegments[i]:
+			default:
+				matched = false
+			}
+			if !matched {
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Cluster) validToken(id string) *token {
+	tok, ok := c.tokens[id]
+	if !ok {
+		return nil
+	}
+	if !tok.expire.IsZero() && !c.clock.Now().Before(tok.expire) {
+		return nil
+	}
+	return tok
+}
+
+// leaseTTL returns the TTL of a lease given the TTL requested by the backend,
+// bounded by the cluster's maximum.
+func (c *Cluster) leaseTTL(opts logical.LeaseOptions) time.Duration {
+	ttl := opts.TTL
+	if ttl == 0 {
+		ttl = c.defaultLeaseTTL
+	}
+	maxTTL := c.maxLeaseTTL
+	if opts.MaxTTL != 0 && opts.MaxTTL < maxTTL {
+		maxTTL = opts.MaxTTL
+	}
+	if !opts.IssueTime.IsZero() {
+		if remaining := opts.IssueTime.Add(maxTTL).Sub(c.clock.Now()); remaining < ttl {
+			ttl = remaining
+		}
+	} else if ttl > maxTTL {
+		ttl = maxTTL
+	}
+	return ttl
+}
+
+func (c *Cluster) registerLease(m *mount, path string, req *logical.Request, resp *logical.Response) error {
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return err
+	}
+
+	now := c.clock.Now()
+	resp.Secret.IssueTime = now
+	resp.Secret.TTL = c.leaseTTL(resp.Secret.LeaseOptions)
+	resp.Secret.LeaseID = m.path + path + "/" + id
+
+	c.leases[resp.Secret.LeaseID] = &lease{
+		id:     resp.Secret.LeaseID,
+		mount:  m,
+		path:   path,
+		secret: resp.Secret,
+		data:   req.Data,
+		token:  req.ClientToken,
+		expire: now.Add(resp.Secret.TTL),
+	}
+	return nil
+}
+
+func (c *Cluster) issueToken(m *mount, path string, auth *logical.Auth) error {
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return err
+	}
+	accessor, err := uuid.GenerateUUID()
+	if err != nil {
+		return err
+	}
+
+	now := c.clock.Now()
+	auth.IssueTime = now
+	auth.TTL = c.leaseTTL(auth.LeaseOptions)
+	auth.ClientToken = id
+	auth.Accessor = accessor
+
+	c.tokens[id] = &token{
+		id:       id,
+		accessor: accessor,
+		policies: auth.Policies,
+		entityID: auth.EntityID,
+		auth:     auth,
+		mount:    m,
+		path:     path,
+		expire:   now.Add(auth.TTL),
+	}
+	return nil
+}
+
+// Renew renews the lease with the given ID by the given increment, and
+// returns the backend's response.
+func (c *Cluster) Renew(leaseID string, increment time.Duration) (*logical.Response, error) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	l, ok := c.leases[leaseID]
+	if !ok {
+		return nil, fmt.Errorf("lease %q not found", leaseID)
+	}
+	if !l.secret.Renewable {
+		return nil, fmt.Errorf("lease %q is not renewable", leaseID)
+	}
+
+	secret := *l.secret
+	secret.Increment = increment
+	req := logical.RenewRequest(l.path, &secret, l.data)
+	req.Storage = l.mount.storage
+	req.MountPoint = l.mount.path
+	resp, err := l.mount.backend.HandleRequest(context.Background(), req)
+	if err != nil || resp == nil || resp.IsError() {
+		return resp, err
+	}
+	if resp.Secret == nil {
+		return nil, fmt.Errorf("renewal of lease %q returned no secret", leaseID)
+	}
+
+	resp.Secret.IssueTime = l.secret.IssueTime
+	resp.Secret.TTL = c.leaseTTL(resp.Secret.LeaseOptions)
+	resp.Secret.LeaseID = l.id
+	if resp.Secret.InternalData == nil {
+		resp.Secret.InternalData = l.secret.InternalData
+	}
+	l.secret = resp.Secret
+	l.expire = c.clock.Now().Add(resp.Secret.TTL)
+	return resp, nil
+}
+
+// RenewToken renews the given token with the auth backend that issued it.
+func (c *Cluster) RenewToken(clientToken string, increment time.Duration) (*logical.Auth, error) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	tok := c.validToken(clientToken)
+	switch {
+	case tok == nil:
+		return nil, logical.ErrPermissionDenied
+	case tok.auth == nil:
+		return nil, errors.New("root tokens cannot be renewed")
+	case !tok.auth.Renewable:
+		return nil, errors.New("token is not renewable")
+	}
+
+	auth := *tok.auth
+	auth.Increment = increment
+	req := logical.RenewAuthRequest(tok.path, &auth, nil)
+	req.Storage = tok.mount.storage
+	req.MountPoint = tok.mount.path
+	resp, err := tok.mount.backend.HandleRequest(context.Background(), req)
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil || resp.Auth == nil {
+		if resp != nil && resp.IsError() {
+			return nil, resp.Error()
+		}
+		return nil, errors.New("token renewal returned no auth")
+	}
+
+	resp.Auth.IssueTime = tok.auth.IssueTime
+	resp.Auth.TTL = c.leaseTTL(resp.Auth.LeaseOptions)
+	resp.Auth.ClientToken = tok.id
+	resp.Auth.Accessor = tok.accessor
+	tok.auth = resp.Auth
+	tok.expire = c.clock.Now().Add(resp.Auth.TTL)
+	return resp.Auth, nil
+}
+
+// Revoke revokes the lease with the given ID.
+func (c *Cluster) Revoke(leaseID string) error {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	l, ok := c.leases[leaseID]
+	if !ok {
+		return fmt.Errorf("lease %q not found", leaseID)
+	}
+	return c.revokeLease(l)
+}
+
+// RevokeToken revokes the given token, and the leases created with it.
+func (c *Cluster) RevokeToken(clientToken string) error {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	if clientToken == c.rootToken {
+		return errors.New("the root token cannot be revoked")
+	}
+	if _, ok := c.tokens[clientToken]; !ok {
+		return errors.New("token not found")
+	}
+	return c.revokeToken(clientToken)
+}
+
+func (c *Cluster) revokeLease(l *lease) error {
+	req := logical.RevokeRequest(l.path, l.secret, l.data)
+	req.Storage = l.mount.storage
+	req.MountPoint = l.mount.path
+	resp, err := l.mount.backend.HandleRequest(context.Background(), req)
+	if err == nil && resp != nil && resp.IsError() {
+		err = resp.Error()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to revoke lease %q: %w", l.id, err)
+	}
+
+	delete(c.leases, l.id)
+	return nil
+}
+
+func (c *Cluster) revokeToken(id string) error {
+	var merr *multierror.Error
+	for _, leaseID := range c.sortedLeaseIDs() {
+		if l := c.leases[leaseID]; l.token == id {
+			merr = multierror.Append(merr, c.revokeLease(l))
+		}
+	}
+	delete(c.tokens, id)
+	return merr.ErrorOrNil()
+}
+
+// LeaseIDs returns the IDs of the unexpired leases, sorted.
+func (c *Cluster) LeaseIDs() []string {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	return c.sortedLeaseIDs()
+}
+
+func (c *Cluster) sortedLeaseIDs() []string {
+	ids := make([]string, 0, len(c.leases))
+	for id := range c.leases {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Rollback invokes the periodic function and WAL rollback of the backend
+// mounted at the given path. If immediate is set, WAL entries are rolled back
+// regardless of their age.
+func (c *Cluster) Rollback(path string, immediate bool) error {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	m, ok := c.mounts[strings.Trim(path, "/")+"/"]
+	if !ok {
+		return fmt.Errorf("no mount at %s", path)
+	}
+	return c.rollback(m, immediate)
+}
+
+func (c *Cluster) rollback(m *mount, immediate bool) error {
+	req := logical.RollbackRequest("")
+	req.Storage = m.storage
+	req.MountPoint = m.path
+	if immediate {
+		req.Data["immediate"] = true
+	}
+
+	_, err := m.backend.HandleRequest(context.Background(), req)
+	if errors.Is(err, logical.ErrUnsupportedOperation) || errors.Is(err, logical.ErrUnsupportedPath) {
+		return nil
+	}
+	return err
+}
+
+// Advance moves the clock forward by the given duration. Along the way,
+// events are processed in time order: the periodic functions of all mounts
+// run at every rollback interval, and leases and tokens are revoked as they
+// expire. Errors from these events are collected and returned.
+func (c *Cluster) Advance(d time.Duration) error {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	var merr *multierror.Error
+	target := c.clock.Now().Add(d)
+	for {
+		next, rollback := c.nextEvent()
+		if next.After(target) {
+			break
+		}
+		c.clock.Set(next)
+
+		if rollback {
+			for _, path := range c.sortedMountPaths() {
+				merr = multierror.Append(merr, c.rollback(c.mounts[path], false))
+			}
+			c.nextRollback = c.nextRollback.Add(c.rollbackInterval)
+		}
+		merr = multierror.Append(merr, c.expire())
+	}
+	c.clock.Set(target)
+
+	return merr.ErrorOrNil()
+}
+
+// nextEvent returns the time of the next rollback or expiry, and whether it
+// is a rollback.
+func (c *Cluster) nextEvent() (time.Time, bool) {
+	next := c.nextRollback
+	rollback := true
+	for _, l := range c.leases {
+		if l.expire.Before(next) {
+			next, rollback = l.expire, false
+		}
+	}
+	for _, tok := range c.tokens {
+		if !tok.expire.IsZero() && tok.expire.Before(next) {
+			next, rollback = tok.expire, false
+		}
+	}
+	return next, rollback
+}
+
+func (c *Cluster) expire() error {
+	var merr *multierror.Error
+	now := c.clock.Now()
+
+	for _, id := range c.sortedLeaseIDs() {
+		if l, ok := c.leases[id]; ok && !now.Before(l.expire) {
+			if err := c.revokeLease(l); err != nil {
+				// Drop the lease regardless so that a failing revocation
+				// doesn't stall the clock
+				delete(c.leases, id)
+				merr = multierror.Append(merr, err)
+			}
+		}
+	}
+	for id, tok := range c.tokens {
+		if !tok.expire.IsZero() && !now.Before(tok.expire) {
+			merr = multierror.Append(merr, c.revokeToken(id))
+		}
+	}
+	return merr.ErrorOrNil()
+}
+
+func (c *Cluster) sortedMountPaths() []string {
+	paths := make([]string, 0, len(c.mounts))
+	for path := range c.mounts {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
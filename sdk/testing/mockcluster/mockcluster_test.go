@@ -0,0 +1,190 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package mockcluster
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+type testSecretsBackend struct {
+	*framework.Backend
+
+	periodic int32
+	revoked  int32
+}
+
+func testSecretsFactory(b *testSecretsBackend) logical.Factory {
+	return func(ctx context.Context, conf *logical.BackendConfig) (logical.Backend, error) {
+		b.Backend = &framework.Backend{
+			BackendType: logical.TypeLogical,
+			Paths: []*framework.Path{
+				{
+					Pattern: "creds",
+					Operations: map[logical.Operation]framework.OperationHandler{
+						logical.ReadOperation: &framework.PathOperation{
+							Callback: func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+								resp := b.Secret("creds").Response(map[string]interface{}{"username": "alice"}, nil)
+								resp.Secret.TTL = time.Hour
+								return resp, nil
+							},
+						},
+					},
+				},
+			},
+			Secrets: []*framework.Secret{
+				{
+					Type: "creds",
+					Revoke: func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+						atomic.AddInt32(&b.revoked, 1)
+						return nil, nil
+					},
+				},
+			},
+			PeriodicFunc: func(ctx context.Context, req *logical.Request) error {
+				atomic.AddInt32(&b.periodic, 1)
+				return nil
+			},
+		}
+		if err := b.Setup(ctx, conf); err != nil {
+			return nil, err
+		}
+		return b, nil
+	}
+}
+
+func testAuthFactory(ctx context.Context, conf *logical.BackendConfig) (logical.Backend, error) {
+	b := &framework.Backend{
+		BackendType: logical.TypeCredential,
+		PathsSpecial: &logical.Paths{
+			Unauthenticated: []string{"login/*"},
+		},
+		Paths: []*framework.Path{
+			{
+				Pattern: "login/" + framework.GenericNameRegex("name"),
+				Fields: map[string]*framework.FieldSchema{
+					"name": {Type: framework.TypeString},
+				},
+				Operations: map[logical.Operation]framework.OperationHandler{
+					logical.UpdateOperation: &framework.PathOperation{
+						Callback: func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+							return &logical.Response{
+								Auth: &logical.Auth{
+									DisplayName: d.Get("name").(string),
+									Policies:    []string{"default"},
+									LeaseOptions: logical.LeaseOptions{
+										TTL: 2 * time.Hour,
+									},
+								},
+							}, nil
+						},
+					},
+				},
+			},
+		},
+	}
+	if err := b.Setup(ctx, conf); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func TestCluster(t *testing.T) {
+	c := New(t, nil)
+
+	secrets := &testSecretsBackend{}
+	if err := c.Mount("test", testSecretsFactory(secrets), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.EnableAuth("test", testAuthFactory, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Read("", "test/creds"); !errors.Is(err, logical.ErrPermissionDenied) {
+		t.Fatalf("expected permission denied without a token, got: %v", err)
+	}
+
+	auth, err := c.Login("auth/test/login/alice", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth.ClientToken == "" || auth.TTL != 2*time.Hour {
+		t.Fatalf("bad: %#v", auth)
+	}
+
+	resp, err := c.Read(auth.ClientToken, "test/creds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Secret == nil || resp.Secret.LeaseID == "" {
+		t.Fatalf("expected a lease, got: %#v", resp)
+	}
+
+	// Periodic functions run every rollback interval, and the lease is
+	// revoked once it expires.
+	if err := c.Advance(30 * time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&secrets.periodic); n != 30 {
+		t.Fatalf("expected 30 periodic runs, got %d", n)
+	}
+	if n := atomic.LoadInt32(&secrets.revoked); n != 0 {
+		t.Fatalf("expected lease not to be revoked yet, got %d revocations", n)
+	}
+
+	if err := c.Advance(31 * time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&secrets.revoked); n != 1 {
+		t.Fatalf("expected the expired lease to be revoked, got %d revocations", n)
+	}
+	if ids := c.LeaseIDs(); len(ids) != 0 {
+		t.Fatalf("expected no leases, got: %v", ids)
+	}
+
+	// Leases of a token are revoked with it when it expires.
+	if _, err := c.Read(auth.ClientToken, "test/creds"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Advance(time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Read(auth.ClientToken, "test/creds"); !errors.Is(err, logical.ErrPermissionDenied) {
+		t.Fatalf("expected the expired token to be rejected, got: %v", err)
+	}
+	if n := atomic.LoadInt32(&secrets.revoked); n != 2 {
+		t.Fatalf("expected the token's lease to be revoked, got %d revocations", n)
+	}
+
+	if _, err := c.Read(c.RootToken(), "test/creds"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIsSpecialPath(t *testing.T) {
+	paths := &logical.Paths{
+		Unauthenticated: []string{"login", "oidc/+/callback", "web/*"},
+	}
+
+	for path, expected := range map[string]bool{
+		"login":           true,
+		"login/alice":     false,
+		"oidc/a/callback": true,
+		"oidc//callback":  false,
+		"web/":            true,
+		"web/foo/bar":     true,
+		"webfoo":          false,
+		"config":          false,
+	} {
+		if actual := isSpecialPath(paths, path); actual != expected {
+			t.Errorf("%q: expected %t, got %t", path, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/helper/identity"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// defaultAdvisorInactiveDays is the number of days without clients after
+// which an auth mount is reported as stale, unless the report is requested
+// with another.
+const defaultAdvisorInactiveDays = 90
+
+// AdvisorEntity is an entity reported by the advisor.
+type AdvisorEntity struct {
+	ID   string
+	Name string
+}
+
+// AdvisorMount is a mount reported by the advisor.
+type AdvisorMount struct {
+	Path     string
+	Type     string
+	Accessor string
+}
+
+// AdvisorReport reports the configuration of a namespace which is likely
+// unused, to support periodic hygiene reviews.
+type AdvisorReport struct {
+	// UnusedPolicies are the ACL policies attached to no token, entity or
+	// group, and allowed by no token role.
+	UnusedPolicies []string
+
+	// OrphanEntities are the entities with no alias on an existing mount,
+	// which belong to no group.
+	OrphanEntities []*AdvisorEntity
+
+	// StaleMounts are the auth mounts no client used in the inactive period,
+	// according to the activity log. It is nil when the activity log is
+	// disabled.
+	StaleMounts []*AdvisorMount
+
+	// UnusedRoles are the token roles no existing token was created with.
+	UnusedRoles []string
+
+	Warnings []string
+}
+
+// advisorReport builds the advisor report of the namespace of the context,
+// reporting the auth mounts without clients in the given number of days as
+// stale.
+func (c *Core) advisorReport(ctx context.Context, inactiveDays int) (*AdvisorReport, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &AdvisorReport{}
+	usedPolicies := make(map[string]bool)
+	usedRoles := make(map[string]bool)
+
+	// Policies of tokens, and the roles they were created with.
+	if err := c.tokenStore.walkAccessors(ctx, ns, func(te *logical.TokenEntry) {
+		for _, p := range te.Policies {
+			usedPolicies[p] = true
+		}
+		if te.Role != "" {
+			usedRoles[te.Role] = true
+		}
+	}); err != nil {
+		return nil, fmt.Errorf("failed to scan tokens: %w", err)
+	}
+
+	// Policies allowed by token roles.
+	roles, err := c.tokenStore.rolesView(ns).List(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list token roles: %w", err)
+	}
+	for _, name := range roles {
+		role, err := c.tokenStore.tokenStoreRole(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read token role %q: %w", name, err)
+		}
+		if role == nil {
+			continue
+		}
+		for _, p := range role.AllowedPolicies {
+			usedPolicies[p] = true
+		}
+		if !usedRoles[name] {
+			report.UnusedRoles = append(report.UnusedRoles, name)
+		}
+	}
+
+	// Policies of entities and groups, and the entities which are orphaned.
+	txn := c.identityStore.db.Txn(false)
+	groupMembers := make(map[string]bool)
+	groupsIter, err := txn.Get(groupsTable, "id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan groups: %w", err)
+	}
+	for raw := groupsIter.Next(); raw != nil; raw = groupsIter.Next() {
+		group := raw.(*identity.Group)
+		if group.NamespaceID != ns.ID {
+			continue
+		}
+		for _, p := range group.Policies {
+			usedPolicies[p] = true
+		}
+		for _, id := range group.MemberEntityIDs {
+			groupMembers[id] = true
+		}
+	}
+
+	entitiesIter, err := txn.Get(entitiesTable, "id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan entities: %w", err)
+	}
+	for raw := entitiesIter.Next(); raw != nil; raw = entitiesIter.Next() {
+		entity := raw.(*identity.Entity)
+		if entity.NamespaceID != ns.ID {
+			continue
+		}
+		for _, p := range entity.Policies {
+			usedPolicies[p] = true
+		}
+		if groupMembers[entity.ID] {
+			continue
+		}
+
+		orphan := true
+		for _, alias := range entity.Aliases {
+			if c.router.ValidateMountByAccessor(alias.MountAccessor) != nil {
+				orphan = false
+				break
+			}
+		}
+		if orphan {
+			report.OrphanEntities = append(report.OrphanEntities, &AdvisorEntity{ID: entity.ID, Name: entity.Name})
+		}
+	}
+
+	policies, err := c.policyStore.ListPolicies(ctx, PolicyTypeACL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list policies: %w", err)
+	}
+	for _, p := range policies {
+		if !usedPolicies[p] {
+			report.UnusedPolicies = append(report.UnusedPolicies, p)
+		}
+	}
+
+	if err := c.advisorStaleMounts(ctx, ns, inactiveDays, report); err != nil {
+		return nil, err
+	}
+
+	sort.Strings(report.UnusedPolicies)
+	sort.Strings(report.UnusedRoles)
+	sort.Slice(report.OrphanEntities, func(i, j int) bool {
+		return report.OrphanEntities[i].Name < report.OrphanEntities[j].Name
+	})
+	return report, nil
+}
+
+// advisorStaleMounts adds the auth mounts of the namespace which no client
+// used in the inactive period to the report. Clients are attributed to the
+// auth mounts they logged in with by the activity log, which counts them by
+// month, so the period starts at the beginning of its first month.
+func (c *Core) advisorStaleMounts(ctx context.Context, ns *namespace.Namespace, inactiveDays int, report *AdvisorReport) error {
+	c.activityLogLock.RLock()
+	a := c.activityLog
+	c.activityLogLock.RUnlock()
+
+	enabled := false
+	if a != nil {
+		a.fragmentLock.RLock()
+		enabled = a.enabled
+		a.fragmentLock.RUnlock()
+	}
+	if !enabled {
+		report.Warnings = append(report.Warnings, "The activity log is disabled, stale mounts are not reported.")
+		return nil
+	}
+
+	now := time.Now().UTC()
+	results, err := a.handleQuery(ctx, now.AddDate(0, 0, -inactiveDays), now, 0)
+	if err != nil {
+		return fmt.Errorf("failed to query the activity log: %w", err)
+	}
+
+	used := make(map[string]bool)
+	if results != nil {
+		byNamespace, _ := results["by_namespace"].([]*ResponseNamespace)
+		for _, nsRecord := range byNamespace {
+			if nsRecord.NamespaceID != ns.ID {
+				continue
+			}
+			for _, mount := range nsRecord.Mounts {
+				if mount.Counts != nil && mount.Counts.Clients > 0 {
+					used[mount.MountPath] = true
+				}
+			}
+		}
+	}
+
+	report.StaleMounts = []*AdvisorMount{}
+	c.authLock.RLock()
+	defer c.authLock.RUnlock()
+	if c.auth == nil {
+		return nil
+	}
+	for _, entry := range c.auth.Entries {
+		if entry.NamespaceID != ns.ID || strutil.StrListContains(singletonMounts, entry.Type) {
+			continue
+		}
+		path := credentialRoutePrefix + entry.Path
+		if !strings.HasSuffix(path, "/") {
+			path += "/"
+		}
+		if used[path] {
+			continue
+		}
+		report.StaleMounts = append(report.StaleMounts, &AdvisorMount{
+			Path:     path,
+			Type:     entry.Type,
+			Accessor: entry.Accessor,
+		})
+	}
+	sort.Slice(report.StaleMounts, func(i, j int) bool {
+		return report.StaleMounts[i].Path < report.StaleMounts[j].Path
+	})
+	return nil
+}
@@ -0,0 +1,88 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemBackend_Advisor(t *testing.T) {
+	c, _, root := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	request := func(op logical.Operation, path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		req := logical.TestRequest(t, op, path)
+		req.Data = data
+		req.ClientToken = root
+		resp, err := c.HandleRequest(ctx, req)
+		require.NoError(t, err)
+		require.False(t, resp != nil && resp.IsError(), "%s: %v", path, resp)
+		return resp
+	}
+
+	for _, name := range []string{"token-policy", "role-policy", "entity-policy", "group-policy", "unused-policy"} {
+		request(logical.UpdateOperation, "sys/policy/"+name, map[string]interface{}{
+			"policy": `path "secret/*" { capabilities = ["read"] }`,
+		})
+	}
+	request(logical.UpdateOperation, "auth/token/create", map[string]interface{}{"policies": []string{"token-policy"}})
+	request(logical.UpdateOperation, "auth/token/roles/used-role", map[string]interface{}{"allowed_policies": []string{"role-policy"}})
+	request(logical.UpdateOperation, "auth/token/roles/unused-role", nil)
+	request(logical.UpdateOperation, "auth/token/create/used-role", nil)
+
+	// Entities with an alias, or in a group, aren't orphaned.
+	for _, name := range []string{"aliased", "member", "orphan"} {
+		request(logical.UpdateOperation, "identity/entity", map[string]interface{}{"name": name, "policies": []string{"entity-policy"}})
+	}
+	aliased, err := c.identityStore.MemDBEntityByName(ctx, "aliased", false)
+	require.NoError(t, err)
+	member, err := c.identityStore.MemDBEntityByName(ctx, "member", false)
+	require.NoError(t, err)
+	tokenMount := c.router.MatchingMountEntry(ctx, "auth/token/")
+	request(logical.UpdateOperation, "identity/entity-alias", map[string]interface{}{
+		"name":           "aliased",
+		"canonical_id":   aliased.ID,
+		"mount_accessor": tokenMount.Accessor,
+	})
+	request(logical.UpdateOperation, "identity/group", map[string]interface{}{
+		"name":              "group",
+		"policies":          []string{"group-policy"},
+		"member_entity_ids": []string{member.ID},
+	})
+
+	// Stale mounts are only reported with the activity log enabled.
+	resp := request(logical.ReadOperation, "sys/advisor", nil)
+	require.Equal(t, []string{"unused-policy"}, resp.Data["unused_policies"])
+	require.Equal(t, []string{"unused-role"}, resp.Data["unused_roles"])
+	require.Len(t, resp.Data["orphan_entities"], 1)
+	require.Equal(t, "orphan", resp.Data["orphan_entities"].([]map[string]interface{})[0]["name"])
+	require.NotContains(t, resp.Data, "stale_mounts")
+	require.Len(t, resp.Warnings, 1)
+
+	for _, path := range []string{"used/", "idle/"} {
+		require.NoError(t, c.enableCredential(ctx, &MountEntry{
+			Table: credentialTableType,
+			Path:  path,
+			Type:  "noop",
+		}))
+	}
+	c.activityLog.SetEnable(true)
+	used := c.router.MatchingMountEntry(ctx, "auth/used/")
+	c.activityLog.AddClientToFragment("client", namespace.RootNamespaceID, time.Now().Unix(), true, used.Accessor)
+
+	resp = request(logical.ReadOperation, "sys/advisor", map[string]interface{}{"inactive_days": 30})
+	require.Equal(t, 30, resp.Data["inactive_days"])
+	require.Equal(t, []map[string]interface{}{{
+		"path":     "auth/idle/",
+		"type":     "noop",
+		"accessor": c.router.MatchingMountEntry(ctx, "auth/idle/").Accessor,
+	}}, resp.Data["stale_mounts"])
+	require.Empty(t, resp.Warnings)
+}
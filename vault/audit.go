@@ -6,8 +6,10 @@ package vault
 import (
 	"context"
 	"crypto/sha256"
+	"crypto/sha512"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
 	uuid "github.com/hashicorp/go-uuid"
@@ -60,6 +62,67 @@ func (c *Core) generateAuditTestProbe() (*logical.LogInput, error) {
 	}, nil
 }
 
+// testAudit logs a test message through the enabled audit backend at the
+// given path, so that the backend can be checked while it is in use.
+func (c *Core) testAudit(ctx context.Context, path string) error {
+	c.auditLock.RLock()
+	defer c.auditLock.RUnlock()
+
+	var entry *MountEntry
+	for _, e := range c.audit.Entries {
+		if e.Path == path {
+			entry = e
+			break
+		}
+	}
+	if entry == nil {
+		return fmt.Errorf("no audit backend enabled at %q", path)
+	}
+
+	testProbe, err := c.generateAuditTestProbe()
+	if err != nil {
+		return err
+	}
+	return c.auditBroker.LogTestMessage(ctx, path, testProbe, entry.Options)
+}
+
+// rotateAuditHMAC replaces the salt of the enabled audit backend at the given
+// path, which is the key of the HMACs of the values it hashes, with a new one.
+func (c *Core) rotateAuditHMAC(ctx context.Context, path string) error {
+	c.auditLock.RLock()
+	defer c.auditLock.RUnlock()
+
+	var entry *MountEntry
+	for _, e := range c.audit.Entries {
+		if e.Path == path {
+			entry = e
+			break
+		}
+	}
+	if entry == nil {
+		return fmt.Errorf("no audit backend enabled at %q", path)
+	}
+
+	newSalt, err := uuid.GenerateUUID()
+	if err != nil {
+		return fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	view := NewBarrierView(c.barrier, entry.ViewPath())
+	err = c.auditBroker.RotateSalt(ctx, path, func(ctx context.Context) error {
+		return view.Put(ctx, &logical.StorageEntry{
+			Key:   salt.DefaultLocation,
+			Value: []byte(newSalt),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	c.logger.Info("rotated audit backend HMAC key", "path", path)
+	return nil
+}
+
 // enableAudit is used to enable a new audit backend
 func (c *Core) enableAudit(ctx context.Context, entry *MountEntry, updateStorage bool) error {
 	// Ensure we end the path in a slash
@@ -132,6 +195,9 @@ func (c *Core) enableAudit(ctx context.Context, entry *MountEntry, updateStorage
 		err = backend.LogTestMessage(ctx, testProbe, entry.Options)
 		if err != nil {
 			c.logger.Error("new audit backend failed test", "path", entry.Path, "type", entry.Type, "error", err)
+			if closable, ok := backend.(audit.Closable); ok {
+				closable.Close()
+			}
 			return fmt.Errorf("audit backend failed test message: %w", err)
 
 		}
@@ -154,6 +220,7 @@ func (c *Core) enableAudit(ctx context.Context, entry *MountEntry, updateStorage
 	}
 
 	c.audit = newTable
+	c.refreshAuditRequestMetrics()
 
 	// Register the backend
 	c.auditBroker.Register(entry.Path, backend, entry.Local, c.IsExperimentEnabled(experiments.VaultExperimentCoreAuditEventsAlpha1))
@@ -207,6 +274,7 @@ func (c *Core) disableAudit(ctx context.Context, path string, updateStorage bool
 	}
 
 	c.audit = newTable
+	c.refreshAuditRequestMetrics()
 
 	// Unmount the backend
 	c.auditBroker.Deregister(path, c.IsExperimentEnabled(experiments.VaultExperimentCoreAuditEventsAlpha1))
@@ -427,6 +495,7 @@ func (c *Core) setupAudits(ctx context.Context) error {
 	}
 
 	c.auditBroker = broker
+	c.refreshAuditRequestMetrics()
 	return nil
 }
 
@@ -443,11 +512,37 @@ func (c *Core) teardownAudits() error {
 		}
 	}
 
+	// Persist the entries buffered by the backends, as they are not used
+	// anymore.
+	if c.auditBroker != nil {
+		if err := c.auditBroker.Flush(context.Background()); err != nil {
+			c.logger.Error("failed to flush audit backends", "error", err)
+		}
+		c.auditBroker.Close()
+	}
+
 	c.audit = nil
 	c.auditBroker = nil
+	c.refreshAuditRequestMetrics()
 	return nil
 }
 
+// refreshAuditRequestMetrics determines whether any enabled audit device logs
+// request metrics, in which case metrics are recorded for every request. The
+// audit lock needs to be held before calling this.
+func (c *Core) refreshAuditRequestMetrics() {
+	enabled := false
+	if c.audit != nil {
+		for _, entry := range c.audit.Entries {
+			if value, err := strconv.ParseBool(entry.Options["log_request_metrics"]); err == nil && value {
+				enabled = true
+				break
+			}
+		}
+	}
+	c.requestTimelines.SetRecordRequestMetrics(enabled)
+}
+
 // removeAuditReloadFunc removes the reload func from the working set. The
 // audit lock needs to be held before calling this.
 func (c *Core) removeAuditReloadFunc(entry *MountEntry) {
@@ -477,6 +572,14 @@ func (c *Core) newAuditBackend(ctx context.Context, entry *MountEntry, view logi
 		HMACType: "hmac-sha256",
 		Location: salt.DefaultLocation,
 	}
+	switch conf["hmac_algorithm"] {
+	case "", "sha256":
+	case "sha512":
+		saltConfig.HMAC = sha512.New
+		saltConfig.HMACType = "hmac-sha512"
+	default:
+		return nil, fmt.Errorf("unsupported hmac_algorithm %q; must be sha256 or sha512", conf["hmac_algorithm"])
+	}
 
 	be, err := f(ctx, &audit.BackendConfig{
 		SaltView:   view,
@@ -493,6 +596,17 @@ func (c *Core) newAuditBackend(ctx context.Context, entry *MountEntry, view logi
 	auditLogger := c.baseLogger.Named("audit")
 	c.AddLogger(auditLogger)
 
+	switch conf["delivery_mode"] {
+	case "", audit.DeliveryModeDirect:
+	case audit.DeliveryModeWAL:
+		be, err = audit.NewWALBackend(entry.Path, be, conf["wal_path"], auditLogger.With("path", entry.Path))
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported delivery_mode %q; must be %q or %q", conf["delivery_mode"], audit.DeliveryModeDirect, audit.DeliveryModeWAL)
+	}
+
 	switch entry.Type {
 	case "file":
 		key := "audit_file|" + entry.Path
@@ -20,6 +20,34 @@ import (
 type backendEntry struct {
 	backend audit.Backend
 	local   bool
+
+	// sampler keeps a sample of the entries of the backend, or every entry
+	// when nil.
+	sampler *audit.EntrySampler
+
+	// headers are the request headers audited by the backend instead of
+	// those of the audited headers configuration, when not nil.
+	headers map[string]*auditedHeaderSettings
+
+	// status tracks the outcome of the attempts of the backend to log
+	// entries.
+	status *auditDeviceStatus
+}
+
+// applyHeaders returns the request headers audited by the backend, either
+// its own or those of the audited headers configuration.
+func (be backendEntry) applyHeaders(ctx context.Context, headers map[string][]string, headersConfig *AuditedHeadersConfig) (map[string][]string, error) {
+	if be.headers != nil {
+		return applyAuditedHeaders(ctx, be.headers, headers, be.backend.GetHash)
+	}
+	return headersConfig.ApplyConfig(ctx, headers, be.backend.GetHash)
+}
+
+// recordFailure records a failure of the backend to log an entry in its
+// status, and in the named failure metric of the backend.
+func (be backendEntry) recordFailure(name, metric string, err error) {
+	metrics.IncrCounter([]string{"audit", name, metric}, 1)
+	be.status.record(err)
 }
 
 // AuditBroker is used to provide a single ingest interface to auditable
@@ -28,6 +56,9 @@ type AuditBroker struct {
 	sync.RWMutex
 	backends map[string]backendEntry
 	logger   log.Logger
+
+	// tails holds the subscriptions to the entries of each backend
+	tails auditTails
 }
 
 // NewAuditBroker creates a new audit broker
@@ -46,10 +77,18 @@ func (a *AuditBroker) Register(name string, b audit.Backend, local bool, useEven
 	} else {
 		a.Lock()
 		defer a.Unlock()
-		a.backends[name] = backendEntry{
+		be := backendEntry{
 			backend: b,
 			local:   local,
+			status:  &auditDeviceStatus{},
+		}
+		if sampleable, ok := b.(audit.Sampleable); ok {
+			be.sampler = sampleable.Sampler()
 		}
+		if auditor, ok := b.(audit.HeaderAuditor); ok {
+			be.headers = auditedHeaderSettingsFromDevice(auditor.AuditedHeaders())
+		}
+		a.backends[name] = be
 	}
 }
 
@@ -60,10 +99,44 @@ func (a *AuditBroker) Deregister(name string, useEventLogger bool) {
 	} else {
 		a.Lock()
 		defer a.Unlock()
+
+		// Persist the entries buffered by the backend before it is removed.
+		if be, ok := a.backends[name]; ok {
+			if flushable, ok := be.backend.(audit.Flushable); ok {
+				if err := flushable.Flush(context.Background()); err != nil {
+					a.logger.Error("failed to flush audit backend", "path", name, "error", err)
+				}
+			}
+			if closable, ok := be.backend.(audit.Closable); ok {
+				if err := closable.Close(); err != nil {
+					a.logger.Error("failed to close audit backend", "path", name, "error", err)
+				}
+			}
+		}
+
 		delete(a.backends, name)
+		a.closeTails(name)
 	}
 }
 
+// RotateSalt persists a new salt for the named backend with the given func,
+// and makes the backend load it. No entry is logged meanwhile, so that no
+// entry is hashed with the previous salt once the rotation returns.
+func (a *AuditBroker) RotateSalt(ctx context.Context, name string, persist func(context.Context) error) error {
+	a.Lock()
+	defer a.Unlock()
+
+	be, ok := a.backends[name]
+	if !ok {
+		return fmt.Errorf("unknown audit backend %q", name)
+	}
+	if err := persist(ctx); err != nil {
+		return fmt.Errorf("failed to persist salt: %w", err)
+	}
+	be.backend.Invalidate(ctx)
+	return nil
+}
+
 // IsRegistered is used to check if a given audit backend is registered
 func (a *AuditBroker) IsRegistered(name string) bool {
 	a.RLock()
@@ -95,6 +168,55 @@ func (a *AuditBroker) GetHash(ctx context.Context, name string, input string) (s
 	return be.backend.GetHash(ctx, input)
 }
 
+// LogTestMessage is used to log the given test message with the named
+// backend only.
+func (a *AuditBroker) LogTestMessage(ctx context.Context, name string, in *logical.LogInput, config map[string]string) error {
+	a.RLock()
+	defer a.RUnlock()
+	be, ok := a.backends[name]
+	if !ok {
+		return fmt.Errorf("unknown audit backend %q", name)
+	}
+
+	return be.backend.LogTestMessage(ctx, in, config)
+}
+
+// Flush persists the entries buffered by the audit backends that buffer
+// them.
+func (a *AuditBroker) Flush(ctx context.Context) error {
+	a.RLock()
+	defer a.RUnlock()
+
+	var retErr *multierror.Error
+	for name, be := range a.backends {
+		flushable, ok := be.backend.(audit.Flushable)
+		if !ok {
+			continue
+		}
+		if err := flushable.Flush(ctx); err != nil {
+			retErr = multierror.Append(retErr, fmt.Errorf("failed to flush audit backend %q: %w", name, err))
+		}
+	}
+	return retErr.ErrorOrNil()
+}
+
+// Close releases the resources held by the audit backends, once they aren't
+// used anymore.
+func (a *AuditBroker) Close() {
+	a.RLock()
+	defer a.RUnlock()
+
+	for name, be := range a.backends {
+		closable, ok := be.backend.(audit.Closable)
+		if !ok {
+			continue
+		}
+		if err := closable.Close(); err != nil {
+			a.logger.Error("failed to close audit backend", "path", name, "error", err)
+		}
+	}
+}
+
 // LogRequest is used to ensure all the audit backends have an opportunity to
 // log the given request and that *at least one* succeeds.
 func (a *AuditBroker) LogRequest(ctx context.Context, in *logical.LogInput, headersConfig *AuditedHeadersConfig) (ret error) {
@@ -142,10 +264,19 @@ func (a *AuditBroker) LogRequest(ctx context.Context, in *logical.LogInput, head
 	// Ensure at least one backend logs
 	anyLogged := false
 	for name, be := range a.backends {
+		// Entries left out of the sample of the backend are deliberately
+		// not logged, which isn't a failure.
+		if !be.sampler.Keep(in) {
+			anyLogged = true
+			metrics.IncrCounter([]string{"audit", name, "sampled_out"}, 1)
+			continue
+		}
+
 		in.Request.Headers = nil
-		transHeaders, thErr := headersConfig.ApplyConfig(ctx, headers, be.backend.GetHash)
+		transHeaders, thErr := be.applyHeaders(ctx, headers, headersConfig)
 		if thErr != nil {
 			a.logger.Error("backend failed to include headers", "backend", name, "error", thErr)
+			be.recordFailure(name, "log_request_failure", thErr)
 			continue
 		}
 		in.Request.Headers = transHeaders
@@ -155,8 +286,11 @@ func (a *AuditBroker) LogRequest(ctx context.Context, in *logical.LogInput, head
 		metrics.MeasureSince([]string{"audit", name, "log_request"}, start)
 		if lrErr != nil {
 			a.logger.Error("backend failed to log request", "backend", name, "error", lrErr)
+			be.recordFailure(name, "log_request_failure", lrErr)
 		} else {
+			be.status.record(nil)
 			anyLogged = true
+			a.tailEntry(ctx, name, be.backend, in, false)
 		}
 	}
 	if !anyLogged && len(a.backends) > 0 {
@@ -207,10 +341,19 @@ func (a *AuditBroker) LogResponse(ctx context.Context, in *logical.LogInput, hea
 	// Ensure at least one backend logs
 	anyLogged := false
 	for name, be := range a.backends {
+		// Entries left out of the sample of the backend are deliberately
+		// not logged, which isn't a failure.
+		if !be.sampler.Keep(in) {
+			anyLogged = true
+			metrics.IncrCounter([]string{"audit", name, "sampled_out"}, 1)
+			continue
+		}
+
 		in.Request.Headers = nil
-		transHeaders, thErr := headersConfig.ApplyConfig(ctx, headers, be.backend.GetHash)
+		transHeaders, thErr := be.applyHeaders(ctx, headers, headersConfig)
 		if thErr != nil {
 			a.logger.Error("backend failed to include headers", "backend", name, "error", thErr)
+			be.recordFailure(name, "log_response_failure", thErr)
 			continue
 		}
 		in.Request.Headers = transHeaders
@@ -220,8 +363,11 @@ func (a *AuditBroker) LogResponse(ctx context.Context, in *logical.LogInput, hea
 		metrics.MeasureSince([]string{"audit", name, "log_response"}, start)
 		if lrErr != nil {
 			a.logger.Error("backend failed to log response", "backend", name, "error", lrErr)
+			be.recordFailure(name, "log_response_failure", lrErr)
 		} else {
+			be.status.record(nil)
 			anyLogged = true
+			a.tailEntry(ctx, name, be.backend, in, true)
 		}
 	}
 	if !anyLogged && len(a.backends) > 0 {
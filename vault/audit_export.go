@@ -0,0 +1,44 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/audit"
+)
+
+// Export calls fn with each entry kept by the named audit device which
+// involves the entity with the given ID, oldest first. Entries are redacted
+// with the export redaction rules of the device, in addition to the redaction
+// and hashing applied when they were logged, and are delivered as JSON.
+func (a *AuditBroker) Export(ctx context.Context, name, entityID string, fn func(entry []byte) error) error {
+	a.RLock()
+	be, ok := a.backends[name]
+	a.RUnlock()
+	if !ok {
+		return fmt.Errorf("unknown audit backend %q", name)
+	}
+	archived, ok := be.backend.(audit.Archived)
+	if !ok {
+		return fmt.Errorf("audit backend %q does not support exporting entries", name)
+	}
+
+	exporter, err := audit.NewEntityExporter(entityID, archived.ExportRedactions())
+	if err != nil {
+		return err
+	}
+
+	return archived.ReadArchive(ctx, func(entry []byte) error {
+		exported, err := exporter.Export(entry)
+		if err != nil {
+			return err
+		}
+		if exported == nil {
+			return nil
+		}
+		return fn(exported)
+	})
+}
@@ -0,0 +1,87 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"sync"
+	"time"
+)
+
+// AuditDeviceStatus reports the outcome of the attempts of an audit device to
+// log entries, since it was registered with the broker.
+type AuditDeviceStatus struct {
+	Successes           uint64
+	Failures            uint64
+	ConsecutiveFailures uint64
+	LastError           string
+	LastErrorTime       time.Time
+	LastSuccessTime     time.Time
+}
+
+// auditDeviceStatus maintains the status of an audit device.
+type auditDeviceStatus struct {
+	l      sync.Mutex
+	status AuditDeviceStatus
+}
+
+// record records the outcome of an attempt to log an entry.
+func (s *auditDeviceStatus) record(err error) {
+	now := time.Now()
+
+	s.l.Lock()
+	defer s.l.Unlock()
+	if err != nil {
+		s.status.Failures++
+		s.status.ConsecutiveFailures++
+		s.status.LastError = err.Error()
+		s.status.LastErrorTime = now
+		return
+	}
+	s.status.Successes++
+	s.status.ConsecutiveFailures = 0
+	s.status.LastSuccessTime = now
+}
+
+// get returns a snapshot of the status.
+func (s *auditDeviceStatus) get() AuditDeviceStatus {
+	s.l.Lock()
+	defer s.l.Unlock()
+	return s.status
+}
+
+// Status returns the status of the registered audit devices, by name. The
+// status is maintained by each node, and reset when the node is unsealed.
+func (a *AuditBroker) Status() map[string]AuditDeviceStatus {
+	a.RLock()
+	defer a.RUnlock()
+
+	statuses := make(map[string]AuditDeviceStatus, len(a.backends))
+	for name, be := range a.backends {
+		if be.status == nil {
+			continue
+		}
+		statuses[name] = be.status.get()
+	}
+	return statuses
+}
+
+// toResponseData returns the status as the data of an API response, with
+// empty times for the events which haven't happened yet.
+func (s AuditDeviceStatus) toResponseData() map[string]interface{} {
+	data := map[string]interface{}{
+		"successes":            s.Successes,
+		"failures":             s.Failures,
+		"consecutive_failures": s.ConsecutiveFailures,
+		"last_error":           s.LastError,
+		"last_error_time":      "",
+		"last_success_time":    "",
+	}
+	if !s.LastErrorTime.IsZero() {
+		data["last_error_time"] = s.LastErrorTime.Format(time.RFC3339Nano)
+	}
+	if !s.LastSuccessTime.IsZero() {
+		data["last_success_time"] = s.LastSuccessTime.Format(time.RFC3339Nano)
+	}
+	return data
+}
@@ -0,0 +1,190 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hashicorp/go-bexpr"
+	"github.com/hashicorp/vault/audit"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// auditTailBufferSize is the number of entries buffered for a tail
+// subscriber. Entries are dropped while the buffer is full.
+const auditTailBufferSize = 512
+
+// auditTailDatum is the data an audit tail filter expression is evaluated
+// against.
+type auditTailDatum struct {
+	Type          string `bexpr:"type"`
+	Operation     string `bexpr:"operation"`
+	Path          string `bexpr:"path"`
+	MountType     string `bexpr:"mount_type"`
+	MountPoint    string `bexpr:"mount_point"`
+	Namespace     string `bexpr:"namespace"`
+	RemoteAddress string `bexpr:"remote_address"`
+}
+
+// auditTail is a subscription to the entries of an audit device.
+type auditTail struct {
+	filter  *bexpr.Evaluator
+	ch      chan []byte
+	dropped uint64
+}
+
+// auditTails holds the tail subscriptions of an audit broker, by device.
+type auditTails struct {
+	l    sync.RWMutex
+	subs map[string]map[*auditTail]struct{}
+}
+
+// Tail subscribes to the entries logged by the named audit device that match
+// the given filter expression, which may be empty. Entries are delivered as
+// JSON, formatted and hashed by the device. The channel is closed when the
+// device is disabled, or when the returned function, which must be called to
+// end the subscription, is called.
+func (a *AuditBroker) Tail(name, filter string) (<-chan []byte, func(), error) {
+	a.RLock()
+	be, ok := a.backends[name]
+	a.RUnlock()
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown audit backend %q", name)
+	}
+	if _, ok := be.backend.(audit.Tailable); !ok {
+		return nil, nil, fmt.Errorf("audit backend %q does not support tailing", name)
+	}
+
+	t := &auditTail{
+		ch: make(chan []byte, auditTailBufferSize),
+	}
+	if filter != "" {
+		eval, err := bexpr.CreateEvaluator(filter)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse filter: %w", err)
+		}
+		t.filter = eval
+	}
+
+	a.tails.l.Lock()
+	if a.tails.subs == nil {
+		a.tails.subs = make(map[string]map[*auditTail]struct{})
+	}
+	if a.tails.subs[name] == nil {
+		a.tails.subs[name] = make(map[*auditTail]struct{})
+	}
+	a.tails.subs[name][t] = struct{}{}
+	a.tails.l.Unlock()
+
+	cancel := func() {
+		a.tails.l.Lock()
+		defer a.tails.l.Unlock()
+		if _, ok := a.tails.subs[name][t]; !ok {
+			return
+		}
+		delete(a.tails.subs[name], t)
+		if len(a.tails.subs[name]) == 0 {
+			delete(a.tails.subs, name)
+		}
+		close(t.ch)
+		if dropped := atomic.LoadUint64(&t.dropped); dropped > 0 {
+			a.logger.Debug("audit tail dropped entries", "backend", name, "dropped", dropped)
+		}
+	}
+	return t.ch, cancel, nil
+}
+
+// closeTails ends the tail subscriptions of the named backend, closing their
+// channels. It is called when the backend is deregistered.
+func (a *AuditBroker) closeTails(name string) {
+	a.tails.l.Lock()
+	defer a.tails.l.Unlock()
+	for t := range a.tails.subs[name] {
+		close(t.ch)
+	}
+	delete(a.tails.subs, name)
+}
+
+// tailEntry sends the entry of a request or response logged by the named
+// backend to the tail subscribers of the backend.
+func (a *AuditBroker) tailEntry(ctx context.Context, name string, be audit.Backend, in *logical.LogInput, response bool) {
+	a.tails.l.RLock()
+	defer a.tails.l.RUnlock()
+
+	subs := a.tails.subs[name]
+	if len(subs) == 0 {
+		return
+	}
+	tailable, ok := be.(audit.Tailable)
+	if !ok {
+		return
+	}
+
+	datum := auditTailDatum{
+		Type:          "request",
+		Operation:     string(in.Request.Operation),
+		Path:          in.Request.Path,
+		MountType:     in.Request.MountType,
+		MountPoint:    in.Request.MountPoint,
+		RemoteAddress: getRemoteAddr(in.Request),
+	}
+	if response {
+		datum.Type = "response"
+	}
+	if ns, err := namespace.FromContext(ctx); err == nil {
+		datum.Namespace = ns.Path
+	}
+
+	var matched []*auditTail
+	for t := range subs {
+		if t.filter != nil {
+			match, err := t.filter.Evaluate(datum)
+			if err != nil || !match {
+				continue
+			}
+		}
+		matched = append(matched, t)
+	}
+	if len(matched) == 0 {
+		return
+	}
+
+	var entry interface{}
+	var err error
+	if response {
+		entry, err = tailable.TailFormatter().FormatResponse(ctx, in)
+	} else {
+		entry, err = tailable.TailFormatter().FormatRequest(ctx, in)
+	}
+	if err != nil {
+		a.logger.Error("failed to format audit entry for tail", "backend", name, "error", err)
+		return
+	}
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		a.logger.Error("failed to encode audit entry for tail", "backend", name, "error", err)
+		return
+	}
+
+	for _, t := range matched {
+		select {
+		case t.ch <- raw:
+		default:
+			atomic.AddUint64(&t.dropped, 1)
+		}
+	}
+}
+
+// getRemoteAddr returns the remote address of the request, if known.
+func getRemoteAddr(req *logical.Request) string {
+	if req.Connection != nil {
+		return req.Connection.RemoteAddr
+	}
+	return ""
+}
@@ -425,6 +425,298 @@ func TestAuditBroker_LogRequest(t *testing.T) {
 	}
 }
 
+// headerAuditingNoopAudit is a NoopAudit auditing its own request headers.
+type headerAuditingNoopAudit struct {
+	*corehelpers.NoopAudit
+	headers map[string]bool
+}
+
+func (n *headerAuditingNoopAudit) AuditedHeaders() map[string]bool {
+	return n.headers
+}
+
+// TestAuditBroker_LogRequest_DeviceHeaders ensures that devices auditing their
+// own request headers log those rather than the headers of the audited
+// headers configuration, matched case-insensitively.
+func TestAuditBroker_LogRequest_DeviceHeaders(t *testing.T) {
+	l := logging.NewVaultLogger(log.Trace)
+	b := NewAuditBroker(l)
+	a1 := corehelpers.TestNoopAudit(t, nil)
+	a2 := &headerAuditingNoopAudit{
+		NoopAudit: corehelpers.TestNoopAudit(t, nil),
+		headers: map[string]bool{
+			"x-vault-header": false,
+			"x-other-header": true,
+		},
+	}
+	b.Register("foo", a1, false, false)
+	b.Register("bar", a2, false, false)
+
+	headersConf := &AuditedHeadersConfig{
+		Headers: map[string]*auditedHeaderSettings{
+			"x-test-header":  {HMAC: false},
+			"x-vault-header": {HMAC: true},
+		},
+	}
+
+	req := &logical.Request{
+		ID:        "foo",
+		Operation: logical.ReadOperation,
+		Path:      "sys/mounts",
+		Headers: map[string][]string{
+			"X-Test-Header":  {"foo"},
+			"X-Vault-Header": {"bar"},
+			"X-Other-HEADER": {"baz"},
+		},
+	}
+	ctx := namespace.RootContext(context.Background())
+	if err := b.LogRequest(ctx, &logical.LogInput{Request: req}, headersConf); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	hashedVault, err := a1.GetHash(ctx, "bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]string{
+		"x-test-header":  {"foo"},
+		"x-vault-header": {hashedVault},
+	}
+	if !reflect.DeepEqual(a1.ReqHeaders[0], expected) {
+		t.Fatalf("Expected headers did not match actual: Expected %#v\n Got %#v\n", expected, a1.ReqHeaders[0])
+	}
+
+	hashedOther, err := a2.GetHash(ctx, "baz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = map[string][]string{
+		"x-vault-header": {"bar"},
+		"x-other-header": {hashedOther},
+	}
+	if !reflect.DeepEqual(a2.ReqHeaders[0], expected) {
+		t.Fatalf("Expected headers did not match actual: Expected %#v\n Got %#v\n", expected, a2.ReqHeaders[0])
+	}
+
+	// The headers of the request are left untouched
+	if len(req.Headers) != 3 || req.Headers["X-Vault-Header"][0] != "bar" {
+		t.Fatalf("Req headers were changed: %#v", req.Headers)
+	}
+}
+
+func TestAuditBroker_Tail(t *testing.T) {
+	l := logging.NewVaultLogger(log.Trace)
+	b := NewAuditBroker(l)
+	a1 := corehelpers.TestNoopAudit(t, nil)
+	b.Register("foo", a1, false, false)
+
+	if _, _, err := b.Tail("bar", ""); err == nil {
+		t.Fatal("expected an error tailing an unknown backend")
+	}
+	if _, _, err := b.Tail("foo", "path =="); err == nil {
+		t.Fatal("expected an error for an invalid filter")
+	}
+
+	entryCh, cancel, err := b.Tail("foo", `path == "sys/mounts"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+
+	headersConf := &AuditedHeadersConfig{
+		Headers: make(map[string]*auditedHeaderSettings),
+	}
+	ctx := namespace.RootContext(context.Background())
+	for _, path := range []string{"secret/foo", "sys/mounts"} {
+		logInput := &logical.LogInput{
+			Auth: &logical.Auth{
+				ClientToken: "foo",
+			},
+			Request: &logical.Request{
+				Operation: logical.ReadOperation,
+				Path:      path,
+			},
+		}
+		if err := b.LogRequest(ctx, logInput, headersConf); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var entry map[string]interface{}
+	select {
+	case raw := <-entryCh:
+		if err := jsonutil.DecodeJSON(raw, &entry); err != nil {
+			t.Fatal(err)
+		}
+	default:
+		t.Fatal("expected an entry")
+	}
+	if path := entry["request"].(map[string]interface{})["path"]; path != "sys/mounts" {
+		t.Fatalf("expected only the matching entry, got path %v", path)
+	}
+	if token := entry["auth"].(map[string]interface{})["client_token"]; token == "foo" {
+		t.Fatal("expected the client token to be hashed")
+	}
+	select {
+	case raw := <-entryCh:
+		t.Fatalf("unexpected entry: %s", raw)
+	default:
+	}
+
+	// Disabling the device ends the subscription
+	b.Deregister("foo", false)
+	if _, ok := <-entryCh; ok {
+		t.Fatal("expected the channel to be closed")
+	}
+}
+
+// TestAuditBroker_Export ensures that only the entries involving the entity
+// are exported, redacted with the export redaction rules of the device.
+func TestAuditBroker_Export(t *testing.T) {
+	l := logging.NewVaultLogger(log.Trace)
+	b := NewAuditBroker(l)
+	a1 := corehelpers.TestNoopAudit(t, map[string]string{
+		"export_redact": "$.request.remote_address",
+	})
+	b.Register("foo", a1, false, false)
+
+	collect := func(entityID string) ([]map[string]interface{}, error) {
+		var entries []map[string]interface{}
+		err := b.Export(context.Background(), "foo", entityID, func(raw []byte) error {
+			var entry map[string]interface{}
+			if err := jsonutil.DecodeJSON(raw, &entry); err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+			return nil
+		})
+		return entries, err
+	}
+
+	if err := b.Export(context.Background(), "bar", "alice", nil); err == nil {
+		t.Fatal("expected an error exporting from an unknown backend")
+	}
+	if _, err := collect(""); err == nil {
+		t.Fatal("expected an error without entity ID")
+	}
+
+	headersConf := &AuditedHeadersConfig{
+		Headers: make(map[string]*auditedHeaderSettings),
+	}
+	ctx := namespace.RootContext(context.Background())
+	for _, entityID := range []string{"alice", "bob"} {
+		logInput := &logical.LogInput{
+			Auth: &logical.Auth{
+				ClientToken: "foo",
+				EntityID:    entityID,
+			},
+			Request: &logical.Request{
+				Operation: logical.ReadOperation,
+				Path:      "secret/foo",
+				Connection: &logical.Connection{
+					RemoteAddr: "127.0.0.1",
+				},
+			},
+		}
+		if err := b.LogRequest(ctx, logInput, headersConf); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := collect("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	if entityID := entries[0]["auth"].(map[string]interface{})["entity_id"]; entityID != "alice" {
+		t.Fatalf("expected the entry of alice, got %v", entityID)
+	}
+	if addr := entries[0]["request"].(map[string]interface{})["remote_address"]; addr != audit.RedactedValue {
+		t.Fatalf("expected the remote address to be redacted, got %v", addr)
+	}
+
+	entries, err = collect("carol")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected no entries, got %d", len(entries))
+	}
+}
+
+// TestAuditBroker_Status ensures that the broker maintains the outcome of the
+// attempts of each backend to log entries.
+func TestAuditBroker_Status(t *testing.T) {
+	l := logging.NewVaultLogger(log.Trace)
+	b := NewAuditBroker(l)
+	a1 := corehelpers.TestNoopAudit(t, nil)
+	a2 := corehelpers.TestNoopAudit(t, nil)
+	b.Register("foo", a1, false, false)
+	b.Register("bar", a2, false, false)
+
+	headersConf := &AuditedHeadersConfig{
+		Headers: make(map[string]*auditedHeaderSettings),
+	}
+	logInput := &logical.LogInput{
+		Auth: &logical.Auth{
+			ClientToken: "foo",
+		},
+		Request: &logical.Request{
+			Operation: logical.ReadOperation,
+			Path:      "sys/mounts",
+		},
+	}
+	ctx := namespace.RootContext(context.Background())
+
+	start := time.Now()
+	if err := b.LogRequest(ctx, logInput, headersConf); err != nil {
+		t.Fatal(err)
+	}
+	a2.RespErr = errors.New("disk full")
+	for i := 0; i < 2; i++ {
+		if err := b.LogResponse(ctx, logInput, headersConf); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	statuses := b.Status()
+	if len(statuses) != 2 {
+		t.Fatalf("expected the status of 2 backends, got %#v", statuses)
+	}
+
+	foo := statuses["foo"]
+	if foo.Successes != 3 || foo.Failures != 0 || foo.LastError != "" || !foo.LastErrorTime.IsZero() {
+		t.Fatalf("bad status of the healthy backend: %#v", foo)
+	}
+	if foo.LastSuccessTime.Before(start) {
+		t.Fatalf("expected the last success to be recorded, got %v", foo.LastSuccessTime)
+	}
+
+	bar := statuses["bar"]
+	if bar.Successes != 1 || bar.Failures != 2 || bar.ConsecutiveFailures != 2 || bar.LastError != "disk full" {
+		t.Fatalf("bad status of the failing backend: %#v", bar)
+	}
+	if bar.LastErrorTime.Before(bar.LastSuccessTime) {
+		t.Fatalf("expected the last error after the last success, got %v and %v", bar.LastErrorTime, bar.LastSuccessTime)
+	}
+
+	// A success resets the consecutive failures.
+	a2.RespErr = nil
+	if err := b.LogResponse(ctx, logInput, headersConf); err != nil {
+		t.Fatal(err)
+	}
+	if bar := b.Status()["bar"]; bar.ConsecutiveFailures != 0 || bar.LastError != "disk full" {
+		t.Fatalf("bad status of the recovered backend: %#v", bar)
+	}
+
+	b.Deregister("bar", false)
+	if _, ok := b.Status()["bar"]; ok {
+		t.Fatal("expected no status for a deregistered backend")
+	}
+}
+
 func TestAuditBroker_LogResponse(t *testing.T) {
 	l := logging.NewVaultLogger(log.Trace)
 	b := NewAuditBroker(l)
@@ -97,6 +97,26 @@ func (a *AuditedHeadersConfig) ApplyConfig(ctx context.Context, headers map[stri
 	a.RLock()
 	defer a.RUnlock()
 
+	return applyAuditedHeaders(ctx, a.Headers, headers, hashFunc)
+}
+
+// auditedHeaderSettingsFromDevice converts the headers audited by an audit
+// device, keyed by their lower case names, to header settings.
+func auditedHeaderSettingsFromDevice(headers map[string]bool) map[string]*auditedHeaderSettings {
+	if headers == nil {
+		return nil
+	}
+
+	settings := make(map[string]*auditedHeaderSettings, len(headers))
+	for name, hmac := range headers {
+		settings[strings.ToLower(name)] = &auditedHeaderSettings{HMAC: hmac}
+	}
+	return settings
+}
+
+// applyAuditedHeaders returns a map of the headers approved by the settings,
+// keyed by lower case name, and their values, either hmac'ed or plaintext
+func applyAuditedHeaders(ctx context.Context, settings map[string]*auditedHeaderSettings, headers map[string][]string, hashFunc func(context.Context, string) (string, error)) (map[string][]string, error) {
 	// Make a copy of the incoming headers with everything lower so we can
 	// case-insensitively compare
 	lowerHeaders := make(map[string][]string, len(headers))
@@ -104,15 +124,15 @@ func (a *AuditedHeadersConfig) ApplyConfig(ctx context.Context, headers map[stri
 		lowerHeaders[strings.ToLower(k)] = v
 	}
 
-	result = make(map[string][]string, len(a.Headers))
-	for key, settings := range a.Headers {
+	result := make(map[string][]string, len(settings))
+	for key, setting := range settings {
 		if val, ok := lowerHeaders[key]; ok {
 			// copy the header values so we don't overwrite them
 			hVals := make([]string, len(val))
 			copy(hVals, val)
 
 			// Optionally hmac the values
-			if settings.HMAC {
+			if setting.HMAC {
 				for i, el := range hVals {
 					hVal, err := hashFunc(ctx, el)
 					if err != nil {
@@ -1018,9 +1018,11 @@ func (c *Core) newCredentialBackend(ctx context.Context, entry *MountEntry, sysV
 		System:      sysView,
 		BackendUUID: entry.BackendAwareUUID,
 	}
+	var eventsSender logical.EventSender
 	if c.IsExperimentEnabled(experiments.VaultExperimentEventsAlpha1) {
-		config.EventsSender = pluginEventSender
+		eventsSender = pluginEventSender
 	}
+	config.EventsSender = c.newHealthWarningSender(entry, eventsSender)
 
 	b, err := f(ctx, config)
 	if err != nil {
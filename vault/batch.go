@@ -0,0 +1,240 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/vault/quotas"
+	"github.com/mitchellh/mapstructure"
+)
+
+const (
+	// batchRequestPath is the path of batch requests, relative to the
+	// namespace of the request, which can't be nested.
+	batchRequestPath = "sys/batch"
+
+	// maxBatchRequests is the number of requests a batch may hold.
+	maxBatchRequests = 128
+
+	// batchRequestConcurrency is the number of requests of a batch handled
+	// at once.
+	batchRequestConcurrency = 16
+)
+
+// batchRequestItem is a request of a batch.
+type batchRequestItem struct {
+	Operation string                 `mapstructure:"operation"`
+	Path      string                 `mapstructure:"path"`
+	Data      map[string]interface{} `mapstructure:"data"`
+}
+
+// batchOperations are the operations requests of a batch may use.
+var batchOperations = map[string]logical.Operation{
+	"read":   logical.ReadOperation,
+	"list":   logical.ListOperation,
+	"create": logical.CreateOperation,
+	"update": logical.UpdateOperation,
+	"patch":  logical.PatchOperation,
+	"delete": logical.DeleteOperation,
+}
+
+// handleBatchRequest handles the independent requests of a batch
+// concurrently, and returns the status and response of each of them, in the
+// order of the batch. The batch itself is authorized and audited as a request
+// to sys/batch. Every request of the batch is made with the client token of
+// the batch, so it is subject to the token's policies, the rate limit quotas of
+// its path and audited like a request of its own. A failed request doesn't fail
+// the batch.
+func (b *SystemBackend) handleBatchRequest(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	rawItems := d.Get("requests").([]interface{})
+	if len(rawItems) == 0 {
+		return logical.ErrorResponse("requests must be a non-empty list"), logical.ErrInvalidRequest
+	}
+	if len(rawItems) > maxBatchRequests {
+		return logical.ErrorResponse("a batch holds at most %d requests", maxBatchRequests), logical.ErrInvalidRequest
+	}
+
+	items := make([]*batchRequestItem, len(rawItems))
+	for i, raw := range rawItems {
+		item := &batchRequestItem{}
+		if err := mapstructure.Decode(raw, item); err != nil {
+			return logical.ErrorResponse("invalid request %d: %s", i, err), logical.ErrInvalidRequest
+		}
+		items[i] = item
+	}
+
+	results := make([]map[string]interface{}, len(items))
+	sem := make(chan struct{}, batchRequestConcurrency)
+	var wg sync.WaitGroup
+	for i, item := range items {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, item *batchRequestItem) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i] = b.Core.handleBatchRequestItem(ctx, req, item)
+		}(i, item)
+	}
+	wg.Wait()
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"responses": results,
+		},
+	}, nil
+}
+
+// handleBatchRequestItem handles a request of a batch, and returns its
+// status and response.
+func (c *Core) handleBatchRequestItem(ctx context.Context, parent *logical.Request, item *batchRequestItem) map[string]interface{} {
+	operation := item.Operation
+	if operation == "" {
+		operation = "read"
+	}
+	op, ok := batchOperations[operation]
+	if !ok {
+		return batchErrorResult(http.StatusBadRequest, fmt.Errorf("unsupported operation %q", item.Operation))
+	}
+	path := strings.TrimPrefix(item.Path, "/")
+	if path == "" {
+		return batchErrorResult(http.StatusBadRequest, fmt.Errorf("path is required"))
+	}
+	if strings.Trim(path, "/") == batchRequestPath {
+		return batchErrorResult(http.StatusBadRequest, fmt.Errorf("batches can't be nested"))
+	}
+
+	if status, err := c.applyBatchRequestItemQuota(ctx, parent, op, path, item.Data); err != nil {
+		return batchErrorResult(status, err)
+	}
+
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return batchErrorResult(http.StatusInternalServerError, err)
+	}
+	req := &logical.Request{
+		ID:                id,
+		Operation:         op,
+		Path:              path,
+		Data:              item.Data,
+		ClientToken:       parent.ClientToken,
+		ClientTokenSource: parent.ClientTokenSource,
+		Connection:        parent.Connection,
+		Headers:           parent.Headers,
+	}
+	if req.Data == nil {
+		req.Data = make(map[string]interface{})
+	}
+
+	resp, err := c.handleCancelableRequest(ctx, req)
+	status, err := logical.RespondErrorCommon(req, resp, err)
+	if err != nil {
+		return batchErrorResult(status, err)
+	}
+	if status == 0 {
+		status = http.StatusOK
+		if resp == nil {
+			status = http.StatusNoContent
+		}
+	}
+	if status >= 400 {
+		return batchErrorResult(status, fmt.Errorf("%s", http.StatusText(status)))
+	}
+
+	result := map[string]interface{}{
+		"status": status,
+	}
+	if resp == nil {
+		return result
+	}
+	if resp.Data != nil {
+		result["data"] = resp.Data
+	}
+	if len(resp.Warnings) > 0 {
+		result["warnings"] = resp.Warnings
+	}
+	if resp.Secret != nil {
+		result["lease_id"] = resp.Secret.LeaseID
+		result["lease_duration"] = int(resp.Secret.TTL.Seconds())
+		result["renewable"] = resp.Secret.Renewable
+	}
+	if resp.Auth != nil {
+		result["auth"] = map[string]interface{}{
+			"client_token":   resp.Auth.ClientToken,
+			"accessor":       resp.Auth.Accessor,
+			"policies":       resp.Auth.Policies,
+			"token_policies": resp.Auth.TokenPolicies,
+			"entity_id":      resp.Auth.EntityID,
+			"lease_duration": int(resp.Auth.TTL.Seconds()),
+			"renewable":      resp.Auth.Renewable,
+		}
+	}
+	return result
+}
+
+// applyBatchRequestItemQuota applies the rate limit quotas of the path of a
+// request of a batch, as if it were made on its own, and returns the status
+// and error of the request if it is rejected.
+func (c *Core) applyBatchRequestItemQuota(ctx context.Context, parent *logical.Request, op logical.Operation, path string, data map[string]interface{}) (int, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	mountPath := strings.TrimPrefix(c.MatchingMount(ctx, path), ns.Path)
+	var clientAddress string
+	if parent.Connection != nil {
+		clientAddress = parent.Connection.RemoteAddr
+	}
+
+	quotaResp, err := c.ApplyRateLimitQuota(ctx, &quotas.Request{
+		Type:          quotas.TypeRateLimit,
+		Path:          path,
+		MountPath:     mountPath,
+		Role:          c.DetermineRoleFromLoginRequest(mountPath, data, ctx),
+		NamespacePath: ns.Path,
+		ClientAddress: clientAddress,
+	})
+	if err != nil {
+		c.logger.Error("failed to apply quota", "path", path, "error", err)
+		return http.StatusUnprocessableEntity, err
+	}
+	if quotaResp.Allowed {
+		return 0, nil
+	}
+
+	quotaErr := fmt.Errorf("request path %q: %w", path, quotas.ErrRateLimitQuotaExceeded)
+	if c.RateLimitAuditLoggingEnabled() {
+		err := c.AuditLogger().AuditRequest(ctx, &logical.LogInput{
+			Request: &logical.Request{
+				Operation:  op,
+				Path:       path,
+				Data:       data,
+				Connection: parent.Connection,
+			},
+			OuterErr: quotaErr,
+		})
+		if err != nil {
+			c.logger.Warn("failed to audit log request rejection caused by rate limit quota violation", "error", err)
+		}
+	}
+	return http.StatusTooManyRequests, quotaErr
+}
+
+// batchErrorResult returns the result of a request of a batch which failed.
+func batchErrorResult(status int, err error) map[string]interface{} {
+	return map[string]interface{}{
+		"status": status,
+		"errors": []string{err.Error()},
+	}
+}
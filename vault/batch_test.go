@@ -0,0 +1,116 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCore_BatchRequest(t *testing.T) {
+	c, _, root := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	for _, key := range []string{"app/db", "app/api", "other/db"} {
+		req := logical.TestRequest(t, logical.UpdateOperation, "secret/"+key)
+		req.Data["value"] = key
+		req.ClientToken = root
+		_, err := c.HandleRequest(ctx, req)
+		require.NoError(t, err)
+	}
+
+	policy, err := ParseACLPolicy(namespace.RootNamespace, `
+path "secret/app/*" {
+	capabilities = ["read", "update"]
+}
+
+path "sys/batch" {
+	capabilities = ["update"]
+}
+`)
+	require.NoError(t, err)
+	policy.Name = "app"
+	require.NoError(t, c.policyStore.SetPolicy(ctx, policy))
+	testMakeServiceTokenViaCore(t, c, root, "app-token", "1h", []string{"app"})
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "sys/batch")
+	req.ClientToken = "app-token"
+	req.Data["requests"] = []interface{}{
+		map[string]interface{}{"path": "secret/app/db"},
+		map[string]interface{}{"operation": "read", "path": "secret/app/api"},
+		map[string]interface{}{"operation": "update", "path": "secret/app/cache", "data": map[string]interface{}{"value": "app/cache"}},
+		map[string]interface{}{"path": "secret/app/missing"},
+		map[string]interface{}{"path": "secret/other/db"},
+		map[string]interface{}{"operation": "rotate", "path": "secret/app/db"},
+		map[string]interface{}{"operation": "update", "path": "sys/batch"},
+	}
+	resp, err := c.HandleRequest(ctx, req)
+	require.NoError(t, err)
+	results := resp.Data["responses"].([]map[string]interface{})
+	require.Len(t, results, 7)
+
+	require.Equal(t, http.StatusOK, results[0]["status"])
+	require.Equal(t, "app/db", results[0]["data"].(map[string]interface{})["value"])
+	require.Equal(t, http.StatusOK, results[1]["status"])
+	require.Equal(t, "app/api", results[1]["data"].(map[string]interface{})["value"])
+	require.Equal(t, http.StatusNoContent, results[2]["status"])
+	require.Equal(t, http.StatusNotFound, results[3]["status"])
+	require.Equal(t, http.StatusForbidden, results[4]["status"])
+	require.NotEmpty(t, results[4]["errors"])
+	require.Equal(t, http.StatusBadRequest, results[5]["status"])
+	require.Equal(t, http.StatusBadRequest, results[6]["status"])
+
+	// The writes of the batch are made.
+	readReq := logical.TestRequest(t, logical.ReadOperation, "secret/app/cache")
+	readReq.ClientToken = root
+	resp, err = c.HandleRequest(ctx, readReq)
+	require.NoError(t, err)
+	require.Equal(t, "app/cache", resp.Data["value"])
+
+	// Batches are authorized as requests of their own.
+	testMakeServiceTokenViaCore(t, c, root, "default-token", "1h", []string{"default"})
+	req = logical.TestRequest(t, logical.UpdateOperation, "sys/batch")
+	req.ClientToken = "default-token"
+	req.Data["requests"] = []interface{}{
+		map[string]interface{}{"path": "secret/app/db"},
+	}
+	_, err = c.HandleRequest(ctx, req)
+	require.ErrorIs(t, err, logical.ErrPermissionDenied)
+
+	// The requests of a batch are subject to the rate limit quotas of their
+	// paths.
+	quotaReq := logical.TestRequest(t, logical.UpdateOperation, "sys/quotas/rate-limit/other")
+	quotaReq.ClientToken = root
+	quotaReq.Data = map[string]interface{}{
+		"path":     "secret/other/",
+		"rate":     1,
+		"interval": "1m",
+	}
+	resp, err = c.HandleRequest(ctx, quotaReq)
+	require.NoError(t, err)
+	require.False(t, resp != nil && resp.IsError())
+	for _, status := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		req = logical.TestRequest(t, logical.UpdateOperation, "sys/batch")
+		req.ClientToken = root
+		req.Data["requests"] = []interface{}{
+			map[string]interface{}{"path": "secret/other/db"},
+			map[string]interface{}{"path": "secret/app/db"},
+		}
+		resp, err = c.HandleRequest(ctx, req)
+		require.NoError(t, err)
+		results = resp.Data["responses"].([]map[string]interface{})
+		require.Equal(t, status, results[0]["status"])
+		require.Equal(t, http.StatusOK, results[1]["status"])
+	}
+
+	// Batches must hold requests.
+	req = logical.TestRequest(t, logical.UpdateOperation, "sys/batch")
+	req.ClientToken = root
+	_, err = c.HandleRequest(ctx, req)
+	require.ErrorIs(t, err, logical.ErrInvalidRequest)
+}
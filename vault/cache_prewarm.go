@@ -0,0 +1,176 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/sdk/physical"
+)
+
+const (
+	// coreCacheHotKeysPath is the path where the active node publishes the
+	// hottest keys of its storage cache.
+	coreCacheHotKeysPath = "core/cache-hot-keys"
+
+	// cacheHotKeysInterval is how often the active node publishes its hot
+	// keys, and how often standbys load them.
+	cacheHotKeysInterval = time.Minute
+
+	// cacheHotKeysMax is the maximum number of hot keys published.
+	cacheHotKeysMax = 4096
+
+	// cachePrewarmWorkers is the number of storage reads made concurrently
+	// when prewarming the cache.
+	cachePrewarmWorkers = 16
+)
+
+// cacheHotKeys is the list of hot keys published by the active node.
+type cacheHotKeys struct {
+	Keys      []string  `json:"keys"`
+	Published time.Time `json:"published"`
+}
+
+// cachePrewarmEnabled returns whether the storage cache is prewarmed when
+// this node becomes active.
+func (c *Core) cachePrewarmEnabled() bool {
+	if c.cachingDisabled || c.disableCachePrewarm {
+		return false
+	}
+	_, ok := c.physicalCache.(physical.HotKeysReporter)
+	return ok
+}
+
+// runCacheHotKeysPublisher periodically publishes the hottest keys of the
+// storage cache of the active node, until the context is done.
+func (c *Core) runCacheHotKeysPublisher(ctx context.Context) {
+	ticker := c.clock.NewTicker(cacheHotKeysInterval)
+	defer ticker.Stop()
+
+	var last []string
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		keys := c.physicalCache.(physical.HotKeysReporter).HotKeys(cacheHotKeysMax)
+		if reflect.DeepEqual(keys, last) {
+			continue
+		}
+		if err := c.publishCacheHotKeys(ctx, keys); err != nil {
+			c.logger.Warn("failed to publish cache hot keys", "error", err)
+			continue
+		}
+		last = keys
+	}
+}
+
+func (c *Core) publishCacheHotKeys(ctx context.Context, keys []string) error {
+	value, err := json.Marshal(&cacheHotKeys{
+		Keys:      keys,
+		Published: c.clock.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	return c.barrier.Put(ctx, &logical.StorageEntry{
+		Key:   coreCacheHotKeysPath,
+		Value: value,
+	})
+}
+
+func (c *Core) loadCacheHotKeys(ctx context.Context) ([]string, error) {
+	entry, err := c.barrier.Get(ctx, coreCacheHotKeysPath)
+	if err != nil || entry == nil {
+		return nil, err
+	}
+
+	var hotKeys cacheHotKeys
+	if err := json.Unmarshal(entry.Value, &hotKeys); err != nil {
+		return nil, err
+	}
+	return hotKeys.Keys, nil
+}
+
+// periodicCacheHotKeysRefresh is run on standbys to keep the hot keys
+// published by the active node loaded, so that the cache can be prewarmed
+// as soon as this node becomes active. The entries themselves are not read
+// ahead of time: the cache is not invalidated by writes of the active node,
+// so they would be stale by the time this node becomes active.
+func (c *Core) periodicCacheHotKeysRefresh(stopCh chan struct{}) {
+	ticker := c.clock.NewTicker(cacheHotKeysInterval)
+	defer ticker.Stop()
+
+	for {
+		keys, err := c.loadCacheHotKeys(context.Background())
+		if err != nil {
+			c.logger.Debug("failed to load cache hot keys", "error", err)
+		} else {
+			c.cacheHotKeys.Store(keys)
+		}
+
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// prewarmCache reads the hot keys published by the previous active node into
+// the storage cache. The keys loaded while this node was a standby are used
+// if there are any.
+func (c *Core) prewarmCache(ctx context.Context) {
+	defer metrics.MeasureSince([]string{"core", "cache", "prewarm"}, time.Now())
+
+	keys, _ := c.cacheHotKeys.Load().([]string)
+	c.cacheHotKeys.Store([]string(nil))
+	if len(keys) == 0 {
+		var err error
+		keys, err = c.loadCacheHotKeys(ctx)
+		if err != nil {
+			c.logger.Warn("failed to load cache hot keys", "error", err)
+			return
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+
+	start := time.Now()
+	work := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < cachePrewarmWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range work {
+				// Errors are ignored, the key will be read again when it is
+				// needed.
+				_, _ = c.physical.Get(ctx, key)
+			}
+		}()
+	}
+
+LOOP:
+	for _, key := range keys {
+		select {
+		case <-ctx.Done():
+			break LOOP
+		case work <- key:
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	c.logger.Info("prewarmed storage cache", "keys", len(keys), "elapsed", time.Since(start))
+}
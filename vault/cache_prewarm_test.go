@@ -0,0 +1,66 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"testing"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/sdk/physical"
+)
+
+func TestCore_PrewarmCache(t *testing.T) {
+	c, _, _ := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	if !c.cachePrewarmEnabled() {
+		t.Fatal("expected cache prewarming to be enabled")
+	}
+	cache := c.physicalCache.(physical.HotKeysReporter)
+
+	for _, key := range []string{"prewarm/a", "prewarm/b"} {
+		if err := c.barrier.Put(ctx, &logical.StorageEntry{Key: key, Value: []byte(key)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	keys := cache.HotKeys(0)
+	if err := c.publishCacheHotKeys(ctx, keys); err != nil {
+		t.Fatal(err)
+	}
+
+	isCached := func() map[string]bool {
+		cached := make(map[string]bool)
+		for _, key := range cache.HotKeys(0) {
+			cached[key] = true
+		}
+		return cached
+	}
+
+	c.physicalCache.Purge(ctx)
+	if cached := isCached(); cached["prewarm/a"] || cached["prewarm/b"] {
+		t.Fatal("expected the cache to be purged")
+	}
+
+	c.prewarmCache(ctx)
+	cached := isCached()
+	for _, key := range append(keys, "prewarm/a", "prewarm/b") {
+		if !cached[key] {
+			t.Fatalf("expected %q to be cached", key)
+		}
+	}
+
+	// Keys loaded while standing by are used instead of the published ones,
+	// and only once.
+	c.physicalCache.Purge(ctx)
+	c.cacheHotKeys.Store([]string{"prewarm/a"})
+	c.prewarmCache(ctx)
+	if cached := isCached(); !cached["prewarm/a"] || cached["prewarm/b"] {
+		t.Fatalf("expected only the loaded key to be cached, got: %v", cached)
+	}
+	if keys, _ := c.cacheHotKeys.Load().([]string); keys != nil {
+		t.Fatalf("expected the loaded keys to be cleared, got: %v", keys)
+	}
+}
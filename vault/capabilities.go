@@ -72,6 +72,16 @@ func (c *Core) Capabilities(ctx context.Context, token, path string) ([]string,
 		policyCount++
 	}
 
+	// Add the policies of the mounts owned by the entity or its groups
+	if !te.NoIdentityPolicies {
+		ownerPolicies, err := c.mountOwnerACLPolicies(ctx, entity)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, ownerPolicies...)
+		policyCount += len(ownerPolicies)
+	}
+
 	if policyCount == 0 {
 		return []string{DenyCapability}, nil
 	}
@@ -47,10 +47,12 @@ import (
 	"github.com/hashicorp/vault/helper/metricsutil"
 	"github.com/hashicorp/vault/helper/namespace"
 	"github.com/hashicorp/vault/helper/osutil"
+	"github.com/hashicorp/vault/helper/timeutil"
 	"github.com/hashicorp/vault/physical/raft"
 	"github.com/hashicorp/vault/sdk/helper/certutil"
 	"github.com/hashicorp/vault/sdk/helper/consts"
 	"github.com/hashicorp/vault/sdk/helper/jsonutil"
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
 	"github.com/hashicorp/vault/sdk/helper/logging"
 	"github.com/hashicorp/vault/sdk/helper/pathmanager"
 	"github.com/hashicorp/vault/sdk/logical"
@@ -363,10 +365,27 @@ type Core struct {
 	// change underneath a calling function
 	mountsLock locking.DeadlockRWMutex
 
+	// mountDataKeys holds the *dataKeyStorage encrypting the entries of the
+	// mounts created with a data key, by mount UUID
+	mountDataKeys sync.Map
+
+	// routedRequests holds the *routedRequest being handled by mounts on
+	// behalf of entities, by request ID, so that their plugins can only issue
+	// identity tokens to the entities of the requests they handle
+	routedRequests sync.Map
+
 	// mountMigrationTracker tracks past and ongoing remount operations
 	// against their migration ids
 	mountMigrationTracker *sync.Map
 
+	// secretsImportTracker tracks past and ongoing imports of secrets from
+	// secret managers
+	secretsImportTracker *sync.Map
+
+	// secretsImportAllowedEndpoints are the custom endpoints which imports
+	// may access secret managers through
+	secretsImportAllowedEndpoints []string
+
 	// auth is loaded after unseal since it is a protected
 	// configuration
 	auth *MountTable
@@ -391,6 +410,14 @@ type Core struct {
 	// can be output in the audit logs
 	auditedHeaders *AuditedHeadersConfig
 
+	// kvRecursiveDeletes holds the progress of the recursive deletes of KV
+	// version 2 metadata started on this node
+	kvRecursiveDeletes kvRecursiveDeletes
+
+	// kvEmbargoLocks serialize the embargoed writes of KV version 2 secrets
+	// with their reads
+	kvEmbargoLocks []*locksutil.LockEntry
+
 	// systemBackend is the backend which is used to manage internal operations
 	systemBackend   *SystemBackend
 	loginMFABackend *LoginMFABackend
@@ -425,6 +452,12 @@ type Core struct {
 	// metricsCh is used to stop the metrics streaming
 	metricsCh chan struct{}
 
+	// pprofCapture periodically captures runtime profiles to storage
+	pprofCapture *PprofCapturer
+
+	// requestTimelines records per-request phase timings for debugging
+	requestTimelines *RequestTimelineCapture
+
 	// metricsMutex is used to prevent a race condition between
 	// metrics emission and sealing leading to a nil pointer
 	metricsMutex sync.Mutex
@@ -459,6 +492,12 @@ type Core struct {
 	// Cache stores the actual cache; we always have this but may bypass it if
 	// disabled
 	physicalCache physical.ToggleablePurgemonster
+	// disableCachePrewarm indicates whether the cache is not prewarmed with
+	// the hot keys of the previous active node when this node becomes active
+	disableCachePrewarm bool
+	// cacheHotKeys holds the hot keys published by the active node, as loaded
+	// by this node while it is a standby
+	cacheHotKeys *atomic.Value
 
 	// logRequestsLevel indicates at which level requests should be logged
 	logRequestsLevel *uberAtomic.Int32
@@ -543,6 +582,10 @@ type Core struct {
 	// pluginFilePermissions is the permissions of the plugin files and directory
 	pluginFilePermissions int
 
+	// pluginRestartPolicy configures the recycling of external plugin
+	// processes using too much memory
+	pluginRestartPolicy PluginRestartPolicy
+
 	// pluginCatalog is used to manage plugin configurations
 	pluginCatalog *PluginCatalog
 
@@ -593,6 +636,19 @@ type Core struct {
 	// active, or give up active as soon as it gets it
 	neverBecomeActive *uint32
 
+	// steppingDown is set while the active node drains in-flight requests
+	// before stepping down, to reject new requests
+	steppingDown *uint32
+
+	// stepDownGracePeriod is how long in-flight requests are given to
+	// complete before a requested step-down. If zero, new requests are not
+	// rejected and in-flight requests are not drained.
+	stepDownGracePeriod time.Duration
+
+	// customPreStepDownHooks are run after the builtin hooks before a
+	// requested step-down
+	customPreStepDownHooks []PreStepDownHook
+
 	// clusterListener starts up and manages connections on the cluster ports
 	clusterListener *atomic.Value
 
@@ -675,6 +731,23 @@ type Core struct {
 
 	rollbackPeriod time.Duration
 
+	// rollbackWorkers is the number of rollbacks run concurrently by the
+	// rollback manager.
+	rollbackWorkers int
+
+	// rollbackMaxBackoff caps the delay between the rollbacks of mounts whose
+	// rollbacks keep failing, and rollbackBackoffJitter randomizes it.
+	rollbackMaxBackoff    time.Duration
+	rollbackBackoffJitter bool
+
+	// watchdog captures debug bundles when the core looks unhealthy. It is nil
+	// unless a watchdog path is configured.
+	watchdog *watchdog
+
+	// clock is the time source of the rollback and expiration managers. It
+	// is replaced in tests so that time can be advanced without sleeping.
+	clock timeutil.Clock
+
 	experiments []string
 
 	pendingRemovalMountsAllowed bool
@@ -682,6 +755,11 @@ type Core struct {
 
 	events *eventbus.EventBus
 
+	// healthWarnings are the warnings reported by mounts, keyed by mount
+	// accessor, which are surfaced by sys/health.
+	healthWarningsLock sync.Mutex
+	healthWarnings     map[string]*mountHealthWarnings
+
 	// writeForwardedPaths are a set of storage paths which are GRPC forwarded
 	// to the active node of the primary cluster, when present. This PathManager
 	// contains absolute paths that we intend to forward (and template) when
@@ -758,6 +836,10 @@ type CoreConfig struct {
 	// Disables the LRU cache on the physical backend
 	DisableCache bool
 
+	// Disables prewarming the LRU cache with the hot keys of the previous
+	// active node when becoming active
+	DisableCachePrewarm bool
+
 	// Disables mlock syscall
 	DisableMlock bool
 
@@ -792,6 +874,12 @@ type CoreConfig struct {
 
 	PluginFilePermissions int
 
+	PluginMaxMemory uint64
+
+	PluginMaxRestarts int
+
+	PluginRestartBackoff time.Duration
+
 	DisableSealWrap bool
 
 	RawConfig *server.Config
@@ -811,6 +899,14 @@ type CoreConfig struct {
 	DisableIndexing           bool
 	DisableKeyEncodingChecks  bool
 
+	// StepDownGracePeriod is how long in-flight requests are given to
+	// complete before a requested step-down, while new requests are rejected
+	StepDownGracePeriod time.Duration
+
+	// PreStepDownHooks are run before a requested step-down, after the
+	// builtin hooks
+	PreStepDownHooks []PreStepDownHook
+
 	AllLoggers []log.Logger
 
 	// Telemetry objects
@@ -843,6 +939,34 @@ type CoreConfig struct {
 
 	RollbackPeriod time.Duration
 
+	// RollbackWorkers is the number of rollbacks run concurrently; it
+	// defaults to defaultRollbackWorkers.
+	RollbackWorkers int
+
+	// RollbackMaxBackoff caps the delay between the rollbacks of a mount
+	// whose rollbacks keep failing; it defaults to defaultRollbackMaxBackoff.
+	// RollbackBackoffJitter randomizes the delay, so that mounts failing
+	// together are not retried together.
+	RollbackMaxBackoff    time.Duration
+	RollbackBackoffJitter bool
+
+	// SecretsImportAllowedEndpoints are the endpoints, other than the ones of
+	// the secret managers, which secrets imports may be configured with, such
+	// as private endpoints or emulators. By default none is allowed.
+	SecretsImportAllowedEndpoints []string
+
+	// WatchdogPath is the directory the watchdog writes debug bundles to; the
+	// watchdog only runs when it is set. The other Watchdog settings are its
+	// thresholds, which default to the defaultWatchdog constants.
+	WatchdogPath            string
+	WatchdogGoroutineGrowth int
+	WatchdogStateLockHold   time.Duration
+	WatchdogBarrierLatency  time.Duration
+
+	// Clock is used by the rollback and expiration managers; it defaults to
+	// the system clock and is only meant to be set in tests.
+	Clock timeutil.Clock
+
 	Experiments []string
 
 	PendingRemovalMountsAllowed bool
@@ -941,6 +1065,11 @@ func CreateCore(conf *CoreConfig) (*Core, error) {
 		stateLock = &locking.SyncRWMutex{}
 	}
 
+	// The watchdog needs to know how long the state lock is held
+	if conf.WatchdogPath != "" {
+		stateLock = &locking.TimedRWMutex{RWMutex: stateLock}
+	}
+
 	effectiveSDKVersion := conf.EffectiveSDKVersion
 	if effectiveSDKVersion == "" {
 		effectiveSDKVersion = version.GetVersion().Version
@@ -973,6 +1102,8 @@ func CreateCore(conf *CoreConfig) (*Core, error) {
 		maxLeaseTTL:                    conf.MaxLeaseTTL,
 		sentinelTraceDisabled:          conf.DisableSentinelTrace,
 		cachingDisabled:                conf.DisableCache,
+		disableCachePrewarm:            conf.DisableCachePrewarm,
+		cacheHotKeys:                   new(atomic.Value),
 		clusterName:                    conf.ClusterName,
 		clusterNetworkLayer:            conf.ClusterNetworkLayer,
 		clusterPeerClusterAddrsCache:   cache.New(3*clusterHeartbeatInterval, time.Second),
@@ -992,6 +1123,9 @@ func CreateCore(conf *CoreConfig) (*Core, error) {
 		allLoggers:                     conf.AllLoggers,
 		builtinRegistry:                conf.BuiltinRegistry,
 		neverBecomeActive:              new(uint32),
+		steppingDown:                   new(uint32),
+		stepDownGracePeriod:            conf.StepDownGracePeriod,
+		customPreStepDownHooks:         conf.PreStepDownHooks,
 		clusterLeaderParams:            new(atomic.Value),
 		metricsHelper:                  conf.MetricsHelper,
 		metricSink:                     conf.MetricSink,
@@ -1010,6 +1144,9 @@ func CreateCore(conf *CoreConfig) (*Core, error) {
 		enableResponseHeaderHostname:   conf.EnableResponseHeaderHostname,
 		enableResponseHeaderRaftNodeID: conf.EnableResponseHeaderRaftNodeID,
 		mountMigrationTracker:          &sync.Map{},
+		secretsImportTracker:           &sync.Map{},
+		secretsImportAllowedEndpoints:  conf.SecretsImportAllowedEndpoints,
+		kvEmbargoLocks:                 locksutil.CreateLocks(),
 		disableSSCTokens:               conf.DisableSSCTokens,
 		effectiveSDKVersion:            effectiveSDKVersion,
 		userFailedLoginInfo:            make(map[FailedLoginUser]*FailedLoginInfo),
@@ -1084,6 +1221,8 @@ func CreateCore(conf *CoreConfig) (*Core, error) {
 		Enabled: new(uint32),
 	}
 
+	c.requestTimelines = NewRequestTimelineCapture()
+
 	// Load write-forwarded path manager.
 	c.writeForwardedPaths = pathmanager.New()
 
@@ -1148,6 +1287,22 @@ func NewCore(conf *CoreConfig) (*Core, error) {
 		c.rollbackPeriod = time.Minute
 	}
 
+	c.rollbackWorkers = conf.RollbackWorkers
+	if conf.RollbackWorkers <= 0 {
+		c.rollbackWorkers = defaultRollbackWorkers
+	}
+
+	c.rollbackMaxBackoff = conf.RollbackMaxBackoff
+	if conf.RollbackMaxBackoff <= 0 {
+		c.rollbackMaxBackoff = defaultRollbackMaxBackoff
+	}
+	c.rollbackBackoffJitter = conf.RollbackBackoffJitter
+
+	c.clock = conf.Clock
+	if c.clock == nil {
+		c.clock = timeutil.DefaultClock{}
+	}
+
 	// All the things happening below this are not required in
 	// recovery mode
 	if c.recoveryMode {
@@ -1167,6 +1322,11 @@ func NewCore(conf *CoreConfig) (*Core, error) {
 	if conf.PluginFilePermissions != 0 {
 		c.pluginFilePermissions = conf.PluginFilePermissions
 	}
+	c.pluginRestartPolicy = PluginRestartPolicy{
+		MaxMemory:   conf.PluginMaxMemory,
+		MaxRestarts: conf.PluginMaxRestarts,
+		Backoff:     conf.PluginRestartBackoff,
+	}
 
 	createSecondaries(c, conf)
 
@@ -1279,6 +1439,13 @@ func NewCore(conf *CoreConfig) (*Core, error) {
 		c.events.Start()
 	}
 
+	if conf.WatchdogPath != "" {
+		watchdogLogger := conf.Logger.Named("watchdog")
+		c.allLoggers = append(c.allLoggers, watchdogLogger)
+		c.watchdog = newWatchdog(c, conf, watchdogLogger)
+		c.watchdog.start()
+	}
+
 	return c, nil
 }
 
@@ -1340,8 +1507,30 @@ func (c *Core) ShutdownCoreError(err error) {
 // happens as quickly as possible.
 func (c *Core) Shutdown() error {
 	c.logger.Debug("shutdown called")
+	c.logger.Info("shutdown phase starting", "phase", "seal")
 	err := c.sealInternal()
 
+	if c.watchdog != nil {
+		c.watchdog.stop()
+	}
+
+	// The event bus outlives seals, so it is only stopped on shutdown, once
+	// nothing is left to send events
+	c.logger.Info("shutdown phase starting", "phase", "events")
+	c.runTeardownPhases([]teardownPhase{
+		{
+			name:    "events",
+			timeout: teardownPhaseTimeout,
+			run: func() error {
+				if c.events != nil {
+					c.events.Stop()
+				}
+				return nil
+			},
+		},
+	})
+	c.logger.Info("shutdown complete")
+
 	c.stateLock.Lock()
 	defer c.stateLock.Unlock()
 
@@ -2364,6 +2553,10 @@ func (s standardUnsealStrategy) unseal(ctx context.Context, logger log.Logger, c
 		if err := c.setupActivityLog(ctx, &wg); err != nil {
 			return err
 		}
+
+		if err := c.setupPprofCapture(ctx); err != nil {
+			return err
+		}
 	} else {
 		c.auditBroker = NewAuditBroker(c.logger)
 	}
@@ -2431,6 +2624,10 @@ func (c *Core) postUnseal(ctx context.Context, ctxCancelFunc context.CancelFunc,
 	if !c.cachingDisabled {
 		c.physicalCache.SetEnabled(true)
 	}
+	if c.cachePrewarmEnabled() {
+		go c.prewarmCache(ctx)
+		go c.runCacheHotKeysPublisher(ctx)
+	}
 
 	// Purge these for safety in case of a rekey
 	_ = c.seal.SetBarrierConfig(ctx, nil)
@@ -2536,67 +2733,22 @@ func (c *Core) preSeal() error {
 	c.barrierRekeyConfig = nil
 	c.recoveryRekeyConfig = nil
 
+	// Mounts report their warnings again once unsealed
+	c.clearHealthWarnings()
+
 	if c.metricsCh != nil {
 		close(c.metricsCh)
 		c.metricsCh = nil
 	}
-	var result error
-
-	c.stopForwarding()
 
-	c.stopRaftActiveNode()
-
-	c.clusterParamsLock.Lock()
-	if err := stopReplication(c); err != nil {
-		result = multierror.Append(result, fmt.Errorf("error stopping replication: %w", err))
-	}
-	c.clusterParamsLock.Unlock()
-
-	if err := c.teardownAudits(); err != nil {
-		result = multierror.Append(result, fmt.Errorf("error tearing down audits: %w", err))
-	}
-	if err := c.stopExpiration(); err != nil {
-		result = multierror.Append(result, fmt.Errorf("error stopping expiration: %w", err))
-	}
-	c.stopActivityLog()
-	// Clean up the censusAgent on seal
-	if err := c.teardownCensusAgent(); err != nil {
-		result = multierror.Append(result, fmt.Errorf("error tearing down reporting agent: %w", err))
-	}
-
-	if err := c.teardownCredentials(context.Background()); err != nil {
-		result = multierror.Append(result, fmt.Errorf("error tearing down credentials: %w", err))
-	}
-	if err := c.teardownPolicyStore(); err != nil {
-		result = multierror.Append(result, fmt.Errorf("error tearing down policy store: %w", err))
-	}
-	if err := c.stopRollback(); err != nil {
-		result = multierror.Append(result, fmt.Errorf("error stopping rollback: %w", err))
-	}
-	if err := c.unloadMounts(context.Background()); err != nil {
-		result = multierror.Append(result, fmt.Errorf("error unloading mounts: %w", err))
-	}
-
-	if err := enterprisePreSeal(c); err != nil {
-		result = multierror.Append(result, err)
-	}
-
-	if c.autoRotateCancel != nil {
-		c.autoRotateCancel()
-		c.autoRotateCancel = nil
-	}
+	// Subsystems are stopped in their dependency order, each with a deadline,
+	// so that a stuck backend can't hang the seal
+	result := c.runTeardownPhases(c.preSealTeardownPhases())
 
 	if seal, ok := c.seal.(*autoSeal); ok {
 		seal.StopHealthCheck()
 	}
 
-	if c.systemBackend != nil && c.systemBackend.mfaBackend != nil {
-		c.systemBackend.mfaBackend.usedCodes = nil
-	}
-	if err := c.teardownLoginMFA(); err != nil {
-		result = multierror.Append(result, fmt.Errorf("error tearing down login MFA, error: %w", err))
-	}
-
 	preSealPhysical(c)
 
 	c.logger.Info("pre-seal teardown complete")
@@ -3261,6 +3413,16 @@ func (c *Core) MatchingMount(ctx context.Context, reqPath string) string {
 	return c.router.MatchingMount(ctx, reqPath)
 }
 
+// MountMaxRequestSize returns the maximum request size tuned on the mount
+// that will handle the given request path, or zero if it has none.
+func (c *Core) MountMaxRequestSize(ctx context.Context, reqPath string) int64 {
+	entry := c.router.MatchingMountEntry(ctx, reqPath)
+	if entry == nil {
+		return 0
+	}
+	return entry.Config.MaxRequestSize
+}
+
 func (c *Core) setupQuotas(ctx context.Context, isPerfStandby bool) error {
 	if c.quotaManager == nil {
 		return nil
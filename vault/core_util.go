@@ -132,8 +132,13 @@ func (c *Core) collectNamespaces() []*namespace.Namespace {
 	}
 }
 
+// HasWALState returns whether the node has applied the writes of the given
+// index state. Index states are only tracked with raft storage.
 func (c *Core) HasWALState(required *logical.WALState, perfStandby bool) bool {
-	return true
+	if required == nil || required.ClusterID != c.ClusterID() {
+		return true
+	}
+	return c.raftAppliedIndex() >= required.LocalIndex
 }
 
 func (c *Core) setupReplicatedClusterPrimary(*replication.Cluster) error { return nil }
@@ -190,7 +195,15 @@ func (c *Core) ForwardToActive() string {
 	return ""
 }
 
+// MissingRequiredState returns whether the node hasn't yet applied the writes
+// of any of the given X-Vault-Index header values. Values which can't be
+// verified are ignored.
 func (c *Core) MissingRequiredState(raw []string, perfStandby bool) bool {
+	for _, state := range c.parseIndexStates(raw) {
+		if !c.HasWALState(state, perfStandby) {
+			return true
+		}
+	}
 	return false
 }
 
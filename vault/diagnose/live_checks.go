@@ -0,0 +1,160 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package diagnose
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+)
+
+const (
+	auditDeviceCheckPrefix = "Check Audit Device "
+	pluginCheckPrefix      = "Check Plugin "
+	raftHealthTestName     = "Check Raft Cluster Health"
+
+	// LiveLatencyWarningThreshold is the latency above which a live check
+	// warns, even though it succeeded.
+	LiveLatencyWarningThreshold = time.Second
+)
+
+// AuditDeviceChecks logs a test message through each audit device enabled on
+// the running Vault server, reporting the latency of each.
+func AuditDeviceChecks(ctx context.Context, client *api.Client) error {
+	audits, err := client.Sys().ListAuditWithContext(ctx)
+	if err != nil {
+		return fmt.Errorf("Could not list audit devices: %w.", err)
+	}
+	if len(audits) == 0 {
+		Warn(ctx, "No audit devices are enabled.")
+		return nil
+	}
+
+	paths := make([]string, 0, len(audits))
+	for path := range audits {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var failed int
+	for _, path := range paths {
+		checkName := auditDeviceCheckPrefix + path
+		start := time.Now()
+		if err := client.Sys().TestAuditWithContext(ctx, path); err != nil {
+			SpotError(ctx, checkName, fmt.Errorf("Audit device %q failed to log a test message: %w.", path, err))
+			failed++
+			continue
+		}
+		latencyCheck(ctx, checkName, fmt.Sprintf("Logged a test message through %q", path), time.Since(start))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d audit devices failed to log a test message.", failed, len(paths))
+	}
+	return nil
+}
+
+// PluginChecks requests the help of each secrets engine and auth method of the
+// running Vault server that is backed by an external plugin, which requires a
+// round trip to the plugin process, reporting the latency of each.
+func PluginChecks(ctx context.Context, client *api.Client) error {
+	paths := make(map[string]bool)
+
+	mounts, err := client.Sys().ListMountsWithContext(ctx)
+	if err != nil {
+		return fmt.Errorf("Could not list secrets engines: %w.", err)
+	}
+	for path, mount := range mounts {
+		if mount.RunningSha256 != "" {
+			paths[path] = true
+		}
+	}
+
+	auths, err := client.Sys().ListAuthWithContext(ctx)
+	if err != nil {
+		return fmt.Errorf("Could not list auth methods: %w.", err)
+	}
+	for path, auth := range auths {
+		if auth.RunningSha256 != "" {
+			paths["auth/"+path] = true
+		}
+	}
+
+	if len(paths) == 0 {
+		Skipped(ctx, "No external plugins are mounted.")
+		return nil
+	}
+
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+
+	var failed int
+	for _, path := range sorted {
+		checkName := pluginCheckPrefix + path
+		start := time.Now()
+		if _, err := client.HelpWithContext(ctx, strings.TrimSuffix(path, "/")); err != nil {
+			SpotError(ctx, checkName, fmt.Errorf("Plugin mounted at %q did not respond: %w.", path, err))
+			failed++
+			continue
+		}
+		latencyCheck(ctx, checkName, fmt.Sprintf("Plugin mounted at %q responded", path), time.Since(start))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d external plugins did not respond.", failed, len(sorted))
+	}
+	return nil
+}
+
+// RaftClusterHealth checks the health of the raft cluster of the running
+// Vault server as seen by autopilot.
+func RaftClusterHealth(ctx context.Context, client *api.Client) error {
+	state, err := client.Sys().RaftAutopilotStateWithContext(ctx)
+	if err != nil {
+		return SpotError(ctx, raftHealthTestName, fmt.Errorf("Could not read the autopilot state: %w.", err))
+	}
+	if state == nil {
+		SpotSkipped(ctx, raftHealthTestName, "Vault is not using integrated storage.")
+		return nil
+	}
+
+	var unhealthy []string
+	for id, server := range state.Servers {
+		if !server.Healthy {
+			unhealthy = append(unhealthy, id)
+		}
+	}
+	sort.Strings(unhealthy)
+
+	summary := fmt.Sprintf("%d voters, %d non-voters, failure tolerance %d", len(state.Voters), len(state.NonVoters), state.FailureTolerance)
+	switch {
+	case !state.Healthy:
+		return SpotError(ctx, raftHealthTestName, fmt.Errorf("Raft cluster is unhealthy (%s); unhealthy servers: %s.", summary, strings.Join(unhealthy, ", ")),
+			Advice("Check the connectivity and logs of the unhealthy servers."))
+	case len(state.Voters) > 1 && state.FailureTolerance == 0:
+		SpotWarn(ctx, raftHealthTestName, fmt.Sprintf("Raft cluster cannot tolerate the failure of any voter (%s).", summary))
+	default:
+		SpotOk(ctx, raftHealthTestName, fmt.Sprintf("Raft cluster is healthy (%s).", summary))
+	}
+	return nil
+}
+
+// SealLatencyCheck reports the latency of a seal operation, warning if it
+// exceeds LiveLatencyWarningThreshold.
+func SealLatencyCheck(ctx context.Context, operation string, latency time.Duration) {
+	latencyCheck(ctx, "Check Seal "+CapitalizeFirstLetter(operation)+" Latency", "Seal "+operation+" completed", latency)
+}
+
+func latencyCheck(ctx context.Context, checkName, message string, latency time.Duration) {
+	if latency > LiveLatencyWarningThreshold {
+		SpotWarn(ctx, checkName, fmt.Sprintf("%s in %s, which exceeds %s.", message, latency, LiveLatencyWarningThreshold))
+		return
+	}
+	SpotOk(ctx, checkName, fmt.Sprintf("%s in %s.", message, latency))
+}
@@ -0,0 +1,110 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package diagnose
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+)
+
+func testLiveClient(t *testing.T, handler http.HandlerFunc) *api.Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	config := api.DefaultConfig()
+	config.Address = srv.URL
+	client, err := api.NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetToken("root")
+	return client
+}
+
+func runLiveCheck(t *testing.T, f func(ctx context.Context) error) *Result {
+	t.Helper()
+	sess := New(io.Discard)
+	ctx := Context(context.Background(), sess)
+	func() {
+		ctx, span := StartSpan(ctx, "live")
+		defer span.End()
+		Test(ctx, "check", f)
+	}()
+	return sess.Finalize(ctx).Children[0]
+}
+
+func TestAuditDeviceChecks(t *testing.T) {
+	client := testLiveClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/sys/audit":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"data": {"file/": {"type": "file", "path": "file/"}, "socket/": {"type": "socket", "path": "socket/"}}}`))
+		case "/v1/sys/audit-test/file/":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errors": ["audit device unavailable"]}`))
+		}
+	})
+
+	result := runLiveCheck(t, func(ctx context.Context) error {
+		return AuditDeviceChecks(ctx, client)
+	})
+	if result.Status != ErrorStatus {
+		t.Fatalf("expected the check to fail, got %s", result.Status)
+	}
+
+	statuses := make(map[string]status)
+	for _, child := range result.Children {
+		statuses[child.Name] = child.Status
+	}
+	if statuses[auditDeviceCheckPrefix+"file/"] != OkStatus {
+		t.Fatalf("expected the file device to pass, got %v", statuses)
+	}
+	if statuses[auditDeviceCheckPrefix+"socket/"] != ErrorStatus {
+		t.Fatalf("expected the socket device to fail, got %v", statuses)
+	}
+}
+
+func TestRaftClusterHealth(t *testing.T) {
+	cases := map[string]struct {
+		response string
+		expected status
+	}{
+		"healthy": {
+			response: `{"healthy": true, "failure_tolerance": 1, "voters": ["a", "b", "c"], "servers": {"a": {"healthy": true}, "b": {"healthy": true}, "c": {"healthy": true}}}`,
+			expected: OkStatus,
+		},
+		"no tolerance": {
+			response: `{"healthy": true, "failure_tolerance": 0, "voters": ["a", "b"], "servers": {"a": {"healthy": true}, "b": {"healthy": true}}}`,
+			expected: WarningStatus,
+		},
+		"unhealthy": {
+			response: `{"healthy": false, "failure_tolerance": 0, "voters": ["a", "b", "c"], "servers": {"a": {"healthy": true}, "b": {"healthy": false}, "c": {"healthy": false}}}`,
+			expected: ErrorStatus,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			client := testLiveClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"data": ` + tc.response + `}`))
+			})
+
+			result := runLiveCheck(t, func(ctx context.Context) error {
+				return RaftClusterHealth(ctx, client)
+			})
+			if result.Status != tc.expected {
+				t.Fatalf("expected status %s, got %s: %#v", tc.expected, result.Status, result.Children)
+			}
+		})
+	}
+}
@@ -10,7 +10,6 @@ import (
 
 	"github.com/hashicorp/vault/helper/identity"
 	"github.com/hashicorp/vault/helper/namespace"
-	"github.com/hashicorp/vault/helper/random"
 	"github.com/hashicorp/vault/sdk/helper/consts"
 	"github.com/hashicorp/vault/sdk/helper/license"
 	"github.com/hashicorp/vault/sdk/helper/pluginutil"
@@ -116,6 +115,16 @@ func (e extendedSystemViewImpl) SudoPrivilege(ctx context.Context, path string,
 		policies = append(policies, inlinePolicy)
 	}
 
+	// Add the policies of the mounts owned by the entity or its groups
+	if !te.NoIdentityPolicies {
+		ownerPolicies, err := e.core.mountOwnerACLPolicies(ctx, entity)
+		if err != nil {
+			e.core.logger.Error("failed to generate mount owner policies", "error", err)
+			return false
+		}
+		policies = append(policies, ownerPolicies...)
+	}
+
 	// Construct the corresponding ACL object. Derive and use a new context that
 	// uses the req.ClientToken's namespace
 	acl, err := e.core.policyStore.ACL(tokenCtx, entity, policyNames, policies...)
@@ -307,9 +316,12 @@ func (d dynamicSystemView) EntityInfo(entityID string) (*logical.Entity, error)
 
 	// Return a subset of the data
 	ret := &logical.Entity{
-		ID:       entity.ID,
-		Name:     entity.Name,
-		Disabled: entity.Disabled,
+		ID:             entity.ID,
+		Name:           entity.Name,
+		Disabled:       entity.Disabled,
+		CreationTime:   identity.CloneTimestamp(entity.CreationTime),
+		LastUpdateTime: identity.CloneTimestamp(entity.LastUpdateTime),
+		Policies:       append([]string(nil), entity.Policies...),
 	}
 
 	if entity.Metadata != nil {
@@ -375,6 +387,147 @@ func (d dynamicSystemView) GroupsForEntity(entityID string) ([]*logical.Group, e
 	return logicalGroups, nil
 }
 
+// AliasesByMount returns the aliases of the entity on the mount with the given
+// accessor. Mounts hold at most one alias of an entity, unless aliases were
+// merged without resolving their conflicts.
+func (d dynamicSystemView) AliasesByMount(ctx context.Context, entityID, mountAccessor string) ([]*logical.Alias, error) {
+	if entityID == "" || mountAccessor == "" {
+		return nil, nil
+	}
+
+	if d.core == nil {
+		return nil, fmt.Errorf("system view core is nil")
+	}
+	if d.core.identityStore == nil {
+		return nil, fmt.Errorf("system view identity store is nil")
+	}
+
+	entity, err := d.core.identityStore.MemDBEntityByID(entityID, false)
+	if err != nil {
+		return nil, err
+	}
+	if entity == nil {
+		return nil, nil
+	}
+
+	var mountType string
+	if mount := d.core.router.ValidateMountByAccessor(mountAccessor); mount != nil {
+		mountType = mount.MountType
+	}
+
+	var aliases []*logical.Alias
+	for _, a := range entity.Aliases {
+		// Don't return aliases from other namespaces
+		if a.MountAccessor != mountAccessor || a.NamespaceID != d.mountEntry.NamespaceID {
+			continue
+		}
+
+		alias := identity.ToSDKAlias(a)
+		alias.MountType = mountType
+		aliases = append(aliases, alias)
+	}
+
+	return aliases, nil
+}
+
+// MFAConstraintsByMount returns the login MFA constraints enforced on every
+// login to the auth mount with the given accessor, keyed by enforcement name.
+// Enforcements targeting entities or groups aren't included, so a login may
+// still require MFA when none is returned.
+func (d dynamicSystemView) MFAConstraintsByMount(ctx context.Context, mountAccessor string) (map[string]*logical.MFAConstraintAny, error) {
+	if mountAccessor == "" {
+		return nil, nil
+	}
+
+	if d.core == nil {
+		return nil, fmt.Errorf("system view core is nil")
+	}
+	if d.core.loginMFABackend == nil {
+		return nil, fmt.Errorf("system view login MFA backend is nil")
+	}
+
+	// Don't return the constraints of mounts in other namespaces
+	me := d.core.router.MatchingMountByAccessor(mountAccessor)
+	if me == nil || me.Table != credentialTableType || me.NamespaceID != d.mountEntry.NamespaceID {
+		return nil, nil
+	}
+
+	eConfigs, err := d.core.buildMFAEnforcementConfigListForMount(ctx, me)
+	if err != nil {
+		return nil, err
+	}
+
+	constraints := make(map[string]*logical.MFAConstraintAny, len(eConfigs))
+	for _, eConfig := range eConfigs {
+		mfaAny, err := d.core.buildMfaEnforcementResponse(eConfig)
+		if err != nil {
+			return nil, err
+		}
+		constraints[eConfig.Name] = mfaAny
+	}
+
+	return constraints, nil
+}
+
+// GenerateIdentityToken returns an identity token for the entity of the
+// request with the given ID, issued through the identity OIDC role configured
+// on the mount by an operator. The request must be in flight on the mount, so
+// that plugins can only issue tokens to the entities they are handling
+// requests of, and the entity must belong to the namespace of the mount.
+func (d dynamicSystemView) GenerateIdentityToken(ctx context.Context, requestID string) (string, error) {
+	if requestID == "" {
+		return "", fmt.Errorf("missing request ID")
+	}
+
+	if d.core == nil {
+		return "", fmt.Errorf("system view core is nil")
+	}
+	if d.core.identityStore == nil {
+		return "", fmt.Errorf("system view identity store is nil")
+	}
+
+	oidcRole := d.mountEntry.Config.IdentityTokenRole
+	if oidcRole == "" {
+		return "", fmt.Errorf("no identity_token_role is configured on the mount")
+	}
+
+	// Only issue tokens to the entity of a request the mount is handling
+	raw, ok := d.core.routedRequests.Load(requestID)
+	if !ok {
+		return "", fmt.Errorf("request %q is not being handled on behalf of an entity", requestID)
+	}
+	routed := raw.(*routedRequest)
+	me := d.core.router.MatchingMountEntry(namespace.ContextWithNamespace(ctx, routed.namespace), routed.path)
+	if me == nil || me.Accessor != d.mountEntry.Accessor {
+		return "", fmt.Errorf("request %q is not being handled by the mount", requestID)
+	}
+
+	ns := d.mountEntry.Namespace()
+	ctx = namespace.ContextWithNamespace(ctx, ns)
+
+	entity, err := d.core.identityStore.MemDBEntityByID(routed.entityID, false)
+	if err != nil {
+		return "", err
+	}
+	if entity == nil || entity.NamespaceID != ns.ID {
+		return "", fmt.Errorf("entity %q not found", routed.entityID)
+	}
+	if entity.Disabled {
+		return "", fmt.Errorf("entity %q is disabled", routed.entityID)
+	}
+
+	s := d.core.router.MatchingStorageByAPIPath(ctx, ns.Path+"identity/oidc")
+	if s == nil {
+		return "", fmt.Errorf("no identity store found for namespace %q", ns.Path)
+	}
+
+	token, _, _, err := d.core.identityStore.generateIdentityToken(ctx, s, oidcRole, entity.ID)
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
 func (d dynamicSystemView) PluginEnv(_ context.Context) (*logical.PluginEnvironment, error) {
 	v := version.GetVersion()
 	return &logical.PluginEnvironment{
@@ -411,7 +564,7 @@ func (d dynamicSystemView) GeneratePasswordFromPolicy(ctx context.Context, polic
 		return "", fmt.Errorf("no password policy found")
 	}
 
-	passPolicy, err := random.ParsePolicy(policyCfg.HCLPolicy)
+	passPolicy, err := d.parsePasswordPolicy(ctx, policyCfg.HCLPolicy)
 	if err != nil {
 		return "", fmt.Errorf("stored password policy is invalid: %w", err)
 	}
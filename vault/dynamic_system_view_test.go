@@ -12,6 +12,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-jose/go-jose/v3/jwt"
 	log "github.com/hashicorp/go-hclog"
 	ldapcred "github.com/hashicorp/vault/builtin/credential/ldap"
 	"github.com/hashicorp/vault/helper/namespace"
@@ -177,6 +178,286 @@ func TestIdentity_BackendTemplating(t *testing.T) {
 	}
 }
 
+// TestDynamicSystemView_EntityInfo ensures that entities are returned with
+// their timestamps and policies.
+func TestDynamicSystemView_EntityInfo(t *testing.T) {
+	c, _, _ := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "entity")
+	req.Data = map[string]interface{}{
+		"name":     "testentity",
+		"policies": []string{"dev", "ops"},
+	}
+	resp, err := c.identityStore.HandleRequest(ctx, req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v resp: %#v", err, resp)
+	}
+	entityID := resp.Data["id"].(string)
+
+	entity, err := TestDynamicSystemView(c, nil).EntityInfo(entityID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entity == nil || entity.Name != "testentity" {
+		t.Fatalf("bad entity: %#v", entity)
+	}
+	if !reflect.DeepEqual(entity.Policies, []string{"dev", "ops"}) {
+		t.Fatalf("bad policies: %#v", entity.Policies)
+	}
+	if entity.CreationTime == nil || entity.LastUpdateTime == nil {
+		t.Fatalf("expected timestamps, got %#v", entity)
+	}
+	if created := entity.CreationTime.AsTime(); time.Since(created) > time.Minute || entity.LastUpdateTime.AsTime().Before(created) {
+		t.Fatalf("bad timestamps: %v, %v", entity.CreationTime, entity.LastUpdateTime)
+	}
+}
+
+func TestDynamicSystemView_AliasesByMount(t *testing.T) {
+	ctx := namespace.RootContext(nil)
+	is, githubAccessor, c := testIdentityStoreWithGithubAuth(ctx, t)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "entity-alias")
+	req.Data = map[string]interface{}{
+		"name":           "testalias",
+		"mount_accessor": githubAccessor,
+	}
+	resp, err := is.HandleRequest(ctx, req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v resp: %#v", err, resp)
+	}
+	entityID := resp.Data["canonical_id"].(string)
+
+	sysView := TestDynamicSystemView(c, nil)
+	aliases, err := sysView.AliasesByMount(ctx, entityID, githubAccessor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(aliases) != 1 || aliases[0].Name != "testalias" || aliases[0].MountType != "github" {
+		t.Fatalf("bad aliases: %#v", aliases)
+	}
+
+	aliases, err = sysView.AliasesByMount(ctx, entityID, "auth_userpass_1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(aliases) != 0 {
+		t.Fatalf("expected no aliases, got %#v", aliases)
+	}
+}
+
+func TestDynamicSystemView_MFAConstraintsByMount(t *testing.T) {
+	ctx := namespace.RootContext(nil)
+	is, githubAccessor, c := testIdentityStoreWithGithubAuth(ctx, t)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "mfa/method/totp")
+	req.Data = map[string]interface{}{
+		"issuer": "vault",
+	}
+	resp, err := is.HandleRequest(ctx, req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v resp: %#v", err, resp)
+	}
+	methodID := resp.Data["method_id"].(string)
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "entity")
+	req.Data = map[string]interface{}{
+		"name": "testentity",
+	}
+	resp, err = is.HandleRequest(ctx, req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v resp: %#v", err, resp)
+	}
+	entityID := resp.Data["id"].(string)
+
+	// Enforcements targeting entities apply to some logins only, so they
+	// aren't returned
+	for name, data := range map[string]map[string]interface{}{
+		"by-accessor": {
+			"mfa_method_ids":        []string{methodID},
+			"auth_method_accessors": []string{githubAccessor},
+		},
+		"by-type": {
+			"mfa_method_ids":    []string{methodID},
+			"auth_method_types": []string{"github"},
+		},
+		"by-entity": {
+			"mfa_method_ids":      []string{methodID},
+			"identity_entity_ids": []string{entityID},
+		},
+	} {
+		req = logical.TestRequest(t, logical.UpdateOperation, "mfa/login-enforcement/"+name)
+		req.Data = data
+		resp, err = is.HandleRequest(ctx, req)
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("err: %v resp: %#v", err, resp)
+		}
+	}
+
+	sysView := TestDynamicSystemView(c, nil)
+	constraints, err := sysView.MFAConstraintsByMount(ctx, githubAccessor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(constraints) != 2 {
+		t.Fatalf("bad constraints: %#v", constraints)
+	}
+	for _, name := range []string{"by-accessor", "by-type"} {
+		if constraints[name] == nil || len(constraints[name].Any) != 1 {
+			t.Fatalf("bad constraint %q: %#v", name, constraints[name])
+		}
+		method := constraints[name].Any[0]
+		if method.ID != methodID || method.Type != "totp" || !method.UsesPasscode {
+			t.Fatalf("bad method: %#v", method)
+		}
+	}
+
+	constraints, err = sysView.MFAConstraintsByMount(ctx, "auth_userpass_1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(constraints) != 0 {
+		t.Fatalf("expected no constraints, got %#v", constraints)
+	}
+}
+
+func TestDynamicSystemView_GenerateIdentityToken(t *testing.T) {
+	c, _, root := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	write := func(path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := c.HandleRequest(ctx, &logical.Request{
+			Path:        path,
+			ClientToken: root,
+			Operation:   logical.UpdateOperation,
+			Data:        data,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("err: %v resp: %#v", err, resp)
+		}
+		return resp
+	}
+
+	write("identity/oidc/key/aws", map[string]interface{}{
+		"allowed_client_ids": "*",
+	})
+	write("identity/oidc/role/aws", map[string]interface{}{
+		"key": "aws",
+	})
+	entityID := write("identity/entity", map[string]interface{}{
+		"name": "testentity",
+	}).Data["id"].(string)
+
+	write("sys/mounts/secret", map[string]interface{}{
+		"type": "kv",
+	})
+	me := c.router.MatchingMountEntry(ctx, "secret/")
+	sysView := &dynamicSystemView{core: c, mountEntry: me}
+	c.routedRequests.Store("request", &routedRequest{
+		entityID:  entityID,
+		namespace: namespace.RootNamespace,
+		path:      "secret/foo",
+	})
+	defer c.routedRequests.Delete("request")
+
+	// Tokens are only issued through the role configured on the mount
+	if _, err := sysView.GenerateIdentityToken(ctx, "request"); err == nil {
+		t.Fatal("expected an error without a configured role")
+	}
+	write("sys/mounts/secret/tune", map[string]interface{}{
+		"identity_token_role": "aws",
+	})
+
+	token, err := sysView.GenerateIdentityToken(ctx, "request")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsedToken, err := jwt.ParseSigned(token)
+	if err != nil {
+		t.Fatalf("error parsing token: %s", err)
+	}
+	claims := &jwt.Claims{}
+	if err := parsedToken.UnsafeClaimsWithoutVerification(claims); err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != entityID {
+		t.Fatalf("expected the token of entity %q, got: %#v", entityID, claims)
+	}
+
+	// Tokens are only issued to the entities of requests in flight on the
+	// mount
+	if _, err := sysView.GenerateIdentityToken(ctx, "missing"); err == nil {
+		t.Fatal("expected an error for a request not in flight")
+	}
+	c.routedRequests.Store("other", &routedRequest{
+		entityID:  entityID,
+		namespace: namespace.RootNamespace,
+		path:      "cubbyhole/foo",
+	})
+	defer c.routedRequests.Delete("other")
+	if _, err := sysView.GenerateIdentityToken(ctx, "other"); err == nil {
+		t.Fatal("expected an error for a request to another mount")
+	}
+
+	write("identity/entity/id/"+entityID, map[string]interface{}{
+		"disabled": true,
+	})
+	if _, err := sysView.GenerateIdentityToken(ctx, "request"); err == nil {
+		t.Fatal("expected an error for a disabled entity")
+	}
+}
+
+func TestDynamicSystemView_GroupsForEntity(t *testing.T) {
+	c, _, _ := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	handle := func(path string, data map[string]interface{}) string {
+		t.Helper()
+		req := logical.TestRequest(t, logical.UpdateOperation, path)
+		req.Data = data
+		resp, err := c.identityStore.HandleRequest(ctx, req)
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("err: %v resp: %#v", err, resp)
+		}
+		return resp.Data["id"].(string)
+	}
+	entityID := handle("entity", map[string]interface{}{"name": "testentity"})
+	childID := handle("group", map[string]interface{}{
+		"name":              "child",
+		"member_entity_ids": []string{entityID},
+	})
+	parentID := handle("group", map[string]interface{}{
+		"name":             "parent",
+		"member_group_ids": []string{childID},
+	})
+
+	groups, err := TestDynamicSystemView(c, nil).GroupsForEntity(entityID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups, got %#v", groups)
+	}
+	for _, g := range groups {
+		if g.Type != "internal" {
+			t.Fatalf("bad type: %#v", g)
+		}
+		switch g.ID {
+		case childID:
+			if !reflect.DeepEqual(g.MemberEntityIDs, []string{entityID}) || !reflect.DeepEqual(g.ParentGroupIDs, []string{parentID}) {
+				t.Fatalf("bad child group: %#v", g)
+			}
+		case parentID:
+			if len(g.MemberEntityIDs) != 0 || len(g.ParentGroupIDs) != 0 {
+				t.Fatalf("bad parent group: %#v", g)
+			}
+		default:
+			t.Fatalf("unexpected group: %#v", g)
+		}
+	}
+}
+
 func TestDynamicSystemView_GeneratePasswordFromPolicy_successful(t *testing.T) {
 	var err error
 	coreConfig := &CoreConfig{
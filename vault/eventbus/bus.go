@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -19,6 +20,7 @@ import (
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-uuid"
 	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/internal/observability/event"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/ryanuber/go-glob"
 )
@@ -45,6 +47,17 @@ type EventBus struct {
 	started         atomic.Bool
 	formatterNodeID eventlogger.NodeID
 	timeout         time.Duration
+
+	// rateLimits are the options limiting the rate of the operational and
+	// informational events delivered to each subscriber.
+	rateLimits []event.Option
+
+	// subscribers holds the *subscription of each subscriber, by ID.
+	subscribers sync.Map
+
+	heartbeatInterval time.Duration
+	l                 sync.Mutex
+	stopHeartbeats    context.CancelFunc
 }
 
 type pluginEventBus struct {
@@ -64,6 +77,11 @@ type asyncChanNode struct {
 	cancelFunc context.CancelFunc
 	pipelineID eventlogger.PipelineID
 	broker     *eventlogger.Broker
+
+	// subscription tracks the delivery of events, and unsubscribe forgets
+	// it once the node is closed.
+	subscription *subscription
+	unsubscribe  func()
 }
 
 var (
@@ -71,13 +89,38 @@ var (
 	_ logical.EventSender = (*pluginEventBus)(nil)
 )
 
-// Start starts the event bus, allowing events to be written.
-// It is not possible to stop or restart the event bus.
+// Start starts the event bus, allowing events to be written, and the
+// sending of heartbeat events.
 // It is safe to call Start() multiple times.
 func (bus *EventBus) Start() {
 	wasStarted := bus.started.Swap(true)
 	if !wasStarted {
 		bus.logger.Info("Starting event system")
+
+		if bus.heartbeatInterval > 0 {
+			ctx, cancel := context.WithCancel(context.Background())
+			bus.l.Lock()
+			bus.stopHeartbeats = cancel
+			bus.l.Unlock()
+			go bus.sendHeartbeats(ctx, bus.heartbeatInterval)
+		}
+	}
+}
+
+// Stop stops the event bus, so that events are not accepted for sending
+// anymore. It is meant to be called once Vault is shutting down, and it is
+// safe to call Stop() multiple times.
+func (bus *EventBus) Stop() {
+	wasStarted := bus.started.Swap(false)
+	if wasStarted {
+		bus.logger.Info("Stopping event system")
+
+		bus.l.Lock()
+		if bus.stopHeartbeats != nil {
+			bus.stopHeartbeats()
+			bus.stopHeartbeats = nil
+		}
+		bus.l.Unlock()
 	}
 }
 
@@ -167,10 +210,11 @@ func NewEventBus(logger hclog.Logger) (*EventBus, error) {
 	}
 
 	return &EventBus{
-		logger:          logger,
-		broker:          broker,
-		formatterNodeID: formatterNodeID,
-		timeout:         defaultTimeout,
+		logger:            logger,
+		broker:            broker,
+		formatterNodeID:   formatterNodeID,
+		timeout:           defaultTimeout,
+		heartbeatInterval: defaultHeartbeatInterval,
 	}, nil
 }
 
@@ -199,7 +243,12 @@ func (bus *EventBus) Subscribe(ctx context.Context, ns *namespace.Namespace, pat
 
 	ctx, cancel := context.WithCancel(ctx)
 	asyncNode := newAsyncNode(ctx, bus.logger)
-	err = bus.broker.RegisterNode(eventlogger.NodeID(sinkNodeID), asyncNode)
+	sinkNode, err := event.NewPrioritySink(asyncNode, append([]event.Option{event.WithPriorityFunc(eventPriority)}, bus.rateLimits...)...)
+	if err != nil {
+		defer cancel()
+		return nil, nil, err
+	}
+	err = bus.broker.RegisterNode(eventlogger.NodeID(sinkNodeID), sinkNode)
 	if err != nil {
 		defer cancel()
 		return nil, nil, err
@@ -219,6 +268,10 @@ func (bus *EventBus) Subscribe(ctx context.Context, ns *namespace.Namespace, pat
 	}
 
 	addSubscriptions(1)
+	sub := newSubscription(pipelineID, ns, pattern)
+	bus.subscribers.Store(pipelineID, sub)
+	asyncNode.subscription = sub
+	asyncNode.unsubscribe = func() { bus.subscribers.Delete(pipelineID) }
 	// add info needed to cancel the subscription
 	asyncNode.pipelineID = eventlogger.PipelineID(pipelineID)
 	asyncNode.cancelFunc = cancel
@@ -232,6 +285,30 @@ func (bus *EventBus) SetSendTimeout(timeout time.Duration) {
 	bus.timeout = timeout
 }
 
+// SetRateLimits sets the number of operational and informational events per
+// second delivered to each new subscriber, the events exceeding the limits
+// being dropped. A limit of zero lets every event through.
+func (bus *EventBus) SetRateLimits(operational, informational float64) {
+	bus.rateLimits = []event.Option{
+		event.WithOperationalRateLimit(strconv.FormatFloat(operational, 'f', -1, 64)),
+		event.WithInformationalRateLimit(strconv.FormatFloat(informational, 'f', -1, 64)),
+	}
+}
+
+// eventPriority returns the priority class of the event: health warnings and
+// heartbeats are operational events, and the other events sent by plugins are
+// informational.
+func eventPriority(e *eventlogger.Event) event.Priority {
+	eventRecv, ok := e.Payload.(*logical.EventReceived)
+	if ok {
+		switch logical.EventType(eventRecv.EventType) {
+		case logical.EventTypeHealthWarnings, logical.EventTypeHeartbeat:
+			return event.PriorityOperational
+		}
+	}
+	return event.PriorityInformational
+}
+
 func newFilterNode(ns *namespace.Namespace, pattern string) *eventlogger.Filter {
 	return &eventlogger.Filter{
 		Predicate: func(e *eventlogger.Event) (bool, error) {
@@ -243,6 +320,12 @@ func newFilterNode(ns *namespace.Namespace, pattern string) *eventlogger.Filter
 				return false, nil
 			}
 
+			// Heartbeats are only sent to the subscribers of their topic.
+			if eventRecv.EventType == string(logical.EventTypeHeartbeat) {
+				topic := eventRecv.Event.GetMetadata().GetFields()[heartbeatTopicKey]
+				return topic.GetStringValue() == pattern, nil
+			}
+
 			// Filter for correct event type, including wildcards.
 			if !glob.Glob(pattern, eventRecv.EventType) {
 				return false, nil
@@ -277,21 +360,31 @@ func (node *asyncChanNode) Close(ctx context.Context) {
 				node.logger.Warn(msg, err)
 			}
 		}
+		if node.unsubscribe != nil {
+			node.unsubscribe()
+		}
 		addSubscriptions(-1)
 	})
 }
 
 func (node *asyncChanNode) Process(ctx context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
 	// sends to the channel async in another goroutine
+	if node.subscription != nil {
+		node.subscription.enqueued()
+	}
 	go func() {
-		var timeout bool
+		var timeout, acked bool
 		select {
 		case node.ch <- e:
+			acked = true
 		case <-ctx.Done():
 			timeout = errors.Is(ctx.Err(), context.DeadlineExceeded)
 		case <-node.ctx.Done():
 			timeout = errors.Is(node.ctx.Err(), context.DeadlineExceeded)
 		}
+		if node.subscription != nil {
+			node.subscription.dequeued(acked)
+		}
 		if timeout {
 			node.logger.Info("Subscriber took too long to process event, closing", "ID", e.Payload.(*logical.EventReceived).Event.Id)
 			node.Close(ctx)
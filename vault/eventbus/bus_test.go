@@ -68,6 +68,13 @@ func TestBusBasics(t *testing.T) {
 	case <-timeout:
 		t.Error("Timeout waiting for message")
 	}
+
+	bus.Stop()
+
+	err = bus.SendInternal(ctx, namespace.RootNamespace, nil, eventType, event)
+	if err != ErrNotStarted {
+		t.Errorf("Expected not started error after stopping but got: %v", err)
+	}
 }
 
 // TestNamespaceFiltering verifies that events for other namespaces are filtered out by the bus.
@@ -377,3 +384,116 @@ func TestBusWildcardSubscriptions(t *testing.T) {
 		t.Error("Timeout waiting for event2")
 	}
 }
+
+// TestBusRateLimits verifies that informational events exceeding the rate
+// limit are dropped, while operational events are delivered.
+func TestBusRateLimits(t *testing.T) {
+	bus, err := NewEventBus(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	bus.Start()
+	bus.SetRateLimits(0, 1)
+
+	ch, cancel, err := bus.Subscribe(ctx, namespace.RootNamespace, "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+
+	send := func(eventType logical.EventType) {
+		t.Helper()
+		event, err := logical.NewEvent()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := bus.SendInternal(ctx, namespace.RootNamespace, nil, eventType, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		send("someType")
+	}
+	send(logical.EventTypeHealthWarnings)
+
+	received := make(map[string]int)
+	timeout := time.After(1 * time.Second)
+	for done := false; !done; {
+		select {
+		case message := <-ch:
+			received[message.Payload.(*logical.EventReceived).EventType]++
+		case <-timeout:
+			done = true
+		}
+	}
+
+	if received["someType"] != 1 {
+		t.Errorf("expected 1 informational event, got %d", received["someType"])
+	}
+	if received[string(logical.EventTypeHealthWarnings)] != 1 {
+		t.Errorf("expected 1 operational event, got %d", received[string(logical.EventTypeHealthWarnings)])
+	}
+}
+
+// TestBusHeartbeats verifies that heartbeats are sent to the subscribers of
+// each topic, and that subscribers which don't receive them fall behind.
+func TestBusHeartbeats(t *testing.T) {
+	bus, err := NewEventBus(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	bus.SetHeartbeatInterval(50 * time.Millisecond)
+	bus.Start()
+	defer bus.Stop()
+
+	ch, cancel, err := bus.Subscribe(ctx, namespace.RootNamespace, "someType")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+
+	_, cancel2, err := bus.Subscribe(ctx, namespace.RootNamespace, "other*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	timeout := time.After(1 * time.Second)
+	select {
+	case message := <-ch:
+		eventRecv := message.Payload.(*logical.EventReceived)
+		if eventRecv.EventType != string(logical.EventTypeHeartbeat) {
+			t.Fatalf("expected a heartbeat, got: %+v", eventRecv)
+		}
+		if topic := eventRecv.Event.Metadata.AsMap()[heartbeatTopicKey]; topic != "someType" {
+			t.Fatalf("expected a heartbeat on someType, got: %v", topic)
+		}
+	case <-timeout:
+		t.Fatal("Timeout waiting for heartbeat")
+	}
+
+	// Give the other subscriber time to fall behind
+	time.Sleep(200 * time.Millisecond)
+
+	subscriptions := bus.Subscriptions(namespace.RootNamespace)
+	if len(subscriptions) != 2 {
+		t.Fatalf("expected 2 subscriptions, got %d", len(subscriptions))
+	}
+	if subscriptions[0].Pattern != "other*" || subscriptions[1].Pattern != "someType" {
+		t.Fatalf("unexpected subscriptions: %+v, %+v", subscriptions[0], subscriptions[1])
+	}
+	if !subscriptions[0].LastAckTime.IsZero() || subscriptions[0].PendingEvents < 2 || subscriptions[0].Lag == 0 {
+		t.Errorf("expected the other subscriber to fall behind: %+v", subscriptions[0])
+	}
+	if subscriptions[1].LastAckTime.IsZero() {
+		t.Errorf("expected the subscriber to have received a heartbeat: %+v", subscriptions[1])
+	}
+
+	cancel2()
+	subscriptions = bus.Subscriptions(namespace.RootNamespace)
+	if len(subscriptions) != 1 || subscriptions[0].Pattern != "someType" {
+		t.Fatalf("expected only the someType subscription to be left, got: %v", subscriptions)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package eventbus
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// defaultHeartbeatInterval is how often heartbeat events are sent on each
+	// subscribed topic by default.
+	defaultHeartbeatInterval = 30 * time.Second
+
+	// heartbeatTopicKey is the metadata of heartbeat events holding the
+	// topic they are sent on.
+	heartbeatTopicKey = "topic"
+)
+
+// SubscriptionInfo describes a subscriber of the event bus and how far behind
+// it is in receiving its events.
+type SubscriptionInfo struct {
+	ID             string
+	NamespacePath  string
+	Pattern        string
+	SubscribedTime time.Time
+
+	// LastAckTime is when the subscriber last received an event, and is zero
+	// if it hasn't received any yet.
+	LastAckTime time.Time
+
+	// PendingEvents is the number of events waiting to be received by the
+	// subscriber, and Lag how long it has had events waiting without
+	// receiving any.
+	PendingEvents int64
+	Lag           time.Duration
+}
+
+// subscription tracks the delivery of events to a subscriber.
+type subscription struct {
+	id         string
+	namespace  *namespace.Namespace
+	pattern    string
+	subscribed time.Time
+
+	l           sync.Mutex
+	pending     int64
+	behindSince time.Time
+	lastAck     time.Time
+}
+
+func newSubscription(id string, ns *namespace.Namespace, pattern string) *subscription {
+	return &subscription{
+		id:         id,
+		namespace:  ns,
+		pattern:    pattern,
+		subscribed: time.Now(),
+	}
+}
+
+// enqueued records that an event is waiting to be received by the subscriber.
+func (s *subscription) enqueued() {
+	s.l.Lock()
+	defer s.l.Unlock()
+	if s.pending == 0 {
+		s.behindSince = time.Now()
+	}
+	s.pending++
+}
+
+// dequeued records that an event was received by the subscriber if acked is
+// set, or that it won't be otherwise.
+func (s *subscription) dequeued(acked bool) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.pending--
+	now := time.Now()
+	if acked {
+		s.lastAck = now
+	}
+	switch {
+	case s.pending == 0:
+		s.behindSince = time.Time{}
+	case acked:
+		s.behindSince = now
+	}
+}
+
+func (s *subscription) info() *SubscriptionInfo {
+	s.l.Lock()
+	defer s.l.Unlock()
+	info := &SubscriptionInfo{
+		ID:             s.id,
+		NamespacePath:  s.namespace.Path,
+		Pattern:        s.pattern,
+		SubscribedTime: s.subscribed,
+		LastAckTime:    s.lastAck,
+		PendingEvents:  s.pending,
+	}
+	if !s.behindSince.IsZero() {
+		info.Lag = time.Since(s.behindSince)
+	}
+	return info
+}
+
+// Subscriptions returns the subscribers of the event bus in the given
+// namespace and its children, sorted by namespace, pattern and ID.
+func (bus *EventBus) Subscriptions(ns *namespace.Namespace) []*SubscriptionInfo {
+	var infos []*SubscriptionInfo
+	bus.subscribers.Range(func(_, value interface{}) bool {
+		s := value.(*subscription)
+		if strings.HasPrefix(s.namespace.Path, ns.Path) {
+			infos = append(infos, s.info())
+		}
+		return true
+	})
+	sort.Slice(infos, func(i, j int) bool {
+		switch {
+		case infos[i].NamespacePath != infos[j].NamespacePath:
+			return infos[i].NamespacePath < infos[j].NamespacePath
+		case infos[i].Pattern != infos[j].Pattern:
+			return infos[i].Pattern < infos[j].Pattern
+		default:
+			return infos[i].ID < infos[j].ID
+		}
+	})
+	return infos
+}
+
+// SetHeartbeatInterval sets how often heartbeat events are sent on each
+// subscribed topic, taking effect the next time the bus is started. An
+// interval of zero disables heartbeats.
+func (bus *EventBus) SetHeartbeatInterval(interval time.Duration) {
+	bus.heartbeatInterval = interval
+}
+
+// sendHeartbeats sends a heartbeat event on each subscribed topic every
+// interval, until the context is done. Heartbeats keep idle subscribers busy,
+// so that the ones which stopped receiving events fall behind and are
+// eventually closed, rather than only when a critical event is sent to them.
+func (bus *EventBus) sendHeartbeats(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		type topic struct {
+			namespaceID string
+			pattern     string
+		}
+		topics := make(map[topic]*namespace.Namespace)
+		bus.subscribers.Range(func(_, value interface{}) bool {
+			s := value.(*subscription)
+			topics[topic{s.namespace.ID, s.pattern}] = s.namespace
+			return true
+		})
+
+		for t, ns := range topics {
+			if err := bus.sendHeartbeat(ctx, ns, t.pattern); err != nil {
+				bus.logger.Warn("failed to send heartbeat event", "namespace", ns.Path, "topic", t.pattern, "error", err)
+			}
+		}
+	}
+}
+
+// sendHeartbeat sends a heartbeat event to the subscribers of the topic.
+func (bus *EventBus) sendHeartbeat(ctx context.Context, ns *namespace.Namespace, pattern string) error {
+	event, err := logical.NewEvent()
+	if err != nil {
+		return err
+	}
+	event.Metadata, err = structpb.NewStruct(map[string]interface{}{
+		heartbeatTopicKey: pattern,
+	})
+	if err != nil {
+		return err
+	}
+	return bus.SendInternal(ctx, ns, nil, logical.EventTypeHeartbeat, event)
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/hashicorp/vault/helper/locking"
 	"github.com/hashicorp/vault/helper/metricsutil"
 	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/helper/timeutil"
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/helper/consts"
 	"github.com/hashicorp/vault/sdk/helper/jsonutil"
@@ -59,6 +60,10 @@ const (
 	// defaultLeaseDuration is the default lease duration used when no lease is specified
 	defaultLeaseTTL = maxLeaseTTL
 
+	// maxLeaseTTLJitter is the highest percentage by which mounts can be
+	// tuned to randomly shorten the TTLs of their leases.
+	maxLeaseTTLJitter = 50
+
 	// maxLeaseThreshold is the maximum lease count before generating log warning
 	maxLeaseThreshold = 256000
 
@@ -85,10 +90,24 @@ const (
 	MaxIrrevocableLeasesWarning = "Command halted because many irrevocable leases were found. To emit the entire list, re-run the command with force set true."
 )
 
+// jitterLeaseTTL shortens the TTL of a lease of the mount by a random number
+// of seconds, up to the lease TTL jitter percentage the mount is tuned with,
+// so that leases issued or renewed at once don't all expire at once.
+func jitterLeaseTTL(entry *MountEntry, ttl time.Duration) time.Duration {
+	if entry == nil || entry.Config.LeaseTTLJitter <= 0 {
+		return ttl
+	}
+	maxJitter := int64(ttl/time.Second) * int64(entry.Config.LeaseTTLJitter) / 100
+	if maxJitter <= 0 {
+		return ttl
+	}
+	return ttl - time.Duration(rand.Int63n(maxJitter+1))*time.Second
+}
+
 type pendingInfo struct {
 	// A subset of the lease entry, cached in memory
 	cachedLeaseInfo  *leaseEntry
-	timer            *time.Timer
+	timer            timeutil.Timer
 	revokesAttempted uint8
 	loginRole        string
 }
@@ -132,6 +151,9 @@ type ExpirationManager struct {
 	uniquePolicies      map[string][]string
 	emptyUniquePolicies *time.Ticker
 
+	// clock is the time source for lease issue, renewal and expiry times.
+	clock timeutil.Clock
+
 	tidyLock *int32
 
 	restoreMode        *int32
@@ -156,6 +178,18 @@ type ExpirationManager struct {
 
 	jobManager      *fairshare.JobManager
 	revokeRetryBase time.Duration
+
+	// revokeTreeJobs holds the background revocations of token trees, which
+	// are stored in revokeTreeJobView. revokeTreeSem bounds the number of
+	// them running at once.
+	revokeTreeJobView *BarrierView
+	revokeTreeJobs    sync.Map
+	revokeTreeSem     chan struct{}
+	revokeTreeWG      sync.WaitGroup
+
+	// importKeyLock serializes the generation of the key leases are
+	// imported with.
+	importKeyLock sync.Mutex
 }
 
 type ExpireLeaseStrategy func(context.Context, *ExpirationManager, string, *namespace.Namespace)
@@ -345,7 +379,8 @@ func NewExpirationManager(c *Core, view *BarrierView, e ExpireLeaseStrategy, log
 		lockPerLease: sync.Map{},
 
 		uniquePolicies:      make(map[string][]string),
-		emptyUniquePolicies: time.NewTicker(7 * 24 * time.Hour),
+		emptyUniquePolicies: c.clock.NewTicker(7 * 24 * time.Hour),
+		clock:               c.clock,
 
 		// new instances of the expiration manager will go immediately into
 		// restore mode
@@ -362,6 +397,9 @@ func NewExpirationManager(c *Core, view *BarrierView, e ExpireLeaseStrategy, log
 
 		jobManager:      jobManager,
 		revokeRetryBase: c.expirationRevokeRetryBase,
+
+		revokeTreeJobView: view.SubView(revokeTreeJobViewPrefix),
+		revokeTreeSem:     make(chan struct{}, maxConcurrentRevokeTreeJobs),
 	}
 	if exp.revokeRetryBase == 0 {
 		exp.revokeRetryBase = revokeRetryBase
@@ -407,14 +445,14 @@ func (c *Core) setupExpiration(e ExpireLeaseStrategy) error {
 
 	quit := c.expiration.quitCh
 	go func() {
-		t := time.NewTimer(24 * time.Hour)
+		t := c.expiration.clock.NewTicker(24 * time.Hour)
+		defer t.Stop()
 		for {
 			select {
 			case <-quit:
 				return
 			case <-t.C:
 				c.expiration.attemptIrrevocableLeasesRevoke()
-				t.Reset(24 * time.Hour)
 			}
 		}
 	}()
@@ -803,6 +841,10 @@ LOOP:
 	m.restoreModeLock.Unlock()
 
 	m.logger.Info("lease restore complete")
+
+	if err := m.resumeRevokeTreeJobs(m.quitContext); err != nil {
+		m.logger.Error("failed to resume token revocations", "error", err)
+	}
 	return nil
 }
 
@@ -859,6 +901,9 @@ func (m *ExpirationManager) Stop() error {
 	// expiring timers
 	close(m.quitCh)
 
+	// Wait for the token revocation jobs to be interrupted
+	m.revokeTreeWG.Wait()
+
 	m.pendingLock.Lock()
 	// Replacing the entire map would cause a race with
 	// a simultaneous WalkTokens, which doesn't hold pendingLock.
@@ -927,7 +972,7 @@ func (m *ExpirationManager) lazyRevokeInternal(ctx context.Context, leaseID stri
 		return nil
 	}
 
-	le.ExpireTime = time.Now()
+	le.ExpireTime = m.clock.Now()
 	if err := m.persistEntry(ctx, le); err != nil {
 		return err
 	}
@@ -942,7 +987,7 @@ func (m *ExpirationManager) attemptIrrevocableLeasesRevoke() {
 		leaseID := k.(string)
 		le := v.(*leaseEntry)
 
-		if le.ExpireTime.Add(time.Hour).Before(time.Now()) {
+		if le.ExpireTime.Add(time.Hour).Before(m.clock.Now()) {
 			// if we get an error (or no namespace) note it, but continue attempting
 			// to revoke other leases
 			leaseNS, err := m.getNamespaceFromLeaseID(m.core.activeContext, leaseID)
@@ -1259,7 +1304,7 @@ func (m *ExpirationManager) Renew(ctx context.Context, leaseID string, increment
 	for _, warning := range warnings {
 		resp.AddWarning(warning)
 	}
-	resp.Secret.TTL = ttl
+	resp.Secret.TTL = jitterLeaseTTL(m.router.MatchingMountEntry(sysViewCtx, le.Path), ttl)
 
 	// Attach the LeaseID
 	resp.Secret.LeaseID = leaseID
@@ -1267,8 +1312,8 @@ func (m *ExpirationManager) Renew(ctx context.Context, leaseID string, increment
 	// Update the lease entry
 	le.Data = resp.Data
 	le.Secret = resp.Secret
-	le.ExpireTime = resp.Secret.ExpirationTime()
-	le.LastRenewalTime = time.Now()
+	le.ExpireTime = m.expirationTime(&resp.Secret.LeaseOptions)
+	le.LastRenewalTime = m.clock.Now()
 
 	// If the token it's associated with is a batch token, constrain lease
 	// times
@@ -1412,8 +1457,8 @@ func (m *ExpirationManager) RenewToken(ctx context.Context, req *logical.Request
 
 	// Update the lease entry
 	le.Auth = resp.Auth
-	le.ExpireTime = resp.Auth.ExpirationTime()
-	le.LastRenewalTime = time.Now()
+	le.ExpireTime = m.expirationTime(&resp.Auth.LeaseOptions)
+	le.LastRenewalTime = m.clock.Now()
 
 	if err := m.persistEntry(ctx, le); err != nil {
 		return nil, err
@@ -1470,8 +1515,8 @@ func (m *ExpirationManager) Register(ctx context.Context, req *logical.Request,
 		Data:            resp.Data,
 		Secret:          resp.Secret,
 		LoginRole:       loginRole,
-		IssueTime:       time.Now(),
-		ExpireTime:      resp.Secret.ExpirationTime(),
+		IssueTime:       m.clock.Now(),
+		ExpireTime:      m.expirationTime(&resp.Secret.LeaseOptions),
 		namespace:       ns,
 		Version:         1,
 	}
@@ -1554,7 +1599,7 @@ func (m *ExpirationManager) Register(ctx context.Context, req *logical.Request,
 	// ticking, so we'll end up always returning 299 instead of 300 or
 	// 26399 instead of 26400, say, even if it's just a few
 	// microseconds. This provides a nicer UX.
-	resp.Secret.TTL = le.ExpireTime.Sub(time.Now()).Round(time.Second)
+	resp.Secret.TTL = le.ExpireTime.Sub(m.clock.Now()).Round(time.Second)
 
 	// Done
 	return le.LeaseID, nil
@@ -1572,7 +1617,7 @@ func (m *ExpirationManager) RegisterAuth(ctx context.Context, te *logical.TokenE
 		return fmt.Errorf("failing explicitly on RegisterAuth")
 	}
 
-	authExpirationTime := auth.ExpirationTime()
+	authExpirationTime := m.expirationTime(&auth.LeaseOptions)
 
 	if te.TTL == 0 && authExpirationTime.IsZero() && (len(te.Policies) != 1 || te.Policies[0] != "root") {
 		return errors.New("refusing to register a lease for a non-root token with no TTL")
@@ -1616,7 +1661,7 @@ func (m *ExpirationManager) RegisterAuth(ctx context.Context, te *logical.TokenE
 		Auth:        auth,
 		Path:        te.Path,
 		LoginRole:   loginRole,
-		IssueTime:   time.Now(),
+		IssueTime:   m.clock.Now(),
 		ExpireTime:  authExpirationTime,
 		namespace:   tokenNS,
 		Version:     1,
@@ -1806,6 +1851,16 @@ func (m *ExpirationManager) deleteLockForLease(id string) {
 	m.lockPerLease.Delete(id)
 }
 
+// expirationTime returns the time at which a lease with the given options
+// expires, or the zero time if leases are not enabled. It mirrors
+// LeaseOptions.ExpirationTime, but uses the clock of the manager.
+func (m *ExpirationManager) expirationTime(l *logical.LeaseOptions) time.Time {
+	if !l.LeaseEnabled() {
+		return time.Time{}
+	}
+	return m.clock.Now().Add(l.LeaseTotal())
+}
+
 // updatePending is used to update a pending invocation for a lease
 func (m *ExpirationManager) updatePending(le *leaseEntry) {
 	m.pendingLock.Lock()
@@ -1850,7 +1905,7 @@ func (m *ExpirationManager) updatePendingInternal(le *leaseEntry) {
 		return
 	}
 
-	leaseTotal := le.ExpireTime.Sub(time.Now())
+	leaseTotal := le.ExpireTime.Sub(m.clock.Now())
 	leaseCreated := false
 
 	if le.isIrrevocable() {
@@ -1880,7 +1935,7 @@ func (m *ExpirationManager) updatePendingInternal(le *leaseEntry) {
 		} else {
 			leaseID, namespace := le.LeaseID, le.namespace
 			// Extend the timer by the lease total
-			timer := time.AfterFunc(leaseTotal, func() {
+			timer := m.clock.AfterFunc(leaseTotal, func() {
 				m.expireFunc(m.quitContext, m, leaseID, namespace)
 			})
 			pending = pendingInfo{
@@ -2277,7 +2332,7 @@ func (m *ExpirationManager) CreateOrFetchRevocationLeaseByToken(ctx context.Cont
 		}
 
 		// Create a lease entry
-		now := time.Now()
+		now := m.clock.Now()
 		le = &leaseEntry{
 			LeaseID:     leaseID,
 			ClientToken: auth.ClientToken,
@@ -2391,7 +2446,7 @@ func (m *ExpirationManager) leaseAggregationMetrics(ctx context.Context, consts
 	leaseEpsilon := consts.LeaseMetricsEpsilon
 	nsLabel := consts.LeaseMetricsNameSpaceLabels
 
-	rollingWindow := time.Now().Add(time.Duration(consts.NumLeaseMetricsTimeBuckets) * leaseEpsilon)
+	rollingWindow := m.clock.Now().Add(time.Duration(consts.NumLeaseMetricsTimeBuckets) * leaseEpsilon)
 
 	err := m.walkLeases(func(entryID string, expireTime time.Time) bool {
 		select {
@@ -0,0 +1,386 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/helper/jsonutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	// leaseImportKeyPath is the storage path of the private key the leases
+	// imported into the cluster are encrypted to.
+	leaseImportKeyPath = "core/lease-import-key"
+
+	// leaseImportKeyBits is the size of the import key.
+	leaseImportKeyBits = 4096
+
+	// leaseExportVersion is the version of the format of lease exports.
+	leaseExportVersion = 1
+)
+
+// leaseExport is an encrypted export of leases. The leases are encrypted with
+// an AES-256-GCM key, which is itself encrypted to the import key of the
+// cluster importing them with RSA-OAEP, so that their revocation data is only
+// readable by that cluster.
+type leaseExport struct {
+	Version    int    `json:"version"`
+	WrappedKey []byte `json:"wrapped_key"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// exportedLease is a lease exported from a cluster, along with the type of its
+// mount, which must be the same on the cluster importing it.
+type exportedLease struct {
+	Lease     json.RawMessage `json:"lease"`
+	MountType string          `json:"mount_type"`
+}
+
+// leaseImportKey returns the key the leases imported into the cluster are
+// encrypted to, generating it the first time.
+func (m *ExpirationManager) leaseImportKey(ctx context.Context) (*rsa.PrivateKey, error) {
+	m.importKeyLock.Lock()
+	defer m.importKeyLock.Unlock()
+
+	entry, err := m.core.barrier.Get(ctx, leaseImportKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lease import key: %w", err)
+	}
+	if entry != nil {
+		key, err := x509.ParsePKCS1PrivateKey(entry.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse lease import key: %w", err)
+		}
+		return key, nil
+	}
+
+	key, err := rsa.GenerateKey(m.core.secureRandomReader, leaseImportKeyBits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate lease import key: %w", err)
+	}
+	if err := m.core.barrier.Put(ctx, &logical.StorageEntry{
+		Key:      leaseImportKeyPath,
+		Value:    x509.MarshalPKCS1PrivateKey(key),
+		SealWrap: true,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to store lease import key: %w", err)
+	}
+	return key, nil
+}
+
+// exportLeases returns the leases of secrets of the namespace whose ID starts
+// with the prefix, encrypted to the import key of another cluster, along with
+// the IDs of the leases exported. Irrevocable and expired leases, which the
+// other cluster couldn't revoke, are left out. The leases are not removed: see
+// releaseLeases.
+func (m *ExpirationManager) exportLeases(ctx context.Context, prefix string, importKey *rsa.PublicKey) (string, []string, error) {
+	if m.inRestoreMode() {
+		return "", nil, ErrInRestoreMode
+	}
+
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	leaseIDs, err := logical.CollectKeysWithPrefix(ctx, m.leaseView(ns), prefix)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to scan for leases: %w", err)
+	}
+
+	leases := make([]exportedLease, 0, len(leaseIDs))
+	exported := make([]string, 0, len(leaseIDs))
+	for _, leaseID := range leaseIDs {
+		le, err := m.loadEntry(ctx, leaseID)
+		if err != nil {
+			return "", nil, err
+		}
+		if le == nil || le.Secret == nil || le.Auth != nil || le.isIrrevocable() {
+			continue
+		}
+		if !le.ExpireTime.IsZero() && le.ExpireTime.Before(m.clock.Now()) {
+			continue
+		}
+
+		mountEntry := m.router.MatchingMountEntry(ctx, le.Path)
+		if mountEntry == nil {
+			continue
+		}
+
+		buf, err := le.encode()
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to encode lease entry %s: %w", leaseID, err)
+		}
+		leases = append(leases, exportedLease{
+			Lease:     buf,
+			MountType: mountEntry.Type,
+		})
+		exported = append(exported, le.LeaseID)
+	}
+
+	plaintext, err := json.Marshal(leases)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encode leases: %w", err)
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", nil, err
+	}
+	gcm, err := leaseExportCipher(key)
+	if err != nil {
+		return "", nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, err
+	}
+	wrappedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, importKey, key, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encrypt to the import key: %w", err)
+	}
+
+	export, err := json.Marshal(&leaseExport{
+		Version:    leaseExportVersion,
+		WrappedKey: wrappedKey,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plaintext, nil),
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encode lease export: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(export), exported, nil
+}
+
+// releaseLeases removes leases exported to another cluster without revoking
+// them, and stops their expiration timers, so that this cluster no longer
+// revokes the secrets the other cluster now manages. It returns the IDs of the
+// leases released, and the errors of the others by lease ID, which this cluster
+// still revokes.
+func (m *ExpirationManager) releaseLeases(ctx context.Context, leaseIDs []string) ([]string, map[string]string) {
+	released := []string{}
+	failed := make(map[string]string)
+	for _, leaseID := range leaseIDs {
+		if err := m.releaseLease(ctx, leaseID); err != nil {
+			m.logger.Error("failed to release exported lease", "lease_id", leaseID, "error", err)
+			failed[leaseID] = err.Error()
+			continue
+		}
+		released = append(released, leaseID)
+	}
+	return released, failed
+}
+
+// releaseLease removes a lease like revokeCommon does, without revoking it with
+// its backend.
+func (m *ExpirationManager) releaseLease(ctx context.Context, leaseID string) error {
+	leaseLock := m.lockForLeaseID(leaseID)
+	leaseLock.Lock()
+	defer leaseLock.Unlock()
+
+	le, err := m.loadEntry(ctx, leaseID)
+	if err != nil {
+		return err
+	}
+	if le == nil {
+		return nil
+	}
+
+	if err := m.deleteEntry(ctx, le); err != nil {
+		return err
+	}
+	m.deleteLockForLease(leaseID)
+
+	indexToken := le.ClientToken
+	if le.ClientTokenType == logical.TokenTypeBatch {
+		te, err := m.tokenStore.lookupBatchTokenInternal(ctx, le.ClientToken)
+		if err != nil {
+			return err
+		}
+		indexToken = te.Parent
+	}
+	if indexToken != "" {
+		if err := m.removeIndexByToken(ctx, le, indexToken); err != nil {
+			return err
+		}
+	}
+
+	m.pendingLock.Lock()
+	m.removeFromPending(ctx, leaseID, true)
+	m.nonexpiring.Delete(leaseID)
+	m.pendingLock.Unlock()
+	return nil
+}
+
+// importLeases decrypts leases exported from another cluster, and registers
+// them so that they are renewed and revoked by this cluster. Their IDs are
+// kept, so that their holders can keep using them. Leases whose token doesn't
+// exist on this cluster are attached to the owner token instead, unless it is
+// nil. It returns the IDs of the leases imported, and the reasons the others
+// were skipped by lease ID.
+func (m *ExpirationManager) importLeases(ctx context.Context, bundle string, owner *logical.TokenEntry) ([]string, map[string]string, error) {
+	if m.inRestoreMode() {
+		return nil, nil, ErrInRestoreMode
+	}
+
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	leases, err := m.decryptLeaseExport(ctx, bundle)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	imported := []string{}
+	skipped := make(map[string]string)
+	for i, exported := range leases {
+		le, err := decodeLeaseEntry(exported.Lease)
+		if err != nil || le.LeaseID == "" {
+			skipped[fmt.Sprintf("#%d", i)] = "invalid lease entry"
+			continue
+		}
+		if err := m.importLease(ctx, ns, le, exported.MountType, owner); err != nil {
+			skipped[le.LeaseID] = err.Error()
+			continue
+		}
+		imported = append(imported, le.LeaseID)
+	}
+	return imported, skipped, nil
+}
+
+// decryptLeaseExport decrypts leases exported to the import key of the
+// cluster.
+func (m *ExpirationManager) decryptLeaseExport(ctx context.Context, bundle string) ([]exportedLease, error) {
+	raw, err := base64.StdEncoding.DecodeString(bundle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode lease export: %w", err)
+	}
+	var export leaseExport
+	if err := jsonutil.DecodeJSON(raw, &export); err != nil {
+		return nil, fmt.Errorf("failed to decode lease export: %w", err)
+	}
+	if export.Version != leaseExportVersion {
+		return nil, fmt.Errorf("unsupported lease export version %d", export.Version)
+	}
+
+	importKey, err := m.leaseImportKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, importKey, export.WrappedKey, nil)
+	if err != nil {
+		return nil, errors.New("failed to decrypt lease export: it was not exported to the import key of this cluster")
+	}
+	gcm, err := leaseExportCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(export.Nonce) != gcm.NonceSize() {
+		return nil, errors.New("failed to decrypt lease export: invalid nonce")
+	}
+	plaintext, err := gcm.Open(nil, export.Nonce, export.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt lease export: %w", err)
+	}
+
+	var leases []exportedLease
+	if err := json.Unmarshal(plaintext, &leases); err != nil {
+		return nil, fmt.Errorf("failed to decode exported leases: %w", err)
+	}
+	return leases, nil
+}
+
+// importLease registers a lease exported from another cluster, which must
+// belong to the namespace and be issued by a mount of the same type at the
+// same path.
+func (m *ExpirationManager) importLease(ctx context.Context, ns *namespace.Namespace, le *leaseEntry, mountType string, owner *logical.TokenEntry) error {
+	_, nsID := namespace.SplitIDFromString(le.LeaseID)
+	if nsID == "" {
+		nsID = namespace.RootNamespaceID
+	}
+	switch {
+	case nsID != ns.ID:
+		return errors.New("lease belongs to another namespace")
+	case le.Secret == nil || le.Auth != nil:
+		return errors.New("not the lease of a secret")
+	case !le.ExpireTime.IsZero() && le.ExpireTime.Before(m.clock.Now()):
+		return errors.New("lease expired")
+	}
+
+	mountEntry := m.router.MatchingMountEntry(ctx, le.Path)
+	if mountEntry == nil {
+		return fmt.Errorf("no mount at path %q", le.Path)
+	}
+	if mountEntry.Type != mountType {
+		return fmt.Errorf("mount at path %q is of type %q rather than %q", le.Path, mountEntry.Type, mountType)
+	}
+
+	existing, err := m.loadEntry(ctx, le.LeaseID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return errors.New("lease already exists")
+	}
+
+	// Leases keep their token if it was brought over too; batch tokens can't
+	// be, as they are encrypted by the cluster which issued them
+	var te *logical.TokenEntry
+	if le.ClientToken != "" && !IsBatchToken(le.ClientToken) {
+		te, err = m.tokenStore.Lookup(ctx, le.ClientToken)
+		if err != nil {
+			return fmt.Errorf("failed to look up the token of the lease: %w", err)
+		}
+	}
+	if te == nil {
+		if owner == nil {
+			return errors.New("token of the lease not found")
+		}
+		te = owner
+	}
+	le.ClientToken = te.ID
+	le.ClientTokenType = te.Type
+	le.RevokeErr = ""
+	le.namespace = ns
+
+	leaseLock := m.lockForLeaseID(le.LeaseID)
+	leaseLock.Lock()
+	defer leaseLock.Unlock()
+
+	if err := m.persistEntry(ctx, le); err != nil {
+		return err
+	}
+	if err := m.createIndexByToken(ctx, le, le.ClientToken); err != nil {
+		if deleteErr := m.deleteEntry(ctx, le); deleteErr != nil {
+			m.logger.Error("failed to delete lease entry after failing to import it", "lease_id", le.LeaseID, "error", deleteErr)
+		}
+		return err
+	}
+	m.updatePending(le)
+	return nil
+}
+
+// leaseExportCipher returns the cipher the leases are exported with.
+func leaseExportCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
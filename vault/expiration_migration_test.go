@@ -0,0 +1,132 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExpiration_ExportImportLeases verifies that leases exported from a
+// cluster can be imported into another, which then revokes them with their
+// original revocation data.
+func TestExpiration_ExportImportLeases(t *testing.T) {
+	source := mockExpiration(t)
+	target := mockExpiration(t)
+	ctx := namespace.RootContext(nil)
+
+	mount := func(exp *ExpirationManager, path, mountType string) *NoopBackend {
+		t.Helper()
+		noop := &NoopBackend{}
+		_, barrier, _ := mockBarrier(t)
+		view := NewBarrierView(barrier, "logical/")
+		meUUID, err := uuid.GenerateUUID()
+		require.NoError(t, err)
+		err = exp.router.Mount(noop, path, &MountEntry{Path: path, Type: mountType, UUID: meUUID, Accessor: mountType + "-" + meUUID, namespace: namespace.RootNamespace}, view)
+		require.NoError(t, err)
+		return noop
+	}
+	sourceNoop := mount(source, "prod/aws/", "noop")
+	mount(source, "prod/gcp/", "noop")
+	targetNoop := mount(target, "prod/aws/", "noop")
+	mount(target, "prod/gcp/", "other")
+
+	register := func(path string) string {
+		t.Helper()
+		req := &logical.Request{
+			Operation:   logical.ReadOperation,
+			Path:        path,
+			ClientToken: "foobar",
+		}
+		req.SetTokenEntry(&logical.TokenEntry{ID: "foobar", NamespaceID: "root"})
+		resp := &logical.Response{
+			Secret: &logical.Secret{
+				LeaseOptions: logical.LeaseOptions{
+					TTL: time.Hour,
+				},
+			},
+			Data: map[string]interface{}{
+				"access_key": "xyz",
+			},
+		}
+		id, err := source.Register(ctx, req, resp, "")
+		require.NoError(t, err)
+		return id
+	}
+	awsID := register("prod/aws/foo")
+	gcpID := register("prod/gcp/foo")
+	barID := register("prod/aws/bar")
+
+	key, err := target.leaseImportKey(ctx)
+	require.NoError(t, err)
+	bundle, exported, err := source.exportLeases(ctx, "prod/aws/foo", &key.PublicKey)
+	require.NoError(t, err)
+	require.Equal(t, []string{awsID}, exported)
+
+	// Released leases are no longer revoked by the source cluster
+	released, failed := source.releaseLeases(ctx, exported)
+	require.Equal(t, []string{awsID}, released)
+	require.Empty(t, failed)
+	le, err := source.loadEntry(ctx, awsID)
+	require.NoError(t, err)
+	require.Nil(t, le)
+	_, ok := source.pending.Load(awsID)
+	require.False(t, ok)
+	_, ok = source.pending.Load(barID)
+	require.True(t, ok)
+	byToken, err := source.lookupLeasesByToken(ctx, &logical.TokenEntry{ID: "foobar", NamespaceID: "root"})
+	require.NoError(t, err)
+	require.NotContains(t, byToken, awsID)
+	require.Contains(t, byToken, barID)
+	require.NoError(t, source.Revoke(ctx, awsID))
+	require.Empty(t, sourceNoop.Requests)
+
+	// Leases whose token doesn't exist are skipped without an owner
+	imported, skipped, err := target.importLeases(ctx, bundle, nil)
+	require.NoError(t, err)
+	require.Empty(t, imported)
+	require.Contains(t, skipped, awsID)
+
+	// Exports are only readable by the cluster they were exported to
+	otherKey, err := source.leaseImportKey(ctx)
+	require.NoError(t, err)
+	other, _, err := source.exportLeases(ctx, "prod/aws/", &otherKey.PublicKey)
+	require.NoError(t, err)
+	_, _, err = target.importLeases(ctx, other, nil)
+	require.Error(t, err)
+
+	owner := &logical.TokenEntry{Policies: []string{"default"}, Type: logical.TokenTypeService}
+	testMakeTokenDirectly(t, target.tokenStore, owner)
+	imported, skipped, err = target.importLeases(ctx, bundle, owner)
+	require.NoError(t, err)
+	require.Equal(t, []string{awsID}, imported)
+	require.Empty(t, skipped)
+
+	le, err = target.loadEntry(ctx, awsID)
+	require.NoError(t, err)
+	require.Equal(t, owner.ID, le.ClientToken)
+
+	_, skipped, err = target.importLeases(ctx, bundle, owner)
+	require.NoError(t, err)
+	require.Equal(t, "lease already exists", skipped[awsID])
+
+	// Leases are only imported into mounts of the same type
+	bundle, _, err = source.exportLeases(ctx, "prod/gcp/", &key.PublicKey)
+	require.NoError(t, err)
+	imported, skipped, err = target.importLeases(ctx, bundle, owner)
+	require.NoError(t, err)
+	require.Empty(t, imported)
+	require.Contains(t, skipped, gcpID)
+
+	require.NoError(t, target.Revoke(ctx, awsID))
+	require.Len(t, targetNoop.Requests, 1)
+	req := targetNoop.Requests[0]
+	require.Equal(t, logical.RevokeOperation, req.Operation)
+	require.Equal(t, "xyz", req.Data["access_key"])
+}
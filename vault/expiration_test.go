@@ -24,11 +24,13 @@ import (
 	"github.com/hashicorp/vault/helper/fairshare"
 	"github.com/hashicorp/vault/helper/metricsutil"
 	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/helper/timeutil"
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/helper/logging"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/hashicorp/vault/sdk/physical"
 	"github.com/hashicorp/vault/sdk/physical/inmem"
+	"github.com/stretchr/testify/require"
 )
 
 var testImagePull sync.Once
@@ -1350,6 +1352,75 @@ func TestExpiration_RevokeOnExpire(t *testing.T) {
 	}
 }
 
+// TestExpiration_RevokeOnExpire_ManualClock verifies that leases are revoked
+// when the clock of the core passes their expiry, and that renewing a lease
+// moves its expiry.
+func TestExpiration_RevokeOnExpire_ManualClock(t *testing.T) {
+	clock := timeutil.NewManualClock(time.Now())
+	c, _, _ := TestCoreUnsealedWithConfig(t, &CoreConfig{Clock: clock})
+	exp := c.expiration
+	require.Eventually(t, func() bool {
+		return !exp.inRestoreMode()
+	}, 10*time.Second, 50*time.Millisecond)
+
+	noop := &NoopBackend{}
+	_, barrier, _ := mockBarrier(t)
+	view := NewBarrierView(barrier, "logical/")
+	meUUID, err := uuid.GenerateUUID()
+	require.NoError(t, err)
+	err = exp.router.Mount(noop, "prod/aws/", &MountEntry{Path: "prod/aws/", Type: "noop", UUID: meUUID, Accessor: "noop-accessor", namespace: namespace.RootNamespace}, view)
+	require.NoError(t, err)
+
+	req := &logical.Request{
+		Operation:   logical.ReadOperation,
+		Path:        "prod/aws/foo",
+		ClientToken: "foobar",
+	}
+	req.SetTokenEntry(&logical.TokenEntry{ID: "foobar", NamespaceID: "root"})
+	resp := &logical.Response{
+		Secret: &logical.Secret{
+			LeaseOptions: logical.LeaseOptions{
+				TTL:       time.Hour,
+				Renewable: true,
+			},
+		},
+		Data: map[string]interface{}{
+			"access_key": "xyz",
+			"secret_key": "abcd",
+		},
+	}
+	id, err := exp.Register(namespace.RootContext(nil), req, resp, "")
+	require.NoError(t, err)
+
+	revoked := func() bool {
+		noop.Lock()
+		defer noop.Unlock()
+		for _, req := range noop.Requests {
+			if req.Operation == logical.RevokeOperation {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Renewing the lease resets its timer
+	clock.Advance(30 * time.Minute)
+	noop.Response = &logical.Response{
+		Secret: &logical.Secret{
+			LeaseOptions: logical.LeaseOptions{
+				TTL: time.Hour,
+			},
+		},
+	}
+	_, err = exp.Renew(namespace.RootContext(nil), id, 0)
+	require.NoError(t, err)
+	clock.Advance(45 * time.Minute)
+	require.Never(t, revoked, 100*time.Millisecond, 10*time.Millisecond)
+
+	clock.Advance(15 * time.Minute)
+	require.Eventually(t, revoked, 5*time.Second, 10*time.Millisecond)
+}
+
 func TestExpiration_RevokePrefix(t *testing.T) {
 	exp := mockExpiration(t)
 	noop := &NoopBackend{}
@@ -1905,6 +1976,72 @@ func TestExpiration_Renew(t *testing.T) {
 	}
 }
 
+func TestExpiration_Renew_LeaseTTLJitter(t *testing.T) {
+	exp := mockExpiration(t)
+	noop := &NoopBackend{}
+	_, barrier, _ := mockBarrier(t)
+	view := NewBarrierView(barrier, "logical/")
+	meUUID, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	me := &MountEntry{
+		Path:      "prod/aws/",
+		Type:      "noop",
+		UUID:      meUUID,
+		Accessor:  "noop-accessor",
+		Config:    MountConfig{LeaseTTLJitter: 50},
+		namespace: namespace.RootNamespace,
+	}
+	if err := exp.router.Mount(noop, "prod/aws/", me, view); err != nil {
+		t.Fatal(err)
+	}
+
+	req := &logical.Request{
+		Operation:   logical.ReadOperation,
+		Path:        "prod/aws/foo",
+		ClientToken: "foobar",
+	}
+	req.SetTokenEntry(&logical.TokenEntry{ID: "foobar", NamespaceID: "root"})
+	id, err := exp.Register(namespace.RootContext(nil), req, &logical.Response{
+		Secret: &logical.Secret{
+			LeaseOptions: logical.LeaseOptions{
+				TTL:       time.Hour,
+				Renewable: true,
+			},
+		},
+	}, "")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	ttls := make(map[time.Duration]bool)
+	for i := 0; i < 20; i++ {
+		noop.Lock()
+		noop.Response = &logical.Response{
+			Secret: &logical.Secret{
+				LeaseOptions: logical.LeaseOptions{
+					TTL: 100 * time.Second,
+				},
+			},
+		}
+		noop.Unlock()
+
+		out, err := exp.Renew(namespace.RootContext(nil), id, 0)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		ttl := out.Secret.TTL
+		if ttl < 50*time.Second || ttl > 100*time.Second || ttl%time.Second != 0 {
+			t.Fatalf("bad TTL: %s", ttl)
+		}
+		ttls[ttl] = true
+	}
+	if len(ttls) < 2 {
+		t.Fatalf("expected jittered TTLs, got %v", ttls)
+	}
+}
+
 func TestExpiration_Renew_NotRenewable(t *testing.T) {
 	exp := mockExpiration(t)
 	noop := &NoopBackend{}
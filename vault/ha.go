@@ -439,6 +439,18 @@ func (c *Core) runStandby(doneCh, manualStepDownCh, stopCh chan struct{}) {
 			c.logger.Debug("shutting down periodic leader refresh")
 		})
 	}
+	if c.cachePrewarmEnabled() {
+		// Keep the hot keys of the active node loaded
+		hotKeysStop := make(chan struct{})
+
+		g.Add(func() error {
+			c.periodicCacheHotKeysRefresh(hotKeysStop)
+			return nil
+		}, func(error) {
+			close(hotKeysStop)
+			c.logger.Debug("shutting down periodic cache hot keys refresh")
+		})
+	}
 	{
 		metricsStop := make(chan struct{})
 
@@ -661,6 +673,7 @@ func (c *Core) waitForLeadership(newLeaderCh chan func(), manualStepDownCh, stop
 		case <-manualStepDownCh:
 			manualStepDown = true
 			c.logger.Warn("stepping down from active operation to standby")
+			c.runPreStepDownHooks(activeCtx)
 		}
 
 		// Stop Active Duty
@@ -690,6 +703,7 @@ func (c *Core) waitForLeadership(newLeaderCh chan func(), manualStepDownCh, stop
 
 			// Mark as standby
 			c.standby = true
+			atomic.StoreUint32(c.steppingDown, 0)
 			c.leaderUUID = ""
 			c.metricSink.SetGaugeWithLabels([]string{"core", "active"}, 0, nil)
 
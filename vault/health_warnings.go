@@ -0,0 +1,123 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// mountHealthWarnings are the warnings last reported by a mount.
+type mountHealthWarnings struct {
+	path     string
+	warnings []string
+}
+
+// healthWarningSender is the event sender of the backends of mounts. Events
+// reporting the health warnings of the mount are recorded, so that they are
+// surfaced by sys/health; every event is forwarded to the event bus, if the
+// mount has one.
+type healthWarningSender struct {
+	core       *Core
+	accessor   string
+	path       string
+	eventsSend logical.EventSender
+}
+
+var _ logical.EventSender = (*healthWarningSender)(nil)
+
+// newHealthWarningSender returns the event sender of the backend of the
+// mount entry. The events sender is nil when events are not enabled.
+func (c *Core) newHealthWarningSender(entry *MountEntry, events logical.EventSender) *healthWarningSender {
+	path := entry.Path
+	if entry.namespace != nil {
+		path = entry.namespace.Path + path
+	}
+	if entry.Table == credentialTableType {
+		path = credentialRoutePrefix + path
+	}
+
+	return &healthWarningSender{
+		core:       c,
+		accessor:   entry.Accessor,
+		path:       path,
+		eventsSend: events,
+	}
+}
+
+func (s *healthWarningSender) Send(ctx context.Context, eventType logical.EventType, event *logical.EventData) error {
+	if eventType == logical.EventTypeHealthWarnings {
+		var warnings []string
+		if event != nil && event.Metadata != nil {
+			if value, ok := event.Metadata.AsMap()["warnings"]; ok {
+				list, ok := value.([]interface{})
+				if !ok {
+					return fmt.Errorf("%q event metadata must hold a list of warnings", eventType)
+				}
+				for _, warning := range list {
+					warnings = append(warnings, fmt.Sprint(warning))
+				}
+			}
+		}
+		s.core.setHealthWarnings(s.accessor, s.path, warnings)
+	}
+
+	if s.eventsSend == nil {
+		if eventType == logical.EventTypeHealthWarnings {
+			return nil
+		}
+		return framework.ErrNoEvents
+	}
+	return s.eventsSend.Send(ctx, eventType, event)
+}
+
+// setHealthWarnings replaces the health warnings of the mount.
+func (c *Core) setHealthWarnings(accessor, path string, warnings []string) {
+	c.healthWarningsLock.Lock()
+	defer c.healthWarningsLock.Unlock()
+
+	if len(warnings) == 0 {
+		delete(c.healthWarnings, accessor)
+		return
+	}
+	if c.healthWarnings == nil {
+		c.healthWarnings = make(map[string]*mountHealthWarnings)
+	}
+	c.healthWarnings[accessor] = &mountHealthWarnings{
+		path:     path,
+		warnings: warnings,
+	}
+}
+
+// clearHealthWarnings drops the health warnings of every mount.
+func (c *Core) clearHealthWarnings() {
+	c.healthWarningsLock.Lock()
+	defer c.healthWarningsLock.Unlock()
+	c.healthWarnings = nil
+}
+
+// HealthWarnings returns the sorted health warnings reported by mounts,
+// prefixed by the path of their mount. Warnings of mounts which no longer
+// exist are dropped.
+func (c *Core) HealthWarnings() []string {
+	c.healthWarningsLock.Lock()
+	defer c.healthWarningsLock.Unlock()
+
+	var result []string
+	for accessor, mount := range c.healthWarnings {
+		if c.router.MatchingMountByAccessor(accessor) == nil {
+			delete(c.healthWarnings, accessor)
+			continue
+		}
+		for _, warning := range mount.warnings {
+			result = append(result, fmt.Sprintf("%s: %s", mount.path, warning))
+		}
+	}
+	sort.Strings(result)
+	return result
+}
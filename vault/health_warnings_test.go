@@ -0,0 +1,79 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestCore_HealthWarnings(t *testing.T) {
+	c, _, _ := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	entry := c.router.MatchingMountEntry(ctx, "secret/")
+	if entry == nil {
+		t.Fatal("missing secret/ mount")
+	}
+	sender := c.newHealthWarningSender(entry, nil)
+
+	send := func(warnings ...interface{}) {
+		t.Helper()
+		event, err := logical.NewEvent()
+		if err != nil {
+			t.Fatal(err)
+		}
+		event.Metadata, err = structpb.NewStruct(map[string]interface{}{"warnings": warnings})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sender.Send(context.Background(), logical.EventTypeHealthWarnings, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	send("second warning", "first warning")
+	expected := []string{"secret/: first warning", "secret/: second warning"}
+	if warnings := c.HealthWarnings(); !reflect.DeepEqual(warnings, expected) {
+		t.Fatalf("expected warnings %v, got %v", expected, warnings)
+	}
+
+	// Warnings replace the previously reported ones.
+	send("first warning")
+	expected = []string{"secret/: first warning"}
+	if warnings := c.HealthWarnings(); !reflect.DeepEqual(warnings, expected) {
+		t.Fatalf("expected warnings %v, got %v", expected, warnings)
+	}
+
+	// Without events enabled, other events cannot be sent.
+	event, err := logical.NewEvent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sender.Send(context.Background(), "kv/data-write", event); !errors.Is(err, framework.ErrNoEvents) {
+		t.Fatalf("expected ErrNoEvents, got %v", err)
+	}
+
+	// Warnings of mounts which no longer exist are dropped.
+	if err := c.unmount(ctx, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if warnings := c.HealthWarnings(); len(warnings) != 0 {
+		t.Fatalf("expected no warnings, got %v", warnings)
+	}
+
+	// An empty list clears the warnings.
+	c.setHealthWarnings("accessor", "pki/", []string{"warning"})
+	c.setHealthWarnings("accessor", "pki/", nil)
+	if len(c.healthWarnings) != 0 {
+		t.Fatalf("expected no warnings, got %v", c.healthWarnings)
+	}
+}
@@ -53,18 +53,20 @@ func (i *IdentityStore) resetDB(ctx context.Context) error {
 
 func NewIdentityStore(ctx context.Context, core *Core, config *logical.BackendConfig, logger log.Logger) (*IdentityStore, error) {
 	iStore := &IdentityStore{
-		view:          config.StorageView,
-		logger:        logger,
-		router:        core.router,
-		redirectAddr:  core.redirectAddr,
-		localNode:     core,
-		namespacer:    core,
-		metrics:       core.MetricSink(),
-		totpPersister: core,
-		groupUpdater:  core,
-		tokenStorer:   core,
-		entityCreator: core,
-		mfaBackend:    core.loginMFABackend,
+		view:            config.StorageView,
+		logger:          logger,
+		router:          core.router,
+		redirectAddr:    core.redirectAddr,
+		localNode:       core,
+		namespacer:      core,
+		metrics:         core.MetricSink(),
+		totpPersister:   core,
+		groupUpdater:    core,
+		tokenStorer:     core,
+		entitySessioner: core,
+		entityCreator:   core,
+		mfaBackend:      core.loginMFABackend,
+		events:          core.events,
 	}
 
 	// Create a memdb instance, which by default, operates on lower cased
@@ -110,9 +112,17 @@ func NewIdentityStore(ctx context.Context, core *Core, config *logical.BackendCo
 			LocalStorage: []string{
 				localAliasesBucketsPrefix,
 			},
+			Root: []string{
+				"entity/import",
+				"entity/export",
+				"entity-alias/import",
+				"group/import",
+			},
 		},
 		PeriodicFunc: func(ctx context.Context, req *logical.Request) error {
 			iStore.oidcPeriodicFunc(ctx)
+			iStore.groupSyncPeriodicFunc(ctx)
+			iStore.entityDedupePeriodicFunc(ctx)
 
 			return nil
 		},
@@ -136,6 +146,7 @@ func (i *IdentityStore) paths() []*framework.Path {
 		aliasPaths(i),
 		groupAliasPaths(i),
 		groupPaths(i),
+		importPaths(i),
 		lookupPaths(i),
 		upgradePaths(i),
 		oidcPaths(i),
@@ -147,6 +158,9 @@ func (i *IdentityStore) paths() []*framework.Path {
 		mfaDuoPaths(i),
 		mfaPingIDPaths(i),
 		mfaLoginEnforcementPaths(i),
+		groupSyncPaths(i),
+		dedupePaths(i),
+		entitySessionPaths(i),
 	)
 }
 
@@ -1148,6 +1162,7 @@ func (i *IdentityStore) CreateOrFetchEntity(ctx context.Context, alias *logical.
 	var err error
 	var update bool
 	var entityCreated bool
+	var updatedAlias *identity.Alias
 
 	if alias == nil {
 		return nil, false, fmt.Errorf("alias is nil")
@@ -1197,6 +1212,7 @@ func (i *IdentityStore) CreateOrFetchEntity(ctx context.Context, alias *logical.
 		a.LastUpdateTime = ptypes.TimestampNow()
 
 		update = true
+		updatedAlias = a
 	}
 
 	if !update {
@@ -1255,6 +1271,14 @@ func (i *IdentityStore) CreateOrFetchEntity(ctx context.Context, alias *logical.
 	}
 
 	txn.Commit()
+
+	if entityCreated {
+		i.sendEntityEvent(ctx, eventTypeEntityCreated, entity, nil)
+		i.sendEntityAliasEvent(ctx, eventTypeEntityAliasCreated, entity.Aliases[0])
+	} else {
+		i.sendEntityAliasEvent(ctx, eventTypeEntityAliasUpdated, updatedAlias)
+	}
+
 	clonedEntity, err := entity.Clone()
 	return clonedEntity, entityCreated, err
 }
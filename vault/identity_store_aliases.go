@@ -289,10 +289,12 @@ func (i *IdentityStore) handleAliasCreate(ctx context.Context, canonicalID, name
 	}
 
 	persist := false
+	entityCreated := false
 	// If the request was not forwarded, then this is the active node of the
 	// primary. Create the entity here itself.
 	if entity == nil {
 		persist = true
+		entityCreated = true
 		entity = new(identity.Entity)
 		err = i.sanitizeEntity(ctx, entity)
 		if err != nil {
@@ -339,6 +341,11 @@ func (i *IdentityStore) handleAliasCreate(ctx context.Context, canonicalID, name
 		return nil, err
 	}
 
+	if entityCreated {
+		i.sendEntityEvent(ctx, eventTypeEntityCreated, entity, nil)
+	}
+	i.sendEntityAliasEvent(ctx, eventTypeEntityAliasCreated, alias)
+
 	// Return ID of both alias and entity
 	return &logical.Response{
 		Data: map[string]interface{}{
@@ -475,6 +482,8 @@ func (i *IdentityStore) handleAliasUpdate(ctx context.Context, canonicalID, name
 			return nil, err
 		}
 
+		i.sendEntityAliasEvent(ctx, eventTypeEntityAliasUpdated, alias)
+
 		return &logical.Response{
 			Data: map[string]interface{}{
 				"id":           alias.ID,
@@ -491,6 +500,8 @@ func (i *IdentityStore) handleAliasUpdate(ctx context.Context, canonicalID, name
 		return nil, err
 	}
 
+	i.sendEntityAliasEvent(ctx, eventTypeEntityAliasUpdated, alias)
+
 	// Return ID of both alias and entity
 	return &logical.Response{
 		Data: map[string]interface{}{
@@ -656,6 +667,8 @@ func (i *IdentityStore) pathAliasIDDelete() framework.OperationFunc {
 		// storage
 		txn.Commit()
 
+		i.sendEntityAliasEvent(ctx, eventTypeEntityAliasDeleted, alias)
+
 		return nil, nil
 	}
 }
@@ -0,0 +1,584 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-secure-stdlib/strutil"
+	"github.com/hashicorp/vault/helper/identity"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	entityDedupeConfigPath = "duplicates/config"
+
+	entityDedupeModeReport = "report"
+	entityDedupeModeMerge  = "merge"
+
+	entityDedupeKeepOldest = "oldest"
+	entityDedupeKeepNewest = "newest"
+
+	defaultEntityDedupeInterval = time.Hour
+	minEntityDedupeInterval     = time.Minute
+)
+
+// entityDedupeConfig configures the periodic detection of entities whose
+// aliases only differ by the case or surrounding whitespace of their names,
+// and whether they are merged.
+type entityDedupeConfig struct {
+	Mode     string        `json:"mode"`
+	Keep     string        `json:"keep"`
+	Interval time.Duration `json:"interval"`
+
+	LastRunTime    time.Time `json:"last_run_time"`
+	LastRunError   string    `json:"last_run_error"`
+	LastDuplicates int       `json:"last_duplicates"`
+	LastMerged     int       `json:"last_merged"`
+}
+
+// entityDuplicate is a set of aliases on the same mount, with the same
+// normalized name, belonging to more than one entity.
+type entityDuplicate struct {
+	MountAccessor  string
+	NormalizedName string
+	Aliases        []*identity.Alias
+}
+
+// entityDedupeResult counts the outcome of a deduplication run.
+type entityDedupeResult struct {
+	// Duplicates is the number of duplicate sets found.
+	Duplicates int
+	// Merged is the number of entities merged into another.
+	Merged int
+	// Failed is the number of entities which failed to be merged.
+	Failed int
+}
+
+func dedupePaths(i *IdentityStore) []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "duplicates/config$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "entity-duplicates",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"mode": {
+					Type:        framework.TypeString,
+					Description: `Whether duplicate entities are only reported, or merged. Valid values are "report" and "merge".`,
+					Default:     entityDedupeModeReport,
+				},
+				"keep": {
+					Type:        framework.TypeString,
+					Description: `Which of the duplicate entities the others are merged into. Valid values are "oldest" and "newest".`,
+					Default:     entityDedupeKeepOldest,
+				},
+				"interval": {
+					Type:        framework.TypeDurationSecond,
+					Description: "How often to look for duplicate entities. Defaults to 1 hour.",
+					Default:     int(defaultEntityDedupeInterval.Seconds()),
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: i.pathEntityDedupeConfigWrite,
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "configure",
+					},
+					Summary: "Enable the periodic detection of duplicate entities.",
+				},
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: i.pathEntityDedupeConfigRead,
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb:   "read",
+						OperationSuffix: "configuration",
+					},
+					Summary: "Read the duplicate entity detection configuration and status.",
+				},
+				logical.DeleteOperation: &framework.PathOperation{
+					Callback: i.pathEntityDedupeConfigDelete,
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb:   "delete",
+						OperationSuffix: "configuration",
+					},
+					Summary: "Disable the periodic detection of duplicate entities.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(dedupeHelp["duplicates-config"][0]),
+			HelpDescription: strings.TrimSpace(dedupeHelp["duplicates-config"][1]),
+		},
+		{
+			Pattern: "duplicates/run$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "entity-duplicates",
+				OperationVerb:   "run",
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: i.pathEntityDedupeRun,
+					Summary:  "Look for duplicate entities immediately, merging them if configured to.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(dedupeHelp["duplicates-run"][0]),
+			HelpDescription: strings.TrimSpace(dedupeHelp["duplicates-run"][1]),
+		},
+		{
+			Pattern: "duplicates/?$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "entity-duplicates",
+				OperationVerb:   "read",
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: i.pathEntityDuplicatesRead,
+					Summary:  "Report the entities whose aliases only differ by the case of their names.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(dedupeHelp["duplicates"][0]),
+			HelpDescription: strings.TrimSpace(dedupeHelp["duplicates"][1]),
+		},
+	}
+}
+
+func (i *IdentityStore) pathEntityDedupeConfigWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	config, err := i.getEntityDedupeConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		config = &entityDedupeConfig{}
+	}
+
+	config.Mode = d.Get("mode").(string)
+	if config.Mode != entityDedupeModeReport && config.Mode != entityDedupeModeMerge {
+		return logical.ErrorResponse("invalid mode %q", config.Mode), logical.ErrInvalidRequest
+	}
+	config.Keep = d.Get("keep").(string)
+	if config.Keep != entityDedupeKeepOldest && config.Keep != entityDedupeKeepNewest {
+		return logical.ErrorResponse("invalid keep %q", config.Keep), logical.ErrInvalidRequest
+	}
+	config.Interval = time.Duration(d.Get("interval").(int)) * time.Second
+	if config.Interval < minEntityDedupeInterval {
+		return logical.ErrorResponse("interval must be at least %s", minEntityDedupeInterval), logical.ErrInvalidRequest
+	}
+
+	if err := i.putEntityDedupeConfig(ctx, req.Storage, config); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (i *IdentityStore) pathEntityDedupeConfigRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	config, err := i.getEntityDedupeConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, nil
+	}
+
+	data := map[string]interface{}{
+		"mode":            config.Mode,
+		"keep":            config.Keep,
+		"interval":        int64(config.Interval.Seconds()),
+		"last_run_time":   "",
+		"last_run_error":  config.LastRunError,
+		"last_duplicates": config.LastDuplicates,
+		"last_merged":     config.LastMerged,
+	}
+	if !config.LastRunTime.IsZero() {
+		data["last_run_time"] = config.LastRunTime.Format(time.RFC3339)
+	}
+
+	return &logical.Response{
+		Data: data,
+	}, nil
+}
+
+func (i *IdentityStore) pathEntityDedupeConfigDelete(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	return nil, req.Storage.Delete(ctx, entityDedupeConfigPath)
+}
+
+func (i *IdentityStore) pathEntityDedupeRun(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	config, err := i.getEntityDedupeConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	i.entityDedupeLock.Lock()
+	defer i.entityDedupeLock.Unlock()
+
+	var result *entityDedupeResult
+	if config == nil {
+		// Without a configuration, duplicates are only counted
+		result, err = i.dedupeEntities(ctx, &entityDedupeConfig{Mode: entityDedupeModeReport})
+	} else {
+		result, err = i.runEntityDedupe(ctx, req.Storage, config)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"duplicates": result.Duplicates,
+			"merged":     result.Merged,
+			"failed":     result.Failed,
+		},
+	}, nil
+}
+
+func (i *IdentityStore) pathEntityDuplicatesRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	duplicates, err := i.findDuplicateEntities(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	duplicatesData := make([]map[string]interface{}, 0, len(duplicates))
+	for _, duplicate := range duplicates {
+		entityIDs := make([]string, 0, len(duplicate.Aliases))
+		aliases := make([]map[string]interface{}, 0, len(duplicate.Aliases))
+		for _, alias := range duplicate.Aliases {
+			entityIDs = append(entityIDs, alias.CanonicalID)
+			aliases = append(aliases, map[string]interface{}{
+				"id":           alias.ID,
+				"name":         alias.Name,
+				"canonical_id": alias.CanonicalID,
+			})
+		}
+
+		duplicateData := map[string]interface{}{
+			"mount_accessor":  duplicate.MountAccessor,
+			"normalized_name": duplicate.NormalizedName,
+			"entity_ids":      strutil.RemoveDuplicates(entityIDs, false),
+			"aliases":         aliases,
+		}
+		if mountValidationResp := i.router.ValidateMountByAccessor(duplicate.MountAccessor); mountValidationResp != nil {
+			duplicateData["mount_type"] = mountValidationResp.MountType
+			duplicateData["mount_path"] = mountValidationResp.MountPath
+		}
+		duplicatesData = append(duplicatesData, duplicateData)
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"duplicates": duplicatesData,
+		},
+	}, nil
+}
+
+func (i *IdentityStore) getEntityDedupeConfig(ctx context.Context, s logical.Storage) (*entityDedupeConfig, error) {
+	entry, err := s.Get(ctx, entityDedupeConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var config entityDedupeConfig
+	if err := entry.DecodeJSON(&config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (i *IdentityStore) putEntityDedupeConfig(ctx context.Context, s logical.Storage, config *entityDedupeConfig) error {
+	entry, err := logical.StorageEntryJSON(entityDedupeConfigPath, config)
+	if err != nil {
+		return err
+	}
+	return s.Put(ctx, entry)
+}
+
+// normalizeAliasName returns the name two aliases on the same mount must share
+// to be considered duplicates.
+func normalizeAliasName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// findDuplicateEntities returns the sets of aliases in the namespace of the
+// request sharing a mount and a normalized name, but not an entity. Local
+// aliases are ignored.
+func (i *IdentityStore) findDuplicateEntities(ctx context.Context) ([]*entityDuplicate, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	iter, err := i.MemDBAliases(nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	byFactors := make(map[string]*entityDuplicate)
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		alias := raw.(*identity.Alias)
+		if alias.NamespaceID != ns.ID || alias.Local {
+			continue
+		}
+
+		normalizedName := normalizeAliasName(alias.Name)
+		key := alias.MountAccessor + "/" + normalizedName
+		duplicate, ok := byFactors[key]
+		if !ok {
+			duplicate = &entityDuplicate{
+				MountAccessor:  alias.MountAccessor,
+				NormalizedName: normalizedName,
+			}
+			byFactors[key] = duplicate
+		}
+		duplicate.Aliases = append(duplicate.Aliases, alias)
+	}
+
+	var duplicates []*entityDuplicate
+	for _, duplicate := range byFactors {
+		for _, alias := range duplicate.Aliases[1:] {
+			if alias.CanonicalID != duplicate.Aliases[0].CanonicalID {
+				duplicates = append(duplicates, duplicate)
+				break
+			}
+		}
+	}
+	sort.Slice(duplicates, func(a, b int) bool {
+		if duplicates[a].MountAccessor != duplicates[b].MountAccessor {
+			return duplicates[a].MountAccessor < duplicates[b].MountAccessor
+		}
+		return duplicates[a].NormalizedName < duplicates[b].NormalizedName
+	})
+	for _, duplicate := range duplicates {
+		sort.Slice(duplicate.Aliases, func(a, b int) bool {
+			return duplicate.Aliases[a].ID < duplicate.Aliases[b].ID
+		})
+	}
+
+	return duplicates, nil
+}
+
+// runEntityDedupe deduplicates the entities of the namespace of the request
+// according to the configuration, and records the outcome in it. The caller
+// must hold entityDedupeLock.
+func (i *IdentityStore) runEntityDedupe(ctx context.Context, s logical.Storage, config *entityDedupeConfig) (*entityDedupeResult, error) {
+	result, err := i.dedupeEntities(ctx, config)
+
+	config.LastRunTime = time.Now()
+	config.LastRunError = ""
+	if err != nil {
+		config.LastRunError = err.Error()
+	} else {
+		config.LastDuplicates = result.Duplicates
+		config.LastMerged = result.Merged
+	}
+	if putErr := i.putEntityDedupeConfig(ctx, s, config); putErr != nil {
+		i.logger.Error("failed to persist entity deduplication status", "error", putErr)
+	}
+
+	return result, err
+}
+
+// dedupeEntities finds the duplicate entities of the namespace of the
+// request, and when configured to, merges each group of entities linked by
+// duplicate aliases into the oldest or newest of them. The aliases of the
+// entity merged into are kept over the duplicate aliases of the others, and
+// the policies of the entities are merged.
+func (i *IdentityStore) dedupeEntities(ctx context.Context, config *entityDedupeConfig) (*entityDedupeResult, error) {
+	duplicates, err := i.findDuplicateEntities(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &entityDedupeResult{
+		Duplicates: len(duplicates),
+	}
+	if len(duplicates) == 0 || config.Mode != entityDedupeModeMerge {
+		if len(duplicates) > 0 {
+			i.logger.Warn("found duplicate entities", "duplicates", len(duplicates))
+		}
+		return result, nil
+	}
+
+	// Group the entities sharing duplicate aliases, including transitively
+	parents := make(map[string]string)
+	var find func(string) string
+	find = func(id string) string {
+		parent, ok := parents[id]
+		if !ok || parent == id {
+			parents[id] = id
+			return id
+		}
+		root := find(parent)
+		parents[id] = root
+		return root
+	}
+	for _, duplicate := range duplicates {
+		root := find(duplicate.Aliases[0].CanonicalID)
+		for _, alias := range duplicate.Aliases[1:] {
+			if other := find(alias.CanonicalID); other != root {
+				parents[other] = root
+			}
+		}
+	}
+	components := make(map[string][]string)
+	for id := range parents {
+		root := find(id)
+		components[root] = append(components[root], id)
+	}
+
+	for _, entityIDs := range components {
+		entities := make([]*identity.Entity, 0, len(entityIDs))
+		for _, entityID := range entityIDs {
+			entity, err := i.MemDBEntityByID(entityID, false)
+			if err != nil {
+				return result, err
+			}
+			if entity != nil {
+				entities = append(entities, entity)
+			}
+		}
+		if len(entities) < 2 {
+			continue
+		}
+
+		sort.Slice(entities, func(a, b int) bool {
+			aTime, bTime := entities[a].CreationTime.AsTime(), entities[b].CreationTime.AsTime()
+			if !aTime.Equal(bTime) {
+				if config.Keep == entityDedupeKeepNewest {
+					return aTime.After(bTime)
+				}
+				return aTime.Before(bTime)
+			}
+			return entities[a].ID < entities[b].ID
+		})
+
+		for _, fromEntity := range entities[1:] {
+			if err := i.mergeDuplicateEntity(ctx, entities[0].ID, fromEntity.ID); err != nil {
+				i.logger.Error("failed to merge duplicate entity", "to_entity_id", entities[0].ID, "from_entity_id", fromEntity.ID, "error", err)
+				result.Failed++
+				continue
+			}
+			i.logger.Info("merged duplicate entity", "to_entity_id", entities[0].ID, "from_entity_id", fromEntity.ID)
+			result.Merged++
+		}
+	}
+
+	return result, nil
+}
+
+// mergeDuplicateEntity merges an entity into another, keeping the aliases of
+// the latter when they share a mount.
+func (i *IdentityStore) mergeDuplicateEntity(ctx context.Context, toEntityID, fromEntityID string) error {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	toEntity, err := i.MemDBEntityByID(toEntityID, true)
+	if err != nil {
+		return err
+	}
+	if toEntity == nil {
+		return fmt.Errorf("entity %q not found", toEntityID)
+	}
+
+	aliasIDsToKeep := make([]string, 0, len(toEntity.Aliases))
+	for _, alias := range toEntity.Aliases {
+		aliasIDsToKeep = append(aliasIDsToKeep, alias.ID)
+	}
+
+	txn := i.db.Txn(true)
+	defer txn.Abort()
+
+	userErr, intErr, _ := i.mergeEntity(ctx, txn, toEntity, []string{fromEntityID}, aliasIDsToKeep, false, false, true, true, false)
+	if userErr != nil {
+		return userErr
+	}
+	if intErr != nil {
+		return intErr
+	}
+
+	txn.Commit()
+
+	i.sendEntityMergedEvent(ctx, toEntity, []string{fromEntityID})
+
+	return nil
+}
+
+// entityDedupePeriodicFunc runs the entity deduplications which are due.
+func (i *IdentityStore) entityDedupePeriodicFunc(ctx context.Context) {
+	// Merges write entities, so only run this on the primary cluster. The
+	// periodic func does not run on perf standbys or DR secondaries.
+	if i.System().ReplicationState().HasState(consts.ReplicationPerformanceSecondary) {
+		return
+	}
+
+	if !i.entityDedupeLock.TryLock() {
+		return
+	}
+	defer i.entityDedupeLock.Unlock()
+
+	now := time.Now()
+	for _, ns := range i.namespacer.ListNamespaces(true) {
+		s := i.router.MatchingStorageByAPIPath(ctx, ns.Path+"identity/"+entityDedupeConfigPath)
+		if s == nil {
+			continue
+		}
+
+		nsCtx := namespace.ContextWithNamespace(ctx, ns)
+		config, err := i.getEntityDedupeConfig(nsCtx, s)
+		if err != nil {
+			i.logger.Error("failed to read entity deduplication configuration", "namespace", ns.Path, "error", err)
+			continue
+		}
+		if config == nil || now.Sub(config.LastRunTime) < config.Interval {
+			continue
+		}
+
+		if _, err := i.runEntityDedupe(nsCtx, s, config); err != nil {
+			i.logger.Warn("failed to deduplicate entities", "namespace", ns.Path, "error", err)
+		}
+	}
+}
+
+var dedupeHelp = map[string][2]string{
+	"duplicates": {
+		"Report the entities whose aliases only differ by the case of their names.",
+		`
+Lists the sets of entity aliases on the same auth method whose names only
+differ by their case or surrounding whitespace, but which belong to different
+entities. Such duplicates arise when an auth method returns the same user
+under names with a different case, while identity names are case sensitive.
+Local aliases are ignored.
+		`,
+	},
+	"duplicates-config": {
+		"Configure the periodic detection of duplicate entities.",
+		`
+When configured, duplicate entities are periodically looked for. In the
+"merge" mode, each group of entities sharing duplicate aliases is merged into
+the oldest or newest of them. Its aliases are kept over the duplicate aliases
+of the others, and the policies of the entities are merged. Merges are
+irreversible.
+		`,
+	},
+	"duplicates-run": {
+		"Look for duplicate entities immediately, merging them if configured to.",
+		"",
+	},
+}
@@ -0,0 +1,184 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestIdentityStore_EntityDedupe(t *testing.T) {
+	ctx := namespace.RootContext(nil)
+	is, githubAccessor, upAccessor, _ := testIdentityStoreWithGithubUserpassAuth(ctx, t)
+
+	// Duplicates can only be stored when names are case sensitive
+	is.disableLowerCasedNames = true
+	if err := is.resetDB(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	registerEntity := func(name string, policies []string, aliases map[string]string) string {
+		t.Helper()
+		resp, err := is.HandleRequest(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "entity",
+			Data: map[string]interface{}{
+				"name":     name,
+				"policies": policies,
+			},
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("err:%v resp:%#v", err, resp)
+		}
+		entityID := resp.Data["id"].(string)
+
+		for aliasName, mountAccessor := range aliases {
+			resp, err = is.HandleRequest(ctx, &logical.Request{
+				Operation: logical.UpdateOperation,
+				Path:      "entity-alias",
+				Data: map[string]interface{}{
+					"name":           aliasName,
+					"mount_accessor": mountAccessor,
+					"canonical_id":   entityID,
+				},
+			})
+			if err != nil || (resp != nil && resp.IsError()) {
+				t.Fatalf("err:%v resp:%#v", err, resp)
+			}
+		}
+		return entityID
+	}
+
+	// The second entity duplicates the first on the github mount, and the
+	// third entity duplicates the second on the userpass mount
+	entityID1 := registerEntity("entity1", []string{"p1"}, map[string]string{"bob": githubAccessor})
+	entityID2 := registerEntity("entity2", []string{"p2"}, map[string]string{"Bob": githubAccessor, "bob-up": upAccessor})
+	entityID3 := registerEntity("entity3", nil, map[string]string{" BOB-UP": upAccessor})
+	entityID4 := registerEntity("entity4", nil, map[string]string{"alice": githubAccessor})
+
+	duplicatesReq := &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "duplicates",
+	}
+	resp, err := is.HandleRequest(ctx, duplicatesReq)
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("err:%v resp:%#v", err, resp)
+	}
+	duplicates := resp.Data["duplicates"].([]map[string]interface{})
+	if len(duplicates) != 2 {
+		t.Fatalf("expected 2 duplicates, got: %#v", duplicates)
+	}
+	for _, duplicate := range duplicates {
+		var expected []string
+		switch duplicate["normalized_name"] {
+		case "bob":
+			expected = []string{entityID1, entityID2}
+		case "bob-up":
+			expected = []string{entityID2, entityID3}
+		default:
+			t.Fatalf("unexpected duplicate: %#v", duplicate)
+		}
+		if len(duplicate["aliases"].([]map[string]interface{})) != 2 {
+			t.Fatalf("bad: aliases of duplicate: %#v", duplicate)
+		}
+		entityIDs := duplicate["entity_ids"].([]string)
+		if len(entityIDs) != 2 || !(reflect.DeepEqual(entityIDs, expected) || reflect.DeepEqual(entityIDs, []string{expected[1], expected[0]})) {
+			t.Fatalf("bad: entities of duplicate: %#v", duplicate)
+		}
+	}
+
+	runReq := &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "duplicates/run",
+	}
+
+	// Without a configuration, duplicates are only reported
+	resp, err = is.HandleRequest(ctx, runReq)
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("err:%v resp:%#v", err, resp)
+	}
+	if resp.Data["duplicates"] != 2 || resp.Data["merged"] != 0 {
+		t.Fatalf("unexpected run result: %#v", resp.Data)
+	}
+
+	configReq := &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "duplicates/config",
+		Data: map[string]interface{}{
+			"mode": "squash",
+		},
+	}
+	resp, err = is.HandleRequest(ctx, configReq)
+	if err != logical.ErrInvalidRequest {
+		t.Fatalf("expected an invalid mode to be rejected, got err: %v resp: %#v", err, resp)
+	}
+
+	configReq.Data["mode"] = "merge"
+	resp, err = is.HandleRequest(ctx, configReq)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err:%v resp:%#v", err, resp)
+	}
+
+	resp, err = is.HandleRequest(ctx, runReq)
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("err:%v resp:%#v", err, resp)
+	}
+	if resp.Data["duplicates"] != 2 || resp.Data["merged"] != 2 || resp.Data["failed"] != 0 {
+		t.Fatalf("unexpected run result: %#v", resp.Data)
+	}
+
+	// The duplicates are merged into the oldest entity, keeping its aliases
+	for _, entityID := range []string{entityID2, entityID3} {
+		entity, err := is.MemDBEntityByID(entityID, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entity != nil {
+			t.Fatalf("entity %q was not merged", entityID)
+		}
+	}
+	entity1, err := is.MemDBEntityByID(entityID1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aliasNames := make(map[string]bool)
+	for _, alias := range entity1.Aliases {
+		aliasNames[alias.Name] = true
+	}
+	if !reflect.DeepEqual(aliasNames, map[string]bool{"bob": true, "bob-up": true}) {
+		t.Fatalf("bad: aliases of the merged entity: %#v", aliasNames)
+	}
+	if !reflect.DeepEqual(entity1.Policies, []string{"p1", "p2"}) {
+		t.Fatalf("bad: policies of the merged entity: %#v", entity1.Policies)
+	}
+	entity4, err := is.MemDBEntityByID(entityID4, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entity4 == nil || len(entity4.MergedEntityIDs) != 0 {
+		t.Fatalf("unrelated entity was merged: %#v", entity4)
+	}
+
+	resp, err = is.HandleRequest(ctx, duplicatesReq)
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("err:%v resp:%#v", err, resp)
+	}
+	if len(resp.Data["duplicates"].([]map[string]interface{})) != 0 {
+		t.Fatalf("expected no duplicates, got: %#v", resp.Data["duplicates"])
+	}
+
+	resp, err = is.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "duplicates/config",
+	})
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("err:%v resp:%#v", err, resp)
+	}
+	if resp.Data["last_duplicates"] != 2 || resp.Data["last_merged"] != 2 || resp.Data["last_run_time"] == "" {
+		t.Fatalf("unexpected status: %#v", resp.Data)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/go-multierror"
@@ -220,6 +221,10 @@ func entityPaths(i *IdentityStore) []*framework.Path {
 					Type:        framework.TypeBool,
 					Description: "Setting this will follow the 'mine' strategy for merging MFA secrets. If there are secrets of the same type both in entities that are merged from and in entity into which all others are getting merged, secrets in the destination will be unaltered. If not set, this API will throw an error containing all the conflicts.",
 				},
+				"dry_run": {
+					Type:        framework.TypeBool,
+					Description: "If set, the merge isn't performed, and the response describes the resulting entity and the conflicts found instead.",
+				},
 			},
 			Callbacks: map[logical.Operation]framework.OperationFunc{
 				logical.UpdateOperation: i.pathEntityMergeID(),
@@ -256,6 +261,8 @@ func (i *IdentityStore) pathEntityMergeID() framework.OperationFunc {
 			force = forceInterface.(bool)
 		}
 
+		dryRun := d.Get("dry_run").(bool)
+
 		// Create a MemDB transaction to merge entities
 		i.lock.Lock()
 		defer i.lock.Unlock()
@@ -268,6 +275,11 @@ func (i *IdentityStore) pathEntityMergeID() framework.OperationFunc {
 			return nil, err
 		}
 
+		if dryRun {
+			// The transaction is aborted, so the merge is never committed
+			return i.entityMergeDryRun(ctx, txn, toEntity, fromEntityIDs, conflictingAliasIDsToKeep, force)
+		}
+
 		userErr, intErr, aliases := i.mergeEntity(ctx, txn, toEntity, fromEntityIDs, conflictingAliasIDsToKeep, force, false, false, true, false)
 		if userErr != nil {
 			// Not an error due to alias clash, return like normal
@@ -292,10 +304,92 @@ func (i *IdentityStore) pathEntityMergeID() framework.OperationFunc {
 		// persistence
 		txn.Commit()
 
+		i.sendEntityMergedEvent(ctx, toEntity, fromEntityIDs)
+
 		return nil, nil
 	}
 }
 
+// entityMergeDryRun merges the entities in txn without persisting them, and
+// describes the resulting entity along with the conflicts found. The caller
+// must abort txn.
+func (i *IdentityStore) entityMergeDryRun(ctx context.Context, txn *memdb.Txn, toEntity *identity.Entity, fromEntityIDs, conflictingAliasIDsToKeep []string, force bool) (*logical.Response, error) {
+	// MFA secrets conflicting with those of the entity merged into fail the
+	// merge unless it is forced, so look for them before merging
+	mfaConflicts := make([]map[string]interface{}, 0)
+	var mfaConflictErr error
+	if toEntity != nil {
+		for _, fromEntityID := range strutil.RemoveDuplicates(fromEntityIDs, false) {
+			fromEntity, err := i.MemDBEntityByID(fromEntityID, false)
+			if err != nil {
+				return nil, err
+			}
+			if fromEntity == nil {
+				continue
+			}
+
+			for configID := range fromEntity.MFASecrets {
+				if _, ok := toEntity.MFASecrets[configID]; !ok {
+					continue
+				}
+				mfaConflicts = append(mfaConflicts, map[string]interface{}{
+					"mfa_config_id":  configID,
+					"from_entity_id": fromEntityID,
+				})
+				if mfaConflictErr == nil {
+					mfaConflictErr = fmt.Errorf("conflicting MFA config ID %q in entity ID %q", configID, fromEntityID)
+				}
+			}
+		}
+	}
+
+	// Merge as if forced, so that the alias conflicts are found even when MFA
+	// secrets conflict
+	userErr, intErr, aliases := i.mergeEntity(ctx, txn, toEntity, fromEntityIDs, conflictingAliasIDsToKeep, true, false, false, false, false)
+	if intErr != nil {
+		return nil, intErr
+	}
+	if aliases == nil {
+		aliases = []aliasClashInformation{}
+	}
+
+	respData := map[string]interface{}{
+		"alias_conflicts": aliases,
+		"mfa_conflicts":   mfaConflicts,
+	}
+	switch {
+	case userErr != nil:
+		respData["error"] = userErr.Error()
+	case mfaConflictErr != nil && !force:
+		respData["error"] = mfaConflictErr.Error()
+	default:
+		entityData := i.entityResponseData(toEntity)
+
+		groups, err := i.MemDBGroupsByMemberEntityIDInTxn(txn, toEntity.ID, false, false)
+		if err != nil {
+			return nil, err
+		}
+		groupIDs := make([]string, len(groups))
+		for i, group := range groups {
+			groupIDs[i] = group.ID
+		}
+		entityData["direct_group_ids"] = groupIDs
+
+		mfaConfigIDs := make([]string, 0, len(toEntity.MFASecrets))
+		for configID := range toEntity.MFASecrets {
+			mfaConfigIDs = append(mfaConfigIDs, configID)
+		}
+		sort.Strings(mfaConfigIDs)
+		entityData["mfa_config_ids"] = mfaConfigIDs
+
+		respData["entity"] = entityData
+	}
+
+	return &logical.Response{
+		Data: respData,
+	}, nil
+}
+
 // handleEntityUpdateCommon is used to update an entity
 func (i *IdentityStore) handleEntityUpdateCommon() framework.OperationFunc {
 	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
@@ -316,93 +410,104 @@ func (i *IdentityStore) handleEntityUpdateCommon() framework.OperationFunc {
 			}
 		}
 
-		// Get the name
-		entityName := d.Get("name").(string)
-		if entityName != "" {
-			entityByName, err := i.MemDBEntityByName(ctx, entityName, false)
-			if err != nil {
-				return nil, err
-			}
-			switch {
-			case entityByName == nil:
-				// Not found, safe to use this name with an existing or new entity
-			case entity.ID == "":
-				// Entity by ID was not found, but and entity for the supplied
-				// name was found. Continue updating the entity.
-				entity = entityByName
-			case entity.ID == entityByName.ID:
-				// Same exact entity, carry on (this is basically a noop then)
-			default:
-				return logical.ErrorResponse("entity name is already in use"), nil
-			}
-		}
+		return i.handleEntityUpdate(ctx, d, entity, false)
+	}
+}
 
-		if entityName != "" {
-			entity.Name = entityName
-		}
+// handleEntityUpdate applies the request to the entity, which is created if
+// its ID is empty, or if it is imported with a caller-specified ID. The
+// identity store lock must be held.
+func (i *IdentityStore) handleEntityUpdate(ctx context.Context, d *framework.FieldData, entity *identity.Entity, imported bool) (*logical.Response, error) {
+	var err error
 
-		// Update the policies if supplied
-		entityPoliciesRaw, ok := d.GetOk("policies")
-		if ok {
-			entity.Policies = strutil.RemoveDuplicates(entityPoliciesRaw.([]string), false)
+	// Get the name
+	entityName := d.Get("name").(string)
+	if entityName != "" {
+		entityByName, err := i.MemDBEntityByName(ctx, entityName, false)
+		if err != nil {
+			return nil, err
 		}
-
-		if strutil.StrListContains(entity.Policies, "root") {
-			return logical.ErrorResponse("policies cannot contain root"), nil
+		switch {
+		case entityByName == nil:
+			// Not found, safe to use this name with an existing or new entity
+		case entity.ID == "":
+			// Entity by ID was not found, but and entity for the supplied
+			// name was found. Continue updating the entity.
+			entity = entityByName
+		case entity.ID == entityByName.ID:
+			// Same exact entity, carry on (this is basically a noop then)
+		default:
+			return logical.ErrorResponse("entity name is already in use"), nil
 		}
+	}
 
-		disabledRaw, ok := d.GetOk("disabled")
-		if ok {
-			entity.Disabled = disabledRaw.(bool)
-		}
+	if entityName != "" {
+		entity.Name = entityName
+	}
 
-		// Get entity metadata
-		metadata, ok, err := d.GetOkErr("metadata")
-		if err != nil {
-			return logical.ErrorResponse(fmt.Sprintf("failed to parse metadata: %v", err)), nil
-		}
-		if ok {
-			entity.Metadata = metadata.(map[string]string)
-		}
+	// Update the policies if supplied
+	entityPoliciesRaw, ok := d.GetOk("policies")
+	if ok {
+		entity.Policies = strutil.RemoveDuplicates(entityPoliciesRaw.([]string), false)
+	}
 
-		// At this point, if entity.ID is empty, it indicates that a new entity
-		// is being created. Using this to respond data in the response.
-		newEntity := entity.ID == ""
+	if strutil.StrListContains(entity.Policies, "root") {
+		return logical.ErrorResponse("policies cannot contain root"), nil
+	}
 
-		// ID creation and some validations
-		err = i.sanitizeEntity(ctx, entity)
-		if err != nil {
-			return nil, err
-		}
+	disabledRaw, ok := d.GetOk("disabled")
+	if ok {
+		entity.Disabled = disabledRaw.(bool)
+	}
 
-		if err := i.upsertEntity(ctx, entity, nil, true); err != nil {
-			return nil, err
-		}
+	// Get entity metadata
+	metadata, ok, err := d.GetOkErr("metadata")
+	if err != nil {
+		return logical.ErrorResponse(fmt.Sprintf("failed to parse metadata: %v", err)), nil
+	}
+	if ok {
+		entity.Metadata = metadata.(map[string]string)
+	}
 
-		// If this operation was an update to an existing entity, return 204
-		if !newEntity {
-			return nil, nil
-		}
+	// At this point, if entity.ID is empty, it indicates that a new entity
+	// is being created. Using this to respond data in the response.
+	newEntity := entity.ID == "" || imported
 
-		// Prepare the response
-		respData := map[string]interface{}{
-			"id":   entity.ID,
-			"name": entity.Name,
-		}
+	// ID creation and some validations
+	err = i.sanitizeEntity(ctx, entity)
+	if err != nil {
+		return nil, err
+	}
 
-		var aliasIDs []string
-		for _, alias := range entity.Aliases {
-			aliasIDs = append(aliasIDs, alias.ID)
-		}
+	if err := i.upsertEntity(ctx, entity, nil, true); err != nil {
+		return nil, err
+	}
 
-		respData["aliases"] = aliasIDs
+	// If this operation was an update to an existing entity, return 204
+	if !newEntity {
+		i.sendEntityEvent(ctx, eventTypeEntityUpdated, entity, nil)
+		return nil, nil
+	}
+	i.sendEntityEvent(ctx, eventTypeEntityCreated, entity, nil)
+
+	// Prepare the response
+	respData := map[string]interface{}{
+		"id":   entity.ID,
+		"name": entity.Name,
+	}
 
-		// Return ID of the entity that was either created or updated along with
-		// its aliases
-		return &logical.Response{
-			Data: respData,
-		}, nil
+	var aliasIDs []string
+	for _, alias := range entity.Aliases {
+		aliasIDs = append(aliasIDs, alias.ID)
 	}
+
+	respData["aliases"] = aliasIDs
+
+	// Return ID of the entity that was either created or updated along with
+	// its aliases
+	return &logical.Response{
+		Data: respData,
+	}, nil
 }
 
 // pathEntityNameRead returns the properties of an entity for a given entity ID
@@ -454,6 +559,36 @@ func (i *IdentityStore) handleEntityReadCommon(ctx context.Context, entity *iden
 		return nil, nil
 	}
 
+	respData := i.entityResponseData(entity)
+
+	// Fetch the groups this entity belongs to and return their identifiers
+	groups, inheritedGroups, err := i.groupsByEntityID(entity.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	groupIDs := make([]string, len(groups))
+	for i, group := range groups {
+		groupIDs[i] = group.ID
+	}
+	respData["direct_group_ids"] = groupIDs
+
+	inheritedGroupIDs := make([]string, len(inheritedGroups))
+	for i, group := range inheritedGroups {
+		inheritedGroupIDs[i] = group.ID
+	}
+	respData["inherited_group_ids"] = inheritedGroupIDs
+
+	respData["group_ids"] = append(groupIDs, inheritedGroupIDs...)
+
+	return &logical.Response{
+		Data: respData,
+	}, nil
+}
+
+// entityResponseData returns the response data describing the entity, but for
+// the groups it belongs to.
+func (i *IdentityStore) entityResponseData(entity *identity.Entity) map[string]interface{} {
 	respData := map[string]interface{}{}
 	respData["id"] = entity.ID
 	respData["name"] = entity.Name
@@ -496,29 +631,7 @@ func (i *IdentityStore) handleEntityReadCommon(ctx context.Context, entity *iden
 
 	addExtraEntityDataToResponse(entity, respData)
 
-	// Fetch the groups this entity belongs to and return their identifiers
-	groups, inheritedGroups, err := i.groupsByEntityID(entity.ID)
-	if err != nil {
-		return nil, err
-	}
-
-	groupIDs := make([]string, len(groups))
-	for i, group := range groups {
-		groupIDs[i] = group.ID
-	}
-	respData["direct_group_ids"] = groupIDs
-
-	inheritedGroupIDs := make([]string, len(inheritedGroups))
-	for i, group := range inheritedGroups {
-		inheritedGroupIDs[i] = group.ID
-	}
-	respData["inherited_group_ids"] = inheritedGroupIDs
-
-	respData["group_ids"] = append(groupIDs, inheritedGroupIDs...)
-
-	return &logical.Response{
-		Data: respData,
-	}, nil
+	return respData
 }
 
 // pathEntityIDDelete deletes the entity for a given entity ID
@@ -545,6 +658,11 @@ func (i *IdentityStore) pathEntityIDDelete() framework.OperationFunc {
 			return nil, nil
 		}
 
+		ns, err := namespace.FromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
 		err = i.handleEntityDeleteCommon(ctx, txn, entity, true)
 		if err != nil {
 			return nil, err
@@ -552,6 +670,11 @@ func (i *IdentityStore) pathEntityIDDelete() framework.OperationFunc {
 
 		txn.Commit()
 
+		// Entities of other namespaces are left untouched
+		if entity.NamespaceID == ns.ID {
+			i.sendEntityEvent(ctx, eventTypeEntityDeleted, entity, nil)
+		}
+
 		return nil, nil
 	}
 }
@@ -596,6 +719,8 @@ func (i *IdentityStore) pathEntityNameDelete() framework.OperationFunc {
 
 		txn.Commit()
 
+		i.sendEntityEvent(ctx, eventTypeEntityDeleted, entity, nil)
+
 		return nil, nil
 	}
 }
@@ -622,6 +747,11 @@ func (i *IdentityStore) handleEntityBatchDelete() framework.OperationFunc {
 			bucket[id] = struct{}{}
 		}
 
+		ns, err := namespace.FromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
 		deleteIdsForBucket := func(entityIDs []string) error {
 			i.lock.Lock()
 			defer i.lock.Unlock()
@@ -632,6 +762,7 @@ func (i *IdentityStore) handleEntityBatchDelete() framework.OperationFunc {
 			txn := i.db.Txn(true)
 			defer txn.Abort()
 
+			var deleted []*identity.Entity
 			for _, entityID := range entityIDs {
 				// Fetch the entity using its ID
 				entity, err := i.MemDBEntityByIDInTxn(txn, entityID, true)
@@ -646,6 +777,9 @@ func (i *IdentityStore) handleEntityBatchDelete() framework.OperationFunc {
 				if err != nil {
 					return err
 				}
+				if entity.NamespaceID == ns.ID {
+					deleted = append(deleted, entity)
+				}
 			}
 
 			// Write all updates for this bucket.
@@ -655,6 +789,10 @@ func (i *IdentityStore) handleEntityBatchDelete() framework.OperationFunc {
 			}
 
 			txn.Commit()
+
+			for _, entity := range deleted {
+				i.sendEntityEvent(ctx, eventTypeEntityDeleted, entity, nil)
+			}
 			return nil
 		}
 
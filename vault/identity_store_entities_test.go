@@ -14,6 +14,7 @@ import (
 	"github.com/hashicorp/go-uuid"
 	credGithub "github.com/hashicorp/vault/builtin/credential/github"
 	"github.com/hashicorp/vault/helper/identity"
+	"github.com/hashicorp/vault/helper/identity/mfa"
 	"github.com/hashicorp/vault/helper/namespace"
 	"github.com/hashicorp/vault/sdk/helper/strutil"
 	"github.com/hashicorp/vault/sdk/logical"
@@ -1191,6 +1192,153 @@ func TestIdentityStore_MergeEntitiesByID(t *testing.T) {
 	}
 }
 
+func TestIdentityStore_MergeEntitiesByID_DryRun(t *testing.T) {
+	ctx := namespace.RootContext(nil)
+	is, githubAccessor, upAccessor, _ := testIdentityStoreWithGithubUserpassAuth(ctx, t)
+
+	registerEntity := func(name, mountAccessor string) string {
+		t.Helper()
+		resp, err := is.HandleRequest(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "entity",
+			Data:      map[string]interface{}{"name": name},
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("err:%v resp:%#v", err, resp)
+		}
+		entityID := resp.Data["id"].(string)
+
+		resp, err = is.HandleRequest(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "entity-alias",
+			Data: map[string]interface{}{
+				"name":           name,
+				"mount_accessor": mountAccessor,
+				"canonical_id":   entityID,
+			},
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("err:%v resp:%#v", err, resp)
+		}
+		return entityID
+	}
+	toEntityID := registerEntity("to", githubAccessor)
+	fromEntityID := registerEntity("from", upAccessor)
+	clashingEntityID := registerEntity("clashing", githubAccessor)
+
+	resp, err := is.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "group",
+		Data:      map[string]interface{}{"member_entity_ids": fromEntityID},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err:%v resp:%#v", err, resp)
+	}
+	groupID := resp.Data["id"].(string)
+
+	mergeReq := &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "entity/merge",
+		Data: map[string]interface{}{
+			"to_entity_id":    toEntityID,
+			"from_entity_ids": []string{fromEntityID},
+			"dry_run":         true,
+		},
+	}
+	resp, err = is.HandleRequest(ctx, mergeReq)
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("err:%v resp:%#v", err, resp)
+	}
+	if _, ok := resp.Data["error"]; ok {
+		t.Fatalf("unexpected merge error: %v", resp.Data["error"])
+	}
+	if len(resp.Data["alias_conflicts"].([]aliasClashInformation)) != 0 {
+		t.Fatalf("unexpected alias conflicts: %#v", resp.Data["alias_conflicts"])
+	}
+	entityData := resp.Data["entity"].(map[string]interface{})
+	if len(entityData["aliases"].([]interface{})) != 2 {
+		t.Fatalf("bad: aliases of the merged entity: %#v", entityData["aliases"])
+	}
+	if !reflect.DeepEqual(entityData["merged_entity_ids"], []string{fromEntityID}) {
+		t.Fatalf("bad: merged entity IDs: %#v", entityData["merged_entity_ids"])
+	}
+	if !reflect.DeepEqual(entityData["direct_group_ids"], []string{groupID}) {
+		t.Fatalf("bad: groups of the merged entity: %#v", entityData["direct_group_ids"])
+	}
+
+	// Nothing was merged
+	fromEntity, err := is.MemDBEntityByID(fromEntityID, false)
+	if err != nil || fromEntity == nil {
+		t.Fatalf("entity merged from was deleted: %v", err)
+	}
+	toEntity, err := is.MemDBEntityByID(toEntityID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(toEntity.Aliases) != 1 || len(toEntity.MergedEntityIDs) != 0 {
+		t.Fatalf("entity merged into was updated: %#v", toEntity)
+	}
+	group, err := is.MemDBGroupByID(groupID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(group.MemberEntityIDs, []string{fromEntityID}) {
+		t.Fatalf("group was updated: %#v", group.MemberEntityIDs)
+	}
+
+	// Alias conflicts are reported instead of the resulting entity
+	mergeReq.Data["from_entity_ids"] = []string{clashingEntityID}
+	resp, err = is.HandleRequest(ctx, mergeReq)
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("err:%v resp:%#v", err, resp)
+	}
+	if resp.Data["error"] == nil || resp.Data["entity"] != nil {
+		t.Fatalf("expected a merge error: %#v", resp.Data)
+	}
+	if len(resp.Data["alias_conflicts"].([]aliasClashInformation)) != 2 {
+		t.Fatalf("bad: alias conflicts: %#v", resp.Data["alias_conflicts"])
+	}
+
+	// MFA secrets conflicts fail the merge unless it is forced
+	txn := is.db.Txn(true)
+	for _, entity := range []*identity.Entity{toEntity, fromEntity} {
+		entity, err = entity.Clone()
+		if err != nil {
+			t.Fatal(err)
+		}
+		entity.MFASecrets = map[string]*mfa.Secret{"totp-config": {MethodName: "totp"}}
+		if err := is.MemDBUpsertEntityInTxn(txn, entity); err != nil {
+			t.Fatal(err)
+		}
+	}
+	txn.Commit()
+
+	mergeReq.Data["from_entity_ids"] = []string{fromEntityID}
+	resp, err = is.HandleRequest(ctx, mergeReq)
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("err:%v resp:%#v", err, resp)
+	}
+	if resp.Data["error"] == nil || resp.Data["entity"] != nil {
+		t.Fatalf("expected a merge error: %#v", resp.Data)
+	}
+	if len(resp.Data["mfa_conflicts"].([]map[string]interface{})) != 1 {
+		t.Fatalf("bad: MFA conflicts: %#v", resp.Data["mfa_conflicts"])
+	}
+
+	mergeReq.Data["force"] = true
+	resp, err = is.HandleRequest(ctx, mergeReq)
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("err:%v resp:%#v", err, resp)
+	}
+	if len(resp.Data["mfa_conflicts"].([]map[string]interface{})) != 1 {
+		t.Fatalf("bad: MFA conflicts: %#v", resp.Data["mfa_conflicts"])
+	}
+	entityData = resp.Data["entity"].(map[string]interface{})
+	if !reflect.DeepEqual(entityData["mfa_config_ids"], []string{"totp-config"}) {
+		t.Fatalf("bad: MFA config IDs: %#v", entityData["mfa_config_ids"])
+	}
+}
+
 func TestIdentityStore_MergeEntitiesByID_DuplicateFromEntityIDs(t *testing.T) {
 	var err error
 	var resp *logical.Response
@@ -0,0 +1,102 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hashicorp/vault/helper/identity"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/vault/eventbus"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// The types of the events sent when entities and their aliases change, in the
+// namespace of the entity.
+const (
+	eventTypeEntityCreated      logical.EventType = "identity/entity/created"
+	eventTypeEntityUpdated      logical.EventType = "identity/entity/updated"
+	eventTypeEntityMerged       logical.EventType = "identity/entity/merged"
+	eventTypeEntityDeleted      logical.EventType = "identity/entity/deleted"
+	eventTypeEntityAliasCreated logical.EventType = "identity/entity-alias/created"
+	eventTypeEntityAliasUpdated logical.EventType = "identity/entity-alias/updated"
+	eventTypeEntityAliasDeleted logical.EventType = "identity/entity-alias/deleted"
+)
+
+// sendEntityEvent sends an event about a change to the entity. Events are
+// best-effort: failures to send them are logged, and never fail the change.
+func (i *IdentityStore) sendEntityEvent(ctx context.Context, eventType logical.EventType, entity *identity.Entity, extra map[string]interface{}) {
+	aliasIDs := make([]interface{}, 0, len(entity.Aliases))
+	for _, alias := range entity.Aliases {
+		aliasIDs = append(aliasIDs, alias.ID)
+	}
+
+	metadata := map[string]interface{}{
+		"entity_id":   entity.ID,
+		"entity_name": entity.Name,
+		"alias_ids":   aliasIDs,
+	}
+	for k, v := range extra {
+		metadata[k] = v
+	}
+
+	i.sendIdentityEvent(ctx, eventType, entity.NamespaceID, []string{entity.ID}, metadata)
+}
+
+// sendEntityMergedEvent sends an event about entities merged into another,
+// which are deleted along the way.
+func (i *IdentityStore) sendEntityMergedEvent(ctx context.Context, toEntity *identity.Entity, fromEntityIDs []string) {
+	mergedIDs := make([]interface{}, 0, len(fromEntityIDs))
+	for _, id := range fromEntityIDs {
+		mergedIDs = append(mergedIDs, id)
+	}
+	i.sendEntityEvent(ctx, eventTypeEntityMerged, toEntity, map[string]interface{}{
+		"from_entity_ids": mergedIDs,
+	})
+}
+
+// sendEntityAliasEvent sends an event about a change to an alias of an
+// entity.
+func (i *IdentityStore) sendEntityAliasEvent(ctx context.Context, eventType logical.EventType, alias *identity.Alias) {
+	i.sendIdentityEvent(ctx, eventType, alias.NamespaceID, []string{alias.ID, alias.CanonicalID}, map[string]interface{}{
+		"alias_id":       alias.ID,
+		"alias_name":     alias.Name,
+		"entity_id":      alias.CanonicalID,
+		"mount_accessor": alias.MountAccessor,
+		"mount_type":     alias.MountType,
+		"local":          alias.Local,
+	})
+}
+
+func (i *IdentityStore) sendIdentityEvent(ctx context.Context, eventType logical.EventType, namespaceID string, ids []string, metadata map[string]interface{}) {
+	if i.events == nil {
+		return
+	}
+
+	err := func() error {
+		ns, err := i.namespacer.NamespaceByID(ctx, namespaceID)
+		if err != nil {
+			return err
+		}
+		if ns == nil {
+			return errors.New("namespace not found")
+		}
+
+		event, err := logical.NewEvent()
+		if err != nil {
+			return err
+		}
+		event.EntityIds = ids
+		event.Metadata, err = structpb.NewStruct(metadata)
+		if err != nil {
+			return err
+		}
+
+		return i.events.SendInternal(ctx, ns, nil, eventType, event)
+	}()
+	if err != nil && !errors.Is(err, eventbus.ErrNotStarted) {
+		i.logger.Warn("failed to send identity event", "event_type", eventType, "error", err)
+	}
+}
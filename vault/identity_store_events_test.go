@@ -0,0 +1,113 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestIdentityStore_EntityEvents(t *testing.T) {
+	ctx := namespace.RootContext(nil)
+	is, githubAccessor, upAccessor, c := testIdentityStoreWithGithubUserpassAuth(ctx, t)
+	c.events.Start()
+
+	ch, cancel, err := c.events.Subscribe(ctx, namespace.RootNamespace, "identity/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+
+	expectEvent := func(eventType logical.EventType, key, value string) *logical.EventReceived {
+		t.Helper()
+		select {
+		case e := <-ch:
+			received := e.Payload.(*logical.EventReceived)
+			if received.EventType != string(eventType) {
+				t.Fatalf("expected a %q event, got: %#v", eventType, received)
+			}
+			if got := received.Event.Metadata.AsMap()[key]; got != value {
+				t.Fatalf("expected %s %q in the %q event, got: %#v", key, value, eventType, got)
+			}
+			return received
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for a %q event", eventType)
+		}
+		return nil
+	}
+	handle := func(op logical.Operation, path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := is.HandleRequest(ctx, &logical.Request{
+			Operation: op,
+			Path:      path,
+			Data:      data,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("err:%v resp:%#v", err, resp)
+		}
+		return resp
+	}
+
+	resp := handle(logical.UpdateOperation, "entity", map[string]interface{}{"name": "alice"})
+	entityID1 := resp.Data["id"].(string)
+	expectEvent(eventTypeEntityCreated, "entity_id", entityID1)
+
+	handle(logical.UpdateOperation, "entity/id/"+entityID1, map[string]interface{}{"policies": "p1"})
+	expectEvent(eventTypeEntityUpdated, "entity_name", "alice")
+
+	// Aliases created without an entity also create one
+	resp = handle(logical.UpdateOperation, "entity-alias", map[string]interface{}{
+		"name":           "bob",
+		"mount_accessor": githubAccessor,
+	})
+	aliasID := resp.Data["id"].(string)
+	entityID2 := resp.Data["canonical_id"].(string)
+	expectEvent(eventTypeEntityCreated, "entity_id", entityID2)
+	expectEvent(eventTypeEntityAliasCreated, "alias_id", aliasID)
+
+	handle(logical.UpdateOperation, "entity-alias/id/"+aliasID, map[string]interface{}{
+		"name":           "bobby",
+		"mount_accessor": githubAccessor,
+	})
+	expectEvent(eventTypeEntityAliasUpdated, "alias_name", "bobby")
+
+	handle(logical.UpdateOperation, "entity/merge", map[string]interface{}{
+		"to_entity_id":    entityID1,
+		"from_entity_ids": []string{entityID2},
+	})
+	received := expectEvent(eventTypeEntityMerged, "entity_id", entityID1)
+	fromEntityIDs := received.Event.Metadata.AsMap()["from_entity_ids"].([]interface{})
+	if len(fromEntityIDs) != 1 || fromEntityIDs[0] != entityID2 {
+		t.Fatalf("bad: merged entities: %#v", fromEntityIDs)
+	}
+
+	// Entities created on login send events too
+	entity, created, err := is.CreateOrFetchEntity(ctx, &logical.Alias{
+		MountAccessor: upAccessor,
+		MountType:     "userpass",
+		Name:          "carol",
+	})
+	if err != nil || !created {
+		t.Fatalf("err:%v created:%v", err, created)
+	}
+	expectEvent(eventTypeEntityCreated, "entity_id", entity.ID)
+	expectEvent(eventTypeEntityAliasCreated, "alias_name", "carol")
+
+	handle(logical.DeleteOperation, "entity-alias/id/"+aliasID, nil)
+	expectEvent(eventTypeEntityAliasDeleted, "alias_id", aliasID)
+
+	handle(logical.DeleteOperation, "entity/id/"+entityID1, nil)
+	expectEvent(eventTypeEntityDeleted, "entity_id", entityID1)
+
+	// Events are not sent when nothing changes
+	handle(logical.DeleteOperation, "entity/id/"+entityID1, nil)
+	select {
+	case e := <-ch:
+		t.Fatalf("unexpected event: %#v", e.Payload)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,452 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/hashicorp/vault/helper/identity"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	groupSyncConfigPrefix = "group-sync/"
+
+	// groupSyncDirectoryPath is the path, relative to an auth mount, that
+	// lists the names of the groups in the mount's identity provider.
+	groupSyncDirectoryPath = "directory/groups/"
+
+	defaultGroupSyncInterval = time.Hour
+	minGroupSyncInterval     = time.Minute
+)
+
+// groupSyncConfig configures the periodic creation of external groups and
+// group aliases for the groups known to an auth method's identity provider.
+type groupSyncConfig struct {
+	MountAccessor string        `json:"mount_accessor"`
+	Interval      time.Duration `json:"interval"`
+
+	LastSyncTime  time.Time `json:"last_sync_time"`
+	LastSyncError string    `json:"last_sync_error"`
+}
+
+// groupSyncResult counts the outcome of a sync of an auth method's groups.
+type groupSyncResult struct {
+	// Created is the number of external groups created.
+	Created int
+	// Linked is the number of existing external groups given an alias.
+	Linked int
+	// Conflicts is the number of directory groups whose name is taken by an
+	// internal group or by an external group with an alias on another mount.
+	Conflicts int
+}
+
+func groupSyncPaths(i *IdentityStore) []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "group-sync/" + framework.GenericNameRegex("mount_accessor") + "/run$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "group-sync",
+				OperationVerb:   "run",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"mount_accessor": {
+					Type:        framework.TypeString,
+					Description: "Accessor of the auth method whose groups are synced.",
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: i.pathGroupSyncRun,
+					Summary:  "Sync the external groups of an auth method immediately.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(groupSyncHelp["group-sync-run"][0]),
+			HelpDescription: strings.TrimSpace(groupSyncHelp["group-sync-run"][1]),
+		},
+		{
+			Pattern: "group-sync/" + framework.GenericNameRegex("mount_accessor") + "$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "group-sync",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"mount_accessor": {
+					Type:        framework.TypeString,
+					Description: "Accessor of the auth method whose groups are synced.",
+				},
+				"interval": {
+					Type:        framework.TypeDurationSecond,
+					Description: "How often to sync the auth method's groups. Defaults to 1 hour.",
+					Default:     int(defaultGroupSyncInterval.Seconds()),
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: i.pathGroupSyncWrite,
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "configure",
+					},
+					Summary: "Enable syncing of the external groups of an auth method.",
+				},
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: i.pathGroupSyncRead,
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb:   "read",
+						OperationSuffix: "configuration",
+					},
+					Summary: "Read the group sync configuration and status of an auth method.",
+				},
+				logical.DeleteOperation: &framework.PathOperation{
+					Callback: i.pathGroupSyncDelete,
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb:   "delete",
+						OperationSuffix: "configuration",
+					},
+					Summary: "Disable syncing of the external groups of an auth method.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(groupSyncHelp["group-sync"][0]),
+			HelpDescription: strings.TrimSpace(groupSyncHelp["group-sync"][1]),
+		},
+		{
+			Pattern: "group-sync/?$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "group-sync",
+				OperationVerb:   "list",
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ListOperation: &framework.PathOperation{
+					Callback: i.pathGroupSyncList,
+					Summary:  "List the accessors of auth methods whose groups are synced.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(groupSyncHelp["group-sync-list"][0]),
+			HelpDescription: strings.TrimSpace(groupSyncHelp["group-sync-list"][1]),
+		},
+	}
+}
+
+func (i *IdentityStore) pathGroupSyncWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	mountAccessor := d.Get("mount_accessor").(string)
+	if err := i.validateGroupSyncMount(ctx, mountAccessor); err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+
+	config, err := i.getGroupSyncConfig(ctx, req.Storage, mountAccessor)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		config = &groupSyncConfig{
+			MountAccessor: mountAccessor,
+		}
+	}
+
+	config.Interval = time.Duration(d.Get("interval").(int)) * time.Second
+	if config.Interval < minGroupSyncInterval {
+		return logical.ErrorResponse("interval must be at least %s", minGroupSyncInterval), logical.ErrInvalidRequest
+	}
+
+	if err := i.putGroupSyncConfig(ctx, req.Storage, config); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (i *IdentityStore) pathGroupSyncRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	config, err := i.getGroupSyncConfig(ctx, req.Storage, d.Get("mount_accessor").(string))
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, nil
+	}
+
+	data := map[string]interface{}{
+		"mount_accessor":  config.MountAccessor,
+		"interval":        int64(config.Interval.Seconds()),
+		"last_sync_time":  "",
+		"last_sync_error": config.LastSyncError,
+	}
+	if !config.LastSyncTime.IsZero() {
+		data["last_sync_time"] = config.LastSyncTime.Format(time.RFC3339)
+	}
+
+	return &logical.Response{
+		Data: data,
+	}, nil
+}
+
+func (i *IdentityStore) pathGroupSyncDelete(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	return nil, req.Storage.Delete(ctx, groupSyncConfigPrefix+d.Get("mount_accessor").(string))
+}
+
+func (i *IdentityStore) pathGroupSyncList(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	keys, err := req.Storage.List(ctx, groupSyncConfigPrefix)
+	if err != nil {
+		return nil, err
+	}
+	return logical.ListResponse(keys), nil
+}
+
+func (i *IdentityStore) pathGroupSyncRun(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	config, err := i.getGroupSyncConfig(ctx, req.Storage, d.Get("mount_accessor").(string))
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return logical.ErrorResponse("group sync is not configured for this mount"), logical.ErrInvalidRequest
+	}
+
+	i.groupSyncLock.Lock()
+	defer i.groupSyncLock.Unlock()
+
+	result, err := i.runGroupSync(ctx, req.Storage, config)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"created":   result.Created,
+			"linked":    result.Linked,
+			"conflicts": result.Conflicts,
+		},
+	}, nil
+}
+
+func (i *IdentityStore) getGroupSyncConfig(ctx context.Context, s logical.Storage, mountAccessor string) (*groupSyncConfig, error) {
+	entry, err := s.Get(ctx, groupSyncConfigPrefix+mountAccessor)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var config groupSyncConfig
+	if err := entry.DecodeJSON(&config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (i *IdentityStore) putGroupSyncConfig(ctx context.Context, s logical.Storage, config *groupSyncConfig) error {
+	entry, err := logical.StorageEntryJSON(groupSyncConfigPrefix+config.MountAccessor, config)
+	if err != nil {
+		return err
+	}
+	return s.Put(ctx, entry)
+}
+
+// validateGroupSyncMount checks that the accessor refers to a replicated auth
+// method in the namespace of the request.
+func (i *IdentityStore) validateGroupSyncMount(ctx context.Context, mountAccessor string) error {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	mountEntry := i.router.MatchingMountByAccessor(mountAccessor)
+	switch {
+	case mountEntry == nil:
+		return fmt.Errorf("invalid mount accessor %q", mountAccessor)
+	case mountEntry.Table != credentialTableType:
+		return fmt.Errorf("mount accessor %q is not an auth method", mountAccessor)
+	case mountEntry.Local:
+		return fmt.Errorf("mount accessor %q is a local mount", mountAccessor)
+	case mountEntry.NamespaceID != ns.ID:
+		return errors.New("mount referenced via 'mount_accessor' not in the same namespace as the request")
+	}
+	return nil
+}
+
+// runGroupSync syncs the groups of the configured auth method and records the
+// outcome in the configuration. The caller must hold groupSyncLock.
+func (i *IdentityStore) runGroupSync(ctx context.Context, s logical.Storage, config *groupSyncConfig) (*groupSyncResult, error) {
+	result, err := i.syncExternalGroups(ctx, config.MountAccessor)
+
+	config.LastSyncTime = time.Now()
+	config.LastSyncError = ""
+	if err != nil {
+		config.LastSyncError = err.Error()
+	}
+	if putErr := i.putGroupSyncConfig(ctx, s, config); putErr != nil {
+		i.logger.Error("failed to persist group sync status", "mount_accessor", config.MountAccessor, "error", putErr)
+	}
+
+	return result, err
+}
+
+// syncExternalGroups lists the groups in the identity provider of the given
+// auth method, and ensures that each has an external group with a matching
+// group alias, so that policies can be assigned to the groups before any of
+// their members have logged in. Groups are matched to existing external
+// groups by name; groups are never removed.
+func (i *IdentityStore) syncExternalGroups(ctx context.Context, mountAccessor string) (*groupSyncResult, error) {
+	if err := i.validateGroupSyncMount(ctx, mountAccessor); err != nil {
+		return nil, err
+	}
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mountEntry := i.router.MatchingMountByAccessor(mountAccessor)
+
+	resp, err := i.router.Route(ctx, &logical.Request{
+		Operation: logical.ListOperation,
+		Path:      mountEntry.APIPathNoNamespace() + groupSyncDirectoryPath,
+	})
+	switch {
+	case errors.Is(err, logical.ErrUnsupportedPath):
+		return nil, fmt.Errorf("auth method %q does not support listing its group directory", mountEntry.Path)
+	case err != nil:
+		return nil, fmt.Errorf("failed to list groups of auth method %q: %w", mountEntry.Path, err)
+	case resp == nil:
+		return nil, fmt.Errorf("auth method %q returned no groups", mountEntry.Path)
+	case resp.IsError():
+		return nil, fmt.Errorf("failed to list groups of auth method %q: %w", mountEntry.Path, resp.Error())
+	}
+	names, _ := resp.Data["keys"].([]string)
+
+	i.groupLock.Lock()
+	defer i.groupLock.Unlock()
+
+	result := &groupSyncResult{}
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+
+		alias, err := i.MemDBAliasByFactors(mountAccessor, name, false, true)
+		if err != nil {
+			return result, err
+		}
+		if alias != nil {
+			continue
+		}
+
+		group, err := i.MemDBGroupByName(ctx, name, true)
+		if err != nil {
+			return result, err
+		}
+		switch {
+		case group == nil:
+			group = &identity.Group{
+				Name: name,
+				Type: groupTypeExternal,
+			}
+			result.Created++
+		case group.Type != groupTypeExternal || group.Alias != nil:
+			i.logger.Warn("not syncing group whose name is already in use", "name", name, "mount_accessor", mountAccessor)
+			result.Conflicts++
+			continue
+		default:
+			result.Linked++
+		}
+
+		now := ptypes.TimestampNow()
+		group.Alias = &identity.Alias{
+			Name:           name,
+			MountAccessor:  mountAccessor,
+			NamespaceID:    ns.ID,
+			CreationTime:   now,
+			LastUpdateTime: now,
+		}
+		if err := i.sanitizeAndUpsertGroup(ctx, group, nil, nil); err != nil {
+			return result, fmt.Errorf("failed to create group %q: %w", name, err)
+		}
+	}
+
+	i.logger.Debug("synced external groups", "mount_accessor", mountAccessor,
+		"groups", len(names), "created", result.Created, "linked", result.Linked, "conflicts", result.Conflicts)
+
+	return result, nil
+}
+
+// groupSyncPeriodicFunc runs the group syncs which are due.
+func (i *IdentityStore) groupSyncPeriodicFunc(ctx context.Context) {
+	// Syncs write groups, so only run this on the primary cluster. The
+	// periodic func does not run on perf standbys or DR secondaries.
+	if i.System().ReplicationState().HasState(consts.ReplicationPerformanceSecondary) {
+		return
+	}
+
+	if !i.groupSyncLock.TryLock() {
+		return
+	}
+	defer i.groupSyncLock.Unlock()
+
+	now := time.Now()
+	for _, ns := range i.namespacer.ListNamespaces(true) {
+		s := i.router.MatchingStorageByAPIPath(ctx, ns.Path+"identity/"+groupSyncConfigPrefix)
+		if s == nil {
+			continue
+		}
+
+		nsCtx := namespace.ContextWithNamespace(ctx, ns)
+		accessors, err := s.List(nsCtx, groupSyncConfigPrefix)
+		if err != nil {
+			i.logger.Error("failed to list group sync configurations", "namespace", ns.Path, "error", err)
+			continue
+		}
+
+		for _, accessor := range accessors {
+			config, err := i.getGroupSyncConfig(nsCtx, s, accessor)
+			if err != nil {
+				i.logger.Error("failed to read group sync configuration", "mount_accessor", accessor, "error", err)
+				continue
+			}
+			if config == nil || now.Sub(config.LastSyncTime) < config.Interval {
+				continue
+			}
+
+			if _, err := i.runGroupSync(nsCtx, s, config); err != nil {
+				i.logger.Warn("failed to sync external groups", "mount_accessor", accessor, "error", err)
+			}
+		}
+	}
+}
+
+var groupSyncHelp = map[string][2]string{
+	"group-sync": {
+		"Configure syncing of the external groups of an auth method.",
+		`
+When enabled, the groups in the identity provider of the auth method are
+periodically listed, and an external group with a group alias on the auth
+method is created for each group which doesn't already have one. This allows
+policies to be assigned to groups before any of their members have logged in.
+The auth method must support listing its groups, as the Okta auth method does
+when configured with an API token.
+		`,
+	},
+	"group-sync-list": {
+		"List the accessors of auth methods whose groups are synced.",
+		"",
+	},
+	"group-sync-run": {
+		"Sync the external groups of an auth method immediately.",
+		"",
+	},
+}
@@ -0,0 +1,120 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestIdentityStore_GroupSync(t *testing.T) {
+	ctx := namespace.RootContext(nil)
+	c, _, _ := TestCoreUnsealed(t)
+
+	c.credentialBackends["noop"] = func(context.Context, *logical.BackendConfig) (logical.Backend, error) {
+		return &NoopBackend{
+			BackendType: logical.TypeCredential,
+			Response:    logical.ListResponse([]string{"engineering", "existing-external", "existing-internal"}),
+		}, nil
+	}
+	me := &MountEntry{
+		Table: credentialTableType,
+		Path:  "directory/",
+		Type:  "noop",
+	}
+	if err := c.enableCredential(ctx, me); err != nil {
+		t.Fatal(err)
+	}
+	is := c.identityStore
+
+	for name, groupType := range map[string]string{
+		"existing-external": "external",
+		"existing-internal": "internal",
+	} {
+		resp, err := is.HandleRequest(ctx, &logical.Request{
+			Path:      "group",
+			Operation: logical.UpdateOperation,
+			Data: map[string]interface{}{
+				"name": name,
+				"type": groupType,
+			},
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("err: %v resp: %#v", err, resp)
+		}
+	}
+
+	configReq := &logical.Request{
+		Path:      "group-sync/" + me.Accessor,
+		Operation: logical.UpdateOperation,
+		Data: map[string]interface{}{
+			"interval": 30,
+		},
+	}
+	resp, err := is.HandleRequest(ctx, configReq)
+	if err != logical.ErrInvalidRequest {
+		t.Fatalf("expected an interval below the minimum to be rejected, got err: %v resp: %#v", err, resp)
+	}
+
+	configReq.Data["interval"] = "2h"
+	resp, err = is.HandleRequest(ctx, configReq)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v resp: %#v", err, resp)
+	}
+
+	resp, err = is.HandleRequest(ctx, &logical.Request{
+		Path:      "group-sync/" + me.Accessor + "/run",
+		Operation: logical.UpdateOperation,
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v resp: %#v", err, resp)
+	}
+	if resp.Data["created"] != 1 || resp.Data["linked"] != 1 || resp.Data["conflicts"] != 1 {
+		t.Fatalf("unexpected sync result: %#v", resp.Data)
+	}
+
+	for _, name := range []string{"engineering", "existing-external"} {
+		group, err := is.MemDBGroupByName(ctx, name, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if group == nil || group.Type != groupTypeExternal {
+			t.Fatalf("expected external group %q, got %#v", name, group)
+		}
+		if group.Alias == nil || group.Alias.Name != name || group.Alias.MountAccessor != me.Accessor || group.Alias.ID == "" {
+			t.Fatalf("expected group %q to have an alias on the mount, got %#v", name, group.Alias)
+		}
+	}
+
+	group, err := is.MemDBGroupByName(ctx, "existing-internal", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if group.Alias != nil {
+		t.Fatalf("expected internal group to be left alone, got alias %#v", group.Alias)
+	}
+
+	// A second sync finds the aliases and changes nothing.
+	result, err := is.syncExternalGroups(ctx, me.Accessor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *result != (groupSyncResult{Conflicts: 1}) {
+		t.Fatalf("unexpected sync result: %#v", result)
+	}
+
+	resp, err = is.HandleRequest(ctx, &logical.Request{
+		Path:      "group-sync/" + me.Accessor,
+		Operation: logical.ReadOperation,
+	})
+	if err != nil || resp == nil {
+		t.Fatalf("err: %v resp: %#v", err, resp)
+	}
+	if resp.Data["interval"] != int64(7200) || resp.Data["last_sync_time"] == "" || resp.Data["last_sync_error"] != "" {
+		t.Fatalf("unexpected config: %#v", resp.Data)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/hashicorp/vault/helper/identity"
 	"github.com/hashicorp/vault/helper/namespace"
 	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/custommetadata"
 	"github.com/hashicorp/vault/sdk/logical"
 )
 
@@ -43,6 +44,11 @@ For example:
 vault <command> <path> metadata=key1=value1 metadata=key2=value2
 					`,
 		},
+		"custom_metadata": {
+			Type: framework.TypeKVPairs,
+			Description: `User provided key-value pairs to be associated with the group,
+such as team or cost center tags, distinct from metadata.`,
+		},
 		"policies": {
 			Type:        framework.TypeCommaStringSlice,
 			Description: "Policies to be tied to the group.",
@@ -304,6 +310,17 @@ func (i *IdentityStore) handleGroupUpdateCommon(ctx context.Context, req *logica
 		group.Metadata = metadata.(map[string]string)
 	}
 
+	customMetadata, ok, err := d.GetOkErr("custom_metadata")
+	if err != nil {
+		return logical.ErrorResponse(fmt.Sprintf("failed to parse custom_metadata: %v", err)), nil
+	}
+	if ok {
+		if err := custommetadata.Validate(customMetadata.(map[string]string)); err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+		group.CustomMetadata = customMetadata.(map[string]string)
+	}
+
 	memberEntityIDsRaw, ok := d.GetOk("member_entity_ids")
 	if ok {
 		if group.Type == groupTypeExternal {
@@ -401,6 +418,7 @@ func (i *IdentityStore) handleGroupReadCommon(ctx context.Context, group *identi
 	respData["member_entity_ids"] = group.MemberEntityIDs
 	respData["parent_group_ids"] = group.ParentGroupIDs
 	respData["metadata"] = group.Metadata
+	respData["custom_metadata"] = group.CustomMetadata
 	respData["creation_time"] = ptypes.TimestampString(group.CreationTime)
 	respData["last_update_time"] = ptypes.TimestampString(group.LastUpdateTime)
 	respData["modify_index"] = group.ModifyIndex
@@ -75,6 +75,72 @@ func TestIdentityStore_Groups_AddByNameEntityUpdate(t *testing.T) {
 	}
 }
 
+func TestIdentityStore_GroupCustomMetadata(t *testing.T) {
+	c, _, _ := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	resp, err := c.identityStore.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "group",
+		Data: map[string]interface{}{
+			"name":            "payments",
+			"metadata":        []string{"owner=vault"},
+			"custom_metadata": []string{"team=payments", "cost_center=cc-42"},
+		},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("bad: resp: %#v, err: %v", resp, err)
+	}
+	groupID := resp.Data["id"].(string)
+
+	readReq := &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "group/id/" + groupID,
+	}
+	resp, err = c.identityStore.HandleRequest(ctx, readReq)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("bad: resp: %#v, err: %v", resp, err)
+	}
+	expected := map[string]string{"team": "payments", "cost_center": "cc-42"}
+	if !reflect.DeepEqual(resp.Data["custom_metadata"], expected) {
+		t.Fatalf("bad: custom metadata: %#v", resp.Data["custom_metadata"])
+	}
+	if !reflect.DeepEqual(resp.Data["metadata"], map[string]string{"owner": "vault"}) {
+		t.Fatalf("bad: metadata: %#v", resp.Data["metadata"])
+	}
+
+	// Updating other fields leaves the custom metadata untouched
+	resp, err = c.identityStore.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "group/id/" + groupID,
+		Data: map[string]interface{}{
+			"policies": "default",
+		},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("bad: resp: %#v, err: %v", resp, err)
+	}
+	resp, err = c.identityStore.HandleRequest(ctx, readReq)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("bad: resp: %#v, err: %v", resp, err)
+	}
+	if !reflect.DeepEqual(resp.Data["custom_metadata"], expected) {
+		t.Fatalf("bad: custom metadata: %#v", resp.Data["custom_metadata"])
+	}
+
+	// Invalid custom metadata is rejected
+	resp, err = c.identityStore.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "group/id/" + groupID,
+		Data: map[string]interface{}{
+			"custom_metadata": map[string]string{"team": strings.Repeat("a", 1000)},
+		},
+	})
+	if err != nil || resp == nil || !resp.IsError() {
+		t.Fatalf("expected an error, got resp: %#v, err: %v", resp, err)
+	}
+}
+
 func TestIdentityStore_FixOverwrittenMemberGroupIDs(t *testing.T) {
 	c, _, _ := TestCoreUnsealed(t)
 	ctx := namespace.RootContext(nil)
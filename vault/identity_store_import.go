@@ -0,0 +1,401 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/vault/helper/identity"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/custommetadata"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// importPaths returns the API endpoints creating entities, entity aliases and
+// groups with caller-specified IDs, so that a rebuilt or migrated cluster can
+// preserve the IDs referenced by external systems and templated policies, and
+// the endpoints exporting and importing entities in bulk. These endpoints
+// require sudo capability.
+func importPaths(i *IdentityStore) []*framework.Path {
+	entityFields := entityPathFields()
+	entityFields["id"] = &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Description: "ID of the imported entity. Must be a UUID which no other entity uses. Required unless entities are imported in bulk.",
+	}
+	entityFields["entities"] = &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Description: "Entities to import in bulk, as the JSON lines returned by the export endpoint. If set, the other entity parameters are ignored.",
+	}
+	entityFields["dry_run"] = &framework.FieldSchema{
+		Type:        framework.TypeBool,
+		Description: "If set, the entities imported in bulk are validated and the outcome of their import is returned, but nothing is persisted.",
+	}
+	entityFields["conflict_strategy"] = &framework.FieldSchema{
+		Type:          framework.TypeString,
+		Description:   "How entities imported in bulk which already exist, by ID or name, are handled: 'skip' leaves them unchanged, 'overwrite' replaces their name, metadata, policies and disabled state, and 'merge' adds the imported policies and metadata to theirs. Aliases and group memberships are added with 'overwrite' and 'merge'.",
+		Default:       entityImportConflictSkip,
+		AllowedValues: []interface{}{entityImportConflictSkip, entityImportConflictOverwrite, entityImportConflictMerge},
+	}
+
+	groupFields := groupPathFields()
+	groupFields["id"] = &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Description: "ID of the imported group. Must be a UUID which no other group uses.",
+		Required:    true,
+	}
+
+	return []*framework.Path{
+		{
+			Pattern: "entity/import$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "entity",
+				OperationVerb:   "import",
+			},
+
+			Fields: entityFields,
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: i.pathEntityImport(),
+					Summary:  "Create an entity with the given ID, or import entities in bulk.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(importHelp["entity"][0]),
+			HelpDescription: strings.TrimSpace(importHelp["entity"][1]),
+		},
+		{
+			Pattern: "entity/export$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "entity",
+				OperationVerb:   "export",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"after": {
+					Type:        framework.TypeString,
+					Description: "The cursor returned as next_cursor by the previous page of the export. If unset, the export starts with the first entity.",
+				},
+				"limit": {
+					Type:        framework.TypeInt,
+					Description: fmt.Sprintf("The maximum number of entities of the page, at most %d.", entityExportMaxLimit),
+					Default:     entityExportDefaultLimit,
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: i.pathEntityExport(),
+					Summary:  "Export a page of the entities of the namespace, with their aliases and group memberships.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(importHelp["entity-export"][0]),
+			HelpDescription: strings.TrimSpace(importHelp["entity-export"][1]),
+		},
+		{
+			Pattern: "entity-alias/import$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "entity",
+				OperationVerb:   "import",
+				OperationSuffix: "alias",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"id": {
+					Type:        framework.TypeString,
+					Description: "ID of the imported entity alias. Must be a UUID which no other alias uses.",
+					Required:    true,
+				},
+				"canonical_id": {
+					Type:        framework.TypeString,
+					Description: "Entity ID to which this alias belongs",
+					Required:    true,
+				},
+				"mount_accessor": {
+					Type:        framework.TypeString,
+					Description: "Mount accessor to which this alias belongs to",
+					Required:    true,
+				},
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Name of the alias",
+					Required:    true,
+				},
+				"custom_metadata": {
+					Type:        framework.TypeKVPairs,
+					Description: "User provided key-value pairs",
+				},
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: i.pathAliasImport(),
+					Summary:  "Create an entity alias with the given ID.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(importHelp["entity-alias"][0]),
+			HelpDescription: strings.TrimSpace(importHelp["entity-alias"][1]),
+		},
+		{
+			Pattern: "group/import$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "group",
+				OperationVerb:   "import",
+			},
+
+			Fields: groupFields,
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback:                  i.pathGroupImport(),
+					ForwardPerformanceStandby: true,
+					Summary:                   "Create a group with the given ID.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(importHelp["group"][0]),
+			HelpDescription: strings.TrimSpace(importHelp["group"][1]),
+		},
+	}
+}
+
+// validateImportID returns an error response if the ID cannot be used for an
+// imported object.
+func validateImportID(id string) *logical.Response {
+	if id == "" {
+		return logical.ErrorResponse("missing id")
+	}
+	if _, err := uuid.ParseUUID(id); err != nil {
+		return logical.ErrorResponse(fmt.Sprintf("id %q is not a valid UUID", id))
+	}
+	return nil
+}
+
+// pathEntityImport creates an entity with the ID of the request, or imports
+// the entities of the request in bulk.
+func (i *IdentityStore) pathEntityImport() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		if _, ok := d.GetOk("entities"); ok {
+			return i.handleEntityBulkImport(ctx, d)
+		}
+
+		entityID := d.Get("id").(string)
+		if resp := validateImportID(entityID); resp != nil {
+			return resp, nil
+		}
+
+		i.lock.Lock()
+		defer i.lock.Unlock()
+
+		// IDs are unique across namespaces.
+		existing, err := i.MemDBEntityByID(entityID, false)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return logical.ErrorResponse("an entity with the given id already exists"), nil
+		}
+
+		entity := &identity.Entity{
+			ID:        entityID,
+			BucketKey: i.entityPacker.BucketKey(entityID),
+		}
+		return i.handleEntityUpdate(ctx, d, entity, true)
+	}
+}
+
+// pathAliasImport creates an entity alias with the ID of the request. Aliases
+// of local mounts cannot be imported.
+func (i *IdentityStore) pathAliasImport() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		ns, err := namespace.FromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		aliasID := d.Get("id").(string)
+		if resp := validateImportID(aliasID); resp != nil {
+			return resp, nil
+		}
+
+		canonicalID := d.Get("canonical_id").(string)
+		mountAccessor := d.Get("mount_accessor").(string)
+		name := d.Get("name").(string)
+		if canonicalID == "" || mountAccessor == "" || name == "" {
+			return logical.ErrorResponse("'canonical_id', 'mount_accessor' and 'name' must be provided"), nil
+		}
+
+		customMetadata := make(map[string]string)
+		if data, ok := d.GetOk("custom_metadata"); ok {
+			customMetadata = data.(map[string]string)
+			if err := custommetadata.Validate(customMetadata); err != nil {
+				return nil, err
+			}
+		}
+
+		mountEntry := i.router.MatchingMountByAccessor(mountAccessor)
+		if mountEntry == nil {
+			return logical.ErrorResponse(fmt.Sprintf("invalid mount accessor %q", mountAccessor)), nil
+		}
+		if mountEntry.NamespaceID != ns.ID {
+			return logical.ErrorResponse("matching mount is in a different namespace than request"), logical.ErrPermissionDenied
+		}
+		if mountEntry.Local {
+			return logical.ErrorResponse("aliases of local mounts cannot be imported"), nil
+		}
+
+		i.lock.Lock()
+		defer i.lock.Unlock()
+
+		existing, err := i.MemDBAliasByID(aliasID, false, false)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return logical.ErrorResponse("an entity alias with the given id already exists"), nil
+		}
+		existing, err = i.MemDBAliasByFactors(mountAccessor, name, false, false)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return logical.ErrorResponse("an entity alias with the given mount accessor and name already exists"), nil
+		}
+
+		entity, err := i.MemDBEntityByID(canonicalID, true)
+		if err != nil {
+			return nil, err
+		}
+		if entity == nil {
+			return logical.ErrorResponse("invalid canonical ID"), nil
+		}
+		if entity.NamespaceID != ns.ID {
+			return logical.ErrorResponse("entity found with 'canonical_id' not in request namespace"), logical.ErrPermissionDenied
+		}
+		for _, currentAlias := range entity.Aliases {
+			if currentAlias.MountAccessor == mountAccessor {
+				return logical.ErrorResponse("Alias already exists for requested entity and mount accessor"), nil
+			}
+		}
+
+		alias := &identity.Alias{
+			ID:             aliasID,
+			LocalBucketKey: i.localAliasPacker.BucketKey(canonicalID),
+			MountAccessor:  mountAccessor,
+			Name:           name,
+			CustomMetadata: customMetadata,
+			CanonicalID:    entity.ID,
+		}
+		if err := i.sanitizeAlias(ctx, alias); err != nil {
+			return nil, err
+		}
+		entity.UpsertAlias(alias)
+
+		if err := i.upsertEntity(ctx, entity, nil, true); err != nil {
+			return nil, err
+		}
+
+		return &logical.Response{
+			Data: map[string]interface{}{
+				"id":           alias.ID,
+				"canonical_id": entity.ID,
+			},
+		}, nil
+	}
+}
+
+// pathGroupImport creates a group with the ID of the request.
+func (i *IdentityStore) pathGroupImport() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		groupID := d.Get("id").(string)
+		if resp := validateImportID(groupID); resp != nil {
+			return resp, nil
+		}
+
+		i.groupLock.Lock()
+		defer i.groupLock.Unlock()
+
+		existing, err := i.MemDBGroupByID(groupID, false)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return logical.ErrorResponse("a group with the given id already exists"), nil
+		}
+
+		group := &identity.Group{
+			ID:        groupID,
+			BucketKey: i.groupPacker.BucketKey(groupID),
+		}
+		resp, err := i.handleGroupUpdateCommon(ctx, req, d, group)
+		if err != nil || resp != nil {
+			return resp, err
+		}
+
+		return &logical.Response{
+			Data: map[string]interface{}{
+				"id":   group.ID,
+				"name": group.Name,
+			},
+		}, nil
+	}
+}
+
+var importHelp = map[string][2]string{
+	"entity": {
+		"Create an entity with a caller-specified ID, or import entities in bulk.",
+		`
+This path creates an entity with the given ID instead of a generated one, so
+that a rebuilt or migrated cluster can preserve the entity IDs referenced by
+external systems and templated policies. The ID must be a UUID which no other
+entity uses.
+
+If 'entities' is set, this path instead imports the entities it holds, as
+returned by the entity/export path, with their IDs, aliases and group
+memberships. The mounts of aliases are found by accessor, or else by path, and
+groups by ID, or else by name. Each entity is imported on its own and its
+outcome is returned, so an entity which cannot be imported does not prevent
+the others from being, and other identity writes are not blocked for the
+whole import. 'conflict_strategy' sets how existing entities are
+handled, and 'dry_run' validates the import without persisting anything.
+
+This path requires sudo capability.
+		`,
+	},
+	"entity-export": {
+		"Export the entities of the namespace in bulk.",
+		`
+This path returns a page of the entities of the namespace, with their aliases
+and the groups they are direct members of, as JSON lines which can be imported
+with the entity/import path. Entities are returned in the order of their
+names. Unless the page is the last one, 'next_cursor' is returned, and the
+next page is read by passing it as 'after'. Aliases of local mounts are not
+exported. This path requires sudo capability.
+		`,
+	},
+	"entity-alias": {
+		"Create an entity alias with a caller-specified ID.",
+		`
+This path creates an entity alias with the given ID instead of a generated
+one. The ID must be a UUID which no other alias uses. Aliases of local mounts
+cannot be imported. This path requires sudo capability.
+		`,
+	},
+	"group": {
+		"Create a group with a caller-specified ID.",
+		`
+This path creates a group with the given ID instead of a generated one, so
+that a rebuilt or migrated cluster can preserve the group IDs referenced by
+external systems and templated policies. The ID must be a UUID which no other
+group uses. This path requires sudo capability.
+		`,
+	},
+}
@@ -0,0 +1,284 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestIdentityStore_Import(t *testing.T) {
+	ctx := namespace.RootContext(nil)
+	is, ghAccessor, c := testIdentityStoreWithGithubAuth(ctx, t)
+
+	for _, path := range []string{"entity/import", "entity-alias/import", "group/import"} {
+		if !c.router.RootPath(ctx, "identity/"+path) {
+			t.Fatalf("expected %q to require sudo", path)
+		}
+	}
+
+	request := func(path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := is.HandleRequest(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      path,
+			Data:      data,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	requireError := func(resp *logical.Response) {
+		t.Helper()
+		if resp == nil || !resp.IsError() {
+			t.Fatalf("expected an error, got: %#v", resp)
+		}
+	}
+
+	const (
+		entityID = "5b1cf2a1-3d0e-4b6f-9c5e-0b8f8d6f5b10"
+		aliasID  = "0f1e3c4a-6b5d-4e7f-8a9b-1c2d3e4f5a6b"
+		groupID  = "9a8b7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d"
+	)
+
+	// IDs must be UUIDs.
+	requireError(request("entity/import", map[string]interface{}{"id": "not-a-uuid"}))
+	requireError(request("entity/import", map[string]interface{}{"name": "imported"}))
+
+	resp := request("entity/import", map[string]interface{}{
+		"id":       entityID,
+		"name":     "imported",
+		"policies": []string{"default"},
+	})
+	if resp == nil || resp.IsError() || resp.Data["id"] != entityID || resp.Data["name"] != "imported" {
+		t.Fatalf("unexpected response: %#v", resp)
+	}
+	entity, err := is.MemDBEntityByID(entityID, false)
+	if err != nil || entity == nil {
+		t.Fatalf("expected the entity to be created; err: %v", err)
+	}
+
+	// IDs cannot be reused.
+	requireError(request("entity/import", map[string]interface{}{"id": entityID}))
+
+	resp = request("entity-alias/import", map[string]interface{}{
+		"id":             aliasID,
+		"canonical_id":   entityID,
+		"mount_accessor": ghAccessor,
+		"name":           "githubuser",
+	})
+	if resp == nil || resp.IsError() || resp.Data["id"] != aliasID || resp.Data["canonical_id"] != entityID {
+		t.Fatalf("unexpected response: %#v", resp)
+	}
+	alias, err := is.MemDBAliasByID(aliasID, false, false)
+	if err != nil || alias == nil || alias.CanonicalID != entityID {
+		t.Fatalf("expected the alias to be created; alias: %#v, err: %v", alias, err)
+	}
+	requireError(request("entity-alias/import", map[string]interface{}{
+		"id":             aliasID,
+		"canonical_id":   entityID,
+		"mount_accessor": ghAccessor,
+		"name":           "otheruser",
+	}))
+
+	resp = request("group/import", map[string]interface{}{
+		"id":                groupID,
+		"name":              "imported-group",
+		"member_entity_ids": []string{entityID},
+	})
+	if resp == nil || resp.IsError() || resp.Data["id"] != groupID {
+		t.Fatalf("unexpected response: %#v", resp)
+	}
+	group, err := is.MemDBGroupByID(groupID, false)
+	if err != nil || group == nil || group.Name != "imported-group" {
+		t.Fatalf("expected the group to be created; group: %#v, err: %v", group, err)
+	}
+	requireError(request("group/import", map[string]interface{}{"id": groupID}))
+}
+
+func TestIdentityStore_BulkImportExport(t *testing.T) {
+	ctx := namespace.RootContext(nil)
+	is, ghAccessor, c := testIdentityStoreWithGithubAuth(ctx, t)
+
+	if !c.router.RootPath(ctx, "identity/entity/export") {
+		t.Fatal("expected entity/export to require sudo")
+	}
+
+	request := func(op logical.Operation, path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := is.HandleRequest(ctx, &logical.Request{
+			Operation: op,
+			Path:      path,
+			Data:      data,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("err: %v, resp: %#v", err, resp)
+		}
+		return resp
+	}
+
+	resp := request(logical.UpdateOperation, "group", map[string]interface{}{"name": "devs"})
+	groupID := resp.Data["id"].(string)
+
+	resp = request(logical.UpdateOperation, "entity", map[string]interface{}{
+		"name":     "alice",
+		"policies": []string{"dev"},
+		"metadata": []string{"team=core"},
+	})
+	entityID := resp.Data["id"].(string)
+
+	resp = request(logical.UpdateOperation, "entity-alias", map[string]interface{}{
+		"name":           "alice-gh",
+		"canonical_id":   entityID,
+		"mount_accessor": ghAccessor,
+	})
+	aliasID := resp.Data["id"].(string)
+
+	request(logical.UpdateOperation, "group/id/"+groupID, map[string]interface{}{
+		"member_entity_ids": []string{entityID},
+	})
+
+	resp = request(logical.ReadOperation, "entity/export", nil)
+	if resp.Data["count"] != 1 {
+		t.Fatalf("expected one exported entity, got: %#v", resp.Data)
+	}
+	exported := resp.Data["entities"].(string)
+
+	request(logical.DeleteOperation, "entity/id/"+entityID, nil)
+
+	importEntities := func(entities, strategy string, dryRun bool) map[string]interface{} {
+		t.Helper()
+		resp := request(logical.UpdateOperation, "entity/import", map[string]interface{}{
+			"entities":          entities,
+			"conflict_strategy": strategy,
+			"dry_run":           dryRun,
+		})
+		return resp.Data["results"].([]map[string]interface{})[0]
+	}
+
+	// Dry runs don't persist anything.
+	result := importEntities(exported, "skip", true)
+	if result["status"] != "created" || result["id"] != entityID {
+		t.Fatalf("unexpected result: %#v", result)
+	}
+	if entity, err := is.MemDBEntityByID(entityID, false); err != nil || entity != nil {
+		t.Fatalf("expected no entity after a dry run; entity: %#v, err: %v", entity, err)
+	}
+
+	// The entity is imported with its ID, alias and group membership.
+	result = importEntities(exported, "skip", false)
+	if result["status"] != "created" {
+		t.Fatalf("unexpected result: %#v", result)
+	}
+	entity, err := is.MemDBEntityByID(entityID, false)
+	if err != nil || entity == nil || entity.Name != "alice" || entity.Metadata["team"] != "core" {
+		t.Fatalf("expected the entity to be imported; entity: %#v, err: %v", entity, err)
+	}
+	if len(entity.Aliases) != 1 || entity.Aliases[0].ID != aliasID || entity.Aliases[0].MountAccessor != ghAccessor {
+		t.Fatalf("expected the alias to be imported; aliases: %#v", entity.Aliases)
+	}
+	group, err := is.MemDBGroupByID(groupID, false)
+	if err != nil || group == nil || len(group.MemberEntityIDs) != 1 || group.MemberEntityIDs[0] != entityID {
+		t.Fatalf("expected the group membership to be imported; group: %#v, err: %v", group, err)
+	}
+
+	changed := `{"id":"` + entityID + `","name":"alice","policies":["ops"],"metadata":{"team":"infra","site":"eu"}}`
+
+	result = importEntities(changed, "skip", false)
+	if result["status"] != "skipped" {
+		t.Fatalf("unexpected result: %#v", result)
+	}
+
+	result = importEntities(changed, "merge", false)
+	if result["status"] != "updated" {
+		t.Fatalf("unexpected result: %#v", result)
+	}
+	entity, _ = is.MemDBEntityByID(entityID, false)
+	if len(entity.Policies) != 2 || entity.Metadata["team"] != "core" || entity.Metadata["site"] != "eu" {
+		t.Fatalf("unexpected merged entity: %#v", entity)
+	}
+
+	result = importEntities(changed, "overwrite", false)
+	if result["status"] != "updated" {
+		t.Fatalf("unexpected result: %#v", result)
+	}
+	entity, _ = is.MemDBEntityByID(entityID, false)
+	if len(entity.Policies) != 1 || entity.Policies[0] != "ops" || entity.Metadata["team"] != "infra" {
+		t.Fatalf("unexpected overwritten entity: %#v", entity)
+	}
+
+	// Aliases of other entities aren't taken over.
+	result = importEntities(`{"name":"bob","aliases":[{"name":"alice-gh","mount_accessor":"`+ghAccessor+`"}]}`, "skip", false)
+	if result["status"] != "error" {
+		t.Fatalf("unexpected result: %#v", result)
+	}
+	if entity, err := is.MemDBEntityByName(ctx, "bob", false); err != nil || entity != nil {
+		t.Fatalf("expected no entity to be created; entity: %#v, err: %v", entity, err)
+	}
+}
+
+func TestIdentityStore_EntityExportPages(t *testing.T) {
+	ctx := namespace.RootContext(nil)
+	is, _, _ := testIdentityStoreWithGithubAuth(ctx, t)
+
+	names := []string{"Alice", "bob", "carol", "Dave", "erin"}
+	for _, name := range names {
+		resp, err := is.HandleRequest(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "entity",
+			Data:      map[string]interface{}{"name": name},
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("err: %v, resp: %#v", err, resp)
+		}
+	}
+
+	// The pages hold the entities in the order of their names, and the last
+	// page has no cursor.
+	var exported []string
+	var pages int
+	after := ""
+	for {
+		resp, err := is.HandleRequest(ctx, &logical.Request{
+			Operation: logical.ReadOperation,
+			Path:      "entity/export",
+			Data:      map[string]interface{}{"after": after, "limit": 2},
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("err: %v, resp: %#v", err, resp)
+		}
+		pages++
+		for _, line := range strings.Split(resp.Data["entities"].(string), "\n") {
+			record := &entityTransferRecord{}
+			if err := json.Unmarshal([]byte(line), record); err != nil {
+				t.Fatal(err)
+			}
+			exported = append(exported, record.Name)
+		}
+
+		cursor, ok := resp.Data["next_cursor"].(string)
+		if !ok {
+			break
+		}
+		after = cursor
+	}
+	if pages != 3 || !reflect.DeepEqual(exported, names) {
+		t.Fatalf("expected %v in 3 pages, got %v in %d pages", names, exported, pages)
+	}
+
+	resp, err := is.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "entity/export",
+		Data:      map[string]interface{}{"limit": entityExportMaxLimit + 1},
+	})
+	if err != nil || resp == nil || !resp.IsError() {
+		t.Fatalf("expected an error for an invalid limit; err: %v, resp: %#v", err, resp)
+	}
+}
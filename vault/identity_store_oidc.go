@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"math"
 	mathrand "math/rand"
+	"net/http"
 	"net/url"
 	"sort"
 	"strings"
@@ -873,49 +874,70 @@ func (i *IdentityStore) pathOIDCKeyExistenceCheck(ctx context.Context, req *logi
 
 // handleOIDCGenerateSignToken generates and signs an OIDC token
 func (i *IdentityStore) pathOIDCGenerateToken(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
-	ns, err := namespace.FromContext(ctx)
+	// generate an OIDC token from entity data
+	if req.EntityID == "" {
+		return logical.ErrorResponse("no entity associated with the request's token"), nil
+	}
+
+	signedIdToken, role, warnings, err := i.generateIdentityToken(ctx, req.Storage, d.Get("name").(string), req.EntityID)
 	if err != nil {
+		if coded, ok := err.(logical.HTTPCodedError); ok && coded.Code() == http.StatusBadRequest {
+			return logical.ErrorResponse(coded.Error()), nil
+		}
 		return nil, err
 	}
 
-	roleName := d.Get("name").(string)
+	retResp := &logical.Response{
+		Warnings: warnings,
+	}
+	retResp.Data = map[string]interface{}{
+		"token":     signedIdToken,
+		"client_id": role.ClientID,
+		"ttl":       int64(role.TokenTTL.Seconds()),
+	}
+	return retResp, nil
+}
 
-	role, err := i.getOIDCRole(ctx, req.Storage, roleName)
+// generateIdentityToken returns an identity token for the entity, issued
+// through the named role in the namespace of the context, along with the role
+// and warnings about the token. Invalid roles are reported as coded errors.
+func (i *IdentityStore) generateIdentityToken(ctx context.Context, s logical.Storage, roleName, entityID string) (string, *role, []string, error) {
+	ns, err := namespace.FromContext(ctx)
 	if err != nil {
-		return nil, err
+		return "", nil, nil, err
+	}
+
+	role, err := i.getOIDCRole(ctx, s, roleName)
+	if err != nil {
+		return "", nil, nil, err
 	}
 	if role == nil {
-		return logical.ErrorResponse("role %q not found", roleName), nil
+		return "", nil, nil, logical.CodedError(http.StatusBadRequest, fmt.Sprintf("role %q not found", roleName))
 	}
 
-	key, err := i.getNamedKey(ctx, req.Storage, role.Key)
+	key, err := i.getNamedKey(ctx, s, role.Key)
 	if err != nil {
-		return nil, err
+		return "", nil, nil, err
 	}
 	if key == nil {
-		return logical.ErrorResponse("key %q not found", role.Key), nil
+		return "", nil, nil, logical.CodedError(http.StatusBadRequest, fmt.Sprintf("key %q not found", role.Key))
 	}
 
 	// Validate that the role is allowed to sign with its key (the key could have been updated)
 	if !strutil.StrListContains(key.AllowedClientIDs, "*") && !strutil.StrListContains(key.AllowedClientIDs, role.ClientID) {
-		return logical.ErrorResponse("the key %q does not list the client ID of the role %q as an allowed client ID", role.Key, roleName), nil
+		return "", nil, nil, logical.CodedError(http.StatusBadRequest, fmt.Sprintf("the key %q does not list the client ID of the role %q as an allowed client ID", role.Key, roleName))
 	}
 
-	// generate an OIDC token from entity data
-	if req.EntityID == "" {
-		return logical.ErrorResponse("no entity associated with the request's token"), nil
-	}
-
-	config, err := i.getOIDCConfig(ctx, req.Storage)
+	config, err := i.getOIDCConfig(ctx, s)
 	if err != nil {
-		return nil, err
+		return "", nil, nil, err
 	}
 
-	retResp := &logical.Response{}
+	var warnings []string
 	expiry := role.TokenTTL
 	if expiry > key.VerificationTTL {
 		expiry = key.VerificationTTL
-		retResp.AddWarning(fmt.Sprintf("a role's token ttl cannot be longer "+
+		warnings = append(warnings, fmt.Sprintf("a role's token ttl cannot be longer "+
 			"than the verification_ttl of the key it references, setting token ttl to %d", expiry))
 	}
 
@@ -923,23 +945,23 @@ func (i *IdentityStore) pathOIDCGenerateToken(ctx context.Context, req *logical.
 	idToken := idToken{
 		Issuer:    config.effectiveIssuer,
 		Namespace: ns.ID,
-		Subject:   req.EntityID,
+		Subject:   entityID,
 		Audience:  role.ClientID,
 		Expiry:    now.Add(expiry).Unix(),
 		IssuedAt:  now.Unix(),
 	}
 
-	e, err := i.MemDBEntityByID(req.EntityID, true)
+	e, err := i.MemDBEntityByID(entityID, true)
 	if err != nil {
-		return nil, err
+		return "", nil, nil, err
 	}
 	if e == nil {
-		return nil, fmt.Errorf("error loading entity ID %q", req.EntityID)
+		return "", nil, nil, fmt.Errorf("error loading entity ID %q", entityID)
 	}
 
 	groups, inheritedGroups, err := i.groupsByEntityID(e.ID)
 	if err != nil {
-		return nil, err
+		return "", nil, nil, err
 	}
 
 	groups = append(groups, inheritedGroups...)
@@ -965,15 +987,10 @@ func (i *IdentityStore) pathOIDCGenerateToken(ctx context.Context, req *logical.
 
 	signedIdToken, err := key.signPayload(payload)
 	if err != nil {
-		return nil, fmt.Errorf("error signing OIDC token: %w", err)
+		return "", nil, nil, fmt.Errorf("error signing OIDC token: %w", err)
 	}
 
-	retResp.Data = map[string]interface{}{
-		"token":     signedIdToken,
-		"client_id": role.ClientID,
-		"ttl":       int64(role.TokenTTL.Seconds()),
-	}
-	return retResp, nil
+	return signedIdToken, role, warnings, nil
 }
 
 func (i *IdentityStore) getNamedKey(ctx context.Context, s logical.Storage, name string) (*namedKey, error) {
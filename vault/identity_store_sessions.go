@@ -0,0 +1,221 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/helper/identity"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// entitySessionsRecentLogins is the number of logins returned by the sessions
+// endpoint of an entity.
+const entitySessionsRecentLogins = 10
+
+func entitySessionPaths(i *IdentityStore) []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "entity/id/" + framework.GenericNameRegex("id") + "/sessions$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "entity",
+				OperationVerb:   "read",
+				OperationSuffix: "sessions",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"id": {
+					Type:        framework.TypeString,
+					Description: "ID of the entity.",
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: i.pathEntitySessionsRead,
+					Summary:  "List the active tokens, leases and recent logins of an entity.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(entitySessionsHelp["entity-sessions"][0]),
+			HelpDescription: strings.TrimSpace(entitySessionsHelp["entity-sessions"][1]),
+		},
+		{
+			Pattern: "entity/id/" + framework.GenericNameRegex("id") + "/sessions/revoke$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "entity",
+				OperationVerb:   "revoke",
+				OperationSuffix: "sessions",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"id": {
+					Type:        framework.TypeString,
+					Description: "ID of the entity.",
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: i.pathEntitySessionsRevoke,
+					Summary:  "Revoke all the active tokens of an entity, along with their leases.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(entitySessionsHelp["entity-sessions-revoke"][0]),
+			HelpDescription: strings.TrimSpace(entitySessionsHelp["entity-sessions-revoke"][1]),
+		},
+	}
+}
+
+// sessionsEntity returns the entity of the request, if it exists in the
+// namespace of the request.
+func (i *IdentityStore) sessionsEntity(ctx context.Context, d *framework.FieldData) (*identity.Entity, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entity, err := i.MemDBEntityByID(d.Get("id").(string), false)
+	if err != nil {
+		return nil, err
+	}
+	if entity == nil || entity.NamespaceID != ns.ID {
+		return nil, nil
+	}
+	return entity, nil
+}
+
+func (i *IdentityStore) pathEntitySessionsRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	entity, err := i.sessionsEntity(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	if entity == nil {
+		return logical.ErrorResponse("entity not found"), logical.ErrInvalidRequest
+	}
+
+	tokens, err := i.entitySessioner.EntityTokens(ctx, entity.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the tokens of the entity: %w", err)
+	}
+	sort.Slice(tokens, func(a, b int) bool {
+		return tokens[a].CreationTime > tokens[b].CreationTime
+	})
+
+	tokenData := make([]map[string]interface{}, 0, len(tokens))
+	leaseIDs := make([]string, 0)
+	logins := make([]map[string]interface{}, 0)
+	for _, te := range tokens {
+		creationTime := time.Unix(te.CreationTime, 0).UTC()
+		tokenData = append(tokenData, map[string]interface{}{
+			"accessor":      te.Accessor,
+			"display_name":  te.DisplayName,
+			"path":          te.Path,
+			"policies":      te.Policies,
+			"type":          te.Type.String(),
+			"creation_time": creationTime,
+			"ttl":           int64(te.TTL.Seconds()),
+		})
+
+		leases, err := i.entitySessioner.TokenLeases(ctx, te)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the leases of the entity: %w", err)
+		}
+		leaseIDs = append(leaseIDs, leases...)
+
+		// Tokens created through the token store are not logins
+		if len(logins) < entitySessionsRecentLogins && !strings.HasPrefix(te.Path, "auth/token/") {
+			logins = append(logins, map[string]interface{}{
+				"accessor": te.Accessor,
+				"path":     te.Path,
+				"time":     creationTime,
+			})
+		}
+	}
+	sort.Strings(leaseIDs)
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"entity_id":     entity.ID,
+			"tokens":        tokenData,
+			"leases":        leaseIDs,
+			"recent_logins": logins,
+		},
+	}, nil
+}
+
+func (i *IdentityStore) pathEntitySessionsRevoke(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	entity, err := i.sessionsEntity(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	if entity == nil {
+		return logical.ErrorResponse("entity not found"), logical.ErrInvalidRequest
+	}
+
+	tokens, err := i.entitySessioner.EntityTokens(ctx, entity.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the tokens of the entity: %w", err)
+	}
+
+	revoked := make([]string, 0, len(tokens))
+	for _, te := range tokens {
+		// The token may have been revoked along with a parent token of the
+		// entity already
+		current, err := i.tokenStorer.LookupToken(ctx, te.ID)
+		if err != nil {
+			return nil, err
+		}
+		if current == nil {
+			revoked = append(revoked, te.Accessor)
+			continue
+		}
+
+		if err := i.entitySessioner.RevokeToken(ctx, current); err != nil {
+			return nil, fmt.Errorf("failed to revoke the token with accessor %q: %w", te.Accessor, err)
+		}
+		revoked = append(revoked, te.Accessor)
+	}
+
+	i.logger.Info("revoked the tokens of entity", "entity_id", entity.ID, "tokens", len(revoked))
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"entity_id":           entity.ID,
+			"revoked_accessors":   revoked,
+			"revoked_token_count": len(revoked),
+		},
+	}, nil
+}
+
+var entitySessionsHelp = map[string][2]string{
+	"entity-sessions": {
+		"List the active tokens, leases and recent logins of an entity.",
+		`
+Lists the accessors and properties of the service tokens tied to the entity in
+the namespace of the request, the leases created with them, and the most
+recent logins which issued them, newest first. Batch tokens are not listed, as
+they are not indexed. The tokens are found by scanning all the tokens of the
+namespace.
+		`,
+	},
+	"entity-sessions-revoke": {
+		"Revoke all the active tokens of an entity, along with their leases.",
+		`
+Revokes the service tokens tied to the entity in the namespace of the request,
+along with their child tokens and their leases, for instance when off-boarding
+a user or responding to an incident. The entity itself is left unchanged: set
+"disabled" on it so that the tokens it obtains afterwards cannot be used.
+		`,
+	},
+}
@@ -0,0 +1,120 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"testing"
+
+	credUserpass "github.com/hashicorp/vault/builtin/credential/userpass"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestIdentityStore_EntitySessions(t *testing.T) {
+	core, _, root := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	core.credentialBackends["userpass"] = credUserpass.Factory
+
+	req := &logical.Request{
+		Path:        "sys/auth/userpass",
+		ClientToken: root,
+		Operation:   logical.UpdateOperation,
+		Data: map[string]interface{}{
+			"type": "userpass",
+		},
+		Connection: &logical.Connection{},
+	}
+	resp, err := core.HandleRequest(ctx, req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v, resp: %#v", err, resp)
+	}
+
+	req.Path = "auth/userpass/users/test"
+	req.Data = map[string]interface{}{
+		"password": "foo",
+		"policies": "default",
+	}
+	resp, err = core.HandleRequest(ctx, req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v, resp: %#v", err, resp)
+	}
+
+	var entityID string
+	accessors := make(map[string]bool)
+	var tokens []string
+	for i := 0; i < 2; i++ {
+		resp, err = core.HandleRequest(ctx, &logical.Request{
+			Path:      "auth/userpass/login/test",
+			Operation: logical.UpdateOperation,
+			Data: map[string]interface{}{
+				"password": "foo",
+			},
+			Connection: &logical.Connection{},
+		})
+		if err != nil || resp == nil || resp.Auth == nil {
+			t.Fatalf("err: %v, resp: %#v", err, resp)
+		}
+		entityID = resp.Auth.EntityID
+		accessors[resp.Auth.Accessor] = true
+		tokens = append(tokens, resp.Auth.ClientToken)
+	}
+
+	resp, err = core.HandleRequest(ctx, &logical.Request{
+		Path:        "identity/entity/id/" + entityID + "/sessions",
+		ClientToken: root,
+		Operation:   logical.ReadOperation,
+	})
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("err: %v, resp: %#v", err, resp)
+	}
+	tokenData := resp.Data["tokens"].([]map[string]interface{})
+	if len(tokenData) != 2 {
+		t.Fatalf("expected 2 tokens, got: %#v", tokenData)
+	}
+	for _, token := range tokenData {
+		if !accessors[token["accessor"].(string)] {
+			t.Fatalf("unexpected token: %#v", token)
+		}
+	}
+	if logins := resp.Data["recent_logins"].([]map[string]interface{}); len(logins) != 2 {
+		t.Fatalf("expected 2 logins, got: %#v", logins)
+	}
+
+	resp, err = core.HandleRequest(ctx, &logical.Request{
+		Path:        "identity/entity/id/" + entityID + "/sessions/revoke",
+		ClientToken: root,
+		Operation:   logical.UpdateOperation,
+	})
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("err: %v, resp: %#v", err, resp)
+	}
+	if resp.Data["revoked_token_count"].(int) != 2 {
+		t.Fatalf("bad: %#v", resp.Data)
+	}
+
+	for _, token := range tokens {
+		te, err := core.LookupToken(ctx, token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if te != nil {
+			t.Fatalf("expected the token to be revoked: %#v", te)
+		}
+	}
+
+	// The root token is left alone
+	if te, err := core.LookupToken(ctx, root); err != nil || te == nil {
+		t.Fatalf("expected the root token to be valid: %v", err)
+	}
+
+	resp, err = core.HandleRequest(ctx, &logical.Request{
+		Path:        "identity/entity/id/missing/sessions",
+		ClientToken: root,
+		Operation:   logical.ReadOperation,
+	})
+	if err == nil || !resp.IsError() {
+		t.Fatalf("expected an error for a missing entity, got: %#v", resp)
+	}
+}
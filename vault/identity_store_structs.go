@@ -17,6 +17,7 @@ import (
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/helper/consts"
 	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/vault/eventbus"
 )
 
 const (
@@ -63,6 +64,13 @@ type IdentityStore struct {
 	// groupLock is used to protect modifications to group entries
 	groupLock sync.RWMutex
 
+	// groupSyncLock prevents external group syncs from running concurrently
+	groupSyncLock sync.Mutex
+
+	// entityDedupeLock prevents entity deduplications from running
+	// concurrently
+	entityDedupeLock sync.Mutex
+
 	// oidcCache stores common response data as well as when the periodic func needs
 	// to run. This is conservatively managed, and most writes to the OIDC endpoints
 	// will invalidate the cache.
@@ -93,16 +101,18 @@ type IdentityStore struct {
 	// operated case insensitively
 	disableLowerCasedNames bool
 
-	router        *Router
-	redirectAddr  string
-	localNode     LocalNode
-	namespacer    Namespacer
-	metrics       metricsutil.Metrics
-	totpPersister TOTPPersister
-	groupUpdater  GroupUpdater
-	tokenStorer   TokenStorer
-	entityCreator EntityCreator
-	mfaBackend    *LoginMFABackend
+	router          *Router
+	redirectAddr    string
+	localNode       LocalNode
+	namespacer      Namespacer
+	metrics         metricsutil.Metrics
+	totpPersister   TOTPPersister
+	groupUpdater    GroupUpdater
+	tokenStorer     TokenStorer
+	entitySessioner EntitySessioner
+	entityCreator   EntityCreator
+	mfaBackend      *LoginMFABackend
+	events          *eventbus.EventBus
 }
 
 type groupDiff struct {
@@ -148,6 +158,14 @@ type TokenStorer interface {
 
 var _ TokenStorer = &Core{}
 
+type EntitySessioner interface {
+	EntityTokens(ctx context.Context, entityID string) ([]*logical.TokenEntry, error)
+	TokenLeases(ctx context.Context, te *logical.TokenEntry) ([]string, error)
+	RevokeToken(ctx context.Context, te *logical.TokenEntry) error
+}
+
+var _ EntitySessioner = &Core{}
+
 type EntityCreator interface {
 	CreateEntity(ctx context.Context) (*identity.Entity, error)
 }
@@ -0,0 +1,525 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-secure-stdlib/strutil"
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/vault/helper/identity"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/custommetadata"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	// entityImportConflictSkip leaves entities which already exist unchanged.
+	entityImportConflictSkip = "skip"
+
+	// entityImportConflictOverwrite replaces the name, metadata, policies and
+	// disabled state of entities which already exist with the imported ones.
+	entityImportConflictOverwrite = "overwrite"
+
+	// entityImportConflictMerge adds the policies, metadata, aliases and
+	// group memberships of the imported entities to the entities which
+	// already exist, keeping their existing values on conflicts.
+	entityImportConflictMerge = "merge"
+
+	// entityExportDefaultLimit and entityExportMaxLimit are the default and
+	// maximum numbers of entities of a page of a bulk export.
+	entityExportDefaultLimit = 1000
+	entityExportMaxLimit     = 10000
+)
+
+// entityTransferRecord is an entity of a bulk export or import. Exports and
+// imports hold one record per line, as JSON.
+type entityTransferRecord struct {
+	ID       string                 `json:"id"`
+	Name     string                 `json:"name"`
+	Metadata map[string]string      `json:"metadata,omitempty"`
+	Policies []string               `json:"policies,omitempty"`
+	Disabled bool                   `json:"disabled,omitempty"`
+	Aliases  []*entityTransferAlias `json:"aliases,omitempty"`
+	Groups   []*entityTransferGroup `json:"groups,omitempty"`
+}
+
+// entityTransferAlias is an alias of an entity of a bulk export or import.
+// The mount of the alias is found by accessor, or else by path, as accessors
+// differ between clusters.
+type entityTransferAlias struct {
+	ID             string            `json:"id"`
+	Name           string            `json:"name"`
+	MountAccessor  string            `json:"mount_accessor"`
+	MountPath      string            `json:"mount_path,omitempty"`
+	MountType      string            `json:"mount_type,omitempty"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
+	CustomMetadata map[string]string `json:"custom_metadata,omitempty"`
+}
+
+// entityTransferGroup is a group an entity of a bulk export or import is a
+// direct member of. The group is found by ID, or else by name.
+type entityTransferGroup struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// pathEntityExport exports a page of the entities of the namespace of the
+// request, with their aliases and direct group memberships, as JSON lines.
+// Entities are exported in the order of their names, starting after the
+// cursor of the request, and the cursor of the next page is returned until
+// every entity has been exported, so that the entities are never all held in
+// memory at once. Aliases of local mounts aren't exported, as they cannot be
+// imported.
+func (i *IdentityStore) pathEntityExport() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		ns, err := namespace.FromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		limit := d.Get("limit").(int)
+		if limit <= 0 || limit > entityExportMaxLimit {
+			return logical.ErrorResponse("limit must be between 1 and %d", entityExportMaxLimit), nil
+		}
+		after := d.Get("after").(string)
+
+		// The name index orders the entities of each namespace by name,
+		// lowercased unless the names are case sensitive.
+		txn := i.db.Txn(false)
+		iter, err := txn.LowerBound(entitiesTable, "name", ns.ID, after)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch iterator for entities in memdb: %w", err)
+		}
+
+		var lines []string
+		var last string
+		more := false
+		for raw := iter.Next(); raw != nil; raw = iter.Next() {
+			entity := raw.(*identity.Entity)
+			if entity.NamespaceID != ns.ID {
+				break
+			}
+			if after != "" && i.sameEntityName(entity.Name, after) {
+				continue
+			}
+			if len(lines) == limit {
+				more = true
+				break
+			}
+
+			line, err := i.exportEntityRecord(entity)
+			if err != nil {
+				return nil, err
+			}
+			lines = append(lines, line)
+			last = entity.Name
+		}
+
+		data := map[string]interface{}{
+			"entities": strings.Join(lines, "\n"),
+			"count":    len(lines),
+		}
+		if more {
+			data["next_cursor"] = last
+		}
+		return &logical.Response{
+			Data: data,
+		}, nil
+	}
+}
+
+// sameEntityName returns whether the entity names are the same, ignoring case
+// unless names are case sensitive.
+func (i *IdentityStore) sameEntityName(a, b string) bool {
+	if i.disableLowerCasedNames {
+		return a == b
+	}
+	return strings.EqualFold(a, b)
+}
+
+// exportEntityRecord returns the JSON line of an entity of a bulk export.
+func (i *IdentityStore) exportEntityRecord(entity *identity.Entity) (string, error) {
+	record := &entityTransferRecord{
+		ID:       entity.ID,
+		Name:     entity.Name,
+		Metadata: entity.Metadata,
+		Policies: entity.Policies,
+		Disabled: entity.Disabled,
+	}
+	for _, alias := range entity.Aliases {
+		if alias.Local {
+			continue
+		}
+		transferAlias := &entityTransferAlias{
+			ID:             alias.ID,
+			Name:           alias.Name,
+			MountAccessor:  alias.MountAccessor,
+			Metadata:       alias.Metadata,
+			CustomMetadata: alias.CustomMetadata,
+		}
+		if mountValidationResp := i.router.ValidateMountByAccessor(alias.MountAccessor); mountValidationResp != nil {
+			transferAlias.MountPath = mountValidationResp.MountPath
+			transferAlias.MountType = mountValidationResp.MountType
+		}
+		record.Aliases = append(record.Aliases, transferAlias)
+	}
+
+	groups, err := i.MemDBGroupsByMemberEntityID(entity.ID, false, false)
+	if err != nil {
+		return "", err
+	}
+	for _, group := range groups {
+		record.Groups = append(record.Groups, &entityTransferGroup{
+			ID:   group.ID,
+			Name: group.Name,
+		})
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode entity %q: %w", entity.ID, err)
+	}
+	return string(line), nil
+}
+
+// handleEntityBulkImport imports the entities of the given JSON lines, as
+// exported by pathEntityExport, with their aliases and group memberships. Each
+// entity is imported on its own, holding the identity store locks only while
+// it is, so that large imports don't block other identity writes and an
+// entity which can't be imported doesn't prevent the others from being. With
+// dry run, the entities are validated and the outcome of their import is
+// returned, but nothing is persisted.
+func (i *IdentityStore) handleEntityBulkImport(ctx context.Context, d *framework.FieldData) (*logical.Response, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	strategy := d.Get("conflict_strategy").(string)
+	switch strategy {
+	case entityImportConflictSkip, entityImportConflictOverwrite, entityImportConflictMerge:
+	default:
+		return logical.ErrorResponse(fmt.Sprintf("invalid conflict_strategy %q", strategy)), nil
+	}
+	dryRun := d.Get("dry_run").(bool)
+
+	type numberedRecord struct {
+		line   int
+		record *entityTransferRecord
+	}
+	var records []numberedRecord
+	for n, line := range strings.Split(d.Get("entities").(string), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		record := &entityTransferRecord{}
+		if err := json.Unmarshal([]byte(line), record); err != nil {
+			return logical.ErrorResponse(fmt.Sprintf("invalid entity on line %d: %s", n+1, err)), nil
+		}
+		records = append(records, numberedRecord{line: n + 1, record: record})
+	}
+	if len(records) == 0 {
+		return logical.ErrorResponse("no entities to import"), nil
+	}
+
+	counts := map[string]int{}
+	results := make([]map[string]interface{}, 0, len(records))
+	seenIDs := map[string]bool{}
+	seenNames := map[string]bool{}
+	for _, r := range records {
+		result := map[string]interface{}{
+			"line": r.line,
+			"id":   r.record.ID,
+			"name": r.record.Name,
+		}
+
+		var status, id string
+		switch {
+		case r.record.ID != "" && seenIDs[r.record.ID]:
+			err = fmt.Errorf("entity id %q is imported more than once", r.record.ID)
+		case r.record.Name != "" && seenNames[r.record.Name]:
+			err = fmt.Errorf("entity name %q is imported more than once", r.record.Name)
+		default:
+			status, id, err = i.importEntityRecordLocked(ctx, ns, r.record, strategy, dryRun)
+		}
+		if r.record.ID != "" {
+			seenIDs[r.record.ID] = true
+		}
+		if r.record.Name != "" {
+			seenNames[r.record.Name] = true
+		}
+
+		if err != nil {
+			status = "error"
+			result["error"] = err.Error()
+		}
+		if id != "" {
+			result["id"] = id
+		}
+		result["status"] = status
+		counts[status]++
+		results = append(results, result)
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"dry_run": dryRun,
+			"results": results,
+			"created": counts["created"],
+			"updated": counts["updated"],
+			"skipped": counts["skipped"],
+			"errors":  counts["error"],
+		},
+	}, nil
+}
+
+// importEntityRecordLocked imports an entity of a bulk import, holding the
+// identity store lock and group lock while it does.
+func (i *IdentityStore) importEntityRecordLocked(ctx context.Context, ns *namespace.Namespace, record *entityTransferRecord, strategy string, dryRun bool) (string, string, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.groupLock.Lock()
+	defer i.groupLock.Unlock()
+
+	return i.importEntityRecord(ctx, ns, record, strategy, dryRun)
+}
+
+// importEntityRecord imports an entity of a bulk import according to the
+// conflict strategy, and returns whether it was created, updated or skipped,
+// along with its ID. The entity is fully validated before anything is
+// persisted, and nothing is persisted with dry run. The identity store lock
+// and group lock must be held.
+func (i *IdentityStore) importEntityRecord(ctx context.Context, ns *namespace.Namespace, record *entityTransferRecord, strategy string, dryRun bool) (string, string, error) {
+	if record.ID != "" {
+		if _, err := uuid.ParseUUID(record.ID); err != nil {
+			return "", "", fmt.Errorf("id %q is not a valid UUID", record.ID)
+		}
+	}
+	if strutil.StrListContains(record.Policies, "root") {
+		return "", "", fmt.Errorf("policies cannot contain root")
+	}
+
+	var existing *identity.Entity
+	if record.ID != "" {
+		byID, err := i.MemDBEntityByID(record.ID, true)
+		if err != nil {
+			return "", "", err
+		}
+		if byID != nil && byID.NamespaceID != ns.ID {
+			return "", "", fmt.Errorf("entity id is in use in another namespace")
+		}
+		existing = byID
+	}
+	if record.Name != "" {
+		byName, err := i.MemDBEntityByName(ctx, record.Name, true)
+		if err != nil {
+			return "", "", err
+		}
+		switch {
+		case byName == nil:
+		case existing == nil:
+			existing = byName
+		case existing.ID != byName.ID:
+			return "", "", fmt.Errorf("entity name is already in use by entity %q", byName.ID)
+		}
+	}
+
+	entity := existing
+	status := "updated"
+	switch {
+	case existing == nil:
+		status = "created"
+		entity = &identity.Entity{
+			ID:       record.ID,
+			Name:     record.Name,
+			Metadata: record.Metadata,
+			Policies: strutil.RemoveDuplicates(record.Policies, false),
+			Disabled: record.Disabled,
+		}
+		if entity.ID != "" {
+			entity.BucketKey = i.entityPacker.BucketKey(entity.ID)
+		}
+		if err := i.sanitizeEntity(ctx, entity); err != nil {
+			return "", "", err
+		}
+	case strategy == entityImportConflictSkip:
+		return "skipped", existing.ID, nil
+	case strategy == entityImportConflictOverwrite:
+		if record.Name != "" {
+			entity.Name = record.Name
+		}
+		entity.Metadata = record.Metadata
+		entity.Policies = strutil.RemoveDuplicates(record.Policies, false)
+		entity.Disabled = record.Disabled
+	case strategy == entityImportConflictMerge:
+		entity.Policies = strutil.RemoveDuplicates(append(entity.Policies, record.Policies...), false)
+		if entity.Metadata == nil && len(record.Metadata) > 0 {
+			entity.Metadata = make(map[string]string, len(record.Metadata))
+		}
+		for k, v := range record.Metadata {
+			if _, ok := entity.Metadata[k]; !ok {
+				entity.Metadata[k] = v
+			}
+		}
+	}
+	if err := validateMetadata(entity.Metadata); err != nil {
+		return "", "", fmt.Errorf("invalid entity metadata: %w", err)
+	}
+
+	aliases, err := i.importEntityAliases(ctx, ns, entity, record.Aliases)
+	if err != nil {
+		return "", "", err
+	}
+	groups, err := i.importEntityGroups(ctx, ns, entity, record.Groups)
+	if err != nil {
+		return "", "", err
+	}
+
+	if dryRun {
+		return status, entity.ID, nil
+	}
+
+	for _, alias := range aliases {
+		if err := i.sanitizeAlias(ctx, alias); err != nil {
+			return "", "", err
+		}
+		entity.UpsertAlias(alias)
+	}
+	if status == "updated" {
+		if err := i.sanitizeEntity(ctx, entity); err != nil {
+			return "", "", err
+		}
+	}
+	if err := i.upsertEntity(ctx, entity, nil, true); err != nil {
+		return "", "", err
+	}
+
+	for _, group := range groups {
+		group.MemberEntityIDs = append(group.MemberEntityIDs, entity.ID)
+		if err := i.UpsertGroup(ctx, group, true); err != nil {
+			return "", "", err
+		}
+	}
+
+	return status, entity.ID, nil
+}
+
+// importEntityAliases returns the aliases of the bulk import which the entity
+// doesn't have yet. An alias whose mount can't be found, which belongs to
+// another entity or which would give the entity two aliases on a mount fails
+// the import of the entity.
+func (i *IdentityStore) importEntityAliases(ctx context.Context, ns *namespace.Namespace, entity *identity.Entity, records []*entityTransferAlias) ([]*identity.Alias, error) {
+	var aliases []*identity.Alias
+	mountAccessors := map[string]bool{}
+	for _, a := range entity.Aliases {
+		mountAccessors[a.MountAccessor] = true
+	}
+
+	for _, record := range records {
+		if record.Name == "" {
+			return nil, fmt.Errorf("missing alias name")
+		}
+
+		mountEntry := i.router.MatchingMountByAccessor(record.MountAccessor)
+		if mountEntry == nil || mountEntry.NamespaceID != ns.ID {
+			mountEntry = nil
+			if record.MountPath != "" {
+				mountEntry = i.router.MatchingMountEntry(ctx, record.MountPath)
+			}
+		}
+		if mountEntry == nil || mountEntry.Table != credentialTableType || mountEntry.NamespaceID != ns.ID {
+			return nil, fmt.Errorf("no auth mount of the namespace matches alias %q", record.Name)
+		}
+		if mountEntry.Local {
+			return nil, fmt.Errorf("aliases of local mounts cannot be imported")
+		}
+		if len(record.CustomMetadata) > 0 {
+			if err := custommetadata.Validate(record.CustomMetadata); err != nil {
+				return nil, fmt.Errorf("invalid custom metadata of alias %q: %w", record.Name, err)
+			}
+		}
+
+		existing, err := i.MemDBAliasByFactors(mountEntry.Accessor, record.Name, false, false)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			if existing.CanonicalID != entity.ID {
+				return nil, fmt.Errorf("alias %q is tied to entity %q", record.Name, existing.CanonicalID)
+			}
+			continue
+		}
+		if mountAccessors[mountEntry.Accessor] {
+			return nil, fmt.Errorf("entity already has an alias on mount %q", mountEntry.Accessor)
+		}
+		mountAccessors[mountEntry.Accessor] = true
+
+		alias := &identity.Alias{
+			MountAccessor:  mountEntry.Accessor,
+			Name:           record.Name,
+			Metadata:       record.Metadata,
+			CustomMetadata: record.CustomMetadata,
+			CanonicalID:    entity.ID,
+		}
+
+		// The ID of the alias is preserved when no other alias uses it
+		if _, err := uuid.ParseUUID(record.ID); err == nil {
+			byID, err := i.MemDBAliasByID(record.ID, false, false)
+			if err != nil {
+				return nil, err
+			}
+			if byID == nil {
+				alias.ID = record.ID
+				alias.LocalBucketKey = i.localAliasPacker.BucketKey(entity.ID)
+			}
+		}
+		aliases = append(aliases, alias)
+	}
+
+	return aliases, nil
+}
+
+// importEntityGroups returns the groups of the bulk import which the entity
+// isn't a member of yet. A group which can't be found, or which is external,
+// fails the import of the entity.
+func (i *IdentityStore) importEntityGroups(ctx context.Context, ns *namespace.Namespace, entity *identity.Entity, records []*entityTransferGroup) ([]*identity.Group, error) {
+	var groups []*identity.Group
+	for _, record := range records {
+		var group *identity.Group
+		if record.ID != "" {
+			byID, err := i.MemDBGroupByID(record.ID, true)
+			if err != nil {
+				return nil, err
+			}
+			if byID != nil && byID.NamespaceID == ns.ID {
+				group = byID
+			}
+		}
+		if group == nil && record.Name != "" {
+			byName, err := i.MemDBGroupByName(ctx, record.Name, true)
+			if err != nil {
+				return nil, err
+			}
+			group = byName
+		}
+		if group == nil {
+			return nil, fmt.Errorf("group %q not found", record.Name)
+		}
+		if group.Type == groupTypeExternal {
+			return nil, fmt.Errorf("entities cannot be members of external group %q", group.Name)
+		}
+		if strutil.StrListContains(group.MemberEntityIDs, entity.ID) {
+			continue
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
@@ -0,0 +1,86 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// raftAppliedIndex returns the latest index applied to the FSM of the raft
+// storage of the node, or 0 if the node doesn't use raft for storage. Raft
+// used only for HA coordination doesn't order storage writes, so it isn't
+// used to build index states.
+func (c *Core) raftAppliedIndex() uint64 {
+	raftStorage, ok := c.underlyingPhysical.(interface{ AppliedIndex() uint64 })
+	if !ok {
+		return 0
+	}
+	return raftStorage.AppliedIndex()
+}
+
+// setWriteIndexState sets the local index of the index state of a write
+// request which didn't already have one to the raft index it was applied at,
+// so that it is returned to the client in the X-Vault-Index header.
+func (c *Core) setWriteIndexState(req *logical.Request, state *logical.WALState) {
+	if state == nil || state.LocalIndex != 0 || state.ReplicatedIndex != 0 {
+		return
+	}
+	switch req.Operation {
+	case logical.ReadOperation, logical.ListOperation, logical.HelpOperation:
+		return
+	}
+	state.LocalIndex = c.raftAppliedIndex()
+}
+
+// IndexStateHeader returns the value of the X-Vault-Index header for the
+// given index state, signed with the index header HMAC key of the cluster. It
+// returns an empty string if the key isn't loaded yet.
+func (c *Core) IndexStateHeader(state *logical.WALState) string {
+	key := c.headerHMACKey()
+	if state == nil || len(key) == 0 {
+		return ""
+	}
+
+	raw := fmt.Sprintf("v1:%s:%d:%d", state.ClusterID, state.LocalIndex, state.ReplicatedIndex)
+	hm := hmac.New(sha256.New, key)
+	hm.Write([]byte(raw))
+	return base64.StdEncoding.EncodeToString([]byte(raw + ":" + hex.EncodeToString(hm.Sum(nil))))
+}
+
+// parseIndexStates parses the given X-Vault-Index header values, skipping
+// those which can't be verified with the index header HMAC key or were issued
+// by another cluster.
+func (c *Core) parseIndexStates(raw []string) []*logical.WALState {
+	key := c.headerHMACKey()
+	if len(raw) == 0 || len(key) == 0 {
+		return nil
+	}
+
+	clusterID := c.ClusterID()
+	var states []*logical.WALState
+	for _, r := range raw {
+		state, err := api.ParseReplicationState(r, key)
+		if err != nil {
+			c.logger.Debug("ignoring invalid index state", "error", err)
+			continue
+		}
+		if state.ClusterID != clusterID {
+			c.logger.Debug("ignoring index state of another cluster", "cluster_id", state.ClusterID)
+			continue
+		}
+		states = append(states, &logical.WALState{
+			ClusterID:       state.ClusterID,
+			LocalIndex:      state.LocalIndex,
+			ReplicatedIndex: state.ReplicatedIndex,
+		})
+	}
+	return states
+}
@@ -0,0 +1,80 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/sdk/physical"
+	"github.com/stretchr/testify/require"
+)
+
+// testAppliedIndexBackend reports a fixed applied index, as raft storage
+// does.
+type testAppliedIndexBackend struct {
+	physical.Backend
+	index uint64
+}
+
+func (b *testAppliedIndexBackend) AppliedIndex() uint64 {
+	return b.index
+}
+
+func TestCore_IndexState(t *testing.T) {
+	c, _, root := TestCoreUnsealed(t)
+
+	// Without raft storage, writes don't return index states.
+	req := logical.TestRequest(t, logical.UpdateOperation, "secret/foo")
+	req.Data["value"] = "bar"
+	req.ClientToken = root
+	_, err := c.HandleRequest(namespace.RootContext(nil), req)
+	require.NoError(t, err)
+	require.Nil(t, req.ResponseState())
+
+	backend := &testAppliedIndexBackend{Backend: c.underlyingPhysical, index: 10}
+	c.underlyingPhysical = backend
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "secret/foo")
+	req.Data["value"] = "baz"
+	req.ClientToken = root
+	_, err = c.HandleRequest(namespace.RootContext(nil), req)
+	require.NoError(t, err)
+	require.NotNil(t, req.ResponseState())
+	require.Equal(t, c.ClusterID(), req.ResponseState().ClusterID)
+	require.Equal(t, uint64(10), req.ResponseState().LocalIndex)
+
+	// Reads don't return index states.
+	readReq := logical.TestRequest(t, logical.ReadOperation, "secret/foo")
+	readReq.ClientToken = root
+	_, err = c.HandleRequest(namespace.RootContext(nil), readReq)
+	require.NoError(t, err)
+	require.Nil(t, readReq.ResponseState())
+
+	header := c.IndexStateHeader(req.ResponseState())
+	state, err := api.ParseReplicationState(header, c.headerHMACKey())
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), state.LocalIndex)
+
+	// The state is present until the node is behind it.
+	require.False(t, c.MissingRequiredState([]string{header}, false))
+	backend.index = 9
+	require.True(t, c.MissingRequiredState([]string{header}, false))
+
+	readReq = logical.TestRequest(t, logical.ReadOperation, "secret/foo")
+	readReq.ClientToken = root
+	readReq.SetRequiredState([]string{header})
+	_, err = c.HandleRequest(namespace.RootContext(nil), readReq)
+	require.ErrorIs(t, err, logical.ErrMissingRequiredState)
+
+	// States which can't be verified, or of other clusters, are ignored.
+	forged := base64.StdEncoding.EncodeToString([]byte("v1:" + c.ClusterID() + ":100:0:00"))
+	require.False(t, c.MissingRequiredState([]string{forged}, false))
+	other := c.IndexStateHeader(&logical.WALState{ClusterID: "other", LocalIndex: 100})
+	require.False(t, c.MissingRequiredState([]string{other}, false))
+	require.False(t, c.MissingRequiredState([]string{"invalid"}, false))
+}
@@ -0,0 +1,250 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	// kvEmbargoPrefix is the barrier path under which the embargoes of the
+	// versions of KV version 2 secrets are stored, by mount UUID, secret path
+	// and version.
+	kvEmbargoPrefix = "core/kv-embargoes/"
+
+	// kvEmbargoOption is the write option of KV version 2 secrets holding the
+	// time before which the version being written can't be read.
+	kvEmbargoOption = "available_after"
+)
+
+// kvEmbargo is the time before which a version of a KV version 2 secret can't
+// be read. The creation time of the version tells it apart from a later
+// version with the same number, written once the metadata of the secret was
+// deleted.
+type kvEmbargo struct {
+	AvailableAfter time.Time `json:"available_after"`
+	CreatedTime    string    `json:"created_time"`
+}
+
+// kvEmbargoRequest is a request to a KV version 2 secret which writes, reads
+// or forgets embargoed versions.
+type kvEmbargoRequest struct {
+	// path is the path of the secret within its mount
+	path string
+
+	// key is the barrier path under which the embargoes of the versions of
+	// the secret are stored
+	key string
+
+	// availableAfter is the embargo of the version being written, if any
+	availableAfter time.Time
+}
+
+// routeKV routes a request, enforcing the embargoes of the versions of KV
+// version 2 secrets. Versions written with the available_after option can't
+// be read until then: reads of their data or subkeys fail with a 403 error
+// telling when they become available. Embargoed writes of a secret are
+// serialized with its reads, so the version can't be read before its embargo
+// is stored.
+func (c *Core) routeKV(ctx context.Context, req *logical.Request) (*logical.Response, error) {
+	embargo, err := c.kvEmbargoRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if embargo == nil {
+		return c.router.Route(ctx, req)
+	}
+
+	lock := locksutil.LockForKey(c.kvEmbargoLocks, embargo.key)
+	switch req.Operation {
+	case logical.ReadOperation:
+		lock.RLock()
+		defer lock.RUnlock()
+	default:
+		lock.Lock()
+		defer lock.Unlock()
+	}
+
+	resp, err := c.router.Route(ctx, req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		return resp, err
+	}
+
+	switch req.Operation {
+	case logical.ReadOperation:
+		if resp == nil {
+			return nil, nil
+		}
+		metadata, _ := resp.Data["metadata"].(map[string]interface{})
+		if metadata == nil || metadata["version"] == nil {
+			return resp, nil
+		}
+		if err := c.checkKVEmbargo(ctx, embargo, metadata["version"], metadata["created_time"]); err != nil {
+			return nil, err
+		}
+
+	case logical.DeleteOperation:
+		if err := c.clearKVEmbargoes(ctx, embargo.key); err != nil {
+			// The embargoes left behind don't apply to the versions written
+			// once the secret is recreated, as their creation times differ.
+			c.logger.Warn("failed to clear the embargoes of deleted secret", "path", req.Path, "error", err)
+		}
+
+	default:
+		if resp == nil || resp.Data["version"] == nil {
+			return resp, nil
+		}
+		entry, err := logical.StorageEntryJSON(embargo.key+fmt.Sprint(resp.Data["version"]), &kvEmbargo{
+			AvailableAfter: embargo.availableAfter,
+			CreatedTime:    kvEmbargoTime(resp.Data["created_time"]),
+		})
+		if err == nil {
+			err = c.barrier.Put(ctx, entry)
+		}
+		if err != nil {
+			c.logger.Error("failed to store the embargo of secret version", "path", req.Path, "version", resp.Data["version"], "error", err)
+			return nil, fmt.Errorf("version %v of %q was written but its embargo could not be stored: %w", resp.Data["version"], embargo.path, err)
+		}
+	}
+
+	return resp, nil
+}
+
+// kvEmbargoRequest returns the embargo request made by a request, or nil if
+// the request doesn't write an embargoed version of a KV version 2 secret,
+// read the data or subkeys of one, or delete its metadata.
+func (c *Core) kvEmbargoRequest(ctx context.Context, req *logical.Request) (*kvEmbargoRequest, error) {
+	switch req.Operation {
+	case logical.ReadOperation, logical.CreateOperation, logical.UpdateOperation, logical.PatchOperation, logical.DeleteOperation:
+	default:
+		return nil, nil
+	}
+
+	entry := c.router.MatchingMountEntry(ctx, req.Path)
+	if entry == nil || entry.Type != "kv" || entry.Options["version"] != "2" {
+		return nil, nil
+	}
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	endpoint, path, ok := strings.Cut(strings.TrimPrefix(ns.Path+req.Path, entry.APIPath()), "/")
+	if !ok || path == "" {
+		return nil, nil
+	}
+
+	embargo := &kvEmbargoRequest{
+		path: path,
+		key:  kvEmbargoPrefix + entry.UUID + "/" + path + "/",
+	}
+	switch {
+	case endpoint == "data" && req.Operation == logical.ReadOperation,
+		endpoint == "subkeys" && req.Operation == logical.ReadOperation,
+		endpoint == "metadata" && req.Operation == logical.DeleteOperation:
+		return embargo, nil
+
+	case endpoint == "data" && req.Operation != logical.DeleteOperation:
+		availableAfter, err := kvEmbargoAvailableAfter(req.Data)
+		if err != nil {
+			return nil, logical.CodedError(http.StatusBadRequest, err.Error())
+		}
+		if !availableAfter.After(c.clock.Now()) {
+			return nil, nil
+		}
+		embargo.availableAfter = availableAfter
+		return embargo, nil
+	}
+
+	return nil, nil
+}
+
+// kvEmbargoAvailableAfter returns the available_after option of a write of a
+// KV version 2 secret, or the zero time if it isn't set.
+func kvEmbargoAvailableAfter(data map[string]interface{}) (time.Time, error) {
+	options, _ := data["options"].(map[string]interface{})
+	raw, ok := options[kvEmbargoOption]
+	if !ok || raw == nil || raw == "" {
+		return time.Time{}, nil
+	}
+	s, ok := raw.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%s must be an RFC 3339 timestamp", kvEmbargoOption)
+	}
+	availableAfter, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be an RFC 3339 timestamp: %w", kvEmbargoOption, err)
+	}
+	return availableAfter, nil
+}
+
+// checkKVEmbargo returns a 403 error if the version of the secret read is
+// still embargoed.
+func (c *Core) checkKVEmbargo(ctx context.Context, embargo *kvEmbargoRequest, version, createdTime interface{}) error {
+	raw, err := c.barrier.Get(ctx, embargo.key+fmt.Sprint(version))
+	if err != nil {
+		return fmt.Errorf("failed to read the embargo of version %v of %q: %w", version, embargo.path, err)
+	}
+	if raw == nil {
+		return nil
+	}
+	var stored kvEmbargo
+	if err := raw.DecodeJSON(&stored); err != nil {
+		return fmt.Errorf("failed to decode the embargo of version %v of %q: %w", version, embargo.path, err)
+	}
+	if stored.CreatedTime != kvEmbargoTime(createdTime) || !c.clock.Now().Before(stored.AvailableAfter) {
+		return nil
+	}
+	return logical.CodedError(http.StatusForbidden, fmt.Sprintf("version %v of %q is embargoed until %s", version, embargo.path, stored.AvailableAfter.UTC().Format(time.RFC3339)))
+}
+
+// clearKVEmbargoes deletes the embargoes stored under the given key, leaving
+// those of the secrets nested under the path of the secret.
+func (c *Core) clearKVEmbargoes(ctx context.Context, key string) error {
+	versions, err := c.barrier.List(ctx, key)
+	if err != nil {
+		return err
+	}
+	for _, version := range versions {
+		if strings.HasSuffix(version, "/") {
+			continue
+		}
+		if err := c.barrier.Delete(ctx, key+version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteKVEmbargoes deletes the embargoes of the versions of the secrets of a
+// KV mount being unmounted.
+func (c *Core) deleteKVEmbargoes(ctx context.Context, entry *MountEntry) error {
+	return logical.ClearView(ctx, NewBarrierView(c.barrier, kvEmbargoPrefix+entry.UUID+"/"))
+}
+
+// kvEmbargoTime formats the creation time of a version of a KV version 2
+// secret, as found in the responses of the mount, whether it is a time or a
+// timestamp.
+func kvEmbargoTime(t interface{}) string {
+	switch t := t.(type) {
+	case time.Time:
+		return t.UTC().Format(time.RFC3339Nano)
+	case string:
+		if parsed, err := time.Parse(time.RFC3339Nano, t); err == nil {
+			return parsed.UTC().Format(time.RFC3339Nano)
+		}
+		return t
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(t)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	logicalKv "github.com/hashicorp/vault-plugin-secrets-kv"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/helper/timeutil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCore_KVEmbargo verifies that versions of KV version 2 secrets written
+// with available_after can't be read until then, and that their embargoes are
+// forgotten with the metadata of the secret.
+func TestCore_KVEmbargo(t *testing.T) {
+	AddTestLogicalBackend("kv", logicalKv.Factory)
+	defer func() {
+		delete(testLogicalBackends, "kv")
+	}()
+	clock := timeutil.NewManualClock(time.Now())
+	c, _, root := TestCoreUnsealedWithConfig(t, &CoreConfig{Clock: clock})
+	ctx := namespace.RootContext(nil)
+
+	require.NoError(t, c.mount(ctx, &MountEntry{
+		Table:   mountTableType,
+		Path:    "secret/",
+		Type:    "kv",
+		Options: map[string]string{"version": "2"},
+	}))
+	mountUUID := c.router.MatchingMountEntry(ctx, "secret/").UUID
+
+	write := func(value string, availableAfter string) error {
+		req := logical.TestRequest(t, logical.UpdateOperation, "secret/data/app/db")
+		req.Data["data"] = map[string]interface{}{"password": value}
+		if availableAfter != "" {
+			req.Data["options"] = map[string]interface{}{"available_after": availableAfter}
+		}
+		req.ClientToken = root
+		_, err := c.HandleRequest(ctx, req)
+		return err
+	}
+	read := func(path string) (*logical.Response, error) {
+		req := logical.TestRequest(t, logical.ReadOperation, path)
+		req.ClientToken = root
+		return c.HandleRequest(ctx, req)
+	}
+	requireCode := func(err error, code int) {
+		t.Helper()
+		var coded logical.HTTPCodedError
+		require.ErrorAs(t, err, &coded)
+		require.Equal(t, code, coded.Code())
+	}
+
+	// The mount may still be upgrading.
+	require.Eventually(t, func() bool {
+		return write("v1", "") == nil
+	}, 10*time.Second, 50*time.Millisecond)
+
+	availableAfter := clock.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	require.NoError(t, write("v2", availableAfter.Format(time.RFC3339)))
+
+	_, err := read("secret/data/app/db")
+	requireCode(err, http.StatusForbidden)
+	require.ErrorContains(t, err, `version 2 of "app/db" is embargoed until `+availableAfter.Format(time.RFC3339))
+	_, err = read("secret/subkeys/app/db")
+	requireCode(err, http.StatusForbidden)
+
+	// Earlier versions and the metadata remain readable.
+	req := logical.TestRequest(t, logical.ReadOperation, "secret/data/app/db")
+	req.Data["version"] = 1
+	req.ClientToken = root
+	resp, err := c.HandleRequest(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, "v1", resp.Data["data"].(map[string]interface{})["password"])
+	_, err = read("secret/metadata/app/db")
+	require.NoError(t, err)
+
+	// Invalid embargoes are rejected before the version is written.
+	requireCode(write("v3", "tomorrow"), http.StatusBadRequest)
+	resp, err = read("secret/metadata/app/db")
+	require.NoError(t, err)
+	require.EqualValues(t, 2, resp.Data["current_version"])
+
+	clock.Advance(time.Hour)
+	resp, err = read("secret/data/app/db")
+	require.NoError(t, err)
+	require.Equal(t, "v2", resp.Data["data"].(map[string]interface{})["password"])
+
+	// Deleting the metadata forgets the embargoes, so that they don't apply
+	// to the versions of the secret once it is written again.
+	require.NoError(t, write("v3", clock.Now().Add(time.Hour).Format(time.RFC3339)))
+	keys, err := c.barrier.List(ctx, kvEmbargoPrefix+mountUUID+"/app/db/")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"2", "3"}, keys)
+
+	req = logical.TestRequest(t, logical.DeleteOperation, "secret/metadata/app/db")
+	req.ClientToken = root
+	_, err = c.HandleRequest(ctx, req)
+	require.NoError(t, err)
+	keys, err = c.barrier.List(ctx, kvEmbargoPrefix+mountUUID+"/app/db/")
+	require.NoError(t, err)
+	require.Empty(t, keys)
+
+	for _, value := range []string{"v1", "v2", "v3"} {
+		require.NoError(t, write(value, ""))
+	}
+	resp, err = read("secret/data/app/db")
+	require.NoError(t, err)
+	require.Equal(t, "v3", resp.Data["data"].(map[string]interface{})["password"])
+}
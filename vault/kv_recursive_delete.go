@@ -0,0 +1,379 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/helper/policyutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	kvRecursiveDeleteStatusRunning   = "running"
+	kvRecursiveDeleteStatusCompleted = "completed"
+	kvRecursiveDeleteStatusCanceled  = "canceled"
+
+	// kvRecursiveDeleteMaxJobs is the number of recursive deletes whose
+	// status is kept. The oldest finished ones are forgotten first.
+	kvRecursiveDeleteMaxJobs = 64
+
+	// kvRecursiveDeleteMaxErrors is the number of errors kept in the status
+	// of a recursive delete.
+	kvRecursiveDeleteMaxErrors = 100
+)
+
+// kvRequestFunc handles a request made to a KV mount on behalf of a
+// background job, such as a recursive delete or a secrets import.
+type kvRequestFunc func(context.Context, *logical.Request) (*logical.Response, error)
+
+// kvRecursiveDeleteJob is the progress of a recursive delete of the metadata
+// under a KV version 2 prefix.
+type kvRecursiveDeleteJob struct {
+	l sync.RWMutex
+
+	id          string
+	namespaceID string
+	mountPoint  string
+	prefix      string
+	startTime   time.Time
+	endTime     time.Time
+	status      string
+	deleted     int
+	failed      int
+	errors      []string
+}
+
+// kvRecursiveDeletes holds the recursive deletes started on this node.
+type kvRecursiveDeletes struct {
+	l     sync.Mutex
+	jobs  map[string]*kvRecursiveDeleteJob
+	order []string
+}
+
+// handleKVRecursiveDelete deletes the metadata, and so all versions, of every
+// key under the given metadata path of a KV version 2 mount, such as
+// "secret/metadata/app/". Every list and delete is authorized with the ACL of
+// the token of the request when the delete starts, and audited as a request of
+// its own. The token itself isn't kept, so the delete can't do more than the
+// token could when it started. Deleting a prefix without keys succeeds, so the
+// request can be retried until it completes.
+//
+// With dryRun set, the keys that would be deleted are returned. Otherwise
+// the delete runs in the background and its progress can be read from
+// sys/kv-recursive-delete/:id.
+func (c *Core) handleKVRecursiveDelete(ctx context.Context, req *logical.Request, path string, dryRun bool) (*logical.Response, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	path = strings.TrimPrefix(path, "/")
+	entry := c.router.MatchingMountEntry(ctx, path)
+	if entry == nil || entry.NamespaceID != ns.ID || entry.Type != "kv" || entry.Options["version"] != "2" {
+		return logical.ErrorResponse("path must be within a KV version 2 mount of the namespace"), logical.ErrInvalidRequest
+	}
+	relative := strings.TrimPrefix(path, entry.Path)
+	if relative != "metadata" && !strings.HasPrefix(relative, "metadata/") {
+		return logical.ErrorResponse("path must be within the metadata of the mount, such as %q", entry.Path+"metadata/"), logical.ErrInvalidRequest
+	}
+
+	prefix := strings.TrimPrefix(strings.TrimPrefix(relative, "metadata"), "/")
+	var key string
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		key = prefix
+		prefix += "/"
+	}
+
+	handle, err := c.kvRequestHandler(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	base := &logical.Request{
+		MountPoint: entry.Path,
+		Connection: req.Connection,
+	}
+
+	// Listing the prefix up front makes the request fail fast when the
+	// token may not list it.
+	if _, err := handle(ctx, kvSubRequest(base, logical.ListOperation, prefix)); err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		var keys, warnings []string
+		if key != "" {
+			resp, err := handle(ctx, kvSubRequest(base, logical.ReadOperation, key))
+			if err == nil && resp != nil {
+				keys = append(keys, key)
+			}
+		}
+		err := c.walkKVMetadata(ctx, handle, base, prefix,
+			func(key string) {
+				keys = append(keys, key)
+			},
+			func(dir string, err error) {
+				warnings = append(warnings, fmt.Sprintf("failed to list %q: %s", dir, err))
+			})
+		if err != nil {
+			return nil, err
+		}
+
+		return &logical.Response{
+			Data: map[string]interface{}{
+				"dry_run": true,
+				"keys":    keys,
+			},
+			Warnings: warnings,
+		}, nil
+	}
+
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
+	job := &kvRecursiveDeleteJob{
+		id:          id,
+		namespaceID: ns.ID,
+		mountPoint:  entry.Path,
+		prefix:      prefix,
+		startTime:   time.Now(),
+		status:      kvRecursiveDeleteStatusRunning,
+	}
+	c.kvRecursiveDeletes.add(job)
+
+	go func() {
+		ctx := namespace.ContextWithNamespace(c.activeContext, ns)
+		deleteKey := func(key string) {
+			if _, err := handle(ctx, kvSubRequest(base, logical.DeleteOperation, key)); err != nil {
+				job.recordError(fmt.Sprintf("failed to delete %q: %s", key, err))
+				return
+			}
+			job.l.Lock()
+			job.deleted++
+			job.l.Unlock()
+		}
+
+		if key != "" {
+			deleteKey(key)
+		}
+		err := c.walkKVMetadata(ctx, handle, base, prefix, deleteKey, func(dir string, err error) {
+			job.recordError(fmt.Sprintf("failed to list %q: %s", dir, err))
+		})
+
+		job.l.Lock()
+		defer job.l.Unlock()
+		job.endTime = time.Now()
+		job.status = kvRecursiveDeleteStatusCompleted
+		if err != nil {
+			job.status = kvRecursiveDeleteStatusCanceled
+		}
+	}()
+
+	return logical.RespondWithStatusCode(&logical.Response{
+		Data: map[string]interface{}{
+			"id":          id,
+			"status_path": "sys/kv-recursive-delete/" + id,
+		},
+	}, req, http.StatusAccepted)
+}
+
+// kvRequestHandler returns the function handling the requests of a background
+// job started by the request, such as the lists and deletes of a recursive
+// delete. They are authorized with the ACL of the token of the request at this
+// time and audited with its authentication, then routed to the mount without
+// the token.
+func (c *Core) kvRequestHandler(ctx context.Context, req *logical.Request) (kvRequestFunc, error) {
+	acl, te, entity, identityPolicies, err := c.fetchACLTokenEntryAndEntity(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if entity != nil && entity.Disabled {
+		return nil, logical.ErrPermissionDenied
+	}
+
+	auth := &logical.Auth{
+		Accessor:         te.Accessor,
+		DisplayName:      te.DisplayName,
+		EntityID:         te.EntityID,
+		Metadata:         te.Meta,
+		TokenPolicies:    te.Policies,
+		IdentityPolicies: identityPolicies[te.NamespaceID],
+		Policies:         policyutil.SanitizePolicies(append(te.Policies, identityPolicies[te.NamespaceID]...), false),
+		TokenType:        te.Type,
+	}
+
+	return func(ctx context.Context, sub *logical.Request) (*logical.Response, error) {
+		sub.ClientTokenAccessor = te.Accessor
+		sub.DisplayName = te.DisplayName
+		sub.EntityID = te.EntityID
+
+		var authErr error
+		if !acl.AllowOperation(ctx, sub, false).Allowed {
+			authErr = logical.ErrPermissionDenied
+		}
+		logInput := &logical.LogInput{
+			Auth:     auth,
+			Request:  sub,
+			OuterErr: authErr,
+		}
+		if err := c.auditBroker.LogRequest(ctx, logInput, c.auditedHeaders); err != nil {
+			c.logger.Error("failed to audit request", "path", sub.Path, "error", err)
+			return nil, ErrInternalError
+		}
+		if authErr != nil {
+			return nil, authErr
+		}
+
+		resp, err := c.routeKV(ctx, sub)
+		logInput.Response = resp
+		logInput.OuterErr = err
+		if auditErr := c.auditBroker.LogResponse(ctx, logInput, c.auditedHeaders); auditErr != nil {
+			c.logger.Error("failed to audit response", "path", sub.Path, "error", auditErr)
+			return nil, ErrInternalError
+		}
+		if err == nil && resp != nil && resp.IsError() {
+			err = resp.Error()
+		}
+		return resp, err
+	}, nil
+}
+
+// walkKVMetadata lists the keys under prefix, breadth first, calling fn with
+// the path, relative to the mount, of every key found. Folders that cannot be
+// listed are passed to onError and skipped. It only fails when the context is
+// done.
+func (c *Core) walkKVMetadata(ctx context.Context, handle kvRequestFunc, parent *logical.Request, prefix string, fn func(key string), onError func(dir string, err error)) error {
+	dirs := []string{prefix}
+	for len(dirs) > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		dir := dirs[0]
+		dirs = dirs[1:]
+
+		resp, err := handle(ctx, kvSubRequest(parent, logical.ListOperation, dir))
+		if err != nil {
+			onError(dir, err)
+			continue
+		}
+		if resp == nil || resp.Data == nil {
+			continue
+		}
+		keys, _ := resp.Data["keys"].([]string)
+		for _, key := range keys {
+			if strings.HasSuffix(key, "/") {
+				dirs = append(dirs, dir+key)
+				continue
+			}
+			fn(dir + key)
+		}
+	}
+	return nil
+}
+
+// kvSubRequest returns a request on the metadata of the given path of the
+// KV mount of the parent request.
+func kvSubRequest(parent *logical.Request, op logical.Operation, path string) *logical.Request {
+	id, _ := uuid.GenerateUUID()
+	return &logical.Request{
+		ID:         id,
+		Operation:  op,
+		Path:       parent.MountPoint + "metadata/" + path,
+		Connection: parent.Connection,
+	}
+}
+
+func (j *kvRecursiveDeleteJob) recordError(msg string) {
+	j.l.Lock()
+	defer j.l.Unlock()
+	j.failed++
+	if len(j.errors) < kvRecursiveDeleteMaxErrors {
+		j.errors = append(j.errors, msg)
+	}
+}
+
+func (j *kvRecursiveDeleteJob) toMap() map[string]interface{} {
+	j.l.RLock()
+	defer j.l.RUnlock()
+
+	m := map[string]interface{}{
+		"id":          j.id,
+		"mount_point": j.mountPoint,
+		"prefix":      j.prefix,
+		"status":      j.status,
+		"start_time":  j.startTime.Format(time.RFC3339Nano),
+		"deleted":     j.deleted,
+		"failed":      j.failed,
+		"errors":      append([]string{}, j.errors...),
+	}
+	if !j.endTime.IsZero() {
+		m["end_time"] = j.endTime.Format(time.RFC3339Nano)
+	}
+	return m
+}
+
+func (j *kvRecursiveDeleteJob) running() bool {
+	j.l.RLock()
+	defer j.l.RUnlock()
+	return j.status == kvRecursiveDeleteStatusRunning
+}
+
+// add records a job, forgetting the oldest finished job if there are too
+// many.
+func (d *kvRecursiveDeletes) add(job *kvRecursiveDeleteJob) {
+	d.l.Lock()
+	defer d.l.Unlock()
+
+	if d.jobs == nil {
+		d.jobs = make(map[string]*kvRecursiveDeleteJob)
+	}
+	if len(d.order) >= kvRecursiveDeleteMaxJobs {
+		for i, id := range d.order {
+			if !d.jobs[id].running() {
+				delete(d.jobs, id)
+				d.order = append(d.order[:i], d.order[i+1:]...)
+				break
+			}
+		}
+	}
+	d.jobs[job.id] = job
+	d.order = append(d.order, job.id)
+}
+
+// get returns the job with the given ID if it was started in the namespace.
+func (d *kvRecursiveDeletes) get(ns *namespace.Namespace, id string) *kvRecursiveDeleteJob {
+	d.l.Lock()
+	defer d.l.Unlock()
+
+	job, ok := d.jobs[id]
+	if !ok || job.namespaceID != ns.ID {
+		return nil
+	}
+	return job
+}
+
+// list returns the IDs of the jobs started in the namespace.
+func (d *kvRecursiveDeletes) list(ns *namespace.Namespace) []string {
+	d.l.Lock()
+	defer d.l.Unlock()
+
+	var ids []string
+	for id, job := range d.jobs {
+		if job.namespaceID == ns.ID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
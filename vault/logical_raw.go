@@ -23,6 +23,8 @@ var protectedPaths = []string{
 	keyringPath,
 	// Changing the cluster info path can change the cluster ID which can be disruptive
 	coreLocalClusterInfoPath,
+	// The lease import key decrypts the revocation data of exported leases
+	leaseImportKeyPath,
 }
 
 type RawBackend struct {
@@ -13,6 +13,7 @@ import (
 	"errors"
 	"fmt"
 	"hash"
+	"io"
 	"math/rand"
 	"net/http"
 	"path"
@@ -20,6 +21,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
 
@@ -27,8 +29,10 @@ import (
 	log "github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-memdb"
 	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/go-secure-stdlib/mlock"
 	"github.com/hashicorp/go-secure-stdlib/parseutil"
 	"github.com/hashicorp/go-secure-stdlib/strutil"
+	"github.com/hashicorp/go-uuid"
 	semver "github.com/hashicorp/go-version"
 	"github.com/hashicorp/vault/helper/experiments"
 	"github.com/hashicorp/vault/helper/hostutil"
@@ -49,6 +53,7 @@ import (
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/hashicorp/vault/version"
 	"github.com/mitchellh/mapstructure"
+	"golang.org/x/crypto/hkdf"
 	"golang.org/x/crypto/sha3"
 )
 
@@ -99,6 +104,9 @@ func NewSystemBackend(core *Core, logger log.Logger) *SystemBackend {
 				"remount",
 				"audit",
 				"audit/*",
+				"audit-tail/*",
+				"audit-export/*",
+				"audit-test/*",
 				"raw",
 				"raw/*",
 				"replication/primary/secondary-token",
@@ -110,16 +118,26 @@ func NewSystemBackend(core *Core, logger log.Logger) *SystemBackend {
 				"rotate",
 				"config/cors",
 				"config/auditing/*",
+				"config/state",
+				"config/state/drift",
 				"config/ui/headers/*",
 				"plugins/catalog/*",
+				"plugins/runtime/*",
+				"import",
+				"import/*",
+				"advisor",
 				"revoke-prefix/*",
 				"revoke-force/*",
 				"leases/revoke-prefix/*",
 				"leases/revoke-force/*",
 				"leases/lookup/*",
+				"leases/export",
+				"leases/import",
 				"storage/raft/snapshot-auto/config/*",
 				"leases",
 				"internal/inspect/*",
+				"internal/debug/*",
+				"pprof/capture/config",
 				// sys/seal and sys/step-down actually have their sudo requirement enforced through hardcoding
 				// PolicyCheckOpts.RootPrivsRequired in dedicated calls to Core.performPolicyChecks, but we still need
 				// to declare them here so that the generated OpenAPI spec gets their sudo status correct.
@@ -179,16 +197,23 @@ func NewSystemBackend(core *Core, logger log.Logger) *SystemBackend {
 
 	b.Backend.Paths = append(b.Backend.Paths, entPaths(b)...)
 	b.Backend.Paths = append(b.Backend.Paths, b.configPaths()...)
+	b.Backend.Paths = append(b.Backend.Paths, b.configStatePaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.rekeyPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.sealPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.statusPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.pluginsCatalogListPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.pluginsCatalogCRUDPath())
 	b.Backend.Paths = append(b.Backend.Paths, b.pluginsReloadPath())
+	b.Backend.Paths = append(b.Backend.Paths, b.pluginsRuntimeStatsPath())
+	b.Backend.Paths = append(b.Backend.Paths, b.secretsImportPaths()...)
+	b.Backend.Paths = append(b.Backend.Paths, b.advisorPath())
+	b.Backend.Paths = append(b.Backend.Paths, b.rollbackPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.auditPaths()...)
+	b.Backend.Paths = append(b.Backend.Paths, b.mountOwnerPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.mountPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.authPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.lockedUserPaths()...)
+	b.Backend.Paths = append(b.Backend.Paths, b.leaseMigrationPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.leasePaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.policyPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.wrappingPaths()...)
@@ -196,11 +221,15 @@ func NewSystemBackend(core *Core, logger log.Logger) *SystemBackend {
 	b.Backend.Paths = append(b.Backend.Paths, b.capabilitiesPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.internalPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.pprofPaths()...)
+	b.Backend.Paths = append(b.Backend.Paths, b.debugPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.remountPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.metricsPath())
 	b.Backend.Paths = append(b.Backend.Paths, b.monitorPath())
 	b.Backend.Paths = append(b.Backend.Paths, b.inFlightRequestPath())
+	b.Backend.Paths = append(b.Backend.Paths, b.eventsSubscriptionsPath())
 	b.Backend.Paths = append(b.Backend.Paths, b.hostInfoPath())
+	b.Backend.Paths = append(b.Backend.Paths, b.batchPaths()...)
+	b.Backend.Paths = append(b.Backend.Paths, b.kvRecursiveDeletePaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.quotasPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.rootActivityPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.loginMFAPaths()...)
@@ -247,6 +276,15 @@ type SystemBackend struct {
 	db         *memdb.MemDB
 	logger     log.Logger
 	mfaBackend *PolicyMFABackend
+
+	// monitorStreams counts the concurrent monitor streams of each token,
+	// keyed by token accessor, to limit them.
+	monitorStreamsLock sync.Mutex
+	monitorStreams     map[string]int
+
+	// configStateLock serializes the applications of declarative states
+	// with sys/config/state.
+	configStateLock sync.Mutex
 }
 
 // handleConfigStateSanitized returns the current configuration state. The configuration
@@ -329,6 +367,54 @@ func (b *SystemBackend) handleTidyLeases(ctx context.Context, req *logical.Reque
 	return logical.RespondWithStatusCode(resp, req, http.StatusAccepted)
 }
 
+func (b *SystemBackend) handleKVRecursiveDeleteStart(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	return b.Core.handleKVRecursiveDelete(ctx, req, d.Get("path").(string), d.Get("dry_run").(bool))
+}
+
+func (b *SystemBackend) handleKVRecursiveDeleteList(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return logical.ListResponse(b.Core.kvRecursiveDeletes.list(ns)), nil
+}
+
+func (b *SystemBackend) handleKVRecursiveDeleteRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	job := b.Core.kvRecursiveDeletes.get(ns, d.Get("id").(string))
+	if job == nil {
+		return nil, nil
+	}
+	return &logical.Response{
+		Data: job.toMap(),
+	}, nil
+}
+
+func (b *SystemBackend) handleRevokeStatusList(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return logical.ListResponse(b.Core.expiration.RevokeTreeJobs(ns)), nil
+}
+
+func (b *SystemBackend) handleRevokeStatusRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status := b.Core.expiration.RevokeTreeJobStatus(ns, d.Get("job_id").(string))
+	if status == nil {
+		return nil, nil
+	}
+	return &logical.Response{
+		Data: status,
+	}, nil
+}
+
 func (b *SystemBackend) handleLeaseCount(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
 	typeRaw, ok := d.GetOk("type")
 	if !ok || strings.ToLower(typeRaw.(string)) != "irrevocable" {
@@ -722,6 +808,49 @@ func (b *SystemBackend) handlePluginReloadUpdate(ctx context.Context, req *logic
 	return &r, nil
 }
 
+// handlePluginRuntimeStats returns the resource usage of the external plugin
+// processes run by this node, along with the restart policy applied to them.
+func (b *SystemBackend) handlePluginRuntimeStats(ctx context.Context, _ *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	stats := b.Core.pluginCatalog.ProcessStats(ctx)
+
+	processes := make([]map[string]interface{}, 0, len(stats))
+	for _, s := range stats {
+		process := map[string]interface{}{
+			"name":              s.Name,
+			"type":              s.Type,
+			"version":           s.Version,
+			"pid":               s.PID,
+			"multiplexed":       s.Multiplexed,
+			"connections":       s.Connections,
+			"restarts":          s.Restarts,
+			"last_restart_time": "",
+		}
+		if !s.LastRestart.IsZero() {
+			process["last_restart_time"] = s.LastRestart.Format(time.RFC3339Nano)
+		}
+		if s.Usage != nil {
+			process["cpu_user_seconds"] = s.Usage.CPUUserSeconds
+			process["cpu_system_seconds"] = s.Usage.CPUSystemSeconds
+			process["memory_rss"] = s.Usage.MemoryRSS
+			process["memory_vms"] = s.Usage.MemoryVMS
+			process["num_threads"] = s.Usage.NumThreads
+		}
+		processes = append(processes, process)
+	}
+
+	policy := b.Core.pluginCatalog.restartPolicy
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"processes": processes,
+			"restart_policy": map[string]interface{}{
+				"max_memory":      policy.MaxMemory,
+				"max_restarts":    policy.MaxRestarts,
+				"restart_backoff": int64(policy.backoff(1).Seconds()),
+			},
+		},
+	}, nil
+}
+
 // handleAuditedHeaderUpdate creates or overwrites a header entry
 func (b *SystemBackend) handleAuditedHeaderUpdate(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
 	header := d.Get("header").(string)
@@ -962,6 +1091,9 @@ func (b *SystemBackend) mountInfo(ctx context.Context, entry *MountEntry) map[st
 		"running_plugin_version":  entry.RunningVersion,
 		"running_sha256":          entry.RunningSha256,
 	}
+	if entry.DataKey {
+		info["data_key"] = true
+	}
 	entryConfig := map[string]interface{}{
 		"default_lease_ttl": int64(entry.Config.DefaultLeaseTTL.Seconds()),
 		"max_lease_ttl":     int64(entry.Config.MaxLeaseTTL.Seconds()),
@@ -984,11 +1116,32 @@ func (b *SystemBackend) mountInfo(ctx context.Context, entry *MountEntry) map[st
 	if rawVal, ok := entry.synthesizedConfigCache.Load("allowed_response_headers"); ok {
 		entryConfig["allowed_response_headers"] = rawVal.([]string)
 	}
+	if entry.Config.RollbackPeriod > 0 {
+		entryConfig["rollback_period"] = int64(entry.Config.RollbackPeriod.Seconds())
+	}
+	if entry.Config.LeaseTTLJitter > 0 {
+		entryConfig["lease_ttl_jitter"] = entry.Config.LeaseTTLJitter
+	}
+	if entry.Config.MaxRequestSize > 0 {
+		entryConfig["max_request_size"] = entry.Config.MaxRequestSize
+	}
+	if entry.Config.MaxResponseSize > 0 {
+		entryConfig["max_response_size"] = entry.Config.MaxResponseSize
+	}
+	if entry.Config.IdentityTokenRole != "" {
+		entryConfig["identity_token_role"] = entry.Config.IdentityTokenRole
+	}
 	if rawVal, ok := entry.synthesizedConfigCache.Load("allowed_managed_keys"); ok {
 		entryConfig["allowed_managed_keys"] = rawVal.([]string)
 	}
 	if entry.Table == credentialTableType {
 		entryConfig["token_type"] = entry.Config.TokenType.String()
+		if entry.Config.TokenBindSourceCIDR {
+			entryConfig["token_bind_source_cidr"] = true
+		}
+		if entry.Config.TokenBindClientCert {
+			entryConfig["token_bind_client_cert"] = true
+		}
 	}
 	if entry.Config.UserLockoutConfig != nil {
 		userLockoutConfig := map[string]interface{}{
@@ -1067,6 +1220,7 @@ func (b *SystemBackend) handleMount(ctx context.Context, req *logical.Request, d
 	description := data.Get("description").(string)
 	pluginName := data.Get("plugin_name").(string)
 	sealWrap := data.Get("seal_wrap").(bool)
+	dataKey := data.Get("data_key").(bool)
 	externalEntropyAccess := data.Get("external_entropy_access").(bool)
 	options := data.Get("options").(map[string]string)
 
@@ -1214,6 +1368,7 @@ func (b *SystemBackend) handleMount(ctx context.Context, req *logical.Request, d
 		Config:                config,
 		Local:                 local,
 		SealWrap:              sealWrap,
+		DataKey:               dataKey,
 		ExternalEntropyAccess: externalEntropyAccess,
 		Options:               options,
 		Version:               pluginVersion,
@@ -1284,6 +1439,57 @@ func (b *SystemBackend) handleReadMount(ctx context.Context, req *logical.Reques
 	}, nil
 }
 
+// handleMountDataKeyRead returns the status of the data key of a mount
+func (b *SystemBackend) handleMountDataKeyRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	path := sanitizePath(data.Get("path").(string))
+
+	entry := b.Core.router.MatchingMountEntry(ctx, path)
+	if entry == nil {
+		return logical.ErrorResponse("No secret engine mount at %s", path), nil
+	}
+	s := b.Core.mountDataKeyStorage(entry)
+	if s == nil {
+		return logical.ErrorResponse("secret engine mount at %s has no data key", path), nil
+	}
+
+	term, installTime, rotating := s.status()
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"term":         term,
+			"install_time": installTime.Format(time.RFC3339Nano),
+			"rotating":     rotating,
+		},
+	}, nil
+}
+
+// handleMountDataKeyRotate rotates the data key of a mount
+func (b *SystemBackend) handleMountDataKeyRotate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	path := sanitizePath(data.Get("path").(string))
+
+	entry := b.Core.router.MatchingMountEntry(ctx, path)
+	if entry == nil {
+		return logical.ErrorResponse("No secret engine mount at %s", path), nil
+	}
+	if !entry.DataKey {
+		return logical.ErrorResponse("secret engine mount at %s has no data key", path), nil
+	}
+
+	term, err := b.Core.rotateMountDataKey(ctx, entry)
+	switch {
+	case errors.Is(err, errDataKeyRotationInProgress):
+		return logical.ErrorResponse(err.Error()), nil
+	case err != nil:
+		b.Backend.Logger().Error("failed to rotate mount data key", "path", path, "error", err)
+		return handleError(err)
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"term": term,
+		},
+	}, nil
+}
+
 // used to intercept an HTTPCodedError so it goes back to callee
 func handleError(
 	err error,
@@ -1635,6 +1841,12 @@ func (b *SystemBackend) handleTuneReadCommon(ctx context.Context, path string) (
 
 	if mountEntry.Table == credentialTableType {
 		resp.Data["token_type"] = mountEntry.Config.TokenType.String()
+		if mountEntry.Config.TokenBindSourceCIDR {
+			resp.Data["token_bind_source_cidr"] = true
+		}
+		if mountEntry.Config.TokenBindClientCert {
+			resp.Data["token_bind_client_cert"] = true
+		}
 	}
 
 	if rawVal, ok := mountEntry.synthesizedConfigCache.Load("audit_non_hmac_request_keys"); ok {
@@ -1661,6 +1873,26 @@ func (b *SystemBackend) handleTuneReadCommon(ctx context.Context, path string) (
 		resp.Data["allowed_managed_keys"] = rawVal.([]string)
 	}
 
+	if mountEntry.Config.RollbackPeriod > 0 {
+		resp.Data["rollback_period"] = int64(mountEntry.Config.RollbackPeriod.Seconds())
+	}
+
+	if mountEntry.Config.LeaseTTLJitter > 0 {
+		resp.Data["lease_ttl_jitter"] = mountEntry.Config.LeaseTTLJitter
+	}
+
+	if mountEntry.Config.MaxRequestSize > 0 {
+		resp.Data["max_request_size"] = mountEntry.Config.MaxRequestSize
+	}
+
+	if mountEntry.Config.MaxResponseSize > 0 {
+		resp.Data["max_response_size"] = mountEntry.Config.MaxResponseSize
+	}
+
+	if mountEntry.Config.IdentityTokenRole != "" {
+		resp.Data["identity_token_role"] = mountEntry.Config.IdentityTokenRole
+	}
+
 	if mountEntry.Config.UserLockoutConfig != nil {
 		resp.Data["user_lockout_counter_reset_duration"] = int64(mountEntry.Config.UserLockoutConfig.LockoutCounterReset.Seconds())
 		resp.Data["user_lockout_threshold"] = mountEntry.Config.UserLockoutConfig.LockoutThreshold
@@ -2033,6 +2265,95 @@ func (b *SystemBackend) handleTuneWriteCommon(ctx context.Context, path string,
 		}
 	}
 
+	if rawVal, ok := data.GetOk("rollback_period"); ok {
+		rollbackPeriod, err := parseutil.ParseDurationSecond(rawVal.(string))
+		if err != nil {
+			return logical.ErrorResponse(fmt.Sprintf("invalid rollback_period: %s", err)), logical.ErrInvalidRequest
+		}
+		if rollbackPeriod != 0 && rollbackPeriod < minRollbackPeriod {
+			return logical.ErrorResponse(fmt.Sprintf("rollback_period must be at least %s", minRollbackPeriod)), logical.ErrInvalidRequest
+		}
+
+		oldVal := mountEntry.Config.RollbackPeriod
+		mountEntry.Config.RollbackPeriod = rollbackPeriod
+
+		// Update the mount table
+		switch {
+		case strings.HasPrefix(path, "auth/"):
+			err = b.Core.persistAuth(ctx, b.Core.auth, &mountEntry.Local)
+		default:
+			err = b.Core.persistMounts(ctx, b.Core.mounts, &mountEntry.Local)
+		}
+		if err != nil {
+			mountEntry.Config.RollbackPeriod = oldVal
+			return handleError(err)
+		}
+
+		if b.Core.logger.IsInfo() {
+			b.Core.logger.Info("mount tuning of rollback_period successful", "path", path, "rollback_period", rollbackPeriod)
+		}
+	}
+
+	if rawVal, ok := data.GetOk("lease_ttl_jitter"); ok {
+		if strings.HasPrefix(path, "auth/") {
+			return logical.ErrorResponse("'lease_ttl_jitter' can only be modified on secrets engine mounts"), logical.ErrInvalidRequest
+		}
+		jitter := rawVal.(int)
+		if jitter < 0 || jitter > maxLeaseTTLJitter {
+			return logical.ErrorResponse(fmt.Sprintf("lease_ttl_jitter must be between 0 and %d", maxLeaseTTLJitter)), logical.ErrInvalidRequest
+		}
+
+		oldVal := mountEntry.Config.LeaseTTLJitter
+		mountEntry.Config.LeaseTTLJitter = jitter
+
+		// Update the mount table
+		if err := b.Core.persistMounts(ctx, b.Core.mounts, &mountEntry.Local); err != nil {
+			mountEntry.Config.LeaseTTLJitter = oldVal
+			return handleError(err)
+		}
+
+		if b.Core.logger.IsInfo() {
+			b.Core.logger.Info("mount tuning of lease_ttl_jitter successful", "path", path, "lease_ttl_jitter", jitter)
+		}
+	}
+
+	for _, limit := range []struct {
+		name  string
+		value *int64
+	}{
+		{"max_request_size", &mountEntry.Config.MaxRequestSize},
+		{"max_response_size", &mountEntry.Config.MaxResponseSize},
+	} {
+		rawVal, ok := data.GetOk(limit.name)
+		if !ok {
+			continue
+		}
+		size := rawVal.(int64)
+		if size < 0 {
+			return logical.ErrorResponse(fmt.Sprintf("%s cannot be negative", limit.name)), logical.ErrInvalidRequest
+		}
+
+		oldVal := *limit.value
+		*limit.value = size
+
+		// Update the mount table
+		var err error
+		switch {
+		case strings.HasPrefix(path, "auth/"):
+			err = b.Core.persistAuth(ctx, b.Core.auth, &mountEntry.Local)
+		default:
+			err = b.Core.persistMounts(ctx, b.Core.mounts, &mountEntry.Local)
+		}
+		if err != nil {
+			*limit.value = oldVal
+			return handleError(err)
+		}
+
+		if b.Core.logger.IsInfo() {
+			b.Core.logger.Info("mount tuning of "+limit.name+" successful", "path", path, limit.name, size)
+		}
+	}
+
 	if rawVal, ok := data.GetOk("token_type"); ok {
 		if !strings.HasPrefix(path, "auth/") {
 			return logical.ErrorResponse(fmt.Sprintf("'token_type' can only be modified on auth mounts")), logical.ErrInvalidRequest
@@ -2071,6 +2392,65 @@ func (b *SystemBackend) handleTuneWriteCommon(ctx context.Context, path string,
 		}
 	}
 
+	for _, binding := range []struct {
+		param string
+		field *bool
+	}{
+		{"token_bind_source_cidr", &mountEntry.Config.TokenBindSourceCIDR},
+		{"token_bind_client_cert", &mountEntry.Config.TokenBindClientCert},
+	} {
+		rawVal, ok := data.GetOk(binding.param)
+		if !ok {
+			continue
+		}
+		if !strings.HasPrefix(path, "auth/") {
+			return logical.ErrorResponse(fmt.Sprintf("'%s' can only be modified on auth mounts", binding.param)), logical.ErrInvalidRequest
+		}
+		if mountEntry.Type == "token" || mountEntry.Type == "ns_token" {
+			return logical.ErrorResponse(fmt.Sprintf("'%s' cannot be set for 'token' or 'ns_token' auth mounts", binding.param)), logical.ErrInvalidRequest
+		}
+
+		oldVal := *binding.field
+		*binding.field = rawVal.(bool)
+
+		// Update the mount table
+		if err := b.Core.persistAuth(ctx, b.Core.auth, &mountEntry.Local); err != nil {
+			*binding.field = oldVal
+			return handleError(err)
+		}
+
+		if b.Core.logger.IsInfo() {
+			b.Core.logger.Info(fmt.Sprintf("mount tuning of %s successful", binding.param), "path", path, binding.param, *binding.field)
+		}
+	}
+
+	if rawVal, ok := data.GetOk("identity_token_role"); ok {
+		if mountEntry.Type == "token" || mountEntry.Type == "ns_token" {
+			return logical.ErrorResponse("'identity_token_role' cannot be set for 'token' or 'ns_token' auth mounts"), logical.ErrInvalidRequest
+		}
+		role := rawVal.(string)
+
+		oldVal := mountEntry.Config.IdentityTokenRole
+		mountEntry.Config.IdentityTokenRole = role
+
+		// Update the mount table
+		var err error
+		switch {
+		case strings.HasPrefix(path, "auth/"):
+			err = b.Core.persistAuth(ctx, b.Core.auth, &mountEntry.Local)
+		default:
+			err = b.Core.persistMounts(ctx, b.Core.mounts, &mountEntry.Local)
+		}
+		if err != nil {
+			mountEntry.Config.IdentityTokenRole = oldVal
+			return handleError(err)
+		}
+
+		if b.Core.logger.IsInfo() {
+			b.Core.logger.Info("mount tuning of identity_token_role successful", "path", path, "identity_token_role", role)
+		}
+	}
+
 	if rawVal, ok := data.GetOk("passthrough_request_headers"); ok {
 		headers := rawVal.([]string)
 
@@ -2629,6 +3009,9 @@ func (b *SystemBackend) handleEnableAuth(ctx context.Context, req *logical.Reque
 			"invalid value for 'token_type'")), logical.ErrInvalidRequest
 	}
 
+	config.TokenBindSourceCIDR = apiConfig.TokenBindSourceCIDR
+	config.TokenBindClientCert = apiConfig.TokenBindClientCert
+
 	switch logicalType {
 	case "":
 		return logical.ErrorResponse(
@@ -2972,6 +3355,7 @@ func (b *SystemBackend) handlePoliciesSet(policyType PolicyType) framework.Opera
 				return errResp, nil
 			}
 		}
+		policy.author = policyVersionAuthorFromRequest(req)
 
 		// Update the policy
 		if err := b.Core.policyStore.SetPolicy(ctx, policy); err != nil {
@@ -2993,6 +3377,119 @@ func (b *SystemBackend) handlePoliciesDelete(policyType PolicyType) framework.Op
 	}
 }
 
+// policyVersionResponseData returns the response data describing a version
+// of an ACL policy.
+func policyVersionResponseData(name string, v *PolicyVersion) map[string]interface{} {
+	data := map[string]interface{}{
+		"name":           name,
+		"version":        v.Version,
+		"created_time":   "",
+		"token_accessor": "",
+		"entity_id":      "",
+		"display_name":   "",
+	}
+	if !v.CreatedTime.IsZero() {
+		data["created_time"] = v.CreatedTime.Format(time.RFC3339Nano)
+	}
+	if v.Author != nil {
+		data["token_accessor"] = v.Author.TokenAccessor
+		data["entity_id"] = v.Author.EntityID
+		data["display_name"] = v.Author.DisplayName
+	}
+	return data
+}
+
+// handlePoliciesACLVersionsList handles the "/sys/policies/acl/<name>/versions"
+// endpoint to list the retained versions of an ACL policy
+func (b *SystemBackend) handlePoliciesACLVersionsList(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	versions, err := b.Core.policyStore.ListACLPolicyVersions(ctx, name)
+	if err != nil {
+		return handleError(err)
+	}
+
+	keys := make([]string, 0, len(versions))
+	keyInfo := make(map[string]interface{}, len(versions))
+	for _, v := range versions {
+		key := strconv.Itoa(v.Version)
+		keys = append(keys, key)
+
+		info := policyVersionResponseData(name, v)
+		delete(info, "name")
+		keyInfo[key] = info
+	}
+
+	return logical.ListResponseWithInfo(keys, keyInfo), nil
+}
+
+// handlePoliciesACLVersionRead handles the
+// "/sys/policies/acl/<name>/versions/<version>" endpoint to read a version of
+// an ACL policy
+func (b *SystemBackend) handlePoliciesACLVersionRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	v, err := b.Core.policyStore.GetACLPolicyVersion(ctx, name, data.Get("version").(int))
+	if err != nil {
+		return handleError(err)
+	}
+	if v == nil {
+		return nil, nil
+	}
+
+	respData := policyVersionResponseData(name, v)
+	respData["policy"] = v.Raw
+	return &logical.Response{
+		Data: respData,
+	}, nil
+}
+
+// handlePoliciesACLVersionRestore handles the
+// "/sys/policies/acl/<name>/versions/<version>/restore" endpoint to make a
+// previous version of an ACL policy the current one. Restoring writes a new
+// version, so the history records who performed the rollback.
+func (b *SystemBackend) handlePoliciesACLVersionRestore(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	name := data.Get("name").(string)
+	version := data.Get("version").(int)
+
+	v, err := b.Core.policyStore.GetACLPolicyVersion(ctx, name, version)
+	if err != nil {
+		return handleError(err)
+	}
+	if v == nil {
+		return logical.ErrorResponse("version %d of policy %q not found", version, name), logical.ErrInvalidRequest
+	}
+
+	policy, err := ParseACLPolicy(ns, v.Raw)
+	if err != nil {
+		return handleError(err)
+	}
+	policy.Name = name
+	policy.Type = PolicyTypeACL
+	policy.author = policyVersionAuthorFromRequest(req)
+
+	if err := b.Core.policyStore.SetPolicy(ctx, policy); err != nil {
+		return handleError(err)
+	}
+
+	versions, err := b.Core.policyStore.ListACLPolicyVersions(ctx, name)
+	if err != nil {
+		return handleError(err)
+	}
+	if len(versions) == 0 {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: policyVersionResponseData(policy.Name, versions[len(versions)-1]),
+	}, nil
+}
+
 type passwordPolicyConfig struct {
 	HCLPolicy string `json:"policy"`
 }
@@ -3045,15 +3542,17 @@ func (*SystemBackend) handlePoliciesPasswordSet(ctx context.Context, req *logica
 			fmt.Sprintf("passwords must be between %d and %d characters", minPasswordLength, maxPasswordLength))
 	}
 
-	// Attempt to construct a test password from the rules to ensure that the policy isn't impossible
-	var testPassword []rune
-
+	// Attempt to construct a test password from the charset rules to ensure that the policy isn't impossible
+	var charsetRules []random.CharsetRule
 	for _, rule := range policy.Rules {
-		charsetRule, ok := rule.(random.CharsetRule)
-		if !ok {
-			return nil, logical.CodedError(http.StatusBadRequest, fmt.Sprintf("unexpected rule type %T", charsetRule))
+		if charsetRule, ok := rule.(random.CharsetRule); ok {
+			charsetRules = append(charsetRules, charsetRule)
 		}
+	}
+
+	var testPassword []rune
 
+	for _, charsetRule := range charsetRules {
 		for j := 0; j < charsetRule.MinLength(); j++ {
 			charIndex := rand.Intn(len(charsetRule.Chars()))
 			testPassword = append(testPassword, charsetRule.Chars()[charIndex])
@@ -3061,14 +3560,10 @@ func (*SystemBackend) handlePoliciesPasswordSet(ctx context.Context, req *logica
 	}
 
 	for i := len(testPassword); i < policy.Length; i++ {
-		for _, rule := range policy.Rules {
+		for _, charsetRule := range charsetRules {
 			if len(testPassword) >= policy.Length {
 				break
 			}
-			charsetRule, ok := rule.(random.CharsetRule)
-			if !ok {
-				return nil, logical.CodedError(http.StatusBadRequest, fmt.Sprintf("unexpected rule type %T", charsetRule))
-			}
 
 			charIndex := rand.Intn(len(charsetRule.Chars()))
 			testPassword = append(testPassword, charsetRule.Chars()[charIndex])
@@ -3079,12 +3574,26 @@ func (*SystemBackend) handlePoliciesPasswordSet(ctx context.Context, req *logica
 		testPassword[i], testPassword[j] = testPassword[j], testPassword[i]
 	})
 
-	for _, rule := range policy.Rules {
-		if !rule.Pass(testPassword) {
+	for _, charsetRule := range charsetRules {
+		if !charsetRule.Pass(testPassword) {
 			return nil, logical.CodedError(http.StatusBadRequest, "unable to construct test password from provided policy: are the rules impossible?")
 		}
 	}
 
+	// The other rules reject random passwords, so the policy is checked by generating one
+	if len(charsetRules) < len(policy.Rules) {
+		err = resolvePasswordPolicyWordlists(ctx, req.Storage, &policy)
+		if err != nil {
+			return nil, logical.CodedError(http.StatusBadRequest, fmt.Sprintf("invalid password policy: %s", err))
+		}
+
+		generateCtx, cancel := context.WithTimeout(ctx, 1*time.Second)
+		defer cancel()
+		if _, err := policy.Generate(generateCtx, nil); err != nil {
+			return nil, logical.CodedError(http.StatusBadRequest, "unable to generate test password from provided policy: are the rules impossible?")
+		}
+	}
+
 	cfg := passwordPolicyConfig{
 		HCLPolicy: rawPolicy,
 	}
@@ -3176,10 +3685,10 @@ func (*SystemBackend) handlePoliciesPasswordGenerate(ctx context.Context, req *l
 		return nil, logical.CodedError(http.StatusNotFound, "policy does not exist")
 	}
 
-	policy, err := random.ParsePolicy(cfg.HCLPolicy)
+	policy, err := parsePasswordPolicy(ctx, req.Storage, cfg.HCLPolicy)
 	if err != nil {
 		return nil, logical.CodedError(http.StatusInternalServerError,
-			"stored password policy configuration failed to parse")
+			fmt.Sprintf("stored password policy configuration failed to parse: %s", err))
 	}
 
 	password, err := policy.Generate(ctx, nil)
@@ -3196,6 +3705,189 @@ func (*SystemBackend) handlePoliciesPasswordGenerate(ctx context.Context, req *l
 	return resp, nil
 }
 
+// handlePoliciesPasswordValidate validates a password against the rules of the specified password policy
+func (*SystemBackend) handlePoliciesPasswordValidate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	policyName := data.Get("name").(string)
+	if policyName == "" {
+		return nil, logical.CodedError(http.StatusBadRequest, "missing policy name")
+	}
+
+	password := []rune(data.Get("password").(string))
+	if len(password) == 0 {
+		return nil, logical.CodedError(http.StatusBadRequest, "missing password")
+	}
+
+	cfg, err := retrievePasswordPolicy(ctx, req.Storage, policyName)
+	if err != nil {
+		return nil, logical.CodedError(http.StatusInternalServerError, "failed to retrieve password policy")
+	}
+	if cfg == nil {
+		return nil, logical.CodedError(http.StatusNotFound, "policy does not exist")
+	}
+
+	policy, err := parsePasswordPolicy(ctx, req.Storage, cfg.HCLPolicy)
+	if err != nil {
+		return nil, logical.CodedError(http.StatusInternalServerError,
+			fmt.Sprintf("stored password policy configuration failed to parse: %s", err))
+	}
+
+	// The length of the policy only applies to generated passwords
+	failedRules := []string{}
+	for _, rule := range policy.Rules {
+		if !rule.Pass(password) && !strutil.StrListContains(failedRules, rule.Type()) {
+			failedRules = append(failedRules, rule.Type())
+		}
+	}
+
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"valid":        len(failedRules) == 0,
+			"failed_rules": failedRules,
+			"entropy_bits": random.EstimateEntropy(password),
+		},
+	}
+	return resp, nil
+}
+
+// parsePasswordPolicy parses a password policy, reading the wordlists of its dictionary rules from the logical storage
+func parsePasswordPolicy(ctx context.Context, storage logical.Storage, rawPolicy string) (*random.StringGenerator, error) {
+	policy, err := random.ParsePolicy(rawPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	err = resolvePasswordPolicyWordlists(ctx, storage, &policy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// resolvePasswordPolicyWordlists reads the wordlists of the dictionary rules of a password policy from the logical storage
+func resolvePasswordPolicyWordlists(ctx context.Context, storage logical.Storage, policy *random.StringGenerator) error {
+	return policy.ResolveWordlists(func(name string) ([]string, error) {
+		wordlist, err := retrievePasswordPolicyWordlist(ctx, storage, name)
+		if err != nil {
+			return nil, err
+		}
+		if wordlist == nil {
+			return nil, fmt.Errorf("wordlist does not exist")
+		}
+		return wordlist.Words, nil
+	})
+}
+
+type passwordPolicyWordlist struct {
+	Words []string `json:"words"`
+}
+
+func getPasswordPolicyWordlistKey(wordlistName string) string {
+	return fmt.Sprintf("password_policy_wordlist/%s", wordlistName)
+}
+
+// handlePoliciesPasswordWordlistList returns the list of password policy wordlists
+func (*SystemBackend) handlePoliciesPasswordWordlistList(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	keys, err := req.Storage.List(ctx, "password_policy_wordlist/")
+	if err != nil {
+		return nil, err
+	}
+
+	return logical.ListResponse(keys), nil
+}
+
+// handlePoliciesPasswordWordlistSet saves/updates password policy wordlists
+func (*SystemBackend) handlePoliciesPasswordWordlistSet(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	wordlistName := data.Get("name").(string)
+	if wordlistName == "" {
+		return nil, logical.CodedError(http.StatusBadRequest, "missing wordlist name")
+	}
+
+	var words []string
+	for _, word := range data.Get("words").([]string) {
+		if word = strings.TrimSpace(word); word != "" {
+			words = append(words, word)
+		}
+	}
+	if len(words) == 0 {
+		return nil, logical.CodedError(http.StatusBadRequest, "missing words")
+	}
+
+	entry, err := logical.StorageEntryJSON(getPasswordPolicyWordlistKey(wordlistName), passwordPolicyWordlist{
+		Words: strutil.RemoveDuplicates(words, false),
+	})
+	if err != nil {
+		return nil, logical.CodedError(http.StatusInternalServerError, fmt.Sprintf("unable to save wordlist: %s", err))
+	}
+
+	err = req.Storage.Put(ctx, entry)
+	if err != nil {
+		return nil, logical.CodedError(http.StatusInternalServerError,
+			fmt.Sprintf("failed to save wordlist to storage backend: %s", err))
+	}
+
+	return logical.RespondWithStatusCode(nil, req, http.StatusNoContent)
+}
+
+// handlePoliciesPasswordWordlistGet retrieves a password policy wordlist if it exists
+func (*SystemBackend) handlePoliciesPasswordWordlistGet(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	wordlistName := data.Get("name").(string)
+	if wordlistName == "" {
+		return nil, logical.CodedError(http.StatusBadRequest, "missing wordlist name")
+	}
+
+	wordlist, err := retrievePasswordPolicyWordlist(ctx, req.Storage, wordlistName)
+	if err != nil {
+		return nil, logical.CodedError(http.StatusInternalServerError, "failed to retrieve wordlist")
+	}
+	if wordlist == nil {
+		return nil, logical.CodedError(http.StatusNotFound, "wordlist does not exist")
+	}
+
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"words": wordlist.Words,
+		},
+	}
+
+	return resp, nil
+}
+
+// retrievePasswordPolicyWordlist retrieves a password policy wordlist from the logical storage
+func retrievePasswordPolicyWordlist(ctx context.Context, storage logical.Storage, wordlistName string) (*passwordPolicyWordlist, error) {
+	entry, err := storage.Get(ctx, getPasswordPolicyWordlistKey(wordlistName))
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	wordlist := &passwordPolicyWordlist{}
+	err = json.Unmarshal(entry.Value, wordlist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal stored data: %w", err)
+	}
+
+	return wordlist, nil
+}
+
+// handlePoliciesPasswordWordlistDelete deletes a password policy wordlist if it exists
+func (*SystemBackend) handlePoliciesPasswordWordlistDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	wordlistName := data.Get("name").(string)
+	if wordlistName == "" {
+		return nil, logical.CodedError(http.StatusBadRequest, "missing wordlist name")
+	}
+
+	err := req.Storage.Delete(ctx, getPasswordPolicyWordlistKey(wordlistName))
+	if err != nil {
+		return nil, logical.CodedError(http.StatusInternalServerError,
+			fmt.Sprintf("failed to delete wordlist: %s", err))
+	}
+
+	return nil, nil
+}
+
 // handleAuditTable handles the "audit" endpoint to provide the audit table
 func (b *SystemBackend) handleAuditTable(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 	b.Core.auditLock.RLock()
@@ -3240,6 +3932,141 @@ func (b *SystemBackend) handleAuditHash(ctx context.Context, req *logical.Reques
 	}, nil
 }
 
+// handleAuditTail streams the entries logged by an audit device, as newline
+// delimited JSON, until the client disconnects, the device is disabled or the
+// core is sealed.
+func (b *SystemBackend) handleAuditTail(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	path := sanitizePath(data.Get("path").(string))
+	filter := data.Get("filter").(string)
+	w := req.ResponseWriter
+
+	flusher, ok := w.ResponseWriter.(http.Flusher)
+	if !ok {
+		// http.ResponseWriter is wrapped in wrapGenericHandler, so let's
+		// access the underlying functionality
+		nw, ok := w.ResponseWriter.(logical.WrappingResponseWriter)
+		if !ok {
+			return logical.ErrorResponse("streaming not supported"), nil
+		}
+		flusher, ok = nw.Wrapped().(http.Flusher)
+		if !ok {
+			return logical.ErrorResponse("streaming not supported"), nil
+		}
+	}
+
+	entryCh, cancel, err := b.Core.auditBroker.Tail(path, filter)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	defer cancel()
+
+	w.WriteHeader(http.StatusOK)
+
+	// 0 byte write is needed before the Flush call so that if we are using
+	// a gzip stream it will go ahead and write out the HTTP response header
+	_, err = w.Write([]byte(""))
+	if err != nil {
+		return nil, fmt.Errorf("error seeding flusher: %w", err)
+	}
+
+	flusher.Flush()
+
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	// Stream entries until the connection is closed. As with the monitor,
+	// errors after this point are ignored upstream since the response has
+	// already been sent.
+	for {
+		select {
+		case <-ticker.C:
+			if b.Core.Sealed() {
+				return nil, nil
+			}
+		case <-ctx.Done():
+			return nil, nil
+		case entry, ok := <-entryCh:
+			if !ok {
+				return nil, nil
+			}
+			if _, err := w.Write(append(entry, '\n')); err != nil {
+				return nil, fmt.Errorf("error streaming audit entries: %w", err)
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// handleAuditExport writes the archived entries of an audit device involving
+// the given entity, as newline delimited JSON, so that data access requests of
+// the entity can be answered without searching the log files.
+func (b *SystemBackend) handleAuditExport(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	path := sanitizePath(data.Get("path").(string))
+	entityID := data.Get("entity_id").(string)
+	if entityID == "" {
+		return logical.ErrorResponse("missing entity_id"), nil
+	}
+
+	w := req.ResponseWriter
+	if w == nil {
+		return logical.ErrorResponse("exporting audit entries requires a response writer"), nil
+	}
+
+	// Errors can only be returned until the first entry is written, after
+	// which the response has already been sent.
+	var written bool
+	err := b.Core.auditBroker.Export(ctx, path, entityID, func(entry []byte) error {
+		if !written {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.WriteHeader(http.StatusOK)
+			written = true
+		}
+		_, err := w.Write(append(entry, '\n'))
+		return err
+	})
+	switch {
+	case err != nil && !written:
+		return logical.ErrorResponse(err.Error()), nil
+	case err != nil:
+		return nil, fmt.Errorf("error exporting audit entries: %w", err)
+	}
+
+	// Without entries, nothing was written and a 204 is returned.
+	return nil, nil
+}
+
+// handleAuditTest logs a test message through the given audit backend
+func (b *SystemBackend) handleAuditTest(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	path := sanitizePath(data.Get("path").(string))
+
+	if err := b.Core.testAudit(ctx, path); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	return nil, nil
+}
+
+// handleAuditStatus returns the outcome of the attempts of the enabled audit
+// devices to log entries on this node
+func (b *SystemBackend) handleAuditStatus(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	resp := &logical.Response{
+		Data: make(map[string]interface{}),
+	}
+	for name, status := range b.Core.auditBroker.Status() {
+		resp.Data[name] = status.toResponseData()
+	}
+	return resp, nil
+}
+
+// handleAuditRotateHMAC rotates the HMAC key of the given audit backend
+func (b *SystemBackend) handleAuditRotateHMAC(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	path := sanitizePath(data.Get("path").(string))
+
+	if err := b.Core.rotateAuditHMAC(ctx, path); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	return nil, nil
+}
+
 // handleEnableAudit is used to enable a new audit backend
 func (b *SystemBackend) handleEnableAudit(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 	repState := b.Core.ReplicationState()
@@ -3726,6 +4553,37 @@ func (b *SystemBackend) handleInFlightRequestData(_ context.Context, req *logica
 	return resp, nil
 }
 
+// handleEventsSubscriptionsRead lists the subscribers of the event bus in the
+// namespace of the request and its children.
+func (b *SystemBackend) handleEventsSubscriptionsRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptions := make([]map[string]interface{}, 0)
+	for _, info := range b.Core.Events().Subscriptions(ns) {
+		subscription := map[string]interface{}{
+			"id":              info.ID,
+			"namespace_path":  info.NamespacePath,
+			"pattern":         info.Pattern,
+			"subscribed_time": info.SubscribedTime.Format(time.RFC3339Nano),
+			"pending_events":  info.PendingEvents,
+			"lag":             int64(info.Lag.Seconds()),
+		}
+		if !info.LastAckTime.IsZero() {
+			subscription["last_ack_time"] = info.LastAckTime.Format(time.RFC3339Nano)
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"subscriptions": subscriptions,
+		},
+	}, nil
+}
+
 func (b *SystemBackend) handleMonitor(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 	ll := data.Get("log_level").(string)
 	w := req.ResponseWriter
@@ -3747,6 +4605,17 @@ func (b *SystemBackend) handleMonitor(ctx context.Context, req *logical.Request,
 		return logical.ErrorResponse("unknown log format"), nil
 	}
 
+	subsystems := data.Get("subsystem").([]string)
+
+	var mountAccessors []string
+	for _, mount := range data.Get("mount").([]string) {
+		entry := b.Core.router.MatchingMountEntry(ctx, sanitizePath(mount))
+		if entry == nil {
+			return logical.ErrorResponse("no mount found at %q", mount), nil
+		}
+		mountAccessors = append(mountAccessors, entry.Accessor)
+	}
+
 	flusher, ok := w.ResponseWriter.(http.Flusher)
 	if !ok {
 		// http.ResponseWriter is wrapped in wrapGenericHandler, so let's
@@ -3761,13 +4630,29 @@ func (b *SystemBackend) handleMonitor(ctx context.Context, req *logical.Request,
 		}
 	}
 
+	tokenKey := req.ClientTokenAccessor
+	if tokenKey == "" {
+		tokenKey = req.ClientToken
+	}
+	if !b.acquireMonitorStream(tokenKey) {
+		return logical.RespondWithStatusCode(
+			logical.ErrorResponse("token already has the maximum of %d concurrent monitor streams", maxMonitorStreamsPerToken),
+			req, http.StatusTooManyRequests)
+	}
+	defer b.releaseMonitorStream(tokenKey)
+
 	isJson := b.Core.LogFormat() == "json" || lf == "json"
 	logger := b.Core.Logger().(log.InterceptLogger)
 
-	mon, err := monitor.NewMonitor(512, logger, &log.LoggerOptions{
+	var filter monitor.Filter
+	if len(subsystems) > 0 || len(mountAccessors) > 0 {
+		filter = monitorFilter(subsystems, mountAccessors)
+	}
+
+	mon, err := monitor.NewMonitorWithFilter(512, logger, &log.LoggerOptions{
 		Level:      logLevel,
 		JSONFormat: isJson,
-	})
+	}, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -3824,6 +4709,56 @@ func (b *SystemBackend) handleMonitor(ctx context.Context, req *logical.Request,
 	}
 }
 
+// maxMonitorStreamsPerToken is the maximum number of concurrent monitor
+// streams of a token.
+const maxMonitorStreamsPerToken = 5
+
+func (b *SystemBackend) acquireMonitorStream(tokenKey string) bool {
+	b.monitorStreamsLock.Lock()
+	defer b.monitorStreamsLock.Unlock()
+
+	if b.monitorStreams == nil {
+		b.monitorStreams = make(map[string]int)
+	}
+	if b.monitorStreams[tokenKey] >= maxMonitorStreamsPerToken {
+		return false
+	}
+	b.monitorStreams[tokenKey]++
+	return true
+}
+
+func (b *SystemBackend) releaseMonitorStream(tokenKey string) {
+	b.monitorStreamsLock.Lock()
+	defer b.monitorStreamsLock.Unlock()
+
+	b.monitorStreams[tokenKey]--
+	if b.monitorStreams[tokenKey] <= 0 {
+		delete(b.monitorStreams, tokenKey)
+	}
+}
+
+// monitorFilter selects the log messages of the given subsystems, such as
+// "core" or "expiration", including those of their named sub-loggers, or of
+// the mounts with the given accessors. Messages matching any of them are
+// selected.
+func monitorFilter(subsystems, mountAccessors []string) monitor.Filter {
+	return func(name string, _ log.Level) bool {
+		for _, subsystem := range subsystems {
+			if name == subsystem || strings.HasPrefix(name, subsystem+".") {
+				return true
+			}
+		}
+		// Loggers of mounts are named after their accessor, see
+		// Core.newLogicalBackend and Core.newCredentialBackend.
+		for _, component := range strings.Split(name, ".") {
+			if strutil.StrListContains(mountAccessors, component) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
 // handleHostInfo collects and returns host-related information, which includes
 // system information, cpu, disk, and memory usage. Any capture-related errors
 // returned by the collection method will be returned as response warnings.
@@ -3872,6 +4807,17 @@ func (b *SystemBackend) handleHostInfo(ctx context.Context, req *logical.Request
 	if info.Memory != nil {
 		respData["memory"] = info.Memory
 	}
+	if info.Runtime != nil {
+		respData["runtime"] = info.Runtime
+	}
+	if info.Process != nil {
+		respData["process"] = info.Process
+	}
+	// Memory is locked on start when mlock is enabled, failing otherwise.
+	respData["mlock"] = map[string]interface{}{
+		"enabled":   b.Core.enableMlock,
+		"supported": mlock.Supported(),
+	}
 	resp.Data = respData
 
 	return resp, nil
@@ -4079,56 +5025,175 @@ func (b *SystemBackend) pathHashWrite(ctx context.Context, req *logical.Request,
 		return logical.ErrorResponse(fmt.Sprintf("unable to decode input as base64: %s", err)), logical.ErrInvalidRequest
 	}
 
-	switch format {
-	case "hex":
-	case "base64":
-	default:
-		return logical.ErrorResponse(fmt.Sprintf("unsupported encoding format %s; must be \"hex\" or \"base64\"", format)), nil
+	if !random.ValidEncodingFormat(format) {
+		return logical.ErrorResponse(fmt.Sprintf("unsupported encoding format %s; must be \"hex\", \"base64\", \"base64url\" or \"base32\"", format)), nil
 	}
 
-	var hf hash.Hash
+	newHash := toolsHashFunc(algorithm)
+	if newHash == nil {
+		return logical.ErrorResponse(fmt.Sprintf("unsupported algorithm %s", algorithm)), nil
+	}
+	hf := newHash()
+	hf.Write(input)
+	retBytes := hf.Sum(nil)
+
+	retStr, err := random.EncodeBytes(retBytes, format)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate the response
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"sum": retStr,
+		},
+	}
+	return resp, nil
+}
+
+// toolsHashFunc returns the constructor of the hash of the given algorithm
+// of sys/tools, or nil if it is unsupported.
+func toolsHashFunc(algorithm string) func() hash.Hash {
 	switch algorithm {
 	case "sha2-224":
-		hf = sha256.New224()
+		return sha256.New224
 	case "sha2-256":
-		hf = sha256.New()
+		return sha256.New
 	case "sha2-384":
-		hf = sha512.New384()
+		return sha512.New384
 	case "sha2-512":
-		hf = sha512.New()
+		return sha512.New
 	case "sha3-224":
-		hf = sha3.New224()
+		return sha3.New224
 	case "sha3-256":
-		hf = sha3.New256()
+		return sha3.New256
 	case "sha3-384":
-		hf = sha3.New384()
+		return sha3.New384
 	case "sha3-512":
-		hf = sha3.New512()
+		return sha3.New512
 	default:
-		return logical.ErrorResponse(fmt.Sprintf("unsupported algorithm %s", algorithm)), nil
+		return nil
 	}
-	hf.Write(input)
-	retBytes := hf.Sum(nil)
+}
+
+func (b *SystemBackend) pathRandomWrite(_ context.Context, _ *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	return random.HandleRandomAPI(d, b.Core.secureRandomReader)
+}
 
-	var retStr string
-	switch format {
-	case "hex":
-		retStr = hex.EncodeToString(retBytes)
-	case "base64":
-		retStr = base64.StdEncoding.EncodeToString(retBytes)
+// maxToolsIDs is the number of UUIDs or ULIDs generated at most by a request.
+const maxToolsIDs = 1000
+
+func (b *SystemBackend) pathUUIDWrite(_ context.Context, _ *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	version := d.Get("version").(int)
+	count := d.Get("count").(int)
+	if count < 1 || count > maxToolsIDs {
+		return logical.ErrorResponse("count must be between 1 and %d", maxToolsIDs), nil
 	}
 
-	// Generate the response
-	resp := &logical.Response{
+	uuids := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		var id string
+		var err error
+		switch version {
+		case 4:
+			id, err = uuid.GenerateUUIDWithReader(b.Core.secureRandomReader)
+		case 7:
+			id, err = random.NewUUIDv7(b.Core.secureRandomReader, time.Now())
+		default:
+			return logical.ErrorResponse("unsupported UUID version %d; must be 4 or 7", version), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		uuids = append(uuids, id)
+	}
+
+	return &logical.Response{
 		Data: map[string]interface{}{
-			"sum": retStr,
+			"uuids": uuids,
 		},
+	}, nil
+}
+
+func (b *SystemBackend) pathULIDWrite(_ context.Context, _ *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	count := d.Get("count").(int)
+	if count < 1 || count > maxToolsIDs {
+		return logical.ErrorResponse("count must be between 1 and %d", maxToolsIDs), nil
 	}
-	return resp, nil
+
+	ulids := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		id, err := random.NewULID(b.Core.secureRandomReader, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		ulids = append(ulids, id)
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"ulids": ulids,
+		},
+	}, nil
 }
 
-func (b *SystemBackend) pathRandomWrite(_ context.Context, _ *logical.Request, d *framework.FieldData) (*logical.Response, error) {
-	return random.HandleRandomAPI(d, b.Core.secureRandomReader)
+// pathHKDFWrite derives bytes from the given input key material with HKDF
+// (RFC 5869). Nothing is stored: the same input, salt and info always derive
+// the same bytes.
+func (b *SystemBackend) pathHKDFWrite(_ context.Context, _ *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	format := d.Get("format").(string)
+	algorithm := d.Get("algorithm").(string)
+	numBytes := d.Get("bytes").(int)
+
+	decode := func(field string) ([]byte, error) {
+		decoded, err := base64.StdEncoding.DecodeString(d.Get(field).(string))
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode %s as base64: %w", field, err)
+		}
+		return decoded, nil
+	}
+	input, err := decode("input")
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+	if len(input) == 0 {
+		return logical.ErrorResponse("input is required"), logical.ErrInvalidRequest
+	}
+	salt, err := decode("salt")
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+	info, err := decode("info")
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+
+	if !random.ValidEncodingFormat(format) {
+		return logical.ErrorResponse("unsupported encoding format %s; must be \"hex\", \"base64\", \"base64url\" or \"base32\"", format), nil
+	}
+	newHash := toolsHashFunc(algorithm)
+	if newHash == nil {
+		return logical.ErrorResponse("unsupported algorithm %s", algorithm), nil
+	}
+	// HKDF derives at most 255 blocks of the size of the hash.
+	if maxBytes := 255 * newHash().Size(); numBytes < 1 || numBytes > maxBytes {
+		return logical.ErrorResponse("bytes must be between 1 and %d with %s", maxBytes, algorithm), nil
+	}
+
+	derived := make([]byte, numBytes)
+	if _, err := io.ReadFull(hkdf.New(newHash, input, salt, info), derived); err != nil {
+		return nil, err
+	}
+	retStr, err := random.EncodeBytes(derived, format)
+	if err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"derived_bytes": retStr,
+		},
+	}, nil
 }
 
 func hasMountAccess(ctx context.Context, acl *ACL, path string) bool {
@@ -5391,6 +6456,30 @@ in the plugin catalog.`,
 		`Whether to turn on seal wrapping for the mount.`,
 	},
 
+	"data_key": {
+		`Whether to encrypt the entries of the mount with a data key of its own, which can be rotated independently and is destroyed along with the mount.`,
+	},
+
+	"mount_data_key": {
+		"Read the status of the data key of a mount.",
+		`
+The entries of the mounts enabled with data_key are encrypted with a data key
+of the mount before being encrypted by the barrier. This path returns the term
+of the active data key, when it was installed, and whether entries are still
+being re-encrypted with it following a rotation.
+		`,
+	},
+
+	"mount_data_key_rotate": {
+		"Rotate the data key of a mount.",
+		`
+Installs a new data key for the mount, which new entries are encrypted with
+immediately. Existing entries are re-encrypted with it in the background, after
+which the older data keys are destroyed. A rotation can't be started while
+another is in progress.
+		`,
+	},
+
 	"external_entropy_access": {
 		`Whether to give the mount access to Vault's external entropy.`,
 	},
@@ -5636,6 +6725,34 @@ or delete a policy.
 		`,
 	},
 
+	"policy-versions": {
+		`List the retained versions of an ACL policy.`,
+		`
+Every change to an ACL policy is recorded as a new version, along with the
+token accessor, entity and display name of the requester that made it. Up to
+20 versions are retained, and the history is kept when the policy is deleted
+so that it can be restored.
+		`,
+	},
+
+	"policy-version": {
+		`Read a version of an ACL policy.`,
+		"",
+	},
+
+	"policy-version-restore": {
+		`Restore a version of an ACL policy.`,
+		`
+Makes the given version of the ACL policy the current one. The restored
+policy is recorded as a new version, authored by the requester.
+		`,
+	},
+
+	"policy-version-number": {
+		`The version of the policy.`,
+		"",
+	},
+
 	"policy-name": {
 		`The name of the policy. Example: "ops"`,
 		"",
@@ -5666,6 +6783,71 @@ or delete a policy.
 		"",
 	},
 
+	"audit-tail": {
+		"Stream the entries logged by the given audit device.",
+		`
+This path streams the entries logged by the given audit device on the node
+handling the request, as newline delimited JSON, until the client disconnects.
+Entries are hashed as they are by the device. The optional "filter" parameter
+is a boolean expression over the "type", "operation", "path", "mount_type",
+"mount_point", "namespace" and "remote_address" fields of the entry, and only
+matching entries are streamed. Entries are dropped if the client does not
+keep up.
+		`,
+	},
+
+	"audit_tail_filter": {
+		"Boolean expression selecting the entries to stream.",
+		"",
+	},
+
+	"audit-export": {
+		"Export the archived entries of the given audit device involving an entity.",
+		`
+This path returns the entries kept by the given audit device on the node
+handling the request which involve the entity with the given ID, as newline
+delimited JSON, oldest first. An entry involves an entity when its request was
+made with a token of the entity, or when its request path contains the ID of
+the entity. The file audit device exports the entries of its log file and of
+the archives rotated alongside it. Entries are redacted with the
+"export_redact" option of the device before being returned.
+		`,
+	},
+
+	"audit_export_entity_id": {
+		"The ID of the entity whose audit entries to export.",
+		"",
+	},
+
+	"audit-test": {
+		"Log a test message through the given audit device.",
+		`
+This path logs a test message through the given audit device, in the same way
+as is done when the device is enabled, and returns an error if the device
+fails to log it.
+		`,
+	},
+
+	"audit-status": {
+		"Report the health of the enabled audit devices.",
+		`
+This path returns, for each enabled audit device, the number of entries it
+logged and failed to log, the number of failures since its last success, its
+last error, and the times of its last failure and success. The counters are
+maintained by each node, and reset when the node is unsealed.
+		`,
+	},
+
+	"audit-rotate-hmac": {
+		"Rotate the HMAC key of the given audit device.",
+		`
+This path replaces the salt of the given audit device, which is the key of the
+HMACs of the values it hashes, with a new one. Values hashed afterwards, and
+hashes returned by the audit-hash path, are computed with the new key, so they
+can't be compared with the hashes computed before the rotation.
+		`,
+	},
+
 	"audit-table": {
 		"List the currently enabled audit backends.",
 		`
@@ -5783,6 +6965,33 @@ necessary, and is only required if upgrade notes or support personnel suggest
 it.`,
 	},
 
+	"lease_import_key": {
+		"Read the public key leases imported into this cluster are exported to.",
+		`Returns the PEM-encoded RSA public key the leases exported from another
+cluster must be encrypted to, so that they can be imported into this one. The
+key is generated the first time it is read.`,
+	},
+
+	"lease_export": {
+		"Export the leases of secrets to another cluster.",
+		`Exports the leases of secrets of the namespace, optionally only those whose
+ID starts with a prefix, encrypted to the import key of another cluster. The
+export includes the revocation data of the leases, so that the other cluster
+can renew and revoke them once imported. Leases of tokens, and irrevocable or
+expired leases, are not exported. The leases exported are removed from this
+cluster without being revoked, so that it no longer revokes them.`,
+	},
+
+	"lease_import": {
+		"Import leases exported from another cluster.",
+		`Imports leases exported from another cluster to the import key of this one.
+The leases keep their IDs, and are renewed and revoked by this cluster from
+then on. Each lease must be issued by a mount of the same type at the same
+path, and must not exist already. Leases keep their token if it exists on
+this cluster; otherwise they are attached to the token of the given owner
+accessor, or skipped.`,
+	},
+
 	"wrap": {
 		"Response-wraps an arbitrary JSON object.",
 		`Round trips the given input data into a response-wrapped token.`,
@@ -5918,6 +7127,90 @@ This path responds to the following HTTP methods.
 		`The mount paths of the plugin backends to reload.`,
 		"",
 	},
+	"mount_owners": {
+		"Delegate the administration of a mount to its owners.",
+		`Sets the entities and groups owning a secrets engine or auth method. Owners
+		are granted the policy generated for the mount on top of the policies of
+		their tokens, which allows them to use everything within a secrets engine,
+		tune it, and rotate its data key, or to read everything within an auth
+		method and tune it. The policy follows the mount when it is moved, and is
+		named after its accessor. It is only granted to the owners, never by name.`,
+	},
+	"mount_owners_list": {
+		"List the owned mounts and their owners.",
+		`Lists the secrets engines and auth methods of the namespace which have
+		owners, optionally only the ones owned by an entity, directly or through its
+		groups, or by a group.`,
+	},
+	"config-state": {
+		"Export or apply the declarative state of the mounts, auth methods, policies and quotas.",
+		`Reading returns the live state of the secrets engines, auth methods, ACL
+		policies and rate limit quotas of the namespace as a document. Writing a
+		document computes the changes making the live state match it, and makes
+		them unless it is a dry run. Each change is a request of its own to the
+		system backend, authorized by the policies of the token and audited. If
+		making a change fails, the changes already made are rolled back on a
+		best-effort basis: applying a state is not atomic. The mounts, auth
+		methods, policies and quotas missing from the document are only removed
+		when pruning.`,
+	},
+	"config-state-drift": {
+		"Report the drift from the last applied declarative state.",
+		`Reports the changes which applying the last state written to
+		sys/config/state again would make, revealing the changes made to the
+		mounts, auth methods, policies and quotas by other means since.`,
+	},
+	"secrets-import": {
+		"Import the secrets of a cloud secret manager into a kv-v2 mount.",
+		`Starts importing the current values of the secrets of AWS Secrets Manager,
+		GCP Secret Manager or an Azure Key Vault into a kv-v2 mount, mapping their
+		names to kv paths with rules. Secrets already imported with the same data
+		are skipped, and dry runs report the actions an import would take without
+		writing any secret. The kv secrets are read and written with the policies
+		of the token starting the import, and each read and write is audited. The
+		import runs in the background; its progress is reported by
+		sys/import/status/:import_id.`,
+	},
+	"secrets-import-status": {
+		"Report the progress of the imports of secrets.",
+		`Lists the imports run by this node, or reports the progress of an import:
+		its status, the number of secrets processed, the count of each action taken,
+		and the action taken for each secret.`,
+	},
+	"secrets-import-cancel": {
+		"Cancel an import of secrets.",
+		`Cancels an import in progress. The secrets imported before it was canceled
+		are kept.`,
+	},
+	"advisor": {
+		"Report the configuration which is likely unused.",
+		`Reports the ACL policies attached to no token, entity or group and allowed
+		by no token role, the entities with no alias on an existing mount which
+		belong to no group, the auth mounts no client used in the given number of
+		days according to the activity log, and the token roles no existing token
+		was created with.`,
+	},
+	"rollback-status": {
+		"Report the outcome of the last rollback of each mount.",
+		`Reports when the last rollback of each mount rolled back since this node
+		became active started, how long it took, the error of the last rollback
+		which failed, the number of rollbacks which failed since the last one
+		which succeeded, and how long the next rollback of mounts which keep
+		failing is delayed.`,
+	},
+	"rollback-trigger": {
+		"Roll back a mount immediately.",
+		`Asks the backend of the mount to roll back, so that it cleans up the
+		partial secrets of operations which failed midway, without waiting for
+		its next scheduled rollback. Returns once the rollback is done, joining
+		the rollback of the mount in progress if any.`,
+	},
+	"plugin-runtime-stats": {
+		"Report the resource usage of the external plugin processes.",
+		`Reports the CPU and memory usage of the external plugin processes run by
+		this node, and the number of times they were restarted for exceeding the
+		memory limit of the plugin restart policy.`,
+	},
 	"hash": {
 		"Generate a hash sum for input data",
 		"Generates a hash sum of the given algorithm against the given input data.",
@@ -5926,6 +7219,48 @@ This path responds to the following HTTP methods.
 		"Generate random bytes",
 		"This function can be used to generate high-entropy random bytes.",
 	},
+	"uuid": {
+		"Generate UUIDs",
+		"Generates random (version 4) UUIDs, or time-ordered (version 7) UUIDs which sort in the order they were generated.",
+	},
+	"ulid": {
+		"Generate ULIDs",
+		"Generates ULIDs, lexicographically sortable identifiers made of a millisecond timestamp and 80 random bits.",
+	},
+	"hkdf": {
+		"Derive bytes from input key material with HKDF",
+		`Derives bytes from the given input key material, salt and info with HKDF
+		(RFC 5869). Nothing is stored: the same parameters always derive the same
+		bytes.`,
+	},
+	"tune_token_bind_source_cidr": {
+		"If true, the tokens issued by logins to the auth mount can only be used from the source address of the login.",
+		"",
+	},
+	"tune_token_bind_client_cert": {
+		"If true, logins to the auth mount must present a TLS client certificate, and the tokens they issue can only be used by requests presenting the same certificate.",
+		"",
+	},
+	"tune_lease_ttl_jitter": {
+		"The percentage, between 0 and 50, by which the TTLs of the leases issued or renewed by the mount are randomly shortened, so that leases issued together don't expire at once.",
+		"",
+	},
+	"tune_max_request_size": {
+		"The maximum size, in bytes, of the requests routed to the mount. Larger requests are rejected. It replaces the max_request_size of the listener, so it can be higher or lower. A value of 0 keeps the limit of the listener.",
+		"",
+	},
+	"tune_max_response_size": {
+		"The maximum size, in bytes, of the JSON encoded data of the responses of the mount to read and list requests. Larger responses are withheld, except those issuing leases or tokens. Responses to other requests are never withheld, as the request may already have had side effects. A value of 0 removes the limit.",
+		"",
+	},
+	"tune_identity_token_role": {
+		"The name of the identity OIDC role through which the plugin of the mount may issue identity tokens to the entities of the requests it handles, such as to exchange them for credentials with external systems. An empty value prevents the plugin from issuing any.",
+		"",
+	},
+	"tune_rollback_period": {
+		"The interval at which the mount is rolled back, overriding the rollback manager's period. A value of 0 restores the default.",
+		"",
+	},
 	"listing_visibility": {
 		"Determines the visibility of the mount in the UI-specific listing endpoint. Accepted value are 'unauth' and 'hidden', with the empty default ('') behaving like 'hidden'.",
 		"",
@@ -5968,6 +7303,81 @@ This path responds to the following HTTP methods.
 		"Export the metrics aggregated for telemetry purpose.",
 		"",
 	},
+	"revoke-status": {
+		"Read the progress of a background revocation of a token and its child tokens.",
+		`
+This path returns the progress of a revocation started with the "async"
+parameter of the auth/token/revoke, revoke-self or revoke-accessor endpoints:
+its status, which is one of "running", "completed" or "failed", its phase,
+which is "invalidating" while the tokens of the tree are made unusable and
+"revoking" while they are revoked, and the number of tokens invalidated and
+revoked. The progress is checkpointed to storage, so running revocations are
+resumed by the next active node, and kept for 24 hours once finished.
+		`,
+	},
+	"revoke-status-list": {
+		"List the background revocations of tokens and their child tokens.",
+		`
+This path lists the IDs of the background token revocations started in the
+namespace whose progress is still kept.
+		`,
+	},
+	"revoke-status-job-id": {
+		"The ID of the revocation job.",
+		"",
+	},
+	"batch": {
+		"Make several independent requests in one call.",
+		`
+Writing to this path handles the requests of the batch concurrently, and
+returns the status and response of each of them in the order of the batch.
+Every request is made with the client token of the batch: it is subject to the
+policies of the token and the rate limit quotas of its own path, and audited
+like a request of its own. A failed request doesn't fail the batch.
+		`,
+	},
+	"batch-requests": {
+		"The requests of the batch, each with an operation, a path and data.",
+		"",
+	},
+	"kv-recursive-delete": {
+		"Read the progress of a recursive delete of KV version 2 metadata.",
+		`
+This path returns the progress of a recursive delete: its status, which is one
+of "running", "completed" or "canceled", and the number of keys deleted and of
+deletes and lists that failed, with their errors. The progress is kept on the
+node that ran the delete, for the last 64 deletes.
+		`,
+	},
+	"kv-recursive-delete-start": {
+		"Start a recursive delete of KV version 2 metadata, or list the recursive deletes.",
+		`
+Writing to this path deletes the metadata, and so all versions, of every key
+under a metadata path of a KV version 2 mount, in the background. Every list
+and delete is authorized with the policies of the token at the time the delete
+starts, and audited as a request of its own. Listing this path returns the IDs
+of the recursive deletes started in the namespace whose progress is still kept.
+		`,
+	},
+	"kv-recursive-delete-path": {
+		"The metadata path of a KV version 2 mount to delete recursively, such as secret/metadata/app/.",
+		"",
+	},
+	"kv-recursive-delete-dry-run": {
+		"If true, return the keys that would be deleted without deleting them.",
+		"",
+	},
+	"kv-recursive-delete-list": {
+		"List the recursive deletes of KV version 2 metadata.",
+		`
+This path lists the IDs of the recursive deletes of KV version 2 metadata
+started in the namespace whose progress is still kept.
+		`,
+	},
+	"kv-recursive-delete-id": {
+		"The ID of the recursive delete.",
+		"",
+	},
 	"in-flight-req": {
 		"reports in-flight requests",
 		`
@@ -5976,6 +7386,17 @@ This path responds to the following HTTP methods.
 			Returns a map of in-flight requests.
 		`,
 	},
+	"events-subscriptions": {
+		"Lists the subscribers of the event bus.",
+		`
+Lists the subscribers of the event bus in the namespace and its children,
+along with the event type pattern they subscribed to, the number of events
+waiting to be received by each, for how many seconds they have been waiting,
+and when each last received an event. Heartbeat events are sent on each
+subscribed topic periodically, so subscribers which stopped receiving events
+fall behind even when no other events are sent.
+		`,
+	},
 	"internal-counters-requests": {
 		"Currently unsupported. Previously, count of requests seen by this Vault cluster over time.",
 		"Currently unsupported. Previously, count of requests seen by this Vault cluster over time. Not included in count: health checks, UI asset requests, requests forwarded from another cluster.",
@@ -6000,7 +7421,9 @@ This path responds to the following HTTP methods.
 		"Information about the host instance that this Vault server is running on.",
 		`Information about the host instance that this Vault server is running on.
 		The information that gets collected includes host hardware information, and CPU,
-		disk, and memory utilization`,
+		disk, and memory utilization, along with Go runtime statistics such as the memory
+		limit and garbage collection activity, the open file descriptors and resource limits
+		of the Vault process, and whether memory is locked with mlock`,
 	},
 	"activity-query": {
 		"Query the historical count of clients.",
@@ -0,0 +1,126 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// advisorPath returns the path reporting configuration which is likely
+// unused.
+func (b *SystemBackend) advisorPath() *framework.Path {
+	return &framework.Path{
+		Pattern: "advisor$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: "advisor",
+			OperationVerb:   "read",
+			OperationSuffix: "report",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"inactive_days": {
+				Type:        framework.TypeInt,
+				Description: "The number of days without clients after which auth mounts are reported as stale.",
+				Default:     defaultAdvisorInactiveDays,
+				Query:       true,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.handleAdvisorRead,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields: map[string]*framework.FieldSchema{
+							"unused_policies": {
+								Type:     framework.TypeStringSlice,
+								Required: true,
+							},
+							"orphan_entities": {
+								Type:     framework.TypeSlice,
+								Required: true,
+							},
+							"stale_mounts": {
+								Type:     framework.TypeSlice,
+								Required: false,
+							},
+							"unused_roles": {
+								Type:     framework.TypeStringSlice,
+								Required: true,
+							},
+							"inactive_days": {
+								Type:     framework.TypeInt,
+								Required: true,
+							},
+						},
+					}},
+				},
+				Summary: "Report the policies, entities, auth mounts and token roles which are likely unused.",
+			},
+		},
+
+		HelpSynopsis:    strings.TrimSpace(sysHelp["advisor"][0]),
+		HelpDescription: strings.TrimSpace(sysHelp["advisor"][1]),
+	}
+}
+
+func (b *SystemBackend) handleAdvisorRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	inactiveDays := d.Get("inactive_days").(int)
+	if inactiveDays <= 0 {
+		return logical.ErrorResponse("inactive_days must be positive"), nil
+	}
+
+	report, err := b.Core.advisorReport(ctx, inactiveDays)
+	if err != nil {
+		return nil, err
+	}
+
+	orphanEntities := make([]map[string]interface{}, 0, len(report.OrphanEntities))
+	for _, e := range report.OrphanEntities {
+		orphanEntities = append(orphanEntities, map[string]interface{}{
+			"id":   e.ID,
+			"name": e.Name,
+		})
+	}
+
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"unused_policies": nonNilStrings(report.UnusedPolicies),
+			"orphan_entities": orphanEntities,
+			"unused_roles":    nonNilStrings(report.UnusedRoles),
+			"inactive_days":   inactiveDays,
+		},
+	}
+	if report.StaleMounts != nil {
+		staleMounts := make([]map[string]interface{}, 0, len(report.StaleMounts))
+		for _, m := range report.StaleMounts {
+			staleMounts = append(staleMounts, map[string]interface{}{
+				"path":     m.Path,
+				"type":     m.Type,
+				"accessor": m.Accessor,
+			})
+		}
+		resp.Data["stale_mounts"] = staleMounts
+	}
+	for _, w := range report.Warnings {
+		resp.AddWarning(w)
+	}
+	return resp, nil
+}
+
+// nonNilStrings returns an empty slice in place of a nil one, so that empty
+// lists are reported as such.
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,905 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/go-secure-stdlib/parseutil"
+	"github.com/hashicorp/go-secure-stdlib/strutil"
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/vault/quotas"
+	"github.com/mitchellh/mapstructure"
+)
+
+// configStateAppliedPath is the storage path of the last state applied with
+// sys/config/state, relative to the system view.
+const configStateAppliedPath = "config-state/applied"
+
+const (
+	configStateKindMount          = "mount"
+	configStateKindAuth           = "auth"
+	configStateKindPolicy         = "policy"
+	configStateKindRateLimitQuota = "rate_limit_quota"
+
+	configStateActionCreate = "create"
+	configStateActionUpdate = "update"
+	configStateActionDelete = "delete"
+)
+
+// configState is a declarative document describing the mounts, auth methods,
+// ACL policies and rate limit quotas of a namespace.
+type configState struct {
+	Mounts          map[string]*configStateMount `mapstructure:"mounts"`
+	Auth            map[string]*configStateMount `mapstructure:"auth"`
+	Policies        map[string]string            `mapstructure:"policies"`
+	RateLimitQuotas map[string]*configStateQuota `mapstructure:"rate_limit_quotas"`
+}
+
+type configStateMount struct {
+	Type            string            `mapstructure:"type"`
+	Description     string            `mapstructure:"description"`
+	Local           bool              `mapstructure:"local"`
+	SealWrap        bool              `mapstructure:"seal_wrap"`
+	Options         map[string]string `mapstructure:"options"`
+	DefaultLeaseTTL time.Duration     `mapstructure:"default_lease_ttl"`
+	MaxLeaseTTL     time.Duration     `mapstructure:"max_lease_ttl"`
+}
+
+type configStateQuota struct {
+	Path          string        `mapstructure:"path"`
+	Role          string        `mapstructure:"role"`
+	Rate          float64       `mapstructure:"rate"`
+	Interval      time.Duration `mapstructure:"interval"`
+	BlockInterval time.Duration `mapstructure:"block_interval"`
+	Inheritable   bool          `mapstructure:"inheritable"`
+}
+
+// configStateChange is a change to make for the live state to match the
+// desired one. Changes which can't be made, such as changing the type of a
+// mount, have a conflict rather than requests.
+type configStateChange struct {
+	Kind   string
+	Name   string
+	Action string
+	Fields []string
+
+	conflict string
+	apply    *logical.Request
+	undo     *logical.Request
+}
+
+// configStateApplied is the last state applied, against which drift is
+// detected.
+type configStateApplied struct {
+	State       map[string]interface{} `json:"state"`
+	Prune       bool                   `json:"prune"`
+	AppliedTime time.Time              `json:"applied_time"`
+}
+
+// configStatePaths returns the paths exporting and applying the declarative
+// state of the mounts, auth methods, policies and quotas.
+func (b *SystemBackend) configStatePaths() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "config/state$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "config-state",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"state": {
+					Type:        framework.TypeMap,
+					Description: "The desired state, an object holding the mounts and auth methods by path (mounts, auth), the ACL policies by name (policies), and the rate limit quotas by name (rate_limit_quotas).",
+					Required:    true,
+				},
+				"prune": {
+					Type:        framework.TypeBool,
+					Description: "Whether to remove the mounts, auth methods, policies and quotas missing from the state. If false, they are left alone.",
+				},
+				"dry_run": {
+					Type:        framework.TypeBool,
+					Description: "Whether to only report the changes applying the state would make, without making them.",
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handleConfigStateExport,
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "export",
+					},
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"state": {
+									Type:     framework.TypeMap,
+									Required: true,
+								},
+							},
+						}},
+					},
+					Summary: "Export the live state of the mounts, auth methods, policies and quotas.",
+				},
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleConfigStateApply,
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "apply",
+					},
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"changes": {
+									Type:     framework.TypeSlice,
+									Required: true,
+								},
+								"dry_run": {
+									Type:     framework.TypeBool,
+									Required: true,
+								},
+							},
+						}},
+					},
+					Summary: "Apply a declarative state of the mounts, auth methods, policies and quotas.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["config-state"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["config-state"][1]),
+		},
+		{
+			Pattern: "config/state/drift$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "config-state",
+				OperationVerb:   "read",
+				OperationSuffix: "drift",
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handleConfigStateDrift,
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"drift": {
+									Type:     framework.TypeSlice,
+									Required: true,
+								},
+								"applied_time": {
+									Type:     framework.TypeTime,
+									Required: true,
+								},
+							},
+						}},
+					},
+					Summary: "Report the changes made to the live state since the last state was applied.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["config-state-drift"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["config-state-drift"][1]),
+		},
+	}
+}
+
+func (b *SystemBackend) handleConfigStateExport(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	live, err := b.liveConfigState(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"state": live.toMap(),
+		},
+	}, nil
+}
+
+func (b *SystemBackend) handleConfigStateApply(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	prune := d.Get("prune").(bool)
+	dryRun := d.Get("dry_run").(bool)
+
+	desired, err := parseConfigState(d.Get("state").(map[string]interface{}))
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	b.configStateLock.Lock()
+	defer b.configStateLock.Unlock()
+
+	live, err := b.liveConfigState(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := planConfigState(live, desired, prune)
+	var conflicts []string
+	for _, change := range changes {
+		if change.conflict != "" {
+			conflicts = append(conflicts, change.conflict)
+		}
+	}
+	if len(conflicts) > 0 {
+		return logical.ErrorResponse("the state can't be applied: %s", strings.Join(conflicts, "; ")), nil
+	}
+
+	if !dryRun {
+		if err := b.applyConfigState(ctx, req, changes); err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+
+		entry, err := logical.StorageEntryJSON(configStateAppliedPath, &configStateApplied{
+			State:       desired.toMap(),
+			Prune:       prune,
+			AppliedTime: time.Now().UTC(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := req.Storage.Put(ctx, entry); err != nil {
+			return nil, err
+		}
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"changes": configStateChangesData(changes),
+			"dry_run": dryRun,
+		},
+	}, nil
+}
+
+func (b *SystemBackend) handleConfigStateDrift(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	b.configStateLock.Lock()
+	defer b.configStateLock.Unlock()
+
+	entry, err := req.Storage.Get(ctx, configStateAppliedPath)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return logical.ErrorResponse("no state has been applied"), nil
+	}
+
+	var applied configStateApplied
+	if err := entry.DecodeJSON(&applied); err != nil {
+		return nil, err
+	}
+	desired, err := parseConfigState(applied.State)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the applied state: %w", err)
+	}
+
+	live, err := b.liveConfigState(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"drift":        configStateChangesData(planConfigState(live, desired, applied.Prune)),
+			"applied_time": applied.AppliedTime,
+		},
+	}, nil
+}
+
+// liveConfigState returns the live state of the namespace of the context.
+// Singleton mounts, such as sys/ and the token auth method, and the root
+// policy can't be managed declaratively and are left out of it.
+func (b *SystemBackend) liveConfigState(ctx context.Context) (*configState, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	state := newConfigState()
+	tableEntries := func(table *MountTable) map[string]*configStateMount {
+		mounts := make(map[string]*configStateMount)
+		for _, entry := range table.Entries {
+			if entry.NamespaceID != ns.ID || entry.Tainted || strutil.StrListContains(singletonMounts, entry.Type) {
+				continue
+			}
+			mounts[entry.Path] = &configStateMount{
+				Type:            entry.Type,
+				Description:     entry.Description,
+				Local:           entry.Local,
+				SealWrap:        entry.SealWrap,
+				Options:         entry.Options,
+				DefaultLeaseTTL: entry.Config.DefaultLeaseTTL,
+				MaxLeaseTTL:     entry.Config.MaxLeaseTTL,
+			}
+		}
+		return mounts
+	}
+
+	b.Core.mountsLock.RLock()
+	state.Mounts = tableEntries(b.Core.mounts)
+	b.Core.mountsLock.RUnlock()
+
+	b.Core.authLock.RLock()
+	state.Auth = tableEntries(b.Core.auth)
+	b.Core.authLock.RUnlock()
+
+	names, err := b.Core.policyStore.ListPolicies(ctx, PolicyTypeACL)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if name == "root" {
+			continue
+		}
+		policy, err := b.Core.policyStore.GetPolicy(ctx, name, PolicyTypeACL)
+		if err != nil {
+			return nil, err
+		}
+		if policy != nil {
+			state.Policies[name] = policy.Raw
+		}
+	}
+
+	names, err = b.Core.quotaManager.QuotaNames(quotas.TypeRateLimit)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		quota, err := b.Core.quotaManager.QuotaByName(quotas.TypeRateLimit.String(), name)
+		if err != nil {
+			return nil, err
+		}
+		rlq, ok := quota.(*quotas.RateLimitQuota)
+		if !ok {
+			continue
+		}
+		nsPath := rlq.NamespacePath
+		if nsPath == "root" {
+			nsPath = ""
+		}
+		if nsPath != ns.Path {
+			continue
+		}
+		state.RateLimitQuotas[name] = &configStateQuota{
+			Path:          nsPath + rlq.MountPath + rlq.PathSuffix,
+			Role:          rlq.Role,
+			Rate:          rlq.Rate,
+			Interval:      rlq.Interval,
+			BlockInterval: rlq.BlockInterval,
+			Inheritable:   rlq.Inheritable || (nsPath == "" && rlq.MountPath == ""),
+		}
+	}
+
+	return state, nil
+}
+
+// applyConfigState makes the changes in order. If one of them fails, the
+// changes already made are undone in reverse order, except for the removal of
+// mounts and auth methods, whose data is gone. Removals are made last so that
+// this only happens when removing one of several mounts fails. This is a
+// best-effort rollback rather than a transaction: other clients see the
+// changes as they are made, and undoing a change may itself fail.
+func (b *SystemBackend) applyConfigState(ctx context.Context, req *logical.Request, changes []*configStateChange) error {
+	for i, change := range changes {
+		err := b.handleConfigStateRequest(ctx, req, change.apply)
+		if err == nil {
+			continue
+		}
+
+		var retErr *multierror.Error
+		retErr = multierror.Append(retErr, fmt.Errorf("failed to %s %s %q: %w", change.Action, change.Kind, change.Name, err))
+		for j := i - 1; j >= 0; j-- {
+			undone := changes[j]
+			if undone.undo == nil {
+				retErr = multierror.Append(retErr, fmt.Errorf("%s %q was removed and can't be restored", undone.Kind, undone.Name))
+				continue
+			}
+			if err := b.handleConfigStateRequest(ctx, req, undone.undo); err != nil {
+				retErr = multierror.Append(retErr, fmt.Errorf("failed to roll back the %s of %s %q: %w", undone.Action, undone.Kind, undone.Name, err))
+			}
+		}
+		return retErr.ErrorOrNil()
+	}
+	return nil
+}
+
+// handleConfigStateRequest makes a change as a request of its own to the
+// system backend, with the client token of the client applying the state, so
+// that it is authorized by the token's policies and audited like any other
+// request.
+func (b *SystemBackend) handleConfigStateRequest(ctx context.Context, req *logical.Request, change *logical.Request) error {
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return err
+	}
+	r := &logical.Request{
+		ID:                id,
+		Operation:         change.Operation,
+		Path:              "sys/" + change.Path,
+		Data:              change.Data,
+		ClientToken:       req.ClientToken,
+		ClientTokenSource: req.ClientTokenSource,
+		Connection:        req.Connection,
+		Headers:           req.Headers,
+	}
+	if r.Data == nil {
+		r.Data = make(map[string]interface{})
+	}
+
+	resp, err := b.Core.handleCancelableRequest(ctx, r)
+	if resp != nil && resp.IsError() {
+		return resp.Error()
+	}
+	return err
+}
+
+// planConfigState returns the changes making the live state match the
+// desired one, in the order they are to be made: the policies, mounts, auth
+// methods and quotas are created or updated first, then the quotas, policies,
+// auth methods and mounts missing from the desired state are removed if
+// pruning.
+func planConfigState(live, desired *configState, prune bool) []*configStateChange {
+	policyUpserts, policyDeletes := planConfigStatePolicies(live.Policies, desired.Policies, prune)
+	mountUpserts, mountDeletes := planConfigStateMounts(configStateKindMount, "mounts/", live.Mounts, desired.Mounts, prune)
+	authUpserts, authDeletes := planConfigStateMounts(configStateKindAuth, "auth/", live.Auth, desired.Auth, prune)
+	quotaUpserts, quotaDeletes := planConfigStateQuotas(live.RateLimitQuotas, desired.RateLimitQuotas, prune)
+
+	var changes []*configStateChange
+	for _, c := range [][]*configStateChange{
+		policyUpserts, mountUpserts, authUpserts, quotaUpserts,
+		quotaDeletes, policyDeletes, authDeletes, mountDeletes,
+	} {
+		changes = append(changes, c...)
+	}
+	return changes
+}
+
+func planConfigStateMounts(kind, prefix string, live, desired map[string]*configStateMount, prune bool) (upserts, deletes []*configStateChange) {
+	for _, path := range sortedConfigStateKeys(desired) {
+		want := desired[path]
+		have, ok := live[path]
+		if !ok {
+			upserts = append(upserts, &configStateChange{
+				Kind:   kind,
+				Name:   path,
+				Action: configStateActionCreate,
+				apply:  configStateRequest(logical.UpdateOperation, prefix+path, want.mountData()),
+				undo:   configStateRequest(logical.DeleteOperation, prefix+path, nil),
+			})
+			continue
+		}
+
+		var fields, immutable []string
+		if have.Type != want.Type {
+			immutable = append(immutable, "type")
+		}
+		if have.Local != want.Local {
+			immutable = append(immutable, "local")
+		}
+		if have.SealWrap != want.SealWrap {
+			immutable = append(immutable, "seal_wrap")
+		}
+		fields = append(fields, immutable...)
+
+		tune := make(map[string]interface{})
+		untune := make(map[string]interface{})
+		if have.Description != want.Description {
+			fields = append(fields, "description")
+			tune["description"] = want.Description
+			untune["description"] = have.Description
+		}
+		if have.DefaultLeaseTTL != want.DefaultLeaseTTL {
+			fields = append(fields, "default_lease_ttl")
+			tune["default_lease_ttl"] = configStateTTL(want.DefaultLeaseTTL)
+			untune["default_lease_ttl"] = configStateTTL(have.DefaultLeaseTTL)
+		}
+		if have.MaxLeaseTTL != want.MaxLeaseTTL {
+			fields = append(fields, "max_lease_ttl")
+			tune["max_lease_ttl"] = configStateTTL(want.MaxLeaseTTL)
+			untune["max_lease_ttl"] = configStateTTL(have.MaxLeaseTTL)
+		}
+
+		// Only the options in the desired state are managed, as tuning can't
+		// remove all the options of a mount, such as the version of kv mounts.
+		options := make(map[string]string)
+		unoptions := make(map[string]string)
+		for k, v := range want.Options {
+			if have.Options[k] != v {
+				options[k] = v
+				unoptions[k] = have.Options[k]
+			}
+		}
+		if len(options) > 0 {
+			fields = append(fields, "options")
+			tune["options"] = options
+			untune["options"] = unoptions
+		}
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		change := &configStateChange{
+			Kind:   kind,
+			Name:   path,
+			Action: configStateActionUpdate,
+			Fields: fields,
+		}
+		if len(immutable) > 0 {
+			change.conflict = fmt.Sprintf("the %s of %s %q can't be changed without removing it", strings.Join(immutable, ", "), kind, path)
+		} else {
+			change.apply = configStateRequest(logical.UpdateOperation, prefix+path+"tune", tune)
+			change.undo = configStateRequest(logical.UpdateOperation, prefix+path+"tune", untune)
+		}
+		upserts = append(upserts, change)
+	}
+
+	if prune {
+		for _, path := range sortedConfigStateKeys(live) {
+			if _, ok := desired[path]; ok {
+				continue
+			}
+			deletes = append(deletes, &configStateChange{
+				Kind:   kind,
+				Name:   path,
+				Action: configStateActionDelete,
+				apply:  configStateRequest(logical.DeleteOperation, prefix+path, nil),
+			})
+		}
+	}
+
+	return upserts, deletes
+}
+
+func planConfigStatePolicies(live, desired map[string]string, prune bool) (upserts, deletes []*configStateChange) {
+	path := func(name string) string {
+		return "policies/acl/" + name
+	}
+
+	for _, name := range sortedConfigStateKeys(desired) {
+		want := desired[name]
+		have, ok := live[name]
+		switch {
+		case !ok:
+			upserts = append(upserts, &configStateChange{
+				Kind:   configStateKindPolicy,
+				Name:   name,
+				Action: configStateActionCreate,
+				apply:  configStateRequest(logical.UpdateOperation, path(name), map[string]interface{}{"policy": want}),
+				undo:   configStateRequest(logical.DeleteOperation, path(name), nil),
+			})
+		case have != want:
+			upserts = append(upserts, &configStateChange{
+				Kind:   configStateKindPolicy,
+				Name:   name,
+				Action: configStateActionUpdate,
+				Fields: []string{"policy"},
+				apply:  configStateRequest(logical.UpdateOperation, path(name), map[string]interface{}{"policy": want}),
+				undo:   configStateRequest(logical.UpdateOperation, path(name), map[string]interface{}{"policy": have}),
+			})
+		}
+	}
+
+	if prune {
+		for _, name := range sortedConfigStateKeys(live) {
+			// The default policy can't be removed
+			if _, ok := desired[name]; ok || name == "default" {
+				continue
+			}
+			deletes = append(deletes, &configStateChange{
+				Kind:   configStateKindPolicy,
+				Name:   name,
+				Action: configStateActionDelete,
+				apply:  configStateRequest(logical.DeleteOperation, path(name), nil),
+				undo:   configStateRequest(logical.UpdateOperation, path(name), map[string]interface{}{"policy": live[name]}),
+			})
+		}
+	}
+
+	return upserts, deletes
+}
+
+func planConfigStateQuotas(live, desired map[string]*configStateQuota, prune bool) (upserts, deletes []*configStateChange) {
+	path := func(name string) string {
+		return "quotas/rate-limit/" + name
+	}
+
+	for _, name := range sortedConfigStateKeys(desired) {
+		want := desired[name]
+		have, ok := live[name]
+		if !ok {
+			upserts = append(upserts, &configStateChange{
+				Kind:   configStateKindRateLimitQuota,
+				Name:   name,
+				Action: configStateActionCreate,
+				apply:  configStateRequest(logical.UpdateOperation, path(name), want.quotaData()),
+				undo:   configStateRequest(logical.DeleteOperation, path(name), nil),
+			})
+			continue
+		}
+
+		var fields []string
+		if have.Path != want.Path {
+			fields = append(fields, "path")
+		}
+		if have.Role != want.Role {
+			fields = append(fields, "role")
+		}
+		if have.Rate != want.Rate {
+			fields = append(fields, "rate")
+		}
+		if have.Interval != want.Interval {
+			fields = append(fields, "interval")
+		}
+		if have.BlockInterval != want.BlockInterval {
+			fields = append(fields, "block_interval")
+		}
+		if have.Inheritable != want.Inheritable {
+			fields = append(fields, "inheritable")
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		upserts = append(upserts, &configStateChange{
+			Kind:   configStateKindRateLimitQuota,
+			Name:   name,
+			Action: configStateActionUpdate,
+			Fields: fields,
+			apply:  configStateRequest(logical.UpdateOperation, path(name), want.quotaData()),
+			undo:   configStateRequest(logical.UpdateOperation, path(name), have.quotaData()),
+		})
+	}
+
+	if prune {
+		for _, name := range sortedConfigStateKeys(live) {
+			if _, ok := desired[name]; ok {
+				continue
+			}
+			deletes = append(deletes, &configStateChange{
+				Kind:   configStateKindRateLimitQuota,
+				Name:   name,
+				Action: configStateActionDelete,
+				apply:  configStateRequest(logical.DeleteOperation, path(name), nil),
+				undo:   configStateRequest(logical.UpdateOperation, path(name), live[name].quotaData()),
+			})
+		}
+	}
+
+	return upserts, deletes
+}
+
+func newConfigState() *configState {
+	return &configState{
+		Mounts:          make(map[string]*configStateMount),
+		Auth:            make(map[string]*configStateMount),
+		Policies:        make(map[string]string),
+		RateLimitQuotas: make(map[string]*configStateQuota),
+	}
+}
+
+// parseConfigState parses a state document and normalizes it, so that it can
+// be compared with the live state.
+func parseConfigState(raw map[string]interface{}) (*configState, error) {
+	var parsed configState
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       configStateDurationHook,
+		WeaklyTypedInput: true,
+		ErrorUnused:      true,
+		Result:           &parsed,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := decoder.Decode(raw); err != nil {
+		return nil, fmt.Errorf("invalid state: %w", err)
+	}
+
+	state := newConfigState()
+	normalizeMounts := func(kind string, from, to map[string]*configStateMount) error {
+		for path, mount := range from {
+			if mount == nil || mount.Type == "" {
+				return fmt.Errorf("%s %q has no type", kind, path)
+			}
+			path = sanitizePath(path)
+			if _, ok := to[path]; ok {
+				return fmt.Errorf("%s %q is declared more than once", kind, path)
+			}
+			if strutil.StrListContains(singletonMounts, mount.Type) {
+				return fmt.Errorf("%s %q is of type %q, which can't be managed", kind, path, mount.Type)
+			}
+
+			if alias, ok := mountAliases[mount.Type]; ok {
+				mount.Type = alias
+			}
+			switch mount.Type {
+			case "kv-v1", "kv-v2":
+				if mount.Options == nil {
+					mount.Options = make(map[string]string)
+				}
+				mount.Options["version"] = strings.TrimPrefix(mount.Type, "kv-v")
+				mount.Type = "kv"
+			}
+			to[path] = mount
+		}
+		return nil
+	}
+	if err := normalizeMounts(configStateKindMount, parsed.Mounts, state.Mounts); err != nil {
+		return nil, err
+	}
+	if err := normalizeMounts(configStateKindAuth, parsed.Auth, state.Auth); err != nil {
+		return nil, err
+	}
+
+	for name, policy := range parsed.Policies {
+		name = strings.ToLower(name)
+		if name == "root" {
+			return nil, fmt.Errorf("the root policy can't be managed")
+		}
+		if _, ok := state.Policies[name]; ok {
+			return nil, fmt.Errorf("policy %q is declared more than once", name)
+		}
+		state.Policies[name] = policy
+	}
+
+	for name, quota := range parsed.RateLimitQuotas {
+		if quota == nil || quota.Rate <= 0 {
+			return nil, fmt.Errorf("rate limit quota %q has no valid rate", name)
+		}
+		// Match the defaults of the quota API
+		if quota.Path != "" {
+			quota.Path = sanitizePath(quota.Path)
+		}
+		if quota.Interval == 0 {
+			quota.Interval = time.Second
+		}
+		if quota.Path == "" {
+			quota.Inheritable = true
+		}
+		state.RateLimitQuotas[name] = quota
+	}
+
+	return state, nil
+}
+
+// configStateDurationHook parses the durations of state documents, which are
+// given in seconds or as duration strings.
+func configStateDurationHook(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if to != reflect.TypeOf(time.Duration(0)) {
+		return data, nil
+	}
+	return parseutil.ParseDurationSecond(data)
+}
+
+func (s *configState) toMap() map[string]interface{} {
+	mounts := make(map[string]interface{}, len(s.Mounts))
+	for path, mount := range s.Mounts {
+		mounts[path] = mount.toMap()
+	}
+	auth := make(map[string]interface{}, len(s.Auth))
+	for path, mount := range s.Auth {
+		auth[path] = mount.toMap()
+	}
+	policies := make(map[string]interface{}, len(s.Policies))
+	for name, policy := range s.Policies {
+		policies[name] = policy
+	}
+	rateLimitQuotas := make(map[string]interface{}, len(s.RateLimitQuotas))
+	for name, quota := range s.RateLimitQuotas {
+		rateLimitQuotas[name] = quota.toMap()
+	}
+
+	return map[string]interface{}{
+		"mounts":            mounts,
+		"auth":              auth,
+		"policies":          policies,
+		"rate_limit_quotas": rateLimitQuotas,
+	}
+}
+
+func (m *configStateMount) toMap() map[string]interface{} {
+	options := make(map[string]interface{}, len(m.Options))
+	for k, v := range m.Options {
+		options[k] = v
+	}
+	return map[string]interface{}{
+		"type":              m.Type,
+		"description":       m.Description,
+		"local":             m.Local,
+		"seal_wrap":         m.SealWrap,
+		"options":           options,
+		"default_lease_ttl": int64(m.DefaultLeaseTTL.Seconds()),
+		"max_lease_ttl":     int64(m.MaxLeaseTTL.Seconds()),
+	}
+}
+
+// mountData returns the data of the request enabling the mount.
+func (m *configStateMount) mountData() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        m.Type,
+		"description": m.Description,
+		"local":       m.Local,
+		"seal_wrap":   m.SealWrap,
+		"options":     m.Options,
+		"config": map[string]interface{}{
+			"default_lease_ttl": configStateTTL(m.DefaultLeaseTTL),
+			"max_lease_ttl":     configStateTTL(m.MaxLeaseTTL),
+		},
+	}
+}
+
+func (q *configStateQuota) toMap() map[string]interface{} {
+	return map[string]interface{}{
+		"path":           q.Path,
+		"role":           q.Role,
+		"rate":           q.Rate,
+		"interval":       int64(q.Interval.Seconds()),
+		"block_interval": int64(q.BlockInterval.Seconds()),
+		"inheritable":    q.Inheritable,
+	}
+}
+
+// quotaData returns the data of the request writing the quota.
+func (q *configStateQuota) quotaData() map[string]interface{} {
+	data := q.toMap()
+	// Global quotas are always inheritable, and can't be given the flag
+	// explicitly otherwise.
+	if q.Path == "" {
+		delete(data, "inheritable")
+	}
+	return data
+}
+
+func configStateChangesData(changes []*configStateChange) []map[string]interface{} {
+	data := make([]map[string]interface{}, 0, len(changes))
+	for _, change := range changes {
+		d := map[string]interface{}{
+			"kind":   change.Kind,
+			"name":   change.Name,
+			"action": change.Action,
+		}
+		if len(change.Fields) > 0 {
+			d["fields"] = change.Fields
+		}
+		data = append(data, d)
+	}
+	return data
+}
+
+func configStateRequest(op logical.Operation, path string, data map[string]interface{}) *logical.Request {
+	return &logical.Request{
+		Operation: op,
+		Path:      path,
+		Data:      data,
+	}
+}
+
+// configStateTTL formats a lease TTL of a mount for the mount APIs, which
+// take "system" to use the system default.
+func configStateTTL(ttl time.Duration) string {
+	if ttl == 0 {
+		return "system"
+	}
+	return fmt.Sprintf("%ds", int64(ttl.Seconds()))
+}
+
+func sortedConfigStateKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,192 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSystemBackend_ConfigState verifies that declarative states are planned,
+// applied, rolled back when a change fails, and checked for drift.
+func TestSystemBackend_ConfigState(t *testing.T) {
+	c, _, root := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	request := func(op logical.Operation, path string, data map[string]interface{}) (*logical.Response, error) {
+		t.Helper()
+		req := logical.TestRequest(t, op, path)
+		req.ClientToken = root
+		req.Data = data
+		resp, err := c.HandleRequest(ctx, req)
+		if err == nil && resp != nil && resp.IsError() {
+			err = resp.Error()
+		}
+		return resp, err
+	}
+
+	resp, err := request(logical.ReadOperation, "sys/config/state", nil)
+	require.NoError(t, err)
+	state := resp.Data["state"].(map[string]interface{})
+	require.Contains(t, state["mounts"], "secret/")
+	require.NotContains(t, state["mounts"], "sys/")
+	require.NotContains(t, state["auth"], "token/")
+	require.Contains(t, state["policies"], "default")
+	require.NotContains(t, state["policies"], "root")
+
+	desired := map[string]interface{}{
+		"mounts": map[string]interface{}{
+			"secret": map[string]interface{}{
+				"type":        "kv",
+				"description": "key/value secret storage",
+			},
+			"foo/": map[string]interface{}{
+				"type":              "kv",
+				"description":       "foo",
+				"default_lease_ttl": "1h",
+			},
+		},
+		"auth": map[string]interface{}{
+			"noop/": map[string]interface{}{
+				"type": "noop",
+			},
+		},
+		"policies": map[string]interface{}{
+			"dev": `path "foo/*" { capabilities = ["read"] }`,
+		},
+		"rate_limit_quotas": map[string]interface{}{
+			"foo": map[string]interface{}{
+				"path": "foo/",
+				"rate": 10,
+			},
+		},
+	}
+
+	// Dry runs don't change anything
+	resp, err = request(logical.UpdateOperation, "sys/config/state", map[string]interface{}{
+		"state":   desired,
+		"dry_run": true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []map[string]interface{}{
+		{"kind": "policy", "name": "dev", "action": "create"},
+		{"kind": "mount", "name": "foo/", "action": "create"},
+		{"kind": "auth", "name": "noop/", "action": "create"},
+		{"kind": "rate_limit_quota", "name": "foo", "action": "create"},
+	}, resp.Data["changes"])
+	require.Nil(t, c.router.MatchingMountEntry(ctx, "foo/"))
+
+	_, err = request(logical.ReadOperation, "sys/config/state/drift", nil)
+	require.Error(t, err)
+
+	resp, err = request(logical.UpdateOperation, "sys/config/state", map[string]interface{}{
+		"state": desired,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Data["changes"], 4)
+
+	me := c.router.MatchingMountEntry(ctx, "foo/")
+	require.NotNil(t, me)
+	require.Equal(t, "foo", me.Description)
+	require.Equal(t, time.Hour, me.Config.DefaultLeaseTTL)
+	require.NotNil(t, c.router.MatchingMountEntry(ctx, "auth/noop/"))
+	policy, err := c.policyStore.GetPolicy(ctx, "dev", PolicyTypeACL)
+	require.NoError(t, err)
+	require.NotNil(t, policy)
+
+	// Applying the same state again changes nothing
+	resp, err = request(logical.UpdateOperation, "sys/config/state", map[string]interface{}{
+		"state": desired,
+	})
+	require.NoError(t, err)
+	require.Empty(t, resp.Data["changes"])
+
+	// Changes made by other means are reported as drift
+	_, err = request(logical.UpdateOperation, "sys/mounts/foo/tune", map[string]interface{}{
+		"description": "bar",
+	})
+	require.NoError(t, err)
+	resp, err = request(logical.ReadOperation, "sys/config/state/drift", nil)
+	require.NoError(t, err)
+	require.Equal(t, []map[string]interface{}{
+		{"kind": "mount", "name": "foo/", "action": "update", "fields": []string{"description"}},
+	}, resp.Data["drift"])
+
+	// Mounts can't change type
+	desired["mounts"].(map[string]interface{})["foo/"].(map[string]interface{})["type"] = "noop"
+	_, err = request(logical.UpdateOperation, "sys/config/state", map[string]interface{}{
+		"state": desired,
+	})
+	require.ErrorContains(t, err, "can't be changed")
+	desired["mounts"].(map[string]interface{})["foo/"].(map[string]interface{})["type"] = "kv"
+
+	// The changes made are rolled back when one fails
+	desired["policies"].(map[string]interface{})["ops"] = `path "bar/*" { capabilities = ["read"] }`
+	desired["mounts"].(map[string]interface{})["bar/"] = map[string]interface{}{
+		"type": "nonexistent",
+	}
+	_, err = request(logical.UpdateOperation, "sys/config/state", map[string]interface{}{
+		"state": desired,
+	})
+	require.Error(t, err)
+	policy, err = c.policyStore.GetPolicy(ctx, "ops", PolicyTypeACL)
+	require.NoError(t, err)
+	require.Nil(t, policy)
+	me = c.router.MatchingMountEntry(ctx, "foo/")
+	require.NotNil(t, me)
+	require.Equal(t, "bar", me.Description)
+
+	// Each change is authorized by the policies of the token applying the
+	// state, not only by its access to sys/config/state
+	_, err = request(logical.UpdateOperation, "sys/policies/acl/config-state", map[string]interface{}{
+		"policy": `path "sys/config/state" { capabilities = ["read", "update", "sudo"] }`,
+	})
+	require.NoError(t, err)
+	testMakeServiceTokenViaCore(t, c, root, "config-state-token", "", []string{"config-state"})
+	req := logical.TestRequest(t, logical.UpdateOperation, "sys/config/state")
+	req.ClientToken = "config-state-token"
+	req.Data = map[string]interface{}{
+		"state": map[string]interface{}{
+			"mounts": map[string]interface{}{
+				"baz/": map[string]interface{}{
+					"type": "kv",
+				},
+			},
+		},
+	}
+	resp, err = c.HandleRequest(ctx, req)
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+	require.Contains(t, resp.Error().Error(), logical.ErrPermissionDenied.Error())
+	require.Nil(t, c.router.MatchingMountEntry(ctx, "baz/"))
+
+	// Only pruning removes what's missing from the state, but never the
+	// default policy
+	resp, err = request(logical.UpdateOperation, "sys/config/state", map[string]interface{}{
+		"state": map[string]interface{}{
+			"mounts": map[string]interface{}{
+				"secret/": map[string]interface{}{
+					"type":        "kv",
+					"description": "key/value secret storage",
+				},
+			},
+		},
+		"prune": true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []map[string]interface{}{
+		{"kind": "rate_limit_quota", "name": "foo", "action": "delete"},
+		{"kind": "policy", "name": "dev", "action": "delete"},
+		{"kind": "auth", "name": "noop/", "action": "delete"},
+		{"kind": "mount", "name": "foo/", "action": "delete"},
+	}, resp.Data["changes"])
+	require.Nil(t, c.router.MatchingMountEntry(ctx, "foo/"))
+	policy, err = c.policyStore.GetPolicy(ctx, "default", PolicyTypeACL)
+	require.NoError(t, err)
+	require.NotNil(t, policy)
+}
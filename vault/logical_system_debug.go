@@ -0,0 +1,152 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func (b *SystemBackend) debugPaths() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "internal/debug/request-capture$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "internal-debug",
+				OperationSuffix: "request-capture",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"enabled": {
+					Type:        framework.TypeBool,
+					Description: "If true, the timelines of recent requests handled by this node are captured.",
+				},
+				"sample_rate": {
+					Type:        framework.TypeFloat,
+					Description: "The fraction of requests, greater than 0 and at most 1, whose timelines are captured.",
+					Default:     1.0,
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handleRequestCaptureRead,
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "read",
+					},
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"enabled":     {Type: framework.TypeBool, Required: true},
+								"sample_rate": {Type: framework.TypeFloat, Required: true},
+							},
+						}},
+					},
+					Summary: "Returns whether request timeline capture is enabled on this node.",
+				},
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleRequestCaptureUpdate,
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "configure",
+					},
+					Responses: map[int][]framework.Response{
+						http.StatusNoContent: {{
+							Description: "OK",
+						}},
+					},
+					Summary: "Enables or disables request timeline capture on this node.",
+					Description: `Request timeline capture is node-local and not persisted; it is disabled
+again when the node restarts.`,
+				},
+			},
+		},
+		{
+			Pattern: "internal/debug/request/(?P<id>.+)",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "internal-debug",
+				OperationVerb:   "read",
+				OperationSuffix: "request-timeline",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"id": {
+					Type:        framework.TypeString,
+					Description: "The ID of the request.",
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handleRequestTimelineRead,
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"request_id":  {Type: framework.TypeString, Required: true},
+								"path":        {Type: framework.TypeString, Required: true},
+								"operation":   {Type: framework.TypeString, Required: true},
+								"mount_point": {Type: framework.TypeString, Required: true},
+								"start_time":  {Type: framework.TypeString, Required: true},
+								"duration_us": {Type: framework.TypeInt64, Required: true},
+								"phases":      {Type: framework.TypeSlice, Required: true},
+								"storage":     {Type: framework.TypeMap, Required: true},
+							},
+						}},
+					},
+					Summary: "Returns the timeline of a recent request handled by this node.",
+					Description: `Returns a breakdown of the phases of the request with the given ID
+(auth, policy check, backend handler, and auditing) and the storage operations
+it caused. Timelines are only available while request capture is enabled, and
+only for recent, sampled requests handled by this node.`,
+				},
+			},
+		},
+	}
+}
+
+func (b *SystemBackend) handleRequestCaptureRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	enabled, sampleRate := b.Core.requestTimelines.Config()
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"enabled":     enabled,
+			"sample_rate": sampleRate,
+		},
+	}, nil
+}
+
+func (b *SystemBackend) handleRequestCaptureUpdate(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	enabled, sampleRate := b.Core.requestTimelines.Config()
+	if enabledRaw, ok := d.GetOk("enabled"); ok {
+		enabled = enabledRaw.(bool)
+	}
+	if sampleRateRaw, ok := d.GetOk("sample_rate"); ok {
+		sampleRate = sampleRateRaw.(float64)
+	}
+
+	if err := b.Core.requestTimelines.Configure(enabled, sampleRate); err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+	return nil, nil
+}
+
+func (b *SystemBackend) handleRequestTimelineRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	if enabled, _ := b.Core.requestTimelines.Config(); !enabled {
+		return logical.ErrorResponse("request capture is not enabled"), logical.ErrInvalidRequest
+	}
+
+	timeline := b.Core.requestTimelines.Get(d.Get("id").(string))
+	if timeline == nil {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: timeline.ToMap(),
+	}, nil
+}
@@ -0,0 +1,306 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/vault/secretsimport"
+)
+
+// secretsImportPaths returns the paths importing the secrets of cloud secret
+// managers into kv-v2 mounts.
+func (b *SystemBackend) secretsImportPaths() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "import$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "secrets-import",
+				OperationVerb:   "start",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"source_type": {
+					Type:          framework.TypeString,
+					Description:   "The type of the secret manager to import secrets from.",
+					AllowedValues: []interface{}{string(secretsimport.SourceTypeAWS), string(secretsimport.SourceTypeGCP), string(secretsimport.SourceTypeAzure)},
+					Required:      true,
+				},
+				"source_config": {
+					Type:        framework.TypeKVPairs,
+					Description: "The configuration of the secret manager, such as its location and credentials. The secret manager is only accessed with these credentials, and custom endpoints must be allowed by the secrets_import_allowed_endpoints server setting.",
+				},
+				"mount": {
+					Type:        framework.TypeString,
+					Description: "The path of the kv-v2 mount to import the secrets into.",
+					Required:    true,
+				},
+				"rules": {
+					Type:        framework.TypeSlice,
+					Description: "The rules mapping the names of secrets to kv paths, each an object holding the regular expression the whole name must match (match), and the path to import the secret to, which may refer to the submatches of the expression (path). Secrets are imported with the first matching rule, and not at all if none matches. Without rules, secrets are imported to the path of their name.",
+				},
+				"overwrite": {
+					Type:        framework.TypeBool,
+					Description: "Whether to write a new version of kv secrets holding other data than the imported secrets. If false, such secrets are skipped as conflicts.",
+				},
+				"dry_run": {
+					Type:        framework.TypeBool,
+					Description: "Whether to only report the actions the import would take, without writing any secret.",
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleSecretsImportStart(),
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"import_id": {
+									Type:     framework.TypeString,
+									Required: true,
+								},
+							},
+						}},
+					},
+					Summary: "Start importing the secrets of a cloud secret manager into a kv-v2 mount.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["secrets-import"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["secrets-import"][1]),
+		},
+		{
+			Pattern: "import/status/?$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "secrets-import",
+				OperationVerb:   "list",
+				OperationSuffix: "statuses",
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ListOperation: &framework.PathOperation{
+					Callback: b.handleSecretsImportList(),
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"keys": {
+									Type:     framework.TypeStringSlice,
+									Required: true,
+								},
+							},
+						}},
+					},
+					Summary: "List the IDs of the imports run by this node since it started.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["secrets-import-status"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["secrets-import-status"][1]),
+		},
+		{
+			Pattern: "import/status/(?P<import_id>.+?)$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "secrets-import",
+				OperationVerb:   "read",
+				OperationSuffix: "status",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"import_id": {
+					Type:        framework.TypeString,
+					Description: "The ID of the import.",
+					Required:    true,
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handleSecretsImportStatus(),
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"import_id": {
+									Type:     framework.TypeString,
+									Required: true,
+								},
+								"import_info": {
+									Type:     framework.TypeMap,
+									Required: true,
+								},
+							},
+						}},
+					},
+					Summary: "Read the progress of an import.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["secrets-import-status"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["secrets-import-status"][1]),
+		},
+		{
+			Pattern: "import/cancel/(?P<import_id>.+?)$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "secrets-import",
+				OperationVerb:   "cancel",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"import_id": {
+					Type:        framework.TypeString,
+					Description: "The ID of the import.",
+					Required:    true,
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleSecretsImportCancel(),
+					Responses: map[int][]framework.Response{
+						http.StatusNoContent: {{
+							Description: "OK",
+						}},
+					},
+					Summary: "Cancel an import in progress.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["secrets-import-cancel"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["secrets-import-cancel"][1]),
+		},
+	}
+}
+
+func (b *SystemBackend) handleSecretsImportStart() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		sourceType := secretsimport.SourceType(d.Get("source_type").(string))
+		dryRun := d.Get("dry_run").(bool)
+
+		mount := sanitizePath(d.Get("mount").(string))
+		if mount == "/" {
+			return logical.ErrorResponse("mount is required"), nil
+		}
+		entry := b.Core.router.MatchingMountEntry(ctx, mount)
+		if entry == nil || entry.Path != mount || entry.Type != "kv" || entry.Options["version"] != "2" {
+			return logical.ErrorResponse("no kv-v2 mount at %q", mount), nil
+		}
+
+		// Writes to replicated mounts are done by the primary cluster.
+		if !dryRun && !entry.Local && b.Core.ReplicationState().HasState(consts.ReplicationPerformanceSecondary) {
+			return nil, logical.ErrReadOnly
+		}
+
+		mapper, err := secretsimport.NewMapper(d.Get("rules").([]interface{}))
+		if err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+
+		// The source outlives the request, as it is used by the import.
+		ns, err := namespace.FromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		source, err := secretsimport.NewSource(namespace.ContextWithNamespace(b.Core.activeContext, ns), sourceType, d.Get("source_config").(map[string]string), b.Core.secretsImportAllowedEndpoints)
+		if err != nil {
+			return logical.ErrorResponse("invalid source: %s", err), nil
+		}
+
+		importID, err := b.Core.startSecretsImport(ctx, req, sourceType, source, mapper, mount, dryRun, d.Get("overwrite").(bool))
+		if err != nil {
+			return nil, err
+		}
+
+		resp := &logical.Response{
+			Data: map[string]interface{}{
+				"import_id": importID,
+			},
+		}
+		resp.AddWarning(fmt.Sprintf("Import has been queued. Its progress is reported by sys/import/status/%s on this node.", importID))
+		return resp, nil
+	}
+}
+
+func (b *SystemBackend) handleSecretsImportList() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		ids := b.Core.listSecretsImports()
+		sort.Strings(ids)
+		return logical.ListResponse(ids), nil
+	}
+}
+
+func (b *SystemBackend) handleSecretsImportStatus() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		importID := d.Get("import_id").(string)
+		info := b.Core.readSecretsImport(importID)
+		if info == nil {
+			return nil, nil
+		}
+
+		results := make([]map[string]interface{}, 0, len(info.Results))
+		for _, r := range info.Results {
+			result := map[string]interface{}{
+				"name":   r.Name,
+				"action": r.Action,
+			}
+			if r.Path != "" {
+				result["path"] = r.Path
+			}
+			if r.Error != "" {
+				result["error"] = r.Error
+			}
+			results = append(results, result)
+		}
+
+		importInfo := map[string]interface{}{
+			"source_type":       string(info.SourceType),
+			"mount":             info.Mount,
+			"dry_run":           info.DryRun,
+			"overwrite":         info.Overwrite,
+			"status":            string(info.Status),
+			"error":             info.Error,
+			"total":             info.Total,
+			"processed":         info.Processed,
+			"counts":            info.Counts,
+			"results":           results,
+			"results_truncated": info.Truncated,
+			"start_time":        info.StartTime.Format(time.RFC3339Nano),
+			"end_time":          "",
+		}
+		if !info.EndTime.IsZero() {
+			importInfo["end_time"] = info.EndTime.Format(time.RFC3339Nano)
+		}
+
+		return &logical.Response{
+			Data: map[string]interface{}{
+				"import_id":   importID,
+				"import_info": importInfo,
+			},
+		}, nil
+	}
+}
+
+func (b *SystemBackend) handleSecretsImportCancel() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		importID := d.Get("import_id").(string)
+		if !b.Core.cancelSecretsImport(importID) {
+			return logical.ErrorResponse("no import with ID %q", importID), logical.ErrInvalidRequest
+		}
+		return nil, nil
+	}
+}
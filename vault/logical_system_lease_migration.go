@@ -0,0 +1,247 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// leaseMigrationPaths returns the paths exporting the leases of a cluster and
+// importing them into another, so that they stay revocable when migrating
+// from one cluster to the other.
+func (b *SystemBackend) leaseMigrationPaths() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "leases/import/key$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "leases",
+				OperationVerb:   "read",
+				OperationSuffix: "import-key",
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handleLeaseImportKeyRead,
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"public_key": {
+									Type:        framework.TypeString,
+									Description: "The PEM-encoded RSA public key leases must be exported to.",
+									Required:    true,
+								},
+							},
+						}},
+					},
+					Summary: "Read the public key leases imported into this cluster must be exported to.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["lease_import_key"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["lease_import_key"][1]),
+		},
+
+		{
+			Pattern: "leases/export$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "leases",
+				OperationVerb:   "export",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"public_key": {
+					Type:        framework.TypeString,
+					Description: "The PEM-encoded import key of the cluster the leases are exported to, read from its sys/leases/import/key endpoint.",
+					Required:    true,
+				},
+				"prefix": {
+					Type:        framework.TypeString,
+					Description: "Only export the leases whose ID starts with this prefix, such as the path of a mount.",
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleLeasesExport,
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"bundle": {
+									Type:        framework.TypeString,
+									Description: "The leases, encrypted to the import key.",
+									Required:    true,
+								},
+								"lease_count": {
+									Type:        framework.TypeInt,
+									Description: "The number of leases exported.",
+									Required:    true,
+								},
+								"released": {
+									Type:        framework.TypeStringSlice,
+									Description: "The IDs of the leases exported and removed from this cluster.",
+									Required:    true,
+								},
+							},
+						}},
+					},
+					Summary: "Export the leases of secrets to another cluster.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["lease_export"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["lease_export"][1]),
+		},
+
+		{
+			Pattern: "leases/import$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "leases",
+				OperationVerb:   "import",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"bundle": {
+					Type:        framework.TypeString,
+					Description: "The leases exported from another cluster.",
+					Required:    true,
+				},
+				"owner_accessor": {
+					Type:        framework.TypeString,
+					Description: "The accessor of the service token the leases whose token doesn't exist on this cluster are attached to. If not set, these leases are skipped.",
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleLeasesImport,
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"imported": {
+									Type:        framework.TypeStringSlice,
+									Description: "The IDs of the leases imported.",
+									Required:    true,
+								},
+								"skipped": {
+									Type:        framework.TypeMap,
+									Description: "The reasons the other leases were skipped, by lease ID.",
+									Required:    true,
+								},
+							},
+						}},
+					},
+					Summary: "Import leases exported from another cluster.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["lease_import"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["lease_import"][1]),
+		},
+	}
+}
+
+func (b *SystemBackend) handleLeaseImportKeyRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	key, err := b.Core.expiration.leaseImportKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"public_key": string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+		},
+	}, nil
+}
+
+func (b *SystemBackend) handleLeasesExport(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	block, _ := pem.Decode([]byte(d.Get("public_key").(string)))
+	if block == nil {
+		return logical.ErrorResponse("public_key must be a PEM-encoded RSA public key"), logical.ErrInvalidRequest
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return logical.ErrorResponse("failed to parse public_key: %s", err), logical.ErrInvalidRequest
+	}
+	importKey, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return logical.ErrorResponse("public_key must be an RSA public key"), logical.ErrInvalidRequest
+	}
+
+	bundle, exported, err := b.Core.expiration.exportLeases(ctx, d.Get("prefix").(string), importKey)
+	if err != nil {
+		return handleError(err)
+	}
+
+	// The leases exported are removed, so that this cluster doesn't revoke
+	// them once the other cluster manages them
+	released, failed := b.Core.expiration.releaseLeases(ctx, exported)
+
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"bundle":      bundle,
+			"lease_count": len(exported),
+			"released":    released,
+		},
+	}
+	for _, leaseID := range exported {
+		if err, ok := failed[leaseID]; ok {
+			resp.AddWarning(fmt.Sprintf("lease %q was exported but not removed from this cluster, which still revokes it when it expires: %s", leaseID, err))
+		}
+	}
+	return resp, nil
+}
+
+func (b *SystemBackend) handleLeasesImport(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	var owner *logical.TokenEntry
+	if accessor := d.Get("owner_accessor").(string); accessor != "" {
+		aEntry, err := b.Core.tokenStore.lookupByAccessor(ctx, accessor, false, false)
+		if err != nil {
+			return nil, err
+		}
+		if aEntry == nil || aEntry.TokenID == "" {
+			return logical.ErrorResponse("no token with accessor %q", accessor), logical.ErrInvalidRequest
+		}
+		owner, err = b.Core.tokenStore.Lookup(ctx, aEntry.TokenID)
+		if err != nil {
+			return nil, err
+		}
+		if owner == nil {
+			return logical.ErrorResponse("no token with accessor %q", accessor), logical.ErrInvalidRequest
+		}
+		if owner.Type != logical.TokenTypeService {
+			return logical.ErrorResponse("owner_accessor must be the accessor of a service token"), logical.ErrInvalidRequest
+		}
+	}
+
+	imported, skipped, err := b.Core.expiration.importLeases(ctx, d.Get("bundle").(string), owner)
+	if err != nil {
+		return handleError(err)
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"imported": imported,
+			"skipped":  skipped,
+		},
+	}, nil
+}
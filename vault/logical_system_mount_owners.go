@@ -0,0 +1,342 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/go-secure-stdlib/strutil"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// mountOwnerPaths returns the paths delegating the administration of mounts
+// and auth methods to their owners. They must come before the paths of
+// mounts and auth methods, whose patterns would match them too.
+func (b *SystemBackend) mountOwnerPaths() []*framework.Path {
+	ownersFields := func() map[string]*framework.FieldSchema {
+		return map[string]*framework.FieldSchema{
+			"path": {
+				Type:        framework.TypeString,
+				Description: strings.TrimSpace(sysHelp["mount_path"][0]),
+			},
+			"entity_ids": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "The IDs of the entities owning the mount.",
+			},
+			"group_ids": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "The IDs of the groups owning the mount. The entities belonging to them, directly or through other groups, are owners.",
+			},
+		}
+	}
+
+	ownersResponse := map[string]*framework.FieldSchema{
+		"entity_ids": {
+			Type:     framework.TypeStringSlice,
+			Required: true,
+		},
+		"group_ids": {
+			Type:     framework.TypeStringSlice,
+			Required: true,
+		},
+		"policy": {
+			Type:        framework.TypeString,
+			Description: "The name of the policy generated for the owners of the mount.",
+			Required:    true,
+		},
+	}
+
+	ownersOperations := func(table string) map[logical.Operation]framework.OperationHandler {
+		return map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.handleMountOwnersRead(table),
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb:   "read",
+					OperationSuffix: "owners",
+				},
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields:      ownersResponse,
+					}},
+				},
+				Summary: "Read the owners of the mount.",
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.handleMountOwnersUpdate(table),
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb:   "set",
+					OperationSuffix: "owners",
+				},
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields:      ownersResponse,
+					}},
+				},
+				Summary: "Set the owners of the mount.",
+			},
+			logical.DeleteOperation: &framework.PathOperation{
+				Callback: b.handleMountOwnersDelete(table),
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb:   "remove",
+					OperationSuffix: "owners",
+				},
+				Responses: map[int][]framework.Response{
+					http.StatusNoContent: {{
+						Description: "OK",
+					}},
+				},
+				Summary: "Remove the owners of the mount.",
+			},
+		}
+	}
+
+	return []*framework.Path{
+		{
+			Pattern: "mounts/(?P<path>.+?)/owners$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "mounts",
+			},
+
+			Fields:     ownersFields(),
+			Operations: ownersOperations(mountTableType),
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["mount_owners"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["mount_owners"][1]),
+		},
+		{
+			Pattern: "auth/(?P<path>.+?)/owners$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "auth",
+			},
+
+			Fields:     ownersFields(),
+			Operations: ownersOperations(credentialTableType),
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["mount_owners"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["mount_owners"][1]),
+		},
+		{
+			Pattern: "mount-owners$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "mount-owners",
+				OperationVerb:   "list",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"entity_id": {
+					Type:        framework.TypeString,
+					Description: "Only list the mounts owned by this entity, directly or through its groups.",
+					Query:       true,
+				},
+				"group_id": {
+					Type:        framework.TypeString,
+					Description: "Only list the mounts owned by this group.",
+					Query:       true,
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handleMountOwnersList,
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"mounts": {
+									Type:     framework.TypeMap,
+									Required: true,
+								},
+								"auth": {
+									Type:     framework.TypeMap,
+									Required: true,
+								},
+							},
+						}},
+					},
+					Summary: "List the owned mounts and auth methods, and their owners.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["mount_owners_list"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["mount_owners_list"][1]),
+		},
+	}
+}
+
+// ownedMountEntry returns the mount or auth method at the path of the request,
+// which must not be one of the singleton mounts.
+func (b *SystemBackend) ownedMountEntry(ctx context.Context, table string, data *framework.FieldData) (*MountEntry, *logical.Response) {
+	path := sanitizePath(data.Get("path").(string))
+	routePath := path
+	if table == credentialTableType {
+		routePath = credentialRoutePrefix + path
+	}
+
+	entry := b.Core.router.MatchingMountEntry(ctx, routePath)
+	if entry == nil || entry.Path != path || entry.Table != table {
+		return nil, logical.ErrorResponse("no mount at %q", path)
+	}
+	if strutil.StrListContains(singletonMounts, entry.Type) {
+		return nil, logical.ErrorResponse("mount %q can't have owners", path)
+	}
+	return entry, nil
+}
+
+func mountOwnersData(entry *MountEntry, owners *MountOwners) map[string]interface{} {
+	data := map[string]interface{}{
+		"entity_ids": []string{},
+		"group_ids":  []string{},
+		"policy":     mountOwnerPolicyName(entry),
+	}
+	if owners != nil {
+		if len(owners.EntityIDs) > 0 {
+			data["entity_ids"] = owners.EntityIDs
+		}
+		if len(owners.GroupIDs) > 0 {
+			data["group_ids"] = owners.GroupIDs
+		}
+	}
+	return data
+}
+
+func (b *SystemBackend) handleMountOwnersRead(table string) framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+		entry, resp := b.ownedMountEntry(ctx, table, data)
+		if resp != nil {
+			return resp, nil
+		}
+
+		owners := b.ownedMountOwners(entry)
+		return &logical.Response{
+			Data: mountOwnersData(entry, owners),
+		}, nil
+	}
+}
+
+func (b *SystemBackend) handleMountOwnersUpdate(table string) framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+		entry, resp := b.ownedMountEntry(ctx, table, data)
+		if resp != nil {
+			return resp, nil
+		}
+
+		entityIDs := strutil.RemoveDuplicates(data.Get("entity_ids").([]string), false)
+		groupIDs := strutil.RemoveDuplicates(data.Get("group_ids").([]string), false)
+		for _, id := range entityIDs {
+			entity, err := b.Core.identityStore.MemDBEntityByID(id, false)
+			if err != nil {
+				return nil, err
+			}
+			if entity == nil {
+				return logical.ErrorResponse("entity %q not found", id), nil
+			}
+		}
+		for _, id := range groupIDs {
+			group, err := b.Core.identityStore.MemDBGroupByID(id, false)
+			if err != nil {
+				return nil, err
+			}
+			if group == nil {
+				return logical.ErrorResponse("group %q not found", id), nil
+			}
+		}
+
+		var owners *MountOwners
+		if len(entityIDs) > 0 || len(groupIDs) > 0 {
+			owners = &MountOwners{
+				EntityIDs: entityIDs,
+				GroupIDs:  groupIDs,
+			}
+		}
+		if err := b.Core.setMountOwners(ctx, entry, owners); err != nil {
+			b.Backend.Logger().Error("failed to set mount owners", "path", entry.Path, "error", err)
+			return handleError(err)
+		}
+
+		return &logical.Response{
+			Data: mountOwnersData(entry, owners),
+		}, nil
+	}
+}
+
+func (b *SystemBackend) handleMountOwnersDelete(table string) framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+		entry, resp := b.ownedMountEntry(ctx, table, data)
+		if resp != nil {
+			return resp, nil
+		}
+
+		if err := b.Core.setMountOwners(ctx, entry, nil); err != nil {
+			b.Backend.Logger().Error("failed to remove mount owners", "path", entry.Path, "error", err)
+			return handleError(err)
+		}
+		return nil, nil
+	}
+}
+
+func (b *SystemBackend) handleMountOwnersList(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entityID := data.Get("entity_id").(string)
+	groupID := data.Get("group_id").(string)
+
+	var groupIDs map[string]bool
+	if entityID != "" {
+		groupIDs, err = b.Core.entityGroupIDs(entityID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	mounts := make(map[string]interface{})
+	auth := make(map[string]interface{})
+	for _, m := range b.Core.ownedMounts() {
+		switch {
+		case m.entry.NamespaceID != ns.ID:
+			continue
+		case entityID != "" && !m.owners.owns(entityID, groupIDs):
+			continue
+		case groupID != "" && !strutil.StrListContains(m.owners.GroupIDs, groupID):
+			continue
+		}
+
+		if m.entry.Table == credentialTableType {
+			auth[m.entry.Path] = mountOwnersData(m.entry, m.owners)
+		} else {
+			mounts[m.entry.Path] = mountOwnersData(m.entry, m.owners)
+		}
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"mounts": mounts,
+			"auth":   auth,
+		},
+	}, nil
+}
+
+// ownedMountOwners returns the owners of the mount, read under the lock of
+// its table.
+func (b *SystemBackend) ownedMountOwners(entry *MountEntry) *MountOwners {
+	if entry.Table == credentialTableType {
+		b.Core.authLock.RLock()
+		defer b.Core.authLock.RUnlock()
+	} else {
+		b.Core.mountsLock.RLock()
+		defer b.Core.mountsLock.RUnlock()
+	}
+	return entry.Owners
+}
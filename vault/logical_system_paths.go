@@ -1529,9 +1529,189 @@ func (b *SystemBackend) auditHashPath() *framework.Path {
 	}
 }
 
+func (b *SystemBackend) auditTailPath() *framework.Path {
+	return &framework.Path{
+		Pattern: "audit-tail/(?P<path>.+)",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: "auditing",
+			OperationVerb:   "tail",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"path": {
+				Type:        framework.TypeString,
+				Description: strings.TrimSpace(sysHelp["audit_path"][0]),
+			},
+			"filter": {
+				Type:        framework.TypeString,
+				Description: strings.TrimSpace(sysHelp["audit_tail_filter"][0]),
+				Query:       true,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.handleAuditTail,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+					}},
+				},
+			},
+		},
+
+		HelpSynopsis:    strings.TrimSpace(sysHelp["audit-tail"][0]),
+		HelpDescription: strings.TrimSpace(sysHelp["audit-tail"][1]),
+	}
+}
+
+func (b *SystemBackend) auditExportPath() *framework.Path {
+	return &framework.Path{
+		Pattern: "audit-export/(?P<path>.+)",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: "auditing",
+			OperationVerb:   "export",
+			OperationSuffix: "entity-entries",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"path": {
+				Type:        framework.TypeString,
+				Description: strings.TrimSpace(sysHelp["audit_path"][0]),
+			},
+			"entity_id": {
+				Type:        framework.TypeString,
+				Description: strings.TrimSpace(sysHelp["audit_export_entity_id"][0]),
+				Query:       true,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.handleAuditExport,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+					}},
+					http.StatusNoContent: {{
+						Description: "No entries",
+					}},
+				},
+			},
+		},
+
+		HelpSynopsis:    strings.TrimSpace(sysHelp["audit-export"][0]),
+		HelpDescription: strings.TrimSpace(sysHelp["audit-export"][1]),
+	}
+}
+
+func (b *SystemBackend) auditTestPath() *framework.Path {
+	return &framework.Path{
+		Pattern: "audit-test/(?P<path>.+)",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: "auditing",
+			OperationVerb:   "test",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"path": {
+				Type:        framework.TypeString,
+				Description: strings.TrimSpace(sysHelp["audit_path"][0]),
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.handleAuditTest,
+				Responses: map[int][]framework.Response{
+					http.StatusNoContent: {{
+						Description: "OK",
+					}},
+				},
+			},
+		},
+
+		HelpSynopsis:    strings.TrimSpace(sysHelp["audit-test"][0]),
+		HelpDescription: strings.TrimSpace(sysHelp["audit-test"][1]),
+	}
+}
+
+func (b *SystemBackend) auditStatusPath() *framework.Path {
+	return &framework.Path{
+		Pattern: "audit/status$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: "auditing",
+			OperationVerb:   "read",
+			OperationSuffix: "status",
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.handleAuditStatus,
+				Summary:  "Report the health of the enabled audit devices.",
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						// this response has dynamic keys
+						Description: "OK",
+						Fields:      nil,
+					}},
+				},
+			},
+		},
+
+		HelpSynopsis:    strings.TrimSpace(sysHelp["audit-status"][0]),
+		HelpDescription: strings.TrimSpace(sysHelp["audit-status"][1]),
+	}
+}
+
+func (b *SystemBackend) auditRotateHMACPath() *framework.Path {
+	return &framework.Path{
+		Pattern: "audit/(?P<path>.+)/rotate-hmac$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: "auditing",
+			OperationVerb:   "rotate",
+			OperationSuffix: "hmac-key",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"path": {
+				Type:        framework.TypeString,
+				Description: strings.TrimSpace(sysHelp["audit_path"][0]),
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.handleAuditRotateHMAC,
+				Summary:  "Rotate the HMAC key of the given audit device.",
+				Responses: map[int][]framework.Response{
+					http.StatusNoContent: {{
+						Description: "OK",
+					}},
+				},
+			},
+		},
+
+		HelpSynopsis:    strings.TrimSpace(sysHelp["audit-rotate-hmac"][0]),
+		HelpDescription: strings.TrimSpace(sysHelp["audit-rotate-hmac"][1]),
+	}
+}
+
 func (b *SystemBackend) auditPaths() []*framework.Path {
 	return []*framework.Path{
 		b.auditHashPath(),
+		b.auditTailPath(),
+		b.auditExportPath(),
+		b.auditTestPath(),
+		// The status and rotation paths must be matched before the path of
+		// the devices.
+		b.auditStatusPath(),
+		b.auditRotateHMACPath(),
 
 		{
 			Pattern: "audit$",
@@ -2093,6 +2273,43 @@ func (b *SystemBackend) pluginsReloadPath() *framework.Path {
 	}
 }
 
+func (b *SystemBackend) pluginsRuntimeStatsPath() *framework.Path {
+	return &framework.Path{
+		Pattern: "plugins/runtime/stats$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: "plugins",
+			OperationVerb:   "read",
+			OperationSuffix: "runtime-stats",
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.handlePluginRuntimeStats,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields: map[string]*framework.FieldSchema{
+							"processes": {
+								Type:     framework.TypeSlice,
+								Required: true,
+							},
+							"restart_policy": {
+								Type:     framework.TypeMap,
+								Required: true,
+							},
+						},
+					}},
+				},
+				Summary: strings.TrimSpace(sysHelp["plugin-runtime-stats"][0]),
+			},
+		},
+
+		HelpSynopsis:    strings.TrimSpace(sysHelp["plugin-runtime-stats"][0]),
+		HelpDescription: strings.TrimSpace(sysHelp["plugin-runtime-stats"][1]),
+	}
+}
+
 func (b *SystemBackend) toolsPaths() []*framework.Path {
 	return []*framework.Path{
 		{
@@ -2130,7 +2347,7 @@ func (b *SystemBackend) toolsPaths() []*framework.Path {
 				"format": {
 					Type:        framework.TypeString,
 					Default:     "hex",
-					Description: `Encoding format to use. Can be "hex" or "base64". Defaults to "hex".`,
+					Description: `Encoding format to use. Can be "hex", "base64", "base64url" or "base32". Defaults to "hex".`,
 				},
 			},
 
@@ -2178,7 +2395,7 @@ func (b *SystemBackend) toolsPaths() []*framework.Path {
 				"format": {
 					Type:        framework.TypeString,
 					Default:     "base64",
-					Description: `Encoding format to use. Can be "hex" or "base64". Defaults to "base64".`,
+					Description: `Encoding format to use. Can be "hex", "base64", "base64url" or "base32". Defaults to "base64".`,
 				},
 
 				"source": {
@@ -2208,68 +2425,38 @@ func (b *SystemBackend) toolsPaths() []*framework.Path {
 			HelpSynopsis:    strings.TrimSpace(sysHelp["random"][0]),
 			HelpDescription: strings.TrimSpace(sysHelp["random"][1]),
 		},
-	}
-}
 
-func (b *SystemBackend) internalPaths() []*framework.Path {
-	return []*framework.Path{
 		{
-			Pattern: "internal/specs/openapi",
+			Pattern: "tools/uuid",
 
 			DisplayAttrs: &framework.DisplayAttributes{
-				OperationPrefix: "internal",
 				OperationVerb:   "generate",
+				OperationSuffix: "uuids",
 			},
 
 			Fields: map[string]*framework.FieldSchema{
-				"context": {
-					Type:        framework.TypeString,
-					Description: "Context string appended to every operationId",
-				},
-				"generic_mount_paths": {
-					Type:        framework.TypeBool,
-					Description: "Use generic mount paths",
-					Query:       true,
-					Default:     false,
+				"version": {
+					Type:        framework.TypeInt,
+					Default:     4,
+					Description: `The version of the UUIDs to generate: 4 for random UUIDs, or 7 for UUIDs ordered by their time of generation. Defaults to 4.`,
 				},
-			},
 
-			Operations: map[logical.Operation]framework.OperationHandler{
-				logical.ReadOperation: &framework.PathOperation{
-					Callback: b.pathInternalOpenAPI,
-					DisplayAttrs: &framework.DisplayAttributes{
-						OperationSuffix: "open-api-document",
-					},
-				},
-				logical.UpdateOperation: &framework.PathOperation{
-					Callback: b.pathInternalOpenAPI,
-					DisplayAttrs: &framework.DisplayAttributes{
-						OperationSuffix: "open-api-document-with-parameters",
-					},
+				"count": {
+					Type:        framework.TypeInt,
+					Default:     1,
+					Description: "The number of UUIDs to generate, up to 1000. Defaults to 1.",
 				},
 			},
 
-			HelpSynopsis: "Generate an OpenAPI 3 document of all mounted paths.",
-		},
-		{
-			Pattern: "internal/ui/feature-flags",
-
-			DisplayAttrs: &framework.DisplayAttributes{
-				OperationPrefix: "internal-ui",
-				OperationVerb:   "list",
-				OperationSuffix: "enabled-feature-flags",
-			},
-
 			Operations: map[logical.Operation]framework.OperationHandler{
-				logical.ReadOperation: &framework.PathOperation{
-					// callback is absent because this is an unauthenticated method
-					Summary: "Lists enabled feature flags.",
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.pathUUIDWrite,
 					Responses: map[int][]framework.Response{
 						http.StatusOK: {{
 							Description: "OK",
 							Fields: map[string]*framework.FieldSchema{
-								"feature_flags": {
-									Type:     framework.TypeCommaStringSlice,
+								"uuids": {
+									Type:     framework.TypeStringSlice,
 									Required: true,
 								},
 							},
@@ -2277,71 +2464,246 @@ func (b *SystemBackend) internalPaths() []*framework.Path {
 					},
 				},
 			},
-			HelpSynopsis:    strings.TrimSpace(sysHelp["internal-ui-feature-flags"][0]),
-			HelpDescription: strings.TrimSpace(sysHelp["internal-ui-feature-flags"][1]),
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["uuid"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["uuid"][1]),
 		},
+
 		{
-			Pattern: "internal/ui/mounts",
+			Pattern: "tools/ulid",
 
 			DisplayAttrs: &framework.DisplayAttributes{
-				OperationPrefix: "internal-ui",
-				OperationVerb:   "list",
-				OperationSuffix: "enabled-visible-mounts",
+				OperationVerb:   "generate",
+				OperationSuffix: "ulids",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"count": {
+					Type:        framework.TypeInt,
+					Default:     1,
+					Description: "The number of ULIDs to generate, up to 1000. Defaults to 1.",
+				},
 			},
 
 			Operations: map[logical.Operation]framework.OperationHandler{
-				logical.ReadOperation: &framework.PathOperation{
-					Callback: b.pathInternalUIMountsRead,
-					Summary:  "Lists all enabled and visible auth and secrets mounts.",
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.pathULIDWrite,
 					Responses: map[int][]framework.Response{
 						http.StatusOK: {{
 							Description: "OK",
 							Fields: map[string]*framework.FieldSchema{
-								"secret": {
-									Description: "secret mounts",
-									Type:        framework.TypeMap,
-									Required:    true,
-								},
-								"auth": {
-									Description: "auth mounts",
-									Type:        framework.TypeMap,
-									Required:    true,
+								"ulids": {
+									Type:     framework.TypeStringSlice,
+									Required: true,
 								},
 							},
 						}},
 					},
 				},
 			},
-			HelpSynopsis:    strings.TrimSpace(sysHelp["internal-ui-mounts"][0]),
-			HelpDescription: strings.TrimSpace(sysHelp["internal-ui-mounts"][1]),
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["ulid"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["ulid"][1]),
 		},
+
 		{
-			Pattern: "internal/ui/mounts/(?P<path>.+)",
+			Pattern: "tools/hkdf",
 
 			DisplayAttrs: &framework.DisplayAttributes{
-				OperationPrefix: "internal-ui",
-				OperationVerb:   "read",
-				OperationSuffix: "mount-information",
+				OperationVerb:   "derive",
+				OperationSuffix: "hkdf",
 			},
 
 			Fields: map[string]*framework.FieldSchema{
-				"path": {
+				"input": {
 					Type:        framework.TypeString,
-					Description: "The path of the mount.",
+					Description: "The base64-encoded input key material",
+					Required:    true,
 				},
-			},
 
-			Operations: map[logical.Operation]framework.OperationHandler{
-				logical.ReadOperation: &framework.PathOperation{
-					Callback: b.pathInternalUIMountRead,
-					Summary:  "Return information about the given mount.",
-					Responses: map[int][]framework.Response{
-						http.StatusOK: {{
-							Description: "OK",
-							Fields: map[string]*framework.FieldSchema{
-								"type": {
-									Type:     framework.TypeString,
-									Required: true,
+				"salt": {
+					Type:        framework.TypeString,
+					Description: "The base64-encoded salt. Defaults to no salt.",
+				},
+
+				"info": {
+					Type:        framework.TypeString,
+					Description: "The base64-encoded context and application specific information, binding the derived bytes to their use. Defaults to no information.",
+				},
+
+				"algorithm": {
+					Type:        framework.TypeString,
+					Default:     "sha2-256",
+					Description: `Hash algorithm to derive with, among the algorithms of tools/hash. Defaults to "sha2-256".`,
+				},
+
+				"bytes": {
+					Type:        framework.TypeInt,
+					Default:     32,
+					Description: "The number of bytes to derive, up to 255 times the size of the hash. Defaults to 32 (256 bits).",
+				},
+
+				"format": {
+					Type:        framework.TypeString,
+					Default:     "base64",
+					Description: `Encoding format to use. Can be "hex", "base64", "base64url" or "base32". Defaults to "base64".`,
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.pathHKDFWrite,
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"derived_bytes": {
+									Type:     framework.TypeString,
+									Required: true,
+								},
+							},
+						}},
+					},
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["hkdf"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["hkdf"][1]),
+		},
+	}
+}
+
+func (b *SystemBackend) internalPaths() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "internal/specs/openapi",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "internal",
+				OperationVerb:   "generate",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"context": {
+					Type:        framework.TypeString,
+					Description: "Context string appended to every operationId",
+				},
+				"generic_mount_paths": {
+					Type:        framework.TypeBool,
+					Description: "Use generic mount paths",
+					Query:       true,
+					Default:     false,
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.pathInternalOpenAPI,
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationSuffix: "open-api-document",
+					},
+				},
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.pathInternalOpenAPI,
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationSuffix: "open-api-document-with-parameters",
+					},
+				},
+			},
+
+			HelpSynopsis: "Generate an OpenAPI 3 document of all mounted paths.",
+		},
+		{
+			Pattern: "internal/ui/feature-flags",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "internal-ui",
+				OperationVerb:   "list",
+				OperationSuffix: "enabled-feature-flags",
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					// callback is absent because this is an unauthenticated method
+					Summary: "Lists enabled feature flags.",
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"feature_flags": {
+									Type:     framework.TypeCommaStringSlice,
+									Required: true,
+								},
+							},
+						}},
+					},
+				},
+			},
+			HelpSynopsis:    strings.TrimSpace(sysHelp["internal-ui-feature-flags"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["internal-ui-feature-flags"][1]),
+		},
+		{
+			Pattern: "internal/ui/mounts",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "internal-ui",
+				OperationVerb:   "list",
+				OperationSuffix: "enabled-visible-mounts",
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.pathInternalUIMountsRead,
+					Summary:  "Lists all enabled and visible auth and secrets mounts.",
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"secret": {
+									Description: "secret mounts",
+									Type:        framework.TypeMap,
+									Required:    true,
+								},
+								"auth": {
+									Description: "auth mounts",
+									Type:        framework.TypeMap,
+									Required:    true,
+								},
+							},
+						}},
+					},
+				},
+			},
+			HelpSynopsis:    strings.TrimSpace(sysHelp["internal-ui-mounts"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["internal-ui-mounts"][1]),
+		},
+		{
+			Pattern: "internal/ui/mounts/(?P<path>.+)",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "internal-ui",
+				OperationVerb:   "read",
+				OperationSuffix: "mount-information",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"path": {
+					Type:        framework.TypeString,
+					Description: "The path of the mount.",
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.pathInternalUIMountRead,
+					Summary:  "Return information about the given mount.",
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"type": {
+									Type:     framework.TypeString,
+									Required: true,
 								},
 								"description": {
 									Type:     framework.TypeString,
@@ -2359,6 +2721,9 @@ func (b *SystemBackend) internalPaths() []*framework.Path {
 									Type:     framework.TypeBool,
 									Required: true,
 								},
+								"data_key": {
+									Type: framework.TypeBool,
+								},
 								"external_entropy_access": {
 									Type:     framework.TypeBool,
 									Required: true,
@@ -2947,6 +3312,100 @@ func (b *SystemBackend) leasePaths() []*framework.Path {
 			HelpDescription: strings.TrimSpace(sysHelp["revoke-prefix"][1]),
 		},
 
+		{
+			Pattern: "leases/revoke-status/?$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "leases",
+				OperationVerb:   "list",
+				OperationSuffix: "revocation-jobs",
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ListOperation: &framework.PathOperation{
+					Callback: b.handleRevokeStatusList,
+					Summary:  strings.TrimSpace(sysHelp["revoke-status-list"][0]),
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"keys": {
+									Type:        framework.TypeStringSlice,
+									Description: "The IDs of the token revocation jobs.",
+								},
+							},
+						}},
+					},
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["revoke-status-list"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["revoke-status-list"][1]),
+		},
+
+		{
+			Pattern: "leases/revoke-status/(?P<job_id>.+)",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "leases",
+				OperationVerb:   "read",
+				OperationSuffix: "revocation-status",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"job_id": {
+					Type:        framework.TypeString,
+					Description: strings.TrimSpace(sysHelp["revoke-status-job-id"][0]),
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handleRevokeStatusRead,
+					Summary:  strings.TrimSpace(sysHelp["revoke-status"][0]),
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"job_id": {
+									Type: framework.TypeString,
+								},
+								"accessor": {
+									Type: framework.TypeString,
+								},
+								"status": {
+									Type: framework.TypeString,
+								},
+								"phase": {
+									Type: framework.TypeString,
+								},
+								"invalidated": {
+									Type: framework.TypeInt,
+								},
+								"revoked": {
+									Type: framework.TypeInt,
+								},
+								"error": {
+									Type:     framework.TypeString,
+									Required: false,
+								},
+								"start_time": {
+									Type: framework.TypeTime,
+								},
+								"end_time": {
+									Type:     framework.TypeTime,
+									Required: false,
+								},
+							},
+						}},
+					},
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["revoke-status"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["revoke-status"][1]),
+		},
+
 		{
 			Pattern: "leases/tidy$",
 
@@ -3208,6 +3667,16 @@ func (b *SystemBackend) monitorPath() *framework.Path {
 				Query:       true,
 				Default:     "standard",
 			},
+			"subsystem": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Subsystems to stream the logs of, such as \"core\" or \"expiration\". Logs of their sub-loggers are streamed too.",
+				Query:       true,
+			},
+			"mount": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Paths of the mounts to stream the logs of, such as \"secret/\" or \"auth/userpass/\".",
+				Query:       true,
+			},
 		},
 		Operations: map[logical.Operation]framework.OperationHandler{
 			logical.ReadOperation: &framework.PathOperation{
@@ -3249,19 +3718,239 @@ func (b *SystemBackend) inFlightRequestPath() *framework.Path {
 	}
 }
 
-func (b *SystemBackend) hostInfoPath() *framework.Path {
+func (b *SystemBackend) eventsSubscriptionsPath() *framework.Path {
 	return &framework.Path{
-		Pattern: "host-info/?",
+		Pattern: "events/subscriptions$",
 
 		DisplayAttrs: &framework.DisplayAttributes{
-			OperationVerb:   "collect",
-			OperationSuffix: "host-information",
+			OperationPrefix: "events",
+			OperationVerb:   "list",
+			OperationSuffix: "subscriptions",
 		},
 
 		Operations: map[logical.Operation]framework.OperationHandler{
 			logical.ReadOperation: &framework.PathOperation{
-				Callback:    b.handleHostInfo,
-				Summary:     strings.TrimSpace(sysHelp["host-info"][0]),
+				Callback: b.handleEventsSubscriptionsRead,
+				Summary:  strings.TrimSpace(sysHelp["events-subscriptions"][0]),
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields: map[string]*framework.FieldSchema{
+							"subscriptions": {
+								Type:        framework.TypeSlice,
+								Description: "The connected subscribers, with their filters and how far behind they are.",
+								Required:    true,
+							},
+						},
+					}},
+				},
+			},
+		},
+
+		HelpSynopsis:    strings.TrimSpace(sysHelp["events-subscriptions"][0]),
+		HelpDescription: strings.TrimSpace(sysHelp["events-subscriptions"][1]),
+	}
+}
+
+func (b *SystemBackend) batchPaths() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "batch$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "batch",
+				OperationVerb:   "request",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"requests": {
+					Type:        framework.TypeSlice,
+					Description: strings.TrimSpace(sysHelp["batch-requests"][0]),
+					Required:    true,
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleBatchRequest,
+					Summary:  strings.TrimSpace(sysHelp["batch"][0]),
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"responses": {
+									Type:        framework.TypeSlice,
+									Description: "The status and response of each request, in the order of the batch.",
+									Required:    true,
+								},
+							},
+						}},
+					},
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["batch"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["batch"][1]),
+		},
+	}
+}
+
+func (b *SystemBackend) kvRecursiveDeletePaths() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "kv-recursive-delete/?$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "kv-recursive-delete",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"path": {
+					Type:        framework.TypeString,
+					Description: strings.TrimSpace(sysHelp["kv-recursive-delete-path"][0]),
+					Required:    true,
+				},
+				"dry_run": {
+					Type:        framework.TypeBool,
+					Description: strings.TrimSpace(sysHelp["kv-recursive-delete-dry-run"][0]),
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleKVRecursiveDeleteStart,
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "start",
+					},
+					Summary: strings.TrimSpace(sysHelp["kv-recursive-delete-start"][0]),
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"dry_run": {
+									Type: framework.TypeBool,
+								},
+								"keys": {
+									Type:        framework.TypeStringSlice,
+									Description: "The keys that would be deleted.",
+								},
+							},
+						}},
+						http.StatusAccepted: {{
+							Description: "Accepted",
+							Fields: map[string]*framework.FieldSchema{
+								"id": {
+									Type:        framework.TypeString,
+									Description: "The ID of the recursive delete.",
+								},
+								"status_path": {
+									Type:        framework.TypeString,
+									Description: "The path the progress of the recursive delete can be read from.",
+								},
+							},
+						}},
+					},
+				},
+				logical.ListOperation: &framework.PathOperation{
+					Callback: b.handleKVRecursiveDeleteList,
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "list",
+					},
+					Summary: strings.TrimSpace(sysHelp["kv-recursive-delete-list"][0]),
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"keys": {
+									Type:        framework.TypeStringSlice,
+									Description: "The IDs of the recursive deletes.",
+								},
+							},
+						}},
+					},
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["kv-recursive-delete-start"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["kv-recursive-delete-start"][1]),
+		},
+		{
+			Pattern: "kv-recursive-delete/(?P<id>.+)",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "kv-recursive-delete",
+				OperationVerb:   "read",
+				OperationSuffix: "status",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"id": {
+					Type:        framework.TypeString,
+					Description: strings.TrimSpace(sysHelp["kv-recursive-delete-id"][0]),
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handleKVRecursiveDeleteRead,
+					Summary:  strings.TrimSpace(sysHelp["kv-recursive-delete"][0]),
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"id": {
+									Type: framework.TypeString,
+								},
+								"mount_point": {
+									Type: framework.TypeString,
+								},
+								"prefix": {
+									Type: framework.TypeString,
+								},
+								"status": {
+									Type: framework.TypeString,
+								},
+								"start_time": {
+									Type: framework.TypeTime,
+								},
+								"end_time": {
+									Type:     framework.TypeTime,
+									Required: false,
+								},
+								"deleted": {
+									Type: framework.TypeInt,
+								},
+								"failed": {
+									Type: framework.TypeInt,
+								},
+								"errors": {
+									Type: framework.TypeStringSlice,
+								},
+							},
+						}},
+					},
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["kv-recursive-delete"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["kv-recursive-delete"][1]),
+		},
+	}
+}
+
+func (b *SystemBackend) hostInfoPath() *framework.Path {
+	return &framework.Path{
+		Pattern: "host-info/?",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationVerb:   "collect",
+			OperationSuffix: "host-information",
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback:    b.handleHostInfo,
+				Summary:     strings.TrimSpace(sysHelp["host-info"][0]),
 				Description: strings.TrimSpace(sysHelp["host-info"][1]),
 				Responses: map[int][]framework.Response{
 					http.StatusOK: {{
@@ -3291,6 +3980,18 @@ func (b *SystemBackend) hostInfoPath() *framework.Path {
 								Type:     framework.TypeMap,
 								Required: false,
 							},
+							"runtime": {
+								Type:     framework.TypeMap,
+								Required: false,
+							},
+							"process": {
+								Type:     framework.TypeMap,
+								Required: false,
+							},
+							"mlock": {
+								Type:     framework.TypeMap,
+								Required: true,
+							},
 						},
 					}},
 				},
@@ -3367,6 +4068,18 @@ func (b *SystemBackend) authPaths() []*framework.Path {
 					Type:        framework.TypeString,
 					Description: strings.TrimSpace(sysHelp["listing_visibility"][0]),
 				},
+				"rollback_period": {
+					Type:        framework.TypeString,
+					Description: strings.TrimSpace(sysHelp["tune_rollback_period"][0]),
+				},
+				"max_request_size": {
+					Type:        framework.TypeInt64,
+					Description: strings.TrimSpace(sysHelp["tune_max_request_size"][0]),
+				},
+				"max_response_size": {
+					Type:        framework.TypeInt64,
+					Description: strings.TrimSpace(sysHelp["tune_max_response_size"][0]),
+				},
 				"passthrough_request_headers": {
 					Type:        framework.TypeCommaStringSlice,
 					Description: strings.TrimSpace(sysHelp["passthrough_request_headers"][0]),
@@ -3379,6 +4092,18 @@ func (b *SystemBackend) authPaths() []*framework.Path {
 					Type:        framework.TypeString,
 					Description: strings.TrimSpace(sysHelp["token_type"][0]),
 				},
+				"token_bind_source_cidr": {
+					Type:        framework.TypeBool,
+					Description: strings.TrimSpace(sysHelp["tune_token_bind_source_cidr"][0]),
+				},
+				"token_bind_client_cert": {
+					Type:        framework.TypeBool,
+					Description: strings.TrimSpace(sysHelp["tune_token_bind_client_cert"][0]),
+				},
+				"identity_token_role": {
+					Type:        framework.TypeString,
+					Description: strings.TrimSpace(sysHelp["tune_identity_token_role"][0]),
+				},
 				"user_lockout_config": {
 					Type:        framework.TypeMap,
 					Description: strings.TrimSpace(sysHelp["tune_user_lockout_config"][0]),
@@ -3425,6 +4150,18 @@ func (b *SystemBackend) authPaths() []*framework.Path {
 									Type:     framework.TypeString,
 									Required: false,
 								},
+								"token_bind_source_cidr": {
+									Type:     framework.TypeBool,
+									Required: false,
+								},
+								"token_bind_client_cert": {
+									Type:     framework.TypeBool,
+									Required: false,
+								},
+								"identity_token_role": {
+									Type:     framework.TypeString,
+									Required: false,
+								},
 								"audit_non_hmac_request_keys": {
 									Type:     framework.TypeCommaStringSlice,
 									Required: false,
@@ -3437,6 +4174,18 @@ func (b *SystemBackend) authPaths() []*framework.Path {
 									Type:     framework.TypeString,
 									Required: false,
 								},
+								"rollback_period": {
+									Type:     framework.TypeInt64,
+									Required: false,
+								},
+								"max_request_size": {
+									Type:     framework.TypeInt64,
+									Required: false,
+								},
+								"max_response_size": {
+									Type:     framework.TypeInt64,
+									Required: false,
+								},
 								"passthrough_request_headers": {
 									Type:     framework.TypeCommaStringSlice,
 									Required: false,
@@ -3787,31 +4536,153 @@ func (b *SystemBackend) policyPaths() []*framework.Path {
 
 			DisplayAttrs: &framework.DisplayAttributes{
 				OperationPrefix: "policies",
-				OperationSuffix: "acl-policies",
+				OperationSuffix: "acl-policies",
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ListOperation: &framework.PathOperation{
+					Callback: b.handlePoliciesList(PolicyTypeACL),
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"keys": {
+									Type:     framework.TypeStringSlice,
+									Required: true,
+								},
+								"policies": {
+									Type: framework.TypeStringSlice,
+								},
+							},
+						}},
+					},
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["policy-list"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["policy-list"][1]),
+		},
+
+		{
+			Pattern: "policies/acl/(?P<name>.+)/versions/?$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "policies",
+				OperationVerb:   "list",
+				OperationSuffix: "acl-policy-versions",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: strings.TrimSpace(sysHelp["policy-name"][0]),
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ListOperation: &framework.PathOperation{
+					Callback: b.handlePoliciesACLVersionsList,
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"keys": {
+									Type:     framework.TypeStringSlice,
+									Required: true,
+								},
+								"key_info": {
+									Type: framework.TypeMap,
+								},
+							},
+						}},
+					},
+					Summary: "List the retained versions of the named ACL policy.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["policy-versions"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["policy-versions"][1]),
+		},
+
+		{
+			Pattern: "policies/acl/(?P<name>.+)/versions/(?P<version>\\d+)/restore$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "policies",
+				OperationVerb:   "restore",
+				OperationSuffix: "acl-policy-version",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: strings.TrimSpace(sysHelp["policy-name"][0]),
+				},
+				"version": {
+					Type:        framework.TypeInt,
+					Description: strings.TrimSpace(sysHelp["policy-version-number"][0]),
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handlePoliciesACLVersionRestore,
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields:      policyVersionResponseFields(),
+						}},
+					},
+					Summary: "Make a previous version of the named ACL policy the current one.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["policy-version-restore"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["policy-version-restore"][1]),
+		},
+
+		{
+			Pattern: "policies/acl/(?P<name>.+)/versions/(?P<version>\\d+)$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "policies",
+				OperationVerb:   "read",
+				OperationSuffix: "acl-policy-version",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: strings.TrimSpace(sysHelp["policy-name"][0]),
+				},
+				"version": {
+					Type:        framework.TypeInt,
+					Description: strings.TrimSpace(sysHelp["policy-version-number"][0]),
+				},
 			},
 
 			Operations: map[logical.Operation]framework.OperationHandler{
-				logical.ListOperation: &framework.PathOperation{
-					Callback: b.handlePoliciesList(PolicyTypeACL),
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handlePoliciesACLVersionRead,
 					Responses: map[int][]framework.Response{
 						http.StatusOK: {{
 							Description: "OK",
-							Fields: map[string]*framework.FieldSchema{
-								"keys": {
-									Type:     framework.TypeStringSlice,
+							Fields: func() map[string]*framework.FieldSchema {
+								fields := policyVersionResponseFields()
+								fields["policy"] = &framework.FieldSchema{
+									Type:     framework.TypeString,
 									Required: true,
-								},
-								"policies": {
-									Type: framework.TypeStringSlice,
-								},
-							},
+								}
+								return fields
+							}(),
 						}},
 					},
+					Summary: "Retrieve a version of the named ACL policy.",
 				},
 			},
 
-			HelpSynopsis:    strings.TrimSpace(sysHelp["policy-list"][0]),
-			HelpDescription: strings.TrimSpace(sysHelp["policy-list"][1]),
+			HelpSynopsis:    strings.TrimSpace(sysHelp["policy-version"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["policy-version"][1]),
 		},
 
 		{
@@ -3948,6 +4819,57 @@ func (b *SystemBackend) policyPaths() []*framework.Path {
 			HelpDescription: "Generate a password from an existing password policy.",
 		},
 
+		{
+			Pattern: "policies/password/(?P<name>.+)/validate$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "policies",
+				OperationVerb:   "validate",
+				OperationSuffix: "password-with-password-policy",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: "The name of the password policy.",
+				},
+				"password": {
+					Type:        framework.TypeString,
+					Description: "The password to validate.",
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handlePoliciesPasswordValidate,
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"valid": {
+									Type:     framework.TypeBool,
+									Required: true,
+								},
+								"failed_rules": {
+									Type:     framework.TypeStringSlice,
+									Required: true,
+								},
+								"entropy_bits": {
+									Type:     framework.TypeFloat,
+									Required: true,
+								},
+							},
+						}},
+					},
+					Summary: "Validate a password against the rules of an existing password policy.",
+				},
+			},
+
+			HelpSynopsis: "Validate a password against the rules of an existing password policy.",
+			HelpDescription: "Validate a password against the rules of an existing password policy, " +
+				"such as a password chosen by a user, reporting the types of the rules it fails and its estimated entropy.",
+		},
+
 		{
 			Pattern: "policies/password/(?P<name>.+)$",
 
@@ -4009,6 +4931,96 @@ func (b *SystemBackend) policyPaths() []*framework.Path {
 			HelpDescription: "Read the rules of an existing password policy, create or update " +
 				"the rules of a password policy, or delete a password policy.",
 		},
+
+		{
+			Pattern: "policies/password-wordlist/?$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "policies",
+				OperationSuffix: "password-policy-wordlists",
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ListOperation: &framework.PathOperation{
+					Callback: b.handlePoliciesPasswordWordlistList,
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"keys": {
+									Type:     framework.TypeStringSlice,
+									Required: false,
+								},
+							},
+						}},
+					},
+					Summary: "List the existing password policy wordlists.",
+				},
+			},
+		},
+
+		{
+			Pattern: "policies/password-wordlist/(?P<name>.+)$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "policies",
+				OperationSuffix: "password-policy-wordlist",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: "The name of the wordlist.",
+				},
+				"words": {
+					Type:        framework.TypeCommaStringSlice,
+					Description: "The words passwords of the policies using the wordlist must not contain.",
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handlePoliciesPasswordWordlistSet,
+					Responses: map[int][]framework.Response{
+						http.StatusNoContent: {{
+							Description: "OK",
+							Fields:      map[string]*framework.FieldSchema{},
+						}},
+					},
+					Summary: "Add a new or update an existing password policy wordlist.",
+				},
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handlePoliciesPasswordWordlistGet,
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"words": {
+									Type:     framework.TypeStringSlice,
+									Required: true,
+								},
+							},
+						}},
+					},
+					Summary: "Retrieve an existing password policy wordlist.",
+				},
+				logical.DeleteOperation: &framework.PathOperation{
+					Callback: b.handlePoliciesPasswordWordlistDelete,
+					Responses: map[int][]framework.Response{
+						http.StatusNoContent: {{
+							Description: "OK",
+							Fields:      map[string]*framework.FieldSchema{},
+						}},
+					},
+					Summary: "Delete a password policy wordlist.",
+				},
+			},
+
+			HelpSynopsis: "Read, Modify, or Delete a password policy wordlist.",
+			HelpDescription: "Read the words of an existing wordlist, create or update the words of a wordlist, " +
+				"or delete a wordlist. Wordlists hold the words rejected by the dictionary rules of password policies " +
+				"referring to them.",
+		},
 	}
 }
 
@@ -4221,6 +5233,22 @@ func (b *SystemBackend) mountPaths() []*framework.Path {
 					Type:        framework.TypeString,
 					Description: strings.TrimSpace(sysHelp["listing_visibility"][0]),
 				},
+				"rollback_period": {
+					Type:        framework.TypeString,
+					Description: strings.TrimSpace(sysHelp["tune_rollback_period"][0]),
+				},
+				"max_request_size": {
+					Type:        framework.TypeInt64,
+					Description: strings.TrimSpace(sysHelp["tune_max_request_size"][0]),
+				},
+				"max_response_size": {
+					Type:        framework.TypeInt64,
+					Description: strings.TrimSpace(sysHelp["tune_max_response_size"][0]),
+				},
+				"lease_ttl_jitter": {
+					Type:        framework.TypeInt,
+					Description: strings.TrimSpace(sysHelp["tune_lease_ttl_jitter"][0]),
+				},
 				"passthrough_request_headers": {
 					Type:        framework.TypeCommaStringSlice,
 					Description: strings.TrimSpace(sysHelp["passthrough_request_headers"][0]),
@@ -4233,6 +5261,18 @@ func (b *SystemBackend) mountPaths() []*framework.Path {
 					Type:        framework.TypeString,
 					Description: strings.TrimSpace(sysHelp["token_type"][0]),
 				},
+				"token_bind_source_cidr": {
+					Type:        framework.TypeBool,
+					Description: strings.TrimSpace(sysHelp["tune_token_bind_source_cidr"][0]),
+				},
+				"token_bind_client_cert": {
+					Type:        framework.TypeBool,
+					Description: strings.TrimSpace(sysHelp["tune_token_bind_client_cert"][0]),
+				},
+				"identity_token_role": {
+					Type:        framework.TypeString,
+					Description: strings.TrimSpace(sysHelp["tune_identity_token_role"][0]),
+				},
 				"allowed_managed_keys": {
 					Type:        framework.TypeCommaStringSlice,
 					Description: strings.TrimSpace(sysHelp["tune_allowed_managed_keys"][0]),
@@ -4282,6 +5322,21 @@ func (b *SystemBackend) mountPaths() []*framework.Path {
 									Description: strings.TrimSpace(sysHelp["token_type"][0]),
 									Required:    false,
 								},
+								"token_bind_source_cidr": {
+									Type:        framework.TypeBool,
+									Description: strings.TrimSpace(sysHelp["tune_token_bind_source_cidr"][0]),
+									Required:    false,
+								},
+								"token_bind_client_cert": {
+									Type:        framework.TypeBool,
+									Description: strings.TrimSpace(sysHelp["tune_token_bind_client_cert"][0]),
+									Required:    false,
+								},
+								"identity_token_role": {
+									Type:        framework.TypeString,
+									Description: strings.TrimSpace(sysHelp["tune_identity_token_role"][0]),
+									Required:    false,
+								},
 								"allowed_managed_keys": {
 									Type:        framework.TypeCommaStringSlice,
 									Description: strings.TrimSpace(sysHelp["tune_allowed_managed_keys"][0]),
@@ -4318,6 +5373,22 @@ func (b *SystemBackend) mountPaths() []*framework.Path {
 									Type:     framework.TypeString,
 									Required: false,
 								},
+								"rollback_period": {
+									Type:     framework.TypeInt64,
+									Required: false,
+								},
+								"max_request_size": {
+									Type:     framework.TypeInt64,
+									Required: false,
+								},
+								"max_response_size": {
+									Type:     framework.TypeInt64,
+									Required: false,
+								},
+								"lease_ttl_jitter": {
+									Type:     framework.TypeInt,
+									Required: false,
+								},
 								"passthrough_request_headers": {
 									Type:     framework.TypeCommaStringSlice,
 									Required: false,
@@ -4360,6 +5431,98 @@ func (b *SystemBackend) mountPaths() []*framework.Path {
 			HelpDescription: strings.TrimSpace(sysHelp["mount_tune"][1]),
 		},
 
+		{
+			Pattern: "mounts/(?P<path>.+?)/data-key$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "mounts",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"path": {
+					Type:        framework.TypeString,
+					Description: strings.TrimSpace(sysHelp["mount_path"][0]),
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handleMountDataKeyRead,
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb:   "read",
+						OperationSuffix: "data-key",
+					},
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"term": {
+									Type:        framework.TypeInt,
+									Description: "The term of the active data key.",
+									Required:    true,
+								},
+								"install_time": {
+									Type:        framework.TypeTime,
+									Description: "The time at which the active data key was installed.",
+									Required:    true,
+								},
+								"rotating": {
+									Type:        framework.TypeBool,
+									Description: "Whether entries are still being re-encrypted with the active data key.",
+									Required:    true,
+								},
+							},
+						}},
+					},
+					Summary: "Read the status of the data key of the mount.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["mount_data_key"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["mount_data_key"][1]),
+		},
+
+		{
+			Pattern: "mounts/(?P<path>.+?)/data-key/rotate$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "mounts",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"path": {
+					Type:        framework.TypeString,
+					Description: strings.TrimSpace(sysHelp["mount_path"][0]),
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleMountDataKeyRotate,
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb:   "rotate",
+						OperationSuffix: "data-key",
+					},
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"term": {
+									Type:        framework.TypeInt,
+									Description: "The term of the new data key.",
+									Required:    true,
+								},
+							},
+						}},
+					},
+					Summary: "Rotate the data key of the mount.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["mount_data_key_rotate"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["mount_data_key_rotate"][1]),
+		},
+
 		{
 			Pattern: "mounts/(?P<path>.+?)",
 
@@ -4394,6 +5557,11 @@ func (b *SystemBackend) mountPaths() []*framework.Path {
 					Default:     false,
 					Description: strings.TrimSpace(sysHelp["seal_wrap"][0]),
 				},
+				"data_key": {
+					Type:        framework.TypeBool,
+					Default:     false,
+					Description: strings.TrimSpace(sysHelp["data_key"][0]),
+				},
 				"external_entropy_access": {
 					Type:        framework.TypeBool,
 					Default:     false,
@@ -4450,6 +5618,10 @@ func (b *SystemBackend) mountPaths() []*framework.Path {
 									Description: strings.TrimSpace(sysHelp["seal_wrap"][0]),
 									Required:    true,
 								},
+								"data_key": {
+									Type:        framework.TypeBool,
+									Description: strings.TrimSpace(sysHelp["data_key"][0]),
+								},
 								"external_entropy_access": {
 									Type:     framework.TypeBool,
 									Required: true,
@@ -4625,3 +5797,30 @@ func (b *SystemBackend) lockedUserPaths() []*framework.Path {
 		},
 	}
 }
+
+// policyVersionResponseFields returns the response fields describing a
+// version of an ACL policy.
+func policyVersionResponseFields() map[string]*framework.FieldSchema {
+	return map[string]*framework.FieldSchema{
+		"name": {
+			Type:     framework.TypeString,
+			Required: true,
+		},
+		"version": {
+			Type:     framework.TypeInt,
+			Required: true,
+		},
+		"created_time": {
+			Type: framework.TypeString,
+		},
+		"token_accessor": {
+			Type: framework.TypeString,
+		},
+		"entity_id": {
+			Type: framework.TypeString,
+		},
+		"display_name": {
+			Type: framework.TypeString,
+		},
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/http/pprof"
 	"strconv"
+	"time"
 
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
@@ -266,6 +267,144 @@ render pages.`,
 				},
 			},
 		},
+		{
+			Pattern: "pprof/capture/config$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "pprof",
+				OperationSuffix: "capture-configuration",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"enabled": {
+					Type:        framework.TypeBool,
+					Description: "If true, profiles are periodically captured to storage.",
+				},
+				"interval": {
+					Type:        framework.TypeDurationSecond,
+					Description: "How often profiles are captured.",
+					Default:     int(defaultPprofCaptureInterval.Seconds()),
+				},
+				"cpu_duration": {
+					Type:        framework.TypeDurationSecond,
+					Description: "How long each CPU profile samples for. Must be less than interval.",
+					Default:     int(defaultPprofCaptureCPUDuration.Seconds()),
+				},
+				"profiles": {
+					Type:        framework.TypeCommaStringSlice,
+					Description: "The profiles to capture. Valid values are cpu, heap, allocs, goroutine, block, mutex, and threadcreate.",
+					Default:     []string{"cpu", "heap", "goroutine"},
+				},
+				"retention": {
+					Type:        framework.TypeInt,
+					Description: "The number of capture rounds to keep in storage; older captures are deleted.",
+					Default:     defaultPprofCaptureRetention,
+				},
+				"push_url": {
+					Type:        framework.TypeString,
+					Description: "If set, each captured profile is also sent via HTTP POST to this pprof-compatible endpoint.",
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handlePprofCaptureConfigRead,
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "read",
+					},
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"enabled":      {Type: framework.TypeBool, Required: true},
+								"interval":     {Type: framework.TypeDurationSecond, Required: true},
+								"cpu_duration": {Type: framework.TypeDurationSecond, Required: true},
+								"profiles":     {Type: framework.TypeCommaStringSlice, Required: true},
+								"retention":    {Type: framework.TypeInt, Required: true},
+								"push_url":     {Type: framework.TypeString, Required: false},
+							},
+						}},
+					},
+					Summary: "Returns the continuous profiling configuration.",
+				},
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handlePprofCaptureConfigUpdate,
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "configure",
+					},
+					Responses: map[int][]framework.Response{
+						http.StatusNoContent: {{
+							Description: "OK",
+						}},
+					},
+					Summary: "Configures continuous profiling.",
+					Description: `Configures the periodic capture of runtime profiles on the active node.
+Captured profiles are kept in storage, bounded by the retention setting.`,
+				},
+			},
+		},
+		{
+			Pattern: "pprof/capture/profiles/?$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "pprof",
+				OperationSuffix: "captured-profiles",
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ListOperation: &framework.PathOperation{
+					Callback: b.handlePprofCaptureList,
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "list",
+					},
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"keys": {
+									Type:     framework.TypeStringSlice,
+									Required: true,
+								},
+								"key_info": {
+									Type:     framework.TypeMap,
+									Required: true,
+								},
+							},
+						}},
+					},
+					Summary: "Lists the captured profiles.",
+				},
+			},
+		},
+		{
+			Pattern: "pprof/capture/profiles/(?P<id>.+)",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "pprof",
+				OperationVerb:   "download",
+				OperationSuffix: "captured-profile",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"id": {
+					Type:        framework.TypeString,
+					Description: "The ID of the captured profile.",
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handlePprofCaptureRead,
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+						}},
+					},
+					Summary:     "Returns a captured profile.",
+					Description: "Returns a captured profile in the pprof binary format.",
+				},
+			},
+		},
 	}
 }
 
@@ -392,6 +531,109 @@ func (b *SystemBackend) handlePprofTrace(ctx context.Context, req *logical.Reque
 	return nil, nil
 }
 
+func (b *SystemBackend) handlePprofCaptureConfigRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	if b.Core.pprofCapture == nil {
+		return nil, errors.New("continuous profiling is not available on this node")
+	}
+
+	config := b.Core.pprofCapture.Config()
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"enabled":      config.Enabled,
+			"interval":     int64(config.Interval.Seconds()),
+			"cpu_duration": int64(config.CPUDuration.Seconds()),
+			"profiles":     config.Profiles,
+			"retention":    config.Retention,
+		},
+	}
+	if config.PushURL != "" {
+		resp.Data["push_url"] = config.PushURL
+	}
+	return resp, nil
+}
+
+func (b *SystemBackend) handlePprofCaptureConfigUpdate(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	if b.Core.pprofCapture == nil {
+		return nil, errors.New("continuous profiling is not available on this node")
+	}
+
+	config := b.Core.pprofCapture.Config()
+	if enabledRaw, ok := d.GetOk("enabled"); ok {
+		config.Enabled = enabledRaw.(bool)
+	}
+	if intervalRaw, ok := d.GetOk("interval"); ok {
+		config.Interval = time.Duration(intervalRaw.(int)) * time.Second
+	}
+	if cpuDurationRaw, ok := d.GetOk("cpu_duration"); ok {
+		config.CPUDuration = time.Duration(cpuDurationRaw.(int)) * time.Second
+	}
+	if profilesRaw, ok := d.GetOk("profiles"); ok {
+		config.Profiles = profilesRaw.([]string)
+	}
+	if retentionRaw, ok := d.GetOk("retention"); ok {
+		config.Retention = retentionRaw.(int)
+	}
+	if pushURLRaw, ok := d.GetOk("push_url"); ok {
+		config.PushURL = pushURLRaw.(string)
+	}
+
+	if err := b.Core.pprofCapture.SetConfig(ctx, config); err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+	return nil, nil
+}
+
+func (b *SystemBackend) handlePprofCaptureList(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	if b.Core.pprofCapture == nil {
+		return nil, errors.New("continuous profiling is not available on this node")
+	}
+
+	ids, err := b.Core.pprofCapture.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keyInfo := make(map[string]interface{}, len(ids))
+	for _, id := range ids {
+		info, err := b.Core.pprofCapture.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if info == nil {
+			continue
+		}
+		keyInfo[id] = map[string]interface{}{
+			"type":        info.Type,
+			"captured_at": info.CapturedAt.Format(time.RFC3339),
+			"size":        info.Size,
+		}
+	}
+
+	return logical.ListResponseWithInfo(ids, keyInfo), nil
+}
+
+func (b *SystemBackend) handlePprofCaptureRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	if b.Core.pprofCapture == nil {
+		return nil, errors.New("continuous profiling is not available on this node")
+	}
+
+	info, err := b.Core.pprofCapture.Get(ctx, d.Get("id").(string))
+	if err != nil {
+		return nil, err
+	}
+	if info == nil {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			logical.HTTPContentType: "application/octet-stream",
+			logical.HTTPRawBody:     info.Profile,
+			logical.HTTPStatusCode:  http.StatusOK,
+		},
+	}, nil
+}
+
 // checkRequestHandlerParams is a helper that checks for the existence of the
 // HTTP request and response writer in a logical.Request.
 func checkRequestHandlerParams(req *logical.Request) error {
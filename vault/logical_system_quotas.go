@@ -149,6 +149,11 @@ The 'rate' must be positive.`,
 					Description: `If set, when a client reaches a rate limit threshold, the client will be prohibited
 from any further requests until after the 'block_interval' has elapsed.`,
 				},
+				"inheritable": {
+					Type: framework.TypeBool,
+					Description: `If set on a quota on a namespace, the quota also applies to the descendant
+namespaces which have no quota of their own. Global quotas are always inheritable.`,
+				},
 			},
 			Operations: map[logical.Operation]framework.OperationHandler{
 				logical.UpdateOperation: &framework.PathOperation{
@@ -199,6 +204,10 @@ from any further requests until after the 'block_interval' has elapsed.`,
 									Type:     framework.TypeInt,
 									Required: true,
 								},
+								"inheritable": {
+									Type:     framework.TypeBool,
+									Required: true,
+								},
 							},
 						}},
 					},
@@ -218,6 +227,36 @@ from any further requests until after the 'block_interval' has elapsed.`,
 			HelpSynopsis:    strings.TrimSpace(quotasHelp["rate-limit"][0]),
 			HelpDescription: strings.TrimSpace(quotasHelp["rate-limit"][1]),
 		},
+		{
+			Pattern: "quotas/usage$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "quotas",
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handleQuotasUsageRead(),
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb:   "read",
+						OperationSuffix: "usage",
+					},
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"by_namespace": {
+									Type:     framework.TypeSlice,
+									Required: true,
+								},
+							},
+						}},
+					},
+				},
+			},
+			HelpSynopsis:    strings.TrimSpace(quotasHelp["usage"][0]),
+			HelpDescription: strings.TrimSpace(quotasHelp["usage"][1]),
+		},
 	}
 }
 
@@ -318,6 +357,19 @@ func (b *SystemBackend) handleRateLimitQuotasUpdate() framework.OperationFunc {
 			mountPath = mountAPIPath
 		}
 
+		// Global quotas are always inherited, and quotas on mounts have no
+		// descendants to be inherited by.
+		inheritable := d.Get("inheritable").(bool)
+		if mountPath == "" && ns.ID == namespace.RootNamespaceID {
+			if rawInheritable, ok := d.GetOk("inheritable"); ok && !rawInheritable.(bool) {
+				return logical.ErrorResponse("global quotas are always inheritable"), nil
+			}
+			inheritable = true
+		}
+		if inheritable && mountPath != "" {
+			return logical.ErrorResponse("only quotas on a namespace can be inheritable"), nil
+		}
+
 		role := d.Get("role").(string)
 		// If this is a quota with a role, ensure the backend supports role resolution
 		if role != "" {
@@ -356,7 +408,9 @@ func (b *SystemBackend) handleRateLimitQuotasUpdate() framework.OperationFunc {
 
 		switch {
 		case quota == nil:
-			quota = quotas.NewRateLimitQuota(name, ns.Path, mountPath, pathSuffix, role, rate, interval, blockInterval)
+			rlq := quotas.NewRateLimitQuota(name, ns.Path, mountPath, pathSuffix, role, rate, interval, blockInterval)
+			rlq.Inheritable = inheritable
+			quota = rlq
 		default:
 			// Re-inserting the already indexed object in memdb might cause problems.
 			// So, clone the object. See https://github.com/hashicorp/go-memdb/issues/76.
@@ -368,6 +422,7 @@ func (b *SystemBackend) handleRateLimitQuotasUpdate() framework.OperationFunc {
 			rlq.Rate = rate
 			rlq.Interval = interval
 			rlq.BlockInterval = blockInterval
+			rlq.Inheritable = inheritable
 			quota = rlq
 		}
 
@@ -416,6 +471,7 @@ func (b *SystemBackend) handleRateLimitQuotasRead() framework.OperationFunc {
 			"rate":           rlq.Rate,
 			"interval":       int(rlq.Interval.Seconds()),
 			"block_interval": int(rlq.BlockInterval.Seconds()),
+			"inheritable":    rlq.Inheritable || (rlq.NamespacePath == "root" && rlq.MountPath == ""),
 		}
 
 		return &logical.Response{
@@ -441,6 +497,44 @@ func (b *SystemBackend) handleRateLimitQuotasDelete() framework.OperationFunc {
 	}
 }
 
+func (b *SystemBackend) handleQuotasUsageRead() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		usages, err := b.Core.quotaManager.Usage()
+		if err != nil {
+			return nil, err
+		}
+
+		// Usages are sorted by namespace, group them accordingly
+		byNamespace := make([]map[string]interface{}, 0)
+		var nsQuotas []map[string]interface{}
+		for i, usage := range usages {
+			if i == 0 || usage.NamespacePath != usages[i-1].NamespacePath {
+				nsPath := usage.NamespacePath
+				if nsPath == "root" {
+					nsPath = ""
+				}
+				nsQuotas = make([]map[string]interface{}, 0)
+				byNamespace = append(byNamespace, map[string]interface{}{
+					"namespace_path": nsPath,
+				})
+			}
+			nsQuotas = append(nsQuotas, map[string]interface{}{
+				"name":     usage.Name,
+				"type":     usage.Type.String(),
+				"allowed":  usage.Allowed,
+				"rejected": usage.Rejected,
+			})
+			byNamespace[len(byNamespace)-1]["quotas"] = nsQuotas
+		}
+
+		return &logical.Response{
+			Data: map[string]interface{}{
+				"by_namespace": byNamespace,
+			},
+		}, nil
+	}
+}
+
 var quotasHelp = map[string][2]string{
 	"quotas-config": {
 		"Create, update and read the quota configuration.",
@@ -453,6 +547,12 @@ mount.`,
 rate limit quota can be created at the root level or defined on a namespace or
 mount by specifying a 'path'. The rate limiter is applied to each unique client
 IP address.`,
+	},
+	"usage": {
+		"Read the consumption of the quotas per namespace.",
+		`Returns, for each namespace, the number of requests allowed and rejected by each
+quota applied to its requests since the quota was loaded on the node serving the
+request. Counts are kept per node and reset when the quota is updated.`,
 	},
 	"rate-limit-list": {
 		"Lists the names of all the rate limit quotas.",
@@ -165,6 +165,18 @@ func (b *SystemBackend) raftStoragePaths() []*framework.Path {
 			HelpSynopsis:    strings.TrimSpace(sysRaftHelp["raft-autopilot-state"][0]),
 			HelpDescription: strings.TrimSpace(sysRaftHelp["raft-autopilot-state"][1]),
 		},
+		{
+			Pattern: "storage/raft/usage",
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handleStorageRaftUsageRead(),
+					Summary:  "Returns the number of keys and bytes stored under each top-level storage prefix.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysRaftHelp["raft-usage"][0]),
+			HelpDescription: strings.TrimSpace(sysRaftHelp["raft-usage"][1]),
+		},
 		{
 			Pattern: "storage/raft/autopilot/configuration",
 			Fields: map[string]*framework.FieldSchema{
@@ -440,6 +452,34 @@ func (b *SystemBackend) handleStorageRaftAutopilotState() framework.OperationFun
 	}
 }
 
+func (b *SystemBackend) handleStorageRaftUsageRead() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		raftBackend := b.Core.getRaftBackend()
+		if raftBackend == nil {
+			return logical.ErrorResponse("raft storage is not in use"), logical.ErrInvalidRequest
+		}
+
+		var totalKeys, totalBytes int64
+		prefixes := make(map[string]interface{})
+		for prefix, usage := range raftBackend.StorageUsage() {
+			prefixes[prefix] = map[string]interface{}{
+				"keys":  usage.Keys,
+				"bytes": usage.Bytes,
+			}
+			totalKeys += usage.Keys
+			totalBytes += usage.Bytes
+		}
+
+		return &logical.Response{
+			Data: map[string]interface{}{
+				"prefixes":    prefixes,
+				"total_keys":  totalKeys,
+				"total_bytes": totalBytes,
+			},
+		}, nil
+	}
+}
+
 func (b *SystemBackend) handleStorageRaftAutopilotConfigRead() framework.OperationFunc {
 	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
 		raftBackend := b.Core.getRaftBackend()
@@ -700,4 +740,14 @@ var sysRaftHelp = map[string][2]string{
 		"Returns autopilot configuration.",
 		"",
 	},
+	"raft-usage": {
+		"Returns the storage usage of the raft cluster, by prefix.",
+		`
+Returns the number of keys and the total size of their values stored under
+each top-level storage prefix. Data belonging to secrets engines and auth
+methods is reported separately for each mount, keyed by the mount's UUID,
+as "logical/<uuid>" and "auth/<uuid>". The totals are maintained by the
+storage layer as writes are applied and reflect the data on this node.
+		`,
+	},
 }
@@ -0,0 +1,143 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// rollbackPaths returns the paths reporting and triggering the rollbacks of
+// the mounts.
+func (b *SystemBackend) rollbackPaths() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "rollback/status$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "rollback",
+				OperationVerb:   "read",
+				OperationSuffix: "status",
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handleRollbackStatus,
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"mounts": {
+									Type:     framework.TypeMap,
+									Required: true,
+								},
+							},
+						}},
+					},
+					Summary: "Report the outcome of the last rollback of each mount.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["rollback-status"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["rollback-status"][1]),
+		},
+		{
+			Pattern: "rollback/trigger/(?P<mount>.+)$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "rollback",
+				OperationVerb:   "trigger",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"mount": {
+					Type:        framework.TypeString,
+					Description: "The path of the mount to roll back.",
+					Required:    true,
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleRollbackTrigger,
+					Responses: map[int][]framework.Response{
+						http.StatusNoContent: {{
+							Description: "OK",
+						}},
+					},
+					Summary: "Roll back a mount immediately.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["rollback-trigger"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["rollback-trigger"][1]),
+		},
+	}
+}
+
+func (b *SystemBackend) handleRollbackStatus(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	mounts := make(map[string]interface{})
+	if b.Core.rollback != nil {
+		for fullPath, status := range b.Core.rollback.Status() {
+			// Mounts of the namespace and of its children are reported.
+			if !strings.HasPrefix(fullPath, ns.Path) {
+				continue
+			}
+
+			mount := map[string]interface{}{
+				"last_rollback":        status.LastRollback.Format(time.RFC3339Nano),
+				"duration_ms":          status.Duration.Milliseconds(),
+				"consecutive_failures": status.ConsecutiveFailures,
+				"last_error":           status.LastError,
+				"last_error_time":      "",
+				"backoff_ms":           status.Backoff.Milliseconds(),
+			}
+			if !status.LastErrorTime.IsZero() {
+				mount["last_error_time"] = status.LastErrorTime.Format(time.RFC3339Nano)
+			}
+			mounts[ns.TrimmedPath(fullPath)] = mount
+		}
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"mounts": mounts,
+		},
+	}, nil
+}
+
+func (b *SystemBackend) handleRollbackTrigger(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	path := sanitizePath(d.Get("mount").(string))
+	if b.Core.router.MatchingMount(ctx, path) != ns.Path+path {
+		return logical.ErrorResponse("no mount at %q", path), logical.ErrInvalidRequest
+	}
+
+	rollback := b.Core.rollback
+	if rollback == nil {
+		return nil, fmt.Errorf("rollback manager is not running")
+	}
+
+	// The state lock is held for the request, as the rollback manager expects.
+	if err := rollback.Rollback(ctx, path); err != nil {
+		return nil, fmt.Errorf("failed to roll back %q: %w", path, err)
+	}
+	return nil, nil
+}
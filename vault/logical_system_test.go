@@ -4,7 +4,9 @@
 package vault
 
 import (
+	"bytes"
 	"context"
+	"encoding/base32"
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
@@ -21,6 +23,7 @@ import (
 	"github.com/go-test/deep"
 	"github.com/hashicorp/go-hclog"
 	semver "github.com/hashicorp/go-version"
+	"github.com/hashicorp/vault/audit"
 	credUserpass "github.com/hashicorp/vault/builtin/credential/userpass"
 	"github.com/hashicorp/vault/helper/builtinplugins"
 	"github.com/hashicorp/vault/helper/experiments"
@@ -2128,6 +2131,83 @@ func TestSystemBackend_disableAuth(t *testing.T) {
 	)
 }
 
+func TestSystemBackend_tuneLeaseTTLJitter(t *testing.T) {
+	_, b, _ := testCoreSystemBackend(t)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "mounts/secret/tune")
+	req.Data["lease_ttl_jitter"] = 20
+	resp, err := b.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v resp: %#v", err, resp)
+	}
+
+	req = logical.TestRequest(t, logical.ReadOperation, "mounts/secret/tune")
+	resp, err = b.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	schema.ValidateResponse(
+		t,
+		schema.GetResponseSchema(t, b.(*SystemBackend).Route(req.Path), req.Operation),
+		resp,
+		true,
+	)
+	if resp.Data["lease_ttl_jitter"] != 20 {
+		t.Fatalf("bad lease_ttl_jitter: %#v", resp.Data["lease_ttl_jitter"])
+	}
+
+	for path, jitter := range map[string]int{
+		"mounts/secret/tune": 51,
+		"auth/token/tune":    20,
+	} {
+		req = logical.TestRequest(t, logical.UpdateOperation, path)
+		req.Data["lease_ttl_jitter"] = jitter
+		resp, err = b.HandleRequest(namespace.RootContext(nil), req)
+		if err == nil || resp == nil || !resp.IsError() {
+			t.Fatalf("expected tuning %s to fail, got resp: %#v, err: %v", path, resp, err)
+		}
+	}
+}
+
+func TestSystemBackend_tuneMaxSizes(t *testing.T) {
+	_, b, _ := testCoreSystemBackend(t)
+
+	for _, path := range []string{"mounts/secret/tune", "auth/token/tune"} {
+		req := logical.TestRequest(t, logical.UpdateOperation, path)
+		req.Data["max_request_size"] = 1024
+		req.Data["max_response_size"] = 4096
+		resp, err := b.HandleRequest(namespace.RootContext(nil), req)
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("err: %v resp: %#v", err, resp)
+		}
+
+		req = logical.TestRequest(t, logical.ReadOperation, path)
+		resp, err = b.HandleRequest(namespace.RootContext(nil), req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		schema.ValidateResponse(
+			t,
+			schema.GetResponseSchema(t, b.(*SystemBackend).Route(req.Path), req.Operation),
+			resp,
+			true,
+		)
+		if resp.Data["max_request_size"] != int64(1024) {
+			t.Fatalf("bad max_request_size: %#v", resp.Data["max_request_size"])
+		}
+		if resp.Data["max_response_size"] != int64(4096) {
+			t.Fatalf("bad max_response_size: %#v", resp.Data["max_response_size"])
+		}
+
+		req = logical.TestRequest(t, logical.UpdateOperation, path)
+		req.Data["max_response_size"] = -1
+		resp, err = b.HandleRequest(namespace.RootContext(nil), req)
+		if err == nil || resp == nil || !resp.IsError() {
+			t.Fatalf("expected negative size to be rejected, got resp: %#v, err: %v", resp, err)
+		}
+	}
+}
+
 func TestSystemBackend_tuneAuth(t *testing.T) {
 	c, b, _ := testCoreSystemBackend(t)
 	c.credentialBackends["noop"] = func(context.Context, *logical.BackendConfig) (logical.Backend, error) {
@@ -2369,6 +2449,86 @@ func TestSystemBackend_policyCRUD(t *testing.T) {
 	}
 }
 
+func TestSystemBackend_policyACLVersions(t *testing.T) {
+	b := testSystemBackend(t)
+	ctx := namespace.RootContext(nil)
+
+	v1 := `path "foo/" { capabilities = ["read"] }`
+	v2 := `path "foo/" { capabilities = ["read", "list"] }`
+	for _, rules := range []string{v1, v2, v2} {
+		req := logical.TestRequest(t, logical.UpdateOperation, "policies/acl/foo")
+		req.Data["policy"] = rules
+		req.ClientTokenAccessor = "accessor"
+		req.EntityID = "entity"
+		resp, err := b.HandleRequest(ctx, req)
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("err: %v resp: %#v", err, resp)
+		}
+	}
+
+	// Writing an unchanged policy does not create a version
+	req := logical.TestRequest(t, logical.ListOperation, "policies/acl/foo/versions")
+	resp, err := b.HandleRequest(ctx, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	schema.ValidateResponse(
+		t,
+		schema.GetResponseSchema(t, b.(*SystemBackend).Route(req.Path), req.Operation),
+		resp,
+		true,
+	)
+	if keys := resp.Data["keys"].([]string); !reflect.DeepEqual(keys, []string{"1", "2"}) {
+		t.Fatalf("bad: %#v", keys)
+	}
+
+	req = logical.TestRequest(t, logical.ReadOperation, "policies/acl/foo/versions/1")
+	resp, err = b.HandleRequest(ctx, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	schema.ValidateResponse(
+		t,
+		schema.GetResponseSchema(t, b.(*SystemBackend).Route(req.Path), req.Operation),
+		resp,
+		true,
+	)
+	if resp.Data["policy"] != v1 || resp.Data["token_accessor"] != "accessor" || resp.Data["entity_id"] != "entity" {
+		t.Fatalf("bad: %#v", resp.Data)
+	}
+
+	// Versions outlive the policy, so a deleted policy can be restored
+	req = logical.TestRequest(t, logical.DeleteOperation, "policies/acl/foo")
+	if _, err := b.HandleRequest(ctx, req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "policies/acl/foo/versions/1/restore")
+	req.ClientTokenAccessor = "restorer"
+	resp, err = b.HandleRequest(ctx, req)
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("err: %v resp: %#v", err, resp)
+	}
+	if resp.Data["version"] != 3 || resp.Data["token_accessor"] != "restorer" {
+		t.Fatalf("bad: %#v", resp.Data)
+	}
+
+	req = logical.TestRequest(t, logical.ReadOperation, "policies/acl/foo")
+	resp, err = b.HandleRequest(ctx, req)
+	if err != nil || resp == nil {
+		t.Fatalf("err: %v resp: %#v", err, resp)
+	}
+	if resp.Data["policy"] != v1 {
+		t.Fatalf("expected restored policy, got: %#v", resp.Data)
+	}
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "policies/acl/foo/versions/10/restore")
+	resp, err = b.HandleRequest(ctx, req)
+	if err != logical.ErrInvalidRequest {
+		t.Fatalf("expected missing version to be rejected, got err: %v resp: %#v", err, resp)
+	}
+}
+
 func TestSystemBackend_enableAudit(t *testing.T) {
 	c, b, _ := testCoreSystemBackend(t)
 	c.auditBackends["noop"] = corehelpers.NoopAuditFactory(nil)
@@ -2439,6 +2599,84 @@ func TestSystemBackend_decodeToken(t *testing.T) {
 	}
 }
 
+// TestSystemBackend_auditRotateHMAC ensures that the HMAC key of an audit
+// device can be rotated, and that devices can hash with SHA-512.
+func TestSystemBackend_auditRotateHMAC(t *testing.T) {
+	c, b, _ := testCoreSystemBackend(t)
+	c.auditBackends["noop"] = func(ctx context.Context, config *audit.BackendConfig, useEventLogger bool) (audit.Backend, error) {
+		n, err := corehelpers.NewNoopAudit(config.Config)
+		if err != nil {
+			return nil, err
+		}
+		// Use the salt of the device rather than a fixed one.
+		n.Config.SaltView = config.SaltView
+		n.Config.SaltConfig = config.SaltConfig
+		return n, nil
+	}
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "audit/foo")
+	req.Data["type"] = "noop"
+	req.Data["options"] = map[string]interface{}{
+		"hmac_algorithm": "md5",
+		"skip_test":      "true",
+	}
+	resp, err := b.HandleRequest(namespace.RootContext(nil), req)
+	if err != logical.ErrInvalidRequest || !strings.Contains(resp.Data["error"].(string), `unsupported hmac_algorithm "md5"`) {
+		t.Fatalf("expected an unsupported algorithm to be rejected, got resp: %#v, err: %v", resp, err)
+	}
+
+	req.Data["options"] = map[string]interface{}{
+		"hmac_algorithm": "sha512",
+		"skip_test":      "true",
+	}
+	resp, err = b.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil || resp != nil {
+		t.Fatalf("bad: resp: %#v, err: %v", resp, err)
+	}
+
+	hash := func() string {
+		t.Helper()
+		req := logical.TestRequest(t, logical.UpdateOperation, "audit-hash/foo")
+		req.Data["input"] = "bar"
+		resp, err := b.HandleRequest(namespace.RootContext(nil), req)
+		if err != nil || resp == nil || resp.IsError() {
+			t.Fatalf("bad: resp: %#v, err: %v", resp, err)
+		}
+		return resp.Data["hash"].(string)
+	}
+
+	before := hash()
+	if !strings.HasPrefix(before, "hmac-sha512:") {
+		t.Fatalf("expected a SHA-512 HMAC, got %q", before)
+	}
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "audit/foo/rotate-hmac")
+	resp, err = b.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil || resp != nil {
+		t.Fatalf("bad: resp: %#v, err: %v", resp, err)
+	}
+	schema.ValidateResponse(
+		t,
+		schema.GetResponseSchema(t, b.(*SystemBackend).Route(req.Path), req.Operation),
+		resp,
+		true,
+	)
+
+	after := hash()
+	if after == before || !strings.HasPrefix(after, "hmac-sha512:") {
+		t.Fatalf("expected a new SHA-512 HMAC, got %q, was %q", after, before)
+	}
+	if again := hash(); again != after {
+		t.Fatalf("expected the new key to be kept, got %q, then %q", after, again)
+	}
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "audit/bar/rotate-hmac")
+	resp, err = b.HandleRequest(namespace.RootContext(nil), req)
+	if err != logical.ErrInvalidRequest || resp.Data["error"] != `no audit backend enabled at "bar/"` {
+		t.Fatalf("bad: resp: %#v, err: %v", resp, err)
+	}
+}
+
 func TestSystemBackend_auditHash(t *testing.T) {
 	c, b, _ := testCoreSystemBackend(t)
 	c.auditBackends["noop"] = corehelpers.NoopAuditFactory(nil)
@@ -3655,6 +3893,10 @@ func TestSystemBackend_ToolsRandom(t *testing.T) {
 				outputBytes, err = base64.StdEncoding.DecodeString(outputStr)
 			case "hex":
 				outputBytes, err = hex.DecodeString(outputStr)
+			case "base64url":
+				outputBytes, err = base64.RawURLEncoding.DecodeString(outputStr)
+			case "base32":
+				outputBytes, err = base32.StdEncoding.DecodeString(outputStr)
 			default:
 				t.Fatal("unknown format")
 			}
@@ -3687,6 +3929,13 @@ func TestSystemBackend_ToolsRandom(t *testing.T) {
 	req.Data["format"] = "hex"
 	doRequest(req, false, "hex", 24)
 
+	// Test the other encodings
+	req.Data["format"] = "base64url"
+	doRequest(req, false, "base64url", 24)
+
+	req.Data["format"] = "base32"
+	doRequest(req, false, "base32", 24)
+
 	// Test bad input/format
 	req.Path = "tools/random"
 	req.Data["format"] = "base92"
@@ -3701,6 +3950,131 @@ func TestSystemBackend_ToolsRandom(t *testing.T) {
 	doRequest(req, true, "", 0)
 }
 
+func TestSystemBackend_ToolsUUID(t *testing.T) {
+	b := testSystemBackend(t)
+
+	doRequest := func(data map[string]interface{}) *logical.Response {
+		t.Helper()
+		req := logical.TestRequest(t, logical.UpdateOperation, "tools/uuid")
+		req.Data = data
+		resp, err := b.HandleRequest(namespace.RootContext(nil), req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if !resp.IsError() {
+			schema.ValidateResponse(
+				t,
+				schema.GetResponseSchema(t, b.(*SystemBackend).Route(req.Path), req.Operation),
+				resp,
+				true,
+			)
+		}
+		return resp
+	}
+
+	resp := doRequest(nil)
+	uuids := resp.Data["uuids"].([]string)
+	if len(uuids) != 1 || len(uuids[0]) != 36 || uuids[0][14] != '4' {
+		t.Fatalf("bad: %#v", uuids)
+	}
+
+	resp = doRequest(map[string]interface{}{"version": 7, "count": 5})
+	uuids = resp.Data["uuids"].([]string)
+	if len(uuids) != 5 {
+		t.Fatalf("expected 5 UUIDs, got %#v", uuids)
+	}
+	for _, id := range uuids {
+		if len(id) != 36 || id[14] != '7' {
+			t.Fatalf("bad UUIDv7: %s", id)
+		}
+	}
+
+	if resp := doRequest(map[string]interface{}{"version": 5}); !resp.IsError() {
+		t.Fatalf("expected an error, got %#v", resp)
+	}
+	if resp := doRequest(map[string]interface{}{"count": maxToolsIDs + 1}); !resp.IsError() {
+		t.Fatalf("expected an error, got %#v", resp)
+	}
+}
+
+func TestSystemBackend_ToolsULID(t *testing.T) {
+	b := testSystemBackend(t)
+	req := logical.TestRequest(t, logical.UpdateOperation, "tools/ulid")
+	req.Data["count"] = 3
+
+	resp, err := b.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	schema.ValidateResponse(
+		t,
+		schema.GetResponseSchema(t, b.(*SystemBackend).Route(req.Path), req.Operation),
+		resp,
+		true,
+	)
+	ulids := resp.Data["ulids"].([]string)
+	if len(ulids) != 3 {
+		t.Fatalf("expected 3 ULIDs, got %#v", ulids)
+	}
+	for _, id := range ulids {
+		if len(id) != 26 {
+			t.Fatalf("bad ULID: %s", id)
+		}
+	}
+
+	req.Data["count"] = 0
+	resp, err = b.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !resp.IsError() {
+		t.Fatalf("expected an error, got %#v", resp)
+	}
+}
+
+func TestSystemBackend_ToolsHKDF(t *testing.T) {
+	b := testSystemBackend(t)
+
+	// RFC 5869, test case 1.
+	req := logical.TestRequest(t, logical.UpdateOperation, "tools/hkdf")
+	req.Data = map[string]interface{}{
+		"input":  base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{0x0b}, 22)),
+		"salt":   "AAECAwQFBgcICQoLDA==",
+		"info":   "8PHy8/T19vf4+Q==",
+		"bytes":  42,
+		"format": "hex",
+	}
+	resp, err := b.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	schema.ValidateResponse(
+		t,
+		schema.GetResponseSchema(t, b.(*SystemBackend).Route(req.Path), req.Operation),
+		resp,
+		true,
+	)
+	expected := "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865"
+	if derived := resp.Data["derived_bytes"].(string); derived != expected {
+		t.Fatalf("expected %s, got %s", expected, derived)
+	}
+
+	// Bad input, algorithm, length and format.
+	for _, data := range []map[string]interface{}{
+		{"input": "foobar"},
+		{"input": "Zm9v", "salt": "foobar"},
+		{"input": "Zm9v", "algorithm": "md5"},
+		{"input": "Zm9v", "bytes": 255*32 + 1},
+		{"input": "Zm9v", "format": "base92"},
+	} {
+		req.Data = data
+		resp, _ := b.HandleRequest(namespace.RootContext(nil), req)
+		if resp == nil || !resp.IsError() {
+			t.Fatalf("expected an error for %#v, got %#v", data, resp)
+		}
+	}
+}
+
 func TestSystemBackend_InternalUIMounts(t *testing.T) {
 	_, b, rootToken := testCoreSystemBackend(t)
 	systemBackend := b.(*SystemBackend)
@@ -4829,6 +5203,103 @@ func TestHandlePoliciesPasswordGenerate(t *testing.T) {
 	})
 }
 
+func TestHandlePoliciesPasswordWordlists(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	storage := new(logical.InmemStorage)
+	b := &SystemBackend{}
+
+	policy := "length = 20\n" +
+		"rule \"charset\" {\n" +
+		"	charset=\"abcdefghijklmnopqrstuvwxyz0123456789\"\n" +
+		"}\n" +
+		"rule \"dictionary\" {\n" +
+		"	wordlist=\"common\"\n" +
+		"}\n" +
+		"rule \"sequence\" {}\n"
+
+	// The wordlist must exist for the policy to be saved
+	_, err := b.handlePoliciesPasswordSet(ctx, &logical.Request{Storage: storage}, passwordPoliciesFieldData(map[string]interface{}{
+		"name":   "testpolicy",
+		"policy": policy,
+	}))
+	if err == nil {
+		t.Fatalf("err expected, got nil")
+	}
+
+	_, err = b.handlePoliciesPasswordWordlistSet(ctx, &logical.Request{Storage: storage}, passwordPolicyWordlistFieldData(map[string]interface{}{
+		"name":  "common",
+		"words": "password, vault,,vault",
+	}))
+	if err != nil {
+		t.Fatalf("no error expected, got: %s", err)
+	}
+
+	resp, err := b.handlePoliciesPasswordWordlistGet(ctx, &logical.Request{Storage: storage}, passwordPolicyWordlistFieldData(map[string]interface{}{
+		"name": "common",
+	}))
+	if err != nil {
+		t.Fatalf("no error expected, got: %s", err)
+	}
+	assertTrue(t, reflect.DeepEqual(resp.Data["words"], []string{"password", "vault"}), "unexpected words: %#v", resp.Data["words"])
+
+	_, err = b.handlePoliciesPasswordSet(ctx, &logical.Request{Storage: storage}, passwordPoliciesFieldData(map[string]interface{}{
+		"name":   "testpolicy",
+		"policy": policy,
+	}))
+	if err != nil {
+		t.Fatalf("no error expected, got: %s", err)
+	}
+
+	resp, err = b.handlePoliciesPasswordGenerate(ctx, &logical.Request{Storage: storage}, passwordPoliciesFieldData(map[string]interface{}{
+		"name": "testpolicy",
+	}))
+	if err != nil {
+		t.Fatalf("no error expected, got: %s", err)
+	}
+	assertIsString(t, resp.Data["password"], "password key should have a string value")
+
+	type testCase struct {
+		password            string
+		expectedValid       bool
+		expectedFailedRules []string
+	}
+
+	tests := map[string]testCase{
+		"valid password": {
+			password:            "x8kd2fxqzm",
+			expectedValid:       true,
+			expectedFailedRules: []string{},
+		},
+		"word of the wordlist": {
+			password:            "myPassword9",
+			expectedValid:       false,
+			expectedFailedRules: []string{"dictionary"},
+		},
+		"sequence": {
+			password:            "x8kd2fxyzq",
+			expectedValid:       false,
+			expectedFailedRules: []string{"sequence"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp, err := b.handlePoliciesPasswordValidate(ctx, &logical.Request{Storage: storage}, passwordPolicyValidateFieldData(map[string]interface{}{
+				"name":     "testpolicy",
+				"password": test.password,
+			}))
+			if err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+			assertTrue(t, resp.Data["valid"] == test.expectedValid, "valid is %v but should be %v", resp.Data["valid"], test.expectedValid)
+			assertTrue(t, reflect.DeepEqual(resp.Data["failed_rules"], test.expectedFailedRules),
+				"failed rules are %#v but should be %#v", resp.Data["failed_rules"], test.expectedFailedRules)
+		})
+	}
+}
+
 func assertTrue(t *testing.T, pass bool, f string, vals ...interface{}) {
 	t.Helper()
 	if !pass {
@@ -4868,6 +5339,38 @@ func passwordPoliciesFieldData(raw map[string]interface{}) *framework.FieldData
 	}
 }
 
+func passwordPolicyValidateFieldData(raw map[string]interface{}) *framework.FieldData {
+	return &framework.FieldData{
+		Raw: raw,
+		Schema: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "The name of the password policy.",
+			},
+			"password": {
+				Type:        framework.TypeString,
+				Description: "The password to validate.",
+			},
+		},
+	}
+}
+
+func passwordPolicyWordlistFieldData(raw map[string]interface{}) *framework.FieldData {
+	return &framework.FieldData{
+		Raw: raw,
+		Schema: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "The name of the wordlist.",
+			},
+			"words": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "The words passwords of the policies using the wordlist must not contain.",
+			},
+		},
+	}
+}
+
 func base64Encode(data string) string {
 	return base64.StdEncoding.EncodeToString([]byte(data))
 }
@@ -5879,3 +6382,47 @@ func TestSystemBackend_ReadExperiments(t *testing.T) {
 		})
 	}
 }
+
+func TestSystemBackend_RollbackStatus(t *testing.T) {
+	c, b, _ := testCoreSystemBackend(t)
+	ctx := namespace.RootContext(nil)
+
+	if err := c.rollback.Rollback(ctx, "cubbyhole/"); err != nil {
+		t.Fatal(err)
+	}
+
+	req := logical.TestRequest(t, logical.ReadOperation, "rollback/status")
+	resp, err := b.HandleRequest(ctx, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	mounts := resp.Data["mounts"].(map[string]interface{})
+	mount, ok := mounts["cubbyhole/"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected the status of cubbyhole/, got %#v", mounts)
+	}
+	if mount["last_rollback"] == "" || mount["last_error"] != "" || mount["consecutive_failures"] != 0 {
+		t.Fatalf("bad status: %#v", mount)
+	}
+}
+
+func TestSystemBackend_RollbackTrigger(t *testing.T) {
+	c, b, _ := testCoreSystemBackend(t)
+	ctx := namespace.RootContext(nil)
+
+	for _, path := range []string{"missing/", "cubbyhole/foo"} {
+		req := logical.TestRequest(t, logical.UpdateOperation, "rollback/trigger/"+path)
+		if _, err := b.HandleRequest(ctx, req); err != logical.ErrInvalidRequest {
+			t.Fatalf("expected an invalid request error for %q, got: %v", path, err)
+		}
+	}
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "rollback/trigger/cubbyhole")
+	if _, err := b.HandleRequest(ctx, req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, ok := c.rollback.Status()["cubbyhole/"]; !ok {
+		t.Fatal("expected cubbyhole/ to be rolled back")
+	}
+}
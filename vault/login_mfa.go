@@ -1792,6 +1792,43 @@ ECONFIG_LOOP:
 	return matchedMfaEnforcementConfig, nil
 }
 
+// buildMFAEnforcementConfigListForMount returns the MFAEnforcement configs
+// which apply to every login to the given auth mount, i.e. those of its
+// namespace or an ancestor which are configured with its accessor or type.
+// Configs which only target entities or groups are not returned, as whether
+// they apply depends on who logs in.
+func (c *Core) buildMFAEnforcementConfigListForMount(ctx context.Context, me *MountEntry) ([]*mfa.MFAEnforcementConfig, error) {
+	ns := me.Namespace()
+	if ns == nil {
+		return nil, fmt.Errorf("failed to find the namespace of the mount entry")
+	}
+
+	eConfigIter, err := c.loginMFABackend.MemDBMFALoginEnforcementConfigIterator()
+	if err != nil {
+		return nil, err
+	}
+
+	var matchedMfaEnforcementConfig []*mfa.MFAEnforcementConfig
+	for eConfigRaw := eConfigIter.Next(); eConfigRaw != nil; eConfigRaw = eConfigIter.Next() {
+		eConfig := eConfigRaw.(*mfa.MFAEnforcementConfig)
+
+		eConfigNS, err := c.NamespaceByID(ctx, eConfig.NamespaceID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the MFAEnforcementConfig namespace")
+		}
+		if eConfigNS == nil || (eConfigNS.ID != ns.ID && !ns.HasParent(eConfigNS)) {
+			continue
+		}
+
+		if strutil.StrListContains(eConfig.AuthMethodAccessors, me.Accessor) ||
+			strutil.StrListContains(eConfig.AuthMethodTypes, me.Type) {
+			matchedMfaEnforcementConfig = append(matchedMfaEnforcementConfig, eConfig)
+		}
+	}
+
+	return matchedMfaEnforcementConfig, nil
+}
+
 func formatUsername(format string, alias *identity.Alias, entity *identity.Entity) string {
 	if format == "" {
 		return alias.Name
@@ -329,6 +329,8 @@ type MountEntry struct {
 	Local                 bool              `json:"local"`                             // Local mounts are not replicated or affected by replication
 	SealWrap              bool              `json:"seal_wrap"`                         // Whether to wrap CSPs
 	ExternalEntropyAccess bool              `json:"external_entropy_access,omitempty"` // Whether to allow external entropy source access
+	DataKey               bool              `json:"data_key,omitempty"`                // Whether entries are encrypted with a data key of the mount
+	Owners                *MountOwners      `json:"owners,omitempty"`                  // The entities and groups delegated the administration of the mount
 	Tainted               bool              `json:"tainted,omitempty"`                 // Set as a Write-Ahead flag for unmount/remount
 	MountState            string            `json:"mount_state,omitempty"`             // The current mount state.  The only non-empty mount state right now is "unmounting"
 	NamespaceID           string            `json:"namespace_id"`
@@ -361,6 +363,28 @@ type MountConfig struct {
 	TokenType                 logical.TokenType     `json:"token_type,omitempty" structs:"token_type" mapstructure:"token_type"`
 	AllowedManagedKeys        []string              `json:"allowed_managed_keys,omitempty" mapstructure:"allowed_managed_keys"`
 	UserLockoutConfig         *UserLockoutConfig    `json:"user_lockout_config,omitempty" mapstructure:"user_lockout_config"`
+	RollbackPeriod            time.Duration         `json:"rollback_period,omitempty" structs:"rollback_period" mapstructure:"rollback_period"`    // Override for the rollback manager's period
+	LeaseTTLJitter            int                   `json:"lease_ttl_jitter,omitempty" structs:"lease_ttl_jitter" mapstructure:"lease_ttl_jitter"` // Percentage by which lease TTLs are randomly shortened
+
+	// MaxRequestSize and MaxResponseSize cap, in bytes, the size of the JSON
+	// encoded data of the requests routed to the mount and of the responses
+	// it returns. MaxRequestSize also replaces the max_request_size of the
+	// listener when reading the body of HTTP requests to the mount.
+	// MaxResponseSize only applies to the responses to reads and lists, which
+	// have no side effects. Zero means no limit.
+	MaxRequestSize  int64 `json:"max_request_size,omitempty" structs:"max_request_size" mapstructure:"max_request_size"`
+	MaxResponseSize int64 `json:"max_response_size,omitempty" structs:"max_response_size" mapstructure:"max_response_size"`
+
+	// TokenBindSourceCIDR and TokenBindClientCert bind the tokens issued by
+	// logins to auth mounts to the source address of the login and to its TLS
+	// client certificate respectively.
+	TokenBindSourceCIDR bool `json:"token_bind_source_cidr,omitempty" structs:"token_bind_source_cidr" mapstructure:"token_bind_source_cidr"`
+	TokenBindClientCert bool `json:"token_bind_client_cert,omitempty" structs:"token_bind_client_cert" mapstructure:"token_bind_client_cert"`
+
+	// IdentityTokenRole is the identity OIDC role through which the plugin
+	// of the mount may issue identity tokens to the entities of the requests
+	// it handles. Plugins can't issue any if it isn't set.
+	IdentityTokenRole string `json:"identity_token_role,omitempty" structs:"identity_token_role" mapstructure:"identity_token_role"`
 
 	// PluginName is the name of the plugin registered in the catalog.
 	//
@@ -396,6 +420,8 @@ type APIMountConfig struct {
 	AllowedManagedKeys        []string              `json:"allowed_managed_keys,omitempty" mapstructure:"allowed_managed_keys"`
 	UserLockoutConfig         *UserLockoutConfig    `json:"user_lockout_config,omitempty" mapstructure:"user_lockout_config"`
 	PluginVersion             string                `json:"plugin_version,omitempty" mapstructure:"plugin_version"`
+	TokenBindSourceCIDR       bool                  `json:"token_bind_source_cidr,omitempty" mapstructure:"token_bind_source_cidr"`
+	TokenBindClientCert       bool                  `json:"token_bind_client_cert,omitempty" mapstructure:"token_bind_client_cert"`
 
 	// PluginName is the name of the plugin registered in the catalog.
 	//
@@ -654,6 +680,11 @@ func (c *Core) mountInternal(ctx context.Context, entry *MountEntry, updateStora
 	if err != nil {
 		return fmt.Errorf("error creating forwarded writer: %v", err)
 	}
+	if entry.DataKey {
+		if forwarded, err = c.newDataKeyStorage(ctx, entry, forwarded, true); err != nil {
+			return err
+		}
+	}
 
 	viewPath := entry.ViewPath()
 	view := NewBarrierView(forwarded, viewPath)
@@ -917,6 +948,20 @@ func (c *Core) unmountInternal(ctx context.Context, path string, updateStorage b
 		}
 	}
 
+	if entry.DataKey && updateStorage {
+		if err := c.deleteDataKeyring(ctx, entry); err != nil {
+			c.logger.Error("failed to delete data keyring of mount being unmounted", "error", err, "path", path)
+			return err
+		}
+	}
+
+	if entry.Type == "kv" && updateStorage {
+		if err := c.deleteKVEmbargoes(ctx, entry); err != nil {
+			c.logger.Error("failed to delete secret embargoes of mount being unmounted", "error", err, "path", path)
+			return err
+		}
+	}
+
 	// Remove the mount table entry
 	if err := c.removeMountEntry(ctx, path, updateStorage); err != nil {
 		c.logger.Error("failed to remove mount entry for path being unmounted", "error", err, "path", path)
@@ -1483,6 +1528,22 @@ func (c *Core) setupMounts(ctx context.Context) error {
 			return fmt.Errorf("error creating forwarded writer: %v", err)
 		}
 
+		// Encrypt the entries of the mount with its data key, resuming any
+		// interrupted rotation of the key once unsealed
+		if entry.DataKey {
+			dataKeyStorage, err := c.newDataKeyStorage(ctx, entry, forwarded, false)
+			if err != nil {
+				return err
+			}
+			if _, _, rotating := dataKeyStorage.status(); rotating && !c.perfStandby {
+				entry := entry
+				c.postUnsealFuncs = append(c.postUnsealFuncs, func() {
+					c.reencryptMountData(entry, dataKeyStorage)
+				})
+			}
+			forwarded = dataKeyStorage
+		}
+
 		// Create a barrier storage view using the UUID
 		view := NewBarrierView(forwarded, barrierPath)
 
@@ -1714,9 +1775,11 @@ func (c *Core) newLogicalBackend(ctx context.Context, entry *MountEntry, sysView
 		System:      sysView,
 		BackendUUID: entry.BackendAwareUUID,
 	}
+	var eventsSender logical.EventSender
 	if c.IsExperimentEnabled(experiments.VaultExperimentEventsAlpha1) {
-		config.EventsSender = pluginEventSender
+		eventsSender = pluginEventSender
 	}
+	config.EventsSender = c.newHealthWarningSender(entry, eventsSender)
 
 	ctx = namespace.ContextWithNamespace(ctx, entry.namespace)
 	ctx = context.WithValue(ctx, "core_number", c.coreNumber)
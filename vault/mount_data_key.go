@@ -0,0 +1,407 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	// dataKeyringPrefix is the barrier path under which the data encryption
+	// keys of the mounts created with data_key are stored, by mount UUID.
+	dataKeyringPrefix = "core/data-keyrings/"
+
+	// dataKeyTermSize is the size of the term prefixing the entries encrypted
+	// with a data key.
+	dataKeyTermSize = 4
+)
+
+var errDataKeyRotationInProgress = errors.New("a rotation of the data key of the mount is already in progress")
+
+// dataKeyring holds the data encryption keys of a mount, by term. It is
+// persisted in the barrier, so the keys are themselves encrypted by the
+// barrier keyring. Entries are encrypted with the key of the active term;
+// the keys of the older terms are kept until all the entries of the mount have
+// been re-encrypted following a rotation.
+type dataKeyring struct {
+	ActiveTerm  uint32            `json:"active_term"`
+	Keys        map[uint32][]byte `json:"keys"`
+	InstallTime time.Time         `json:"install_time"`
+}
+
+// rotating reports whether entries may still be encrypted with older keys.
+func (k *dataKeyring) rotating() bool {
+	return len(k.Keys) > 1
+}
+
+// dataKeyStorage encrypts the values of the entries of a mount with its data
+// encryption keys, before they are handed to the barrier. It sits below the
+// barrier view of the mount, so keys are full barrier paths.
+type dataKeyStorage struct {
+	logical.Storage
+
+	// barrier stores the keyring, at keyringPath
+	barrier     logical.Storage
+	keyringPath string
+
+	// prefix is the view path of the mount. The keys of entries relative to
+	// it are authenticated along with their values, so that entries can't be
+	// swapped around within the mount.
+	prefix string
+
+	// locks serialize the re-encryption of entries with their writes
+	locks []*locksutil.LockEntry
+
+	// rotateLock serializes the changes to the keyring
+	rotateLock sync.Mutex
+
+	l       sync.RWMutex
+	keyring *dataKeyring
+	aeads   map[uint32]cipher.AEAD
+}
+
+// newDataKeyStorage loads the data keyring of the mount and returns storage
+// encrypting its entries. When create is set, a keyring is generated for
+// mounts which have none yet.
+func (c *Core) newDataKeyStorage(ctx context.Context, entry *MountEntry, storage logical.Storage, create bool) (*dataKeyStorage, error) {
+	s := &dataKeyStorage{
+		Storage:     storage,
+		barrier:     c.barrier,
+		keyringPath: dataKeyringPrefix + entry.UUID,
+		prefix:      entry.ViewPath(),
+		locks:       locksutil.CreateLocks(),
+	}
+
+	raw, err := s.barrier.Get(ctx, s.keyringPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data keyring: %w", err)
+	}
+
+	var keyring *dataKeyring
+	switch {
+	case raw != nil:
+		keyring = new(dataKeyring)
+		if err := raw.DecodeJSON(keyring); err != nil {
+			return nil, fmt.Errorf("failed to decode data keyring: %w", err)
+		}
+	case create:
+		key, err := c.generateDataKey()
+		if err != nil {
+			return nil, err
+		}
+		keyring = &dataKeyring{
+			ActiveTerm:  1,
+			Keys:        map[uint32][]byte{1: key},
+			InstallTime: time.Now(),
+		}
+		if err := s.persistKeyring(ctx, keyring); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("missing data keyring of mount %q", entry.Path)
+	}
+
+	if err := s.setKeyring(keyring); err != nil {
+		return nil, err
+	}
+
+	c.mountDataKeys.Store(entry.UUID, s)
+	return s, nil
+}
+
+// generateDataKey returns a new AES-256 data encryption key.
+func (c *Core) generateDataKey() ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(c.secureRandomReader, key); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	return key, nil
+}
+
+// mountDataKeyStorage returns the storage encrypting the entries of the mount,
+// or nil if it wasn't created with a data key.
+func (c *Core) mountDataKeyStorage(entry *MountEntry) *dataKeyStorage {
+	raw, ok := c.mountDataKeys.Load(entry.UUID)
+	if !ok {
+		return nil
+	}
+	return raw.(*dataKeyStorage)
+}
+
+// deleteDataKeyring forgets the data keys of the mount, leaving whatever is
+// left of its entries, e.g. in storage backups, impossible to decrypt.
+func (c *Core) deleteDataKeyring(ctx context.Context, entry *MountEntry) error {
+	c.mountDataKeys.Delete(entry.UUID)
+	return c.barrier.Delete(ctx, dataKeyringPrefix+entry.UUID)
+}
+
+// rotateMountDataKey makes a new data key active for the mount and
+// re-encrypts its entries with it in the background, after which the older
+// keys are destroyed.
+func (c *Core) rotateMountDataKey(ctx context.Context, entry *MountEntry) (uint32, error) {
+	s := c.mountDataKeyStorage(entry)
+	if s == nil {
+		return 0, fmt.Errorf("mount %q has no data key", entry.Path)
+	}
+
+	key, err := c.generateDataKey()
+	if err != nil {
+		return 0, err
+	}
+	term, err := s.rotate(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+
+	c.reencryptMountData(entry, s)
+	return term, nil
+}
+
+// reencryptMountData re-encrypts the entries of the mount with its active data
+// key in the background, until done or until the node seals or steps down.
+// Rotations interrupted this way resume when mounts are next set up.
+func (c *Core) reencryptMountData(entry *MountEntry, s *dataKeyStorage) {
+	ctx := c.activeContext
+	logger := c.logger.Named("data-key").With("path", entry.Path)
+	go func() {
+		start := time.Now()
+		if err := s.reencrypt(ctx); err != nil {
+			if ctx.Err() == nil {
+				logger.Error("failed to re-encrypt the entries of the mount with its new data key", "error", err)
+			}
+			return
+		}
+		logger.Info("re-encrypted the entries of the mount with its new data key", "duration", time.Since(start))
+	}()
+}
+
+// setKeyring makes the keyring current.
+func (s *dataKeyStorage) setKeyring(keyring *dataKeyring) error {
+	aeads := make(map[uint32]cipher.AEAD, len(keyring.Keys))
+	for term, key := range keyring.Keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return fmt.Errorf("invalid data key for term %d: %w", term, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return err
+		}
+		aeads[term] = aead
+	}
+	if _, ok := aeads[keyring.ActiveTerm]; !ok {
+		return fmt.Errorf("missing data key for active term %d", keyring.ActiveTerm)
+	}
+
+	s.l.Lock()
+	s.keyring = keyring
+	s.aeads = aeads
+	s.l.Unlock()
+	return nil
+}
+
+func (s *dataKeyStorage) persistKeyring(ctx context.Context, keyring *dataKeyring) error {
+	entry, err := logical.StorageEntryJSON(s.keyringPath, keyring)
+	if err != nil {
+		return fmt.Errorf("failed to encode data keyring: %w", err)
+	}
+	if err := s.barrier.Put(ctx, entry); err != nil {
+		return fmt.Errorf("failed to persist data keyring: %w", err)
+	}
+	return nil
+}
+
+// status returns the active term of the keyring and whether a rotation is in
+// progress.
+func (s *dataKeyStorage) status() (uint32, time.Time, bool) {
+	s.l.RLock()
+	defer s.l.RUnlock()
+	return s.keyring.ActiveTerm, s.keyring.InstallTime, s.keyring.rotating()
+}
+
+// rotate makes the given key active under a new term.
+func (s *dataKeyStorage) rotate(ctx context.Context, key []byte) (uint32, error) {
+	s.rotateLock.Lock()
+	defer s.rotateLock.Unlock()
+
+	s.l.RLock()
+	current := s.keyring
+	s.l.RUnlock()
+	if current.rotating() {
+		return 0, errDataKeyRotationInProgress
+	}
+
+	keyring := &dataKeyring{
+		ActiveTerm:  current.ActiveTerm + 1,
+		Keys:        make(map[uint32][]byte, len(current.Keys)+1),
+		InstallTime: time.Now(),
+	}
+	for term, key := range current.Keys {
+		keyring.Keys[term] = key
+	}
+	keyring.Keys[keyring.ActiveTerm] = key
+
+	if err := s.persistKeyring(ctx, keyring); err != nil {
+		return 0, err
+	}
+	if err := s.setKeyring(keyring); err != nil {
+		return 0, err
+	}
+	return keyring.ActiveTerm, nil
+}
+
+// reencrypt re-encrypts the entries which aren't encrypted with the active key
+// yet, then destroys the older keys.
+func (s *dataKeyStorage) reencrypt(ctx context.Context) error {
+	keys, err := logical.CollectKeys(ctx, logical.NewStorageView(s.Storage, s.prefix))
+	if err != nil {
+		return fmt.Errorf("failed to list entries: %w", err)
+	}
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.reencryptEntry(ctx, s.prefix+key); err != nil {
+			return fmt.Errorf("failed to re-encrypt entry %q: %w", key, err)
+		}
+	}
+
+	s.rotateLock.Lock()
+	defer s.rotateLock.Unlock()
+
+	s.l.RLock()
+	current := s.keyring
+	s.l.RUnlock()
+	keyring := &dataKeyring{
+		ActiveTerm:  current.ActiveTerm,
+		Keys:        map[uint32][]byte{current.ActiveTerm: current.Keys[current.ActiveTerm]},
+		InstallTime: current.InstallTime,
+	}
+	if err := s.persistKeyring(ctx, keyring); err != nil {
+		return err
+	}
+	return s.setKeyring(keyring)
+}
+
+func (s *dataKeyStorage) reencryptEntry(ctx context.Context, key string) error {
+	lock := locksutil.LockForKey(s.locks, key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	entry, err := s.Storage.Get(ctx, key)
+	if err != nil || entry == nil {
+		return err
+	}
+
+	s.l.RLock()
+	activeTerm := s.keyring.ActiveTerm
+	s.l.RUnlock()
+	if len(entry.Value) >= dataKeyTermSize && binary.BigEndian.Uint32(entry.Value) == activeTerm {
+		return nil
+	}
+
+	value, err := s.decrypt(key, entry.Value)
+	if err != nil {
+		return err
+	}
+	if entry.Value, err = s.encrypt(key, value); err != nil {
+		return err
+	}
+	return s.Storage.Put(ctx, entry)
+}
+
+// encrypt returns the value encrypted with the active key, prefixed with its
+// term and nonce.
+func (s *dataKeyStorage) encrypt(key string, value []byte) ([]byte, error) {
+	s.l.RLock()
+	term := s.keyring.ActiveTerm
+	aead := s.aeads[term]
+	s.l.RUnlock()
+
+	size := dataKeyTermSize + aead.NonceSize()
+	out := make([]byte, size, size+len(value)+aead.Overhead())
+	binary.BigEndian.PutUint32(out, term)
+	nonce := out[dataKeyTermSize:size]
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(out, nonce, value, s.additionalData(key)), nil
+}
+
+// decrypt returns the value encrypted by encrypt.
+func (s *dataKeyStorage) decrypt(key string, value []byte) ([]byte, error) {
+	if len(value) < dataKeyTermSize {
+		return nil, errors.New("invalid encrypted entry")
+	}
+	term := binary.BigEndian.Uint32(value)
+
+	s.l.RLock()
+	aead, ok := s.aeads[term]
+	s.l.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no data key for term %d", term)
+	}
+
+	value = value[dataKeyTermSize:]
+	if len(value) < aead.NonceSize() {
+		return nil, errors.New("invalid encrypted entry")
+	}
+	return aead.Open(nil, value[:aead.NonceSize()], value[aead.NonceSize():], s.additionalData(key))
+}
+
+func (s *dataKeyStorage) additionalData(key string) []byte {
+	return []byte(strings.TrimPrefix(key, s.prefix))
+}
+
+func (s *dataKeyStorage) Get(ctx context.Context, key string) (*logical.StorageEntry, error) {
+	lock := locksutil.LockForKey(s.locks, key)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	entry, err := s.Storage.Get(ctx, key)
+	if err != nil || entry == nil {
+		return entry, err
+	}
+	value, err := s.decrypt(key, entry.Value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt entry with the data key of the mount: %w", err)
+	}
+	entry.Value = value
+	return entry, nil
+}
+
+func (s *dataKeyStorage) Put(ctx context.Context, entry *logical.StorageEntry) error {
+	lock := locksutil.LockForKey(s.locks, entry.Key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	value, err := s.encrypt(entry.Key, entry.Value)
+	if err != nil {
+		return err
+	}
+	encrypted := *entry
+	encrypted.Value = value
+	return s.Storage.Put(ctx, &encrypted)
+}
+
+func (s *dataKeyStorage) Delete(ctx context.Context, key string) error {
+	lock := locksutil.LockForKey(s.locks, key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	return s.Storage.Delete(ctx, key)
+}
@@ -0,0 +1,104 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCore_MountDataKey verifies that the entries of mounts created with a data
+// key are encrypted with it, that they're re-encrypted when it is rotated, and
+// that the keyring is destroyed along with the mount.
+func TestCore_MountDataKey(t *testing.T) {
+	c, _, _ := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	me := &MountEntry{
+		Table:   mountTableType,
+		Path:    "foo/",
+		Type:    "kv",
+		DataKey: true,
+	}
+	require.NoError(t, c.mount(ctx, me))
+
+	s := c.mountDataKeyStorage(me)
+	require.NotNil(t, s)
+	term, _, rotating := s.status()
+	require.Equal(t, uint32(1), term)
+	require.False(t, rotating)
+
+	view := c.router.MatchingStorageByAPIPath(ctx, "foo/")
+	require.NotNil(t, view)
+	require.NoError(t, view.Put(ctx, &logical.StorageEntry{Key: "bar", Value: []byte("baz")}))
+
+	rawTerm := func(key string) uint32 {
+		t.Helper()
+		raw, err := c.barrier.Get(ctx, me.ViewPath()+key)
+		require.NoError(t, err)
+		require.NotNil(t, raw)
+		require.NotContains(t, string(raw.Value), "baz")
+		return binary.BigEndian.Uint32(raw.Value)
+	}
+	require.Equal(t, uint32(1), rawTerm("bar"))
+
+	entry, err := view.Get(ctx, "bar")
+	require.NoError(t, err)
+	require.Equal(t, "baz", string(entry.Value))
+
+	// Entries can't be moved around within the mount
+	raw, err := c.barrier.Get(ctx, me.ViewPath()+"bar")
+	require.NoError(t, err)
+	raw.Key = me.ViewPath() + "moved"
+	require.NoError(t, c.barrier.Put(ctx, raw))
+	_, err = view.Get(ctx, "moved")
+	require.Error(t, err)
+	require.NoError(t, view.Delete(ctx, "moved"))
+
+	// Rotate, without re-encrypting in the background
+	key, err := c.generateDataKey()
+	require.NoError(t, err)
+	term, err = s.rotate(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), term)
+	_, err = s.rotate(ctx, key)
+	require.ErrorIs(t, err, errDataKeyRotationInProgress)
+
+	require.NoError(t, view.Put(ctx, &logical.StorageEntry{Key: "qux", Value: []byte("baz")}))
+	require.Equal(t, uint32(1), rawTerm("bar"))
+	require.Equal(t, uint32(2), rawTerm("qux"))
+	entry, err = view.Get(ctx, "bar")
+	require.NoError(t, err)
+	require.Equal(t, "baz", string(entry.Value))
+
+	require.NoError(t, s.reencrypt(ctx))
+	require.Equal(t, uint32(2), rawTerm("bar"))
+	_, _, rotating = s.status()
+	require.False(t, rotating)
+
+	var keyring dataKeyring
+	raw, err = c.barrier.Get(ctx, dataKeyringPrefix+me.UUID)
+	require.NoError(t, err)
+	require.NoError(t, raw.DecodeJSON(&keyring))
+	require.Equal(t, uint32(2), keyring.ActiveTerm)
+	require.Len(t, keyring.Keys, 1)
+
+	entry, err = view.Get(ctx, "bar")
+	require.NoError(t, err)
+	require.Equal(t, "baz", string(entry.Value))
+
+	// Mounts without a data key are left alone
+	_, err = c.rotateMountDataKey(ctx, c.router.MatchingMountEntry(ctx, "secret/"))
+	require.Error(t, err)
+
+	require.NoError(t, c.unmount(ctx, "foo"))
+	require.Nil(t, c.mountDataKeyStorage(me))
+	raw, err = c.barrier.Get(ctx, dataKeyringPrefix+me.UUID)
+	require.NoError(t, err)
+	require.Nil(t, raw)
+}
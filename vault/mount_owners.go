@@ -0,0 +1,209 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-secure-stdlib/strutil"
+	"github.com/hashicorp/vault/helper/identity"
+)
+
+// mountOwnerPolicyPrefix prefixes the names of the policies generated for the
+// owners of mounts, which are followed by the accessor of the mount.
+const mountOwnerPolicyPrefix = "mount-owner-"
+
+// MountOwners are the entities and groups delegated the administration of a
+// mount. They are granted the policy generated for the owners of the mount,
+// on top of the policies of their tokens.
+type MountOwners struct {
+	EntityIDs []string `json:"entity_ids,omitempty"`
+	GroupIDs  []string `json:"group_ids,omitempty"`
+}
+
+// owns returns whether the entity, or one of the groups it belongs to, is an
+// owner.
+func (o *MountOwners) owns(entityID string, groupIDs map[string]bool) bool {
+	if strutil.StrListContains(o.EntityIDs, entityID) {
+		return true
+	}
+	for _, id := range o.GroupIDs {
+		if groupIDs[id] {
+			return true
+		}
+	}
+	return false
+}
+
+// ownedMount is a mount which has owners.
+type ownedMount struct {
+	entry  *MountEntry
+	owners *MountOwners
+}
+
+// mountOwnerPolicyName returns the name of the policy generated for the owners
+// of the mount.
+func mountOwnerPolicyName(entry *MountEntry) string {
+	return mountOwnerPolicyPrefix + entry.Accessor
+}
+
+// mountOwnerPolicyRules returns the rules of the policy generated for the
+// owners of the mount. They can use everything within a secrets engine, tune
+// it, and rotate its data key, but nothing else. Within an auth method they
+// can only read: writing its roles and users would let them grant any policy
+// to the tokens it issues.
+func mountOwnerPolicyRules(entry *MountEntry) string {
+	if entry.Table == credentialTableType {
+		return fmt.Sprintf(`
+path "%[1]s%[2]s*" {
+	capabilities = ["read", "list"]
+}
+
+path "sys/auth/%[2]stune" {
+	capabilities = ["read", "update", "sudo"]
+}
+`, credentialRoutePrefix, entry.Path)
+	}
+
+	return fmt.Sprintf(`
+path "%[1]s*" {
+	capabilities = ["create", "read", "update", "patch", "delete", "list"]
+}
+
+path "sys/mounts/%[1]stune" {
+	capabilities = ["read", "update"]
+}
+
+path "sys/mounts/%[1]sdata-key" {
+	capabilities = ["read"]
+}
+
+path "sys/mounts/%[1]sdata-key/rotate" {
+	capabilities = ["update"]
+}
+`, entry.Path)
+}
+
+// checkMountOwnerPolicyNames returns an error if one of the policies is named
+// like the policies generated for the owners of mounts. These are only granted
+// to the owners themselves, so listing them elsewhere is a mistake.
+func checkMountOwnerPolicyNames(policies []string) error {
+	for _, name := range policies {
+		if strings.HasPrefix(name, mountOwnerPolicyPrefix) {
+			return fmt.Errorf("policy %q can't be granted: policies prefixed with %q are reserved for the owners of mounts", name, mountOwnerPolicyPrefix)
+		}
+	}
+	return nil
+}
+
+// ownedMounts returns the mounts and auth methods which have owners.
+func (c *Core) ownedMounts() []ownedMount {
+	var owned []ownedMount
+	collect := func(table *MountTable) {
+		if table == nil {
+			return
+		}
+		for _, entry := range table.Entries {
+			if entry.Owners != nil {
+				owned = append(owned, ownedMount{entry: entry, owners: entry.Owners})
+			}
+		}
+	}
+
+	c.mountsLock.RLock()
+	collect(c.mounts)
+	c.mountsLock.RUnlock()
+
+	c.authLock.RLock()
+	collect(c.auth)
+	c.authLock.RUnlock()
+
+	return owned
+}
+
+// entityGroupIDs returns the IDs of the groups the entity belongs to, directly
+// or through other groups.
+func (c *Core) entityGroupIDs(entityID string) (map[string]bool, error) {
+	directGroups, inheritedGroups, err := c.identityStore.groupsByEntityID(entityID)
+	if err != nil {
+		return nil, err
+	}
+	groupIDs := make(map[string]bool, len(directGroups)+len(inheritedGroups))
+	for _, group := range append(directGroups, inheritedGroups...) {
+		groupIDs[group.ID] = true
+	}
+	return groupIDs, nil
+}
+
+// mountOwnerACLPolicies returns the policies generated for the mounts owned by
+// the entity or its groups. This is the only way they are granted: they can't
+// be looked up by name, so tokens, roles and entities listing their names get
+// nothing. The policies are generated from the current path of the mounts, so
+// that they follow remounts.
+func (c *Core) mountOwnerACLPolicies(ctx context.Context, entity *identity.Entity) ([]*Policy, error) {
+	if entity == nil {
+		return nil, nil
+	}
+	owned := c.ownedMounts()
+	if len(owned) == 0 {
+		return nil, nil
+	}
+
+	groupIDs, err := c.entityGroupIDs(entity.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var policies []*Policy
+	for _, m := range owned {
+		// Singleton mounts can't have owners, which would be granted the
+		// administration of the whole system or identity store
+		if strutil.StrListContains(singletonMounts, m.entry.Type) || !m.owners.owns(entity.ID, groupIDs) {
+			continue
+		}
+
+		ns, err := NamespaceByID(ctx, m.entry.NamespaceID, c)
+		if err != nil {
+			return nil, err
+		}
+		if ns == nil {
+			continue
+		}
+		policy, err := ParseACLPolicy(ns, mountOwnerPolicyRules(m.entry))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the policy of the owners of mount %q: %w", m.entry.Path, err)
+		}
+		policy.Name = mountOwnerPolicyName(m.entry)
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// setMountOwners replaces the owners of the mount, which are removed if nil.
+func (c *Core) setMountOwners(ctx context.Context, entry *MountEntry, owners *MountOwners) error {
+	if entry.Table == credentialTableType {
+		c.authLock.Lock()
+		defer c.authLock.Unlock()
+	} else {
+		c.mountsLock.Lock()
+		defer c.mountsLock.Unlock()
+	}
+
+	oldOwners := entry.Owners
+	entry.Owners = owners
+
+	var err error
+	if entry.Table == credentialTableType {
+		err = c.persistAuth(ctx, c.auth, &entry.Local)
+	} else {
+		err = c.persistMounts(ctx, c.mounts, &entry.Local)
+	}
+	if err != nil {
+		entry.Owners = oldOwners
+		return err
+	}
+	return nil
+}
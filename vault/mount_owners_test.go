@@ -0,0 +1,141 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCore_MountOwners verifies that the owners of a mount, whether entities
+// or members of groups, are granted the rights to administer it and nothing
+// else.
+func TestCore_MountOwners(t *testing.T) {
+	c, _, root := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	request := func(token string, op logical.Operation, path string, data map[string]interface{}) (*logical.Response, error) {
+		t.Helper()
+		req := logical.TestRequest(t, op, path)
+		req.ClientToken = token
+		req.Data = data
+		resp, err := c.HandleRequest(ctx, req)
+		if err == nil && resp != nil && resp.IsError() {
+			err = resp.Error()
+		}
+		return resp, err
+	}
+
+	_, err := request(root, logical.UpdateOperation, "sys/mounts/foo", map[string]interface{}{"type": "kv"})
+	require.NoError(t, err)
+	resp, err := request(root, logical.UpdateOperation, "identity/entity", map[string]interface{}{"name": "alice"})
+	require.NoError(t, err)
+	entityID := resp.Data["id"].(string)
+
+	te := &logical.TokenEntry{
+		Path:     "auth/token/create",
+		Policies: []string{"default"},
+		EntityID: entityID,
+	}
+	testMakeTokenDirectly(t, c.tokenStore, te)
+
+	assertOwner := func(owner bool) {
+		t.Helper()
+		for _, path := range []string{"foo/bar", "sys/mounts/foo/tune"} {
+			_, err := request(te.ID, logical.UpdateOperation, path, map[string]interface{}{"description": "baz"})
+			if owner {
+				require.NoError(t, err, path)
+			} else {
+				require.ErrorIs(t, err, logical.ErrPermissionDenied, path)
+			}
+		}
+		_, err := request(te.ID, logical.UpdateOperation, "secret/bar", map[string]interface{}{"baz": "qux"})
+		require.ErrorIs(t, err, logical.ErrPermissionDenied)
+		_, err = request(te.ID, logical.UpdateOperation, "sys/mounts/secret/tune", map[string]interface{}{"description": "baz"})
+		require.ErrorIs(t, err, logical.ErrPermissionDenied)
+	}
+	assertOwner(false)
+
+	_, err = request(root, logical.UpdateOperation, "sys/mounts/foo/owners", map[string]interface{}{"entity_ids": "nonexistent"})
+	require.Error(t, err)
+	_, err = request(root, logical.UpdateOperation, "sys/mounts/sys/owners", map[string]interface{}{"entity_ids": entityID})
+	require.Error(t, err)
+
+	resp, err = request(root, logical.UpdateOperation, "sys/mounts/foo/owners", map[string]interface{}{"entity_ids": entityID})
+	require.NoError(t, err)
+	me := c.router.MatchingMountEntry(ctx, "foo/")
+	require.Equal(t, mountOwnerPolicyPrefix+me.Accessor, resp.Data["policy"])
+	assertOwner(true)
+
+	// The generated policy can't be written, nor granted by name
+	_, err = request(root, logical.UpdateOperation, "sys/policies/acl/"+mountOwnerPolicyName(me), map[string]interface{}{"policy": `path "*" { capabilities = ["sudo"] }`})
+	require.Error(t, err)
+	_, err = request(root, logical.UpdateOperation, "auth/token/create", map[string]interface{}{"policies": mountOwnerPolicyName(me)})
+	require.Error(t, err)
+	_, err = request(root, logical.UpdateOperation, "auth/token/roles/owner", map[string]interface{}{"allowed_policies": mountOwnerPolicyName(me)})
+	require.Error(t, err)
+	sysEntry := c.router.MatchingMountEntry(ctx, "sys/")
+	resp, err = request(root, logical.UpdateOperation, "identity/entity", map[string]interface{}{
+		"name":     "mallory",
+		"policies": mountOwnerPolicyName(sysEntry),
+	})
+	require.NoError(t, err)
+	other := &logical.TokenEntry{
+		Path:     "auth/token/create",
+		Policies: []string{"default"},
+		EntityID: resp.Data["id"].(string),
+	}
+	testMakeTokenDirectly(t, c.tokenStore, other)
+	_, err = request(other.ID, logical.UpdateOperation, "sys/mounts/bar", map[string]interface{}{"type": "kv"})
+	require.ErrorIs(t, err, logical.ErrPermissionDenied)
+	_, err = request(other.ID, logical.UpdateOperation, "foo/bar", map[string]interface{}{"baz": "qux"})
+	require.ErrorIs(t, err, logical.ErrPermissionDenied)
+
+	// Owners of auth methods can't write their roles or users, which would
+	// let them grant any policy
+	policy, err := ParseACLPolicy(namespace.RootNamespace, mountOwnerPolicyRules(&MountEntry{Table: credentialTableType, Path: "userpass/"}))
+	require.NoError(t, err)
+	acl, err := NewACL(ctx, []*Policy{policy})
+	require.NoError(t, err)
+	require.Equal(t, []string{ReadCapability, ListCapability}, acl.Capabilities(ctx, "auth/userpass/users/alice"))
+
+	// Members of owner groups are owners too
+	resp, err = request(root, logical.UpdateOperation, "identity/group", map[string]interface{}{
+		"name":              "devs",
+		"member_entity_ids": entityID,
+	})
+	require.NoError(t, err)
+	groupID := resp.Data["id"].(string)
+	_, err = request(root, logical.UpdateOperation, "sys/mounts/foo/owners", map[string]interface{}{"group_ids": groupID})
+	require.NoError(t, err)
+	assertOwner(true)
+
+	resp, err = request(root, logical.ReadOperation, "sys/mount-owners", map[string]interface{}{"entity_id": entityID})
+	require.NoError(t, err)
+	require.Contains(t, resp.Data["mounts"], "foo/")
+	resp, err = request(root, logical.ReadOperation, "sys/mount-owners", map[string]interface{}{"group_id": "other"})
+	require.NoError(t, err)
+	require.Empty(t, resp.Data["mounts"])
+
+	// Owners follow the mount when it is moved
+	_, err = request(root, logical.UpdateOperation, "sys/remount", map[string]interface{}{"from": "foo", "to": "moved"})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		_, err := request(te.ID, logical.UpdateOperation, "moved/bar", map[string]interface{}{"baz": "qux"})
+		return err == nil
+	}, 5*time.Second, 100*time.Millisecond)
+	_, err = request(root, logical.UpdateOperation, "sys/remount", map[string]interface{}{"from": "moved", "to": "foo"})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return c.router.MatchingMountEntry(ctx, "foo/") != nil
+	}, 5*time.Second, 100*time.Millisecond)
+
+	_, err = request(root, logical.DeleteOperation, "sys/mounts/foo/owners", nil)
+	require.NoError(t, err)
+	assertOwner(false)
+}
@@ -9,6 +9,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+
+	"github.com/hashicorp/vault/helper/random"
 )
 
 const (
@@ -34,3 +36,8 @@ func (d dynamicSystemView) retrievePasswordPolicy(ctx context.Context, policyNam
 
 	return policyCfg, nil
 }
+
+// parsePasswordPolicy parses a password policy, reading the wordlists of its dictionary rules from the logical storage
+func (d dynamicSystemView) parsePasswordPolicy(ctx context.Context, rawPolicy string) (*random.StringGenerator, error) {
+	return parsePasswordPolicy(ctx, d.core.systemBarrierView, rawPolicy)
+}
@@ -59,6 +59,11 @@ type PluginCatalog struct {
 	externalPlugins map[externalPluginsKey]*externalPlugin
 	mlockPlugins    bool
 
+	// restartPolicy configures the restarts of the external plugin processes
+	// using too much memory, and restarts tracks them by plugin.
+	restartPolicy PluginRestartPolicy
+	restarts      map[externalPluginsKey]*pluginRestartState
+
 	lock    sync.RWMutex
 	wrapper pluginutil.RunnerUtil
 }
@@ -173,6 +178,7 @@ func (c *Core) setupPluginCatalog(ctx context.Context) error {
 		directory:       c.pluginDirectory,
 		logger:          c.logger,
 		mlockPlugins:    c.enableMlock,
+		restartPolicy:   c.pluginRestartPolicy,
 		wrapper:         logical.StaticSystemView{VersionString: version.GetVersion().Version},
 	}
 
@@ -183,6 +189,8 @@ func (c *Core) setupPluginCatalog(ctx context.Context) error {
 		return err
 	}
 
+	go c.pluginCatalog.monitorProcesses(c.activeContext)
+
 	if c.logger.IsInfo() {
 		c.logger.Info("successfully setup plugin catalog", "plugin-directory", c.pluginDirectory)
 	}
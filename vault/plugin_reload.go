@@ -249,6 +249,11 @@ func (c *Core) reloadBackendCommon(ctx context.Context, entry *MountEntry, isAut
 				return err
 			}
 			re.loginPaths.Store(loginPathsEntry)
+			limiter, err := newRequestLimiter(paths)
+			if err != nil {
+				return err
+			}
+			re.limiter.Store(limiter)
 		}
 	}
 
@@ -0,0 +1,287 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/hashicorp/go-plugin"
+	"github.com/hashicorp/vault/helper/hostutil"
+)
+
+const (
+	// defaultPluginRestartBackoff is the delay between the first and second
+	// restarts of a plugin when no backoff is configured.
+	defaultPluginRestartBackoff = 30 * time.Second
+
+	// maxPluginRestartBackoff caps the delay between restarts of a plugin.
+	maxPluginRestartBackoff = 10 * time.Minute
+
+	// pluginRestartResetInterval is the time after which the restarts of a
+	// plugin are no longer considered consecutive.
+	pluginRestartResetInterval = time.Hour
+)
+
+// pluginMonitorInterval is the interval at which the resource usage of
+// external plugin processes is collected.
+var pluginMonitorInterval = 10 * time.Second
+
+// PluginRestartPolicy configures the recycling of external plugin processes
+// whose memory usage exceeds a limit, before they exhaust the memory of the
+// host.
+type PluginRestartPolicy struct {
+	// MaxMemory is the resident memory size, in bytes, above which a plugin
+	// process is restarted. Zero disables the restarts.
+	MaxMemory uint64
+
+	// MaxRestarts is the number of consecutive restarts of a plugin after
+	// which its process is left running. Zero means no limit.
+	MaxRestarts int
+
+	// Backoff is the delay between the first and second consecutive restarts
+	// of a plugin, doubled for each subsequent restart.
+	Backoff time.Duration
+}
+
+// backoff returns the delay to wait after the given number of consecutive
+// restarts before restarting again.
+func (p PluginRestartPolicy) backoff(restarts int) time.Duration {
+	if restarts == 0 {
+		return 0
+	}
+
+	backoff := p.Backoff
+	if backoff <= 0 {
+		backoff = defaultPluginRestartBackoff
+	}
+	for i := 1; i < restarts && backoff < maxPluginRestartBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxPluginRestartBackoff {
+		backoff = maxPluginRestartBackoff
+	}
+	return backoff
+}
+
+// pluginRestartState tracks the restarts of the processes of a plugin.
+type pluginRestartState struct {
+	// consecutive is the number of restarts since the restarts were last
+	// reset, and total the number of restarts since the catalog was set up.
+	consecutive int
+	total       int
+	lastRestart time.Time
+
+	// exhausted is set once the maximum number of consecutive restarts was
+	// reached and reported.
+	exhausted bool
+}
+
+// pluginProcess is a running external plugin process, which serves one
+// connection, or all the connections of a multiplexed plugin.
+type pluginProcess struct {
+	key         externalPluginsKey
+	extPlugin   *externalPlugin
+	client      *plugin.Client
+	pid         int
+	connections int
+}
+
+// PluginProcessStats holds the resource usage and restarts of an external
+// plugin process.
+type PluginProcessStats struct {
+	Name        string
+	Type        string
+	Version     string
+	PID         int
+	Multiplexed bool
+	Connections int
+
+	// Usage is nil if the usage of the process couldn't be collected.
+	Usage *hostutil.ProcessUsage
+
+	// Restarts is the number of times the processes of the plugin were
+	// restarted by the restart policy since the catalog was set up.
+	Restarts    int
+	LastRestart time.Time
+}
+
+// processes returns the running external plugin processes.
+func (c *PluginCatalog) processes() []pluginProcess {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var processes []pluginProcess
+	for key, extPlugin := range c.externalPlugins {
+		byPID := make(map[int]*pluginProcess)
+		for _, pc := range extPlugin.connections {
+			if pc.client == nil || pc.client.Exited() {
+				continue
+			}
+			if p, ok := byPID[pc.pid]; ok {
+				p.connections++
+				continue
+			}
+			byPID[pc.pid] = &pluginProcess{
+				key:         key,
+				extPlugin:   extPlugin,
+				client:      pc.client,
+				pid:         pc.pid,
+				connections: 1,
+			}
+		}
+		for _, p := range byPID {
+			processes = append(processes, *p)
+		}
+	}
+
+	sort.Slice(processes, func(i, j int) bool {
+		a, b := processes[i].key, processes[j].key
+		switch {
+		case a.name != b.name:
+			return a.name < b.name
+		case a.typ != b.typ:
+			return a.typ < b.typ
+		case a.version != b.version:
+			return a.version < b.version
+		}
+		return processes[i].pid < processes[j].pid
+	})
+	return processes
+}
+
+// ProcessStats returns the resource usage of the running external plugin
+// processes. Builtin plugins run within the Vault process and aren't
+// reported.
+func (c *PluginCatalog) ProcessStats(ctx context.Context) []PluginProcessStats {
+	processes := c.processes()
+
+	stats := make([]PluginProcessStats, 0, len(processes))
+	for _, p := range processes {
+		usage, err := hostutil.CollectProcessUsage(ctx, int32(p.pid))
+		if err != nil {
+			c.logger.Debug("failed to collect the usage of a plugin process", "plugin", p.key.name, "pid", p.pid, "error", err)
+		}
+		stats = append(stats, c.processStats(p, usage))
+	}
+	return stats
+}
+
+func (c *PluginCatalog) processStats(p pluginProcess, usage *hostutil.ProcessUsage) PluginProcessStats {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	stats := PluginProcessStats{
+		Name:        p.key.name,
+		Type:        p.key.typ.String(),
+		Version:     p.key.version,
+		PID:         p.pid,
+		Multiplexed: p.extPlugin.multiplexingSupport,
+		Connections: p.connections,
+		Usage:       usage,
+	}
+	if state, ok := c.restarts[p.key]; ok {
+		stats.Restarts = state.total
+		stats.LastRestart = state.lastRestart
+	}
+	return stats
+}
+
+// monitorProcesses periodically emits the resource usage of the external
+// plugin processes, and restarts the processes exceeding the memory limit of
+// the restart policy, until the context is done.
+func (c *PluginCatalog) monitorProcesses(ctx context.Context) {
+	ticker := time.NewTicker(pluginMonitorInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for _, p := range c.processes() {
+			usage, err := hostutil.CollectProcessUsage(ctx, int32(p.pid))
+			if err != nil {
+				c.logger.Trace("failed to collect the usage of a plugin process", "plugin", p.key.name, "pid", p.pid, "error", err)
+			}
+			if usage == nil {
+				continue
+			}
+
+			labels := []metrics.Label{
+				{Name: "plugin_name", Value: p.key.name},
+				{Name: "plugin_type", Value: p.key.typ.String()},
+				{Name: "plugin_version", Value: p.key.version},
+			}
+			metrics.SetGaugeWithLabels([]string{"plugin", "process", "memory_rss"}, float32(usage.MemoryRSS), labels)
+			metrics.SetGaugeWithLabels([]string{"plugin", "process", "cpu_seconds"}, float32(usage.CPUUserSeconds+usage.CPUSystemSeconds), labels)
+
+			if c.restartPolicy.MaxMemory > 0 && usage.MemoryRSS > c.restartPolicy.MaxMemory {
+				c.restartProcess(p, usage.MemoryRSS, labels)
+			}
+		}
+	}
+}
+
+// restartProcess kills a plugin process exceeding the memory limit of the
+// restart policy, unless the backoff of the plugin hasn't elapsed or it was
+// restarted too many times. The backends using the process spawn a new one
+// when they handle their next request.
+func (c *PluginCatalog) restartProcess(p pluginProcess, rss uint64, labels []metrics.Label) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	// The process may have been reloaded since it was listed.
+	if c.externalPlugins[p.key] != p.extPlugin || p.client.Exited() {
+		return
+	}
+
+	if c.restarts == nil {
+		c.restarts = make(map[externalPluginsKey]*pluginRestartState)
+	}
+	state, ok := c.restarts[p.key]
+	if !ok {
+		state = &pluginRestartState{}
+		c.restarts[p.key] = state
+	}
+
+	now := time.Now()
+	if !state.lastRestart.IsZero() && now.Sub(state.lastRestart) > pluginRestartResetInterval {
+		state.consecutive = 0
+		state.exhausted = false
+	}
+
+	policy := c.restartPolicy
+	if policy.MaxRestarts > 0 && state.consecutive >= policy.MaxRestarts {
+		if !state.exhausted {
+			state.exhausted = true
+			c.logger.Error("plugin process exceeds its memory limit, but was restarted too many times",
+				"plugin", p.key.name, "pid", p.pid, "memory_rss", rss, "max_memory", policy.MaxMemory, "restarts", state.consecutive)
+		}
+		return
+	}
+	if now.Sub(state.lastRestart) < policy.backoff(state.consecutive) {
+		return
+	}
+
+	c.logger.Warn("restarting plugin process exceeding its memory limit",
+		"plugin", p.key.name, "pid", p.pid, "memory_rss", rss, "max_memory", policy.MaxMemory)
+
+	// A multiplexed process is shared by the connections of the plugin, so
+	// it is forgotten for the next connection to spawn a new process, as on
+	// reload.
+	if p.extPlugin.multiplexingSupport {
+		delete(c.externalPlugins, p.key)
+	}
+	p.client.Kill()
+
+	state.consecutive++
+	state.total++
+	state.lastRestart = now
+	metrics.IncrCounterWithLabels([]string{"plugin", "process", "restarts"}, 1, labels)
+}
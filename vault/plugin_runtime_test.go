@@ -0,0 +1,87 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/helper/testhelpers/corehelpers"
+	"github.com/hashicorp/vault/helper/testhelpers/pluginhelpers"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPluginRestartPolicy_backoff ensures that the delay between restarts
+// doubles with each consecutive restart, up to its cap.
+func TestPluginRestartPolicy_backoff(t *testing.T) {
+	policy := PluginRestartPolicy{Backoff: time.Minute}
+	require.Equal(t, time.Duration(0), policy.backoff(0))
+	require.Equal(t, time.Minute, policy.backoff(1))
+	require.Equal(t, 2*time.Minute, policy.backoff(2))
+	require.Equal(t, 8*time.Minute, policy.backoff(4))
+	require.Equal(t, maxPluginRestartBackoff, policy.backoff(5))
+	require.Equal(t, maxPluginRestartBackoff, policy.backoff(100))
+
+	require.Equal(t, defaultPluginRestartBackoff, PluginRestartPolicy{}.backoff(1))
+}
+
+// TestPluginCatalog_restartProcess ensures that the stats of external plugin
+// processes are reported, and that processes exceeding their memory limit are
+// restarted, at most the configured number of times.
+func TestPluginCatalog_restartProcess(t *testing.T) {
+	pluginDir, cleanup := corehelpers.MakeTestPluginDir(t)
+	t.Cleanup(func() { cleanup(t) })
+	plugin := pluginhelpers.CompilePlugin(t, consts.PluginTypeSecrets, "v1.0.0", pluginDir)
+
+	c := TestCoreWithSealAndUI(t, &CoreConfig{
+		BuiltinRegistry: corehelpers.NewMockBuiltinRegistry(),
+		PluginDirectory: pluginDir,
+	})
+	c, _, root := testCoreUnsealed(t, c)
+	registerPlugin(t, c.systemBackend, plugin.Name, consts.PluginTypeSecrets.String(), "v1.0.0", plugin.Sha256, plugin.FileName)
+	mountPlugin(t, c.systemBackend, plugin.Name, consts.PluginTypeSecrets, "v1.0.0", "")
+
+	readInternal := func() {
+		t.Helper()
+		req := logical.TestRequest(t, logical.ReadOperation, "foo/internal")
+		req.ClientToken = root
+		resp, err := c.HandleRequest(namespace.RootContext(nil), req)
+		require.NoError(t, err)
+		require.False(t, resp.IsError(), "%#v", resp)
+	}
+	readInternal()
+
+	resp, err := c.systemBackend.HandleRequest(namespace.RootContext(nil), logical.TestRequest(t, logical.ReadOperation, "plugins/runtime/stats"))
+	require.NoError(t, err)
+	processes := resp.Data["processes"].([]map[string]interface{})
+	require.Len(t, processes, 1)
+	require.Equal(t, plugin.Name, processes[0]["name"])
+	require.Equal(t, "v1.0.0", processes[0]["version"])
+	require.NotZero(t, processes[0]["pid"])
+	require.Equal(t, 0, processes[0]["restarts"])
+
+	c.pluginCatalog.restartPolicy = PluginRestartPolicy{MaxMemory: 1, MaxRestarts: 1}
+	restarted := c.pluginCatalog.processes()
+	require.Len(t, restarted, 1)
+	c.pluginCatalog.restartProcess(restarted[0], 2, nil)
+	require.True(t, restarted[0].client.Exited())
+
+	// The backend spawns a new process on its next request.
+	readInternal()
+	processes2 := c.pluginCatalog.processes()
+	require.Len(t, processes2, 1)
+	require.NotEqual(t, restarted[0].pid, processes2[0].pid)
+
+	// The maximum number of consecutive restarts was reached.
+	c.pluginCatalog.restartProcess(processes2[0], 2, nil)
+	require.False(t, processes2[0].client.Exited())
+
+	stats := c.pluginCatalog.ProcessStats(namespace.RootContext(nil))
+	require.Len(t, stats, 1)
+	require.Equal(t, 1, stats[0].Restarts)
+	require.False(t, stats[0].LastRestart.IsZero())
+}
@@ -106,6 +106,10 @@ type Policy struct {
 	Type      PolicyType
 	Templated bool
 	namespace *namespace.Namespace
+
+	// author identifies the requester writing the policy, and is recorded in
+	// the policy's version history. It is not persisted with the policy.
+	author *PolicyVersionAuthor
 }
 
 // ShallowClone returns a shallow clone of the policy. This should not be used
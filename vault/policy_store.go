@@ -29,6 +29,10 @@ const (
 	policyRGPSubPath = "policy-rgp/"
 	policyEGPSubPath = "policy-egp/"
 
+	// policyACLVersionsSubPath is the sub-path used to store the version
+	// history of ACL policies.
+	policyACLVersionsSubPath = "policy-acl-versions/"
+
 	// policyCacheSize is the number of policies that are kept cached
 	policyCacheSize = 1024
 
@@ -183,6 +187,9 @@ type PolicyStore struct {
 	rgpView *BarrierView
 	egpView *BarrierView
 
+	// aclVersionsView stores the version history of ACL policies
+	aclVersionsView *BarrierView
+
 	tokenPoliciesLRU *lru.TwoQueueCache
 	egpLRU           *lru.TwoQueueCache
 
@@ -212,12 +219,13 @@ type PolicyEntry struct {
 // using a given view. It used used to durable store and manage named policy.
 func NewPolicyStore(ctx context.Context, core *Core, baseView *BarrierView, system logical.SystemView, logger log.Logger) (*PolicyStore, error) {
 	ps := &PolicyStore{
-		aclView:    baseView.SubView(policyACLSubPath),
-		rgpView:    baseView.SubView(policyRGPSubPath),
-		egpView:    baseView.SubView(policyEGPSubPath),
-		modifyLock: new(sync.RWMutex),
-		logger:     logger,
-		core:       core,
+		aclView:         baseView.SubView(policyACLSubPath),
+		rgpView:         baseView.SubView(policyRGPSubPath),
+		egpView:         baseView.SubView(policyEGPSubPath),
+		aclVersionsView: baseView.SubView(policyACLVersionsSubPath),
+		modifyLock:      new(sync.RWMutex),
+		logger:          logger,
+		core:            core,
 	}
 
 	ps.extraInit()
@@ -358,6 +366,9 @@ func (ps *PolicyStore) SetPolicy(ctx context.Context, p *Policy) error {
 	if strutil.StrListContains(immutablePolicies, p.Name) {
 		return fmt.Errorf("cannot update %q policy", p.Name)
 	}
+	if strings.HasPrefix(p.Name, mountOwnerPolicyPrefix) {
+		return fmt.Errorf("cannot update %q policy, the policies of mount owners are generated", p.Name)
+	}
 
 	return ps.setPolicyInternal(ctx, p)
 }
@@ -402,10 +413,19 @@ func (ps *PolicyStore) setPolicyInternal(ctx context.Context, p *Policy) error {
 			return fmt.Errorf("cannot reuse policy names between ACLs and RGPs")
 		}
 
+		previous, err := view.Get(ctx, entry.Key)
+		if err != nil {
+			return fmt.Errorf("failed to read existing policy: %w", err)
+		}
+
 		if err := view.Put(ctx, entry); err != nil {
 			return fmt.Errorf("failed to persist policy: %w", err)
 		}
 
+		if err := ps.recordACLPolicyVersion(ctx, p, previous); err != nil {
+			return fmt.Errorf("failed to record policy version: %w", err)
+		}
+
 		ps.policyTypeMap.Store(index, PolicyTypeACL)
 
 		if ps.tokenPoliciesLRU != nil {
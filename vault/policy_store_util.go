@@ -23,6 +23,10 @@ func (ps *PolicyStore) getACLView(*namespace.Namespace) *BarrierView {
 	return ps.aclView
 }
 
+func (ps *PolicyStore) getACLVersionsView(*namespace.Namespace) *BarrierView {
+	return ps.aclVersionsView
+}
+
 func (ps *PolicyStore) getRGPView(ns *namespace.Namespace) *BarrierView {
 	return ps.rgpView
 }
@@ -0,0 +1,146 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// maxPolicyVersions is the number of versions of an ACL policy that are kept
+// in its history. Older versions are discarded.
+const maxPolicyVersions = 20
+
+// PolicyVersionAuthor identifies the requester that wrote a version of a
+// policy.
+type PolicyVersionAuthor struct {
+	TokenAccessor string `json:"token_accessor"`
+	EntityID      string `json:"entity_id"`
+	DisplayName   string `json:"display_name"`
+}
+
+// PolicyVersion is a single version in the history of an ACL policy.
+type PolicyVersion struct {
+	Version     int                  `json:"version"`
+	Raw         string               `json:"raw"`
+	CreatedTime time.Time            `json:"created_time"`
+	Author      *PolicyVersionAuthor `json:"author,omitempty"`
+}
+
+// policyVersionHistory is the storage entry holding the versions of an ACL
+// policy, oldest first. The history outlives the deletion of the policy so
+// that a deleted policy can be restored.
+type policyVersionHistory struct {
+	Versions []*PolicyVersion `json:"versions"`
+}
+
+// policyVersionAuthorFromRequest returns the author of a policy write made by
+// the given request.
+func policyVersionAuthorFromRequest(req *logical.Request) *PolicyVersionAuthor {
+	return &PolicyVersionAuthor{
+		TokenAccessor: req.ClientTokenAccessor,
+		EntityID:      req.EntityID,
+		DisplayName:   req.DisplayName,
+	}
+}
+
+func (ps *PolicyStore) getACLPolicyVersionHistory(ctx context.Context, view *BarrierView, name string) (*policyVersionHistory, error) {
+	entry, err := view.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &policyVersionHistory{}
+	if entry == nil {
+		return history, nil
+	}
+	if err := entry.DecodeJSON(history); err != nil {
+		return nil, fmt.Errorf("failed to decode policy version history: %w", err)
+	}
+	return history, nil
+}
+
+// recordACLPolicyVersion appends the given policy to its version history. The
+// previous storage entry of the policy, if any, seeds the history of policies
+// written before versions were tracked. The caller must hold modifyLock.
+func (ps *PolicyStore) recordACLPolicyVersion(ctx context.Context, p *Policy, previous *logical.StorageEntry) error {
+	view := ps.getACLVersionsView(p.namespace)
+	history, err := ps.getACLPolicyVersionHistory(ctx, view, p.Name)
+	if err != nil {
+		return err
+	}
+
+	if len(history.Versions) == 0 && previous != nil {
+		var policyEntry PolicyEntry
+		if err := previous.DecodeJSON(&policyEntry); err != nil {
+			return fmt.Errorf("failed to decode existing policy: %w", err)
+		}
+		history.Versions = append(history.Versions, &PolicyVersion{
+			Version: 1,
+			Raw:     policyEntry.Raw,
+		})
+	}
+
+	version := 1
+	if n := len(history.Versions); n > 0 {
+		latest := history.Versions[n-1]
+		if latest.Raw == p.Raw {
+			return nil
+		}
+		version = latest.Version + 1
+	}
+
+	history.Versions = append(history.Versions, &PolicyVersion{
+		Version:     version,
+		Raw:         p.Raw,
+		CreatedTime: time.Now().UTC(),
+		Author:      p.author,
+	})
+	if len(history.Versions) > maxPolicyVersions {
+		history.Versions = history.Versions[len(history.Versions)-maxPolicyVersions:]
+	}
+
+	entry, err := logical.StorageEntryJSON(p.Name, history)
+	if err != nil {
+		return fmt.Errorf("failed to create entry: %w", err)
+	}
+	return view.Put(ctx, entry)
+}
+
+// ListACLPolicyVersions returns the retained versions of the named ACL
+// policy, oldest first.
+func (ps *PolicyStore) ListACLPolicyVersions(ctx context.Context, name string) ([]*PolicyVersion, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ps.modifyLock.RLock()
+	defer ps.modifyLock.RUnlock()
+
+	history, err := ps.getACLPolicyVersionHistory(ctx, ps.getACLVersionsView(ns), ps.sanitizeName(name))
+	if err != nil {
+		return nil, err
+	}
+	return history.Versions, nil
+}
+
+// GetACLPolicyVersion returns the given version of the named ACL policy, or
+// nil if it is not retained.
+func (ps *PolicyStore) GetACLPolicyVersion(ctx context.Context, name string, version int) (*PolicyVersion, error) {
+	versions, err := ps.ListACLPolicyVersions(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range versions {
+		if v.Version == version {
+			return v, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,433 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/go-cleanhttp"
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/go-secure-stdlib/strutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	// pprofCaptureConfigPath is the path, relative to the system barrier
+	// view, where the continuous profiling configuration is stored.
+	pprofCaptureConfigPath = "config/pprof-capture"
+
+	// pprofCaptureSubPath is the path, relative to the system barrier view,
+	// under which captured profiles are stored.
+	pprofCaptureSubPath = "pprof-captures/"
+
+	pprofCaptureTimeFormat = "20060102T150405Z"
+
+	defaultPprofCaptureInterval    = 10 * time.Minute
+	defaultPprofCaptureCPUDuration = 10 * time.Second
+	defaultPprofCaptureRetention   = 24
+
+	// maxPprofCaptureRetention bounds the number of stored captures so that
+	// a misconfiguration cannot fill up storage.
+	maxPprofCaptureRetention = 256
+)
+
+// pprofCaptureProfileTypes are the profiles that may be captured. "cpu" is
+// special cased as it requires sampling for a duration; the others are
+// looked up by name in the runtime/pprof registry.
+var pprofCaptureProfileTypes = []string{"cpu", "heap", "allocs", "goroutine", "block", "mutex", "threadcreate"}
+
+// PprofCaptureConfig is the persisted configuration for continuous profiling.
+type PprofCaptureConfig struct {
+	Enabled     bool          `json:"enabled"`
+	Interval    time.Duration `json:"interval"`
+	CPUDuration time.Duration `json:"cpu_duration"`
+	Profiles    []string      `json:"profiles"`
+	Retention   int           `json:"retention"`
+	PushURL     string        `json:"push_url,omitempty"`
+}
+
+// pprofCaptureInfo is the metadata stored alongside a captured profile.
+type pprofCaptureInfo struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	CapturedAt time.Time `json:"captured_at"`
+	Size       int       `json:"size"`
+	Profile    []byte    `json:"profile"`
+}
+
+func defaultPprofCaptureConfig() *PprofCaptureConfig {
+	return &PprofCaptureConfig{
+		Interval:    defaultPprofCaptureInterval,
+		CPUDuration: defaultPprofCaptureCPUDuration,
+		Profiles:    []string{"cpu", "heap", "goroutine"},
+		Retention:   defaultPprofCaptureRetention,
+	}
+}
+
+func (p *PprofCaptureConfig) validate() error {
+	if p.Interval <= 0 {
+		return errors.New("interval must be positive")
+	}
+	if p.CPUDuration <= 0 {
+		return errors.New("cpu_duration must be positive")
+	}
+	if p.CPUDuration >= p.Interval {
+		return errors.New("cpu_duration must be less than interval")
+	}
+	if p.Retention <= 0 || p.Retention > maxPprofCaptureRetention {
+		return fmt.Errorf("retention must be between 1 and %d", maxPprofCaptureRetention)
+	}
+	if len(p.Profiles) == 0 {
+		return errors.New("at least one profile type must be specified")
+	}
+	for _, profile := range p.Profiles {
+		if !strutil.StrListContains(pprofCaptureProfileTypes, profile) {
+			return fmt.Errorf("unsupported profile type %q", profile)
+		}
+	}
+	if p.PushURL != "" {
+		u, err := url.Parse(p.PushURL)
+		if err != nil {
+			return fmt.Errorf("invalid push_url: %w", err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return errors.New("push_url must use the http or https scheme")
+		}
+	}
+	return nil
+}
+
+// PprofCapturer periodically captures runtime profiles on the active node and
+// stores them in a bounded location in storage, optionally pushing them to a
+// pprof-compatible collection endpoint as well.
+type PprofCapturer struct {
+	core   *Core
+	logger log.Logger
+	view   *BarrierView
+	client *http.Client
+
+	// now returns the current time; overridable in tests.
+	now func() time.Time
+
+	l      sync.Mutex
+	config *PprofCaptureConfig
+	stopCh chan struct{}
+	doneCh chan struct{}
+
+	// captureLock serializes captures so that a capture triggered by the
+	// ticker never overlaps one still running.
+	captureLock sync.Mutex
+}
+
+// setupPprofCapture loads the continuous profiling configuration and, if
+// enabled, starts the capture loop.
+func (c *Core) setupPprofCapture(ctx context.Context) error {
+	capturer := &PprofCapturer{
+		core:   c,
+		logger: c.baseLogger.Named("pprof-capture"),
+		view:   c.systemBarrierView.SubView(pprofCaptureSubPath),
+		client: cleanhttp.DefaultClient(),
+		now:    time.Now,
+		config: defaultPprofCaptureConfig(),
+	}
+	c.AddLogger(capturer.logger)
+
+	entry, err := c.systemBarrierView.Get(ctx, pprofCaptureConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to read pprof capture config: %w", err)
+	}
+	if entry != nil {
+		config := defaultPprofCaptureConfig()
+		if err := entry.DecodeJSON(config); err != nil {
+			return fmt.Errorf("failed to decode pprof capture config: %w", err)
+		}
+		capturer.config = config
+	}
+
+	c.pprofCapture = capturer
+	if capturer.config.Enabled {
+		capturer.start()
+	}
+	return nil
+}
+
+// stopPprofCapture stops the capture loop, if running.
+func (c *Core) stopPprofCapture() {
+	if c.pprofCapture != nil {
+		c.pprofCapture.stop()
+		c.pprofCapture = nil
+	}
+}
+
+// Config returns a copy of the current configuration.
+func (p *PprofCapturer) Config() *PprofCaptureConfig {
+	p.l.Lock()
+	defer p.l.Unlock()
+
+	config := *p.config
+	config.Profiles = append([]string(nil), p.config.Profiles...)
+	return &config
+}
+
+// SetConfig validates and persists the given configuration, restarting the
+// capture loop to pick up the new settings.
+func (p *PprofCapturer) SetConfig(ctx context.Context, config *PprofCaptureConfig) error {
+	if err := config.validate(); err != nil {
+		return err
+	}
+
+	entry, err := logical.StorageEntryJSON(pprofCaptureConfigPath, config)
+	if err != nil {
+		return fmt.Errorf("failed to create pprof capture config entry: %w", err)
+	}
+	if err := p.core.systemBarrierView.Put(ctx, entry); err != nil {
+		return fmt.Errorf("failed to save pprof capture config: %w", err)
+	}
+
+	p.stop()
+
+	p.l.Lock()
+	p.config = config
+	p.l.Unlock()
+
+	if config.Enabled {
+		p.start()
+	}
+
+	// Lowering the retention should take effect immediately rather than
+	// waiting for the next capture.
+	return p.prune(ctx, config.Retention)
+}
+
+func (p *PprofCapturer) start() {
+	p.l.Lock()
+	defer p.l.Unlock()
+
+	if p.stopCh != nil {
+		return
+	}
+
+	p.stopCh = make(chan struct{})
+	p.doneCh = make(chan struct{})
+	go p.run(p.config.Interval, p.stopCh, p.doneCh)
+}
+
+func (p *PprofCapturer) stop() {
+	p.l.Lock()
+	stopCh, doneCh := p.stopCh, p.doneCh
+	p.stopCh, p.doneCh = nil, nil
+	p.l.Unlock()
+
+	if stopCh != nil {
+		close(stopCh)
+		<-doneCh
+	}
+}
+
+func (p *PprofCapturer) run(interval time.Duration, stopCh, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	p.logger.Info("starting continuous profiling", "interval", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			p.logger.Info("stopping continuous profiling")
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithCancel(p.core.activeContext)
+			go func() {
+				select {
+				case <-stopCh:
+					cancel()
+				case <-ctx.Done():
+				}
+			}()
+			if err := p.Capture(ctx); err != nil {
+				p.logger.Error("failed to capture profiles", "error", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// Capture takes one capture of each configured profile, stores them and
+// prunes the oldest stored captures beyond the configured retention.
+func (p *PprofCapturer) Capture(ctx context.Context) error {
+	p.captureLock.Lock()
+	defer p.captureLock.Unlock()
+
+	config := p.Config()
+	now := p.now().UTC()
+
+	var retErr *multierror.Error
+	for _, profileType := range config.Profiles {
+		data, err := p.captureProfile(ctx, profileType, config.CPUDuration)
+		if err != nil {
+			metrics.IncrCounter([]string{"core", "pprof_capture", "failure"}, 1)
+			retErr = multierror.Append(retErr, fmt.Errorf("failed to capture %s profile: %w", profileType, err))
+			continue
+		}
+
+		info := &pprofCaptureInfo{
+			ID:         fmt.Sprintf("%s-%s", now.Format(pprofCaptureTimeFormat), profileType),
+			Type:       profileType,
+			CapturedAt: now,
+			Size:       len(data),
+			Profile:    data,
+		}
+		entry, err := logical.StorageEntryJSON(info.ID, info)
+		if err != nil {
+			retErr = multierror.Append(retErr, err)
+			continue
+		}
+		if err := p.view.Put(ctx, entry); err != nil {
+			retErr = multierror.Append(retErr, fmt.Errorf("failed to store %s profile: %w", profileType, err))
+			continue
+		}
+		metrics.IncrCounter([]string{"core", "pprof_capture", "success"}, 1)
+
+		if config.PushURL != "" {
+			if err := p.push(ctx, config.PushURL, info); err != nil {
+				p.logger.Warn("failed to push profile", "type", profileType, "error", err)
+			}
+		}
+	}
+
+	if err := p.prune(ctx, config.Retention); err != nil {
+		retErr = multierror.Append(retErr, err)
+	}
+	return retErr.ErrorOrNil()
+}
+
+func (p *PprofCapturer) captureProfile(ctx context.Context, profileType string, cpuDuration time.Duration) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if profileType == "cpu" {
+		// This fails if a CPU profile is already being taken, e.g. via
+		// sys/pprof/profile, in which case we simply skip this capture.
+		if err := pprof.StartCPUProfile(&buf); err != nil {
+			return nil, err
+		}
+		select {
+		case <-time.After(cpuDuration):
+		case <-ctx.Done():
+		}
+		pprof.StopCPUProfile()
+		return buf.Bytes(), ctx.Err()
+	}
+
+	profile := pprof.Lookup(profileType)
+	if profile == nil {
+		return nil, fmt.Errorf("unknown profile %q", profileType)
+	}
+	if err := profile.WriteTo(&buf, 0); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// push sends the profile to a pprof-compatible collection endpoint.
+func (p *PprofCapturer) push(ctx context.Context, pushURL string, info *pprofCaptureInfo) error {
+	u, err := url.Parse(pushURL)
+	if err != nil {
+		return err
+	}
+	q := u.Query()
+	q.Set("type", info.Type)
+	q.Set("captured_at", info.CapturedAt.Format(time.RFC3339))
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(info.Profile))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// prune removes the oldest captures so that at most retention capture
+// rounds remain in storage.
+func (p *PprofCapturer) prune(ctx context.Context, retention int) error {
+	ids, err := p.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Group by capture time so that a single round of captures is retained
+	// or removed as a whole.
+	var rounds []string
+	for _, id := range ids {
+		round, _, _ := strings.Cut(id, "-")
+		if len(rounds) == 0 || rounds[len(rounds)-1] != round {
+			rounds = append(rounds, round)
+		}
+	}
+	if len(rounds) <= retention {
+		return nil
+	}
+	cutoff := rounds[len(rounds)-retention]
+
+	for _, id := range ids {
+		if id >= cutoff {
+			break
+		}
+		if err := p.view.Delete(ctx, id); err != nil {
+			return fmt.Errorf("failed to delete profile %q: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// List returns the IDs of the stored captures, oldest first.
+func (p *PprofCapturer) List(ctx context.Context) ([]string, error) {
+	ids, err := p.view.List(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Get returns the stored capture with the given ID, or nil if it does not
+// exist.
+func (p *PprofCapturer) Get(ctx context.Context, id string) (*pprofCaptureInfo, error) {
+	entry, err := p.view.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile: %w", err)
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var info pprofCaptureInfo
+	if err := entry.DecodeJSON(&info); err != nil {
+		return nil, fmt.Errorf("failed to decode profile: %w", err)
+	}
+	return &info, nil
+}
@@ -0,0 +1,136 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// TestPprofCapture_ConfigValidation verifies that invalid configurations are
+// rejected by the system backend.
+func TestPprofCapture_ConfigValidation(t *testing.T) {
+	_, b, _ := testCoreSystemBackend(t)
+
+	testCases := map[string]map[string]interface{}{
+		"bad profile":        {"profiles": "cpu,bogus"},
+		"zero retention":     {"retention": 0},
+		"excess retention":   {"retention": maxPprofCaptureRetention + 1},
+		"cpu exceeds period": {"interval": 10, "cpu_duration": 20},
+		"bad push url":       {"push_url": "ftp://example.com"},
+	}
+
+	for name, data := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := logical.TestRequest(t, logical.UpdateOperation, "pprof/capture/config")
+			req.Data = data
+			resp, err := b.HandleRequest(namespace.RootContext(nil), req)
+			if err == nil || !resp.IsError() {
+				t.Fatalf("expected error, got resp: %#v", resp)
+			}
+		})
+	}
+}
+
+// TestPprofCapture_CaptureAndRetention verifies that captures are stored,
+// listed and downloadable, and that retention prunes the oldest rounds.
+func TestPprofCapture_CaptureAndRetention(t *testing.T) {
+	c, b, _ := testCoreSystemBackend(t)
+	ctx := namespace.RootContext(nil)
+
+	var pushed int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if len(body) == 0 || r.URL.Query().Get("type") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		atomic.AddInt32(&pushed, 1)
+	}))
+	defer srv.Close()
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "pprof/capture/config")
+	req.Data = map[string]interface{}{
+		"profiles":  "heap,goroutine",
+		"retention": 2,
+		"push_url":  srv.URL,
+	}
+	resp, err := b.HandleRequest(ctx, req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v, resp: %#v", err, resp)
+	}
+
+	// Capture IDs have a one second resolution, so space the rounds out
+	// with a fake clock rather than sleeping.
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		c.pprofCapture.now = func() time.Time { return now.Add(time.Duration(i) * time.Minute) }
+		if err := c.pprofCapture.Capture(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := atomic.LoadInt32(&pushed); got != 6 {
+		t.Fatalf("expected 6 pushed profiles, got %d", got)
+	}
+
+	req = logical.TestRequest(t, logical.ListOperation, "pprof/capture/profiles")
+	resp, err = b.HandleRequest(ctx, req)
+	if err != nil || resp.IsError() {
+		t.Fatalf("err: %v, resp: %#v", err, resp)
+	}
+	keys := resp.Data["keys"].([]string)
+	if len(keys) != 4 {
+		t.Fatalf("expected 2 rounds of 2 profiles, got %v", keys)
+	}
+	keyInfo := resp.Data["key_info"].(map[string]interface{})
+	if typ := keyInfo[keys[0]].(map[string]interface{})["type"]; typ != "goroutine" && typ != "heap" {
+		t.Fatalf("unexpected type %v", typ)
+	}
+
+	req = logical.TestRequest(t, logical.ReadOperation, "pprof/capture/profiles/"+keys[0])
+	resp, err = b.HandleRequest(ctx, req)
+	if err != nil || resp.IsError() {
+		t.Fatalf("err: %v, resp: %#v", err, resp)
+	}
+	if body, ok := resp.Data[logical.HTTPRawBody].([]byte); !ok || len(body) == 0 {
+		t.Fatalf("expected profile body, got %#v", resp.Data)
+	}
+}
+
+// TestPprofCapture_PersistsAcrossSeal verifies that the configuration is
+// reloaded on unseal.
+func TestPprofCapture_PersistsAcrossSeal(t *testing.T) {
+	c, keys, _ := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	config := c.pprofCapture.Config()
+	config.Enabled = true
+	config.Interval = time.Hour
+	config.Profiles = []string{"heap"}
+	if err := c.pprofCapture.SetConfig(ctx, config); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.sealInternal(); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range keys {
+		if _, err := TestCoreUnseal(c, TestKeyCopy(key)); err != nil {
+			t.Fatalf("unseal err: %s", err)
+		}
+	}
+
+	got := c.pprofCapture.Config()
+	if !got.Enabled || got.Interval != time.Hour || len(got.Profiles) != 1 || got.Profiles[0] != "heap" {
+		t.Fatalf("unexpected config after unseal: %#v", got)
+	}
+}
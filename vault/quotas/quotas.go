@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"path"
+	"sort"
 	"strings"
 
 	log "github.com/hashicorp/go-hclog"
@@ -213,6 +214,32 @@ type Quota interface {
 
 	// handleRemount updates the mount and namesapce paths of the quota
 	handleRemount(string, string)
+
+	// isInheritable returns whether a quota on a namespace applies to the
+	// descendant namespaces which have no quota of their own
+	isInheritable() bool
+
+	// Usage returns the consumption of the quota per namespace
+	Usage() []*Usage
+}
+
+// Usage is the consumption of a quota rule by the requests of a namespace,
+// since the rule was loaded on the node.
+type Usage struct {
+	// Name is the name of the quota rule
+	Name string
+
+	// Type is the type of the quota rule
+	Type Type
+
+	// NamespacePath is the path of the namespace of the requests
+	NamespacePath string
+
+	// Allowed is the number of requests allowed by the quota rule
+	Allowed uint64
+
+	// Rejected is the number of requests rejected by the quota rule
+	Rejected uint64
 }
 
 // Response holds information about the result of the Allow() call. The response
@@ -490,7 +517,9 @@ func (m *Manager) QueryQuota(req *Request) (Quota, error) {
 // the quota rule that takes priority.
 //
 // Priority rules are as follows:
-// - namespace specific quota takes precedence over global quota
+// - namespace specific quota takes precedence over inheritable quotas of the
+// ancestor namespaces, the closest ancestor first
+// - inheritable quotas of the ancestor namespaces take precedence over global quota
 // - mount specific quota takes precedence over namespace specific quota
 // - path suffix specific quota takes precedence over mount specific quota
 // - role based quota takes precedence over path suffix/mount specific quota
@@ -580,24 +609,60 @@ func (m *Manager) queryQuota(txn *memdb.Txn, req *Request) (Quota, error) {
 		return quota, nil
 	}
 
-	// If the request belongs to "root" namespace, then we have already looked at
-	// global quotas when fetching namespace specific quota rule. When the request
-	// belongs to a non-root namespace, and when there are no namespace specific
-	// quota rules present, we fallback on the global quotas.
-	if req.NamespacePath == "root" {
-		return nil, nil
+	// When there are no quota rules specific to the namespace of the request,
+	// fall back on the inheritable quotas of its ancestors, from the closest
+	// one up to the global quotas, which are always inherited. If the request
+	// belongs to the "root" namespace, the global quotas have already been
+	// looked at.
+	for nsPath := req.NamespacePath; nsPath != "root"; {
+		nsPath = parentNamespacePath(nsPath)
+		quota, err = quotaFetchFunc(indexNamespace, nsPath, false, false, false)
+		if err != nil {
+			return nil, err
+		}
+		if quota != nil && (nsPath == "root" || quota.isInheritable()) {
+			return quota, nil
+		}
 	}
 
-	// Fetch global quota
-	quota, err = quotaFetchFunc(indexNamespace, "root", false, false, false)
-	if err != nil {
-		return nil, err
+	return nil, nil
+}
+
+// parentNamespacePath returns the path of the parent of the namespace with the
+// given path, which is "root" for the top level namespaces.
+func parentNamespacePath(nsPath string) string {
+	i := strings.LastIndex(strings.TrimSuffix(nsPath, "/"), "/")
+	if i < 0 {
+		return "root"
 	}
-	if quota != nil {
-		return quota, nil
+	return nsPath[:i+1]
+}
+
+// Usage returns the consumption of all the quota rules, per namespace, sorted
+// by namespace path and quota name.
+func (m *Manager) Usage() ([]*Usage, error) {
+	m.dbAndCacheLock.RLock()
+	defer m.dbAndCacheLock.RUnlock()
+
+	txn := m.db.Txn(false)
+	var usages []*Usage
+	for _, qType := range quotaTypes() {
+		iter, err := txn.Get(qType, indexID)
+		if err != nil {
+			return nil, err
+		}
+		for raw := iter.Next(); raw != nil; raw = iter.Next() {
+			usages = append(usages, raw.(Quota).Usage()...)
+		}
 	}
 
-	return nil, nil
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].NamespacePath != usages[j].NamespacePath {
+			return usages[i].NamespacePath < usages[j].NamespacePath
+		}
+		return usages[i].Name < usages[j].Name
+	})
+	return usages, nil
 }
 
 // DeleteQuota removes a quota rule from the db for a given name
@@ -10,6 +10,7 @@ import (
 	"math"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/armon/go-metrics"
@@ -75,6 +76,10 @@ type RateLimitQuota struct {
 	// reaches the rate limit.
 	BlockInterval time.Duration `json:"block_interval"`
 
+	// Inheritable marks a quota on a namespace as applying to the descendant
+	// namespaces which have no quota of their own.
+	Inheritable bool `json:"inheritable"`
+
 	lock                *sync.RWMutex
 	store               limiter.Store
 	logger              log.Logger
@@ -84,6 +89,17 @@ type RateLimitQuota struct {
 	blockedClients      sync.Map
 	purgeBlocked        bool
 	closePurgeBlockedCh chan struct{}
+
+	// usage holds the *rateLimitUsage of each namespace whose requests the
+	// quota was applied to.
+	usage sync.Map
+}
+
+// rateLimitUsage counts the requests of a namespace allowed and rejected by a
+// rate limit quota.
+type rateLimitUsage struct {
+	allowed  atomic.Uint64
+	rejected atomic.Uint64
 }
 
 // NewRateLimitQuota creates a quota checker for imposing limits on the number
@@ -125,6 +141,7 @@ func (q *RateLimitQuota) Clone() Quota {
 		BlockInterval: q.BlockInterval,
 		Rate:          q.Rate,
 		Interval:      q.Interval,
+		Inheritable:   q.Inheritable,
 	}
 	return rlq
 }
@@ -199,6 +216,7 @@ func (rlq *RateLimitQuota) initialize(logger log.Logger, ms *metricsutil.Cluster
 
 	rlq.store = rlStore
 	rlq.blockedClients = sync.Map{}
+	rlq.usage = sync.Map{}
 
 	if rlq.BlockInterval > 0 && !rlq.purgeBlocked {
 		rlq.purgeBlocked = true
@@ -275,6 +293,42 @@ func (rlq *RateLimitQuota) QuotaName() string {
 	return rlq.Name
 }
 
+// isInheritable returns whether the quota applies to the descendants of its
+// namespace
+func (rlq *RateLimitQuota) isInheritable() bool {
+	return rlq.Inheritable
+}
+
+// recordUsage counts a request of the namespace with the given path as allowed
+// or rejected.
+func (rlq *RateLimitQuota) recordUsage(nsPath string, allowed bool) {
+	raw, _ := rlq.usage.LoadOrStore(nsPath, &rateLimitUsage{})
+	usage := raw.(*rateLimitUsage)
+	if allowed {
+		usage.allowed.Add(1)
+	} else {
+		usage.rejected.Add(1)
+	}
+}
+
+// Usage returns the number of requests allowed and rejected by the quota, per
+// namespace, since the quota was loaded.
+func (rlq *RateLimitQuota) Usage() []*Usage {
+	var usages []*Usage
+	rlq.usage.Range(func(key, value interface{}) bool {
+		usage := value.(*rateLimitUsage)
+		usages = append(usages, &Usage{
+			Name:          rlq.Name,
+			Type:          TypeRateLimit,
+			NamespacePath: key.(string),
+			Allowed:       usage.allowed.Load(),
+			Rejected:      usage.rejected.Load(),
+		})
+		return true
+	})
+	return usages
+}
+
 // allow decides if the request is allowed by the quota. An error will be
 // returned if the request ID or address is empty. If the path is exempt, the
 // quota will not be evaluated. Otherwise, the client rate limiter is retrieved
@@ -295,6 +349,7 @@ func (rlq *RateLimitQuota) allow(ctx context.Context, req *Request) (Response, e
 			resp.Headers[httplimit.HeaderRetryAfter] = retryAfter
 			rlq.metricSink.IncrCounterWithLabels([]string{"quota", "rate_limit", "violation"}, 1, []metrics.Label{{"name", rlq.Name}})
 		}
+		rlq.recordUsage(req.NamespacePath, resp.Allowed)
 	}()
 
 	// Check if the client is currently blocked and if so, deny the request. Note,
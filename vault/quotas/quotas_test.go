@@ -117,3 +117,83 @@ func TestQuotas_Precedence(t *testing.T) {
 	checkQuotaFunc(t, "", "", "", "", rateLimitGlobalQuota)
 	checkQuotaFunc(t, "testns/", "", "", "", rateLimitNSQuota)
 }
+
+func TestQuotas_Inheritance(t *testing.T) {
+	qm, err := NewManager(logging.NewVaultLogger(log.Trace), nil, metricsutil.BlackholeSink())
+	require.NoError(t, err)
+
+	setQuotaFunc := func(t *testing.T, name, nsPath string, inheritable bool) Quota {
+		t.Helper()
+		quota := NewRateLimitQuota(name, nsPath, "", "", "", 10, time.Second, 0)
+		quota.Inheritable = inheritable
+		require.NoError(t, qm.SetQuota(context.Background(), TypeRateLimit.String(), quota, true))
+		return quota
+	}
+
+	checkQuotaFunc := func(t *testing.T, nsPath string, expected Quota) {
+		t.Helper()
+		quota, err := qm.QueryQuota(&Request{
+			Type:          TypeRateLimit,
+			NamespacePath: nsPath,
+			MountPath:     "testmount/",
+			Path:          nsPath + "testmount/foo",
+		})
+		require.NoError(t, err)
+
+		if diff := deep.Equal(expected, quota); len(diff) > 0 {
+			t.Fatal(diff)
+		}
+	}
+
+	// Quotas on namespaces don't apply to their descendants by default
+	parentQuota := setQuotaFunc(t, "parent", "parent/", false)
+	checkQuotaFunc(t, "parent/", parentQuota)
+	checkQuotaFunc(t, "parent/child/", nil)
+
+	// Global quotas are always inherited
+	globalQuota := setQuotaFunc(t, "global", "", false)
+	checkQuotaFunc(t, "parent/child/", globalQuota)
+
+	// The closest inheritable ancestor takes precedence
+	parentQuota = parentQuota.Clone()
+	parentQuota.(*RateLimitQuota).Inheritable = true
+	require.NoError(t, qm.SetQuota(context.Background(), TypeRateLimit.String(), parentQuota, true))
+	checkQuotaFunc(t, "parent/child/", parentQuota)
+	checkQuotaFunc(t, "parent/child/grandchild/", parentQuota)
+	checkQuotaFunc(t, "other/", globalQuota)
+
+	childQuota := setQuotaFunc(t, "child", "parent/child/", true)
+	checkQuotaFunc(t, "parent/child/grandchild/", childQuota)
+
+	// Quotas of the namespace itself override inherited ones
+	grandchildQuota := setQuotaFunc(t, "grandchild", "parent/child/grandchild/", false)
+	checkQuotaFunc(t, "parent/child/grandchild/", grandchildQuota)
+	checkQuotaFunc(t, "parent/child/", childQuota)
+}
+
+func TestQuotas_Usage(t *testing.T) {
+	qm, err := NewManager(logging.NewVaultLogger(log.Trace), nil, metricsutil.BlackholeSink())
+	require.NoError(t, err)
+
+	quota := NewRateLimitQuota("parent", "parent/", "", "", "", 2, time.Hour, 0)
+	quota.Inheritable = true
+	require.NoError(t, qm.SetQuota(context.Background(), TypeRateLimit.String(), quota, false))
+
+	for _, nsPath := range []string{"parent/", "parent/", "parent/child/", "other/"} {
+		_, err := qm.ApplyQuota(context.Background(), &Request{
+			Type:          TypeRateLimit,
+			NamespacePath: nsPath,
+			Path:          nsPath + "kv/foo",
+			MountPath:     "kv/",
+			ClientAddress: "127.0.0.1",
+		})
+		require.NoError(t, err)
+	}
+
+	usages, err := qm.Usage()
+	require.NoError(t, err)
+	require.Equal(t, []*Usage{
+		{Name: "parent", Type: TypeRateLimit, NamespacePath: "parent/", Allowed: 2},
+		{Name: "parent", Type: TypeRateLimit, NamespacePath: "parent/child/", Rejected: 1},
+	}, usages)
+}
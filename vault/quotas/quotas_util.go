@@ -70,3 +70,11 @@ func (l LeaseCountQuota) Clone() Quota {
 func (l LeaseCountQuota) handleRemount(mountPath, nsPath string) {
 	panic("implement me")
 }
+
+func (l LeaseCountQuota) isInheritable() bool {
+	panic("implement me")
+}
+
+func (l LeaseCountQuota) Usage() []*Usage {
+	panic("implement me")
+}
@@ -12,6 +12,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/armon/go-metrics"
@@ -202,6 +203,12 @@ func (c *Core) fetchACLTokenEntryAndEntity(ctx context.Context, req *logical.Req
 		}
 	}
 
+	// Tokens bound to a TLS client certificate can only be used by requests
+	// presenting it
+	if !tokenClientCertMatches(te, req) {
+		return nil, nil, nil, nil, logical.ErrPermissionDenied
+	}
+
 	policyNames := make(map[string][]string)
 	// Add tokens policies
 	policyNames[te.NamespaceID] = append(policyNames[te.NamespaceID], te.Policies...)
@@ -253,6 +260,16 @@ func (c *Core) fetchACLTokenEntryAndEntity(ctx context.Context, req *logical.Req
 		policies = append(policies, inlinePolicy)
 	}
 
+	// Add the policies of the mounts owned by the entity or its groups
+	if !te.NoIdentityPolicies {
+		ownerPolicies, err := c.mountOwnerACLPolicies(ctx, entity)
+		if err != nil {
+			c.logger.Error("failed to generate mount owner policies", "error", err)
+			return nil, nil, nil, nil, ErrInternalError
+		}
+		policies = append(policies, ownerPolicies...)
+	}
+
 	// Construct the corresponding ACL object. ACL construction should be
 	// performed on the token's namespace.
 	acl, err := c.policyStore.ACL(tokenCtx, entity, policyNames, policies...)
@@ -385,6 +402,7 @@ func (c *Core) CheckToken(ctx context.Context, req *logical.Request, unauth bool
 		req.EntityID = te.EntityID
 		auth.TokenType = te.Type
 		auth.TTL = te.TTL
+		auth.Impersonator = te.Impersonator
 		if te.CreationTime > 0 {
 			auth.IssueTime = time.Unix(te.CreationTime, 0)
 		}
@@ -394,10 +412,12 @@ func (c *Core) CheckToken(ctx context.Context, req *logical.Request, unauth bool
 
 	// Check the standard non-root ACLs. Return the token entry if it's not
 	// allowed so we can decrement the use count.
+	endPolicyPhase := requestTimelineFromContext(ctx).StartPhase(RequestPhasePolicyCheck)
 	authResults := c.performPolicyChecks(ctx, acl, te, req, entity, &PolicyCheckOpts{
 		Unauth:            unauth,
 		RootPrivsRequired: rootPath,
 	})
+	endPolicyPhase()
 
 	auth.PolicyResults = &logical.PolicyResults{
 		Allowed: authResults.Allowed,
@@ -461,12 +481,24 @@ func (c *Core) switchedLockHandleRequest(httpCtx context.Context, req *logical.R
 	if c.standby && !c.perfStandby {
 		return nil, consts.ErrStandby
 	}
+	if atomic.LoadUint32(c.steppingDown) == 1 {
+		return nil, errSteppingDown
+	}
 
 	if c.activeContext == nil || c.activeContext.Err() != nil {
 		return nil, errors.New("active context canceled after getting state lock")
 	}
 
-	ctx, cancel := context.WithCancel(c.activeContext)
+	// The request is handled in the active context, so that it is canceled
+	// on seal, but keeps the deadline of the client's request and is canceled
+	// when the client disconnects.
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if deadline, ok := httpCtx.Deadline(); ok {
+		ctx, cancel = context.WithDeadline(c.activeContext, deadline)
+	} else {
+		ctx, cancel = context.WithCancel(c.activeContext)
+	}
 	go func(ctx context.Context, httpCtx context.Context) {
 		select {
 		case <-ctx.Done():
@@ -486,12 +518,30 @@ func (c *Core) switchedLockHandleRequest(httpCtx context.Context, req *logical.R
 	if ok {
 		ctx = context.WithValue(ctx, logical.CtxKeyInFlightRequestID{}, inFlightReqID)
 	}
+	ctx, tracker := logical.ContextWithProgressTracker(ctx)
+	ctx, timeline := c.requestTimelines.Start(ctx, req)
+	start := time.Now()
 	resp, err = c.handleCancelableRequest(ctx, req)
+	c.requestTimelines.Finish(timeline, req)
+	if ctx.Err() != nil && c.activeContext.Err() == nil {
+		c.logInterruptedRequest(ctx, req, tracker, time.Since(start))
+	}
 	req.SetTokenEntry(nil)
 	cancel()
 	return resp, err
 }
 
+// logInterruptedRequest logs a request whose deadline expired, or whose client
+// disconnected, while it was handled, with the progress reported by the
+// backend handling it.
+func (c *Core) logInterruptedRequest(ctx context.Context, req *logical.Request, tracker *logical.ProgressTracker, elapsed time.Duration) {
+	args := []interface{}{"path", req.Path, "operation", req.Operation, "error", ctx.Err(), "elapsed", elapsed}
+	if progress, ok := tracker.Progress(); ok {
+		args = append(args, progress.LogArgs()...)
+	}
+	c.logger.Warn("request interrupted", args...)
+}
+
 func (c *Core) handleCancelableRequest(ctx context.Context, req *logical.Request) (resp *logical.Response, err error) {
 	// Allowing writing to a path ending in / makes it extremely difficult to
 	// understand user intent for the filesystem-like backends (kv,
@@ -676,9 +726,10 @@ func (c *Core) handleCancelableRequest(ctx context.Context, req *logical.Request
 	walState := &logical.WALState{}
 	ctx = logical.IndexStateContext(ctx, walState)
 	var auth *logical.Auth
-	if c.isLoginRequest(ctx, req) {
+	switch {
+	case c.isLoginRequest(ctx, req):
 		resp, auth, err = c.handleLoginRequest(ctx, req)
-	} else {
+	default:
 		resp, auth, err = c.handleRequest(ctx, req)
 	}
 
@@ -798,14 +849,22 @@ func (c *Core) handleCancelableRequest(ctx context.Context, req *logical.Request
 				OuterErr:            err,
 				NonHMACReqDataKeys:  nonHMACReqDataKeys,
 				NonHMACRespDataKeys: nonHMACRespDataKeys,
+				RequestMetrics:      requestTimelineFromContext(ctx).RequestMetrics(),
 			}
-			if auditErr := c.auditBroker.LogResponse(ctx, logInput, c.auditedHeaders); auditErr != nil {
+			endAuditPhase := requestTimelineFromContext(ctx).StartPhase(RequestPhaseAuditResponse)
+			auditErr := c.auditBroker.LogResponse(ctx, logInput, c.auditedHeaders)
+			endAuditPhase()
+			if auditErr != nil {
 				c.logger.Error("failed to audit response", "request_path", req.Path, "error", auditErr)
 				return nil, ErrInternalError
 			}
 		}
 	}
 
+	if err == nil {
+		c.setWriteIndexState(req, walState)
+	}
+
 	if walState.LocalIndex != 0 || walState.ReplicatedIndex != 0 {
 		walState.ClusterID = c.ClusterID()
 		if walState.LocalIndex == 0 {
@@ -833,9 +892,28 @@ func isControlGroupRun(req *logical.Request) bool {
 	return req.ControlGroup != nil
 }
 
+// routedRequest is a request being handled by a mount on behalf of an entity.
+type routedRequest struct {
+	entityID  string
+	namespace *namespace.Namespace
+	path      string
+}
+
 func (c *Core) doRouting(ctx context.Context, req *logical.Request) (*logical.Response, error) {
+	// Track the requests of entities while they are handled, so that plugins
+	// can issue identity tokens to them. If a request ID is reused while the
+	// first request is in flight, the first one keeps it.
+	if req.ID != "" && req.EntityID != "" {
+		if ns, err := namespace.FromContext(ctx); err == nil {
+			routed := &routedRequest{entityID: req.EntityID, namespace: ns, path: req.Path}
+			if _, loaded := c.routedRequests.LoadOrStore(req.ID, routed); !loaded {
+				defer c.routedRequests.CompareAndDelete(req.ID, routed)
+			}
+		}
+	}
+
 	// If we're replicating and we get a read-only error from a backend, need to forward to primary
-	resp, err := c.router.Route(ctx, req)
+	resp, err := c.routeKV(ctx, req)
 	if shouldForward(c, resp, err) {
 		fwdResp, fwdErr := forward(ctx, c, req)
 		if fwdErr != nil && err != logical.ErrReadOnly {
@@ -896,7 +974,9 @@ func (c *Core) handleRequest(ctx context.Context, req *logical.Request) (retResp
 	}
 
 	// Validate the token
+	endAuthPhase := requestTimelineFromContext(ctx).StartPhase(RequestPhaseAuth)
 	auth, te, ctErr := c.CheckToken(ctx, req, false)
+	endAuthPhase()
 	if ctErr == logical.ErrRelativePath {
 		return logical.ErrorResponse(ctErr.Error()), nil, ctErr
 	}
@@ -1010,7 +1090,10 @@ func (c *Core) handleRequest(ctx context.Context, req *logical.Request) (retResp
 			Request:            req,
 			NonHMACReqDataKeys: nonHMACReqDataKeys,
 		}
-		if err := c.auditBroker.LogRequest(ctx, logInput, c.auditedHeaders); err != nil {
+		endAuditPhase := requestTimelineFromContext(ctx).StartPhase(RequestPhaseAuditRequest)
+		err := c.auditBroker.LogRequest(ctx, logInput, c.auditedHeaders)
+		endAuditPhase()
+		if err != nil {
 			c.logger.Error("failed to audit request", "path", req.Path, "error", err)
 			retErr = multierror.Append(retErr, ErrInternalError)
 			return nil, auth, retErr
@@ -1156,7 +1239,7 @@ func (c *Core) handleRequest(ctx context.Context, req *logical.Request) (retResp
 			for _, warning := range warnings {
 				resp.AddWarning(warning)
 			}
-			resp.Secret.TTL = ttl
+			resp.Secret.TTL = jitterLeaseTTL(matchingMountEntry, ttl)
 
 			registerFunc, funcGetErr := getLeaseRegisterFunc(c)
 			if funcGetErr != nil {
@@ -1211,7 +1294,9 @@ func (c *Core) handleRequest(ctx context.Context, req *logical.Request) (retResp
 			return nil, auth, retErr
 		}
 
-		_, identityPolicies, err := c.fetchEntityAndDerivedPolicies(ctx, tokenNS, resp.Auth.EntityID, false)
+		// Impersonation tokens don't inherit the identity policies of their
+		// entity, so don't report them
+		_, identityPolicies, err := c.fetchEntityAndDerivedPolicies(ctx, tokenNS, resp.Auth.EntityID, resp.Auth.Impersonator != nil)
 		if err != nil {
 			// Best-effort clean up on error, so we log the cleanup error as a
 			// warning but still return as internal error.
@@ -1314,7 +1399,9 @@ func (c *Core) handleLoginRequest(ctx context.Context, req *logical.Request) (re
 	// Do an unauth check. This will cause EGP policies to be checked
 	var auth *logical.Auth
 	var ctErr error
+	endAuthPhase := requestTimelineFromContext(ctx).StartPhase(RequestPhaseAuth)
 	auth, _, ctErr = c.CheckToken(ctx, req, true)
+	endAuthPhase()
 	if ctErr == logical.ErrPerfStandbyPleaseForward {
 		return nil, nil, ctErr
 	}
@@ -1579,6 +1666,13 @@ func (c *Core) handleLoginRequest(ctx context.Context, req *logical.Request) (re
 		// Determine the source of the login
 		source := c.router.MatchingMount(ctx, req.Path)
 
+		// Bind the token to the source of the login, if the mount asks for
+		// it. This happens before login MFA, so that the token issued once
+		// MFA is validated is bound to the source of the login as well.
+		if err := c.bindLoginToken(mEntry, req, auth); err != nil {
+			return nil, nil, err
+		}
+
 		// Login MFA
 		entity, _, err := c.fetchEntityAndDerivedPolicies(ctx, ns, auth.EntityID, false)
 		if err != nil {
@@ -2119,6 +2213,8 @@ func (c *Core) RegisterAuth(ctx context.Context, tokenTTL time.Duration, path st
 		ExplicitMaxTTL: auth.ExplicitMaxTTL,
 		Period:         auth.Period,
 		Type:           auth.TokenType,
+
+		BoundClientCertFingerprint: auth.BoundClientCertFingerprint,
 	}
 
 	if te.TTL == 0 && (len(te.Policies) != 1 || te.Policies[0] != "root") {
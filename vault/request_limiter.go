@@ -0,0 +1,109 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"fmt"
+	"regexp"
+	"sync/atomic"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// requestLimiter admits the requests routed to a mount within the concurrency
+// limits and costs its backend declares in its special paths, so that
+// expensive requests can't monopolize the backend.
+type requestLimiter struct {
+	paths   []*pathLimiter
+	maxCost int64
+
+	// cost is the total cost of the requests being handled
+	cost atomic.Int64
+}
+
+// pathLimiter counts the requests being handled to the paths matching a
+// pattern.
+type pathLimiter struct {
+	pattern        *regexp.Regexp
+	maxConcurrency int64
+	cost           int64
+
+	count atomic.Int64
+}
+
+// newRequestLimiter returns the limiter of the requests to a backend with the
+// given special paths, or nil if the backend declares no limits.
+func newRequestLimiter(paths *logical.Paths) (*requestLimiter, error) {
+	if len(paths.Limits) == 0 && paths.MaxConcurrentCost <= 0 {
+		return nil, nil
+	}
+
+	l := &requestLimiter{
+		maxCost: int64(paths.MaxConcurrentCost),
+	}
+	for _, limit := range paths.Limits {
+		pattern, err := regexp.Compile(limit.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q of path limit: %w", limit.Pattern, err)
+		}
+		cost := int64(limit.Cost)
+		if cost <= 0 {
+			cost = 1
+		}
+		l.paths = append(l.paths, &pathLimiter{
+			pattern:        pattern,
+			maxConcurrency: int64(limit.MaxConcurrency),
+			cost:           cost,
+		})
+	}
+	return l, nil
+}
+
+// admit reserves the concurrency and cost of a request, whose path is relative
+// to the mount. It returns a function releasing them once the request has been
+// handled, or an error if the request would exceed the limits. Help requests
+// are always admitted.
+func (l *requestLimiter) admit(req *logical.Request) (func(), error) {
+	if l == nil || req.Operation == logical.HelpOperation {
+		return func() {}, nil
+	}
+
+	cost := int64(1)
+	var path *pathLimiter
+	for _, p := range l.paths {
+		if p.pattern.MatchString(req.Path) {
+			path, cost = p, p.cost
+			break
+		}
+	}
+
+	if path != nil && path.maxConcurrency > 0 {
+		if path.count.Add(1) > path.maxConcurrency {
+			path.count.Add(-1)
+			return nil, fmt.Errorf("%w: at most %d requests to path %q are handled at once", logical.ErrTooManyConcurrentRequests, path.maxConcurrency, req.Path)
+		}
+	}
+	release := func() {
+		if path != nil && path.maxConcurrency > 0 {
+			path.count.Add(-1)
+		}
+	}
+
+	if l.maxCost > 0 {
+		// Requests costing more than the limit are only admitted when the
+		// backend isn't handling any other request
+		if total := l.cost.Add(cost); total > l.maxCost && total != cost {
+			l.cost.Add(-cost)
+			release()
+			return nil, fmt.Errorf("%w: the requests handled at once by the backend would exceed a total cost of %d", logical.ErrTooManyConcurrentRequests, l.maxCost)
+		}
+		releasePath := release
+		release = func() {
+			l.cost.Add(-cost)
+			releasePath()
+		}
+	}
+
+	return release, nil
+}
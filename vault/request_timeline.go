@@ -0,0 +1,297 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	// defaultRequestTimelineCacheSize is the number of recent request
+	// timelines kept in memory when request capture is enabled.
+	defaultRequestTimelineCacheSize = 1000
+
+	RequestPhaseAuth           = "auth"
+	RequestPhasePolicyCheck    = "policy_check"
+	RequestPhaseBackendHandler = "backend_handler"
+	RequestPhaseAuditRequest   = "audit_request"
+	RequestPhaseAuditResponse  = "audit_response"
+)
+
+type ctxKeyRequestTimeline struct{}
+
+// RequestTimeline is a breakdown of the time spent in the phases of a single
+// request, along with the storage operations it caused.
+type RequestTimeline struct {
+	l sync.Mutex
+
+	// captured is true if the timeline is kept for lookup once the request
+	// completes, rather than only recorded for request metrics.
+	captured bool
+
+	RequestID  string
+	Path       string
+	Operation  logical.Operation
+	MountPoint string
+	StartTime  time.Time
+	Duration   time.Duration
+	Phases     []*RequestTimelinePhase
+	Storage    map[string]*RequestTimelineStorageStats
+}
+
+// RequestTimelinePhase records the time spent in a phase of a request,
+// relative to the start of the request.
+type RequestTimelinePhase struct {
+	Name     string
+	Offset   time.Duration
+	Duration time.Duration
+}
+
+// RequestTimelineStorageStats aggregates the storage operations of one type
+// caused by a request.
+type RequestTimelineStorageStats struct {
+	Count    int
+	Duration time.Duration
+}
+
+func newRequestTimeline(req *logical.Request) *RequestTimeline {
+	return &RequestTimeline{
+		RequestID: req.ID,
+		Path:      req.Path,
+		Operation: req.Operation,
+		StartTime: time.Now(),
+		Storage:   make(map[string]*RequestTimelineStorageStats),
+	}
+}
+
+// requestTimelineFromContext returns the timeline being recorded for the
+// request in ctx, or nil if the request is not being captured.
+func requestTimelineFromContext(ctx context.Context) *RequestTimeline {
+	timeline, _ := ctx.Value(ctxKeyRequestTimeline{}).(*RequestTimeline)
+	return timeline
+}
+
+// StartPhase marks the beginning of the named phase and returns a function
+// which marks its end. It is safe to call on a nil timeline, so that call
+// sites need not check whether the request is being captured:
+//
+//	defer requestTimelineFromContext(ctx).StartPhase(RequestPhaseAuth)()
+func (t *RequestTimeline) StartPhase(name string) func() {
+	if t == nil {
+		return func() {}
+	}
+
+	start := time.Now()
+	return func() {
+		t.l.Lock()
+		defer t.l.Unlock()
+		t.Phases = append(t.Phases, &RequestTimelinePhase{
+			Name:     name,
+			Offset:   start.Sub(t.StartTime),
+			Duration: time.Since(start),
+		})
+	}
+}
+
+func (t *RequestTimeline) recordStorageOp(op string, start time.Time) {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	stats, ok := t.Storage[op]
+	if !ok {
+		stats = &RequestTimelineStorageStats{}
+		t.Storage[op] = stats
+	}
+	stats.Count++
+	stats.Duration += time.Since(start)
+}
+
+// RequestMetrics summarizes the timeline for inclusion in audit entries. It
+// returns nil for a nil timeline.
+func (t *RequestTimeline) RequestMetrics() *logical.RequestMetrics {
+	if t == nil {
+		return nil
+	}
+
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	metrics := &logical.RequestMetrics{}
+	for op, stats := range t.Storage {
+		switch op {
+		case "get", "list":
+			metrics.StorageReads += stats.Count
+		case "put", "delete":
+			metrics.StorageWrites += stats.Count
+		}
+	}
+	for _, phase := range t.Phases {
+		if phase.Name == RequestPhaseBackendHandler {
+			metrics.HandlerDuration += phase.Duration
+		}
+	}
+	return metrics
+}
+
+// ToMap returns the timeline in the form used in API responses.
+func (t *RequestTimeline) ToMap() map[string]interface{} {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	phases := make([]map[string]interface{}, 0, len(t.Phases))
+	for _, phase := range t.Phases {
+		phases = append(phases, map[string]interface{}{
+			"name":        phase.Name,
+			"offset_us":   phase.Offset.Microseconds(),
+			"duration_us": phase.Duration.Microseconds(),
+		})
+	}
+
+	storage := make(map[string]interface{}, len(t.Storage))
+	for op, stats := range t.Storage {
+		storage[op] = map[string]interface{}{
+			"count":       stats.Count,
+			"duration_us": stats.Duration.Microseconds(),
+		}
+	}
+
+	return map[string]interface{}{
+		"request_id":  t.RequestID,
+		"path":        t.Path,
+		"operation":   string(t.Operation),
+		"mount_point": t.MountPoint,
+		"start_time":  t.StartTime.Format(time.RFC3339Nano),
+		"duration_us": t.Duration.Microseconds(),
+		"phases":      phases,
+		"storage":     storage,
+	}
+}
+
+// timelineStorage wraps a request's storage view to record the storage
+// operations made by the backend handling the request.
+type timelineStorage struct {
+	logical.Storage
+	timeline *RequestTimeline
+}
+
+var _ logical.Storage = (*timelineStorage)(nil)
+
+func (s *timelineStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	defer s.timeline.recordStorageOp("list", time.Now())
+	return s.Storage.List(ctx, prefix)
+}
+
+func (s *timelineStorage) Get(ctx context.Context, key string) (*logical.StorageEntry, error) {
+	defer s.timeline.recordStorageOp("get", time.Now())
+	return s.Storage.Get(ctx, key)
+}
+
+func (s *timelineStorage) Put(ctx context.Context, entry *logical.StorageEntry) error {
+	defer s.timeline.recordStorageOp("put", time.Now())
+	return s.Storage.Put(ctx, entry)
+}
+
+func (s *timelineStorage) Delete(ctx context.Context, key string) error {
+	defer s.timeline.recordStorageOp("delete", time.Now())
+	return s.Storage.Delete(ctx, key)
+}
+
+// RequestTimelineCapture decides which requests have their timelines
+// recorded and keeps the most recent ones in memory, keyed by request ID.
+// Capture is node-local and disabled by default.
+type RequestTimelineCapture struct {
+	l          sync.RWMutex
+	enabled    bool
+	sampleRate float64
+	timelines  *lru.Cache
+
+	// recordRequestMetrics is set when an audit device logs request metrics,
+	// in which case every request is recorded regardless of sampling.
+	recordRequestMetrics atomic.Bool
+}
+
+func NewRequestTimelineCapture() *RequestTimelineCapture {
+	cache, _ := lru.New(defaultRequestTimelineCacheSize)
+	return &RequestTimelineCapture{
+		sampleRate: 1.0,
+		timelines:  cache,
+	}
+}
+
+// Configure enables or disables capture. sampleRate is the fraction of
+// requests, between 0 and 1, whose timelines are recorded.
+func (r *RequestTimelineCapture) Configure(enabled bool, sampleRate float64) error {
+	if sampleRate <= 0 || sampleRate > 1 {
+		return errors.New("sample_rate must be greater than 0 and at most 1")
+	}
+
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	r.enabled = enabled
+	r.sampleRate = sampleRate
+	if !enabled {
+		r.timelines.Purge()
+	}
+	return nil
+}
+
+// SetRecordRequestMetrics sets whether every request is recorded so that its
+// metrics can be included in audit entries.
+func (r *RequestTimelineCapture) SetRecordRequestMetrics(record bool) {
+	r.recordRequestMetrics.Store(record)
+}
+
+// Config returns whether capture is enabled, and the sample rate.
+func (r *RequestTimelineCapture) Config() (bool, float64) {
+	r.l.RLock()
+	defer r.l.RUnlock()
+	return r.enabled, r.sampleRate
+}
+
+// Start returns a context carrying a new timeline for req if the request is
+// selected for capture or request metrics are being recorded; otherwise ctx
+// is returned unchanged along with a nil timeline.
+func (r *RequestTimelineCapture) Start(ctx context.Context, req *logical.Request) (context.Context, *RequestTimeline) {
+	enabled, sampleRate := r.Config()
+	captured := enabled && req.ID != "" && (sampleRate >= 1 || rand.Float64() < sampleRate)
+	if !captured && !r.recordRequestMetrics.Load() {
+		return ctx, nil
+	}
+
+	timeline := newRequestTimeline(req)
+	timeline.captured = captured
+	return context.WithValue(ctx, ctxKeyRequestTimeline{}, timeline), timeline
+}
+
+// Finish completes the timeline and makes it available for lookup.
+func (r *RequestTimelineCapture) Finish(timeline *RequestTimeline, req *logical.Request) {
+	if timeline == nil || !timeline.captured {
+		return
+	}
+
+	timeline.l.Lock()
+	timeline.Duration = time.Since(timeline.StartTime)
+	timeline.MountPoint = req.MountPoint
+	timeline.l.Unlock()
+
+	r.timelines.Add(timeline.RequestID, timeline)
+}
+
+// Get returns the timeline recorded for the given request ID, or nil.
+func (r *RequestTimelineCapture) Get(requestID string) *RequestTimeline {
+	raw, ok := r.timelines.Get(requestID)
+	if !ok {
+		return nil
+	}
+	return raw.(*RequestTimeline)
+}
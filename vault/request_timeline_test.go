@@ -0,0 +1,134 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestRequestTimeline_Capture(t *testing.T) {
+	c, _, root := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "secret/foo")
+	req.ID = "not-captured"
+	req.ClientToken = root
+	req.Data["bar"] = "baz"
+	if _, err := c.HandleRequest(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if timeline := c.requestTimelines.Get("not-captured"); timeline != nil {
+		t.Fatalf("expected no timeline while capture is disabled, got %#v", timeline)
+	}
+
+	if err := c.requestTimelines.Configure(true, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "secret/foo")
+	req.ID = "captured"
+	req.ClientToken = root
+	req.Data["bar"] = "baz"
+	if _, err := c.HandleRequest(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+
+	req = logical.TestRequest(t, logical.ReadOperation, "sys/internal/debug/request/captured")
+	req.ClientToken = root
+	resp, err := c.HandleRequest(ctx, req)
+	if err != nil || resp.IsError() {
+		t.Fatalf("err: %v, resp: %#v", err, resp)
+	}
+
+	if resp.Data["path"] != "secret/foo" || resp.Data["mount_point"] != "secret/" {
+		t.Fatalf("unexpected timeline: %#v", resp.Data)
+	}
+
+	phases := make(map[string]bool)
+	for _, phase := range resp.Data["phases"].([]map[string]interface{}) {
+		phases[phase["name"].(string)] = true
+	}
+	for _, expected := range []string{RequestPhaseAuth, RequestPhasePolicyCheck, RequestPhaseBackendHandler, RequestPhaseAuditRequest, RequestPhaseAuditResponse} {
+		if !phases[expected] {
+			t.Fatalf("expected phase %q in %v", expected, phases)
+		}
+	}
+
+	storage := resp.Data["storage"].(map[string]interface{})
+	put, ok := storage["put"].(map[string]interface{})
+	if !ok || put["count"] != 1 {
+		t.Fatalf("expected a single storage put, got %#v", storage)
+	}
+
+	// Disabling capture discards recorded timelines.
+	if err := c.requestTimelines.Configure(false, 1); err != nil {
+		t.Fatal(err)
+	}
+	if timeline := c.requestTimelines.Get("captured"); timeline != nil {
+		t.Fatalf("expected timelines to be purged, got %#v", timeline)
+	}
+}
+
+func TestRequestTimeline_ConfigValidation(t *testing.T) {
+	_, b, _ := testCoreSystemBackend(t)
+
+	for _, rate := range []float64{0, -0.5, 1.5} {
+		req := logical.TestRequest(t, logical.UpdateOperation, "internal/debug/request-capture")
+		req.Data["enabled"] = true
+		req.Data["sample_rate"] = rate
+		resp, err := b.HandleRequest(namespace.RootContext(nil), req)
+		if err == nil || !resp.IsError() {
+			t.Fatalf("expected error for sample rate %v, got %#v", rate, resp)
+		}
+	}
+}
+
+// TestRequestTimeline_RequestMetrics verifies that when an audit device logs
+// request metrics, requests are recorded without being kept for lookup.
+func TestRequestTimeline_RequestMetrics(t *testing.T) {
+	c, _, root := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "sys/audit/noop")
+	req.ClientToken = root
+	req.Data["type"] = "noop"
+	req.Data["options"] = map[string]string{"log_request_metrics": "true"}
+	if _, err := c.HandleRequest(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+
+	req = logical.TestRequest(t, logical.ReadOperation, "secret/foo")
+	req.ID = "metrics-only"
+	_, timeline := c.requestTimelines.Start(ctx, req)
+	if timeline == nil {
+		t.Fatal("expected request to be recorded for request metrics")
+	}
+	timeline.StartPhase(RequestPhaseBackendHandler)()
+	timeline.recordStorageOp("get", time.Now())
+	timeline.recordStorageOp("put", time.Now())
+	timeline.recordStorageOp("list", time.Now())
+
+	metrics := timeline.RequestMetrics()
+	if metrics.StorageReads != 2 || metrics.StorageWrites != 1 {
+		t.Fatalf("unexpected metrics: %#v", metrics)
+	}
+
+	c.requestTimelines.Finish(timeline, req)
+	if c.requestTimelines.Get("metrics-only") != nil {
+		t.Fatal("expected timeline recorded only for metrics not to be kept")
+	}
+
+	req = logical.TestRequest(t, logical.DeleteOperation, "sys/audit/noop")
+	req.ClientToken = root
+	if _, err := c.HandleRequest(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if _, timeline := c.requestTimelines.Start(ctx, req); timeline != nil {
+		t.Fatal("expected no recording once the audit device is disabled")
+	}
+}
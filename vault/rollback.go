@@ -6,6 +6,7 @@ package vault
 import (
 	"context"
 	"errors"
+	"math/rand"
 	"strings"
 	"sync"
 	"time"
@@ -13,6 +14,7 @@ import (
 	metrics "github.com/armon/go-metrics"
 	log "github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/helper/timeutil"
 	"github.com/hashicorp/vault/sdk/logical"
 )
 
@@ -25,9 +27,30 @@ import (
 // This manager handles that by periodically (on a timer) requesting that the
 // backends clean up.
 //
+// minRollbackPeriod is the shortest rollback period mounts can be tuned with.
+const minRollbackPeriod = time.Second
+
+// defaultRollbackWorkers is the number of rollbacks run concurrently when the
+// core isn't configured with rollback_workers.
+const defaultRollbackWorkers = 256
+
+// defaultRollbackMaxBackoff caps the delay between the rollbacks of mounts
+// whose rollbacks keep failing when the core isn't configured with
+// rollback_max_backoff.
+const defaultRollbackMaxBackoff = time.Hour
+
 // The RollbackManager periodically initiates a logical.RollbackOperation
 // on every mounted logical backend. It ensures that only one rollback operation
 // is in-flight at any given time within a single seal/unseal phase.
+//
+// Mounts are rolled back every period, unless they are tuned with their own
+// rollback_period. The manager ticks at the shortest period of all mounts, and
+// skips the mounts whose period has not elapsed since their last rollback.
+// The period of mounts whose rollbacks keep failing doubles with each failure,
+// up to the core's rollbackMaxBackoff, and is reset once a rollback succeeds.
+//
+// Scheduled rollbacks are queued, and run by a fixed number of workers, so
+// that the number of goroutines does not grow with the number of mounts.
 type RollbackManager struct {
 	logger log.Logger
 
@@ -38,11 +61,34 @@ type RollbackManager struct {
 
 	router *Router
 	period time.Duration
+	clock  timeutil.Clock
+
+	// lastRollback holds when the rollback of each mount was last started,
+	// keyed by full path, and tickPeriod the shortest rollback period of all
+	// mounts at the time.
+	scheduleLock sync.Mutex
+	lastRollback map[string]time.Time
+	tickPeriod   time.Duration
 
 	inflightAll  sync.WaitGroup
 	inflight     map[string]*rollbackState
 	inflightLock sync.RWMutex
 
+	// status holds the outcome of the last rollback of each mount, keyed by
+	// full path.
+	status     map[string]*RollbackStatus
+	statusLock sync.RWMutex
+
+	// queue holds the scheduled rollbacks waiting for one of the workers,
+	// which are signaled through queueCh. workers is the number of workers
+	// started, and is zero until the manager is started, when rollbacks
+	// are started right away.
+	workers     int
+	workersDone sync.WaitGroup
+	queue       []*rollbackState
+	queueLock   sync.Mutex
+	queueCh     chan struct{}
+
 	doneCh          chan struct{}
 	shutdown        bool
 	shutdownCh      chan struct{}
@@ -60,27 +106,67 @@ type rollbackState struct {
 	sync.WaitGroup
 	cancelLockGrabCtx       context.Context
 	cancelLockGrabCtxCancel context.CancelFunc
+
+	// The queued rollback of the mount at fullPath, started by a worker or
+	// by a manual rollback, whichever comes first. started is guarded by the
+	// inflightLock.
+	ctx      context.Context
+	fullPath string
+	queuedAt time.Time
+	started  bool
+}
+
+// RollbackStatus is the outcome of the last rollback attempted on a mount.
+type RollbackStatus struct {
+	// LastRollback is when the last rollback started, and Duration how long
+	// it took.
+	LastRollback time.Time
+	Duration     time.Duration
+
+	// LastError is the error of the last rollback which failed, at
+	// LastErrorTime, and ConsecutiveFailures the number of rollbacks which
+	// failed since the last one which succeeded.
+	LastError           string
+	LastErrorTime       time.Time
+	ConsecutiveFailures int
+
+	// Backoff is the delay before the next scheduled rollback, when it is
+	// longer than the rollback period of the mount because of its failures.
+	Backoff time.Duration
+
+	// jitter randomizes Backoff when backoff jitter is enabled. It is drawn
+	// anew on each failure, so that the delay doesn't change between ticks.
+	jitter float64
 }
 
 // NewRollbackManager is used to create a new rollback manager
 func NewRollbackManager(ctx context.Context, logger log.Logger, backendsFunc func() []*MountEntry, router *Router, core *Core) *RollbackManager {
 	r := &RollbackManager{
-		logger:      logger,
-		backends:    backendsFunc,
-		router:      router,
-		period:      core.rollbackPeriod,
-		inflight:    make(map[string]*rollbackState),
-		doneCh:      make(chan struct{}),
-		shutdownCh:  make(chan struct{}),
-		stopTicker:  make(chan struct{}),
-		quitContext: ctx,
-		core:        core,
+		logger:       logger,
+		backends:     backendsFunc,
+		router:       router,
+		period:       core.rollbackPeriod,
+		clock:        core.clock,
+		lastRollback: make(map[string]time.Time),
+		inflight:     make(map[string]*rollbackState),
+		status:       make(map[string]*RollbackStatus),
+		doneCh:       make(chan struct{}),
+		shutdownCh:   make(chan struct{}),
+		stopTicker:   make(chan struct{}),
+		quitContext:  ctx,
+		core:         core,
+		queueCh:      make(chan struct{}, 1),
 	}
 	return r
 }
 
 // Start starts the rollback manager
 func (m *RollbackManager) Start() {
+	m.workers = m.core.rollbackWorkers
+	m.workersDone.Add(m.workers)
+	for i := 0; i < m.workers; i++ {
+		go m.runWorker()
+	}
 	go m.run()
 }
 
@@ -93,6 +179,13 @@ func (m *RollbackManager) Stop() {
 		m.shutdown = true
 		close(m.shutdownCh)
 		<-m.doneCh
+		m.workersDone.Wait()
+
+		// Nothing is queued once the manager is done, and the rollbacks
+		// still queued are abandoned.
+		for rs := m.dequeueRollback(); rs != nil; rs = m.dequeueRollback() {
+			m.finishRollback(rs, errors.New("rollback shutting down"))
+		}
 	}
 	m.inflightAll.Wait()
 }
@@ -113,7 +206,17 @@ func (m *RollbackManager) StopTicker() {
 // run is a long running routine to periodically invoke rollback
 func (m *RollbackManager) run() {
 	m.logger.Info("starting rollback manager")
-	tick := time.NewTicker(m.period)
+	tickPeriod := m.period
+	for _, e := range m.backends() {
+		if period := m.mountRollbackPeriod(e); period < tickPeriod {
+			tickPeriod = period
+		}
+	}
+	m.scheduleLock.Lock()
+	m.tickPeriod = tickPeriod
+	m.scheduleLock.Unlock()
+
+	tick := m.clock.NewTicker(tickPeriod)
 	logTestStopOnce := false
 	defer tick.Stop()
 	defer close(m.doneCh)
@@ -122,6 +225,16 @@ func (m *RollbackManager) run() {
 		case <-tick.C:
 			m.triggerRollbacks()
 
+			// Follow the rollback periods tuned since the last tick.
+			m.scheduleLock.Lock()
+			next := m.tickPeriod
+			m.scheduleLock.Unlock()
+			if next != tickPeriod {
+				m.logger.Debug("rollback tick period changed", "period", next)
+				tick.Reset(next)
+				tickPeriod = next
+			}
+
 		case <-m.shutdownCh:
 			m.logger.Info("stopping rollback manager")
 			return
@@ -137,8 +250,20 @@ func (m *RollbackManager) run() {
 }
 
 // triggerRollbacks is used to trigger the rollbacks across all the backends
+// whose rollback period elapsed since their last rollback.
 func (m *RollbackManager) triggerRollbacks() {
 	backends := m.backends()
+	now := m.clock.Now()
+
+	m.scheduleLock.Lock()
+	defer m.scheduleLock.Unlock()
+
+	// Ticks may come slightly early, allow for half a tick so that mounts
+	// using the tick period are not skipped.
+	slack := m.tickPeriod / 2
+	last := m.lastRollback
+	m.lastRollback = make(map[string]time.Time, len(backends))
+	m.tickPeriod = m.period
 
 	for _, e := range backends {
 		path := e.Path
@@ -154,18 +279,104 @@ func (m *RollbackManager) triggerRollbacks() {
 		}
 		fullPath := e.namespace.Path + path
 
+		period := m.mountRollbackPeriod(e)
+		if period < m.tickPeriod {
+			m.tickPeriod = period
+		}
+		if started, ok := last[fullPath]; ok && now.Sub(started)+slack < m.rollbackBackoff(fullPath, period) {
+			m.lastRollback[fullPath] = started
+			continue
+		}
+		m.lastRollback[fullPath] = now
+
 		// Start a rollback if necessary
 		m.startOrLookupRollback(ctx, fullPath, true)
 	}
+
+	// Forget the rollbacks of the mounts which were removed.
+	m.statusLock.Lock()
+	for fullPath := range m.status {
+		if _, ok := m.lastRollback[fullPath]; !ok {
+			delete(m.status, fullPath)
+		}
+	}
+	m.statusLock.Unlock()
 }
 
-// startOrLookupRollback is used to start an async rollback attempt.
-// This must be called with the inflightLock held.
+// rollbackBackoff returns the delay between the rollbacks of the mount at the
+// given path, which is its rollback period doubled for each consecutive
+// failure, up to the maximum backoff. With jitter, the delay is picked between
+// half of it and all of it, and never shorter than the period.
+func (m *RollbackManager) rollbackBackoff(fullPath string, period time.Duration) time.Duration {
+	m.statusLock.Lock()
+	defer m.statusLock.Unlock()
+
+	status, ok := m.status[fullPath]
+	if !ok {
+		return period
+	}
+	status.Backoff = 0
+	if status.ConsecutiveFailures == 0 {
+		return period
+	}
+
+	maxBackoff := m.core.rollbackMaxBackoff
+	backoff := period
+	for i := 0; i < status.ConsecutiveFailures && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	if m.core.rollbackBackoffJitter {
+		backoff = backoff/2 + time.Duration(status.jitter*float64(backoff/2))
+	}
+	if backoff <= period {
+		return period
+	}
+
+	status.Backoff = backoff
+	return backoff
+}
+
+// resetRollbackSchedule makes every mount due for a rollback on the next
+// call to triggerRollbacks.
+func (m *RollbackManager) resetRollbackSchedule() {
+	m.scheduleLock.Lock()
+	defer m.scheduleLock.Unlock()
+	m.lastRollback = make(map[string]time.Time)
+}
+
+// mountRollbackPeriod returns the rollback period of the mount, which is the
+// manager's period unless the mount is tuned with its own.
+func (m *RollbackManager) mountRollbackPeriod(e *MountEntry) time.Duration {
+	lock := &m.core.mountsLock
+	if e.Table == credentialTableType {
+		lock = &m.core.authLock
+	}
+	lock.RLock()
+	period := e.Config.RollbackPeriod
+	lock.RUnlock()
+
+	if period <= 0 {
+		return m.period
+	}
+	return period
+}
+
+// startOrLookupRollback is used to start an async rollback attempt, or to
+// look up the attempt in flight for the path. Rollbacks grabbing the
+// statelock are queued for the workers, whereas the others are started right
+// away: their callers hold the statelock, which may block the workers.
 func (m *RollbackManager) startOrLookupRollback(ctx context.Context, fullPath string, grabStatelock bool) *rollbackState {
 	m.inflightLock.Lock()
 	defer m.inflightLock.Unlock()
 	rsInflight, ok := m.inflight[fullPath]
 	if ok {
+		if !grabStatelock && !rsInflight.started {
+			rsInflight.started = true
+			go m.attemptRollback(rsInflight.ctx, fullPath, rsInflight, true)
+		}
 		return rsInflight
 	}
 
@@ -173,27 +384,154 @@ func (m *RollbackManager) startOrLookupRollback(ctx context.Context, fullPath st
 	rs := &rollbackState{
 		cancelLockGrabCtx:       cancelCtx,
 		cancelLockGrabCtxCancel: cancelFunc,
+		ctx:                     ctx,
+		fullPath:                fullPath,
 	}
 
 	// If no inflight rollback is already running, kick one off
 	m.inflight[fullPath] = rs
 	rs.Add(1)
 	m.inflightAll.Add(1)
-	go m.attemptRollback(ctx, fullPath, rs, grabStatelock)
+	if !grabStatelock || m.workers <= 0 {
+		rs.started = true
+		go m.attemptRollback(ctx, fullPath, rs, grabStatelock)
+		return rs
+	}
+
+	rs.queuedAt = time.Now()
+	m.queueLock.Lock()
+	m.queue = append(m.queue, rs)
+	queued := len(m.queue)
+	m.queueLock.Unlock()
+	metrics.SetGauge([]string{"rollback", "queued"}, float32(queued))
+
+	select {
+	case m.queueCh <- struct{}{}:
+	default:
+	}
 	return rs
 }
 
+// dequeueRollback returns the next queued rollback which hasn't been started
+// by a manual rollback yet, and marks it started. It returns nil when the
+// queue is empty.
+func (m *RollbackManager) dequeueRollback() *rollbackState {
+	for {
+		m.queueLock.Lock()
+		if len(m.queue) == 0 {
+			m.queueLock.Unlock()
+			return nil
+		}
+		rs := m.queue[0]
+		m.queue[0] = nil
+		m.queue = m.queue[1:]
+		remaining := len(m.queue)
+		m.queueLock.Unlock()
+		metrics.SetGauge([]string{"rollback", "queued"}, float32(remaining))
+
+		// The queue lock is released first, as the inflightLock is held
+		// while queueing.
+		m.inflightLock.Lock()
+		started := rs.started
+		rs.started = true
+		m.inflightLock.Unlock()
+		if started {
+			continue
+		}
+
+		// Wake another worker for the rest of the queue, as a single signal
+		// is pending however many rollbacks were queued.
+		if remaining > 0 {
+			select {
+			case m.queueCh <- struct{}{}:
+			default:
+			}
+		}
+		return rs
+	}
+}
+
+// runWorker runs queued rollbacks until the manager stops.
+func (m *RollbackManager) runWorker() {
+	defer m.workersDone.Done()
+	for {
+		select {
+		case <-m.shutdownCh:
+			return
+		default:
+		}
+
+		rs := m.dequeueRollback()
+		if rs == nil {
+			select {
+			case <-m.queueCh:
+			case <-m.shutdownCh:
+				return
+			}
+			continue
+		}
+
+		metrics.MeasureSince([]string{"rollback", "waiting"}, rs.queuedAt)
+		m.attemptRollback(rs.ctx, rs.fullPath, rs, true)
+	}
+}
+
+// finishRollback records the outcome of a rollback attempt, and releases its
+// waiters.
+func (m *RollbackManager) finishRollback(rs *rollbackState, err error) {
+	rs.lastError = err
+	rs.Done()
+	m.inflightAll.Done()
+	m.inflightLock.Lock()
+	delete(m.inflight, rs.fullPath)
+	m.inflightLock.Unlock()
+}
+
+// recordRollback records the outcome of the rollback of the mount at the given
+// path, started at the given time.
+func (m *RollbackManager) recordRollback(fullPath string, start time.Time, err error) {
+	m.statusLock.Lock()
+	defer m.statusLock.Unlock()
+
+	status, ok := m.status[fullPath]
+	if !ok {
+		status = &RollbackStatus{}
+		m.status[fullPath] = status
+	}
+	status.LastRollback = start
+	status.Duration = time.Since(start)
+	if err != nil {
+		status.LastError = err.Error()
+		status.LastErrorTime = time.Now()
+		status.ConsecutiveFailures++
+		status.jitter = rand.Float64()
+	} else {
+		status.ConsecutiveFailures = 0
+		status.Backoff = 0
+	}
+}
+
+// Status returns the outcome of the last rollback of the mounts rolled back
+// since the manager started, keyed by full path.
+func (m *RollbackManager) Status() map[string]RollbackStatus {
+	m.statusLock.RLock()
+	defer m.statusLock.RUnlock()
+
+	status := make(map[string]RollbackStatus, len(m.status))
+	for fullPath, s := range m.status {
+		status[fullPath] = *s
+	}
+	return status
+}
+
 // attemptRollback invokes a RollbackOperation for the given path
 func (m *RollbackManager) attemptRollback(ctx context.Context, fullPath string, rs *rollbackState, grabStatelock bool) (err error) {
-	defer metrics.MeasureSince([]string{"rollback", "attempt", strings.ReplaceAll(fullPath, "/", "-")}, time.Now())
+	start := time.Now()
+	defer metrics.MeasureSince([]string{"rollback", "attempt", strings.ReplaceAll(fullPath, "/", "-")}, start)
 
 	defer func() {
-		rs.lastError = err
-		rs.Done()
-		m.inflightAll.Done()
-		m.inflightLock.Lock()
-		delete(m.inflight, fullPath)
-		m.inflightLock.Unlock()
+		m.recordRollback(fullPath, start, err)
+		m.finishRollback(rs, err)
 	}()
 
 	ns, err := namespace.FromContext(ctx)
@@ -5,6 +5,7 @@ package vault
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -12,7 +13,9 @@ import (
 	log "github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-uuid"
 	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/helper/timeutil"
 	"github.com/hashicorp/vault/sdk/helper/logging"
+	"github.com/hashicorp/vault/sdk/logical"
 )
 
 // mockRollback returns a mock rollback manager
@@ -77,6 +80,204 @@ func TestRollbackManager(t *testing.T) {
 	}
 }
 
+func TestRollbackManager_ManualClock(t *testing.T) {
+	m, backend := mockRollback(t)
+	clock := timeutil.NewManualClock(time.Now())
+	m.clock = clock
+	m.period = time.Minute
+
+	m.Start()
+	defer m.Stop()
+
+	numPaths := func() int {
+		backend.Lock()
+		defer backend.Unlock()
+		return len(backend.Paths)
+	}
+
+	// Nothing happens until the clock is advanced past the period.
+	time.Sleep(50 * time.Millisecond)
+	if n := numPaths(); n != 0 {
+		t.Fatalf("expected no rollbacks before advancing the clock, got %d", n)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for numPaths() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for a rollback")
+		}
+		clock.Advance(m.period)
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestRollbackManager_RollbackPeriod ensures that mounts tuned with their own
+// rollback period are rolled back on their own schedule.
+func TestRollbackManager_RollbackPeriod(t *testing.T) {
+	core, _, _ := TestCoreUnsealed(t)
+	router := NewRouter()
+	_, barrier, _ := mockBarrier(t)
+
+	periods := map[string]time.Duration{
+		"default": 0,
+		"fast":    10 * time.Second,
+		"slow":    time.Hour,
+	}
+	backends := make(map[string]*NoopBackend)
+	var entries []*MountEntry
+	for path, period := range periods {
+		meUUID, err := uuid.GenerateUUID()
+		if err != nil {
+			t.Fatal(err)
+		}
+		entry := &MountEntry{
+			Path:        path + "/",
+			UUID:        meUUID,
+			Accessor:    path + "accessor",
+			NamespaceID: namespace.RootNamespaceID,
+			namespace:   namespace.RootNamespace,
+			Config:      MountConfig{RollbackPeriod: period},
+		}
+		backends[path] = new(NoopBackend)
+		view := NewBarrierView(barrier, "logical/"+meUUID+"/")
+		if err := router.Mount(backends[path], path+"/", entry, view); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	logger := logging.NewVaultLogger(log.Trace)
+	m := NewRollbackManager(context.Background(), logger, func() []*MountEntry { return entries }, router, core)
+	clock := timeutil.NewManualClock(time.Now())
+	m.clock = clock
+	m.period = time.Minute
+	m.tickPeriod = 10 * time.Second
+
+	numRollbacks := func(path string) int {
+		backends[path].Lock()
+		defer backends[path].Unlock()
+		return len(backends[path].Paths)
+	}
+
+	// Every mount is rolled back on the first tick, then on their own period.
+	for i := 0; i < 12; i++ {
+		m.triggerRollbacks()
+		m.inflightAll.Wait()
+		clock.Advance(10 * time.Second)
+	}
+
+	expected := map[string]int{"default": 2, "fast": 12, "slow": 1}
+	for path, n := range expected {
+		if got := numRollbacks(path); got != n {
+			t.Fatalf("expected %d rollbacks of %q, got %d", n, path, got)
+		}
+	}
+	if m.tickPeriod != 10*time.Second {
+		t.Fatalf("expected the tick period to follow the shortest period, got %s", m.tickPeriod)
+	}
+
+	// Forcing a rollback rolls back every mount.
+	m.resetRollbackSchedule()
+	m.triggerRollbacks()
+	m.inflightAll.Wait()
+	expected = map[string]int{"default": 3, "fast": 13, "slow": 2}
+	for path, n := range expected {
+		if got := numRollbacks(path); got != n {
+			t.Fatalf("expected %d rollbacks of %q after reset, got %d", n, path, got)
+		}
+	}
+}
+
+// TestRollbackManager_Backoff ensures that mounts whose rollbacks keep failing
+// are rolled back less and less often, until a rollback succeeds.
+func TestRollbackManager_Backoff(t *testing.T) {
+	core, _, _ := TestCoreUnsealed(t)
+	core.rollbackMaxBackoff = 40 * time.Second
+	router := NewRouter()
+	_, barrier, _ := mockBarrier(t)
+
+	failing := true
+	backend := &NoopBackend{
+		RequestHandler: func(context.Context, *logical.Request) (*logical.Response, error) {
+			if failing {
+				return nil, fmt.Errorf("rollback failed")
+			}
+			return nil, nil
+		},
+	}
+	meUUID, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := &MountEntry{
+		Path:        "failing/",
+		UUID:        meUUID,
+		Accessor:    "failingaccessor",
+		NamespaceID: namespace.RootNamespaceID,
+		namespace:   namespace.RootNamespace,
+	}
+	view := NewBarrierView(barrier, "logical/"+meUUID+"/")
+	if err := router.Mount(backend, "failing/", entry, view); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	logger := logging.NewVaultLogger(log.Trace)
+	m := NewRollbackManager(context.Background(), logger, func() []*MountEntry { return []*MountEntry{entry} }, router, core)
+	clock := timeutil.NewManualClock(time.Now())
+	m.clock = clock
+	m.period = 10 * time.Second
+	m.tickPeriod = 10 * time.Second
+
+	numRollbacks := func() int {
+		backend.Lock()
+		defer backend.Unlock()
+		return len(backend.Paths)
+	}
+	tick := func(n int) {
+		for i := 0; i < n; i++ {
+			m.triggerRollbacks()
+			m.inflightAll.Wait()
+			clock.Advance(10 * time.Second)
+		}
+	}
+
+	// The delay doubles after each failure, from 10s to 20s, then 40s at
+	// most: rollbacks run at 0s, 20s, 60s and 100s.
+	tick(11)
+	if n := numRollbacks(); n != 4 {
+		t.Fatalf("expected 4 rollbacks, got %d", n)
+	}
+	if s := m.Status()["failing/"]; s.ConsecutiveFailures != 4 || s.Backoff != 40*time.Second {
+		t.Fatalf("bad status: %#v", s)
+	}
+
+	// The next rollback runs at 140s and succeeds, and the mount is rolled
+	// back every period again.
+	failing = false
+	tick(6)
+	if n := numRollbacks(); n != 7 {
+		t.Fatalf("expected 7 rollbacks, got %d", n)
+	}
+	if s := m.Status()["failing/"]; s.ConsecutiveFailures != 0 || s.Backoff != 0 {
+		t.Fatalf("bad status: %#v", s)
+	}
+
+	// With jitter, the delay is between half of the backoff and all of it,
+	// and never shorter than the period.
+	core.rollbackBackoffJitter = true
+	m.recordRollback("failing/", time.Now(), fmt.Errorf("rollback failed"))
+	m.recordRollback("failing/", time.Now(), fmt.Errorf("rollback failed"))
+	for i := 0; i < 100; i++ {
+		m.recordRollback("failing/", time.Now(), fmt.Errorf("rollback failed"))
+		if backoff := m.rollbackBackoff("failing/", 10*time.Second); backoff < 20*time.Second || backoff > 40*time.Second {
+			t.Fatalf("expected a backoff between 20s and 40s, got %s", backoff)
+		}
+	}
+	if backoff := m.rollbackBackoff("failing/", time.Minute); backoff != time.Minute {
+		t.Fatalf("expected the backoff not to be shorter than the period, got %s", backoff)
+	}
+}
+
 func TestRollbackManager_Join(t *testing.T) {
 	m, backend := mockRollback(t)
 	if len(backend.Paths) > 0 {
@@ -120,3 +321,154 @@ func TestRollbackManager_Join(t *testing.T) {
 		t.Fatalf("Error on rollback:%v", err)
 	}
 }
+
+// TestRollbackManager_Workers ensures that scheduled rollbacks are run by a
+// bounded number of workers.
+func TestRollbackManager_Workers(t *testing.T) {
+	core, _, _ := TestCoreUnsealed(t)
+	core.rollbackWorkers = 2
+	router := NewRouter()
+	_, barrier, _ := mockBarrier(t)
+
+	var l sync.Mutex
+	var running, maxRunning, done int
+	handler := func(ctx context.Context, req *logical.Request) (*logical.Response, error) {
+		l.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		l.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		l.Lock()
+		running--
+		done++
+		l.Unlock()
+		return nil, nil
+	}
+
+	var entries []*MountEntry
+	for i := 0; i < 10; i++ {
+		path := fmt.Sprintf("mount%d/", i)
+		meUUID, err := uuid.GenerateUUID()
+		if err != nil {
+			t.Fatal(err)
+		}
+		entry := &MountEntry{
+			Path:        path,
+			UUID:        meUUID,
+			Accessor:    fmt.Sprintf("accessor%d", i),
+			NamespaceID: namespace.RootNamespaceID,
+			namespace:   namespace.RootNamespace,
+		}
+		view := NewBarrierView(barrier, "logical/"+meUUID+"/")
+		if err := router.Mount(&NoopBackend{RequestHandler: handler}, path, entry, view); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	logger := logging.NewVaultLogger(log.Trace)
+	m := NewRollbackManager(context.Background(), logger, func() []*MountEntry { return entries }, router, core)
+	m.period = time.Hour
+	m.Start()
+	defer m.Stop()
+
+	m.triggerRollbacks()
+
+	// Manual rollbacks of queued mounts don't wait for the workers.
+	if err := m.Rollback(namespace.RootContext(nil), "mount9/"); err != nil {
+		t.Fatal(err)
+	}
+
+	m.inflightAll.Wait()
+	l.Lock()
+	defer l.Unlock()
+	if done != len(entries) {
+		t.Fatalf("expected %d rollbacks, got %d", len(entries), done)
+	}
+	if maxRunning > 3 {
+		t.Fatalf("expected at most 2 scheduled and 1 manual rollbacks at once, got %d", maxRunning)
+	}
+}
+
+// TestRollbackManager_Status ensures that the outcome of the last rollback of
+// each mount is recorded.
+func TestRollbackManager_Status(t *testing.T) {
+	core, _, _ := TestCoreUnsealed(t)
+	router := NewRouter()
+	_, barrier, _ := mockBarrier(t)
+
+	var failing bool
+	handlers := map[string]func(context.Context, *logical.Request) (*logical.Response, error){
+		"ok/": func(context.Context, *logical.Request) (*logical.Response, error) {
+			return nil, nil
+		},
+		"failing/": func(context.Context, *logical.Request) (*logical.Response, error) {
+			if failing {
+				return nil, fmt.Errorf("rollback failed")
+			}
+			return nil, nil
+		},
+	}
+	for path, handler := range handlers {
+		meUUID, err := uuid.GenerateUUID()
+		if err != nil {
+			t.Fatal(err)
+		}
+		entry := &MountEntry{
+			Path:        path,
+			UUID:        meUUID,
+			Accessor:    "accessor-" + path,
+			NamespaceID: namespace.RootNamespaceID,
+			namespace:   namespace.RootNamespace,
+		}
+		view := NewBarrierView(barrier, "logical/"+meUUID+"/")
+		if err := router.Mount(&NoopBackend{RequestHandler: handler}, path, entry, view); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	logger := logging.NewVaultLogger(log.Trace)
+	m := NewRollbackManager(context.Background(), logger, func() []*MountEntry { return nil }, router, core)
+	ctx := namespace.RootContext(nil)
+
+	if err := m.Rollback(ctx, "ok/"); err != nil {
+		t.Fatal(err)
+	}
+	failing = true
+	for i := 0; i < 2; i++ {
+		if err := m.Rollback(ctx, "failing/"); err == nil {
+			t.Fatal("expected an error")
+		}
+	}
+
+	status := m.Status()
+	if len(status) != 2 {
+		t.Fatalf("expected the status of 2 mounts, got %#v", status)
+	}
+	if s := status["ok/"]; s.LastRollback.IsZero() || s.LastError != "" || s.ConsecutiveFailures != 0 {
+		t.Fatalf("bad status: %#v", s)
+	}
+	if s := status["failing/"]; s.LastError != "rollback failed" || s.LastErrorTime.IsZero() || s.ConsecutiveFailures != 2 {
+		t.Fatalf("bad status: %#v", s)
+	}
+
+	// Failures are reset by the next rollback which succeeds, but the last
+	// error is kept.
+	failing = false
+	if err := m.Rollback(ctx, "failing/"); err != nil {
+		t.Fatal(err)
+	}
+	if s := m.Status()["failing/"]; s.LastError != "rollback failed" || s.ConsecutiveFailures != 0 {
+		t.Fatalf("bad status: %#v", s)
+	}
+
+	// Mounts which were removed are forgotten.
+	m.triggerRollbacks()
+	if status := m.Status(); len(status) != 0 {
+		t.Fatalf("expected no status, got %#v", status)
+	}
+}
@@ -5,8 +5,11 @@ package vault
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -65,6 +68,7 @@ type routeEntry struct {
 	storagePrefix string
 	rootPaths     atomic.Value
 	loginPaths    atomic.Value
+	limiter       atomic.Pointer[requestLimiter]
 	l             sync.RWMutex
 }
 
@@ -201,6 +205,11 @@ func (r *Router) Mount(backend logical.Backend, prefix string, mountEntry *Mount
 		return err
 	}
 	re.loginPaths.Store(loginPathsEntry)
+	limiter, err := newRequestLimiter(paths)
+	if err != nil {
+		return err
+	}
+	re.limiter.Store(limiter)
 
 	switch {
 	case prefix == "":
@@ -613,6 +622,15 @@ func (r *Router) routeCommon(ctx context.Context, req *logical.Request, existenc
 		}
 	}
 
+	if !existenceCheck {
+		if max := re.mountEntry.Config.MaxRequestSize; max > 0 {
+			if size := encodedSize(req.Data); size > max {
+				metrics.IncrCounterWithLabels([]string{"route", "request_too_large"}, 1, []metrics.Label{{Name: "mount_point", Value: mount}})
+				return nil, false, false, fmt.Errorf("%w: request data of %d bytes exceeds the maximum of %d bytes of mount %q", logical.ErrRequestTooLarge, size, max, mount)
+			}
+		}
+	}
+
 	// Adjust the path to exclude the routing prefix
 	originalPath := req.Path
 	req.Path = strings.TrimPrefix(ns.Path+req.Path, mount)
@@ -630,6 +648,13 @@ func (r *Router) routeCommon(ctx context.Context, req *logical.Request, existenc
 	// Attach the storage view for the request
 	req.Storage = re.storageView
 
+	// If the request's timeline is being captured, record the storage
+	// operations made by the backend.
+	timeline := requestTimelineFromContext(ctx)
+	if timeline != nil {
+		req.Storage = &timelineStorage{Storage: re.storageView, timeline: timeline}
+	}
+
 	originalEntityID := req.EntityID
 
 	// Hash the request token unless the request is being routed to the token
@@ -779,7 +804,30 @@ func (r *Router) routeCommon(ctx context.Context, req *logical.Request, existenc
 		ok, exists, err := re.backend.HandleExistenceCheck(ctx, req)
 		return nil, ok, exists, err
 	} else {
+		release, err := re.limiter.Load().admit(req)
+		if err != nil {
+			metrics.IncrCounterWithLabels([]string{"route", "too_many_concurrent_requests"}, 1, []metrics.Label{{Name: "mount_point", Value: mount}})
+			return nil, false, false, err
+		}
+		defer release()
+
+		endBackendPhase := timeline.StartPhase(RequestPhaseBackendHandler)
 		resp, err := re.backend.HandleRequest(ctx, req)
+		endBackendPhase()
+
+		// The size of responses is only known once the backend has handled
+		// the request, so only the responses to reads and lists are withheld:
+		// other requests may have had side effects, such as issuing a
+		// certificate, which the caller would never learn of. Responses
+		// issuing leases or tokens are never withheld either, as their
+		// credentials would be left behind without anyone knowing of them.
+		if max := re.mountEntry.Config.MaxResponseSize; max > 0 && resp != nil && withholdableResponse(req.Operation) && resp.Secret == nil && resp.Auth == nil {
+			if size := encodedSize(resp.Data); size > max {
+				metrics.IncrCounterWithLabels([]string{"route", "response_too_large"}, 1, []metrics.Label{{Name: "mount_point", Value: mount}})
+				return nil, false, false, fmt.Errorf("%w: response data of %d bytes exceeds the maximum of %d bytes of mount %q", logical.ErrResponseTooLarge, size, max, mount)
+			}
+		}
+
 		if resp != nil {
 			if len(allowedResponseHeaders) > 0 {
 				resp.Headers = filteredHeaders(resp.Headers, allowedResponseHeaders, nil)
@@ -1027,6 +1075,98 @@ func pathsToRadix(paths []string) *radix.Tree {
 	return tree
 }
 
+// withholdableResponse returns whether the responses to requests of the given
+// operation may be withheld for exceeding the maximum response size of their
+// mount, which is only the case for operations without side effects.
+func withholdableResponse(op logical.Operation) bool {
+	switch op {
+	case logical.ReadOperation, logical.ListOperation:
+		return true
+	default:
+		return false
+	}
+}
+
+// encodedSize returns the size of the JSON encoding of the data of a request
+// or response, which is what the size limits of mounts apply to. The size is
+// computed by walking the data rather than encoding it, so the escaping of
+// strings is not counted, and only the values of types that JSON doesn't
+// decode to are encoded to be measured.
+func encodedSize(data map[string]interface{}) int64 {
+	if len(data) == 0 {
+		return 0
+	}
+	return jsonSize(data)
+}
+
+func jsonSize(v interface{}) int64 {
+	switch v := v.(type) {
+	case nil:
+		return int64(len("null"))
+	case bool:
+		if v {
+			return int64(len("true"))
+		}
+		return int64(len("false"))
+	case string:
+		return int64(len(v)) + 2
+	case []byte:
+		return int64(base64.StdEncoding.EncodedLen(len(v))) + 2
+	case json.Number:
+		return int64(len(v))
+	case int:
+		return int64(len(strconv.Itoa(v)))
+	case int64:
+		return int64(len(strconv.FormatInt(v, 10)))
+	case float64:
+		return int64(len(strconv.FormatFloat(v, 'g', -1, 64)))
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return 2
+		}
+		// Braces, and the quotes and colon of each key, and commas
+		size := int64(1 + 4*len(v))
+		for key, value := range v {
+			size += int64(len(key)) + jsonSize(value)
+		}
+		return size
+	case map[string]string:
+		if len(v) == 0 {
+			return 2
+		}
+		size := int64(1 + 6*len(v))
+		for key, value := range v {
+			size += int64(len(key) + len(value))
+		}
+		return size
+	case []interface{}:
+		// Brackets and commas
+		size := int64(1 + len(v))
+		if len(v) == 0 {
+			size++
+		}
+		for _, value := range v {
+			size += jsonSize(value)
+		}
+		return size
+	case []string:
+		size := int64(1 + 3*len(v))
+		if len(v) == 0 {
+			size++
+		}
+		for _, value := range v {
+			size += int64(len(value))
+		}
+		return size
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return 0
+		}
+		return int64(len(encoded))
+	}
+}
+
 // filteredHeaders returns a headers map[string][]string that
 // contains the filtered values contained in candidateHeaders. Filtering of
 // candidateHeaders from the origHeaders is done is a case-insensitive manner.
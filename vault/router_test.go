@@ -4,6 +4,8 @@
 package vault
 
 import (
+	"context"
+	"errors"
 	"reflect"
 	"strings"
 	"testing"
@@ -11,6 +13,7 @@ import (
 	"github.com/hashicorp/go-uuid"
 	"github.com/hashicorp/vault/helper/namespace"
 	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRouter_Mount(t *testing.T) {
@@ -528,6 +531,164 @@ func TestRouter_Untaint(t *testing.T) {
 	}
 }
 
+func TestRouter_MaxSizes(t *testing.T) {
+	r := NewRouter()
+	_, barrier, _ := mockBarrier(t)
+	view := NewBarrierView(barrier, "logical/")
+
+	meUUID, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := &NoopBackend{
+		Response: &logical.Response{
+			Data: map[string]interface{}{
+				"value": strings.Repeat("a", 100),
+			},
+		},
+	}
+	me := &MountEntry{
+		UUID:        meUUID,
+		Accessor:    "kvaccessor",
+		NamespaceID: namespace.RootNamespaceID,
+		namespace:   namespace.RootNamespace,
+		Config: MountConfig{
+			MaxRequestSize:  50,
+			MaxResponseSize: 200,
+		},
+	}
+	err = r.Mount(n, "kv/", me, view)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	req := &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "kv/foo",
+		Data: map[string]interface{}{
+			"value": strings.Repeat("a", 50),
+		},
+	}
+	_, err = r.Route(namespace.RootContext(nil), req)
+	if !errors.Is(err, logical.ErrRequestTooLarge) {
+		t.Fatalf("expected request to be too large, got: %v", err)
+	}
+	if len(n.Paths) != 0 {
+		t.Fatalf("request should not have reached the backend: %v", n.Paths)
+	}
+	if req.Path != "kv/foo" {
+		t.Fatalf("bad path: %s", req.Path)
+	}
+
+	req.Data["value"] = "a"
+	resp, err := r.Route(namespace.RootContext(nil), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp != n.Response {
+		t.Fatalf("bad response: %#v", resp)
+	}
+
+	// Responses to writes are not withheld, as the write has already been
+	// handled
+	n.Response.Data["value"] = strings.Repeat("a", 200)
+	resp, err = r.Route(namespace.RootContext(nil), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp != n.Response {
+		t.Fatalf("bad response: %#v", resp)
+	}
+
+	req.Operation = logical.ReadOperation
+	req.Data = nil
+	_, err = r.Route(namespace.RootContext(nil), req)
+	if !errors.Is(err, logical.ErrResponseTooLarge) {
+		t.Fatalf("expected response to be too large, got: %v", err)
+	}
+
+	// Responses issuing leases are not withheld
+	n.Response.Secret = &logical.Secret{}
+	resp, err = r.Route(namespace.RootContext(nil), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp != n.Response {
+		t.Fatalf("bad response: %#v", resp)
+	}
+}
+
+// TestRouter_ConcurrencyLimits verifies that requests exceeding the
+// concurrency limits of their path or the cost limit of their backend are
+// rejected before reaching the backend.
+func TestRouter_ConcurrencyLimits(t *testing.T) {
+	r := NewRouter()
+	_, barrier, _ := mockBarrier(t)
+	view := NewBarrierView(barrier, "logical/")
+
+	entered := make(chan struct{})
+	unblock := make(chan struct{})
+	n := &NoopBackend{
+		Limits: []*logical.PathLimit{
+			{Pattern: "^slow$", MaxConcurrency: 1, Cost: 2},
+		},
+		MaxConcurrentCost: 2,
+		RequestHandler: func(ctx context.Context, req *logical.Request) (*logical.Response, error) {
+			if req.Path == "slow" {
+				entered <- struct{}{}
+				<-unblock
+			}
+			return nil, nil
+		},
+	}
+	meUUID, err := uuid.GenerateUUID()
+	require.NoError(t, err)
+	me := &MountEntry{
+		Path:        "limited/",
+		UUID:        meUUID,
+		Accessor:    "limitedaccessor",
+		NamespaceID: namespace.RootNamespaceID,
+		namespace:   namespace.RootNamespace,
+	}
+	require.NoError(t, r.Mount(n, "limited/", me, view))
+
+	route := func(op logical.Operation, path string) error {
+		_, err := r.Route(namespace.RootContext(nil), &logical.Request{
+			Operation: op,
+			Path:      "limited/" + path,
+		})
+		return err
+	}
+
+	require.NoError(t, route(logical.UpdateOperation, "fast"))
+
+	done := make(chan error)
+	go func() {
+		done <- route(logical.UpdateOperation, "slow")
+	}()
+	<-entered
+
+	n.Lock()
+	handled := len(n.Requests)
+	n.Unlock()
+	require.ErrorIs(t, route(logical.UpdateOperation, "slow"), logical.ErrTooManyConcurrentRequests)
+	require.ErrorIs(t, route(logical.UpdateOperation, "fast"), logical.ErrTooManyConcurrentRequests)
+	require.NoError(t, route(logical.HelpOperation, "fast"))
+
+	close(unblock)
+	require.NoError(t, <-done)
+	n.Lock()
+	require.Len(t, n.Requests, handled+2)
+	n.Unlock()
+
+	require.NoError(t, route(logical.UpdateOperation, "fast"))
+	go func() {
+		done <- route(logical.UpdateOperation, "slow")
+	}()
+	<-entered
+	require.NoError(t, <-done)
+}
+
 func TestPathsToRadix(t *testing.T) {
 	// Provide real paths
 	paths := []string{
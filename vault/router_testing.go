@@ -29,6 +29,10 @@ type NoopBackend struct {
 	DefaultLeaseTTL time.Duration
 	MaxLeaseTTL     time.Duration
 	BackendType     logical.BackendType
+
+	// Limits and MaxConcurrentCost are reported in the special paths
+	Limits            []*logical.PathLimit
+	MaxConcurrentCost int
 }
 
 func NoopBackendFactory(_ context.Context, _ *logical.BackendConfig) (logical.Backend, error) {
@@ -69,8 +73,10 @@ func (n *NoopBackend) HandleExistenceCheck(ctx context.Context, req *logical.Req
 
 func (n *NoopBackend) SpecialPaths() *logical.Paths {
 	return &logical.Paths{
-		Root:            n.Root,
-		Unauthenticated: n.Login,
+		Root:              n.Root,
+		Unauthenticated:   n.Login,
+		Limits:            n.Limits,
+		MaxConcurrentCost: n.MaxConcurrentCost,
 	}
 }
 
@@ -0,0 +1,367 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/vault/secretsimport"
+)
+
+const (
+	// maxSecretsImportResults caps the number of per-secret results reported
+	// by an import.
+	maxSecretsImportResults = 10000
+)
+
+// SecretsImportStatus is the status of an import.
+type SecretsImportStatus string
+
+const (
+	SecretsImportInProgressStatus SecretsImportStatus = "in-progress"
+	SecretsImportSuccessStatus    SecretsImportStatus = "success"
+	SecretsImportFailureStatus    SecretsImportStatus = "failure"
+	SecretsImportCanceledStatus   SecretsImportStatus = "canceled"
+)
+
+// The actions taken, or which would be taken in a dry run, for the secrets of
+// an import.
+const (
+	// secretsImportCreate imports a secret to a new kv path.
+	secretsImportCreate = "create"
+	// secretsImportUpdate writes a new version of an existing kv secret.
+	secretsImportUpdate = "update"
+	// secretsImportUnchanged skips a secret already imported with the same
+	// data.
+	secretsImportUnchanged = "unchanged"
+	// secretsImportConflict skips a secret whose kv path holds other data,
+	// when overwriting isn't allowed.
+	secretsImportConflict = "conflict"
+	// secretsImportDuplicate skips a secret mapped to the path of a secret
+	// imported before it.
+	secretsImportDuplicate = "duplicate"
+	// secretsImportUnmapped skips a secret matching none of the rules.
+	secretsImportUnmapped = "unmapped"
+	// secretsImportFailed reports a secret which couldn't be read or written.
+	secretsImportFailed = "failed"
+)
+
+// SecretsImportResult is the outcome of the import of a secret.
+type SecretsImportResult struct {
+	Name   string
+	Path   string
+	Action string
+	Error  string
+}
+
+// SecretsImportInfo reports the progress of an import of the secrets of a
+// secret manager into a kv-v2 mount.
+type SecretsImportInfo struct {
+	ID         string
+	SourceType secretsimport.SourceType
+	Mount      string
+	DryRun     bool
+	Overwrite  bool
+	Status     SecretsImportStatus
+	Error      string
+	Total      int
+	Processed  int
+	Counts     map[string]int
+	Results    []SecretsImportResult
+
+	// Truncated is set once results are dropped, past
+	// maxSecretsImportResults.
+	Truncated bool
+
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// secretsImport is an import in progress or done.
+type secretsImport struct {
+	l      sync.RWMutex
+	info   SecretsImportInfo
+	source secretsimport.Source
+	mapper *secretsimport.Mapper
+	cancel context.CancelFunc
+
+	// handle authorizes, audits and routes the reads and writes of the
+	// secrets of the mount.
+	handle     kvRequestFunc
+	connection *logical.Connection
+}
+
+// snapshot returns a copy of the progress of the import.
+func (i *secretsImport) snapshot() SecretsImportInfo {
+	i.l.RLock()
+	defer i.l.RUnlock()
+
+	info := i.info
+	info.Counts = make(map[string]int, len(i.info.Counts))
+	for action, count := range i.info.Counts {
+		info.Counts[action] = count
+	}
+	info.Results = append([]SecretsImportResult(nil), i.info.Results...)
+	return info
+}
+
+// record records the outcome of the import of a secret.
+func (i *secretsImport) record(result SecretsImportResult) {
+	i.l.Lock()
+	defer i.l.Unlock()
+
+	i.info.Processed++
+	i.info.Counts[result.Action]++
+	if len(i.info.Results) < maxSecretsImportResults {
+		i.info.Results = append(i.info.Results, result)
+	} else {
+		i.info.Truncated = true
+	}
+}
+
+// finish records the end of the import.
+func (i *secretsImport) finish(err error) {
+	i.l.Lock()
+	defer i.l.Unlock()
+
+	i.info.EndTime = time.Now()
+	switch {
+	case err == nil:
+		i.info.Status = SecretsImportSuccessStatus
+	case errors.Is(err, context.Canceled):
+		i.info.Status = SecretsImportCanceledStatus
+		i.info.Error = err.Error()
+	default:
+		i.info.Status = SecretsImportFailureStatus
+		i.info.Error = err.Error()
+	}
+}
+
+// startSecretsImport starts importing the secrets of the source into the
+// kv-v2 mount at the given path of the namespace of the context, and returns
+// the ID of the import. The import runs in the background, until it is done,
+// canceled, or the node is sealed. The reads and writes of the secrets of the
+// mount are authorized with the ACL of the token of the request when the
+// import starts, and audited as requests of their own.
+func (c *Core) startSecretsImport(ctx context.Context, req *logical.Request, sourceType secretsimport.SourceType, source secretsimport.Source, mapper *secretsimport.Mapper, mount string, dryRun, overwrite bool) (string, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	handle, err := c.kvRequestHandler(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	importID, err := uuid.GenerateUUID()
+	if err != nil {
+		return "", fmt.Errorf("error generating uuid for import: %w", err)
+	}
+
+	importCtx, cancel := context.WithCancel(namespace.ContextWithNamespace(c.activeContext, ns))
+	job := &secretsImport{
+		info: SecretsImportInfo{
+			ID:         importID,
+			SourceType: sourceType,
+			Mount:      ns.Path + mount,
+			DryRun:     dryRun,
+			Overwrite:  overwrite,
+			Status:     SecretsImportInProgressStatus,
+			Counts:     make(map[string]int),
+			StartTime:  time.Now(),
+		},
+		source:     source,
+		mapper:     mapper,
+		cancel:     cancel,
+		handle:     handle,
+		connection: req.Connection,
+	}
+	c.secretsImportTracker.Store(importID, job)
+
+	go func() {
+		defer cancel()
+
+		logger := c.logger.Named("secrets-import").With("import_id", importID, "source_type", sourceType, "mount", job.info.Mount, "dry_run", dryRun)
+		logger.Info("starting secrets import")
+
+		err := c.importSecrets(importCtx, job, mount)
+		job.finish(err)
+
+		info := job.snapshot()
+		if err != nil {
+			logger.Error("secrets import failed", "error", err, "processed", info.Processed, "total", info.Total)
+			return
+		}
+		logger.Info("completed secrets import", "processed", info.Processed, "counts", info.Counts)
+	}()
+
+	return importID, nil
+}
+
+// importSecrets imports the secrets of the source of the import.
+func (c *Core) importSecrets(ctx context.Context, job *secretsImport, mount string) error {
+	source, mapper := job.source, job.mapper
+
+	names, err := source.List(ctx)
+	if err != nil {
+		return err
+	}
+	job.l.Lock()
+	job.info.Total = len(names)
+	job.l.Unlock()
+
+	imported := make(map[string]string, len(names))
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		result := SecretsImportResult{Name: name}
+		p, ok, err := mapper.Map(name)
+		switch {
+		case err != nil:
+			result.Action, result.Error = secretsImportFailed, err.Error()
+		case !ok:
+			result.Action = secretsImportUnmapped
+		case imported[p] != "":
+			result.Path = p
+			result.Action = secretsImportDuplicate
+			result.Error = fmt.Sprintf("path already imported from secret %q", imported[p])
+		default:
+			result.Path = p
+			imported[p] = name
+			result.Action, err = c.importSecret(ctx, job, name, mount, p)
+			if err != nil {
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					return ctxErr
+				}
+				result.Action, result.Error = secretsImportFailed, err.Error()
+			}
+		}
+		job.record(result)
+	}
+	return nil
+}
+
+// importSecret imports the named secret of the source of the import to the
+// given path of the kv-v2 mount, and returns the action taken.
+func (c *Core) importSecret(ctx context.Context, job *secretsImport, name, mount, path string) (string, error) {
+	secret, err := job.source.Read(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	c.stateLock.RLock()
+	defer c.stateLock.RUnlock()
+	if c.Sealed() {
+		return "", consts.ErrSealed
+	}
+
+	resp, err := job.handle(ctx, job.subRequest(logical.ReadOperation, mount+"data/"+path, nil))
+	if err != nil {
+		return "", fmt.Errorf("failed to read %q: %w", path, err)
+	}
+	if resp != nil && resp.IsError() {
+		return "", fmt.Errorf("failed to read %q: %w", path, resp.Error())
+	}
+
+	action, op := secretsImportCreate, logical.CreateOperation
+	if resp != nil && resp.Data["data"] != nil {
+		same, err := sameSecretData(resp.Data["data"], secret.Data)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case same:
+			return secretsImportUnchanged, nil
+		case !job.info.Overwrite:
+			return secretsImportConflict, nil
+		}
+		action, op = secretsImportUpdate, logical.UpdateOperation
+	}
+
+	if job.info.DryRun {
+		return action, nil
+	}
+
+	resp, err = job.handle(ctx, job.subRequest(op, mount+"data/"+path, map[string]interface{}{
+		"data": secret.Data,
+	}))
+	if err != nil {
+		return "", fmt.Errorf("failed to write %q: %w", path, err)
+	}
+	if resp != nil && resp.IsError() {
+		return "", fmt.Errorf("failed to write %q: %w", path, resp.Error())
+	}
+	return action, nil
+}
+
+// subRequest returns a request of the import to the given path.
+func (i *secretsImport) subRequest(op logical.Operation, path string, data map[string]interface{}) *logical.Request {
+	id, _ := uuid.GenerateUUID()
+	return &logical.Request{
+		ID:         id,
+		Operation:  op,
+		Path:       path,
+		Data:       data,
+		Connection: i.connection,
+	}
+}
+
+// sameSecretData returns whether the data of a kv secret and of an imported
+// secret are the same, comparing their JSON encodings so that numbers of
+// either are decoded alike.
+func sameSecretData(current interface{}, imported map[string]interface{}) (bool, error) {
+	a, err := json.Marshal(current)
+	if err != nil {
+		return false, err
+	}
+	b, err := json.Marshal(imported)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(a, b), nil
+}
+
+// readSecretsImport returns the progress of the import with the given ID.
+func (c *Core) readSecretsImport(importID string) *SecretsImportInfo {
+	raw, ok := c.secretsImportTracker.Load(importID)
+	if !ok {
+		return nil
+	}
+	info := raw.(*secretsImport).snapshot()
+	return &info
+}
+
+// listSecretsImports returns the IDs of the imports.
+func (c *Core) listSecretsImports() []string {
+	var ids []string
+	c.secretsImportTracker.Range(func(key, _ interface{}) bool {
+		ids = append(ids, key.(string))
+		return true
+	})
+	return ids
+}
+
+// cancelSecretsImport cancels the import with the given ID, and returns false
+// if there is none.
+func (c *Core) cancelSecretsImport(importID string) bool {
+	raw, ok := c.secretsImportTracker.Load(importID)
+	if !ok {
+		return false
+	}
+	raw.(*secretsImport).cancel()
+	return true
+}
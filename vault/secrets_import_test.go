@@ -0,0 +1,264 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	logicalKv "github.com/hashicorp/vault-plugin-secrets-kv"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/vault/secretsimport"
+	"github.com/stretchr/testify/require"
+)
+
+// testSecretsSource is a source of the secrets of a map. Secrets with an
+// empty value can't be read.
+type testSecretsSource map[string]string
+
+func (s testSecretsSource) List(context.Context) ([]string, error) {
+	var names []string
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (s testSecretsSource) Read(_ context.Context, name string) (*secretsimport.Secret, error) {
+	if s[name] == "" {
+		return nil, fmt.Errorf("access denied")
+	}
+	return &secretsimport.Secret{
+		Name: name,
+		Data: map[string]interface{}{"value": s[name]},
+	}, nil
+}
+
+func TestCore_SecretsImport(t *testing.T) {
+	AddTestLogicalBackend("kv", logicalKv.Factory)
+	defer func() {
+		delete(testLogicalBackends, "kv")
+	}()
+	c, _, root := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	require.NoError(t, c.mount(ctx, &MountEntry{
+		Table:   mountTableType,
+		Path:    "imported/",
+		Type:    "kv",
+		Options: map[string]string{"version": "2"},
+	}))
+
+	write := func(path string, data map[string]interface{}) {
+		t.Helper()
+		req := logical.TestRequest(t, logical.CreateOperation, "imported/data/"+path)
+		req.Data["data"] = data
+		req.ClientToken = root
+		// The mount may still be upgrading.
+		require.Eventually(t, func() bool {
+			_, err := c.HandleRequest(ctx, req)
+			return err == nil
+		}, 10*time.Second, 50*time.Millisecond)
+	}
+	read := func(path string) map[string]interface{} {
+		t.Helper()
+		req := logical.TestRequest(t, logical.ReadOperation, "imported/data/"+path)
+		req.ClientToken = root
+		resp, err := c.HandleRequest(ctx, req)
+		require.NoError(t, err)
+		if resp == nil || resp.Data["data"] == nil {
+			return nil
+		}
+		return resp.Data["data"].(map[string]interface{})
+	}
+	write("apps/billing/db", map[string]interface{}{"value": "admin"})
+	write("apps/billing/api", map[string]interface{}{"value": "old-key"})
+
+	source := testSecretsSource{
+		"prod/billing/db":    "admin",
+		"prod/billing/api":   "new-key",
+		"prod/billing/cache": "redis",
+		"legacy-cache":       "memcached",
+		"prod/billing/token": "",
+		"dev/billing/db":     "dev-admin",
+	}
+	mapper, err := secretsimport.NewMapper([]interface{}{
+		map[string]interface{}{"match": `prod/(.+)`, "path": "apps/$1"},
+		map[string]interface{}{"match": `legacy-(.+)`, "path": "apps/billing/$1"},
+	})
+	require.NoError(t, err)
+
+	runImport := func(dryRun, overwrite bool) *SecretsImportInfo {
+		t.Helper()
+		importID, err := c.startSecretsImport(ctx, &logical.Request{ClientToken: root}, secretsimport.SourceTypeAWS, source, mapper, "imported/", dryRun, overwrite)
+		require.NoError(t, err)
+
+		var info *SecretsImportInfo
+		require.Eventually(t, func() bool {
+			info = c.readSecretsImport(importID)
+			return info.Status != SecretsImportInProgressStatus
+		}, 10*time.Second, 50*time.Millisecond)
+		require.Equal(t, SecretsImportSuccessStatus, info.Status, info.Error)
+		require.Equal(t, len(source), info.Total)
+		require.Equal(t, len(source), info.Processed)
+		return info
+	}
+
+	// A dry run reports the actions without writing secrets.
+	info := runImport(true, false)
+	require.Equal(t, map[string]int{
+		secretsImportCreate:    1,
+		secretsImportUnchanged: 1,
+		secretsImportConflict:  1,
+		secretsImportDuplicate: 1,
+		secretsImportUnmapped:  1,
+		secretsImportFailed:    1,
+	}, info.Counts)
+	require.Contains(t, info.Results, SecretsImportResult{Name: "legacy-cache", Path: "apps/billing/cache", Action: secretsImportCreate})
+	require.Contains(t, info.Results, SecretsImportResult{Name: "prod/billing/token", Path: "apps/billing/token", Action: secretsImportFailed, Error: "access denied"})
+	require.Nil(t, read("apps/billing/cache"))
+
+	info = runImport(false, true)
+	require.Equal(t, map[string]int{
+		secretsImportCreate:    1,
+		secretsImportUpdate:    1,
+		secretsImportUnchanged: 1,
+		secretsImportDuplicate: 1,
+		secretsImportUnmapped:  1,
+		secretsImportFailed:    1,
+	}, info.Counts)
+	require.Equal(t, map[string]interface{}{"value": "memcached"}, read("apps/billing/cache"))
+	require.Equal(t, map[string]interface{}{"value": "new-key"}, read("apps/billing/api"))
+
+	// Importing again changes nothing.
+	info = runImport(false, true)
+	require.Equal(t, 3, info.Counts[secretsImportUnchanged])
+
+	// The imports are reported by the API.
+	resp, err := c.systemBackend.HandleRequest(ctx, logical.TestRequest(t, logical.ListOperation, "import/status"))
+	require.NoError(t, err)
+	require.Len(t, resp.Data["keys"], 3)
+
+	resp, err = c.systemBackend.HandleRequest(ctx, logical.TestRequest(t, logical.ReadOperation, "import/status/"+info.ID))
+	require.NoError(t, err)
+	importInfo := resp.Data["import_info"].(map[string]interface{})
+	require.Equal(t, "success", importInfo["status"])
+	require.Equal(t, "imported/", importInfo["mount"])
+	require.Len(t, importInfo["results"], len(source))
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "import")
+	req.Data = map[string]interface{}{
+		"source_type": "aws-secrets-manager",
+		"mount":       "secret",
+	}
+	resp, err = c.systemBackend.HandleRequest(ctx, req)
+	require.NoError(t, err)
+	require.EqualError(t, resp.Error(), `no kv-v2 mount at "secret/"`)
+
+	_, err = c.systemBackend.HandleRequest(ctx, logical.TestRequest(t, logical.UpdateOperation, "import/cancel/missing"))
+	require.ErrorIs(t, err, logical.ErrInvalidRequest)
+}
+
+// TestCore_SecretsImport_ACL ensures that the reads and writes of an import
+// are authorized with the policies of the token which started it.
+func TestCore_SecretsImport_ACL(t *testing.T) {
+	AddTestLogicalBackend("kv", logicalKv.Factory)
+	defer func() {
+		delete(testLogicalBackends, "kv")
+	}()
+	c, _, root := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	require.NoError(t, c.mount(ctx, &MountEntry{
+		Table:   mountTableType,
+		Path:    "imported/",
+		Type:    "kv",
+		Options: map[string]string{"version": "2"},
+	}))
+
+	policy, err := ParseACLPolicy(namespace.RootNamespace, `
+path "imported/data/apps/*" {
+	capabilities = ["create", "read", "update"]
+}
+`)
+	require.NoError(t, err)
+	policy.Name = "importer"
+	require.NoError(t, c.policyStore.SetPolicy(ctx, policy))
+	testMakeServiceTokenViaCore(t, c, root, "importer-token", "1h", []string{"importer"})
+
+	// The mount may still be upgrading.
+	req := logical.TestRequest(t, logical.CreateOperation, "imported/data/apps/api")
+	req.Data["data"] = map[string]interface{}{"value": "key"}
+	req.ClientToken = root
+	require.Eventually(t, func() bool {
+		_, err := c.HandleRequest(ctx, req)
+		return err == nil
+	}, 10*time.Second, 50*time.Millisecond)
+
+	source := testSecretsSource{
+		"apps/db":  "admin",
+		"infra/db": "root",
+	}
+	importID, err := c.startSecretsImport(ctx, &logical.Request{ClientToken: "importer-token"}, secretsimport.SourceTypeAWS, source, &secretsimport.Mapper{}, "imported/", false, false)
+	require.NoError(t, err)
+	var info *SecretsImportInfo
+	require.Eventually(t, func() bool {
+		info = c.readSecretsImport(importID)
+		return info.Status != SecretsImportInProgressStatus
+	}, 10*time.Second, 50*time.Millisecond)
+
+	require.Equal(t, SecretsImportSuccessStatus, info.Status, info.Error)
+	require.Equal(t, map[string]int{
+		secretsImportCreate: 1,
+		secretsImportFailed: 1,
+	}, info.Counts)
+	require.Contains(t, info.Results, SecretsImportResult{Name: "infra/db", Path: "infra/db", Action: secretsImportFailed, Error: `failed to read "infra/db": permission denied`})
+
+	req = logical.TestRequest(t, logical.ReadOperation, "imported/data/infra/db")
+	req.ClientToken = root
+	resp, err := c.HandleRequest(ctx, req)
+	require.NoError(t, err)
+	require.True(t, resp == nil || resp.Data["data"] == nil)
+
+	// Imports can't be started without a valid token.
+	_, err = c.startSecretsImport(ctx, &logical.Request{ClientToken: "missing"}, secretsimport.SourceTypeAWS, source, &secretsimport.Mapper{}, "imported/", false, false)
+	require.ErrorIs(t, err, logical.ErrPermissionDenied)
+}
+
+// TestCore_SecretsImport_Cancel ensures that canceled imports stop.
+func TestCore_SecretsImport_Cancel(t *testing.T) {
+	c, _, root := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	listed := make(chan struct{})
+	source := &blockingSecretsSource{listed: listed}
+	importID, err := c.startSecretsImport(ctx, &logical.Request{ClientToken: root}, secretsimport.SourceTypeGCP, source, &secretsimport.Mapper{}, "secret/", false, false)
+	require.NoError(t, err)
+
+	<-listed
+	require.True(t, c.cancelSecretsImport(importID))
+	require.Eventually(t, func() bool {
+		return c.readSecretsImport(importID).Status == SecretsImportCanceledStatus
+	}, 10*time.Second, 50*time.Millisecond)
+}
+
+// blockingSecretsSource blocks reads until the import is canceled.
+type blockingSecretsSource struct {
+	listed chan struct{}
+}
+
+func (s *blockingSecretsSource) List(context.Context) ([]string, error) {
+	return []string{"foo", "bar"}, nil
+}
+
+func (s *blockingSecretsSource) Read(ctx context.Context, _ string) (*secretsimport.Secret, error) {
+	close(s.listed)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
@@ -0,0 +1,83 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package secretsimport
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+	"github.com/hashicorp/go-cleanhttp"
+)
+
+// awsSource imports the secrets of AWS Secrets Manager. The configuration
+// holds the region, static credentials and an optional endpoint, which must
+// be allowed by the operator.
+type awsSource struct {
+	client secretsmanageriface.SecretsManagerAPI
+}
+
+func newAWSSource(config map[string]string, allowedEndpoints []string) (*awsSource, error) {
+	if err := requireConfig(config, "region", "access_key", "secret_key"); err != nil {
+		return nil, err
+	}
+
+	// The credentials are static so that the default credential chain,
+	// which would find the credentials of the server, is never used.
+	awsConf := aws.NewConfig().
+		WithCredentials(credentials.NewStaticCredentials(config["access_key"], config["secret_key"], config["session_token"])).
+		WithRegion(config["region"]).
+		WithHTTPClient(cleanhttp.DefaultClient())
+	if endpoint := config["endpoint"]; endpoint != "" {
+		if err := checkEndpoint(endpoint, allowedEndpoints); err != nil {
+			return nil, err
+		}
+		awsConf = awsConf.WithEndpoint(endpoint)
+	}
+
+	sess, err := session.NewSession(awsConf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %w", err)
+	}
+	return &awsSource{client: secretsmanager.New(sess)}, nil
+}
+
+func (s *awsSource) List(ctx context.Context) ([]string, error) {
+	var names []string
+	err := s.client.ListSecretsPagesWithContext(ctx, &secretsmanager.ListSecretsInput{}, func(out *secretsmanager.ListSecretsOutput, _ bool) bool {
+		for _, entry := range out.SecretList {
+			names = append(names, aws.StringValue(entry.Name))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+	return names, nil
+}
+
+func (s *awsSource) Read(ctx context.Context, name string) (*Secret, error) {
+	out, err := s.client.GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(name),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret %q: %w", name, err)
+	}
+
+	// Binary secrets are imported base64 encoded.
+	if out.SecretString == nil {
+		return &Secret{
+			Name: name,
+			Data: map[string]interface{}{
+				"value_base64": base64.StdEncoding.EncodeToString(out.SecretBinary),
+			},
+		}, nil
+	}
+	return newSecret(name, aws.StringValue(out.SecretString)), nil
+}
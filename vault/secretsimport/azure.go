@@ -0,0 +1,168 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package secretsimport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/Azure/go-autorest/autorest/adal"
+	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/hashicorp/go-cleanhttp"
+)
+
+// azureKeyVaultAPIVersion is the version of the Key Vault REST API used.
+const azureKeyVaultAPIVersion = "7.4"
+
+// azureToken is an Azure AD token for the Key Vault API.
+type azureToken interface {
+	EnsureFreshWithContext(ctx context.Context) error
+	OAuthToken() string
+}
+
+// azureSource imports the secrets of an Azure Key Vault. The configuration
+// holds the URL of the vault, and the tenant, client ID and secret of a
+// service principal. The URL must be the one of a vault of the Azure
+// environment, unless the operator allows it.
+type azureSource struct {
+	client   *http.Client
+	token    azureToken
+	vaultURL string
+}
+
+func newAzureSource(config map[string]string, allowedEndpoints []string) (*azureSource, error) {
+	if err := requireConfig(config, "vault_url", "tenant_id", "client_id", "client_secret"); err != nil {
+		return nil, err
+	}
+	vaultURL := strings.TrimSuffix(config["vault_url"], "/")
+
+	environmentName := config["environment"]
+	if environmentName == "" {
+		environmentName = "AzurePublicCloud"
+	}
+	environment, err := azure.EnvironmentFromName(environmentName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up Azure environment descriptor for name %q: %w", environmentName, err)
+	}
+	if err := checkAzureVaultURL(vaultURL, environment.KeyVaultDNSSuffix, allowedEndpoints); err != nil {
+		return nil, err
+	}
+	resource := strings.TrimSuffix(environment.ResourceIdentifiers.KeyVault, "/")
+
+	// The managed identity of the host is never used, as it is the identity
+	// of the server.
+	oauthConfig, err := adal.NewOAuthConfig(environment.ActiveDirectoryEndpoint, config["tenant_id"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure AD configuration: %w", err)
+	}
+	token, err := adal.NewServicePrincipalToken(*oauthConfig, config["client_id"], config["client_secret"], resource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create service principal token: %w", err)
+	}
+
+	return &azureSource{
+		client:   cleanhttp.DefaultClient(),
+		token:    token,
+		vaultURL: vaultURL,
+	}, nil
+}
+
+// checkAzureVaultURL returns an error unless the URL is the HTTPS URL of a
+// vault with the given DNS suffix, such as https://example.vault.azure.net,
+// or one of the allowed endpoints.
+func checkAzureVaultURL(vaultURL, dnsSuffix string, allowedEndpoints []string) error {
+	u, err := url.Parse(vaultURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse vault_url: %w", err)
+	}
+	if u.Scheme == "https" && u.User == nil && u.Port() == "" && u.Path == "" && u.RawQuery == "" && strings.HasSuffix(u.Hostname(), "."+dnsSuffix) {
+		return nil
+	}
+	return checkEndpoint(vaultURL, allowedEndpoints)
+}
+
+func (s *azureSource) List(ctx context.Context) ([]string, error) {
+	var names []string
+	next := fmt.Sprintf("%s/secrets?api-version=%s", s.vaultURL, azureKeyVaultAPIVersion)
+	for next != "" {
+		var page struct {
+			Value []struct {
+				ID         string `json:"id"`
+				Managed    bool   `json:"managed"`
+				Attributes struct {
+					Enabled bool `json:"enabled"`
+				} `json:"attributes"`
+			} `json:"value"`
+			NextLink string `json:"nextLink"`
+		}
+		if err := s.get(ctx, next, &page); err != nil {
+			return nil, fmt.Errorf("failed to list secrets: %w", err)
+		}
+
+		for _, item := range page.Value {
+			// Disabled secrets can't be read, and managed secrets back
+			// certificates, which aren't imported.
+			if !item.Attributes.Enabled || item.Managed {
+				continue
+			}
+			id, err := url.Parse(item.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse secret ID %q: %w", item.ID, err)
+			}
+			names = append(names, path.Base(id.Path))
+		}
+		next = page.NextLink
+	}
+	return names, nil
+}
+
+func (s *azureSource) Read(ctx context.Context, name string) (*Secret, error) {
+	var bundle struct {
+		Value string `json:"value"`
+	}
+	u := fmt.Sprintf("%s/secrets/%s?api-version=%s", s.vaultURL, url.PathEscape(name), azureKeyVaultAPIVersion)
+	if err := s.get(ctx, u, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to read secret %q: %w", name, err)
+	}
+	return newSecret(name, bundle.Value), nil
+}
+
+// get sends an authorized GET request to the Key Vault API, decoding the
+// response into out.
+func (s *azureSource) get(ctx context.Context, u string, out interface{}) error {
+	if err := s.token.EnsureFreshWithContext(ctx); err != nil {
+		return fmt.Errorf("failed to refresh token: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.token.OAuthToken())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err == nil && apiErr.Error.Message != "" {
+			return fmt.Errorf("%s: %s (%s)", resp.Status, apiErr.Error.Message, apiErr.Error.Code)
+		}
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,76 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package secretsimport
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type staticAzureToken string
+
+func (t staticAzureToken) EnsureFreshWithContext(context.Context) error { return nil }
+
+func (t staticAzureToken) OAuthToken() string { return string(t) }
+
+// TestAzureSource ensures that the enabled secrets of a key vault are listed
+// across pages, and that they are read.
+func TestAzureSource(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error": {"code": "Unauthorized", "message": "no token"}}`)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/secrets":
+			if r.URL.Query().Get("page") == "" {
+				fmt.Fprintf(w, `{"value": [
+					{"id": "%[1]s/secrets/db", "attributes": {"enabled": true}},
+					{"id": "%[1]s/secrets/disabled", "attributes": {"enabled": false}}
+				], "nextLink": "%[1]s/secrets?api-version=7.4&page=2"}`, srv.URL)
+				return
+			}
+			fmt.Fprintf(w, `{"value": [
+				{"id": "%[1]s/secrets/api-key", "attributes": {"enabled": true}},
+				{"id": "%[1]s/secrets/cert", "managed": true, "attributes": {"enabled": true}}
+			]}`, srv.URL)
+		case "/secrets/db":
+			fmt.Fprint(w, `{"value": "{\"username\": \"admin\"}"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": {"code": "SecretNotFound", "message": "not found"}}`)
+		}
+	}))
+	defer srv.Close()
+
+	s := &azureSource{
+		client:   srv.Client(),
+		token:    staticAzureToken("token"),
+		vaultURL: srv.URL,
+	}
+	ctx := context.Background()
+
+	names, err := s.List(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"db", "api-key"}, names)
+
+	secret, err := s.Read(ctx, "db")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"username": "admin"}, secret.Data)
+
+	_, err = s.Read(ctx, "missing")
+	require.ErrorContains(t, err, "not found (SecretNotFound)")
+
+	s.token = staticAzureToken("invalid")
+	_, err = s.List(ctx)
+	require.ErrorContains(t, err, "no token")
+}
@@ -0,0 +1,92 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package secretsimport
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"google.golang.org/api/option"
+	secretmanager "google.golang.org/api/secretmanager/v1"
+)
+
+// gcpSource imports the secrets of a project of GCP Secret Manager. The
+// configuration holds the project, the key of a service account and an
+// optional endpoint, which must be allowed by the operator.
+type gcpSource struct {
+	service *secretmanager.Service
+	project string
+}
+
+func newGCPSource(ctx context.Context, config map[string]string, allowedEndpoints []string) (*gcpSource, error) {
+	if err := requireConfig(config, "project", "credentials"); err != nil {
+		return nil, err
+	}
+
+	// Other types of credentials, such as external accounts, may read
+	// files or query metadata servers on behalf of the server.
+	var credentials struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal([]byte(config["credentials"]), &credentials); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials: %w", err)
+	}
+	if credentials.Type != "service_account" {
+		return nil, fmt.Errorf("credentials must be the key of a service account, not of type %q", credentials.Type)
+	}
+
+	opts := []option.ClientOption{
+		option.WithCredentialsJSON([]byte(config["credentials"])),
+	}
+	if endpoint := config["endpoint"]; endpoint != "" {
+		if err := checkEndpoint(endpoint, allowedEndpoints); err != nil {
+			return nil, err
+		}
+		opts = append(opts, option.WithEndpoint(endpoint))
+	}
+
+	service, err := secretmanager.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Secret Manager client: %w", err)
+	}
+	return &gcpSource{
+		service: service,
+		project: config["project"],
+	}, nil
+}
+
+func (s *gcpSource) List(ctx context.Context) ([]string, error) {
+	var names []string
+	err := s.service.Projects.Secrets.List("projects/"+s.project).Pages(ctx, func(resp *secretmanager.ListSecretsResponse) error {
+		for _, secret := range resp.Secrets {
+			// Names are of the form projects/*/secrets/*.
+			names = append(names, path.Base(secret.Name))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+	return names, nil
+}
+
+func (s *gcpSource) Read(ctx context.Context, name string) (*Secret, error) {
+	version := fmt.Sprintf("projects/%s/secrets/%s/versions/latest", s.project, name)
+	resp, err := s.service.Projects.Secrets.Versions.Access(version).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret %q: %w", name, err)
+	}
+	if resp.Payload == nil {
+		return nil, fmt.Errorf("secret %q has no payload", name)
+	}
+
+	value, err := base64.StdEncoding.DecodeString(resp.Payload.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode secret %q: %w", name, err)
+	}
+	return newSecret(name, string(value)), nil
+}
@@ -0,0 +1,88 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package secretsimport
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// Rule maps the names of the secrets matching its pattern to paths of the
+// target kv mount.
+type Rule struct {
+	// Match is a regular expression matched against the whole name of a
+	// secret.
+	Match string `mapstructure:"match"`
+
+	// Path is the path the secret is imported to, which may refer to the
+	// submatches of Match, as in regexp.Regexp.Expand.
+	Path string `mapstructure:"path"`
+
+	pattern *regexp.Regexp
+}
+
+// Mapper maps the names of secrets to kv paths, with the first rule matching
+// them. Secrets are imported to the path of their name when there are no
+// rules, and aren't imported when no rule matches them.
+type Mapper struct {
+	rules []*Rule
+}
+
+// NewMapper parses the given rules, each a map holding the match and path of
+// a rule.
+func NewMapper(rawRules []interface{}) (*Mapper, error) {
+	m := &Mapper{}
+	for i, raw := range rawRules {
+		rule := &Rule{}
+		if err := mapstructure.Decode(raw, rule); err != nil {
+			return nil, fmt.Errorf("invalid rule %d: %w", i, err)
+		}
+		if rule.Match == "" || rule.Path == "" {
+			return nil, fmt.Errorf("invalid rule %d: match and path are required", i)
+		}
+
+		pattern, err := regexp.Compile("^(?:" + rule.Match + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule %d: %w", i, err)
+		}
+		rule.pattern = pattern
+		m.rules = append(m.rules, rule)
+	}
+	return m, nil
+}
+
+// Map returns the path the named secret is imported to, and false if it
+// isn't imported.
+func (m *Mapper) Map(name string) (string, bool, error) {
+	p := name
+	if len(m.rules) > 0 {
+		var matched bool
+		for _, rule := range m.rules {
+			submatches := rule.pattern.FindStringSubmatchIndex(name)
+			if submatches == nil {
+				continue
+			}
+			p = string(rule.pattern.ExpandString(nil, rule.Path, name, submatches))
+			matched = true
+			break
+		}
+		if !matched {
+			return "", false, nil
+		}
+	}
+
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return "", false, fmt.Errorf("secret %q maps to an empty path", name)
+	}
+	for _, segment := range strings.Split(p, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return "", false, fmt.Errorf("secret %q maps to invalid path %q", name, p)
+		}
+	}
+	return p, true, nil
+}
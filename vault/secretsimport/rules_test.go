@@ -0,0 +1,62 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package secretsimport
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestMapper ensures that secret names are mapped with the first matching
+// rule, and that invalid rules and paths are rejected.
+func TestMapper(t *testing.T) {
+	m, err := NewMapper(nil)
+	require.NoError(t, err)
+	p, ok, err := m.Map("/prod/db/")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "prod/db", p)
+
+	m, err = NewMapper([]interface{}{
+		map[string]interface{}{"match": `prod/(?P<app>[^/]+)/(.+)`, "path": "apps/${app}/$2"},
+		map[string]interface{}{"match": `prod-.*`, "path": "legacy/$0"},
+		map[string]interface{}{"match": `escape-(.*)`, "path": "$1"},
+	})
+	require.NoError(t, err)
+
+	for name, expected := range map[string]string{
+		"prod/billing/db":   "apps/billing/db",
+		"prod/billing/db/1": "apps/billing/db/1",
+		"prod-api-key":      "legacy/prod-api-key",
+	} {
+		p, ok, err := m.Map(name)
+		require.NoError(t, err)
+		require.True(t, ok, name)
+		require.Equal(t, expected, p)
+	}
+
+	// The whole name must match.
+	_, ok, err = m.Map("dev/prod-api-key")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, _, err = m.Map("escape-../sys")
+	require.ErrorContains(t, err, "invalid path")
+
+	_, err = NewMapper([]interface{}{map[string]interface{}{"match": "("}})
+	require.Error(t, err)
+	_, err = NewMapper([]interface{}{map[string]interface{}{"match": "(", "path": "foo"}})
+	require.Error(t, err)
+}
+
+// TestNewSecret ensures that JSON objects are imported as their fields, and
+// other values as a single field.
+func TestNewSecret(t *testing.T) {
+	require.Equal(t, map[string]interface{}{"username": "admin", "port": float64(5432)},
+		newSecret("db", `{"username": "admin", "port": 5432}`).Data)
+	require.Equal(t, map[string]interface{}{"value": "hunter2"}, newSecret("password", "hunter2").Data)
+	require.Equal(t, map[string]interface{}{"value": "null"}, newSecret("null", "null").Data)
+	require.Equal(t, map[string]interface{}{"value": `["a"]`}, newSecret("list", `["a"]`).Data)
+}
@@ -0,0 +1,100 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package secretsimport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SourceType is the kind of secret manager secrets are imported from.
+type SourceType string
+
+const (
+	// SourceTypeAWS imports the secrets of AWS Secrets Manager
+	SourceTypeAWS SourceType = "aws-secrets-manager"
+
+	// SourceTypeGCP imports the secrets of GCP Secret Manager
+	SourceTypeGCP SourceType = "gcp-secret-manager"
+
+	// SourceTypeAzure imports the secrets of an Azure Key Vault
+	SourceTypeAzure SourceType = "azure-key-vault"
+)
+
+// Secret is the current value of a secret of a source.
+type Secret struct {
+	Name string
+
+	// Data holds the fields of the secret if its value is a JSON object, and
+	// the value of the secret under "value" otherwise.
+	Data map[string]interface{}
+}
+
+// Source lists and reads the secrets of a secret manager.
+type Source interface {
+	// List returns the names of the secrets of the source.
+	List(ctx context.Context) ([]string, error)
+
+	// Read returns the current value of the named secret.
+	Read(ctx context.Context, name string) (*Secret, error)
+}
+
+// NewSource returns a source of the given type, configured with the given
+// parameters. Sources are only ever accessed with the credentials of their
+// configuration, never with those of the server. The configuration may only
+// point sources at the endpoints of the secret managers, or at one of the
+// allowed endpoints, so that imports can't be used to make the server send
+// requests elsewhere.
+func NewSource(ctx context.Context, typ SourceType, config map[string]string, allowedEndpoints []string) (Source, error) {
+	switch typ {
+	case SourceTypeAWS:
+		return newAWSSource(config, allowedEndpoints)
+	case SourceTypeGCP:
+		return newGCPSource(ctx, config, allowedEndpoints)
+	case SourceTypeAzure:
+		return newAzureSource(config, allowedEndpoints)
+	default:
+		return nil, fmt.Errorf("unsupported source type %q", typ)
+	}
+}
+
+// newSecret returns the secret of the given value, parsing the value as a
+// JSON object if it is one.
+func newSecret(name, value string) *Secret {
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &data); err != nil || data == nil {
+		data = map[string]interface{}{
+			"value": value,
+		}
+	}
+	return &Secret{
+		Name: name,
+		Data: data,
+	}
+}
+
+// checkEndpoint returns an error unless the endpoint is one of the allowed
+// endpoints, ignoring trailing slashes.
+func checkEndpoint(endpoint string, allowedEndpoints []string) error {
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	for _, allowed := range allowedEndpoints {
+		if endpoint == strings.TrimSuffix(allowed, "/") {
+			return nil
+		}
+	}
+	return fmt.Errorf("endpoint %q is not allowed by the secrets_import_allowed_endpoints server setting", endpoint)
+}
+
+// requireConfig returns an error naming the first of the keys missing from
+// the configuration.
+func requireConfig(config map[string]string, keys ...string) error {
+	for _, key := range keys {
+		if config[key] == "" {
+			return fmt.Errorf("%s is required", key)
+		}
+	}
+	return nil
+}
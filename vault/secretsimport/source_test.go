@@ -0,0 +1,98 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package secretsimport
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewSource ensures that sources are only configured with their own
+// credentials, and with custom endpoints allowed by the operator.
+func TestNewSource(t *testing.T) {
+	allowed := []string{"https://secretsmanager.internal.example.com/"}
+	azureConfig := func(vaultURL string) map[string]string {
+		return map[string]string{
+			"vault_url":     vaultURL,
+			"tenant_id":     "tenant",
+			"client_id":     "client",
+			"client_secret": "secret",
+		}
+	}
+
+	tests := map[string]struct {
+		typ    SourceType
+		config map[string]string
+		err    string
+	}{
+		"aws": {
+			typ:    SourceTypeAWS,
+			config: map[string]string{"region": "us-east-1", "access_key": "key", "secret_key": "secret"},
+		},
+		"aws without credentials": {
+			typ:    SourceTypeAWS,
+			config: map[string]string{"region": "us-east-1"},
+			err:    "access_key is required",
+		},
+		"aws allowed endpoint": {
+			typ:    SourceTypeAWS,
+			config: map[string]string{"region": "us-east-1", "access_key": "key", "secret_key": "secret", "endpoint": "https://secretsmanager.internal.example.com"},
+		},
+		"aws endpoint": {
+			typ:    SourceTypeAWS,
+			config: map[string]string{"region": "us-east-1", "access_key": "key", "secret_key": "secret", "endpoint": "http://127.0.0.1:8200"},
+			err:    `endpoint "http://127.0.0.1:8200" is not allowed`,
+		},
+		"gcp without credentials": {
+			typ:    SourceTypeGCP,
+			config: map[string]string{"project": "project"},
+			err:    "credentials is required",
+		},
+		"gcp external account": {
+			typ:    SourceTypeGCP,
+			config: map[string]string{"project": "project", "credentials": `{"type": "external_account", "credential_source": {"file": "/etc/vault/token"}}`},
+			err:    `not of type "external_account"`,
+		},
+		"gcp endpoint": {
+			typ:    SourceTypeGCP,
+			config: map[string]string{"project": "project", "credentials": `{"type": "service_account"}`, "endpoint": "http://169.254.169.254"},
+			err:    `endpoint "http://169.254.169.254" is not allowed`,
+		},
+		"azure": {
+			typ:    SourceTypeAzure,
+			config: azureConfig("https://example.vault.azure.net/"),
+		},
+		"azure without client secret": {
+			typ:    SourceTypeAzure,
+			config: map[string]string{"vault_url": "https://example.vault.azure.net", "tenant_id": "tenant", "client_id": "client"},
+			err:    "client_secret is required",
+		},
+		"azure vault url": {
+			typ:    SourceTypeAzure,
+			config: azureConfig("https://example.vault.azure.net.attacker.example.com"),
+			err:    "is not allowed",
+		},
+		"azure vault url port": {
+			typ:    SourceTypeAzure,
+			config: azureConfig("https://example.vault.azure.net:8443"),
+			err:    "is not allowed",
+		},
+		"azure allowed vault url": {
+			typ:    SourceTypeAzure,
+			config: azureConfig("https://secretsmanager.internal.example.com"),
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewSource(context.Background(), tc.typ, tc.config, allowed)
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
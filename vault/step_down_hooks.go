@@ -0,0 +1,120 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/vault/eventbus"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// stepDownEventType is the type of the event sent before the active node
+// steps down.
+const stepDownEventType logical.EventType = "core/step-down"
+
+// stepDownDrainInterval is how often in-flight requests are checked while
+// draining them before a step-down.
+const stepDownDrainInterval = 50 * time.Millisecond
+
+// errSteppingDown is returned for the requests received by the active node
+// while it drains in-flight requests before stepping down. The status code
+// makes clients retry them, by which time a new active node may be elected.
+var errSteppingDown = logical.CodedError(http.StatusServiceUnavailable, "Vault is stepping down, retry the request")
+
+// PreStepDownHook is run by the active node before it steps down to standby
+// on request. Hooks are run in order and the errors they return are logged;
+// they do not prevent the step-down.
+type PreStepDownHook struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// preStepDownHooks returns the hooks to run before stepping down. When a
+// grace period is configured, new requests are rejected and in-flight
+// requests are given the grace period to complete. Audit devices are then
+// flushed and the step-down is sent to the event bus, before the hooks set
+// in the core config are run.
+func (c *Core) preStepDownHooks() []PreStepDownHook {
+	var hooks []PreStepDownHook
+	if c.stepDownGracePeriod > 0 {
+		hooks = append(hooks,
+			PreStepDownHook{Name: "reject-new-requests", Run: c.rejectNewRequests},
+			PreStepDownHook{Name: "drain-in-flight-requests", Run: c.drainInFlightRequests},
+		)
+	}
+	hooks = append(hooks,
+		PreStepDownHook{Name: "flush-audit-devices", Run: c.auditBroker.Flush},
+		PreStepDownHook{Name: "notify-event-bus", Run: c.notifyStepDown},
+	)
+	return append(hooks, c.customPreStepDownHooks...)
+}
+
+func (c *Core) runPreStepDownHooks(ctx context.Context) {
+	defer metrics.MeasureSince([]string{"core", "pre_step_down"}, time.Now())
+
+	for _, hook := range c.preStepDownHooks() {
+		c.logger.Debug("running pre-step-down hook", "hook", hook.Name)
+		if err := hook.Run(ctx); err != nil {
+			c.logger.Warn("pre-step-down hook failed", "hook", hook.Name, "error", err)
+		}
+	}
+}
+
+func (c *Core) rejectNewRequests(context.Context) error {
+	atomic.StoreUint32(c.steppingDown, 1)
+	return nil
+}
+
+// drainInFlightRequests waits for the in-flight requests to complete, for up
+// to the step-down grace period.
+func (c *Core) drainInFlightRequests(ctx context.Context) error {
+	timer := c.clock.NewTimer(c.stepDownGracePeriod)
+	defer timer.Stop()
+	ticker := c.clock.NewTicker(stepDownDrainInterval)
+	defer ticker.Stop()
+
+	for {
+		inFlight := c.inFlightReqData.InFlightReqCount.Load()
+		if inFlight == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			c.logger.Warn("step-down grace period expired with requests in flight", "in_flight_requests", inFlight)
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Core) notifyStepDown(ctx context.Context) error {
+	event, err := logical.NewEvent()
+	if err != nil {
+		return err
+	}
+	event.Metadata, err = structpb.NewStruct(map[string]interface{}{
+		"cluster_addr": c.ClusterAddr(),
+		"grace_period": c.stepDownGracePeriod.String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = c.events.SendInternal(ctx, namespace.RootNamespace, nil, stepDownEventType, event)
+	if errors.Is(err, eventbus.ErrNotStarted) {
+		return nil
+	}
+	return err
+}
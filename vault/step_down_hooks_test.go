@@ -0,0 +1,88 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestCore_PreStepDownHooks(t *testing.T) {
+	c, _, root := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	c.stepDownGracePeriod = time.Minute
+
+	var customRan int32
+	c.customPreStepDownHooks = []PreStepDownHook{{
+		Name: "custom",
+		Run: func(context.Context) error {
+			atomic.StoreInt32(&customRan, 1)
+			return nil
+		},
+	}}
+
+	var names []string
+	for _, hook := range c.preStepDownHooks() {
+		names = append(names, hook.Name)
+	}
+	expected := []string{"reject-new-requests", "drain-in-flight-requests", "flush-audit-devices", "notify-event-bus", "custom"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected hooks %v, got %v", expected, names)
+	}
+
+	// runHooks runs the hooks, failing if they take more than timeout.
+	runHooks := func(timeout time.Duration) {
+		t.Helper()
+
+		done := make(chan struct{})
+		go func() {
+			c.runPreStepDownHooks(ctx)
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(timeout):
+			t.Fatal("timed out waiting for the hooks to run")
+		}
+	}
+
+	// In-flight requests are drained, while new requests are rejected.
+	c.StoreInFlightReqData("in-flight", InFlightReqData{})
+	go func() {
+		for atomic.LoadUint32(c.steppingDown) == 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		req := logical.TestRequest(t, logical.ReadOperation, "sys/mounts")
+		req.ClientToken = root
+		if _, err := c.HandleRequest(ctx, req); !errors.Is(err, errSteppingDown) {
+			t.Errorf("expected new requests to be rejected, got: %v", err)
+		}
+		c.FinalizeInFlightReqData("in-flight", http.StatusOK)
+	}()
+	runHooks(10 * time.Second)
+
+	if n := c.inFlightReqData.InFlightReqCount.Load(); n != 0 {
+		t.Fatalf("expected the in-flight request to be drained, got %d", n)
+	}
+	if atomic.LoadInt32(&customRan) != 1 {
+		t.Fatal("expected the custom hook to run")
+	}
+
+	// Draining gives up once the grace period expires.
+	c.stepDownGracePeriod = 100 * time.Millisecond
+	c.StoreInFlightReqData("stuck", InFlightReqData{})
+	defer c.FinalizeInFlightReqData("stuck", http.StatusOK)
+	runHooks(10 * time.Second)
+}
@@ -0,0 +1,198 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	multierror "github.com/hashicorp/go-multierror"
+)
+
+const (
+	// teardownPhaseTimeout is the deadline of the teardown phases which only
+	// stop background work of the core.
+	teardownPhaseTimeout = 10 * time.Second
+
+	// teardownBackendPhaseTimeout is the deadline of the teardown phases
+	// which wait on backends, such as revoking leases or cleaning up mounts,
+	// and so may take longer.
+	teardownBackendPhaseTimeout = 30 * time.Second
+)
+
+// teardownPhase is a step of the teardown of the core on seal or shutdown.
+type teardownPhase struct {
+	name    string
+	timeout time.Duration
+	run     func() error
+}
+
+// runTeardownPhases runs the given teardown phases one after the other, in
+// their order, so that subsystems are stopped before the ones they depend on.
+// A phase which doesn't complete before its deadline is abandoned, rather than
+// waited on, so that a stuck backend can't hang the teardown: the following
+// phases are run regardless, and the phases which timed out are logged. The
+// errors returned by the phases which completed are returned.
+func (c *Core) runTeardownPhases(phases []teardownPhase) error {
+	var result error
+	for _, phase := range phases {
+		start := time.Now()
+		c.logger.Debug("teardown phase starting", "phase", phase.name)
+
+		errCh := make(chan error, 1)
+		go func(phase teardownPhase) {
+			errCh <- phase.run()
+		}(phase)
+
+		timer := time.NewTimer(phase.timeout)
+		select {
+		case err := <-errCh:
+			timer.Stop()
+			if err != nil {
+				result = multierror.Append(result, err)
+			}
+			c.logger.Debug("teardown phase complete", "phase", phase.name, "duration", time.Since(start))
+		case <-timer.C:
+			c.logger.Error("teardown phase did not complete before its deadline, abandoning it",
+				"phase", phase.name, "timeout", phase.timeout)
+			metrics.IncrCounterWithLabels([]string{"core", "teardown", "phase_timeout"}, 1,
+				[]metrics.Label{{Name: "phase", Value: phase.name}})
+		}
+		metrics.MeasureSinceWithLabels([]string{"core", "teardown", "phase"}, start,
+			[]metrics.Label{{Name: "phase", Value: phase.name}})
+	}
+	return result
+}
+
+// preSealTeardownPhases returns the teardown phases of preSeal, in the order
+// subsystems must be stopped: the core first stops accepting work from other
+// nodes, then stops the subsystems making requests to backends, such as the
+// expiration and rollback managers, before tearing the backends down. Audit
+// devices are torn down last, so that the requests made by the other phases
+// are still audited.
+func (c *Core) preSealTeardownPhases() []teardownPhase {
+	return []teardownPhase{
+		{
+			name:    "forwarding",
+			timeout: teardownPhaseTimeout,
+			run: func() error {
+				c.stopForwarding()
+				c.stopRaftActiveNode()
+
+				c.clusterParamsLock.Lock()
+				defer c.clusterParamsLock.Unlock()
+				if err := stopReplication(c); err != nil {
+					return fmt.Errorf("error stopping replication: %w", err)
+				}
+				return nil
+			},
+		},
+		{
+			name:    "expiration",
+			timeout: teardownBackendPhaseTimeout,
+			run: func() error {
+				if err := c.stopExpiration(); err != nil {
+					return fmt.Errorf("error stopping expiration: %w", err)
+				}
+				return nil
+			},
+		},
+		{
+			name:    "rollback",
+			timeout: teardownBackendPhaseTimeout,
+			run: func() error {
+				if err := c.stopRollback(); err != nil {
+					return fmt.Errorf("error stopping rollback: %w", err)
+				}
+				return nil
+			},
+		},
+		{
+			name:    "rotation",
+			timeout: teardownPhaseTimeout,
+			run: func() error {
+				if c.autoRotateCancel != nil {
+					c.autoRotateCancel()
+					c.autoRotateCancel = nil
+				}
+				return nil
+			},
+		},
+		{
+			name:    "activity log",
+			timeout: teardownPhaseTimeout,
+			run: func() error {
+				c.stopActivityLog()
+				c.stopPprofCapture()
+				// Clean up the censusAgent on seal
+				if err := c.teardownCensusAgent(); err != nil {
+					return fmt.Errorf("error tearing down reporting agent: %w", err)
+				}
+				return nil
+			},
+		},
+		{
+			name:    "credentials",
+			timeout: teardownBackendPhaseTimeout,
+			run: func() error {
+				if err := c.teardownCredentials(context.Background()); err != nil {
+					return fmt.Errorf("error tearing down credentials: %w", err)
+				}
+				return nil
+			},
+		},
+		{
+			name:    "policy store",
+			timeout: teardownPhaseTimeout,
+			run: func() error {
+				if err := c.teardownPolicyStore(); err != nil {
+					return fmt.Errorf("error tearing down policy store: %w", err)
+				}
+				return nil
+			},
+		},
+		{
+			name:    "mounts",
+			timeout: teardownBackendPhaseTimeout,
+			run: func() error {
+				if err := c.unloadMounts(context.Background()); err != nil {
+					return fmt.Errorf("error unloading mounts: %w", err)
+				}
+				return nil
+			},
+		},
+		{
+			name:    "login MFA",
+			timeout: teardownPhaseTimeout,
+			run: func() error {
+				if c.systemBackend != nil && c.systemBackend.mfaBackend != nil {
+					c.systemBackend.mfaBackend.usedCodes = nil
+				}
+				if err := c.teardownLoginMFA(); err != nil {
+					return fmt.Errorf("error tearing down login MFA, error: %w", err)
+				}
+				return nil
+			},
+		},
+		{
+			name:    "audit",
+			timeout: teardownBackendPhaseTimeout,
+			run: func() error {
+				if err := c.teardownAudits(); err != nil {
+					return fmt.Errorf("error tearing down audits: %w", err)
+				}
+				return nil
+			},
+		},
+		{
+			name:    "enterprise",
+			timeout: teardownBackendPhaseTimeout,
+			run: func() error {
+				return enterprisePreSeal(c)
+			},
+		},
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/vault/eventbus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCore_RunTeardownPhases(t *testing.T) {
+	c, _, _ := TestCoreUnsealed(t)
+
+	var ran []string
+	stuck := make(chan struct{})
+	defer close(stuck)
+
+	err := c.runTeardownPhases([]teardownPhase{
+		{
+			name:    "first",
+			timeout: time.Second,
+			run: func() error {
+				ran = append(ran, "first")
+				return nil
+			},
+		},
+		{
+			name:    "stuck",
+			timeout: 100 * time.Millisecond,
+			run: func() error {
+				<-stuck
+				return errors.New("stuck phase completed")
+			},
+		},
+		{
+			name:    "failing",
+			timeout: time.Second,
+			run: func() error {
+				ran = append(ran, "failing")
+				return errors.New("failing phase failed")
+			},
+		},
+	})
+
+	// The stuck phase is abandoned, and the following phases are still run
+	// in order
+	require.Equal(t, []string{"first", "failing"}, ran)
+	require.ErrorContains(t, err, "failing phase failed")
+	require.NotContains(t, err.Error(), "stuck phase completed")
+}
+
+func TestCore_ShutdownStopsEvents(t *testing.T) {
+	c, _, _ := TestCoreUnsealed(t)
+	c.events.Start()
+
+	require.NoError(t, c.Shutdown())
+	require.True(t, c.Sealed())
+	err := c.events.SendInternal(context.Background(), namespace.RootNamespace, nil, "someType", nil)
+	require.ErrorIs(t, err, eventbus.ErrNotStarted)
+}
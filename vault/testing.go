@@ -219,6 +219,12 @@ func TestCoreWithSealAndUINoCleanup(t testing.T, opts *CoreConfig) *Core {
 	conf.Experiments = opts.Experiments
 	conf.CensusAgent = opts.CensusAgent
 	conf.AdministrativeNamespacePath = opts.AdministrativeNamespacePath
+	conf.SecretsImportAllowedEndpoints = opts.SecretsImportAllowedEndpoints
+	conf.WatchdogPath = opts.WatchdogPath
+	conf.WatchdogGoroutineGrowth = opts.WatchdogGoroutineGrowth
+	conf.WatchdogStateLockHold = opts.WatchdogStateLockHold
+	conf.WatchdogBarrierLatency = opts.WatchdogBarrierLatency
+	conf.Clock = opts.Clock
 
 	if opts.Logger != nil {
 		conf.Logger = opts.Logger
@@ -1027,6 +1033,7 @@ func (c *TestClusterCore) ReleaseRollbackLock() {
 }
 
 func (c *TestClusterCore) TriggerRollbacks() {
+	c.rollback.resetRollbackSchedule()
 	c.rollback.triggerRollbacks()
 }
 
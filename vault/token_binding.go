@@ -0,0 +1,88 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/hex"
+
+	"github.com/hashicorp/go-sockaddr"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// bindLoginToken binds the token issued by a login to the source address of
+// the login and to the TLS client certificate it presented, as configured by
+// the token_bind_source_cidr and token_bind_client_cert options of the auth
+// mount. Both are then enforced on every use of the token.
+func (c *Core) bindLoginToken(mountEntry *MountEntry, req *logical.Request, auth *logical.Auth) error {
+	if mountEntry == nil || (!mountEntry.Config.TokenBindSourceCIDR && !mountEntry.Config.TokenBindClientCert) {
+		return nil
+	}
+	if req.Connection == nil {
+		return logical.ErrPermissionDenied
+	}
+
+	if mountEntry.Config.TokenBindSourceCIDR {
+		source, err := sockaddr.NewSockAddr(req.Connection.RemoteAddr)
+		if err != nil {
+			c.logger.Debug("could not parse login source address to bind the token", "error", err, "remote_addr", req.Connection.RemoteAddr)
+			return logical.ErrPermissionDenied
+		}
+
+		// If the backend already bound the token to CIDRs, the source must be
+		// within them, otherwise binding it to the source would widen them.
+		if len(auth.BoundCIDRs) > 0 {
+			var contained bool
+			for _, cidr := range auth.BoundCIDRs {
+				if cidr.Contains(source) {
+					contained = true
+					break
+				}
+			}
+			if !contained {
+				c.logger.Debug("login source address is not within the bound CIDRs of the token", "remote_addr", req.Connection.RemoteAddr)
+				return logical.ErrPermissionDenied
+			}
+		}
+
+		auth.BoundCIDRs = []*sockaddr.SockAddrMarshaler{{SockAddr: source}}
+	}
+
+	if mountEntry.Config.TokenBindClientCert {
+		fingerprint := clientCertFingerprint(req.Connection.ConnState)
+		if fingerprint == "" {
+			c.logger.Debug("login did not present a TLS client certificate to bind the token to", "path", req.Path)
+			return logical.ErrPermissionDenied
+		}
+		auth.BoundClientCertFingerprint = fingerprint
+	}
+
+	return nil
+}
+
+// clientCertFingerprint returns the hex-encoded SHA-256 fingerprint of the
+// TLS client certificate of the connection, or an empty string if there is
+// none.
+func clientCertFingerprint(connState *tls.ConnectionState) string {
+	if connState == nil || len(connState.PeerCertificates) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(connState.PeerCertificates[0].Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// tokenClientCertMatches returns whether the request presented the TLS client
+// certificate the token is bound to, if any.
+func tokenClientCertMatches(te *logical.TokenEntry, req *logical.Request) bool {
+	if te.BoundClientCertFingerprint == "" {
+		return true
+	}
+	if req.Connection == nil {
+		return false
+	}
+	fingerprint := clientCertFingerprint(req.Connection.ConnState)
+	return fingerprint != "" && subtle.ConstantTimeCompare([]byte(fingerprint), []byte(te.BoundClientCertFingerprint)) == 1
+}
@@ -0,0 +1,135 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"testing"
+
+	credUserpass "github.com/hashicorp/vault/builtin/credential/userpass"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func testTokenBindingConnection(remoteAddr, cert string) *logical.Connection {
+	conn := &logical.Connection{RemoteAddr: remoteAddr}
+	if cert != "" {
+		conn.ConnState = &tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{{Raw: []byte(cert)}},
+		}
+	}
+	return conn
+}
+
+func TestRequestHandling_LoginTokenBinding(t *testing.T) {
+	core, _, root := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	core.credentialBackends["userpass"] = credUserpass.Factory
+
+	req := &logical.Request{
+		Path:        "sys/auth/userpass",
+		ClientToken: root,
+		Operation:   logical.UpdateOperation,
+		Data: map[string]interface{}{
+			"type": "userpass",
+			"config": map[string]interface{}{
+				"token_bind_source_cidr": true,
+				"token_bind_client_cert": true,
+			},
+		},
+		Connection: &logical.Connection{},
+	}
+	resp, err := core.HandleRequest(ctx, req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v, resp: %#v", err, resp)
+	}
+
+	req.Path = "auth/userpass/users/test"
+	req.Data = map[string]interface{}{
+		"password": "foo",
+		"policies": "default",
+	}
+	resp, err = core.HandleRequest(ctx, req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v, resp: %#v", err, resp)
+	}
+
+	login := func(conn *logical.Connection) (*logical.Response, error) {
+		return core.HandleRequest(ctx, &logical.Request{
+			Path:      "auth/userpass/login/test",
+			Operation: logical.UpdateOperation,
+			Data: map[string]interface{}{
+				"password": "foo",
+			},
+			Connection: conn,
+		})
+	}
+
+	// Logins must present a client certificate
+	if _, err := login(testTokenBindingConnection("127.0.0.1", "")); !errors.Is(err, logical.ErrPermissionDenied) {
+		t.Fatalf("expected permission denied, got: %v", err)
+	}
+
+	resp, err = login(testTokenBindingConnection("127.0.0.1", "cert-a"))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp == nil || resp.Auth == nil {
+		t.Fatalf("bad: %#v", resp)
+	}
+	clientToken := resp.Auth.ClientToken
+
+	lookupSelf := func(conn *logical.Connection) error {
+		_, err := core.HandleRequest(ctx, &logical.Request{
+			Path:        "auth/token/lookup-self",
+			ClientToken: clientToken,
+			Operation:   logical.ReadOperation,
+			Connection:  conn,
+		})
+		return err
+	}
+
+	if err := lookupSelf(testTokenBindingConnection("127.0.0.1", "cert-a")); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for _, conn := range []*logical.Connection{
+		testTokenBindingConnection("127.0.0.2", "cert-a"),
+		testTokenBindingConnection("127.0.0.1", "cert-b"),
+		testTokenBindingConnection("127.0.0.1", ""),
+	} {
+		if err := lookupSelf(conn); err == nil {
+			t.Fatalf("expected the token to be rejected for connection %#v", conn)
+		}
+	}
+
+	// Turning the bindings off applies to subsequent logins
+	resp, err = core.HandleRequest(ctx, &logical.Request{
+		Path:        "sys/auth/userpass/tune",
+		ClientToken: root,
+		Operation:   logical.UpdateOperation,
+		Data: map[string]interface{}{
+			"token_bind_source_cidr": false,
+			"token_bind_client_cert": false,
+		},
+		Connection: &logical.Connection{},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v, resp: %#v", err, resp)
+	}
+
+	resp, err = login(testTokenBindingConnection("127.0.0.1", ""))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp == nil || resp.Auth == nil {
+		t.Fatalf("bad: %#v", resp)
+	}
+	clientToken = resp.Auth.ClientToken
+	if err := lookupSelf(testTokenBindingConnection("127.0.0.2", "cert-b")); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+}
@@ -0,0 +1,407 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/helper/jsonutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	revokeTreeJobStatusRunning   = "running"
+	revokeTreeJobStatusCompleted = "completed"
+	revokeTreeJobStatusFailed    = "failed"
+
+	// revokeTreeJobPhaseInvalidating is the phase of a job during which the
+	// tokens of the tree are marked as pending revocation, so that they can't
+	// be used anymore.
+	revokeTreeJobPhaseInvalidating = "invalidating"
+
+	// revokeTreeJobPhaseRevoking is the phase of a job during which the
+	// tokens of the tree, and their leases, are revoked.
+	revokeTreeJobPhaseRevoking = "revoking"
+
+	// revokeTreeJobViewPrefix is the prefix of the expiration view the
+	// revocation tree jobs are stored under.
+	revokeTreeJobViewPrefix = "revoke-tree/"
+
+	// revokeTreeJobCheckpointInterval is the number of tokens a job
+	// invalidates or revokes between two checkpoints of its progress.
+	revokeTreeJobCheckpointInterval = 500
+
+	// maxConcurrentRevokeTreeJobs is the number of revocation tree jobs run
+	// at once. The other jobs wait for one of them to finish.
+	maxConcurrentRevokeTreeJobs = 4
+
+	// revokeTreeJobRetention is how long the progress of finished jobs is
+	// kept.
+	revokeTreeJobRetention = 24 * time.Hour
+)
+
+// errRevokeTreeJobStopped is returned when a job is interrupted because the
+// expiration manager is stopped. The job is resumed by the next active node.
+var errRevokeTreeJobStopped = errors.New("token revocation stopped")
+
+// revokeTreeJob is the background revocation of a token and all of its child
+// tokens. Its progress is checkpointed to storage, so that the job is resumed
+// when the active node is restarted or changes.
+type revokeTreeJob struct {
+	l sync.RWMutex
+
+	ID string `json:"id"`
+
+	// NamespaceID is the namespace the job was started in, which is the one
+	// its progress can be read from.
+	NamespaceID string `json:"namespace_id"`
+
+	// TokenNamespaceID is the namespace of the root token of the tree.
+	TokenNamespaceID string `json:"token_namespace_id"`
+
+	// TokenID is the salted ID of the root token of the tree.
+	TokenID  string `json:"token_id"`
+	Accessor string `json:"accessor"`
+	LeaseID  string `json:"lease_id"`
+
+	Status      string    `json:"status"`
+	Phase       string    `json:"phase"`
+	Invalidated int       `json:"invalidated"`
+	Revoked     int       `json:"revoked"`
+	Error       string    `json:"error,omitempty"`
+	StartTime   time.Time `json:"start_time"`
+	EndTime     time.Time `json:"end_time"`
+}
+
+// RevokeTreeAsync starts the revocation of the given token and all of its
+// child tokens in the background. The token is invalidated before returning,
+// and its child tokens are invalidated first by the job, before any of them
+// is revoked.
+//
+// Jobs run apart from the workers revoking expired leases, so that the
+// revocation of a large tree isn't queued behind routine expirations.
+func (m *ExpirationManager) RevokeTreeAsync(ctx context.Context, ns *namespace.Namespace, te *logical.TokenEntry) (*revokeTreeJob, error) {
+	defer metrics.MeasureSince([]string{"expire", "revoke-tree-async"}, time.Now())
+
+	tokenNS, err := NamespaceByID(ctx, te.NamespaceID, m.core)
+	if err != nil {
+		return nil, err
+	}
+	if tokenNS == nil {
+		return nil, namespace.ErrNoNamespace
+	}
+	ctx = namespace.ContextWithNamespace(ctx, tokenNS)
+
+	leaseID, err := m.CreateOrFetchRevocationLeaseByToken(ctx, te)
+	if err != nil {
+		return nil, err
+	}
+	saltedID, err := m.tokenStore.SaltID(ctx, te.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.tokenStore.invalidateInternal(ctx, saltedID); err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
+	job := &revokeTreeJob{
+		ID:               id,
+		NamespaceID:      ns.ID,
+		TokenNamespaceID: tokenNS.ID,
+		TokenID:          saltedID,
+		Accessor:         te.Accessor,
+		LeaseID:          leaseID,
+		Status:           revokeTreeJobStatusRunning,
+		Phase:            revokeTreeJobPhaseInvalidating,
+		StartTime:        time.Now(),
+	}
+	if err := m.persistRevokeTreeJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	m.pruneRevokeTreeJobs(ctx)
+	m.revokeTreeJobs.Store(job.ID, job)
+	m.startRevokeTreeJob(job)
+
+	return job, nil
+}
+
+// RevokeTreeJobStatus returns the progress of the job with the given ID if
+// it was started in the namespace.
+func (m *ExpirationManager) RevokeTreeJobStatus(ns *namespace.Namespace, id string) map[string]interface{} {
+	raw, ok := m.revokeTreeJobs.Load(id)
+	if !ok {
+		return nil
+	}
+	job := raw.(*revokeTreeJob)
+	if job.NamespaceID != ns.ID {
+		return nil
+	}
+	return job.toMap()
+}
+
+// RevokeTreeJobs returns the IDs of the jobs started in the namespace.
+func (m *ExpirationManager) RevokeTreeJobs(ns *namespace.Namespace) []string {
+	var ids []string
+	m.revokeTreeJobs.Range(func(_, raw interface{}) bool {
+		job := raw.(*revokeTreeJob)
+		if job.NamespaceID == ns.ID {
+			ids = append(ids, job.ID)
+		}
+		return true
+	})
+	sort.Strings(ids)
+	return ids
+}
+
+// resumeRevokeTreeJobs loads the jobs from storage, once the leases are
+// restored, and resumes the ones which were running. Jobs resume from the
+// phase they were in; revoking a tree again is safe, as the tokens which
+// were revoked are gone.
+func (m *ExpirationManager) resumeRevokeTreeJobs(ctx context.Context) error {
+	ids, err := m.revokeTreeJobView.List(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to list token revocation jobs: %w", err)
+	}
+
+	for _, id := range ids {
+		entry, err := m.revokeTreeJobView.Get(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to read token revocation job: %w", err)
+		}
+		if entry == nil {
+			continue
+		}
+		job := &revokeTreeJob{}
+		if err := jsonutil.DecodeJSON(entry.Value, job); err != nil {
+			return fmt.Errorf("failed to decode token revocation job: %w", err)
+		}
+
+		m.revokeTreeJobs.Store(job.ID, job)
+		if job.Status == revokeTreeJobStatusRunning {
+			m.logger.Info("resuming token revocation", "job_id", job.ID, "phase", job.Phase)
+			m.startRevokeTreeJob(job)
+		}
+	}
+
+	m.pruneRevokeTreeJobs(ctx)
+	return nil
+}
+
+// startRevokeTreeJob runs the job in the background, once fewer than
+// maxConcurrentRevokeTreeJobs jobs are running.
+func (m *ExpirationManager) startRevokeTreeJob(job *revokeTreeJob) {
+	m.revokeTreeWG.Add(1)
+	go func() {
+		defer m.revokeTreeWG.Done()
+
+		select {
+		case m.revokeTreeSem <- struct{}{}:
+		case <-m.quitCh:
+			return
+		}
+		defer func() {
+			<-m.revokeTreeSem
+		}()
+
+		m.runRevokeTreeJob(job)
+	}()
+}
+
+// runRevokeTreeJob invalidates the whole tree of the job, then revokes it,
+// checkpointing the progress of the job every
+// revokeTreeJobCheckpointInterval tokens.
+func (m *ExpirationManager) runRevokeTreeJob(job *revokeTreeJob) {
+	start := time.Now()
+	ctx := m.quitContext
+
+	err := func() error {
+		tokenNS, err := NamespaceByID(ctx, job.TokenNamespaceID, m.core)
+		if err != nil {
+			return err
+		}
+		if tokenNS == nil {
+			return namespace.ErrNoNamespace
+		}
+		ctx = namespace.ContextWithNamespace(ctx, tokenNS)
+
+		checkpoint := func(count int) error {
+			if m.revokeTreeJobStopped() {
+				return errRevokeTreeJobStopped
+			}
+			if count%revokeTreeJobCheckpointInterval == 0 {
+				if err := m.persistRevokeTreeJob(ctx, job); err != nil {
+					m.logger.Warn("failed to checkpoint token revocation", "job_id", job.ID, "error", err)
+				}
+			}
+			return nil
+		}
+
+		if job.phase() == revokeTreeJobPhaseInvalidating {
+			err := m.tokenStore.invalidateTreeInternal(ctx, job.TokenID, func() error {
+				return checkpoint(job.invalidatedOne())
+			})
+			if err != nil {
+				return err
+			}
+
+			job.setPhase(revokeTreeJobPhaseRevoking)
+			if err := m.persistRevokeTreeJob(ctx, job); err != nil {
+				return err
+			}
+		}
+
+		err = m.tokenStore.revokeTreeInternal(ctx, job.TokenID, func() error {
+			return checkpoint(job.revokedOne())
+		})
+		if err != nil {
+			return err
+		}
+
+		// The token's lease is revoked last, so that it is cleaned up once
+		// the whole tree is gone.
+		if job.LeaseID != "" {
+			if err := m.Revoke(ctx, job.LeaseID); err != nil {
+				return err
+			}
+		}
+		return nil
+	}()
+
+	if err != nil && (errors.Is(err, errRevokeTreeJobStopped) || m.revokeTreeJobStopped()) {
+		// The job is left running in storage, so that it is resumed by the
+		// next active node from its last checkpoint.
+		m.logger.Info("token revocation interrupted", "job_id", job.ID)
+		return
+	}
+
+	job.finish(err)
+	if err != nil {
+		m.logger.Error("failed to revoke token tree", "job_id", job.ID, "error", err)
+	} else {
+		m.logger.Debug("token tree revoked", "job_id", job.ID, "revoked", job.Revoked, "duration", time.Since(start))
+	}
+	if err := m.persistRevokeTreeJob(ctx, job); err != nil {
+		m.logger.Warn("failed to store token revocation result", "job_id", job.ID, "error", err)
+	}
+	metrics.MeasureSince([]string{"expire", "revoke-tree-job"}, start)
+}
+
+// revokeTreeJobStopped returns whether the expiration manager is stopping, in
+// which case the running jobs are interrupted.
+func (m *ExpirationManager) revokeTreeJobStopped() bool {
+	select {
+	case <-m.quitCh:
+		return true
+	default:
+		return m.quitContext.Err() != nil
+	}
+}
+
+// persistRevokeTreeJob stores the progress of the job.
+func (m *ExpirationManager) persistRevokeTreeJob(ctx context.Context, job *revokeTreeJob) error {
+	job.l.RLock()
+	buf, err := jsonutil.EncodeJSON(job)
+	job.l.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode token revocation job: %w", err)
+	}
+
+	return m.revokeTreeJobView.Put(ctx, &logical.StorageEntry{
+		Key:   job.ID,
+		Value: buf,
+	})
+}
+
+// pruneRevokeTreeJobs forgets the jobs which finished more than
+// revokeTreeJobRetention ago.
+func (m *ExpirationManager) pruneRevokeTreeJobs(ctx context.Context) {
+	m.revokeTreeJobs.Range(func(key, raw interface{}) bool {
+		job := raw.(*revokeTreeJob)
+		job.l.RLock()
+		expired := job.Status != revokeTreeJobStatusRunning && time.Since(job.EndTime) > revokeTreeJobRetention
+		job.l.RUnlock()
+		if !expired {
+			return true
+		}
+
+		if err := m.revokeTreeJobView.Delete(ctx, job.ID); err != nil {
+			m.logger.Warn("failed to delete token revocation job", "job_id", job.ID, "error", err)
+			return true
+		}
+		m.revokeTreeJobs.Delete(key)
+		return true
+	})
+}
+
+func (j *revokeTreeJob) phase() string {
+	j.l.RLock()
+	defer j.l.RUnlock()
+	return j.Phase
+}
+
+func (j *revokeTreeJob) setPhase(phase string) {
+	j.l.Lock()
+	defer j.l.Unlock()
+	j.Phase = phase
+}
+
+func (j *revokeTreeJob) invalidatedOne() int {
+	j.l.Lock()
+	defer j.l.Unlock()
+	j.Invalidated++
+	return j.Invalidated
+}
+
+func (j *revokeTreeJob) revokedOne() int {
+	j.l.Lock()
+	defer j.l.Unlock()
+	j.Revoked++
+	return j.Revoked
+}
+
+func (j *revokeTreeJob) finish(err error) {
+	j.l.Lock()
+	defer j.l.Unlock()
+	j.EndTime = time.Now()
+	j.Status = revokeTreeJobStatusCompleted
+	if err != nil {
+		j.Status = revokeTreeJobStatusFailed
+		j.Error = err.Error()
+	}
+}
+
+func (j *revokeTreeJob) toMap() map[string]interface{} {
+	j.l.RLock()
+	defer j.l.RUnlock()
+
+	m := map[string]interface{}{
+		"job_id":      j.ID,
+		"accessor":    j.Accessor,
+		"status":      j.Status,
+		"phase":       j.Phase,
+		"invalidated": j.Invalidated,
+		"revoked":     j.Revoked,
+		"start_time":  j.StartTime.Format(time.RFC3339Nano),
+	}
+	if j.Error != "" {
+		m["error"] = j.Error
+	}
+	if !j.EndTime.IsZero() {
+		m["end_time"] = j.EndTime.Format(time.RFC3339Nano)
+	}
+	return m
+}
@@ -0,0 +1,139 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"net/http"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/helper/jsonutil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenStore_RevokeTreeAsync(t *testing.T) {
+	c, _, rootToken := TestCoreUnsealed(t)
+	ts := c.tokenStore
+	ctx := namespace.RootContext(nil)
+	root, children := buildTokenTree(t, ts, 3)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "revoke")
+	req.ClientToken = rootToken
+	req.Data["token"] = root.ID
+	req.Data["async"] = true
+	resp, err := ts.HandleRequest(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusAccepted, resp.Data[logical.HTTPStatusCode])
+
+	var httpResp logical.HTTPResponse
+	require.NoError(t, jsonutil.DecodeJSON([]byte(resp.Data[logical.HTTPRawBody].(string)), &httpResp))
+	jobID := httpResp.Data["job_id"].(string)
+	require.Equal(t, "sys/leases/revoke-status/"+jobID, httpResp.Data["status_path"])
+
+	status := testWaitRevokeTreeJob(t, c, rootToken, jobID)
+	require.Equal(t, revokeTreeJobStatusCompleted, status["status"])
+	require.Equal(t, revokeTreeJobPhaseRevoking, status["phase"])
+	require.Equal(t, 15, status["invalidated"])
+	require.Equal(t, 15, status["revoked"])
+	require.Equal(t, root.Accessor, status["accessor"])
+
+	for _, entry := range append(children, root) {
+		out, err := ts.lookupInternal(ctx, entry.ID, false, true)
+		require.NoError(t, err)
+		require.Nil(t, out)
+	}
+
+	saltedID, err := ts.SaltID(ctx, root.ID)
+	require.NoError(t, err)
+	le, err := c.expiration.loadEntry(ctx, path.Join(root.Path, saltedID))
+	require.NoError(t, err)
+	require.Nil(t, le)
+
+	listReq := logical.TestRequest(t, logical.ListOperation, "sys/leases/revoke-status")
+	listReq.ClientToken = rootToken
+	resp, err = c.HandleRequest(ctx, listReq)
+	require.NoError(t, err)
+	require.Equal(t, []string{jobID}, resp.Data["keys"])
+
+	// Unknown jobs aren't found.
+	statusReq := logical.TestRequest(t, logical.ReadOperation, "sys/leases/revoke-status/unknown")
+	statusReq.ClientToken = rootToken
+	resp, err = c.HandleRequest(ctx, statusReq)
+	require.NoError(t, err)
+	require.Nil(t, resp)
+}
+
+func TestTokenStore_RevokeTreeAsync_Resume(t *testing.T) {
+	c, _, rootToken := TestCoreUnsealed(t)
+	ts := c.tokenStore
+	ctx := namespace.RootContext(nil)
+	root, children := buildTokenTree(t, ts, 2)
+
+	saltedID, err := ts.SaltID(ctx, root.ID)
+	require.NoError(t, err)
+
+	// A job interrupted while invalidating the tree is resumed from its
+	// phase, keeping its progress.
+	job := &revokeTreeJob{
+		ID:               "interrupted",
+		NamespaceID:      namespace.RootNamespaceID,
+		TokenNamespaceID: namespace.RootNamespaceID,
+		TokenID:          saltedID,
+		Accessor:         root.Accessor,
+		Status:           revokeTreeJobStatusRunning,
+		Phase:            revokeTreeJobPhaseInvalidating,
+		Invalidated:      1,
+		StartTime:        time.Now(),
+	}
+	require.NoError(t, c.expiration.persistRevokeTreeJob(ctx, job))
+
+	// Finished jobs past their retention are forgotten.
+	expired := &revokeTreeJob{
+		ID:          "expired",
+		NamespaceID: namespace.RootNamespaceID,
+		Status:      revokeTreeJobStatusCompleted,
+		StartTime:   time.Now().Add(-2 * revokeTreeJobRetention),
+		EndTime:     time.Now().Add(-2 * revokeTreeJobRetention),
+	}
+	require.NoError(t, c.expiration.persistRevokeTreeJob(ctx, expired))
+
+	require.NoError(t, c.expiration.resumeRevokeTreeJobs(ctx))
+
+	status := testWaitRevokeTreeJob(t, c, rootToken, job.ID)
+	require.Equal(t, revokeTreeJobStatusCompleted, status["status"])
+	require.Equal(t, 8, status["invalidated"])
+	require.Equal(t, 7, status["revoked"])
+
+	for _, entry := range append(children, root) {
+		out, err := ts.lookupInternal(ctx, entry.ID, false, true)
+		require.NoError(t, err)
+		require.Nil(t, out)
+	}
+
+	require.Nil(t, c.expiration.RevokeTreeJobStatus(namespace.RootNamespace, expired.ID))
+	entry, err := c.expiration.revokeTreeJobView.Get(ctx, expired.ID)
+	require.NoError(t, err)
+	require.Nil(t, entry)
+}
+
+// testWaitRevokeTreeJob reads the status of the token revocation job until it
+// is no longer running, and returns it.
+func testWaitRevokeTreeJob(t *testing.T, c *Core, token, id string) map[string]interface{} {
+	t.Helper()
+
+	var status map[string]interface{}
+	require.Eventually(t, func() bool {
+		req := logical.TestRequest(t, logical.ReadOperation, "sys/leases/revoke-status/"+id)
+		req.ClientToken = token
+		resp, err := c.HandleRequest(namespace.RootContext(nil), req)
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		status = resp.Data
+		return status["status"] != revokeTreeJobStatusRunning
+	}, 10*time.Second, 10*time.Millisecond)
+	return status
+}
@@ -402,6 +402,10 @@ func (ts *TokenStore) paths() []*framework.Path {
 					Type:        framework.TypeString,
 					Description: "Accessor of the token (request body)",
 				},
+				"async": {
+					Type:        framework.TypeBool,
+					Description: "Revoke the child tokens in the background, returning the ID of the revocation job (request body)",
+				},
 			},
 
 			Callbacks: map[logical.Operation]framework.OperationFunc{
@@ -421,6 +425,13 @@ func (ts *TokenStore) paths() []*framework.Path {
 				OperationSuffix: "self",
 			},
 
+			Fields: map[string]*framework.FieldSchema{
+				"async": {
+					Type:        framework.TypeBool,
+					Description: "Revoke the child tokens in the background, returning the ID of the revocation job (request body)",
+				},
+			},
+
 			Callbacks: map[logical.Operation]framework.OperationFunc{
 				logical.UpdateOperation: ts.handleRevokeSelf,
 			},
@@ -442,6 +453,10 @@ func (ts *TokenStore) paths() []*framework.Path {
 					Type:        framework.TypeString,
 					Description: "Token to revoke (request body)",
 				},
+				"async": {
+					Type:        framework.TypeBool,
+					Description: "Revoke the child tokens in the background, returning the ID of the revocation job (request body)",
+				},
 			},
 
 			Callbacks: map[logical.Operation]framework.OperationFunc{
@@ -664,7 +679,7 @@ func (ts *TokenStore) paths() []*framework.Path {
 	}
 
 	tokenutil.AddTokenFieldsWithAllowList(rolesPath.Fields, []string{"token_bound_cidrs", "token_explicit_max_ttl", "token_period", "token_type", "token_no_default_policy", "token_num_uses"})
-	p = append(p, rolesPath)
+	p = append(p, rolesPath, ts.impersonatePath())
 
 	return p
 }
@@ -701,6 +716,81 @@ func (c *Core) CreateToken(ctx context.Context, entry *logical.TokenEntry) error
 	return c.tokenStore.create(ctx, entry)
 }
 
+// EntityTokens returns the tokens of the namespace of the context which are
+// tied to the given entity. Tokens are found through the accessor index, so
+// batch tokens, which have none, are not returned.
+func (c *Core) EntityTokens(ctx context.Context, entityID string) ([]*logical.TokenEntry, error) {
+	if c.tokenStore == nil {
+		return nil, errors.New("unable to list tokens with nil token store")
+	}
+
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := c.tokenStore.accessorView(ns).List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var tokens []*logical.TokenEntry
+	for _, entry := range entries {
+		aEntry, err := c.tokenStore.lookupByAccessor(ctx, entry, true, false)
+		if err != nil {
+			c.logger.Warn("failed to read accessor entry while listing the tokens of an entity", "error", err)
+			continue
+		}
+		if aEntry == nil || aEntry.TokenID == "" || aEntry.NamespaceID != ns.ID {
+			continue
+		}
+
+		te, err := c.tokenStore.Lookup(ctx, aEntry.TokenID)
+		if err != nil {
+			return nil, err
+		}
+		if te == nil || te.EntityID != entityID {
+			continue
+		}
+		tokens = append(tokens, te)
+	}
+
+	return tokens, nil
+}
+
+// TokenLeases returns the IDs of the leases created with the given token.
+func (c *Core) TokenLeases(ctx context.Context, te *logical.TokenEntry) ([]string, error) {
+	if c.expiration == nil {
+		return nil, errors.New("unable to list leases with nil expiration manager")
+	}
+
+	return c.expiration.lookupLeasesByToken(ctx, te)
+}
+
+// RevokeToken revokes the given token, along with its child tokens and its
+// leases.
+func (c *Core) RevokeToken(ctx context.Context, te *logical.TokenEntry) error {
+	if c.tokenStore == nil {
+		return errors.New("unable to revoke token with nil token store")
+	}
+
+	tokenNS, err := NamespaceByID(ctx, te.NamespaceID, c)
+	if err != nil {
+		return err
+	}
+	if tokenNS == nil {
+		return namespace.ErrNoNamespace
+	}
+
+	revokeCtx := namespace.ContextWithNamespace(c.tokenStore.quitContext, tokenNS)
+	leaseID, err := c.expiration.CreateOrFetchRevocationLeaseByToken(revokeCtx, te)
+	if err != nil {
+		return err
+	}
+
+	return c.expiration.Revoke(revokeCtx, leaseID)
+}
+
 // TokenStore is used to manage client tokens. Tokens are used for
 // clients to authenticate, and each token is mapped to an applicable
 // set of policy which is used for authorization.
@@ -785,6 +875,7 @@ func NewTokenStore(ctx context.Context, logger log.Logger, core *Core, config *l
 			Root: []string{
 				"revoke-orphan",
 				"accessors/",
+				"impersonate",
 			},
 
 			// Most token store items are local since tokens are local, but a
@@ -1051,6 +1142,9 @@ func (ts *TokenStore) create(ctx context.Context, entry *logical.TokenEntry) err
 	}
 
 	entry.Policies = policyutil.SanitizePolicies(entry.Policies, policyutil.DoNotAddDefaultPolicy)
+	if err := checkMountOwnerPolicyNames(entry.Policies); err != nil {
+		return err
+	}
 	var createRootTokenFlag bool
 	if len(entry.Policies) == 1 && entry.Policies[0] == "root" {
 		createRootTokenFlag = true
@@ -1162,6 +1256,8 @@ func (ts *TokenStore) create(ctx context.Context, entry *logical.TokenEntry) err
 			InternalMeta:       entry.InternalMeta,
 			InlinePolicy:       entry.InlinePolicy,
 			NoIdentityPolicies: entry.NoIdentityPolicies,
+
+			BoundClientCertFingerprint: entry.BoundClientCertFingerprint,
 		}
 
 		boundCIDRs := make([]string, len(entry.BoundCIDRs))
@@ -1974,14 +2070,16 @@ func (ts *TokenStore) revokeTree(ctx context.Context, le *leaseEntry) error {
 	}
 
 	// Nuke the entire tree recursively
-	return ts.revokeTreeInternal(revCtx, saltedID)
+	return ts.revokeTreeInternal(revCtx, saltedID, nil)
 }
 
 // revokeTreeInternal is used to invalidate a given token and all
 // child tokens.
 // Updated to be non-recursive and revoke child tokens
 // before parent tokens(DFS).
-func (ts *TokenStore) revokeTreeInternal(ctx context.Context, id string) error {
+// If progress is set, it is called after each token is revoked, and the
+// revocation stops if it returns an error.
+func (ts *TokenStore) revokeTreeInternal(ctx context.Context, id string, progress func() error) error {
 	dfs := []string{id}
 	seenIDs := make(map[string]struct{})
 
@@ -2057,6 +2155,11 @@ func (ts *TokenStore) revokeTreeInternal(ctx context.Context, id string) error {
 			if err := ts.revokeInternal(saltedCtx, saltedID, true); err != nil {
 				return fmt.Errorf("failed to revoke entry: %w", err)
 			}
+			if progress != nil {
+				if err := progress(); err != nil {
+					return err
+				}
+			}
 			// If the length of l is equal to 1, then the last token has been deleted
 			if l == 1 {
 				return nil
@@ -2071,6 +2174,79 @@ func (ts *TokenStore) revokeTreeInternal(ctx context.Context, id string) error {
 	return nil
 }
 
+// invalidateInternal marks the given salted token as pending revocation, so
+// that it can't be used anymore, without revoking it.
+func (ts *TokenStore) invalidateInternal(ctx context.Context, saltedID string) error {
+	entry, err := ts.lookupInternal(ctx, saltedID, true, true)
+	if err != nil {
+		return err
+	}
+	if entry == nil || entry.NumUses == tokenRevocationPending {
+		return nil
+	}
+
+	entry.NumUses = tokenRevocationPending
+	if err := ts.store(ctx, entry); err != nil {
+		return fmt.Errorf("failed to mark token as revoked: %w", err)
+	}
+	return nil
+}
+
+// invalidateTreeInternal marks a given token and all child tokens as pending
+// revocation, parents first, so that the whole tree can't be used anymore
+// while it is revoked by revokeTreeInternal. If progress is set, it is called
+// after each token is invalidated, and the invalidation stops if it returns
+// an error.
+func (ts *TokenStore) invalidateTreeInternal(ctx context.Context, id string, progress func() error) error {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	bfs := []string{id}
+	seenIDs := make(map[string]struct{})
+	for len(bfs) > 0 {
+		id := bfs[0]
+		bfs = bfs[1:]
+		if _, seen := seenIDs[id]; seen {
+			continue
+		}
+		seenIDs[id] = struct{}{}
+
+		saltedCtx := ctx
+		saltedNS := ns
+		saltedID, saltedNSID := namespace.SplitIDFromString(id)
+		if saltedNSID != "" {
+			saltedNS, err = NamespaceByID(ctx, saltedNSID, ts.core)
+			if err != nil {
+				return fmt.Errorf("failed to find namespace for token revocation: %w", err)
+			}
+			if saltedNS == nil {
+				return errors.New("failed to find namespace for token revocation")
+			}
+
+			saltedCtx = namespace.ContextWithNamespace(ctx, saltedNS)
+		}
+
+		if err := ts.invalidateInternal(saltedCtx, saltedID); err != nil {
+			return err
+		}
+		if progress != nil {
+			if err := progress(); err != nil {
+				return err
+			}
+		}
+
+		children, err := ts.parentView(saltedNS).List(saltedCtx, saltedID+"/")
+		if err != nil {
+			return fmt.Errorf("failed to scan for children: %w", err)
+		}
+		bfs = append(bfs, children...)
+	}
+
+	return nil
+}
+
 // handleCreateAgainstRole handles the auth/token/create path for a role
 func (ts *TokenStore) handleCreateAgainstRole(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
 	name := d.Get("role_name").(string)
@@ -2155,6 +2331,38 @@ func (ts *TokenStore) lookupByAccessor(ctx context.Context, id string, salted, t
 	return &aEntry, nil
 }
 
+// walkAccessors calls walkFn with the entry of each token with an accessor in
+// the namespace of the context, including tainted tokens.
+func (ts *TokenStore) walkAccessors(ctx context.Context, ns *namespace.Namespace, walkFn func(*logical.TokenEntry)) error {
+	saltedAccessors, err := ts.accessorView(ns).List(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to list accessors: %w", err)
+	}
+
+	for _, saltedAccessor := range saltedAccessors {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		aEntry, err := ts.lookupByAccessor(ctx, saltedAccessor, true, true)
+		if err != nil {
+			return err
+		}
+		if aEntry == nil || aEntry.TokenID == "" {
+			continue
+		}
+
+		te, err := ts.lookupInternal(ctx, aEntry.TokenID, false, true)
+		if err != nil {
+			return err
+		}
+		if te != nil {
+			walkFn(te)
+		}
+	}
+	return nil
+}
+
 // handleTidy handles the cleaning up of leaked accessor storage entries and
 // cleaning up of leases that are associated to tokens that are expired.
 func (ts *TokenStore) handleTidy(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
@@ -2567,6 +2775,10 @@ func (ts *TokenStore) handleUpdateRevokeAccessor(ctx context.Context, req *logic
 	}
 
 	revokeCtx := namespace.ContextWithNamespace(ts.quitContext, tokenNS)
+	if data.Get("async").(bool) {
+		return ts.revokeAsync(ctx, revokeCtx, req, te)
+	}
+
 	leaseID, err := ts.expiration.CreateOrFetchRevocationLeaseByToken(revokeCtx, te)
 	if err != nil {
 		return nil, err
@@ -2580,6 +2792,28 @@ func (ts *TokenStore) handleUpdateRevokeAccessor(ctx context.Context, req *logic
 	return nil, nil
 }
 
+// revokeAsync starts the revocation of the token and all of its child tokens
+// in the background. The tokens are invalidated right away, but revoked by a
+// job whose progress is read from sys/leases/revoke-status/:job_id.
+func (ts *TokenStore) revokeAsync(ctx, revokeCtx context.Context, req *logical.Request, te *logical.TokenEntry) (*logical.Response, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := ts.expiration.RevokeTreeAsync(revokeCtx, ns, te)
+	if err != nil {
+		return nil, err
+	}
+
+	return logical.RespondWithStatusCode(&logical.Response{
+		Data: map[string]interface{}{
+			"job_id":      job.ID,
+			"status_path": "sys/leases/revoke-status/" + job.ID,
+		},
+	}, req, http.StatusAccepted)
+}
+
 // handleCreate handles the auth/token/create path for creation of new orphan
 // tokens
 func (ts *TokenStore) handleCreateOrphan(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
@@ -3036,6 +3270,10 @@ func (ts *TokenStore) handleCreateCommon(ctx context.Context, req *logical.Reque
 		if role == nil {
 			te.BoundCIDRs = parent.BoundCIDRs
 		}
+
+		// Children of tokens bound to a client certificate are bound to it
+		// as well, so that binding can't be escaped by creating a child.
+		te.BoundClientCertFingerprint = parent.BoundClientCertFingerprint
 	}
 
 	var explicitMaxTTLToUse time.Duration
@@ -3272,6 +3510,10 @@ func (ts *TokenStore) revokeCommon(ctx context.Context, req *logical.Request, da
 	}
 
 	revokeCtx := namespace.ContextWithNamespace(ts.quitContext, tokenNS)
+	if data.Get("async").(bool) {
+		return ts.revokeAsync(ctx, revokeCtx, req, te)
+	}
+
 	leaseID, err := ts.expiration.CreateOrFetchRevocationLeaseByToken(revokeCtx, te)
 	if err != nil {
 		return nil, err
@@ -3394,6 +3636,18 @@ func (ts *TokenStore) handleLookup(ctx context.Context, req *logical.Request, da
 		resp.Data["bound_cidrs"] = out.BoundCIDRs
 	}
 
+	if out.BoundClientCertFingerprint != "" {
+		resp.Data["bound_client_cert_fingerprint"] = out.BoundClientCertFingerprint
+	}
+
+	if out.Impersonator != nil {
+		resp.Data["impersonator"] = map[string]interface{}{
+			"entity_id":    out.Impersonator.EntityID,
+			"accessor":     out.Impersonator.Accessor,
+			"display_name": out.Impersonator.DisplayName,
+		}
+	}
+
 	tokenNS, err := NamespaceByID(ctx, out.NamespaceID, ts.core)
 	if err != nil {
 		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
@@ -3739,6 +3993,12 @@ func (ts *TokenStore) tokenStoreRoleCreateUpdate(ctx context.Context, req *logic
 	if err := entry.ParseTokenFields(req, data); err != nil {
 		return logical.ErrorResponse(fmt.Errorf("error parsing role fields: %w", err).Error()), nil
 	}
+	if err := checkMountOwnerPolicyNames(entry.TokenPolicies); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	if err := checkMountOwnerPolicyNames(entry.AllowedPolicies); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
 
 	entry.TokenType = oldEntryTokenType
 	if entry.TokenType == logical.TokenTypeDefault {
@@ -4197,9 +4457,9 @@ as revocation of tokens. The tokens are renewable if associated with a lease.`
 	tokenRenewAccessorHelp   = `This endpoint will renew a token associated with the given accessor and its properties. Response will not contain the token ID.`
 	tokenLookupHelp          = `This endpoint will lookup a token and its properties.`
 	tokenPathRolesHelp       = `This endpoint allows creating, reading, and deleting roles.`
-	tokenRevokeAccessorHelp  = `This endpoint will delete the token associated with the accessor and all of its child tokens.`
-	tokenRevokeHelp          = `This endpoint will delete the given token and all of its child tokens.`
-	tokenRevokeSelfHelp      = `This endpoint will delete the token used to call it and all of its child tokens.`
+	tokenRevokeAccessorHelp  = `This endpoint will delete the token associated with the accessor and all of its child tokens. With async set, the child tokens are revoked in the background.`
+	tokenRevokeHelp          = `This endpoint will delete the given token and all of its child tokens. With async set, the child tokens are revoked in the background.`
+	tokenRevokeSelfHelp      = `This endpoint will delete the token used to call it and all of its child tokens. With async set, the child tokens are revoked in the background.`
 	tokenRevokeOrphanHelp    = `This endpoint will delete the token and orphan its child tokens.`
 	tokenRenewHelp           = `This endpoint will renew the given token and prevent expiration.`
 	tokenRenewSelfHelp       = `This endpoint will renew the token used to call it and prevent expiration.`
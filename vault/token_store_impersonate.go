@@ -0,0 +1,225 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/hashicorp/vault/helper/metricsutil"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/policyutil"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	// defaultImpersonationTokenTTL is the TTL of impersonation tokens if none
+	// is requested
+	defaultImpersonationTokenTTL = 15 * time.Minute
+
+	// maxImpersonationTokenTTL is the longest TTL an impersonation token may
+	// be issued with. Impersonation tokens are not renewable.
+	maxImpersonationTokenTTL = time.Hour
+)
+
+func (ts *TokenStore) impersonatePath() *framework.Path {
+	return &framework.Path{
+		Pattern: "impersonate$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: "token",
+			OperationVerb:   "impersonate",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"entity_id": {
+				Type:        framework.TypeString,
+				Required:    true,
+				Description: "ID of the entity to impersonate.",
+			},
+			"policies": {
+				Type:        framework.TypeCommaStringSlice,
+				Required:    true,
+				Description: tokenImpersonatePoliciesHelp,
+			},
+			"ttl": {
+				Type:        framework.TypeDurationSecond,
+				Description: "Time to live of the token. Defaults to 15 minutes, and may not exceed 1 hour.",
+				Default:     int(defaultImpersonationTokenTTL.Seconds()),
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: ts.handleImpersonate,
+		},
+
+		HelpSynopsis:    strings.TrimSpace(tokenImpersonateHelp),
+		HelpDescription: strings.TrimSpace(tokenImpersonateDesc),
+	}
+}
+
+// handleImpersonate handles the auth/token/impersonate path, which issues a
+// short-lived, non-renewable token bound to the given entity but carrying
+// only the requested policies. The operator obtaining the token is recorded
+// on it, and tagged in the audit log on every request made with it.
+func (ts *TokenStore) handleImpersonate(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	parent, err := ts.Lookup(ctx, req.ClientToken)
+	if err != nil {
+		return nil, fmt.Errorf("parent token lookup failed: %w", err)
+	}
+	if parent == nil {
+		return logical.ErrorResponse("parent token lookup failed: no parent found"), logical.ErrInvalidRequest
+	}
+	switch {
+	case parent.Type == logical.TokenTypeBatch:
+		return logical.ErrorResponse("batch tokens cannot create more tokens"), nil
+	case parent.NumUses > 0:
+		return logical.ErrorResponse("restricted use token cannot generate child tokens"), logical.ErrInvalidRequest
+	case parent.Impersonator != nil:
+		return logical.ErrorResponse("impersonation tokens cannot be used to impersonate"), logical.ErrPermissionDenied
+	}
+
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if ns.ID != parent.NamespaceID {
+		return logical.ErrorResponse("impersonation tokens must be created in the namespace of the requesting token"), logical.ErrInvalidRequest
+	}
+
+	entityID := d.Get("entity_id").(string)
+	if entityID == "" {
+		return logical.ErrorResponse("missing entity_id"), logical.ErrInvalidRequest
+	}
+	entity, identityPolicies, err := ts.core.fetchEntityAndDerivedPolicies(ctx, ns, entityID, false)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case entity == nil || entity.NamespaceID != ns.ID:
+		return logical.ErrorResponse("entity not found"), logical.ErrInvalidRequest
+	case entity.Disabled:
+		return logical.ErrorResponse("entity is disabled"), logical.ErrInvalidRequest
+	}
+
+	// The token may only carry policies that are available either to the
+	// impersonated entity through identity, or to the operator themselves,
+	// so that impersonation never grants more than the two combined.
+	policies := policyutil.SanitizePolicies(d.Get("policies").([]string), policyutil.DoNotAddDefaultPolicy)
+	if len(policies) == 0 {
+		return logical.ErrorResponse("at least one policy must be given"), logical.ErrInvalidRequest
+	}
+	if strutil.StrListContains(policies, "root") {
+		return logical.ErrorResponse("impersonation tokens cannot be root tokens"), logical.ErrInvalidRequest
+	}
+	for _, policy := range policies {
+		if strutil.StrListContains(nonAssignablePolicies, policy) {
+			return logical.ErrorResponse(fmt.Sprintf("cannot assign policy %q", policy)), logical.ErrInvalidRequest
+		}
+	}
+	if !strutil.StrListContains(parent.Policies, "root") {
+		available := append(policyutil.SanitizePolicies(parent.Policies, policyutil.DoNotAddDefaultPolicy), identityPolicies[ns.ID]...)
+		if !strutil.StrListSubset(available, policies) {
+			return logical.ErrorResponse("policies must be a subset of the policies of the entity or of the requesting token"), logical.ErrInvalidRequest
+		}
+	}
+
+	ttl := time.Duration(d.Get("ttl").(int)) * time.Second
+	switch {
+	case ttl <= 0:
+		ttl = defaultImpersonationTokenTTL
+	case ttl > maxImpersonationTokenTTL:
+		return logical.ErrorResponse("ttl may not exceed %s", maxImpersonationTokenTTL), logical.ErrInvalidRequest
+	}
+
+	displayName := displayNameSanitize.ReplaceAllString("impersonate-"+entity.Name, "-")
+
+	te := logical.TokenEntry{
+		Parent:             req.ClientToken,
+		Path:               "auth/token/impersonate",
+		Policies:           policies,
+		DisplayName:        strings.TrimSuffix(displayName, "-"),
+		CreationTime:       time.Now().Unix(),
+		TTL:                ttl,
+		ExplicitMaxTTL:     ttl,
+		NamespaceID:        ns.ID,
+		Type:               logical.TokenTypeService,
+		EntityID:           entity.ID,
+		NoIdentityPolicies: true,
+		Impersonator: &logical.Impersonator{
+			EntityID:    parent.EntityID,
+			Accessor:    parent.Accessor,
+			DisplayName: parent.DisplayName,
+		},
+	}
+
+	if ts.core.perfStandby {
+		forwardedTokenEntry, err := forwardCreateTokenRegisterAuth(ctx, ts.core, &te, "", false, 0, ttl)
+		if err != nil {
+			return logical.ErrorResponse(err.Error()), ErrInternalError
+		}
+		te = *forwardedTokenEntry
+	} else {
+		if err := ts.create(ctx, &te); err != nil {
+			return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+		}
+	}
+
+	ts.core.metricSink.IncrCounterWithLabels(
+		[]string{"token", "impersonation"},
+		1,
+		[]metrics.Label{
+			metricsutil.NamespaceLabel(ns),
+		},
+	)
+	ts.logger.Info("issued impersonation token", "entity_id", entity.ID, "actor_entity_id", parent.EntityID, "policies", te.Policies)
+
+	resp := &logical.Response{
+		Auth: &logical.Auth{
+			DisplayName: te.DisplayName,
+			Policies:    te.Policies,
+			LeaseOptions: logical.LeaseOptions{
+				TTL:       te.TTL,
+				Renewable: false,
+			},
+			ClientToken:    te.ID,
+			Accessor:       te.Accessor,
+			EntityID:       te.EntityID,
+			ExplicitMaxTTL: ttl,
+			CreationPath:   te.Path,
+			TokenType:      te.Type,
+			Impersonator:   te.Impersonator,
+		},
+	}
+
+	// We have registered the auth at this point if core is perfStandby.
+	if ts.core.perfStandby && te.ExternalID != "" {
+		resp.Auth.ClientToken = te.ExternalID
+	}
+
+	return resp, nil
+}
+
+const (
+	tokenImpersonateHelp = `This endpoint creates a token that impersonates an entity with a reduced set of policies.`
+	tokenImpersonateDesc = `
+This endpoint creates a short-lived, non-renewable token that is bound to the
+given entity, so that templated policies and identity-aware endpoints behave
+as they would for that entity, but that carries only the given policies.
+Identity policies of the entity are not inherited. The policies must be held
+by the entity through identity, or by the requesting token.
+
+The requesting token is recorded as the impersonator on the new token, and
+every audit entry for a request made with the token, or for its creation,
+contains an "impersonation" block identifying the actor and the subject.
+This endpoint requires sudo capability.
+`
+	tokenImpersonatePoliciesHelp = `Policies of the token. Each policy must be held by the entity through
+identity, or by the requesting token.`
+)
@@ -6180,3 +6180,91 @@ func TestTokenStore_Collectors(t *testing.T) {
 	// Need to set up router for this to work, TODO
 	// ts.gaugeCollectorByMethod( ctx )
 }
+
+func TestTokenStore_Impersonate(t *testing.T) {
+	c, _, root := TestCoreUnsealed(t)
+	ts := c.tokenStore
+	ctx := namespace.RootContext(nil)
+
+	resp, err := c.identityStore.HandleRequest(ctx, &logical.Request{
+		Path:      "entity",
+		Operation: logical.UpdateOperation,
+		Data: map[string]interface{}{
+			"name":     "alice",
+			"policies": []string{"subject"},
+		},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v\nresp: %#v", err, resp)
+	}
+	entityID := resp.Data["id"].(string)
+
+	testMakeServiceTokenViaBackend(t, ts, root, "operator", "", []string{"ops"})
+	operator, err := ts.Lookup(ctx, "operator")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	impersonate := func(clientToken string, data map[string]interface{}) (*logical.Response, error) {
+		req := logical.TestRequest(t, logical.UpdateOperation, "impersonate")
+		req.ClientToken = clientToken
+		req.Data = data
+		return ts.HandleRequest(ctx, req)
+	}
+
+	// Policies held by neither the entity nor the operator are rejected
+	resp, err = impersonate("operator", map[string]interface{}{
+		"entity_id": entityID,
+		"policies":  "other",
+	})
+	if err != logical.ErrInvalidRequest {
+		t.Fatalf("expected invalid request, got err: %v\nresp: %#v", err, resp)
+	}
+
+	resp, err = impersonate("operator", map[string]interface{}{
+		"entity_id": entityID,
+		"policies":  "subject",
+		"ttl":       "2h",
+	})
+	if err != logical.ErrInvalidRequest {
+		t.Fatalf("expected invalid request, got err: %v\nresp: %#v", err, resp)
+	}
+
+	resp, err = impersonate("operator", map[string]interface{}{
+		"entity_id": entityID,
+		"policies":  "subject,ops",
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v\nresp: %#v", err, resp)
+	}
+	if resp.Auth.Renewable || resp.Auth.TTL != defaultImpersonationTokenTTL {
+		t.Fatalf("expected a non-renewable token with the default TTL, got: %#v", resp.Auth)
+	}
+
+	te, err := ts.Lookup(ctx, resp.Auth.ClientToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if te.EntityID != entityID || !te.NoIdentityPolicies || te.Parent != "operator" {
+		t.Fatalf("bad: %#v", te)
+	}
+	if !reflect.DeepEqual(te.Policies, []string{"ops", "subject"}) {
+		t.Fatalf("bad: policies: %#v", te.Policies)
+	}
+	expected := &logical.Impersonator{
+		Accessor:    operator.Accessor,
+		DisplayName: operator.DisplayName,
+	}
+	if !reflect.DeepEqual(te.Impersonator, expected) {
+		t.Fatalf("bad: impersonator: %#v", te.Impersonator)
+	}
+
+	// Impersonation tokens cannot be chained
+	resp, err = impersonate(te.ID, map[string]interface{}{
+		"entity_id": entityID,
+		"policies":  "subject",
+	})
+	if err != logical.ErrPermissionDenied {
+		t.Fatalf("expected permission denied, got err: %v\nresp: %#v", err, resp)
+	}
+}
@@ -0,0 +1,421 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/armon/go-metrics"
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/helper/locking"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/vault/eventbus"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// defaultWatchdogGoroutineGrowth is the growth of the number of goroutines
+	// over watchdogGoroutineWindow which triggers the watchdog by default.
+	defaultWatchdogGoroutineGrowth = 10000
+
+	// defaultWatchdogStateLockHold is how long the state lock can be held
+	// before it triggers the watchdog by default.
+	defaultWatchdogStateLockHold = 30 * time.Second
+
+	// defaultWatchdogBarrierLatency is how long a read through the barrier can
+	// take before it triggers the watchdog by default.
+	defaultWatchdogBarrierLatency = 5 * time.Second
+
+	// watchdogInterval is how often the watchdog checks the core.
+	watchdogInterval = 10 * time.Second
+
+	// watchdogGoroutineWindow is the window over which the growth of the
+	// number of goroutines is measured.
+	watchdogGoroutineWindow = 5 * time.Minute
+
+	// watchdogCooldown is the minimum delay between two debug bundles, so that
+	// a lasting problem doesn't fill the disk.
+	watchdogCooldown = 15 * time.Minute
+
+	// watchdogCPUProfileDuration is how long the CPU is profiled for when
+	// capturing a debug bundle.
+	watchdogCPUProfileDuration = 10 * time.Second
+
+	// watchdogLogLines is the number of recent log lines kept for the debug
+	// bundles.
+	watchdogLogLines = 1000
+
+	// watchdogProbePath is the barrier path read to measure its latency. It
+	// doesn't need to exist.
+	watchdogProbePath = "core/watchdog-probe"
+)
+
+// eventTypeWatchdogAlert is the type of the events sent when the watchdog
+// captures a debug bundle.
+const eventTypeWatchdogAlert logical.EventType = "core/watchdog/alert"
+
+// watchdog monitors the growth of the number of goroutines, how long the state
+// lock is held and the latency of the barrier. When one of them exceeds its
+// threshold, it captures a debug bundle with profiles, a snapshot of the
+// metrics and the recent logs, and sends an alert event.
+type watchdog struct {
+	core   *Core
+	logger log.Logger
+
+	path            string
+	goroutineGrowth int
+	stateLockHold   time.Duration
+	barrierLatency  time.Duration
+
+	// stateLock is the state lock of the core, which is timed when the
+	// watchdog is enabled.
+	stateLock *locking.TimedRWMutex
+
+	logs *watchdogLogs
+	sink log.SinkAdapter
+
+	// goroutines are the numbers of goroutines sampled over the window.
+	goroutines []int
+
+	// probeDone is closed once the pending barrier probe completes, and
+	// probeStart is when it started.
+	probeDone  chan struct{}
+	probeStart time.Time
+
+	lastCapture time.Time
+
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+	stopOnce sync.Once
+}
+
+func newWatchdog(c *Core, conf *CoreConfig, logger log.Logger) *watchdog {
+	w := &watchdog{
+		core:            c,
+		logger:          logger,
+		path:            conf.WatchdogPath,
+		goroutineGrowth: conf.WatchdogGoroutineGrowth,
+		stateLockHold:   conf.WatchdogStateLockHold,
+		barrierLatency:  conf.WatchdogBarrierLatency,
+		logs:            newWatchdogLogs(watchdogLogLines),
+		stopCh:          make(chan struct{}),
+		doneCh:          make(chan struct{}),
+	}
+	if w.goroutineGrowth <= 0 {
+		w.goroutineGrowth = defaultWatchdogGoroutineGrowth
+	}
+	if w.stateLockHold <= 0 {
+		w.stateLockHold = defaultWatchdogStateLockHold
+	}
+	if w.barrierLatency <= 0 {
+		w.barrierLatency = defaultWatchdogBarrierLatency
+	}
+	w.stateLock, _ = c.stateLock.(*locking.TimedRWMutex)
+	return w
+}
+
+// start keeps the recent logs of the core and starts checking it.
+func (w *watchdog) start() {
+	if il, ok := w.core.logger.(log.InterceptLogger); ok {
+		w.sink = log.NewSinkAdapter(&log.LoggerOptions{
+			Output: w.logs,
+			Level:  w.core.logger.GetLevel(),
+		})
+		il.RegisterSink(w.sink)
+	}
+
+	go w.run()
+}
+
+// stop stops checking the core, and waits for a debug bundle being captured.
+func (w *watchdog) stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+		<-w.doneCh
+		if il, ok := w.core.logger.(log.InterceptLogger); ok && w.sink != nil {
+			il.DeregisterSink(w.sink)
+		}
+	})
+}
+
+func (w *watchdog) run() {
+	defer close(w.doneCh)
+
+	ticker := time.NewTicker(watchdogInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+// check captures a debug bundle if a threshold is exceeded, unless one was
+// captured recently.
+func (w *watchdog) check() {
+	reasons := w.breaches()
+	if len(reasons) == 0 {
+		return
+	}
+
+	for _, reason := range reasons {
+		w.logger.Warn("watchdog threshold exceeded", "reason", reason)
+	}
+	if !w.lastCapture.IsZero() && time.Since(w.lastCapture) < watchdogCooldown {
+		return
+	}
+	w.lastCapture = time.Now()
+
+	dir, err := w.capture(reasons)
+	if err != nil {
+		w.logger.Error("failed to capture debug bundle", "error", err)
+		return
+	}
+	w.logger.Error("captured debug bundle", "path", dir, "reasons", reasons)
+	metrics.IncrCounter([]string{"core", "watchdog", "bundle"}, 1)
+	w.sendAlert(dir, reasons)
+}
+
+// breaches returns the reasons the core looks unhealthy, if any.
+func (w *watchdog) breaches() []string {
+	var reasons []string
+
+	if growth := w.sampleGoroutines(); growth >= w.goroutineGrowth {
+		reasons = append(reasons, fmt.Sprintf("goroutines grew by %d over %s", growth, watchdogGoroutineWindow))
+	}
+
+	if w.stateLock != nil {
+		if held := w.stateLock.HeldFor(); held >= w.stateLockHold {
+			reasons = append(reasons, fmt.Sprintf("state lock held for %s", held.Round(time.Millisecond)))
+		}
+	}
+
+	if latency := w.probeBarrier(); latency >= w.barrierLatency {
+		reasons = append(reasons, fmt.Sprintf("barrier read took %s", latency.Round(time.Millisecond)))
+	}
+
+	return reasons
+}
+
+// sampleGoroutines records the current number of goroutines, and returns
+// their growth over the window.
+func (w *watchdog) sampleGoroutines() int {
+	current := runtime.NumGoroutine()
+	w.goroutines = append(w.goroutines, current)
+	if size := int(watchdogGoroutineWindow / watchdogInterval); len(w.goroutines) > size {
+		w.goroutines = w.goroutines[len(w.goroutines)-size:]
+	}
+
+	lowest := current
+	for _, n := range w.goroutines {
+		if n < lowest {
+			lowest = n
+		}
+	}
+	return current - lowest
+}
+
+// probeBarrier reads through the barrier, and returns how long it took. The
+// read is left running in the background if it takes longer than the
+// threshold, in which case it is only awaited by the next probes. Sealed
+// barriers aren't probed.
+func (w *watchdog) probeBarrier() time.Duration {
+	if w.probeDone != nil {
+		select {
+		case <-w.probeDone:
+			w.probeDone = nil
+		default:
+			return time.Since(w.probeStart)
+		}
+	}
+
+	barrier := w.core.barrier
+	if barrier == nil {
+		return 0
+	}
+	if sealed, err := barrier.Sealed(); err != nil || sealed {
+		return 0
+	}
+
+	done := make(chan struct{})
+	start := time.Now()
+	go func() {
+		defer close(done)
+		barrier.Get(context.Background(), watchdogProbePath)
+	}()
+
+	timer := time.NewTimer(w.barrierLatency)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return time.Since(start)
+	case <-timer.C:
+		w.probeDone = done
+		w.probeStart = start
+		return time.Since(start)
+	}
+}
+
+// capture writes a debug bundle to a new directory of the watchdog path, and
+// returns that directory. Whatever can be captured is kept, even if some of
+// the bundle fails.
+func (w *watchdog) capture(reasons []string) (string, error) {
+	now := time.Now().UTC()
+	dir := filepath.Join(w.path, "vault-watchdog-"+now.Format("20060102T150405Z"))
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create debug bundle directory: %w", err)
+	}
+
+	writeFile := func(name string, write func(f *os.File) error) error {
+		f, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+		if err != nil {
+			return err
+		}
+		if err := write(f); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		return f.Close()
+	}
+
+	var errs []error
+	summary := map[string]interface{}{
+		"time":       now.Format(time.RFC3339Nano),
+		"reasons":    reasons,
+		"goroutines": runtime.NumGoroutine(),
+	}
+	errs = append(errs, writeFile("watchdog.json", func(f *os.File) error {
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		return enc.Encode(summary)
+	}))
+	errs = append(errs, writeFile("goroutine.txt", func(f *os.File) error {
+		return pprof.Lookup("goroutine").WriteTo(f, 2)
+	}))
+	errs = append(errs, writeFile("heap.prof", func(f *os.File) error {
+		return pprof.Lookup("heap").WriteTo(f, 0)
+	}))
+	if w.core.metricsHelper != nil {
+		errs = append(errs, writeFile("metrics.json", func(f *os.File) error {
+			resp := w.core.metricsHelper.GenericResponse()
+			body, ok := resp.Data[logical.HTTPRawBody].([]byte)
+			if !ok {
+				return fmt.Errorf("%v", resp.Data[logical.HTTPRawBody])
+			}
+			_, err := f.Write(body)
+			return err
+		}))
+	}
+	errs = append(errs, writeFile("vault.log", func(f *os.File) error {
+		_, err := f.WriteString(strings.Join(w.logs.lines(), ""))
+		return err
+	}))
+	errs = append(errs, writeFile("profile.prof", func(f *os.File) error {
+		if err := pprof.StartCPUProfile(f); err != nil {
+			return err
+		}
+		defer pprof.StopCPUProfile()
+
+		timer := time.NewTimer(watchdogCPUProfileDuration)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-w.stopCh:
+		}
+		return nil
+	}))
+
+	for _, err := range errs {
+		if err != nil {
+			w.logger.Warn("failed to capture part of the debug bundle", "path", dir, "error", err)
+		}
+	}
+	return dir, nil
+}
+
+// sendAlert sends an event about the debug bundle captured. Events are
+// best-effort: failures to send them are logged.
+func (w *watchdog) sendAlert(dir string, reasons []string) {
+	if w.core.events == nil {
+		return
+	}
+
+	err := func() error {
+		event, err := logical.NewEvent()
+		if err != nil {
+			return err
+		}
+		metadataReasons := make([]interface{}, 0, len(reasons))
+		for _, reason := range reasons {
+			metadataReasons = append(metadataReasons, reason)
+		}
+		event.Metadata, err = structpb.NewStruct(map[string]interface{}{
+			"bundle_path": dir,
+			"reasons":     metadataReasons,
+		})
+		if err != nil {
+			return err
+		}
+		return w.core.events.SendInternal(namespace.RootContext(context.Background()), namespace.RootNamespace, nil, eventTypeWatchdogAlert, event)
+	}()
+	if err != nil && !errors.Is(err, eventbus.ErrNotStarted) {
+		w.logger.Warn("failed to send watchdog alert event", "error", err)
+	}
+}
+
+// watchdogLogs keeps the most recent log lines written to it.
+type watchdogLogs struct {
+	l     sync.Mutex
+	ring  []string
+	next  int
+	count int
+}
+
+func newWatchdogLogs(size int) *watchdogLogs {
+	return &watchdogLogs{
+		ring: make([]string, size),
+	}
+}
+
+// Write keeps a log line, replacing the oldest one once full. The loggers
+// write each line at once.
+func (r *watchdogLogs) Write(p []byte) (int, error) {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	r.ring[r.next] = string(p)
+	r.next = (r.next + 1) % len(r.ring)
+	if r.count < len(r.ring) {
+		r.count++
+	}
+	return len(p), nil
+}
+
+// lines returns the log lines kept, oldest first.
+func (r *watchdogLogs) lines() []string {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	lines := make([]string, 0, r.count)
+	start := (r.next - r.count + len(r.ring)) % len(r.ring)
+	for i := 0; i < r.count; i++ {
+		lines = append(lines, r.ring[(start+i)%len(r.ring)])
+	}
+	return lines
+}
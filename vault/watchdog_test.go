@@ -0,0 +1,98 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/helper/locking"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWatchdog_Capture verifies that the watchdog captures a debug bundle when
+// the state lock is held for too long, and not again during its cooldown.
+func TestWatchdog_Capture(t *testing.T) {
+	dir := t.TempDir()
+	conf := &CoreConfig{
+		WatchdogPath:          dir,
+		WatchdogStateLockHold: time.Millisecond,
+	}
+	c, _, _ := TestCoreUnsealedWithConfig(t, conf)
+	require.IsType(t, &locking.TimedRWMutex{}, c.stateLock)
+
+	// A watchdog of its own is checked, rather than waiting for the core's.
+	// Stopping it beforehand cuts the CPU profile short.
+	w := newWatchdog(c, conf, c.logger)
+	close(w.stopCh)
+
+	readBundles := func() []os.DirEntry {
+		t.Helper()
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		return entries
+	}
+
+	w.check()
+	require.Empty(t, readBundles())
+
+	c.stateLock.Lock()
+	time.Sleep(10 * time.Millisecond)
+	w.check()
+	c.stateLock.Unlock()
+
+	bundles := readBundles()
+	require.Len(t, bundles, 1)
+	bundle := filepath.Join(dir, bundles[0].Name())
+	for _, name := range []string{"watchdog.json", "goroutine.txt", "heap.prof", "vault.log", "profile.prof"} {
+		require.FileExists(t, filepath.Join(bundle, name))
+	}
+
+	raw, err := os.ReadFile(filepath.Join(bundle, "watchdog.json"))
+	require.NoError(t, err)
+	var summary struct {
+		Reasons []string `json:"reasons"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &summary))
+	require.Len(t, summary.Reasons, 1)
+	require.True(t, strings.HasPrefix(summary.Reasons[0], "state lock held for"), summary.Reasons[0])
+
+	c.stateLock.Lock()
+	time.Sleep(10 * time.Millisecond)
+	w.check()
+	c.stateLock.Unlock()
+	require.Len(t, readBundles(), 1)
+}
+
+// TestWatchdog_Goroutines verifies that the growth of the number of goroutines
+// is measured from the lowest number sampled.
+func TestWatchdog_Goroutines(t *testing.T) {
+	w := &watchdog{}
+	w.sampleGoroutines()
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	for i := 0; i < 100; i++ {
+		go func() {
+			<-stopCh
+		}()
+	}
+
+	require.GreaterOrEqual(t, w.sampleGoroutines(), 90)
+}
+
+func TestWatchdog_Logs(t *testing.T) {
+	logs := newWatchdogLogs(3)
+	require.Empty(t, logs.lines())
+
+	for _, line := range []string{"a\n", "b\n", "c\n", "d\n"} {
+		_, err := logs.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.Equal(t, []string{"b\n", "c\n", "d\n"}, logs.lines())
+}